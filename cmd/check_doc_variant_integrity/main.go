@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/app"
+)
+
+// danglingRef describes one nullable foreign-key-like column that should point at an existing row.
+type danglingRef struct {
+	Table     string
+	Column    string
+	RefTable  string
+	RefColumn string
+}
+
+var checks = []danglingRef{
+	{Table: "doc_variant_exposure", Column: "variant_id", RefTable: "learning_node_doc_variant", RefColumn: "id"},
+	{Table: "doc_variant_exposure", Column: "base_doc_id", RefTable: "learning_node_doc", RefColumn: "id"},
+	{Table: "doc_variant_outcome", Column: "variant_id", RefTable: "learning_node_doc_variant", RefColumn: "id"},
+	{Table: "doc_variant_outcome", Column: "exposure_id", RefTable: "doc_variant_exposure", RefColumn: "id"},
+}
+
+func (c danglingRef) where() string {
+	return fmt.Sprintf(
+		"%s.%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s r WHERE r.%s = %s.%s)",
+		c.Table, c.Column, c.RefTable, c.RefColumn, c.Table, c.Column,
+	)
+}
+
+func main() {
+	var fix bool
+	var sample int
+	flag.BoolVar(&fix, "fix", false, "null out dangling references instead of only reporting them")
+	flag.IntVar(&sample, "sample", 10, "number of offending row ids to print per check")
+	flag.Parse()
+
+	application, err := app.New()
+	if err != nil {
+		fmt.Printf("init app: %v\n", err)
+		os.Exit(1)
+	}
+	defer application.Close()
+
+	db := application.DB.WithContext(context.Background())
+
+	total := int64(0)
+	failed := false
+	for _, c := range checks {
+		n, ids, err := scan(db, c, sample)
+		if err != nil {
+			fmt.Printf("[check] %s.%s -> %s: %v\n", c.Table, c.Column, c.RefTable, err)
+			failed = true
+			continue
+		}
+		total += n
+		fmt.Printf("[check] %s.%s -> %s.%s dangling=%d\n", c.Table, c.Column, c.RefTable, c.RefColumn, n)
+		for _, id := range ids {
+			fmt.Printf("  %s\n", id)
+		}
+		if n == 0 || !fix {
+			continue
+		}
+		res := db.Exec(fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s", c.Table, c.Column, c.where()))
+		if res.Error != nil {
+			fmt.Printf("[fix] %s.%s: %v\n", c.Table, c.Column, res.Error)
+			failed = true
+			continue
+		}
+		fmt.Printf("[fix] %s.%s nulled=%d\n", c.Table, c.Column, res.RowsAffected)
+	}
+
+	if fix {
+		fmt.Printf("done. dangling=%d (fixed)\n", total)
+	} else {
+		fmt.Printf("done. dangling=%d (re-run with --fix to null them)\n", total)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func scan(db *gorm.DB, c danglingRef, sample int) (int64, []uuid.UUID, error) {
+	var n int64
+	if err := db.Table(c.Table).Where(c.where()).Count(&n).Error; err != nil {
+		return 0, nil, err
+	}
+	if n == 0 || sample <= 0 {
+		return n, nil, nil
+	}
+	var ids []uuid.UUID
+	if err := db.Table(c.Table).Where(c.where()).Order(c.Table+".id").Limit(sample).Pluck(c.Table+".id", &ids).Error; err != nil {
+		return n, nil, err
+	}
+	return n, ids, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yungbote/neurobridge-backend/internal/app"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// Reports path nodes with more than one learning_node_doc row (the unique index on
+// path_node_id should prevent them; a partially failed migration did not). With --fix each
+// node keeps its latest doc and the others are archived as revisions, then deleted.
+func main() {
+	var fix bool
+	var limit int
+	flag.BoolVar(&fix, "fix", false, "keep the latest doc per node and archive the other rows as revisions")
+	flag.IntVar(&limit, "limit", 0, "max nodes to report/repair (0 = all)")
+	flag.Parse()
+
+	application, err := app.New()
+	if err != nil {
+		fmt.Printf("init app: %v\n", err)
+		os.Exit(1)
+	}
+	defer application.Close()
+
+	docs := application.Repos.DocGen.LearningNodeDoc
+	dbc := dbctx.Context{Ctx: context.Background()}
+
+	dups, err := docs.ListDuplicatePathNodes(dbc, limit)
+	if err != nil {
+		fmt.Printf("[check] learning_node_doc duplicates: %v\n", err)
+		os.Exit(1)
+	}
+	rows := 0
+	failed := false
+	archived := 0
+	for _, d := range dups {
+		rows += d.Count
+		fmt.Printf("[check] path_node_id=%s docs=%d\n", d.PathNodeID, d.Count)
+		if !fix {
+			continue
+		}
+		n, err := docs.RepairDuplicates(dbc, d.PathNodeID)
+		if err != nil {
+			fmt.Printf("[fix] path_node_id=%s: %v\n", d.PathNodeID, err)
+			failed = true
+			continue
+		}
+		archived += n
+		fmt.Printf("[fix] path_node_id=%s archived=%d\n", d.PathNodeID, n)
+	}
+
+	if fix {
+		fmt.Printf("done. nodes=%d rows=%d archived=%d\n", len(dups), rows, archived)
+	} else {
+		fmt.Printf("done. nodes=%d rows=%d (re-run with --fix to repair)\n", len(dups), rows)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/yungbote/neurobridge-backend/internal/app"
+	"github.com/yungbote/neurobridge-backend/internal/data/db"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/steps"
+)
+
+func main() {
+	var apply bool
+	var limit int
+	flag.BoolVar(&apply, "apply", false, "merge duplicate canonical concepts (default is a dry run that only reports)")
+	flag.IntVar(&limit, "limit", 0, "max duplicate groups to process (0 = all)")
+	flag.Parse()
+
+	application, err := app.New()
+	if err != nil {
+		fmt.Printf("init app: %v\n", err)
+		os.Exit(1)
+	}
+	defer application.Close()
+
+	ctx := context.Background()
+	report, err := steps.ReconcileCanonicalConcepts(ctx, steps.CanonicalReconcileDeps{
+		DB:  application.DB,
+		Log: application.Log,
+		AI:  application.Clients.OpenaiClient,
+		Vec: application.Clients.PineconeVectorStore,
+	}, steps.CanonicalReconcileOptions{DryRun: !apply, Limit: limit})
+	if err != nil {
+		fmt.Printf("reconcile: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, g := range report.Groups {
+		fmt.Printf("[group] key=%q winner=%s losers=%d states_merged=%d\n", g.Key, g.WinnerID, len(g.LoserIDs), g.StatesMerged)
+		for _, id := range g.LoserIDs {
+			fmt.Printf("  loser %s\n", id)
+		}
+		printCounts("repointed", g.Repointed)
+		printCounts("dropped", g.Dropped)
+		if g.Error != "" {
+			fmt.Printf("  error: %s\n", g.Error)
+			failed++
+		}
+	}
+
+	if !apply {
+		fmt.Printf("done. groups=%d failed=%d (dry run; pass --apply to merge)\n", len(report.Groups), failed)
+	} else {
+		fmt.Printf("done. groups=%d failed=%d vectors_written=%d vectors_deleted=%d\n", len(report.Groups), failed, report.VectorsWritten, report.VectorsDeleted)
+		created, dupes, err := db.EnsureCanonicalConceptKeyIndex(application.DB.WithContext(ctx))
+		switch {
+		case err != nil:
+			fmt.Printf("[index] %v\n", err)
+			failed++
+		case created:
+			fmt.Printf("[index] idx_concept_global_natural_key ready\n")
+		default:
+			fmt.Printf("[index] still blocked by %d duplicate keys\n", dupes)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printCounts(label string, counts map[string]int64) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s %s=%d\n", label, k, counts[k])
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/app"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/steps"
+)
+
+func main() {
+	var pathArg string
+	var fix bool
+	var sample int
+	flag.StringVar(&pathArg, "path", "", "path id to validate (comma-separated for several)")
+	flag.BoolVar(&fix, "fix", false, "repair violations with the concept graph normalizers/canonicalizer")
+	flag.IntVar(&sample, "sample", 10, "number of offending rows to print per violation kind")
+	flag.Parse()
+
+	var pathIDs []uuid.UUID
+	for _, raw := range strings.Split(pathArg, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			fmt.Printf("invalid path id %q: %v\n", raw, err)
+			os.Exit(2)
+		}
+		pathIDs = append(pathIDs, id)
+	}
+	if len(pathIDs) == 0 {
+		fmt.Println("usage: validate_concept_graph --path <uuid>[,<uuid>...] [--fix]")
+		os.Exit(2)
+	}
+
+	application, err := app.New()
+	if err != nil {
+		fmt.Printf("init app: %v\n", err)
+		os.Exit(1)
+	}
+	defer application.Close()
+
+	ctx := context.Background()
+	deps := steps.ConceptGraphValidateDeps{DB: application.DB, Log: application.Log}
+
+	total := 0
+	failed := false
+	for _, pathID := range pathIDs {
+		report, err := steps.ValidateConceptGraph(ctx, deps, pathID, fix)
+		if err != nil {
+			fmt.Printf("[path] %s: %v\n", pathID, err)
+			failed = true
+			continue
+		}
+		total += len(report.Violations)
+		fmt.Printf("[path] %s concepts=%d edges=%d evidence=%d violations=%d\n",
+			pathID, report.Concepts, report.Edges, report.Evidence, len(report.Violations))
+
+		counts := report.Counts()
+		kinds := make([]string, 0, len(counts))
+		for k := range counts {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Printf("  [check] %s=%d\n", kind, counts[kind])
+			shown := 0
+			for _, v := range report.Violations {
+				if v.Kind != kind || shown >= sample {
+					continue
+				}
+				shown++
+				fmt.Printf("    %s %s %s\n", v.Table, v.RowID, v.Detail)
+			}
+		}
+		if fix {
+			fixed := make([]string, 0, len(report.Fixed))
+			for k := range report.Fixed {
+				fixed = append(fixed, k)
+			}
+			sort.Strings(fixed)
+			for _, k := range fixed {
+				fmt.Printf("  [fix] %s=%d\n", k, report.Fixed[k])
+			}
+		}
+	}
+
+	if fix {
+		fmt.Printf("done. violations=%d (fixed)\n", total)
+	} else {
+		fmt.Printf("done. violations=%d (re-run with --fix to repair them)\n", total)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
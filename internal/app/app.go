@@ -14,10 +14,12 @@ import (
 
 	"github.com/yungbote/neurobridge-backend/internal/data/db"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/steps"
 	"github.com/yungbote/neurobridge-backend/internal/observability"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/realtime"
 )
@@ -77,9 +79,18 @@ func New() (*App, error) {
 	}
 
 	theDB := pg.DB()
+	if err := featureflag.SetSource(context.Background(), featureflag.DBSource(theDB)); err != nil {
+		log.Warn("Feature flags not loaded; using env defaults", "error", err)
+	}
 	ssehub := realtime.NewSSEHub(log)
 
 	reposet := wireRepos(theDB, log)
+	if runMigrations {
+		if err := backfillDocVariantAssignments(log, reposet); err != nil {
+			log.Sync()
+			return nil, fmt.Errorf("doc variant assignment backfill: %w", err)
+		}
+	}
 
 	clientSet, err := wireClients(log, cfg)
 	if err != nil {
@@ -173,6 +184,19 @@ func (a *App) Start(runServer bool, runWorker bool) error {
 	if runServer {
 		go a.seedTeachingPatternsOnStartup(ctx)
 	}
+
+	// (D) Background: keep the doc policy config cache warm for the doc serving hot path.
+	if runServer {
+		go docgen.RunDocPolicyRefresher(ctx)
+	}
+
+	// (E) Background: refresh feature flags (handlers and job steps both evaluate them).
+	go featureflag.RunRefresher(ctx)
+
+	// (F) Worker: enqueue the nightly prereq gate prefetch round.
+	if runWorker && a.Services.PrereqGateNightly != nil {
+		go a.Services.PrereqGateNightly.Run(ctx)
+	}
 	return nil
 }
 
@@ -203,6 +227,26 @@ func (a *App) Close() {
 	}
 }
 
+// backfillDocVariantAssignments pins users exposed under the current doc policy version before
+// assignments were persisted to the arm their last exposure recorded. It runs with the migrations,
+// before any request can hash a first assignment; reruns are no-ops.
+func backfillDocVariantAssignments(log *logger.Logger, reposet Repos) error {
+	if reposet.DocGen.DocVariantAssignment == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	policy := docgen.DocPolicy(ctx)
+	n, err := reposet.DocGen.DocVariantAssignment.BackfillFromExposures(dbctx.Context{Ctx: ctx}, policy.PolicyKey, policy.PolicyVersion)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Info("Backfilled doc variant assignments from exposures", "assignments", n, "policy_key", policy.PolicyKey, "policy_version", policy.PolicyVersion)
+	}
+	return nil
+}
+
 func (a *App) seedTeachingPatternsOnStartup(ctx context.Context) {
 	if a == nil || a.DB == nil || a.Log == nil || a.Repos.Activities.TeachingPattern == nil || a.Clients.OpenaiClient == nil {
 		return
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/localmedia"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
@@ -14,7 +15,9 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
 	"github.com/yungbote/neurobridge-backend/internal/platform/sendgrid"
+	"github.com/yungbote/neurobridge-backend/internal/platform/tts"
 	"github.com/yungbote/neurobridge-backend/internal/platform/twilio"
+	"github.com/yungbote/neurobridge-backend/internal/platform/websearch"
 	"github.com/yungbote/neurobridge-backend/internal/realtime/bus"
 	"github.com/yungbote/neurobridge-backend/internal/temporalx"
 
@@ -33,6 +36,12 @@ type Clients struct {
 	OpenaiCaption      openai.Caption
 	StructureExtractAI openai.Client
 
+	// Text-to-speech (doc narration)
+	TTS tts.Provider
+
+	// Web search (chat web enrichment; nil when not configured)
+	WebSearch websearch.Provider
+
 	// Pinecone
 	PineconeClient      pinecone.Client
 	PineconeVectorStore pinecone.VectorStore
@@ -44,6 +53,9 @@ type Clients struct {
 	GcpVideo    gcp.Video
 	GcpVision   gcp.Vision
 
+	// Field-level encryption for user-authored doc content (nil when no keys are configured)
+	FieldCrypt *fieldcrypt.Envelope
+
 	// Twilio
 	TwilioClient twilio.Client
 
@@ -117,6 +129,22 @@ func wireClients(log *logger.Logger, cfg Config) (Clients, error) {
 	}
 	out.OpenaiCaption = cap
 
+	// ---------------- Text-to-speech ----------------
+	narrator, err := tts.New(log)
+	if err != nil {
+		out.Close()
+		return Clients{}, fmt.Errorf("init tts provider: %w", err)
+	}
+	out.TTS = narrator
+
+	// ---------------- Web search ----------------
+	webSearch, err := websearch.New(log)
+	if err != nil {
+		out.Close()
+		return Clients{}, fmt.Errorf("init web search provider: %w", err)
+	}
+	out.WebSearch = webSearch
+
 	// ---------------- Vector Store Provider ----------------
 	pineconeClient, vectorStore, err := resolveVectorStoreProvider(log, cfg)
 	if err != nil {
@@ -155,6 +183,14 @@ func wireClients(log *logger.Logger, cfg Config) (Clients, error) {
 	}
 	out.GcpVideo = video
 
+	// ---------------- Field Encryption (KMS, or local keys in dev) ----------------
+	fc, err := resolveFieldCrypt(log)
+	if err != nil {
+		out.Close()
+		return Clients{}, fmt.Errorf("init field encryption: %w", err)
+	}
+	out.FieldCrypt = fc
+
 	// ---------------- Local Media Tools ----------------
 	out.LMTools = localmedia.New(log)
 
@@ -193,6 +229,25 @@ func wireClients(log *logger.Logger, cfg Config) (Clients, error) {
 	return out, nil
 }
 
+func resolveFieldCrypt(log *logger.Logger) (*fieldcrypt.Envelope, error) {
+	if keyName := strings.TrimSpace(os.Getenv("FIELD_CRYPT_KMS_KEY")); keyName != "" {
+		kms, err := gcp.NewKMSKeyWrapper(log, keyName)
+		if err != nil {
+			return nil, err
+		}
+		return fieldcrypt.New(kms, fieldcrypt.OptionsFromEnv()), nil
+	}
+	local, err := fieldcrypt.LocalKeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if local == nil {
+		log.Warn("FIELD_CRYPT_KMS_KEY and FIELD_CRYPT_LOCAL_KEYS not set; user notes stored unencrypted")
+		return nil, nil
+	}
+	return fieldcrypt.New(local, fieldcrypt.OptionsFromEnv()), nil
+}
+
 func (c *Clients) Close() {
 	if c == nil {
 		return
@@ -236,6 +291,8 @@ func (c *Clients) Close() {
 	c.OpenaiClient = nil
 	c.OpenaiCaption = nil
 	c.StructureExtractAI = nil
+	c.TTS = nil
+	c.WebSearch = nil
 
 	c.LMTools = nil
 }
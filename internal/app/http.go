@@ -5,9 +5,14 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/http"
 	httpH "github.com/yungbote/neurobridge-backend/internal/http/handlers"
 	httpMW "github.com/yungbote/neurobridge-backend/internal/http/middleware"
+	chatsteps "github.com/yungbote/neurobridge-backend/internal/modules/chat/steps"
 	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/portability"
 	librarymod "github.com/yungbote/neurobridge-backend/internal/modules/library"
 	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/realtime"
 	"gorm.io/gorm"
@@ -26,12 +31,20 @@ type Handlers struct {
 	Realtime *httpH.RealtimeHandler
 	Material *httpH.MaterialHandler
 	Chat     *httpH.ChatHandler
+	ChatPlan *httpH.ChatContextPlanHandler
 	Library  *httpH.LibraryHandler
 	Path     *httpH.PathHandler
 	Activity *httpH.ActivityHandler
 	Event    *httpH.EventHandler
 	Gaze     *httpH.GazeHandler
 	Job      *httpH.JobHandler
+
+	Notification      *httpH.NotificationHandler
+	LearningState     *httpH.LearningStateHandler
+	DocVariantOutcome *httpH.DocVariantOutcomeHandler
+	Diagnostics       *httpH.DiagnosticsHandler
+	Trace             *httpH.TraceHandler
+	ContextBudget     *httpH.ContextBudgetHandler
 }
 
 func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services, repos Repos, clients Clients, sseHub *realtime.SSEHub) Handlers {
@@ -43,6 +56,7 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 		Avatar:       services.Avatar,
 		Path:         repos.Paths.Path,
 		PathNodes:    repos.Paths.PathNode,
+		PathFocus:    repos.Paths.PathFocus,
 		NodeDocs:     repos.DocGen.LearningNodeDoc,
 		Concepts:     repos.Concepts.Concept,
 		Chunks:       repos.Materials.MaterialChunk,
@@ -85,9 +99,11 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 		Log:      log,
 		Workflow: services.Workflow,
 		SSEHub:   sseHub,
+		JobSvc:   services.JobService,
 		Bucket:   clients.GcpBucket,
 		Repos: httpH.MaterialHandlerRepoDeps{
 			MaterialFiles:    repos.Materials.MaterialFile,
+			Chunks:           repos.Materials.MaterialChunk,
 			MaterialAssets:   repos.Materials.MaterialAsset,
 			UserLibraryIndex: repos.Library.UserLibraryIndex,
 		},
@@ -112,14 +128,23 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 		Path: httpH.PathHandlerPathRepos{
 			Path:             repos.Paths.Path,
 			PathNodes:        repos.Paths.PathNode,
+			Shares:           repos.Paths.PathShare,
+			Focus:            repos.Paths.PathFocus,
 			PathNodeActivity: repos.Paths.PathNodeActivity,
+			Activity:         repos.Paths.PathActivity,
 		},
 		Content: httpH.PathHandlerContentRepos{
 			Activities:         repos.Activities.Activity,
+			ActivityVariants:   repos.Activities.ActivityVariant,
 			NodeDocs:           repos.DocGen.LearningNodeDoc,
+			DocSearch:          repos.DocGen.NodeDocSearch,
 			DocRevisions:       repos.DocGen.LearningNodeDocRevision,
 			DocVariants:        repos.DocGen.LearningNodeDocVariant,
 			DocVariantExposure: repos.DocGen.DocVariantExposure,
+			DocAssignments:     repos.DocGen.DocVariantAssignment,
+			DocGenRuns:         repos.DocGen.DocGenerationRun,
+			NodeFigures:        repos.DocGen.LearningNodeFigure,
+			NodeAudio:          repos.DocGen.LearningNodeAudio,
 			Chunks:             repos.Materials.MaterialChunk,
 			MaterialSets:       repos.Materials.MaterialSet,
 			MaterialFiles:      repos.Materials.MaterialFile,
@@ -133,15 +158,23 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 			ConceptState: repos.Learning.UserConceptState,
 			PolicyEval:   repos.Runtime.PolicyEvalSnapshot,
 			PrereqGates:  repos.Learning.PrereqGateDecision,
+			NodeRuns:     repos.Paths.NodeRun,
+			ActivityRuns: repos.Paths.ActivityRun,
+			Profiles:     repos.Learning.LearningProfile,
 		},
 		Services: httpH.PathHandlerServices{
 			Jobs:     repos.Jobs.JobRun,
 			JobSvc:   services.JobService,
 			Events:   services.Events,
+			DocCache: services.DocServingCache,
+			Outlines: services.PathOutlines,
 			Avatar:   services.Avatar,
 			Learning: learningUC,
 			Bucket:   clients.GcpBucket,
+			// Sealed user notes are opened on serve.
+			FieldCrypt: clients.FieldCrypt,
 		},
+		AssetViewBasePath: httpH.AssetViewBasePathFromEnv(),
 	})
 
 	activityHandler := httpH.NewActivityHandlerWithDeps(httpH.ActivityHandlerDeps{
@@ -163,6 +196,58 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 		},
 	})
 
+	var embed portability.EmbedFunc
+	if clients.OpenaiClient != nil {
+		embed = clients.OpenaiClient.Embed
+	}
+	learningStateHandler := httpH.NewLearningStateHandlerWithDeps(httpH.LearningStateHandlerDeps{
+		Log: log,
+		Portability: portability.New(portability.Deps{
+			DB:             db,
+			Log:            log,
+			Embed:          embed,
+			Concepts:       repos.Concepts.Concept,
+			ConceptStates:  repos.Learning.UserConceptState,
+			ConceptModels:  repos.Learning.UserConceptModel,
+			Misconceptions: repos.Learning.UserMisconception,
+			Evidence:       repos.Learning.UserConceptEvidence,
+			CompletedUnits: repos.Activities.UserCompletedUnit,
+			NodeRuns:       repos.Paths.NodeRun,
+			Paths:          repos.Paths.Path,
+			PathNodes:      repos.Paths.PathNode,
+		}),
+	})
+
+	chatPlanHandler := httpH.NewChatContextPlanHandlerWithDeps(httpH.ChatContextPlanHandlerDeps{
+		Log: log,
+		Preview: &chatsteps.ContextPlanPreviewer{
+			Deps: chatsteps.ContextPlanDeps{
+				DB:        db,
+				AI:        clients.OpenaiClient,
+				Vec:       clients.PineconeVectorStore,
+				Docs:      repos.Chat.ChatDoc,
+				Messages:  repos.Chat.ChatMessage,
+				Summaries: repos.Chat.ChatSummaryNode,
+				Path:      repos.Paths.Path,
+				PathNodes: repos.Paths.PathNode,
+				NodeDocs:  repos.DocGen.LearningNodeDoc,
+				Concepts:  repos.Concepts.Concept,
+				Edges:     repos.Concepts.ConceptEdge,
+				Evidence:  repos.Concepts.ConceptEvidence,
+				Mastery:   repos.Learning.UserConceptState,
+				Models:    repos.Learning.UserConceptModel,
+				Miscon:    repos.Learning.UserMisconception,
+				Sessions:  repos.Users.UserSessionState,
+				Outlines:  services.PathOutlines,
+				Web:       clients.WebSearch,
+				Focus:     repos.Paths.PathFocus,
+				Profiles:  repos.Learning.LearningProfile,
+				Log:       log,
+			},
+			Threads: repos.Chat.ChatThread,
+		},
+	})
+
 	return Handlers{
 		Health:   httpH.NewHealthHandler(),
 		Auth:     httpH.NewAuthHandler(services.Auth),
@@ -172,12 +257,23 @@ func wireHandlers(log *logger.Logger, db *gorm.DB, cfg Config, services Services
 		Realtime: httpH.NewRealtimeHandler(log, sseHub),
 		Material: materialHandler,
 		Chat:     httpH.NewChatHandler(services.Chat),
+		ChatPlan: chatPlanHandler,
 		Library:  libraryHandler,
 		Path:     pathHandler,
 		Activity: activityHandler,
 		Event:    eventHandler,
 		Gaze:     httpH.NewGazeHandler(services.Gaze),
 		Job:      httpH.NewJobHandler(services.JobService),
+
+		Notification:  httpH.NewNotificationHandler(services.Notification),
+		LearningState: learningStateHandler,
+		DocVariantOutcome: httpH.NewDocVariantOutcomeHandlerWithDeps(httpH.DocVariantOutcomeHandlerDeps{
+			Labels: docgen.DocVariantOutcomeLabels(),
+		}),
+		Diagnostics: httpH.NewDiagnosticsHandler(dbstats.Default(), logger.DefaultThrottle(), featureflag.Default(), services.DocGenScheduler, repos.DocGen.DocConsistencyAuditRun),
+		Trace:       httpH.NewTraceHandler(services.TraceTimeline),
+
+		ContextBudget: httpH.NewContextBudgetHandler(repos.Chat.ContextBudget),
 	}
 }
 
@@ -191,6 +287,7 @@ func wireRouter(log *logger.Logger, cfg Config, handlers Handlers, middleware Mi
 		RealtimeHandler: handlers.Realtime,
 		MaterialHandler: handlers.Material,
 		ChatHandler:     handlers.Chat,
+		ChatPlanHandler: handlers.ChatPlan,
 		LibraryHandler:  handlers.Library,
 		PathHandler:     handlers.Path,
 		ActivityHandler: handlers.Activity,
@@ -198,6 +295,13 @@ func wireRouter(log *logger.Logger, cfg Config, handlers Handlers, middleware Mi
 		EventHandler:    handlers.Event,
 		GazeHandler:     handlers.Gaze,
 		JobHandler:      handlers.Job,
+
+		NotificationHandler:      handlers.Notification,
+		LearningStateHandler:     handlers.LearningState,
+		DocVariantOutcomeHandler: handlers.DocVariantOutcome,
+		DiagnosticsHandler:       handlers.Diagnostics,
+		TraceHandler:             handlers.Trace,
+		ContextBudgetHandler:     handlers.ContextBudget,
 	})
 	if log != nil {
 		r.Use(httpMW.RequestLogger(log))
@@ -4,6 +4,7 @@ import (
 	agg "github.com/yungbote/neurobridge-backend/internal/data/aggregates"
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	"github.com/yungbote/neurobridge-backend/internal/observability"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"gorm.io/gorm"
@@ -22,6 +23,7 @@ type UserRepos struct {
 	UserSessionState         repos.UserSessionStateRepo
 	UserGazeEvent            repos.UserGazeEventRepo
 	UserGazeBlockStat        repos.UserGazeBlockStatRepo
+	UserNotification         repos.UserNotificationRepo
 }
 
 type EventRepos struct {
@@ -54,6 +56,7 @@ type LearningRepos struct {
 	InterventionPlan          repos.InterventionPlanRepo
 	ConceptReadinessSnapshot  repos.ConceptReadinessSnapshotRepo
 	PrereqGateDecision        repos.PrereqGateDecisionRepo
+	LearningProfile           repos.LearningProfileRepo
 }
 
 type MaterialRepos struct {
@@ -72,6 +75,7 @@ type MaterialRepos struct {
 
 type ConceptRepos struct {
 	Concept                 repos.ConceptRepo
+	ConceptDocEmbedding     repos.ConceptDocEmbeddingRepo
 	ConceptRepresentation   repos.ConceptRepresentationRepo
 	ConceptMappingOverride  repos.ConceptMappingOverrideRepo
 	ConceptCluster          repos.ConceptClusterRepo
@@ -103,7 +107,10 @@ type PathRepos struct {
 
 	Path               repos.PathRepo
 	PathNode           repos.PathNodeRepo
+	PathShare          repos.PathShareRepo
+	PathFocus          repos.PathFocusRepo
 	PathNodeActivity   repos.PathNodeActivityRepo
+	PathActivity       repos.PathActivityRepo
 	PathStructuralUnit repos.PathStructuralUnitRepo
 	PathRun            repos.PathRunRepo
 	NodeRun            repos.NodeRunRepo
@@ -136,7 +143,11 @@ type DocGenRepos struct {
 
 	LearningNodeDoc          repos.LearningNodeDocRepo
 	LearningNodeDocRevision  repos.LearningNodeDocRevisionRepo
+	NodeDocSearch            repos.NodeDocSearchRepo
 	LearningNodeFigure       repos.LearningNodeFigureRepo
+	FigureBlob               repos.FigureBlobRepo
+	NodeAssetRef             repos.NodeAssetRefRepo
+	LearningNodeAudio        repos.LearningNodeAudioRepo
 	LearningNodeVideo        repos.LearningNodeVideoRepo
 	DocGenerationRun         repos.LearningDocGenerationRunRepo
 	LearningNodeDocBlueprint repos.LearningNodeDocBlueprintRepo
@@ -145,9 +156,11 @@ type DocGenRepos struct {
 	DocRetrievalPack         repos.DocRetrievalPackRepo
 	DocGenerationTrace       repos.DocGenerationTraceRepo
 	DocConstraintReport      repos.DocConstraintReportRepo
+	DocConsistencyAuditRun   repos.DocConsistencyAuditRunRepo
 	DocProbe                 repos.DocProbeRepo
 	DocProbeOutcome          repos.DocProbeOutcomeRepo
 	DocVariantExposure       repos.DocVariantExposureRepo
+	DocVariantAssignment     repos.DocVariantAssignmentRepo
 	DocVariantOutcome        repos.DocVariantOutcomeRepo
 }
 
@@ -155,9 +168,10 @@ type JobRepos struct {
 	// Aggregate contract slots.
 	Saga domainagg.SagaAggregate
 
-	JobRun     repos.JobRunRepo
-	SagaRun    repos.SagaRunRepo
-	SagaAction repos.SagaActionRepo
+	JobRun       repos.JobRunRepo
+	SagaRun      repos.SagaRunRepo
+	SagaAction   repos.SagaActionRepo
+	JobScheduler repos.JobSchedulerRepo
 }
 
 type ChatRepos struct {
@@ -174,6 +188,8 @@ type ChatRepos struct {
 	ChatClaim       repos.ChatClaimRepo
 	ChatDoc         repos.ChatDocRepo
 	ChatTurn        repos.ChatTurnRepo
+	ChatToolExec    repos.ChatToolExecutionRepo
+	ContextBudget   repos.ContextBudgetStatRepo
 }
 
 type Repos struct {
@@ -218,6 +234,7 @@ func wireUserRepos(db *gorm.DB, log *logger.Logger) UserRepos {
 		UserSessionState:         repos.NewUserSessionStateRepo(db, log),
 		UserGazeEvent:            repos.NewUserGazeEventRepo(db, log),
 		UserGazeBlockStat:        repos.NewUserGazeBlockStatRepo(db, log),
+		UserNotification:         repos.NewUserNotificationRepo(db, log),
 	}
 }
 
@@ -270,6 +287,7 @@ func wireLearningRepos(db *gorm.DB, log *logger.Logger) LearningRepos {
 		InterventionPlan:          repos.NewInterventionPlanRepo(db, log),
 		ConceptReadinessSnapshot:  conceptReadinessRepo,
 		PrereqGateDecision:        prereqGateRepo,
+		LearningProfile:           repos.NewLearningProfileRepo(db, log),
 	}
 }
 
@@ -292,6 +310,7 @@ func wireMaterialRepos(db *gorm.DB, log *logger.Logger) MaterialRepos {
 func wireConceptRepos(db *gorm.DB, log *logger.Logger) ConceptRepos {
 	return ConceptRepos{
 		Concept:                 repos.NewConceptRepo(db, log),
+		ConceptDocEmbedding:     repos.NewConceptDocEmbeddingRepo(db, log),
 		ConceptRepresentation:   repos.NewConceptRepresentationRepo(db, log),
 		ConceptMappingOverride:  repos.NewConceptMappingOverrideRepo(db, log),
 		ConceptCluster:          repos.NewConceptClusterRepo(db, log),
@@ -337,7 +356,10 @@ func wirePathRepos(db *gorm.DB, log *logger.Logger) PathRepos {
 		}),
 		Path:               repos.NewPathRepo(db, log),
 		PathNode:           repos.NewPathNodeRepo(db, log),
+		PathShare:          repos.NewPathShareRepo(db, log),
+		PathFocus:          repos.NewPathFocusRepo(db, log),
 		PathNodeActivity:   repos.NewPathNodeActivityRepo(db, log),
+		PathActivity:       repos.NewPathActivityRepo(db, log),
 		PathStructuralUnit: repos.NewPathStructuralUnitRepo(db, log),
 		PathRun:            pathRunRepo,
 		NodeRun:            nodeRunRepo,
@@ -403,10 +425,15 @@ func wireDocGenRepos(db *gorm.DB, log *logger.Logger) DocGenRepos {
 			GenRuns:         docGenerationRunRepo,
 			GenTrace:        docGenerationTraceRepo,
 			Constraints:     docConstraintReportRepo,
+			OutcomeLabels:   docgen.DocVariantOutcomeLabels(),
 		}),
 		LearningNodeDoc:          nodeDocRepo,
 		LearningNodeDocRevision:  nodeDocRevisionRepo,
+		NodeDocSearch:            repos.NewNodeDocSearchRepo(db, log),
 		LearningNodeFigure:       repos.NewLearningNodeFigureRepo(db, log),
+		FigureBlob:               repos.NewFigureBlobRepo(db, log),
+		NodeAssetRef:             repos.NewNodeAssetRefRepo(db, log),
+		LearningNodeAudio:        repos.NewLearningNodeAudioRepo(db, log),
 		LearningNodeVideo:        repos.NewLearningNodeVideoRepo(db, log),
 		DocGenerationRun:         docGenerationRunRepo,
 		LearningNodeDocBlueprint: repos.NewLearningNodeDocBlueprintRepo(db, log),
@@ -415,9 +442,11 @@ func wireDocGenRepos(db *gorm.DB, log *logger.Logger) DocGenRepos {
 		DocRetrievalPack:         repos.NewDocRetrievalPackRepo(db, log),
 		DocGenerationTrace:       docGenerationTraceRepo,
 		DocConstraintReport:      docConstraintReportRepo,
+		DocConsistencyAuditRun:   repos.NewDocConsistencyAuditRunRepo(db, log),
 		DocProbe:                 repos.NewDocProbeRepo(db, log),
 		DocProbeOutcome:          repos.NewDocProbeOutcomeRepo(db, log),
 		DocVariantExposure:       docVariantExposureRepo,
+		DocVariantAssignment:     repos.NewDocVariantAssignmentRepo(db, log),
 		DocVariantOutcome:        docVariantOutcomeRepo,
 	}
 }
@@ -433,9 +462,10 @@ func wireJobRepos(db *gorm.DB, log *logger.Logger) JobRepos {
 			Runs:    sagaRunRepo,
 			Actions: sagaActionRepo,
 		}),
-		JobRun:     repos.NewJobRunRepo(db, log),
-		SagaRun:    sagaRunRepo,
-		SagaAction: sagaActionRepo,
+		JobRun:       repos.NewJobRunRepo(db, log),
+		SagaRun:      sagaRunRepo,
+		SagaAction:   sagaActionRepo,
+		JobScheduler: repos.NewJobSchedulerRepo(db, log),
 	}
 }
 
@@ -451,6 +481,7 @@ func wireChatRepos(db *gorm.DB, log *logger.Logger) ChatRepos {
 	claimRepo := repos.NewChatClaimRepo(db, log)
 	docRepo := repos.NewChatDocRepo(db, log)
 	turnRepo := repos.NewChatTurnRepo(db, log)
+	toolExecRepo := repos.NewChatToolExecutionRepo(db, log)
 
 	return ChatRepos{
 		Thread: agg.NewThreadAggregate(agg.ThreadAggregateDeps{
@@ -476,6 +507,8 @@ func wireChatRepos(db *gorm.DB, log *logger.Logger) ChatRepos {
 		ChatClaim:       claimRepo,
 		ChatDoc:         docRepo,
 		ChatTurn:        turnRepo,
+		ChatToolExec:    toolExecRepo,
+		ContextBudget:   repos.NewContextBudgetStatRepo(db, log),
 	}
 }
 
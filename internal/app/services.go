@@ -20,27 +20,37 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/concept_cluster_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/concept_graph_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/concept_graph_patch_build"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/concept_recanonicalize"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/coverage_coherence_audit"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/doc_consistency_audit"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/doc_probe_select"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/doc_variant_eval"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/embed_chunks"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/field_crypt_rotate"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/file_signature_build"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/generated_object_sweep"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/graph_version_rollback"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/ingest_chunks"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/learning_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/learning_build_progressive"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/library_taxonomy_refine"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/library_taxonomy_route"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/material_file_cleanup"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/material_file_relink"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/material_kg_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/material_set_summarize"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/material_signal_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_avatar_render"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_description_sync"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_edit"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_edit_apply"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_narrate"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_patch"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_prefetch"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_progressive_build"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_regenerate"
+	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_doc_see_also_refresh"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_figures_plan_build"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_figures_render"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/node_videos_plan_build"
@@ -75,6 +85,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/waitpoint_stage"
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/web_resources_seed"
 	jobruntime "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	ingestion "github.com/yungbote/neurobridge-backend/internal/modules/learning/ingestion/pipeline"
 	"github.com/yungbote/neurobridge-backend/internal/observability"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
@@ -101,15 +112,22 @@ type Services struct {
 	Events services.EventService
 	// Runtime per-session state (active path/node/etc)
 	SessionState services.SessionStateService
+	// In-process doc serving caches, warmed on session start
+	DocServingCache  services.DocServingCache
+	SessionPrewarmer services.SessionPrewarmer
+	// Path outline read model shared by chat context and path handlers
+	PathOutlines services.PathOutlineService
 	// Gaze ingestion + aggregation
 	Gaze services.GazeService
 
 	// Jobs + notifications
-	JobNotifier  services.JobNotifier
-	JobService   services.JobService
-	Workflow     services.WorkflowService
-	ChatNotifier services.ChatNotifier
-	Chat         services.ChatService
+	JobNotifier   services.JobNotifier
+	Notification  services.NotificationService
+	JobService    services.JobService
+	Workflow      services.WorkflowService
+	ChatNotifier  services.ChatNotifier
+	Chat          services.ChatService
+	TraceTimeline services.TraceTimelineService
 
 	// Orchestrator
 	ContentExtractor ingestion.ContentExtractionService
@@ -117,6 +135,11 @@ type Services struct {
 	// Job infra
 	JobRegistry    *jobruntime.Registry
 	TemporalWorker *temporalworker.Runner
+	// Fair share of doc generation slots across users
+	DocGenScheduler services.DocGenScheduler
+
+	// Nightly prereq gate prefetch enqueuer (started on workers)
+	PrereqGateNightly services.PrereqGateNightly
 
 	// Keep bus here for convenience/compat
 	SSEBus bus.Bus
@@ -154,7 +177,10 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 	userService := services.NewUserService(db, log, repos.Auth.User, repos.Users.UserPersonalizationPrefs, avatarService)
 	materialService := services.NewMaterialService(db, log, repos.Materials.MaterialSet, repos.Materials.MaterialFile, fileService)
 	eventService := services.NewEventService(db, log, repos.Events.UserEvent)
-	sessionStateService := services.NewSessionStateService(db, log, repos.Users.UserSessionState)
+	docServingCache := services.NewDocServingCache(log, repos.Concepts.Concept, repos.Runtime.PolicyEvalSnapshot, repos.DocGen.LearningNodeDoc)
+	pathOutlines := services.NewPathOutlineService(log, repos.Paths.Path, repos.Paths.PathNode)
+	sessionPrewarmer := services.NewSessionPrewarmer(log, docServingCache, repos.Paths.Path, repos.Paths.PathNode, []string{docgen.DocVariantPolicyKey()})
+	sessionStateService := services.NewSessionStateService(db, log, repos.Users.UserSessionState, sessionPrewarmer)
 	gazeService := services.NewGazeService(log, repos.Users.UserGazeEvent, repos.Users.UserGazeBlockStat, repos.Users.UserPersonalizationPrefs)
 
 	runServer := strings.EqualFold(strings.TrimSpace(os.Getenv("RUN_SERVER")), "true")
@@ -172,10 +198,12 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		emitter = &services.RedisEmitter{Bus: clients.SSEBus}
 	}
 
-	jobNotifier := services.NewJobNotifier(emitter)
+	notificationService := services.NewNotificationService(log, repos.Users.UserNotification, emitter)
+	jobNotifier := services.WithJobNotifications(services.NewJobNotifier(emitter), log, notificationService)
 	tc := clients.Temporal
 	tcfg := temporalx.LoadConfig()
 	jobService := services.NewJobService(db, log, repos.Jobs.JobRun, jobNotifier, tc, tcfg.TaskQueue)
+	traceTimeline := services.NewTraceTimelineService(db, log)
 
 	// Shared bootstrap service (used by workflows + learning pipelines).
 	bootstrapSvc := services.NewLearningBuildBootstrapService(db, log, repos.Paths.Path, repos.Library.UserLibraryIndex)
@@ -222,6 +250,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.DocGen.LearningNodeDoc,
 		repos.Concepts.Concept,
 		repos.Concepts.ConceptEdge,
+		repos.Concepts.ConceptEvidence,
 		repos.Learning.UserConceptState,
 		repos.Learning.UserConceptModel,
 		repos.Learning.UserMisconception,
@@ -229,6 +258,15 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.Jobs.JobRun,
 		jobService,
 		chatNotifier,
+		repos.Chat.ChatToolExec,
+		repos.Materials.MaterialChunk,
+		repos.Materials.DrillInstance,
+		repos.DocGen.DocGenerationRun,
+		pathOutlines,
+		clients.WebSearch,
+		repos.Paths.PathFocus,
+		services.NewContextBudgetSampler(log, repos.Chat.ContextBudget, pathOutlines),
+		repos.Learning.LearningProfile,
 	)
 	if err := jobRegistry.Register(chatRespond); err != nil {
 		return Services{}, err
@@ -437,16 +475,21 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
-	conceptGraph := concept_graph_build.New(db, log, repos.Materials.MaterialFile, repos.Materials.MaterialFileSignature, repos.Materials.MaterialChunk, repos.Paths.Path, repos.Concepts.Concept, repos.Concepts.ConceptRepresentation, repos.Concepts.ConceptMappingOverride, repos.Concepts.ConceptEvidence, repos.Concepts.ConceptEdge, clients.Neo4j, clients.OpenaiClient, clients.PineconeVectorStore, sagaSvc, bootstrapSvc, repos.Materials.LearningArtifact, repos.Concepts.GraphVersion, repos.Concepts.StructuralDecisionTrace)
+	conceptGraph := concept_graph_build.New(db, log, repos.Materials.MaterialFile, repos.Materials.MaterialFileSignature, repos.Materials.MaterialChunk, repos.Paths.Path, repos.Concepts.Concept, repos.Concepts.ConceptRepresentation, repos.Concepts.ConceptMappingOverride, repos.Concepts.ConceptEvidence, repos.Concepts.ConceptEdge, clients.Neo4j, clients.OpenaiClient, clients.PineconeVectorStore, sagaSvc, bootstrapSvc, repos.Materials.LearningArtifact, repos.Concepts.GraphVersion, repos.Concepts.StructuralDecisionTrace, repos.Concepts.ConceptDocEmbedding)
 	if err := jobRegistry.Register(conceptGraph); err != nil {
 		return Services{}, err
 	}
 
-	conceptGraphPatch := concept_graph_patch_build.New(db, log, repos.Materials.MaterialFile, repos.Materials.MaterialFileSignature, repos.Materials.MaterialChunk, repos.Paths.Path, repos.Concepts.Concept, repos.Concepts.ConceptRepresentation, repos.Concepts.ConceptMappingOverride, repos.Concepts.ConceptEvidence, repos.Concepts.ConceptEdge, clients.Neo4j, clients.OpenaiClient, clients.PineconeVectorStore, sagaSvc, bootstrapSvc, repos.Materials.LearningArtifact, repos.Concepts.GraphVersion, repos.Concepts.StructuralDecisionTrace)
+	conceptGraphPatch := concept_graph_patch_build.New(db, log, repos.Materials.MaterialFile, repos.Materials.MaterialFileSignature, repos.Materials.MaterialChunk, repos.Paths.Path, repos.Concepts.Concept, repos.Concepts.ConceptRepresentation, repos.Concepts.ConceptMappingOverride, repos.Concepts.ConceptEvidence, repos.Concepts.ConceptEdge, clients.Neo4j, clients.OpenaiClient, clients.PineconeVectorStore, sagaSvc, bootstrapSvc, repos.Materials.LearningArtifact, repos.Concepts.GraphVersion, repos.Concepts.StructuralDecisionTrace, repos.Paths.PathNode, repos.DocGen.LearningNodeDoc)
 	if err := jobRegistry.Register(conceptGraphPatch); err != nil {
 		return Services{}, err
 	}
 
+	conceptRecanonicalize := concept_recanonicalize.New(db, log, repos.Concepts.Concept, repos.Concepts.ConceptRepresentation, repos.Concepts.ConceptMappingOverride, repos.Concepts.ConceptDocEmbedding, clients.OpenaiClient, clients.PineconeVectorStore)
+	if err := jobRegistry.Register(conceptRecanonicalize); err != nil {
+		return Services{}, err
+	}
+
 	conceptBridge := concept_bridge_build.New(db, log, repos.Concepts.Concept, repos.Concepts.ConceptEdge, clients.OpenaiClient, clients.PineconeVectorStore, bootstrapSvc)
 	if err := jobRegistry.Register(conceptBridge); err != nil {
 		return Services{}, err
@@ -691,11 +734,13 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.Paths.Path,
 		repos.Paths.PathNode,
 		repos.DocGen.LearningNodeFigure,
+		repos.DocGen.FigureBlob,
 		repos.DocGen.DocGenerationRun,
 		repos.Materials.MaterialFile,
 		repos.Materials.MaterialChunk,
 		clients.OpenaiClient,
 		clients.PineconeVectorStore,
+		clients.GcpBucket,
 		bootstrapSvc,
 	)
 	if err := jobRegistry.Register(nodeFiguresPlan); err != nil {
@@ -708,6 +753,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.Paths.Path,
 		repos.Paths.PathNode,
 		repos.DocGen.LearningNodeFigure,
+		repos.DocGen.FigureBlob,
 		repos.Materials.Asset,
 		repos.DocGen.DocGenerationRun,
 		clients.OpenaiClient,
@@ -813,6 +859,59 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
+	nodeDocRegenerate := node_doc_regenerate.New(
+		db,
+		log,
+		jobService,
+		repos.Paths.Path,
+		repos.Paths.PathNode,
+		repos.DocGen.LearningNodeDoc,
+		repos.DocGen.LearningNodeFigure,
+		repos.DocGen.LearningNodeVideo,
+		repos.DocGen.DocGenerationRun,
+		repos.DocGen.LearningNodeDocBlueprint,
+		repos.DocGen.DocRetrievalPack,
+		repos.DocGen.DocGenerationTrace,
+		repos.DocGen.DocConstraintReport,
+		repos.DocGen.LearningNodeDocRevision,
+		repos.Materials.MaterialFile,
+		repos.Materials.MaterialChunk,
+		repos.Users.UserProfileVector,
+		repos.Activities.TeachingPattern,
+		repos.Concepts.Concept,
+		repos.Learning.UserConceptState,
+		repos.Learning.UserConceptModel,
+		repos.Learning.UserMisconception,
+		clients.OpenaiClient,
+		clients.PineconeVectorStore,
+		clients.GcpBucket,
+		bootstrapSvc,
+	)
+	if err := jobRegistry.Register(nodeDocRegenerate); err != nil {
+		return Services{}, err
+	}
+
+	nodeDocSeeAlsoRefresh := node_doc_see_also_refresh.New(
+		db,
+		log,
+		repos.Paths.PathNode,
+		repos.DocGen.LearningNodeDoc,
+	)
+	if err := jobRegistry.Register(nodeDocSeeAlsoRefresh); err != nil {
+		return Services{}, err
+	}
+
+	nodeDescriptionSync := node_description_sync.New(
+		db,
+		log,
+		repos.Paths.PathNode,
+		repos.DocGen.LearningNodeDoc,
+		jobService,
+	)
+	if err := jobRegistry.Register(nodeDescriptionSync); err != nil {
+		return Services{}, err
+	}
+
 	nodeDocProgressive := node_doc_progressive_build.New(
 		db,
 		log,
@@ -871,6 +970,20 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
+	nodeDocNarrate := node_doc_narrate.New(
+		db,
+		log,
+		repos.Paths.Path,
+		repos.Paths.PathNode,
+		repos.DocGen.LearningNodeDoc,
+		repos.DocGen.LearningNodeAudio,
+		clients.GcpBucket,
+		clients.TTS,
+	)
+	if err := jobRegistry.Register(nodeDocNarrate); err != nil {
+		return Services{}, err
+	}
+
 	nodeDocEdit := node_doc_edit.New(
 		db,
 		log,
@@ -903,6 +1016,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.Paths.PathNode,
 		repos.DocGen.LearningNodeDoc,
 		repos.DocGen.LearningNodeDocRevision,
+		clients.FieldCrypt,
 	)
 	if err := jobRegistry.Register(nodeDocEditApply); err != nil {
 		return Services{}, err
@@ -999,13 +1113,18 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		repos.Runtime.PolicyEvalSnapshot,
 		jobService,
 		runtimeNotifier,
+		notificationService,
 		metrics,
 	)
 	if err := jobRegistry.Register(runtimeUpdate); err != nil {
 		return Services{}, err
 	}
+	if err := jobRegistry.Register(runtime_update.NewPrereqGatePrefetch(runtimeUpdate)); err != nil {
+		return Services{}, err
+	}
+	prereqGateNightly := services.NewPrereqGateNightly(log, repos.Paths.Path, jobService)
 
-	policyEval := policy_eval_refresh.New(db, log, repos.Runtime.DecisionTrace, repos.Runtime.PolicyEvalSnapshot)
+	policyEval := policy_eval_refresh.New(db, log, repos.Runtime.DecisionTrace, repos.Runtime.PolicyEvalSnapshot, repos.DocGen.DocVariantExposure, notificationService)
 	if err := jobRegistry.Register(policyEval); err != nil {
 		return Services{}, err
 	}
@@ -1025,6 +1144,11 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
+	fieldCryptRotate := field_crypt_rotate.New(db, log, repos.DocGen.LearningNodeDoc, clients.FieldCrypt)
+	if err := jobRegistry.Register(fieldCryptRotate); err != nil {
+		return Services{}, err
+	}
+
 	graphRollback := graph_version_rollback.New(db, log, repos.Concepts.GraphVersion, repos.Concepts.RollbackEvent, repos.Jobs.JobRun, jobService)
 	if err := jobRegistry.Register(graphRollback); err != nil {
 		return Services{}, err
@@ -1092,11 +1216,46 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
-	sagaCleanup := saga_cleanup.New(db, log, repos.Jobs.SagaRun, sagaSvc, clients.GcpBucket)
+	sagaCleanup := saga_cleanup.New(db, log, repos.Jobs.SagaRun, sagaSvc, clients.GcpBucket, repos.Users.UserNotification, repos.Chat.ContextBudget)
 	if err := jobRegistry.Register(sagaCleanup); err != nil {
 		return Services{}, err
 	}
 
+	materialFileCleanup := material_file_cleanup.New(
+		db,
+		log,
+		repos.Materials.MaterialFile,
+		repos.Materials.MaterialChunk,
+		repos.Concepts.ConceptEvidence,
+		repos.DocGen.LearningNodeDoc,
+		clients.PineconeVectorStore,
+	)
+	if err := jobRegistry.Register(materialFileCleanup); err != nil {
+		return Services{}, err
+	}
+
+	materialFileRelink := material_file_relink.New(
+		db,
+		log,
+		repos.Materials.MaterialFile,
+		repos.Materials.MaterialChunk,
+		repos.Concepts.ConceptEvidence,
+		repos.DocGen.LearningNodeDoc,
+	)
+	if err := jobRegistry.Register(materialFileRelink); err != nil {
+		return Services{}, err
+	}
+
+	generatedSweep := generated_object_sweep.New(db, log, jobService, clients.GcpBucket, repos.DocGen.NodeAssetRef)
+	if err := jobRegistry.Register(generatedSweep); err != nil {
+		return Services{}, err
+	}
+
+	docConsistencyAudit := doc_consistency_audit.New(db, log, repos.DocGen.DocConsistencyAuditRun)
+	if err := jobRegistry.Register(docConsistencyAudit); err != nil {
+		return Services{}, err
+	}
+
 	learningBuild := learning_build.New(
 		db,
 		log,
@@ -1127,6 +1286,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 			MappingOverrides: repos.Concepts.ConceptMappingOverride,
 			Evidence:         repos.Concepts.ConceptEvidence,
 			Edges:            repos.Concepts.ConceptEdge,
+			DocEmbeddings:    repos.Concepts.ConceptDocEmbedding,
 
 			Clusters: repos.Concepts.ConceptCluster,
 			Members:  repos.Concepts.ConceptClusterMember,
@@ -1150,6 +1310,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 			NodeDocRevisions:     repos.DocGen.LearningNodeDocRevision,
 			NodeDocBlueprints:    repos.DocGen.LearningNodeDocBlueprint,
 			NodeFigures:          repos.DocGen.LearningNodeFigure,
+			FigureBlobs:          repos.DocGen.FigureBlob,
 			NodeVideos:           repos.DocGen.LearningNodeVideo,
 			DocGenRuns:           repos.DocGen.DocGenerationRun,
 			DocRetrievalPacks:    repos.DocGen.DocRetrievalPack,
@@ -1208,6 +1369,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 			MappingOverrides: repos.Concepts.ConceptMappingOverride,
 			Evidence:         repos.Concepts.ConceptEvidence,
 			Edges:            repos.Concepts.ConceptEdge,
+			DocEmbeddings:    repos.Concepts.ConceptDocEmbedding,
 
 			Clusters: repos.Concepts.ConceptCluster,
 			Members:  repos.Concepts.ConceptClusterMember,
@@ -1231,6 +1393,7 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 			NodeDocRevisions:     repos.DocGen.LearningNodeDocRevision,
 			NodeDocBlueprints:    repos.DocGen.LearningNodeDocBlueprint,
 			NodeFigures:          repos.DocGen.LearningNodeFigure,
+			FigureBlobs:          repos.DocGen.FigureBlob,
 			NodeVideos:           repos.DocGen.LearningNodeVideo,
 			DocGenRuns:           repos.DocGen.DocGenerationRun,
 			DocRetrievalPacks:    repos.DocGen.DocRetrievalPack,
@@ -1288,9 +1451,11 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		return Services{}, err
 	}
 
+	docGenScheduler := services.NewDocGenScheduler(db, log, repos.Jobs.JobScheduler, services.DocGenSchedulerConfigFromEnv())
+
 	var temporalRunner *temporalworker.Runner
 	if runWorker {
-		w, err := temporalworker.NewRunner(log, clients.Temporal, db, repos.Jobs.JobRun, jobRegistry, jobNotifier, metrics)
+		w, err := temporalworker.NewRunner(log, clients.Temporal, db, repos.Jobs.JobRun, jobRegistry, jobNotifier, metrics, docGenScheduler)
 		if err != nil {
 			return Services{}, fmt.Errorf("init temporal worker: %w", err)
 		}
@@ -1318,15 +1483,23 @@ func wireServices(db *gorm.DB, log *logger.Logger, cfg Config, repos Repos, sseH
 		Material:         materialService,
 		Events:           eventService,
 		SessionState:     sessionStateService,
+		DocServingCache:  docServingCache,
+		PathOutlines:     pathOutlines,
+		SessionPrewarmer: sessionPrewarmer,
 		Gaze:             gazeService,
 		JobNotifier:      jobNotifier,
+		Notification:     notificationService,
 		JobService:       jobService,
 		Workflow:         workflow,
 		ChatNotifier:     chatNotifier,
 		Chat:             chatService,
+		TraceTimeline:    traceTimeline,
 		ContentExtractor: extractor,
 		JobRegistry:      jobRegistry,
 		TemporalWorker:   temporalRunner,
+		DocGenScheduler:  docGenScheduler,
 		SSEBus:           clients.SSEBus,
+
+		PrereqGateNightly: prereqGateNightly,
 	}, nil
 }
@@ -279,6 +279,10 @@ func (t *testBucketService) ListKeys(ctx context.Context, category gcp.BucketCat
 	return nil, nil
 }
 
+func (t *testBucketService) ListObjects(ctx context.Context, category gcp.BucketCategory, prefix, cursor string, limit int) (gcp.ObjectPage, error) {
+	return gcp.ObjectPage{}, nil
+}
+
 func (t *testBucketService) DeletePrefix(ctx context.Context, category gcp.BucketCategory, prefix string) error {
 	return nil
 }
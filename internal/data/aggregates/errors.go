@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
+	"github.com/yungbote/neurobridge-backend/internal/platform/errclass"
 	"gorm.io/gorm"
 )
 
@@ -52,6 +54,8 @@ func MapError(op string, err error) error {
 	switch {
 	case errors.Is(err, ErrValidation):
 		return domainagg.Wrap(domainagg.CodeValidation, op, err)
+	case errors.Is(err, repos.ErrDocTooLarge):
+		return domainagg.Wrap(domainagg.CodeValidation, op, err)
 	case errors.Is(err, ErrInvariant):
 		return domainagg.Wrap(domainagg.CodeInvariantViolation, op, err)
 	case errors.Is(err, ErrConflict):
@@ -65,25 +69,14 @@ func MapError(op string, err error) error {
 	}
 
 	var pgErr *pgconn.PgError
-	if errors.As(err, &pgErr) {
-		switch strings.TrimSpace(pgErr.Code) {
-		case "23505":
-			return domainagg.Wrap(domainagg.CodeConflict, op, err) // unique_violation
-		case "23503":
-			return domainagg.Wrap(domainagg.CodePreconditionFailed, op, err) // foreign_key_violation
-		case "40001", "40P01", "55P03":
-			return domainagg.Wrap(domainagg.CodeRetryable, op, err) // serialization/deadlock/lock_not_available
-		}
+	if errors.As(err, &pgErr) && strings.TrimSpace(pgErr.Code) == "23503" {
+		return domainagg.Wrap(domainagg.CodePreconditionFailed, op, err) // foreign_key_violation
 	}
 
-	msg := strings.ToLower(strings.TrimSpace(err.Error()))
-	switch {
-	case strings.Contains(msg, "duplicate key"), strings.Contains(msg, "already exists"):
+	switch errclass.Classify(err) {
+	case errclass.UniqueViolation:
 		return domainagg.Wrap(domainagg.CodeConflict, op, err)
-	case strings.Contains(msg, "deadlock"),
-		strings.Contains(msg, "serialization"),
-		strings.Contains(msg, "timeout"),
-		strings.Contains(msg, "temporar"):
+	case errclass.Timeout, errclass.RateLimited:
 		return domainagg.Wrap(domainagg.CodeRetryable, op, err)
 	default:
 		return domainagg.Wrap(domainagg.CodeInternal, op, err)
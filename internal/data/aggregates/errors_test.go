@@ -2,8 +2,11 @@ package aggregates
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
 	"gorm.io/gorm"
 )
@@ -29,6 +32,13 @@ func TestMapError_NotFound(t *testing.T) {
 	}
 }
 
+func TestMapError_DocTooLarge(t *testing.T) {
+	err := MapError("op", fmt.Errorf("write doc: %w", repos.ErrDocTooLarge))
+	if !domainagg.IsCode(err, domainagg.CodeValidation) {
+		t.Fatalf("expected validation code, got %q (%v)", domainagg.CodeOf(err), err)
+	}
+}
+
 func TestMapError_PassthroughAggregateError(t *testing.T) {
 	in := domainagg.NewError(domainagg.CodeRetryable, "op", "retry", errors.New("boom"))
 	out := MapError("other", in)
@@ -36,3 +46,21 @@ func TestMapError_PassthroughAggregateError(t *testing.T) {
 		t.Fatalf("expected passthrough aggregate error")
 	}
 }
+
+func TestMapError_ClassifiedInfraErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		want domainagg.ErrorCode
+	}{
+		{&pgconn.PgError{Code: "23505"}, domainagg.CodeConflict},
+		{&pgconn.PgError{Code: "23503"}, domainagg.CodePreconditionFailed},
+		{fmt.Errorf("tx: %w", &pgconn.PgError{Code: "40001"}), domainagg.CodeRetryable},
+		{errors.New("lock timeout"), domainagg.CodeRetryable},
+		{errors.New("boom"), domainagg.CodeInternal},
+	}
+	for _, tc := range cases {
+		if err := MapError("op", tc.err); !domainagg.IsCode(err, tc.want) {
+			t.Fatalf("MapError(%v) = %q, want %q", tc.err, domainagg.CodeOf(err), tc.want)
+		}
+	}
+}
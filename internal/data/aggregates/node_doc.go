@@ -2,6 +2,7 @@ package aggregates
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
@@ -19,6 +20,10 @@ type NodeDocAggregateDeps struct {
 	GenRuns         repos.LearningDocGenerationRunRepo
 	GenTrace        repos.DocGenerationTraceRepo
 	Constraints     repos.DocConstraintReportRepo
+
+	// OutcomeLabels is the accepted outcome label enumeration; defaults to
+	// domainagg.DefaultDocVariantOutcomeLabels when empty.
+	OutcomeLabels []domainagg.DocVariantOutcomeLabel
 }
 
 type nodeDocAggregate struct {
@@ -27,6 +32,9 @@ type nodeDocAggregate struct {
 
 func NewNodeDocAggregate(deps NodeDocAggregateDeps) domainagg.NodeDocAggregate {
 	deps.Base = deps.Base.withDefaults()
+	if len(deps.OutcomeLabels) == 0 {
+		deps.OutcomeLabels = domainagg.DefaultDocVariantOutcomeLabels()
+	}
 	return &nodeDocAggregate{deps: deps}
 }
 
@@ -38,6 +46,17 @@ func (a *nodeDocAggregate) CommitRevision(ctx context.Context, in domainagg.Comm
 	const op = "DocGen.NodeDoc.CommitRevision"
 	var out domainagg.CommitNodeDocRevisionResult
 	err := executeWrite(ctx, a.deps.Base, op, func(_ dbctx.Context) error {
+		if max := repos.MaxNodeDocBytes(); max > 0 && len(in.AfterJSON) > max {
+			if a.deps.Base.Log != nil {
+				a.deps.Base.Log.Warn("Rejecting oversized node doc revision",
+					"path_node_id", in.PathNodeID,
+					"doc_id", in.DocID,
+					"bytes", len(in.AfterJSON),
+					"max_bytes", max,
+				)
+			}
+			return domainagg.NewError(domainagg.CodeValidation, op, "doc_too_large", repos.ErrDocTooLarge)
+		}
 		return notImplemented(op)
 	})
 	return out, err
@@ -46,6 +65,9 @@ func (a *nodeDocAggregate) CommitRevision(ctx context.Context, in domainagg.Comm
 func (a *nodeDocAggregate) RecordVariantOutcome(ctx context.Context, in domainagg.RecordDocVariantOutcomeInput) (domainagg.RecordDocVariantOutcomeResult, error) {
 	const op = "DocGen.NodeDoc.RecordVariantOutcome"
 	var out domainagg.RecordDocVariantOutcomeResult
+	if _, ok := domainagg.FindDocVariantOutcomeLabel(a.deps.OutcomeLabels, in.OutcomeLabel); !ok {
+		return out, domainagg.NewError(domainagg.CodeValidation, op, fmt.Sprintf("unknown outcome_label %q", in.OutcomeLabel), nil)
+	}
 	err := executeWrite(ctx, a.deps.Base, op, func(_ dbctx.Context) error {
 		return notImplemented(op)
 	})
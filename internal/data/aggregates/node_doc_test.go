@@ -0,0 +1,34 @@
+package aggregates
+
+import (
+	"context"
+	"testing"
+
+	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
+)
+
+func TestNodeDocRecordVariantOutcome_RejectsUnknownLabel(t *testing.T) {
+	agg := NewNodeDocAggregate(NodeDocAggregateDeps{
+		OutcomeLabels: []domainagg.DocVariantOutcomeLabel{{Label: "completed", Reward: 1}},
+	})
+
+	for _, label := range []string{"", "bogus", "completed!"} {
+		_, err := agg.RecordVariantOutcome(context.Background(), domainagg.RecordDocVariantOutcomeInput{OutcomeLabel: label})
+		if !domainagg.IsCode(err, domainagg.CodeValidation) {
+			t.Fatalf("label %q: expected validation code, got %q (%v)", label, domainagg.CodeOf(err), err)
+		}
+	}
+
+	_, err := agg.RecordVariantOutcome(context.Background(), domainagg.RecordDocVariantOutcomeInput{OutcomeLabel: " Completed "})
+	if domainagg.IsCode(err, domainagg.CodeValidation) {
+		t.Fatalf("known label rejected: %v", err)
+	}
+}
+
+func TestNodeDocAggregate_DefaultsOutcomeLabels(t *testing.T) {
+	agg := NewNodeDocAggregate(NodeDocAggregateDeps{})
+	_, err := agg.RecordVariantOutcome(context.Background(), domainagg.RecordDocVariantOutcomeInput{OutcomeLabel: "abandoned"})
+	if domainagg.IsCode(err, domainagg.CodeValidation) {
+		t.Fatalf("default label rejected: %v", err)
+	}
+}
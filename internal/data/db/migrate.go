@@ -17,6 +17,7 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&types.User{},
 		&types.UserToken{},
 		&types.UserSessionState{},
+		&types.UserNotification{},
 		&types.UserIdentity{},
 		&types.OAuthNonce{},
 
@@ -55,6 +56,7 @@ func AutoMigrateAll(db *gorm.DB) error {
 		// =========================
 		// Concepts + Graph Products
 		&types.Concept{},
+		&types.ConceptDocEmbedding{},
 		&types.ConceptEvidence{},
 		&types.ConceptEdge{},
 		&types.ConceptCluster{},
@@ -86,6 +88,9 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&types.LearningNodeDoc{},
 		&types.LearningNodeDocRevision{},
 		&types.LearningNodeFigure{},
+		&types.FigureBlob{},
+		&types.LearningNodeAudio{},
+		&types.NodeAssetRef{},
 		&types.LearningNodeVideo{},
 		&types.LearningDocGenerationRun{},
 		&types.LearningNodeDocBlueprint{},
@@ -94,9 +99,11 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&types.DocRetrievalPack{},
 		&types.DocGenerationTrace{},
 		&types.DocConstraintReport{},
+		&types.DocConsistencyAuditRun{},
 		&types.DocProbe{},
 		&types.DocProbeOutcome{},
 		&types.DocVariantExposure{},
+		&types.DocVariantAssignment{},
 		&types.DocVariantOutcome{},
 		&types.LearningDrillInstance{},
 		&types.LearningArtifact{},
@@ -110,6 +117,8 @@ func AutoMigrateAll(db *gorm.DB) error {
 		// Path (the non-legacy top-level object)
 		&types.Path{},
 		&types.PathNode{},
+		&types.PathShare{},
+		&types.PathFocus{},
 		&types.PathNodeActivity{},
 		&types.PathRun{},
 		&types.NodeRun{},
@@ -161,6 +170,7 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&types.SagaAction{},
 		&types.JobRun{},
 		&types.JobRunEvent{},
+		&types.JobSchedulerState{},
 
 		// =========================
 		// Chat
@@ -175,6 +185,13 @@ func AutoMigrateAll(db *gorm.DB) error {
 		&types.ChatClaim{},
 		&types.ChatDoc{},
 		&types.ChatTurn{},
+		&types.ChatToolExecution{},
+		&types.ContextBudgetStat{},
+
+		// =========================
+		// Runtime config
+		// =========================
+		&types.FeatureFlag{},
 	)
 }
 
@@ -369,12 +386,16 @@ func EnsureLearningIndexes(db *gorm.DB) error {
 		return fmt.Errorf("create idx_path_root_sort: %w", err)
 	}
 
-	// Node docs: canonical per node.
+	// Node docs: canonical per node and track. The per-node index predates tracks and would
+	// reject a node's second track.
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_learning_node_doc_path_node_id;`).Error; err != nil {
+		return fmt.Errorf("drop idx_learning_node_doc_path_node_id: %w", err)
+	}
 	if err := db.Exec(`
-		CREATE UNIQUE INDEX IF NOT EXISTS idx_learning_node_doc_path_node_id
-		ON learning_node_doc (path_node_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_learning_node_doc_node_track
+		ON learning_node_doc (path_node_id, track);
 	`).Error; err != nil {
-		return fmt.Errorf("create idx_learning_node_doc_path_node_id: %w", err)
+		return fmt.Errorf("create idx_learning_node_doc_node_track: %w", err)
 	}
 	if err := db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_learning_node_doc_user_path_updated
@@ -382,6 +403,14 @@ func EnsureLearningIndexes(db *gorm.DB) error {
 	`).Error; err != nil {
 		return fmt.Errorf("create idx_learning_node_doc_user_path_updated: %w", err)
 	}
+	// Node docs: full-text search over doc_text (doc search).
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_learning_node_doc_fts
+		ON learning_node_doc
+		USING GIN (to_tsvector('english', doc_text));
+	`).Error; err != nil {
+		return fmt.Errorf("create idx_learning_node_doc_fts: %w", err)
+	}
 
 	// Node doc revisions: per-node history view.
 	if err := db.Exec(`
@@ -558,6 +587,106 @@ func EnsureLearningIndexes(db *gorm.DB) error {
 	return nil
 }
 
+// EnsureUserEventIndexes builds the (user_id, client_event_id) unique index that event ingestion's
+// ON CONFLICT DO NOTHING depends on. Existing duplicates are removed first, keeping the earliest
+// row per key, and the index is built CONCURRENTLY so ingestion isn't blocked on large tables.
+// A concurrent build that failed leaves an invalid index behind; it is dropped and rebuilt.
+func EnsureUserEventIndexes(db *gorm.DB) error {
+	var valid []bool
+	if err := db.Raw(`
+		SELECT i.indisvalid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE c.relname = 'idx_user_client_event'
+		LIMIT 1;
+	`).Scan(&valid).Error; err != nil {
+		return fmt.Errorf("load idx_user_client_event: %w", err)
+	}
+	if len(valid) == 1 && valid[0] {
+		return nil
+	}
+	if len(valid) == 1 {
+		if err := db.Exec(`DROP INDEX CONCURRENTLY IF EXISTS idx_user_client_event;`).Error; err != nil {
+			return fmt.Errorf("drop invalid idx_user_client_event: %w", err)
+		}
+	}
+	if err := db.Exec(`
+		WITH ranked AS (
+			SELECT id,
+				ROW_NUMBER() OVER (
+					PARTITION BY user_id, client_event_id
+					ORDER BY created_at ASC, id ASC
+				) AS rn
+			FROM user_event
+		)
+		DELETE FROM user_event WHERE id IN (SELECT id FROM ranked WHERE rn > 1);
+	`).Error; err != nil {
+		return fmt.Errorf("dedupe user_event: %w", err)
+	}
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_user_client_event
+		ON user_event (user_id, client_event_id);
+	`).Error; err != nil {
+		return fmt.Errorf("create idx_user_client_event: %w", err)
+	}
+	return nil
+}
+
+func EnsureJobIndexes(db *gorm.DB) error {
+	// Job history: per-user listing filtered by status or type, newest first.
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_job_run_owner_status_created
+		ON job_run (owner_user_id, status, created_at DESC);
+	`).Error; err != nil {
+		return fmt.Errorf("create idx_job_run_owner_status_created: %w", err)
+	}
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_job_run_owner_type_created
+		ON job_run (owner_user_id, job_type, created_at DESC);
+	`).Error; err != nil {
+		return fmt.Errorf("create idx_job_run_owner_type_created: %w", err)
+	}
+	// Job history ?entity_id=: payload containment lookups (payload @> '{"path_node_id": ...}').
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_job_run_payload_path_ops
+		ON job_run
+		USING GIN (payload jsonb_path_ops);
+	`).Error; err != nil {
+		return fmt.Errorf("create idx_job_run_payload_path_ops: %w", err)
+	}
+	return nil
+}
+
+// EnsureCanonicalConceptKeyIndex makes global concepts unique on their natural key
+// (lower(trim(key))), so concurrent builds in different paths cannot both create a canonical
+// row for the same concept. Creation is skipped while historical duplicates exist (the index
+// would fail to build); it reports how many duplicate keys block it.
+func EnsureCanonicalConceptKeyIndex(db *gorm.DB) (bool, int64, error) {
+	var dupes int64
+	if err := db.Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT lower(btrim(key))
+			FROM concept
+			WHERE scope = 'global' AND scope_id IS NULL AND deleted_at IS NULL
+			GROUP BY lower(btrim(key))
+			HAVING COUNT(*) > 1
+		) d;
+	`).Scan(&dupes).Error; err != nil {
+		return false, 0, fmt.Errorf("count duplicate canonical concepts: %w", err)
+	}
+	if dupes > 0 {
+		return false, dupes, nil
+	}
+	if err := db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_concept_global_natural_key
+		ON concept (lower(btrim(key)))
+		WHERE scope = 'global' AND scope_id IS NULL AND deleted_at IS NULL;
+	`).Error; err != nil {
+		return false, 0, fmt.Errorf("create idx_concept_global_natural_key: %w", err)
+	}
+	return true, 0, nil
+}
+
 func (s *PostgresService) AutoMigrateAll() error {
 	s.log.Info("Auto migrating postgres tables...")
 	if err := AutoMigrateAll(s.db); err != nil {
@@ -576,6 +705,22 @@ func (s *PostgresService) AutoMigrateAll() error {
 		s.log.Error("Learning index migration failed", "error", err)
 		return err
 	}
+	if err := EnsureUserEventIndexes(s.db); err != nil {
+		s.log.Error("User event index migration failed", "error", err)
+		return err
+	}
+	if err := EnsureJobIndexes(s.db); err != nil {
+		s.log.Error("Job index migration failed", "error", err)
+		return err
+	}
+	created, dupes, err := EnsureCanonicalConceptKeyIndex(s.db)
+	if err != nil {
+		s.log.Error("Canonical concept key index migration failed", "error", err)
+		return err
+	}
+	if !created {
+		s.log.Warn("Canonical concept key index skipped: duplicate global concepts exist; run cmd/reconcile_canonical_concepts --apply", "duplicate_keys", dupes)
+	}
 
 	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/utils"
 )
@@ -55,6 +56,10 @@ func NewPostgresService(logg *logger.Logger) (*PostgresService, error) {
 		return nil, fmt.Errorf("failed to connect to Postgres: %w", err)
 	}
 
+	if err := dbstats.Register(db, dbstats.ConfigFromEnv(serviceLog)); err != nil {
+		return nil, fmt.Errorf("failed to register query instrumentation: %w", err)
+	}
+
 	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`).Error; err != nil {
 		return nil, fmt.Errorf("failed to enable uuid-ossp extension: %w", err)
 	}
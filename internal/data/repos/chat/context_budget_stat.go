@@ -0,0 +1,216 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// ContextBudgetBucketAll is the rollup bucket across every path size.
+const ContextBudgetBucketAll = "all"
+
+// ContextBudgetLaneSummary aggregates one lane within one path-size bucket.
+// Plans counts sampled plans where the lane was enabled; the rates are fractions of Plans.
+type ContextBudgetLaneSummary struct {
+	Lane   string `json:"lane"`
+	Bucket string `json:"path_size_bucket"`
+
+	Plans           int64 `json:"plans"`
+	AllocatedTokens int64 `json:"allocated_tokens"`
+	ConsumedTokens  int64 `json:"consumed_tokens"`
+	TruncatedTokens int64 `json:"truncated_tokens"`
+	TruncatedPlans  int64 `json:"truncated_plans"`
+	DroppedPlans    int64 `json:"dropped_plans"`
+	SaturatedPlans  int64 `json:"saturated_plans"`
+
+	TruncationRate float64 `json:"truncation_rate"`
+	DropRate       float64 `json:"drop_rate"`
+	// PressureScore is the fraction of plans where the lane hit its allocation ceiling.
+	PressureScore float64 `json:"pressure_score"`
+	// Utilization is consumed over allocated tokens.
+	Utilization float64 `json:"utilization"`
+}
+
+type ContextBudgetBucketSummary struct {
+	Bucket        string `json:"path_size_bucket"`
+	Plans         int64  `json:"plans"`
+	PlansWithDrop int64  `json:"plans_with_drop"`
+}
+
+type ContextBudgetSummary struct {
+	Since         time.Time                    `json:"since"`
+	Until         time.Time                    `json:"until"`
+	Plans         int64                        `json:"plans"`
+	PlansWithDrop int64                        `json:"plans_with_drop"`
+	Buckets       []ContextBudgetBucketSummary `json:"buckets"`
+	// Lanes holds one row per (lane, bucket) plus a per-lane "all" rollup.
+	Lanes []ContextBudgetLaneSummary `json:"lanes"`
+}
+
+type ContextBudgetStatRepo interface {
+	Create(dbc dbctx.Context, row *types.ContextBudgetStat) error
+	Summarize(dbc dbctx.Context, since, until time.Time) (ContextBudgetSummary, error)
+	DeleteOlderThan(dbc dbctx.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+type contextBudgetStatRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewContextBudgetStatRepo(db *gorm.DB, baseLog *logger.Logger) ContextBudgetStatRepo {
+	return &contextBudgetStatRepo{db: db, log: baseLog.With("repo", "ContextBudgetStatRepo")}
+}
+
+func (r *contextBudgetStatRepo) Create(dbc dbctx.Context, row *types.ContextBudgetStat) error {
+	if row == nil {
+		return fmt.Errorf("invalid context budget stat")
+	}
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now().UTC()
+	}
+	return t.WithContext(dbc.Ctx).Create(row).Error
+}
+
+func (r *contextBudgetStatRepo) Summarize(dbc dbctx.Context, since, until time.Time) (ContextBudgetSummary, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+
+	var buckets []ContextBudgetBucketSummary
+	if err := t.WithContext(dbc.Ctx).Raw(`
+		SELECT path_size_bucket AS bucket,
+		       COUNT(*) AS plans,
+		       COUNT(*) FILTER (WHERE any_lane_dropped) AS plans_with_drop
+		FROM context_budget_stat
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY path_size_bucket
+	`, since, until).Scan(&buckets).Error; err != nil {
+		return ContextBudgetSummary{}, err
+	}
+
+	var lanes []ContextBudgetLaneSummary
+	if err := t.WithContext(dbc.Ctx).Raw(`
+		SELECT l.lane AS lane,
+		       s.path_size_bucket AS bucket,
+		       COUNT(*) AS plans,
+		       COALESCE(SUM(l.allocated), 0) AS allocated_tokens,
+		       COALESCE(SUM(l.consumed), 0) AS consumed_tokens,
+		       COALESCE(SUM(l.truncated), 0) AS truncated_tokens,
+		       COUNT(*) FILTER (WHERE l.truncated > 0) AS truncated_plans,
+		       COUNT(*) FILTER (WHERE COALESCE(l.dropped, false)) AS dropped_plans,
+		       COUNT(*) FILTER (WHERE l.allocated > 0 AND (l.consumed >= l.allocated OR l.truncated > 0)) AS saturated_plans
+		FROM context_budget_stat s
+		CROSS JOIN LATERAL jsonb_to_recordset(s.lanes)
+		     AS l(lane text, allocated int, consumed int, truncated int, dropped boolean)
+		WHERE s.created_at >= ? AND s.created_at < ?
+		GROUP BY l.lane, s.path_size_bucket
+	`, since, until).Scan(&lanes).Error; err != nil {
+		return ContextBudgetSummary{}, err
+	}
+
+	out := buildContextBudgetSummary(buckets, lanes)
+	out.Since, out.Until = since, until
+	return out, nil
+}
+
+func (r *contextBudgetStatRepo) DeleteOlderThan(dbc dbctx.Context, cutoff time.Time, limit int) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	res := t.WithContext(dbc.Ctx).
+		Where("id IN (?)", t.Model(&types.ContextBudgetStat{}).
+			Select("id").
+			Where("created_at < ?", cutoff).
+			Limit(limit)).
+		Delete(&types.ContextBudgetStat{})
+	return res.RowsAffected, res.Error
+}
+
+// buildContextBudgetSummary adds the per-lane rollups and derives the rates from the raw counts.
+func buildContextBudgetSummary(buckets []ContextBudgetBucketSummary, lanes []ContextBudgetLaneSummary) ContextBudgetSummary {
+	out := ContextBudgetSummary{Buckets: buckets, Lanes: make([]ContextBudgetLaneSummary, 0, len(lanes)+8)}
+	for _, b := range buckets {
+		out.Plans += b.Plans
+		out.PlansWithDrop += b.PlansWithDrop
+	}
+
+	rollup := map[string]*ContextBudgetLaneSummary{}
+	for _, row := range lanes {
+		all := rollup[row.Lane]
+		if all == nil {
+			all = &ContextBudgetLaneSummary{Lane: row.Lane, Bucket: ContextBudgetBucketAll}
+			rollup[row.Lane] = all
+		}
+		all.Plans += row.Plans
+		all.AllocatedTokens += row.AllocatedTokens
+		all.ConsumedTokens += row.ConsumedTokens
+		all.TruncatedTokens += row.TruncatedTokens
+		all.TruncatedPlans += row.TruncatedPlans
+		all.DroppedPlans += row.DroppedPlans
+		all.SaturatedPlans += row.SaturatedPlans
+		out.Lanes = append(out.Lanes, row)
+	}
+	for _, all := range rollup {
+		out.Lanes = append(out.Lanes, *all)
+	}
+	for i := range out.Lanes {
+		out.Lanes[i].derive()
+	}
+
+	sort.Slice(out.Buckets, func(i, j int) bool {
+		return bucketRank(out.Buckets[i].Bucket) < bucketRank(out.Buckets[j].Bucket)
+	})
+	sort.Slice(out.Lanes, func(i, j int) bool {
+		a, b := out.Lanes[i], out.Lanes[j]
+		if a.Lane != b.Lane {
+			return a.Lane < b.Lane
+		}
+		return bucketRank(a.Bucket) < bucketRank(b.Bucket)
+	})
+	return out
+}
+
+func (s *ContextBudgetLaneSummary) derive() {
+	s.TruncationRate = ratio(s.TruncatedPlans, s.Plans)
+	s.DropRate = ratio(s.DroppedPlans, s.Plans)
+	s.PressureScore = ratio(s.SaturatedPlans, s.Plans)
+	s.Utilization = ratio(s.ConsumedTokens, s.AllocatedTokens)
+}
+
+func ratio(n, d int64) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func bucketRank(bucket string) int {
+	for i, b := range []string{ContextBudgetBucketAll, "none", "small", "medium", "large", "xl"} {
+		if b == bucket {
+			return i
+		}
+	}
+	return 99
+}
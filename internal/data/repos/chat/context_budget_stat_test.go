@@ -0,0 +1,174 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type budgetFixturePlan struct {
+	bucket string
+	lanes  []types.ContextBudgetLaneUsage
+}
+
+// contextBudgetFixture: three large-path plans with materials pressure, one small-path plan
+// with headroom. Retrieval is disabled on one large plan.
+func contextBudgetFixture() []budgetFixturePlan {
+	lane := func(name string, allocated, consumed, truncated int) types.ContextBudgetLaneUsage {
+		return types.ContextBudgetLaneUsage{Lane: name, Allocated: allocated, Consumed: consumed, Truncated: truncated, Dropped: consumed == 0 && truncated > 0}
+	}
+	return []budgetFixturePlan{
+		{"large", []types.ContextBudgetLaneUsage{lane("materials", 1000, 1000, 600), lane("retrieve", 800, 800, 200)}},
+		{"large", []types.ContextBudgetLaneUsage{lane("materials", 1000, 990, 400), lane("retrieve", 800, 500, 0)}},
+		{"large", []types.ContextBudgetLaneUsage{lane("materials", 1000, 0, 300)}},
+		{"small", []types.ContextBudgetLaneUsage{lane("materials", 1000, 200, 0), lane("retrieve", 800, 300, 0)}},
+	}
+}
+
+func findLaneSummary(t *testing.T, s ContextBudgetSummary, lane, bucket string) ContextBudgetLaneSummary {
+	t.Helper()
+	for _, row := range s.Lanes {
+		if row.Lane == lane && row.Bucket == bucket {
+			return row
+		}
+	}
+	t.Fatalf("no summary for lane=%s bucket=%s in %+v", lane, bucket, s.Lanes)
+	return ContextBudgetLaneSummary{}
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func assertContextBudgetFixtureSummary(t *testing.T, s ContextBudgetSummary) {
+	t.Helper()
+	if s.Plans != 4 || s.PlansWithDrop != 1 {
+		t.Fatalf("plans = %d (with drop %d), want 4 (1)", s.Plans, s.PlansWithDrop)
+	}
+	if len(s.Buckets) != 2 || s.Buckets[0].Bucket != "small" || s.Buckets[1].Bucket != "large" {
+		t.Fatalf("buckets = %+v", s.Buckets)
+	}
+
+	m := findLaneSummary(t, s, "materials", "large")
+	if m.Plans != 3 || m.TruncatedPlans != 3 || m.DroppedPlans != 1 || m.SaturatedPlans != 3 {
+		t.Fatalf("materials/large counts = %+v", m)
+	}
+	if m.AllocatedTokens != 3000 || m.ConsumedTokens != 1990 || m.TruncatedTokens != 1300 {
+		t.Fatalf("materials/large tokens = %+v", m)
+	}
+	if !approx(m.TruncationRate, 1) || !approx(m.DropRate, 1.0/3) || !approx(m.PressureScore, 1) || !approx(m.Utilization, 1990.0/3000) {
+		t.Fatalf("materials/large rates = %+v", m)
+	}
+
+	// Hitting the ceiling without truncation still counts as pressure; headroom does not.
+	r := findLaneSummary(t, s, "retrieve", "large")
+	if r.Plans != 2 || r.TruncatedPlans != 1 || r.SaturatedPlans != 1 || !approx(r.PressureScore, 0.5) {
+		t.Fatalf("retrieve/large = %+v", r)
+	}
+
+	all := findLaneSummary(t, s, "materials", ContextBudgetBucketAll)
+	if all.Plans != 4 || all.SaturatedPlans != 3 || !approx(all.PressureScore, 0.75) || !approx(all.TruncationRate, 0.75) || !approx(all.DropRate, 0.25) {
+		t.Fatalf("materials/all = %+v", all)
+	}
+	if all.AllocatedTokens != 4000 || all.ConsumedTokens != 2190 || !approx(all.Utilization, 2190.0/4000) {
+		t.Fatalf("materials/all tokens = %+v", all)
+	}
+	if got := findLaneSummary(t, s, "retrieve", "small"); got.PressureScore != 0 || got.TruncationRate != 0 {
+		t.Fatalf("retrieve/small = %+v", got)
+	}
+
+	// Rollups sort ahead of the size buckets within each lane.
+	if s.Lanes[0].Lane != "materials" || s.Lanes[0].Bucket != ContextBudgetBucketAll {
+		t.Fatalf("first row = %+v", s.Lanes[0])
+	}
+}
+
+func TestBuildContextBudgetSummary(t *testing.T) {
+	bucketRows := map[string]*ContextBudgetBucketSummary{}
+	laneRows := map[[2]string]*ContextBudgetLaneSummary{}
+	for _, plan := range contextBudgetFixture() {
+		b := bucketRows[plan.bucket]
+		if b == nil {
+			b = &ContextBudgetBucketSummary{Bucket: plan.bucket}
+			bucketRows[plan.bucket] = b
+		}
+		b.Plans++
+		dropped := false
+		for _, u := range plan.lanes {
+			key := [2]string{u.Lane, plan.bucket}
+			row := laneRows[key]
+			if row == nil {
+				row = &ContextBudgetLaneSummary{Lane: u.Lane, Bucket: plan.bucket}
+				laneRows[key] = row
+			}
+			row.Plans++
+			row.AllocatedTokens += int64(u.Allocated)
+			row.ConsumedTokens += int64(u.Consumed)
+			row.TruncatedTokens += int64(u.Truncated)
+			if u.Truncated > 0 {
+				row.TruncatedPlans++
+			}
+			if u.Dropped {
+				row.DroppedPlans++
+				dropped = true
+			}
+			if u.Saturated() {
+				row.SaturatedPlans++
+			}
+		}
+		if dropped {
+			b.PlansWithDrop++
+		}
+	}
+	var buckets []ContextBudgetBucketSummary
+	for _, b := range bucketRows {
+		buckets = append(buckets, *b)
+	}
+	var lanes []ContextBudgetLaneSummary
+	for _, row := range laneRows {
+		lanes = append(lanes, *row)
+	}
+	assertContextBudgetFixtureSummary(t, buildContextBudgetSummary(buckets, lanes))
+}
+
+func TestContextBudgetStatRepoSummarize(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	repo := NewContextBudgetStatRepo(db, testutil.Logger(t))
+
+	now := time.Now().UTC()
+	for _, plan := range contextBudgetFixture() {
+		lanes, _ := json.Marshal(plan.lanes)
+		dropped := false
+		for _, u := range plan.lanes {
+			dropped = dropped || u.Dropped
+		}
+		row := &types.ContextBudgetStat{UserID: uuid.New(), ThreadID: uuid.New(), PathSizeBucket: plan.bucket, Lanes: lanes, AnyLaneDropped: dropped, CreatedAt: now.Add(-time.Hour)}
+		if err := repo.Create(dbc, row); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	old := &types.ContextBudgetStat{UserID: uuid.New(), ThreadID: uuid.New(), PathSizeBucket: "xl", Lanes: []byte(`[{"lane":"materials","allocated":10,"consumed":10,"truncated":5}]`), CreatedAt: now.Add(-30 * 24 * time.Hour)}
+	if err := repo.Create(dbc, old); err != nil {
+		t.Fatalf("Create old: %v", err)
+	}
+
+	s, err := repo.Summarize(dbc, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	assertContextBudgetFixtureSummary(t, s)
+
+	deleted, err := repo.DeleteOlderThan(dbc, now.Add(-7*24*time.Hour), 100)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteOlderThan = %d, %v; want 1", deleted, err)
+	}
+}
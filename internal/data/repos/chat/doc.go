@@ -18,6 +18,9 @@ import (
 type ChatDocRepo interface {
 	Upsert(dbc dbctx.Context, rows []*types.ChatDoc) error
 	GetByIDs(dbc dbctx.Context, userID uuid.UUID, ids []uuid.UUID) ([]*types.ChatDoc, error)
+	// GetByIDsOrdered is GetByIDs with the rows in the order of ids (e.g. a ranked vector
+	// search result). Missing ids are dropped; repeated ids yield the row once.
+	GetByIDsOrdered(dbc dbctx.Context, userID uuid.UUID, ids []uuid.UUID) ([]*types.ChatDoc, error)
 	LexicalSearch(dbc dbctx.Context, q ChatLexicalQuery) ([]*types.ChatDoc, error)
 	LexicalSearchHits(dbc dbctx.Context, q ChatLexicalQuery) ([]ChatLexicalHit, error)
 }
@@ -94,6 +97,31 @@ func (r *chatDocRepo) GetByIDs(dbc dbctx.Context, userID uuid.UUID, ids []uuid.U
 	return out, nil
 }
 
+func (r *chatDocRepo) GetByIDsOrdered(dbc dbctx.Context, userID uuid.UUID, ids []uuid.UUID) ([]*types.ChatDoc, error) {
+	rows, err := r.GetByIDs(dbc, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return orderChatDocsByIDs(rows, ids), nil
+}
+
+func orderChatDocsByIDs(rows []*types.ChatDoc, ids []uuid.UUID) []*types.ChatDoc {
+	byID := make(map[uuid.UUID]*types.ChatDoc, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			byID[row.ID] = row
+		}
+	}
+	out := make([]*types.ChatDoc, 0, len(rows))
+	for _, id := range ids {
+		if row := byID[id]; row != nil {
+			out = append(out, row)
+			delete(byID, id)
+		}
+	}
+	return out
+}
+
 type ChatLexicalQuery struct {
 	UserID   uuid.UUID
 	Scope    string
@@ -101,6 +129,9 @@ type ChatLexicalQuery struct {
 	DocTypes []string
 	Query    string
 	Limit    int
+
+	// SourceID, when set, narrows the scope to docs derived from one source (e.g. a path node).
+	SourceID *uuid.UUID
 }
 
 type ChatLexicalHit struct {
@@ -151,6 +182,11 @@ func (r *chatDocRepo) LexicalSearchHits(dbc dbctx.Context, q ChatLexicalQuery) (
 		where += " AND chat_doc.scope_id IS NULL"
 	}
 
+	if q.SourceID != nil && *q.SourceID != uuid.Nil {
+		where += " AND chat_doc.source_id = ?"
+		args = append(args, *q.SourceID)
+	}
+
 	if len(q.DocTypes) > 0 {
 		where += " AND chat_doc.doc_type IN ?"
 		args = append(args, q.DocTypes)
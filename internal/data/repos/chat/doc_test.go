@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestOrderChatDocsByIDs(t *testing.T) {
+	ids := make([]uuid.UUID, 6)
+	rows := make([]*types.ChatDoc, 0, len(ids))
+	for i := range ids {
+		ids[i] = uuid.New()
+		if i != 3 { // ids[3] has no row
+			rows = append(rows, &types.ChatDoc{ID: ids[i]})
+		}
+	}
+	r := rand.New(rand.NewSource(7))
+	for n := 0; n < 5; n++ {
+		r.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+		r.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		want := make([]uuid.UUID, 0, len(rows))
+		for _, id := range ids {
+			for _, row := range rows {
+				if row.ID == id {
+					want = append(want, id)
+				}
+			}
+		}
+		got := orderChatDocsByIDs(append(rows, nil), append(ids, ids[0]))
+		if len(got) != len(want) {
+			t.Fatalf("got %d rows, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i].ID != want[i] {
+				t.Fatalf("row %d = %s, want %s", i, got[i].ID, want[i])
+			}
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package chat
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +22,9 @@ type ChatSummaryNodeRepo interface {
 	ListOrphansByLevel(dbc dbctx.Context, threadID uuid.UUID, level int) ([]*types.ChatSummaryNode, error)
 	SetParent(dbc dbctx.Context, childIDs []uuid.UUID, parentID uuid.UUID) error
 	GetRoot(dbc dbctx.Context, threadID uuid.UUID) (*types.ChatSummaryNode, error)
+	// GetBudgetedSummary returns the thread summary within tokenBudget: the root when it fits,
+	// otherwise the newest nodes that fit plus the root's cached "earlier topics" digest.
+	GetBudgetedSummary(dbc dbctx.Context, threadID uuid.UUID, tokenBudget int) (BudgetedSummary, error)
 }
 
 type chatSummaryNodeRepo struct {
@@ -114,3 +121,262 @@ func (r *chatSummaryNodeRepo) GetRoot(dbc dbctx.Context, threadID uuid.UUID) (*t
 	}
 	return &out, nil
 }
+
+// BudgetedSummary is a thread summary assembled to fit a token budget.
+type BudgetedSummary struct {
+	Text string
+	// RootText is the untrimmed root summary, for callers that need the whole-thread gist
+	// (e.g. query contextualization) regardless of the budget.
+	RootText string
+	// Mode is "empty", "root" (the root fit), or "recent" (newest nodes plus digest).
+	Mode string
+	// NodeIDs are the included nodes, oldest first.
+	NodeIDs []uuid.UUID
+	// Digest reports whether the "earlier topics" digest was included.
+	Digest bool
+	// Truncated reports that even the newest node did not fit and was cut to the budget.
+	Truncated bool
+	Tokens    int
+}
+
+func (r *chatSummaryNodeRepo) GetBudgetedSummary(dbc dbctx.Context, threadID uuid.UUID, tokenBudget int) (BudgetedSummary, error) {
+	out := BudgetedSummary{Mode: "empty"}
+	root, err := r.GetRoot(dbc, threadID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && root == nil) {
+		return out, nil
+	}
+	if err != nil {
+		return out, err
+	}
+	rootText := strings.TrimSpace(root.SummaryMD)
+	if tokenBudget <= 0 || rootText == "" || summaryTokens(rootText) <= tokenBudget {
+		return SelectBudgetedSummary(root, nil, tokenBudget), nil
+	}
+
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	var nodes []*types.ChatSummaryNode
+	if err := transaction.WithContext(dbc.Ctx).
+		Model(&types.ChatSummaryNode{}).
+		Where("thread_id = ? AND start_seq >= ? AND end_seq <= ?", threadID, root.StartSeq, root.EndSeq).
+		Find(&nodes).Error; err != nil {
+		return out, err
+	}
+	if strings.TrimSpace(root.DigestMD) == "" {
+		root.DigestMD = summaryDigest(root, nodes)
+		if root.DigestMD != "" {
+			// Best effort: a missing cache only costs recomputing the digest next time.
+			if err := transaction.WithContext(dbc.Ctx).
+				Model(&types.ChatSummaryNode{}).
+				Where("id = ? AND digest_md = ''", root.ID).
+				Update("digest_md", root.DigestMD).Error; err != nil {
+				r.log.Warn("cache summary digest failed", "error", err, "node_id", root.ID)
+			}
+		}
+	}
+	return SelectBudgetedSummary(root, nodes, tokenBudget), nil
+}
+
+// SelectBudgetedSummary assembles a summary of at most tokenBudget tokens from a root and the
+// nodes of its tree. When the root is too long it walks back from the newest messages, at each
+// step taking the coarsest non-overlapping node ending at the newest uncovered seq that still
+// fits, and prefixes the root's digest (capped at a quarter of the budget) when older messages
+// remain uncovered.
+func SelectBudgetedSummary(root *types.ChatSummaryNode, nodes []*types.ChatSummaryNode, tokenBudget int) BudgetedSummary {
+	out := BudgetedSummary{Mode: "empty"}
+	if root == nil {
+		return out
+	}
+	out.RootText = strings.TrimSpace(root.SummaryMD)
+	if tokenBudget <= 0 || out.RootText == "" {
+		return out
+	}
+	if summaryTokens(out.RootText) <= tokenBudget {
+		out.Mode = "root"
+		out.Text = out.RootText
+		out.NodeIDs = []uuid.UUID{root.ID}
+		out.Tokens = summaryTokens(out.Text)
+		return out
+	}
+	out.Mode = "recent"
+
+	digest := ""
+	if d := strings.TrimSpace(root.DigestMD); d != "" {
+		digest = trimSummaryToTokens("Earlier topics: "+d, tokenBudget/4)
+	}
+
+	candidates := make([]*types.ChatSummaryNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil || n.ID == root.ID || strings.TrimSpace(n.SummaryMD) == "" {
+			continue
+		}
+		if n.StartSeq < root.StartSeq || n.EndSeq > root.EndSeq {
+			continue
+		}
+		candidates = append(candidates, n)
+	}
+	// Newest end first; for the same end, the coarsest node covers the most history per token.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].EndSeq != candidates[j].EndSeq {
+			return candidates[i].EndSeq > candidates[j].EndSeq
+		}
+		if candidates[i].Level != candidates[j].Level {
+			return candidates[i].Level > candidates[j].Level
+		}
+		return candidates[i].StartSeq < candidates[j].StartSeq
+	})
+
+	// picked is newest first; the rendered text is oldest first.
+	var picked []*types.ChatSummaryNode
+	cursor := root.EndSeq + 1
+	for {
+		end := int64(-1)
+		for _, c := range candidates {
+			if c.EndSeq < cursor {
+				end = c.EndSeq
+				break
+			}
+		}
+		if end < 0 {
+			break
+		}
+		var next *types.ChatSummaryNode
+		for _, c := range candidates {
+			if c.EndSeq != end {
+				continue
+			}
+			if summaryTokens(renderBudgetedSummary(digest, append(picked, c))) <= tokenBudget {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		picked = append(picked, next)
+		cursor = next.StartSeq
+	}
+
+	if len(picked) == 0 {
+		// Nothing fits whole: cut the finest node at the newest end rather than the oldest-first root.
+		if len(candidates) > 0 {
+			newest := candidates[0]
+			for _, c := range candidates {
+				if c.EndSeq == newest.EndSeq && c.Level < newest.Level {
+					newest = c
+				}
+			}
+			for remain := tokenBudget - summaryTokens(digest); remain > 0; remain-- {
+				body := trimSummaryToTokens(newest.SummaryMD, remain)
+				text := renderBudgetedSummary(digest, []*types.ChatSummaryNode{{SummaryMD: body}})
+				if body != "" && summaryTokens(text) <= tokenBudget {
+					out.Text = text
+					out.NodeIDs = []uuid.UUID{newest.ID}
+					out.Truncated = true
+					break
+				}
+			}
+		}
+		if out.Text == "" {
+			out.Text = digest
+		}
+		out.Digest = digest != ""
+		out.Tokens = summaryTokens(out.Text)
+		return out
+	}
+
+	if cursor <= root.StartSeq {
+		digest = "" // the picked nodes cover the whole thread
+	}
+	out.Text = renderBudgetedSummary(digest, picked)
+	out.Digest = digest != ""
+	out.Tokens = summaryTokens(out.Text)
+	for i := len(picked) - 1; i >= 0; i-- {
+		out.NodeIDs = append(out.NodeIDs, picked[i].ID)
+	}
+	return out
+}
+
+// renderBudgetedSummary renders the digest then the nodes (given newest first) oldest first.
+func renderBudgetedSummary(digest string, newestFirst []*types.ChatSummaryNode) string {
+	parts := make([]string, 0, len(newestFirst)+1)
+	if digest != "" {
+		parts = append(parts, digest)
+	}
+	for i := len(newestFirst) - 1; i >= 0; i-- {
+		parts = append(parts, strings.TrimSpace(newestFirst[i].SummaryMD))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// summaryDigest is a deterministic one-line topic list: the first line of each of the root's
+// children (or of the root itself for a single-level tree), oldest first.
+func summaryDigest(root *types.ChatSummaryNode, nodes []*types.ChatSummaryNode) string {
+	children := make([]*types.ChatSummaryNode, 0)
+	for _, n := range nodes {
+		if n != nil && n.ParentID != nil && *n.ParentID == root.ID {
+			children = append(children, n)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].StartSeq < children[j].StartSeq })
+	if len(children) == 0 {
+		children = []*types.ChatSummaryNode{root}
+	}
+	topics := make([]string, 0, len(children))
+	for _, c := range children {
+		if t := summaryTopic(c.SummaryMD); t != "" {
+			topics = append(topics, t)
+		}
+	}
+	return trimSummaryToTokens(strings.Join(topics, "; "), 150)
+}
+
+func summaryTopic(md string) string {
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#-*>0123456789. "))
+		line = strings.TrimRight(line, ":")
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > 80 {
+			cut := string(r[:80])
+			if i := strings.LastIndex(cut, " "); i > 40 {
+				cut = cut[:i]
+			}
+			line = strings.TrimSpace(cut) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// summaryTokens matches the chat context planner's estimate (~4 chars/token).
+func summaryTokens(s string) int {
+	return int(math.Ceil(float64(len([]rune(s))) / 4.0))
+}
+
+// trimSummaryToTokens cuts s to at most n tokens, backing off to a sentence or word boundary.
+func trimSummaryToTokens(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if n <= 0 || s == "" {
+		return ""
+	}
+	if summaryTokens(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	maxRunes := n*4 - 1 // room for the ellipsis
+	if maxRunes <= 0 {
+		return ""
+	}
+	cut := string(r[:maxRunes])
+	if i := strings.LastIndexAny(cut, ".!?\n"); i > len(cut)/2 {
+		return strings.TrimSpace(cut[:i+1])
+	}
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
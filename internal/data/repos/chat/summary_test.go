@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func summaryNode(level int, start, end int64, parent *types.ChatSummaryNode, topic string, tokens int) *types.ChatSummaryNode {
+	n := &types.ChatSummaryNode{
+		ID:        uuid.New(),
+		Level:     level,
+		StartSeq:  start,
+		EndSeq:    end,
+		SummaryMD: topic + "\n" + strings.Repeat("word ", tokens*4/5),
+	}
+	if parent != nil {
+		n.ParentID = &parent.ID
+	}
+	return n
+}
+
+// syntheticSummaryTree is a three-level tree over seqs 1-40: four ~100-token leaves, two
+// ~150-token intermediates and a ~600-token root.
+func syntheticSummaryTree() (*types.ChatSummaryNode, []*types.ChatSummaryNode) {
+	root := summaryNode(2, 1, 40, nil, "Whole thread", 600)
+	i1 := summaryNode(1, 1, 20, root, "Recursion basics", 150)
+	i2 := summaryNode(1, 21, 40, root, "Tail calls", 150)
+	l1 := summaryNode(0, 1, 10, i1, "Base cases", 100)
+	l2 := summaryNode(0, 11, 20, i1, "Call stack", 100)
+	l3 := summaryNode(0, 21, 30, i2, "Accumulators", 100)
+	l4 := summaryNode(0, 31, 40, i2, "Trampolines", 100)
+	nodes := []*types.ChatSummaryNode{root, i1, i2, l1, l2, l3, l4}
+	root.DigestMD = summaryDigest(root, nodes)
+	return root, nodes
+}
+
+func TestSelectBudgetedSummaryPrefersRecentNodes(t *testing.T) {
+	root, nodes := syntheticSummaryTree()
+	i2, l2, l4 := nodes[2], nodes[4], nodes[6]
+
+	if root.DigestMD != "Recursion basics; Tail calls" {
+		t.Fatalf("digest = %q", root.DigestMD)
+	}
+
+	got := SelectBudgetedSummary(root, nodes, 1000)
+	if got.Mode != "root" || !reflect.DeepEqual(got.NodeIDs, []uuid.UUID{root.ID}) {
+		t.Fatalf("root fits: %+v", got)
+	}
+
+	// The newest half (as one intermediate) fits, then only the newer of the two older leaves.
+	got = SelectBudgetedSummary(root, nodes, 300)
+	if got.Mode != "recent" || !reflect.DeepEqual(got.NodeIDs, []uuid.UUID{l2.ID, i2.ID}) {
+		t.Fatalf("recent selection = %+v, want [%s %s]", got.NodeIDs, l2.ID, i2.ID)
+	}
+	if !got.Digest || !strings.HasPrefix(got.Text, "Earlier topics: Recursion basics; Tail calls") {
+		t.Fatalf("expected digest prefix, got %q", got.Text[:60])
+	}
+	if strings.Index(got.Text, "Call stack") > strings.Index(got.Text, "Tail calls\nword") {
+		t.Fatalf("nodes should render oldest first")
+	}
+
+	// Below the smallest node the newest leaf is cut instead of the oldest-first root.
+	got = SelectBudgetedSummary(root, nodes, 60)
+	if !got.Truncated || !reflect.DeepEqual(got.NodeIDs, []uuid.UUID{l4.ID}) || !strings.Contains(got.Text, "Trampolines") {
+		t.Fatalf("truncated selection = %+v", got)
+	}
+}
+
+func TestSelectBudgetedSummaryStaysWithinBudget(t *testing.T) {
+	root, nodes := syntheticSummaryTree()
+	newest := nodes[6]
+	for budget := 1; budget <= 700; budget++ {
+		got := SelectBudgetedSummary(root, nodes, budget)
+		if got.Tokens > budget || summaryTokens(got.Text) != got.Tokens {
+			t.Fatalf("budget %d: tokens=%d estimate=%d", budget, got.Tokens, summaryTokens(got.Text))
+		}
+		if got.Mode == "recent" && len(got.NodeIDs) > 0 {
+			last := got.NodeIDs[len(got.NodeIDs)-1]
+			if last != newest.ID && last != nodes[2].ID {
+				t.Fatalf("budget %d: newest messages not covered, last node %s", budget, last)
+			}
+		}
+	}
+	if got := SelectBudgetedSummary(root, nodes, 0); got.Text != "" || got.Mode != "empty" {
+		t.Fatalf("zero budget should be empty: %+v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	ChatToolExecutionRunning   = "running"
+	ChatToolExecutionSucceeded = "succeeded"
+	ChatToolExecutionFailed    = "failed"
+)
+
+type ChatToolExecutionRepo interface {
+	// Claim records a running execution for (message_id, tool_name, args_hash). It returns
+	// claimed=false with the existing row when another delivery already ran or is running the
+	// same call; a previously failed execution is re-claimed so the action can be retried.
+	Claim(dbc dbctx.Context, row *types.ChatToolExecution) (*types.ChatToolExecution, bool, error)
+	Finish(dbc dbctx.Context, id uuid.UUID, status string, result datatypes.JSON, errMsg string) error
+	ListByMessageID(dbc dbctx.Context, userID uuid.UUID, messageID uuid.UUID) ([]*types.ChatToolExecution, error)
+}
+
+type chatToolExecutionRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewChatToolExecutionRepo(db *gorm.DB, log *logger.Logger) ChatToolExecutionRepo {
+	return &chatToolExecutionRepo{
+		db:  db,
+		log: log.With("repo", "ChatToolExecutionRepo"),
+	}
+}
+
+func (r *chatToolExecutionRepo) Claim(dbc dbctx.Context, row *types.ChatToolExecution) (*types.ChatToolExecution, bool, error) {
+	if row == nil || row.UserID == uuid.Nil || row.ThreadID == uuid.Nil || row.MessageID == uuid.Nil ||
+		strings.TrimSpace(row.ToolName) == "" || strings.TrimSpace(row.ArgsHash) == "" {
+		return nil, false, fmt.Errorf("invalid chat tool execution")
+	}
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	now := time.Now().UTC()
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	row.Status = ChatToolExecutionRunning
+	if len(row.Arguments) == 0 {
+		row.Arguments = datatypes.JSON([]byte("{}"))
+	}
+
+	res := transaction.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(row)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return row, true, nil
+	}
+
+	retry := transaction.WithContext(dbc.Ctx).
+		Model(&types.ChatToolExecution{}).
+		Where("message_id = ? AND tool_name = ? AND args_hash = ? AND status = ?", row.MessageID, row.ToolName, row.ArgsHash, ChatToolExecutionFailed).
+		Updates(map[string]interface{}{
+			"status":       ChatToolExecutionRunning,
+			"error":        "",
+			"completed_at": nil,
+			"updated_at":   now,
+		})
+	if retry.Error != nil {
+		return nil, false, retry.Error
+	}
+
+	var existing types.ChatToolExecution
+	err := transaction.WithContext(dbc.Ctx).
+		Model(&types.ChatToolExecution{}).
+		Where("message_id = ? AND tool_name = ? AND args_hash = ?", row.MessageID, row.ToolName, row.ArgsHash).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("chat tool execution vanished after conflict")
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if existing.UserID != row.UserID {
+		return nil, false, fmt.Errorf("chat tool execution owned by another user")
+	}
+	return &existing, retry.RowsAffected > 0, nil
+}
+
+func (r *chatToolExecutionRepo) Finish(dbc dbctx.Context, id uuid.UUID, status string, result datatypes.JSON, errMsg string) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("missing id")
+	}
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"status":       status,
+		"error":        errMsg,
+		"completed_at": &now,
+		"updated_at":   now,
+	}
+	if len(result) > 0 {
+		updates["result"] = result
+	}
+	return transaction.WithContext(dbc.Ctx).
+		Model(&types.ChatToolExecution{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *chatToolExecutionRepo) ListByMessageID(dbc dbctx.Context, userID uuid.UUID, messageID uuid.UUID) ([]*types.ChatToolExecution, error) {
+	if userID == uuid.Nil || messageID == uuid.Nil {
+		return nil, fmt.Errorf("missing ids")
+	}
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	var out []*types.ChatToolExecution
+	if err := transaction.WithContext(dbc.Ctx).
+		Model(&types.ChatToolExecution{}).
+		Where("user_id = ? AND message_id = ?", userID, messageID).
+		Order("created_at ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
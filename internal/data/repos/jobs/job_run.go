@@ -1,13 +1,16 @@
 package jobs
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
@@ -23,6 +26,37 @@ type JobRunRepo interface {
 	Heartbeat(dbc dbctx.Context, id uuid.UUID) error
 	HasRunnableForEntity(dbc dbctx.Context, ownerUserID uuid.UUID, entityType string, entityID uuid.UUID, jobType string) (bool, error)
 	ExistsRunnable(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID) (bool, error)
+	CountRunnableForOwner(dbc dbctx.Context, ownerUserID uuid.UUID, jobTypes []string) (int64, error)
+	ListForOwner(dbc dbctx.Context, filter JobRunListFilter) ([]*types.JobRun, error)
+	LatestFailureMessages(dbc dbctx.Context, jobIDs []uuid.UUID) (map[uuid.UUID]string, error)
+}
+
+const (
+	JobRunListDefaultLimit = 50
+	JobRunListMaxLimit     = 100
+)
+
+// Payload keys that may reference an entity for jobs keyed elsewhere (e.g. node_doc_patch
+// jobs carry path_node_id). Each is matched with JSONB containment so the GIN index applies.
+var jobRunPayloadRefKeys = []string{"path_node_id", "path_id", "material_set_id"}
+
+// JobRunCursor is a keyset position in (created_at, id) order.
+type JobRunCursor = keyset.Cursor
+
+type JobRunListFilter struct {
+	OwnerUserID uuid.UUID
+	JobType     string
+	Status      string
+	EntityType  string
+	EntityID    *uuid.UUID
+	// IncludePayloadRefs widens EntityID to jobs whose payload references it.
+	IncludePayloadRefs bool
+	CreatedAfter       *time.Time
+	CreatedBefore      *time.Time
+	Before             *JobRunCursor
+	// Ascending lists oldest first; Before is then the position to resume after.
+	Ascending bool
+	Limit     int
 }
 
 type jobRunRepo struct {
@@ -105,7 +139,7 @@ func (r *jobRunRepo) ClaimNextRunnable(dbc dbctx.Context, maxAttempts int, retry
 		q := txx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
 			Where(`
         (
-          status = ?
+          (status = ? AND (not_before IS NULL OR not_before <= ?))
           OR (
             status = ?
             AND attempts < ?
@@ -117,7 +151,7 @@ func (r *jobRunRepo) ClaimNextRunnable(dbc dbctx.Context, maxAttempts int, retry
             AND heartbeat_at < ?
           )
         )
-      `, "queued", "failed", maxAttempts, retryCutoff, "running", staleCutoff).
+      `, "queued", now, "failed", maxAttempts, retryCutoff, "running", staleCutoff).
 			Order("created_at ASC")
 		qErr := q.First(&job).Error
 		if errors.Is(qErr, gorm.ErrRecordNotFound) {
@@ -262,3 +296,112 @@ func (r *jobRunRepo) ExistsRunnable(dbc dbctx.Context, ownerUserID uuid.UUID, jo
 	}
 	return count > 0, nil
 }
+
+// CountRunnableForOwner counts the owner's queued or running jobs of the given types.
+func (r *jobRunRepo) CountRunnableForOwner(dbc dbctx.Context, ownerUserID uuid.UUID, jobTypes []string) (int64, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	if ownerUserID == uuid.Nil || len(jobTypes) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := transaction.WithContext(dbc.Ctx).
+		Model(&types.JobRun{}).
+		Where("owner_user_id = ? AND job_type IN ? AND status IN ?", ownerUserID, jobTypes, []string{"queued", "running"}).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *jobRunRepo) ListForOwner(dbc dbctx.Context, filter JobRunListFilter) ([]*types.JobRun, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	out := []*types.JobRun{}
+	if filter.OwnerUserID == uuid.Nil {
+		return out, nil
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = JobRunListDefaultLimit
+	}
+	// Callers page by asking for one row past the page size, so a full page may ask for one more.
+	if limit > JobRunListMaxLimit+1 {
+		limit = JobRunListMaxLimit + 1
+	}
+
+	q := transaction.WithContext(dbc.Ctx).Model(&types.JobRun{}).
+		Where("owner_user_id = ?", filter.OwnerUserID)
+	if filter.JobType != "" {
+		q = q.Where("job_type = ?", filter.JobType)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.EntityType != "" {
+		q = q.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil && *filter.EntityID != uuid.Nil {
+		if filter.IncludePayloadRefs {
+			clauses := []string{"entity_id = ?"}
+			args := []interface{}{*filter.EntityID}
+			for _, key := range jobRunPayloadRefKeys {
+				ref, err := json.Marshal(map[string]string{key: filter.EntityID.String()})
+				if err != nil {
+					return nil, err
+				}
+				clauses = append(clauses, "payload @> ?::jsonb")
+				args = append(args, string(ref))
+			}
+			q = q.Where("("+strings.Join(clauses, " OR ")+")", args...)
+		} else {
+			q = q.Where("entity_id = ?", *filter.EntityID)
+		}
+	}
+	if filter.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	q = keyset.Page(q, "created_at", !filter.Ascending, filter.Before)
+
+	if err := q.Limit(limit).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LatestFailureMessages returns the message of the most recent failed event per job.
+func (r *jobRunRepo) LatestFailureMessages(dbc dbctx.Context, jobIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	out := map[uuid.UUID]string{}
+	if len(jobIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		JobID   uuid.UUID `gorm:"column:job_id"`
+		Message string    `gorm:"column:message"`
+	}
+	err := transaction.WithContext(dbc.Ctx).Raw(`
+		SELECT DISTINCT ON (job_id) job_id, message
+		FROM job_run_event
+		WHERE job_id IN ? AND kind = ? AND deleted_at IS NULL
+		ORDER BY job_id, created_at DESC
+	`, jobIDs, "failed").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.JobID] = row.Message
+	}
+	return out, nil
+}
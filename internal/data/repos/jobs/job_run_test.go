@@ -211,8 +211,173 @@ func TestJobRunRepo(t *testing.T) {
 	if exists {
 		t.Fatalf("ExistsRunnable (other): expected false")
 	}
+
+	if n, err := repo.CountRunnableForOwner(dbc, ownerUserID, []string{"rebuild", "other"}); err != nil || n != 1 {
+		t.Fatalf("CountRunnableForOwner: err=%v n=%d", err, n)
+	}
+	if n, err := repo.CountRunnableForOwner(dbc, ownerUserID, []string{"other"}); err != nil || n != 0 {
+		t.Fatalf("CountRunnableForOwner (other): err=%v n=%d", err, n)
+	}
+}
+
+func TestJobRunRepoListForOwner(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewJobRunRepo(db, testutil.Logger(t))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	owner := uuid.New()
+	pathID := uuid.New()
+	nodeID := uuid.New()
+
+	mk := func(jobType, status, entityType string, entityID *uuid.UUID, payload string, age time.Duration) *types.JobRun {
+		return &types.JobRun{
+			ID:          uuid.New(),
+			OwnerUserID: owner,
+			JobType:     jobType,
+			EntityType:  entityType,
+			EntityID:    entityID,
+			Status:      status,
+			Stage:       status,
+			Payload:     datatypes.JSON([]byte(payload)),
+			Result:      datatypes.JSON([]byte("{}")),
+			CreatedAt:   now.Add(-age),
+			UpdatedAt:   now.Add(-age),
+		}
+	}
+	build := mk("learning_build", "succeeded", "path", &pathID, "{}", 5*time.Hour)
+	buildFailed := mk("learning_build", "failed", "path", &pathID, "{}", 4*time.Hour)
+	patch := mk("node_doc_patch", "failed", "path_node", &nodeID, `{"path_node_id":"`+nodeID.String()+`","action":"rewrite"}`, 3*time.Hour)
+	prefetch := mk("node_doc_prefetch", "queued", "path", ptrUUID(uuid.New()), `{"path_id":"`+pathID.String()+`"}`, 2*time.Hour)
+	other := mk("learning_build", "failed", "path", &pathID, "{}", time.Hour)
+	other.OwnerUserID = uuid.New()
+	if _, err := repo.Create(dbc, []*types.JobRun{build, buildFailed, patch, prefetch, other}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	after := now.Add(-270 * time.Minute)
+	cases := []struct {
+		name   string
+		filter JobRunListFilter
+		want   []uuid.UUID
+	}{
+		{name: "all", filter: JobRunListFilter{}, want: []uuid.UUID{prefetch.ID, patch.ID, buildFailed.ID, build.ID}},
+		{name: "job_type", filter: JobRunListFilter{JobType: "learning_build"}, want: []uuid.UUID{buildFailed.ID, build.ID}},
+		{name: "status", filter: JobRunListFilter{Status: "failed"}, want: []uuid.UUID{patch.ID, buildFailed.ID}},
+		{name: "type+status", filter: JobRunListFilter{JobType: "learning_build", Status: "failed"}, want: []uuid.UUID{buildFailed.ID}},
+		{name: "entity", filter: JobRunListFilter{EntityType: "path", EntityID: &pathID}, want: []uuid.UUID{buildFailed.ID, build.ID}},
+		{name: "entity_id+payload", filter: JobRunListFilter{EntityID: &pathID, IncludePayloadRefs: true}, want: []uuid.UUID{prefetch.ID, buildFailed.ID, build.ID}},
+		{name: "node payload", filter: JobRunListFilter{EntityID: &nodeID, IncludePayloadRefs: true}, want: []uuid.UUID{patch.ID}},
+		{name: "window", filter: JobRunListFilter{CreatedAfter: &after, CreatedBefore: ptrTime(now.Add(-150 * time.Minute))}, want: []uuid.UUID{patch.ID, buildFailed.ID}},
+		{name: "cursor", filter: JobRunListFilter{Before: &JobRunCursor{CreatedAt: patch.CreatedAt, ID: patch.ID}}, want: []uuid.UUID{buildFailed.ID, build.ID}},
+		{name: "limit", filter: JobRunListFilter{Limit: 1}, want: []uuid.UUID{prefetch.ID}},
+	}
+	for _, tc := range cases {
+		f := tc.filter
+		f.OwnerUserID = owner
+		got, err := repo.ListForOwner(dbc, f)
+		if err != nil {
+			t.Fatalf("%s: ListForOwner: %v", tc.name, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %d rows, want %d", tc.name, len(got), len(tc.want))
+		}
+		for i := range tc.want {
+			if got[i].ID != tc.want[i] {
+				t.Fatalf("%s: row %d = %v, want %v", tc.name, i, got[i].ID, tc.want[i])
+			}
+		}
+	}
+	if got, err := repo.ListForOwner(dbc, JobRunListFilter{}); err != nil || len(got) != 0 {
+		t.Fatalf("unscoped list must be empty: rows=%d err=%v", len(got), err)
+	}
+
+	events := []*types.JobRunEvent{
+		{JobID: patch.ID, OwnerUserID: owner, JobType: patch.JobType, Kind: "failed", Status: "failed", Stage: "failed", Message: "first failure", CreatedAt: now.Add(-170 * time.Minute)},
+		{JobID: patch.ID, OwnerUserID: owner, JobType: patch.JobType, Kind: "failed", Status: "failed", Stage: "failed", Message: "latest failure", CreatedAt: now.Add(-160 * time.Minute)},
+		{JobID: patch.ID, OwnerUserID: owner, JobType: patch.JobType, Kind: "progress", Status: "running", Stage: "patch", Message: "progress note", CreatedAt: now.Add(-150 * time.Minute)},
+	}
+	if err := tx.Create(&events).Error; err != nil {
+		t.Fatalf("seed events: %v", err)
+	}
+	msgs, err := repo.LatestFailureMessages(dbc, []uuid.UUID{patch.ID, buildFailed.ID})
+	if err != nil {
+		t.Fatalf("LatestFailureMessages: %v", err)
+	}
+	if msgs[patch.ID] != "latest failure" {
+		t.Fatalf("LatestFailureMessages: got %q", msgs[patch.ID])
+	}
+	if _, ok := msgs[buildFailed.ID]; ok {
+		t.Fatalf("LatestFailureMessages: unexpected entry for job without events")
+	}
+
+	// A full page asks for its lookahead row; the repo must return it so the handler sees a next cursor.
+	busy := uuid.New()
+	many := make([]*types.JobRun, 0, JobRunListMaxLimit+5)
+	for i := 0; i < JobRunListMaxLimit+5; i++ {
+		j := mk("learning_build", "succeeded", "path", &pathID, "{}", time.Duration(i+1)*time.Minute)
+		j.OwnerUserID = busy
+		many = append(many, j)
+	}
+	if _, err := repo.Create(dbc, many); err != nil {
+		t.Fatalf("seed max page: %v", err)
+	}
+	for _, limit := range []int{JobRunListMaxLimit + 1, JobRunListMaxLimit * 2} {
+		got, err := repo.ListForOwner(dbc, JobRunListFilter{OwnerUserID: busy, Limit: limit})
+		if err != nil {
+			t.Fatalf("max page limit=%d: ListForOwner: %v", limit, err)
+		}
+		if len(got) != JobRunListMaxLimit+1 {
+			t.Fatalf("max page limit=%d: got %d rows, want %d", limit, len(got), JobRunListMaxLimit+1)
+		}
+	}
 }
 
 func ptrTime(t time.Time) *time.Time { return &t }
 
 func ptrUUID(u uuid.UUID) *uuid.UUID { return &u }
+
+func TestClaimNextRunnableSkipsDeferred(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	repo := NewJobRunRepo(db, testutil.Logger(t))
+
+	now := time.Now().UTC()
+	job := func(createdAt time.Time, notBefore *time.Time) *types.JobRun {
+		return &types.JobRun{
+			ID:          uuid.New(),
+			OwnerUserID: uuid.New(),
+			JobType:     "test_job",
+			Status:      "queued",
+			Stage:       "queued",
+			NotBefore:   notBefore,
+			Payload:     datatypes.JSON([]byte("{}")),
+			Result:      datatypes.JSON([]byte("{}")),
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		}
+	}
+	deferred := job(now.Add(-3*time.Hour), ptrTime(now.Add(time.Hour)))
+	due := job(now.Add(-2*time.Hour), ptrTime(now.Add(-time.Minute)))
+	plain := job(now.Add(-1*time.Hour), nil)
+	if _, err := repo.Create(dbc, []*types.JobRun{deferred, due, plain}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, want := range []uuid.UUID{due.ID, plain.ID} {
+		got, err := repo.ClaimNextRunnable(dbc, 3, time.Hour, time.Hour)
+		if err != nil {
+			t.Fatalf("ClaimNextRunnable: %v", err)
+		}
+		if got == nil || got.ID != want {
+			t.Fatalf("ClaimNextRunnable: expected %v got %v", want, got)
+		}
+	}
+	if got, err := repo.ClaimNextRunnable(dbc, 3, time.Hour, time.Hour); err != nil || got != nil {
+		t.Fatalf("deferred job claimed before not_before: %v %v", got, err)
+	}
+}
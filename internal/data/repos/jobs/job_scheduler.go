@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// JobSchedulerRepo backs fair scheduling of a job class (job_run.sched_class) across users.
+// Claims are serialized per class with a transaction-scoped advisory lock, so callers run
+// Lock, Snapshot and MarkClaimed inside one transaction.
+type JobSchedulerRepo interface {
+	Lock(dbc dbctx.Context, class string) error
+	Snapshot(dbc dbctx.Context, class string, opts JobSchedSnapshotOptions) (*JobSchedSnapshot, error)
+	// MarkClaimed moves a still-runnable job to running and charges its owner one claim.
+	// It reports false when the job was canceled or finished meanwhile.
+	MarkClaimed(dbc dbctx.Context, class string, job *types.JobRun, floorVirtualTime float64, now time.Time) (bool, error)
+	MarkReleased(dbc dbctx.Context, class string, ownerUserID uuid.UUID) error
+}
+
+type JobSchedSnapshotOptions struct {
+	Now time.Time
+	// RunningFreshAfter: running jobs whose heartbeat is older are treated as dead and runnable.
+	RunningFreshAfter time.Time
+	// LiveAfter: queued jobs untouched since are left out of the heads (their workflow is not
+	// polling); they rejoin as soon as they tick again.
+	LiveAfter time.Time
+	// HeadsPerUser bounds the oldest runnable jobs returned per user.
+	HeadsPerUser int
+}
+
+type JobSchedRunning struct {
+	OwnerUserID uuid.UUID  `json:"owner_user_id"`
+	PathID      *uuid.UUID `json:"path_id,omitempty"`
+	Count       int        `json:"count"`
+}
+
+type JobSchedDepth struct {
+	OwnerUserID uuid.UUID `json:"owner_user_id"`
+	Count       int       `json:"count"`
+}
+
+type JobSchedSnapshot struct {
+	Running []JobSchedRunning
+	// Heads are each user's oldest runnable jobs, ordered by user then age.
+	Heads  []*types.JobRun
+	Depths []JobSchedDepth
+	States []*types.JobSchedulerState
+}
+
+type jobSchedulerRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewJobSchedulerRepo(db *gorm.DB, baseLog *logger.Logger) JobSchedulerRepo {
+	return &jobSchedulerRepo{db: db, log: baseLog.With("repo", "JobSchedulerRepo")}
+}
+
+func (r *jobSchedulerRepo) Lock(dbc dbctx.Context, class string) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	return t.WithContext(dbc.Ctx).Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, "job_sched:"+class).Error
+}
+
+const jobSchedRunnableWhere = `
+  sched_class = ?
+  AND deleted_at IS NULL
+  AND (not_before IS NULL OR not_before <= ?)
+  AND (
+    (status = 'queued' AND updated_at >= ?)
+    OR (status = 'running' AND (heartbeat_at IS NULL OR heartbeat_at < ?))
+  )`
+
+func (r *jobSchedulerRepo) Snapshot(dbc dbctx.Context, class string, opts JobSchedSnapshotOptions) (*JobSchedSnapshot, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now().UTC()
+	}
+	if opts.HeadsPerUser <= 0 {
+		opts.HeadsPerUser = 8
+	}
+	out := &JobSchedSnapshot{}
+	db := t.WithContext(dbc.Ctx)
+
+	if err := db.Raw(`
+      SELECT owner_user_id, sched_path_id AS path_id, COUNT(*) AS count
+      FROM job_run
+      WHERE sched_class = ? AND deleted_at IS NULL
+        AND status = 'running' AND heartbeat_at >= ?
+      GROUP BY owner_user_id, sched_path_id
+    `, class, opts.RunningFreshAfter).Scan(&out.Running).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(`
+      SELECT * FROM (
+        SELECT job_run.*, row_number() OVER (PARTITION BY owner_user_id ORDER BY created_at, id) AS sched_rn
+        FROM job_run
+        WHERE `+jobSchedRunnableWhere+`
+      ) heads
+      WHERE sched_rn <= ?
+      ORDER BY owner_user_id, sched_rn
+    `, class, opts.Now, opts.LiveAfter, opts.RunningFreshAfter, opts.HeadsPerUser).Scan(&out.Heads).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(`
+      SELECT owner_user_id, COUNT(*) AS count
+      FROM job_run
+      WHERE `+jobSchedRunnableWhere+`
+      GROUP BY owner_user_id
+    `, class, opts.Now, opts.LiveAfter, opts.RunningFreshAfter).Scan(&out.Depths).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("sched_class = ?", class).Find(&out.States).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *jobSchedulerRepo) MarkClaimed(dbc dbctx.Context, class string, job *types.JobRun, floorVirtualTime float64, now time.Time) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if job == nil || job.ID == uuid.Nil {
+		return false, nil
+	}
+	claimed := false
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&types.JobRun{}).
+			Where("id = ? AND status IN ?", job.ID, []string{"queued", "running"}).
+			Updates(map[string]any{
+				"status":       "running",
+				"locked_at":    now,
+				"heartbeat_at": now,
+				"updated_at":   now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		claimed = true
+		return tx.Exec(`
+          INSERT INTO job_scheduler_state (sched_class, owner_user_id, weight, virtual_time, in_flight, claimed_total, completed_total, updated_at)
+          VALUES (?, ?, 1, ? + 1, 1, 1, 0, ?)
+          ON CONFLICT (sched_class, owner_user_id) DO UPDATE SET
+            virtual_time  = GREATEST(job_scheduler_state.virtual_time, ?) + 1.0 / GREATEST(job_scheduler_state.weight, 0.01),
+            in_flight     = job_scheduler_state.in_flight + 1,
+            claimed_total = job_scheduler_state.claimed_total + 1,
+            updated_at    = EXCLUDED.updated_at
+        `, class, job.OwnerUserID, floorVirtualTime, now, floorVirtualTime).Error
+	})
+	return claimed, err
+}
+
+func (r *jobSchedulerRepo) MarkReleased(dbc dbctx.Context, class string, ownerUserID uuid.UUID) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	return t.WithContext(dbc.Ctx).Exec(`
+      UPDATE job_scheduler_state
+      SET in_flight = GREATEST(in_flight - 1, 0),
+          completed_total = completed_total + 1,
+          updated_at = now()
+      WHERE sched_class = ? AND owner_user_id = ?
+    `, class, ownerUserID).Error
+}
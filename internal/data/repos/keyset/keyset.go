@@ -0,0 +1,36 @@
+// Package keyset holds the shared keyset-pagination helpers for list queries ordered by
+// (timestamp column, id).
+package keyset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cursor is the position of the last row of a page in (time column, id) order. The id breaks
+// ties between rows with the same timestamp, so the order is total.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorFor is the cursor that resumes after a row.
+func CursorFor(at time.Time, id uuid.UUID) *Cursor {
+	return &Cursor{CreatedAt: at, ID: id}
+}
+
+// Page orders q by (col, id) in the given direction and, when after is set, keeps only rows
+// past it. col must be a trusted column name, never user input.
+func Page(q *gorm.DB, col string, desc bool, after *Cursor) *gorm.DB {
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	if after != nil {
+		q = q.Where(fmt.Sprintf("(%s, id) %s (?, ?)", col, cmp), after.CreatedAt, after.ID)
+	}
+	return q.Order(fmt.Sprintf("%s %s, id %s", col, dir, dir))
+}
@@ -19,6 +19,7 @@ type ActivityVariantRepo interface {
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.ActivityVariant, error)
 
 	GetByActivityIDs(dbc dbctx.Context, activityIDs []uuid.UUID) ([]*types.ActivityVariant, error)
+	CountByActivityIDs(dbc dbctx.Context, activityIDs []uuid.UUID) (map[uuid.UUID]int, error)
 	GetByActivityAndVariants(dbc dbctx.Context, activityID uuid.UUID, variants []string) ([]*types.ActivityVariant, error)
 	GetByActivityAndVariant(dbc dbctx.Context, activityID uuid.UUID, variant string) (*types.ActivityVariant, error)
 
@@ -106,6 +107,33 @@ func (r *activityVariantRepo) GetByActivityIDs(dbc dbctx.Context, activityIDs []
 	return out, nil
 }
 
+func (r *activityVariantRepo) CountByActivityIDs(dbc dbctx.Context, activityIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := map[uuid.UUID]int{}
+	if len(activityIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ActivityID uuid.UUID `gorm:"column:activity_id"`
+		N          int       `gorm:"column:n"`
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Model(&types.ActivityVariant{}).
+		Select("activity_id, COUNT(*) AS n").
+		Where("activity_id IN ?", activityIDs).
+		Group("activity_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.ActivityID] = row.N
+	}
+	return out, nil
+}
+
 func (r *activityVariantRepo) GetByActivityAndVariants(dbc dbctx.Context, activityID uuid.UUID, variants []string) ([]*types.ActivityVariant, error) {
 	t := dbc.Tx
 	if t == nil {
@@ -1,10 +1,12 @@
 package learning
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
@@ -22,6 +24,15 @@ type ConceptRepo interface {
 	GetByScopeAndParent(dbc dbctx.Context, scope string, scopeID *uuid.UUID, parentID *uuid.UUID) ([]*types.Concept, error)
 	GetByParentIDs(dbc dbctx.Context, parentIDs []uuid.UUID) ([]*types.Concept, error)
 	GetByVectorIDs(dbc dbctx.Context, vectorIDs []string) ([]*types.Concept, error)
+	// ListByScopeChangedSince returns concepts of the scope created, updated or soft-deleted after
+	// since, deleted rows included (DeletedAt set) so callers can emit tombstones.
+	ListByScopeChangedSince(dbc dbctx.Context, scope string, scopeID *uuid.UUID, since time.Time) ([]*types.Concept, error)
+	// GetGlobalByNaturalKeys matches live global concepts on lower(trim(key)), oldest first.
+	GetGlobalByNaturalKeys(dbc dbctx.Context, keys []string) ([]*types.Concept, error)
+
+	// CreateGlobalIfAbsent inserts global concepts with ON CONFLICT DO NOTHING and returns the rows
+	// that now own those natural keys (ours, or a concurrent writer's).
+	CreateGlobalIfAbsent(dbc dbctx.Context, rows []*types.Concept) ([]*types.Concept, error)
 
 	UpsertByScopeAndKey(dbc dbctx.Context, row *types.Concept) error
 	Update(dbc dbctx.Context, row *types.Concept) error
@@ -176,6 +187,85 @@ func (r *conceptRepo) GetByVectorIDs(dbc dbctx.Context, vectorIDs []string) ([]*
 	return out, nil
 }
 
+// ConceptNaturalKey is the normalized key global concepts are unique on.
+func ConceptNaturalKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func (r *conceptRepo) ListByScopeChangedSince(dbc dbctx.Context, scope string, scopeID *uuid.UUID, since time.Time) ([]*types.Concept, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.Concept
+	if scope == "" {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Unscoped().
+		Where("scope = ? AND scope_id IS NOT DISTINCT FROM ?", scope, scopeID).
+		Where("updated_at > ? OR deleted_at > ?", since, since).
+		Order("updated_at ASC, id ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *conceptRepo) GetGlobalByNaturalKeys(dbc dbctx.Context, keys []string) ([]*types.Concept, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.Concept
+	norm := make([]string, 0, len(keys))
+	seen := map[string]bool{}
+	for _, k := range keys {
+		k = ConceptNaturalKey(k)
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		norm = append(norm, k)
+	}
+	if len(norm) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("scope = ? AND scope_id IS NULL AND lower(btrim(key)) IN ?", "global", norm).
+		Order("created_at ASC, id ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *conceptRepo) CreateGlobalIfAbsent(dbc dbctx.Context, rows []*types.Concept) ([]*types.Concept, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	valid := make([]*types.Concept, 0, len(rows))
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row == nil || row.Scope != "global" || row.ScopeID != nil || ConceptNaturalKey(row.Key) == "" {
+			continue
+		}
+		valid = append(valid, row)
+		keys = append(keys, row.Key)
+	}
+	if len(valid) == 0 {
+		return []*types.Concept{}, nil
+	}
+	// Target-less so it covers whichever unique index the install has (exact or natural key).
+	if err := t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&valid).Error; err != nil {
+		return nil, err
+	}
+	return r.GetGlobalByNaturalKeys(dbc, keys)
+}
+
 func (r *conceptRepo) UpsertByScopeAndKey(dbc dbctx.Context, row *types.Concept) error {
 	t := dbc.Tx
 	if t == nil {
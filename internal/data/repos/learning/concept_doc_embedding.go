@@ -0,0 +1,76 @@
+package learning
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type ConceptDocEmbeddingRepo interface {
+	GetByHashes(dbc dbctx.Context, model string, hashes []string) ([]*types.ConceptDocEmbedding, error)
+	// CreateIgnoreDuplicates stores new vectors; an existing (doc_hash, model) row wins since the
+	// same text under the same model embeds to the same vector.
+	CreateIgnoreDuplicates(dbc dbctx.Context, rows []*types.ConceptDocEmbedding) error
+}
+
+type conceptDocEmbeddingRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewConceptDocEmbeddingRepo(db *gorm.DB, baseLog *logger.Logger) ConceptDocEmbeddingRepo {
+	return &conceptDocEmbeddingRepo{db: db, log: baseLog.With("repo", "ConceptDocEmbeddingRepo")}
+}
+
+func (r *conceptDocEmbeddingRepo) GetByHashes(dbc dbctx.Context, model string, hashes []string) ([]*types.ConceptDocEmbedding, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.ConceptDocEmbedding{}
+	model = strings.TrimSpace(model)
+	if model == "" || len(hashes) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("model = ? AND doc_hash IN ?", model, hashes).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *conceptDocEmbeddingRepo) CreateIgnoreDuplicates(dbc dbctx.Context, rows []*types.ConceptDocEmbedding) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if row.ID == uuid.Nil {
+			row.ID = uuid.New()
+		}
+		if row.CreatedAt.IsZero() {
+			row.CreatedAt = now
+		}
+	}
+	return t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "doc_hash"}, {Name: "model"}},
+			DoNothing: true,
+		}).
+		CreateInBatches(rows, 200).Error
+}
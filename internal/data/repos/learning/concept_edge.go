@@ -1,9 +1,12 @@
 package learning
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
@@ -17,10 +20,14 @@ type ConceptEdgeRepo interface {
 	CreateIgnoreDuplicates(dbc dbctx.Context, rows []*types.ConceptEdge) (int, error)
 
 	GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.ConceptEdge, error)
+	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.ConceptEdge, error)
 
 	GetByFromConceptIDs(dbc dbctx.Context, fromIDs []uuid.UUID) ([]*types.ConceptEdge, error)
 	GetByToConceptIDs(dbc dbctx.Context, toIDs []uuid.UUID) ([]*types.ConceptEdge, error)
 	GetByConceptIDs(dbc dbctx.Context, conceptIDs []uuid.UUID) ([]*types.ConceptEdge, error)
+	// ListByConceptScopeChangedSince returns edges between two concepts of the scope (live or
+	// deleted) created, updated or soft-deleted after since, deleted rows included.
+	ListByConceptScopeChangedSince(dbc dbctx.Context, scope string, scopeID *uuid.UUID, since time.Time) ([]*types.ConceptEdge, error)
 
 	Upsert(dbc dbctx.Context, row *types.ConceptEdge) error
 
@@ -88,6 +95,20 @@ func (r *conceptEdgeRepo) GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types
 	return out, nil
 }
 
+func (r *conceptEdgeRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.ConceptEdge, error) {
+	if id == uuid.Nil {
+		return nil, nil
+	}
+	rows, err := r.GetByIDs(dbc, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
 func (r *conceptEdgeRepo) GetByFromConceptIDs(dbc dbctx.Context, fromIDs []uuid.UUID) ([]*types.ConceptEdge, error) {
 	t := dbc.Tx
 	if t == nil {
@@ -143,6 +164,30 @@ func (r *conceptEdgeRepo) GetByConceptIDs(dbc dbctx.Context, conceptIDs []uuid.U
 	return out, nil
 }
 
+func (r *conceptEdgeRepo) ListByConceptScopeChangedSince(dbc dbctx.Context, scope string, scopeID *uuid.UUID, since time.Time) ([]*types.ConceptEdge, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.ConceptEdge
+	if scope == "" {
+		return out, nil
+	}
+	scoped := t.Session(&gorm.Session{NewDB: true}).
+		Table("concept").
+		Select("id").
+		Where("scope = ? AND scope_id IS NOT DISTINCT FROM ?", scope, scopeID)
+	if err := t.WithContext(dbc.Ctx).
+		Unscoped().
+		Where("from_concept_id IN (?) AND to_concept_id IN (?)", scoped, scoped).
+		Where("updated_at > ? OR deleted_at > ?", since, since).
+		Order("updated_at ASC, id ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *conceptEdgeRepo) Upsert(dbc dbctx.Context, row *types.ConceptEdge) error {
 	t := dbc.Tx
 	if t == nil {
@@ -215,3 +260,39 @@ func (r *conceptEdgeRepo) FullDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) er
 	}
 	return t.WithContext(dbc.Ctx).Unscoped().Where("id IN ?", ids).Delete(&types.ConceptEdge{}).Error
 }
+
+// ConceptEdgeEvidence is the typed form of ConceptEdge.Evidence as written by the concept graph
+// build: the model's rationale for the edge and the material chunk IDs it cited.
+type ConceptEdgeEvidence struct {
+	Rationale string   `json:"rationale"`
+	Citations []string `json:"citations"`
+}
+
+// ChunkIDs returns the citations that parse as chunk IDs, deduped in order.
+func (e ConceptEdgeEvidence) ChunkIDs() []uuid.UUID {
+	out := make([]uuid.UUID, 0, len(e.Citations))
+	seen := map[uuid.UUID]bool{}
+	for _, s := range e.Citations {
+		id, err := uuid.Parse(strings.TrimSpace(s))
+		if err != nil || id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+// ParseConceptEdgeEvidence decodes an edge's evidence JSON. Edges written without evidence
+// (empty or null) parse to the zero value.
+func ParseConceptEdgeEvidence(raw datatypes.JSON) (ConceptEdgeEvidence, error) {
+	var out ConceptEdgeEvidence
+	if len(raw) == 0 || strings.TrimSpace(string(raw)) == "null" {
+		return out, nil
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return ConceptEdgeEvidence{}, err
+	}
+	out.Rationale = strings.TrimSpace(out.Rationale)
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package learning
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+func TestParseConceptEdgeEvidence(t *testing.T) {
+	chunk := uuid.New()
+	raw := datatypes.JSON(`{"rationale":"  Loops are needed before recursion. ","citations":["` + chunk.String() + `","not-a-uuid","` + chunk.String() + `"]}`)
+	ev, err := ParseConceptEdgeEvidence(raw)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if ev.Rationale != "Loops are needed before recursion." {
+		t.Fatalf("unexpected rationale: %q", ev.Rationale)
+	}
+	if ids := ev.ChunkIDs(); len(ids) != 1 || ids[0] != chunk {
+		t.Fatalf("unexpected chunk ids: %v", ids)
+	}
+
+	for _, empty := range []datatypes.JSON{nil, datatypes.JSON(`null`)} {
+		ev, err := ParseConceptEdgeEvidence(empty)
+		if err != nil || ev.Rationale != "" || len(ev.ChunkIDs()) != 0 {
+			t.Fatalf("expected zero evidence for %q, got %+v (%v)", empty, ev, err)
+		}
+	}
+	if _, err := ParseConceptEdgeEvidence(datatypes.JSON(`[1,2]`)); err == nil {
+		t.Fatalf("expected error for non-object evidence")
+	}
+}
@@ -21,6 +21,11 @@ type ConceptEvidenceRepo interface {
 	GetByMaterialChunkIDs(dbc dbctx.Context, chunkIDs []uuid.UUID) ([]*types.ConceptEvidence, error)
 
 	Upsert(dbc dbctx.Context, row *types.ConceptEvidence) error
+	// MarkSourceRemovedByMaterialChunkIDs sets status source_removed on evidence citing the chunks.
+	MarkSourceRemovedByMaterialChunkIDs(dbc dbctx.Context, chunkIDs []uuid.UUID) (int64, error)
+	// RelinkMaterialChunks repoints evidence from each old chunk to its replacement and clears
+	// the removed status. Rows whose concept already cites the replacement are left alone.
+	RelinkMaterialChunks(dbc dbctx.Context, relink map[uuid.UUID]uuid.UUID) (int64, error)
 	SoftDeleteByConceptIDs(dbc dbctx.Context, conceptIDs []uuid.UUID) error
 	SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
 	FullDeleteByConceptIDs(dbc dbctx.Context, conceptIDs []uuid.UUID) error
@@ -157,6 +162,46 @@ func (r *conceptEvidenceRepo) SoftDeleteByConceptIDs(dbc dbctx.Context, conceptI
 	return t.WithContext(dbc.Ctx).Where("concept_id IN ?", conceptIDs).Delete(&types.ConceptEvidence{}).Error
 }
 
+func (r *conceptEvidenceRepo) MarkSourceRemovedByMaterialChunkIDs(dbc dbctx.Context, chunkIDs []uuid.UUID) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if len(chunkIDs) == 0 {
+		return 0, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.ConceptEvidence{}).
+		Where("material_chunk_id IN ?", chunkIDs).
+		Updates(map[string]any{"status": types.ConceptEvidenceSourceRemoved, "updated_at": time.Now().UTC()})
+	return res.RowsAffected, res.Error
+}
+
+func (r *conceptEvidenceRepo) RelinkMaterialChunks(dbc dbctx.Context, relink map[uuid.UUID]uuid.UUID) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var total int64
+	now := time.Now().UTC()
+	for from, to := range relink {
+		if from == uuid.Nil || to == uuid.Nil {
+			continue
+		}
+		// The (concept_id, material_chunk_id) index is unique, soft-deleted rows included.
+		res := t.WithContext(dbc.Ctx).
+			Model(&types.ConceptEvidence{}).
+			Where("material_chunk_id = ?", from).
+			Where("concept_id NOT IN (SELECT concept_id FROM concept_evidence WHERE material_chunk_id = ?)", to).
+			Updates(map[string]any{"material_chunk_id": to, "status": "", "updated_at": now})
+		if res.Error != nil {
+			return total, res.Error
+		}
+		total += res.RowsAffected
+	}
+	return total, nil
+}
+
 func (r *conceptEvidenceRepo) SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error {
 	t := dbc.Tx
 	if t == nil {
@@ -0,0 +1,80 @@
+package learning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestConceptGraphChangedSince(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	concepts := NewConceptRepo(db, testutil.Logger(t))
+	edges := NewConceptEdgeRepo(db, testutil.Logger(t))
+
+	pathID, otherPath := uuid.New(), uuid.New()
+	old := time.Now().UTC().Add(-time.Hour)
+	since := old.Add(30 * time.Minute)
+	mk := func(scopeID uuid.UUID, key string, updated time.Time) *types.Concept {
+		return &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &scopeID, Key: key, Name: key, CreatedAt: old, UpdatedAt: updated}
+	}
+	stale, fresh, gone, foreign := mk(pathID, "stale", old), mk(pathID, "fresh", time.Now().UTC()), mk(pathID, "gone", old), mk(otherPath, "foreign", time.Now().UTC())
+	if err := tx.Create([]*types.Concept{stale, fresh, gone, foreign}).Error; err != nil {
+		t.Fatalf("seed concepts: %v", err)
+	}
+	edgeRows := []*types.ConceptEdge{
+		{ID: uuid.New(), FromConceptID: stale.ID, ToConceptID: fresh.ID, EdgeType: "prereq", CreatedAt: old, UpdatedAt: time.Now().UTC()},
+		{ID: uuid.New(), FromConceptID: stale.ID, ToConceptID: gone.ID, EdgeType: "prereq", CreatedAt: old, UpdatedAt: old},
+		{ID: uuid.New(), FromConceptID: fresh.ID, ToConceptID: foreign.ID, EdgeType: "prereq", CreatedAt: old, UpdatedAt: time.Now().UTC()},
+	}
+	if err := tx.Create(edgeRows).Error; err != nil {
+		t.Fatalf("seed edges: %v", err)
+	}
+	if err := concepts.SoftDeleteByIDs(dbc, []uuid.UUID{gone.ID}); err != nil {
+		t.Fatalf("delete concept: %v", err)
+	}
+	if err := edges.SoftDeleteByIDs(dbc, []uuid.UUID{edgeRows[1].ID}); err != nil {
+		t.Fatalf("delete edge: %v", err)
+	}
+
+	gotConcepts, err := concepts.ListByScopeChangedSince(dbc, "path", &pathID, since)
+	if err != nil {
+		t.Fatalf("concept delta: %v", err)
+	}
+	byID := map[uuid.UUID]*types.Concept{}
+	for _, cc := range gotConcepts {
+		byID[cc.ID] = cc
+	}
+	if len(gotConcepts) != 2 || byID[fresh.ID] == nil || byID[gone.ID] == nil || !byID[gone.ID].DeletedAt.Valid {
+		t.Fatalf("concept delta = %+v", gotConcepts)
+	}
+
+	gotEdges, err := edges.ListByConceptScopeChangedSince(dbc, "path", &pathID, since)
+	if err != nil {
+		t.Fatalf("edge delta: %v", err)
+	}
+	if len(gotEdges) != 2 {
+		t.Fatalf("edge delta has %d rows, want 2 (cross-path edge excluded)", len(gotEdges))
+	}
+	deleted := 0
+	for _, e := range gotEdges {
+		if e.ID == edgeRows[2].ID {
+			t.Fatalf("cross-path edge in delta")
+		}
+		if e.DeletedAt.Valid {
+			deleted++
+		}
+	}
+	if deleted != 1 {
+		t.Fatalf("edge tombstones = %d, want 1", deleted)
+	}
+}
@@ -0,0 +1,58 @@
+package learning
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type DocConsistencyAuditRunRepo interface {
+	Create(dbc dbctx.Context, row *types.DocConsistencyAuditRun) error
+	GetLatest(dbc dbctx.Context) (*types.DocConsistencyAuditRun, error)
+}
+
+type docConsistencyAuditRunRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewDocConsistencyAuditRunRepo(db *gorm.DB, baseLog *logger.Logger) DocConsistencyAuditRunRepo {
+	return &docConsistencyAuditRunRepo{db: db, log: baseLog.With("repo", "DocConsistencyAuditRunRepo")}
+}
+
+func (r *docConsistencyAuditRunRepo) Create(dbc dbctx.Context, row *types.DocConsistencyAuditRun) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil {
+		return nil
+	}
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now().UTC()
+	}
+	return t.WithContext(dbc.Ctx).Create(row).Error
+}
+
+func (r *docConsistencyAuditRunRepo) GetLatest(dbc dbctx.Context) (*types.DocConsistencyAuditRun, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out types.DocConsistencyAuditRun
+	if err := t.WithContext(dbc.Ctx).Order("created_at DESC, id DESC").First(&out).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
@@ -11,6 +11,7 @@ import (
 
 type LearningDocGenerationRunRepo interface {
 	Create(dbc dbctx.Context, rows []*types.LearningDocGenerationRun) ([]*types.LearningDocGenerationRun, error)
+	GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.LearningDocGenerationRun, error)
 }
 
 type learningDocGenerationRunRepo struct {
@@ -41,3 +42,20 @@ func (r *learningDocGenerationRunRepo) Create(dbc dbctx.Context, rows []*types.L
 	}
 	return rows, nil
 }
+
+func (r *learningDocGenerationRunRepo) GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.LearningDocGenerationRun, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.LearningDocGenerationRun
+	if len(ids) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("id IN ?", ids).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
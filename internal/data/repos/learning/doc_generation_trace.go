@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm/clause"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 )
@@ -99,6 +100,14 @@ func (r *docGenerationTraceRepo) Upsert(dbc dbctx.Context, row *types.DocGenerat
 	if row.CreatedAt.IsZero() {
 		row.CreatedAt = time.Now().UTC()
 	}
+	if td := ctxutil.GetTraceData(dbc.Ctx); td != nil && row.RequestTraceID == "" {
+		row.RequestTraceID = td.TraceID
+		row.RequestID = td.RequestID
+		if td.JobID != uuid.Nil {
+			jobID := td.JobID
+			row.JobID = &jobID
+		}
+	}
 
 	return t.WithContext(dbc.Ctx).
 		Clauses(clause.OnConflict{
@@ -114,6 +123,9 @@ func (r *docGenerationTraceRepo) Upsert(dbc dbctx.Context, row *types.DocGenerat
 				"retrieval_pack_id",
 				"blueprint_version",
 				"trace_json",
+				"request_trace_id",
+				"request_id",
+				"job_id",
 			}),
 		}).
 		Create(row).Error
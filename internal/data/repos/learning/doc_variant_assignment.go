@@ -0,0 +1,180 @@
+package learning
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	DocVariantArmTreatment = "treatment"
+	DocVariantArmHoldback  = "holdback"
+
+	DocVariantAssignmentSourceHash     = "hash"
+	DocVariantAssignmentSourceBackfill = "exposure_backfill"
+)
+
+type DocVariantArmCount struct {
+	Arm   string `json:"arm"`
+	Users int64  `json:"users"`
+}
+
+type DocVariantAssignmentRepo interface {
+	Get(dbc dbctx.Context, userID uuid.UUID, policyVersion string) (*types.DocVariantAssignment, error)
+	// Assign stores row unless the user already has an arm for its policy version and returns the
+	// stored row; concurrent first requests all converge on the first writer's arm.
+	Assign(dbc dbctx.Context, row *types.DocVariantAssignment) (*types.DocVariantAssignment, bool, error)
+	// BackfillFromExposures pins every user who was exposed under exposurePolicyVersion before
+	// assignments existed to the arm their newest exposure recorded, stored under policyVersion.
+	// Users with any assignment are skipped, so a policy version bump re-randomizes instead of
+	// replaying, and reruns are no-ops. It returns the number of assignments written.
+	BackfillFromExposures(dbc dbctx.Context, policyVersion string, exposurePolicyVersion string) (int64, error)
+	// CountArms reports users per arm for a policy version (experiment balance checks).
+	CountArms(dbc dbctx.Context, policyVersion string) ([]DocVariantArmCount, error)
+}
+
+type docVariantAssignmentRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewDocVariantAssignmentRepo(db *gorm.DB, baseLog *logger.Logger) DocVariantAssignmentRepo {
+	return &docVariantAssignmentRepo{db: db, log: baseLog.With("repo", "DocVariantAssignmentRepo")}
+}
+
+func (r *docVariantAssignmentRepo) Get(dbc dbctx.Context, userID uuid.UUID, policyVersion string) (*types.DocVariantAssignment, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	policyVersion = strings.TrimSpace(policyVersion)
+	if userID == uuid.Nil || policyVersion == "" {
+		return nil, nil
+	}
+	var row types.DocVariantAssignment
+	err := t.WithContext(dbc.Ctx).
+		Where("user_id = ? AND policy_version = ?", userID, policyVersion).
+		Limit(1).
+		Find(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	if row.ID == uuid.Nil {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (r *docVariantAssignmentRepo) Assign(dbc dbctx.Context, row *types.DocVariantAssignment) (*types.DocVariantAssignment, bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil || row.UserID == uuid.Nil || strings.TrimSpace(row.PolicyVersion) == "" {
+		return nil, false, errors.New("doc variant assignment: missing user_id or policy_version")
+	}
+	row.PolicyVersion = strings.TrimSpace(row.PolicyVersion)
+	if row.Arm != DocVariantArmTreatment && row.Arm != DocVariantArmHoldback {
+		return nil, false, errors.New("doc variant assignment: invalid arm " + strconv.Quote(row.Arm))
+	}
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	if row.AssignedAt.IsZero() {
+		row.AssignedAt = now
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	if strings.TrimSpace(row.Source) == "" {
+		row.Source = DocVariantAssignmentSourceHash
+	}
+
+	res := t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "policy_version"}},
+			DoNothing: true,
+		}).
+		Create(row)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return row, true, nil
+	}
+	// Lost the race (or already assigned): the stored arm wins.
+	existing, err := r.Get(dbc, row.UserID, row.PolicyVersion)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		return nil, false, errors.New("doc variant assignment: conflict without stored row")
+	}
+	return existing, false, nil
+}
+
+func (r *docVariantAssignmentRepo) BackfillFromExposures(dbc dbctx.Context, policyVersion string, exposurePolicyVersion string) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	policyVersion = strings.TrimSpace(policyVersion)
+	exposurePolicyVersion = strings.TrimSpace(exposurePolicyVersion)
+	if policyVersion == "" || exposurePolicyVersion == "" {
+		return 0, nil
+	}
+	res := t.WithContext(dbc.Ctx).Exec(`
+		INSERT INTO doc_variant_assignment (id, user_id, policy_version, arm, rollout_pct, source, assigned_at, created_at)
+		SELECT uuid_generate_v4(), e.user_id, ?,
+		       CASE WHEN lower(btrim(e.metadata->>'rollout_eligible')) = 'true' THEN ? ELSE ? END,
+		       CASE WHEN btrim(e.metadata->>'rollout_pct') ~ '^[0-9]+([.][0-9]+){0,1}$'
+		            THEN (btrim(e.metadata->>'rollout_pct'))::double precision ELSE 0 END,
+		       ?, e.created_at, now()
+		FROM (
+			SELECT DISTINCT ON (user_id) user_id, metadata, created_at
+			FROM doc_variant_exposure
+			WHERE policy_version = ?
+			  AND metadata->>'rollout_eligible' IS NOT NULL
+			ORDER BY user_id, created_at DESC
+		) e
+		WHERE NOT EXISTS (SELECT 1 FROM doc_variant_assignment a WHERE a.user_id = e.user_id)
+		ON CONFLICT (user_id, policy_version) DO NOTHING
+	`, policyVersion, DocVariantArmTreatment, DocVariantArmHoldback, DocVariantAssignmentSourceBackfill, exposurePolicyVersion)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
+
+func (r *docVariantAssignmentRepo) CountArms(dbc dbctx.Context, policyVersion string) ([]DocVariantArmCount, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []DocVariantArmCount{}
+	policyVersion = strings.TrimSpace(policyVersion)
+	if policyVersion == "" {
+		return out, nil
+	}
+	err := t.WithContext(dbc.Ctx).
+		Model(&types.DocVariantAssignment{}).
+		Select("arm, COUNT(*) AS users").
+		Where("policy_version = ?", policyVersion).
+		Group("arm").
+		Order("arm ASC").
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,184 @@
+package learning
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestDocVariantAssignmentRepo(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewDocVariantAssignmentRepo(db, testutil.Logger(t))
+
+	userID := uuid.New()
+	policy := "doc_variant_policy_test_" + uuid.NewString()
+
+	if got, err := repo.Get(dbc, userID, policy); err != nil || got != nil {
+		t.Fatalf("Get(missing): got=%+v err=%v", got, err)
+	}
+	row, created, err := repo.Assign(dbc, &types.DocVariantAssignment{UserID: userID, PolicyVersion: policy, Arm: DocVariantArmHoldback, RolloutPct: 0.1})
+	if err != nil || !created || row.Arm != DocVariantArmHoldback || row.Source != DocVariantAssignmentSourceHash {
+		t.Fatalf("Assign(first): row=%+v created=%v err=%v", row, created, err)
+	}
+
+	// Raising the rollout pct later must not move an assigned user.
+	row, created, err = repo.Assign(dbc, &types.DocVariantAssignment{UserID: userID, PolicyVersion: policy, Arm: DocVariantArmTreatment, RolloutPct: 1})
+	if err != nil || created || row.Arm != DocVariantArmHoldback || row.RolloutPct != 0.1 {
+		t.Fatalf("Assign(after pct change): row=%+v created=%v err=%v", row, created, err)
+	}
+
+	// A new policy version is a new experiment.
+	next := policy + "_v2"
+	if row, created, err := repo.Assign(dbc, &types.DocVariantAssignment{UserID: userID, PolicyVersion: next, Arm: DocVariantArmTreatment, RolloutPct: 1}); err != nil || !created || row.Arm != DocVariantArmTreatment {
+		t.Fatalf("Assign(new policy): row=%+v created=%v err=%v", row, created, err)
+	}
+
+	if _, _, err := repo.Assign(dbc, &types.DocVariantAssignment{UserID: userID, PolicyVersion: policy, Arm: "both"}); err == nil {
+		t.Fatalf("Assign(invalid arm) should fail")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := repo.Assign(dbc, &types.DocVariantAssignment{UserID: uuid.New(), PolicyVersion: policy, Arm: DocVariantArmTreatment}); err != nil {
+			t.Fatalf("Assign(other user): %v", err)
+		}
+	}
+	counts, err := repo.CountArms(dbc, policy)
+	if err != nil {
+		t.Fatalf("CountArms: %v", err)
+	}
+	want := map[string]int64{DocVariantArmHoldback: 1, DocVariantArmTreatment: 3}
+	if len(counts) != len(want) {
+		t.Fatalf("CountArms: %+v", counts)
+	}
+	for _, c := range counts {
+		if want[c.Arm] != c.Users {
+			t.Fatalf("CountArms: %+v", counts)
+		}
+	}
+}
+
+func TestDocVariantAssignmentRepoBackfillFromExposures(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewDocVariantAssignmentRepo(db, testutil.Logger(t))
+	exposures := NewDocVariantExposureRepo(db, testutil.Logger(t))
+
+	policy := "doc_variant_policy_test_" + uuid.NewString()
+	exposureVersion := "doc_policy_test_" + uuid.NewString()
+	base := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Microsecond)
+	expose := func(userID uuid.UUID, version string, at time.Time, meta string) {
+		t.Helper()
+		row := &types.DocVariantExposure{UserID: userID, PathID: uuid.New(), PathNodeID: uuid.New(), PolicyVersion: version, Metadata: datatypes.JSON(meta), CreatedAt: at}
+		if err := exposures.Create(dbc, row); err != nil {
+			t.Fatalf("Create exposure: %v", err)
+		}
+	}
+
+	// Newest exposure under the current version decides the arm.
+	current := uuid.New()
+	expose(current, exposureVersion, base, `{"rollout_eligible":false,"rollout_pct":0.1}`)
+	expose(current, exposureVersion, base.Add(time.Hour), `{"rollout_eligible":true,"rollout_pct":0.3}`)
+	// Exposed only under a previous policy version: left to the hash.
+	previous := uuid.New()
+	expose(previous, exposureVersion+"_old", base, `{"rollout_eligible":true,"rollout_pct":0.5}`)
+	// Already assigned (for any policy): a version bump re-randomizes.
+	assigned := uuid.New()
+	expose(assigned, exposureVersion, base, `{"rollout_eligible":true,"rollout_pct":0.5}`)
+	if _, _, err := repo.Assign(dbc, &types.DocVariantAssignment{UserID: assigned, PolicyVersion: policy + "_prev", Arm: DocVariantArmHoldback}); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	// No rollout decision was recorded.
+	undecided := uuid.New()
+	expose(undecided, exposureVersion, base, `{"policy_mode":"off"}`)
+
+	n, err := repo.BackfillFromExposures(dbc, policy, exposureVersion)
+	if err != nil || n != 1 {
+		t.Fatalf("BackfillFromExposures: n=%d err=%v", n, err)
+	}
+	row, err := repo.Get(dbc, current, policy)
+	if err != nil || row == nil {
+		t.Fatalf("Get(backfilled): row=%+v err=%v", row, err)
+	}
+	if row.Arm != DocVariantArmTreatment || row.RolloutPct != 0.3 || row.Source != DocVariantAssignmentSourceBackfill || !row.AssignedAt.Equal(base.Add(time.Hour)) {
+		t.Fatalf("backfilled row: %+v", row)
+	}
+	for _, userID := range []uuid.UUID{previous, assigned, undecided} {
+		if got, err := repo.Get(dbc, userID, policy); err != nil || got != nil {
+			t.Fatalf("Get(%s): got=%+v err=%v", userID, got, err)
+		}
+	}
+
+	if n, err := repo.BackfillFromExposures(dbc, policy, exposureVersion); err != nil || n != 0 {
+		t.Fatalf("BackfillFromExposures(rerun): n=%d err=%v", n, err)
+	}
+}
+
+func TestDocVariantAssignmentRepoConcurrentAssign(t *testing.T) {
+	db := testutil.DB(t)
+
+	ctx := context.Background()
+	repo := NewDocVariantAssignmentRepo(db, testutil.Logger(t))
+
+	userID := uuid.New()
+	policy := "doc_variant_policy_test_" + uuid.NewString()
+	t.Cleanup(func() {
+		_ = db.Where("policy_version = ?", policy).Delete(&types.DocVariantAssignment{}).Error
+	})
+
+	// Concurrent first requests propose different arms; all must observe the winner's.
+	const writers = 16
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		creates int
+		arms    = map[string]int{}
+	)
+	for i := 0; i < writers; i++ {
+		arm := DocVariantArmTreatment
+		if i%2 == 1 {
+			arm = DocVariantArmHoldback
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			row, created, err := repo.Assign(dbctx.Context{Ctx: ctx}, &types.DocVariantAssignment{UserID: userID, PolicyVersion: policy, Arm: arm, RolloutPct: 0.5})
+			if err != nil {
+				t.Errorf("Assign: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			arms[row.Arm]++
+			if created {
+				creates++
+			}
+		}()
+	}
+	wg.Wait()
+	if creates != 1 {
+		t.Fatalf("expected exactly one creator, got %d", creates)
+	}
+	if len(arms) != 1 {
+		t.Fatalf("writers disagree on the arm: %v", arms)
+	}
+
+	counts, err := repo.CountArms(dbctx.Context{Ctx: ctx}, policy)
+	if err != nil || len(counts) != 1 || counts[0].Users != 1 {
+		t.Fatalf("CountArms: counts=%+v err=%v", counts, err)
+	}
+}
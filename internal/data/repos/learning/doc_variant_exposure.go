@@ -14,7 +14,19 @@ import (
 
 type DocVariantExposureRepo interface {
 	Create(dbc dbctx.Context, row *types.DocVariantExposure) error
+	// CreateMany inserts rows in one multi-row statement, skipping rows without user/path/node ids.
+	CreateMany(dbc dbctx.Context, rows []*types.DocVariantExposure) error
 	ListUnevaluatedByUser(dbc dbctx.Context, userID uuid.UUID, pathID *uuid.UUID, cutoff time.Time, limit int) ([]*types.DocVariantExposure, error)
+	// ListServedReadersSince returns distinct (user, node) pairs that were served a variant since.
+	ListServedReadersSince(dbc dbctx.Context, since time.Time, limit int) ([]DocVariantReader, error)
+}
+
+// DocVariantReader is a user who was served a variant of a node doc.
+type DocVariantReader struct {
+	UserID     uuid.UUID
+	PathID     uuid.UUID
+	PathNodeID uuid.UUID
+	LastServed time.Time
 }
 
 type docVariantExposureRepo struct {
@@ -31,9 +43,34 @@ func (r *docVariantExposureRepo) Create(dbc dbctx.Context, row *types.DocVariant
 	if t == nil {
 		t = r.db
 	}
-	if row == nil || row.UserID == uuid.Nil || row.PathID == uuid.Nil || row.PathNodeID == uuid.Nil {
+	if !prepareDocVariantExposure(row) {
 		return nil
 	}
+	return t.WithContext(dbc.Ctx).Create(row).Error
+}
+
+func (r *docVariantExposureRepo) CreateMany(dbc dbctx.Context, rows []*types.DocVariantExposure) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	valid := make([]*types.DocVariantExposure, 0, len(rows))
+	for _, row := range rows {
+		if prepareDocVariantExposure(row) {
+			valid = append(valid, row)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return t.WithContext(dbc.Ctx).Create(&valid).Error
+}
+
+// prepareDocVariantExposure fills ids, timestamps, and label defaults; false means skip the row.
+func prepareDocVariantExposure(row *types.DocVariantExposure) bool {
+	if row == nil || row.UserID == uuid.Nil || row.PathID == uuid.Nil || row.PathNodeID == uuid.Nil {
+		return false
+	}
 	if row.ID == uuid.Nil {
 		row.ID = uuid.New()
 	}
@@ -56,7 +93,7 @@ func (r *docVariantExposureRepo) Create(dbc dbctx.Context, row *types.DocVariant
 	if row.Source == "" {
 		row.Source = "api"
 	}
-	return t.WithContext(dbc.Ctx).Create(row).Error
+	return true
 }
 
 func (r *docVariantExposureRepo) ListUnevaluatedByUser(dbc dbctx.Context, userID uuid.UUID, pathID *uuid.UUID, cutoff time.Time, limit int) ([]*types.DocVariantExposure, error) {
@@ -87,3 +124,26 @@ func (r *docVariantExposureRepo) ListUnevaluatedByUser(dbc dbctx.Context, userID
 	}
 	return out, nil
 }
+
+func (r *docVariantExposureRepo) ListServedReadersSince(dbc dbctx.Context, since time.Time, limit int) ([]DocVariantReader, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []DocVariantReader{}
+	if limit <= 0 {
+		limit = 1000
+	}
+	err := t.WithContext(dbc.Ctx).
+		Model(&types.DocVariantExposure{}).
+		Select("user_id, path_id, path_node_id, MAX(created_at) AS last_served").
+		Where("exposure_kind = ? AND created_at >= ?", "served", since).
+		Group("user_id, path_id, path_node_id").
+		Order("last_served DESC").
+		Limit(limit).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
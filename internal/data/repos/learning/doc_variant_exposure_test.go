@@ -0,0 +1,46 @@
+package learning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestDocVariantExposureRepoCreateMany(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	repo := NewDocVariantExposureRepo(db, testutil.Logger(t))
+
+	userID := uuid.New()
+	pathID := uuid.New()
+	rows := make([]*types.DocVariantExposure, 0, 52)
+	for i := 0; i < 50; i++ {
+		rows = append(rows, &types.DocVariantExposure{UserID: userID, PathID: pathID, PathNodeID: uuid.New(), ExposureKind: "served"})
+	}
+	// Rows without a node are skipped, not rejected.
+	rows = append(rows, nil, &types.DocVariantExposure{UserID: userID, PathID: pathID})
+
+	if err := repo.CreateMany(dbc, rows); err != nil {
+		t.Fatalf("CreateMany: %v", err)
+	}
+	var n int64
+	if err := tx.Model(&types.DocVariantExposure{}).Where("user_id = ?", userID).Count(&n).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 50 {
+		t.Fatalf("inserted %d exposures, want 50", n)
+	}
+	if rows[0].ID == uuid.Nil || rows[0].CreatedAt.IsZero() || rows[0].PolicyVersion != "base" || rows[0].Source != "api" {
+		t.Fatalf("defaults not applied: %+v", rows[0])
+	}
+	if err := repo.CreateMany(dbc, nil); err != nil {
+		t.Fatalf("CreateMany(nil): %v", err)
+	}
+}
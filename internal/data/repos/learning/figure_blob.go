@@ -0,0 +1,130 @@
+package learning
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type FigureBlobRepo interface {
+	GetByContentHash(dbc dbctx.Context, contentHash string) (*types.FigureBlob, error)
+
+	// Acquire inserts the blob (ref_count=1) or increments the ref_count of the existing row.
+	// created reports whether this call inserted the row (the caller owns the upload).
+	Acquire(dbc dbctx.Context, row *types.FigureBlob) (blob *types.FigureBlob, created bool, err error)
+	// Release decrements the ref_count under a row lock and deletes the row when it reaches zero.
+	// The returned row carries the post-decrement ref_count; nil means no row existed.
+	// Callers should run Release inside a transaction and delete the object before commit when
+	// RefCount == 0 so a concurrent Acquire cannot observe a row whose object is gone.
+	Release(dbc dbctx.Context, contentHash string) (*types.FigureBlob, error)
+}
+
+type figureBlobRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewFigureBlobRepo(db *gorm.DB, baseLog *logger.Logger) FigureBlobRepo {
+	return &figureBlobRepo{db: db, log: baseLog.With("repo", "FigureBlobRepo")}
+}
+
+func (r *figureBlobRepo) GetByContentHash(dbc dbctx.Context, contentHash string) (*types.FigureBlob, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	contentHash = strings.TrimSpace(contentHash)
+	if contentHash == "" {
+		return nil, nil
+	}
+	var out []*types.FigureBlob
+	if err := t.WithContext(dbc.Ctx).Where("content_hash = ?", contentHash).Limit(1).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0], nil
+}
+
+func (r *figureBlobRepo) Acquire(dbc dbctx.Context, row *types.FigureBlob) (*types.FigureBlob, bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil {
+		return nil, false, nil
+	}
+	row.ContentHash = strings.TrimSpace(row.ContentHash)
+	row.StorageKey = strings.TrimSpace(row.StorageKey)
+	if row.ContentHash == "" || row.StorageKey == "" {
+		return nil, false, nil
+	}
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	row.RefCount = 1
+	row.CreatedAt = now
+	row.UpdatedAt = now
+
+	var out types.FigureBlob
+	err := t.WithContext(dbc.Ctx).Raw(`
+		INSERT INTO figure_blob (id, content_hash, storage_key, mime_type, byte_len, ref_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (content_hash) DO UPDATE
+		SET ref_count = figure_blob.ref_count + 1, updated_at = EXCLUDED.updated_at
+		RETURNING *
+	`, row.ID, row.ContentHash, row.StorageKey, row.MimeType, row.ByteLen, now, now).Scan(&out).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &out, out.RefCount == 1, nil
+}
+
+func (r *figureBlobRepo) Release(dbc dbctx.Context, contentHash string) (*types.FigureBlob, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	contentHash = strings.TrimSpace(contentHash)
+	if contentHash == "" {
+		return nil, nil
+	}
+	var out *types.FigureBlob
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []*types.FigureBlob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("content_hash = ?", contentHash).
+			Limit(1).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		row := rows[0]
+		row.RefCount--
+		if row.RefCount <= 0 {
+			row.RefCount = 0
+			out = row
+			return tx.Where("id = ?", row.ID).Delete(&types.FigureBlob{}).Error
+		}
+		row.UpdatedAt = time.Now().UTC()
+		out = row
+		return tx.Model(&types.FigureBlob{}).
+			Where("id = ?", row.ID).
+			Updates(map[string]any{"ref_count": row.RefCount, "updated_at": row.UpdatedAt}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package learning
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"gorm.io/gorm"
+)
+
+func TestFigureBlobRepo(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewFigureBlobRepo(db, testutil.Logger(t))
+
+	hash := "hash-" + uuid.NewString()
+	key := "generated/figures_cas/" + hash + ".png"
+
+	blob, created, err := repo.Acquire(dbc, &types.FigureBlob{ContentHash: hash, StorageKey: key, MimeType: "image/png", ByteLen: 10})
+	if err != nil || blob == nil || !created || blob.RefCount != 1 {
+		t.Fatalf("Acquire(first): blob=%+v created=%v err=%v", blob, created, err)
+	}
+	blob, created, err = repo.Acquire(dbc, &types.FigureBlob{ContentHash: hash, StorageKey: "ignored", MimeType: "image/png", ByteLen: 10})
+	if err != nil || blob == nil || created || blob.RefCount != 2 || blob.StorageKey != key {
+		t.Fatalf("Acquire(second): blob=%+v created=%v err=%v", blob, created, err)
+	}
+
+	if got, err := repo.Release(dbc, hash); err != nil || got == nil || got.RefCount != 1 {
+		t.Fatalf("Release(1): got=%+v err=%v", got, err)
+	}
+	if got, err := repo.Release(dbc, hash); err != nil || got == nil || got.RefCount != 0 {
+		t.Fatalf("Release(2): got=%+v err=%v", got, err)
+	}
+	if got, err := repo.GetByContentHash(dbc, hash); err != nil || got != nil {
+		t.Fatalf("GetByContentHash after zero: got=%+v err=%v", got, err)
+	}
+	if got, err := repo.Release(dbc, hash); err != nil || got != nil {
+		t.Fatalf("Release(missing): got=%+v err=%v", got, err)
+	}
+
+	// Re-acquiring after the row was dropped must report created so the caller re-uploads.
+	if _, created, err := repo.Acquire(dbc, &types.FigureBlob{ContentHash: hash, StorageKey: key}); err != nil || !created {
+		t.Fatalf("Acquire(after zero): created=%v err=%v", created, err)
+	}
+}
+
+func TestFigureBlobRepoConcurrentRefCount(t *testing.T) {
+	db := testutil.DB(t)
+
+	ctx := context.Background()
+	repo := NewFigureBlobRepo(db, testutil.Logger(t))
+
+	hash := "hash-" + uuid.NewString()
+	key := "generated/figures_cas/" + hash + ".png"
+	t.Cleanup(func() {
+		_ = db.Where("content_hash = ?", hash).Delete(&types.FigureBlob{}).Error
+	})
+
+	const writers = 16
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		creates int
+	)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, created, err := repo.Acquire(dbctx.Context{Ctx: ctx}, &types.FigureBlob{ContentHash: hash, StorageKey: key})
+			if err != nil {
+				t.Errorf("Acquire: %v", err)
+				return
+			}
+			if created {
+				mu.Lock()
+				creates++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if creates != 1 {
+		t.Fatalf("expected exactly one creator, got %d", creates)
+	}
+
+	// Interleave releases with re-acquires inside transactions (the regeneration pattern):
+	// every goroutine swaps one reference out and one back in.
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				inner := dbctx.Context{Ctx: ctx, Tx: tx}
+				if _, _, err := repo.Acquire(inner, &types.FigureBlob{ContentHash: hash, StorageKey: key}); err != nil {
+					return err
+				}
+				_, err := repo.Release(inner, hash)
+				return err
+			})
+			if err != nil {
+				t.Errorf("swap: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetByContentHash(dbctx.Context{Ctx: ctx}, hash)
+	if err != nil || got == nil || got.RefCount != writers {
+		t.Fatalf("after swaps: got=%+v err=%v", got, err)
+	}
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Release(dbctx.Context{Ctx: ctx}, hash); err != nil {
+				t.Errorf("Release: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, err := repo.GetByContentHash(dbctx.Context{Ctx: ctx}, hash); err != nil || got != nil {
+		t.Fatalf("expected row dropped at zero: got=%+v err=%v", got, err)
+	}
+}
@@ -9,11 +9,15 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LearningProfileRepo interface {
 	Upsert(dbc dbctx.Context, row *types.LearningProfile) error
 	GetByUserID(dbc dbctx.Context, userID uuid.UUID) (*types.LearningProfile, error)
+	// SetDefaultDocTrack stores the user's default node doc track ("" clears it), creating the
+	// profile if needed and leaving its other fields alone.
+	SetDefaultDocTrack(dbc dbctx.Context, userID uuid.UUID, track string) error
 }
 
 type learningProfileRepo struct {
@@ -71,3 +75,22 @@ func (r *learningProfileRepo) Upsert(dbc dbctx.Context, row *types.LearningProfi
 	}
 	return t.Create(row).Error
 }
+
+func (r *learningProfileRepo) SetDefaultDocTrack(dbc dbctx.Context, userID uuid.UUID, track string) error {
+	if userID == uuid.Nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	row := &types.LearningProfile{
+		UserID:          userID,
+		DefaultDocTrack: track,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	return r.dbx(dbc).WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"default_doc_track", "updated_at"}),
+		}).
+		Create(row).Error
+}
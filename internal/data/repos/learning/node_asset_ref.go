@@ -0,0 +1,188 @@
+package learning
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	NodeAssetRefOwnerDoc     = "node_doc"
+	NodeAssetRefOwnerVariant = "node_doc_variant"
+)
+
+// GeneratedObjectPrefix is the bucket prefix all generated node assets live under.
+const GeneratedObjectPrefix = "generated/"
+
+type NodeAssetRefRepo interface {
+	ListByOwner(dbc dbctx.Context, ownerKind, ownerKey string) ([]*types.NodeAssetRef, error)
+	// ReferencedKeys returns the subset of keys still referenced by a live doc or doc variant
+	// (via node_asset_ref), a figure or video row, a narration segment, or a figure blob.
+	ReferencedKeys(dbc dbctx.Context, keys []string) (map[string]bool, error)
+}
+
+type nodeAssetRefRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewNodeAssetRefRepo(db *gorm.DB, baseLog *logger.Logger) NodeAssetRefRepo {
+	return &nodeAssetRefRepo{db: db, log: baseLog.With("repo", "NodeAssetRefRepo")}
+}
+
+func (r *nodeAssetRefRepo) ListByOwner(dbc dbctx.Context, ownerKind, ownerKey string) ([]*types.NodeAssetRef, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.NodeAssetRef
+	if ownerKind == "" || ownerKey == "" {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("owner_kind = ? AND owner_key = ?", ownerKind, ownerKey).
+		Order("storage_key ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *nodeAssetRefRepo) ReferencedKeys(dbc dbctx.Context, keys []string) (map[string]bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := map[string]bool{}
+	if len(keys) == 0 {
+		return out, nil
+	}
+	// Refs whose owner is gone (node or variant deleted) no longer count.
+	var found []string
+	if err := t.WithContext(dbc.Ctx).Raw(`
+		SELECT r.storage_key FROM node_asset_ref r
+		WHERE r.storage_key IN ? AND (
+			(r.owner_kind = ? AND EXISTS (SELECT 1 FROM learning_node_doc d WHERE d.path_node_id = r.path_node_id))
+			OR (r.owner_kind = ? AND EXISTS (SELECT 1 FROM learning_node_doc_variant v WHERE v.snapshot_id = r.owner_key))
+		)
+		UNION SELECT asset_storage_key FROM learning_node_figure WHERE asset_storage_key IN ?
+		UNION SELECT asset_storage_key FROM learning_node_video WHERE asset_storage_key IN ?
+		UNION SELECT storage_key FROM learning_node_audio WHERE storage_key IN ?
+		UNION SELECT storage_key FROM figure_blob WHERE storage_key IN ?`,
+		keys, NodeAssetRefOwnerDoc, NodeAssetRefOwnerVariant, keys, keys, keys, keys,
+	).Scan(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, k := range found {
+		out[k] = true
+	}
+	return out, nil
+}
+
+// replaceNodeAssetRefs rewrites an owner's refs to the storage keys its doc JSON references.
+// Doc writers call it in the transaction that writes the doc, so refs never lag a commit.
+func replaceNodeAssetRefs(tx *gorm.DB, ownerKind, ownerKey string, pathNodeID uuid.UUID, docJSON []byte) error {
+	if ownerKind == "" || ownerKey == "" || pathNodeID == uuid.Nil {
+		return nil
+	}
+	keys := docAssetStorageKeys(docJSON)
+	del := tx.Where("owner_kind = ? AND owner_key = ?", ownerKind, ownerKey)
+	if len(keys) > 0 {
+		del = del.Where("storage_key NOT IN ?", keys)
+	}
+	if err := del.Delete(&types.NodeAssetRef{}).Error; err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rows := make([]*types.NodeAssetRef, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, &types.NodeAssetRef{
+			ID:         uuid.New(),
+			OwnerKind:  ownerKind,
+			OwnerKey:   ownerKey,
+			StorageKey: k,
+			PathNodeID: pathNodeID,
+			CreatedAt:  now,
+		})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// docAssetStorageKeys collects the generated-object keys a doc references: every generated
+// "storage_key" value, plus keys embedded in asset URLs (video blocks only carry a URL).
+func docAssetStorageKeys(docJSON []byte) []string {
+	if len(docJSON) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(docJSON, &v); err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var walk func(any)
+	walk = func(n any) {
+		switch x := n.(type) {
+		case map[string]any:
+			for k, child := range x {
+				if s, ok := child.(string); ok {
+					if key := assetKeyFromField(k, s); key != "" {
+						seen[key] = true
+					}
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range x {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	out := make([]string, 0, len(seen))
+	for k := range seen {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func assetKeyFromField(field, value string) string {
+	value = strings.TrimSpace(value)
+	switch field {
+	case "storage_key":
+		value = strings.TrimLeft(value, "/")
+		if !strings.HasPrefix(value, GeneratedObjectPrefix) {
+			return ""
+		}
+		return value
+	case "url", "src", "poster_url":
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		i := strings.Index(value, "/"+GeneratedObjectPrefix)
+		if i < 0 {
+			return ""
+		}
+		key := value[i+1:]
+		if q := strings.IndexAny(key, "?#"); q >= 0 {
+			key = key[:q]
+		}
+		return key
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,77 @@
+package learning
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestDocAssetStorageKeys(t *testing.T) {
+	doc := []byte(`{"blocks":[
+		{"type":"figure","asset":{"storage_key":"generated/node_figures/p/n/slot_1_a.png","url":"https://cdn.example.com/generated/node_figures/p/n/slot_1_a.png"}},
+		{"type":"video","url":"https://storage.googleapis.com/b/generated/node_videos/p/n/slot%202.mp4?X-Goog-Signature=abc"},
+		{"type":"figure","asset":{"storage_key":"materials/upload.png","url":"https://example.com/other.png"}},
+		{"type":"paragraph","md":"see generated/node_figures/not_a_field.png"}
+	]}`)
+	got := docAssetStorageKeys(doc)
+	want := []string{
+		"generated/node_figures/p/n/slot_1_a.png",
+		"generated/node_videos/p/n/slot 2.mp4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("docAssetStorageKeys = %v, want %v", got, want)
+	}
+	if keys := docAssetStorageKeys([]byte(`not json`)); len(keys) != 0 {
+		t.Fatalf("invalid JSON should yield no keys, got %v", keys)
+	}
+}
+
+func TestNodeDocWritesMaintainAssetRefs(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	docs := NewLearningNodeDocRepo(db, testutil.Logger(t))
+	refs := NewNodeAssetRefRepo(db, testutil.Logger(t))
+
+	const keyA = "generated/node_figures/p/n/slot_1_a.png"
+	const keyB = "generated/node_figures/p/n/slot_1_b.png"
+	figureDoc := func(key string) datatypes.JSON {
+		return datatypes.JSON([]byte(`{"blocks":[{"type":"figure","asset":{"storage_key":"` + key + `"}}]}`))
+	}
+
+	user := testutil.SeedUser(t, dbc, "node-asset-ref@example.com")
+	row := newTestNodeDoc(user.ID, "v1")
+	row.DocJSON = figureDoc(keyA)
+	if err := docs.Upsert(dbc, row); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	owner := row.PathNodeID.String()
+	got, err := refs.ListByOwner(dbc, NodeAssetRefOwnerDoc, owner)
+	if err != nil || len(got) != 1 || got[0].StorageKey != keyA {
+		t.Fatalf("refs after create: %+v %v", got, err)
+	}
+
+	// Regenerating the figure swaps the ref in the same write.
+	row.DocJSON = figureDoc(keyB)
+	if err := docs.UpdateWithVersion(dbc, row, row.Version); err != nil {
+		t.Fatalf("UpdateWithVersion: %v", err)
+	}
+	got, err = refs.ListByOwner(dbc, NodeAssetRefOwnerDoc, owner)
+	if err != nil || len(got) != 1 || got[0].StorageKey != keyB {
+		t.Fatalf("refs after regen: %+v %v", got, err)
+	}
+
+	referenced, err := refs.ReferencedKeys(dbc, []string{keyA, keyB})
+	if err != nil {
+		t.Fatalf("ReferencedKeys: %v", err)
+	}
+	if referenced[keyA] || !referenced[keyB] {
+		t.Fatalf("ReferencedKeys = %v, want only %s", referenced, keyB)
+	}
+}
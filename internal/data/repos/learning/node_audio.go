@@ -0,0 +1,109 @@
+package learning
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	NodeAudioStatusReady = "ready"
+	NodeAudioStatusStale = "stale"
+)
+
+type LearningNodeAudioRepo interface {
+	// ListByPathNodeID returns the node's narration segments in playback order.
+	ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]*types.LearningNodeAudio, error)
+	// ReplaceForNode swaps the node's segments for rows atomically and returns the replaced rows.
+	ReplaceForNode(dbc dbctx.Context, pathNodeID uuid.UUID, rows []*types.LearningNodeAudio) ([]*types.LearningNodeAudio, error)
+	// MarkStale flags ready segments synthesized from a different doc content hash.
+	MarkStale(dbc dbctx.Context, pathNodeID uuid.UUID, currentContentHash string) (int64, error)
+}
+
+type learningNodeAudioRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewLearningNodeAudioRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeAudioRepo {
+	return &learningNodeAudioRepo{db: db, log: baseLog.With("repo", "LearningNodeAudioRepo")}
+}
+
+func (r *learningNodeAudioRepo) ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]*types.LearningNodeAudio, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.LearningNodeAudio
+	if pathNodeID == uuid.Nil {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("path_node_id = ?", pathNodeID).
+		Order("segment_index ASC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *learningNodeAudioRepo) ReplaceForNode(dbc dbctx.Context, pathNodeID uuid.UUID, rows []*types.LearningNodeAudio) ([]*types.LearningNodeAudio, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathNodeID == uuid.Nil {
+		return nil, nil
+	}
+	var replaced []*types.LearningNodeAudio
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("path_node_id = ?", pathNodeID).Find(&replaced).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("path_node_id = ?", pathNodeID).Delete(&types.LearningNodeAudio{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		now := time.Now().UTC()
+		for _, row := range rows {
+			if row.ID == uuid.Nil {
+				row.ID = uuid.New()
+			}
+			row.PathNodeID = pathNodeID
+			if row.CreatedAt.IsZero() {
+				row.CreatedAt = now
+			}
+			row.UpdatedAt = now
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+func (r *learningNodeAudioRepo) MarkStale(dbc dbctx.Context, pathNodeID uuid.UUID, currentContentHash string) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathNodeID == uuid.Nil {
+		return 0, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeAudio{}).
+		Where("path_node_id = ? AND status = ? AND doc_content_hash <> ?", pathNodeID, NodeAudioStatusReady, currentContentHash).
+		Updates(map[string]any{
+			"status":     NodeAudioStatusStale,
+			"updated_at": time.Now().UTC(),
+		})
+	return res.RowsAffected, res.Error
+}
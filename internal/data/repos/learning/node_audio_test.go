@@ -0,0 +1,71 @@
+package learning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestLearningNodeAudioRepo(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewLearningNodeAudioRepo(db, testutil.Logger(t))
+
+	userID, pathID, nodeID, docID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mk := func(idx int, blockID, hash string) *types.LearningNodeAudio {
+		return &types.LearningNodeAudio{
+			UserID:         userID,
+			PathID:         pathID,
+			SegmentIndex:   idx,
+			DocID:          docID,
+			DocContentHash: hash,
+			BlockID:        blockID,
+			BlockType:      "paragraph",
+			TextHash:       "t",
+			StorageKey:     "generated/node_audio/k",
+			MimeType:       "audio/wav",
+			StartMS:        idx * 1000,
+			DurationMS:     1000,
+			Provider:       "stub",
+			Status:         NodeAudioStatusReady,
+		}
+	}
+
+	if _, err := repo.ReplaceForNode(dbc, nodeID, []*types.LearningNodeAudio{mk(1, "b2", "h1"), mk(0, "b1", "h1")}); err != nil {
+		t.Fatalf("ReplaceForNode(first): %v", err)
+	}
+	rows, err := repo.ListByPathNodeID(dbc, nodeID)
+	if err != nil || len(rows) != 2 || rows[0].BlockID != "b1" || rows[1].BlockID != "b2" {
+		t.Fatalf("ListByPathNodeID: rows=%+v err=%v", rows, err)
+	}
+
+	if n, err := repo.MarkStale(dbc, nodeID, "h1"); err != nil || n != 0 {
+		t.Fatalf("MarkStale(same hash): n=%d err=%v", n, err)
+	}
+	if n, err := repo.MarkStale(dbc, nodeID, "h2"); err != nil || n != 2 {
+		t.Fatalf("MarkStale(new hash): n=%d err=%v", n, err)
+	}
+	rows, _ = repo.ListByPathNodeID(dbc, nodeID)
+	for _, row := range rows {
+		if row.Status != NodeAudioStatusStale {
+			t.Fatalf("expected stale row, got %s", row.Status)
+		}
+	}
+
+	replaced, err := repo.ReplaceForNode(dbc, nodeID, []*types.LearningNodeAudio{mk(0, "b1", "h2")})
+	if err != nil || len(replaced) != 2 {
+		t.Fatalf("ReplaceForNode(second): replaced=%d err=%v", len(replaced), err)
+	}
+	rows, _ = repo.ListByPathNodeID(dbc, nodeID)
+	if len(rows) != 1 || rows[0].DocContentHash != "h2" || rows[0].Status != NodeAudioStatusReady {
+		t.Fatalf("after replace: %+v", rows)
+	}
+}
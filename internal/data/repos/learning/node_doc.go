@@ -1,25 +1,105 @@
 package learning
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 )
 
+// ErrStaleDoc is returned when a versioned write finds that the doc changed since it was read.
+// Callers should reload, re-apply their change and retry (or surface a conflict).
+var ErrStaleDoc = errors.New("learning_node_doc: stale version")
+
+// ErrDocTooLarge is returned when a doc's serialized JSON exceeds MaxNodeDocBytes.
+var ErrDocTooLarge = errors.New("doc_too_large")
+
+// DefaultMaxNodeDocBytes caps serialized DocJSON; override with NODE_DOC_MAX_BYTES (<= 0 disables).
+const DefaultMaxNodeDocBytes = 1 << 20
+
+// MaxNodeDocBytes returns the configured serialized doc size limit, or 0 when unlimited.
+func MaxNodeDocBytes() int {
+	n := envutil.Int("NODE_DOC_MAX_BYTES", DefaultMaxNodeDocBytes)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// The node-keyed reads and writes below address a node's primary-track doc unless they take a
+// track; see types.NodeDocTrackPrimary.
 type LearningNodeDocRepo interface {
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.LearningNodeDoc, error)
+	// GetByPathNodeID returns the node's doc as GetLatestByPathNodeIDs picks it.
 	GetByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) (*types.LearningNodeDoc, error)
+	// GetByPathNodeIDAndTrack is GetByPathNodeID for a named track ("" is primary).
+	GetByPathNodeIDAndTrack(dbc dbctx.Context, pathNodeID uuid.UUID, track string) (*types.LearningNodeDoc, error)
+	// ListTracksByPathNodeID returns the tracks the node has a doc on, primary first.
+	ListTracksByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]string, error)
+	// GetByPathNodeIDs returns every doc row of the nodes, latest first (updated_at, then id).
+	// Only callers that want duplicate rows should use it; see GetLatestByPathNodeIDs.
 	GetByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) ([]*types.LearningNodeDoc, error)
+	// GetLatestByPathNodeIDs returns exactly one doc per node that has any: the greatest
+	// updated_at, with the greater id breaking ties, so duplicate rows resolve the same way on
+	// every read. Rows come back ordered by path_node_id.
+	GetLatestByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) ([]*types.LearningNodeDoc, error)
+	// ListByUserCitingChunks returns the user's docs on any track whose JSON mentions any of the
+	// chunk IDs. It is a coarse text match; callers confirm against the doc's citations.
+	ListByUserCitingChunks(dbc dbctx.Context, userID uuid.UUID, chunkIDs []uuid.UUID) ([]*types.LearningNodeDoc, error)
+	// MergeMetadata merges patch into the doc's metadata object without touching the doc or
+	// its version, for maintenance flags that are not content (e.g. sources_stale).
+	MergeMetadata(dbc dbctx.Context, id uuid.UUID, patch map[string]any) error
 
+	// Upsert inserts the doc or, when one already exists for the node and row.Track (empty is
+	// primary), overwrites it only if the stored version still equals row.Version (0 for callers
+	// that never read a doc).
 	Upsert(dbc dbctx.Context, row *types.LearningNodeDoc) error
+	// UpdateWithVersion updates an existing doc by ID only if its version equals expectedVersion.
+	// Metadata is only written when row.Metadata is set, so writers that don't track it keep it.
+	UpdateWithVersion(dbc dbctx.Context, row *types.LearningNodeDoc, expectedVersion int) error
+	// SetFrozen sets the node's doc freeze flag and bumps its version, so patches generated
+	// against the unfrozen doc go stale instead of landing. found is false when no doc exists.
+	SetFrozen(dbc dbctx.Context, pathNodeID uuid.UUID, frozen bool) (found bool, err error)
+
+	// ListDuplicatePathNodes reports nodes with more than one doc row on a track, which the
+	// unique index on (path_node_id, track) should rule out (seen after a partially failed
+	// migration). limit <= 0 returns them all.
+	ListDuplicatePathNodes(dbc dbctx.Context, limit int) ([]NodeDocDuplicate, error)
+	// RepairDuplicates keeps the latest doc of each of the node's tracks (as
+	// GetLatestByPathNodeIDs picks it) and archives every other row as a revision of it before
+	// deleting the row. It returns the number of rows archived.
+	RepairDuplicates(dbc dbctx.Context, pathNodeID uuid.UUID) (int, error)
+}
+
+// NodeDocDuplicate is a path node with Count doc rows on Track.
+type NodeDocDuplicate struct {
+	PathNodeID uuid.UUID `json:"path_node_id"`
+	Track      string    `json:"track"`
+	Count      int       `json:"count"`
+}
+
+// nodeDocIDRefs are the columns that point at a learning_node_doc row by id.
+var nodeDocIDRefs = []struct{ Table, Column string }{
+	{Table: "learning_node_doc_revision", Column: "doc_id"},
+	{Table: "learning_node_doc_variant", Column: "base_doc_id"},
+	{Table: "doc_variant_exposure", Column: "base_doc_id"},
+	{Table: "learning_node_audio", Column: "doc_id"},
 }
 
+// NodeDocRevisionOpArchiveDuplicate marks revisions holding a duplicate doc row removed by
+// RepairDuplicates.
+const NodeDocRevisionOpArchiveDuplicate = "archive_duplicate"
+
 type learningNodeDocRepo struct {
 	db  *gorm.DB
 	log *logger.Logger
@@ -62,7 +142,25 @@ func (r *learningNodeDocRepo) GetByPathNodeID(dbc dbctx.Context, pathNodeID uuid
 	if pathNodeID == uuid.Nil {
 		return nil, nil
 	}
-	rows, err := r.GetByPathNodeIDs(dbc, []uuid.UUID{pathNodeID})
+	rows, err := r.GetLatestByPathNodeIDs(dbc, []uuid.UUID{pathNodeID})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+func (r *learningNodeDocRepo) GetByPathNodeIDAndTrack(dbc dbctx.Context, pathNodeID uuid.UUID, track string) (*types.LearningNodeDoc, error) {
+	if pathNodeID == uuid.Nil {
+		return nil, nil
+	}
+	track, err := nodeDocTrack(track)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.latestByPathNodeIDs(dbc, []uuid.UUID{pathNodeID}, track)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +170,26 @@ func (r *learningNodeDocRepo) GetByPathNodeID(dbc dbctx.Context, pathNodeID uuid
 	return rows[0], nil
 }
 
+func (r *learningNodeDocRepo) ListTracksByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]string, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []string{}
+	if pathNodeID == uuid.Nil {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeDoc{}).
+		Distinct("track").
+		Where("path_node_id = ?", pathNodeID).
+		Order(clause.Expr{SQL: "track <> ?, track", Vars: []any{types.NodeDocTrackPrimary}}).
+		Pluck("track", &out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *learningNodeDocRepo) GetByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) ([]*types.LearningNodeDoc, error) {
 	t := dbc.Tx
 	if t == nil {
@@ -82,14 +200,82 @@ func (r *learningNodeDocRepo) GetByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []
 		return out, nil
 	}
 	if err := t.WithContext(dbc.Ctx).
-		Where("path_node_id IN ?", pathNodeIDs).
-		Order("updated_at DESC").
+		Where("path_node_id IN ? AND track = ?", pathNodeIDs, types.NodeDocTrackPrimary).
+		Order("updated_at DESC, id DESC").
 		Find(&out).Error; err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
+func (r *learningNodeDocRepo) GetLatestByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) ([]*types.LearningNodeDoc, error) {
+	return r.latestByPathNodeIDs(dbc, pathNodeIDs, types.NodeDocTrackPrimary)
+}
+
+func (r *learningNodeDocRepo) latestByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID, track string) ([]*types.LearningNodeDoc, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.LearningNodeDoc
+	if len(pathNodeIDs) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Select("DISTINCT ON (path_node_id) *").
+		Where("path_node_id IN ? AND track = ?", pathNodeIDs, track).
+		Order("path_node_id, updated_at DESC, id DESC").
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *learningNodeDocRepo) ListByUserCitingChunks(dbc dbctx.Context, userID uuid.UUID, chunkIDs []uuid.UUID) ([]*types.LearningNodeDoc, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.LearningNodeDoc
+	if userID == uuid.Nil || len(chunkIDs) == 0 {
+		return out, nil
+	}
+	patterns := make([]string, 0, len(chunkIDs))
+	for _, id := range chunkIDs {
+		if id != uuid.Nil {
+			patterns = append(patterns, "%"+id.String()+"%")
+		}
+	}
+	if len(patterns) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ?", userID).
+		Where("doc_json::text LIKE ANY (ARRAY[?])", patterns).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *learningNodeDocRepo) MergeMetadata(dbc dbctx.Context, id uuid.UUID, patch map[string]any) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if id == uuid.Nil || len(patch) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeDoc{}).
+		Where("id = ?", id).
+		Update("metadata", gorm.Expr("(CASE WHEN jsonb_typeof(metadata) = 'object' THEN metadata ELSE '{}'::jsonb END) || ?::jsonb", string(raw))).Error
+}
+
 func (r *learningNodeDocRepo) Upsert(dbc dbctx.Context, row *types.LearningNodeDoc) error {
 	t := dbc.Tx
 	if t == nil {
@@ -98,6 +284,14 @@ func (r *learningNodeDocRepo) Upsert(dbc dbctx.Context, row *types.LearningNodeD
 	if row == nil || row.UserID == uuid.Nil || row.PathID == uuid.Nil || row.PathNodeID == uuid.Nil {
 		return nil
 	}
+	track, err := nodeDocTrack(row.Track)
+	if err != nil {
+		return err
+	}
+	row.Track = track
+	if err := r.checkDocSize(row); err != nil {
+		return err
+	}
 	if row.ID == uuid.Nil {
 		row.ID = uuid.New()
 	}
@@ -106,10 +300,19 @@ func (r *learningNodeDocRepo) Upsert(dbc dbctx.Context, row *types.LearningNodeD
 	if row.CreatedAt.IsZero() {
 		row.CreatedAt = now
 	}
+	expected := row.Version
+	row.Version = expected + 1
+	row.Digest = nodeDocDigestJSON(row.DocJSON)
 
-	return t.WithContext(dbc.Ctx).
-		Clauses(clause.OnConflict{
-			Columns: []clause.Column{{Name: "path_node_id"}},
+	var res *gorm.DB
+	err = t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		res = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "path_node_id"}, {Name: "track"}},
+			// Rows start at version 1, so a caller that never read a doc (expected=0) loses
+			// to any concurrent creator instead of overwriting it.
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "learning_node_doc.version = ?", Vars: []any{expected}},
+			}},
 			DoUpdates: clause.AssignmentColumns([]string{
 				"user_id",
 				"path_id",
@@ -118,8 +321,262 @@ func (r *learningNodeDocRepo) Upsert(dbc dbctx.Context, row *types.LearningNodeD
 				"doc_text",
 				"content_hash",
 				"sources_hash",
+				// A full rewrite resets doc-level maintenance state such as summary_stale.
+				"metadata",
+				"digest",
+				"version",
 				"updated_at",
 			}),
 		}).
-		Create(row).Error
+			Create(row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := bumpPathOutlineVersion(tx, row.PathID); err != nil {
+			return err
+		}
+		return replaceNodeAssetRefs(tx, NodeAssetRefOwnerDoc, nodeDocAssetOwnerKey(row.PathNodeID, row.Track), row.PathNodeID, row.DocJSON)
+	})
+	if err != nil {
+		row.Version = expected
+		return err
+	}
+	if res.RowsAffected == 0 {
+		row.Version = expected
+		return ErrStaleDoc
+	}
+	return nil
+}
+
+func (r *learningNodeDocRepo) UpdateWithVersion(dbc dbctx.Context, row *types.LearningNodeDoc, expectedVersion int) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil || row.ID == uuid.Nil {
+		return nil
+	}
+	if err := r.checkDocSize(row); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	digest := nodeDocDigestJSON(row.DocJSON)
+	updates := map[string]any{
+		"schema_version": row.SchemaVersion,
+		"doc_json":       row.DocJSON,
+		"doc_text":       row.DocText,
+		"content_hash":   row.ContentHash,
+		"sources_hash":   row.SourcesHash,
+		"digest":         digest,
+		"version":        gorm.Expr("version + 1"),
+		"updated_at":     now,
+	}
+	if len(row.Metadata) > 0 {
+		updates["metadata"] = row.Metadata
+	}
+	var res *gorm.DB
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		res = tx.Model(&types.LearningNodeDoc{}).
+			Where("id = ? AND version = ?", row.ID, expectedVersion).
+			Updates(updates)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		key := struct {
+			PathNodeID uuid.UUID
+			Track      string
+		}{row.PathNodeID, row.Track}
+		if key.PathNodeID == uuid.Nil || key.Track == "" {
+			if err := tx.Model(&types.LearningNodeDoc{}).Select("path_node_id, track").Where("id = ?", row.ID).Take(&key).Error; err != nil {
+				return err
+			}
+		}
+		if err := bumpPathOutlineVersionForNodes(tx, []uuid.UUID{key.PathNodeID}); err != nil {
+			return err
+		}
+		return replaceNodeAssetRefs(tx, NodeAssetRefOwnerDoc, nodeDocAssetOwnerKey(key.PathNodeID, key.Track), key.PathNodeID, row.DocJSON)
+	})
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return ErrStaleDoc
+	}
+	row.Version = expectedVersion + 1
+	row.UpdatedAt = now
+	row.Digest = digest
+	return nil
+}
+
+// nodeDocTrack is the stored form of a track argument; "" is primary.
+func nodeDocTrack(track string) (string, error) {
+	out, ok := types.NormalizeNodeDocTrack(track)
+	if !ok {
+		return "", fmt.Errorf("learning_node_doc: unknown track %q", track)
+	}
+	return out, nil
+}
+
+// nodeDocAssetOwnerKey is the node_asset_ref owner key of a node's doc on a track. The primary
+// doc keeps the bare node id it was keyed by before tracks existed.
+func nodeDocAssetOwnerKey(pathNodeID uuid.UUID, track string) string {
+	if track == "" || track == types.NodeDocTrackPrimary {
+		return pathNodeID.String()
+	}
+	return pathNodeID.String() + ":" + track
+}
+
+// nodeDocDigestJSON is the digest stored alongside a committed doc.
+func nodeDocDigestJSON(docJSON []byte) datatypes.JSON {
+	raw, err := json.Marshal(types.ExtractNodeDocDigest(docJSON))
+	if err != nil {
+		return nil
+	}
+	return datatypes.JSON(raw)
+}
+
+func (r *learningNodeDocRepo) SetFrozen(dbc dbctx.Context, pathNodeID uuid.UUID, frozen bool) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathNodeID == uuid.Nil {
+		return false, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeDoc{}).
+		Where("path_node_id = ? AND track = ?", pathNodeID, types.NodeDocTrackPrimary).
+		Updates(map[string]any{
+			"frozen":  frozen,
+			"version": gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *learningNodeDocRepo) checkDocSize(row *types.LearningNodeDoc) error {
+	max := MaxNodeDocBytes()
+	if max <= 0 || len(row.DocJSON) <= max {
+		return nil
+	}
+	r.log.Warn("Rejecting oversized node doc",
+		"path_node_id", row.PathNodeID,
+		"doc_id", row.ID,
+		"bytes", len(row.DocJSON),
+		"max_bytes", max,
+	)
+	return ErrDocTooLarge
+}
+
+func (r *learningNodeDocRepo) ListDuplicatePathNodes(dbc dbctx.Context, limit int) ([]NodeDocDuplicate, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []NodeDocDuplicate
+	q := t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeDoc{}).
+		Select("path_node_id, track, COUNT(*) AS count").
+		Group("path_node_id, track").
+		Having("COUNT(*) > 1").
+		Order("path_node_id, track")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *learningNodeDocRepo) RepairDuplicates(dbc dbctx.Context, pathNodeID uuid.UUID) (int, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathNodeID == uuid.Nil {
+		return 0, nil
+	}
+	archived := 0
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []*types.LearningNodeDoc
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("path_node_id = ?", pathNodeID).
+			Order("track, updated_at DESC, id DESC").
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for len(rows) > 0 {
+			n := 1
+			for n < len(rows) && rows[n].Track == rows[0].Track {
+				n++
+			}
+			trackRows := rows[:n]
+			rows = rows[n:]
+			if len(trackRows) < 2 {
+				continue
+			}
+			if err := archiveDuplicateNodeDocs(tx, trackRows[0], trackRows[1:], now); err != nil {
+				return err
+			}
+			archived += len(trackRows) - 1
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return archived, nil
+}
+
+// archiveDuplicateNodeDocs archives each duplicate as a revision of keep, moves its references
+// to keep and deletes it.
+func archiveDuplicateNodeDocs(tx *gorm.DB, keep *types.LearningNodeDoc, dups []*types.LearningNodeDoc, now time.Time) error {
+	for _, dup := range dups {
+		meta, _ := json.Marshal(map[string]any{
+			"reason":              "duplicate_path_node_doc",
+			"archived_doc_id":     dup.ID.String(),
+			"archived_version":    dup.Version,
+			"archived_created_at": dup.CreatedAt,
+			"archived_updated_at": dup.UpdatedAt,
+			"content_hash":        dup.ContentHash,
+			"sources_hash":        dup.SourcesHash,
+			"frozen":              dup.Frozen,
+			"metadata":            dup.Metadata,
+		})
+		rev := &types.LearningNodeDocRevision{
+			ID:         uuid.New(),
+			DocID:      keep.ID,
+			UserID:     dup.UserID,
+			PathID:     dup.PathID,
+			PathNodeID: dup.PathNodeID,
+			Track:      keep.Track,
+			Operation:  NodeDocRevisionOpArchiveDuplicate,
+			BeforeJSON: dup.DocJSON,
+			AfterJSON:  keep.DocJSON,
+			Status:     "archived",
+			Metadata:   meta,
+			CreatedAt:  now,
+		}
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+		// The duplicate's history, variants and audio move to the doc that survives; audio
+		// synthesized from it goes stale on its content hash.
+		for _, ref := range nodeDocIDRefs {
+			if err := tx.Exec("UPDATE "+ref.Table+" SET "+ref.Column+" = ? WHERE "+ref.Column+" = ?", keep.ID, dup.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id = ?", dup.ID).Delete(&types.LearningNodeDoc{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := bumpPathOutlineVersion(tx, keep.PathID); err != nil {
+		return err
+	}
+	return replaceNodeAssetRefs(tx, NodeAssetRefOwnerDoc, nodeDocAssetOwnerKey(keep.PathNodeID, keep.Track), keep.PathNodeID, keep.DocJSON)
 }
@@ -1,18 +1,30 @@
 package learning
 
 import (
+	"encoding/json"
+
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 )
 
+// NodeDocRevisionTraceKey is the revision metadata key holding the request trace of its writer.
+const NodeDocRevisionTraceKey = "trace"
+
 type LearningNodeDocRevisionRepo interface {
 	Create(dbc dbctx.Context, rows []*types.LearningNodeDocRevision) ([]*types.LearningNodeDocRevision, error)
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.LearningNodeDocRevision, error)
-	ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, limit int) ([]*types.LearningNodeDocRevision, error)
+	// ListByPathNodeID returns the revisions of the node's doc on track ("" is primary).
+	ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, track string, limit int) ([]*types.LearningNodeDocRevision, error)
+	// ListPageByPathNodeID is the keyset-paginated form of ListByPathNodeID, in (created_at, id)
+	// order, newest first unless ascending.
+	ListPageByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, track string, after *keyset.Cursor, ascending bool, limit int) ([]*types.LearningNodeDocRevision, error)
 	ListByDocID(dbc dbctx.Context, docID uuid.UUID, limit int) ([]*types.LearningNodeDocRevision, error)
 }
 
@@ -33,10 +45,18 @@ func (r *learningNodeDocRevisionRepo) Create(dbc dbctx.Context, rows []*types.Le
 	if len(rows) == 0 {
 		return []*types.LearningNodeDocRevision{}, nil
 	}
+	trace := ctxutil.TraceFields(dbc.Ctx)
 	for _, row := range rows {
-		if row != nil && row.ID == uuid.Nil {
+		if row == nil {
+			continue
+		}
+		if row.ID == uuid.Nil {
 			row.ID = uuid.New()
 		}
+		if row.Track == "" {
+			row.Track = types.NodeDocTrackPrimary
+		}
+		row.Metadata = withRevisionTrace(row.Metadata, trace)
 	}
 	if err := t.WithContext(dbc.Ctx).Create(&rows).Error; err != nil {
 		return nil, err
@@ -44,6 +64,30 @@ func (r *learningNodeDocRevisionRepo) Create(dbc dbctx.Context, rows []*types.Le
 	return rows, nil
 }
 
+// withRevisionTrace records the writer's request trace (trace_id, request_id, span_id,
+// trace_depth) under metadata.trace; top-level trace_id is the docgen trace of a regeneration.
+// Metadata that already has a trace, or is not an object, is left as is.
+func withRevisionTrace(meta datatypes.JSON, trace map[string]any) datatypes.JSON {
+	if len(trace) == 0 {
+		return meta
+	}
+	m := map[string]any{}
+	if len(meta) > 0 && string(meta) != "null" {
+		if err := json.Unmarshal(meta, &m); err != nil || m == nil {
+			return meta
+		}
+	}
+	if _, ok := m[NodeDocRevisionTraceKey]; ok {
+		return meta
+	}
+	m[NodeDocRevisionTraceKey] = trace
+	b, err := json.Marshal(m)
+	if err != nil {
+		return meta
+	}
+	return datatypes.JSON(b)
+}
+
 func (r *learningNodeDocRevisionRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.LearningNodeDocRevision, error) {
 	if id == uuid.Nil {
 		return nil, nil
@@ -73,7 +117,7 @@ func (r *learningNodeDocRevisionRepo) getByIDs(dbc dbctx.Context, ids []uuid.UUI
 	return out, nil
 }
 
-func (r *learningNodeDocRevisionRepo) ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, limit int) ([]*types.LearningNodeDocRevision, error) {
+func (r *learningNodeDocRevisionRepo) ListByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, track string, limit int) ([]*types.LearningNodeDocRevision, error) {
 	t := dbc.Tx
 	if t == nil {
 		t = r.db
@@ -82,7 +126,26 @@ func (r *learningNodeDocRevisionRepo) ListByPathNodeID(dbc dbctx.Context, pathNo
 	if pathNodeID == uuid.Nil {
 		return out, nil
 	}
-	q := t.WithContext(dbc.Ctx).Where("path_node_id = ?", pathNodeID).Order("created_at DESC")
+	q := t.WithContext(dbc.Ctx).Where("path_node_id = ? AND track = ?", pathNodeID, revisionTrack(track)).Order("created_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *learningNodeDocRevisionRepo) ListPageByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, track string, after *keyset.Cursor, ascending bool, limit int) ([]*types.LearningNodeDocRevision, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.LearningNodeDocRevision{}
+	if pathNodeID == uuid.Nil {
+		return out, nil
+	}
+	q := keyset.Page(t.WithContext(dbc.Ctx).Where("path_node_id = ? AND track = ?", pathNodeID, revisionTrack(track)), "created_at", !ascending, after)
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -110,3 +173,10 @@ func (r *learningNodeDocRevisionRepo) ListByDocID(dbc dbctx.Context, docID uuid.
 	}
 	return out, nil
 }
+
+func revisionTrack(track string) string {
+	if track == "" {
+		return types.NodeDocTrackPrimary
+	}
+	return track
+}
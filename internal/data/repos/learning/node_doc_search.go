@@ -0,0 +1,212 @@
+package learning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	NodeDocSearchDefaultLimit = 20
+	NodeDocSearchMaxLimit     = 50
+	NodeDocSearchMaxQueryLen  = 256
+)
+
+// ts_headline marks matches and separates fragments with control characters so highlights can
+// be returned as offsets instead of markup that clients would have to sanitize.
+const (
+	nodeDocHeadlineStart     = "\x02"
+	nodeDocHeadlineStop      = "\x03"
+	nodeDocHeadlineDelimiter = "\x1e"
+	nodeDocHeadlineOptions   = `StartSel="` + nodeDocHeadlineStart + `", StopSel="` + nodeDocHeadlineStop +
+		`", FragmentDelimiter="` + nodeDocHeadlineDelimiter + `", MaxFragments=3, MaxWords=24, MinWords=8`
+)
+
+// NodeDocSearchCursor is the keyset position of the last hit of a page. Hits are ordered by
+// (rank DESC, doc id ASC), which is total because doc ids are unique.
+type NodeDocSearchCursor struct {
+	Rank  float64
+	DocID uuid.UUID
+}
+
+type NodeDocSearchQuery struct {
+	UserID uuid.UUID
+	// PathID optionally restricts the search to one path.
+	PathID uuid.UUID
+	Query  string
+	After  *NodeDocSearchCursor
+	Limit  int
+}
+
+// NodeDocSearchFragment is one highlighted excerpt. Matches are [start, end) rune offsets into
+// Text.
+type NodeDocSearchFragment struct {
+	Text    string   `json:"text"`
+	Matches [][2]int `json:"matches"`
+}
+
+type NodeDocSearchHit struct {
+	DocID      uuid.UUID `gorm:"column:doc_id"`
+	PathID     uuid.UUID `gorm:"column:path_id"`
+	PathNodeID uuid.UUID `gorm:"column:path_node_id"`
+	PathTitle  string    `gorm:"column:path_title"`
+	NodeTitle  string    `gorm:"column:node_title"`
+	NodeIndex  int       `gorm:"column:node_index"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+	Rank       float64   `gorm:"column:rank"`
+	Headline   string    `gorm:"column:headline"`
+
+	Fragments []NodeDocSearchFragment `gorm:"-"`
+}
+
+// NodeDocSearchRepo is lexical search over the plain text of a user's node docs.
+type NodeDocSearchRepo interface {
+	Search(dbc dbctx.Context, q NodeDocSearchQuery) ([]*NodeDocSearchHit, error)
+}
+
+type nodeDocSearchRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewNodeDocSearchRepo(db *gorm.DB, baseLog *logger.Logger) NodeDocSearchRepo {
+	return &nodeDocSearchRepo{db: db, log: baseLog.With("repo", "NodeDocSearchRepo")}
+}
+
+// Search returns one page of matching docs. Only the page is loaded: ranking runs over the
+// FTS index, and ts_headline (the expensive part) runs on the page's rows alone.
+func (r *nodeDocSearchRepo) Search(dbc dbctx.Context, q NodeDocSearchQuery) ([]*NodeDocSearchHit, error) {
+	if q.UserID == uuid.Nil {
+		return nil, fmt.Errorf("missing user_id")
+	}
+	q.Query = strings.TrimSpace(q.Query)
+	if q.Query == "" {
+		return []*NodeDocSearchHit{}, nil
+	}
+	if q.Limit <= 0 {
+		q.Limit = NodeDocSearchDefaultLimit
+	}
+	// Callers page by asking for one row past the page size, so a full page may ask for one more.
+	if q.Limit > NodeDocSearchMaxLimit+1 {
+		q.Limit = NodeDocSearchMaxLimit + 1
+	}
+
+	// Use plainto_tsquery for safety, same as the chat lexical search.
+	args := []any{q.Query, q.UserID, types.NodeDocTrackPrimary}
+	filters := ""
+	if q.PathID != uuid.Nil {
+		filters += " AND d.path_id = ?"
+		args = append(args, q.PathID)
+	}
+	after := "TRUE"
+	if q.After != nil {
+		after = "(hits.rank < ? OR (hits.rank = ? AND hits.doc_id > ?))"
+		args = append(args, q.After.Rank, q.After.Rank, q.After.DocID)
+	}
+	args = append(args, nodeDocHeadlineOptions)
+
+	sql := fmt.Sprintf(`
+		WITH tsq AS (SELECT plainto_tsquery('english', ?) AS q),
+		hits AS (
+			SELECT d.id AS doc_id, d.path_id, d.path_node_id, d.updated_at,
+			       ts_rank(to_tsvector('english', d.doc_text), tsq.q)::float8 AS rank
+			FROM learning_node_doc d
+			CROSS JOIN tsq
+			JOIN path p ON p.id = d.path_id AND p.deleted_at IS NULL
+			WHERE d.user_id = ? AND d.track = ?%s
+			  AND to_tsvector('english', d.doc_text) @@ tsq.q
+		),
+		page AS (
+			SELECT * FROM hits
+			WHERE %s
+			ORDER BY hits.rank DESC, hits.doc_id ASC
+			LIMIT %d
+		)
+		SELECT page.doc_id, page.path_id, page.path_node_id, page.updated_at, page.rank,
+		       COALESCE(p.title, '') AS path_title,
+		       COALESCE(pn.title, '') AS node_title, COALESCE(pn.index, 0) AS node_index,
+		       ts_headline('english', d.doc_text, tsq.q, ?) AS headline
+		FROM page
+		CROSS JOIN tsq
+		JOIN learning_node_doc d ON d.id = page.doc_id
+		LEFT JOIN path p ON p.id = page.path_id
+		LEFT JOIN path_node pn ON pn.id = page.path_node_id
+		ORDER BY page.rank DESC, page.doc_id ASC
+	`, filters, after, q.Limit)
+
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*NodeDocSearchHit
+	if err := t.WithContext(dbc.Ctx).Raw(sql, args...).Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range out {
+		if h != nil {
+			h.Fragments = parseNodeDocHeadline(h.Headline)
+			h.Headline = ""
+		}
+	}
+	return out, nil
+}
+
+// parseNodeDocHeadline splits a ts_headline result into fragments with rune-offset matches.
+func parseNodeDocHeadline(headline string) []NodeDocSearchFragment {
+	out := []NodeDocSearchFragment{}
+	for _, raw := range strings.Split(headline, nodeDocHeadlineDelimiter) {
+		var b strings.Builder
+		matches := [][2]int{}
+		pos, start := 0, -1
+		for _, r := range raw {
+			switch string(r) {
+			case nodeDocHeadlineStart:
+				start = pos
+			case nodeDocHeadlineStop:
+				if start >= 0 && pos > start {
+					matches = append(matches, [2]int{start, pos})
+				}
+				start = -1
+			default:
+				b.WriteRune(r)
+				pos++
+			}
+		}
+		// Trim surrounding whitespace and shift offsets by what was cut from the front.
+		full := b.String()
+		text := strings.TrimLeftFunc(full, unicode.IsSpace)
+		lead := utf8.RuneCountInString(full) - utf8.RuneCountInString(text)
+		text = strings.TrimRightFunc(text, unicode.IsSpace)
+		if text == "" {
+			continue
+		}
+		for i := range matches {
+			matches[i][0] -= lead
+			matches[i][1] -= lead
+		}
+		n := utf8.RuneCountInString(text)
+		kept := matches[:0]
+		for _, m := range matches {
+			if m[0] < 0 {
+				m[0] = 0
+			}
+			if m[1] > n {
+				m[1] = n
+			}
+			if m[1] > m[0] {
+				kept = append(kept, m)
+			}
+		}
+		out = append(out, NodeDocSearchFragment{Text: text, Matches: kept})
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package learning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestParseNodeDocHeadline(t *testing.T) {
+	got := parseNodeDocHeadline("  A \x02loop\x03 runs é \x02loops\x03\x1e\x1eThen \x02loop\x03 ")
+	if len(got) != 2 {
+		t.Fatalf("fragments = %+v", got)
+	}
+	if got[0].Text != "A loop runs é loops" || len(got[0].Matches) != 2 || got[0].Matches[0] != [2]int{2, 6} || got[0].Matches[1] != [2]int{14, 19} {
+		t.Fatalf("first = %+v", got[0])
+	}
+	if got[1].Text != "Then loop" || got[1].Matches[0] != [2]int{5, 9} {
+		t.Fatalf("second = %+v", got[1])
+	}
+}
+
+func TestNodeDocSearchRepo(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewNodeDocSearchRepo(db, testutil.Logger(t))
+
+	user := testutil.SeedUser(t, dbc, "doc-search@example.com")
+	other := testutil.SeedUser(t, dbc, "doc-search-other@example.com")
+	seedPath := func(owner uuid.UUID, title string) *types.Path {
+		p := &types.Path{ID: uuid.New(), UserID: &owner, Title: title}
+		if err := tx.Create(p).Error; err != nil {
+			t.Fatalf("seed path: %v", err)
+		}
+		return p
+	}
+	seedDoc := func(owner uuid.UUID, path *types.Path, index int, text string) *types.LearningNodeDoc {
+		node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: index, Title: "Node"}
+		if err := tx.Create(node).Error; err != nil {
+			t.Fatalf("seed node: %v", err)
+		}
+		doc := &types.LearningNodeDoc{
+			UserID: owner, PathID: path.ID, PathNodeID: node.ID, SchemaVersion: 1,
+			DocJSON: datatypes.JSON(`{}`), DocText: text, ContentHash: "h", SourcesHash: "s",
+		}
+		if err := tx.Create(doc).Error; err != nil {
+			t.Fatalf("seed doc: %v", err)
+		}
+		return doc
+	}
+
+	a, b := seedPath(user.ID, "Recursion"), seedPath(user.ID, "Iteration")
+	for i := 0; i < 4; i++ {
+		seedDoc(user.ID, a, i+1, "Recursion calls itself until the base case stops the recursion.")
+	}
+	seedDoc(user.ID, b, 1, "A loop repeats work; recursion is an alternative.")
+	seedDoc(user.ID, b, 2, "Nothing relevant here.")
+	seedDoc(other.ID, seedPath(other.ID, "Theirs"), 1, "Recursion for someone else.")
+
+	var all []*NodeDocSearchHit
+	var after *NodeDocSearchCursor
+	for page := 0; page < 10; page++ {
+		hits, err := repo.Search(dbc, NodeDocSearchQuery{UserID: user.ID, Query: "recursion", After: after, Limit: 2})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		all = append(all, hits...)
+		if len(hits) < 2 {
+			break
+		}
+		last := hits[len(hits)-1]
+		after = &NodeDocSearchCursor{Rank: last.Rank, DocID: last.DocID}
+	}
+	if len(all) != 5 {
+		t.Fatalf("hits = %d, want 5", len(all))
+	}
+	seen := map[uuid.UUID]bool{}
+	for i, h := range all {
+		if seen[h.DocID] {
+			t.Fatalf("duplicate hit across pages: %s", h.DocID)
+		}
+		seen[h.DocID] = true
+		if i > 0 && h.Rank > all[i-1].Rank {
+			t.Fatalf("hits not ranked: %v > %v", h.Rank, all[i-1].Rank)
+		}
+		if len(h.Fragments) == 0 || len(h.Fragments[0].Matches) == 0 || h.PathTitle == "" {
+			t.Fatalf("hit missing highlight or titles: %+v", h)
+		}
+	}
+
+	scoped, err := repo.Search(dbc, NodeDocSearchQuery{UserID: user.ID, PathID: b.ID, Query: "recursion"})
+	if err != nil {
+		t.Fatalf("Search(path): %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].PathID != b.ID {
+		t.Fatalf("path-scoped hits = %+v", scoped)
+	}
+}
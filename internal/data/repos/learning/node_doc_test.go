@@ -0,0 +1,337 @@
+package learning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func newTestNodeDoc(userID uuid.UUID, body string) *types.LearningNodeDoc {
+	return &types.LearningNodeDoc{
+		UserID:        userID,
+		PathID:        uuid.New(),
+		PathNodeID:    uuid.New(),
+		SchemaVersion: 1,
+		DocJSON:       datatypes.JSON([]byte(`{"body":"` + body + `"}`)),
+		ContentHash:   body,
+		SourcesHash:   "s",
+	}
+}
+
+func TestLearningNodeDocRepoOptimisticLock(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewLearningNodeDocRepo(db, testutil.Logger(t))
+
+	user := testutil.SeedUser(t, dbc, "node-doc-version@example.com")
+	row := newTestNodeDoc(user.ID, "v1")
+	if err := repo.Upsert(dbc, row); err != nil || row.Version != 1 {
+		t.Fatalf("Upsert(create): version=%d err=%v", row.Version, err)
+	}
+
+	// Two writers read the same version.
+	a, err := repo.GetByPathNodeID(dbc, row.PathNodeID)
+	if err != nil || a == nil {
+		t.Fatalf("GetByPathNodeID(a): %+v %v", a, err)
+	}
+	b, err := repo.GetByPathNodeID(dbc, row.PathNodeID)
+	if err != nil || b == nil {
+		t.Fatalf("GetByPathNodeID(b): %+v %v", b, err)
+	}
+
+	a.DocJSON = datatypes.JSON([]byte(`{"body":"figure regen"}`))
+	if err := repo.UpdateWithVersion(dbc, a, a.Version); err != nil || a.Version != 2 {
+		t.Fatalf("writer A: version=%d err=%v", a.Version, err)
+	}
+	b.DocJSON = datatypes.JSON([]byte(`{"body":"patch"}`))
+	if err := repo.UpdateWithVersion(dbc, b, b.Version); !errors.Is(err, ErrStaleDoc) {
+		t.Fatalf("writer B: expected ErrStaleDoc, got %v", err)
+	}
+	if err := repo.Upsert(dbc, b); !errors.Is(err, ErrStaleDoc) || b.Version != 1 {
+		t.Fatalf("writer B upsert: version=%d err=%v", b.Version, err)
+	}
+
+	// A fresh writer that never read the doc must not clobber it either.
+	fresh := newTestNodeDoc(user.ID, "fresh")
+	fresh.PathNodeID = row.PathNodeID
+	if err := repo.Upsert(dbc, fresh); !errors.Is(err, ErrStaleDoc) {
+		t.Fatalf("fresh upsert: expected ErrStaleDoc, got %v", err)
+	}
+
+	got, err := repo.GetByPathNodeID(dbc, row.PathNodeID)
+	if err != nil || got == nil || got.Version != 2 || string(got.DocJSON) != `{"body": "figure regen"}` {
+		t.Fatalf("after writers: %+v err=%v", got, err)
+	}
+
+	// Rebase: reload and retry succeeds.
+	got.DocJSON = datatypes.JSON([]byte(`{"body":"patch"}`))
+	if err := repo.UpdateWithVersion(dbc, got, got.Version); err != nil || got.Version != 3 {
+		t.Fatalf("rebased writer: version=%d err=%v", got.Version, err)
+	}
+}
+
+func TestLearningNodeDocRepoConcurrentWriters(t *testing.T) {
+	db := testutil.DB(t)
+
+	ctx := context.Background()
+	repo := NewLearningNodeDocRepo(db, testutil.Logger(t))
+
+	row := newTestNodeDoc(uuid.New(), "base")
+	if err := repo.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Where("id = ?", row.ID).Delete(&types.LearningNodeDoc{}).Error
+	})
+
+	const writers = 2
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := *row
+			w.DocJSON = datatypes.JSON([]byte(fmt.Sprintf(`{"writer":%d}`, i)))
+			errs[i] = repo.UpdateWithVersion(dbctx.Context{Ctx: ctx}, &w, row.Version)
+		}(i)
+	}
+	wg.Wait()
+
+	won, stale := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case errors.Is(err, ErrStaleDoc):
+			stale++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if won != 1 || stale != 1 {
+		t.Fatalf("expected exactly one winner, got won=%d stale=%d", won, stale)
+	}
+	got, err := repo.GetByID(dbctx.Context{Ctx: ctx}, row.ID)
+	if err != nil || got == nil || got.Version != row.Version+1 {
+		t.Fatalf("final row: %+v err=%v", got, err)
+	}
+}
+
+func TestLearningNodeDocRepoRejectsOversizedDoc(t *testing.T) {
+	t.Setenv("NODE_DOC_MAX_BYTES", "64")
+	// Size is checked before any query runs, so no database is needed.
+	repo := NewLearningNodeDocRepo(nil, testutil.Logger(t))
+	dbc := dbctx.Context{Ctx: context.Background()}
+
+	big := &types.LearningNodeDoc{
+		ID:         uuid.New(),
+		UserID:     uuid.New(),
+		PathID:     uuid.New(),
+		PathNodeID: uuid.New(),
+		DocJSON:    datatypes.JSON([]byte(fmt.Sprintf(`{"title":%q}`, strings.Repeat("x", 80)))),
+	}
+	if err := repo.Upsert(dbc, big); !errors.Is(err, ErrDocTooLarge) {
+		t.Fatalf("Upsert: expected ErrDocTooLarge, got %v", err)
+	}
+	if err := repo.UpdateWithVersion(dbc, big, 1); !errors.Is(err, ErrDocTooLarge) {
+		t.Fatalf("UpdateWithVersion: expected ErrDocTooLarge, got %v", err)
+	}
+
+	t.Setenv("NODE_DOC_MAX_BYTES", "0")
+	if got := MaxNodeDocBytes(); got != 0 {
+		t.Fatalf("MaxNodeDocBytes with 0: got %d", got)
+	}
+}
+
+func TestLearningNodeDocRepoDuplicateRows(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewLearningNodeDocRepo(db, testutil.Logger(t))
+
+	// The unique index normally rules this out; drop it inside the test tx to rebuild the
+	// state a partially failed migration left behind.
+	if err := tx.Exec(`DROP INDEX IF EXISTS idx_learning_node_doc_node_track`).Error; err != nil {
+		t.Fatalf("drop index: %v", err)
+	}
+
+	user := testutil.SeedUser(t, dbc, "node-doc-dups@example.com")
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	older := newTestNodeDoc(user.ID, "older")
+	older.ID = uuid.MustParse("00000000-0000-0000-0000-00000000000f")
+	older.UpdatedAt = at.Add(-time.Hour)
+	tieLow := newTestNodeDoc(user.ID, "tie-low")
+	tieLow.ID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	tieHigh := newTestNodeDoc(user.ID, "tie-high")
+	tieHigh.ID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	for _, d := range []*types.LearningNodeDoc{tieLow, tieHigh} {
+		d.PathID, d.PathNodeID, d.UpdatedAt = older.PathID, older.PathNodeID, at
+	}
+	single := newTestNodeDoc(user.ID, "single")
+	for _, d := range []*types.LearningNodeDoc{older, tieLow, tieHigh, single} {
+		if err := tx.Create(d).Error; err != nil {
+			t.Fatalf("seed %s: %v", d.ContentHash, err)
+		}
+	}
+
+	// Latest updated_at wins; equal timestamps fall back to the larger id.
+	for i := 0; i < 3; i++ {
+		got, err := repo.GetByPathNodeID(dbc, older.PathNodeID)
+		if err != nil || got == nil || got.ID != tieHigh.ID {
+			t.Fatalf("GetByPathNodeID: %+v err=%v", got, err)
+		}
+	}
+	latest, err := repo.GetLatestByPathNodeIDs(dbc, []uuid.UUID{older.PathNodeID, single.PathNodeID})
+	if err != nil || len(latest) != 2 {
+		t.Fatalf("GetLatestByPathNodeIDs: %d rows err=%v", len(latest), err)
+	}
+	for _, d := range latest {
+		if d.PathNodeID == older.PathNodeID && d.ID != tieHigh.ID {
+			t.Fatalf("latest for duplicated node = %s", d.ContentHash)
+		}
+	}
+	all, err := repo.GetByPathNodeIDs(dbc, []uuid.UUID{older.PathNodeID})
+	if err != nil || len(all) != 3 || all[0].ID != tieHigh.ID || all[1].ID != tieLow.ID || all[2].ID != older.ID {
+		t.Fatalf("GetByPathNodeIDs order: %d rows err=%v", len(all), err)
+	}
+
+	dups, err := repo.ListDuplicatePathNodes(dbc, 0)
+	if err != nil {
+		t.Fatalf("ListDuplicatePathNodes: %v", err)
+	}
+	found := false
+	for _, d := range dups {
+		if d.PathNodeID == single.PathNodeID {
+			t.Fatalf("single-doc node reported as duplicate")
+		}
+		if d.PathNodeID == older.PathNodeID {
+			found = d.Count == 3
+		}
+	}
+	if !found {
+		t.Fatalf("duplicates = %+v", dups)
+	}
+
+	n, err := repo.RepairDuplicates(dbc, older.PathNodeID)
+	if err != nil || n != 2 {
+		t.Fatalf("RepairDuplicates: archived=%d err=%v", n, err)
+	}
+	all, err = repo.GetByPathNodeIDs(dbc, []uuid.UUID{older.PathNodeID})
+	if err != nil || len(all) != 1 || all[0].ID != tieHigh.ID {
+		t.Fatalf("after repair: %d rows err=%v", len(all), err)
+	}
+	var revs []*types.LearningNodeDocRevision
+	if err := tx.Where("doc_id = ? AND operation = ?", tieHigh.ID, NodeDocRevisionOpArchiveDuplicate).
+		Order("created_at ASC").Find(&revs).Error; err != nil || len(revs) != 2 {
+		t.Fatalf("archive revisions: %d err=%v", len(revs), err)
+	}
+	bodies := map[string]bool{}
+	for _, rev := range revs {
+		bodies[string(rev.BeforeJSON)] = true
+	}
+	if !bodies[`{"body": "tie-low"}`] || !bodies[`{"body": "older"}`] {
+		t.Fatalf("archived bodies = %v", bodies)
+	}
+	if n, err := repo.RepairDuplicates(dbc, older.PathNodeID); err != nil || n != 0 {
+		t.Fatalf("second repair: archived=%d err=%v", n, err)
+	}
+}
+
+func TestLearningNodeDocRepoTracks(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewLearningNodeDocRepo(db, testutil.Logger(t))
+	revisions := NewLearningNodeDocRevisionRepo(db, testutil.Logger(t))
+
+	user := testutil.SeedUser(t, dbc, "node-doc-tracks@example.com")
+	primary := newTestNodeDoc(user.ID, "primary")
+	if err := repo.Upsert(dbc, primary); err != nil || primary.Track != types.NodeDocTrackPrimary {
+		t.Fatalf("Upsert(primary): track=%q err=%v", primary.Track, err)
+	}
+	visual := newTestNodeDoc(user.ID, "visual")
+	visual.PathID, visual.PathNodeID, visual.Track = primary.PathID, primary.PathNodeID, types.NodeDocTrackVisual
+	if err := repo.Upsert(dbc, visual); err != nil {
+		t.Fatalf("Upsert(visual): %v", err)
+	}
+	bad := newTestNodeDoc(user.ID, "bad")
+	bad.Track = "podcast"
+	if err := repo.Upsert(dbc, bad); err == nil {
+		t.Fatalf("Upsert(unknown track) should fail")
+	}
+
+	// Node-keyed reads stay on primary; the track read returns the track doc.
+	got, err := repo.GetByPathNodeID(dbc, primary.PathNodeID)
+	if err != nil || got == nil || got.ContentHash != "primary" {
+		t.Fatalf("GetByPathNodeID: %+v err=%v", got, err)
+	}
+	got, err = repo.GetByPathNodeIDAndTrack(dbc, primary.PathNodeID, types.NodeDocTrackVisual)
+	if err != nil || got == nil || got.ContentHash != "visual" {
+		t.Fatalf("GetByPathNodeIDAndTrack(visual): %+v err=%v", got, err)
+	}
+	if got, err := repo.GetByPathNodeIDAndTrack(dbc, primary.PathNodeID, types.NodeDocTrackReading); err != nil || got != nil {
+		t.Fatalf("GetByPathNodeIDAndTrack(reading): %+v err=%v", got, err)
+	}
+	if all, err := repo.GetByPathNodeIDs(dbc, []uuid.UUID{primary.PathNodeID}); err != nil || len(all) != 1 {
+		t.Fatalf("GetByPathNodeIDs: %d rows err=%v", len(all), err)
+	}
+	tracks, err := repo.ListTracksByPathNodeID(dbc, primary.PathNodeID)
+	if err != nil || strings.Join(tracks, ",") != "primary,visual" {
+		t.Fatalf("ListTracksByPathNodeID: %v err=%v", tracks, err)
+	}
+
+	// Re-upserting a track replaces that track's row only.
+	visual2 := newTestNodeDoc(user.ID, "visual-2")
+	visual2.PathID, visual2.PathNodeID, visual2.Track = primary.PathID, primary.PathNodeID, types.NodeDocTrackVisual
+	if err := repo.Upsert(dbc, visual2); err != nil {
+		t.Fatalf("Upsert(visual again): %v", err)
+	}
+	if got, _ := repo.GetByPathNodeID(dbc, primary.PathNodeID); got == nil || got.ContentHash != "primary" {
+		t.Fatalf("primary after track upsert: %+v", got)
+	}
+
+	for _, d := range []*types.LearningNodeDoc{primary, visual} {
+		rev := &types.LearningNodeDocRevision{
+			DocID:      d.ID,
+			UserID:     user.ID,
+			PathID:     d.PathID,
+			PathNodeID: d.PathNodeID,
+			Track:      d.Track,
+			Operation:  "rewrite",
+			Status:     "succeeded",
+			BeforeJSON: datatypes.JSON([]byte(`{}`)),
+			AfterJSON:  datatypes.JSON([]byte(`{}`)),
+		}
+		if _, err := revisions.Create(dbc, []*types.LearningNodeDocRevision{rev}); err != nil {
+			t.Fatalf("Create revision: %v", err)
+		}
+	}
+	for _, track := range []string{"", types.NodeDocTrackVisual} {
+		rows, err := revisions.ListByPathNodeID(dbc, primary.PathNodeID, track, 10)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("ListByPathNodeID(%q): %d rows err=%v", track, len(rows), err)
+		}
+		if want, _ := types.NormalizeNodeDocTrack(track); rows[0].Track != want {
+			t.Fatalf("ListByPathNodeID(%q) track = %q", track, rows[0].Track)
+		}
+	}
+}
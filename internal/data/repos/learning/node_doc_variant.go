@@ -114,8 +114,8 @@ func (r *learningNodeDocVariantRepo) Upsert(dbc dbctx.Context, row *types.Learni
 		row.CreatedAt = now
 	}
 
-	return t.WithContext(dbc.Ctx).
-		Clauses(clause.OnConflict{
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "snapshot_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{
 				"user_id",
@@ -135,6 +135,9 @@ func (r *learningNodeDocVariantRepo) Upsert(dbc dbctx.Context, row *types.Learni
 				"expires_at",
 				"updated_at",
 			}),
-		}).
-		Create(row).Error
+		}).Create(row).Error; err != nil {
+			return err
+		}
+		return replaceNodeAssetRefs(tx, NodeAssetRefOwnerVariant, row.SnapshotID, row.PathNodeID, row.DocJSON)
+	})
 }
@@ -1,6 +1,7 @@
 package learning
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,8 @@ import (
 type LearningNodeFigureRepo interface {
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.LearningNodeFigure, error)
 	GetByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) ([]*types.LearningNodeFigure, error)
+	// HasContentHash reports whether any figure row of the node references the shared blob.
+	HasContentHash(dbc dbctx.Context, pathNodeID uuid.UUID, contentHash string) (bool, error)
 
 	Upsert(dbc dbctx.Context, row *types.LearningNodeFigure) error
 }
@@ -75,6 +78,25 @@ func (r *learningNodeFigureRepo) GetByPathNodeIDs(dbc dbctx.Context, pathNodeIDs
 	return out, nil
 }
 
+func (r *learningNodeFigureRepo) HasContentHash(dbc dbctx.Context, pathNodeID uuid.UUID, contentHash string) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	contentHash = strings.TrimSpace(contentHash)
+	if pathNodeID == uuid.Nil || contentHash == "" {
+		return false, nil
+	}
+	var n int64
+	if err := t.WithContext(dbc.Ctx).
+		Model(&types.LearningNodeFigure{}).
+		Where("path_node_id = ? AND content_hash = ?", pathNodeID, contentHash).
+		Count(&n).Error; err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 func (r *learningNodeFigureRepo) Upsert(dbc dbctx.Context, row *types.LearningNodeFigure) error {
 	t := dbc.Tx
 	if t == nil {
@@ -106,6 +128,7 @@ func (r *learningNodeFigureRepo) Upsert(dbc dbctx.Context, row *types.LearningNo
 				"asset_storage_key",
 				"asset_url",
 				"asset_mime_type",
+				"content_hash",
 				"error",
 				"updated_at",
 			}),
@@ -1,6 +1,7 @@
 package learning
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,8 @@ type PathRepo interface {
 
 	GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.Path, error)
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.Path, error)
+	// GetOutlineVersion reads the path's outline version; ok is false when the path is gone.
+	GetOutlineVersion(dbc dbctx.Context, id uuid.UUID) (version int64, ok bool, err error)
 
 	ListByUser(dbc dbctx.Context, userID *uuid.UUID) ([]*types.Path, error)
 	ListByUserIDs(dbc dbctx.Context, userIDs []uuid.UUID) ([]*types.Path, error)
@@ -24,6 +27,9 @@ type PathRepo interface {
 
 	Update(dbc dbctx.Context, row *types.Path) error
 	UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[string]interface{}) error
+	// MergeMetadata merges patch into the path's metadata object in place, so concurrent writers
+	// of other keys are not overwritten.
+	MergeMetadata(dbc dbctx.Context, id uuid.UUID, patch map[string]any) error
 	RecordView(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, dedupeWindow time.Duration) (viewCount int, lastViewedAt *time.Time, ok bool, err error)
 
 	SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
@@ -190,6 +196,27 @@ func (r *pathRepo) UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[str
 		Updates(updates).Error
 }
 
+func (r *pathRepo) MergeMetadata(dbc dbctx.Context, id uuid.UUID, patch map[string]any) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if id == uuid.Nil || len(patch) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return t.WithContext(dbc.Ctx).
+		Model(&types.Path{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"metadata":   gorm.Expr("(CASE WHEN jsonb_typeof(metadata) = 'object' THEN metadata ELSE '{}'::jsonb END) || ?::jsonb", string(raw)),
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
 type pathViewUpdateRow struct {
 	ViewCount    int        `gorm:"column:view_count"`
 	LastViewedAt *time.Time `gorm:"column:last_viewed_at"`
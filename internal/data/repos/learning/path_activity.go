@@ -0,0 +1,220 @@
+package learning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	PathActivityKindDocRevision     = "doc_revision"
+	PathActivityKindVariantExposure = "variant_exposure"
+	PathActivityKindPrereqGate      = "prereq_gate"
+	PathActivityKindDocGeneration   = "doc_generation"
+	PathActivityKindJobRun          = "job_run"
+
+	PathActivityDefaultLimit = 50
+	PathActivityMaxLimit     = 100
+)
+
+// PathActivityKinds lists every feed source in a stable order.
+var PathActivityKinds = []string{
+	PathActivityKindDocRevision,
+	PathActivityKindVariantExposure,
+	PathActivityKindPrereqGate,
+	PathActivityKindDocGeneration,
+	PathActivityKindJobRun,
+}
+
+// PathActivityCursor is the keyset position of the last row of a page. The feed is ordered by
+// (occurred_at, kind, source_id) descending, which is a total order across sources.
+type PathActivityCursor struct {
+	OccurredAt time.Time
+	Kind       string
+	SourceID   string
+}
+
+type PathActivityQuery struct {
+	UserID uuid.UUID
+	PathID uuid.UUID
+
+	// Build runs are attached either to the path itself, its material set, or the path's job_id.
+	MaterialSetID *uuid.UUID
+	JobID         *uuid.UUID
+
+	// Kinds restricts the sources; empty means all. Unknown kinds match no source, so a filter of
+	// only unknown kinds returns nothing.
+	Kinds  []string
+	Before *PathActivityCursor
+	Limit  int
+}
+
+// PathActivityRow is one normalized feed row. Action/Detail/Status carry the source-specific
+// fields the summary is built from; PolicyVersion/TraceID are internals for debug views.
+type PathActivityRow struct {
+	Kind          string     `gorm:"column:kind"`
+	SourceID      string     `gorm:"column:source_id"`
+	OccurredAt    time.Time  `gorm:"column:occurred_at"`
+	PathNodeID    *uuid.UUID `gorm:"column:path_node_id"`
+	NodeTitle     string     `gorm:"column:node_title"`
+	NodeIndex     int        `gorm:"column:node_index"`
+	Action        string     `gorm:"column:action"`
+	Detail        string     `gorm:"column:detail"`
+	Status        string     `gorm:"column:status"`
+	ItemCount     int        `gorm:"column:item_count"`
+	PolicyVersion string     `gorm:"column:policy_version"`
+	TraceID       string     `gorm:"column:trace_id"`
+}
+
+type PathActivityRepo interface {
+	List(dbc dbctx.Context, q PathActivityQuery) ([]*PathActivityRow, error)
+}
+
+type pathActivityRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewPathActivityRepo(db *gorm.DB, baseLog *logger.Logger) PathActivityRepo {
+	return &pathActivityRepo{db: db, log: baseLog.With("repo", "PathActivityRepo")}
+}
+
+// Every projection must yield the same column list/types so the sources can be UNION ALL'd.
+const (
+	pathActivityDocRevisionSQL = `
+		SELECT 'doc_revision'::text AS kind, r.id::text AS source_id, r.created_at AS occurred_at,
+		       r.path_node_id AS path_node_id, r.operation::text AS action, r.block_type::text AS detail,
+		       r.status::text AS status, 1::bigint AS item_count,
+		       COALESCE(r.prompt_version, '')::text AS policy_version, ''::text AS trace_id
+		FROM learning_node_doc_revision r
+		WHERE r.path_id = ? AND r.user_id = ?`
+
+	// Exposures are collapsed per node, UTC day and exposure kind; the group's latest exposure
+	// is its timestamp, so today's group can move up the feed as more exposures arrive.
+	pathActivityVariantExposureSQL = `
+		SELECT 'variant_exposure'::text AS kind,
+		       e.path_node_id::text || ':' || to_char(date_trunc('day', e.created_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD') || ':' || e.exposure_kind AS source_id,
+		       MAX(e.created_at) AS occurred_at, e.path_node_id AS path_node_id,
+		       e.exposure_kind::text AS action,
+		       string_agg(DISTINCT e.variant_kind, ',' ORDER BY e.variant_kind)::text AS detail,
+		       ''::text AS status, COUNT(*)::bigint AS item_count,
+		       string_agg(DISTINCT e.policy_version, ',' ORDER BY e.policy_version)::text AS policy_version,
+		       COALESCE(MAX(e.trace_id), '')::text AS trace_id
+		FROM doc_variant_exposure e
+		WHERE e.path_id = ? AND e.user_id = ?
+		GROUP BY e.path_node_id, date_trunc('day', e.created_at AT TIME ZONE 'UTC'), e.exposure_kind`
+
+	pathActivityPrereqGateSQL = `
+		SELECT 'prereq_gate'::text AS kind, g.id::text AS source_id, g.created_at AS occurred_at,
+		       g.path_node_id AS path_node_id, g.decision::text AS action, g.reason::text AS detail,
+		       g.readiness_status::text AS status, 1::bigint AS item_count,
+		       g.policy_version::text AS policy_version, g.snapshot_id::text AS trace_id
+		FROM prereq_gate_decision g
+		WHERE g.path_id = ? AND g.user_id = ?`
+
+	pathActivityDocGenerationSQL = `
+		SELECT 'doc_generation'::text AS kind, d.id::text AS source_id, d.created_at AS occurred_at,
+		       d.path_node_id AS path_node_id, d.artifact_type::text AS action, ''::text AS detail,
+		       d.status::text AS status, GREATEST(d.attempt, 1)::bigint AS item_count,
+		       d.prompt_version::text AS policy_version, ''::text AS trace_id
+		FROM learning_doc_generation_run d
+		WHERE d.path_id = ? AND d.user_id = ?`
+
+	pathActivityJobRunSQL = `
+		SELECT 'job_run'::text AS kind, j.id::text AS source_id, j.created_at AS occurred_at,
+		       NULL::uuid AS path_node_id, j.job_type::text AS action, COALESCE(j.stage, '')::text AS detail,
+		       j.status::text AS status, 1::bigint AS item_count,
+		       ''::text AS policy_version, ''::text AS trace_id
+		FROM job_run j
+		WHERE j.owner_user_id = ? AND j.deleted_at IS NULL
+		  AND ((j.entity_type = 'path' AND j.entity_id = ?)
+		    OR (j.entity_type = 'material_set' AND j.entity_id = ?)
+		    OR j.id = ?)`
+)
+
+func (r *pathActivityRepo) List(dbc dbctx.Context, q PathActivityQuery) ([]*PathActivityRow, error) {
+	if q.UserID == uuid.Nil || q.PathID == uuid.Nil {
+		return nil, fmt.Errorf("missing user_id or path_id")
+	}
+	if q.Limit <= 0 {
+		q.Limit = PathActivityDefaultLimit
+	}
+	// Callers page by asking for one row past the page size, so a full page may ask for one more.
+	if q.Limit > PathActivityMaxLimit+1 {
+		q.Limit = PathActivityMaxLimit + 1
+	}
+
+	want := map[string]bool{}
+	for _, k := range q.Kinds {
+		if k = strings.TrimSpace(k); k != "" {
+			want[k] = true
+		}
+	}
+	include := func(kind string) bool { return len(want) == 0 || want[kind] }
+
+	parts := make([]string, 0, len(PathActivityKinds))
+	args := make([]any, 0, 16)
+	if include(PathActivityKindDocRevision) {
+		parts = append(parts, pathActivityDocRevisionSQL)
+		args = append(args, q.PathID, q.UserID)
+	}
+	if include(PathActivityKindVariantExposure) {
+		parts = append(parts, pathActivityVariantExposureSQL)
+		args = append(args, q.PathID, q.UserID)
+	}
+	if include(PathActivityKindPrereqGate) {
+		parts = append(parts, pathActivityPrereqGateSQL)
+		args = append(args, q.PathID, q.UserID)
+	}
+	if include(PathActivityKindDocGeneration) {
+		parts = append(parts, pathActivityDocGenerationSQL)
+		args = append(args, q.PathID, q.UserID)
+	}
+	if include(PathActivityKindJobRun) {
+		materialSetID, jobID := uuid.Nil, uuid.Nil
+		if q.MaterialSetID != nil {
+			materialSetID = *q.MaterialSetID
+		}
+		if q.JobID != nil {
+			jobID = *q.JobID
+		}
+		parts = append(parts, pathActivityJobRunSQL)
+		args = append(args, q.UserID, q.PathID, materialSetID, jobID)
+	}
+	if len(parts) == 0 {
+		return []*PathActivityRow{}, nil
+	}
+
+	where := "TRUE"
+	if q.Before != nil {
+		where = "(ev.occurred_at, ev.kind, ev.source_id) < (?, ?, ?)"
+		args = append(args, q.Before.OccurredAt, q.Before.Kind, q.Before.SourceID)
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT ev.kind, ev.source_id, ev.occurred_at, ev.path_node_id,
+		       COALESCE(pn.title, '') AS node_title, COALESCE(pn.index, 0) AS node_index,
+		       ev.action, ev.detail, ev.status, ev.item_count, ev.policy_version, ev.trace_id
+		FROM (%s) ev
+		LEFT JOIN path_node pn ON pn.id = ev.path_node_id
+		WHERE %s
+		ORDER BY ev.occurred_at DESC, ev.kind DESC, ev.source_id DESC
+		LIMIT %d
+	`, strings.Join(parts, "\n\t\tUNION ALL\n"), where, q.Limit)
+
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*PathActivityRow
+	if err := t.WithContext(dbc.Ctx).Raw(sql, args...).Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
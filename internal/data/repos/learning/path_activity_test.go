@@ -0,0 +1,137 @@
+package learning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestPathActivityRepo(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewPathActivityRepo(db, testutil.Logger(t))
+
+	user := testutil.SeedUser(t, dbc, "path-activity@example.com")
+	set := testutil.SeedMaterialSet(t, dbc, user.ID)
+
+	path := &types.Path{ID: uuid.New(), UserID: &user.ID, MaterialSetID: &set.ID, Title: "Activity"}
+	if err := tx.Create(path).Error; err != nil {
+		t.Fatalf("seed path: %v", err)
+	}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "Intro"}
+	if err := tx.Create(node).Error; err != nil {
+		t.Fatalf("seed node: %v", err)
+	}
+
+	// Same timestamp across every source to exercise tie-breaking.
+	tie := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := tie.Add(-time.Hour)
+
+	emptyJSON := datatypes.JSON([]byte(`{}`))
+	rows := []any{
+		&types.LearningNodeDocRevision{DocID: uuid.New(), UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, BlockID: "b1", BlockType: "paragraph", Operation: "rewrite", CitationPolicy: "reuse_only", BeforeJSON: emptyJSON, AfterJSON: emptyJSON, Status: "succeeded", CreatedAt: tie},
+		&types.DocVariantExposure{UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, ExposureKind: "served", VariantKind: "personalized", PolicyVersion: "v2", CreatedAt: earlier},
+		&types.DocVariantExposure{UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, ExposureKind: "served", VariantKind: "personalized", PolicyVersion: "v2", CreatedAt: tie},
+		&types.PrereqGateDecision{UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, SnapshotID: "snap", PolicyVersion: "gate_v1", SchemaVersion: 1, ReadinessStatus: "not_ready", GateMode: "hard", Decision: "blocked", Reason: "low_readiness", CreatedAt: tie},
+		&types.LearningDocGenerationRun{ArtifactType: "node_doc", UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, Status: "succeeded", Model: "m", PromptVersion: "p1", Attempt: 2, CreatedAt: tie},
+		&types.JobRun{OwnerUserID: user.ID, JobType: "learning_build", EntityType: "material_set", EntityID: &set.ID, Status: "succeeded", Stage: "done", CreatedAt: tie, UpdatedAt: tie},
+		// Other users' rows for the same path must never leak into the feed.
+		&types.JobRun{OwnerUserID: uuid.New(), JobType: "learning_build", EntityType: "path", EntityID: &path.ID, Status: "queued", Stage: "queued", CreatedAt: tie, UpdatedAt: tie},
+	}
+	for _, r := range rows {
+		if err := tx.Create(r).Error; err != nil {
+			t.Fatalf("seed %T: %v", r, err)
+		}
+	}
+
+	q := PathActivityQuery{UserID: user.ID, PathID: path.ID, MaterialSetID: &set.ID}
+	all, err := repo.List(dbc, q)
+	if err != nil {
+		t.Fatalf("List(all): %v", err)
+	}
+	wantOrder := []string{
+		PathActivityKindVariantExposure,
+		PathActivityKindPrereqGate,
+		PathActivityKindJobRun,
+		PathActivityKindDocRevision,
+		PathActivityKindDocGeneration,
+	}
+	if len(all) != len(wantOrder) {
+		t.Fatalf("List(all): got %d rows, want %d", len(all), len(wantOrder))
+	}
+	for i, k := range wantOrder {
+		if all[i].Kind != k || !all[i].OccurredAt.Equal(tie) {
+			t.Fatalf("row %d: kind=%s occurred_at=%s, want kind=%s at %s", i, all[i].Kind, all[i].OccurredAt, k, tie)
+		}
+	}
+	if exp := all[0]; exp.ItemCount != 2 || exp.PolicyVersion != "v2" || exp.NodeTitle != "Intro" {
+		t.Fatalf("collapsed exposure: %+v", exp)
+	}
+
+	// Paging one row at a time across the tie must visit every row exactly once, in order.
+	var before *PathActivityCursor
+	for i := range wantOrder {
+		page, err := repo.List(dbc, PathActivityQuery{UserID: user.ID, PathID: path.ID, MaterialSetID: &set.ID, Before: before, Limit: 1})
+		if err != nil || len(page) != 1 || page[0].Kind != wantOrder[i] {
+			t.Fatalf("page %d: rows=%+v err=%v", i, page, err)
+		}
+		before = &PathActivityCursor{OccurredAt: page[0].OccurredAt, Kind: page[0].Kind, SourceID: page[0].SourceID}
+	}
+	if page, err := repo.List(dbc, PathActivityQuery{UserID: user.ID, PathID: path.ID, MaterialSetID: &set.ID, Before: before}); err != nil || len(page) != 0 {
+		t.Fatalf("past last page: rows=%+v err=%v", page, err)
+	}
+
+	cases := []struct {
+		kinds []string
+		want  []string
+	}{
+		{kinds: []string{PathActivityKindJobRun}, want: []string{PathActivityKindJobRun}},
+		{kinds: []string{PathActivityKindDocRevision, PathActivityKindDocGeneration}, want: []string{PathActivityKindDocRevision, PathActivityKindDocGeneration}},
+		{kinds: []string{PathActivityKindPrereqGate, PathActivityKindVariantExposure}, want: []string{PathActivityKindVariantExposure, PathActivityKindPrereqGate}},
+		{kinds: []string{"unknown"}, want: nil},
+		{kinds: []string{"unknown", PathActivityKindJobRun}, want: []string{PathActivityKindJobRun}},
+	}
+	for _, tc := range cases {
+		q := PathActivityQuery{UserID: user.ID, PathID: path.ID, MaterialSetID: &set.ID, Kinds: tc.kinds}
+		got, err := repo.List(dbc, q)
+		if err != nil {
+			t.Fatalf("List(%v): %v", tc.kinds, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("List(%v): got %d rows, want %d", tc.kinds, len(got), len(tc.want))
+		}
+		for i := range tc.want {
+			if got[i].Kind != tc.want[i] {
+				t.Fatalf("List(%v)[%d]: kind=%s want %s", tc.kinds, i, got[i].Kind, tc.want[i])
+			}
+		}
+	}
+
+	// A full max-size page plus the caller's lookahead row must come back, or callers can't tell
+	// that another page exists.
+	for i := 0; i < PathActivityMaxLimit+1; i++ {
+		at := earlier.Add(-time.Duration(i+1) * time.Minute)
+		rev := &types.LearningNodeDocRevision{DocID: uuid.New(), UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, BlockID: "b1", BlockType: "paragraph", Operation: "rewrite", CitationPolicy: "reuse_only", BeforeJSON: emptyJSON, AfterJSON: emptyJSON, Status: "succeeded", CreatedAt: at}
+		if err := tx.Create(rev).Error; err != nil {
+			t.Fatalf("seed revision %d: %v", i, err)
+		}
+	}
+	revisions := PathActivityQuery{UserID: user.ID, PathID: path.ID, MaterialSetID: &set.ID, Kinds: []string{PathActivityKindDocRevision}, Limit: PathActivityMaxLimit + 1}
+	if page, err := repo.List(dbc, revisions); err != nil || len(page) != PathActivityMaxLimit+1 {
+		t.Fatalf("List(max+1): rows=%d err=%v", len(page), err)
+	}
+	revisions.Limit = PathActivityMaxLimit * 2
+	if page, err := repo.List(dbc, revisions); err != nil || len(page) != PathActivityMaxLimit+1 {
+		t.Fatalf("List(over max): rows=%d err=%v", len(page), err)
+	}
+}
@@ -0,0 +1,101 @@
+package learning
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type PathFocusRepo interface {
+	// Start ends the user's open focus on the path (if any) and stores row in one transaction.
+	Start(dbc dbctx.Context, row *types.PathFocus) error
+	// GetActive returns the user's focus on the path that applies at now, or nil. Expired
+	// focuses are never returned, whether or not they were ended.
+	GetActive(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, now time.Time) (*types.PathFocus, error)
+	// End ends the user's active focus on the path; it reports false when none was active.
+	End(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, at time.Time) (bool, error)
+}
+
+type pathFocusRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewPathFocusRepo(db *gorm.DB, baseLog *logger.Logger) PathFocusRepo {
+	return &pathFocusRepo{db: db, log: baseLog.With("repo", "PathFocusRepo")}
+}
+
+func (r *pathFocusRepo) Start(dbc dbctx.Context, row *types.PathFocus) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil || row.UserID == uuid.Nil || row.PathID == uuid.Nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if len(row.ConceptKeys) == 0 {
+		row.ConceptKeys = []byte("[]")
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.PathFocus{}).
+			Where("user_id = ? AND path_id = ? AND ended_at IS NULL", row.UserID, row.PathID).
+			Updates(map[string]interface{}{"ended_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Create(row).Error
+	})
+}
+
+func (r *pathFocusRepo) GetActive(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, now time.Time) (*types.PathFocus, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil || pathID == uuid.Nil {
+		return nil, nil
+	}
+	var row types.PathFocus
+	err := t.WithContext(dbc.Ctx).
+		Where("user_id = ? AND path_id = ? AND ended_at IS NULL AND until > ?", userID, pathID, now).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	if row.ID == uuid.Nil {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (r *pathFocusRepo) End(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, at time.Time) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil || pathID == uuid.Nil {
+		return false, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.PathFocus{}).
+		Where("user_id = ? AND path_id = ? AND ended_at IS NULL AND until > ?", userID, pathID, at).
+		Updates(map[string]interface{}{"ended_at": at, "updated_at": at})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,107 @@
+package learning
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// PathOutlineRow is one node of a path outline plus the content hash of its committed doc
+// ("" when the node has no doc yet).
+type PathOutlineRow struct {
+	Node           *types.PathNode
+	DocContentHash string
+	// DocDigest is the node doc's stored digest (version 0 without a doc or digest).
+	DocDigest types.NodeDocDigest
+}
+
+// bumpPathOutlineVersion invalidates outline read models for the given paths. Node and doc
+// writers call it in the transaction that performs the write, so a reader can never observe
+// the new rows under the old version.
+func bumpPathOutlineVersion(tx *gorm.DB, pathIDs ...uuid.UUID) error {
+	ids := make([]uuid.UUID, 0, len(pathIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range pathIDs {
+		if id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Exec(`UPDATE path SET outline_version = outline_version + 1 WHERE id IN ?`, ids).Error
+}
+
+// bumpPathOutlineVersionForNodes is bumpPathOutlineVersion keyed by node; it must run before a
+// hard delete removes the node rows it resolves the paths from.
+func bumpPathOutlineVersionForNodes(tx *gorm.DB, nodeIDs []uuid.UUID) error {
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+	return tx.Exec(
+		`UPDATE path SET outline_version = outline_version + 1 WHERE id IN (SELECT path_id FROM path_node WHERE id IN ?)`,
+		nodeIDs,
+	).Error
+}
+
+func (r *pathRepo) GetOutlineVersion(dbc dbctx.Context, id uuid.UUID) (int64, bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if id == uuid.Nil {
+		return 0, false, nil
+	}
+	var versions []int64
+	if err := t.WithContext(dbc.Ctx).
+		Model(&types.Path{}).
+		Where("id = ?", id).
+		Limit(1).
+		Pluck("outline_version", &versions).Error; err != nil {
+		return 0, false, err
+	}
+	if len(versions) == 0 {
+		return 0, false, nil
+	}
+	return versions[0], true, nil
+}
+
+type pathOutlineScanRow struct {
+	types.PathNode
+	DocContentHash *string `gorm:"column:doc_content_hash"`
+	DocDigest      []byte  `gorm:"column:doc_digest"`
+}
+
+func (r *pathNodeRepo) ListOutlineByPathID(dbc dbctx.Context, pathID uuid.UUID) ([]PathOutlineRow, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathID == uuid.Nil {
+		return []PathOutlineRow{}, nil
+	}
+	var scanned []pathOutlineScanRow
+	if err := t.WithContext(dbc.Ctx).
+		Table("path_node").
+		Select("path_node.*, learning_node_doc.content_hash AS doc_content_hash, learning_node_doc.digest AS doc_digest").
+		Joins("LEFT JOIN learning_node_doc ON learning_node_doc.path_node_id = path_node.id AND learning_node_doc.track = ?", types.NodeDocTrackPrimary).
+		Where("path_node.path_id = ? AND path_node.deleted_at IS NULL", pathID).
+		Order("path_node.index ASC").
+		Scan(&scanned).Error; err != nil {
+		return nil, err
+	}
+	out := make([]PathOutlineRow, 0, len(scanned))
+	for i := range scanned {
+		node := scanned[i].PathNode
+		row := PathOutlineRow{Node: &node}
+		if scanned[i].DocContentHash != nil {
+			row.DocContentHash = *scanned[i].DocContentHash
+		}
+		row.DocDigest = types.DecodeNodeDocDigest(scanned[i].DocDigest)
+		out = append(out, row)
+	}
+	return out, nil
+}
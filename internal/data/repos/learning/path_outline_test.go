@@ -0,0 +1,104 @@
+package learning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestPathOutlineVersionBumpedByNodeAndDocWrites(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	log := testutil.Logger(t)
+	paths := NewPathRepo(db, log)
+	nodes := NewPathNodeRepo(db, log)
+	docs := NewLearningNodeDocRepo(db, log)
+
+	user := testutil.SeedUser(t, dbc, "path-outline-version@example.com")
+	created, err := paths.Create(dbc, []*types.Path{{UserID: &user.ID, Title: "Outline"}})
+	if err != nil {
+		t.Fatalf("Create(path): %v", err)
+	}
+	pathID := created[0].ID
+
+	version := func() int64 {
+		t.Helper()
+		v, ok, err := paths.GetOutlineVersion(dbc, pathID)
+		if err != nil || !ok {
+			t.Fatalf("GetOutlineVersion: %d %v %v", v, ok, err)
+		}
+		return v
+	}
+	expectBump := func(step string, write func() error) {
+		t.Helper()
+		before := version()
+		if err := write(); err != nil {
+			t.Fatalf("%s: %v", step, err)
+		}
+		if after := version(); after <= before {
+			t.Fatalf("%s left outline_version at %d", step, after)
+		}
+	}
+
+	second := &types.PathNode{PathID: pathID, Index: 1, Title: "Second"}
+	first := &types.PathNode{PathID: pathID, Index: 0, Title: "First"}
+	expectBump("Create(nodes)", func() error {
+		_, err := nodes.Create(dbc, []*types.PathNode{second, first})
+		return err
+	})
+	expectBump("UpdateFields(node)", func() error {
+		return nodes.UpdateFields(dbc, first.ID, map[string]interface{}{"title": "First, renamed"})
+	})
+	expectBump("Upsert(node)", func() error {
+		return nodes.Upsert(dbc, &types.PathNode{PathID: pathID, Index: 1, Title: "Second, regenerated"})
+	})
+
+	doc := &types.LearningNodeDoc{
+		UserID:        user.ID,
+		PathID:        pathID,
+		PathNodeID:    first.ID,
+		SchemaVersion: 1,
+		DocJSON:       datatypes.JSON(`{"blocks":[]}`),
+		ContentHash:   "h1",
+		SourcesHash:   "s",
+	}
+	expectBump("Upsert(doc)", func() error { return docs.Upsert(dbc, doc) })
+	doc.ContentHash = "h2"
+	expectBump("UpdateWithVersion(doc)", func() error { return docs.UpdateWithVersion(dbc, doc, doc.Version) })
+
+	rows, err := nodes.ListOutlineByPathID(dbc, pathID)
+	if err != nil {
+		t.Fatalf("ListOutlineByPathID: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Node.ID != first.ID || rows[0].Node.Title != "First, renamed" || rows[1].Node.Title != "Second, regenerated" {
+		t.Fatalf("outline rows = %+v", rows)
+	}
+	if rows[0].DocContentHash != "h2" || rows[1].DocContentHash != "" {
+		t.Fatalf("doc hashes = %q, %q", rows[0].DocContentHash, rows[1].DocContentHash)
+	}
+
+	expectBump("SoftDeleteByIDs(node)", func() error { return nodes.SoftDeleteByIDs(dbc, []uuid.UUID{second.ID}) })
+	if rows, _ := nodes.ListOutlineByPathID(dbc, pathID); len(rows) != 1 {
+		t.Fatalf("soft-deleted node still in outline: %+v", rows)
+	}
+
+	// A rejected (stale) doc write changes nothing, so it must not invalidate outlines either.
+	before := version()
+	stale := *doc
+	stale.Version = 0
+	if err := docs.Upsert(dbc, &stale); err == nil {
+		t.Fatalf("stale Upsert succeeded")
+	}
+	if after := version(); after != before {
+		t.Fatalf("stale doc write bumped outline_version %d -> %d", before, after)
+	}
+}
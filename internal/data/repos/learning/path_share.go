@@ -0,0 +1,126 @@
+package learning
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type PathShareRepo interface {
+	Create(dbc dbctx.Context, row *types.PathShare) error
+	// GetByTokenHash always reads the row, revoked or not, so callers see revocation immediately.
+	GetByTokenHash(dbc dbctx.Context, tokenHash string) (*types.PathShare, error)
+	ListByPath(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID) ([]*types.PathShare, error)
+	// Revoke marks an owner's share revoked; it reports false when no unrevoked share matched.
+	Revoke(dbc dbctx.Context, userID uuid.UUID, shareID uuid.UUID, at time.Time) (bool, error)
+	RecordAccess(dbc dbctx.Context, shareID uuid.UUID, at time.Time) error
+}
+
+type pathShareRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewPathShareRepo(db *gorm.DB, baseLog *logger.Logger) PathShareRepo {
+	return &pathShareRepo{db: db, log: baseLog.With("repo", "PathShareRepo")}
+}
+
+func (r *pathShareRepo) Create(dbc dbctx.Context, row *types.PathShare) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if row.Scope == "" {
+		row.Scope = "read_only"
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	return t.WithContext(dbc.Ctx).Create(row).Error
+}
+
+func (r *pathShareRepo) GetByTokenHash(dbc dbctx.Context, tokenHash string) (*types.PathShare, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if tokenHash == "" {
+		return nil, nil
+	}
+	var row types.PathShare
+	if err := t.WithContext(dbc.Ctx).Where("token_hash = ?", tokenHash).Limit(1).Find(&row).Error; err != nil {
+		return nil, err
+	}
+	if row.ID == uuid.Nil {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (r *pathShareRepo) ListByPath(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID) ([]*types.PathShare, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.PathShare
+	if userID == uuid.Nil || pathID == uuid.Nil {
+		return out, nil
+	}
+	err := t.WithContext(dbc.Ctx).
+		Where("user_id = ? AND path_id = ?", userID, pathID).
+		Order("created_at DESC").
+		Limit(1000).
+		Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *pathShareRepo) Revoke(dbc dbctx.Context, userID uuid.UUID, shareID uuid.UUID, at time.Time) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil || shareID == uuid.Nil {
+		return false, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.PathShare{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", shareID, userID).
+		Updates(map[string]interface{}{"revoked_at": at, "updated_at": at})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *pathShareRepo) RecordAccess(dbc dbctx.Context, shareID uuid.UUID, at time.Time) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if shareID == uuid.Nil {
+		return nil
+	}
+	return t.WithContext(dbc.Ctx).
+		Model(&types.PathShare{}).
+		Where("id = ?", shareID).
+		UpdateColumns(map[string]interface{}{
+			"view_count":       gorm.Expr("view_count + 1"),
+			"last_accessed_at": at,
+		}).Error
+}
@@ -1,6 +1,7 @@
 package learning
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,10 +20,17 @@ type PathNodeRepo interface {
 	GetByID(dbc dbctx.Context, id uuid.UUID) (*types.PathNode, error)
 	GetByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUID) ([]*types.PathNode, error)
 	GetByPathAndIndex(dbc dbctx.Context, pathID uuid.UUID, index int) (*types.PathNode, error)
+	// ListOutlineByPathID loads the path's live nodes in index order with their doc content
+	// hashes in one query.
+	ListOutlineByPathID(dbc dbctx.Context, pathID uuid.UUID) ([]PathOutlineRow, error)
 
 	Upsert(dbc dbctx.Context, row *types.PathNode) error
 	Update(dbc dbctx.Context, row *types.PathNode) error
 	UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[string]interface{}) error
+	// SetDescription merges the node's displayed description, its source and any extra keys
+	// into metadata. A derived_from_doc write is skipped while the stored description is
+	// user-edited; updated is false when that, or a missing node, stopped it.
+	SetDescription(dbc dbctx.Context, id uuid.UUID, description, source string, extra map[string]any) (updated bool, err error)
 
 	SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
 	SoftDeleteByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUID) error
@@ -47,7 +55,19 @@ func (r *pathNodeRepo) Create(dbc dbctx.Context, rows []*types.PathNode) ([]*typ
 	if len(rows) == 0 {
 		return []*types.PathNode{}, nil
 	}
-	if err := t.WithContext(dbc.Ctx).Create(&rows).Error; err != nil {
+	err := t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		pathIDs := make([]uuid.UUID, 0, len(rows))
+		for _, row := range rows {
+			if row != nil {
+				pathIDs = append(pathIDs, row.PathID)
+			}
+		}
+		return bumpPathOutlineVersion(tx, pathIDs...)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -135,8 +155,8 @@ func (r *pathNodeRepo) Upsert(dbc dbctx.Context, row *types.PathNode) error {
 	}
 	row.UpdatedAt = time.Now().UTC()
 
-	return t.WithContext(dbc.Ctx).
-		Clauses(clause.OnConflict{
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "path_id"}, {Name: "index"}},
 			DoUpdates: clause.AssignmentColumns([]string{
 				"title",
@@ -147,7 +167,11 @@ func (r *pathNodeRepo) Upsert(dbc dbctx.Context, row *types.PathNode) error {
 				"updated_at",
 			}),
 		}).
-		Create(row).Error
+			Create(row).Error; err != nil {
+			return err
+		}
+		return bumpPathOutlineVersion(tx, row.PathID)
+	})
 }
 
 func (r *pathNodeRepo) Update(dbc dbctx.Context, row *types.PathNode) error {
@@ -158,7 +182,12 @@ func (r *pathNodeRepo) Update(dbc dbctx.Context, row *types.PathNode) error {
 	if row == nil {
 		return nil
 	}
-	return t.WithContext(dbc.Ctx).Save(row).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(row).Error; err != nil {
+			return err
+		}
+		return bumpPathOutlineVersion(tx, row.PathID)
+	})
 }
 
 func (r *pathNodeRepo) UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[string]interface{}) error {
@@ -175,10 +204,49 @@ func (r *pathNodeRepo) UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map
 	if _, ok := updates["updated_at"]; !ok {
 		updates["updated_at"] = time.Now().UTC()
 	}
-	return t.WithContext(dbc.Ctx).
-		Model(&types.PathNode{}).
-		Where("id = ?", id).
-		Updates(updates).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.PathNode{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		return bumpPathOutlineVersionForNodes(tx, []uuid.UUID{id})
+	})
+}
+
+func (r *pathNodeRepo) SetDescription(dbc dbctx.Context, id uuid.UUID, description, source string, extra map[string]any) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if id == uuid.Nil || source == "" {
+		return false, nil
+	}
+	patch := map[string]any{}
+	for k, v := range extra {
+		patch[k] = v
+	}
+	patch[types.PathNodeDescriptionKey] = description
+	patch[types.PathNodeDescriptionSourceKey] = source
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return false, err
+	}
+	var updated bool
+	err = t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&types.PathNode{}).Where("id = ? AND deleted_at IS NULL", id)
+		if source == types.PathNodeDescriptionSourceDoc {
+			q = q.Where("COALESCE(metadata->>?, '') <> ?", types.PathNodeDescriptionSourceKey, types.PathNodeDescriptionSourceUser)
+		}
+		res := q.Updates(map[string]any{
+			"metadata":   gorm.Expr("(CASE WHEN jsonb_typeof(metadata) = 'object' THEN metadata ELSE '{}'::jsonb END) || ?::jsonb", string(raw)),
+			"updated_at": time.Now().UTC(),
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		updated = true
+		return bumpPathOutlineVersionForNodes(tx, []uuid.UUID{id})
+	})
+	return updated, err
 }
 
 func (r *pathNodeRepo) SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error {
@@ -189,7 +257,12 @@ func (r *pathNodeRepo) SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
 	if len(ids) == 0 {
 		return nil
 	}
-	return t.WithContext(dbc.Ctx).Where("id IN ?", ids).Delete(&types.PathNode{}).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := bumpPathOutlineVersionForNodes(tx, ids); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&types.PathNode{}).Error
+	})
 }
 
 func (r *pathNodeRepo) SoftDeleteByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUID) error {
@@ -200,7 +273,12 @@ func (r *pathNodeRepo) SoftDeleteByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUI
 	if len(pathIDs) == 0 {
 		return nil
 	}
-	return t.WithContext(dbc.Ctx).Where("path_id IN ?", pathIDs).Delete(&types.PathNode{}).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("path_id IN ?", pathIDs).Delete(&types.PathNode{}).Error; err != nil {
+			return err
+		}
+		return bumpPathOutlineVersion(tx, pathIDs...)
+	})
 }
 
 func (r *pathNodeRepo) FullDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error {
@@ -211,7 +289,12 @@ func (r *pathNodeRepo) FullDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
 	if len(ids) == 0 {
 		return nil
 	}
-	return t.WithContext(dbc.Ctx).Unscoped().Where("id IN ?", ids).Delete(&types.PathNode{}).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := bumpPathOutlineVersionForNodes(tx, ids); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&types.PathNode{}).Error
+	})
 }
 
 func (r *pathNodeRepo) FullDeleteByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUID) error {
@@ -222,5 +305,10 @@ func (r *pathNodeRepo) FullDeleteByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUI
 	if len(pathIDs) == 0 {
 		return nil
 	}
-	return t.WithContext(dbc.Ctx).Unscoped().Where("path_id IN ?", pathIDs).Delete(&types.PathNode{}).Error
+	return t.WithContext(dbc.Ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("path_id IN ?", pathIDs).Delete(&types.PathNode{}).Error; err != nil {
+			return err
+		}
+		return bumpPathOutlineVersion(tx, pathIDs...)
+	})
 }
@@ -24,6 +24,10 @@ type PathNodeActivityRepo interface {
 	Upsert(dbc dbctx.Context, row *types.PathNodeActivity) error
 	Update(dbc dbctx.Context, row *types.PathNodeActivity) error
 	UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[string]interface{}) error
+	// ReplaceForPathNode makes rows the node's complete linkage set. Links not in rows are
+	// removed (the activities themselves are untouched); the rest are upserted with their rank,
+	// primary flag and role. Run it inside a transaction.
+	ReplaceForPathNode(dbc dbctx.Context, pathNodeID uuid.UUID, rows []*types.PathNodeActivity) error
 
 	SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error
 	SoftDeleteByPathNodeIDs(dbc dbctx.Context, pathNodeIDs []uuid.UUID) error
@@ -197,6 +201,60 @@ func (r *pathNodeActivityRepo) UpdateFields(dbc dbctx.Context, id uuid.UUID, upd
 		Updates(updates).Error
 }
 
+func (r *pathNodeActivityRepo) ReplaceForPathNode(dbc dbctx.Context, pathNodeID uuid.UUID, rows []*types.PathNodeActivity) error {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if pathNodeID == uuid.Nil {
+		return nil
+	}
+	keep := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if row != nil && row.ActivityID != uuid.Nil {
+			keep = append(keep, row.ActivityID)
+		}
+	}
+
+	// Hard delete: the (path_node_id, activity_id) unique index also covers soft-deleted rows.
+	del := t.WithContext(dbc.Ctx).Unscoped().Where("path_node_id = ?", pathNodeID)
+	if len(keep) > 0 {
+		del = del.Where("activity_id NOT IN ?", keep)
+	}
+	if err := del.Delete(&types.PathNodeActivity{}).Error; err != nil {
+		return err
+	}
+	if len(keep) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	upserts := make([]*types.PathNodeActivity, 0, len(rows))
+	for _, row := range rows {
+		if row == nil || row.ActivityID == uuid.Nil {
+			continue
+		}
+		row.PathNodeID = pathNodeID
+		if row.ID == uuid.Nil {
+			row.ID = uuid.New()
+		}
+		row.UpdatedAt = now
+		upserts = append(upserts, row)
+	}
+	return t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "path_node_id"}, {Name: "activity_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"rank",
+				"is_primary",
+				"role",
+				"updated_at",
+				"deleted_at",
+			}),
+		}).
+		Create(&upserts).Error
+}
+
 func (r *pathNodeActivityRepo) SoftDeleteByIDs(dbc dbctx.Context, ids []uuid.UUID) error {
 	t := dbc.Tx
 	if t == nil {
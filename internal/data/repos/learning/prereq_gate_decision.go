@@ -14,6 +14,8 @@ import (
 
 type PrereqGateDecisionRepo interface {
 	GetLatestByUserAndNode(dbc dbctx.Context, userID, pathNodeID uuid.UUID) (*types.PrereqGateDecision, error)
+	// ListLatestByUserAndPath returns the latest decision for each of the path's nodes.
+	ListLatestByUserAndPath(dbc dbctx.Context, userID, pathID uuid.UUID) ([]*types.PrereqGateDecision, error)
 	Upsert(dbc dbctx.Context, row *types.PrereqGateDecision) error
 }
 
@@ -48,6 +50,27 @@ func (r *prereqGateDecisionRepo) GetLatestByUserAndNode(dbc dbctx.Context, userI
 	return &out, nil
 }
 
+func (r *prereqGateDecisionRepo) ListLatestByUserAndPath(dbc dbctx.Context, userID, pathID uuid.UUID) ([]*types.PrereqGateDecision, error) {
+	if userID == uuid.Nil || pathID == uuid.Nil {
+		return nil, nil
+	}
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.PrereqGateDecision
+	err := t.WithContext(dbc.Ctx).Raw(`
+		SELECT DISTINCT ON (path_node_id) *
+		FROM prereq_gate_decision
+		WHERE user_id = ? AND path_id = ?
+		ORDER BY path_node_id, created_at DESC
+	`, userID, pathID).Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *prereqGateDecisionRepo) Upsert(dbc dbctx.Context, row *types.PrereqGateDecision) error {
 	t := dbc.Tx
 	if t == nil {
@@ -80,6 +103,8 @@ func (r *prereqGateDecisionRepo) Upsert(dbc dbctx.Context, row *types.PrereqGate
 				"decision",
 				"reason",
 				"evidence_json",
+				// Re-evaluating to an earlier snapshot makes it the latest decision again.
+				"created_at",
 			}),
 		}).
 		Create(row).Error
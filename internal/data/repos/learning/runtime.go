@@ -20,11 +20,14 @@ type PathRunRepo interface {
 
 type NodeRunRepo interface {
 	GetByUserAndNodeID(dbc dbctx.Context, userID uuid.UUID, nodeID uuid.UUID) (*types.NodeRun, error)
+	ListByUser(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.NodeRun, error)
+	ListByUserAndNodeIDs(dbc dbctx.Context, userID uuid.UUID, nodeIDs []uuid.UUID) ([]*types.NodeRun, error)
 	Upsert(dbc dbctx.Context, row *types.NodeRun) error
 }
 
 type ActivityRunRepo interface {
 	GetByUserAndActivityID(dbc dbctx.Context, userID uuid.UUID, activityID uuid.UUID) (*types.ActivityRun, error)
+	GetByUserAndActivityIDs(dbc dbctx.Context, userID uuid.UUID, activityIDs []uuid.UUID) ([]*types.ActivityRun, error)
 	Upsert(dbc dbctx.Context, row *types.ActivityRun) error
 }
 
@@ -157,6 +160,48 @@ func (r *nodeRunRepo) GetByUserAndNodeID(dbc dbctx.Context, userID uuid.UUID, no
 	return &row, nil
 }
 
+func (r *nodeRunRepo) ListByUser(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.NodeRun, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.NodeRun{}
+	if userID == uuid.Nil {
+		return out, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	if limit > 5000 {
+		limit = 5000
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *nodeRunRepo) ListByUserAndNodeIDs(dbc dbctx.Context, userID uuid.UUID, nodeIDs []uuid.UUID) ([]*types.NodeRun, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.NodeRun{}
+	if userID == uuid.Nil || len(nodeIDs) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ? AND node_id IN ?", userID, nodeIDs).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *nodeRunRepo) Upsert(dbc dbctx.Context, row *types.NodeRun) error {
 	t := dbc.Tx
 	if t == nil {
@@ -213,6 +258,23 @@ func (r *activityRunRepo) GetByUserAndActivityID(dbc dbctx.Context, userID uuid.
 	return &row, nil
 }
 
+func (r *activityRunRepo) GetByUserAndActivityIDs(dbc dbctx.Context, userID uuid.UUID, activityIDs []uuid.UUID) ([]*types.ActivityRun, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	var out []*types.ActivityRun
+	if userID == uuid.Nil || len(activityIDs) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ? AND activity_id IN ?", userID, activityIDs).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *activityRunRepo) Upsert(dbc dbctx.Context, row *types.ActivityRun) error {
 	t := dbc.Tx
 	if t == nil {
@@ -14,6 +14,9 @@ import (
 
 type UserCompletedUnitRepo interface {
 	Get(dbc dbctx.Context, userID uuid.UUID, chainKey string) (*types.UserCompletedUnit, error)
+	ListByUserAndChainKeys(dbc dbctx.Context, userID uuid.UUID, chainKeys []string) ([]*types.UserCompletedUnit, error)
+	// ListByUserAndChainKeysForUpdate is ListByUserAndChainKeys with the rows locked until dbc.Tx ends.
+	ListByUserAndChainKeysForUpdate(dbc dbctx.Context, userID uuid.UUID, chainKeys []string) ([]*types.UserCompletedUnit, error)
 	Upsert(dbc dbctx.Context, row *types.UserCompletedUnit) error
 	ListByUser(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserCompletedUnit, error)
 }
@@ -49,6 +52,57 @@ func (r *userCompletedUnitRepo) Get(dbc dbctx.Context, userID uuid.UUID, chainKe
 	return &row, nil
 }
 
+func (r *userCompletedUnitRepo) ListByUserAndChainKeys(dbc dbctx.Context, userID uuid.UUID, chainKeys []string) ([]*types.UserCompletedUnit, error) {
+	return r.listByUserAndChainKeys(dbc, userID, chainKeys, false)
+}
+
+func (r *userCompletedUnitRepo) ListByUserAndChainKeysForUpdate(dbc dbctx.Context, userID uuid.UUID, chainKeys []string) ([]*types.UserCompletedUnit, error) {
+	return r.listByUserAndChainKeys(dbc, userID, chainKeys, true)
+}
+
+// listByUserAndChainKeys looks chainKeys (deduped) up in chunks that stay under the bind
+// parameter cap.
+func (r *userCompletedUnitRepo) listByUserAndChainKeys(dbc dbctx.Context, userID uuid.UUID, chainKeys []string, forUpdate bool) ([]*types.UserCompletedUnit, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.UserCompletedUnit{}
+	if userID == uuid.Nil {
+		return out, nil
+	}
+	keys := make([]string, 0, len(chainKeys))
+	seen := make(map[string]struct{}, len(chainKeys))
+	for _, k := range chainKeys {
+		if k == "" {
+			continue
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	for start := 0; start < len(keys); start += conceptIDQueryChunk {
+		end := start + conceptIDQueryChunk
+		if end > len(keys) {
+			end = len(keys)
+		}
+		q := t.WithContext(dbc.Ctx)
+		if forUpdate {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var rows []*types.UserCompletedUnit
+		if err := q.
+			Where("user_id = ? AND chain_key IN ?", userID, keys[start:end]).
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		out = append(out, rows...)
+	}
+	return out, nil
+}
+
 func (r *userCompletedUnitRepo) ListByUser(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserCompletedUnit, error) {
 	t := dbc.Tx
 	if t == nil {
@@ -16,6 +16,7 @@ type UserConceptModelRepo interface {
 	Upsert(dbc dbctx.Context, row *types.UserConceptModel) error
 	Get(dbc dbctx.Context, userID uuid.UUID, conceptID uuid.UUID) (*types.UserConceptModel, error)
 	ListByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserConceptModel, error)
+	ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserConceptModel, error)
 }
 
 type userConceptModelRepo struct {
@@ -102,3 +103,28 @@ func (r *userConceptModelRepo) ListByUserAndConceptIDs(dbc dbctx.Context, userID
 	}
 	return out, nil
 }
+
+func (r *userConceptModelRepo) ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserConceptModel, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.UserConceptModel{}
+	if userID == uuid.Nil {
+		return out, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	if limit > 5000 {
+		limit = 5000
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -16,6 +16,10 @@ type UserConceptStateRepo interface {
 	Upsert(dbc dbctx.Context, row *types.UserConceptState) error
 	Get(dbc dbctx.Context, userID uuid.UUID, conceptID uuid.UUID) (*types.UserConceptState, error)
 	ListByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserConceptState, error)
+	// ListByUserAndConceptIDsForUpdate is ListByUserAndConceptIDs with the rows locked until
+	// dbc.Tx ends, for read-merge-write callers.
+	ListByUserAndConceptIDsForUpdate(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserConceptState, error)
+	ListByUserAndConceptIDsPaged(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID, pageSize int, fn func(rows []*types.UserConceptState) error) error
 	ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserConceptState, error)
 }
 
@@ -97,21 +101,83 @@ func (r *userConceptStateRepo) Upsert(dbc dbctx.Context, row *types.UserConceptS
 		Create(row).Error
 }
 
+// conceptIDQueryChunk bounds the IN list per query. Postgres caps a statement at 65535 bind
+// parameters; canonical concept sets across many studied paths can get close to that.
+const conceptIDQueryChunk = 1000
+
 func (r *userConceptStateRepo) ListByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserConceptState, error) {
+	return r.listByUserAndConceptIDs(dbc, userID, conceptIDs, false)
+}
+
+func (r *userConceptStateRepo) ListByUserAndConceptIDsForUpdate(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserConceptState, error) {
+	return r.listByUserAndConceptIDs(dbc, userID, conceptIDs, true)
+}
+
+func (r *userConceptStateRepo) listByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID, forUpdate bool) ([]*types.UserConceptState, error) {
+	out := []*types.UserConceptState{}
+	err := r.listByUserAndConceptIDsPaged(dbc, userID, conceptIDs, conceptIDQueryChunk, forUpdate, func(rows []*types.UserConceptState) error {
+		out = append(out, rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListByUserAndConceptIDsPaged looks up conceptIDs (deduped) in chunks of at most pageSize IDs
+// and calls fn once per non-empty chunk, so callers with very large ID sets never hold every
+// row at once. Returning an error from fn stops the scan.
+func (r *userConceptStateRepo) ListByUserAndConceptIDsPaged(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID, pageSize int, fn func(rows []*types.UserConceptState) error) error {
+	return r.listByUserAndConceptIDsPaged(dbc, userID, conceptIDs, pageSize, false, fn)
+}
+
+func (r *userConceptStateRepo) listByUserAndConceptIDsPaged(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID, pageSize int, forUpdate bool, fn func(rows []*types.UserConceptState) error) error {
 	transaction := dbc.Tx
 	if transaction == nil {
 		transaction = r.db
 	}
-	out := []*types.UserConceptState{}
-	if userID == uuid.Nil || len(conceptIDs) == 0 {
-		return out, nil
-	}
-	if err := transaction.WithContext(dbc.Ctx).
-		Where("user_id = ? AND concept_id IN ?", userID, conceptIDs).
-		Find(&out).Error; err != nil {
-		return nil, err
+	if userID == uuid.Nil || len(conceptIDs) == 0 || fn == nil {
+		return nil
 	}
-	return out, nil
+	if pageSize <= 0 || pageSize > conceptIDQueryChunk {
+		pageSize = conceptIDQueryChunk
+	}
+	ids := make([]uuid.UUID, 0, len(conceptIDs))
+	seen := make(map[uuid.UUID]struct{}, len(conceptIDs))
+	for _, id := range conceptIDs {
+		if id == uuid.Nil {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	for start := 0; start < len(ids); start += pageSize {
+		end := start + pageSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		rows := []*types.UserConceptState{}
+		q := transaction.WithContext(dbc.Ctx)
+		if forUpdate {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := q.
+			Where("user_id = ? AND concept_id IN ?", userID, ids[start:end]).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *userConceptStateRepo) ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserConceptState, error) {
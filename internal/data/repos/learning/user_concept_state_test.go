@@ -0,0 +1,77 @@
+package learning
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestUserConceptStateListByUserAndConceptIDsLargeSet(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	repo := NewUserConceptStateRepo(db, testutil.Logger(t))
+
+	userID, otherUser := uuid.New(), uuid.New()
+	const stored = 2500
+	rows := make([]*types.UserConceptState, 0, stored+1)
+	ids := make([]uuid.UUID, 0, 70000)
+	for i := 0; i < stored; i++ {
+		id := uuid.New()
+		ids = append(ids, id)
+		rows = append(rows, &types.UserConceptState{ID: uuid.New(), UserID: userID, ConceptID: id, Mastery: 0.5})
+	}
+	rows = append(rows, &types.UserConceptState{ID: uuid.New(), UserID: otherUser, ConceptID: ids[0], Mastery: 0.9})
+	if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	// Pad past the Postgres bind-parameter limit with IDs that have no state, plus duplicates.
+	for len(ids) < 69000 {
+		ids = append(ids, uuid.New())
+	}
+	ids = append(ids, ids[:1000]...)
+
+	got, err := repo.ListByUserAndConceptIDs(dbc, userID, ids)
+	if err != nil {
+		t.Fatalf("ListByUserAndConceptIDs: %v", err)
+	}
+	if len(got) != stored {
+		t.Fatalf("expected %d rows, got %d", stored, len(got))
+	}
+	for _, row := range got {
+		if row.UserID != userID {
+			t.Fatalf("row for another user leaked: %+v", row)
+		}
+	}
+
+	pages, total := 0, 0
+	err = repo.ListByUserAndConceptIDsPaged(dbc, userID, ids[:stored], 400, func(rows []*types.UserConceptState) error {
+		if len(rows) > 400 {
+			t.Fatalf("page larger than requested: %d", len(rows))
+		}
+		pages++
+		total += len(rows)
+		return nil
+	})
+	if err != nil || total != stored || pages != 7 {
+		t.Fatalf("paged: pages=%d total=%d err=%v", pages, total, err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.ListByUserAndConceptIDsPaged(dbc, userID, ids, 500, func([]*types.UserConceptState) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("callback error should stop the scan: calls=%d err=%v", calls, err)
+	}
+}
@@ -15,6 +15,8 @@ import (
 type UserMisconceptionInstanceRepo interface {
 	Upsert(dbc dbctx.Context, row *types.UserMisconceptionInstance) error
 	ListActiveByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserMisconceptionInstance, error)
+	ListByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserMisconceptionInstance, error)
+	ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserMisconceptionInstance, error)
 }
 
 type userMisconceptionInstanceRepo struct {
@@ -80,3 +82,46 @@ func (r *userMisconceptionInstanceRepo) ListActiveByUserAndConceptIDs(dbc dbctx.
 	}
 	return out, nil
 }
+
+// ListByUserAndConceptIDs returns instances in any status (active, resolved, ...).
+func (r *userMisconceptionInstanceRepo) ListByUserAndConceptIDs(dbc dbctx.Context, userID uuid.UUID, conceptIDs []uuid.UUID) ([]*types.UserMisconceptionInstance, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	out := []*types.UserMisconceptionInstance{}
+	if userID == uuid.Nil || len(conceptIDs) == 0 {
+		return out, nil
+	}
+	if err := transaction.WithContext(dbc.Ctx).
+		Where("user_id = ? AND canonical_concept_id IN ?", userID, conceptIDs).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *userMisconceptionInstanceRepo) ListByUserID(dbc dbctx.Context, userID uuid.UUID, limit int) ([]*types.UserMisconceptionInstance, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.UserMisconceptionInstance{}
+	if userID == uuid.Nil {
+		return out, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	if limit > 5000 {
+		limit = 5000
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
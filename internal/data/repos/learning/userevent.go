@@ -15,6 +15,7 @@ import (
 type UserEventRepo interface {
 	Create(dbc dbctx.Context, events []*types.UserEvent) ([]*types.UserEvent, error)
 	CreateIgnoreDuplicates(dbc dbctx.Context, events []*types.UserEvent) (int, error)
+	CreateIgnoreDuplicatesReturningIDs(dbc dbctx.Context, events []*types.UserEvent) (map[uuid.UUID]bool, error)
 
 	ListAfterCursor(dbc dbctx.Context, userID uuid.UUID, afterCreatedAt *time.Time, afterID *uuid.UUID, limit int) ([]*types.UserEvent, error)
 	ListDistinctPathIDsByUser(dbc dbctx.Context, userID uuid.UUID, since *time.Time, limit int) ([]uuid.UUID, error)
@@ -70,6 +71,55 @@ func (r *userEventRepo) CreateIgnoreDuplicates(dbc dbctx.Context, events []*type
 	return int(res.RowsAffected), nil
 }
 
+// CreateIgnoreDuplicatesReturningIDs inserts events, skipping (user_id, client_event_id)
+// conflicts, and returns the IDs of the rows it actually inserted. Callers must assign IDs
+// up front; an event whose ID is missing from the result was a duplicate.
+func (r *userEventRepo) CreateIgnoreDuplicatesReturningIDs(dbc dbctx.Context, events []*types.UserEvent) (map[uuid.UUID]bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := map[uuid.UUID]bool{}
+	if len(events) == 0 {
+		return out, nil
+	}
+	rows := make([]*types.UserEvent, 0, len(events))
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		if ev.ID == uuid.Nil {
+			ev.ID = uuid.New()
+		}
+		rows = append(rows, ev)
+		ids = append(ids, ev.ID)
+	}
+	if len(rows) == 0 {
+		return out, nil
+	}
+	if err := t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "client_event_id"}},
+			DoNothing: true,
+		}).
+		Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	// RETURNING can't be mapped back onto a partially inserted batch, so look the fresh IDs up.
+	var inserted []uuid.UUID
+	if err := t.WithContext(dbc.Ctx).
+		Model(&types.UserEvent{}).
+		Where("id IN ?", ids).
+		Pluck("id", &inserted).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range inserted {
+		out[id] = true
+	}
+	return out, nil
+}
+
 func (r *userEventRepo) ListAfterCursor(dbc dbctx.Context, userID uuid.UUID, afterCreatedAt *time.Time, afterID *uuid.UUID, limit int) ([]*types.UserEvent, error) {
 	t := dbc.Tx
 	if t == nil {
@@ -0,0 +1,55 @@
+package learning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestUserEventRepoCreateIgnoreDuplicatesReturningIDs(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	repo := NewUserEventRepo(db, testutil.Logger(t))
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+	event := func(clientID string) *types.UserEvent {
+		return &types.UserEvent{ID: uuid.New(), UserID: userID, ClientEventID: clientID, OccurredAt: now, Type: "block_read"}
+	}
+	a, b := uuid.NewString(), uuid.NewString()
+
+	first := []*types.UserEvent{event(a), event(b), nil}
+	inserted, err := repo.CreateIgnoreDuplicatesReturningIDs(dbc, first)
+	if err != nil {
+		t.Fatalf("CreateIgnoreDuplicatesReturningIDs: %v", err)
+	}
+	if len(inserted) != 2 || !inserted[first[0].ID] || !inserted[first[1].ID] {
+		t.Fatalf("first batch inserted = %v", inserted)
+	}
+
+	// A resubmitted batch: a is a duplicate, c is new.
+	retry := []*types.UserEvent{event(a), event(uuid.NewString())}
+	inserted, err = repo.CreateIgnoreDuplicatesReturningIDs(dbc, retry)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if inserted[retry[0].ID] || !inserted[retry[1].ID] || len(inserted) != 1 {
+		t.Fatalf("retry inserted = %v", inserted)
+	}
+
+	var n int64
+	if err := tx.Model(&types.UserEvent{}).Where("user_id = ?", userID).Count(&n).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("stored %d events, want 3", n)
+	}
+}
@@ -1,6 +1,10 @@
 package materials
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,7 +19,18 @@ type MaterialChunkRepo interface {
 	Create(dbc dbctx.Context, chunks []*types.MaterialChunk) ([]*types.MaterialChunk, error)
 	GetByMaterialFileIDs(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialChunk, error)
 	GetByIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.MaterialChunk, error)
+	// GetByIDsIncludingDeleted also returns soft-deleted chunks, for read paths that must
+	// render citations of removed files instead of dropping them.
+	GetByIDsIncludingDeleted(dbc dbctx.Context, ids []uuid.UUID) ([]*types.MaterialChunk, error)
+	// GetByIDsIncludingDeletedOrdered returns the chunks in the order of ids, dropping
+	// missing ids and repeats, so callers can render citations in document order.
+	GetByIDsIncludingDeletedOrdered(dbc dbctx.Context, ids []uuid.UUID) ([]*types.MaterialChunk, error)
+	GetByMaterialFileIDsIncludingDeleted(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialChunk, error)
 	UpdateFields(dbc dbctx.Context, id uuid.UUID, updates map[string]interface{}) error
+	SoftDeleteByMaterialFileIDs(dbc dbctx.Context, fileIDs []uuid.UUID) error
+	// MatchRemovedChunks pairs the chunks of a soft-deleted file with the chunks of newFileID
+	// whose text hashes match (see MatchChunksByTextHash).
+	MatchRemovedChunks(dbc dbctx.Context, removedFileID, newFileID uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
 }
 
 type materialChunkRepo struct {
@@ -100,3 +115,146 @@ func (r *materialChunkRepo) UpdateFields(dbc dbctx.Context, id uuid.UUID, update
 		Where("id = ?", id).
 		Updates(updates).Error
 }
+
+func (r *materialChunkRepo) GetByIDsIncludingDeleted(dbc dbctx.Context, ids []uuid.UUID) ([]*types.MaterialChunk, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	var results []*types.MaterialChunk
+	if len(ids) == 0 {
+		return results, nil
+	}
+	if err := transaction.WithContext(dbc.Ctx).
+		Unscoped().
+		Where("id IN ?", ids).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *materialChunkRepo) GetByIDsIncludingDeletedOrdered(dbc dbctx.Context, ids []uuid.UUID) ([]*types.MaterialChunk, error) {
+	rows, err := r.GetByIDsIncludingDeleted(dbc, ids)
+	if err != nil {
+		return nil, err
+	}
+	return orderChunksByIDs(rows, ids), nil
+}
+
+func orderChunksByIDs(rows []*types.MaterialChunk, ids []uuid.UUID) []*types.MaterialChunk {
+	byID := make(map[uuid.UUID]*types.MaterialChunk, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			byID[row.ID] = row
+		}
+	}
+	out := make([]*types.MaterialChunk, 0, len(rows))
+	for _, id := range ids {
+		if row := byID[id]; row != nil {
+			out = append(out, row)
+			delete(byID, id)
+		}
+	}
+	return out
+}
+
+func (r *materialChunkRepo) GetByMaterialFileIDsIncludingDeleted(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialChunk, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	var results []*types.MaterialChunk
+	if len(fileIDs) == 0 {
+		return results, nil
+	}
+	if err := transaction.WithContext(dbc.Ctx).
+		Unscoped().
+		Where("material_file_id IN ?", fileIDs).
+		Order("material_file_id, index ASC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *materialChunkRepo) SoftDeleteByMaterialFileIDs(dbc dbctx.Context, fileIDs []uuid.UUID) error {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	return transaction.WithContext(dbc.Ctx).
+		Where("material_file_id IN ?", fileIDs).
+		Delete(&types.MaterialChunk{}).Error
+}
+
+func (r *materialChunkRepo) MatchRemovedChunks(dbc dbctx.Context, removedFileID, newFileID uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+	if removedFileID == uuid.Nil || newFileID == uuid.Nil {
+		return map[uuid.UUID]uuid.UUID{}, nil
+	}
+	var removed []*types.MaterialChunk
+	if err := transaction.WithContext(dbc.Ctx).
+		Unscoped().
+		Select("id", "index", "text").
+		Where("material_file_id = ? AND deleted_at IS NOT NULL", removedFileID).
+		Find(&removed).Error; err != nil {
+		return nil, err
+	}
+	var fresh []*types.MaterialChunk
+	if err := transaction.WithContext(dbc.Ctx).
+		Select("id", "index", "text").
+		Where("material_file_id = ?", newFileID).
+		Find(&fresh).Error; err != nil {
+		return nil, err
+	}
+	return MatchChunksByTextHash(removed, fresh), nil
+}
+
+// ChunkTextHash is the relink key of a chunk: its text with whitespace collapsed, so
+// re-extraction differences in spacing don't break a match.
+func ChunkTextHash(text string) string {
+	norm := strings.Join(strings.Fields(text), " ")
+	if norm == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(norm))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchChunksByTextHash maps removed chunk IDs to fresh chunk IDs with the same text hash.
+// Matching is one-to-one: repeated texts pair up in chunk index order, and chunks without a
+// counterpart (or with empty text) stay unmatched.
+func MatchChunksByTextHash(removed, fresh []*types.MaterialChunk) map[uuid.UUID]uuid.UUID {
+	byIndex := func(rows []*types.MaterialChunk) []*types.MaterialChunk {
+		out := make([]*types.MaterialChunk, 0, len(rows))
+		for _, ch := range rows {
+			if ch != nil && ch.ID != uuid.Nil {
+				out = append(out, ch)
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Index < out[j].Index })
+		return out
+	}
+	pool := map[string][]uuid.UUID{}
+	for _, ch := range byIndex(fresh) {
+		if h := ChunkTextHash(ch.Text); h != "" {
+			pool[h] = append(pool[h], ch.ID)
+		}
+	}
+	out := map[uuid.UUID]uuid.UUID{}
+	for _, ch := range byIndex(removed) {
+		h := ChunkTextHash(ch.Text)
+		if ids := pool[h]; h != "" && len(ids) > 0 {
+			out[ch.ID] = ids[0]
+			pool[h] = ids[1:]
+		}
+	}
+	return out
+}
@@ -65,4 +65,43 @@ func TestMaterialChunkRepo(t *testing.T) {
 	if rows, err := repo.GetByIDs(dbc, []uuid.UUID{c1.ID, c2.ID}); err != nil || len(rows) != 2 {
 		t.Fatalf("GetByIDs: err=%v len=%d", err, len(rows))
 	}
+
+	// Shuffled request order is preserved; unknown and repeated ids are dropped.
+	rows, err := repo.GetByIDsIncludingDeletedOrdered(dbc, []uuid.UUID{c2.ID, uuid.New(), c1.ID, c2.ID})
+	if err != nil || len(rows) != 2 || rows[0].ID != c2.ID || rows[1].ID != c1.ID {
+		t.Fatalf("GetByIDsIncludingDeletedOrdered: err=%v rows=%d", err, len(rows))
+	}
+}
+
+func TestMatchChunksByTextHash(t *testing.T) {
+	chunk := func(i int, text string) *types.MaterialChunk {
+		return &types.MaterialChunk{ID: uuid.New(), Index: i, Text: text}
+	}
+	removed := []*types.MaterialChunk{
+		chunk(0, "Intro to loops."),
+		chunk(1, "Repeated  paragraph."),
+		chunk(2, "Repeated paragraph."),
+		chunk(3, "Only in the old upload."),
+		chunk(4, "   "),
+	}
+	fresh := []*types.MaterialChunk{
+		chunk(0, "Intro to\nloops."),
+		chunk(1, "Repeated paragraph."),
+		chunk(2, "New material."),
+		chunk(3, " "),
+	}
+
+	got := MatchChunksByTextHash(removed, fresh)
+	want := map[uuid.UUID]uuid.UUID{
+		removed[0].ID: fresh[0].ID,
+		removed[1].ID: fresh[1].ID,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("matched %d chunks, want %d: %v", len(got), len(want), got)
+	}
+	for from, to := range want {
+		if got[from] != to {
+			t.Fatalf("chunk %s -> %s, want %s", from, got[from], to)
+		}
+	}
 }
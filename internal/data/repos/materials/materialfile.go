@@ -12,6 +12,8 @@ import (
 type MaterialFileRepo interface {
 	Create(dbc dbctx.Context, files []*types.MaterialFile) ([]*types.MaterialFile, error)
 	GetByIDs(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialFile, error)
+	// GetByIDsIncludingDeleted also returns soft-deleted files (DeletedAt.Valid).
+	GetByIDsIncludingDeleted(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialFile, error)
 	GetByMaterialSetIDs(dbc dbctx.Context, setIDs []uuid.UUID) ([]*types.MaterialFile, error)
 	GetByMaterialSetID(dbc dbctx.Context, setID uuid.UUID) ([]*types.MaterialFile, error)
 	SoftDeleteByIDs(dbc dbctx.Context, fileIDs []uuid.UUID) error
@@ -65,6 +67,26 @@ func (r *materialFileRepo) GetByIDs(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*
 	return results, nil
 }
 
+func (r *materialFileRepo) GetByIDsIncludingDeleted(dbc dbctx.Context, fileIDs []uuid.UUID) ([]*types.MaterialFile, error) {
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = r.db
+	}
+
+	var results []*types.MaterialFile
+	if len(fileIDs) == 0 {
+		return results, nil
+	}
+
+	if err := transaction.WithContext(dbc.Ctx).
+		Unscoped().
+		Where("id IN ?", fileIDs).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (r *materialFileRepo) GetByMaterialSetIDs(dbc dbctx.Context, setIDs []uuid.UUID) ([]*types.MaterialFile, error) {
 	transaction := dbc.Tx
 	if transaction == nil {
@@ -50,10 +50,18 @@ type UserEventCursorRepo = learning.UserEventCursorRepo
 type UserProgressionEventRepo = learning.UserProgressionEventRepo
 type UserGazeEventRepo = user.UserGazeEventRepo
 type UserGazeBlockStatRepo = user.UserGazeBlockStatRepo
+type UserNotificationRepo = user.UserNotificationRepo
+type UserNotificationCursor = user.UserNotificationCursor
+
+const (
+	UserNotificationListDefaultLimit = user.UserNotificationListDefaultLimit
+	UserNotificationListMaxLimit     = user.UserNotificationListMaxLimit
+)
 
 type UserBeliefSnapshotRepo = learning.UserBeliefSnapshotRepo
 type InterventionPlanRepo = learning.InterventionPlanRepo
 type ConceptRepo = learning.ConceptRepo
+type ConceptDocEmbeddingRepo = learning.ConceptDocEmbeddingRepo
 type ConceptRepresentationRepo = learning.ConceptRepresentationRepo
 type ConceptMappingOverrideRepo = learning.ConceptMappingOverrideRepo
 type ActivityRepo = learning.ActivityRepo
@@ -63,7 +71,11 @@ type ActivityCitationRepo = learning.ActivityCitationRepo
 
 type PathRepo = learning.PathRepo
 type PathNodeRepo = learning.PathNodeRepo
+type PathOutlineRow = learning.PathOutlineRow
+type PathShareRepo = learning.PathShareRepo
+type PathFocusRepo = learning.PathFocusRepo
 type PathNodeActivityRepo = learning.PathNodeActivityRepo
+type PathActivityRepo = learning.PathActivityRepo
 type PathStructuralUnitRepo = learning.PathStructuralUnitRepo
 type PathRunRepo = learning.PathRunRepo
 type NodeRunRepo = learning.NodeRunRepo
@@ -73,6 +85,7 @@ type PathRunTransitionRepo = learning.PathRunTransitionRepo
 type ConceptClusterRepo = learning.ConceptClusterRepo
 type ConceptClusterMemberRepo = learning.ConceptClusterMemberRepo
 type ConceptEdgeRepo = learning.ConceptEdgeRepo
+type ConceptEdgeEvidence = learning.ConceptEdgeEvidence
 type ConceptEvidenceRepo = learning.ConceptEvidenceRepo
 type CohortPriorRepo = learning.CohortPriorRepo
 type ActivityVariantStatRepo = learning.ActivityVariantStatRepo
@@ -89,8 +102,12 @@ type ChainPriorRepo = learning.ChainPriorRepo
 type UserCompletedUnitRepo = learning.UserCompletedUnitRepo
 type TeachingPatternRepo = learning.TeachingPatternRepo
 type LearningNodeDocRepo = learning.LearningNodeDocRepo
+type NodeDocSearchRepo = learning.NodeDocSearchRepo
 type LearningNodeDocRevisionRepo = learning.LearningNodeDocRevisionRepo
 type LearningNodeFigureRepo = learning.LearningNodeFigureRepo
+type LearningNodeAudioRepo = learning.LearningNodeAudioRepo
+type FigureBlobRepo = learning.FigureBlobRepo
+type NodeAssetRefRepo = learning.NodeAssetRefRepo
 type LearningNodeVideoRepo = learning.LearningNodeVideoRepo
 type LearningDocGenerationRunRepo = learning.LearningDocGenerationRunRepo
 type LearningNodeDocBlueprintRepo = learning.LearningNodeDocBlueprintRepo
@@ -101,9 +118,21 @@ type PrereqGateDecisionRepo = learning.PrereqGateDecisionRepo
 type DocRetrievalPackRepo = learning.DocRetrievalPackRepo
 type DocGenerationTraceRepo = learning.DocGenerationTraceRepo
 type DocConstraintReportRepo = learning.DocConstraintReportRepo
+type DocConsistencyAuditRunRepo = learning.DocConsistencyAuditRunRepo
 type DocProbeRepo = learning.DocProbeRepo
 type DocProbeOutcomeRepo = learning.DocProbeOutcomeRepo
 type DocVariantExposureRepo = learning.DocVariantExposureRepo
+type DocVariantAssignmentRepo = learning.DocVariantAssignmentRepo
+type DocVariantArmCount = learning.DocVariantArmCount
+type DocVariantReader = learning.DocVariantReader
+
+const (
+	DocVariantArmTreatment             = learning.DocVariantArmTreatment
+	DocVariantArmHoldback              = learning.DocVariantArmHoldback
+	DocVariantAssignmentSourceHash     = learning.DocVariantAssignmentSourceHash
+	DocVariantAssignmentSourceBackfill = learning.DocVariantAssignmentSourceBackfill
+)
+
 type DocVariantOutcomeRepo = learning.DocVariantOutcomeRepo
 type LearningDrillInstanceRepo = learning.LearningDrillInstanceRepo
 type LearningArtifactRepo = learning.LearningArtifactRepo
@@ -117,17 +146,50 @@ type LibraryPathEmbeddingRepo = learning.LibraryPathEmbeddingRepo
 type JobRunRepo = jobs.JobRunRepo
 type SagaRunRepo = jobs.SagaRunRepo
 type SagaActionRepo = jobs.SagaActionRepo
+type JobSchedulerRepo = jobs.JobSchedulerRepo
+type JobSchedSnapshot = jobs.JobSchedSnapshot
+type JobSchedSnapshotOptions = jobs.JobSchedSnapshotOptions
+type JobSchedRunning = jobs.JobSchedRunning
+type JobSchedDepth = jobs.JobSchedDepth
+type JobRunListFilter = jobs.JobRunListFilter
+type JobRunCursor = jobs.JobRunCursor
+
+const (
+	JobRunListDefaultLimit = jobs.JobRunListDefaultLimit
+	JobRunListMaxLimit     = jobs.JobRunListMaxLimit
+)
 
 type ChatThreadRepo = chat.ChatThreadRepo
 type ChatMessageRepo = chat.ChatMessageRepo
 type ChatThreadStateRepo = chat.ChatThreadStateRepo
 type ChatSummaryNodeRepo = chat.ChatSummaryNodeRepo
+type BudgetedSummary = chat.BudgetedSummary
 type ChatMemoryItemRepo = chat.ChatMemoryItemRepo
 type ChatEntityRepo = chat.ChatEntityRepo
 type ChatEdgeRepo = chat.ChatEdgeRepo
 type ChatClaimRepo = chat.ChatClaimRepo
 type ChatDocRepo = chat.ChatDocRepo
 type ChatTurnRepo = chat.ChatTurnRepo
+type ChatToolExecutionRepo = chat.ChatToolExecutionRepo
+type ContextBudgetStatRepo = chat.ContextBudgetStatRepo
+type ContextBudgetSummary = chat.ContextBudgetSummary
+
+// ErrStaleDoc reports an optimistic-lock miss on learning_node_doc writes.
+var ErrStaleDoc = learning.ErrStaleDoc
+
+// ErrDocTooLarge reports a learning_node_doc write over the configured size cap.
+var ErrDocTooLarge = learning.ErrDocTooLarge
+
+func MaxNodeDocBytes() int { return learning.MaxNodeDocBytes() }
+
+// NodeDocRevisionTraceKey is the revision metadata key holding the writer's request trace.
+const NodeDocRevisionTraceKey = learning.NodeDocRevisionTraceKey
+
+// ParseConceptEdgeEvidence decodes ConceptEdge.Evidence into its typed form.
+var ParseConceptEdgeEvidence = learning.ParseConceptEdgeEvidence
+
+// ConceptNaturalKey normalizes a concept key the way global concepts are deduplicated.
+func ConceptNaturalKey(key string) string { return learning.ConceptNaturalKey(key) }
 
 func NewUserRepo(db *gorm.DB, baseLog *logger.Logger) UserRepo { return user.NewUserRepo(db, baseLog) }
 func NewUserProfileVectorRepo(db *gorm.DB, baseLog *logger.Logger) UserProfileVectorRepo {
@@ -239,6 +301,9 @@ func NewUserGazeEventRepo(db *gorm.DB, baseLog *logger.Logger) UserGazeEventRepo
 func NewUserGazeBlockStatRepo(db *gorm.DB, baseLog *logger.Logger) UserGazeBlockStatRepo {
 	return user.NewUserGazeBlockStatRepo(db, baseLog)
 }
+func NewUserNotificationRepo(db *gorm.DB, baseLog *logger.Logger) UserNotificationRepo {
+	return user.NewUserNotificationRepo(db, baseLog)
+}
 func NewUserProgressionEventRepo(db *gorm.DB, baseLog *logger.Logger) UserProgressionEventRepo {
 	return learning.NewUserProgressionEventRepo(db, baseLog)
 }
@@ -246,6 +311,9 @@ func NewUserProgressionEventRepo(db *gorm.DB, baseLog *logger.Logger) UserProgre
 func NewConceptRepo(db *gorm.DB, baseLog *logger.Logger) ConceptRepo {
 	return learning.NewConceptRepo(db, baseLog)
 }
+func NewConceptDocEmbeddingRepo(db *gorm.DB, baseLog *logger.Logger) ConceptDocEmbeddingRepo {
+	return learning.NewConceptDocEmbeddingRepo(db, baseLog)
+}
 func NewConceptRepresentationRepo(db *gorm.DB, baseLog *logger.Logger) ConceptRepresentationRepo {
 	return learning.NewConceptRepresentationRepo(db, baseLog)
 }
@@ -271,9 +339,18 @@ func NewPathRepo(db *gorm.DB, baseLog *logger.Logger) PathRepo {
 func NewPathNodeRepo(db *gorm.DB, baseLog *logger.Logger) PathNodeRepo {
 	return learning.NewPathNodeRepo(db, baseLog)
 }
+func NewPathShareRepo(db *gorm.DB, baseLog *logger.Logger) PathShareRepo {
+	return learning.NewPathShareRepo(db, baseLog)
+}
+func NewPathFocusRepo(db *gorm.DB, baseLog *logger.Logger) PathFocusRepo {
+	return learning.NewPathFocusRepo(db, baseLog)
+}
 func NewPathNodeActivityRepo(db *gorm.DB, baseLog *logger.Logger) PathNodeActivityRepo {
 	return learning.NewPathNodeActivityRepo(db, baseLog)
 }
+func NewPathActivityRepo(db *gorm.DB, baseLog *logger.Logger) PathActivityRepo {
+	return learning.NewPathActivityRepo(db, baseLog)
+}
 func NewPathStructuralUnitRepo(db *gorm.DB, baseLog *logger.Logger) PathStructuralUnitRepo {
 	return learning.NewPathStructuralUnitRepo(db, baseLog)
 }
@@ -308,6 +385,9 @@ func NewTeachingPatternRepo(db *gorm.DB, baseLog *logger.Logger) TeachingPattern
 func NewLearningNodeDocRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeDocRepo {
 	return learning.NewLearningNodeDocRepo(db, baseLog)
 }
+func NewNodeDocSearchRepo(db *gorm.DB, baseLog *logger.Logger) NodeDocSearchRepo {
+	return learning.NewNodeDocSearchRepo(db, baseLog)
+}
 func NewLearningNodeDocRevisionRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeDocRevisionRepo {
 	return learning.NewLearningNodeDocRevisionRepo(db, baseLog)
 }
@@ -317,6 +397,15 @@ func NewLearningNodeDocBlueprintRepo(db *gorm.DB, baseLog *logger.Logger) Learni
 func NewLearningNodeFigureRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeFigureRepo {
 	return learning.NewLearningNodeFigureRepo(db, baseLog)
 }
+func NewLearningNodeAudioRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeAudioRepo {
+	return learning.NewLearningNodeAudioRepo(db, baseLog)
+}
+func NewFigureBlobRepo(db *gorm.DB, baseLog *logger.Logger) FigureBlobRepo {
+	return learning.NewFigureBlobRepo(db, baseLog)
+}
+func NewNodeAssetRefRepo(db *gorm.DB, baseLog *logger.Logger) NodeAssetRefRepo {
+	return learning.NewNodeAssetRefRepo(db, baseLog)
+}
 func NewLearningNodeVideoRepo(db *gorm.DB, baseLog *logger.Logger) LearningNodeVideoRepo {
 	return learning.NewLearningNodeVideoRepo(db, baseLog)
 }
@@ -350,6 +439,9 @@ func NewDocGenerationTraceRepo(db *gorm.DB, baseLog *logger.Logger) DocGeneratio
 func NewDocConstraintReportRepo(db *gorm.DB, baseLog *logger.Logger) DocConstraintReportRepo {
 	return learning.NewDocConstraintReportRepo(db, baseLog)
 }
+func NewDocConsistencyAuditRunRepo(db *gorm.DB, baseLog *logger.Logger) DocConsistencyAuditRunRepo {
+	return learning.NewDocConsistencyAuditRunRepo(db, baseLog)
+}
 func NewDocProbeRepo(db *gorm.DB, baseLog *logger.Logger) DocProbeRepo {
 	return learning.NewDocProbeRepo(db, baseLog)
 }
@@ -359,6 +451,9 @@ func NewDocProbeOutcomeRepo(db *gorm.DB, baseLog *logger.Logger) DocProbeOutcome
 func NewDocVariantExposureRepo(db *gorm.DB, baseLog *logger.Logger) DocVariantExposureRepo {
 	return learning.NewDocVariantExposureRepo(db, baseLog)
 }
+func NewDocVariantAssignmentRepo(db *gorm.DB, baseLog *logger.Logger) DocVariantAssignmentRepo {
+	return learning.NewDocVariantAssignmentRepo(db, baseLog)
+}
 func NewDocVariantOutcomeRepo(db *gorm.DB, baseLog *logger.Logger) DocVariantOutcomeRepo {
 	return learning.NewDocVariantOutcomeRepo(db, baseLog)
 }
@@ -396,6 +491,9 @@ func NewSagaRunRepo(db *gorm.DB, baseLog *logger.Logger) SagaRunRepo {
 func NewSagaActionRepo(db *gorm.DB, baseLog *logger.Logger) SagaActionRepo {
 	return jobs.NewSagaActionRepo(db, baseLog)
 }
+func NewJobSchedulerRepo(db *gorm.DB, baseLog *logger.Logger) JobSchedulerRepo {
+	return jobs.NewJobSchedulerRepo(db, baseLog)
+}
 
 func NewConceptClusterRepo(db *gorm.DB, baseLog *logger.Logger) ConceptClusterRepo {
 	return learning.NewConceptClusterRepo(db, baseLog)
@@ -486,3 +584,11 @@ func NewChatDocRepo(db *gorm.DB, baseLog *logger.Logger) ChatDocRepo {
 func NewChatTurnRepo(db *gorm.DB, baseLog *logger.Logger) ChatTurnRepo {
 	return chat.NewChatTurnRepo(db, baseLog)
 }
+
+func NewChatToolExecutionRepo(db *gorm.DB, baseLog *logger.Logger) ChatToolExecutionRepo {
+	return chat.NewChatToolExecutionRepo(db, baseLog)
+}
+
+func NewContextBudgetStatRepo(db *gorm.DB, baseLog *logger.Logger) ContextBudgetStatRepo {
+	return chat.NewContextBudgetStatRepo(db, baseLog)
+}
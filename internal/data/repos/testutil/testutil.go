@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -56,6 +57,12 @@ func DB(tb testing.TB) *gorm.DB {
 			return
 		}
 
+		// Slow-query logging stays off in tests; budgets only need the per-context tally.
+		if err := dbstats.Register(db, dbstats.Config{Recorder: dbstats.NewRecorder()}); err != nil {
+			dbErr = err
+			return
+		}
+
 		if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp";`).Error; err != nil {
 			dbErr = err
 			return
@@ -65,6 +72,11 @@ func DB(tb testing.TB) *gorm.DB {
 			dbErr = err
 			return
 		}
+		// Mirrors db.EnsureUserEventIndexes (importing it here would cycle); test tables start empty.
+		if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_user_client_event ON user_event (user_id, client_event_id);`).Error; err != nil {
+			dbErr = err
+			return
+		}
 	})
 
 	if errors.Is(dbErr, errMissingDSN) {
@@ -92,6 +104,7 @@ func autoMigrateAll(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&types.User{},
 		&types.UserToken{},
+		&types.UserNotification{},
 
 		&types.MaterialSet{},
 		&types.MaterialSetFile{},
@@ -100,14 +113,22 @@ func autoMigrateAll(db *gorm.DB) error {
 		&types.MaterialFileSection{},
 		&types.MaterialChunk{},
 		&types.MaterialAsset{},
+		&types.MaterialSetConceptCoverage{},
+		&types.GlobalConceptCoverage{},
 
 		&types.Concept{},
+		&types.ConceptDocEmbedding{},
+		&types.ConceptRepresentation{},
+		&types.ConceptMappingOverride{},
+		&types.PathStructuralUnit{},
 		&types.Activity{},
 		&types.ActivityVariant{},
 		&types.ActivityConcept{},
 		&types.ActivityCitation{},
 		&types.Path{},
 		&types.PathNode{},
+		&types.PathShare{},
+		&types.PathFocus{},
 		&types.PathNodeActivity{},
 		&types.PathRun{},
 		&types.NodeRun{},
@@ -118,6 +139,8 @@ func autoMigrateAll(db *gorm.DB) error {
 		&types.UserEvent{},
 		&types.UserEventCursor{},
 		&types.UserConceptState{},
+		&types.UserConceptModel{},
+		&types.UserConceptEvidence{},
 		&types.UserSkillState{},
 		&types.UserConceptEdgeStat{},
 		&types.ItemCalibration{},
@@ -132,6 +155,7 @@ func autoMigrateAll(db *gorm.DB) error {
 		&types.DocProbe{},
 		&types.DocProbeOutcome{},
 		&types.DocVariantExposure{},
+		&types.DocVariantAssignment{},
 		&types.DocVariantOutcome{},
 		&types.DecisionTrace{},
 		&types.StructuralDecisionTrace{},
@@ -143,6 +167,21 @@ func autoMigrateAll(db *gorm.DB) error {
 		&types.TopicMastery{},
 		&types.TopicStylePreference{},
 		&types.LearningArtifact{},
+		&types.LearningNodeFigure{},
+		&types.FigureBlob{},
+		&types.LearningNodeAudio{},
+		&types.LearningNodeDoc{},
+		&types.LearningNodeDocRevision{},
+		&types.LearningNodeDocVariant{},
+		&types.DocConsistencyAuditRun{},
+		&types.LearningNodeVideo{},
+		&types.NodeAssetRef{},
+		&types.LearningDocGenerationRun{},
+		&types.DocGenerationTrace{},
 		&types.JobRun{},
+		&types.JobRunEvent{},
+		&types.JobSchedulerState{},
+		&types.FeatureFlag{},
+		&types.ContextBudgetStat{},
 	)
 }
@@ -0,0 +1,184 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type UserNotificationRepo interface {
+	// Create inserts the row unless (user_id, dedup_key) already exists; created reports which.
+	Create(dbc dbctx.Context, row *types.UserNotification) (created bool, err error)
+	CountByKindSince(dbc dbctx.Context, userID uuid.UUID, kind string, since time.Time) (int64, error)
+	ListByUser(dbc dbctx.Context, userID uuid.UUID, before *UserNotificationCursor, limit int, unreadOnly bool) ([]*types.UserNotification, error)
+	CountUnread(dbc dbctx.Context, userID uuid.UUID) (int64, error)
+	// MarkRead sets read_at on an unread row; found is false when the row is not the user's.
+	MarkRead(dbc dbctx.Context, userID, id uuid.UUID, at time.Time) (found bool, err error)
+	MarkAllRead(dbc dbctx.Context, userID uuid.UUID, at time.Time) (int64, error)
+	DeleteOlderThan(dbc dbctx.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+const (
+	UserNotificationListDefaultLimit = 30
+	UserNotificationListMaxLimit     = 100
+)
+
+// UserNotificationCursor is a keyset position in (created_at DESC, id DESC) order.
+type UserNotificationCursor = keyset.Cursor
+
+type userNotificationRepo struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewUserNotificationRepo(db *gorm.DB, baseLog *logger.Logger) UserNotificationRepo {
+	return &userNotificationRepo{
+		db:  db,
+		log: baseLog.With("repo", "UserNotificationRepo"),
+	}
+}
+
+func (r *userNotificationRepo) Create(dbc dbctx.Context, row *types.UserNotification) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if row == nil || row.UserID == uuid.Nil || strings.TrimSpace(row.Kind) == "" {
+		return false, nil
+	}
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+	if strings.TrimSpace(row.DedupKey) == "" {
+		row.DedupKey = row.ID.String()
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now().UTC()
+	}
+	res := t.WithContext(dbc.Ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "dedup_key"}},
+			DoNothing: true,
+		}).
+		Create(row)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *userNotificationRepo) CountByKindSince(dbc dbctx.Context, userID uuid.UUID, kind string, since time.Time) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil {
+		return 0, nil
+	}
+	var n int64
+	err := t.WithContext(dbc.Ctx).
+		Model(&types.UserNotification{}).
+		Where("user_id = ? AND kind = ? AND created_at >= ?", userID, kind, since).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *userNotificationRepo) ListByUser(dbc dbctx.Context, userID uuid.UUID, before *UserNotificationCursor, limit int, unreadOnly bool) ([]*types.UserNotification, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	out := []*types.UserNotification{}
+	if userID == uuid.Nil {
+		return out, nil
+	}
+	if limit <= 0 {
+		limit = UserNotificationListDefaultLimit
+	}
+	q := t.WithContext(dbc.Ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if err := keyset.Page(q, "created_at", true, before).Limit(limit).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *userNotificationRepo) CountUnread(dbc dbctx.Context, userID uuid.UUID) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil {
+		return 0, nil
+	}
+	var n int64
+	err := t.WithContext(dbc.Ctx).
+		Model(&types.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *userNotificationRepo) MarkRead(dbc dbctx.Context, userID, id uuid.UUID, at time.Time) (bool, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil || id == uuid.Nil {
+		return false, nil
+	}
+	// COALESCE keeps the first read time when the row was already read.
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.UserNotification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *userNotificationRepo) MarkAllRead(dbc dbctx.Context, userID uuid.UUID, at time.Time) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if userID == uuid.Nil {
+		return 0, nil
+	}
+	res := t.WithContext(dbc.Ctx).
+		Model(&types.UserNotification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *userNotificationRepo) DeleteOlderThan(dbc dbctx.Context, cutoff time.Time, limit int) (int64, error) {
+	t := dbc.Tx
+	if t == nil {
+		t = r.db
+	}
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	res := t.WithContext(dbc.Ctx).
+		Where("id IN (?)", t.Model(&types.UserNotification{}).
+			Select("id").
+			Where("created_at < ?", cutoff).
+			Limit(limit)).
+		Delete(&types.UserNotification{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestUserNotificationRepoReadState(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+	repo := NewUserNotificationRepo(db, testutil.Logger(t))
+	user := testutil.SeedUser(t, dbc, "notifications@example.com")
+	other := testutil.SeedUser(t, dbc, "notifications-other@example.com")
+
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Millisecond)
+	var rows []*types.UserNotification
+	for i, key := range []string{"job:a", "job:b", "job:c"} {
+		row := &types.UserNotification{UserID: user.ID, Kind: "doc_patch_applied", Title: key, DedupKey: key, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		created, err := repo.Create(dbc, row)
+		if err != nil || !created {
+			t.Fatalf("Create %s: created=%v err=%v", key, created, err)
+		}
+		rows = append(rows, row)
+	}
+	if created, err := repo.Create(dbc, &types.UserNotification{UserID: user.ID, Kind: "doc_patch_applied", Title: "dup", DedupKey: "job:a"}); err != nil || created {
+		t.Fatalf("duplicate Create: created=%v err=%v", created, err)
+	}
+
+	if n, _ := repo.CountUnread(dbc, user.ID); n != 3 {
+		t.Fatalf("unread = %d, want 3", n)
+	}
+
+	// Another user's id is not found; a second read keeps the first read time.
+	if found, err := repo.MarkRead(dbc, other.ID, rows[0].ID, time.Now()); err != nil || found {
+		t.Fatalf("MarkRead as other user: found=%v err=%v", found, err)
+	}
+	firstRead := base.Add(30 * time.Minute)
+	if found, err := repo.MarkRead(dbc, user.ID, rows[0].ID, firstRead); err != nil || !found {
+		t.Fatalf("MarkRead: found=%v err=%v", found, err)
+	}
+	if found, _ := repo.MarkRead(dbc, user.ID, rows[0].ID, firstRead.Add(time.Hour)); !found {
+		t.Fatalf("re-reading should still find the row")
+	}
+
+	unread, err := repo.ListByUser(dbc, user.ID, nil, 10, true)
+	if err != nil || len(unread) != 2 || unread[0].ID != rows[2].ID {
+		t.Fatalf("unread list = %+v err=%v", unread, err)
+	}
+	page, err := repo.ListByUser(dbc, user.ID, &UserNotificationCursor{CreatedAt: rows[1].CreatedAt, ID: rows[1].ID}, 10, false)
+	if err != nil || len(page) != 1 || page[0].ID != rows[0].ID || page[0].ReadAt == nil || !page[0].ReadAt.Equal(firstRead) {
+		t.Fatalf("cursor page = %+v err=%v", page, err)
+	}
+
+	if n, err := repo.MarkAllRead(dbc, user.ID, time.Now()); err != nil || n != 2 {
+		t.Fatalf("MarkAllRead = %d err=%v, want 2", n, err)
+	}
+	if n, _ := repo.CountUnread(dbc, user.ID); n != 0 {
+		t.Fatalf("unread after read-all = %d", n)
+	}
+
+	if n, err := repo.DeleteOlderThan(dbc, base.Add(90*time.Second), 10); err != nil || n != 2 {
+		t.Fatalf("DeleteOlderThan = %d err=%v, want 2", n, err)
+	}
+}
@@ -3,6 +3,7 @@ package aggregates
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -52,18 +53,59 @@ type CommitNodeDocRevisionResult struct {
 }
 
 type RecordDocVariantOutcomeInput struct {
-	UserID       uuid.UUID
-	PathID       uuid.UUID
-	PathNodeID   uuid.UUID
-	DocID        uuid.UUID
-	VariantID    uuid.UUID
-	ExposureID   uuid.UUID
-	OutcomeID    uuid.UUID
+	UserID     uuid.UUID
+	PathID     uuid.UUID
+	PathNodeID uuid.UUID
+	DocID      uuid.UUID
+	VariantID  uuid.UUID
+	ExposureID uuid.UUID
+	OutcomeID  uuid.UUID
+	// OutcomeLabel must be one of the configured DocVariantOutcomeLabel values; unknown labels
+	// are rejected with CodeValidation.
 	OutcomeLabel string
 	ObservedAt   time.Time
 	Metadata     map[string]any
 }
 
+// DocVariantOutcomeLabel is an accepted outcome label and the reward weight policy eval assigns it.
+type DocVariantOutcomeLabel struct {
+	Label       string  `json:"label"`
+	Reward      float64 `json:"reward"`
+	Description string  `json:"description,omitempty"`
+}
+
+// DefaultDocVariantOutcomeLabels is the label set used when none is configured.
+func DefaultDocVariantOutcomeLabels() []DocVariantOutcomeLabel {
+	return []DocVariantOutcomeLabel{
+		{Label: "completed", Reward: 1, Description: "learner finished the node doc"},
+		{Label: "mastery_gain", Reward: 1, Description: "concept mastery rose after exposure"},
+		{Label: "quick_check_correct", Reward: 0.5, Description: "quick check answered correctly"},
+		{Label: "quick_check_incorrect", Reward: -0.25, Description: "quick check answered incorrectly"},
+		{Label: "no_change", Reward: 0, Description: "no measurable effect"},
+		{Label: "regenerate_requested", Reward: -0.5, Description: "learner asked for the doc to be rewritten"},
+		{Label: "abandoned", Reward: -0.5, Description: "learner left without finishing"},
+	}
+}
+
+// NormalizeDocVariantOutcomeLabel is the canonical form labels are compared in.
+func NormalizeDocVariantOutcomeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// FindDocVariantOutcomeLabel looks label up in labels.
+func FindDocVariantOutcomeLabel(labels []DocVariantOutcomeLabel, label string) (DocVariantOutcomeLabel, bool) {
+	label = NormalizeDocVariantOutcomeLabel(label)
+	if label == "" {
+		return DocVariantOutcomeLabel{}, false
+	}
+	for _, l := range labels {
+		if l.Label == label {
+			return l, true
+		}
+	}
+	return DocVariantOutcomeLabel{}, false
+}
+
 type RecordDocVariantOutcomeResult struct {
 	VariantID  uuid.UUID
 	ExposureID uuid.UUID
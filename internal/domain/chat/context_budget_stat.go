@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ContextBudgetStat is one sampled context plan: how each lane's token allocation was used.
+// Rows are written off the plan path (see services.ContextBudgetSampler) and only feed the
+// admin truncation-pressure summary, so they carry no soft delete and are pruned by age.
+type ContextBudgetStat struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ThreadID uuid.UUID  `gorm:"type:uuid;not null;index" json:"thread_id"`
+	PathID   *uuid.UUID `gorm:"type:uuid;index" json:"path_id,omitempty"`
+
+	// PathSizeBucket is none, small, medium, large, or xl (see PathSizeBucket).
+	PathSizeBucket string `gorm:"type:text;not null;default:'none';index" json:"path_size_bucket"`
+	PathNodeCount  int    `gorm:"not null;default:0" json:"path_node_count"`
+
+	// LanesEnabled is the sorted list of effective lanes for the plan.
+	LanesEnabled datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"lanes_enabled"`
+	// Lanes is a []ContextBudgetLaneUsage.
+	Lanes datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"lanes"`
+
+	MaxContextTokens int  `gorm:"not null;default:0" json:"max_context_tokens"`
+	AnyLaneDropped   bool `gorm:"not null;default:false" json:"any_lane_dropped"`
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+func (ContextBudgetStat) TableName() string { return "context_budget_stat" }
+
+// ContextBudgetLaneUsage is the token accounting for one lane of a context plan.
+// Allocated is the lane budget, Consumed what reached the prompt, and Truncated the demand
+// that was cut to fit. Dropped means the lane had content but none of it survived.
+type ContextBudgetLaneUsage struct {
+	Lane      string `json:"lane"`
+	Allocated int    `json:"allocated"`
+	Consumed  int    `json:"consumed"`
+	Truncated int    `json:"truncated"`
+	Dropped   bool   `json:"dropped,omitempty"`
+}
+
+// Saturated reports whether the lane hit its allocation ceiling.
+func (u ContextBudgetLaneUsage) Saturated() bool {
+	return u.Allocated > 0 && (u.Consumed >= u.Allocated || u.Truncated > 0)
+}
+
+const (
+	PathSizeNone   = "none"
+	PathSizeSmall  = "small"
+	PathSizeMedium = "medium"
+	PathSizeLarge  = "large"
+	PathSizeXL     = "xl"
+)
+
+// PathSizeBucket groups a path by node count for budget telemetry.
+func PathSizeBucket(nodes int) string {
+	switch {
+	case nodes <= 0:
+		return PathSizeNone
+	case nodes <= 10:
+		return PathSizeSmall
+	case nodes <= 30:
+		return PathSizeMedium
+	case nodes <= 80:
+		return PathSizeLarge
+	default:
+		return PathSizeXL
+	}
+}
@@ -20,6 +20,10 @@ type ChatSummaryNode struct {
 	SummaryMD    string         `gorm:"type:text;not null" json:"summary_md"`
 	ChildNodeIDs datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"child_node_ids"`
 
+	// DigestMD is a one-line "earlier topics" digest of the subtree, cached on root nodes the
+	// first time a budgeted summary needs it.
+	DigestMD string `gorm:"type:text;not null;default:''" json:"digest_md,omitempty"`
+
 	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:now();index" json:"updated_at"`
 }
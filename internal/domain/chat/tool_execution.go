@@ -0,0 +1,36 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ChatToolExecution is the per-message ledger entry for a server-executed chat action tool.
+// The (message_id, tool_name, args_hash) key makes a re-delivered generation replay the
+// recorded result instead of executing the action a second time.
+type ChatToolExecution struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	ThreadID uuid.UUID `gorm:"type:uuid;not null;index" json:"thread_id"`
+
+	// MessageID is the assistant message the execution is recorded on.
+	MessageID uuid.UUID `gorm:"type:uuid;not null;index:idx_chat_tool_execution_key,unique,priority:1" json:"message_id"`
+	ToolName  string    `gorm:"type:text;not null;index:idx_chat_tool_execution_key,unique,priority:2" json:"tool_name"`
+	ArgsHash  string    `gorm:"type:text;not null;index:idx_chat_tool_execution_key,unique,priority:3" json:"args_hash"`
+
+	Arguments datatypes.JSON `gorm:"type:jsonb;not null;default:'{}'" json:"arguments"`
+
+	// Status is running, succeeded, or failed.
+	Status string         `gorm:"type:text;not null;default:'running';index" json:"status"`
+	Result datatypes.JSON `gorm:"type:jsonb" json:"result,omitempty"`
+	Error  string         `gorm:"type:text" json:"error,omitempty"`
+
+	CreatedAt   time.Time  `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func (ChatToolExecution) TableName() string { return "chat_tool_execution" }
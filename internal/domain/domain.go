@@ -10,6 +10,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/domain/learning/products"
 	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
 	"github.com/yungbote/neurobridge-backend/internal/domain/materials"
+	"github.com/yungbote/neurobridge-backend/internal/domain/platform"
 	"github.com/yungbote/neurobridge-backend/internal/domain/user"
 	"gorm.io/datatypes"
 )
@@ -75,6 +76,7 @@ type User = user.User
 type UserProfileVector = user.UserProfileVector
 type UserPersonalizationPrefs = user.UserPersonalizationPrefs
 type UserSessionState = user.UserSessionState
+type UserNotification = user.UserNotification
 type UserToken = auth.UserToken
 type UserIdentity = auth.UserIdentity
 type OAuthNonce = auth.OAuthNonce
@@ -112,6 +114,7 @@ func PtrFloat(v float64) *float64 { return materials.PtrFloat(v) }
 
 type JobRun = jobs.JobRun
 type JobRunEvent = jobs.JobRunEvent
+type JobSchedulerState = jobs.JobSchedulerState
 type SagaRun = jobs.SagaRun
 type SagaAction = jobs.SagaAction
 
@@ -145,6 +148,52 @@ type MisconceptionResolutionState = personalization.MisconceptionResolutionState
 
 const MisconceptionSupportSchemaVersion = personalization.MisconceptionSupportSchemaVersion
 
+const ConceptEvidenceSourceRemoved = products.ConceptEvidenceSourceRemoved
+
+type NodeDocDigest = products.NodeDocDigest
+
+const NodeDocDigestVersion = products.NodeDocDigestVersion
+
+func ExtractNodeDocDigest(docJSON []byte) NodeDocDigest {
+	return products.ExtractNodeDocDigest(docJSON)
+}
+
+func DecodeNodeDocDigest(raw []byte) NodeDocDigest {
+	return products.DecodeNodeDocDigest(raw)
+}
+
+const (
+	NodeDocTrackPrimary = products.NodeDocTrackPrimary
+	NodeDocTrackReading = products.NodeDocTrackReading
+	NodeDocTrackHandsOn = products.NodeDocTrackHandsOn
+	NodeDocTrackVisual  = products.NodeDocTrackVisual
+)
+
+func NodeDocTracks() []string { return products.NodeDocTracks() }
+
+func NormalizeNodeDocTrack(track string) (string, bool) {
+	return products.NormalizeNodeDocTrack(track)
+}
+
+const (
+	PathNodeDescriptionKey         = core.PathNodeDescriptionKey
+	PathNodeDescriptionSourceKey   = core.PathNodeDescriptionSourceKey
+	PathNodeDescriptionDocHashKey  = core.PathNodeDescriptionDocHashKey
+	PathNodeDescriptionSourceBuild = core.PathNodeDescriptionSourceBuild
+	PathNodeDescriptionSourceDoc   = core.PathNodeDescriptionSourceDoc
+	PathNodeDescriptionSourceUser  = core.PathNodeDescriptionSourceUser
+)
+
+func PathNodeDescription(meta []byte) (string, string) {
+	return core.PathNodeDescription(meta)
+}
+
+const PathChatWebEnrichmentKey = core.PathChatWebEnrichmentKey
+
+func PathChatWebEnrichmentEnabled(meta []byte) bool {
+	return core.PathChatWebEnrichmentEnabled(meta)
+}
+
 func NormalizeMisconceptionSignature(sig string) string {
 	return personalization.NormalizeMisconceptionSignature(sig)
 }
@@ -168,6 +217,7 @@ func AddMisconceptionTriggerContext(s personalization.MisconceptionSupport, ctx
 type Concept = core.Concept
 type ConceptRepresentation = core.ConceptRepresentation
 type ConceptMappingOverride = core.ConceptMappingOverride
+type ConceptDocEmbedding = core.ConceptDocEmbedding
 type Activity = core.Activity
 type ActivityVariant = core.ActivityVariant
 type ActivityConcept = joins.ActivityConcept
@@ -175,6 +225,8 @@ type ActivityCitation = joins.ActivityCitation
 
 type Path = core.Path
 type PathNode = core.PathNode
+type PathShare = core.PathShare
+type PathFocus = core.PathFocus
 type PathStructuralUnit = core.PathStructuralUnit
 type PathNodeActivity = joins.PathNodeActivity
 
@@ -205,6 +257,9 @@ type ActivityVariantStat = products.ActivityVariantStat
 type LearningNodeDoc = products.LearningNodeDoc
 type LearningNodeDocRevision = products.LearningNodeDocRevision
 type LearningNodeFigure = products.LearningNodeFigure
+type FigureBlob = products.FigureBlob
+type LearningNodeAudio = products.LearningNodeAudio
+type NodeAssetRef = products.NodeAssetRef
 type LearningNodeVideo = products.LearningNodeVideo
 type LearningDocGenerationRun = products.LearningDocGenerationRun
 type LearningNodeDocVariant = products.LearningNodeDocVariant
@@ -213,9 +268,11 @@ type UserDocSignalSnapshot = products.UserDocSignalSnapshot
 type DocRetrievalPack = products.DocRetrievalPack
 type DocGenerationTrace = products.DocGenerationTrace
 type DocConstraintReport = products.DocConstraintReport
+type DocConsistencyAuditRun = products.DocConsistencyAuditRun
 type DocProbe = products.DocProbe
 type DocProbeOutcome = products.DocProbeOutcome
 type DocVariantExposure = products.DocVariantExposure
+type DocVariantAssignment = products.DocVariantAssignment
 type DocVariantOutcome = products.DocVariantOutcome
 type LearningDrillInstance = products.LearningDrillInstance
 type LearningArtifact = products.LearningArtifact
@@ -236,3 +293,10 @@ type ChatEdge = chat.ChatEdge
 type ChatClaim = chat.ChatClaim
 type ChatDoc = chat.ChatDoc
 type ChatTurn = chat.ChatTurn
+type ChatToolExecution = chat.ChatToolExecution
+type ContextBudgetStat = chat.ContextBudgetStat
+type ContextBudgetLaneUsage = chat.ContextBudgetLaneUsage
+
+func PathSizeBucket(nodes int) string { return chat.PathSizeBucket(nodes) }
+
+type FeatureFlag = platform.FeatureFlag
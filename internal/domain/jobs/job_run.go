@@ -11,9 +11,9 @@ type JobRun struct {
 	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 	OwnerUserID uuid.UUID      `gorm:"type:uuid;not null;index" json:"owner_user_id"`
 	JobType     string         `gorm:"column:job_type;not null;index" json:"job_type"`
-	EntityType  string         `gorm:"column:entity_type;index" json:"entity_type,omitempty"`
-	EntityID    *uuid.UUID     `gorm:"type:uuid;column:entity_id;index" json:"entity_id,omitempty"`
-	Status      string         `gorm:"column:status;not null;index" json:"status"`
+	EntityType  string         `gorm:"column:entity_type;index;index:idx_job_run_entity_status,priority:1" json:"entity_type,omitempty"`
+	EntityID    *uuid.UUID     `gorm:"type:uuid;column:entity_id;index;index:idx_job_run_entity_status,priority:2" json:"entity_id,omitempty"`
+	Status      string         `gorm:"column:status;not null;index;index:idx_job_run_entity_status,priority:3" json:"status"`
 	Stage       string         `gorm:"column:stage;not null;index" json:"stage"`
 	Progress    int            `gorm:"column:progress;not null;default:0" json:"progress"`
 	Attempts    int            `gorm:"column:attempts;not null;default:0" json:"attempts"`
@@ -22,8 +22,19 @@ type JobRun struct {
 	LockedAt    *time.Time     `gorm:"column:locked_at;index" json:"locked_at,omitempty"`
 	HeartbeatAt *time.Time     `gorm:"column:heartbeat_at;index" json:"heartbeat_at,omitempty"`
 	LastErrorAt *time.Time     `gorm:"column:last_error_at;index" json:"last_error_at,omitempty"`
+	NotBefore   *time.Time     `gorm:"column:not_before;index" json:"not_before,omitempty"`
 	Payload     datatypes.JSON `gorm:"column:payload;type:jsonb" json:"payload"`
+	// SchedClass opts the job into fair scheduling (e.g. "docgen"); SchedPathID is the path
+	// whose in-flight cap it counts against.
+	SchedClass  string         `gorm:"column:sched_class;not null;default:'';index" json:"sched_class,omitempty"`
+	SchedPathID *uuid.UUID     `gorm:"type:uuid;column:sched_path_id;index" json:"sched_path_id,omitempty"`
 	Result      datatypes.JSON `gorm:"column:result;type:jsonb" json:"result"`
+	// Trace envelope, kept out of Payload: the request trace the job descends from, the job
+	// that enqueued it (nil when enqueued by a request) and the number of job hops from it.
+	TraceID     string         `gorm:"column:trace_id;type:text;not null;default:'';index" json:"trace_id,omitempty"`
+	RequestID   string         `gorm:"column:request_id;type:text;not null;default:''" json:"request_id,omitempty"`
+	ParentJobID *uuid.UUID     `gorm:"type:uuid;column:parent_job_id;index" json:"parent_job_id,omitempty"`
+	TraceDepth  int            `gorm:"column:trace_depth;not null;default:0" json:"trace_depth,omitempty"`
 	CreatedAt   time.Time      `gorm:"not null;default:now();index" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"not null;default:now();index" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// JobSchedulerState tracks one user's share of a fair-scheduled job class. VirtualTime grows by
+// 1/Weight per claim; the user with the lowest value is served next.
+type JobSchedulerState struct {
+	SchedClass     string    `gorm:"column:sched_class;primaryKey" json:"sched_class"`
+	OwnerUserID    uuid.UUID `gorm:"type:uuid;column:owner_user_id;primaryKey" json:"owner_user_id"`
+	Weight         float64   `gorm:"column:weight;not null;default:1" json:"weight"`
+	VirtualTime    float64   `gorm:"column:virtual_time;not null;default:0" json:"virtual_time"`
+	InFlight       int       `gorm:"column:in_flight;not null;default:0" json:"in_flight"`
+	ClaimedTotal   int64     `gorm:"column:claimed_total;not null;default:0" json:"claimed_total"`
+	CompletedTotal int64     `gorm:"column:completed_total;not null;default:0" json:"completed_total"`
+	UpdatedAt      time.Time `gorm:"not null;default:now();index" json:"updated_at"`
+}
+
+func (JobSchedulerState) TableName() string { return "job_scheduler_state" }
@@ -37,7 +37,7 @@ type Concept struct {
 	Metadata datatypes.JSON `gorm:"column:metadata;type:jsonb" json:"metadata,omitempty"`
 
 	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:now();index" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
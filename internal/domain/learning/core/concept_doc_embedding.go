@@ -0,0 +1,24 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ConceptDocEmbedding caches the vector of one concept doc (name + summary + key points),
+// addressed by the text's hash, so a rebuild only embeds concepts whose text changed.
+type ConceptDocEmbedding struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	DocHash string `gorm:"column:doc_hash;type:text;not null;uniqueIndex:idx_concept_doc_embedding_hash_model,priority:1" json:"doc_hash"`
+	Model   string `gorm:"column:model;type:text;not null;uniqueIndex:idx_concept_doc_embedding_hash_model,priority:2" json:"model"`
+
+	Dims      int            `gorm:"column:dims;not null;default:0" json:"dims"`
+	Embedding datatypes.JSON `gorm:"column:embedding;type:jsonb;not null" json:"embedding"` // []float32
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+func (ConceptDocEmbedding) TableName() string { return "concept_doc_embedding" }
@@ -34,9 +34,14 @@ type Path struct {
 	ViewCount             int            `gorm:"column:view_count;not null;default:0" json:"view_count"`
 	LastViewedAt          *time.Time     `gorm:"column:last_viewed_at;index" json:"last_viewed_at,omitempty"`
 	ReadyAt               *time.Time     `gorm:"column:ready_at;index" json:"ready_at,omitempty"`
-	CreatedAt             time.Time      `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt             time.Time      `gorm:"not null;default:now()" json:"updated_at"`
-	DeletedAt             gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// OutlineVersion is bumped in the same transaction as every node write and doc commit;
+	// outline read models compare it to decide whether their copy is current.
+	OutlineVersion int64 `gorm:"column:outline_version;not null;default:0" json:"outline_version"`
+
+	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 func (Path) TableName() string { return "path" }
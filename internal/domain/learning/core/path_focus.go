@@ -0,0 +1,113 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// PathFocus is a learner-declared goal ("the midterm on recursion") that chat, drills, session
+// plans and doc serving bias toward until it expires or is ended. A user has at most one open
+// focus per path; starting a new one ends the previous.
+type PathFocus struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_path_focus_user_path,priority:1" json:"user_id"`
+	PathID uuid.UUID `gorm:"type:uuid;not null;index:idx_path_focus_user_path,priority:2" json:"path_id"`
+
+	GoalText string `gorm:"column:goal_text;type:text;not null" json:"goal_text"`
+	// ConceptKeys optionally narrows the focus to concepts; empty means the goal text alone.
+	ConceptKeys datatypes.JSON `gorm:"column:concept_keys;type:jsonb;not null;default:'[]'" json:"concept_keys"`
+
+	Until   time.Time  `gorm:"column:until;not null;index" json:"until"`
+	EndedAt *time.Time `gorm:"column:ended_at;index" json:"ended_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (PathFocus) TableName() string { return "path_focus" }
+
+// Active reports whether the focus still applies at now.
+func (f *PathFocus) Active(now time.Time) bool {
+	if f == nil || f.EndedAt != nil {
+		return false
+	}
+	return now.Before(f.Until)
+}
+
+// ConceptKeyList returns the focus concept keys, lowercased and deduplicated.
+func (f *PathFocus) ConceptKeyList() []string {
+	if f == nil || len(f.ConceptKeys) == 0 || string(f.ConceptKeys) == "null" {
+		return nil
+	}
+	var raw []string
+	if json.Unmarshal(f.ConceptKeys, &raw) != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(raw))
+	for _, k := range raw {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, k)
+	}
+	return out
+}
+
+// pathFocusStopwords are dropped from goal text; they match every node and bias nothing.
+var pathFocusStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true, "about": true,
+	"that": true, "this": true, "these": true, "those": true, "are": true, "was": true, "were": true,
+	"will": true, "can": true, "how": true, "what": true, "why": true, "when": true, "which": true,
+	"exam": true, "test": true, "quiz": true, "midterm": true, "final": true, "study": true,
+	"review": true, "learn": true, "understand": true, "prepare": true, "prep": true, "need": true,
+	"want": true, "focus": true, "week": true, "next": true, "tomorrow": true, "today": true,
+}
+
+// maxPathFocusGoalTerms caps the terms taken from the goal text.
+const maxPathFocusGoalTerms = 12
+
+// GoalTerms returns the distinctive lowercased words of the goal text, in order, without
+// stopwords or words shorter than three characters.
+func (f *PathFocus) GoalTerms() []string {
+	if f == nil {
+		return nil
+	}
+	words := strings.FieldsFunc(strings.ToLower(f.GoalText), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := map[string]bool{}
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		if len([]rune(w)) < 3 || pathFocusStopwords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+		if len(out) >= maxPathFocusGoalTerms {
+			break
+		}
+	}
+	return out
+}
+
+// TraceMeta is the focus as recorded in chat traces and doc exposure metadata, so later
+// analysis can compare focused and unfocused sessions.
+func (f *PathFocus) TraceMeta() map[string]any {
+	if f == nil {
+		return nil
+	}
+	return map[string]any{
+		"focus_id":     f.ID.String(),
+		"goal_text":    f.GoalText,
+		"concept_keys": f.ConceptKeyList(),
+		"until":        f.Until.UTC().Format(time.RFC3339),
+	}
+}
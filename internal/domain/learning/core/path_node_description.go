@@ -0,0 +1,46 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Path node metadata keys for the description shown in outlines. The path build writes the
+// node's goal; the description sync and user edits write description, tagged with its source.
+const (
+	PathNodeDescriptionKey       = "description"
+	PathNodeDescriptionSourceKey = "description_source"
+	// PathNodeDescriptionDocHashKey is the content hash of the doc a derived description was
+	// taken from.
+	PathNodeDescriptionDocHashKey = "description_doc_hash"
+)
+
+// Description sources. A user-edited description is never replaced by a derived one.
+const (
+	PathNodeDescriptionSourceBuild = "build"
+	PathNodeDescriptionSourceDoc   = "derived_from_doc"
+	PathNodeDescriptionSourceUser  = "user"
+)
+
+// PathNodeDescription returns the description to show for a node and where it came from: the
+// synced or user-edited description when one is set, otherwise the goal from the path build.
+func PathNodeDescription(meta []byte) (string, string) {
+	if len(meta) == 0 || string(meta) == "null" {
+		return "", ""
+	}
+	var m map[string]any
+	if json.Unmarshal(meta, &m) != nil {
+		return "", ""
+	}
+	if desc, _ := m[PathNodeDescriptionKey].(string); strings.TrimSpace(desc) != "" {
+		source, _ := m[PathNodeDescriptionSourceKey].(string)
+		if source = strings.TrimSpace(source); source == "" {
+			source = PathNodeDescriptionSourceBuild
+		}
+		return strings.TrimSpace(desc), source
+	}
+	if goal, _ := m["goal"].(string); strings.TrimSpace(goal) != "" {
+		return strings.TrimSpace(goal), PathNodeDescriptionSourceBuild
+	}
+	return "", ""
+}
@@ -0,0 +1,20 @@
+package core
+
+import "encoding/json"
+
+// PathChatWebEnrichmentKey is the path metadata flag that lets chat in the path's threads
+// search the public web when a question falls outside the path's materials. Off unless set.
+const PathChatWebEnrichmentKey = "chat_web_enrichment"
+
+// PathChatWebEnrichmentEnabled reads PathChatWebEnrichmentKey from path metadata.
+func PathChatWebEnrichmentEnabled(meta []byte) bool {
+	if len(meta) == 0 || string(meta) == "null" {
+		return false
+	}
+	var m map[string]any
+	if json.Unmarshal(meta, &m) != nil {
+		return false
+	}
+	on, _ := m[PathChatWebEnrichmentKey].(bool)
+	return on
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PathShare is a read-only, revocable link to a path's outline and base node docs. Only the
+// SHA-256 of the token is stored; the token itself is shown once, when the share is created.
+type PathShare struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	PathID uuid.UUID `gorm:"type:uuid;not null;index" json:"path_id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+
+	TokenHash string `gorm:"column:token_hash;type:text;not null;uniqueIndex" json:"-"`
+	Scope     string `gorm:"column:scope;type:text;not null;default:'read_only'" json:"scope"`
+
+	ExpiresAt *time.Time `gorm:"column:expires_at;index" json:"expires_at,omitempty"`
+	RevokedAt *time.Time `gorm:"column:revoked_at;index" json:"revoked_at,omitempty"`
+
+	ViewCount      int        `gorm:"column:view_count;not null;default:0" json:"view_count"`
+	LastAccessedAt *time.Time `gorm:"column:last_accessed_at" json:"last_accessed_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (PathShare) TableName() string { return "path_share" }
+
+// Active reports whether the share can still be used at now.
+func (s *PathShare) Active(now time.Time) bool {
+	if s == nil || s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
@@ -19,6 +19,9 @@ type PathNodeActivity struct {
 
 	Rank      int  `gorm:"column:rank;not null;default:0" json:"rank"`
 	IsPrimary bool `gorm:"column:is_primary;not null;default:true" json:"is_primary"`
+	// Role is the curated role of the activity on this node ("practice", "assessment",
+	// "enrichment"); empty for generated links.
+	Role string `gorm:"column:role;not null;default:''" json:"role,omitempty"`
 
 	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
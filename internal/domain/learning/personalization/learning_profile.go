@@ -20,6 +20,10 @@ type LearningProfile struct {
 	Preferences   datatypes.JSON `gorm:"type:jsonb;column:preferences" json:"preferences"`
 	Notes         string         `gorm:"column:notes" json:"notes"`
 
+	// DefaultDocTrack is the node doc track served when a request doesn't name one; empty means
+	// primary.
+	DefaultDocTrack string `gorm:"column:default_doc_track;type:text" json:"default_doc_track,omitempty"`
+
 	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -75,10 +75,13 @@ const (
 
 type UserEvent struct {
 	ID     uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	UserID uuid.UUID  `gorm:"type:uuid;not null;index;index:idx_user_client_event,unique,priority:1" json:"user_id"`
+	UserID uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
 	User   *user.User `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID" json:"user,omitempty"`
-	// Frontend-provided idempotency key (required; backend will generate if missing)
-	ClientEventID string `gorm:"column:client_event_id;not null;index:idx_user_client_event,unique,priority:2" json:"client_event_id"`
+	// Client-generated idempotency key (a UUID; the server generates one only during the
+	// deprecation window). Unique per user via idx_user_client_event, which db.EnsureUserEventIndexes
+	// builds concurrently after removing historical duplicates; fold pipelines rely on it and
+	// do no dedupe of their own.
+	ClientEventID string `gorm:"column:client_event_id;not null" json:"client_event_id"`
 	// When the action happened (client clock). CreatedAt is server receive time.
 	OccurredAt time.Time `gorm:"column:occurred_at;not null;index" json:"occurred_at"`
 	// Correlate to a session (your UserToken.ID is perfect)
@@ -7,6 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ConceptEvidenceSourceRemoved marks evidence whose material file was deleted by the user.
+const ConceptEvidenceSourceRemoved = "source_removed"
+
 // Concept evidence (grounding).
 type ConceptEvidence struct {
 	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
@@ -14,6 +17,9 @@ type ConceptEvidence struct {
 	MaterialChunkID uuid.UUID `gorm:"type:uuid;not null;index:idx_concept_evidence,unique,priority:2" json:"material_chunk_id"`
 	Kind            string    `gorm:"column:kind;index" json:"kind,omitempty"`
 	Weight          float64   `gorm:"column:weight;not null;default:1" json:"weight"`
+	// Status is empty for live evidence and ConceptEvidenceSourceRemoved once the chunk's file
+	// was deleted; such rows are kept so evidence counts don't shift.
+	Status string `gorm:"column:status;not null;default:'';index" json:"status,omitempty"`
 
 	CreatedAt time.Time      `gorm:"not null;default:now();index" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"not null;default:now();index" json:"updated_at"`
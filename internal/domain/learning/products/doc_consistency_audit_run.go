@@ -0,0 +1,27 @@
+package products
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// DocConsistencyAuditRun stores the report of one doc/variant/exposure/revision invariant audit.
+// ReportJSON holds the per-checker results (counts, sample row IDs, repairs).
+type DocConsistencyAuditRun struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	JobID *uuid.UUID `gorm:"type:uuid;column:job_id;index" json:"job_id,omitempty"`
+
+	Checks     int  `gorm:"column:checks;not null" json:"checks"`
+	Violations int  `gorm:"column:violations;not null" json:"violations"`
+	Repaired   int  `gorm:"column:repaired;not null" json:"repaired"`
+	AutoRepair bool `gorm:"column:auto_repair;not null" json:"auto_repair"`
+
+	ReportJSON datatypes.JSON `gorm:"type:jsonb;column:report_json;not null" json:"report_json"`
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+func (DocConsistencyAuditRun) TableName() string { return "doc_consistency_audit_run" }
@@ -23,6 +23,8 @@ type LearningDocGenerationRun struct {
 	Model         string `gorm:"column:model;type:text;not null" json:"model"`
 	PromptVersion string `gorm:"column:prompt_version;type:text;not null" json:"prompt_version"`
 	Attempt       int    `gorm:"column:attempt;not null" json:"attempt"`
+	// EmphasisProfile is the regeneration emphasis profile the run was prompted with, if any.
+	EmphasisProfile string `gorm:"column:emphasis_profile;type:text;not null;default:''" json:"emphasis_profile,omitempty"`
 
 	LatencyMS int `gorm:"column:latency_ms;not null" json:"latency_ms"`
 	TokensIn  int `gorm:"column:tokens_in;not null" json:"tokens_in"`
@@ -26,6 +26,12 @@ type DocGenerationTrace struct {
 
 	TraceJSON datatypes.JSON `gorm:"type:jsonb;column:trace_json;not null" json:"trace_json"`
 
+	// TraceID above identifies the generation inputs; these link the row to the request trace
+	// and job run that last produced it.
+	RequestTraceID string     `gorm:"column:request_trace_id;type:text;not null;default:'';index" json:"request_trace_id,omitempty"`
+	RequestID      string     `gorm:"column:request_id;type:text;not null;default:''" json:"request_id,omitempty"`
+	JobID          *uuid.UUID `gorm:"type:uuid;column:job_id;index" json:"job_id,omitempty"`
+
 	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
 }
 
@@ -0,0 +1,26 @@
+package products
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocVariantAssignment pins a user to a doc variant experiment arm for one policy version.
+// Once written it wins over the rollout hash, so changing the rollout pct never reshuffles users.
+type DocVariantAssignment struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_doc_variant_assignment_user_policy,priority:1" json:"user_id"`
+	PolicyVersion string    `gorm:"column:policy_version;type:text;not null;uniqueIndex:idx_doc_variant_assignment_user_policy,priority:2;index" json:"policy_version"`
+
+	Arm        string  `gorm:"column:arm;type:text;not null;index" json:"arm"` // treatment|holdback
+	RolloutPct float64 `gorm:"column:rollout_pct;not null;default:0" json:"rollout_pct"`
+	// Source is how the arm was decided: hash (first eligible request) or exposure_backfill.
+	Source string `gorm:"column:source;type:text;not null;default:'hash'" json:"source"`
+
+	AssignedAt time.Time `gorm:"column:assigned_at;not null;default:now()" json:"assigned_at"`
+	CreatedAt  time.Time `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+func (DocVariantAssignment) TableName() string { return "doc_variant_assignment" }
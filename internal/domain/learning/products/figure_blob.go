@@ -0,0 +1,25 @@
+package products
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FigureBlob indexes generated figure images by content hash so identical renders share one
+// stored object. RefCount tracks how many LearningNodeFigure rows point at the object; the
+// object is deleted only when the count reaches zero.
+type FigureBlob struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	ContentHash string `gorm:"column:content_hash;type:text;not null;uniqueIndex:idx_figure_blob_content_hash" json:"content_hash"`
+	StorageKey  string `gorm:"column:storage_key;type:text;not null" json:"storage_key"`
+	MimeType    string `gorm:"column:mime_type;type:text" json:"mime_type,omitempty"`
+	ByteLen     int    `gorm:"column:byte_len;not null;default:0" json:"byte_len"`
+	RefCount    int    `gorm:"column:ref_count;not null;default:0" json:"ref_count"`
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now();index" json:"updated_at"`
+}
+
+func (FigureBlob) TableName() string { return "figure_blob" }
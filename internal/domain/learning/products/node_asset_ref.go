@@ -0,0 +1,24 @@
+package products
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NodeAssetRef records that a doc (or doc variant) references a stored object. Rows are
+// rewritten with every doc write so the orphan sweeper can check a key with an index lookup
+// instead of scanning doc JSON. OwnerKey is the owner's stable identity: the path node ID for
+// docs, the snapshot ID for variants.
+type NodeAssetRef struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	OwnerKind  string    `gorm:"column:owner_kind;not null;uniqueIndex:idx_node_asset_ref_owner_key,priority:1" json:"owner_kind"`
+	OwnerKey   string    `gorm:"column:owner_key;not null;uniqueIndex:idx_node_asset_ref_owner_key,priority:2" json:"owner_key"`
+	StorageKey string    `gorm:"column:storage_key;type:text;not null;uniqueIndex:idx_node_asset_ref_owner_key,priority:3;index" json:"storage_key"`
+	PathNodeID uuid.UUID `gorm:"type:uuid;not null;index" json:"path_node_id"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+func (NodeAssetRef) TableName() string { return "node_asset_ref" }
@@ -0,0 +1,42 @@
+package products
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LearningNodeAudio is one narrated segment of a node doc. Segments are block-aligned (see
+// content.NodeDocNarrationSegments) so playback position maps back to a block.
+type LearningNodeAudio struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PathID       uuid.UUID `gorm:"type:uuid;not null;index" json:"path_id"`
+	PathNodeID   uuid.UUID `gorm:"type:uuid;not null;index:idx_learning_node_audio_segment,unique,priority:1;index" json:"path_node_id"`
+	SegmentIndex int       `gorm:"column:segment_index;not null;index:idx_learning_node_audio_segment,unique,priority:2" json:"segment_index"`
+
+	DocID uuid.UUID `gorm:"type:uuid;column:doc_id;not null;index" json:"doc_id"`
+	// DocContentHash is the doc's ContentHash at synthesis time; a mismatch means the audio is stale.
+	DocContentHash string `gorm:"column:doc_content_hash;type:text;not null;index" json:"doc_content_hash"`
+
+	BlockID   string `gorm:"column:block_id;type:text;index" json:"block_id,omitempty"` // empty for the title intro
+	BlockType string `gorm:"column:block_type;type:text;not null" json:"block_type"`
+	Part      int    `gorm:"column:part;not null;default:0" json:"part"`
+	// TextHash covers provider, voice and text; equal hashes reuse the same audio object.
+	TextHash string `gorm:"column:text_hash;type:text;not null" json:"text_hash"`
+
+	StorageKey string `gorm:"column:storage_key;type:text;not null" json:"storage_key"`
+	MimeType   string `gorm:"column:mime_type;type:text;not null" json:"mime_type"`
+	StartMS    int    `gorm:"column:start_ms;not null" json:"start_ms"`
+	DurationMS int    `gorm:"column:duration_ms;not null" json:"duration_ms"`
+
+	Provider string `gorm:"column:provider;type:text;not null" json:"provider"`
+	Voice    string `gorm:"column:voice;type:text" json:"voice,omitempty"`
+	Status   string `gorm:"column:status;type:text;not null;index" json:"status"` // ready|stale
+
+	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now();index" json:"updated_at"`
+}
+
+func (LearningNodeAudio) TableName() string { return "learning_node_audio" }
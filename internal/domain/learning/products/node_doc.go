@@ -1,12 +1,45 @@
 package products
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+// Doc tracks are stable alternate docs for one node that the learner picks between (unlike
+// variants, which are experiments on the primary doc). The primary track is the doc the path
+// is built from; the others are generated on demand from the same sources.
+const (
+	NodeDocTrackPrimary = "primary"
+	NodeDocTrackReading = "reading"
+	NodeDocTrackHandsOn = "hands_on"
+	NodeDocTrackVisual  = "visual"
+)
+
+var nodeDocTracks = []string{NodeDocTrackPrimary, NodeDocTrackReading, NodeDocTrackHandsOn, NodeDocTrackVisual}
+
+// NodeDocTracks lists the accepted tracks, primary first.
+func NodeDocTracks() []string {
+	return append([]string(nil), nodeDocTracks...)
+}
+
+// NormalizeNodeDocTrack returns the canonical track name, mapping "" to primary. ok is false
+// for unknown tracks.
+func NormalizeNodeDocTrack(track string) (string, bool) {
+	track = strings.ToLower(strings.TrimSpace(track))
+	if track == "" {
+		return NodeDocTrackPrimary, true
+	}
+	for _, t := range nodeDocTracks {
+		if t == track {
+			return t, true
+		}
+	}
+	return "", false
+}
+
 // LearningNodeDoc is the canonical, versioned doc artifact for a PathNode.
 // It is intentionally separate from path_node.content_json so we can evolve schemas
 // without overloading core node rows.
@@ -15,7 +48,10 @@ type LearningNodeDoc struct {
 
 	UserID     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
 	PathID     uuid.UUID `gorm:"type:uuid;not null;index" json:"path_id"`
-	PathNodeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"path_node_id"`
+	PathNodeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_learning_node_doc_node_track,priority:1" json:"path_node_id"`
+	// Track names which of the node's docs this is (see NodeDocTrackPrimary); a node has at most
+	// one doc per track.
+	Track string `gorm:"column:track;type:text;not null;default:'primary';uniqueIndex:idx_learning_node_doc_node_track,priority:2" json:"track"`
 
 	SchemaVersion int            `gorm:"column:schema_version;not null" json:"schema_version"`
 	DocJSON       datatypes.JSON `gorm:"type:jsonb;column:doc_json;not null" json:"doc_json"`
@@ -27,6 +63,22 @@ type LearningNodeDoc struct {
 	ContentHash string `gorm:"column:content_hash;type:text;not null;index" json:"content_hash"`
 	SourcesHash string `gorm:"column:sources_hash;type:text;not null;index" json:"sources_hash"`
 
+	// Version is bumped on every write; writers must present the version they read
+	// (optimistic locking) so concurrent edits are never silently overwritten.
+	Version int `gorm:"column:version;not null;default:1" json:"version"`
+
+	// Frozen locks a finalized doc: it is always served as the base (no variants) and block
+	// patches are rejected.
+	Frozen bool `gorm:"column:frozen;not null;default:false" json:"frozen"`
+
+	// Metadata holds doc-level maintenance state (e.g. summary_stale) that isn't part of the
+	// rendered doc.
+	Metadata datatypes.JSON `gorm:"type:jsonb;column:metadata" json:"metadata,omitempty"`
+
+	// Digest is the NodeDocDigest of DocJSON, recomputed by the repo on every commit so readers
+	// that only need what the doc teaches (outlines, description sync) skip parsing the doc.
+	Digest datatypes.JSON `gorm:"type:jsonb;column:digest" json:"digest,omitempty"`
+
 	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:now();index" json:"updated_at"`
 }
@@ -0,0 +1,85 @@
+package products
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// NodeDocDigestVersion is the NodeDocDigest layout written on commit; rows written before the
+// digest existed decode to version 0.
+const NodeDocDigestVersion = 1
+
+// Digest limits: the summary is a snippet for outlines, not the doc summary itself.
+const (
+	nodeDocDigestSummaryMaxRunes = 280
+	nodeDocDigestMaxObjectives   = 12
+)
+
+// NodeDocDigest is what a node doc teaches, extracted from its top-level summary and its
+// objectives blocks.
+type NodeDocDigest struct {
+	Version    int      `json:"version"`
+	Summary    string   `json:"summary"`
+	Objectives []string `json:"objectives"`
+}
+
+// DecodeNodeDocDigest reads a stored digest; a missing or unreadable one decodes to version 0.
+func DecodeNodeDocDigest(raw []byte) NodeDocDigest {
+	var d NodeDocDigest
+	if len(raw) == 0 || string(raw) == "null" || json.Unmarshal(raw, &d) != nil {
+		return NodeDocDigest{}
+	}
+	return d
+}
+
+// ExtractNodeDocDigest computes the digest of a NodeDocV1 doc_json. The summary is cut to its
+// leading sentences within nodeDocDigestSummaryMaxRunes; objectives are the items of the doc's
+// objectives blocks, in order and deduplicated.
+func ExtractNodeDocDigest(docJSON []byte) NodeDocDigest {
+	out := NodeDocDigest{Version: NodeDocDigestVersion, Objectives: []string{}}
+	var doc struct {
+		Summary string           `json:"summary"`
+		Blocks  []map[string]any `json:"blocks"`
+	}
+	if len(docJSON) == 0 || json.Unmarshal(docJSON, &doc) != nil {
+		return out
+	}
+	out.Summary = nodeDocSummarySnippet(doc.Summary)
+	seen := map[string]bool{}
+	for _, b := range doc.Blocks {
+		if t, _ := b["type"].(string); t != "objectives" {
+			continue
+		}
+		items, _ := b["items_md"].([]any)
+		for _, it := range items {
+			s, _ := it.(string)
+			s = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(s), "-*• "))
+			key := strings.ToLower(s)
+			if s == "" || seen[key] || len(out.Objectives) >= nodeDocDigestMaxObjectives {
+				continue
+			}
+			seen[key] = true
+			out.Objectives = append(out.Objectives, s)
+		}
+	}
+	return out
+}
+
+// nodeDocSummarySnippet collapses whitespace and keeps whole sentences up to the limit; a
+// first sentence longer than the limit is cut at a word boundary.
+func nodeDocSummarySnippet(summary string) string {
+	s := strings.Join(strings.Fields(summary), " ")
+	if utf8.RuneCountInString(s) <= nodeDocDigestSummaryMaxRunes {
+		return s
+	}
+	runes := []rune(s)[:nodeDocDigestSummaryMaxRunes]
+	cut := string(runes)
+	if i := strings.LastIndexAny(cut, ".!?"); i > 0 {
+		return cut[:i+1]
+	}
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
@@ -16,6 +16,8 @@ type LearningNodeDocRevision struct {
 	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
 	PathID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"path_id"`
 	PathNodeID uuid.UUID  `gorm:"type:uuid;not null;index" json:"path_node_id"`
+	// Track is the track of the doc the revision belongs to.
+	Track string `gorm:"column:track;type:text;not null;default:'primary'" json:"track"`
 
 	BlockID   string `gorm:"column:block_id;type:text;not null" json:"block_id"`
 	BlockType string `gorm:"column:block_type;type:text;not null" json:"block_type"`
@@ -35,6 +37,9 @@ type LearningNodeDocRevision struct {
 	TokensIn      int    `gorm:"column:tokens_in;not null" json:"tokens_in"`
 	TokensOut     int    `gorm:"column:tokens_out;not null" json:"tokens_out"`
 
+	// Metadata carries validation output for the edit (rich-text repairs, constraint warnings).
+	Metadata datatypes.JSON `gorm:"type:jsonb;column:metadata" json:"metadata,omitempty"`
+
 	CreatedAt time.Time `gorm:"not null;default:now();index" json:"created_at"`
 }
 
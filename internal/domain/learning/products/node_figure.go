@@ -31,6 +31,9 @@ type LearningNodeFigure struct {
 	AssetStorageKey string     `gorm:"column:asset_storage_key;type:text" json:"asset_storage_key,omitempty"`
 	AssetURL        string     `gorm:"column:asset_url;type:text" json:"asset_url,omitempty"`
 	AssetMimeType   string     `gorm:"column:asset_mime_type;type:text" json:"asset_mime_type,omitempty"`
+	// ContentHash is the sha256 of the rendered image; set when the asset lives in the shared
+	// content-addressed store (see FigureBlob).
+	ContentHash string `gorm:"column:content_hash;type:text;index" json:"content_hash,omitempty"`
 
 	Error string `gorm:"column:error;type:text" json:"error,omitempty"`
 
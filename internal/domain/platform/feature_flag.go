@@ -0,0 +1,29 @@
+package platform
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// FeatureFlag is one runtime flag evaluated by internal/platform/featureflag.
+//
+// DefaultValue (when set) overrides the caller's env default for everyone; Rules hold the
+// targeting (path overrides, user allowlists, percentage rollout) as JSON.
+type FeatureFlag struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	Name        string `gorm:"column:name;not null;uniqueIndex" json:"name"`
+	Description string `gorm:"column:description;type:text" json:"description,omitempty"`
+	// DefaultValue NULL means "use the env default".
+	DefaultValue *string        `gorm:"column:default_value;type:text" json:"default_value,omitempty"`
+	Rules        datatypes.JSON `gorm:"column:rules;type:jsonb;not null;default:'{}'" json:"rules"`
+
+	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func (FeatureFlag) TableName() string { return "feature_flag" }
@@ -0,0 +1,32 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// UserNotification is a persisted, per-user inbox entry (build finished, doc updated, ...).
+// Unlike SSE job events it survives reconnects and carries read state.
+type UserNotification struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_user_notification_dedup,priority:1;index:idx_user_notification_feed,priority:1" json:"user_id"`
+
+	Kind  string `gorm:"column:kind;type:text;not null;index" json:"kind"`
+	Title string `gorm:"column:title;type:text;not null" json:"title"`
+	Body  string `gorm:"column:body;type:text;not null;default:''" json:"body,omitempty"`
+
+	// Link target the client navigates to (e.g. link_type=path, link_id=<path id>).
+	LinkType string     `gorm:"column:link_type;type:text;not null;default:''" json:"link_type,omitempty"`
+	LinkID   *uuid.UUID `gorm:"type:uuid;column:link_id" json:"link_id,omitempty"`
+
+	// DedupKey collapses repeats of the same event (e.g. "job:<id>"); one row per user and key.
+	DedupKey string         `gorm:"column:dedup_key;type:text;not null;uniqueIndex:idx_user_notification_dedup,priority:2" json:"-"`
+	Metadata datatypes.JSON `gorm:"type:jsonb;column:metadata" json:"metadata,omitempty"`
+
+	CreatedAt time.Time  `gorm:"not null;default:now();index;index:idx_user_notification_feed,priority:2" json:"created_at"`
+	ReadAt    *time.Time `gorm:"column:read_at;index" json:"read_at,omitempty"`
+}
+
+func (UserNotification) TableName() string { return "user_notification" }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const (
+	contextBudgetDefaultWindow = 7 * 24 * time.Hour
+	contextBudgetMaxWindow     = 90 * 24 * time.Hour
+)
+
+// ContextBudgetHandler serves the sampled chat context budget telemetry.
+type ContextBudgetHandler struct {
+	stats repos.ContextBudgetStatRepo
+}
+
+func NewContextBudgetHandler(stats repos.ContextBudgetStatRepo) *ContextBudgetHandler {
+	return &ContextBudgetHandler{stats: stats}
+}
+
+// GET /api/admin/context-budget/summary?window_hours=168
+// Returns per-lane truncation, drop and pressure rates grouped by path-size bucket, with an
+// "all" rollup per lane. Pressure is the fraction of plans where the lane hit its allocation.
+func (h *ContextBudgetHandler) GetSummary(c *gin.Context) {
+	window := contextBudgetDefaultWindow
+	if v := strings.TrimSpace(c.Query("window_hours")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			response.RespondError(c, http.StatusBadRequest, "invalid_window", err)
+			return
+		}
+		window = time.Duration(n) * time.Hour
+		if window > contextBudgetMaxWindow {
+			window = contextBudgetMaxWindow
+		}
+	}
+	if h.stats == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "context_budget_stats_unavailable", nil)
+		return
+	}
+	until := time.Now().UTC()
+	out, err := h.stats.Summarize(dbctx.Context{Ctx: c.Request.Context()}, until.Add(-window), until)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_context_budget_summary_failed", err)
+		return
+	}
+	response.RespondOK(c, out)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// POST /api/admin/paths/:id/recanonicalize
+//
+// Enqueues a concept_recanonicalize for the path: its concepts are re-matched against the
+// current canonical concept space and re-linked, and the global concept vectors re-upserted,
+// without rebuilding the graph. The job runs as the path owner. A queued or running
+// recanonicalize of the same path is returned instead of enqueueing another.
+func (h *PathHandler) RecanonicalizePathConcepts(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "RecanonicalizePathConcepts"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_service_missing", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	pathRow, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("RecanonicalizePathConcepts failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+	ownerID := rd.UserID
+	if pathRow.UserID != nil && *pathRow.UserID != uuid.Nil {
+		ownerID = *pathRow.UserID
+	}
+
+	if h.jobs != nil {
+		latest, err := h.jobs.GetLatestByEntity(dbc, ownerID, "path", pathID, "concept_recanonicalize")
+		if err != nil {
+			h.log.Warn("RecanonicalizePathConcepts latest job lookup failed", "error", err, "path_id", pathID)
+		} else if regenerateJobMatches(latest, pathID.String()) {
+			response.RespondOK(c, gin.H{"job_id": latest.ID, "deduped": true})
+			return
+		}
+	}
+
+	payload := map[string]any{
+		"path_id":         pathID.String(),
+		"idempotency_key": pathID.String(),
+		"requested_by":    rd.UserID.String(),
+	}
+	entityID := pathID
+	job, err := h.jobSvc.Enqueue(dbc, ownerID, "concept_recanonicalize", "path", &entityID, payload)
+	if err != nil {
+		h.log.Error("RecanonicalizePathConcepts failed (enqueue)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"job_id": job.ID})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// TraceHandler serves support diagnostics keyed by request trace id (the X-Trace-Id header).
+type TraceHandler struct {
+	timeline services.TraceTimelineService
+}
+
+func NewTraceHandler(timeline services.TraceTimelineService) *TraceHandler {
+	return &TraceHandler{timeline: timeline}
+}
+
+// GET /api/admin/trace/:trace_id
+// Returns the jobs, LLM generation traces, doc revisions and exposures recorded under the
+// trace as one timeline, so an investigation can follow a request past "job enqueued".
+func (h *TraceHandler) GetTraceTimeline(c *gin.Context) {
+	traceID := strings.TrimSpace(c.Param("trace_id"))
+	if traceID == "" || len(traceID) > 128 {
+		response.RespondError(c, http.StatusBadRequest, "invalid_trace_id", nil)
+		return
+	}
+	if h.timeline == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "trace_timeline_unavailable", nil)
+		return
+	}
+	out, err := h.timeline.Timeline(c.Request.Context(), traceID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_trace_failed", err)
+		return
+	}
+	response.RespondOK(c, out)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// rolloutBucketHash documents how rolloutBucket places a user, for support reading the audit.
+const rolloutBucketHash = "fnv32a(user_id) % 10000 / 10000"
+
+type variantAssignmentAudit struct {
+	UserID           uuid.UUID `json:"user_id"`
+	PolicyKey        string    `json:"policy_key"`
+	CurrentPolicyKey string    `json:"current_policy_key"`
+	PolicyMode       string    `json:"policy_mode"`
+
+	// Deterministic decision: eligible when bucket < threshold (the current rollout pct).
+	Hash      string  `json:"hash"`
+	Bucket    float64 `json:"bucket"`
+	Threshold float64 `json:"threshold"`
+	Eligible  bool    `json:"rollout_eligible"`
+
+	// Persisted arm for policy_key, if one was recorded. Once assigned it wins over the hash.
+	Assignment *variantAssignmentAuditRow `json:"assignment"`
+}
+
+type variantAssignmentAuditRow struct {
+	Arm        string    `json:"arm"`
+	Source     string    `json:"source"`
+	RolloutPct float64   `json:"rollout_pct"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// GET /api/admin/users/:id/variant-assignment?policy_key=
+//
+// Explains a user's doc variant arm: the stateless rolloutEligible decision (bucket vs the
+// current rollout pct) plus any persisted assignment for the policy key, which defaults to the
+// current one. The bucket does not depend on the policy key.
+func (h *PathHandler) GetUserVariantAssignment(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil || userID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_user_id", err)
+		return
+	}
+
+	policy := docgen.DocPolicy(c.Request.Context())
+	policyKey := strings.TrimSpace(c.Query("policy_key"))
+	if policyKey == "" {
+		policyKey = policy.PolicyKey
+	}
+	out := variantAssignmentAudit{
+		UserID:           userID,
+		PolicyKey:        policyKey,
+		CurrentPolicyKey: policy.PolicyKey,
+		PolicyMode:       policy.Mode,
+		Hash:             rolloutBucketHash,
+		Bucket:           rolloutBucket(userID),
+		Threshold:        policy.RolloutPct,
+		Eligible:         rolloutEligible(userID, policy.RolloutPct),
+	}
+
+	if h.docAssignments != nil {
+		row, err := h.docAssignments.Get(dbctx.Context{Ctx: c.Request.Context()}, userID, policyKey)
+		if err != nil {
+			h.log.Error("GetUserVariantAssignment failed (load assignment)", "error", err, "user_id", userID)
+			response.RespondError(c, http.StatusInternalServerError, "load_assignment_failed", err)
+			return
+		}
+		if row != nil {
+			out.Assignment = &variantAssignmentAuditRow{
+				Arm:        row.Arm,
+				Source:     row.Source,
+				RolloutPct: row.RolloutPct,
+				AssignedAt: row.AssignedAt.UTC(),
+			}
+		}
+	}
+
+	response.RespondOK(c, out)
+}
+
+type variantArmCounts struct {
+	PolicyKey        string                     `json:"policy_key"`
+	CurrentPolicyKey string                     `json:"current_policy_key"`
+	Arms             []repos.DocVariantArmCount `json:"arms"`
+	Total            int64                      `json:"total"`
+}
+
+// GET /api/admin/variant-assignments/arms?policy_key=
+//
+// Reports how many users are pinned to each doc variant arm for the policy key (default: the
+// current one), for checking experiment balance.
+func (h *PathHandler) GetVariantAssignmentArmCounts(c *gin.Context) {
+	policy := docgen.DocPolicy(c.Request.Context())
+	policyKey := strings.TrimSpace(c.Query("policy_key"))
+	if policyKey == "" {
+		policyKey = policy.PolicyKey
+	}
+	out := variantArmCounts{
+		PolicyKey:        policyKey,
+		CurrentPolicyKey: policy.PolicyKey,
+		Arms:             []repos.DocVariantArmCount{},
+	}
+
+	if h.docAssignments != nil {
+		arms, err := h.docAssignments.CountArms(dbctx.Context{Ctx: c.Request.Context()}, policyKey)
+		if err != nil {
+			h.log.Error("GetVariantAssignmentArmCounts failed (count arms)", "error", err, "policy_key", policyKey)
+			response.RespondError(c, http.StatusInternalServerError, "count_arms_failed", err)
+			return
+		}
+		out.Arms = arms
+		for _, a := range arms {
+			out.Total += a.Users
+		}
+	}
+
+	response.RespondOK(c, out)
+}
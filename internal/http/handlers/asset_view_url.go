@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EnvAssetViewBasePath overrides where the API's asset view routes are reachable from clients,
+// e.g. "/backend/api" behind a path prefix or "https://cdn.example.com/api" behind a CDN.
+const EnvAssetViewBasePath = "ASSET_VIEW_BASE_PATH"
+
+const defaultAssetViewBasePath = "/api"
+
+// AssetViewBasePathFromEnv returns the configured asset view base path, or the default.
+func AssetViewBasePathFromEnv() string {
+	return normalizeAssetViewBasePath(os.Getenv(EnvAssetViewBasePath))
+}
+
+func normalizeAssetViewBasePath(base string) string {
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		return defaultAssetViewBasePath
+	}
+	lower := strings.ToLower(base)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+	return base
+}
+
+func (h *PathHandler) assetViewBasePath() string {
+	if h == nil || h.assetViewBase == "" {
+		return defaultAssetViewBasePath
+	}
+	return h.assetViewBase
+}
+
+// nodeAssetViewURLPrefix is the protected asset view URL for a node, up to the escaped key.
+func nodeAssetViewURLPrefix(base string, nodeID uuid.UUID) string {
+	return base + "/path-nodes/" + nodeID.String() + "/assets/view?key="
+}
+
+// sharedNodeAssetViewURLPrefix is the token-scoped asset view URL for a shared node.
+func sharedNodeAssetViewURLPrefix(base string, token string, nodeID uuid.UUID) string {
+	return base + "/shared/" + token + "/nodes/" + nodeID.String() + "/assets/view?key="
+}
+
+func nodeAssetViewURL(base string, nodeID uuid.UUID, storageKey string) string {
+	return nodeAssetViewURLPrefix(base, nodeID) + url.QueryEscape(storageKey)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	chatsteps "github.com/yungbote/neurobridge-backend/internal/modules/chat/steps"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
@@ -162,6 +163,10 @@ func (h *ChatHandler) DeleteThread(c *gin.Context) {
 type sendMessageReq struct {
 	Content        string `json:"content"`
 	IdempotencyKey string `json:"idempotency_key"`
+	// Verbosity is terse, normal (default), or detailed.
+	Verbosity string `json:"verbosity"`
+	// DisabledLanes hard-disables context lanes (e.g. "materials", "graph") for this turn.
+	DisabledLanes []string `json:"disabled_lanes"`
 }
 
 // POST /api/chat/threads/:id/messages
@@ -177,14 +182,36 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	verbosity, ok := chatsteps.ParseAnswerVerbosity(req.Verbosity)
+	if !ok {
+		response.RespondError(c, http.StatusBadRequest, "invalid_verbosity", nil)
+		return
+	}
+	disabledLanes, ok := chatsteps.ParseContextLanes(req.DisabledLanes)
+	if !ok {
+		response.RespondError(c, http.StatusBadRequest, "invalid_disabled_lanes", nil)
+		return
+	}
+
 	idem := strings.TrimSpace(req.IdempotencyKey)
 	if hdr := strings.TrimSpace(c.GetHeader("Idempotency-Key")); hdr != "" {
 		idem = hdr
 	}
 
+	// The reply needs the model right away; while the provider throttles us, tell the client
+	// when to come back instead of queueing a turn that would fail.
+	if wait := llmThrottleWait(); wait > 0 {
+		respondLLMThrottled(c, wait)
+		return
+	}
+
 	dbc := dbctx.Context{Ctx: c.Request.Context()}
-	userMsg, asstMsg, job, err := h.chat.SendMessage(dbc, threadID, req.Content, idem)
+	userMsg, asstMsg, job, err := h.chat.SendMessage(dbc, threadID, req.Content, idem, string(verbosity), disabledLanes)
 	if err != nil {
+		if wait, ok := llmThrottledWait(err); ok {
+			respondLLMThrottled(c, wait)
+			return
+		}
 		response.RespondError(c, http.StatusBadRequest, "send_message_failed", err)
 		return
 	}
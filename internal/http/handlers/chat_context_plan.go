@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	chatsteps "github.com/yungbote/neurobridge-backend/internal/modules/chat/steps"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// ChatContextPlanHandler exposes the assembled context plan for debugging. It is off unless
+// CHAT_CONTEXT_PLAN_PREVIEW_ENABLED is set and only ever plans against the caller's own threads.
+type ChatContextPlanHandler struct {
+	log      *logger.Logger
+	preview  *chatsteps.ContextPlanPreviewer
+	enabled  bool
+	maxChars int
+}
+
+type ChatContextPlanHandlerDeps struct {
+	Log     *logger.Logger
+	Preview *chatsteps.ContextPlanPreviewer
+}
+
+func NewChatContextPlanHandlerWithDeps(deps ChatContextPlanHandlerDeps) *ChatContextPlanHandler {
+	h := &ChatContextPlanHandler{
+		preview:  deps.Preview,
+		enabled:  envutil.Bool("CHAT_CONTEXT_PLAN_PREVIEW_ENABLED", false),
+		maxChars: 20000,
+	}
+	if deps.Log != nil {
+		h.log = deps.Log.With("handler", "ChatContextPlanHandler")
+	}
+	return h
+}
+
+type contextPlanPreviewReq struct {
+	ThreadID  uuid.UUID `json:"thread_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Text      string    `json:"text"`
+	// DisabledLanes overrides the lanes the message was sent with disabled.
+	DisabledLanes []string `json:"disabled_lanes"`
+}
+
+// POST /api/chat/context-plan/preview
+func (h *ChatContextPlanHandler) Preview(c *gin.Context) {
+	if !h.enabled {
+		response.RespondError(c, http.StatusNotFound, "not_found", nil)
+		return
+	}
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.preview == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "context_plan_preview_unavailable", nil)
+		return
+	}
+	var req contextPlanPreviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+	if req.ThreadID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_thread_id", nil)
+		return
+	}
+	if req.MessageID == uuid.Nil && strings.TrimSpace(req.Text) == "" {
+		response.RespondError(c, http.StatusBadRequest, "missing_message", nil)
+		return
+	}
+	if len(req.Text) > h.maxChars {
+		response.RespondError(c, http.StatusBadRequest, "message_too_large", nil)
+		return
+	}
+
+	var disabledLanes []string
+	if req.DisabledLanes != nil {
+		lanes, ok := chatsteps.ParseContextLanes(req.DisabledLanes)
+		if !ok {
+			response.RespondError(c, http.StatusBadRequest, "invalid_disabled_lanes", nil)
+			return
+		}
+		disabledLanes = lanes
+	}
+
+	out, err := h.preview.Preview(c.Request.Context(), chatsteps.ContextPlanPreviewInput{
+		UserID:        rd.UserID,
+		ThreadID:      req.ThreadID,
+		MessageID:     req.MessageID,
+		Text:          req.Text,
+		DisabledLanes: disabledLanes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, chatsteps.ErrPreviewThreadNotFound):
+			response.RespondError(c, http.StatusNotFound, "thread_not_found", err)
+		case errors.Is(err, chatsteps.ErrPreviewMessageNotFound):
+			response.RespondError(c, http.StatusNotFound, "message_not_found", err)
+		case errors.Is(err, chatsteps.ErrPreviewEmptyText):
+			response.RespondError(c, http.StatusBadRequest, "missing_message", err)
+		default:
+			if h.log != nil {
+				h.log.Warn("context plan preview failed", "thread_id", req.ThreadID.String(), "error", err)
+			}
+			response.RespondError(c, http.StatusInternalServerError, "context_plan_preview_failed", err)
+		}
+		return
+	}
+	response.RespondOK(c, gin.H{"plan": out})
+}
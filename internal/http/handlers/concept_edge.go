@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// GET /api/concept-edges/:id
+//
+// Returns an edge's rationale and cited chunk IDs, as computed by the concept graph build, to
+// answer "why is X a prerequisite of Y?". Both endpoints must be concepts of a path the user owns.
+func (h *PathHandler) GetConceptEdge(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "GetConceptEdge"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	edgeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || edgeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_concept_edge_id", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	edge, err := h.edges.GetByID(dbc, edgeID)
+	if err != nil {
+		h.log.Error("GetConceptEdge failed (load edge)", "error", err, "concept_edge_id", edgeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_edge_failed", err)
+		return
+	}
+	if edge == nil {
+		response.RespondError(c, http.StatusNotFound, "concept_edge_not_found", nil)
+		return
+	}
+
+	concepts, err := h.concepts.GetByIDs(dbc, []uuid.UUID{edge.FromConceptID, edge.ToConceptID})
+	if err != nil {
+		h.log.Error("GetConceptEdge failed (load concepts)", "error", err, "concept_edge_id", edgeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_concepts_failed", err)
+		return
+	}
+	var from, to gin.H
+	var pathID uuid.UUID
+	for _, cc := range concepts {
+		if cc == nil || cc.Scope != "path" || cc.ScopeID == nil || *cc.ScopeID == uuid.Nil {
+			continue
+		}
+		// Edges only ever connect concepts of one path; anything else is not servable.
+		if pathID != uuid.Nil && *cc.ScopeID != pathID {
+			response.RespondError(c, http.StatusNotFound, "concept_edge_not_found", nil)
+			return
+		}
+		pathID = *cc.ScopeID
+		ref := gin.H{"id": cc.ID, "key": cc.Key, "name": cc.Name}
+		if cc.ID == edge.FromConceptID {
+			from = ref
+		}
+		if cc.ID == edge.ToConceptID {
+			to = ref
+		}
+	}
+	if from == nil || to == nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "concept_edge_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("GetConceptEdge failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "concept_edge_not_found", nil)
+		return
+	}
+
+	evidence, err := repos.ParseConceptEdgeEvidence(edge.Evidence)
+	if err != nil {
+		h.log.Warn("GetConceptEdge: edge evidence is not valid JSON", "error", err, "concept_edge_id", edgeID)
+	}
+
+	// Citations into removed files are kept but labelled, so the rationale stays explainable.
+	removedChunkIDs := []uuid.UUID{}
+	if cited := evidence.ChunkIDs(); len(cited) > 0 && h.chunks != nil {
+		chunks, err := h.chunks.GetByIDsIncludingDeleted(dbc, cited)
+		if err != nil {
+			h.log.Error("GetConceptEdge failed (load chunks)", "error", err, "concept_edge_id", edgeID)
+			response.RespondError(c, http.StatusInternalServerError, "load_chunks_failed", err)
+			return
+		}
+		for _, ch := range chunks {
+			if ch != nil && ch.DeletedAt.Valid {
+				removedChunkIDs = append(removedChunkIDs, ch.ID)
+			}
+		}
+	}
+
+	response.RespondOK(c, gin.H{
+		"edge": gin.H{
+			"id":        edge.ID,
+			"path_id":   pathID,
+			"edge_type": edge.EdgeType,
+			"strength":  edge.Strength,
+			"from":      from,
+			"to":        to,
+		},
+		"rationale":          evidence.Rationale,
+		"citation_chunk_ids": evidence.ChunkIDs(),
+		"removed_chunk_ids":  removedChunkIDs,
+		"removed_note":       removedSourceNote,
+	})
+}
@@ -61,3 +61,30 @@ func TestNewEventHandlerWithDeps(t *testing.T) {
 		t.Fatal("expected non-nil handler")
 	}
 }
+
+func TestNewLearningStateHandlerWithDeps(t *testing.T) {
+	h := NewLearningStateHandlerWithDeps(LearningStateHandlerDeps{Log: newTestLogger(t)})
+	if h == nil {
+		t.Fatal("expected non-nil handler")
+	}
+}
+
+func TestNewChatContextPlanHandlerWithDeps(t *testing.T) {
+	h := NewChatContextPlanHandlerWithDeps(ChatContextPlanHandlerDeps{Log: newTestLogger(t)})
+	if h == nil {
+		t.Fatal("expected non-nil handler")
+	}
+	if h.enabled {
+		t.Fatal("context plan preview should be disabled by default")
+	}
+}
+
+func TestNewDocVariantOutcomeHandlerWithDeps(t *testing.T) {
+	h := NewDocVariantOutcomeHandlerWithDeps(DocVariantOutcomeHandlerDeps{})
+	if h == nil {
+		t.Fatal("expected non-nil handler")
+	}
+	if len(h.labels) == 0 {
+		t.Fatal("expected default outcome labels")
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// DiagnosticsHandler exposes process-local operational aggregates. Routes are only mounted
+// when DIAGNOSTICS_ENABLED is set since they reveal query shapes.
+type DiagnosticsHandler struct {
+	queries  *dbstats.Recorder
+	throttle *logger.Throttle
+	flags    *featureflag.Evaluator
+	sched    services.DocGenScheduler
+	audits   repos.DocConsistencyAuditRunRepo
+}
+
+func NewDiagnosticsHandler(queries *dbstats.Recorder, throttle *logger.Throttle, flags *featureflag.Evaluator, sched services.DocGenScheduler, audits repos.DocConsistencyAuditRunRepo) *DiagnosticsHandler {
+	if queries == nil {
+		queries = dbstats.Default()
+	}
+	if throttle == nil {
+		throttle = logger.DefaultThrottle()
+	}
+	if flags == nil {
+		flags = featureflag.Default()
+	}
+	return &DiagnosticsHandler{queries: queries, throttle: throttle, flags: flags, sched: sched, audits: audits}
+}
+
+// QueryStats returns per-operation query aggregates (count, rows, total and p95 latency).
+func (h *DiagnosticsHandler) QueryStats(c *gin.Context) {
+	response.RespondOK(c, h.queries.Snapshot())
+}
+
+// ResetQueryStats clears the aggregates, e.g. before measuring one flow.
+func (h *DiagnosticsHandler) ResetQueryStats(c *gin.Context) {
+	h.queries.Reset()
+	response.RespondOK(c, h.queries.Snapshot())
+}
+
+// LogThrottleStats returns per-key counters of throttled warnings (logged vs suppressed).
+func (h *DiagnosticsHandler) LogThrottleStats(c *gin.Context) {
+	response.RespondOK(c, h.throttle.Snapshot())
+}
+
+// FeatureFlags lists the flags this process has cached and their targeting rules.
+func (h *DiagnosticsHandler) FeatureFlags(c *gin.Context) {
+	response.RespondOK(c, h.flags.List(c.Request.Context()))
+}
+
+// JobScheduler returns the docgen scheduler's slots, per-user in-flight and queue depths, and
+// per-path in-flight counts.
+func (h *DiagnosticsHandler) JobScheduler(c *gin.Context) {
+	if h.sched == nil {
+		response.RespondOK(c, &services.DocGenSchedulerSnapshot{})
+		return
+	}
+	snap, err := h.sched.Snapshot(c.Request.Context())
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_scheduler_snapshot_failed", err)
+		return
+	}
+	response.RespondOK(c, snap)
+}
+
+// ConsistencyAudit returns the latest doc consistency audit run; its report holds the
+// per-checker violation counts, sample IDs and repairs.
+func (h *DiagnosticsHandler) ConsistencyAudit(c *gin.Context) {
+	if h.audits == nil {
+		response.RespondOK(c, gin.H{"run": nil})
+		return
+	}
+	run, err := h.audits.GetLatest(dbctx.Context{Ctx: c.Request.Context()})
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "consistency_audit_load_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"run": run})
+}
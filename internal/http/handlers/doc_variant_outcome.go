@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+)
+
+// DocVariantOutcomeHandler exposes the outcome label enumeration the node doc aggregate accepts,
+// so clients only ever send labels that map to a reward.
+type DocVariantOutcomeHandler struct {
+	labels []domainagg.DocVariantOutcomeLabel
+}
+
+type DocVariantOutcomeHandlerDeps struct {
+	// Labels should be the same list the node doc aggregate validates against.
+	Labels []domainagg.DocVariantOutcomeLabel
+}
+
+func NewDocVariantOutcomeHandlerWithDeps(deps DocVariantOutcomeHandlerDeps) *DocVariantOutcomeHandler {
+	labels := deps.Labels
+	if len(labels) == 0 {
+		labels = domainagg.DefaultDocVariantOutcomeLabels()
+	}
+	return &DocVariantOutcomeHandler{labels: labels}
+}
+
+// GET /api/doc-variant-outcomes/labels
+func (h *DocVariantOutcomeHandler) ListLabels(c *gin.Context) {
+	response.RespondOK(c, gin.H{"labels": h.labels})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+// EnvDocGenMaxInflightJobsPerUser caps a user's queued+running doc generation and patch jobs;
+// 0 disables the cap.
+const EnvDocGenMaxInflightJobsPerUser = "DOCGEN_MAX_INFLIGHT_JOBS_PER_USER"
+
+const defaultDocGenMaxInflightJobsPerUser = 6
+
+// docGenJobTypes are the jobs counted against the per-user cap: every job that spends model
+// calls generating or rewriting node docs.
+var docGenJobTypes = []string{
+	"node_doc_build",
+	"node_doc_prefetch",
+	"node_doc_progressive_build",
+	"node_doc_patch",
+	"node_doc_regenerate",
+	"node_doc_edit",
+	"node_doc_edit_apply",
+	"doc_probe_select",
+}
+
+// docGenJobCap is the outcome of a cap check at enqueue time.
+type docGenJobCap struct {
+	Inflight int64
+	Limit    int
+}
+
+func (c docGenJobCap) Reached() bool {
+	return c.Limit > 0 && c.Inflight >= int64(c.Limit)
+}
+
+// checkDocGenJobCap counts the user's in-flight doc-gen jobs. A failed count is logged and does
+// not block the enqueue: the cap guards spend, it should not take doc generation down with it.
+func (h *PathHandler) checkDocGenJobCap(ctx context.Context, userID uuid.UUID) docGenJobCap {
+	out := docGenJobCap{Limit: envutil.Int(EnvDocGenMaxInflightJobsPerUser, defaultDocGenMaxInflightJobsPerUser)}
+	if out.Limit <= 0 || h == nil || h.jobs == nil || userID == uuid.Nil {
+		return out
+	}
+	n, err := h.jobs.CountRunnableForOwner(dbctx.Context{Ctx: ctx}, userID, docGenJobTypes)
+	if err != nil {
+		if h.log != nil {
+			h.log.WarnThrottled("docgen_job_cap.count", time.Minute, "doc-gen job cap count failed; allowing enqueue", "error", err)
+		}
+		return docGenJobCap{}
+	}
+	out.Inflight = n
+	return out
+}
+
+// respondTooManyJobs writes the 429 for a reached cap; extra fields are merged into the body.
+func respondTooManyJobs(c *gin.Context, jobCap docGenJobCap, extra gin.H) {
+	body := gin.H{
+		"error":             response.APIError{Message: "too_many_jobs", Code: "too_many_jobs"},
+		"inflight_jobs":     jobCap.Inflight,
+		"max_inflight_jobs": jobCap.Limit,
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	c.JSON(http.StatusTooManyRequests, body)
+}
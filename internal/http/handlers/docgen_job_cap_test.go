@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type countingJobRuns struct {
+	repos.JobRunRepo
+	count int64
+	types []string
+}
+
+func (f *countingJobRuns) CountRunnableForOwner(dbc dbctx.Context, ownerUserID uuid.UUID, jobTypes []string) (int64, error) {
+	f.types = jobTypes
+	return f.count, nil
+}
+
+func TestCheckDocGenJobCap(t *testing.T) {
+	jobs := &countingJobRuns{count: 3}
+	h := &PathHandler{jobs: jobs}
+	userID := uuid.New()
+
+	t.Setenv(EnvDocGenMaxInflightJobsPerUser, "3")
+	got := h.checkDocGenJobCap(context.Background(), userID)
+	if !got.Reached() || got.Inflight != 3 || got.Limit != 3 {
+		t.Fatalf("expected cap reached at 3/3, got %+v", got)
+	}
+	if len(jobs.types) != len(docGenJobTypes) {
+		t.Fatalf("expected doc-gen job types counted, got %v", jobs.types)
+	}
+
+	t.Setenv(EnvDocGenMaxInflightJobsPerUser, "4")
+	if got := h.checkDocGenJobCap(context.Background(), userID); got.Reached() {
+		t.Fatalf("expected room under a cap of 4, got %+v", got)
+	}
+
+	t.Setenv(EnvDocGenMaxInflightJobsPerUser, "0")
+	if got := h.checkDocGenJobCap(context.Background(), userID); got.Reached() {
+		t.Fatalf("expected cap disabled at 0, got %+v", got)
+	}
+}
+
+func TestRespondTooManyJobs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	respondTooManyJobs(c, docGenJobCap{Inflight: 7, Limit: 6}, nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+		Inflight int64 `json:"inflight_jobs"`
+		Limit    int   `json:"max_inflight_jobs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Error.Code != "too_many_jobs" || body.Inflight != 7 || body.Limit != 6 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
+	"github.com/yungbote/neurobridge-backend/internal/http/pagination"
 	"github.com/yungbote/neurobridge-backend/internal/http/response"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/services"
@@ -72,3 +77,143 @@ func (h *JobHandler) RestartJob(c *gin.Context) {
 	}
 	response.RespondOK(c, gin.H{"job": job})
 }
+
+type jobHistoryEntity struct {
+	Type string     `json:"type"`
+	ID   *uuid.UUID `json:"id,omitempty"`
+}
+
+type jobHistoryItem struct {
+	ID           uuid.UUID         `json:"id"`
+	JobType      string            `json:"job_type"`
+	Status       string            `json:"status"`
+	Stage        string            `json:"stage"`
+	Progress     int               `json:"progress"`
+	Entity       *jobHistoryEntity `json:"entity,omitempty"`
+	ErrorSummary string            `json:"error_summary,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// jobListSpec: newest first by default; fields selects top-level jobHistoryItem keys.
+var jobListSpec = pagination.Spec{
+	DefaultLimit: repos.JobRunListDefaultLimit,
+	MaxLimit:     repos.JobRunListMaxLimit,
+	Sorts:        []string{"-created_at", "created_at"},
+	Fields:       []string{"id", "job_type", "status", "stage", "progress", "entity", "error_summary", "created_at", "updated_at"},
+}
+
+// GET /api/jobs?cursor=&limit=&sort=&fields=&job_type=&status=&entity_type=&entity_id=
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	params, perr := pagination.ParseListParams(c, jobListSpec)
+	if perr != nil {
+		pagination.RespondParamError(c, perr)
+		return
+	}
+	filter := repos.JobRunListFilter{
+		JobType:    strings.TrimSpace(c.Query("job_type")),
+		Status:     strings.ToLower(strings.TrimSpace(c.Query("status"))),
+		EntityType: strings.TrimSpace(c.Query("entity_type")),
+		Before:     params.After,
+		Ascending:  !params.Desc,
+	}
+	if raw := strings.TrimSpace(c.Query("entity_id")); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil || id == uuid.Nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_entity_id", err)
+			return
+		}
+		filter.EntityID = &id
+		// Without an entity type, also match jobs keyed elsewhere that reference the ID in their payload.
+		filter.IncludePayloadRefs = filter.EntityType == ""
+	}
+	for _, p := range []struct {
+		key string
+		dst **time.Time
+	}{{"created_after", &filter.CreatedAfter}, {"created_before", &filter.CreatedBefore}} {
+		raw := strings.TrimSpace(c.Query(p.key))
+		if raw == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_"+p.key, err)
+			return
+		}
+		*p.dst = &ts
+	}
+	// Fetch one extra row to learn whether another page exists.
+	filter.Limit = params.Limit + 1
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	entries, err := h.jobs.ListForRequestUser(dbc, filter)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "list_jobs_failed", err)
+		return
+	}
+
+	var next *keyset.Cursor
+	if len(entries) > params.Limit {
+		entries = entries[:params.Limit]
+		last := entries[len(entries)-1].Job
+		next = keyset.CursorFor(last.CreatedAt, last.ID)
+	}
+
+	items := make([]jobHistoryItem, 0, len(entries))
+	for _, e := range entries {
+		job := e.Job
+		item := jobHistoryItem{
+			ID:        job.ID,
+			JobType:   job.JobType,
+			Status:    job.Status,
+			Stage:     job.Stage,
+			Progress:  job.Progress,
+			CreatedAt: job.CreatedAt,
+			UpdatedAt: job.UpdatedAt,
+		}
+		if job.EntityType != "" || job.EntityID != nil {
+			item.Entity = &jobHistoryEntity{Type: job.EntityType, ID: job.EntityID}
+		}
+		if job.Status == "failed" {
+			item.ErrorSummary = sanitizeJobError(e.LastError)
+		}
+		items = append(items, item)
+	}
+
+	pagination.RespondList(c, params, pagination.Page{Items: items, Next: next})
+}
+
+const maxJobErrorSummaryRunes = 200
+
+var (
+	jobErrUUIDRe     = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	jobErrHexRe      = regexp.MustCompile(`(?i)\b[0-9a-f]{16,}\b`)
+	jobErrSourceRe   = regexp.MustCompile(`(?:\s*\bat)?\s*[\w./-]+\.go:\d+(?::\d+)?`)
+	jobErrSQLStateRe = regexp.MustCompile(`\s*\(SQLSTATE [0-9A-Z]+\)`)
+	jobErrSpaceRe    = regexp.MustCompile(`\s+`)
+)
+
+// sanitizeJobError reduces a raw failure message to a short user-facing summary:
+// first line only, no stack traces, source locations, or internal identifiers.
+func sanitizeJobError(raw string) string {
+	msg := raw
+	if i := strings.Index(msg, "goroutine "); i >= 0 {
+		msg = msg[:i]
+	}
+	if i := strings.IndexAny(msg, "\r\n"); i >= 0 {
+		msg = msg[:i]
+	}
+	msg = jobErrUUIDRe.ReplaceAllString(msg, "[id]")
+	msg = jobErrHexRe.ReplaceAllString(msg, "[id]")
+	msg = jobErrSourceRe.ReplaceAllString(msg, "")
+	msg = jobErrSQLStateRe.ReplaceAllString(msg, "")
+	msg = strings.TrimSpace(jobErrSpaceRe.ReplaceAllString(msg, " "))
+	msg = strings.TrimRight(msg, " :,;")
+	if msg == "" {
+		return "Job failed"
+	}
+	if r := []rune(msg); len(r) > maxJobErrorSummaryRunes {
+		msg = strings.TrimSpace(string(r[:maxJobErrorSummaryRunes])) + "…"
+	}
+	return msg
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeJobError(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "", want: "Job failed"},
+		{raw: "  ", want: "Job failed"},
+		{raw: "openai: request timed out", want: "openai: request timed out"},
+		{
+			raw:  "load path_node 3f2c6a1e-8b7d-4c2a-9e1f-0a1b2c3d4e5f: record not found",
+			want: "load path_node [id]: record not found",
+		},
+		{
+			raw:  "panic: runtime error: index out of range\ngoroutine 12 [running]:\nmain.run()\n\t/app/internal/steps/node_doc_build.go:214 +0x1f",
+			want: "panic: runtime error: index out of range",
+		},
+		{
+			raw:  "insert failed at steps/node_doc_patch.go:88: duplicate key value (SQLSTATE 23505)",
+			want: "insert failed: duplicate key value",
+		},
+		{raw: "blob deadbeefdeadbeefdeadbeef missing", want: "blob [id] missing"},
+	}
+	for _, tc := range cases {
+		if got := sanitizeJobError(tc.raw); got != tc.want {
+			t.Fatalf("sanitizeJobError(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+
+	long := sanitizeJobError(strings.Repeat("x", 500))
+	if r := []rune(long); len(r) != maxJobErrorSummaryRunes+1 || !strings.HasSuffix(long, "…") {
+		t.Fatalf("expected truncation to %d runes, got %d", maxJobErrorSummaryRunes, len(r))
+	}
+}
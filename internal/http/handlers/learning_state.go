@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/portability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type LearningStateHandler struct {
+	log      *logger.Logger
+	svc      *portability.Service
+	maxBytes int64
+	maxItems int
+}
+
+type LearningStateHandlerDeps struct {
+	Log         *logger.Logger
+	Portability *portability.Service
+}
+
+func NewLearningStateHandlerWithDeps(deps LearningStateHandlerDeps) *LearningStateHandler {
+	maxBytes := int64(envutil.Int("LEARNING_STATE_IMPORT_MAX_BYTES", 16<<20))
+	if maxBytes <= 0 {
+		maxBytes = 16 << 20
+	}
+	maxItems := envutil.Int("LEARNING_STATE_IMPORT_MAX_ITEMS", 50000)
+	if maxItems <= 0 {
+		maxItems = 50000
+	}
+	h := &LearningStateHandler{svc: deps.Portability, maxBytes: maxBytes, maxItems: maxItems}
+	if deps.Log != nil {
+		h.log = deps.Log.With("handler", "LearningStateHandler")
+	}
+	return h
+}
+
+// GET /api/export/learning-state
+func (h *LearningStateHandler) Export(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.svc == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "learning_state_unavailable", nil)
+		return
+	}
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="learning-state.json"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if err := h.svc.Export(c.Request.Context(), rd.UserID, c.Writer, c.Writer.Flush); err != nil {
+		if !c.Writer.Written() {
+			response.RespondError(c, http.StatusInternalServerError, "learning_state_export_failed", err)
+			return
+		}
+		// Headers are out; the truncated body is the only signal the client gets.
+		if h.log != nil {
+			h.log.Warn("learning state export aborted mid-stream", "user_id", rd.UserID.String(), "error", err)
+		}
+		_ = c.Error(err)
+	}
+}
+
+// POST /api/import/learning-state?dry_run=true
+func (h *LearningStateHandler) Import(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.svc == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "learning_state_unavailable", nil)
+		return
+	}
+	if c.Request.ContentLength > h.maxBytes {
+		response.RespondError(c, http.StatusRequestEntityTooLarge, "payload_too_large", nil)
+		return
+	}
+	dryRun := false
+	if raw := strings.TrimSpace(c.Query("dry_run")); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_dry_run", err)
+			return
+		}
+		dryRun = v
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes)
+	report, err := h.svc.Import(c.Request.Context(), rd.UserID, body, h.maxItems, portability.ImportOptions{DryRun: dryRun})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge), errors.Is(err, portability.ErrTooManyItems):
+			response.RespondError(c, http.StatusRequestEntityTooLarge, "payload_too_large", err)
+		case errors.Is(err, portability.ErrSchemaVersion):
+			response.RespondError(c, http.StatusBadRequest, "unsupported_schema_version", err)
+		case errors.Is(err, portability.ErrMalformed):
+			response.RespondError(c, http.StatusBadRequest, "invalid_body", err)
+		default:
+			response.RespondError(c, http.StatusInternalServerError, "learning_state_import_failed", err)
+		}
+		return
+	}
+	response.RespondOK(c, gin.H{"report": report})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/pagination"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type listJobService struct {
+	services.JobService
+	entries []services.JobHistoryEntry
+}
+
+func (s listJobService) ListForRequestUser(dbc dbctx.Context, filter repos.JobRunListFilter) ([]services.JobHistoryEntry, error) {
+	if len(s.entries) > filter.Limit {
+		return s.entries[:filter.Limit], nil
+	}
+	return s.entries, nil
+}
+
+type listNotificationService struct {
+	services.NotificationService
+	page services.NotificationPage
+}
+
+func (s listNotificationService) List(dbc dbctx.Context, userID uuid.UUID, before *repos.UserNotificationCursor, limit int, unreadOnly bool) (services.NotificationPage, error) {
+	return s.page, nil
+}
+
+type listRevisionRepo struct {
+	repos.LearningNodeDocRevisionRepo
+	rows []*types.LearningNodeDocRevision
+}
+
+func (r listRevisionRepo) ListPageByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID, track string, after *keyset.Cursor, ascending bool, limit int) ([]*types.LearningNodeDocRevision, error) {
+	if len(r.rows) > limit {
+		return r.rows[:limit], nil
+	}
+	return r.rows, nil
+}
+
+// Every list endpoint must reject bad parameters with the same codes and answer in the same
+// envelope.
+func TestListEndpointsShareConventions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	userID := uuid.New()
+	now := time.Now().UTC()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+
+	var jobs []services.JobHistoryEntry
+	var revisions []*types.LearningNodeDocRevision
+	for i := 0; i < 3; i++ {
+		at := now.Add(-time.Duration(i) * time.Minute)
+		jobs = append(jobs, services.JobHistoryEntry{Job: &types.JobRun{ID: uuid.New(), JobType: "node_doc_patch", Status: "succeeded", CreatedAt: at}})
+		revisions = append(revisions, &types.LearningNodeDocRevision{ID: uuid.New(), PathNodeID: node.ID, Operation: "rewrite", CreatedAt: at})
+	}
+	notes := services.NotificationPage{
+		Notifications: []*types.UserNotification{{ID: uuid.New(), UserID: userID, Kind: "job_done", Title: "Done", CreatedAt: now}},
+		Unread:        1,
+	}
+
+	ph := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:     log,
+		Path:    PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}},
+		Content: PathHandlerContentRepos{DocRevisions: listRevisionRepo{rows: revisions}},
+	})
+	endpoints := map[string]gin.HandlerFunc{
+		"jobs":          NewJobHandler(listJobService{entries: jobs}).ListJobs,
+		"notifications": NewNotificationHandler(listNotificationService{page: notes}).ListNotifications,
+		"revisions":     ph.ListPathNodeDocRevisions,
+	}
+	call := func(fn gin.HandlerFunc, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/list?"+query, nil)
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		fn(c)
+		return w
+	}
+
+	bad := map[string]string{
+		"cursor=bogus!":  pagination.CodeInvalidCursor,
+		"limit=1000":     pagination.CodeInvalidLimit,
+		"limit=-1":       pagination.CodeInvalidLimit,
+		"sort=bogus":     pagination.CodeInvalidSort,
+		"fields=bogus":   pagination.CodeInvalidFields,
+		"fields=id,nope": pagination.CodeInvalidFields,
+	}
+	for name, fn := range endpoints {
+		for query, code := range bad {
+			w := call(fn, query)
+			var body struct {
+				Error struct {
+					Code string `json:"code"`
+				} `json:"error"`
+			}
+			_ = json.Unmarshal(w.Body.Bytes(), &body)
+			if w.Code != http.StatusBadRequest || body.Error.Code != code {
+				t.Fatalf("%s?%s: status %d, want 400 %s: %s", name, query, w.Code, code, w.Body.String())
+			}
+		}
+
+		w := call(fn, "limit=1&fields=id,created_at")
+		var page struct {
+			Items      []map[string]any `json:"items"`
+			NextCursor string           `json:"next_cursor"`
+			HasMore    bool             `json:"has_more"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", name, w.Code, w.Body.String())
+		}
+		if len(page.Items) != 1 || len(page.Items[0]) != 2 || page.Items[0]["id"] == nil {
+			t.Fatalf("%s: fieldset not applied: %s", name, w.Body.String())
+		}
+		if name != "notifications" && (!page.HasMore || page.NextCursor == "") {
+			t.Fatalf("%s: expected another page: %s", name, w.Body.String())
+		}
+	}
+}
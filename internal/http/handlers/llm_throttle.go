@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// llmThrottleWait is the estimated time until the LLM provider accepts calls again (0 when it
+// isn't throttling us). A var so tests can simulate a throttled provider.
+var llmThrottleWait = func() time.Duration { return openai.ProviderThrottle().Wait() }
+
+// llmThrottledWait returns the wait carried by a provider-throttled error.
+func llmThrottledWait(err error) (time.Duration, bool) {
+	te, ok := openai.AsThrottled(err)
+	if !ok {
+		return 0, false
+	}
+	if wait := llmThrottleWait(); wait > te.RetryAfter {
+		return wait, true
+	}
+	return te.RetryAfter, true
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds for the Retry-After header (at least 1).
+func retryAfterSeconds(wait time.Duration) int {
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// respondLLMThrottled writes 503 llm_throttled with a Retry-After derived from the throttle state.
+func respondLLMThrottled(c *gin.Context, wait time.Duration) {
+	secs := retryAfterSeconds(wait)
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":               response.APIError{Message: "llm provider is throttled; retry later", Code: "llm_throttled"},
+		"retry_after_seconds": secs,
+	})
+}
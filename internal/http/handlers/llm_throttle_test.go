@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// simulateLLMThrottle makes the handlers see a provider throttled for wait.
+func simulateLLMThrottle(t *testing.T, wait time.Duration) {
+	t.Helper()
+	prev := llmThrottleWait
+	llmThrottleWait = func() time.Duration { return wait }
+	t.Cleanup(func() { llmThrottleWait = prev })
+}
+
+func TestSendMessageThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	simulateLLMThrottle(t, 2500*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodPost, "/api/chat/threads/x/messages", strings.NewReader(`{"content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+
+	// A nil chat service would panic if the throttled send got past the check.
+	(&ChatHandler{}).SendMessage(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Fatalf("Retry-After = %q, want 3", got)
+	}
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+		RetryAfterSeconds int `json:"retry_after_seconds"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error.Code != "llm_throttled" || body.RetryAfterSeconds != 3 {
+		t.Fatalf("body = %s (%v)", w.Body.String(), err)
+	}
+}
+
+type deferringJobService struct {
+	services.JobService
+	deferred  bool
+	notBefore time.Time
+	err       error
+}
+
+func (s *deferringJobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &types.JobRun{ID: uuid.New(), JobType: jobType, Status: "queued"}, nil
+}
+
+func (s *deferringJobService) EnqueueDeferred(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any, notBefore time.Time) (*types.JobRun, error) {
+	s.deferred, s.notBefore = true, notBefore
+	return &types.JobRun{ID: uuid.New(), JobType: jobType, Status: "queued", NotBefore: &notBefore}, nil
+}
+
+func TestEnqueuePathNodeDocPatchThrottled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+	doc := &types.LearningNodeDoc{
+		ID:         uuid.New(),
+		PathID:     path.ID,
+		PathNodeID: node.ID,
+		DocJSON:    datatypes.JSON(`{"schema_version":1,"title":"Loops","blocks":[{"id":"p1","type":"paragraph","md":"Loops repeat work."}]}`),
+		Version:    1,
+	}
+	jobs := &deferringJobService{}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:     log,
+		Path:    PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}},
+		Content: PathHandlerContentRepos{NodeDocs: &fakeNodeDocRepo{doc: doc}},
+		Services: PathHandlerServices{
+			JobSvc:   jobs,
+			Learning: learningmod.New(learningmod.UsecasesDeps{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}, NodeDocs: &fakeNodeDocRepo{doc: doc}}),
+		},
+	})
+	patch := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodPost, "/api/path-nodes/x/doc/patch", strings.NewReader(`{"block_id":"p1","instruction":"shorter"}`))
+		req.Header.Set("Content-Type", "application/json")
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		h.EnqueuePathNodeDocPatch(c)
+		return w
+	}
+
+	// Throttled provider: the patch is accepted but deferred past the expected wait.
+	simulateLLMThrottle(t, 30*time.Second)
+	before := time.Now()
+	w := patch()
+	if w.Code != http.StatusOK || !jobs.deferred {
+		t.Fatalf("expected deferred enqueue, got %d deferred=%v: %s", w.Code, jobs.deferred, w.Body.String())
+	}
+	if jobs.notBefore.Before(before.Add(30*time.Second)) || jobs.notBefore.After(time.Now().Add(30*time.Second)) {
+		t.Fatalf("not_before %s not ~30s out", jobs.notBefore)
+	}
+	var body struct {
+		Deferred  bool       `json:"deferred"`
+		NotBefore *time.Time `json:"not_before"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || !body.Deferred || body.NotBefore == nil {
+		t.Fatalf("body = %s (%v)", w.Body.String(), err)
+	}
+
+	// Not throttled: a plain enqueue.
+	simulateLLMThrottle(t, 0)
+	jobs.deferred = false
+	if w = patch(); w.Code != http.StatusOK || jobs.deferred || strings.Contains(w.Body.String(), "deferred") {
+		t.Fatalf("expected plain enqueue, got %d deferred=%v: %s", w.Code, jobs.deferred, w.Body.String())
+	}
+
+	// A throttled error surfacing from the enqueue becomes a 503.
+	jobs.err = &openai.ThrottledError{StatusCode: http.StatusTooManyRequests, RetryAfter: 4 * time.Second, Err: errors.New("openai http 429")}
+	if w = patch(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "4" || !strings.Contains(w.Body.String(), "llm_throttled") {
+		t.Fatalf("expected 503 llm_throttled, got %d %q: %s", w.Code, w.Header().Get("Retry-After"), w.Body.String())
+	}
+}
@@ -40,15 +40,18 @@ type MaterialHandler struct {
 	log      *logger.Logger
 	workflow services.WorkflowService
 	sseHub   *realtime.SSEHub
+	jobSvc   services.JobService
 
 	bucket           gcp.BucketService
 	materialFiles    repos.MaterialFileRepo
+	chunks           repos.MaterialChunkRepo
 	materialAssets   repos.MaterialAssetRepo
 	userLibraryIndex repos.UserLibraryIndexRepo
 }
 
 type MaterialHandlerRepoDeps struct {
 	MaterialFiles    repos.MaterialFileRepo
+	Chunks           repos.MaterialChunkRepo
 	MaterialAssets   repos.MaterialAssetRepo
 	UserLibraryIndex repos.UserLibraryIndexRepo
 }
@@ -57,6 +60,7 @@ type MaterialHandlerDeps struct {
 	Log      *logger.Logger
 	Workflow services.WorkflowService
 	SSEHub   *realtime.SSEHub
+	JobSvc   services.JobService
 	Bucket   gcp.BucketService
 	Repos    MaterialHandlerRepoDeps
 }
@@ -66,8 +70,10 @@ func NewMaterialHandlerWithDeps(deps MaterialHandlerDeps) *MaterialHandler {
 		log:              deps.Log.With("handler", "MaterialHandler"),
 		workflow:         deps.Workflow,
 		sseHub:           deps.SSEHub,
+		jobSvc:           deps.JobSvc,
 		bucket:           deps.Bucket,
 		materialFiles:    deps.Repos.MaterialFiles,
+		chunks:           deps.Repos.Chunks,
 		materialAssets:   deps.Repos.MaterialAssets,
 		userLibraryIndex: deps.Repos.UserLibraryIndex,
 	}
@@ -194,6 +200,7 @@ func (h *MaterialHandler) UploadMaterials(c *gin.Context) {
 
 // GET /api/material-files
 func (h *MaterialHandler) ListUserMaterialFiles(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "ListUserMaterialFiles"))
 	rd := ctxutil.GetRequestData(c.Request.Context())
 	if rd == nil || rd.UserID == uuid.Nil {
 		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
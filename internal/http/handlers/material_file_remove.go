@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// removedSourceNote labels citations whose material file the user removed.
+const removedSourceNote = "file removed by user"
+
+// DELETE /api/material-files/:id
+//
+// Soft-deletes the file and its chunks, then enqueues material_file_cleanup to drop vectors and
+// chat projections, flag concept evidence as source_removed and mark citing docs sources_stale.
+// Deleting an already-removed file re-enqueues the cleanup.
+func (h *MaterialHandler) DeleteMaterialFile(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.materialFiles == nil || h.chunks == nil || h.userLibraryIndex == nil || h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "material_repo_missing", nil)
+		return
+	}
+
+	fileID, err := uuid.Parse(c.Param("id"))
+	if err != nil || fileID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_material_file_id", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	file, ok := h.loadOwnedMaterialFile(c, rd.UserID, fileID, true)
+	if !ok {
+		return
+	}
+
+	if !file.DeletedAt.Valid {
+		if err := h.chunks.SoftDeleteByMaterialFileIDs(dbc, []uuid.UUID{file.ID}); err != nil {
+			h.log.Error("DeleteMaterialFile failed (delete chunks)", "error", err, "material_file_id", file.ID)
+			response.RespondError(c, http.StatusInternalServerError, "delete_chunks_failed", err)
+			return
+		}
+		if err := h.materialFiles.SoftDeleteByIDs(dbc, []uuid.UUID{file.ID}); err != nil {
+			h.log.Error("DeleteMaterialFile failed (delete file)", "error", err, "material_file_id", file.ID)
+			response.RespondError(c, http.StatusInternalServerError, "delete_file_failed", err)
+			return
+		}
+	}
+
+	entityID := file.ID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "material_file_cleanup", "material_file", &entityID, map[string]any{
+		"material_file_id": file.ID.String(),
+	})
+	if err != nil {
+		h.log.Error("DeleteMaterialFile failed (enqueue)", "error", err, "material_file_id", file.ID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"material_file_id": file.ID, "job_id": job.ID})
+}
+
+type relinkMaterialFileRequest struct {
+	RemovedFileID string `json:"removed_file_id"`
+	// DryRun only reports how many chunks would relink.
+	DryRun bool `json:"dry_run"`
+}
+
+// POST /api/material-files/:id/relink
+//
+// Offers relinking a removed file's citations onto the re-uploaded file :id. Chunks match on
+// text hash; a dry run reports the overlap, otherwise material_file_relink is enqueued.
+func (h *MaterialHandler) RelinkMaterialFile(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.materialFiles == nil || h.chunks == nil || h.userLibraryIndex == nil || h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "material_repo_missing", nil)
+		return
+	}
+
+	fileID, err := uuid.Parse(c.Param("id"))
+	if err != nil || fileID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_material_file_id", err)
+		return
+	}
+	var req relinkMaterialFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.RespondError(c, http.StatusBadRequest, "invalid_body", err)
+		return
+	}
+	removedID, err := uuid.Parse(req.RemovedFileID)
+	if err != nil || removedID == uuid.Nil || removedID == fileID {
+		response.RespondError(c, http.StatusBadRequest, "invalid_removed_file_id", err)
+		return
+	}
+
+	file, ok := h.loadOwnedMaterialFile(c, rd.UserID, fileID, false)
+	if !ok {
+		return
+	}
+	removed, ok := h.loadOwnedMaterialFile(c, rd.UserID, removedID, true)
+	if !ok {
+		return
+	}
+	if !removed.DeletedAt.Valid {
+		response.RespondError(c, http.StatusConflict, "material_file_not_removed", nil)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	if req.DryRun {
+		removedChunks, err := h.chunks.GetByMaterialFileIDsIncludingDeleted(dbc, []uuid.UUID{removed.ID})
+		if err != nil {
+			response.RespondError(c, http.StatusInternalServerError, "load_chunks_failed", err)
+			return
+		}
+		matched, err := h.chunks.MatchRemovedChunks(dbc, removed.ID, file.ID)
+		if err != nil {
+			h.log.Error("RelinkMaterialFile failed (match)", "error", err, "material_file_id", file.ID)
+			response.RespondError(c, http.StatusInternalServerError, "match_chunks_failed", err)
+			return
+		}
+		response.RespondOK(c, gin.H{"matched_chunks": len(matched), "removed_chunks": len(removedChunks)})
+		return
+	}
+
+	entityID := file.ID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "material_file_relink", "material_file", &entityID, map[string]any{
+		"material_file_id": file.ID.String(),
+		"removed_file_id":  removed.ID.String(),
+	})
+	if err != nil {
+		h.log.Error("RelinkMaterialFile failed (enqueue)", "error", err, "material_file_id", file.ID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"job_id": job.ID})
+}
+
+// loadOwnedMaterialFile loads a file the user owns through its material set, answering 404
+// otherwise. Removed files are only returned when includeRemoved is set.
+func (h *MaterialHandler) loadOwnedMaterialFile(c *gin.Context, userID, fileID uuid.UUID, includeRemoved bool) (*types.MaterialFile, bool) {
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	load := h.materialFiles.GetByIDs
+	if includeRemoved {
+		load = h.materialFiles.GetByIDsIncludingDeleted
+	}
+	files, err := load(dbc, []uuid.UUID{fileID})
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_files_failed", err)
+		return nil, false
+	}
+	if len(files) == 0 || files[0] == nil {
+		response.RespondError(c, http.StatusNotFound, "material_not_found", nil)
+		return nil, false
+	}
+	idx, err := h.userLibraryIndex.GetByUserAndMaterialSet(dbc, userID, files[0].MaterialSetID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_library_index_failed", err)
+		return nil, false
+	}
+	if idx == nil {
+		response.RespondError(c, http.StatusNotFound, "material_not_found", nil)
+		return nil, false
+	}
+	return files[0], true
+}
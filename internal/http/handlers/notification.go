@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/http/pagination"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type NotificationHandler struct {
+	notes services.NotificationService
+}
+
+func NewNotificationHandler(notes services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notes: notes}
+}
+
+var notificationListSpec = pagination.Spec{
+	DefaultLimit: repos.UserNotificationListDefaultLimit,
+	MaxLimit:     repos.UserNotificationListMaxLimit,
+	Sorts:        []string{"-created_at"},
+	Fields:       []string{"id", "user_id", "kind", "title", "body", "link_type", "link_id", "metadata", "created_at", "read_at"},
+}
+
+// GET /api/notifications?cursor=&limit=&fields=&unread=true
+//
+// Newest first. New notifications are also pushed on the SSE stream as NotificationCreated.
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	params, perr := pagination.ParseListParams(c, notificationListSpec)
+	if perr != nil {
+		pagination.RespondParamError(c, perr)
+		return
+	}
+	unreadOnly := strings.EqualFold(strings.TrimSpace(c.Query("unread")), "true")
+
+	page, err := h.notes.List(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, params.After, params.Limit, unreadOnly)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "list_notifications_failed", err)
+		return
+	}
+	pagination.RespondList(c, params, pagination.Page{
+		Items: page.Notifications,
+		Next:  page.NextCursor,
+		Extra: gin.H{"unread": page.Unread},
+	})
+}
+
+// POST /api/notifications/:id/read
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil || id == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_notification_id", err)
+		return
+	}
+	found, err := h.notes.MarkRead(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, id)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "mark_read_failed", err)
+		return
+	}
+	if !found {
+		response.RespondError(c, http.StatusNotFound, "notification_not_found", nil)
+		return
+	}
+	response.RespondOK(c, gin.H{"ok": true})
+}
+
+// POST /api/notifications/read-all
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	n, err := h.notes.MarkAllRead(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "mark_read_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"ok": true, "marked": n})
+}
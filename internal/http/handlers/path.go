@@ -454,19 +454,25 @@ func (h *PathHandler) ListPathNodes(c *gin.Context) {
 		return
 	}
 
-	nodes, err := h.pathNodes.GetByPathIDs(dbctx.Context{Ctx: c.Request.Context()}, []uuid.UUID{pathID})
+	outline, err := h.outlines.Outline(dbctx.Context{Ctx: c.Request.Context()}, pathID)
 	if err != nil {
 		h.log.Error("ListPathNodes failed (load nodes)", "error", err, "path_id", pathID)
 		response.RespondError(c, http.StatusInternalServerError, "load_nodes_failed", err)
 		return
 	}
-	for _, n := range nodes {
-		normalizePathNodeAvatarURLs(h.bucket, n)
+	// Outline nodes are shared with other readers; normalize copies.
+	nodes := make([]*types.PathNode, 0)
+	for _, n := range outline.OrderedNodes() {
+		cp := *n
+		normalizePathNodeAvatarURLs(h.bucket, &cp)
+		nodes = append(nodes, &cp)
 	}
 	response.RespondOK(c, gin.H{"nodes": nodes})
 }
 
-// GET /api/paths/:id/concept-graph
+// GET /api/paths/:id/concept-graph[?since=<RFC3339 timestamp>]
+//
+// With since, only the delta is returned (see respondConceptGraphDelta).
 func (h *PathHandler) GetConceptGraph(c *gin.Context) {
 	rd := ctxutil.GetRequestData(c.Request.Context())
 	if rd == nil || rd.UserID == uuid.Nil {
@@ -491,6 +497,16 @@ func (h *PathHandler) GetConceptGraph(c *gin.Context) {
 		return
 	}
 
+	if raw := strings.TrimSpace(c.Query("since")); raw != "" {
+		since, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_since", err)
+			return
+		}
+		h.respondConceptGraphDelta(c, pathID, since)
+		return
+	}
+
 	concepts, err := h.concepts.GetByScope(dbctx.Context{Ctx: c.Request.Context()}, "path", &pathID)
 	if err != nil {
 		h.log.Error("GetConceptGraph failed (load concepts)", "error", err, "path_id", pathID)
@@ -533,3 +549,67 @@ func (h *PathHandler) GetConceptGraph(c *gin.Context) {
 
 	response.RespondOK(c, gin.H{"concepts": concepts, "edges": filtered})
 }
+
+type conceptGraphTombstone struct {
+	ID        uuid.UUID `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// respondConceptGraphDelta answers a concept-graph sync: concepts and edges created or updated
+// after since, plus tombstones for those soft-deleted after it. Clients pass the returned as_of
+// as the next since. Hard-deleted rows leave no tombstone; a client that sees unknown IDs
+// should refetch the full graph.
+func (h *PathHandler) respondConceptGraphDelta(c *gin.Context, pathID uuid.UUID, since time.Time) {
+	// Taken before reading so writes racing the queries are seen again next sync.
+	asOf := time.Now().UTC()
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+
+	concepts, err := h.concepts.ListByScopeChangedSince(dbc, "path", &pathID, since)
+	if err != nil {
+		h.log.Error("GetConceptGraph failed (load concept delta)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_concepts_failed", err)
+		return
+	}
+	edges, err := h.edges.ListByConceptScopeChangedSince(dbc, "path", &pathID, since)
+	if err != nil {
+		h.log.Error("GetConceptGraph failed (load edge delta)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_edges_failed", err)
+		return
+	}
+
+	changedConcepts := make([]*types.Concept, 0, len(concepts))
+	deletedConcepts := make([]conceptGraphTombstone, 0)
+	for _, cc := range concepts {
+		if cc == nil {
+			continue
+		}
+		if cc.DeletedAt.Valid {
+			deletedConcepts = append(deletedConcepts, conceptGraphTombstone{ID: cc.ID, DeletedAt: cc.DeletedAt.Time.UTC()})
+			continue
+		}
+		changedConcepts = append(changedConcepts, cc)
+	}
+	changedEdges := make([]*types.ConceptEdge, 0, len(edges))
+	deletedEdges := make([]conceptGraphTombstone, 0)
+	for _, e := range edges {
+		if e == nil {
+			continue
+		}
+		if e.DeletedAt.Valid {
+			deletedEdges = append(deletedEdges, conceptGraphTombstone{ID: e.ID, DeletedAt: e.DeletedAt.Time.UTC()})
+			continue
+		}
+		changedEdges = append(changedEdges, e)
+	}
+
+	response.RespondOK(c, gin.H{
+		"since":    since.UTC(),
+		"as_of":    asOf,
+		"concepts": changedConcepts,
+		"edges":    changedEdges,
+		"tombstones": gin.H{
+			"concepts": deletedConcepts,
+			"edges":    deletedEdges,
+		},
+	})
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type pathActivityNodeRef struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title,omitempty"`
+	Index int       `json:"index"`
+}
+
+type pathActivityEvent struct {
+	ID         string               `json:"id"`
+	Kind       string               `json:"kind"`
+	OccurredAt time.Time            `json:"occurred_at"`
+	Node       *pathActivityNodeRef `json:"node,omitempty"`
+	Summary    string               `json:"summary"`
+	Link       string               `json:"link,omitempty"`
+	Status     string               `json:"status,omitempty"`
+	Count      int                  `json:"count,omitempty"`
+
+	// Debug-only internals.
+	PolicyVersion string `json:"policy_version,omitempty"`
+	TraceID       string `json:"trace_id,omitempty"`
+}
+
+// GET /api/paths/:id/activity
+func (h *PathHandler) ListPathActivity(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.pathActivity == nil {
+		response.RespondError(c, http.StatusInternalServerError, "activity_repo_missing", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	kinds, err := parsePathActivityKinds(c.QueryArray("kinds"))
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_kinds", err)
+		return
+	}
+	before, err := decodePathActivityCursor(c.Query("cursor"))
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_cursor", err)
+		return
+	}
+	limit := repolearning.PathActivityDefaultLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if limit > repolearning.PathActivityMaxLimit {
+		limit = repolearning.PathActivityMaxLimit
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	pathRow, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("ListPathActivity failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+	// Only the owner reaches this point; internals still require an explicit opt-in.
+	debug := queryBool(c.Query("debug"))
+
+	// Fetch one extra row to learn whether another page exists.
+	rows, err := h.pathActivity.List(dbc, repolearning.PathActivityQuery{
+		UserID:        rd.UserID,
+		PathID:        pathID,
+		MaterialSetID: pathRow.MaterialSetID,
+		JobID:         pathRow.JobID,
+		Kinds:         kinds,
+		Before:        before,
+		Limit:         limit + 1,
+	})
+	if err != nil {
+		h.log.Error("ListPathActivity failed (load activity)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_activity_failed", err)
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodePathActivityCursor(repolearning.PathActivityCursor{
+			OccurredAt: last.OccurredAt,
+			Kind:       last.Kind,
+			SourceID:   last.SourceID,
+		})
+	}
+
+	events := make([]pathActivityEvent, 0, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			events = append(events, buildPathActivityEvent(row, debug))
+		}
+	}
+
+	response.RespondOK(c, gin.H{"events": events, "next_cursor": nextCursor})
+}
+
+func queryBool(raw string) bool {
+	v := strings.ToLower(strings.TrimSpace(raw))
+	return v == "1" || v == "true" || v == "yes" || v == "y"
+}
+
+// parsePathActivityKinds accepts both repeated (?kinds=a&kinds=b) and comma-separated values.
+func parsePathActivityKinds(raw []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, k := range repolearning.PathActivityKinds {
+		known[k] = true
+	}
+	out := make([]string, 0, len(raw))
+	seen := map[string]bool{}
+	for _, v := range raw {
+		for _, k := range strings.Split(v, ",") {
+			k = strings.ToLower(strings.TrimSpace(k))
+			if k == "" || seen[k] {
+				continue
+			}
+			if !known[k] {
+				return nil, fmt.Errorf("unknown kind %q", k)
+			}
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func encodePathActivityCursor(cur repolearning.PathActivityCursor) string {
+	raw := cur.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + cur.Kind + "|" + cur.SourceID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePathActivityCursor(s string) (*repolearning.PathActivityCursor, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(string(b), "|", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	return &repolearning.PathActivityCursor{OccurredAt: ts, Kind: parts[1], SourceID: parts[2]}, nil
+}
+
+func buildPathActivityEvent(row *repolearning.PathActivityRow, debug bool) pathActivityEvent {
+	ev := pathActivityEvent{
+		ID:         row.Kind + ":" + row.SourceID,
+		Kind:       row.Kind,
+		OccurredAt: row.OccurredAt.UTC(),
+		Summary:    pathActivitySummary(row),
+		Link:       pathActivityLink(row),
+		Status:     strings.TrimSpace(row.Status),
+	}
+	if row.ItemCount > 1 {
+		ev.Count = row.ItemCount
+	}
+	if row.PathNodeID != nil && *row.PathNodeID != uuid.Nil {
+		ev.Node = &pathActivityNodeRef{ID: *row.PathNodeID, Title: row.NodeTitle, Index: row.NodeIndex}
+	}
+	if debug {
+		ev.PolicyVersion = row.PolicyVersion
+		ev.TraceID = row.TraceID
+	}
+	return ev
+}
+
+func pathActivitySummary(row *repolearning.PathActivityRow) string {
+	action := strings.ToLower(strings.TrimSpace(row.Action))
+	detail := strings.TrimSpace(row.Detail)
+	status := strings.ToLower(strings.TrimSpace(row.Status))
+
+	switch row.Kind {
+	case repolearning.PathActivityKindDocRevision:
+		block := "a block"
+		if detail != "" {
+			block = "a " + humanizeActivityToken(detail) + " block"
+		}
+		var s string
+		switch action {
+		case "rewrite":
+			s = "Rewrote " + block
+		case "regen_media":
+			s = "Regenerated media for " + block
+		case "restore", "revert":
+			s = "Restored " + block
+		default:
+			s = capitalizeActivity(humanizeActivityToken(action)) + " " + block
+		}
+		if status == "failed" {
+			s += " (failed)"
+		}
+		return s
+
+	case repolearning.PathActivityKindVariantExposure:
+		var s string
+		switch action {
+		case "served":
+			s = "Served a personalized version of the doc"
+		case "holdback":
+			s = "Showed the base doc (holdback)"
+		case "rollback":
+			s = "Rolled back to the base doc"
+		case "shadow":
+			s = "Evaluated a personalized version in shadow mode"
+		default:
+			s = "Served the base doc"
+		}
+		if row.ItemCount > 1 {
+			s += fmt.Sprintf(" (%d times)", row.ItemCount)
+		}
+		return s
+
+	case repolearning.PathActivityKindPrereqGate:
+		var s string
+		switch action {
+		case "allow":
+			s = "Prerequisites met"
+		case "blocked":
+			s = "Blocked until prerequisites are reviewed"
+		case "soft_remediate":
+			s = "Suggested reviewing prerequisites first"
+		default:
+			s = "Prerequisite check: " + humanizeActivityToken(action)
+		}
+		if detail != "" && action != "allow" {
+			s += " (" + humanizeActivityToken(detail) + ")"
+		}
+		return s
+
+	case repolearning.PathActivityKindDocGeneration:
+		artifact := humanizeActivityToken(action)
+		if artifact == "" {
+			artifact = "content"
+		}
+		var s string
+		switch status {
+		case "succeeded", "success", "ok":
+			s = "Generated " + artifact
+		case "failed", "error":
+			s = capitalizeActivity(artifact) + " generation failed"
+		default:
+			s = capitalizeActivity(artifact) + " generation " + humanizeActivityToken(status)
+		}
+		if row.ItemCount > 1 {
+			s += fmt.Sprintf(" after %d attempts", row.ItemCount)
+		}
+		return s
+
+	case repolearning.PathActivityKindJobRun:
+		job := capitalizeActivity(humanizeActivityToken(action))
+		if job == "" {
+			job = "Job"
+		}
+		switch status {
+		case "succeeded":
+			return job + " completed"
+		case "running":
+			if detail != "" {
+				return job + " running (" + humanizeActivityToken(detail) + ")"
+			}
+			return job + " running"
+		case "":
+			return job
+		default:
+			return job + " " + humanizeActivityToken(status)
+		}
+	}
+	return capitalizeActivity(humanizeActivityToken(row.Kind))
+}
+
+func pathActivityLink(row *repolearning.PathActivityRow) string {
+	if row.Kind == repolearning.PathActivityKindJobRun {
+		return "/api/jobs/" + row.SourceID
+	}
+	if row.PathNodeID == nil || *row.PathNodeID == uuid.Nil {
+		return ""
+	}
+	base := "/api/path-nodes/" + row.PathNodeID.String()
+	switch {
+	case row.Kind == repolearning.PathActivityKindDocRevision:
+		return base + "/doc/revisions"
+	case row.Kind == repolearning.PathActivityKindDocGeneration && strings.EqualFold(strings.TrimSpace(row.Action), "drill"):
+		return base + "/drills"
+	default:
+		return base + "/doc"
+	}
+}
+
+func humanizeActivityToken(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(s, "_", " "), "-", " "))
+}
+
+func capitalizeActivity(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
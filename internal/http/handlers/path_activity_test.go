@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// keysetActivityRepo pages a fixed, newest-first feed and clamps the limit like
+// pathActivityRepo.List does.
+type keysetActivityRepo struct {
+	rows []*repolearning.PathActivityRow
+}
+
+func (r *keysetActivityRepo) List(dbc dbctx.Context, q repolearning.PathActivityQuery) ([]*repolearning.PathActivityRow, error) {
+	if q.Limit > repolearning.PathActivityMaxLimit+1 {
+		q.Limit = repolearning.PathActivityMaxLimit + 1
+	}
+	out := []*repolearning.PathActivityRow{}
+	for _, row := range r.rows {
+		if b := q.Before; b != nil && !row.OccurredAt.Before(b.OccurredAt) {
+			continue
+		}
+		out = append(out, row)
+		if len(out) == q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func TestPathActivityCursorRoundTrip(t *testing.T) {
+	cur := repolearning.PathActivityCursor{
+		OccurredAt: time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC),
+		Kind:       repolearning.PathActivityKindVariantExposure,
+		SourceID:   uuid.NewString() + ":2026-03-01:served",
+	}
+	got, err := decodePathActivityCursor(encodePathActivityCursor(cur))
+	if err != nil || got == nil {
+		t.Fatalf("decode: got=%+v err=%v", got, err)
+	}
+	if !got.OccurredAt.Equal(cur.OccurredAt) || got.Kind != cur.Kind || got.SourceID != cur.SourceID {
+		t.Fatalf("round trip mismatch: got=%+v want=%+v", got, cur)
+	}
+	if got, err := decodePathActivityCursor(""); err != nil || got != nil {
+		t.Fatalf("empty cursor: got=%+v err=%v", got, err)
+	}
+	if _, err := decodePathActivityCursor("not-a-cursor"); err == nil {
+		t.Fatalf("expected malformed cursor error")
+	}
+}
+
+func TestParsePathActivityKinds(t *testing.T) {
+	got, err := parsePathActivityKinds([]string{"job_run, doc_revision", "JOB_RUN", "prereq_gate"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{"job_run", "doc_revision", "prereq_gate"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+	if got, err := parsePathActivityKinds(nil); err != nil || len(got) != 0 {
+		t.Fatalf("empty: got=%v err=%v", got, err)
+	}
+	if _, err := parsePathActivityKinds([]string{"doc_revision,bogus"}); err == nil {
+		t.Fatalf("expected unknown kind error")
+	}
+}
+
+func TestBuildPathActivityEvent(t *testing.T) {
+	nodeID := uuid.New()
+	cases := []struct {
+		row     repolearning.PathActivityRow
+		summary string
+		link    string
+	}{
+		{
+			row:     repolearning.PathActivityRow{Kind: repolearning.PathActivityKindDocRevision, Action: "rewrite", Detail: "paragraph", Status: "succeeded", PathNodeID: &nodeID},
+			summary: "Rewrote a paragraph block",
+			link:    "/api/path-nodes/" + nodeID.String() + "/doc/revisions",
+		},
+		{
+			row:     repolearning.PathActivityRow{Kind: repolearning.PathActivityKindVariantExposure, Action: "rollback", ItemCount: 3, PathNodeID: &nodeID},
+			summary: "Rolled back to the base doc (3 times)",
+			link:    "/api/path-nodes/" + nodeID.String() + "/doc",
+		},
+		{
+			row:     repolearning.PathActivityRow{Kind: repolearning.PathActivityKindPrereqGate, Action: "blocked", Detail: "low_readiness", PathNodeID: &nodeID},
+			summary: "Blocked until prerequisites are reviewed (low readiness)",
+			link:    "/api/path-nodes/" + nodeID.String() + "/doc",
+		},
+		{
+			row:     repolearning.PathActivityRow{Kind: repolearning.PathActivityKindDocGeneration, Action: "drill", Status: "failed", ItemCount: 3, PathNodeID: &nodeID},
+			summary: "Drill generation failed after 3 attempts",
+			link:    "/api/path-nodes/" + nodeID.String() + "/drills",
+		},
+		{
+			row:     repolearning.PathActivityRow{Kind: repolearning.PathActivityKindJobRun, SourceID: "job-1", Action: "learning_build", Status: "running", Detail: "node_docs"},
+			summary: "Learning build running (node docs)",
+			link:    "/api/jobs/job-1",
+		},
+	}
+	for _, tc := range cases {
+		row := tc.row
+		row.PolicyVersion = "v9"
+		ev := buildPathActivityEvent(&row, false)
+		if ev.Summary != tc.summary || ev.Link != tc.link {
+			t.Fatalf("%s: summary=%q link=%q, want %q %q", row.Kind, ev.Summary, ev.Link, tc.summary, tc.link)
+		}
+		if ev.PolicyVersion != "" {
+			t.Fatalf("%s: policy version leaked without debug", row.Kind)
+		}
+		if dbg := buildPathActivityEvent(&row, true); dbg.PolicyVersion != "v9" {
+			t.Fatalf("%s: debug event missing policy version", row.Kind)
+		}
+	}
+}
+
+func TestListPathActivityMaxPageSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	repo := &keysetActivityRepo{}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	total := repolearning.PathActivityMaxLimit + 50
+	for i := 0; i < total; i++ {
+		repo.rows = append(repo.rows, &repolearning.PathActivityRow{
+			Kind:       repolearning.PathActivityKindJobRun,
+			SourceID:   uuid.NewString(),
+			OccurredAt: start.Add(-time.Duration(i) * time.Minute),
+			Status:     "succeeded",
+		})
+	}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{Log: log, Path: PathHandlerPathRepos{Path: &fakePathRepo{path: path}, Activity: repo}})
+
+	seen := 0
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/paths/"+path.ID.String()+"/activity?limit=100&cursor="+url.QueryEscape(cursor), nil)
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: path.ID.String()}}
+		h.ListPathActivity(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("page %d: status %d: %s", page, w.Code, w.Body.String())
+		}
+		var body struct {
+			Events     []pathActivityEvent `json:"events"`
+			NextCursor string              `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if page == 0 && (len(body.Events) != repolearning.PathActivityMaxLimit || body.NextCursor == "") {
+			t.Fatalf("first page: %d events, cursor %q", len(body.Events), body.NextCursor)
+		}
+		seen += len(body.Events)
+		if cursor = body.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if seen != total {
+		t.Fatalf("paged %d events, want %d", seen, total)
+	}
+}
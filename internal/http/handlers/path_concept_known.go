@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+// POST /api/paths/:id/concepts/:concept_key/known
+//
+// Records that the user already knows a path concept, raising its mastery/confidence to the
+// "known" band. Repeating the call leaves the state unchanged.
+func (h *PathHandler) MarkPathConceptKnown(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	out, err := h.learning.MarkConceptKnown(c.Request.Context(), learningmod.MarkConceptKnownInput{
+		UserID:     rd.UserID,
+		PathID:     pathID,
+		ConceptKey: strings.TrimSpace(c.Param("concept_key")),
+	})
+	if err != nil {
+		var ae *apierr.Error
+		if errors.As(err, &ae) {
+			response.RespondError(c, ae.Status, ae.Code, ae.Err)
+			return
+		}
+		response.RespondError(c, http.StatusInternalServerError, "mark_concept_known_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"concept": out})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// docSearchStreamMaxHits caps one streamed response; clients resume from the trailing cursor.
+const docSearchStreamMaxHits = 500
+
+const ndjsonContentType = "application/x-ndjson"
+
+type docSearchHit struct {
+	DocID      uuid.UUID                            `json:"doc_id"`
+	PathID     uuid.UUID                            `json:"path_id"`
+	PathNodeID uuid.UUID                            `json:"path_node_id"`
+	PathTitle  string                               `json:"path_title,omitempty"`
+	NodeTitle  string                               `json:"node_title,omitempty"`
+	NodeIndex  int                                  `json:"node_index"`
+	UpdatedAt  time.Time                            `json:"updated_at"`
+	Rank       float64                              `json:"rank"`
+	Highlights []repolearning.NodeDocSearchFragment `json:"highlights"`
+	Link       string                               `json:"link"`
+}
+
+// GET /api/doc-search?q=&path_id=&cursor=&limit=
+//
+// Lexical search over the caller's node docs, ranked by ts_rank with per-doc highlights and
+// keyset pagination. With Accept: application/x-ndjson (or stream=1) hits are streamed one per
+// line, page by page, followed by a {"next_cursor": ...} line.
+func (h *PathHandler) SearchDocs(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.docSearch == nil {
+		response.RespondError(c, http.StatusInternalServerError, "doc_search_repo_missing", nil)
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		response.RespondError(c, http.StatusBadRequest, "missing_query", nil)
+		return
+	}
+	if utf8.RuneCountInString(query) > repolearning.NodeDocSearchMaxQueryLen {
+		response.RespondError(c, http.StatusBadRequest, "query_too_long", nil)
+		return
+	}
+	pathID := uuid.Nil
+	if raw := strings.TrimSpace(c.Query("path_id")); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil || id == uuid.Nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+			return
+		}
+		pathID = id
+	}
+	after, err := decodeDocSearchCursor(c.Query("cursor"))
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_cursor", err)
+		return
+	}
+	limit := repolearning.NodeDocSearchDefaultLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if limit > repolearning.NodeDocSearchMaxLimit {
+		limit = repolearning.NodeDocSearchMaxLimit
+	}
+
+	// Results are filtered by user_id, so another user's path_id simply matches nothing.
+	q := repolearning.NodeDocSearchQuery{UserID: rd.UserID, PathID: pathID, Query: query, After: after}
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+
+	if queryBool(c.Query("stream")) || strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		h.streamDocSearch(c, dbc, q)
+		return
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	q.Limit = limit + 1
+	rows, err := h.docSearch.Search(dbc, q)
+	if err != nil {
+		h.log.Error("SearchDocs failed", "error", err, "user_id", rd.UserID)
+		response.RespondError(c, http.StatusInternalServerError, "doc_search_failed", err)
+		return
+	}
+	nextCursor := ""
+	if len(rows) > limit {
+		rows = rows[:limit]
+		nextCursor = encodeDocSearchCursor(rows[len(rows)-1])
+	}
+	hits := make([]docSearchHit, 0, len(rows))
+	for _, row := range rows {
+		if row != nil {
+			hits = append(hits, buildDocSearchHit(row))
+		}
+	}
+	response.RespondOK(c, gin.H{"hits": hits, "next_cursor": nextCursor})
+}
+
+// streamDocSearch writes NDJSON page by page so only one page is ever held in memory. Errors
+// after the first byte can't change the status, so they are reported as a final error line.
+func (h *PathHandler) streamDocSearch(c *gin.Context, dbc dbctx.Context, q repolearning.NodeDocSearchQuery) {
+	c.Header("Content-Type", ndjsonContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	sent := 0
+	nextCursor := ""
+	for sent < docSearchStreamMaxHits {
+		if err := dbc.Ctx.Err(); err != nil {
+			return
+		}
+		pageSize := repolearning.NodeDocSearchMaxLimit
+		if left := docSearchStreamMaxHits - sent; left < pageSize {
+			pageSize = left
+		}
+		q.Limit = pageSize + 1
+		rows, err := h.docSearch.Search(dbc, q)
+		if err != nil {
+			h.log.Error("SearchDocs stream failed", "error", err, "user_id", q.UserID)
+			_ = enc.Encode(gin.H{"error": "doc_search_failed"})
+			return
+		}
+		more := len(rows) > pageSize
+		if more {
+			rows = rows[:pageSize]
+		}
+		for _, row := range rows {
+			if row == nil {
+				continue
+			}
+			if err := enc.Encode(buildDocSearchHit(row)); err != nil {
+				return
+			}
+			sent++
+		}
+		c.Writer.Flush()
+		nextCursor = ""
+		if !more || len(rows) == 0 {
+			break
+		}
+		last := rows[len(rows)-1]
+		nextCursor = encodeDocSearchCursor(last)
+		q.After = &repolearning.NodeDocSearchCursor{Rank: last.Rank, DocID: last.DocID}
+	}
+	_ = enc.Encode(gin.H{"next_cursor": nextCursor})
+	c.Writer.Flush()
+}
+
+func buildDocSearchHit(row *repolearning.NodeDocSearchHit) docSearchHit {
+	highlights := row.Fragments
+	if highlights == nil {
+		highlights = []repolearning.NodeDocSearchFragment{}
+	}
+	return docSearchHit{
+		DocID:      row.DocID,
+		PathID:     row.PathID,
+		PathNodeID: row.PathNodeID,
+		PathTitle:  row.PathTitle,
+		NodeTitle:  row.NodeTitle,
+		NodeIndex:  row.NodeIndex,
+		UpdatedAt:  row.UpdatedAt.UTC(),
+		Rank:       row.Rank,
+		Highlights: highlights,
+		Link:       "/api/path-nodes/" + row.PathNodeID.String() + "/doc",
+	}
+}
+
+// The rank is encoded with full precision so the keyset comparison matches the stored float.
+func encodeDocSearchCursor(row *repolearning.NodeDocSearchHit) string {
+	raw := strconv.FormatFloat(row.Rank, 'g', -1, 64) + "|" + row.DocID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeDocSearchCursor(s string) (*repolearning.NodeDocSearchCursor, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	rank, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil || id == uuid.Nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &repolearning.NodeDocSearchCursor{Rank: rank, DocID: id}, nil
+}
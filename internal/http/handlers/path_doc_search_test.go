@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// keysetSearchRepo pages a fixed hit list the way the SQL does: (rank DESC, doc_id ASC).
+type keysetSearchRepo struct {
+	hits  []*repolearning.NodeDocSearchHit
+	calls int
+}
+
+func (r *keysetSearchRepo) Search(dbc dbctx.Context, q repolearning.NodeDocSearchQuery) ([]*repolearning.NodeDocSearchHit, error) {
+	r.calls++
+	// Clamp like nodeDocSearchRepo.Search: a full page plus the lookahead row.
+	if q.Limit > repolearning.NodeDocSearchMaxLimit+1 {
+		q.Limit = repolearning.NodeDocSearchMaxLimit + 1
+	}
+	out := []*repolearning.NodeDocSearchHit{}
+	for _, h := range r.hits {
+		if a := q.After; a != nil && !(h.Rank < a.Rank || (h.Rank == a.Rank && h.DocID.String() > a.DocID.String())) {
+			continue
+		}
+		out = append(out, h)
+		if len(out) == q.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func TestSearchDocsKeysetPaging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	repo := &keysetSearchRepo{}
+	// Ties on rank exercise the doc_id tie-break.
+	for i, rank := range []float64{0.9, 0.5, 0.5, 0.5, 0.1 / 3} {
+		repo.hits = append(repo.hits, &repolearning.NodeDocSearchHit{
+			DocID: uuid.New(), PathNodeID: uuid.New(), Rank: rank, NodeIndex: i,
+			Fragments: []repolearning.NodeDocSearchFragment{{Text: "loops repeat", Matches: [][2]int{{0, 5}}}},
+		})
+	}
+	sort.SliceStable(repo.hits, func(i, j int) bool {
+		a, b := repo.hits[i], repo.hits[j]
+		return a.Rank > b.Rank || (a.Rank == b.Rank && a.DocID.String() < b.DocID.String())
+	})
+	h := NewPathHandlerWithDeps(PathHandlerDeps{Log: log, Content: PathHandlerContentRepos{DocSearch: repo}})
+	userID := uuid.New()
+
+	call := func(query string, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/doc-search?"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		h.SearchDocs(c)
+		return w
+	}
+
+	if w := call("q=", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("empty query: status %d", w.Code)
+	}
+	if w := call("q=loops&cursor=bogus", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad cursor: status %d", w.Code)
+	}
+
+	var seen []uuid.UUID
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		w := call("q=loops&limit=2&cursor="+url.QueryEscape(cursor), "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("page %d: status %d: %s", page, w.Code, w.Body.String())
+		}
+		var body struct {
+			Hits       []docSearchHit `json:"hits"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		for _, hit := range body.Hits {
+			seen = append(seen, hit.DocID)
+			if len(hit.Highlights) != 1 || !strings.HasSuffix(hit.Link, "/doc") {
+				t.Fatalf("hit = %+v", hit)
+			}
+		}
+		if cursor = body.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(seen) != len(repo.hits) {
+		t.Fatalf("paged %d hits, want %d", len(seen), len(repo.hits))
+	}
+	for i, id := range seen {
+		if id != repo.hits[i].DocID {
+			t.Fatalf("hit %d out of order", i)
+		}
+	}
+
+	// Streaming returns every hit plus a trailing empty cursor.
+	w := call("q=loops", ndjsonContentType)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), ndjsonContentType) {
+		t.Fatalf("stream: status %d type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	sc := bufio.NewScanner(strings.NewReader(w.Body.String()))
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != len(repo.hits)+1 || lines[len(lines)-1] != `{"next_cursor":""}` {
+		t.Fatalf("stream lines = %v", lines)
+	}
+}
+
+func TestSearchDocsPagesPastRepoMaxLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	repo := &keysetSearchRepo{}
+	for i := 0; i < docSearchStreamMaxHits+20; i++ {
+		repo.hits = append(repo.hits, &repolearning.NodeDocSearchHit{DocID: uuid.New(), PathNodeID: uuid.New(), Rank: 1 - float64(i)/1000, NodeIndex: i})
+	}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{Log: log, Content: PathHandlerContentRepos{DocSearch: repo}})
+	userID := uuid.New()
+	call := func(query string, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/doc-search?"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		h.SearchDocs(c)
+		return w
+	}
+
+	// Paged at the max limit: every full page carries a cursor until the hits run out.
+	seen := 0
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > len(repo.hits) {
+			t.Fatalf("paging did not terminate")
+		}
+		w := call("q=loops&limit=50&cursor="+url.QueryEscape(cursor), "")
+		var body struct {
+			Hits       []docSearchHit `json:"hits"`
+			NextCursor string         `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if body.NextCursor != "" && len(body.Hits) != repolearning.NodeDocSearchMaxLimit {
+			t.Fatalf("page %d: %d hits with a cursor", page, len(body.Hits))
+		}
+		seen += len(body.Hits)
+		if cursor = body.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if seen != len(repo.hits) {
+		t.Fatalf("paged %d hits, want %d", seen, len(repo.hits))
+	}
+
+	// Streaming crosses repo pages up to the response cap and hands back a resume cursor.
+	w := call("q=loops", ndjsonContentType)
+	sc := bufio.NewScanner(strings.NewReader(w.Body.String()))
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != docSearchStreamMaxHits+1 {
+		t.Fatalf("stream returned %d lines, want %d", len(lines), docSearchStreamMaxHits+1)
+	}
+	var tail struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &tail); err != nil || tail.NextCursor == "" {
+		t.Fatalf("stream tail = %q", lines[len(lines)-1])
+	}
+	w = call("q=loops&cursor="+url.QueryEscape(tail.NextCursor), ndjsonContentType)
+	if n := strings.Count(w.Body.String(), "\n"); n != len(repo.hits)-docSearchStreamMaxHits+1 {
+		t.Fatalf("resumed stream returned %d lines", n)
+	}
+}
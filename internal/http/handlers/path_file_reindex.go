@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// POST /api/paths/:id/files/:file_id/reindex-concepts
+//
+// Enqueues a concept_graph_patch_build scoped to one material file: its chunks are inventoried,
+// the concepts merged into the path's existing graph, and edges touching them appended or
+// updated. A queued or running reindex of the same file is returned instead of enqueueing another.
+func (h *PathHandler) ReindexPathFileConcepts(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "ReindexPathFileConcepts"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_service_missing", nil)
+		return
+	}
+	if h.materialFiles == nil {
+		response.RespondError(c, http.StatusInternalServerError, "material_repo_missing", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil || fileID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_material_file_id", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	pathRow, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("ReindexPathFileConcepts failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	materialSetID := resolvePathMaterialSetID(pathRow, nil)
+	if materialSetID == uuid.Nil && h.userLibraryIndex != nil {
+		if idx, err := h.userLibraryIndex.GetByUserAndPathID(dbc, rd.UserID, pathID); err == nil && idx != nil {
+			materialSetID = resolvePathMaterialSetID(pathRow, idx)
+		}
+	}
+	if materialSetID == uuid.Nil {
+		response.RespondError(c, http.StatusConflict, "material_set_missing", nil)
+		return
+	}
+
+	files, err := h.materialFiles.GetByIDs(dbc, []uuid.UUID{fileID})
+	if err != nil {
+		h.log.Error("ReindexPathFileConcepts failed (load file)", "error", err, "material_file_id", fileID)
+		response.RespondError(c, http.StatusInternalServerError, "load_files_failed", err)
+		return
+	}
+	inSet := false
+	for _, f := range files {
+		if f != nil && f.ID == fileID && f.MaterialSetID == materialSetID {
+			inSet = true
+		}
+	}
+	if allow := materialFileAllowlistFromPathMetaJSON(pathRow.Metadata); inSet && len(allow) > 0 {
+		inSet = allow[fileID]
+	}
+	if !inSet {
+		response.RespondError(c, http.StatusNotFound, "material_file_not_found", nil)
+		return
+	}
+
+	if h.jobs != nil {
+		latest, err := h.jobs.GetLatestByEntity(dbc, rd.UserID, "material_file", fileID, "concept_graph_patch_build")
+		if err != nil {
+			h.log.Warn("ReindexPathFileConcepts latest job lookup failed", "error", err, "material_file_id", fileID)
+		} else if regenerateJobMatches(latest, fileID.String()) {
+			response.RespondOK(c, gin.H{"job_id": latest.ID, "deduped": true})
+			return
+		}
+	}
+
+	payload := map[string]any{
+		"material_set_id":  materialSetID.String(),
+		"path_id":          pathID.String(),
+		"material_file_id": fileID.String(),
+		"idempotency_key":  fileID.String(),
+	}
+	entityID := fileID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "concept_graph_patch_build", "material_file", &entityID, payload)
+	if err != nil {
+		h.log.Error("ReindexPathFileConcepts failed (enqueue)", "error", err, "material_file_id", fileID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"job_id": job.ID})
+}
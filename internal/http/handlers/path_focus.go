@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const (
+	pathFocusMaxDuration    = 90 * 24 * time.Hour
+	pathFocusMaxGoalLen     = 500
+	pathFocusMaxConceptKeys = 50
+)
+
+type startPathFocusReq struct {
+	GoalText    string    `json:"goal_text"`
+	ConceptKeys []string  `json:"concept_keys"`
+	Until       time.Time `json:"until"`
+}
+
+// GET /api/paths/:id/focus
+func (h *PathHandler) GetPathFocus(c *gin.Context) {
+	rd, pathRow, ok := h.loadOwnedPathForFocus(c)
+	if !ok {
+		return
+	}
+	focus, err := h.pathFocus.GetActive(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, pathRow.ID, time.Now().UTC())
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_focus_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"focus": focus})
+}
+
+// POST /api/paths/:id/focus
+func (h *PathHandler) StartPathFocus(c *gin.Context) {
+	rd, pathRow, ok := h.loadOwnedPathForFocus(c)
+	if !ok {
+		return
+	}
+	var req startPathFocusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_request", err)
+		return
+	}
+	goal := strings.TrimSpace(req.GoalText)
+	if goal == "" || len([]rune(goal)) > pathFocusMaxGoalLen {
+		response.RespondError(c, http.StatusBadRequest, "invalid_goal_text", nil)
+		return
+	}
+	now := time.Now().UTC()
+	until := req.Until.UTC()
+	if !until.After(now) || until.Sub(now) > pathFocusMaxDuration {
+		response.RespondError(c, http.StatusBadRequest, "invalid_until", nil)
+		return
+	}
+	keys := normalizeConceptKeys(req.ConceptKeys)
+	if len(keys) > pathFocusMaxConceptKeys {
+		response.RespondError(c, http.StatusBadRequest, "too_many_concept_keys", nil)
+		return
+	}
+	if unknown, err := h.unknownPathConceptKeys(c.Request.Context(), pathRow.ID, keys); err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_concepts_failed", err)
+		return
+	} else if len(unknown) > 0 {
+		response.RespondError(c, http.StatusBadRequest, "unknown_concept_keys", fmt.Errorf("unknown concept keys: %s", strings.Join(unknown, ", ")))
+		return
+	}
+
+	rawKeys, _ := json.Marshal(keys)
+	if len(keys) == 0 {
+		rawKeys = []byte("[]")
+	}
+	row := &types.PathFocus{
+		ID:          uuid.New(),
+		UserID:      rd.UserID,
+		PathID:      pathRow.ID,
+		GoalText:    goal,
+		ConceptKeys: datatypes.JSON(rawKeys),
+		Until:       until,
+		CreatedAt:   now,
+	}
+	if err := h.pathFocus.Start(dbctx.Context{Ctx: c.Request.Context()}, row); err != nil {
+		h.log.Error("StartPathFocus failed", "error", err, "path_id", pathRow.ID)
+		response.RespondError(c, http.StatusInternalServerError, "start_focus_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"focus": row})
+}
+
+// DELETE /api/paths/:id/focus
+func (h *PathHandler) EndPathFocus(c *gin.Context) {
+	rd, pathRow, ok := h.loadOwnedPathForFocus(c)
+	if !ok {
+		return
+	}
+	ended, err := h.pathFocus.End(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, pathRow.ID, time.Now().UTC())
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "end_focus_failed", err)
+		return
+	}
+	if !ended {
+		response.RespondError(c, http.StatusNotFound, "focus_not_found", nil)
+		return
+	}
+	response.RespondOK(c, gin.H{"ok": true})
+}
+
+func (h *PathHandler) loadOwnedPathForFocus(c *gin.Context) (*ctxutil.RequestData, *types.Path, bool) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return nil, nil, false
+	}
+	if h.pathFocus == nil || h.path == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "path_focus_unavailable", nil)
+		return nil, nil, false
+	}
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return nil, nil, false
+	}
+	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, pathID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return nil, nil, false
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return nil, nil, false
+	}
+	return rd, pathRow, true
+}
+
+// unknownPathConceptKeys returns the keys that name none of the path's concepts. Without a
+// concept repo every key is accepted.
+func (h *PathHandler) unknownPathConceptKeys(ctx context.Context, pathID uuid.UUID, keys []string) ([]string, error) {
+	if h.concepts == nil || len(keys) == 0 {
+		return nil, nil
+	}
+	rows, err := h.concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]bool{}
+	for _, c := range rows {
+		if c != nil {
+			known[normalizeConceptKeyDoc(c.Key)] = true
+		}
+	}
+	var unknown []string
+	for _, k := range keys {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	return unknown, nil
+}
+
+// activePathFocus returns the user's focus on the path, or nil when there is none or it can't
+// be loaded; focus only biases serving, so a failed read never fails the request.
+func (h *PathHandler) activePathFocus(ctx context.Context, userID uuid.UUID, pathID uuid.UUID) *types.PathFocus {
+	if h.pathFocus == nil {
+		return nil
+	}
+	focus, err := h.pathFocus.GetActive(dbctx.Context{Ctx: ctx}, userID, pathID, time.Now().UTC())
+	if err != nil {
+		h.log.WarnThrottled("path_focus.load", time.Minute, "load path focus failed", "error", err, "path_id", pathID)
+		return nil
+	}
+	return focus
+}
+
+// pathFocusMatch is how a node relates to the active focus.
+type pathFocusMatch struct {
+	Related bool `json:"related"`
+	// Basis is "concepts" (focus concept keys vs the node's), "goal_terms" (goal words vs the
+	// node's title and concept keys) or "unknown" when the node gives nothing to compare.
+	Basis   string   `json:"basis"`
+	Score   float64  `json:"score"`
+	Matched []string `json:"matched,omitempty"`
+}
+
+// pathFocusMinStemLen is the shortest shared prefix that counts as a goal-term match, so
+// "recursion" matches a "recursive_calls" concept.
+const pathFocusMinStemLen = 5
+
+// pathFocusRelatedness scores a node against the focus by overlap: the share of focus concept
+// keys the node covers, or, for a focus without concept keys, the share of goal terms found in
+// the node's title and concept keys. A node with nothing to compare counts as related, so the
+// notice is only shown on evidence.
+func pathFocusRelatedness(focus *types.PathFocus, nodeTitle string, nodeKeys []string) pathFocusMatch {
+	unknown := pathFocusMatch{Related: true, Basis: "unknown"}
+	if focus == nil {
+		return unknown
+	}
+	keys := normalizeConceptKeys(nodeKeys)
+	if focusKeys := focus.ConceptKeyList(); len(focusKeys) > 0 {
+		if len(keys) == 0 {
+			return unknown
+		}
+		has := map[string]bool{}
+		for _, k := range keys {
+			has[k] = true
+		}
+		m := pathFocusMatch{Basis: "concepts"}
+		for _, k := range focusKeys {
+			if has[k] {
+				m.Matched = append(m.Matched, k)
+			}
+		}
+		m.Score = float64(len(m.Matched)) / float64(len(focusKeys))
+		m.Related = len(m.Matched) > 0
+		return m
+	}
+
+	terms := focus.GoalTerms()
+	words := pathFocusWords(nodeTitle + " " + strings.Join(keys, " "))
+	if len(terms) == 0 || len(words) == 0 {
+		return unknown
+	}
+	m := pathFocusMatch{Basis: "goal_terms"}
+	for _, t := range terms {
+		for _, w := range words {
+			if pathFocusTermMatch(t, w) {
+				m.Matched = append(m.Matched, t)
+				break
+			}
+		}
+	}
+	m.Score = float64(len(m.Matched)) / float64(len(terms))
+	m.Related = len(m.Matched) > 0
+	return m
+}
+
+func pathFocusWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func pathFocusTermMatch(term, word string) bool {
+	if term == word {
+		return true
+	}
+	a, b := []rune(term), []rune(word)
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n >= pathFocusMinStemLen
+}
+
+// pathNodeConceptKeys reads the concept keys the path build stored on the node.
+func pathNodeConceptKeys(node *types.PathNode) []string {
+	if node == nil || len(node.Metadata) == 0 || string(node.Metadata) == "null" {
+		return nil
+	}
+	var meta map[string]any
+	if json.Unmarshal(node.Metadata, &meta) != nil {
+		return nil
+	}
+	return stringSliceFromAny(meta["concept_keys"])
+}
+
+// nodeDocFocusNotice records the focus and the node's relatedness in the exposure meta and
+// returns a notice when the node is unrelated to it. The doc is served either way.
+func nodeDocFocusNotice(focus *types.PathFocus, node *types.PathNode, nodeKeys []string, meta map[string]any) *nodeDocContentNotice {
+	if focus == nil || node == nil {
+		return nil
+	}
+	match := pathFocusRelatedness(focus, node.Title, append(pathNodeConceptKeys(node), nodeKeys...))
+	trace := focus.TraceMeta()
+	trace["related"] = match.Related
+	trace["basis"] = match.Basis
+	trace["score"] = match.Score
+	if meta != nil {
+		meta["focus"] = trace
+	}
+	if match.Related {
+		return nil
+	}
+	return &nodeDocContentNotice{
+		Kind:      "focus_unrelated",
+		FocusID:   focus.ID.String(),
+		ChangedAt: focus.CreatedAt,
+		Message:   fmt.Sprintf("This unit is outside your current focus (%s).", focus.GoalText),
+	}
+}
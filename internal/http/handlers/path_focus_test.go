@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestPathFocusRelatedness(t *testing.T) {
+	conceptFocus := &types.PathFocus{
+		GoalText:    "Midterm on recursion",
+		ConceptKeys: datatypes.JSON(`["Recursion", "base_case", "recursion", "memoization"]`),
+	}
+	goalFocus := &types.PathFocus{GoalText: "Prepare for the exam on recursion and tree traversal"}
+
+	cases := []struct {
+		name    string
+		focus   *types.PathFocus
+		title   string
+		keys    []string
+		related bool
+		basis   string
+		score   float64
+		matched []string
+	}{
+		{"concept overlap", conceptFocus, "Unrelated title", []string{"BASE_CASE", "stack_frames"}, true, "concepts", 1.0 / 3, []string{"base_case"}},
+		{"full concept overlap", conceptFocus, "", []string{"recursion", "base_case", "memoization"}, true, "concepts", 1, []string{"recursion", "base_case", "memoization"}},
+		{"no concept overlap", conceptFocus, "Recursion intro", []string{"hash_maps"}, false, "concepts", 0, nil},
+		{"node without keys", conceptFocus, "Hash maps", nil, true, "unknown", 0, nil},
+		{"goal term in title", goalFocus, "Tree Traversal Basics", nil, true, "goal_terms", 2.0 / 3, []string{"tree", "traversal"}},
+		{"goal term stem in key", goalFocus, "Functions", []string{"recursive_calls"}, true, "goal_terms", 1.0 / 3, []string{"recursion"}},
+		{"no goal terms match", goalFocus, "Sorting algorithms", []string{"quicksort"}, false, "goal_terms", 0, nil},
+		{"stopword-only goal", &types.PathFocus{GoalText: "study for the final exam"}, "Sorting", nil, true, "unknown", 0, nil},
+		{"no focus", nil, "Sorting", []string{"quicksort"}, true, "unknown", 0, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := pathFocusRelatedness(tc.focus, tc.title, tc.keys)
+			if m.Related != tc.related || m.Basis != tc.basis {
+				t.Fatalf("match = %+v, want related=%v basis=%s", m, tc.related, tc.basis)
+			}
+			if diff := m.Score - tc.score; diff > 1e-9 || diff < -1e-9 {
+				t.Fatalf("score = %v, want %v", m.Score, tc.score)
+			}
+			if !reflect.DeepEqual(m.Matched, tc.matched) {
+				t.Fatalf("matched = %v, want %v", m.Matched, tc.matched)
+			}
+		})
+	}
+}
+
+func TestNodeDocFocusNotice(t *testing.T) {
+	focus := &types.PathFocus{
+		ID:          uuid.New(),
+		GoalText:    "Graphs midterm",
+		ConceptKeys: datatypes.JSON(`["bfs"]`),
+		Until:       time.Now().Add(time.Hour),
+		CreatedAt:   time.Now(),
+	}
+	related := &types.PathNode{Title: "Search", Metadata: datatypes.JSON(`{"concept_keys":["bfs","dfs"]}`)}
+	unrelated := &types.PathNode{Title: "Sorting", Metadata: datatypes.JSON(`{"concept_keys":["quicksort"]}`)}
+
+	meta := map[string]any{}
+	if n := nodeDocFocusNotice(focus, related, nil, meta); n != nil {
+		t.Fatalf("related node got notice %+v", n)
+	}
+	trace, _ := meta["focus"].(map[string]any)
+	if trace["related"] != true || trace["focus_id"] != focus.ID.String() {
+		t.Fatalf("meta focus = %+v", trace)
+	}
+
+	// Doc concept keys count toward overlap too.
+	if n := nodeDocFocusNotice(focus, unrelated, []string{"BFS"}, map[string]any{}); n != nil {
+		t.Fatalf("doc keys ignored: %+v", n)
+	}
+
+	meta = map[string]any{}
+	n := nodeDocFocusNotice(focus, unrelated, nil, meta)
+	if n == nil || n.Kind != "focus_unrelated" || n.FocusID != focus.ID.String() {
+		t.Fatalf("notice = %+v", n)
+	}
+	if trace, _ := meta["focus"].(map[string]any); trace["related"] != false {
+		t.Fatalf("meta focus = %+v", trace)
+	}
+	if n := nodeDocFocusNotice(nil, unrelated, nil, meta); n != nil {
+		t.Fatalf("no focus got notice %+v", n)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/services"
@@ -16,12 +17,21 @@ type PathHandler struct {
 
 	path               repos.PathRepo
 	pathNodes          repos.PathNodeRepo
+	pathShares         repos.PathShareRepo
+	pathFocus          repos.PathFocusRepo
 	pathNodeActivity   repos.PathNodeActivityRepo
+	pathActivity       repos.PathActivityRepo
 	activities         repos.ActivityRepo
+	activityVariants   repos.ActivityVariantRepo
 	nodeDocs           repos.LearningNodeDocRepo
+	docSearch          repos.NodeDocSearchRepo
 	docRevisions       repos.LearningNodeDocRevisionRepo
 	docVariants        repos.LearningNodeDocVariantRepo
 	docVariantExposure repos.DocVariantExposureRepo
+	docAssignments     repos.DocVariantAssignmentRepo
+	docGenRuns         repos.LearningDocGenerationRunRepo
+	nodeFigures        repos.LearningNodeFigureRepo
+	nodeAudio          repos.LearningNodeAudioRepo
 	chunks             repos.MaterialChunkRepo
 	materialSets       repos.MaterialSetRepo
 	materialFiles      repos.MaterialFileRepo
@@ -33,29 +43,49 @@ type PathHandler struct {
 	conceptState repos.UserConceptStateRepo
 	policyEval   repos.PolicyEvalSnapshotRepo
 	prereqGates  repos.PrereqGateDecisionRepo
+	nodeRuns     repos.NodeRunRepo
+	activityRuns repos.ActivityRunRepo
+	// Optional; nil serves the primary doc track unless a track is requested.
+	profiles repos.LearningProfileRepo
 
 	assets repos.AssetRepo
 	jobs   repos.JobRunRepo
 	jobSvc services.JobService
 	events services.EventService
+	// Optional; nil falls back to direct repo reads.
+	docCache services.DocServingCache
+	outlines services.PathOutlineService
 
 	avatar   services.AvatarService
 	learning learningmod.Usecases
 	bucket   gcp.BucketService
+	// Opens sealed user_md bodies on serve; nil serves only unsealed notes.
+	fieldCrypt *fieldcrypt.Envelope
+
+	assetViewBase string
 }
 
 type PathHandlerPathRepos struct {
 	Path             repos.PathRepo
 	PathNodes        repos.PathNodeRepo
+	Shares           repos.PathShareRepo
+	Focus            repos.PathFocusRepo
 	PathNodeActivity repos.PathNodeActivityRepo
+	Activity         repos.PathActivityRepo
 }
 
 type PathHandlerContentRepos struct {
 	Activities         repos.ActivityRepo
+	ActivityVariants   repos.ActivityVariantRepo
 	NodeDocs           repos.LearningNodeDocRepo
+	DocSearch          repos.NodeDocSearchRepo
 	DocRevisions       repos.LearningNodeDocRevisionRepo
 	DocVariants        repos.LearningNodeDocVariantRepo
 	DocVariantExposure repos.DocVariantExposureRepo
+	DocAssignments     repos.DocVariantAssignmentRepo
+	DocGenRuns         repos.LearningDocGenerationRunRepo
+	NodeFigures        repos.LearningNodeFigureRepo
+	NodeAudio          repos.LearningNodeAudioRepo
 	Chunks             repos.MaterialChunkRepo
 	MaterialSets       repos.MaterialSetRepo
 	MaterialFiles      repos.MaterialFileRepo
@@ -70,15 +100,23 @@ type PathHandlerLearningRepos struct {
 	ConceptState repos.UserConceptStateRepo
 	PolicyEval   repos.PolicyEvalSnapshotRepo
 	PrereqGates  repos.PrereqGateDecisionRepo
+	NodeRuns     repos.NodeRunRepo
+	ActivityRuns repos.ActivityRunRepo
+	Profiles     repos.LearningProfileRepo
 }
 
 type PathHandlerServices struct {
 	Jobs     repos.JobRunRepo
 	JobSvc   services.JobService
 	Events   services.EventService
+	DocCache services.DocServingCache
+	// Optional; nil gets a handler-local outline cache.
+	Outlines services.PathOutlineService
 	Avatar   services.AvatarService
 	Learning learningmod.Usecases
 	Bucket   gcp.BucketService
+	// Optional; nil serves sealed user notes as a placeholder.
+	FieldCrypt *fieldcrypt.Envelope
 }
 
 type PathHandlerDeps struct {
@@ -89,20 +127,36 @@ type PathHandlerDeps struct {
 	Content  PathHandlerContentRepos
 	Learning PathHandlerLearningRepos
 	Services PathHandlerServices
+
+	// AssetViewBasePath prefixes the asset view URLs handed to clients (default "/api").
+	AssetViewBasePath string
 }
 
 func NewPathHandlerWithDeps(deps PathHandlerDeps) *PathHandler {
+	outlines := deps.Services.Outlines
+	if outlines == nil {
+		outlines = services.NewPathOutlineService(deps.Log, deps.Path.Path, deps.Path.PathNodes)
+	}
 	return &PathHandler{
 		log:                deps.Log.With("handler", "PathHandler"),
 		db:                 deps.DB,
 		path:               deps.Path.Path,
 		pathNodes:          deps.Path.PathNodes,
+		pathShares:         deps.Path.Shares,
+		pathFocus:          deps.Path.Focus,
 		pathNodeActivity:   deps.Path.PathNodeActivity,
+		pathActivity:       deps.Path.Activity,
 		activities:         deps.Content.Activities,
+		activityVariants:   deps.Content.ActivityVariants,
 		nodeDocs:           deps.Content.NodeDocs,
+		docSearch:          deps.Content.DocSearch,
 		docRevisions:       deps.Content.DocRevisions,
 		docVariants:        deps.Content.DocVariants,
 		docVariantExposure: deps.Content.DocVariantExposure,
+		docAssignments:     deps.Content.DocAssignments,
+		docGenRuns:         deps.Content.DocGenRuns,
+		nodeFigures:        deps.Content.NodeFigures,
+		nodeAudio:          deps.Content.NodeAudio,
 		chunks:             deps.Content.Chunks,
 		materialSets:       deps.Content.MaterialSets,
 		materialFiles:      deps.Content.MaterialFiles,
@@ -113,13 +167,20 @@ func NewPathHandlerWithDeps(deps PathHandlerDeps) *PathHandler {
 		conceptState:       deps.Learning.ConceptState,
 		policyEval:         deps.Learning.PolicyEval,
 		prereqGates:        deps.Learning.PrereqGates,
+		nodeRuns:           deps.Learning.NodeRuns,
+		activityRuns:       deps.Learning.ActivityRuns,
+		profiles:           deps.Learning.Profiles,
 		assets:             deps.Content.Assets,
 		jobs:               deps.Services.Jobs,
 		jobSvc:             deps.Services.JobSvc,
 		events:             deps.Services.Events,
+		docCache:           deps.Services.DocCache,
+		outlines:           outlines,
 		avatar:             deps.Services.Avatar,
 		learning:           deps.Services.Learning,
 		bucket:             deps.Services.Bucket,
+		fieldCrypt:         deps.Services.FieldCrypt,
+		assetViewBase:      normalizeAssetViewBasePath(deps.AssetViewBasePath),
 	}
 }
 
@@ -1,14 +1,34 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/http/response"
 	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+const (
+	PathNodeActivityRolePractice   = "practice"
+	PathNodeActivityRoleAssessment = "assessment"
+	PathNodeActivityRoleEnrichment = "enrichment"
+
+	// Activities owned by the shared library rather than a single path.
+	activityOwnerCanonical = "canonical"
+
+	// Linkage edits kept in path_node.metadata.activity_revisions (oldest dropped first).
+	pathNodeActivityRevisionsKept = 20
 )
 
 type PathNodeActivityListItem struct {
@@ -17,12 +37,34 @@ type PathNodeActivityListItem struct {
 	PathNodeID         uuid.UUID `json:"path_node_id"`
 	Rank               int       `json:"rank"`
 	IsPrimary          bool      `json:"is_primary"`
+	Role               string    `json:"role,omitempty"`
 
 	Kind             string `json:"kind"`
 	Title            string `json:"title"`
 	EstimatedMinutes int    `json:"estimated_minutes"`
 	Difficulty       string `json:"difficulty,omitempty"`
 	Status           string `json:"status"`
+
+	VariantCount int                          `json:"variant_count"`
+	Attempts     *PathNodeActivityAttemptInfo `json:"attempts,omitempty"`
+}
+
+// PathNodeActivityAttemptInfo summarizes the caller's activity_run for one activity.
+type PathNodeActivityAttemptInfo struct {
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	LastScore     float64    `json:"last_score"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+type putPathNodeActivitiesRequest struct {
+	Activities []putPathNodeActivityItem `json:"activities"`
+}
+
+type putPathNodeActivityItem struct {
+	ActivityID uuid.UUID `json:"activity_id"`
+	Role       string    `json:"role,omitempty"`
 }
 
 // GET /api/path-nodes/:id/activities
@@ -39,37 +81,190 @@ func (h *PathHandler) ListPathNodeActivities(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, ok := h.loadOwnedPathNode(c, dbc, "ListPathNodeActivities", rd.UserID, nodeID)
+	if !ok {
+		return
+	}
+
+	items, code, err := h.listPathNodeActivityItems(dbc, rd.UserID, node)
 	if err != nil {
-		h.log.Error("ListPathNodeActivities failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		h.log.Error("ListPathNodeActivities failed", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, code, err)
 		return
 	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+	response.RespondOK(c, gin.H{"activities": items})
+}
+
+// PUT /api/path-nodes/:id/activities
+//
+// Replaces the node's activity linkage with the given ordered list. Ranks follow list order and
+// the first item is primary. Removed links never delete the Activity. Each activity must belong
+// to the node's path or be canonical. Changes are recorded in the node metadata;
+// resubmitting the current list changes nothing.
+func (h *PathHandler) PutPathNodeActivities(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "PutPathNodeActivities"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
 
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req putPathNodeActivitiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+	if max := pathNodeActivitiesMax(); len(req.Activities) > max {
+		response.RespondError(c, http.StatusBadRequest, "too_many_activities", fmt.Errorf("at most %d activities per node", max))
+		return
+	}
+	seen := map[uuid.UUID]bool{}
+	activityIDs := make([]uuid.UUID, 0, len(req.Activities))
+	for i := range req.Activities {
+		item := &req.Activities[i]
+		if item.ActivityID == uuid.Nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_activity_id", nil)
+			return
+		}
+		if seen[item.ActivityID] {
+			response.RespondError(c, http.StatusBadRequest, "duplicate_activity", fmt.Errorf("activity %s listed twice", item.ActivityID))
+			return
+		}
+		seen[item.ActivityID] = true
+		role, ok := normalizePathNodeActivityRole(item.Role)
+		if !ok {
+			response.RespondError(c, http.StatusBadRequest, "invalid_role", fmt.Errorf("unknown role %q", item.Role))
+			return
+		}
+		item.Role = role
+		activityIDs = append(activityIDs, item.ActivityID)
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, ok := h.loadOwnedPathNode(c, dbc, "PutPathNodeActivities", rd.UserID, nodeID)
+	if !ok {
+		return
+	}
+
+	activities, err := h.activities.GetByIDs(dbc, activityIDs)
 	if err != nil {
-		h.log.Error("ListPathNodeActivities failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		h.log.Error("PutPathNodeActivities failed (load activities)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_activities_failed", err)
 		return
 	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+	visible := map[uuid.UUID]bool{}
+	for _, a := range activities {
+		if a != nil && activityVisibleOnPath(a, node.PathID) {
+			visible[a.ID] = true
+		}
+	}
+	for _, id := range activityIDs {
+		if !visible[id] {
+			response.RespondError(c, http.StatusNotFound, "activity_not_found", fmt.Errorf("activity %s not found", id))
+			return
+		}
+	}
+
+	if h.db == nil {
+		response.RespondError(c, http.StatusInternalServerError, "db_unavailable", nil)
+		return
+	}
+	if err := h.db.WithContext(c.Request.Context()).Transaction(func(txx *gorm.DB) error {
+		tdbc := dbctx.Context{Ctx: c.Request.Context(), Tx: txx}
+		before, err := h.pathNodeActivity.GetByPathNodeIDs(tdbc, []uuid.UUID{nodeID})
+		if err != nil {
+			return err
+		}
+		rows := make([]*types.PathNodeActivity, 0, len(req.Activities))
+		for i, item := range req.Activities {
+			rows = append(rows, &types.PathNodeActivity{
+				PathNodeID: nodeID,
+				ActivityID: item.ActivityID,
+				Rank:       i,
+				IsPrimary:  i == 0,
+				Role:       item.Role,
+			})
+		}
+		rev := diffPathNodeActivities(before, rows)
+		if rev == nil {
+			return nil
+		}
+		if err := h.pathNodeActivity.ReplaceForPathNode(tdbc, nodeID, rows); err != nil {
+			return err
+		}
+		fresh, err := h.pathNodes.GetByID(tdbc, nodeID)
+		if err != nil {
+			return err
+		}
+		if fresh == nil {
+			return fmt.Errorf("path node %s disappeared", nodeID)
+		}
+		rev["at"] = time.Now().UTC().Format(time.RFC3339Nano)
+		rev["user_id"] = rd.UserID.String()
+		meta, err := appendPathNodeActivityRevision(fresh.Metadata, rev)
+		if err != nil {
+			return err
+		}
+		return h.pathNodes.UpdateFields(tdbc, nodeID, map[string]interface{}{"metadata": meta})
+	}); err != nil {
+		h.log.Error("PutPathNodeActivities failed (transaction)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "update_path_node_activities_failed", err)
 		return
 	}
 
-	joins, err := h.pathNodeActivity.GetByPathNodeIDs(dbctx.Context{Ctx: c.Request.Context()}, []uuid.UUID{nodeID})
+	items, code, err := h.listPathNodeActivityItems(dbc, rd.UserID, node)
 	if err != nil {
-		h.log.Error("ListPathNodeActivities failed (load joins)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_node_activities_failed", err)
+		h.log.Error("PutPathNodeActivities failed (reload)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, code, err)
 		return
 	}
+	response.RespondOK(c, gin.H{"activities": items})
+}
+
+// loadOwnedPathNode loads the node and checks the caller owns its path, responding on failure.
+func (h *PathHandler) loadOwnedPathNode(c *gin.Context, dbc dbctx.Context, op string, userID uuid.UUID, nodeID uuid.UUID) (*types.PathNode, bool) {
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error(op+" failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return nil, false
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return nil, false
+	}
+
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error(op+" failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return nil, false
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != userID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return nil, false
+	}
+	return node, true
+}
+
+// listPathNodeActivityItems returns the node's linked activities in join order (primary desc,
+// rank asc) with variant counts and the user's attempt summary. On error it also returns the
+// response code to use.
+func (h *PathHandler) listPathNodeActivityItems(dbc dbctx.Context, userID uuid.UUID, node *types.PathNode) ([]PathNodeActivityListItem, string, error) {
+	items := []PathNodeActivityListItem{}
+	joins, err := h.pathNodeActivity.GetByPathNodeIDs(dbc, []uuid.UUID{node.ID})
+	if err != nil {
+		return nil, "load_path_node_activities_failed", err
+	}
 	if len(joins) == 0 {
-		response.RespondOK(c, gin.H{"activities": []PathNodeActivityListItem{}})
-		return
+		return items, "", nil
 	}
 
 	activityIDs := make([]uuid.UUID, 0, len(joins))
@@ -80,31 +275,38 @@ func (h *PathHandler) ListPathNodeActivities(c *gin.Context) {
 		activityIDs = append(activityIDs, j.ActivityID)
 	}
 
-	activities, err := h.activities.GetByIDs(dbctx.Context{Ctx: c.Request.Context()}, activityIDs)
+	activities, err := h.activities.GetByIDs(dbc, activityIDs)
 	if err != nil {
-		h.log.Error("ListPathNodeActivities failed (load activities)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_activities_failed", err)
-		return
+		return nil, "load_activities_failed", err
 	}
-
-	// Preserve join order as returned by repo (primary desc, rank asc).
-	items := make([]PathNodeActivityListItem, 0, len(joins))
-	actByID := make(map[uuid.UUID]*typesActivityProxy, len(activities))
+	actByID := make(map[uuid.UUID]*types.Activity, len(activities))
 	for _, a := range activities {
 		if a == nil || a.ID == uuid.Nil {
 			continue
 		}
-		// Ownership guard: activities for this node must belong to this path.
-		if a.OwnerType != "path" || a.OwnerID == nil || *a.OwnerID != node.PathID {
+		// Ownership guard: activities for this node must belong to this path (or be canonical).
+		if !activityVisibleOnPath(a, node.PathID) {
 			continue
 		}
-		actByID[a.ID] = &typesActivityProxy{
-			ID:               a.ID,
-			Kind:             a.Kind,
-			Title:            a.Title,
-			EstimatedMinutes: a.EstimatedMinutes,
-			Difficulty:       a.Difficulty,
-			Status:           a.Status,
+		actByID[a.ID] = a
+	}
+
+	variantCounts := map[uuid.UUID]int{}
+	if h.activityVariants != nil {
+		if variantCounts, err = h.activityVariants.CountByActivityIDs(dbc, activityIDs); err != nil {
+			return nil, "load_activity_variants_failed", err
+		}
+	}
+	runByActivity := map[uuid.UUID]*types.ActivityRun{}
+	if h.activityRuns != nil {
+		runs, err := h.activityRuns.GetByUserAndActivityIDs(dbc, userID, activityIDs)
+		if err != nil {
+			return nil, "load_activity_runs_failed", err
+		}
+		for _, r := range runs {
+			if r != nil {
+				runByActivity[r.ActivityID] = r
+			}
 		}
 	}
 
@@ -116,30 +318,131 @@ func (h *PathHandler) ListPathNodeActivities(c *gin.Context) {
 		if act == nil {
 			continue
 		}
-		items = append(items, PathNodeActivityListItem{
+		item := PathNodeActivityListItem{
 			ID:                 act.ID,
 			PathNodeActivityID: j.ID,
 			PathNodeID:         j.PathNodeID,
 			Rank:               j.Rank,
 			IsPrimary:          j.IsPrimary,
+			Role:               j.Role,
 			Kind:               act.Kind,
 			Title:              act.Title,
 			EstimatedMinutes:   act.EstimatedMinutes,
 			Difficulty:         act.Difficulty,
 			Status:             act.Status,
-		})
+			VariantCount:       variantCounts[act.ID],
+		}
+		if run := runByActivity[act.ID]; run != nil {
+			item.Attempts = &PathNodeActivityAttemptInfo{
+				State:         string(run.State),
+				Attempts:      run.Attempts,
+				LastScore:     run.LastScore,
+				LastAttemptAt: run.LastAttemptAt,
+				CompletedAt:   run.CompletedAt,
+			}
+		}
+		items = append(items, item)
 	}
+	return items, "", nil
+}
 
-	response.RespondOK(c, gin.H{"activities": items})
+func activityVisibleOnPath(a *types.Activity, pathID uuid.UUID) bool {
+	switch a.OwnerType {
+	case "path":
+		return a.OwnerID != nil && *a.OwnerID == pathID
+	case activityOwnerCanonical:
+		return true
+	default:
+		return false
+	}
 }
 
-// Lightweight proxies so this handler doesn't need to depend on full domain types for response shaping.
-// (We intentionally keep the response schema small and stable for the frontend.)
-type typesActivityProxy struct {
-	ID               uuid.UUID
-	Kind             string
-	Title            string
-	EstimatedMinutes int
-	Difficulty       string
-	Status           string
+func normalizePathNodeActivityRole(raw string) (string, bool) {
+	role := strings.ToLower(strings.TrimSpace(raw))
+	switch role {
+	case "", PathNodeActivityRolePractice, PathNodeActivityRoleAssessment, PathNodeActivityRoleEnrichment:
+		return role, true
+	default:
+		return "", false
+	}
+}
+
+func pathNodeActivitiesMax() int {
+	n := envutil.Int("PATH_NODE_ACTIVITIES_MAX", 50)
+	if n < 1 {
+		n = 50
+	}
+	return n
+}
+
+// diffPathNodeActivities compares the current links to the requested set in effective order.
+// It returns nil when nothing would change.
+func diffPathNodeActivities(before []*types.PathNodeActivity, after []*types.PathNodeActivity) map[string]any {
+	prev := make([]*types.PathNodeActivity, 0, len(before))
+	prevByID := map[uuid.UUID]*types.PathNodeActivity{}
+	for _, j := range before {
+		if j != nil && j.ActivityID != uuid.Nil {
+			prev = append(prev, j)
+			prevByID[j.ActivityID] = j
+		}
+	}
+	nextByID := map[uuid.UUID]bool{}
+	added, removed, roles := []string{}, []string{}, []string{}
+	unchanged := len(prev) == len(after)
+	for i, row := range after {
+		nextByID[row.ActivityID] = true
+		old := prevByID[row.ActivityID]
+		if old == nil {
+			added = append(added, row.ActivityID.String())
+			unchanged = false
+			continue
+		}
+		if old.Role != row.Role {
+			roles = append(roles, row.ActivityID.String())
+		}
+		if unchanged && (prev[i].ActivityID != row.ActivityID || old.Rank != row.Rank || old.IsPrimary != row.IsPrimary) {
+			unchanged = false
+		}
+	}
+	for _, j := range prev {
+		if !nextByID[j.ActivityID] {
+			removed = append(removed, j.ActivityID.String())
+		}
+	}
+	if unchanged && len(roles) == 0 {
+		return nil
+	}
+	order := make([]string, 0, len(after))
+	for _, row := range after {
+		order = append(order, row.ActivityID.String())
+	}
+	return map[string]any{
+		"added":          added,
+		"removed":        removed,
+		"role_changed":   roles,
+		"reordered":      len(added) == 0 && len(removed) == 0 && !unchanged,
+		"activity_ids":   order,
+		"activity_count": len(after),
+	}
+}
+
+// appendPathNodeActivityRevision appends rev to metadata.activity_revisions, keeping the newest.
+func appendPathNodeActivityRevision(raw datatypes.JSON, rev map[string]any) (datatypes.JSON, error) {
+	meta := map[string]any{}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, err
+		}
+	}
+	revs, _ := meta["activity_revisions"].([]any)
+	revs = append(revs, rev)
+	if len(revs) > pathNodeActivityRevisionsKept {
+		revs = revs[len(revs)-pathNodeActivityRevisionsKept:]
+	}
+	meta["activity_revisions"] = revs
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(b), nil
 }
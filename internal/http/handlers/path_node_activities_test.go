@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestDiffPathNodeActivities(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	cur := []*types.PathNodeActivity{
+		{ActivityID: a, Rank: 0, IsPrimary: true, Role: "practice"},
+		{ActivityID: b, Rank: 1},
+	}
+	same := []*types.PathNodeActivity{
+		{ActivityID: a, Rank: 0, IsPrimary: true, Role: "practice"},
+		{ActivityID: b, Rank: 1},
+	}
+	if rev := diffPathNodeActivities(cur, same); rev != nil {
+		t.Fatalf("identical set produced revision %v", rev)
+	}
+	swapped := []*types.PathNodeActivity{
+		{ActivityID: b, Rank: 0, IsPrimary: true},
+		{ActivityID: a, Rank: 1, Role: "practice"},
+	}
+	if rev := diffPathNodeActivities(cur, swapped); rev == nil || rev["reordered"] != true {
+		t.Fatalf("swap revision = %v", rev)
+	}
+	c := uuid.New()
+	rev := diffPathNodeActivities(cur, []*types.PathNodeActivity{{ActivityID: c, Rank: 0, IsPrimary: true}})
+	if rev == nil || len(rev["added"].([]string)) != 1 || len(rev["removed"].([]string)) != 2 {
+		t.Fatalf("replace revision = %v", rev)
+	}
+}
+
+func TestPutPathNodeActivities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+
+	user := testutil.SeedUser(t, dbc, "node-activities@example.com")
+	other := testutil.SeedUser(t, dbc, "node-activities-other@example.com")
+	path := &types.Path{ID: uuid.New(), UserID: &user.ID, Title: "Loops"}
+	otherPath := &types.Path{ID: uuid.New(), UserID: &other.ID, Title: "Theirs"}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "For loops"}
+	acts := make([]*types.Activity, 3)
+	for i := range acts {
+		acts[i] = &types.Activity{ID: uuid.New(), OwnerType: "path", OwnerID: &path.ID, Kind: "drill", Title: "Drill " + string(rune('A'+i)), Status: "ready"}
+	}
+	canonical := &types.Activity{ID: uuid.New(), OwnerType: activityOwnerCanonical, Kind: "reading", Title: "Shared reading", Status: "ready"}
+	foreign := &types.Activity{ID: uuid.New(), OwnerType: "path", OwnerID: &otherPath.ID, Kind: "drill", Title: "Not yours", Status: "ready"}
+	seed := []any{path, otherPath, node, acts[0], acts[1], acts[2], canonical, foreign,
+		&types.ActivityVariant{ActivityID: acts[0].ID, Variant: "default"},
+		&types.ActivityVariant{ActivityID: acts[0].ID, Variant: "worked_example"},
+		&types.ActivityRun{UserID: user.ID, PathID: path.ID, NodeID: node.ID, ActivityID: acts[1].ID, State: "completed", Attempts: 2, LastScore: 0.8},
+	}
+	for _, row := range seed {
+		if err := tx.Create(row).Error; err != nil {
+			t.Fatalf("seed %T: %v", row, err)
+		}
+	}
+
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log: log,
+		DB:  tx,
+		Path: PathHandlerPathRepos{
+			Path:             repos.NewPathRepo(tx, log),
+			PathNodes:        repos.NewPathNodeRepo(tx, log),
+			PathNodeActivity: repos.NewPathNodeActivityRepo(tx, log),
+		},
+		Content: PathHandlerContentRepos{
+			Activities:       repos.NewActivityRepo(tx, log),
+			ActivityVariants: repos.NewActivityVariantRepo(tx, log),
+		},
+		Learning: PathHandlerLearningRepos{ActivityRuns: repos.NewActivityRunRepo(tx, log)},
+	})
+
+	call := func(method, body string, fn gin.HandlerFunc) (int, []PathNodeActivityListItem, string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(method, "/api/path-nodes/"+node.ID.String()+"/activities", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: user.ID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		fn(c)
+		var out struct {
+			Activities []PathNodeActivityListItem `json:"activities"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out.Activities, w.Body.String()
+	}
+	put := func(items ...string) (int, []PathNodeActivityListItem, string) {
+		return call(http.MethodPut, `{"activities":[`+strings.Join(items, ",")+`]}`, h.PutPathNodeActivities)
+	}
+	item := func(a *types.Activity, role string) string {
+		if role == "" {
+			return `{"activity_id":"` + a.ID.String() + `"}`
+		}
+		return `{"activity_id":"` + a.ID.String() + `","role":"` + role + `"}`
+	}
+	ids := func(items []PathNodeActivityListItem) []uuid.UUID {
+		out := make([]uuid.UUID, 0, len(items))
+		for _, it := range items {
+			out = append(out, it.ID)
+		}
+		return out
+	}
+	revisions := func() int {
+		var n types.PathNode
+		if err := tx.First(&n, "id = ?", node.ID).Error; err != nil {
+			t.Fatalf("load node: %v", err)
+		}
+		meta := map[string]any{}
+		_ = json.Unmarshal(n.Metadata, &meta)
+		revs, _ := meta["activity_revisions"].([]any)
+		return len(revs)
+	}
+
+	code, got, body := put(item(acts[2], "assessment"), item(acts[0], "practice"), item(canonical, "enrichment"))
+	if code != http.StatusOK {
+		t.Fatalf("put: status %d: %s", code, body)
+	}
+	want := []uuid.UUID{acts[2].ID, acts[0].ID, canonical.ID}
+	if g := ids(got); len(g) != 3 || g[0] != want[0] || g[1] != want[1] || g[2] != want[2] {
+		t.Fatalf("order = %v, want %v", g, want)
+	}
+	if got[0].Role != "assessment" || !got[0].IsPrimary || got[1].VariantCount != 2 || got[1].Title != "Drill A" {
+		t.Fatalf("items = %+v", got)
+	}
+	if revisions() != 1 {
+		t.Fatalf("revisions = %d, want 1", revisions())
+	}
+
+	// Idempotent: the same PUT keeps ids, ranks and records no new revision.
+	_, again, _ := put(item(acts[2], "assessment"), item(acts[0], "practice"), item(canonical, "enrichment"))
+	for i := range got {
+		if again[i].PathNodeActivityID != got[i].PathNodeActivityID || again[i].Rank != got[i].Rank {
+			t.Fatalf("repeat PUT changed row %d: %+v vs %+v", i, again[i], got[i])
+		}
+	}
+	if revisions() != 1 {
+		t.Fatalf("repeat PUT recorded a revision")
+	}
+
+	// Reorder + removal: ranks follow the new order and the removed activity survives.
+	code, got, body = put(item(acts[1], ""), item(acts[2], "assessment"))
+	if code != http.StatusOK {
+		t.Fatalf("reorder: status %d: %s", code, body)
+	}
+	if g := ids(got); len(g) != 2 || g[0] != acts[1].ID || g[1] != acts[2].ID || got[1].Rank != 1 {
+		t.Fatalf("reordered = %+v", got)
+	}
+	if got[0].Attempts == nil || got[0].Attempts.Attempts != 2 || got[0].Attempts.State != "completed" {
+		t.Fatalf("attempt summary = %+v", got[0].Attempts)
+	}
+	var survivors int64
+	tx.Model(&types.Activity{}).Where("id IN ?", []uuid.UUID{acts[0].ID, canonical.ID}).Count(&survivors)
+	if survivors != 2 || revisions() != 2 {
+		t.Fatalf("survivors=%d revisions=%d", survivors, revisions())
+	}
+	_, listed, _ := call(http.MethodGet, "", h.ListPathNodeActivities)
+	if g := ids(listed); len(g) != 2 || g[0] != acts[1].ID || g[1] != acts[2].ID {
+		t.Fatalf("GET order = %v", g)
+	}
+
+	// Another user's path-scoped activity is rejected and nothing changes.
+	if code, _, body := put(item(acts[1], ""), item(foreign, "")); code != http.StatusNotFound || !strings.Contains(body, "activity_not_found") {
+		t.Fatalf("foreign activity: status %d: %s", code, body)
+	}
+	if code, _, body := put(item(acts[1], "bogus")); code != http.StatusBadRequest || !strings.Contains(body, "invalid_role") {
+		t.Fatalf("bad role: status %d: %s", code, body)
+	}
+	t.Setenv("PATH_NODE_ACTIVITIES_MAX", "1")
+	if code, _, body := put(item(acts[1], ""), item(acts[2], "")); code != http.StatusBadRequest || !strings.Contains(body, "too_many_activities") {
+		t.Fatalf("over max: status %d: %s", code, body)
+	}
+	_, listed, _ = call(http.MethodGet, "", h.ListPathNodeActivities)
+	if len(listed) != 2 || revisions() != 2 {
+		t.Fatalf("rejected PUTs changed linkage: %d items, %d revisions", len(listed), revisions())
+	}
+}
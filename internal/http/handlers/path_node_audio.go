@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type nodeDocNarrateRequest struct {
+	Force bool `json:"force"`
+}
+
+type nodeAudioSegmentView struct {
+	Index      int    `json:"index"`
+	BlockID    string `json:"block_id,omitempty"`
+	BlockType  string `json:"block_type"`
+	Part       int    `json:"part"`
+	StartMS    int    `json:"start_ms"`
+	DurationMS int    `json:"duration_ms"`
+	MimeType   string `json:"mime_type"`
+	URL        string `json:"url"`
+}
+
+type audioProgressRequest struct {
+	PositionMS    int        `json:"position_ms"`
+	ClientEventID string     `json:"client_event_id"`
+	OccurredAt    *time.Time `json:"occurred_at,omitempty"`
+}
+
+// POST /api/path-nodes/:id/doc/narrate
+func (h *PathHandler) EnqueuePathNodeDocNarration(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_service_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req nodeDocNarrateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.RespondError(c, http.StatusBadRequest, "invalid_body", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("EnqueuePathNodeDocNarration failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("EnqueuePathNodeDocNarration failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("EnqueuePathNodeDocNarration failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+
+	// Repeated taps while a narration is in flight should not queue duplicate TTS work.
+	if h.jobs != nil {
+		if latest, err := h.jobs.GetLatestByEntity(dbc, rd.UserID, "path_node", nodeID, "node_doc_narrate"); err == nil && latest != nil {
+			if latest.Status == "queued" || latest.Status == "running" {
+				response.RespondOK(c, gin.H{"job_id": latest.ID, "already_running": true})
+				return
+			}
+		}
+	}
+
+	entityID := nodeID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "node_doc_narrate", "path_node", &entityID, map[string]any{
+		"path_node_id": nodeID.String(),
+		"force":        req.Force,
+	})
+	if err != nil {
+		h.log.Error("EnqueuePathNodeDocNarration failed (enqueue)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"job_id": job.ID})
+}
+
+// GET /api/path-nodes/:id/audio
+func (h *PathHandler) GetPathNodeAudio(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.nodeAudio == nil {
+		response.RespondError(c, http.StatusInternalServerError, "audio_repo_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeAudio failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("GetPathNodeAudio failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeAudio failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	contentHash := ""
+	if docRow != nil {
+		contentHash = strings.TrimSpace(docRow.ContentHash)
+	}
+
+	// Doc writes don't know about audio; invalidate lazily against the current hash.
+	if contentHash != "" {
+		if _, err := h.nodeAudio.MarkStale(dbc, nodeID, contentHash); err != nil {
+			h.log.Warn("GetPathNodeAudio: mark stale failed", "error", err, "path_node_id", nodeID)
+		}
+	}
+
+	rows, err := h.nodeAudio.ListByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeAudio failed (load audio)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_audio_failed", err)
+		return
+	}
+
+	status := nodeAudioStatus(rows, contentHash)
+	segments := make([]nodeAudioSegmentView, 0, len(rows))
+	totalMS := 0
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		segments = append(segments, nodeAudioSegmentView{
+			Index:      row.SegmentIndex,
+			BlockID:    row.BlockID,
+			BlockType:  row.BlockType,
+			Part:       row.Part,
+			StartMS:    row.StartMS,
+			DurationMS: row.DurationMS,
+			MimeType:   row.MimeType,
+			URL:        nodeAssetViewURL(h.assetViewBasePath(), nodeID, row.StorageKey),
+		})
+		if end := row.StartMS + row.DurationMS; end > totalMS {
+			totalMS = end
+		}
+	}
+
+	resp := gin.H{
+		"status":       status,
+		"content_hash": contentHash,
+		"duration_ms":  totalMS,
+		"segments":     segments,
+	}
+	if h.jobs != nil {
+		if latest, err := h.jobs.GetLatestByEntity(dbc, rd.UserID, "path_node", nodeID, "node_doc_narrate"); err == nil && latest != nil {
+			resp["job"] = gin.H{"id": latest.ID, "status": latest.Status, "stage": latest.Stage}
+		}
+	}
+
+	response.RespondOK(c, resp)
+}
+
+// POST /api/path-nodes/:id/audio/progress
+func (h *PathHandler) RecordPathNodeAudioProgress(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.events == nil {
+		response.RespondError(c, http.StatusInternalServerError, "event_service_missing", nil)
+		return
+	}
+	if h.nodeAudio == nil {
+		response.RespondError(c, http.StatusInternalServerError, "audio_repo_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1<<12)
+	var req audioProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.RespondError(c, http.StatusBadRequest, "invalid_body", err)
+		return
+	}
+	if req.PositionMS < 0 {
+		response.RespondError(c, http.StatusBadRequest, "invalid_position_ms", nil)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("RecordPathNodeAudioProgress failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("RecordPathNodeAudioProgress failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	rows, err := h.nodeAudio.ListByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("RecordPathNodeAudioProgress failed (load audio)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_audio_failed", err)
+		return
+	}
+	seg := audioSegmentAtPosition(rows, req.PositionMS)
+	if seg == nil {
+		response.RespondError(c, http.StatusNotFound, "audio_not_found", nil)
+		return
+	}
+	// The title/summary intro has no block to credit.
+	if strings.TrimSpace(seg.BlockID) == "" {
+		response.RespondOK(c, gin.H{"ok": true, "ingested": 0})
+		return
+	}
+
+	// Listening progress lands in the same block_viewed stream as reading, so block-level
+	// progress and the user model need no audio-specific path.
+	dwellMS := req.PositionMS - seg.StartMS
+	if dwellMS < 0 {
+		dwellMS = 0
+	}
+	if dwellMS > seg.DurationMS && seg.DurationMS > 0 {
+		dwellMS = seg.DurationMS
+	}
+	data := map[string]any{
+		"block_id":    seg.BlockID,
+		"block_type":  seg.BlockType,
+		"dwell_ms":    dwellMS,
+		"position_ms": req.PositionMS,
+		"source":      "audio_playback",
+	}
+
+	n, err := h.ingestBlockViewed(dbc, rd.UserID, node, strings.TrimSpace(req.ClientEventID), req.OccurredAt, data)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "event_ingest_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"ok": true, "ingested": n, "block_id": seg.BlockID})
+}
+
+func nodeAudioStatus(rows []*types.LearningNodeAudio, contentHash string) string {
+	if len(rows) == 0 {
+		return "none"
+	}
+	for _, row := range rows {
+		if row == nil || row.Status != repolearning.NodeAudioStatusReady {
+			return repolearning.NodeAudioStatusStale
+		}
+		if contentHash != "" && row.DocContentHash != contentHash {
+			return repolearning.NodeAudioStatusStale
+		}
+	}
+	return repolearning.NodeAudioStatusReady
+}
+
+// audioSegmentAtPosition maps a playback offset onto the segment being heard. Positions past
+// the end clamp to the last segment so a finished track credits the final block.
+func audioSegmentAtPosition(rows []*types.LearningNodeAudio, positionMS int) *types.LearningNodeAudio {
+	var last *types.LearningNodeAudio
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if positionMS >= row.StartMS && positionMS < row.StartMS+row.DurationMS {
+			return row
+		}
+		if last == nil || row.StartMS >= last.StartMS {
+			last = row
+		}
+	}
+	if last != nil && positionMS >= last.StartMS {
+		return last
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestAudioSegmentAtPosition(t *testing.T) {
+	rows := []*types.LearningNodeAudio{
+		{SegmentIndex: 0, BlockID: "", StartMS: 0, DurationMS: 1000},
+		{SegmentIndex: 1, BlockID: "p1", StartMS: 1000, DurationMS: 2000},
+		{SegmentIndex: 2, BlockID: "p1", Part: 1, StartMS: 3000, DurationMS: 500},
+		{SegmentIndex: 3, BlockID: "h2", StartMS: 3500, DurationMS: 1500},
+	}
+	cases := []struct {
+		pos  int
+		want int
+	}{
+		{pos: 0, want: 0},
+		{pos: 999, want: 0},
+		{pos: 1000, want: 1},
+		{pos: 3200, want: 2},
+		{pos: 4999, want: 3},
+		{pos: 60000, want: 3},
+	}
+	for _, tc := range cases {
+		got := audioSegmentAtPosition(rows, tc.pos)
+		if got == nil || got.SegmentIndex != tc.want {
+			t.Fatalf("pos=%d: got %+v, want segment %d", tc.pos, got, tc.want)
+		}
+	}
+	if got := audioSegmentAtPosition(nil, 10); got != nil {
+		t.Fatalf("expected nil for empty manifest, got %+v", got)
+	}
+}
+
+func TestNodeAudioStatus(t *testing.T) {
+	ready := []*types.LearningNodeAudio{{DocContentHash: "h1", Status: "ready"}}
+	if got := nodeAudioStatus(nil, "h1"); got != "none" {
+		t.Fatalf("empty: got %q", got)
+	}
+	if got := nodeAudioStatus(ready, "h1"); got != "ready" {
+		t.Fatalf("matching hash: got %q", got)
+	}
+	if got := nodeAudioStatus(ready, "h2"); got != "stale" {
+		t.Fatalf("changed hash: got %q", got)
+	}
+	if got := nodeAudioStatus([]*types.LearningNodeAudio{{DocContentHash: "h1", Status: "stale"}}, "h1"); got != "stale" {
+		t.Fatalf("stale row: got %q", got)
+	}
+}
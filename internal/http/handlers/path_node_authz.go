@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// authorizeNode loads a path node and its path and checks that the caller owns the path.
+// Errors are *apierr.Error carrying the status and code to respond with (see respondAPIError).
+// A path owned by someone else reports path_not_found, so node ids don't confirm existence.
+func (h *PathHandler) authorizeNode(c *gin.Context, nodeID uuid.UUID) (*types.PathNode, *types.Path, error) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusUnauthorized, "unauthorized", nil)
+	}
+	if nodeID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusBadRequest, "invalid_path_node_id", nil)
+	}
+	if h.pathNodes == nil || h.path == nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "path_repo_missing", nil)
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "load_node_failed", err)
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusNotFound, "node_not_found", nil)
+	}
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "load_path_failed", err)
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		return nil, nil, apierr.New(http.StatusNotFound, "path_not_found", nil)
+	}
+	return node, pathRow, nil
+}
+
+// respondAPIError writes an *apierr.Error as-is, logging server-side failures under op.
+// Any other error is logged and reported as a 500 with fallbackCode.
+func (h *PathHandler) respondAPIError(c *gin.Context, op string, err error, fallbackCode string, keysAndValues ...any) {
+	var ae *apierr.Error
+	if !errors.As(err, &ae) {
+		h.log.Error(op+" failed", append([]any{"error", err}, keysAndValues...)...)
+		response.RespondError(c, http.StatusInternalServerError, fallbackCode, err)
+		return
+	}
+	if ae.Status >= http.StatusInternalServerError {
+		h.log.Error(op+" failed ("+ae.Code+")", append([]any{"error", err}, keysAndValues...)...)
+	}
+	response.RespondError(c, ae.Status, ae.Code, ae.Err)
+}
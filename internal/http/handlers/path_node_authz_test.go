@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type failingPathNodeRepo struct{ fakePathNodeRepo }
+
+func (r *failingPathNodeRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.PathNode, error) {
+	return nil, errors.New("db down")
+}
+
+func TestAuthorizeNode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	owner, other := uuid.New(), uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &owner}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID}
+
+	call := func(h *PathHandler, userID uuid.UUID) (*types.PathNode, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/path-nodes/x/doc", nil)
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		got, _, err := h.authorizeNode(c, node.ID)
+		if err != nil {
+			h.respondAPIError(c, "TestAuthorizeNode", err, "load_node_failed")
+		}
+		return got, w
+	}
+	handler := func(nodes *fakePathNodeRepo, paths *fakePathRepo) *PathHandler {
+		return NewPathHandlerWithDeps(PathHandlerDeps{Log: log, Path: PathHandlerPathRepos{Path: paths, PathNodes: nodes}})
+	}
+
+	h := handler(&fakePathNodeRepo{node: node}, &fakePathRepo{path: path})
+	if got, w := call(h, owner); got != node || w.Body.Len() != 0 {
+		t.Fatalf("owner: node=%v body=%s", got, w.Body.String())
+	}
+	for name, tc := range map[string]struct {
+		h      *PathHandler
+		userID uuid.UUID
+		status int
+		code   string
+	}{
+		"anonymous":    {h, uuid.Nil, http.StatusUnauthorized, "unauthorized"},
+		"other user":   {h, other, http.StatusNotFound, "path_not_found"},
+		"missing node": {handler(&fakePathNodeRepo{}, &fakePathRepo{path: path}), owner, http.StatusNotFound, "node_not_found"},
+		"missing path": {handler(&fakePathNodeRepo{node: node}, &fakePathRepo{}), owner, http.StatusNotFound, "path_not_found"},
+		"load failure": {NewPathHandlerWithDeps(PathHandlerDeps{Log: log, Path: PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &failingPathNodeRepo{}}}), owner, http.StatusInternalServerError, "load_node_failed"},
+	} {
+		got, w := call(tc.h, tc.userID)
+		if got != nil || w.Code != tc.status || !strings.Contains(w.Body.String(), tc.code) {
+			t.Fatalf("%s: node=%v status=%d body=%s, want %d %s", name, got, w.Code, w.Body.String(), tc.status, tc.code)
+		}
+	}
+}
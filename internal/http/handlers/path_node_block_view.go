@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// Dwell beyond this is almost always an idle tab; clamp instead of rejecting.
+const maxBlockViewDwellMS = 30 * 60 * 1000
+
+type blockViewRequest struct {
+	BlockID       string     `json:"block_id"`
+	BlockType     string     `json:"block_type,omitempty"`
+	DwellMS       int        `json:"dwell_ms"`
+	ClientEventID string     `json:"client_event_id"`
+	OccurredAt    *time.Time `json:"occurred_at,omitempty"`
+}
+
+// POST /api/path-nodes/:id/doc/block-view
+func (h *PathHandler) RecordPathNodeBlockView(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.events == nil {
+		response.RespondError(c, http.StatusInternalServerError, "event_service_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1<<12)
+	var req blockViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.RespondError(c, http.StatusBadRequest, "invalid_body", err)
+		return
+	}
+	blockID := strings.TrimSpace(req.BlockID)
+	if blockID == "" {
+		response.RespondError(c, http.StatusBadRequest, "missing_block_id", nil)
+		return
+	}
+	if req.DwellMS < 0 {
+		response.RespondError(c, http.StatusBadRequest, "invalid_dwell_ms", nil)
+		return
+	}
+	if req.DwellMS > maxBlockViewDwellMS {
+		req.DwellMS = maxBlockViewDwellMS
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("RecordPathNodeBlockView failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("RecordPathNodeBlockView failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	data := map[string]any{
+		"block_id": blockID,
+		"dwell_ms": req.DwellMS,
+		"source":   "block_view_api",
+	}
+	if v := strings.TrimSpace(req.BlockType); v != "" {
+		data["block_type"] = v
+	}
+
+	n, err := h.ingestBlockViewed(dbc, rd.UserID, node, strings.TrimSpace(req.ClientEventID), req.OccurredAt, data)
+	if err != nil {
+		if errors.Is(err, services.ErrClientEventIDRequired) {
+			response.RespondError(c, http.StatusBadRequest, "client_event_id_required", err)
+			return
+		}
+		h.log.Error("RecordPathNodeBlockView failed (ingest event)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "event_ingest_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"ok": true, "ingested": n})
+}
+
+// ingestBlockViewed records a block_viewed event for an owned node and nudges the
+// user-model/runtime updates when it is new (client_event_id dedupes retries).
+func (h *PathHandler) ingestBlockViewed(dbc dbctx.Context, userID uuid.UUID, node *types.PathNode, clientEventID string, occurredAt *time.Time, data map[string]any) (int, error) {
+	if td := ctxutil.GetTraceData(dbc.Ctx); td != nil {
+		if v := strings.TrimSpace(td.TraceID); v != "" {
+			data["trace_id"] = v
+		}
+		if v := strings.TrimSpace(td.RequestID); v != "" {
+			data["request_id"] = v
+		}
+	}
+
+	// Session ID is attached by the event service from the request context.
+	res, err := h.events.Ingest(dbc, []services.EventInput{{
+		ClientEventID: clientEventID,
+		Type:          types.EventBlockViewed,
+		OccurredAt:    occurredAt,
+		PathID:        node.PathID.String(),
+		PathNodeID:    node.ID.String(),
+		Data:          data,
+	}})
+	if err != nil {
+		return 0, err
+	}
+	n := res.Accepted
+	if n > 0 && h.jobSvc != nil {
+		_, _, _ = h.jobSvc.EnqueueUserModelUpdateIfNeeded(dbc, userID, types.EventBlockViewed)
+		_, _, _ = h.jobSvc.EnqueueRuntimeUpdateIfNeeded(dbc, userID, types.EventBlockViewed)
+	}
+	return n, nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type recordingEventService struct {
+	inputs []services.EventInput
+	err    error
+}
+
+func (s *recordingEventService) Ingest(dbc dbctx.Context, inputs []services.EventInput) (*services.EventIngestResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.inputs = append(s.inputs, inputs...)
+	return &services.EventIngestResult{Accepted: len(inputs)}, nil
+}
+
+func TestRecordPathNodeBlockView(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	ownerID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &ownerID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID}
+
+	call := func(events *recordingEventService, nodeID string, body string) *httptest.ResponseRecorder {
+		h := NewPathHandlerWithDeps(PathHandlerDeps{
+			Log: log,
+			Path: PathHandlerPathRepos{
+				Path:      &fakePathRepo{path: path},
+				PathNodes: &sharePathNodeRepo{nodes: []*types.PathNode{node}},
+			},
+			Services: PathHandlerServices{Events: events},
+		})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodPost, "/api/path-nodes/"+nodeID+"/doc/block-view", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: ownerID}))
+		c.Params = gin.Params{{Key: "id", Value: nodeID}}
+		h.RecordPathNodeBlockView(c)
+		return w
+	}
+
+	events := &recordingEventService{}
+	w := call(events, node.ID.String(), `{"block_id":"b1","block_type":"paragraph","dwell_ms":99999999,"client_event_id":"`+uuid.NewString()+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("success: %d %s", w.Code, w.Body.String())
+	}
+	if len(events.inputs) != 1 || events.inputs[0].Type != types.EventBlockViewed || events.inputs[0].PathNodeID != node.ID.String() {
+		t.Fatalf("ingested: %+v", events.inputs)
+	}
+	if got := events.inputs[0].Data["dwell_ms"]; got != maxBlockViewDwellMS {
+		t.Fatalf("dwell_ms = %v, want clamped to %d", got, maxBlockViewDwellMS)
+	}
+
+	for name, tc := range map[string]struct{ nodeID, body string }{
+		"bad node id":      {"not-a-uuid", `{"block_id":"b1"}`},
+		"bad json":         {node.ID.String(), `{"block_id":`},
+		"missing block id": {node.ID.String(), `{"dwell_ms":10}`},
+		"negative dwell":   {node.ID.String(), `{"block_id":"b1","dwell_ms":-1}`},
+	} {
+		events := &recordingEventService{}
+		if w := call(events, tc.nodeID, tc.body); w.Code != http.StatusBadRequest || len(events.inputs) != 0 {
+			t.Fatalf("%s: %d ingested=%d", name, w.Code, len(events.inputs))
+		}
+	}
+
+	if w := call(&recordingEventService{err: services.ErrClientEventIDRequired}, node.ID.String(), `{"block_id":"b1"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing client_event_id: %d", w.Code)
+	}
+	w = call(&recordingEventService{err: errors.New("db down")}, node.ID.String(), `{"block_id":"b1"}`)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "event_ingest_failed") {
+		t.Fatalf("ingest failure: %d %s", w.Code, w.Body.String())
+	}
+}
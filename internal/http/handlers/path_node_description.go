@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const pathNodeDescriptionMaxRunes = 1000
+
+type putPathNodeDescriptionRequest struct {
+	Description string `json:"description"`
+}
+
+// PUT /api/path-nodes/:id/description
+//
+// Sets the node's description as user-edited, which the description sync never overwrites.
+// An empty description drops the edit: the outline falls back to the build goal and the sync
+// may derive one from the doc again.
+func (h *PathHandler) PutPathNodeDescription(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "PutPathNodeDescription"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req putPathNodeDescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+	desc := strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(desc) > pathNodeDescriptionMaxRunes {
+		response.RespondError(c, http.StatusBadRequest, "description_too_long", nil)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("PutPathNodeDescription failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("PutPathNodeDescription failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	source := types.PathNodeDescriptionSourceUser
+	if desc == "" {
+		source = types.PathNodeDescriptionSourceBuild
+	}
+	// The user's write is unconditional, so only a vanished node stops it.
+	updated, err := h.pathNodes.SetDescription(dbc, nodeID, desc, source, nil)
+	if err != nil {
+		h.log.Error("PutPathNodeDescription failed (update)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "update_node_failed", err)
+		return
+	}
+	if !updated {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	if h.jobSvc != nil {
+		entityID := node.PathID
+		payload := map[string]any{"path_id": node.PathID.String(), "path_node_id": nodeID.String()}
+		jobTypes := []string{"chat_path_index"}
+		if desc == "" {
+			jobTypes = append(jobTypes, "node_description_sync")
+		}
+		for _, jobType := range jobTypes {
+			if _, err := h.jobSvc.Enqueue(dbc, rd.UserID, jobType, "path", &entityID, payload); err != nil {
+				h.log.Warn("PutPathNodeDescription: enqueue failed", "error", err, "job_type", jobType, "path_node_id", nodeID)
+			}
+		}
+	}
+
+	response.RespondOK(c, gin.H{
+		"path_node_id":       nodeID,
+		"description":        desc,
+		"description_source": source,
+	})
+}
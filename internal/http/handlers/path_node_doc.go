@@ -7,8 +7,8 @@ import (
 	"hash/fnv"
 	"net/http"
 	"net/url"
+	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
@@ -17,17 +17,23 @@ import (
 	"gorm.io/datatypes"
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/pagination"
 	"github.com/yungbote/neurobridge-backend/internal/http/response"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
 	docgen "github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/metabound"
 )
 
 // GET /api/path-nodes/:id/doc
 func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "GetPathNodeDoc"))
 	rd := ctxutil.GetRequestData(c.Request.Context())
 	if rd == nil || rd.UserID == uuid.Nil {
 		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
@@ -40,29 +46,24 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	node, pathRow, err := h.authorizeNode(c, nodeID)
 	if err != nil {
-		h.log.Error("GetPathNodeDoc failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		h.respondAPIError(c, "GetPathNodeDoc", err, "load_node_failed", "path_node_id", nodeID)
 		return
 	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
-		return
+	c.Request = c.Request.WithContext(ctxutil.WithFlagSubject(c.Request.Context(), ctxutil.FlagSubject{UserID: rd.UserID, PathID: node.PathID}))
+	if h.docCache != nil {
+		h.docCache.SyncPath(pathRow)
 	}
 
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	trackChoice, err := h.resolveNodeDocTrack(c, rd.UserID)
 	if err != nil {
-		h.log.Error("GetPathNodeDoc failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
-		return
-	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		h.respondAPIError(c, "GetPathNodeDoc", err, "invalid_track", "path_node_id", nodeID)
 		return
 	}
-
-	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	// A track that hasn't been generated falls back to primary; only a missing primary doc
+	// triggers on-demand generation.
+	docRow, err := h.loadNodeDocForTrack(c.Request.Context(), nodeID, &trackChoice)
 	if err != nil {
 		h.log.Error("GetPathNodeDoc failed (load doc)", "error", err, "path_node_id", nodeID)
 		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
@@ -70,6 +71,10 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 	}
 	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
 		status := h.ensureNodeDocOnDemand(c.Request.Context(), rd.UserID, node, pathRow)
+		if status.jobCap != nil {
+			respondTooManyJobs(c, *status.jobCap, gin.H{"doc": nil, "doc_status": status})
+			return
+		}
 		c.JSON(http.StatusAccepted, gin.H{
 			"doc":        nil,
 			"doc_status": status,
@@ -99,12 +104,13 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 		if rawDoc, err := json.Marshal(baseDoc); err == nil {
 			if canon, cErr := content.CanonicalizeJSON(rawDoc); cErr == nil {
 				now := time.Now().UTC()
-				contentHash := content.HashBytes(canon)
+				contentHash := content.NodeDocContentHash(canon)
 				updated := &types.LearningNodeDoc{
 					ID:            docRow.ID,
 					UserID:        docRow.UserID,
 					PathID:        docRow.PathID,
 					PathNodeID:    docRow.PathNodeID,
+					Track:         docRow.Track,
 					SchemaVersion: docRow.SchemaVersion,
 					DocJSON:       datatypes.JSON(canon),
 					DocText:       docRow.DocText,
@@ -113,20 +119,34 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 					CreatedAt:     docRow.CreatedAt,
 					UpdatedAt:     now,
 				}
-				_ = h.nodeDocs.Upsert(dbctx.Context{Ctx: c.Request.Context()}, updated)
+				// Best-effort write-back: on ErrStaleDoc another writer won; drop ours and let the
+				// next read re-derive IDs/fallbacks from the newer doc.
+				_ = h.nodeDocs.UpdateWithVersion(dbctx.Context{Ctx: c.Request.Context()}, updated, docRow.Version)
 				baseContentHash = contentHash
 			}
 		}
 	}
 
-	variantRow, variantDoc, variantContentHash, variantReady := h.loadDocVariant(c, rd.UserID, nodeID)
+	// A frozen doc is always served as its base; variants are not even loaded. Variants are built
+	// from the primary doc, so other tracks skip them too.
+	var (
+		variantRow         *types.LearningNodeDocVariant
+		variantDoc         content.NodeDocV1
+		variantContentHash string
+		variantReady       bool
+	)
+	if !docRow.Frozen && trackChoice.Served == types.NodeDocTrackPrimary {
+		variantRow, variantDoc, variantContentHash, variantReady = h.loadDocVariant(c, rd.UserID, nodeID)
+	}
 
-	policyMode := docgen.DocVariantPolicyMode()
-	rolloutPct := docgen.DocVariantRolloutPct()
-	eligible := rolloutEligible(rd.UserID, rolloutPct)
+	policy := docgen.DocPolicy(c.Request.Context())
+	policyMode, policyModeFlag := policy.ModeFor(c.Request.Context())
+	rolloutPct := policy.RolloutPct
+	assignment := h.resolveDocVariantAssignment(c.Request.Context(), rd.UserID, policyMode, rolloutPct)
+	eligible := assignment.Eligible
 	safe := true
-	if policyMode == "active" && docgen.DocVariantRequireSafe() {
-		safe = docVariantPolicySafe(c.Request.Context(), h.policyEval)
+	if policyMode == "active" && policy.RequireSafe {
+		safe = h.docVariantPolicySafe(c.Request.Context(), policy)
 	}
 
 	servedDoc := baseDoc
@@ -135,7 +155,7 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 	exposureDoc := baseDoc
 	exposureContentHash := baseContentHash
 	exposureKind := "base"
-	exposurePolicyVersion := docgen.DocPolicyVersion()
+	exposurePolicyVersion := policy.PolicyVersion
 	exposureVariantKind := "base"
 	var exposureVariantID *uuid.UUID
 	baseDocID := docRow.ID
@@ -147,9 +167,16 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 		"policy_mode":      policyMode,
 		"rollout_pct":      rolloutPct,
 		"rollout_eligible": eligible,
-		"safe_required":    docgen.DocVariantRequireSafe(),
+		"safe_required":    policy.RequireSafe,
 		"safe_to_activate": safe,
 	}
+	if docRow.Frozen {
+		candidateMeta["doc_frozen"] = true
+	}
+	trackChoice.annotate(candidateMeta)
+	policyModeFlag.Annotate(candidateMeta)
+	assignment.annotate(candidateMeta)
+	focusNotice := nodeDocFocusNotice(h.activePathFocus(c.Request.Context(), rd.UserID, node.PathID), node, extractDocConceptKeys(baseDoc), candidateMeta)
 
 	if variantReady {
 		candidatePolicyVersion := strings.TrimSpace(variantRow.PolicyVersion)
@@ -194,9 +221,13 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 
 	// Generated figures are stored in a private bucket; rewrite figure URLs to a protected streaming endpoint.
 	// This avoids mixed public/private bucket configs and prevents stale/signed URLs from breaking the UI.
-	if withAssetURLs, changed := h.rewriteNodeDocFigureAssetURLs(servedDoc, nodeID); changed {
+	if withAssetURLs, changed := h.rewriteNodeDocAssetURLs(servedDoc, nodeID); changed {
 		servedDoc = withAssetURLs
 	}
+	if _, err := content.OpenUserBlocks(c.Request.Context(), &servedDoc, h.fieldCrypt); err != nil {
+		h.log.Warn("open sealed user notes failed; serving placeholders", "node_id", nodeID, "error", err)
+	}
+	servedDoc, validation := nodeDocRichTextStatus(servedDoc)
 
 	var prereqGate *types.PrereqGateDecision
 	var gateEvidence prereqGateEvidence
@@ -218,6 +249,13 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 		return
 	}
 
+	suppressCallouts := false
+	if prereqGate != nil {
+		var ev featureflag.Evaluation
+		suppressCallouts, ev = prereqGateCalloutsSuppressed(c.Request.Context())
+		ev.Annotate(candidateMeta)
+	}
+
 	if h.docVariantExposure != nil {
 		h.logDocVariantExposure(
 			c,
@@ -235,17 +273,74 @@ func (h *PathHandler) GetPathNodeDoc(c *gin.Context) {
 		)
 	}
 
-	if prereqGate != nil {
+	if prereqGate != nil && !suppressCallouts {
 		if patched, changed := injectPrereqGateCallout(servedDoc, gateEvidence); changed {
 			servedDoc = patched
 		}
 	}
+	emphasisProfile, _ := content.NodeDocEmphasisOf(docRow.Metadata)
+	notices := nodeDocContentNotices(docRow.Metadata)
+	if focusNotice != nil {
+		notices = append(notices, *focusNotice)
+	}
+	var availableTracks []string
+	if h.docCache != nil {
+		availableTracks, err = h.docCache.NodeDocTracks(dbctx.Context{Ctx: c.Request.Context()}, node.PathID, nodeID)
+	} else {
+		availableTracks, err = h.nodeDocs.ListTracksByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	}
+	if err != nil {
+		h.log.Warn("GetPathNodeDoc: list doc tracks failed", "error", err, "path_node_id", nodeID)
+	}
 	response.RespondOK(c, gin.H{
-		"doc":         servedDoc,
-		"prereq_gate": prereqGate,
+		"doc":             servedDoc,
+		"prereq_gate":     prereqGate,
+		"content_notices": notices,
+		"doc_status": nodeDocStatus{
+			State:           "ready",
+			PathID:          nodePathIDString(node),
+			PathNodeID:      nodeIDString(node),
+			Validation:      validation,
+			Frozen:          docRow.Frozen,
+			SummaryStale:    content.NodeDocSummaryStale(docRow.Metadata),
+			SourcesStale:    content.NodeDocSourcesStale(docRow.Metadata),
+			EmphasisProfile: emphasisProfile,
+			Track:           trackChoice.Served,
+			RequestedTrack:  trackChoice.Requested,
+			TrackFallback:   trackChoice.Fallback,
+			AvailableTracks: availableTracks,
+		},
 	})
 }
 
+// maxNodeDocValidationIssues caps how many unrepaired issues the doc endpoint returns.
+const maxNodeDocValidationIssues = 20
+
+// nodeDocRichTextStatus applies the mechanical rich-text repairs to the doc being served (docs
+// stored before the validation pass can still carry dangling fences or $$) and reports what is
+// left, so the client can show a "some content may not render correctly" notice. Provenance lint
+// findings ride along as warnings without changing the state.
+func nodeDocRichTextStatus(doc content.NodeDocV1) (content.NodeDocV1, *nodeDocValidationStatus) {
+	doc, report := content.ValidateNodeDocRichText(doc)
+	status := &nodeDocValidationStatus{State: "ok", Repaired: len(report.Repairs)}
+	if status.Repaired > 0 {
+		status.State = "repaired"
+	}
+	if !report.Clean() {
+		status.State = "degraded"
+		status.IssueCount = len(report.Issues)
+		status.Issues = report.Issues
+		if len(status.Issues) > maxNodeDocValidationIssues {
+			status.Issues = status.Issues[:maxNodeDocValidationIssues]
+		}
+	}
+	status.Warnings = content.LintNodeDocProvenance(doc)
+	if len(status.Warnings) > maxNodeDocValidationIssues {
+		status.Warnings = status.Warnings[:maxNodeDocValidationIssues]
+	}
+	return doc, status
+}
+
 func ensureNodeDocInteractiveFallback(doc content.NodeDocV1) (content.NodeDocV1, bool) {
 	if len(doc.Blocks) == 0 {
 		return doc, false
@@ -433,6 +528,40 @@ type docVariantBaseline struct {
 	AleatoricUncertainty float64 `json:"aleatoric_uncertainty,omitempty"`
 }
 
+// nodeDocContentNotice tells the reader that something the doc builds on changed after it was
+// written (the doc's concept_stale metadata), or that the unit is outside their current focus;
+// for focus notices ChangedAt is when the focus started.
+type nodeDocContentNotice struct {
+	Kind        string    `json:"kind"`
+	ConceptID   string    `json:"concept_id,omitempty"`
+	ConceptKey  string    `json:"concept_key,omitempty"`
+	ConceptName string    `json:"concept_name,omitempty"`
+	ChangeKinds []string  `json:"change_kinds,omitempty"`
+	FocusID     string    `json:"focus_id,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+	Message     string    `json:"message"`
+}
+
+func nodeDocContentNotices(meta []byte) []nodeDocContentNotice {
+	out := make([]nodeDocContentNotice, 0)
+	for _, c := range content.NodeDocConceptStale(meta) {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			name = c.Key
+		}
+		out = append(out, nodeDocContentNotice{
+			Kind:        "concept_updated",
+			ConceptID:   c.ConceptID,
+			ConceptKey:  c.Key,
+			ConceptName: strings.TrimSpace(c.Name),
+			ChangeKinds: c.Kinds,
+			ChangedAt:   c.ChangedAt,
+			Message:     fmt.Sprintf("The concept map for %s was updated after this unit was written.", name),
+		})
+	}
+	return out
+}
+
 type nodeDocJobStatus struct {
 	JobType   string `json:"job_type"`
 	JobID     string `json:"job_id,omitempty"`
@@ -445,12 +574,39 @@ type nodeDocJobStatus struct {
 }
 
 type nodeDocStatus struct {
-	State         string             `json:"state"`
-	Reason        string             `json:"reason,omitempty"`
-	PathID        string             `json:"path_id,omitempty"`
-	PathNodeID    string             `json:"path_node_id,omitempty"`
-	MaterialSetID string             `json:"material_set_id,omitempty"`
-	Jobs          []nodeDocJobStatus `json:"jobs,omitempty"`
+	State         string                   `json:"state"`
+	Reason        string                   `json:"reason,omitempty"`
+	PathID        string                   `json:"path_id,omitempty"`
+	PathNodeID    string                   `json:"path_node_id,omitempty"`
+	MaterialSetID string                   `json:"material_set_id,omitempty"`
+	Jobs          []nodeDocJobStatus       `json:"jobs,omitempty"`
+	Validation    *nodeDocValidationStatus `json:"validation,omitempty"`
+	Frozen        bool                     `json:"frozen,omitempty"`
+	SummaryStale  bool                     `json:"summary_stale,omitempty"`
+	SourcesStale  bool                     `json:"sources_stale,omitempty"`
+	// EmphasisProfile is the profile the doc was last regenerated with.
+	EmphasisProfile string `json:"emphasis_profile,omitempty"`
+
+	// Track is the doc track served; RequestedTrack is the one asked for (query or preference).
+	// TrackFallback is set when the requested track has no doc yet and primary was served.
+	Track           string   `json:"track,omitempty"`
+	RequestedTrack  string   `json:"requested_track,omitempty"`
+	TrackFallback   bool     `json:"track_fallback,omitempty"`
+	AvailableTracks []string `json:"available_tracks,omitempty"`
+
+	// jobCap is set when on-demand generation was skipped because the user is at the job cap.
+	jobCap *docGenJobCap
+}
+
+// nodeDocValidationStatus is the rich-text validation state of the served doc: "ok", "repaired"
+// (fixed on read), or "degraded" when some blocks may not render correctly.
+type nodeDocValidationStatus struct {
+	State      string                  `json:"state"`
+	Repaired   int                     `json:"repaired,omitempty"`
+	IssueCount int                     `json:"issue_count,omitempty"`
+	Issues     []content.RichTextIssue `json:"issues,omitempty"`
+	// Warnings are lint findings that don't affect rendering (e.g. blocks missing provenance).
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 func (h *PathHandler) ensureNodeDocOnDemand(ctx context.Context, userID uuid.UUID, node *types.PathNode, pathRow *types.Path) nodeDocStatus {
@@ -561,6 +717,7 @@ func (h *PathHandler) ensureNodeDocOnDemand(ctx context.Context, userID uuid.UUI
 		specs[i].trigger = true
 	}
 
+	var jobCap *docGenJobCap
 	for i := range specs {
 		latest := specs[i].latest
 		if latest != nil && isRunnableJobStatus(strings.TrimSpace(strings.ToLower(latest.Status))) {
@@ -569,6 +726,13 @@ func (h *PathHandler) ensureNodeDocOnDemand(ctx context.Context, userID uuid.UUI
 		if !specs[i].trigger {
 			continue
 		}
+		if jobCap == nil {
+			checked := h.checkDocGenJobCap(ctx, userID)
+			jobCap = &checked
+		}
+		if jobCap.Reached() {
+			continue
+		}
 		job, created, err := specs[i].enqueue()
 		if err != nil {
 			specs[i].err = err
@@ -609,6 +773,10 @@ func (h *PathHandler) ensureNodeDocOnDemand(ctx context.Context, userID uuid.UUI
 	case queuedOrRunning:
 		status.State = "pending"
 		status.Reason = "build_in_progress"
+	case jobCap != nil && jobCap.Reached():
+		status.State = "pending"
+		status.Reason = "too_many_jobs"
+		status.jobCap = jobCap
 	case anyErr:
 		status.State = "error"
 		status.Reason = "enqueue_failed"
@@ -717,7 +885,7 @@ func (h *PathHandler) loadDocVariant(c *gin.Context, userID, nodeID uuid.UUID) (
 	row, err := h.docVariants.GetLatestByUserAndNode(dbctx.Context{Ctx: c.Request.Context()}, userID, nodeID)
 	if err != nil || row == nil {
 		if err != nil && h.log != nil {
-			h.log.Warn("GetPathNodeDoc failed (load variant)", "error", err, "path_node_id", nodeID)
+			h.log.WarnThrottled("GetPathNodeDoc.load_variant", time.Minute, "GetPathNodeDoc failed (load variant)", "error", err, "path_node_id", nodeID)
 		}
 		return nil, empty, "", false
 	}
@@ -742,7 +910,7 @@ func (h *PathHandler) loadDocVariant(c *gin.Context, userID, nodeID uuid.UUID) (
 		if rawDoc, err := json.Marshal(doc); err == nil {
 			if canon, cErr := content.CanonicalizeJSON(rawDoc); cErr == nil {
 				now := time.Now().UTC()
-				contentHash = content.HashBytes(canon)
+				contentHash = content.NodeDocContentHash(canon)
 				updated := &types.LearningNodeDocVariant{
 					ID:              row.ID,
 					UserID:          row.UserID,
@@ -837,10 +1005,8 @@ func (h *PathHandler) logDocVariantExposure(
 			exposure.BaselineJSON = datatypes.JSON(b)
 		}
 	}
-	if metadata != nil {
-		if b, err := json.Marshal(metadata); err == nil {
-			exposure.Metadata = datatypes.JSON(b)
-		}
+	if b := docVariantExposureMeta.JSON(metadata); b != nil {
+		exposure.Metadata = datatypes.JSON(b)
 	}
 	_ = h.docVariantExposure.Create(dbctx.Context{Ctx: ctx}, exposure)
 }
@@ -851,7 +1017,13 @@ func (h *PathHandler) resolveConceptIDs(ctx context.Context, pathID uuid.UUID, k
 	if h == nil || h.concepts == nil || pathID == uuid.Nil || len(keys) == 0 {
 		return out, idToKey
 	}
-	rows, err := h.concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
+	var rows []*types.Concept
+	var err error
+	if h.docCache != nil {
+		rows, err = h.docCache.PathConcepts(dbctx.Context{Ctx: ctx}, pathID)
+	} else {
+		rows, err = h.concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
+	}
 	if err != nil || len(rows) == 0 {
 		return out, idToKey
 	}
@@ -908,31 +1080,117 @@ func (h *PathHandler) buildConceptBaseline(ctx context.Context, userID uuid.UUID
 	return out
 }
 
-func docVariantPolicySafe(ctx context.Context, evals repos.PolicyEvalSnapshotRepo) bool {
+func (h *PathHandler) docVariantPolicySafe(ctx context.Context, policy docgen.DocPolicyConfig) bool {
+	if h.docCache != nil {
+		snap, err := h.docCache.PolicySnapshot(dbctx.Context{Ctx: ctx}, policy.PolicyKey)
+		return err == nil && policy.SnapshotSafe(snap)
+	}
+	return docVariantPolicySafe(ctx, h.policyEval, policy)
+}
+
+func docVariantPolicySafe(ctx context.Context, evals repos.PolicyEvalSnapshotRepo, policy docgen.DocPolicyConfig) bool {
 	if evals == nil {
 		return false
 	}
-	snap, err := evals.GetLatestByKey(dbctx.Context{Ctx: ctx}, docgen.DocVariantPolicyKey())
+	snap, err := evals.GetLatestByKey(dbctx.Context{Ctx: ctx}, policy.PolicyKey)
 	if err != nil || snap == nil {
 		return false
 	}
-	return docVariantSnapshotSafe(snap)
+	return policy.SnapshotSafe(snap)
 }
 
-func docVariantSnapshotSafe(snap *types.PolicyEvalSnapshot) bool {
-	if snap == nil {
-		return false
+// docVariantExposureMeta bounds the metadata stored on exposure rows. Keys missing from the
+// allowlist are dropped (and counted), so new candidateMeta keys must be added here.
+var docVariantExposureMeta = metabound.New(
+	"policy_mode",
+	"rollout_pct",
+	"rollout_eligible",
+	"safe_required",
+	"safe_to_activate",
+	"doc_frozen",
+	"doc_track",
+	"requested_track",
+	"track_source",
+	"track_fallback",
+	"assignment_source",
+	"assignment_arm",
+	"assignment_rollout_pct",
+	"assigned_at",
+	"candidate_variant_id",
+	"candidate_variant_kind",
+	"candidate_policy_version",
+	"candidate_status",
+	"served_variant",
+	"exposure_kind",
+	"aligned_blocks",
+	"variant_blocks",
+	"diverged",
+	"flags",
+)
+
+type docVariantAssignment struct {
+	Eligible bool
+	Source   string
+	Arm      string
+	// Pct is the rollout pct the arm was assigned under, which may differ from the current one.
+	Pct        float64
+	AssignedAt time.Time
+}
+
+func (a docVariantAssignment) annotate(meta map[string]any) {
+	meta["assignment_source"] = a.Source
+	if a.Arm != "" {
+		meta["assignment_arm"] = a.Arm
+		meta["assignment_rollout_pct"] = a.Pct
 	}
-	if snap.Samples < docgen.DocVariantSafeMinSamples() {
-		return false
+	if !a.AssignedAt.IsZero() {
+		meta["assigned_at"] = a.AssignedAt.UTC().Format(time.RFC3339)
 	}
-	if snap.IPS < docgen.DocVariantSafeMinIPS() {
-		return false
+}
+
+// resolveDocVariantAssignment returns the user's persisted arm for the current doc variant policy,
+// creating it on first contact. The hash only seeds new rows: once assigned, the arm survives
+// rollout pct changes until the policy key changes. Users exposed before assignments existed
+// were pinned by the one-time exposure backfill that runs with the migrations.
+func (h *PathHandler) resolveDocVariantAssignment(ctx context.Context, userID uuid.UUID, policyMode string, pct float64) docVariantAssignment {
+	hashed := docVariantAssignment{Eligible: rolloutEligible(userID, pct), Source: "hash_stateless"}
+	if h.docAssignments == nil || policyMode == "off" || userID == uuid.Nil {
+		return hashed
 	}
-	if snap.Lift < docgen.DocVariantSafeMinLift() {
-		return false
+	dbc := dbctx.Context{Ctx: ctx}
+	policyVersion := docgen.DocPolicy(ctx).PolicyKey
+	fallback := func(err error) docVariantAssignment {
+		h.log.WarnThrottled("GetPathNodeDoc.variant_assignment", time.Minute, "doc variant assignment failed; using hash", "error", err, "user_id", userID)
+		hashed.Source = "hash_fallback"
+		return hashed
+	}
+
+	row, err := h.docAssignments.Get(dbc, userID, policyVersion)
+	if err != nil {
+		return fallback(err)
+	}
+	if row == nil {
+		row = &types.DocVariantAssignment{
+			UserID:        userID,
+			PolicyVersion: policyVersion,
+			Arm:           repos.DocVariantArmHoldback,
+			RolloutPct:    pct,
+			Source:        repos.DocVariantAssignmentSourceHash,
+		}
+		if hashed.Eligible {
+			row.Arm = repos.DocVariantArmTreatment
+		}
+		if row, _, err = h.docAssignments.Assign(dbc, row); err != nil {
+			return fallback(err)
+		}
+	}
+	return docVariantAssignment{
+		Eligible:   row.Arm == repos.DocVariantArmTreatment,
+		Source:     row.Source,
+		Arm:        row.Arm,
+		Pct:        row.RolloutPct,
+		AssignedAt: row.AssignedAt,
 	}
-	return true
 }
 
 func rolloutEligible(userID uuid.UUID, pct float64) bool {
@@ -942,21 +1200,23 @@ func rolloutEligible(userID uuid.UUID, pct float64) bool {
 	if pct <= 0 || userID == uuid.Nil {
 		return false
 	}
+	return rolloutBucket(userID) < pct
+}
+
+// rolloutBucket is the user's stable position in [0, 1) for rollout pct comparisons.
+func rolloutBucket(userID uuid.UUID) float64 {
 	h := fnv.New32a()
 	_, _ = h.Write([]byte(userID.String()))
-	val := float64(h.Sum32()%10000) / 10000.0
-	return val < pct
+	return float64(h.Sum32()%10000) / 10000.0
 }
 
 func extractDocConceptKeys(doc content.NodeDocV1) []string {
 	keys := make([]string, 0, len(doc.ConceptKeys))
 	keys = append(keys, doc.ConceptKeys...)
-	for _, block := range doc.Blocks {
-		if block == nil {
-			continue
-		}
-		keys = append(keys, stringSliceFromAny(block["concept_keys"])...)
-		payload, ok := block["payload"].(map[string]any)
+	for _, block := range content.DecodeBlocks(doc) {
+		h := block.Header()
+		keys = append(keys, h.ConceptKeys...)
+		payload, ok := h.Extra["payload"].(map[string]any)
 		if !ok || payload == nil {
 			continue
 		}
@@ -1008,10 +1268,6 @@ func (h *PathHandler) ViewPathNodeAsset(c *gin.Context) {
 		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
-	if h.pathNodes == nil || h.path == nil {
-		response.RespondError(c, http.StatusInternalServerError, "path_repo_missing", nil)
-		return
-	}
 	if h.bucket == nil {
 		response.RespondError(c, http.StatusInternalServerError, "bucket_unavailable", nil)
 		return
@@ -1028,39 +1284,57 @@ func (h *PathHandler) ViewPathNodeAsset(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	node, _, err := h.authorizeNode(c, nodeID)
 	if err != nil {
-		h.log.Error("ViewPathNodeAsset failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
-		return
-	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		h.respondAPIError(c, "ViewPathNodeAsset", err, "load_node_failed", "path_node_id", nodeID)
 		return
 	}
 
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	allowed, err := h.nodeAssetKeyAllowed(c.Request.Context(), node, storageKey, true)
 	if err != nil {
-		h.log.Error("ViewPathNodeAsset failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		h.log.Error("ViewPathNodeAsset failed (load figures)", "error", err, "path_node_id", node.ID)
+		response.RespondError(c, http.StatusInternalServerError, "load_figures_failed", err)
 		return
 	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+	if !allowed {
+		response.RespondError(c, http.StatusNotFound, "asset_not_found", nil)
 		return
 	}
+	h.streamNodeAsset(c, storageKey, "ViewPathNodeAsset")
+}
 
-	// Prevent arbitrary bucket reads: only allow generated node figure assets for this node.
-	allowedPrefix := fmt.Sprintf("generated/node_figures/%s/%s/", node.PathID.String(), node.ID.String())
-	if !strings.HasPrefix(storageKey, allowedPrefix) {
-		response.RespondError(c, http.StatusNotFound, "asset_not_found", nil)
-		return
+// nodeAssetKeyAllowed prevents arbitrary bucket reads: only generated figure (and, when allowAudio
+// is set, audio) assets for this node pass. Shared (content-addressed) figures live outside the
+// node prefix, so the node must reference the hash through its own figure rows.
+func (h *PathHandler) nodeAssetKeyAllowed(ctx context.Context, node *types.PathNode, storageKey string, allowAudio bool) (bool, error) {
+	if node == nil || storageKey == "" {
+		return false, nil
+	}
+	if contentHash, ok := content.FigureBlobHashFromKey(storageKey); ok {
+		if h.nodeFigures == nil {
+			return false, nil
+		}
+		return h.nodeFigures.HasContentHash(dbctx.Context{Ctx: ctx}, node.ID, contentHash)
+	}
+	figurePrefix := fmt.Sprintf("generated/node_figures/%s/%s/", node.PathID.String(), node.ID.String())
+	if strings.HasPrefix(storageKey, figurePrefix) {
+		return true, nil
 	}
+	// Rendered node videos can back non-figure media blocks (e.g. video_frame).
+	videoPrefix := fmt.Sprintf("generated/node_videos/%s/%s/", node.PathID.String(), node.ID.String())
+	if strings.HasPrefix(storageKey, videoPrefix) {
+		return true, nil
+	}
+	audioPrefix := content.NodeAudioPrefix(node.PathID.String(), node.ID.String())
+	return allowAudio && strings.HasPrefix(storageKey, audioPrefix), nil
+}
 
+// streamNodeAsset serves an already-authorized bucket object, honouring single byte ranges.
+func (h *PathHandler) streamNodeAsset(c *gin.Context, storageKey string, op string) {
 	ctx := c.Request.Context()
 	attrs, err := h.bucket.GetObjectAttrs(ctx, gcp.BucketCategoryMaterial, storageKey)
 	if err != nil {
-		h.log.Error("ViewPathNodeAsset failed (GetObjectAttrs)", "error", err, "storage_key", storageKey)
+		h.log.Error(op+" failed (GetObjectAttrs)", "error", err, "storage_key", storageKey)
 		response.RespondError(c, http.StatusNotFound, "asset_not_found", err)
 		return
 	}
@@ -1080,7 +1354,7 @@ func (h *PathHandler) ViewPathNodeAsset(c *gin.Context) {
 		if ok {
 			reader, err := h.bucket.OpenRangeReader(ctx, gcp.BucketCategoryMaterial, storageKey, rng.start, rng.end-rng.start+1)
 			if err != nil {
-				h.log.Error("ViewPathNodeAsset failed (OpenRangeReader)", "error", err, "storage_key", storageKey)
+				h.log.Error(op+" failed (OpenRangeReader)", "error", err, "storage_key", storageKey)
 				response.RespondError(c, http.StatusInternalServerError, "stream_failed", err)
 				return
 			}
@@ -1097,7 +1371,7 @@ func (h *PathHandler) ViewPathNodeAsset(c *gin.Context) {
 
 	reader, err := h.bucket.DownloadFile(ctx, gcp.BucketCategoryMaterial, storageKey)
 	if err != nil {
-		h.log.Error("ViewPathNodeAsset failed (DownloadFile)", "error", err, "storage_key", storageKey)
+		h.log.Error(op+" failed (DownloadFile)", "error", err, "storage_key", storageKey)
 		response.RespondError(c, http.StatusInternalServerError, "stream_failed", err)
 		return
 	}
@@ -1113,46 +1387,50 @@ func (h *PathHandler) ViewPathNodeAsset(c *gin.Context) {
 	c.DataFromReader(http.StatusOK, contentLength, contentType, reader, headers)
 }
 
-func (h *PathHandler) rewriteNodeDocFigureAssetURLs(doc content.NodeDocV1, nodeID uuid.UUID) (content.NodeDocV1, bool) {
+func (h *PathHandler) rewriteNodeDocAssetURLs(doc content.NodeDocV1, nodeID uuid.UUID) (content.NodeDocV1, bool) {
 	if h == nil || h.bucket == nil || nodeID == uuid.Nil || len(doc.Blocks) == 0 {
 		return doc, false
 	}
 
-	changed := false
-	base := fmt.Sprintf("/api/path-nodes/%s/assets/view?key=", nodeID.String())
+	return rewriteBlockAssetURLs(doc, nodeAssetViewURLPrefix(h.assetViewBasePath(), nodeID))
+}
 
-	for i := range doc.Blocks {
-		b := doc.Blocks[i]
-		if b == nil {
-			continue
-		}
-		if strings.ToLower(strings.TrimSpace(stringFromAny(b["type"]))) != "figure" {
-			continue
-		}
-		assetAny, ok := b["asset"]
-		if !ok || assetAny == nil {
-			continue
+// NODE_DOC_ASSET_REWRITE_SKIP_TYPES lists block types (comma-separated) whose assets are served
+// as stored, e.g. a media type whose client resolves storage keys itself.
+const envNodeDocAssetRewriteSkipTypes = "NODE_DOC_ASSET_REWRITE_SKIP_TYPES"
+
+func nodeDocAssetRewriteSkipTypes() map[string]bool {
+	out := map[string]bool{}
+	for _, t := range strings.Split(os.Getenv(envNodeDocAssetRewriteSkipTypes), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			out[t] = true
 		}
-		asset, ok := assetAny.(map[string]any)
-		if !ok || asset == nil {
-			continue
+	}
+	return out
+}
+
+// rewriteBlockAssetURLs points every stored (non-external) block asset at base+escaped storage
+// key: figures and any other block type carrying an asset, minus the configured skip list.
+func rewriteBlockAssetURLs(doc content.NodeDocV1, base string) (content.NodeDocV1, bool) {
+	skip := nodeDocAssetRewriteSkipTypes()
+	changed := content.ForEachBlockAsset(&doc, func(_ int, blockType string, asset *content.MediaRefV1) bool {
+		if skip[strings.ToLower(strings.TrimSpace(blockType))] {
+			return false
 		}
-		source := strings.ToLower(strings.TrimSpace(stringFromAny(asset["source"])))
-		if source == "external" {
-			continue
+		if strings.EqualFold(strings.TrimSpace(asset.Source), "external") {
+			return false
 		}
-		storageKey := strings.TrimSpace(stringFromAny(asset["storage_key"]))
+		storageKey := strings.TrimSpace(asset.StorageKey)
 		if storageKey == "" {
-			continue
+			return false
 		}
 		wantURL := base + url.QueryEscape(storageKey)
-		if strings.TrimSpace(stringFromAny(asset["url"])) == wantURL {
-			continue
+		if strings.TrimSpace(asset.URL) == wantURL {
+			return false
 		}
-		asset["url"] = wantURL
-		b["asset"] = asset
-		changed = true
-	}
+		asset.URL = wantURL
+		return true
+	})
 
 	return doc, changed
 }
@@ -1174,6 +1452,17 @@ type prereqGateEvidence struct {
 	EscalationReason      string   `json:"escalation_reason"`
 }
 
+// flagPrereqGateCalloutSuppress stops prereq gate callouts from being injected into served docs;
+// the gate decision is still returned. PREREQ_GATE_CALLOUT_SUPPRESS is its env default.
+const (
+	flagPrereqGateCalloutSuppress = "prereq_gate_callout_suppress"
+	envPrereqGateCalloutSuppress  = "PREREQ_GATE_CALLOUT_SUPPRESS"
+)
+
+func prereqGateCalloutsSuppressed(ctx context.Context) (bool, featureflag.Evaluation) {
+	return featureflag.Bool(ctx, flagPrereqGateCalloutSuppress, envutil.Bool(envPrereqGateCalloutSuppress, false))
+}
+
 func injectPrereqGateCallout(doc content.NodeDocV1, evidence prereqGateEvidence) (content.NodeDocV1, bool) {
 	if len(doc.Blocks) == 0 {
 		return doc, false
@@ -1193,7 +1482,7 @@ func injectPrereqGateCallout(doc content.NodeDocV1, evidence prereqGateEvidence)
 		return doc, false
 	}
 
-	blocksToInsert := []map[string]any{}
+	blocksToInsert := []content.Block{}
 	if needsPrereq {
 		variant := "note"
 		title := "Prerequisite check"
@@ -1223,23 +1512,11 @@ func injectPrereqGateCallout(doc content.NodeDocV1, evidence prereqGateEvidence)
 		parts = append(parts, "Use the quick checks and flashcards, or revisit prerequisite sections if anything feels shaky.")
 		md := strings.Join(parts, "\n\n")
 
-		blocksToInsert = append(blocksToInsert, map[string]any{
-			"id":      uuid.New().String(),
-			"type":    "callout",
-			"variant": variant,
-			"title":   title,
-			"md":      md,
-		})
+		blocksToInsert = append(blocksToInsert, newPrereqGateCallout(variant, title, md))
 	}
 
 	if needsFrame {
-		blocksToInsert = append(blocksToInsert, map[string]any{
-			"id":      uuid.New().String(),
-			"type":    "callout",
-			"variant": "info",
-			"title":   "Try a different frame",
-			"md":      frameMD,
-		})
+		blocksToInsert = append(blocksToInsert, newPrereqGateCallout("info", "Try a different frame", frameMD))
 	}
 
 	if needsEscalation {
@@ -1258,21 +1535,12 @@ func injectPrereqGateCallout(doc content.NodeDocV1, evidence prereqGateEvidence)
 			parts = append(parts, "- Take a short recap or ask for a worked example.")
 		}
 		escalationMD := strings.Join(parts, "\n")
-		blocksToInsert = append(blocksToInsert, map[string]any{
-			"id":      uuid.New().String(),
-			"type":    "callout",
-			"variant": "warning",
-			"title":   "Need a different approach",
-			"md":      escalationMD,
-		})
+		blocksToInsert = append(blocksToInsert, newPrereqGateCallout("warning", "Need a different approach", escalationMD))
 	}
 
 	insertAt := 0
 	for i, b := range doc.Blocks {
-		if b == nil {
-			continue
-		}
-		typ := strings.ToLower(strings.TrimSpace(stringFromAny(b["type"])))
+		typ := content.BlockType(b)
 		if typ == "prerequisites" {
 			insertAt = i + 1
 			break
@@ -1283,16 +1551,26 @@ func injectPrereqGateCallout(doc content.NodeDocV1, evidence prereqGateEvidence)
 	}
 	blocks := append([]map[string]any{}, doc.Blocks...)
 	if len(blocksToInsert) > 0 {
+		inserted := content.EncodeBlocks(blocksToInsert)
 		if insertAt >= len(blocks) {
-			blocks = append(blocks, blocksToInsert...)
+			blocks = append(blocks, inserted...)
 		} else {
-			blocks = append(blocks[:insertAt], append(blocksToInsert, blocks[insertAt:]...)...)
+			blocks = append(blocks[:insertAt], append(inserted, blocks[insertAt:]...)...)
 		}
 	}
 	doc.Blocks = blocks
 	return doc, len(blocksToInsert) > 0
 }
 
+func newPrereqGateCallout(variant, title, md string) *content.CalloutBlock {
+	return &content.CalloutBlock{
+		BlockHeader: content.BlockHeader{ID: uuid.New().String(), Type: "callout"},
+		Variant:     variant,
+		Title:       title,
+		MD:          md,
+	}
+}
+
 func normalizeKeyList(in []string) []string {
 	if len(in) == 0 {
 		return nil
@@ -1317,6 +1595,9 @@ type DocPatchSelection struct {
 	End   int    `json:"end"`
 }
 
+// docPatchActionRefreshSummary regenerates only the doc's top-level summary; blocks are untouched.
+const docPatchActionRefreshSummary = "refresh_summary"
+
 type DocPatchRequest struct {
 	BlockID        string             `json:"block_id"`
 	BlockIndex     *int               `json:"block_index"`
@@ -1324,6 +1605,25 @@ type DocPatchRequest struct {
 	Instruction    string             `json:"instruction"`
 	CitationPolicy string             `json:"citation_policy"`
 	Selection      *DocPatchSelection `json:"selection"`
+
+	// Track is the doc track to patch; "" is primary.
+	Track string `json:"track"`
+}
+
+// docBlockIDAtIndex resolves block_index the same way the patch worker does (0-based, with a
+// 1-based fallback when the index is one past the end) and returns that block's id.
+func docBlockIDAtIndex(doc content.NodeDocV1, blockIndex int) (string, bool) {
+	idx := -1
+	if blockIndex >= 0 && blockIndex < len(doc.Blocks) {
+		idx = blockIndex
+	} else if blockIndex > 0 && blockIndex-1 < len(doc.Blocks) {
+		idx = blockIndex - 1
+	}
+	if idx < 0 || doc.Blocks[idx] == nil {
+		return "", false
+	}
+	id, _ := doc.Blocks[idx]["id"].(string)
+	return strings.TrimSpace(id), true
 }
 
 // POST /api/path-nodes/:id/doc/patch
@@ -1344,42 +1644,26 @@ func (h *PathHandler) EnqueuePathNodeDocPatch(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
-	if err != nil {
-		h.log.Error("EnqueuePathNodeDocPatch failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
-		return
-	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+	var req DocPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
 		return
 	}
 
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	// LoadPatchableNodeDoc runs the same ownership check as authorizeNode (the chat patch
+	// action shares it) and reports failures as *apierr.Error.
+	_, docRow, err := h.learning.LoadPatchableNodeDoc(c.Request.Context(), rd.UserID, nodeID, req.Track)
 	if err != nil {
-		h.log.Error("EnqueuePathNodeDocPatch failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
-		return
-	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		h.respondAPIError(c, "EnqueuePathNodeDocPatch", err, "load_doc_failed", "path_node_id", nodeID)
 		return
 	}
 
-	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
-	if err != nil {
-		h.log.Error("EnqueuePathNodeDocPatch failed (load doc)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
-		return
-	}
-	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
-		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
-		return
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if action == "" {
+		action = "rewrite"
 	}
-
-	var req DocPatchRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+	if action != "rewrite" && action != "regen_media" && action != docPatchActionRefreshSummary {
+		response.RespondError(c, http.StatusBadRequest, "invalid_action", nil)
 		return
 	}
 
@@ -1388,18 +1672,23 @@ func (h *PathHandler) EnqueuePathNodeDocPatch(c *gin.Context) {
 	if req.BlockIndex != nil {
 		blockIndex = *req.BlockIndex
 	}
-	if blockID == "" && blockIndex < 0 {
+	if action == docPatchActionRefreshSummary {
+		// A summary refresh works on the whole doc; any block target is ignored.
+		blockID, blockIndex = "", -1
+	} else if blockID == "" && blockIndex < 0 {
 		response.RespondError(c, http.StatusBadRequest, "missing_block_target", nil)
 		return
 	}
-
-	action := strings.ToLower(strings.TrimSpace(req.Action))
-	if action == "" {
-		action = "rewrite"
-	}
-	if action != "rewrite" && action != "regen_media" {
-		response.RespondError(c, http.StatusBadRequest, "invalid_action", nil)
-		return
+	if blockID != "" && blockIndex >= 0 {
+		var doc content.NodeDocV1
+		if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+			response.RespondError(c, http.StatusInternalServerError, "doc_invalid_json", err)
+			return
+		}
+		if id, ok := docBlockIDAtIndex(doc, blockIndex); !ok || id != blockID {
+			response.RespondError(c, http.StatusBadRequest, "block_target_mismatch", nil)
+			return
+		}
 	}
 
 	policy := strings.ToLower(strings.TrimSpace(req.CitationPolicy))
@@ -1413,9 +1702,11 @@ func (h *PathHandler) EnqueuePathNodeDocPatch(c *gin.Context) {
 
 	payload := map[string]any{
 		"path_node_id":    nodeID.String(),
+		"path_id":         docRow.PathID.String(),
 		"action":          action,
 		"instruction":     strings.TrimSpace(req.Instruction),
 		"citation_policy": policy,
+		"track":           docRow.Track,
 	}
 	if blockID != "" {
 		payload["block_id"] = blockID
@@ -1431,18 +1722,53 @@ func (h *PathHandler) EnqueuePathNodeDocPatch(c *gin.Context) {
 		}
 	}
 
+	if jobCap := h.checkDocGenJobCap(c.Request.Context(), rd.UserID); jobCap.Reached() {
+		respondTooManyJobs(c, jobCap, nil)
+		return
+	}
+
+	// While the LLM provider throttles us, accept the patch but defer it past the expected wait
+	// rather than failing it; the client gets a JobDeferred event and the not_before time.
 	entityID := nodeID
-	job, err := h.jobSvc.Enqueue(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, "node_doc_patch", "path_node", &entityID, payload)
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	var job *types.JobRun
+	if wait := llmThrottleWait(); wait > 0 {
+		job, err = h.jobSvc.EnqueueDeferred(dbc, rd.UserID, "node_doc_patch", "path_node", &entityID, payload, time.Now().Add(wait))
+	} else {
+		job, err = h.jobSvc.Enqueue(dbc, rd.UserID, "node_doc_patch", "path_node", &entityID, payload)
+	}
 	if err != nil {
+		if wait, ok := llmThrottledWait(err); ok {
+			respondLLMThrottled(c, wait)
+			return
+		}
 		h.log.Error("EnqueuePathNodeDocPatch failed (enqueue)", "error", err, "path_node_id", nodeID)
 		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
 		return
 	}
 
-	response.RespondOK(c, gin.H{"job_id": job.ID})
+	out := gin.H{"job_id": job.ID}
+	if job.NotBefore != nil {
+		out["deferred"] = true
+		out["not_before"] = job.NotBefore.UTC()
+	}
+	response.RespondOK(c, out)
+}
+
+var docRevisionListSpec = pagination.Spec{
+	DefaultLimit: 50,
+	MaxLimit:     200,
+	Sorts:        []string{"-created_at", "created_at"},
+	Fields: []string{
+		"id", "doc_id", "job_id", "user_id", "path_id", "path_node_id", "track", "block_id", "block_type",
+		"operation", "citation_policy", "instruction", "selection", "before_json", "after_json",
+		"status", "error", "model", "prompt_version", "tokens_in", "tokens_out", "metadata", "created_at",
+	},
 }
 
-// GET /api/path-nodes/:id/doc/revisions
+// GET /api/path-nodes/:id/doc/revisions?cursor=&limit=&sort=&fields=&include_docs=&track=
+//
+// Lists the revisions of one doc track (default primary).
 func (h *PathHandler) ListPathNodeDocRevisions(c *gin.Context) {
 	rd := ctxutil.GetRequestData(c.Request.Context())
 	if rd == nil || rd.UserID == uuid.Nil {
@@ -1453,6 +1779,11 @@ func (h *PathHandler) ListPathNodeDocRevisions(c *gin.Context) {
 		response.RespondError(c, http.StatusInternalServerError, "revision_repo_missing", nil)
 		return
 	}
+	params, perr := pagination.ParseListParams(c, docRevisionListSpec)
+	if perr != nil {
+		pagination.RespondParamError(c, perr)
+		return
+	}
 
 	nodeID, err := uuid.Parse(c.Param("id"))
 	if err != nil || nodeID == uuid.Nil {
@@ -1460,42 +1791,31 @@ func (h *PathHandler) ListPathNodeDocRevisions(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
-	if err != nil {
-		h.log.Error("ListPathNodeDocRevisions failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
-		return
-	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+	if _, _, err := h.authorizeNode(c, nodeID); err != nil {
+		h.respondAPIError(c, "ListPathNodeDocRevisions", err, "load_node_failed", "path_node_id", nodeID)
 		return
 	}
 
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
-	if err != nil {
-		h.log.Error("ListPathNodeDocRevisions failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
-		return
-	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+	includeDocs := strings.EqualFold(strings.TrimSpace(c.Query("include_docs")), "true") || c.Query("include_docs") == "1"
+	track, ok := types.NormalizeNodeDocTrack(c.Query("track"))
+	if !ok {
+		response.RespondError(c, http.StatusBadRequest, "invalid_track", nil)
 		return
 	}
 
-	limit := 0
-	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
-		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
-			limit = v
-		}
-	}
-	includeDocs := strings.EqualFold(strings.TrimSpace(c.Query("include_docs")), "true") || c.Query("include_docs") == "1"
-
-	rows, err := h.docRevisions.ListByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID, limit)
+	// Fetch one extra row to learn whether another page exists.
+	rows, err := h.docRevisions.ListPageByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID, track, params.After, !params.Desc, params.Limit+1)
 	if err != nil {
 		h.log.Error("ListPathNodeDocRevisions failed (load revisions)", "error", err, "path_node_id", nodeID)
 		response.RespondError(c, http.StatusInternalServerError, "load_revisions_failed", err)
 		return
 	}
+	var next *keyset.Cursor
+	if len(rows) > params.Limit {
+		rows = rows[:params.Limit]
+		last := rows[len(rows)-1]
+		next = keyset.CursorFor(last.CreatedAt, last.ID)
+	}
 	if !includeDocs {
 		for _, r := range rows {
 			if r != nil {
@@ -1505,7 +1825,7 @@ func (h *PathHandler) ListPathNodeDocRevisions(c *gin.Context) {
 		}
 	}
 
-	response.RespondOK(c, gin.H{"revisions": rows})
+	pagination.RespondList(c, params, pagination.Page{Items: rows, Next: next})
 }
 
 // GET /api/path-nodes/:id/doc/materials
@@ -1526,25 +1846,9 @@ func (h *PathHandler) ListPathNodeDocMaterials(c *gin.Context) {
 		return
 	}
 
-	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	_, _, err = h.authorizeNode(c, nodeID)
 	if err != nil {
-		h.log.Error("ListPathNodeDocMaterials failed (load node)", "error", err, "path_node_id", nodeID)
-		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
-		return
-	}
-	if node == nil || node.PathID == uuid.Nil {
-		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
-		return
-	}
-
-	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
-	if err != nil {
-		h.log.Error("ListPathNodeDocMaterials failed (load path)", "error", err, "path_id", node.PathID)
-		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
-		return
-	}
-	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
-		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		h.respondAPIError(c, "ListPathNodeDocMaterials", err, "load_node_failed", "path_node_id", nodeID)
 		return
 	}
 
@@ -1561,42 +1865,70 @@ func (h *PathHandler) ListPathNodeDocMaterials(c *gin.Context) {
 
 	chunkIDs := dedupeUUIDsLocal(extractChunkIDsFromNodeDocJSON(docRow.DocJSON))
 	if len(chunkIDs) == 0 {
-		response.RespondOK(c, gin.H{"files": []any{}, "chunk_ids": []any{}, "chunk_ids_by_file": gin.H{}})
+		response.RespondOK(c, gin.H{"files": []any{}, "removed_files": []any{}, "chunk_ids": []any{}, "chunk_ids_by_file": gin.H{}})
 		return
 	}
 
-	chunks, err := h.chunks.GetByIDs(dbctx.Context{Ctx: c.Request.Context()}, chunkIDs)
+	// Chunks and files are listed in the order the doc first cites them.
+	chunks, err := h.chunks.GetByIDsIncludingDeletedOrdered(dbctx.Context{Ctx: c.Request.Context()}, chunkIDs)
 	if err != nil {
 		h.log.Error("ListPathNodeDocMaterials failed (load chunks)", "error", err, "path_node_id", nodeID)
 		response.RespondError(c, http.StatusInternalServerError, "load_chunks_failed", err)
 		return
 	}
 
-	fileIDSet := map[uuid.UUID]bool{}
+	fileRank := map[uuid.UUID]int{}
+	fileIDs := make([]uuid.UUID, 0)
 	chunkIDsByFile := map[string][]string{}
 	for _, ch := range chunks {
 		if ch == nil || ch.MaterialFileID == uuid.Nil {
 			continue
 		}
-		fileIDSet[ch.MaterialFileID] = true
+		if _, ok := fileRank[ch.MaterialFileID]; !ok {
+			fileRank[ch.MaterialFileID] = len(fileIDs)
+			fileIDs = append(fileIDs, ch.MaterialFileID)
+		}
 		chunkIDsByFile[ch.MaterialFileID.String()] = append(chunkIDsByFile[ch.MaterialFileID.String()], ch.ID.String())
 	}
 
-	fileIDs := make([]uuid.UUID, 0, len(fileIDSet))
-	for id := range fileIDSet {
-		fileIDs = append(fileIDs, id)
-	}
-
-	files, err := h.materialFiles.GetByIDs(dbctx.Context{Ctx: c.Request.Context()}, fileIDs)
+	allFiles, err := h.materialFiles.GetByIDsIncludingDeleted(dbctx.Context{Ctx: c.Request.Context()}, fileIDs)
 	if err != nil {
 		h.log.Error("ListPathNodeDocMaterials failed (load files)", "error", err, "path_node_id", nodeID)
 		response.RespondError(c, http.StatusInternalServerError, "load_files_failed", err)
 		return
 	}
+	rank := func(f *types.MaterialFile) int {
+		if f == nil {
+			return len(fileIDs)
+		}
+		return fileRank[f.ID]
+	}
+	sort.SliceStable(allFiles, func(i, j int) bool { return rank(allFiles[i]) < rank(allFiles[j]) })
+
+	// Citations of removed files stay readable: they are listed separately with a label
+	// instead of disappearing from the doc's sources.
+	files := make([]*types.MaterialFile, 0, len(allFiles))
+	removedFiles := make([]gin.H, 0)
+	for _, f := range allFiles {
+		if f == nil {
+			continue
+		}
+		if f.DeletedAt.Valid {
+			removedFiles = append(removedFiles, gin.H{
+				"id":            f.ID,
+				"original_name": f.OriginalName,
+				"chunk_ids":     chunkIDsByFile[f.ID.String()],
+				"note":          removedSourceNote,
+			})
+			continue
+		}
+		files = append(files, f)
+	}
 	normalizeMaterialFileURLs(h.bucket, files)
 
 	response.RespondOK(c, gin.H{
 		"files":             files,
+		"removed_files":     removedFiles,
 		"chunk_ids":         uuidStrings(chunkIDs),
 		"chunk_ids_by_file": chunkIDsByFile,
 	})
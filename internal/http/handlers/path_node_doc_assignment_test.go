@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type memAssignmentRepo struct {
+	repos.DocVariantAssignmentRepo
+	rows map[string]*types.DocVariantAssignment
+}
+
+func (r *memAssignmentRepo) Get(dbc dbctx.Context, userID uuid.UUID, policyVersion string) (*types.DocVariantAssignment, error) {
+	return r.rows[userID.String()+"|"+policyVersion], nil
+}
+
+func (r *memAssignmentRepo) Assign(dbc dbctx.Context, row *types.DocVariantAssignment) (*types.DocVariantAssignment, bool, error) {
+	key := row.UserID.String() + "|" + row.PolicyVersion
+	if existing := r.rows[key]; existing != nil {
+		return existing, false, nil
+	}
+	r.rows[key] = row
+	return row, true, nil
+}
+
+func (r *memAssignmentRepo) CountArms(dbc dbctx.Context, policyVersion string) ([]repos.DocVariantArmCount, error) {
+	users := map[string]int64{}
+	for _, row := range r.rows {
+		if row.PolicyVersion == policyVersion {
+			users[row.Arm]++
+		}
+	}
+	out := []repos.DocVariantArmCount{}
+	for _, arm := range []string{repos.DocVariantArmHoldback, repos.DocVariantArmTreatment} {
+		if users[arm] > 0 {
+			out = append(out, repos.DocVariantArmCount{Arm: arm, Users: users[arm]})
+		}
+	}
+	return out, nil
+}
+
+func TestResolveDocVariantAssignmentSurvivesPctChange(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	store := &memAssignmentRepo{rows: map[string]*types.DocVariantAssignment{}}
+	h := &PathHandler{log: log, docAssignments: store}
+	userID := uuid.New()
+
+	first := h.resolveDocVariantAssignment(context.Background(), userID, "active", 0)
+	if first.Eligible || first.Arm != repos.DocVariantArmHoldback || first.Source != repos.DocVariantAssignmentSourceHash {
+		t.Fatalf("first: %+v", first)
+	}
+	// At 100% the hash would now say treatment; the stored arm must win.
+	again := h.resolveDocVariantAssignment(context.Background(), userID, "active", 1)
+	if again.Eligible || again.Arm != repos.DocVariantArmHoldback || again.Pct != 0 {
+		t.Fatalf("after pct change: %+v", again)
+	}
+	if off := h.resolveDocVariantAssignment(context.Background(), userID, "off", 1); !off.Eligible || off.Source != "hash_stateless" {
+		t.Fatalf("policy off should skip assignments: %+v", off)
+	}
+}
+
+func TestResolveDocVariantAssignmentKeepsBackfilledArm(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	userID := uuid.New()
+	policyKey := docgen.DocPolicy(context.Background()).PolicyKey
+	exposedAt := time.Now().Add(-time.Hour).UTC()
+	store := &memAssignmentRepo{rows: map[string]*types.DocVariantAssignment{
+		userID.String() + "|" + policyKey: {UserID: userID, PolicyVersion: policyKey, Arm: repos.DocVariantArmTreatment, RolloutPct: 0.2, Source: repos.DocVariantAssignmentSourceBackfill, AssignedAt: exposedAt},
+	}}
+	h := &PathHandler{log: log, docAssignments: store}
+
+	// The hash says holdback at 0%; the arm pinned by the migration backfill wins.
+	got := h.resolveDocVariantAssignment(context.Background(), userID, "shadow", 0)
+	if !got.Eligible || got.Source != repos.DocVariantAssignmentSourceBackfill || got.Pct != 0.2 || !got.AssignedAt.Equal(exposedAt) {
+		t.Fatalf("backfill: %+v", got)
+	}
+	meta := map[string]any{}
+	got.annotate(meta)
+	if meta["assignment_source"] != repos.DocVariantAssignmentSourceBackfill || meta["assignment_arm"] != repos.DocVariantArmTreatment {
+		t.Fatalf("meta: %v", meta)
+	}
+}
+
+func TestGetUserVariantAssignmentExplainsDecision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	// Registered before Setenv so it reloads the shared policy after the env is restored.
+	t.Cleanup(func() { _ = docgen.RefreshDocPolicy(context.Background()) })
+	t.Setenv(docgen.EnvDocVariantPolicyKey, "audit-k2")
+	t.Setenv(docgen.EnvDocVariantRolloutPct, "0.5")
+	if err := docgen.RefreshDocPolicy(context.Background()); err != nil {
+		t.Fatalf("refresh policy: %v", err)
+	}
+
+	userID := uuid.New()
+	assignedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := &memAssignmentRepo{rows: map[string]*types.DocVariantAssignment{
+		userID.String() + "|audit-k1": {UserID: userID, PolicyVersion: "audit-k1", Arm: repos.DocVariantArmTreatment, RolloutPct: 0.1, Source: repos.DocVariantAssignmentSourceHash, AssignedAt: assignedAt},
+	}}
+	h := &PathHandler{log: log, docAssignments: store}
+
+	get := func(target string) (int, variantAssignmentAudit) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+		h.GetUserVariantAssignment(c)
+		var out variantAssignmentAudit
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out
+	}
+
+	code, cur := get("/api/admin/users/x/variant-assignment")
+	if code != http.StatusOK || cur.PolicyKey != "audit-k2" || cur.CurrentPolicyKey != "audit-k2" || cur.Assignment != nil {
+		t.Fatalf("current policy: %d %+v", code, cur)
+	}
+	if cur.Threshold != 0.5 || cur.Bucket != rolloutBucket(userID) || cur.Eligible != rolloutEligible(userID, 0.5) || cur.Eligible != (cur.Bucket < cur.Threshold) {
+		t.Fatalf("decision: %+v", cur)
+	}
+
+	_, old := get("/api/admin/users/x/variant-assignment?policy_key=audit-k1")
+	if old.PolicyKey != "audit-k1" || old.Assignment == nil || old.Assignment.Arm != repos.DocVariantArmTreatment || !old.Assignment.AssignedAt.Equal(assignedAt) {
+		t.Fatalf("persisted assignment: %+v", old)
+	}
+	if old.Bucket != cur.Bucket {
+		t.Fatalf("bucket should not depend on policy key")
+	}
+}
+
+func TestGetVariantAssignmentArmCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	t.Cleanup(func() { _ = docgen.RefreshDocPolicy(context.Background()) })
+	t.Setenv(docgen.EnvDocVariantPolicyKey, "arms-k2")
+	if err := docgen.RefreshDocPolicy(context.Background()); err != nil {
+		t.Fatalf("refresh policy: %v", err)
+	}
+
+	store := &memAssignmentRepo{rows: map[string]*types.DocVariantAssignment{}}
+	for i, arm := range []string{repos.DocVariantArmTreatment, repos.DocVariantArmTreatment, repos.DocVariantArmHoldback} {
+		userID := uuid.New()
+		store.rows[userID.String()+"|arms-k2"] = &types.DocVariantAssignment{UserID: userID, PolicyVersion: "arms-k2", Arm: arm}
+		if i == 0 {
+			store.rows[userID.String()+"|arms-k1"] = &types.DocVariantAssignment{UserID: userID, PolicyVersion: "arms-k1", Arm: repos.DocVariantArmHoldback}
+		}
+	}
+	h := &PathHandler{log: log, docAssignments: store}
+
+	get := func(target string) (int, variantArmCounts) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		h.GetVariantAssignmentArmCounts(c)
+		var out variantArmCounts
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out
+	}
+
+	code, cur := get("/api/admin/variant-assignments/arms")
+	if code != http.StatusOK || cur.PolicyKey != "arms-k2" || cur.CurrentPolicyKey != "arms-k2" || cur.Total != 3 {
+		t.Fatalf("current policy: %d %+v", code, cur)
+	}
+	want := []repos.DocVariantArmCount{{Arm: repos.DocVariantArmHoldback, Users: 1}, {Arm: repos.DocVariantArmTreatment, Users: 2}}
+	if len(cur.Arms) != len(want) || cur.Arms[0] != want[0] || cur.Arms[1] != want[1] {
+		t.Fatalf("arms: %+v", cur.Arms)
+	}
+
+	_, old := get("/api/admin/variant-assignments/arms?policy_key=arms-k1")
+	if old.PolicyKey != "arms-k1" || old.Total != 1 || len(old.Arms) != 1 || old.Arms[0].Arm != repos.DocVariantArmHoldback {
+		t.Fatalf("previous policy: %+v", old)
+	}
+}
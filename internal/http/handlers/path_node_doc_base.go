@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	docgen "github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// GET /api/path-nodes/:id/doc/base
+//
+// Returns the base doc regardless of variant serving, for the "compare with base" view. When an
+// active variant exists, the response carries a block alignment from variant blocks onto base
+// blocks so the client can line the two up; prereq gate callouts are never injected here.
+func (h *PathHandler) GetPathNodeDocBase(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "GetPathNodeDocBase"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBase failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBase failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBase failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+
+	var baseDoc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &baseDoc); err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "doc_invalid_json", err)
+		return
+	}
+	// IDs are only assigned in memory: GetPathNodeDoc owns the write-back, and this read-only
+	// view must not bump the doc version under a concurrent reader.
+	baseDoc, _ = content.EnsureNodeDocBlockIDs(baseDoc)
+	baseContentHash := docRow.ContentHash
+
+	variantRow, variantDoc, variantContentHash, variantReady := h.loadDocVariant(c, rd.UserID, nodeID)
+
+	var alignment *content.DocAlignment
+	var variantID *uuid.UUID
+	variantKind := "base"
+	policyVersion := docgen.DocPolicy(c.Request.Context()).PolicyVersion
+	baseDocID := docRow.ID
+	meta := map[string]any{"exposure_kind": "base_comparison"}
+	if variantReady {
+		a := content.AlignNodeDocBlocks(variantDoc, baseDoc)
+		alignment = &a
+		id := variantRow.ID
+		variantID = &id
+		variantKind = strings.TrimSpace(variantRow.VariantKind)
+		policyVersion = strings.TrimSpace(variantRow.PolicyVersion)
+		if variantRow.BaseDocID != nil && *variantRow.BaseDocID != uuid.Nil {
+			baseDocID = *variantRow.BaseDocID
+		}
+		meta["candidate_variant_id"] = id.String()
+		meta["candidate_variant_kind"] = variantKind
+		meta["aligned_blocks"] = a.Aligned
+		meta["variant_blocks"] = a.VariantBlocks
+		meta["diverged"] = a.Diverged
+	}
+
+	if withAssetURLs, changed := h.rewriteNodeDocAssetURLs(baseDoc, nodeID); changed {
+		baseDoc = withAssetURLs
+	}
+	baseDoc, validation := nodeDocRichTextStatus(baseDoc)
+
+	if h.docVariantExposure != nil {
+		h.logDocVariantExposure(
+			c,
+			rd,
+			nodeID,
+			node.PathID,
+			baseDoc,
+			baseDocID,
+			variantID,
+			variantKind,
+			policyVersion,
+			"base_comparison",
+			baseContentHash,
+			meta,
+		)
+	}
+
+	out := gin.H{
+		"doc":                  baseDoc,
+		"base_content_hash":    baseContentHash,
+		"variant_content_hash": nil,
+		"variant_id":           nil,
+		"alignment":            alignment,
+		"diverged":             false,
+		"doc_status": nodeDocStatus{
+			State:      "ready",
+			PathID:     nodePathIDString(node),
+			PathNodeID: nodeIDString(node),
+			Validation: validation,
+		},
+	}
+	if alignment != nil {
+		out["variant_content_hash"] = variantContentHash
+		out["variant_id"] = variantID.String()
+		out["diverged"] = alignment.Diverged
+	}
+	response.RespondOK(c, out)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type nopBucket struct{ gcp.BucketService }
+
+func TestRewriteNodeDocFigureAssetURLsKeepsOtherAssetFields(t *testing.T) {
+	nodeID := uuid.New()
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "f1", "type": "figure", "asset": map[string]any{"url": "https://old", "storage_key": "generated/figures/a b.png", "width": float64(640)}, "caption": "A"},
+		{"id": "f2", "type": "figure", "asset": map[string]any{"url": "https://cdn/x.png", "storage_key": "x", "source": "External"}},
+		{"id": "p1", "type": "paragraph", "md": "text"},
+	}}
+	before, _ := json.Marshal(doc.Blocks[1:])
+
+	h := &PathHandler{bucket: nopBucket{}}
+	out, changed := h.rewriteNodeDocAssetURLs(doc, nodeID)
+	if !changed {
+		t.Fatalf("expected the generated figure to be rewritten")
+	}
+	asset := out.Blocks[0]["asset"].(map[string]any)
+	want := "/api/path-nodes/" + nodeID.String() + "/assets/view?key=generated%2Ffigures%2Fa+b.png"
+	if asset["url"] != want || asset["width"] != float64(640) {
+		t.Fatalf("asset: %v", asset)
+	}
+	if after, _ := json.Marshal(out.Blocks[1:]); string(after) != string(before) {
+		t.Fatalf("external figure or paragraph changed:\n%s\n%s", before, after)
+	}
+	if _, again := h.rewriteNodeDocAssetURLs(out, nodeID); again {
+		t.Fatalf("second rewrite should be a no-op")
+	}
+}
+
+func TestRewriteNodeDocAssetURLsCoversMediaBlocks(t *testing.T) {
+	nodeID := uuid.New()
+	newDoc := func() content.NodeDocV1 {
+		return content.NodeDocV1{Blocks: []map[string]any{
+			{"id": "d1", "type": "diagram", "kind": "mermaid", "asset": map[string]any{"storage_key": "generated/node_figures/d.png", "alt": "flow"}},
+			{"id": "v1", "type": "video_frame", "asset": map[string]any{"storage_key": "generated/node_videos/v.mp4"}, "at_sec": float64(3)},
+			{"id": "v2", "type": "video_frame", "asset": map[string]any{"url": "https://cdn/v.mp4", "storage_key": "v", "source": "external"}},
+			{"id": "c1", "type": "callout", "md": "no asset"},
+		}}
+	}
+	h := &PathHandler{bucket: nopBucket{}}
+	out, changed := h.rewriteNodeDocAssetURLs(newDoc(), nodeID)
+	if !changed {
+		t.Fatalf("expected media blocks to be rewritten")
+	}
+	prefix := "/api/path-nodes/" + nodeID.String() + "/assets/view?key="
+	d := out.Blocks[0]["asset"].(map[string]any)
+	if d["url"] != prefix+url.QueryEscape("generated/node_figures/d.png") || d["alt"] != "flow" || out.Blocks[0]["kind"] != "mermaid" {
+		t.Fatalf("diagram: %v", out.Blocks[0])
+	}
+	if v := out.Blocks[1]["asset"].(map[string]any); v["url"] != prefix+url.QueryEscape("generated/node_videos/v.mp4") || out.Blocks[1]["at_sec"] != float64(3) {
+		t.Fatalf("video_frame: %v", out.Blocks[1])
+	}
+	if v := out.Blocks[2]["asset"].(map[string]any); v["url"] != "https://cdn/v.mp4" {
+		t.Fatalf("external asset rewritten: %v", v)
+	}
+	if _, again := h.rewriteNodeDocAssetURLs(out, nodeID); again {
+		t.Fatalf("second rewrite should be a no-op")
+	}
+
+	t.Setenv(envNodeDocAssetRewriteSkipTypes, " Video_Frame ,")
+	out, _ = h.rewriteNodeDocAssetURLs(newDoc(), nodeID)
+	if _, ok := out.Blocks[1]["asset"].(map[string]any)["url"]; ok {
+		t.Fatalf("skipped type was rewritten: %v", out.Blocks[1])
+	}
+	if out.Blocks[0]["asset"].(map[string]any)["url"] == nil {
+		t.Fatalf("diagram should still be rewritten")
+	}
+}
+
+func TestRewriteNodeDocFigureAssetURLsHonorsBasePath(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	nodeID := uuid.New()
+	newDoc := func() content.NodeDocV1 {
+		return content.NodeDocV1{Blocks: []map[string]any{
+			{"id": "f1", "type": "figure", "asset": map[string]any{"storage_key": "generated/figures/a.png"}},
+		}}
+	}
+	for base, want := range map[string]string{
+		"":                               "/api/path-nodes/",
+		"/backend/api/":                  "/backend/api/path-nodes/",
+		"edge/api":                       "/edge/api/path-nodes/",
+		" https://cdn.example.com/api/ ": "https://cdn.example.com/api/path-nodes/",
+	} {
+		h := NewPathHandlerWithDeps(PathHandlerDeps{
+			Log:               log,
+			Services:          PathHandlerServices{Bucket: nopBucket{}},
+			AssetViewBasePath: base,
+		})
+		out, changed := h.rewriteNodeDocAssetURLs(newDoc(), nodeID)
+		if !changed {
+			t.Fatalf("base %q: expected rewrite", base)
+		}
+		got := out.Blocks[0]["asset"].(map[string]any)["url"]
+		if got != want+nodeID.String()+"/assets/view?key=generated%2Ffigures%2Fa.png" {
+			t.Fatalf("base %q: url = %v", base, got)
+		}
+	}
+
+	node := &types.PathNode{ID: nodeID, PathID: uuid.New()}
+	key := "generated/node_figures/" + node.PathID.String() + "/" + nodeID.String() + "/a.png"
+	shared := sanitizeSharedNodeDoc(content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "f1", "type": "figure", "asset": map[string]any{"storage_key": key}},
+	}}, "/backend/api", "tok", node)
+	if got := shared.Blocks[0]["asset"].(map[string]any)["url"]; got != "/backend/api/shared/tok/nodes/"+nodeID.String()+"/assets/view?key="+url.QueryEscape(key) {
+		t.Fatalf("shared url = %v", got)
+	}
+}
+
+func TestExtractDocConceptKeysFromTypedBlocks(t *testing.T) {
+	doc := content.NodeDocV1{
+		ConceptKeys: []string{"loops"},
+		Blocks: []map[string]any{
+			{"id": "fc", "type": "flashcard", "front_md": "F", "back_md": "B", "concept_keys": []any{"iteration"}},
+			{"id": "d", "type": "drill", "payload": map[string]any{
+				"concept_keys": []any{"drills"},
+				"cards":        []any{map[string]any{"concept_keys": []any{"cards"}}},
+				"questions":    []any{map[string]any{"concept_keys": []any{"questions"}}},
+			}},
+		},
+	}
+	got := strings.Join(extractDocConceptKeys(doc), ",")
+	if got != "loops,iteration,drills,cards,questions" {
+		t.Fatalf("keys: %s", got)
+	}
+}
+
+func TestInjectPrereqGateCalloutAfterPrerequisites(t *testing.T) {
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "o", "type": "objectives", "items_md": []any{"x"}},
+		{"id": "pr", "type": "prerequisites", "items_md": []any{"y"}},
+		{"id": "p", "type": "paragraph", "md": "body"},
+	}}
+	out, changed := injectPrereqGateCallout(doc, prereqGateEvidence{Status: "not_ready", WeakConcepts: []string{"Fractions"}})
+	if !changed || len(out.Blocks) != 4 {
+		t.Fatalf("changed=%v blocks=%d", changed, len(out.Blocks))
+	}
+	callout := out.Blocks[2]
+	if callout["type"] != "callout" || callout["variant"] != "warning" || callout["title"] != "Prerequisites need attention" {
+		t.Fatalf("callout: %v", callout)
+	}
+	if _, ok := callout["citations"]; ok {
+		t.Fatalf("new callout should not carry empty fields: %v", callout)
+	}
+	if !strings.Contains(callout["md"].(string), "- fractions") {
+		t.Fatalf("md: %v", callout["md"])
+	}
+	if out.Blocks[3]["id"] != "p" {
+		t.Fatalf("following block moved: %v", out.Blocks[3])
+	}
+}
+
+func TestNodeDocTOCKeepsBlockOrderAndTitles(t *testing.T) {
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "h1", "type": "Heading", "level": 2, "text": "Why halving works"},
+		{"id": "p1", "type": "paragraph", "md": "Body text is not part of the outline."},
+		{"id": "c1", "type": "callout", "title": " Worked example ", "md": "..."},
+		nil,
+		{"id": "qc", "type": "quick_check", "prompt_md": "What is mid?"},
+	}}
+	got := nodeDocTOC(doc)
+	want := []nodeDocTOCEntry{
+		{BlockID: "h1", Type: "heading", Title: "Why halving works"},
+		{BlockID: "p1", Type: "paragraph", Title: ""},
+		{BlockID: "c1", Type: "callout", Title: "Worked example"},
+		{BlockID: "qc", Type: "quick_check", Title: ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entry %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
+)
+
+// Query budget for a cold GetPathNodeDoc (no doc cache, policy off): node, path, doc, variant,
+// prereq gate, path concepts, concept state baseline, exposure insert. Raise it only with a reason.
+const getPathNodeDocQueryBudget = 8
+
+func TestGetPathNodeDocQueryBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	dbc := dbctx.Context{Ctx: context.Background(), Tx: tx}
+
+	user := testutil.SeedUser(t, dbc, "docbudget@example.com")
+	path := &types.Path{ID: uuid.New(), UserID: &user.ID, Title: "Loops"}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "Loops"}
+	raw, err := json.Marshal(content.NodeDocV1{
+		SchemaVersion: 1,
+		Title:         "Loops",
+		ConceptKeys:   []string{"loops"},
+		Blocks: []map[string]any{
+			{"id": "p1", "type": "paragraph", "md": "Loops repeat work."},
+			{"id": "qc1", "type": "quick_check", "prompt_md": "What repeats?", "answer_md": "Loops."},
+			{"id": "fc1", "type": "flashcard", "front_md": "Loop", "back_md": "Repeat"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal doc: %v", err)
+	}
+	seed := []any{
+		path,
+		node,
+		&types.LearningNodeDoc{ID: uuid.New(), UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, SchemaVersion: 1, DocJSON: datatypes.JSON(raw), ContentHash: "h", SourcesHash: "s"},
+		&types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &path.ID, Key: "loops", Name: "Loops"},
+	}
+	for _, row := range seed {
+		if err := tx.Create(row).Error; err != nil {
+			t.Fatalf("seed %T: %v", row, err)
+		}
+	}
+
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log: log,
+		Path: PathHandlerPathRepos{
+			Path:      repos.NewPathRepo(tx, log),
+			PathNodes: repos.NewPathNodeRepo(tx, log),
+		},
+		Content: PathHandlerContentRepos{
+			NodeDocs:           repos.NewLearningNodeDocRepo(tx, log),
+			DocVariants:        repos.NewLearningNodeDocVariantRepo(tx, log),
+			DocVariantExposure: repos.NewDocVariantExposureRepo(tx, log),
+		},
+		Learning: PathHandlerLearningRepos{
+			Concepts:     repos.NewConceptRepo(tx, log),
+			ConceptState: repos.NewUserConceptStateRepo(tx, log),
+			PrereqGates:  repos.NewPrereqGateDecisionRepo(tx, log),
+		},
+	})
+
+	budgets := dbstats.ExpectQueryBudgets(t, map[string]int{"GetPathNodeDoc": getPathNodeDocQueryBudget})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/api/path-nodes/"+node.ID.String()+"/doc", nil)
+	ctx := ctxutil.WithRequestData(budgets.Context(req.Context()), &ctxutil.RequestData{UserID: user.ID})
+	c.Request = req.WithContext(ctx)
+	c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+
+	h.GetPathNodeDoc(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if budgets.Tally().Count("GetPathNodeDoc") == 0 {
+		t.Fatal("expected queries attributed to GetPathNodeDoc")
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+// TestDocVariantExposureMetaAllowlist fails when path_node_doc.go writes a metadata key the
+// exposure allowlist would silently drop.
+func TestDocVariantExposureMetaAllowlist(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "path_node_doc.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	metaVars := map[string]bool{"candidateMeta": true, "meta": true}
+	keys := map[string]token.Pos{}
+	addKey := func(e ast.Expr) {
+		if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if k, err := strconv.Unquote(lit.Value); err == nil {
+				keys[k] = lit.Pos()
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if idx, ok := lhs.(*ast.IndexExpr); ok {
+					if id, ok := idx.X.(*ast.Ident); ok && metaVars[id.Name] {
+						addKey(idx.Index)
+					}
+					continue
+				}
+				id, ok := lhs.(*ast.Ident)
+				if !ok || id.Name != "candidateMeta" || i >= len(n.Rhs) {
+					continue
+				}
+				if lit, ok := n.Rhs[i].(*ast.CompositeLit); ok {
+					for _, el := range lit.Elts {
+						if kv, ok := el.(*ast.KeyValueExpr); ok {
+							addKey(kv.Key)
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+	if len(keys) < 10 {
+		t.Fatalf("found only %d metadata keys; the scan no longer matches the handler", len(keys))
+	}
+	for k, pos := range keys {
+		if !docVariantExposureMeta.Allows(k) {
+			t.Errorf("%s: metadata key %q is not in docVariantExposureMeta", fset.Position(pos), k)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+)
+
+func TestPrereqGateCalloutSuppressFlag(t *testing.T) {
+	ctx := context.Background()
+	pathID := uuid.New()
+
+	// Env default only.
+	t.Setenv(envPrereqGateCalloutSuppress, "true")
+	if err := featureflag.SetSource(ctx, nil); err != nil {
+		t.Fatalf("set source: %v", err)
+	}
+	if off, ev := prereqGateCalloutsSuppressed(ctx); !off || ev.Source != featureflag.SourceEnv {
+		t.Fatalf("env = %v %+v", off, ev)
+	}
+
+	// Table default overrides env; a path override wins over both.
+	if err := featureflag.SetSource(ctx, func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		def := "false"
+		return []*types.FeatureFlag{{
+			Name:         flagPrereqGateCalloutSuppress,
+			DefaultValue: &def,
+			Rules:        datatypes.JSON(`{"paths":{"` + pathID.String() + `":"true"}}`),
+		}}, nil
+	}); err != nil {
+		t.Fatalf("set source: %v", err)
+	}
+	t.Cleanup(func() { _ = featureflag.SetSource(ctx, nil) })
+
+	if off, ev := prereqGateCalloutsSuppressed(ctx); off || ev.Source != featureflag.SourceDefault {
+		t.Fatalf("table default = %v %+v", off, ev)
+	}
+	onPath := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: uuid.New(), PathID: pathID})
+	off, ev := prereqGateCalloutsSuppressed(onPath)
+	if !off || ev.Source != featureflag.SourcePath {
+		t.Fatalf("path override = %v %+v", off, ev)
+	}
+	meta := map[string]any{}
+	ev.Annotate(meta)
+	if _, ok := meta["flags"].(map[string]any)[flagPrereqGateCalloutSuppress]; !ok {
+		t.Fatalf("candidate meta = %v", meta)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type freezePathNodeDocRequest struct {
+	// Frozen sets the flag explicitly; when omitted the current value is toggled.
+	Frozen *bool `json:"frozen"`
+}
+
+// POST /api/path-nodes/:id/doc/freeze
+//
+// Locks (or unlocks) a finalized doc. A frozen doc is always served as its base, never a
+// variant, and block patches are rejected with 409 doc_frozen.
+func (h *PathHandler) FreezePathNodeDoc(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "FreezePathNodeDoc"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req freezePathNodeDocRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+			return
+		}
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("FreezePathNodeDoc failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("FreezePathNodeDoc failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("FreezePathNodeDoc failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+
+	frozen := !docRow.Frozen
+	if req.Frozen != nil {
+		frozen = *req.Frozen
+	}
+	if frozen != docRow.Frozen {
+		found, err := h.nodeDocs.SetFrozen(dbc, nodeID, frozen)
+		if err != nil {
+			h.log.Error("FreezePathNodeDoc failed (update)", "error", err, "path_node_id", nodeID)
+			response.RespondError(c, http.StatusInternalServerError, "update_doc_failed", err)
+			return
+		}
+		if !found {
+			response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+			return
+		}
+	}
+
+	response.RespondOK(c, gin.H{
+		"path_node_id": nodeID,
+		"frozen":       frozen,
+	})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type freezableNodeDocRepo struct {
+	fakeNodeDocRepo
+}
+
+func (r *freezableNodeDocRepo) SetFrozen(dbc dbctx.Context, pathNodeID uuid.UUID, frozen bool) (bool, error) {
+	r.doc.Frozen = frozen
+	r.doc.Version++
+	return true, nil
+}
+
+// variantCountingRepo records whether variant serving looked up a variant at all.
+type variantCountingRepo struct {
+	repos.LearningNodeDocVariantRepo
+	loads int
+}
+
+func (r *variantCountingRepo) GetLatestByUserAndNode(dbc dbctx.Context, userID, pathNodeID uuid.UUID) (*types.LearningNodeDocVariant, error) {
+	r.loads++
+	return nil, nil
+}
+
+// unusedJobService fails the test if a frozen doc still reaches the enqueue.
+type unusedJobService struct {
+	services.JobService
+	t *testing.T
+}
+
+func (s unusedJobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error) {
+	s.t.Fatalf("unexpected enqueue of %s for a frozen doc", jobType)
+	return nil, nil
+}
+
+func TestFrozenPathNodeDoc(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+	doc := &types.LearningNodeDoc{
+		ID:         uuid.New(),
+		PathID:     path.ID,
+		PathNodeID: node.ID,
+		DocJSON:    datatypes.JSON(`{"schema_version":1,"title":"Loops","blocks":[{"id":"p1","type":"paragraph","md":"Loops repeat work."},{"id":"qc1","type":"quick_check","prompt_md":"?","answer_md":"."},{"id":"fc1","type":"flashcard","front_md":"a","back_md":"b"}]}`),
+		Version:    1,
+	}
+	docs := &freezableNodeDocRepo{fakeNodeDocRepo{doc: doc}}
+	variants := &variantCountingRepo{}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:     log,
+		Path:    PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}},
+		Content: PathHandlerContentRepos{NodeDocs: docs, DocVariants: variants},
+		Services: PathHandlerServices{
+			JobSvc:   unusedJobService{t: t},
+			Learning: learningmod.New(learningmod.UsecasesDeps{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}, NodeDocs: docs}),
+		},
+	})
+
+	call := func(method, target, body string, fn gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		fn(c)
+		return w
+	}
+	base := "/api/path-nodes/" + node.ID.String() + "/doc"
+
+	// No body toggles: unfrozen -> frozen.
+	w := call(http.MethodPost, base+"/freeze", "", h.FreezePathNodeDoc)
+	if w.Code != http.StatusOK || !doc.Frozen || doc.Version != 2 {
+		t.Fatalf("freeze: status %d frozen=%v version=%d: %s", w.Code, doc.Frozen, doc.Version, w.Body.String())
+	}
+
+	w = call(http.MethodPost, base+"/patch", `{"block_id":"p1","instruction":"shorter"}`, h.EnqueuePathNodeDocPatch)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "doc_frozen") {
+		t.Fatalf("patch on frozen doc: status %d: %s", w.Code, w.Body.String())
+	}
+
+	w = call(http.MethodGet, base, "", h.GetPathNodeDoc)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: status %d: %s", w.Code, w.Body.String())
+	}
+	if variants.loads != 0 {
+		t.Fatalf("frozen doc should not load variants (%d loads)", variants.loads)
+	}
+	var got struct {
+		DocStatus struct {
+			Frozen bool `json:"frozen"`
+		} `json:"doc_status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !got.DocStatus.Frozen {
+		t.Fatalf("doc_status should report frozen: %v %s", err, w.Body.String())
+	}
+
+	// An explicit value is idempotent; false unfreezes and variants are considered again.
+	call(http.MethodPost, base+"/freeze", `{"frozen":true}`, h.FreezePathNodeDoc)
+	if !doc.Frozen || doc.Version != 2 {
+		t.Fatalf("re-freeze should be a no-op: frozen=%v version=%d", doc.Frozen, doc.Version)
+	}
+	if w = call(http.MethodPost, base+"/freeze", `{"frozen":false}`, h.FreezePathNodeDoc); w.Code != http.StatusOK || doc.Frozen {
+		t.Fatalf("unfreeze: status %d frozen=%v", w.Code, doc.Frozen)
+	}
+	call(http.MethodGet, base, "", h.GetPathNodeDoc)
+	if variants.loads != 1 {
+		t.Fatalf("unfrozen doc should load variants, got %d loads", variants.loads)
+	}
+}
+
+type recordingJobService struct {
+	services.JobService
+	payloads []map[string]any
+}
+
+func (s *recordingJobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error) {
+	s.payloads = append(s.payloads, payload)
+	return &types.JobRun{ID: uuid.New()}, nil
+}
+
+func TestRefreshSummaryPatchAndStaleFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+	doc := &types.LearningNodeDoc{
+		ID:         uuid.New(),
+		PathID:     path.ID,
+		PathNodeID: node.ID,
+		DocJSON:    datatypes.JSON(`{"schema_version":1,"title":"Loops","summary":"Old.","blocks":[{"id":"p1","type":"paragraph","md":"Loops repeat work."}]}`),
+		Metadata:   datatypes.JSON(`{"summary_stale":true}`),
+		Version:    1,
+	}
+	jobs := &recordingJobService{}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:     log,
+		Path:    PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}},
+		Content: PathHandlerContentRepos{NodeDocs: &fakeNodeDocRepo{doc: doc}},
+		Services: PathHandlerServices{
+			JobSvc:   jobs,
+			Learning: learningmod.New(learningmod.UsecasesDeps{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}, NodeDocs: &fakeNodeDocRepo{doc: doc}}),
+		},
+	})
+
+	call := func(method, body string, fn gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(method, "/api/path-nodes/"+node.ID.String()+"/doc", strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		fn(c)
+		return w
+	}
+
+	w := call(http.MethodGet, "", h.GetPathNodeDoc)
+	var got struct {
+		DocStatus struct {
+			SummaryStale bool `json:"summary_stale"`
+		} `json:"doc_status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || !got.DocStatus.SummaryStale {
+		t.Fatalf("doc_status should report summary_stale: %v %s", err, w.Body.String())
+	}
+
+	// No block target is needed, and a stray one is dropped.
+	w = call(http.MethodPost, `{"action":"refresh_summary","block_id":"p1"}`, h.EnqueuePathNodeDocPatch)
+	if w.Code != http.StatusOK || len(jobs.payloads) != 1 {
+		t.Fatalf("enqueue: status %d: %s", w.Code, w.Body.String())
+	}
+	p := jobs.payloads[0]
+	if p["action"] != "refresh_summary" || p["block_id"] != nil || p["block_index"] != nil {
+		t.Fatalf("payload = %v", p)
+	}
+
+	if w = call(http.MethodPost, `{"action":"rewrite"}`, h.EnqueuePathNodeDocPatch); w.Code != http.StatusBadRequest {
+		t.Fatalf("rewrite without target: status %d", w.Code)
+	}
+}
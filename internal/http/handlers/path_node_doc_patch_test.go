@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+func TestDocBlockIDAtIndex(t *testing.T) {
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "h1", "type": "heading"},
+		{"id": "p1", "type": "paragraph"},
+	}}
+	cases := []struct {
+		index int
+		id    string
+		ok    bool
+	}{
+		{index: 0, id: "h1", ok: true},
+		{index: 1, id: "p1", ok: true},
+		// One past the end falls back to 1-based, matching the patch worker.
+		{index: 2, id: "p1", ok: true},
+		{index: 3, ok: false},
+		{index: -1, ok: false},
+	}
+	for _, tc := range cases {
+		id, ok := docBlockIDAtIndex(doc, tc.index)
+		if id != tc.id || ok != tc.ok {
+			t.Fatalf("index %d: got (%q, %v) want (%q, %v)", tc.index, id, ok, tc.id, tc.ok)
+		}
+	}
+}
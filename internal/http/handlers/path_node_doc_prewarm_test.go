@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type countingConceptRepo struct {
+	repos.ConceptRepo
+	rows    []*types.Concept
+	byScope int
+}
+
+func (r *countingConceptRepo) GetByScope(dbc dbctx.Context, scope string, scopeID *uuid.UUID) ([]*types.Concept, error) {
+	r.byScope++
+	return r.rows, nil
+}
+
+type fakePathNodeRepo struct {
+	repos.PathNodeRepo
+	node *types.PathNode
+}
+
+func (r *fakePathNodeRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.PathNode, error) {
+	return r.node, nil
+}
+
+type fakePathRepo struct {
+	repos.PathRepo
+	path *types.Path
+}
+
+func (r *fakePathRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.Path, error) {
+	return r.path, nil
+}
+
+type fakeNodeDocRepo struct {
+	repos.LearningNodeDocRepo
+	doc *types.LearningNodeDoc
+	// tracks holds the node's non-primary track docs; doc is the primary one.
+	tracks map[string]*types.LearningNodeDoc
+}
+
+func (r *fakeNodeDocRepo) GetByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) (*types.LearningNodeDoc, error) {
+	return r.doc, nil
+}
+
+func (r *fakeNodeDocRepo) GetByPathNodeIDAndTrack(dbc dbctx.Context, pathNodeID uuid.UUID, track string) (*types.LearningNodeDoc, error) {
+	if track == "" || track == types.NodeDocTrackPrimary {
+		return r.doc, nil
+	}
+	return r.tracks[track], nil
+}
+
+func (r *fakeNodeDocRepo) ListTracksByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]string, error) {
+	out := []string{}
+	if r.doc != nil {
+		out = append(out, types.NodeDocTrackPrimary)
+	}
+	for _, t := range types.NodeDocTracks() {
+		if r.tracks[t] != nil {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeNodeDocRepo) UpdateWithVersion(dbc dbctx.Context, row *types.LearningNodeDoc, expectedVersion int) error {
+	return nil
+}
+
+type countingTracksRepo struct {
+	*fakeNodeDocRepo
+	listTracks int
+}
+
+func (r *countingTracksRepo) ListTracksByPathNodeID(dbc dbctx.Context, pathNodeID uuid.UUID) ([]string, error) {
+	r.listTracks++
+	return r.fakeNodeDocRepo.ListTracksByPathNodeID(dbc, pathNodeID)
+}
+
+type fakeExposureRepo struct {
+	repos.DocVariantExposureRepo
+	rows []*types.DocVariantExposure
+}
+
+func (r *fakeExposureRepo) Create(dbc dbctx.Context, row *types.DocVariantExposure) error {
+	r.rows = append(r.rows, row)
+	return nil
+}
+
+func TestGetPathNodeDocAfterPrewarmSkipsConceptScopeQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID, OutlineVersion: 3}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+	next := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 2}
+	later := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 3}
+	conceptID := uuid.New()
+	raw, err := json.Marshal(content.NodeDocV1{
+		SchemaVersion: 1,
+		Title:         "Loops",
+		ConceptKeys:   []string{"loops"},
+		Blocks: []map[string]any{
+			{"id": "p1", "type": "paragraph", "md": "Loops repeat work."},
+			{"id": "qc1", "type": "quick_check", "prompt_md": "What repeats?", "answer_md": "Loops."},
+			{"id": "fc1", "type": "flashcard", "front_md": "Loop", "back_md": "Repeat"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal doc: %v", err)
+	}
+
+	concepts := &countingConceptRepo{rows: []*types.Concept{{ID: conceptID, Key: "loops"}}}
+	exposures := &fakeExposureRepo{}
+	nodeDocs := &countingTracksRepo{fakeNodeDocRepo: &fakeNodeDocRepo{doc: &types.LearningNodeDoc{ID: uuid.New(), PathID: path.ID, PathNodeID: node.ID, DocJSON: datatypes.JSON(raw)}}}
+	pathRepo := &fakePathRepo{path: path}
+	pathNodes := &sharePathNodeRepo{nodes: []*types.PathNode{later, next, node}}
+	cache := services.NewDocServingCache(log, concepts, nil, nodeDocs)
+	prewarmer := services.NewSessionPrewarmer(log, cache, pathRepo, pathNodes, nil)
+
+	if warmed, err := prewarmer.Prewarm(context.Background(), path.ID, node.ID); err != nil || !warmed {
+		t.Fatalf("prewarm: warmed=%v err=%v", warmed, err)
+	}
+	if concepts.byScope != 1 {
+		t.Fatalf("prewarm should load the concept scope once, got %d", concepts.byScope)
+	}
+	if nodeDocs.listTracks != 2 || !cache.NodeDocTracksWarm(node.ID) || !cache.NodeDocTracksWarm(next.ID) || cache.NodeDocTracksWarm(later.ID) {
+		t.Fatalf("prewarm should load the active and next node only, got %d track loads", nodeDocs.listTracks)
+	}
+	if warmed, _ := prewarmer.Prewarm(context.Background(), path.ID, node.ID); warmed {
+		t.Fatalf("second prewarm should be skipped when warm")
+	}
+	if st := prewarmer.Stats(); st.Misses != 1 || st.Hits != 1 {
+		t.Fatalf("stats: %+v", st)
+	}
+
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:  log,
+		Path: PathHandlerPathRepos{Path: pathRepo, PathNodes: pathNodes},
+		Content: PathHandlerContentRepos{
+			NodeDocs:           nodeDocs,
+			DocVariantExposure: exposures,
+		},
+		Learning: PathHandlerLearningRepos{Concepts: concepts},
+		Services: PathHandlerServices{DocCache: cache},
+	})
+
+	getDoc := func() {
+		t.Helper()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/path-nodes/"+node.ID.String()+"/doc", nil)
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		h.GetPathNodeDoc(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	getDoc()
+	if concepts.byScope != 1 {
+		t.Fatalf("GetPathNodeDoc queried the concept scope after prewarm (%d calls)", concepts.byScope)
+	}
+	if nodeDocs.listTracks != 2 {
+		t.Fatalf("GetPathNodeDoc listed doc tracks after prewarm (%d calls)", nodeDocs.listTracks)
+	}
+	if len(exposures.rows) != 1 {
+		t.Fatalf("expected one exposure, got %d", len(exposures.rows))
+	}
+	var ids []string
+	_ = json.Unmarshal(exposures.rows[0].ConceptIDs, &ids)
+	if len(ids) != 1 || ids[0] != conceptID.String() {
+		t.Fatalf("exposure concept ids from cache: %v", ids)
+	}
+
+	// A rebuild moves the path's build stamp; the next open must reload instead of serving the
+	// previous build's concepts.
+	jobID := uuid.New()
+	path.JobID = &jobID
+	path.OutlineVersion++
+	getDoc()
+	if concepts.byScope != 2 || nodeDocs.listTracks != 3 {
+		t.Fatalf("rebuild should invalidate the cache: byScope=%d listTracks=%d", concepts.byScope, nodeDocs.listTracks)
+	}
+	if cache.NodeDocTracksWarm(next.ID) {
+		t.Fatalf("rebuild should drop the next node's cached tracks")
+	}
+}
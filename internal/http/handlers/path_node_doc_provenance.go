@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type blockProvenanceView struct {
+	BlockID       string                    `json:"block_id"`
+	Source        string                    `json:"source"`
+	PromptVersion string                    `json:"prompt_version,omitempty"`
+	PolicyVersion string                    `json:"policy_version,omitempty"`
+	GenerationRun *blockProvenanceRunView   `json:"generation_run,omitempty"`
+	Revision      *blockProvenanceRevView   `json:"revision,omitempty"`
+	Sources       []blockProvenanceChunkRef `json:"sources"`
+}
+
+type blockProvenanceRunView struct {
+	ID            uuid.UUID `json:"id"`
+	Status        string    `json:"status"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	Attempt       int       `json:"attempt"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type blockProvenanceRevView struct {
+	ID            uuid.UUID `json:"id"`
+	Operation     string    `json:"operation"`
+	Instruction   string    `json:"instruction,omitempty"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// blockProvenanceChunkRef is one cited chunk. Chunks of a file the user removed still resolve,
+// with Removed set; FileName and Page are empty only when the chunk no longer exists at all.
+type blockProvenanceChunkRef struct {
+	ChunkID        string `json:"chunk_id"`
+	MaterialFileID string `json:"material_file_id,omitempty"`
+	FileName       string `json:"file_name,omitempty"`
+	Page           *int   `json:"page,omitempty"`
+	ChunkIndex     *int   `json:"chunk_index,omitempty"`
+	Removed        bool   `json:"removed,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
+
+// GET /api/path-nodes/:id/doc/blocks/:block_id/provenance
+//
+// Resolves a block's provenance stamp (see content.BlockProvenance) into something a reader
+// can follow: the generation run or revision that wrote the block and the file/page of every
+// cited chunk. Blocks from docs generated before stamping answer 404 provenance_not_found.
+func (h *PathHandler) GetPathNodeDocBlockProvenance(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+	blockID := strings.TrimSpace(c.Param("block_id"))
+	if blockID == "" {
+		response.RespondError(c, http.StatusBadRequest, "missing_block_id", nil)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBlockProvenance failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBlockProvenance failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocBlockProvenance failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "doc_invalid", err)
+		return
+	}
+	var block map[string]any
+	for _, b := range doc.Blocks {
+		if id, _ := b["id"].(string); strings.TrimSpace(id) == blockID {
+			block = b
+			break
+		}
+	}
+	if block == nil {
+		response.RespondError(c, http.StatusNotFound, "block_not_found", nil)
+		return
+	}
+	stamp, ok := content.BlockProvenanceOf(block)
+	if !ok {
+		response.RespondError(c, http.StatusNotFound, "provenance_not_found", nil)
+		return
+	}
+
+	out := blockProvenanceView{
+		BlockID:       blockID,
+		Source:        stamp.Source,
+		PromptVersion: stamp.PromptVersion,
+		PolicyVersion: stamp.PolicyVersion,
+		Sources:       []blockProvenanceChunkRef{},
+	}
+
+	if runID, err := uuid.Parse(stamp.GenerationRunID); err == nil && h.docGenRuns != nil {
+		runs, err := h.docGenRuns.GetByIDs(dbc, []uuid.UUID{runID})
+		if err != nil {
+			h.log.Error("GetPathNodeDocBlockProvenance failed (load run)", "error", err, "run_id", runID)
+			response.RespondError(c, http.StatusInternalServerError, "load_generation_run_failed", err)
+			return
+		}
+		if len(runs) > 0 && runs[0] != nil {
+			r := runs[0]
+			out.GenerationRun = &blockProvenanceRunView{
+				ID:            r.ID,
+				Status:        r.Status,
+				Model:         r.Model,
+				PromptVersion: r.PromptVersion,
+				Attempt:       r.Attempt,
+				CreatedAt:     r.CreatedAt.UTC(),
+			}
+		}
+	}
+
+	if revID, err := uuid.Parse(stamp.RevisionID); err == nil && h.docRevisions != nil {
+		rev, err := h.docRevisions.GetByID(dbc, revID)
+		if err != nil {
+			h.log.Error("GetPathNodeDocBlockProvenance failed (load revision)", "error", err, "revision_id", revID)
+			response.RespondError(c, http.StatusInternalServerError, "load_revision_failed", err)
+			return
+		}
+		if rev != nil && rev.PathNodeID == nodeID {
+			out.Revision = &blockProvenanceRevView{
+				ID:            rev.ID,
+				Operation:     rev.Operation,
+				Instruction:   rev.Instruction,
+				Model:         rev.Model,
+				PromptVersion: rev.PromptVersion,
+				CreatedAt:     rev.CreatedAt.UTC(),
+			}
+		}
+	}
+
+	chunkIDs := make([]uuid.UUID, 0, len(stamp.ChunkIDs))
+	for _, s := range stamp.ChunkIDs {
+		out.Sources = append(out.Sources, blockProvenanceChunkRef{ChunkID: s})
+		if id, err := uuid.Parse(s); err == nil && id != uuid.Nil {
+			chunkIDs = append(chunkIDs, id)
+		}
+	}
+	if len(chunkIDs) > 0 && h.chunks != nil {
+		chunks, err := h.chunks.GetByIDsIncludingDeleted(dbc, chunkIDs)
+		if err != nil {
+			h.log.Error("GetPathNodeDocBlockProvenance failed (load chunks)", "error", err, "path_node_id", nodeID)
+			response.RespondError(c, http.StatusInternalServerError, "load_chunks_failed", err)
+			return
+		}
+		fileNames := map[uuid.UUID]string{}
+		fileRemoved := map[uuid.UUID]bool{}
+		fileIDs := []uuid.UUID{}
+		for _, ch := range chunks {
+			if ch != nil && ch.MaterialFileID != uuid.Nil {
+				if _, seen := fileNames[ch.MaterialFileID]; !seen {
+					fileNames[ch.MaterialFileID] = ""
+					fileIDs = append(fileIDs, ch.MaterialFileID)
+				}
+			}
+		}
+		if len(fileIDs) > 0 && h.materialFiles != nil {
+			files, err := h.materialFiles.GetByIDsIncludingDeleted(dbc, fileIDs)
+			if err != nil {
+				h.log.Error("GetPathNodeDocBlockProvenance failed (load files)", "error", err, "path_node_id", nodeID)
+				response.RespondError(c, http.StatusInternalServerError, "load_files_failed", err)
+				return
+			}
+			for _, f := range files {
+				if f != nil {
+					fileNames[f.ID] = f.OriginalName
+					fileRemoved[f.ID] = f.DeletedAt.Valid
+				}
+			}
+		}
+		byID := map[string]int{}
+		for i, ref := range out.Sources {
+			byID[ref.ChunkID] = i
+		}
+		for _, ch := range chunks {
+			if ch == nil {
+				continue
+			}
+			i, ok := byID[ch.ID.String()]
+			if !ok {
+				continue
+			}
+			index := ch.Index
+			out.Sources[i].MaterialFileID = ch.MaterialFileID.String()
+			out.Sources[i].FileName = fileNames[ch.MaterialFileID]
+			out.Sources[i].Page = ch.Page
+			out.Sources[i].ChunkIndex = &index
+			if ch.DeletedAt.Valid || fileRemoved[ch.MaterialFileID] {
+				out.Sources[i].Removed = true
+				out.Sources[i].Note = removedSourceNote
+			}
+		}
+	}
+
+	response.RespondOK(c, gin.H{"provenance": out})
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type regeneratePathNodeDocRequest struct {
+	// Profile names a docgen emphasis profile (examples_heavy, theory_light, exam_prep, eli5).
+	Profile          string `json:"profile"`
+	FreeTextEmphasis string `json:"free_text_emphasis"`
+}
+
+// POST /api/path-nodes/:id/doc/regenerate
+//
+// Enqueues node_doc_regenerate, which rewrites the whole doc with an emphasis profile and keeps
+// the previous content as a "regenerate" revision. A queued or running regeneration of the same
+// node with the same profile and note is returned instead of enqueueing another.
+func (h *PathHandler) RegeneratePathNodeDoc(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "RegeneratePathNodeDoc"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_service_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req regeneratePathNodeDocRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+	emphasis, err := docgen.NewNodeDocEmphasis(req.Profile, req.FreeTextEmphasis)
+	if err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_emphasis_profile", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	node, err := h.pathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("RegeneratePathNodeDoc failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbc, node.PathID)
+	if err != nil {
+		h.log.Error("RegeneratePathNodeDoc failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("RegeneratePathNodeDoc failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+	if docRow.Frozen {
+		response.RespondError(c, http.StatusConflict, "doc_frozen", nil)
+		return
+	}
+
+	materialSetID := resolvePathMaterialSetID(pathRow, nil)
+	if materialSetID == uuid.Nil && h.userLibraryIndex != nil {
+		if idx, err := h.userLibraryIndex.GetByUserAndPathID(dbc, rd.UserID, node.PathID); err == nil && idx != nil {
+			materialSetID = resolvePathMaterialSetID(pathRow, idx)
+		}
+	}
+	if materialSetID == uuid.Nil {
+		response.RespondError(c, http.StatusConflict, "material_set_missing", nil)
+		return
+	}
+
+	idempotencyKey := nodeID.String() + ":" + emphasis.Key()
+	if h.jobs != nil {
+		latest, err := h.jobs.GetLatestByEntity(dbc, rd.UserID, "path_node", nodeID, "node_doc_regenerate")
+		if err != nil {
+			h.log.Warn("RegeneratePathNodeDoc latest job lookup failed", "error", err, "path_node_id", nodeID)
+		} else if regenerateJobMatches(latest, idempotencyKey) {
+			response.RespondOK(c, gin.H{"job_id": latest.ID, "emphasis_profile": emphasis.Profile.Name, "deduped": true})
+			return
+		}
+	}
+
+	if jobCap := h.checkDocGenJobCap(c.Request.Context(), rd.UserID); jobCap.Reached() {
+		respondTooManyJobs(c, jobCap, nil)
+		return
+	}
+
+	payload := map[string]any{
+		"material_set_id":  materialSetID.String(),
+		"path_id":          node.PathID.String(),
+		"path_node_id":     nodeID.String(),
+		"emphasis_profile": emphasis.Profile.Name,
+		"idempotency_key":  idempotencyKey,
+	}
+	if emphasis.FreeText != "" {
+		payload["free_text_emphasis"] = emphasis.FreeText
+	}
+
+	entityID := nodeID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "node_doc_regenerate", "path_node", &entityID, payload)
+	if err != nil {
+		h.log.Error("RegeneratePathNodeDoc failed (enqueue)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+
+	response.RespondOK(c, gin.H{"job_id": job.ID, "emphasis_profile": emphasis.Profile.Name})
+}
+
+// regenerateJobMatches reports whether job is a queued or running regeneration carrying key.
+func regenerateJobMatches(job *types.JobRun, key string) bool {
+	if job == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(job.Status)) {
+	case "queued", "running":
+	default:
+		return false
+	}
+	var payload map[string]any
+	if json.Unmarshal(job.Payload, &payload) != nil {
+		return false
+	}
+	got, _ := payload["idempotency_key"].(string)
+	return got == key
+}
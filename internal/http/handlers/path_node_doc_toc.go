@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	docgen "github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type nodeDocTOCEntry struct {
+	BlockID string `json:"block_id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+}
+
+// GET /api/path-nodes/:id/doc/toc
+//
+// Returns the ordered block outline of the doc the user is served, so navigation can render
+// (and anchor-scroll) without shipping the full doc JSON.
+func (h *PathHandler) GetPathNodeDocTOC(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "GetPathNodeDocTOC"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocTOC failed (load node)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return
+	}
+
+	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, node.PathID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocTOC failed (load path)", "error", err, "path_id", node.PathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	if err != nil {
+		h.log.Error("GetPathNodeDocTOC failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "doc_invalid_json", err)
+		return
+	}
+	doc, _ = content.EnsureNodeDocBlockIDs(doc)
+
+	// Anchors must match the blocks GetPathNodeDoc renders, so follow the same serving decision
+	// (without logging an exposure: an outline is not a read of the content).
+	servedVariant := false
+	if _, variantDoc, _, ok := h.loadDocVariant(c, rd.UserID, nodeID); ok {
+		policy := docgen.DocPolicy(c.Request.Context())
+		if policy.Mode == "active" && h.resolveDocVariantAssignment(c.Request.Context(), rd.UserID, policy.Mode, policy.RolloutPct).Eligible {
+			if !policy.RequireSafe || h.docVariantPolicySafe(c.Request.Context(), policy) {
+				doc = variantDoc
+				servedVariant = true
+			}
+		}
+	}
+
+	response.RespondOK(c, gin.H{
+		"toc":            nodeDocTOC(doc),
+		"served_variant": servedVariant,
+		"path_node_id":   nodeIDString(node),
+	})
+}
+
+func nodeDocTOC(doc content.NodeDocV1) []nodeDocTOCEntry {
+	out := make([]nodeDocTOCEntry, 0, len(doc.Blocks))
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		out = append(out, nodeDocTOCEntry{
+			BlockID: stringFromAny(b["id"]),
+			Type:    content.BlockType(b),
+			Title:   content.BlockTitle(b),
+		})
+	}
+	return out
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// Track sources reported in doc_status and the serving decision log.
+const (
+	nodeDocTrackSourceQuery      = "query"
+	nodeDocTrackSourcePreference = "preference"
+	nodeDocTrackSourceDefault    = "default"
+)
+
+// nodeDocTrackChoice is how a doc read picked its track: the track asked for and where that came
+// from, and the track actually served (primary when the requested doc doesn't exist yet).
+type nodeDocTrackChoice struct {
+	Requested string
+	Source    string
+	Served    string
+	Fallback  bool
+}
+
+// resolveNodeDocTrack picks the requested track: ?track= when given, else the user's default
+// track preference, else primary. An unknown ?track= is a 400; an unknown stored preference is
+// ignored.
+func (h *PathHandler) resolveNodeDocTrack(c *gin.Context, userID uuid.UUID) (nodeDocTrackChoice, error) {
+	if raw := strings.TrimSpace(c.Query("track")); raw != "" {
+		track, ok := types.NormalizeNodeDocTrack(raw)
+		if !ok {
+			return nodeDocTrackChoice{}, apierr.New(http.StatusBadRequest, "invalid_track", fmt.Errorf("unknown track %q", raw))
+		}
+		return nodeDocTrackChoice{Requested: track, Source: nodeDocTrackSourceQuery}, nil
+	}
+	if track := h.preferredDocTrack(c.Request.Context(), userID); track != "" {
+		return nodeDocTrackChoice{Requested: track, Source: nodeDocTrackSourcePreference}, nil
+	}
+	return nodeDocTrackChoice{Requested: types.NodeDocTrackPrimary, Source: nodeDocTrackSourceDefault}, nil
+}
+
+// preferredDocTrack is the user's stored default track, or "" when unset or unusable.
+func (h *PathHandler) preferredDocTrack(ctx context.Context, userID uuid.UUID) string {
+	if h.profiles == nil || userID == uuid.Nil {
+		return ""
+	}
+	profile, err := h.profiles.GetByUserID(dbctx.Context{Ctx: ctx}, userID)
+	if err != nil {
+		h.log.Warn("load doc track preference failed", "error", err, "user_id", userID)
+		return ""
+	}
+	if profile == nil || strings.TrimSpace(profile.DefaultDocTrack) == "" {
+		return ""
+	}
+	track, ok := types.NormalizeNodeDocTrack(profile.DefaultDocTrack)
+	if !ok {
+		return ""
+	}
+	return track
+}
+
+// loadNodeDocForTrack loads the doc for choice.Requested, falling back to the primary doc when
+// that track has not been generated; it records the outcome on choice.
+func (h *PathHandler) loadNodeDocForTrack(ctx context.Context, nodeID uuid.UUID, choice *nodeDocTrackChoice) (*types.LearningNodeDoc, error) {
+	dbc := dbctx.Context{Ctx: ctx}
+	if choice.Requested != types.NodeDocTrackPrimary {
+		doc, err := h.nodeDocs.GetByPathNodeIDAndTrack(dbc, nodeID, choice.Requested)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil && len(doc.DocJSON) > 0 && string(doc.DocJSON) != "null" {
+			choice.Served = choice.Requested
+			return doc, nil
+		}
+		choice.Fallback = true
+	}
+	choice.Served = types.NodeDocTrackPrimary
+	return h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+}
+
+// annotate records the choice on the serving decision metadata.
+func (t nodeDocTrackChoice) annotate(meta map[string]any) {
+	meta["doc_track"] = t.Served
+	meta["requested_track"] = t.Requested
+	meta["track_source"] = t.Source
+	if t.Fallback {
+		meta["track_fallback"] = true
+	}
+}
+
+type createPathNodeDocTrackRequest struct {
+	Track string `json:"track"`
+	// Profile optionally names a docgen emphasis profile to generate the track with.
+	Profile string `json:"profile"`
+}
+
+// POST /api/path-nodes/:id/doc/tracks
+//
+// Enqueues generation of an alternate doc track for the node, grounded on the same sources as
+// the primary doc. Regenerating an existing track goes through the same endpoint. A queued or
+// running generation of the same track and profile is returned instead of enqueueing another.
+func (h *PathHandler) CreatePathNodeDocTrack(c *gin.Context) {
+	c.Request = c.Request.WithContext(ctxutil.WithOperation(c.Request.Context(), "CreatePathNodeDocTrack"))
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.jobSvc == nil {
+		response.RespondError(c, http.StatusInternalServerError, "job_service_missing", nil)
+		return
+	}
+
+	nodeID, err := uuid.Parse(c.Param("id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return
+	}
+
+	var req createPathNodeDocTrackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+	track, ok := types.NormalizeNodeDocTrack(req.Track)
+	if !ok || track == types.NodeDocTrackPrimary {
+		// The primary doc is built with the path; use /doc/regenerate to rewrite it.
+		response.RespondError(c, http.StatusBadRequest, "invalid_track", nil)
+		return
+	}
+	var emphasis *docgen.NodeDocEmphasis
+	if strings.TrimSpace(req.Profile) != "" {
+		e, err := docgen.NewNodeDocEmphasis(req.Profile, "")
+		if err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_emphasis_profile", err)
+			return
+		}
+		emphasis = &e
+	}
+
+	node, pathRow, err := h.authorizeNode(c, nodeID)
+	if err != nil {
+		h.respondAPIError(c, "CreatePathNodeDocTrack", err, "load_node_failed", "path_node_id", nodeID)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	primary, err := h.nodeDocs.GetByPathNodeID(dbc, nodeID)
+	if err != nil {
+		h.log.Error("CreatePathNodeDocTrack failed (load doc)", "error", err, "path_node_id", nodeID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if primary == nil || len(primary.DocJSON) == 0 || string(primary.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_found", nil)
+		return
+	}
+	existing, err := h.nodeDocs.GetByPathNodeIDAndTrack(dbc, nodeID, track)
+	if err != nil {
+		h.log.Error("CreatePathNodeDocTrack failed (load track doc)", "error", err, "path_node_id", nodeID, "track", track)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if existing != nil && existing.Frozen {
+		response.RespondError(c, http.StatusConflict, "doc_frozen", nil)
+		return
+	}
+
+	materialSetID := resolvePathMaterialSetID(pathRow, nil)
+	if materialSetID == uuid.Nil && h.userLibraryIndex != nil {
+		if idx, err := h.userLibraryIndex.GetByUserAndPathID(dbc, rd.UserID, node.PathID); err == nil && idx != nil {
+			materialSetID = resolvePathMaterialSetID(pathRow, idx)
+		}
+	}
+	if materialSetID == uuid.Nil {
+		response.RespondError(c, http.StatusConflict, "material_set_missing", nil)
+		return
+	}
+
+	idempotencyKey := nodeID.String() + ":" + track
+	if emphasis != nil {
+		idempotencyKey += ":" + emphasis.Key()
+	}
+	out := gin.H{"track": track}
+	if emphasis != nil {
+		out["emphasis_profile"] = emphasis.Profile.Name
+	}
+	if h.jobs != nil {
+		latest, err := h.jobs.GetLatestByEntity(dbc, rd.UserID, "path_node", nodeID, "node_doc_regenerate")
+		if err != nil {
+			h.log.Warn("CreatePathNodeDocTrack latest job lookup failed", "error", err, "path_node_id", nodeID)
+		} else if regenerateJobMatches(latest, idempotencyKey) {
+			out["job_id"] = latest.ID
+			out["deduped"] = true
+			response.RespondOK(c, out)
+			return
+		}
+	}
+
+	if jobCap := h.checkDocGenJobCap(c.Request.Context(), rd.UserID); jobCap.Reached() {
+		respondTooManyJobs(c, jobCap, nil)
+		return
+	}
+
+	payload := map[string]any{
+		"material_set_id": materialSetID.String(),
+		"path_id":         node.PathID.String(),
+		"path_node_id":    nodeID.String(),
+		"track":           track,
+		"idempotency_key": idempotencyKey,
+	}
+	if emphasis != nil {
+		payload["emphasis_profile"] = emphasis.Profile.Name
+	}
+
+	entityID := nodeID
+	job, err := h.jobSvc.Enqueue(dbc, rd.UserID, "node_doc_regenerate", "path_node", &entityID, payload)
+	if err != nil {
+		h.log.Error("CreatePathNodeDocTrack failed (enqueue)", "error", err, "path_node_id", nodeID, "track", track)
+		response.RespondError(c, http.StatusInternalServerError, "enqueue_failed", err)
+		return
+	}
+	out["job_id"] = job.ID
+	response.RespondOK(c, out)
+}
+
+type docTrackPreferenceRequest struct {
+	Track string `json:"track"`
+}
+
+// GET /api/user/doc-track
+func (h *PathHandler) GetDocTrackPreference(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	track := h.preferredDocTrack(c.Request.Context(), rd.UserID)
+	if track == "" {
+		track = types.NodeDocTrackPrimary
+	}
+	response.RespondOK(c, gin.H{"track": track, "tracks": types.NodeDocTracks()})
+}
+
+// PUT /api/user/doc-track
+//
+// Sets the track doc reads (and chat unit context) use when no track is requested. Nodes without
+// a doc on that track keep serving primary.
+func (h *PathHandler) SetDocTrackPreference(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.profiles == nil {
+		response.RespondError(c, http.StatusInternalServerError, "profile_repo_missing", nil)
+		return
+	}
+	var req docTrackPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+	track, ok := types.NormalizeNodeDocTrack(req.Track)
+	if !ok {
+		response.RespondError(c, http.StatusBadRequest, "invalid_track", nil)
+		return
+	}
+	if err := h.profiles.SetDefaultDocTrack(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, track); err != nil {
+		h.log.Error("SetDocTrackPreference failed", "error", err, "user_id", rd.UserID)
+		response.RespondError(c, http.StatusInternalServerError, "save_preference_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"track": track})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type fakeLearningProfileRepo struct {
+	repos.LearningProfileRepo
+	track string
+}
+
+func (r *fakeLearningProfileRepo) GetByUserID(dbc dbctx.Context, userID uuid.UUID) (*types.LearningProfile, error) {
+	if r.track == "" {
+		return nil, nil
+	}
+	return &types.LearningProfile{UserID: userID, DefaultDocTrack: r.track}, nil
+}
+
+func (r *fakeLearningProfileRepo) SetDefaultDocTrack(dbc dbctx.Context, userID uuid.UUID, track string) error {
+	r.track = track
+	return nil
+}
+
+func TestGetPathNodeDocTrackResolution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	userID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &userID}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1}
+	doc := func(track, title string) *types.LearningNodeDoc {
+		raw, _ := json.Marshal(map[string]any{
+			"schema_version": 1,
+			"title":          title,
+			"blocks": []map[string]any{
+				{"id": "p1", "type": "paragraph", "md": title},
+				{"id": "qc1", "type": "quick_check", "prompt_md": "Why?", "answer_md": "Because."},
+				{"id": "fc1", "type": "flashcard", "front_md": "Q", "back_md": "A"},
+			},
+		})
+		return &types.LearningNodeDoc{ID: uuid.New(), PathID: path.ID, PathNodeID: node.ID, Track: track, DocJSON: datatypes.JSON(raw)}
+	}
+	docs := &fakeNodeDocRepo{
+		doc:    doc(types.NodeDocTrackPrimary, "Primary"),
+		tracks: map[string]*types.LearningNodeDoc{types.NodeDocTrackVisual: doc(types.NodeDocTrackVisual, "Visual")},
+	}
+	profiles := &fakeLearningProfileRepo{}
+	variants := &variantCountingRepo{}
+	exposures := &fakeExposureRepo{}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log:  log,
+		Path: PathHandlerPathRepos{Path: &fakePathRepo{path: path}, PathNodes: &fakePathNodeRepo{node: node}},
+		Content: PathHandlerContentRepos{
+			NodeDocs:           docs,
+			DocVariants:        variants,
+			DocVariantExposure: exposures,
+		},
+		Learning: PathHandlerLearningRepos{Profiles: profiles},
+	})
+
+	type result struct {
+		Doc struct {
+			Title string `json:"title"`
+		} `json:"doc"`
+		DocStatus struct {
+			Track           string   `json:"track"`
+			RequestedTrack  string   `json:"requested_track"`
+			TrackFallback   bool     `json:"track_fallback"`
+			AvailableTracks []string `json:"available_tracks"`
+		} `json:"doc_status"`
+	}
+	get := func(query string) (int, result) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodGet, "/api/path-nodes/"+node.ID.String()+"/doc"+query, nil)
+		c.Request = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+		c.Params = gin.Params{{Key: "id", Value: node.ID.String()}}
+		h.GetPathNodeDoc(c)
+		var got result
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v: %s", err, w.Body.String())
+			}
+		}
+		return w.Code, got
+	}
+	lastExposureMeta := func() map[string]any {
+		t.Helper()
+		if len(exposures.rows) == 0 {
+			t.Fatalf("no exposure logged")
+		}
+		var meta map[string]any
+		_ = json.Unmarshal(exposures.rows[len(exposures.rows)-1].Metadata, &meta)
+		return meta
+	}
+
+	// No query and no preference: primary, with variants considered as before.
+	code, got := get("")
+	if code != http.StatusOK || got.Doc.Title != "Primary" || got.DocStatus.Track != types.NodeDocTrackPrimary || got.DocStatus.TrackFallback {
+		t.Fatalf("default: %d %+v", code, got)
+	}
+	if len(got.DocStatus.AvailableTracks) != 2 || got.DocStatus.AvailableTracks[1] != types.NodeDocTrackVisual {
+		t.Fatalf("available tracks = %v", got.DocStatus.AvailableTracks)
+	}
+	if variants.loads != 1 {
+		t.Fatalf("primary should consider variants (%d loads)", variants.loads)
+	}
+	if m := lastExposureMeta(); m["doc_track"] != types.NodeDocTrackPrimary || m["track_source"] != nodeDocTrackSourceDefault {
+		t.Fatalf("default exposure meta = %v", m)
+	}
+
+	// An explicit track that exists is served; variants are primary-only.
+	code, got = get("?track=visual")
+	if code != http.StatusOK || got.Doc.Title != "Visual" || got.DocStatus.Track != types.NodeDocTrackVisual {
+		t.Fatalf("visual: %d %+v", code, got)
+	}
+	if variants.loads != 1 {
+		t.Fatalf("a non-primary track should not load variants (%d loads)", variants.loads)
+	}
+
+	// A track without a doc falls back to primary and says so.
+	code, got = get("?track=hands_on")
+	if code != http.StatusOK || got.Doc.Title != "Primary" || got.DocStatus.Track != types.NodeDocTrackPrimary ||
+		got.DocStatus.RequestedTrack != types.NodeDocTrackHandsOn || !got.DocStatus.TrackFallback {
+		t.Fatalf("fallback: %d %+v", code, got)
+	}
+	if m := lastExposureMeta(); m["requested_track"] != types.NodeDocTrackHandsOn || m["track_fallback"] != true {
+		t.Fatalf("fallback exposure meta = %v", m)
+	}
+
+	if code, _ = get("?track=podcast"); code != http.StatusBadRequest {
+		t.Fatalf("unknown track: status %d", code)
+	}
+
+	// The stored preference applies when no track is given; an explicit query still wins.
+	profiles.track = types.NodeDocTrackVisual
+	code, got = get("")
+	if code != http.StatusOK || got.Doc.Title != "Visual" || got.DocStatus.RequestedTrack != types.NodeDocTrackVisual {
+		t.Fatalf("preference: %d %+v", code, got)
+	}
+	if m := lastExposureMeta(); m["track_source"] != nodeDocTrackSourcePreference {
+		t.Fatalf("preference exposure meta = %v", m)
+	}
+	code, got = get("?track=primary")
+	if code != http.StatusOK || got.Doc.Title != "Primary" {
+		t.Fatalf("query over preference: %d %+v", code, got)
+	}
+
+	// A preference for a track the node lacks falls back like a query does.
+	profiles.track = types.NodeDocTrackReading
+	code, got = get("")
+	if code != http.StatusOK || got.Doc.Title != "Primary" || !got.DocStatus.TrackFallback {
+		t.Fatalf("preference fallback: %d %+v", code, got)
+	}
+}
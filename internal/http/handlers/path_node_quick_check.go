@@ -118,6 +118,12 @@ func (h *PathHandler) bestEffortIngestQuickCheckEvent(
 		typ = types.EventQuestionAnswered
 		data["is_correct"] = out.IsCorrect
 		data["grader_confidence"] = out.Confidence
+		if out.SelectedOptionID != "" {
+			data["selected_option_id"] = out.SelectedOptionID
+		}
+		if out.MisconceptionKey != "" {
+			data["misconception_key"] = out.MisconceptionKey
+		}
 		if req.LatencyMS > 0 {
 			data["latency_ms"] = req.LatencyMS
 		}
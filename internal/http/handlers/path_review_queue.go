@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// reviewQueueMaxUpcoming caps the upcoming gate blocks listed; the prefetch job only evaluates a
+// few nodes ahead, so more than this would be old decisions.
+const reviewQueueMaxUpcoming = 5
+
+type reviewQueueDue struct {
+	ConceptID uuid.UUID `json:"concept_id"`
+	Key       string    `json:"key,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	DueAt     time.Time `json:"due_at"`
+}
+
+// reviewQueueUpcoming is a node ahead of the user whose precomputed prereq gate says they are
+// not ready for it yet.
+type reviewQueueUpcoming struct {
+	PathNodeID  uuid.UUID `json:"path_node_id"`
+	NodeIndex   int       `json:"node_index"`
+	NodeTitle   string    `json:"node_title"`
+	Decision    string    `json:"decision"`
+	Blocking    bool      `json:"blocking"`
+	Score       float64   `json:"readiness_score"`
+	ConceptKeys []string  `json:"concept_keys"`
+	Message     string    `json:"message"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+// GET /api/paths/:id/review-queue
+//
+// Lists concepts due for spaced review and the upcoming units the user is not yet ready for,
+// from gate decisions the prereq gate prefetch job computed ahead of time.
+func (h *PathHandler) GetPathReviewQueue(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	dbc := dbctx.Context{Ctx: ctx}
+	row, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("GetPathReviewQueue failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if row == nil || row.UserID == nil || *row.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	due, err := h.sessionPlanDueConcepts(ctx, rd.UserID, pathID, time.Now().UTC(), nil)
+	if err != nil {
+		h.log.Error("GetPathReviewQueue failed (load concept state)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_concept_state_failed", err)
+		return
+	}
+	concepts := map[uuid.UUID]*types.Concept{}
+	names := map[string]string{}
+	if h.concepts != nil {
+		rows, err := h.concepts.GetByScope(dbc, "path", &pathID)
+		if err != nil {
+			h.log.Warn("GetPathReviewQueue concept names unavailable", "error", err, "path_id", pathID)
+		}
+		for _, cc := range rows {
+			if cc == nil {
+				continue
+			}
+			concepts[cc.ID] = cc
+			if key := normalizeConceptKeyDoc(cc.Key); key != "" && strings.TrimSpace(cc.Name) != "" {
+				names[key] = strings.TrimSpace(cc.Name)
+			}
+		}
+	}
+	dueOut := make([]reviewQueueDue, 0, len(due))
+	for _, d := range due {
+		item := reviewQueueDue{ConceptID: d.ConceptID, DueAt: d.DueAt}
+		if cc := concepts[d.ConceptID]; cc != nil {
+			item.Key = cc.Key
+			item.Name = cc.Name
+		}
+		dueOut = append(dueOut, item)
+	}
+	sort.SliceStable(dueOut, func(i, j int) bool { return dueOut[i].DueAt.Before(dueOut[j].DueAt) })
+
+	upcoming := []reviewQueueUpcoming{}
+	if h.prereqGates != nil {
+		nodes, err := h.pathNodes.GetByPathIDs(dbc, []uuid.UUID{pathID})
+		if err != nil {
+			h.log.Error("GetPathReviewQueue failed (load nodes)", "error", err, "path_id", pathID)
+			response.RespondError(c, http.StatusInternalServerError, "load_nodes_failed", err)
+			return
+		}
+		decisions, err := h.prereqGates.ListLatestByUserAndPath(dbc, rd.UserID, pathID)
+		if err != nil {
+			h.log.Error("GetPathReviewQueue failed (load gates)", "error", err, "path_id", pathID)
+			response.RespondError(c, http.StatusInternalServerError, "load_prereq_gates_failed", err)
+			return
+		}
+		runs := map[uuid.UUID]*types.NodeRun{}
+		if h.nodeRuns != nil && len(nodes) > 0 {
+			ids := make([]uuid.UUID, 0, len(nodes))
+			for _, n := range nodes {
+				if n != nil {
+					ids = append(ids, n.ID)
+				}
+			}
+			rows, err := h.nodeRuns.ListByUserAndNodeIDs(dbc, rd.UserID, ids)
+			if err != nil {
+				h.log.Error("GetPathReviewQueue failed (load node runs)", "error", err, "path_id", pathID)
+				response.RespondError(c, http.StatusInternalServerError, "load_node_runs_failed", err)
+				return
+			}
+			for _, r := range rows {
+				if r != nil {
+					runs[r.NodeID] = r
+				}
+			}
+		}
+		upcoming = upcomingGateBlocks(nodes, runs, decisions, names, reviewQueueMaxUpcoming)
+	}
+
+	response.RespondOK(c, gin.H{"due_reviews": dueOut, "upcoming_blocks": upcoming})
+}
+
+// upcomingGateBlocks lists unopened nodes, in path order, whose latest gate decision found the
+// user not ready, with the concepts to brush up on. names maps concept keys to display names.
+func upcomingGateBlocks(nodes []*types.PathNode, runs map[uuid.UUID]*types.NodeRun, decisions []*types.PrereqGateDecision, names map[string]string, limit int) []reviewQueueUpcoming {
+	byNode := map[uuid.UUID]*types.PrereqGateDecision{}
+	for _, d := range decisions {
+		if d != nil {
+			byNode[d.PathNodeID] = d
+		}
+	}
+	ordered := make([]*types.PathNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n != nil && n.ID != uuid.Nil {
+			ordered = append(ordered, n)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+
+	out := []reviewQueueUpcoming{}
+	for _, n := range ordered {
+		if len(out) >= limit {
+			break
+		}
+		if run := runs[n.ID]; run != nil && run.State != "" && run.State != runtime.NodeRunNotStarted {
+			continue
+		}
+		d := byNode[n.ID]
+		if d == nil || !strings.EqualFold(d.ReadinessStatus, "not_ready") {
+			continue
+		}
+		var ev prereqGateEvidence
+		if len(d.EvidenceJSON) > 0 {
+			_ = json.Unmarshal(d.EvidenceJSON, &ev)
+		}
+		keys := dedupeStrings(append(append([]string{}, ev.WeakConcepts...), ev.MisconceptionConcepts...))
+		out = append(out, reviewQueueUpcoming{
+			PathNodeID:  n.ID,
+			NodeIndex:   n.Index,
+			NodeTitle:   n.Title,
+			Decision:    d.Decision,
+			Blocking:    strings.EqualFold(d.Decision, "blocked"),
+			Score:       d.ReadinessScore,
+			ConceptKeys: keys,
+			Message:     upcomingGateMessage(n.Index, keys, names),
+			EvaluatedAt: d.CreatedAt,
+		})
+	}
+	return out
+}
+
+// upcomingGateMessage reads like "Unit 7 will need a refresher on Limits and Continuity".
+func upcomingGateMessage(index int, keys []string, names map[string]string) string {
+	labels := make([]string, 0, 2)
+	for _, k := range keys {
+		if len(labels) == 2 {
+			break
+		}
+		label := names[normalizeConceptKeyDoc(k)]
+		if label == "" {
+			label = strings.ReplaceAll(k, "_", " ")
+		}
+		labels = append(labels, label)
+	}
+	switch {
+	case len(labels) == 0:
+		return fmt.Sprintf("Unit %d will need a refresher on its prerequisites", index)
+	case len(keys) > len(labels):
+		return fmt.Sprintf("Unit %d will need a refresher on %s and more", index, strings.Join(labels, ", "))
+	default:
+		return fmt.Sprintf("Unit %d will need a refresher on %s", index, strings.Join(labels, " and "))
+	}
+}
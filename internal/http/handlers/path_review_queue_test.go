@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+)
+
+func TestUpcomingGateBlocks(t *testing.T) {
+	nodes := []*types.PathNode{}
+	for i := 1; i <= 9; i++ {
+		nodes = append(nodes, &types.PathNode{ID: uuid.New(), Index: i, Title: "Unit"})
+	}
+	decision := func(n *types.PathNode, status, decision string, weak ...string) *types.PrereqGateDecision {
+		ev, _ := json.Marshal(map[string]any{"weak_concepts": weak, "misconception_concepts": []string{}})
+		return &types.PrereqGateDecision{PathNodeID: n.ID, ReadinessStatus: status, Decision: decision, EvidenceJSON: datatypes.JSON(ev)}
+	}
+	decisions := []*types.PrereqGateDecision{
+		decision(nodes[5], "not_ready", "blocked", "limits"),                                  // opened: skipped
+		decision(nodes[6], "not_ready", "blocked", "limits", "continuity"),                    // Unit 7
+		decision(nodes[7], "uncertain", "soft_remediate", "derivatives"),                      // ready enough: skipped
+		decision(nodes[8], "not_ready", "soft_remediate", "chain_rule", "limits", "products"), // Unit 9
+	}
+	runs := map[uuid.UUID]*types.NodeRun{nodes[5].ID: {State: runtime.NodeRunReading}}
+	names := map[string]string{"limits": "Limits", "continuity": "Continuity"}
+
+	got := upcomingGateBlocks(nodes, runs, decisions, names, 5)
+	if len(got) != 2 {
+		t.Fatalf("upcoming = %+v", got)
+	}
+	if got[0].NodeIndex != 7 || !got[0].Blocking || got[0].Message != "Unit 7 will need a refresher on Limits and Continuity" {
+		t.Fatalf("first = %+v", got[0])
+	}
+	if got[1].NodeIndex != 9 || got[1].Blocking || got[1].Message != "Unit 9 will need a refresher on chain rule, Limits and more" {
+		t.Fatalf("second = %+v", got[1])
+	}
+	if got := upcomingGateBlocks(nodes, runs, decisions, names, 1); len(got) != 1 {
+		t.Fatalf("limit ignored: %+v", got)
+	}
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/studyplan"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const (
+	sessionPlanDefaultMinutes = 30
+	sessionPlanMaxMinutes     = 480
+)
+
+// GET /api/paths/:id/session-plan?minutes=45
+func (h *PathHandler) GetPathSessionPlan(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	minutes := sessionPlanDefaultMinutes
+	if raw := strings.TrimSpace(c.Query("minutes")); raw != "" {
+		minutes, err = strconv.Atoi(raw)
+		if err != nil || minutes <= 0 || minutes > sessionPlanMaxMinutes {
+			response.RespondError(c, http.StatusBadRequest, "invalid_minutes", err)
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	dbc := dbctx.Context{Ctx: ctx}
+	row, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("GetPathSessionPlan failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if row == nil || row.UserID == nil || *row.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	nodes, err := h.pathNodes.GetByPathIDs(dbc, []uuid.UUID{pathID})
+	if err != nil {
+		h.log.Error("GetPathSessionPlan failed (load nodes)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_nodes_failed", err)
+		return
+	}
+
+	in := studyplan.Input{
+		UserID:        rd.UserID,
+		PathID:        pathID,
+		BudgetMinutes: minutes,
+		Estimator:     studyplan.EstimatorFromEnv(),
+	}
+
+	// A focus with concept keys narrows the plan to units and reviews on those concepts.
+	focus := h.activePathFocus(ctx, rd.UserID, pathID)
+	focusKeys := focus.ConceptKeyList()
+
+	next, drill, readBlocks, err := h.sessionPlanNextNode(ctx, rd.UserID, nodes, focusKeys)
+	if err != nil {
+		h.log.Error("GetPathSessionPlan failed (load node runs)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_node_runs_failed", err)
+		return
+	}
+	in.DrillNodeID = drill
+	if next != nil {
+		in.NodeID = next
+		blocks, err := h.sessionPlanUnreadBlocks(ctx, *next, readBlocks, in.Estimator)
+		if err != nil {
+			h.log.Error("GetPathSessionPlan failed (load doc)", "error", err, "path_node_id", *next)
+			response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+			return
+		}
+		in.Blocks = blocks
+	}
+
+	due, err := h.sessionPlanDueConcepts(ctx, rd.UserID, pathID, time.Now().UTC(), focusKeys)
+	if err != nil {
+		h.log.Error("GetPathSessionPlan failed (load concept state)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_concept_state_failed", err)
+		return
+	}
+	in.DueConcepts = due
+
+	response.RespondOK(c, gin.H{"plan": studyplan.Pack(in), "focus": focus})
+}
+
+// sessionPlanNextNode returns the first incomplete node by index, the node drills should
+// target (the next node, else the last one), and the next node's read block ids. With focus
+// keys, the first incomplete node covering one of them is preferred when there is one.
+func (h *PathHandler) sessionPlanNextNode(ctx context.Context, userID uuid.UUID, nodes []*types.PathNode, focusKeys []string) (*uuid.UUID, *uuid.UUID, map[string]bool, error) {
+	ordered := make([]*types.PathNode, 0, len(nodes))
+	ids := make([]uuid.UUID, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil || n.ID == uuid.Nil {
+			continue
+		}
+		ordered = append(ordered, n)
+		ids = append(ids, n.ID)
+	}
+	if len(ordered) == 0 {
+		return nil, nil, nil, nil
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+
+	runs := map[uuid.UUID]*types.NodeRun{}
+	if h.nodeRuns != nil {
+		rows, err := h.nodeRuns.ListByUserAndNodeIDs(dbctx.Context{Ctx: ctx}, userID, ids)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, r := range rows {
+			if r != nil {
+				runs[r.NodeID] = r
+			}
+		}
+	}
+
+	var first *types.PathNode
+	for _, n := range ordered {
+		run := runs[n.ID]
+		if run != nil && run.State == runtime.NodeRunCompleted {
+			continue
+		}
+		if first == nil {
+			first = n
+		}
+		if len(focusKeys) == 0 || sessionPlanCoversFocus(n, focusKeys) {
+			id := n.ID
+			return &id, &id, nodeRunReadBlocks(run), nil
+		}
+	}
+	if first != nil {
+		id := first.ID
+		return &id, &id, nodeRunReadBlocks(runs[first.ID]), nil
+	}
+	last := ordered[len(ordered)-1].ID
+	return nil, &last, nil, nil
+}
+
+func sessionPlanCoversFocus(node *types.PathNode, focusKeys []string) bool {
+	has := map[string]bool{}
+	for _, k := range normalizeConceptKeys(pathNodeConceptKeys(node)) {
+		has[k] = true
+	}
+	for _, k := range focusKeys {
+		if has[k] {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeRunReadBlocks(run *types.NodeRun) map[string]bool {
+	out := map[string]bool{}
+	if run == nil || len(run.Metadata) == 0 {
+		return out
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(run.Metadata, &meta); err != nil {
+		return out
+	}
+	rt, _ := meta["runtime"].(map[string]any)
+	for _, id := range stringSliceFromAny(rt["read_blocks"]) {
+		out[id] = true
+	}
+	return out
+}
+
+func (h *PathHandler) sessionPlanUnreadBlocks(ctx context.Context, nodeID uuid.UUID, read map[string]bool, est studyplan.Estimator) ([]studyplan.BlockEstimate, error) {
+	if h.nodeDocs == nil {
+		return nil, nil
+	}
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: ctx}, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	// Not generated yet: the plan falls back to reviews and a drill.
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		return nil, nil
+	}
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		h.log.Warn("session plan: invalid node doc", "error", err, "path_node_id", nodeID)
+		return nil, nil
+	}
+	out := []studyplan.BlockEstimate{}
+	for _, b := range est.NodeDocBlocks(doc) {
+		if !read[b.BlockID] {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// sessionPlanDueConcepts lists the path's concepts whose spaced review is due by now, limited
+// to focusKeys when any are given.
+func (h *PathHandler) sessionPlanDueConcepts(ctx context.Context, userID uuid.UUID, pathID uuid.UUID, now time.Time, focusKeys []string) ([]studyplan.DueConcept, error) {
+	if h.concepts == nil || h.conceptState == nil {
+		return nil, nil
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+	concepts, err := h.concepts.GetByScope(dbc, "path", &pathID)
+	if err != nil {
+		return nil, err
+	}
+	inFocus := map[string]bool{}
+	for _, k := range focusKeys {
+		inFocus[k] = true
+	}
+	ids := make([]uuid.UUID, 0, len(concepts))
+	for _, cc := range concepts {
+		if cc == nil || cc.ID == uuid.Nil {
+			continue
+		}
+		if len(inFocus) > 0 && !inFocus[normalizeConceptKeyDoc(cc.Key)] {
+			continue
+		}
+		ids = append(ids, cc.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := h.conceptState.ListByUserAndConceptIDs(dbc, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := []studyplan.DueConcept{}
+	for _, st := range rows {
+		if st == nil || st.NextReviewAt == nil || st.NextReviewAt.After(now) {
+			continue
+		}
+		out = append(out, studyplan.DueConcept{ConceptID: st.ConceptID, DueAt: *st.NextReviewAt})
+	}
+	return out, nil
+}
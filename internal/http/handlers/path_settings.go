@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type patchPathSettingsRequest struct {
+	WebEnrichment *bool `json:"web_enrichment"`
+}
+
+// PATCH /api/paths/:id/settings
+//
+// Updates per-path chat settings. web_enrichment lets chat in the path's threads consult the
+// public web when a question is clearly outside the path's materials; it is off by default.
+func (h *PathHandler) PatchPathSettings(c *gin.Context) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+	if h.path == nil {
+		response.RespondError(c, http.StatusInternalServerError, "path_repo_missing", nil)
+		return
+	}
+
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return
+	}
+
+	var req patchPathSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_json", err)
+		return
+	}
+
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	row, err := h.path.GetByID(dbc, pathID)
+	if err != nil {
+		h.log.Error("PatchPathSettings failed (load path)", "error", err, "path_id", pathID)
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return
+	}
+	if row == nil || row.UserID == nil || *row.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return
+	}
+
+	webEnrichment := types.PathChatWebEnrichmentEnabled(row.Metadata)
+	if req.WebEnrichment != nil {
+		webEnrichment = *req.WebEnrichment
+		patch := map[string]any{types.PathChatWebEnrichmentKey: webEnrichment}
+		if err := h.path.MergeMetadata(dbc, pathID, patch); err != nil {
+			h.log.Error("PatchPathSettings failed (update)", "error", err, "path_id", pathID)
+			response.RespondError(c, http.StatusInternalServerError, "update_path_failed", err)
+			return
+		}
+	}
+
+	response.RespondOK(c, gin.H{
+		"path_id":        pathID,
+		"web_enrichment": webEnrichment,
+	})
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const pathShareMaxTTL = 365 * 24 * time.Hour
+
+type createPathShareReq struct {
+	// ExpiresInHours is optional; zero means the share lives until revoked.
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
+// POST /api/paths/:id/share
+func (h *PathHandler) CreatePathShare(c *gin.Context) {
+	rd, pathRow, ok := h.loadOwnedPathForShare(c)
+	if !ok {
+		return
+	}
+	var req createPathShareReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.RespondError(c, http.StatusBadRequest, "invalid_request", err)
+			return
+		}
+	}
+	ttl := time.Duration(req.ExpiresInHours) * time.Hour
+	if req.ExpiresInHours < 0 || ttl > pathShareMaxTTL {
+		response.RespondError(c, http.StatusBadRequest, "invalid_expiry", nil)
+		return
+	}
+
+	token, err := newPathShareToken()
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "share_token_failed", err)
+		return
+	}
+	now := time.Now().UTC()
+	row := &types.PathShare{
+		ID:        uuid.New(),
+		PathID:    pathRow.ID,
+		UserID:    rd.UserID,
+		TokenHash: hashPathShareToken(token),
+		Scope:     "read_only",
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		exp := now.Add(ttl)
+		row.ExpiresAt = &exp
+	}
+	if err := h.pathShares.Create(dbctx.Context{Ctx: c.Request.Context()}, row); err != nil {
+		h.log.Error("CreatePathShare failed", "error", err, "path_id", pathRow.ID)
+		response.RespondError(c, http.StatusInternalServerError, "create_share_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{
+		"share": row,
+		"token": token,
+		"url":   pathShareURL(token),
+	})
+}
+
+// GET /api/paths/:id/shares
+func (h *PathHandler) ListPathShares(c *gin.Context) {
+	rd, pathRow, ok := h.loadOwnedPathForShare(c)
+	if !ok {
+		return
+	}
+	rows, err := h.pathShares.ListByPath(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, pathRow.ID)
+	if err != nil {
+		h.log.Error("ListPathShares failed", "error", err, "path_id", pathRow.ID)
+		response.RespondError(c, http.StatusInternalServerError, "list_shares_failed", err)
+		return
+	}
+	response.RespondOK(c, gin.H{"shares": rows})
+}
+
+// DELETE /api/paths/:id/shares/:share_id
+func (h *PathHandler) RevokePathShare(c *gin.Context) {
+	rd, _, ok := h.loadOwnedPathForShare(c)
+	if !ok {
+		return
+	}
+	shareID, err := uuid.Parse(c.Param("share_id"))
+	if err != nil || shareID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_share_id", err)
+		return
+	}
+	revoked, err := h.pathShares.Revoke(dbctx.Context{Ctx: c.Request.Context()}, rd.UserID, shareID, time.Now().UTC())
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "revoke_share_failed", err)
+		return
+	}
+	if !revoked {
+		response.RespondError(c, http.StatusNotFound, "share_not_found", nil)
+		return
+	}
+	response.RespondOK(c, gin.H{"ok": true})
+}
+
+// GET /api/shared/:token/path
+func (h *PathHandler) GetSharedPath(c *gin.Context) {
+	share, pathRow, ok := h.resolvePathShare(c)
+	if !ok {
+		return
+	}
+	nodes, err := h.pathNodes.GetByPathIDs(dbctx.Context{Ctx: c.Request.Context()}, []uuid.UUID{pathRow.ID})
+	if err != nil {
+		h.log.Error("GetSharedPath failed (load nodes)", "error", err, "path_id", pathRow.ID)
+		response.RespondError(c, http.StatusInternalServerError, "load_nodes_failed", err)
+		return
+	}
+	h.recordPathShareAccess(c, share)
+	response.RespondOK(c, gin.H{"path": buildSharedPathView(pathRow, nodes)})
+}
+
+// GET /api/shared/:token/nodes/:node_id/doc
+func (h *PathHandler) GetSharedNodeDoc(c *gin.Context) {
+	share, pathRow, ok := h.resolvePathShare(c)
+	if !ok {
+		return
+	}
+	node, ok := h.loadSharedNode(c, pathRow)
+	if !ok {
+		return
+	}
+	// Base doc only: variants, exposure logging and prereq gates are per-learner and never apply.
+	docRow, err := h.nodeDocs.GetByPathNodeID(dbctx.Context{Ctx: c.Request.Context()}, node.ID)
+	if err != nil {
+		h.log.Error("GetSharedNodeDoc failed (load doc)", "error", err, "path_node_id", node.ID)
+		response.RespondError(c, http.StatusInternalServerError, "load_doc_failed", err)
+		return
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		response.RespondError(c, http.StatusNotFound, "doc_not_ready", nil)
+		return
+	}
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "doc_invalid_json", err)
+		return
+	}
+	h.recordPathShareAccess(c, share)
+	response.RespondOK(c, gin.H{"doc": sanitizeSharedNodeDoc(doc, h.assetViewBasePath(), c.Param("token"), node)})
+}
+
+// GET /api/shared/:token/nodes/:node_id/assets/view?key=...
+func (h *PathHandler) ViewSharedNodeAsset(c *gin.Context) {
+	if h.bucket == nil {
+		response.RespondError(c, http.StatusInternalServerError, "bucket_unavailable", nil)
+		return
+	}
+	_, pathRow, ok := h.resolvePathShare(c)
+	if !ok {
+		return
+	}
+	node, ok := h.loadSharedNode(c, pathRow)
+	if !ok {
+		return
+	}
+	storageKey := strings.TrimSpace(c.Query("key"))
+	if storageKey == "" {
+		response.RespondError(c, http.StatusBadRequest, "missing_storage_key", nil)
+		return
+	}
+	// Figures only: narration audio is not part of the shared view.
+	allowed, err := h.nodeAssetKeyAllowed(c.Request.Context(), node, storageKey, false)
+	if err != nil {
+		h.log.Error("ViewSharedNodeAsset failed (load figures)", "error", err, "path_node_id", node.ID)
+		response.RespondError(c, http.StatusInternalServerError, "load_figures_failed", err)
+		return
+	}
+	if !allowed {
+		response.RespondError(c, http.StatusNotFound, "asset_not_found", nil)
+		return
+	}
+	h.streamNodeAsset(c, storageKey, "ViewSharedNodeAsset")
+}
+
+func (h *PathHandler) loadOwnedPathForShare(c *gin.Context) (*ctxutil.RequestData, *types.Path, bool) {
+	rd := ctxutil.GetRequestData(c.Request.Context())
+	if rd == nil || rd.UserID == uuid.Nil {
+		response.RespondError(c, http.StatusUnauthorized, "unauthorized", nil)
+		return nil, nil, false
+	}
+	if h.pathShares == nil || h.path == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "path_sharing_unavailable", nil)
+		return nil, nil, false
+	}
+	pathID, err := uuid.Parse(c.Param("id"))
+	if err != nil || pathID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_id", err)
+		return nil, nil, false
+	}
+	pathRow, err := h.path.GetByID(dbctx.Context{Ctx: c.Request.Context()}, pathID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return nil, nil, false
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != rd.UserID {
+		response.RespondError(c, http.StatusNotFound, "path_not_found", nil)
+		return nil, nil, false
+	}
+	return rd, pathRow, true
+}
+
+// resolvePathShare validates the token on every request (no caching), so revocation and expiry
+// take effect immediately. Unknown, revoked and expired tokens are indistinguishable to callers.
+func (h *PathHandler) resolvePathShare(c *gin.Context) (*types.PathShare, *types.Path, bool) {
+	c.Header("Cache-Control", "no-store")
+	if h.pathShares == nil || h.path == nil || h.pathNodes == nil {
+		response.RespondError(c, http.StatusServiceUnavailable, "path_sharing_unavailable", nil)
+		return nil, nil, false
+	}
+	token := strings.TrimSpace(c.Param("token"))
+	if token == "" || len(token) > 128 {
+		response.RespondError(c, http.StatusNotFound, "share_not_found", nil)
+		return nil, nil, false
+	}
+	dbc := dbctx.Context{Ctx: c.Request.Context()}
+	share, err := h.pathShares.GetByTokenHash(dbc, hashPathShareToken(token))
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_share_failed", err)
+		return nil, nil, false
+	}
+	if share == nil || !share.Active(time.Now().UTC()) {
+		response.RespondError(c, http.StatusNotFound, "share_not_found", nil)
+		return nil, nil, false
+	}
+	pathRow, err := h.path.GetByID(dbc, share.PathID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_path_failed", err)
+		return nil, nil, false
+	}
+	// A share dies with its path, and with a change of ownership.
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != share.UserID {
+		response.RespondError(c, http.StatusNotFound, "share_not_found", nil)
+		return nil, nil, false
+	}
+	return share, pathRow, true
+}
+
+func (h *PathHandler) loadSharedNode(c *gin.Context, pathRow *types.Path) (*types.PathNode, bool) {
+	nodeID, err := uuid.Parse(c.Param("node_id"))
+	if err != nil || nodeID == uuid.Nil {
+		response.RespondError(c, http.StatusBadRequest, "invalid_path_node_id", err)
+		return nil, false
+	}
+	node, err := h.pathNodes.GetByID(dbctx.Context{Ctx: c.Request.Context()}, nodeID)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "load_node_failed", err)
+		return nil, false
+	}
+	if node == nil || node.PathID != pathRow.ID {
+		response.RespondError(c, http.StatusNotFound, "node_not_found", nil)
+		return nil, false
+	}
+	return node, true
+}
+
+func (h *PathHandler) recordPathShareAccess(c *gin.Context, share *types.PathShare) {
+	if err := h.pathShares.RecordAccess(dbctx.Context{Ctx: c.Request.Context()}, share.ID, time.Now().UTC()); err != nil {
+		h.log.Warn("path share access count failed", "error", err, "share_id", share.ID)
+	}
+}
+
+type sharedPathNode struct {
+	ID           uuid.UUID  `json:"id"`
+	Index        int        `json:"index"`
+	Title        string     `json:"title"`
+	ParentNodeID *uuid.UUID `json:"parent_node_id,omitempty"`
+}
+
+type sharedPathView struct {
+	ID          uuid.UUID        `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Kind        string           `json:"kind"`
+	Nodes       []sharedPathNode `json:"nodes"`
+}
+
+// buildSharedPathView is an allowlist: only outline fields are copied, never gating, metadata,
+// progress or owner identifiers.
+func buildSharedPathView(p *types.Path, nodes []*types.PathNode) sharedPathView {
+	out := sharedPathView{
+		ID:          p.ID,
+		Title:       p.Title,
+		Description: p.Description,
+		Kind:        p.Kind,
+		Nodes:       make([]sharedPathNode, 0, len(nodes)),
+	}
+	for _, n := range nodes {
+		if n == nil || n.PathID != p.ID {
+			continue
+		}
+		out.Nodes = append(out.Nodes, sharedPathNode{ID: n.ID, Index: n.Index, Title: n.Title, ParentNodeID: n.ParentNodeID})
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].Index < out.Nodes[j].Index })
+	return out
+}
+
+// sanitizeSharedNodeDoc prepares a base doc for the shared view: the owner's private notes are
+// dropped, block assets are re-pointed at the token-scoped asset route, and stored assets the
+// route would refuse (uploaded material files) lose their URL and storage key instead of
+// leaking a private bucket path.
+func sanitizeSharedNodeDoc(doc content.NodeDocV1, assetBase string, token string, node *types.PathNode) content.NodeDocV1 {
+	content.StripUserBlocks(&doc)
+	if withIDs, changed := content.EnsureNodeDocBlockIDs(doc); changed {
+		doc = withIDs
+	}
+	if withFallback, changed := ensureNodeDocInteractiveFallback(doc); changed {
+		doc = withFallback
+	}
+	figurePrefix := fmt.Sprintf("generated/node_figures/%s/%s/", node.PathID.String(), node.ID.String())
+	content.ForEachBlockAsset(&doc, func(_ int, _ string, asset *content.MediaRefV1) bool {
+		asset.MaterialFileID = ""
+		if strings.EqualFold(strings.TrimSpace(asset.Source), "external") {
+			return true
+		}
+		key := strings.TrimSpace(asset.StorageKey)
+		if _, blob := content.FigureBlobHashFromKey(key); !blob && !strings.HasPrefix(key, figurePrefix) {
+			asset.StorageKey = ""
+			asset.URL = ""
+		}
+		return true
+	})
+	doc, _ = rewriteBlockAssetURLs(doc, sharedNodeAssetViewURLPrefix(assetBase, token, node.ID))
+	return doc
+}
+
+func newPathShareToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashPathShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// pathShareURL is the learner-facing link; PATH_SHARE_BASE_URL is the web app origin.
+func pathShareURL(token string) string {
+	base := strings.TrimRight(strings.TrimSpace(os.Getenv("PATH_SHARE_BASE_URL")), "/")
+	return base + "/shared/" + token
+}
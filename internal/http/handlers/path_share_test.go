@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type memPathShareRepo struct {
+	repos.PathShareRepo
+	rows []*types.PathShare
+}
+
+func (r *memPathShareRepo) Create(dbc dbctx.Context, row *types.PathShare) error {
+	r.rows = append(r.rows, row)
+	return nil
+}
+
+func (r *memPathShareRepo) GetByTokenHash(dbc dbctx.Context, tokenHash string) (*types.PathShare, error) {
+	for _, row := range r.rows {
+		if row.TokenHash == tokenHash {
+			cp := *row
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memPathShareRepo) Revoke(dbc dbctx.Context, userID uuid.UUID, shareID uuid.UUID, at time.Time) (bool, error) {
+	for _, row := range r.rows {
+		if row.ID == shareID && row.UserID == userID && row.RevokedAt == nil {
+			row.RevokedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memPathShareRepo) RecordAccess(dbc dbctx.Context, shareID uuid.UUID, at time.Time) error {
+	for _, row := range r.rows {
+		if row.ID == shareID {
+			row.ViewCount++
+			row.LastAccessedAt = &at
+		}
+	}
+	return nil
+}
+
+type sharePathNodeRepo struct {
+	repos.PathNodeRepo
+	nodes []*types.PathNode
+}
+
+func (r *sharePathNodeRepo) GetByID(dbc dbctx.Context, id uuid.UUID) (*types.PathNode, error) {
+	for _, n := range r.nodes {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *sharePathNodeRepo) GetByPathIDs(dbc dbctx.Context, pathIDs []uuid.UUID) ([]*types.PathNode, error) {
+	return r.nodes, nil
+}
+
+type fakeShareBucket struct {
+	gcp.BucketService
+	served []string
+}
+
+func (b *fakeShareBucket) GetObjectAttrs(ctx context.Context, category gcp.BucketCategory, key string) (*gcp.ObjectAttrs, error) {
+	return &gcp.ObjectAttrs{Size: 3, ContentType: "image/png"}, nil
+}
+
+func (b *fakeShareBucket) DownloadFile(ctx context.Context, category gcp.BucketCategory, key string) (io.ReadCloser, error) {
+	b.served = append(b.served, key)
+	return io.NopCloser(strings.NewReader("png")), nil
+}
+
+type shareFixture struct {
+	h       *PathHandler
+	shares  *memPathShareRepo
+	bucket  *fakeShareBucket
+	ownerID uuid.UUID
+	path    *types.Path
+	node    *types.PathNode
+	other   *types.PathNode
+}
+
+func newShareFixture(t *testing.T) *shareFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	ownerID := uuid.New()
+	path := &types.Path{ID: uuid.New(), UserID: &ownerID, Title: "Go basics", Kind: "path", ViewCount: 7,
+		Metadata: datatypes.JSON(`{"intake":"private"}`)}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "Loops",
+		Gating: datatypes.JSON(`{"prereq":"x"}`), Metadata: datatypes.JSON(`{"mastery":0.4}`)}
+	other := &types.PathNode{ID: uuid.New(), PathID: uuid.New(), Index: 1, Title: "Elsewhere"}
+
+	figureKey := "generated/node_figures/" + path.ID.String() + "/" + node.ID.String() + "/fig.png"
+	raw, err := json.Marshal(content.NodeDocV1{
+		SchemaVersion: 1,
+		Title:         "Loops",
+		Blocks: []map[string]any{
+			{"id": "p1", "type": "paragraph", "md": "Loops repeat work."},
+			{"id": "f1", "type": "figure", "asset": map[string]any{"storage_key": figureKey, "material_file_id": uuid.NewString()}},
+			{"id": "f2", "type": "figure", "asset": map[string]any{"storage_key": "materials/" + ownerID.String() + "/notes.png"}},
+			{"id": "f3", "type": "figure", "asset": map[string]any{"source": "external", "url": "https://example.com/x.png"}},
+			{"id": "u1", "type": "user_md", "md": "owner's private note"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal doc: %v", err)
+	}
+	shares := &memPathShareRepo{}
+	bucket := &fakeShareBucket{}
+	h := NewPathHandlerWithDeps(PathHandlerDeps{
+		Log: log,
+		Path: PathHandlerPathRepos{
+			Path:      &fakePathRepo{path: path},
+			PathNodes: &sharePathNodeRepo{nodes: []*types.PathNode{node, other}},
+			Shares:    shares,
+		},
+		Content: PathHandlerContentRepos{
+			NodeDocs: &fakeNodeDocRepo{doc: &types.LearningNodeDoc{ID: uuid.New(), PathID: path.ID, PathNodeID: node.ID, DocJSON: datatypes.JSON(raw)}},
+		},
+		Services: PathHandlerServices{Bucket: bucket},
+	})
+	return &shareFixture{h: h, shares: shares, bucket: bucket, ownerID: ownerID, path: path, node: node, other: other}
+}
+
+func (f *shareFixture) call(handler gin.HandlerFunc, method, target string, userID uuid.UUID, params gin.Params) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(method, target, nil)
+	if userID != uuid.Nil {
+		req = req.WithContext(ctxutil.WithRequestData(req.Context(), &ctxutil.RequestData{UserID: userID}))
+	}
+	c.Request = req
+	c.Params = params
+	handler(c)
+	return w
+}
+
+func (f *shareFixture) createShare(t *testing.T) (string, uuid.UUID) {
+	t.Helper()
+	w := f.call(f.h.CreatePathShare, http.MethodPost, "/api/paths/x/share", f.ownerID, gin.Params{{Key: "id", Value: f.path.ID.String()}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("create share: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Share types.PathShare `json:"share"`
+		Token string          `json:"token"`
+		URL   string          `json:"url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Token == "" || !strings.HasSuffix(body.URL, "/shared/"+body.Token) {
+		t.Fatalf("share response: %+v", body)
+	}
+	if strings.Contains(w.Body.String(), f.shares.rows[0].TokenHash) {
+		t.Fatalf("token hash must not be returned")
+	}
+	return body.Token, body.Share.ID
+}
+
+func TestSharedPathAndDocAreSanitized(t *testing.T) {
+	f := newShareFixture(t)
+	token, _ := f.createShare(t)
+
+	// Strangers can't mint shares for someone else's path.
+	if w := f.call(f.h.CreatePathShare, http.MethodPost, "/", uuid.New(), gin.Params{{Key: "id", Value: f.path.ID.String()}}); w.Code != http.StatusNotFound {
+		t.Fatalf("non-owner create: %d", w.Code)
+	}
+
+	w := f.call(f.h.GetSharedPath, http.MethodGet, "/", uuid.Nil, gin.Params{{Key: "token", Value: token}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("shared path: %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("shared responses must not be cached")
+	}
+	body := w.Body.String()
+	for _, leak := range []string{f.ownerID.String(), "gating", "metadata", "view_count", "mastery", "private", "Elsewhere"} {
+		if strings.Contains(body, leak) {
+			t.Fatalf("shared path leaks %q: %s", leak, body)
+		}
+	}
+	if !strings.Contains(body, "Loops") {
+		t.Fatalf("outline missing node: %s", body)
+	}
+
+	w = f.call(f.h.GetSharedNodeDoc, http.MethodGet, "/", uuid.Nil, gin.Params{{Key: "token", Value: token}, {Key: "node_id", Value: f.node.ID.String()}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("shared doc: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Doc content.NodeDocV1 `json:"doc"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode doc: %v", err)
+	}
+	figures := map[string]content.MediaRefV1{}
+	content.ForEachFigure(&resp.Doc, func(_ int, fb *content.FigureBlock) bool {
+		figures[fb.ID] = fb.Asset
+		return false
+	})
+	wantPrefix := "/api/shared/" + token + "/nodes/" + f.node.ID.String() + "/assets/view?key="
+	if got := figures["f1"]; !strings.HasPrefix(got.URL, wantPrefix) || got.MaterialFileID != "" {
+		t.Fatalf("generated figure: %+v", got)
+	}
+	if got := figures["f2"]; got.URL != "" || got.StorageKey != "" {
+		t.Fatalf("uploaded-material figure should be stripped: %+v", got)
+	}
+	if got := figures["f3"]; got.URL != "https://example.com/x.png" {
+		t.Fatalf("external figure: %+v", got)
+	}
+	for _, b := range resp.Doc.Blocks {
+		if content.BlockType(b) == "user_md" {
+			t.Fatalf("shared doc must not carry the owner's notes: %+v", b)
+		}
+	}
+	if strings.Contains(w.Body.String(), "private note") {
+		t.Fatalf("shared doc leaks note text: %s", w.Body.String())
+	}
+	if f.shares.rows[0].ViewCount != 2 || f.shares.rows[0].LastAccessedAt == nil {
+		t.Fatalf("access count: %+v", f.shares.rows[0])
+	}
+
+	// Nodes outside the shared path are invisible.
+	w = f.call(f.h.GetSharedNodeDoc, http.MethodGet, "/", uuid.Nil, gin.Params{{Key: "token", Value: token}, {Key: "node_id", Value: f.other.ID.String()}})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("foreign node doc: %d", w.Code)
+	}
+}
+
+func TestSharedNodeAssetIsTokenAndNodeScoped(t *testing.T) {
+	f := newShareFixture(t)
+	token, _ := f.createShare(t)
+	figureKey := "generated/node_figures/" + f.path.ID.String() + "/" + f.node.ID.String() + "/fig.png"
+	audioKey := content.NodeAudioPrefix(f.path.ID.String(), f.node.ID.String()) + "a.mp3"
+
+	asset := func(tok string, nodeID uuid.UUID, key string) int {
+		target := "/api/shared/x/nodes/y/assets/view?key=" + url.QueryEscape(key)
+		return f.call(f.h.ViewSharedNodeAsset, http.MethodGet, target, uuid.Nil,
+			gin.Params{{Key: "token", Value: tok}, {Key: "node_id", Value: nodeID.String()}}).Code
+	}
+	if code := asset(token, f.node.ID, figureKey); code != http.StatusOK {
+		t.Fatalf("figure: %d", code)
+	}
+	cases := map[string]int{
+		"bad token":     asset("nope", f.node.ID, figureKey),
+		"audio":         asset(token, f.node.ID, audioKey),
+		"material key":  asset(token, f.node.ID, "materials/"+f.ownerID.String()+"/notes.png"),
+		"foreign node":  asset(token, f.other.ID, figureKey),
+		"prefix escape": asset(token, f.node.ID, "generated/node_figures/"+f.path.ID.String()+"/"+f.other.ID.String()+"/fig.png"),
+	}
+	for name, code := range cases {
+		if code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", name, code)
+		}
+	}
+	if len(f.bucket.served) != 1 || f.bucket.served[0] != figureKey {
+		t.Fatalf("bucket reads: %v", f.bucket.served)
+	}
+}
+
+func TestPathShareRevocationAndExpiry(t *testing.T) {
+	f := newShareFixture(t)
+	token, shareID := f.createShare(t)
+	sharedPath := func() int {
+		return f.call(f.h.GetSharedPath, http.MethodGet, "/", uuid.Nil, gin.Params{{Key: "token", Value: token}}).Code
+	}
+	if code := sharedPath(); code != http.StatusOK {
+		t.Fatalf("before revoke: %d", code)
+	}
+
+	revoke := func(userID uuid.UUID) int {
+		return f.call(f.h.RevokePathShare, http.MethodDelete, "/", userID,
+			gin.Params{{Key: "id", Value: f.path.ID.String()}, {Key: "share_id", Value: shareID.String()}}).Code
+	}
+	if code := revoke(uuid.New()); code != http.StatusNotFound {
+		t.Fatalf("non-owner revoke: %d", code)
+	}
+	if code := sharedPath(); code != http.StatusOK {
+		t.Fatalf("non-owner revoke must not take effect: %d", code)
+	}
+	if code := revoke(f.ownerID); code != http.StatusOK {
+		t.Fatalf("revoke: %d", code)
+	}
+	// The very next request sees the revocation; nothing is cached by token.
+	if code := sharedPath(); code != http.StatusNotFound {
+		t.Fatalf("after revoke: %d", code)
+	}
+	figureKey := "generated/node_figures/" + f.path.ID.String() + "/" + f.node.ID.String() + "/fig.png"
+	code := f.call(f.h.ViewSharedNodeAsset, http.MethodGet, "/?key="+url.QueryEscape(figureKey), uuid.Nil,
+		gin.Params{{Key: "token", Value: token}, {Key: "node_id", Value: f.node.ID.String()}}).Code
+	if code != http.StatusNotFound || len(f.bucket.served) != 0 {
+		t.Fatalf("asset after revoke: %d served=%v", code, f.bucket.served)
+	}
+
+	expiredToken, _ := f.createShare(t)
+	past := time.Now().Add(-time.Minute)
+	f.shares.rows[len(f.shares.rows)-1].ExpiresAt = &past
+	code = f.call(f.h.GetSharedPath, http.MethodGet, "/", uuid.Nil, gin.Params{{Key: "token", Value: expiredToken}}).Code
+	if code != http.StatusNotFound {
+		t.Fatalf("expired share: %d", code)
+	}
+}
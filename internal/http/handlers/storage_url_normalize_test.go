@@ -38,6 +38,9 @@ func (t *testBucketService) CopyObject(context.Context, gcp.BucketCategory, stri
 func (t *testBucketService) ListKeys(context.Context, gcp.BucketCategory, string) ([]string, error) {
 	return nil, nil
 }
+func (t *testBucketService) ListObjects(context.Context, gcp.BucketCategory, string, string, int) (gcp.ObjectPage, error) {
+	return gcp.ObjectPage{}, nil
+}
 func (t *testBucketService) DeletePrefix(context.Context, gcp.BucketCategory, string) error {
 	return nil
 }
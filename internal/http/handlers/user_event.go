@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -126,11 +127,26 @@ func (h *EventHandler) Ingest(c *gin.Context) {
 		inputs = arr
 	}
 	dbc := dbctx.Context{Ctx: c.Request.Context()}
-	n, err := h.events.Ingest(dbc, inputs)
+	res, err := h.events.Ingest(dbc, inputs)
 	if err != nil {
-		response.RespondError(c, http.StatusBadRequest, "event_ingest_failed", err)
+		switch {
+		case errors.Is(err, services.ErrClientEventIDRequired):
+			response.RespondError(c, http.StatusBadRequest, "client_event_id_required", err)
+		case errors.Is(err, services.ErrTooManyEvents):
+			response.RespondError(c, http.StatusRequestEntityTooLarge, "too_many_events", err)
+		default:
+			response.RespondError(c, http.StatusBadRequest, "event_ingest_failed", err)
+		}
 		return
 	}
+	// Duplicates and rejected events already triggered (or never will trigger) their updates.
+	accepted := make([]services.EventInput, 0, res.Accepted)
+	for _, item := range res.Events {
+		if item.Status == services.EventIngestAccepted {
+			accepted = append(accepted, inputs[item.Index])
+		}
+	}
+	inputs = accepted
 	meaningful := false
 	trigger := ""
 	for _, ev := range inputs {
@@ -228,9 +244,14 @@ func (h *EventHandler) Ingest(c *gin.Context) {
 		}
 	}
 	response.RespondOK(c, gin.H{
-		"ok":       true,
-		"ingested": n,
-		"enqueued": enqueued,
-		"jobs":     enqueuedJobs,
+		"ok":            true,
+		"ingested":      res.Accepted,
+		"duplicates":    res.Duplicates,
+		"rejected":      res.Rejected,
+		"clamped":       res.Clamped,
+		"generated_ids": res.GeneratedIDs,
+		"events":        res.Events,
+		"enqueued":      enqueued,
+		"jobs":          enqueuedJobs,
 	})
 }
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -72,3 +73,34 @@ func extractTokenFromAll(c *gin.Context) string {
 	}
 	return ""
 }
+
+// EnvAdminUserIDs is a comma-separated list of user IDs allowed on admin routes.
+const EnvAdminUserIDs = "ADMIN_USER_IDS"
+
+// RequireAdmin admits only authenticated users listed in ADMIN_USER_IDS; with the list unset
+// admin routes are closed to everyone. Mount it after RequireAuth.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rd := ctxutil.GetRequestData(c.Request.Context())
+		if rd == nil || rd.UserID == uuid.Nil || !adminUserIDs()[rd.UserID] {
+			if metrics := observability.Current(); metrics != nil {
+				metrics.IncSecurityEvent("forbidden")
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": gin.H{"message": "forbidden", "code": "forbidden"},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func adminUserIDs() map[uuid.UUID]bool {
+	out := map[uuid.UUID]bool{}
+	for _, raw := range strings.Split(os.Getenv(EnvAdminUserIDs), ",") {
+		if id, err := uuid.Parse(strings.TrimSpace(raw)); err == nil && id != uuid.Nil {
+			out[id] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admin, other := uuid.New(), uuid.New()
+
+	status := func(userID uuid.UUID) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if userID != uuid.Nil {
+				c.Request = c.Request.WithContext(ctxutil.WithRequestData(c.Request.Context(), &ctxutil.RequestData{UserID: userID}))
+			}
+		}, RequireAdmin())
+		r.GET("/admin", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return rec.Code
+	}
+
+	if got := status(admin); got != http.StatusForbidden {
+		t.Fatalf("unset allowlist: got %d", got)
+	}
+	t.Setenv(EnvAdminUserIDs, "not-a-uuid, "+admin.String())
+	if got := status(admin); got != http.StatusNoContent {
+		t.Fatalf("admin: got %d", got)
+	}
+	if got := status(other); got != http.StatusForbidden {
+		t.Fatalf("non-admin: got %d", got)
+	}
+	if got := status(uuid.Nil); got != http.StatusForbidden {
+		t.Fatalf("anonymous: got %d", got)
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+
+	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+// RateLimiter is an in-process token bucket per caller (user id when authenticated, client IP
+// otherwise). It is meant for expensive routes; limits are per API instance, not global.
+type RateLimiter struct {
+	name  string
+	limit rate.Limit
+	burst int
+	idle  time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	sweptAt time.Time
+}
+
+type rateBucket struct {
+	lim  *rate.Limiter
+	seen time.Time
+}
+
+// NewRateLimiter allows perMinute requests per caller with the given burst. perMinute <= 0
+// disables the limiter.
+func NewRateLimiter(name string, perMinute float64, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &RateLimiter{
+		name:    name,
+		limit:   rate.Limit(perMinute / 60),
+		burst:   burst,
+		idle:    10 * time.Minute,
+		buckets: map[string]*rateBucket{},
+	}
+}
+
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl == nil || rl.limit <= 0 {
+			c.Next()
+			return
+		}
+		key := "ip:" + c.ClientIP()
+		if rd := ctxutil.GetRequestData(c.Request.Context()); rd != nil && rd.UserID != uuid.Nil {
+			key = "user:" + rd.UserID.String()
+		}
+		lim := rl.bucket(key, time.Now())
+		res := lim.Reserve()
+		if delay := res.Delay(); delay > 0 {
+			res.Cancel()
+			if metrics := observability.Current(); metrics != nil {
+				metrics.IncSecurityEvent("rate_limited")
+			}
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": gin.H{"message": "too many requests", "code": "rate_limited", "route": rl.name},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (rl *RateLimiter) bucket(key string, now time.Time) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if now.Sub(rl.sweptAt) > rl.idle {
+		for k, b := range rl.buckets {
+			if now.Sub(b.seen) > rl.idle {
+				delete(rl.buckets, k)
+			}
+		}
+		rl.sweptAt = now
+	}
+	b := rl.buckets[key]
+	if b == nil {
+		b = &rateBucket{lim: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.buckets[key] = b
+	}
+	b.seen = now
+	return b.lim
+}
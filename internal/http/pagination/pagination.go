@@ -0,0 +1,237 @@
+// Package pagination is the shared query-parameter and response convention for list
+// endpoints:
+//
+//	?cursor=<opaque>&limit=<n>&sort=<field|-field>&fields=<a,b,c>
+//
+// and the envelope {"items": [...], "next_cursor": "", "has_more": false, "total": n}, where
+// total is only present when the endpoint can count cheaply.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
+	"github.com/yungbote/neurobridge-backend/internal/http/response"
+)
+
+// Error codes shared by every list endpoint.
+const (
+	CodeInvalidCursor = "invalid_cursor"
+	CodeInvalidLimit  = "invalid_limit"
+	CodeInvalidSort   = "invalid_sort"
+	CodeInvalidFields = "invalid_fields"
+)
+
+// Spec declares what one list endpoint accepts.
+type Spec struct {
+	DefaultLimit int
+	// MaxLimit caps limit; larger values are rejected rather than silently clamped.
+	MaxLimit int
+	// Sorts is the sort allowlist ("created_at" ascending, "-created_at" descending). The first
+	// entry is the default.
+	Sorts []string
+	// Fields is the allowlist for sparse fieldsets; empty disables ?fields=.
+	Fields []string
+}
+
+// Params are validated list parameters.
+type Params struct {
+	After *keyset.Cursor
+	Limit int
+	// Sort is the field without its direction prefix.
+	Sort string
+	Desc bool
+	// Fields is the requested sparse fieldset; nil means every field.
+	Fields []string
+}
+
+// Error is a rejected list parameter.
+type Error struct {
+	Code string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Code
+	}
+	return e.Code + ": " + e.Err.Error()
+}
+
+// ParseListParams validates the list query parameters of c against spec.
+func ParseListParams(c *gin.Context, spec Spec) (Params, *Error) {
+	p := Params{Limit: spec.DefaultLimit}
+
+	after, err := DecodeTimeIDCursor(c.Query("cursor"))
+	if err != nil {
+		return p, &Error{Code: CodeInvalidCursor, Err: err}
+	}
+	p.After = after
+
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return p, &Error{Code: CodeInvalidLimit, Err: fmt.Errorf("limit must be a positive integer")}
+		}
+		if spec.MaxLimit > 0 && n > spec.MaxLimit {
+			return p, &Error{Code: CodeInvalidLimit, Err: fmt.Errorf("limit must be at most %d", spec.MaxLimit)}
+		}
+		p.Limit = n
+	}
+
+	sort := strings.TrimSpace(c.Query("sort"))
+	if sort == "" && len(spec.Sorts) > 0 {
+		sort = spec.Sorts[0]
+	}
+	if sort != "" {
+		if !contains(spec.Sorts, sort) {
+			return p, &Error{Code: CodeInvalidSort, Err: fmt.Errorf("sort must be one of %s", strings.Join(spec.Sorts, ", "))}
+		}
+		p.Sort, p.Desc = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	}
+
+	if raw := strings.TrimSpace(c.Query("fields")); raw != "" {
+		for _, f := range strings.Split(raw, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if !contains(spec.Fields, f) {
+				return p, &Error{Code: CodeInvalidFields, Err: fmt.Errorf("unknown field %q", f)}
+			}
+			if !contains(p.Fields, f) {
+				p.Fields = append(p.Fields, f)
+			}
+		}
+	}
+	return p, nil
+}
+
+// RespondParamError writes a rejected parameter as a 400 with its shared code.
+func RespondParamError(c *gin.Context, err *Error) {
+	response.RespondError(c, http.StatusBadRequest, err.Code, err.Err)
+}
+
+// Page is one page of a list response.
+type Page struct {
+	Items any
+	// Next is the cursor of the last item when another page exists.
+	Next *keyset.Cursor
+	// Total is set only when it is cheap to compute.
+	Total *int64
+	// Extra holds endpoint-specific top-level keys (e.g. an unread count).
+	Extra gin.H
+}
+
+// RespondList writes page in the shared envelope, applying the sparse fieldset from p.
+func RespondList(c *gin.Context, p Params, page Page) {
+	items, err := selectFields(page.Items, p.Fields)
+	if err != nil {
+		response.RespondError(c, http.StatusInternalServerError, "encode_list_failed", err)
+		return
+	}
+	out := gin.H{}
+	for k, v := range page.Extra {
+		out[k] = v
+	}
+	out["items"] = items
+	out["next_cursor"] = ""
+	out["has_more"] = page.Next != nil
+	if page.Next != nil {
+		out["next_cursor"] = EncodeTimeIDCursor(*page.Next)
+	}
+	if page.Total != nil {
+		out["total"] = *page.Total
+	}
+	response.RespondOK(c, out)
+}
+
+// selectFields drops unrequested top-level fields from each item. Items are marshaled once and
+// filtered as raw JSON, so nested values are untouched.
+func selectFields(items any, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+	out := make([]map[string]json.RawMessage, 0, len(rows))
+	for _, row := range rows {
+		kept := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := row[f]; ok {
+				kept[f] = v
+			}
+		}
+		out = append(out, kept)
+	}
+	return out, nil
+}
+
+// EncodeCursor packs a keyset tuple into an opaque token.
+func EncodeCursor(parts ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "|")))
+}
+
+// DecodeCursor unpacks a token produced by EncodeCursor with exactly n parts. An empty token
+// decodes to nil.
+func DecodeCursor(s string, n int) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(string(b), "|", n)
+	if len(parts) != n {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return parts, nil
+}
+
+// EncodeTimeIDCursor encodes a (timestamp, id) keyset position.
+func EncodeTimeIDCursor(cur keyset.Cursor) string {
+	return EncodeCursor(cur.CreatedAt.UTC().Format(time.RFC3339Nano), cur.ID.String())
+}
+
+// DecodeTimeIDCursor decodes a token produced by EncodeTimeIDCursor.
+func DecodeTimeIDCursor(s string) (*keyset.Cursor, error) {
+	parts, err := DecodeCursor(s, 2)
+	if err != nil || parts == nil {
+		return nil, err
+	}
+	ts, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil || id == uuid.Nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &keyset.Cursor{CreatedAt: ts, ID: id}, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/keyset"
+)
+
+func TestTimeIDCursorRoundTrip(t *testing.T) {
+	cur := keyset.Cursor{CreatedAt: time.Date(2026, 4, 2, 8, 30, 0, 5000, time.UTC), ID: uuid.New()}
+	got, err := DecodeTimeIDCursor(EncodeTimeIDCursor(cur))
+	if err != nil || got == nil || !got.CreatedAt.Equal(cur.CreatedAt) || got.ID != cur.ID {
+		t.Fatalf("round trip: got=%+v err=%v", got, err)
+	}
+	if got, err := DecodeTimeIDCursor(""); err != nil || got != nil {
+		t.Fatalf("empty cursor: got=%+v err=%v", got, err)
+	}
+	if _, err := DecodeTimeIDCursor("bm90LWEtY3Vyc29y"); err == nil {
+		t.Fatalf("expected malformed cursor error")
+	}
+}
+
+func TestParseListParamsDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := Spec{DefaultLimit: 20, MaxLimit: 50, Sorts: []string{"-created_at", "created_at"}, Fields: []string{"id", "title"}}
+	parse := func(query string) (Params, *Error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/x?"+query, nil)
+		return ParseListParams(c, spec)
+	}
+
+	p, perr := parse("")
+	if perr != nil || p.Limit != 20 || p.Sort != "created_at" || !p.Desc || p.After != nil || p.Fields != nil {
+		t.Fatalf("defaults: %+v %v", p, perr)
+	}
+	p, perr = parse("limit=50&sort=created_at&fields=title,id,title")
+	if perr != nil || p.Limit != 50 || p.Desc || len(p.Fields) != 2 {
+		t.Fatalf("explicit: %+v %v", p, perr)
+	}
+	for query, code := range map[string]string{
+		"limit=0":       CodeInvalidLimit,
+		"limit=51":      CodeInvalidLimit,
+		"sort=-title":   CodeInvalidSort,
+		"fields=body":   CodeInvalidFields,
+		"cursor=bogus!": CodeInvalidCursor,
+	} {
+		if _, perr := parse(query); perr == nil || perr.Code != code {
+			t.Fatalf("%s: got %v, want %s", query, perr, code)
+		}
+	}
+}
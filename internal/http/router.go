@@ -9,6 +9,7 @@ import (
 
 	httpH "github.com/yungbote/neurobridge-backend/internal/http/handlers"
 	httpMW "github.com/yungbote/neurobridge-backend/internal/http/middleware"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
 )
 
 type RouterConfig struct {
@@ -20,6 +21,7 @@ type RouterConfig struct {
 
 	MaterialHandler *httpH.MaterialHandler
 	ChatHandler     *httpH.ChatHandler
+	ChatPlanHandler *httpH.ChatContextPlanHandler
 	LibraryHandler  *httpH.LibraryHandler
 	PathHandler     *httpH.PathHandler
 	RuntimeHandler  *httpH.RuntimeStateHandler
@@ -28,7 +30,15 @@ type RouterConfig struct {
 	GazeHandler     *httpH.GazeHandler
 	JobHandler      *httpH.JobHandler
 
-	HealthHandler *httpH.HealthHandler
+	NotificationHandler      *httpH.NotificationHandler
+	LearningStateHandler     *httpH.LearningStateHandler
+	DocVariantOutcomeHandler *httpH.DocVariantOutcomeHandler
+
+	HealthHandler      *httpH.HealthHandler
+	DiagnosticsHandler *httpH.DiagnosticsHandler
+	TraceHandler       *httpH.TraceHandler
+
+	ContextBudgetHandler *httpH.ContextBudgetHandler
 }
 
 func NewRouter(cfg RouterConfig) *gin.Engine {
@@ -45,6 +55,14 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			r.GET("/health/metrics", cfg.HealthHandler.MetricsHealth)
 		}
 	}
+	if cfg.DiagnosticsHandler != nil && envutil.Bool("DIAGNOSTICS_ENABLED", false) {
+		r.GET("/diagnostics/queries", cfg.DiagnosticsHandler.QueryStats)
+		r.POST("/diagnostics/queries/reset", cfg.DiagnosticsHandler.ResetQueryStats)
+		r.GET("/diagnostics/log-throttle", cfg.DiagnosticsHandler.LogThrottleStats)
+		r.GET("/diagnostics/feature-flags", cfg.DiagnosticsHandler.FeatureFlags)
+		r.GET("/diagnostics/job-scheduler", cfg.DiagnosticsHandler.JobScheduler)
+		r.GET("/diagnostics/consistency-audit", cfg.DiagnosticsHandler.ConsistencyAudit)
+	}
 
 	api := r.Group("/api")
 	{
@@ -56,6 +74,17 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			api.POST("/oauth/google", cfg.AuthHandler.OAuthGoogle)
 			api.POST("/oauth/apple", cfg.AuthHandler.OAuthApple)
 		}
+
+		// Shared paths (public, token-scoped, read-only)
+		if cfg.PathHandler != nil {
+			pageLimiter := httpMW.NewRateLimiter("shared_path",
+				envutil.Float("PATH_SHARE_RATE_LIMIT_PER_MIN", 30), envutil.Int("PATH_SHARE_RATE_LIMIT_BURST", 10))
+			assetLimiter := httpMW.NewRateLimiter("shared_path_asset",
+				envutil.Float("PATH_SHARE_ASSET_RATE_LIMIT_PER_MIN", 120), envutil.Int("PATH_SHARE_ASSET_RATE_LIMIT_BURST", 30))
+			api.GET("/shared/:token/path", pageLimiter.Handler(), cfg.PathHandler.GetSharedPath)
+			api.GET("/shared/:token/nodes/:node_id/doc", pageLimiter.Handler(), cfg.PathHandler.GetSharedNodeDoc)
+			api.GET("/shared/:token/nodes/:node_id/assets/view", assetLimiter.Handler(), cfg.PathHandler.ViewSharedNodeAsset)
+		}
 	}
 
 	protected := api.Group("/")
@@ -89,6 +118,8 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			protected.POST("/user/avatar/upload", cfg.UserHandler.UploadAvatar)
 			protected.GET("/user/personalization", cfg.UserHandler.GetPersonalizationPrefs)
 			protected.PATCH("/user/personalization", cfg.UserHandler.PatchPersonalizationPrefs)
+			protected.GET("/user/doc-track", cfg.PathHandler.GetDocTrackPreference)
+			protected.PUT("/user/doc-track", cfg.PathHandler.SetDocTrackPreference)
 		}
 
 		// Session (runtime state)
@@ -103,6 +134,8 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			protected.GET("/material-files", cfg.MaterialHandler.ListUserMaterialFiles)
 			protected.GET("/material-files/:id/view", cfg.MaterialHandler.ViewMaterialFile)
 			protected.GET("/material-files/:id/thumbnail", cfg.MaterialHandler.ViewMaterialFileThumbnail)
+			protected.DELETE("/material-files/:id", cfg.MaterialHandler.DeleteMaterialFile)
+			protected.POST("/material-files/:id/relink", cfg.MaterialHandler.RelinkMaterialFile)
 			protected.GET("/material-assets/:id/view", cfg.MaterialHandler.ViewMaterialAsset)
 		}
 
@@ -121,6 +154,11 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 
 			protected.GET("/chat/turns/:id", cfg.ChatHandler.GetTurn)
 		}
+		// Debug: assembled context plan without generation (disabled unless explicitly enabled).
+		if cfg.ChatPlanHandler != nil {
+			limiter := httpMW.NewRateLimiter("chat_context_plan_preview", envutil.Float("CHAT_CONTEXT_PLAN_PREVIEW_RATE_LIMIT_PER_MIN", 10), 3)
+			protected.POST("/chat/context-plan/preview", limiter.Handler(), cfg.ChatPlanHandler.Preview)
+		}
 
 		// Library (taxonomy snapshot)
 		if cfg.LibraryHandler != nil {
@@ -134,17 +172,43 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 			protected.GET("/paths/:id", cfg.PathHandler.GetPath)
 			protected.DELETE("/paths/:id", cfg.PathHandler.DeletePath)
 			protected.POST("/paths/:id/view", cfg.PathHandler.ViewPath)
+			protected.PATCH("/paths/:id/settings", cfg.PathHandler.PatchPathSettings)
 			protected.POST("/paths/:id/cover", cfg.PathHandler.GeneratePathCover)
+			protected.POST("/paths/:id/share", cfg.PathHandler.CreatePathShare)
+			protected.GET("/paths/:id/shares", cfg.PathHandler.ListPathShares)
+			protected.DELETE("/paths/:id/shares/:share_id", cfg.PathHandler.RevokePathShare)
+			protected.GET("/paths/:id/focus", cfg.PathHandler.GetPathFocus)
+			protected.POST("/paths/:id/focus", cfg.PathHandler.StartPathFocus)
+			protected.DELETE("/paths/:id/focus", cfg.PathHandler.EndPathFocus)
 			protected.GET("/paths/:id/materials", cfg.PathHandler.ListPathMaterials)
+			protected.POST("/paths/:id/files/:file_id/reindex-concepts", cfg.PathHandler.ReindexPathFileConcepts)
 			protected.GET("/paths/:id/nodes", cfg.PathHandler.ListPathNodes)
 			protected.GET("/paths/:id/concept-graph", cfg.PathHandler.GetConceptGraph)
+			protected.POST("/paths/:id/concepts/:concept_key/known", cfg.PathHandler.MarkPathConceptKnown)
+			protected.GET("/paths/:id/session-plan", cfg.PathHandler.GetPathSessionPlan)
+			protected.GET("/paths/:id/review-queue", cfg.PathHandler.GetPathReviewQueue)
+			protected.GET("/concept-edges/:id", cfg.PathHandler.GetConceptEdge)
+			protected.GET("/paths/:id/activity", cfg.PathHandler.ListPathActivity)
+			protected.GET("/doc-search", cfg.PathHandler.SearchDocs)
+			protected.PUT("/path-nodes/:id/description", cfg.PathHandler.PutPathNodeDescription)
 			protected.GET("/path-nodes/:id/activities", cfg.PathHandler.ListPathNodeActivities)
+			protected.PUT("/path-nodes/:id/activities", cfg.PathHandler.PutPathNodeActivities)
 			protected.GET("/path-nodes/:id/content", cfg.PathHandler.GetPathNodeContent)
 			protected.GET("/path-nodes/:id/doc", cfg.PathHandler.GetPathNodeDoc)
+			protected.GET("/path-nodes/:id/doc/base", cfg.PathHandler.GetPathNodeDocBase)
+			protected.GET("/path-nodes/:id/doc/toc", cfg.PathHandler.GetPathNodeDocTOC)
 			protected.GET("/path-nodes/:id/assets/view", cfg.PathHandler.ViewPathNodeAsset)
 			protected.POST("/path-nodes/:id/doc/patch", cfg.PathHandler.EnqueuePathNodeDocPatch)
+			protected.POST("/path-nodes/:id/doc/freeze", cfg.PathHandler.FreezePathNodeDoc)
+			protected.POST("/path-nodes/:id/doc/regenerate", cfg.PathHandler.RegeneratePathNodeDoc)
+			protected.POST("/path-nodes/:id/doc/tracks", cfg.PathHandler.CreatePathNodeDocTrack)
 			protected.GET("/path-nodes/:id/doc/revisions", cfg.PathHandler.ListPathNodeDocRevisions)
 			protected.GET("/path-nodes/:id/doc/materials", cfg.PathHandler.ListPathNodeDocMaterials)
+			protected.GET("/path-nodes/:id/doc/blocks/:block_id/provenance", cfg.PathHandler.GetPathNodeDocBlockProvenance)
+			protected.POST("/path-nodes/:id/doc/block-view", cfg.PathHandler.RecordPathNodeBlockView)
+			protected.POST("/path-nodes/:id/doc/narrate", cfg.PathHandler.EnqueuePathNodeDocNarration)
+			protected.GET("/path-nodes/:id/audio", cfg.PathHandler.GetPathNodeAudio)
+			protected.POST("/path-nodes/:id/audio/progress", cfg.PathHandler.RecordPathNodeAudioProgress)
 			protected.GET("/path-nodes/:id/drills", cfg.PathHandler.ListPathNodeDrills)
 			protected.POST("/path-nodes/:id/drills/:kind", cfg.PathHandler.GeneratePathNodeDrill)
 			protected.POST("/path-nodes/:id/quick-checks/:block_id/attempt", cfg.PathHandler.AttemptPathNodeQuickCheck)
@@ -171,16 +235,58 @@ func NewRouter(cfg RouterConfig) *gin.Engine {
 
 		// Job
 		if cfg.JobHandler != nil {
+			protected.GET("/jobs", cfg.JobHandler.ListJobs)
 			protected.GET("/jobs/:id", cfg.JobHandler.GetJob)
 			protected.POST("/jobs/:id/cancel", cfg.JobHandler.CancelJob)
 			protected.POST("/jobs/:id/restart", cfg.JobHandler.RestartJob)
 		}
 
+		// Notifications
+		if cfg.NotificationHandler != nil {
+			protected.GET("/notifications", cfg.NotificationHandler.ListNotifications)
+			protected.POST("/notifications/read-all", cfg.NotificationHandler.MarkAllNotificationsRead)
+			protected.POST("/notifications/:id/read", cfg.NotificationHandler.MarkNotificationRead)
+		}
+
+		// Learning state portability (expensive: full-state reads / embedding-backed imports)
+		if cfg.LearningStateHandler != nil {
+			limiter := learningStateRateLimiter()
+			protected.GET("/export/learning-state", limiter.Handler(), cfg.LearningStateHandler.Export)
+			protected.POST("/import/learning-state", limiter.Handler(), cfg.LearningStateHandler.Import)
+		}
+
+		if cfg.DocVariantOutcomeHandler != nil {
+			protected.GET("/doc-variant-outcomes/labels", cfg.DocVariantOutcomeHandler.ListLabels)
+		}
+
+		// Admin (support tooling)
+		admin := protected.Group("/admin")
+		admin.Use(httpMW.RequireAdmin())
+		if cfg.PathHandler != nil {
+			admin.GET("/users/:id/variant-assignment", cfg.PathHandler.GetUserVariantAssignment)
+			admin.GET("/variant-assignments/arms", cfg.PathHandler.GetVariantAssignmentArmCounts)
+			admin.POST("/paths/:id/recanonicalize", cfg.PathHandler.RecanonicalizePathConcepts)
+		}
+		if cfg.TraceHandler != nil {
+			admin.GET("/trace/:trace_id", cfg.TraceHandler.GetTraceTimeline)
+		}
+		if cfg.ContextBudgetHandler != nil {
+			admin.GET("/context-budget/summary", cfg.ContextBudgetHandler.GetSummary)
+		}
+
 	}
 
 	return r
 }
 
+// learningStateRateLimiter is shared by export and import so alternating the two doesn't double
+// the budget.
+func learningStateRateLimiter() *httpMW.RateLimiter {
+	perMinute := envutil.Float("LEARNING_STATE_RATE_LIMIT_PER_MIN", 4)
+	burst := envutil.Int("LEARNING_STATE_RATE_LIMIT_BURST", 2)
+	return httpMW.NewRateLimiter("learning_state", perMinute, burst)
+}
+
 func metricsHealthRouteEnabled() bool {
 	v := strings.TrimSpace(os.Getenv("METRICS_HEALTHCHECK_ENABLED"))
 	if v == "" {
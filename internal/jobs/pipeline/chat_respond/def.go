@@ -8,6 +8,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/platform/websearch"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
@@ -30,6 +31,7 @@ type Pipeline struct {
 	nodeDocs  repos.LearningNodeDocRepo
 	concepts  repos.ConceptRepo
 	edges     repos.ConceptEdgeRepo
+	evidence  repos.ConceptEvidenceRepo
 	mastery   repos.UserConceptStateRepo
 	models    repos.UserConceptModelRepo
 	miscon    repos.UserMisconceptionInstanceRepo
@@ -38,6 +40,17 @@ type Pipeline struct {
 	jobRuns repos.JobRunRepo
 	jobs    services.JobService
 	notify  services.ChatNotifier
+
+	toolExecs repos.ChatToolExecutionRepo
+	chunks    repos.MaterialChunkRepo
+	drills    repos.LearningDrillInstanceRepo
+	genRuns   repos.LearningDocGenerationRunRepo
+	outlines  services.PathOutlineService
+	web       websearch.Provider
+	pathFocus repos.PathFocusRepo
+
+	budgetStats services.ContextBudgetSampler
+	profiles    repos.LearningProfileRepo
 }
 
 func New(
@@ -57,6 +70,7 @@ func New(
 	nodeDocs repos.LearningNodeDocRepo,
 	concepts repos.ConceptRepo,
 	edges repos.ConceptEdgeRepo,
+	evidence repos.ConceptEvidenceRepo,
 	mastery repos.UserConceptStateRepo,
 	models repos.UserConceptModelRepo,
 	miscon repos.UserMisconceptionInstanceRepo,
@@ -64,6 +78,15 @@ func New(
 	jobRuns repos.JobRunRepo,
 	jobs services.JobService,
 	notify services.ChatNotifier,
+	toolExecs repos.ChatToolExecutionRepo,
+	chunks repos.MaterialChunkRepo,
+	drills repos.LearningDrillInstanceRepo,
+	genRuns repos.LearningDocGenerationRunRepo,
+	outlines services.PathOutlineService,
+	web websearch.Provider,
+	pathFocus repos.PathFocusRepo,
+	budgetStats services.ContextBudgetSampler,
+	profiles repos.LearningProfileRepo,
 ) *Pipeline {
 	return &Pipeline{
 		db:        db,
@@ -82,6 +105,7 @@ func New(
 		nodeDocs:  nodeDocs,
 		concepts:  concepts,
 		edges:     edges,
+		evidence:  evidence,
 		mastery:   mastery,
 		models:    models,
 		miscon:    miscon,
@@ -89,6 +113,16 @@ func New(
 		jobRuns:   jobRuns,
 		jobs:      jobs,
 		notify:    notify,
+		toolExecs: toolExecs,
+		chunks:    chunks,
+		drills:    drills,
+		genRuns:   genRuns,
+		outlines:  outlines,
+		web:       web,
+		pathFocus: pathFocus,
+
+		budgetStats: budgetStats,
+		profiles:    profiles,
 	}
 }
 
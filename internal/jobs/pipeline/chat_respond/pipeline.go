@@ -7,6 +7,7 @@ import (
 
 	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
 	chatmod "github.com/yungbote/neurobridge-backend/internal/modules/chat"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
 )
 
 func (p *Pipeline) Run(jc *jobrt.Context) error {
@@ -52,6 +53,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		NodeDocs:     p.nodeDocs,
 		Concepts:     p.concepts,
 		ConceptEdges: p.edges,
+		Evidence:     p.evidence,
 		ConceptState: p.mastery,
 		ConceptModel: p.models,
 		MisconRepo:   p.miscon,
@@ -59,6 +61,26 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		JobRuns:      p.jobRuns,
 		Jobs:         p.jobs,
 		Notify:       p.notify,
+		ToolExecs:    p.toolExecs,
+		Outlines:     p.outlines,
+		Web:          p.web,
+		Focus:        p.pathFocus,
+		BudgetStats:  p.budgetStats,
+		Profiles:     p.profiles,
+		PathActions: learningmod.New(learningmod.UsecasesDeps{
+			DB:           p.db,
+			Log:          p.log,
+			AI:           p.ai,
+			Path:         p.path,
+			PathNodes:    p.pathNodes,
+			PathFocus:    p.pathFocus,
+			NodeDocs:     p.nodeDocs,
+			Concepts:     p.concepts,
+			Chunks:       p.chunks,
+			Drills:       p.drills,
+			GenRuns:      p.genRuns,
+			ConceptState: p.mastery,
+		}),
 	}).Respond(jc.Ctx, chatmod.RespondInput{
 		UserID:             jc.Job.OwnerUserID,
 		ThreadID:           threadID,
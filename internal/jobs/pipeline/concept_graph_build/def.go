@@ -31,6 +31,7 @@ type Pipeline struct {
 	artifacts        repos.LearningArtifactRepo
 	graphVersions    repos.GraphVersionRepo
 	structuralTraces repos.StructuralDecisionTraceRepo
+	docEmbeddings    repos.ConceptDocEmbeddingRepo
 }
 
 func New(
@@ -53,6 +54,7 @@ func New(
 	artifacts repos.LearningArtifactRepo,
 	graphVersions repos.GraphVersionRepo,
 	structuralTraces repos.StructuralDecisionTraceRepo,
+	docEmbeddings repos.ConceptDocEmbeddingRepo,
 ) *Pipeline {
 	return &Pipeline{
 		db:               db,
@@ -74,6 +76,7 @@ func New(
 		artifacts:        artifacts,
 		graphVersions:    graphVersions,
 		structuralTraces: structuralTraces,
+		docEmbeddings:    docEmbeddings,
 	}
 }
 
@@ -72,11 +72,15 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 
 	jc.Progress("concept_graph", 2, "Building concept graph")
 	mode := ""
+	force := false
 	if raw, ok := jc.Payload()["stage_config"]; ok && raw != nil {
 		if cfg, ok := raw.(map[string]any); ok {
 			if v, ok := cfg["mode"]; ok && v != nil {
 				mode = strings.TrimSpace(fmt.Sprint(v))
 			}
+			if v, ok := cfg["force"].(bool); ok {
+				force = v
+			}
 		}
 	}
 	out, err := learningmod.New(learningmod.UsecasesDeps{
@@ -91,6 +95,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		MappingOverrides: p.overrides,
 		Evidence:         p.evidence,
 		Edges:            p.edges,
+		DocEmbeddings:    p.docEmbeddings,
 		Graph:            p.graph,
 		AI:               p.ai,
 		Vec:              p.vec,
@@ -103,6 +108,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		SagaID:        sagaID,
 		PathID:        pathID,
 		Mode:          mode,
+		Force:         force,
 		Report: func(stage string, pct int, message string) {
 			jc.Progress(stage, pct, message)
 		},
@@ -122,6 +128,10 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		"edges_made":       out.EdgesMade,
 		"pinecone_batches": out.PineconeBatches,
 		"mode":             mode,
+		"force":            force,
+	}
+	if len(out.Models) > 0 {
+		meta["models"] = out.Models
 	}
 	inputs := map[string]any{
 		"material_set_id": setID.String(),
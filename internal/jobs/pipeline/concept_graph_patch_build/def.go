@@ -31,6 +31,8 @@ type Pipeline struct {
 	artifacts        repos.LearningArtifactRepo
 	graphVersions    repos.GraphVersionRepo
 	structuralTraces repos.StructuralDecisionTraceRepo
+	pathNodes        repos.PathNodeRepo
+	nodeDocs         repos.LearningNodeDocRepo
 }
 
 func New(
@@ -53,6 +55,8 @@ func New(
 	artifacts repos.LearningArtifactRepo,
 	graphVersions repos.GraphVersionRepo,
 	structuralTraces repos.StructuralDecisionTraceRepo,
+	pathNodes repos.PathNodeRepo,
+	nodeDocs repos.LearningNodeDocRepo,
 ) *Pipeline {
 	return &Pipeline{
 		db:               db,
@@ -74,6 +78,8 @@ func New(
 		artifacts:        artifacts,
 		graphVersions:    graphVersions,
 		structuralTraces: structuralTraces,
+		pathNodes:        pathNodes,
+		nodeDocs:         nodeDocs,
 	}
 }
 
@@ -26,10 +26,21 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	}
 	sagaID, ok := jc.PayloadUUID("saga_id")
 	if !ok || sagaID == uuid.Nil {
-		jc.Fail("validate", fmt.Errorf("missing saga_id"))
-		return nil
+		// Standalone runs (e.g. a single-file reindex) are not part of a learning_build saga.
+		if p.saga == nil {
+			jc.Fail("validate", fmt.Errorf("missing saga_id"))
+			return nil
+		}
+		id, err := p.saga.CreateOrGetSaga(jc.Ctx, jc.Job.OwnerUserID, jc.Job.ID)
+		if err != nil {
+			jc.Fail("saga", err)
+			return nil
+		}
+		sagaID = id
 	}
 	pathID, _ := jc.PayloadUUID("path_id")
+	// material_file_id is optional: with it only that file's chunks are reindexed into the graph.
+	fileID, _ := jc.PayloadUUID("material_file_id")
 
 	heartbeatSec := getEnvInt("CONCEPT_GRAPH_HEARTBEAT_SECONDS", 20)
 	if heartbeatSec < 1 {
@@ -85,11 +96,14 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		Saga:             p.saga,
 		Bootstrap:        p.bootstrap,
 		Artifacts:        p.artifacts,
+		PathNodes:        p.pathNodes,
+		NodeDocs:         p.nodeDocs,
 	}).ConceptGraphPatchBuild(jc.Ctx, learningmod.ConceptGraphPatchBuildInput{
-		OwnerUserID:   jc.Job.OwnerUserID,
-		MaterialSetID: setID,
-		SagaID:        sagaID,
-		PathID:        pathID,
+		OwnerUserID:    jc.Job.OwnerUserID,
+		MaterialSetID:  setID,
+		SagaID:         sagaID,
+		PathID:         pathID,
+		MaterialFileID: fileID,
 	})
 	stopTicker()
 	if err != nil {
@@ -106,11 +120,22 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		"edges_made":       out.EdgesMade,
 		"pinecone_batches": out.PineconeBatches,
 	}
+	if len(out.ConceptChanges) > 0 {
+		meta["concept_changes"] = out.ConceptChanges
+		meta["docs_concept_stale"] = out.DocsConceptStaled
+	}
+	if len(out.Models) > 0 {
+		meta["models"] = out.Models
+	}
 	inputs := map[string]any{
 		"material_set_id": setID.String(),
 		"saga_id":         sagaID.String(),
 		"path_id":         out.PathID.String(),
 	}
+	if fileID != uuid.Nil {
+		meta["material_file_id"] = fileID.String()
+		inputs["material_file_id"] = fileID.String()
+	}
 	chosen := map[string]any{
 		"concepts_made":    out.ConceptsMade,
 		"edges_made":       out.EdgesMade,
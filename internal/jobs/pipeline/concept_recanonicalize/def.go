@@ -0,0 +1,45 @@
+package concept_recanonicalize
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+)
+
+type Pipeline struct {
+	db            *gorm.DB
+	log           *logger.Logger
+	concepts      repos.ConceptRepo
+	reps          repos.ConceptRepresentationRepo
+	overrides     repos.ConceptMappingOverrideRepo
+	docEmbeddings repos.ConceptDocEmbeddingRepo
+	ai            openai.Client
+	vec           pinecone.VectorStore
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	concepts repos.ConceptRepo,
+	reps repos.ConceptRepresentationRepo,
+	overrides repos.ConceptMappingOverrideRepo,
+	docEmbeddings repos.ConceptDocEmbeddingRepo,
+	ai openai.Client,
+	vec pinecone.VectorStore,
+) *Pipeline {
+	return &Pipeline{
+		db:            db,
+		log:           baseLog.With("job", "concept_recanonicalize"),
+		concepts:      concepts,
+		reps:          reps,
+		overrides:     overrides,
+		docEmbeddings: docEmbeddings,
+		ai:            ai,
+		vec:           vec,
+	}
+}
+
+func (p *Pipeline) Type() string { return "concept_recanonicalize" }
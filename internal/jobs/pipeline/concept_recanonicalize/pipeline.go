@@ -0,0 +1,47 @@
+package concept_recanonicalize
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	pathID, ok := jc.PayloadUUID("path_id")
+	if !ok || pathID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_id"))
+		return nil
+	}
+
+	jc.Progress("recanonicalize", 10, "Re-linking concepts to canonical concepts")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:               p.db,
+		Log:              p.log,
+		Concepts:         p.concepts,
+		ConceptReps:      p.reps,
+		MappingOverrides: p.overrides,
+		DocEmbeddings:    p.docEmbeddings,
+		AI:               p.ai,
+		Vec:              p.vec,
+	}).ConceptRecanonicalize(jc.Ctx, learningmod.ConceptRecanonicalizeInput{PathID: pathID})
+	if err != nil {
+		jc.Fail("recanonicalize", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"path_id":           pathID.String(),
+		"concepts":          out.Concepts,
+		"semantic_matches":  out.SemanticMatches,
+		"relinked":          out.Relinked,
+		"global_vectors":    out.GlobalVectors,
+		"embeddings_reused": out.EmbeddingsReused,
+	})
+	return nil
+}
@@ -0,0 +1,24 @@
+package doc_consistency_audit
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type Pipeline struct {
+	db   *gorm.DB
+	log  *logger.Logger
+	runs repos.DocConsistencyAuditRunRepo
+}
+
+func New(db *gorm.DB, baseLog *logger.Logger, runs repos.DocConsistencyAuditRunRepo) *Pipeline {
+	return &Pipeline{
+		db:   db,
+		log:  baseLog.With("job", "doc_consistency_audit"),
+		runs: runs,
+	}
+}
+
+func (p *Pipeline) Type() string { return "doc_consistency_audit" }
@@ -0,0 +1,40 @@
+package doc_consistency_audit
+
+import (
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+// Run audits doc, variant, exposure and revision invariants. It is a "system" job meant to be
+// enqueued nightly; it takes no payload.
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+
+	jobID := jc.Job.ID
+	jc.Progress("audit", 2, "Auditing doc consistency invariants")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:                p.db,
+		Log:               p.log,
+		ConsistencyAudits: p.runs,
+	}).DocConsistencyAudit(jc.Ctx, learningmod.DocConsistencyAuditInput{JobID: &jobID})
+	if err != nil {
+		jc.Fail("audit", err)
+		return nil
+	}
+
+	counts := make(map[string]int, len(out.Checks))
+	for _, c := range out.Checks {
+		counts[c.Name] = c.Count
+	}
+	jc.Succeed("done", map[string]any{
+		"run_id":      out.RunID.String(),
+		"violations":  out.Violations,
+		"repaired":    out.Repaired,
+		"increased":   out.Increased,
+		"auto_repair": out.AutoRepair,
+		"counts":      counts,
+	})
+	return nil
+}
@@ -0,0 +1,27 @@
+package field_crypt_rotate
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type Pipeline struct {
+	db         *gorm.DB
+	log        *logger.Logger
+	nodeDocs   repos.LearningNodeDocRepo
+	fieldCrypt *fieldcrypt.Envelope
+}
+
+func New(db *gorm.DB, baseLog *logger.Logger, nodeDocs repos.LearningNodeDocRepo, fieldCrypt *fieldcrypt.Envelope) *Pipeline {
+	return &Pipeline{
+		db:         db,
+		log:        baseLog.With("job", "field_crypt_rotate"),
+		nodeDocs:   nodeDocs,
+		fieldCrypt: fieldCrypt,
+	}
+}
+
+func (p *Pipeline) Type() string { return "field_crypt_rotate" }
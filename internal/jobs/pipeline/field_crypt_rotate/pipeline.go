@@ -0,0 +1,81 @@
+package field_crypt_rotate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	if p.db == nil || p.nodeDocs == nil {
+		jc.Fail("deps", fmt.Errorf("missing db or node doc repo"))
+		return nil
+	}
+	if !p.fieldCrypt.Enabled() {
+		jc.Succeed("done", map[string]any{"skipped": "field encryption not configured"})
+		return nil
+	}
+	payload := jc.Payload()
+
+	jc.Progress("rotate", 5, "Re-sealing user notes under the current key")
+
+	dryRun := boolFromAny(payload["dry_run"], false)
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:         p.db,
+		Log:        p.log,
+		NodeDocs:   p.nodeDocs,
+		FieldCrypt: p.fieldCrypt,
+	}).FieldCryptRotate(jc.Ctx, learningmod.FieldCryptRotateInput{
+		DryRun:    dryRun,
+		Limit:     intFromAny(payload["limit"], 0),
+		BatchSize: intFromAny(payload["batch_size"], 0),
+	})
+	if err != nil {
+		jc.Fail("rotate", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"dry_run":     dryRun,
+		"key_version": out.KeyVersion,
+		"scanned":     out.Scanned,
+		"resealed":    out.Resealed,
+		"stale":       out.Stale,
+	})
+	return nil
+}
+
+func intFromAny(v any, def int) int {
+	if v == nil {
+		return def
+	}
+	raw := strings.TrimSpace(fmt.Sprint(v))
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+func boolFromAny(v any, def bool) bool {
+	if v == nil {
+		return def
+	}
+	switch strings.TrimSpace(strings.ToLower(fmt.Sprint(v))) {
+	case "1", "true", "t", "yes", "y", "on":
+		return true
+	case "0", "false", "f", "no", "n", "off":
+		return false
+	default:
+		return def
+	}
+}
@@ -0,0 +1,36 @@
+package generated_object_sweep
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type Pipeline struct {
+	db     *gorm.DB
+	log    *logger.Logger
+	jobs   services.JobService
+	bucket gcp.BucketService
+	refs   repos.NodeAssetRefRepo
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	jobs services.JobService,
+	bucket gcp.BucketService,
+	refs repos.NodeAssetRefRepo,
+) *Pipeline {
+	return &Pipeline{
+		db:     db,
+		log:    baseLog.With("job", "generated_object_sweep"),
+		jobs:   jobs,
+		bucket: bucket,
+		refs:   refs,
+	}
+}
+
+func (p *Pipeline) Type() string { return "generated_object_sweep" }
@@ -0,0 +1,79 @@
+package generated_object_sweep
+
+import (
+	"fmt"
+	"strings"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+
+	in := learningmod.GeneratedObjectSweepInput{
+		Cursor:      payloadString(jc, "cursor"),
+		TrashCursor: payloadString(jc, "trash_cursor"),
+	}
+	if s := payloadString(jc, "dry_run"); strings.EqualFold(s, "true") || s == "1" {
+		in.DryRun = true
+	}
+
+	jc.Progress("sweep", 2, "Sweeping unreferenced generated objects")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:            p.db,
+		Log:           p.log,
+		Bucket:        p.bucket,
+		NodeAssetRefs: p.refs,
+	}).GeneratedObjectSweep(jc.Ctx, in)
+	if err != nil {
+		jc.Fail("sweep", err)
+		return nil
+	}
+
+	// Each run is bounded; pick up where this one stopped in a follow-up job.
+	continued := false
+	if !out.Done && p.jobs != nil {
+		payload := map[string]any{
+			"cursor":       out.NextCursor,
+			"trash_cursor": out.NextTrashCursor,
+			"dry_run":      in.DryRun,
+		}
+		if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, p.Type(), "system", nil, payload); err != nil {
+			p.log.Warn("generated_object_sweep: enqueue continuation failed", "error", err)
+		} else {
+			continued = true
+		}
+	}
+
+	jc.Succeed("done", map[string]any{
+		"scanned":             out.Scanned,
+		"referenced":          out.Referenced,
+		"in_grace":            out.InGrace,
+		"trashed":             out.Trashed,
+		"trashed_bytes":       out.TrashedBytes,
+		"trashed_by_producer": out.TrashedByProducer,
+		"trash_scanned":       out.TrashScanned,
+		"retained":            out.Retained,
+		"restored":            out.Restored,
+		"deleted":             out.Deleted,
+		"errors":              out.Errors,
+		"dry_run":             in.DryRun,
+		"done":                out.Done,
+		"next_cursor":         out.NextCursor,
+		"next_trash_cursor":   out.NextTrashCursor,
+		"continued":           continued,
+	})
+	return nil
+}
+
+func payloadString(jc *jobrt.Context, key string) string {
+	v, ok := jc.Payload()[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(v))
+}
@@ -37,6 +37,7 @@ type InlineDeps struct {
 	Concepts         repos.ConceptRepo
 	ConceptReps      repos.ConceptRepresentationRepo
 	MappingOverrides repos.ConceptMappingOverrideRepo
+	DocEmbeddings    repos.ConceptDocEmbeddingRepo
 	Evidence         repos.ConceptEvidenceRepo
 	Edges            repos.ConceptEdgeRepo
 
@@ -62,6 +63,7 @@ type InlineDeps struct {
 	NodeDocRevisions     repos.LearningNodeDocRevisionRepo
 	NodeDocBlueprints    repos.LearningNodeDocBlueprintRepo
 	NodeFigures          repos.LearningNodeFigureRepo
+	FigureBlobs          repos.FigureBlobRepo
 	NodeVideos           repos.LearningNodeVideoRepo
 	DocGenRuns           repos.LearningDocGenerationRunRepo
 	DocRetrievalPacks    repos.DocRetrievalPackRepo
@@ -263,6 +263,7 @@ func (p *Pipeline) runInline(jc *jobrt.Context, st *state, setID, sagaID, pathID
 		MappingOverrides: p.inline.MappingOverrides,
 		Evidence:         p.inline.Evidence,
 		Edges:            p.inline.Edges,
+		DocEmbeddings:    p.inline.DocEmbeddings,
 
 		Clusters: p.inline.Clusters,
 		Members:  p.inline.Members,
@@ -281,6 +282,7 @@ func (p *Pipeline) runInline(jc *jobrt.Context, st *state, setID, sagaID, pathID
 		Revisions:         p.inline.NodeDocRevisions,
 		Blueprints:        p.inline.NodeDocBlueprints,
 		Figures:           p.inline.NodeFigures,
+		FigureBlobs:       p.inline.FigureBlobs,
 		Videos:            p.inline.NodeVideos,
 		GenRuns:           p.inline.DocGenRuns,
 		RetrievalPacks:    p.inline.DocRetrievalPacks,
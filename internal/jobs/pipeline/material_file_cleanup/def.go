@@ -0,0 +1,41 @@
+package material_file_cleanup
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+)
+
+type Pipeline struct {
+	db       *gorm.DB
+	log      *logger.Logger
+	files    repos.MaterialFileRepo
+	chunks   repos.MaterialChunkRepo
+	evidence repos.ConceptEvidenceRepo
+	nodeDocs repos.LearningNodeDocRepo
+	vec      pinecone.VectorStore
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	files repos.MaterialFileRepo,
+	chunks repos.MaterialChunkRepo,
+	evidence repos.ConceptEvidenceRepo,
+	nodeDocs repos.LearningNodeDocRepo,
+	vec pinecone.VectorStore,
+) *Pipeline {
+	return &Pipeline{
+		db:       db,
+		log:      baseLog.With("job", "material_file_cleanup"),
+		files:    files,
+		chunks:   chunks,
+		evidence: evidence,
+		nodeDocs: nodeDocs,
+		vec:      vec,
+	}
+}
+
+func (p *Pipeline) Type() string { return "material_file_cleanup" }
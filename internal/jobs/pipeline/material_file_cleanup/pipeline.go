@@ -0,0 +1,64 @@
+package material_file_cleanup
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	chatmod "github.com/yungbote/neurobridge-backend/internal/modules/chat"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	fileID, ok := jc.PayloadUUID("material_file_id")
+	if !ok || fileID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing material_file_id"))
+		return nil
+	}
+
+	jc.Progress("cleanup", 10, "Removing material file sources")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:       p.db,
+		Log:      p.log,
+		Files:    p.files,
+		Chunks:   p.chunks,
+		Evidence: p.evidence,
+		NodeDocs: p.nodeDocs,
+		Vec:      p.vec,
+	}).MaterialFileCleanup(jc.Ctx, learningmod.MaterialFileCleanupInput{
+		OwnerUserID:    jc.Job.OwnerUserID,
+		MaterialFileID: fileID,
+	})
+	if err != nil {
+		jc.Fail("cleanup", err)
+		return nil
+	}
+
+	jc.Progress("chat", 80, "Dropping chat projections")
+	purged, err := chatmod.New(chatmod.UsecasesDeps{
+		DB:  p.db,
+		Log: p.log,
+		Vec: p.vec,
+	}).PurgeMaterialFileProjections(jc.Ctx, chatmod.MaterialFileProjectionsInput{
+		UserID:         jc.Job.OwnerUserID,
+		MaterialFileID: fileID,
+	})
+	if err != nil {
+		jc.Fail("chat", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"material_file_id":   fileID.String(),
+		"chunks_removed":     out.ChunksRemoved,
+		"vectors_deleted":    out.VectorsDeleted,
+		"evidence_flagged":   out.EvidenceFlagged,
+		"docs_sources_stale": out.DocsSourcesStaled,
+		"chat_docs_purged":   purged,
+	})
+	return nil
+}
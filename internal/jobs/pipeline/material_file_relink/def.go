@@ -0,0 +1,37 @@
+package material_file_relink
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type Pipeline struct {
+	db       *gorm.DB
+	log      *logger.Logger
+	files    repos.MaterialFileRepo
+	chunks   repos.MaterialChunkRepo
+	evidence repos.ConceptEvidenceRepo
+	nodeDocs repos.LearningNodeDocRepo
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	files repos.MaterialFileRepo,
+	chunks repos.MaterialChunkRepo,
+	evidence repos.ConceptEvidenceRepo,
+	nodeDocs repos.LearningNodeDocRepo,
+) *Pipeline {
+	return &Pipeline{
+		db:       db,
+		log:      baseLog.With("job", "material_file_relink"),
+		files:    files,
+		chunks:   chunks,
+		evidence: evidence,
+		nodeDocs: nodeDocs,
+	}
+}
+
+func (p *Pipeline) Type() string { return "material_file_relink" }
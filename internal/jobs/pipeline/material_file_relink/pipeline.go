@@ -0,0 +1,55 @@
+package material_file_relink
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	removedID, ok := jc.PayloadUUID("removed_file_id")
+	if !ok || removedID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing removed_file_id"))
+		return nil
+	}
+	newID, ok := jc.PayloadUUID("material_file_id")
+	if !ok || newID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing material_file_id"))
+		return nil
+	}
+
+	jc.Progress("relink", 10, "Relinking citations")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:       p.db,
+		Log:      p.log,
+		Files:    p.files,
+		Chunks:   p.chunks,
+		Evidence: p.evidence,
+		NodeDocs: p.nodeDocs,
+	}).MaterialFileRelink(jc.Ctx, learningmod.MaterialFileRelinkInput{
+		OwnerUserID:   jc.Job.OwnerUserID,
+		RemovedFileID: removedID,
+		NewFileID:     newID,
+	})
+	if err != nil {
+		jc.Fail("relink", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"removed_file_id":    removedID.String(),
+		"material_file_id":   newID.String(),
+		"chunks_matched":     out.ChunksMatched,
+		"chunks_unmatched":   out.ChunksUnmatched,
+		"evidence_relinked":  out.EvidenceRelinked,
+		"citations_relinked": out.CitationsRelinked,
+		"docs_updated":       out.DocsUpdated,
+	})
+	return nil
+}
@@ -0,0 +1,35 @@
+package node_description_sync
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+type Pipeline struct {
+	db    *gorm.DB
+	log   *logger.Logger
+	nodes repos.PathNodeRepo
+	docs  repos.LearningNodeDocRepo
+	jobs  services.JobService
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	nodes repos.PathNodeRepo,
+	docs repos.LearningNodeDocRepo,
+	jobs services.JobService,
+) *Pipeline {
+	return &Pipeline{
+		db:    db,
+		log:   baseLog.With("job", "node_description_sync"),
+		nodes: nodes,
+		docs:  docs,
+		jobs:  jobs,
+	}
+}
+
+func (p *Pipeline) Type() string { return "node_description_sync" }
@@ -0,0 +1,60 @@
+package node_description_sync
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	pathID, ok := jc.PayloadUUID("path_id")
+	if !ok || pathID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_id"))
+		return nil
+	}
+	// path_node_id is optional: without it every node in the path is checked.
+	var nodeIDs []uuid.UUID
+	if nodeID, ok := jc.PayloadUUID("path_node_id"); ok && nodeID != uuid.Nil {
+		nodeIDs = []uuid.UUID{nodeID}
+	}
+
+	jc.Progress("sync", 10, "Syncing unit descriptions with their docs")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:        p.db,
+		Log:       p.log,
+		PathNodes: p.nodes,
+		NodeDocs:  p.docs,
+	}).NodeDescriptionSync(jc.Ctx, learningmod.NodeDescriptionSyncInput{
+		OwnerUserID: jc.Job.OwnerUserID,
+		PathID:      pathID,
+		NodeIDs:     nodeIDs,
+	})
+	if err != nil {
+		jc.Fail("sync", err)
+		return nil
+	}
+
+	// The chat path overview lists unit descriptions; rebuild it with the synced ones.
+	if out.NodesUpdated > 0 && p.jobs != nil {
+		entityID := pathID
+		payload := map[string]any{"path_id": pathID.String()}
+		if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "chat_path_index", "path", &entityID, payload); err != nil {
+			p.log.Warn("Failed to enqueue chat_path_index", "error", err, "path_id", pathID.String())
+		}
+	}
+
+	jc.Succeed("done", map[string]any{
+		"path_id":         pathID.String(),
+		"nodes_checked":   out.NodesChecked,
+		"nodes_updated":   out.NodesUpdated,
+		"nodes_protected": out.NodesProtected,
+	})
+	return nil
+}
@@ -42,6 +42,12 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	action := strings.TrimSpace(fmt.Sprint(payload["action"]))
 	instruction := strings.TrimSpace(fmt.Sprint(payload["instruction"]))
 	citationPolicy := strings.TrimSpace(fmt.Sprint(payload["citation_policy"]))
+	track, _ := payload["track"].(string)
+	track, ok = types.NormalizeNodeDocTrack(track)
+	if !ok {
+		jc.Fail("validate", fmt.Errorf("unknown track"))
+		return nil
+	}
 
 	var sel learningmod.NodeDocPatchSelection
 	if raw, ok := payload["selection"].(map[string]any); ok {
@@ -76,6 +82,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		CitationPolicy: citationPolicy,
 		Selection:      sel,
 		JobID:          jc.Job.ID,
+		Track:          track,
 	})
 	if err != nil {
 		jc.Fail("propose", err)
@@ -92,6 +99,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		"action":          preview.Action,
 		"citation_policy": preview.CitationPolicy,
 		"instruction":     instruction,
+		"track":           track,
 		"selection": map[string]any{
 			"text":  strings.TrimSpace(sel.Text),
 			"start": sel.Start,
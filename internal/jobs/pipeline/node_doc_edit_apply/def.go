@@ -4,6 +4,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
@@ -17,6 +18,8 @@ type Pipeline struct {
 	nodes     repos.PathNodeRepo
 	docs      repos.LearningNodeDocRepo
 	revisions repos.LearningNodeDocRevisionRepo
+	// Seals user_md bodies before the doc is written; nil stores them as-is.
+	fieldCrypt *fieldcrypt.Envelope
 }
 
 func New(
@@ -28,6 +31,7 @@ func New(
 	nodes repos.PathNodeRepo,
 	docs repos.LearningNodeDocRepo,
 	revisions repos.LearningNodeDocRevisionRepo,
+	fieldCrypt *fieldcrypt.Envelope,
 ) *Pipeline {
 	return &Pipeline{
 		db:        db,
@@ -38,6 +42,8 @@ func New(
 		nodes:     nodes,
 		docs:      docs,
 		revisions: revisions,
+
+		fieldCrypt: fieldCrypt,
 	}
 }
 
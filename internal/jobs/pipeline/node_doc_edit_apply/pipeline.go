@@ -2,6 +2,7 @@ package node_doc_edit_apply
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -11,9 +12,11 @@ import (
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 )
 
@@ -32,6 +35,8 @@ type proposalPayload struct {
 	AfterBlockText  string          `json:"after_block_text"`
 	Model           string          `json:"model"`
 	PromptVersion   string          `json:"prompt_version"`
+	// Track is the doc track the proposal was drafted against; "" (older proposals) is primary.
+	Track string `json:"track"`
 }
 
 func (p *Pipeline) Run(jc *jobrt.Context) error {
@@ -97,7 +102,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		return nil
 	}
 
-	docRow, err := p.docs.GetByPathNodeID(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, pathNodeID)
+	docRow, err := p.docs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, pathNodeID, prop.Track)
 	if err != nil || docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
 		jc.Fail("load", fmt.Errorf("doc not found"))
 		return nil
@@ -143,6 +148,22 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		jc.Fail("validate", fmt.Errorf("invalid proposed block"))
 		return nil
 	}
+	// Repair broken fences/math/tables before committing; the outcome goes on the revision.
+	richTextIssues := content.ValidateBlockRichText(updatedBlock)
+	revisionID := uuid.New()
+	content.StampBlockProvenance(updatedBlock, content.BlockProvenance{
+		Source:        content.ProvenanceSourcePatch,
+		RevisionID:    revisionID.String(),
+		PromptVersion: strings.TrimSpace(prop.PromptVersion),
+		PolicyVersion: docgen.DocPolicyVersion(),
+	})
+	afterBlockJSON, _ := json.Marshal(updatedBlock)
+	var revisionMeta datatypes.JSON
+	if meta := docgen.RichTextRevisionMetadata(richTextIssues); meta != nil {
+		if b, err := json.Marshal(meta); err == nil {
+			revisionMeta = datatypes.JSON(b)
+		}
+	}
 	blocks[idx] = updatedBlock
 	docObj["blocks"] = blocks
 
@@ -168,8 +189,23 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		return nil
 	}
 
+	// Learner-authored bodies are validated in the clear but only ever stored sealed.
+	if sealed, err := content.SealUserBlocks(jc.Ctx, &updatedDoc, p.fieldCrypt); err != nil {
+		jc.Fail("apply", fmt.Errorf("seal user notes: %w", err))
+		return nil
+	} else if sealed {
+		sealedBytes, _ := json.Marshal(updatedDoc)
+		if canon, err = content.CanonicalizeJSON(sealedBytes); err != nil {
+			jc.Fail("apply", err)
+			return nil
+		}
+		if idx < len(updatedDoc.Blocks) {
+			afterBlockJSON, _ = json.Marshal(updatedDoc.Blocks[idx])
+		}
+	}
+
 	now := time.Now().UTC()
-	contentHash := content.HashBytes(canon)
+	contentHash := content.NodeDocContentHash(canon)
 	sourcesHash := content.HashSources(strings.TrimSpace(prop.PromptVersion), 1, content.CitedChunkIDsFromNodeDocV1(updatedDoc))
 	docText, _ := content.NodeDocMetrics(updatedDoc)["doc_text"].(string)
 	docText = content.SanitizeStringForPostgres(docText)
@@ -179,6 +215,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		UserID:        jc.Job.OwnerUserID,
 		PathID:        node.PathID,
 		PathNodeID:    node.ID,
+		Track:         docRow.Track,
 		SchemaVersion: 1,
 		DocJSON:       datatypes.JSON(canon),
 		DocText:       docText,
@@ -189,11 +226,12 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	}
 
 	revision := &types.LearningNodeDocRevision{
-		ID:             uuid.New(),
+		ID:             revisionID,
 		DocID:          updatedRow.ID,
 		UserID:         jc.Job.OwnerUserID,
 		PathID:         node.PathID,
 		PathNodeID:     node.ID,
+		Track:          docRow.Track,
 		BlockID:        blockID,
 		BlockType:      strings.TrimSpace(prop.BlockType),
 		Operation:      strings.TrimSpace(prop.Action),
@@ -201,19 +239,20 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		Instruction:    strings.TrimSpace(prop.Instruction),
 		Selection:      datatypes.JSON([]byte(`null`)),
 		BeforeJSON:     datatypes.JSON(currentBlockJSON),
-		AfterJSON:      datatypes.JSON(prop.AfterBlock),
+		AfterJSON:      datatypes.JSON(afterBlockJSON),
 		Status:         "succeeded",
 		Error:          "",
 		Model:          strings.TrimSpace(prop.Model),
 		PromptVersion:  strings.TrimSpace(prop.PromptVersion),
 		TokensIn:       0,
 		TokensOut:      0,
+		Metadata:       revisionMeta,
 		CreatedAt:      now,
 	}
 
 	err = p.db.WithContext(jc.Ctx).Transaction(func(tx *gorm.DB) error {
 		inner := dbctx.Context{Ctx: jc.Ctx, Tx: tx}
-		if err := p.docs.Upsert(inner, updatedRow); err != nil {
+		if err := p.docs.UpdateWithVersion(inner, updatedRow, docRow.Version); err != nil {
 			return err
 		}
 		if _, err := p.revisions.Create(inner, []*types.LearningNodeDocRevision{revision}); err != nil {
@@ -221,6 +260,11 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		}
 		return nil
 	})
+	if errors.Is(err, repos.ErrStaleDoc) {
+		// Same outcome as the before-block guard: the proposal was made against an older doc.
+		jc.Fail("apply", fmt.Errorf("doc changed since proposal"))
+		return nil
+	}
 	if err != nil {
 		jc.Fail("apply", err)
 		return nil
@@ -229,7 +273,8 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	_ = p.markProposalResolved(jc, proposalJobID, "applied")
 	_ = p.clearThreadPendingWaitpoint(jc, env)
 
-	if p.jobSvc != nil {
+	// The chat index and the node description follow the primary doc only.
+	if p.jobSvc != nil && docRow.Track == types.NodeDocTrackPrimary {
 		entityID := node.PathID
 		payload := map[string]any{
 			"path_id":      node.PathID.String(),
@@ -238,12 +283,16 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		if _, err := p.jobSvc.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "chat_path_node_index", "path_node", &entityID, payload); err != nil {
 			p.log.Warn("node_doc_edit_apply: enqueue chat_path_node_index failed", "error", err, "path_id", node.PathID.String(), "path_node_id", node.ID.String())
 		}
+		if _, err := p.jobSvc.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "node_description_sync", "path_node", &entityID, payload); err != nil {
+			p.log.Warn("node_doc_edit_apply: enqueue node_description_sync failed", "error", err, "path_id", node.PathID.String(), "path_node_id", node.ID.String())
+		}
 	}
 
 	jc.Succeed("done", map[string]any{
 		"status":       "applied",
 		"path_node_id": node.ID.String(),
 		"block_id":     blockID,
+		"track":        docRow.Track,
 	})
 	return nil
 }
@@ -0,0 +1,45 @@
+package node_doc_narrate
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/tts"
+)
+
+type Pipeline struct {
+	db     *gorm.DB
+	log    *logger.Logger
+	path   repos.PathRepo
+	nodes  repos.PathNodeRepo
+	docs   repos.LearningNodeDocRepo
+	audio  repos.LearningNodeAudioRepo
+	bucket gcp.BucketService
+	tts    tts.Provider
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	path repos.PathRepo,
+	nodes repos.PathNodeRepo,
+	docs repos.LearningNodeDocRepo,
+	audio repos.LearningNodeAudioRepo,
+	bucket gcp.BucketService,
+	provider tts.Provider,
+) *Pipeline {
+	return &Pipeline{
+		db:     db,
+		log:    baseLog.With("job", "node_doc_narrate"),
+		path:   path,
+		nodes:  nodes,
+		docs:   docs,
+		audio:  audio,
+		bucket: bucket,
+		tts:    provider,
+	}
+}
+
+func (p *Pipeline) Type() string { return "node_doc_narrate" }
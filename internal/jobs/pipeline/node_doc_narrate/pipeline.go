@@ -0,0 +1,54 @@
+package node_doc_narrate
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	nodeID, ok := jc.PayloadUUID("path_node_id")
+	if !ok || nodeID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_node_id"))
+		return nil
+	}
+	force, _ := jc.Payload()["force"].(bool)
+
+	jc.Progress("narrate", 2, "Narrating doc")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:        p.db,
+		Log:       p.log,
+		Path:      p.path,
+		PathNodes: p.nodes,
+		NodeDocs:  p.docs,
+		NodeAudio: p.audio,
+		Bucket:    p.bucket,
+		TTS:       p.tts,
+	}).NodeDocNarrate(jc.Ctx, learningmod.NodeDocNarrateInput{
+		OwnerUserID: jc.Job.OwnerUserID,
+		PathNodeID:  nodeID,
+		Force:       force,
+	})
+	if err != nil {
+		jc.Fail("narrate", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"path_node_id": nodeID.String(),
+		"doc_id":       out.DocID.String(),
+		"content_hash": out.ContentHash,
+		"segments":     out.Segments,
+		"synthesized":  out.Synthesized,
+		"reused":       out.Reused,
+		"duration_ms":  out.DurationMS,
+		"up_to_date":   out.UpToDate,
+	})
+	return nil
+}
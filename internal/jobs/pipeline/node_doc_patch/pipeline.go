@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
 	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
@@ -27,6 +28,12 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	action := strings.TrimSpace(fmt.Sprint(payload["action"]))
 	instruction := strings.TrimSpace(fmt.Sprint(payload["instruction"]))
 	citationPolicy := strings.TrimSpace(fmt.Sprint(payload["citation_policy"]))
+	track, _ := payload["track"].(string)
+	track, ok = types.NormalizeNodeDocTrack(track)
+	if !ok {
+		jc.Fail("validate", fmt.Errorf("unknown track"))
+		return nil
+	}
 
 	var sel learningmod.NodeDocPatchSelection
 	if raw, ok := payload["selection"].(map[string]any); ok {
@@ -62,13 +69,15 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		CitationPolicy: citationPolicy,
 		Selection:      sel,
 		JobID:          jc.Job.ID,
+		Track:          track,
 	})
 	if err != nil {
 		jc.Fail("patch", err)
 		return nil
 	}
 
-	if p.jobs != nil {
+	// The chat index and the node description follow the primary doc only.
+	if p.jobs != nil && track == types.NodeDocTrackPrimary {
 		if node, nerr := p.nodes.GetByID(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, nodeID); nerr == nil && node != nil && node.PathID != uuid.Nil {
 			entityID := node.PathID
 			payload := map[string]any{
@@ -78,11 +87,16 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 			if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "chat_path_node_index", "path_node", &entityID, payload); err != nil {
 				p.log.Warn("Failed to enqueue chat_path_node_index", "error", err, "path_id", node.PathID.String(), "path_node_id", nodeID.String())
 			}
+			// A patch can move what the doc teaches away from the node's description.
+			if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "node_description_sync", "path_node", &entityID, payload); err != nil {
+				p.log.Warn("Failed to enqueue node_description_sync", "error", err, "path_id", node.PathID.String(), "path_node_id", nodeID.String())
+			}
 		}
 	}
 
 	jc.Succeed("done", map[string]any{
 		"path_node_id": nodeID.String(),
+		"track":        track,
 		"doc_id":       out.DocID.String(),
 		"revision_id":  out.RevisionID.String(),
 		"block_id":     out.BlockID,
@@ -0,0 +1,124 @@
+package node_doc_regenerate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	setID, ok := jc.PayloadUUID("material_set_id")
+	if !ok || setID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing material_set_id"))
+		return nil
+	}
+	pathID, ok := jc.PayloadUUID("path_id")
+	if !ok || pathID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_id"))
+		return nil
+	}
+	nodeID, ok := jc.PayloadUUID("path_node_id")
+	if !ok || nodeID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_node_id"))
+		return nil
+	}
+	payload := jc.Payload()
+	profile, _ := payload["emphasis_profile"].(string)
+	profile = strings.TrimSpace(profile)
+	freeText := ""
+	if v, ok := payload["free_text_emphasis"].(string); ok {
+		freeText = v
+	}
+	track, _ := payload["track"].(string)
+	track, ok = types.NormalizeNodeDocTrack(track)
+	if !ok {
+		jc.Fail("validate", fmt.Errorf("unknown track"))
+		return nil
+	}
+
+	jc.Progress("docs", 2, "Regenerating unit doc")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:                p.db,
+		Log:               p.log,
+		Path:              p.path,
+		PathNodes:         p.nodes,
+		NodeDocs:          p.docs,
+		Figures:           p.figures,
+		Videos:            p.videos,
+		GenRuns:           p.genRuns,
+		Blueprints:        p.blueprints,
+		RetrievalPacks:    p.retrievalPacks,
+		DocTraces:         p.docTraces,
+		ConstraintReports: p.constraintReports,
+		Revisions:         p.revisions,
+		Files:             p.files,
+		Chunks:            p.chunks,
+		UserProfile:       p.userProf,
+		TeachingPatterns:  p.patterns,
+		Concepts:          p.concepts,
+		ConceptState:      p.mastery,
+		ConceptModel:      p.model,
+		MisconRepo:        p.miscon,
+		AI:                p.ai,
+		Vec:               p.vec,
+		Bucket:            p.bucket,
+		Bootstrap:         p.bootstrap,
+	}).NodeDocRegenerate(jc.Ctx, learningmod.NodeDocRegenerateInput{
+		OwnerUserID:   jc.Job.OwnerUserID,
+		MaterialSetID: setID,
+		PathID:        pathID,
+		PathNodeID:    nodeID,
+		Profile:       profile,
+		FreeText:      freeText,
+		Track:         track,
+		JobID:         jc.Job.ID,
+		Report: func(stage string, pct int, message string) {
+			jc.Progress(stage, pct, message)
+		},
+	})
+	if err != nil {
+		jc.Fail("docs", err)
+		return nil
+	}
+
+	// The chat index, node description and see_also targets follow the primary doc only.
+	if out.Regenerated && p.jobs != nil && track == types.NodeDocTrackPrimary {
+		entityID := pathID
+		indexPayload := map[string]any{
+			"path_id":      pathID.String(),
+			"path_node_id": nodeID.String(),
+		}
+		if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "chat_path_node_index", "path_node", &entityID, indexPayload); err != nil {
+			p.log.Warn("Failed to enqueue chat_path_node_index", "error", err, "path_id", pathID.String(), "path_node_id", nodeID.String())
+		}
+		if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "node_description_sync", "path_node", &entityID, indexPayload); err != nil {
+			p.log.Warn("Failed to enqueue node_description_sync", "error", err, "path_id", pathID.String(), "path_node_id", nodeID.String())
+		}
+		// Later docs point into this one; re-resolve their see_also targets against the new blocks.
+		seeAlsoPayload := map[string]any{
+			"path_id":       pathID.String(),
+			"after_node_id": nodeID.String(),
+		}
+		if _, err := p.jobs.Enqueue(dbctx.Context{Ctx: jc.Ctx, Tx: jc.DB}, jc.Job.OwnerUserID, "node_doc_see_also_refresh", "path", &entityID, seeAlsoPayload); err != nil {
+			p.log.Warn("Failed to enqueue node_doc_see_also_refresh", "error", err, "path_id", pathID.String(), "path_node_id", nodeID.String())
+		}
+	}
+
+	jc.Succeed("done", map[string]any{
+		"path_id":          pathID.String(),
+		"path_node_id":     nodeID.String(),
+		"emphasis_profile": out.Profile,
+		"regenerated":      out.Regenerated,
+		"track":            track,
+	})
+	return nil
+}
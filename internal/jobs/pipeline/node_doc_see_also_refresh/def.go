@@ -0,0 +1,31 @@
+package node_doc_see_also_refresh
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type Pipeline struct {
+	db    *gorm.DB
+	log   *logger.Logger
+	nodes repos.PathNodeRepo
+	docs  repos.LearningNodeDocRepo
+}
+
+func New(
+	db *gorm.DB,
+	baseLog *logger.Logger,
+	nodes repos.PathNodeRepo,
+	docs repos.LearningNodeDocRepo,
+) *Pipeline {
+	return &Pipeline{
+		db:    db,
+		log:   baseLog.With("job", "node_doc_see_also_refresh"),
+		nodes: nodes,
+		docs:  docs,
+	}
+}
+
+func (p *Pipeline) Type() string { return "node_doc_see_also_refresh" }
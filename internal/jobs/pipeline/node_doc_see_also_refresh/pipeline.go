@@ -0,0 +1,47 @@
+package node_doc_see_also_refresh
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+)
+
+func (p *Pipeline) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	pathID, ok := jc.PayloadUUID("path_id")
+	if !ok || pathID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("missing path_id"))
+		return nil
+	}
+	// after_node_id is optional: without it every doc in the path is refreshed.
+	afterID, _ := jc.PayloadUUID("after_node_id")
+
+	jc.Progress("see_also", 10, "Refreshing unit cross-references")
+	out, err := learningmod.New(learningmod.UsecasesDeps{
+		DB:        p.db,
+		Log:       p.log,
+		PathNodes: p.nodes,
+		NodeDocs:  p.docs,
+	}).NodeDocSeeAlsoRefresh(jc.Ctx, learningmod.NodeDocSeeAlsoRefreshInput{
+		OwnerUserID: jc.Job.OwnerUserID,
+		PathID:      pathID,
+		AfterNodeID: afterID,
+	})
+	if err != nil {
+		jc.Fail("see_also", err)
+		return nil
+	}
+
+	jc.Succeed("done", map[string]any{
+		"path_id":      pathID.String(),
+		"docs_checked": out.DocsChecked,
+		"docs_updated": out.DocsUpdated,
+		"docs_skipped": out.DocsSkipped,
+	})
+	return nil
+}
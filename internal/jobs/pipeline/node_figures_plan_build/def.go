@@ -4,6 +4,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
@@ -16,11 +17,13 @@ type Pipeline struct {
 	path      repos.PathRepo
 	nodes     repos.PathNodeRepo
 	figures   repos.LearningNodeFigureRepo
+	blobs     repos.FigureBlobRepo
 	genRuns   repos.LearningDocGenerationRunRepo
 	files     repos.MaterialFileRepo
 	chunks    repos.MaterialChunkRepo
 	ai        openai.Client
 	vec       pinecone.VectorStore
+	bucket    gcp.BucketService
 	bootstrap services.LearningBuildBootstrapService
 }
 
@@ -30,11 +33,13 @@ func New(
 	path repos.PathRepo,
 	nodes repos.PathNodeRepo,
 	figures repos.LearningNodeFigureRepo,
+	blobs repos.FigureBlobRepo,
 	genRuns repos.LearningDocGenerationRunRepo,
 	files repos.MaterialFileRepo,
 	chunks repos.MaterialChunkRepo,
 	ai openai.Client,
 	vec pinecone.VectorStore,
+	bucket gcp.BucketService,
 	bootstrap services.LearningBuildBootstrapService,
 ) *Pipeline {
 	return &Pipeline{
@@ -43,11 +48,13 @@ func New(
 		path:      path,
 		nodes:     nodes,
 		figures:   figures,
+		blobs:     blobs,
 		genRuns:   genRuns,
 		files:     files,
 		chunks:    chunks,
 		ai:        ai,
 		vec:       vec,
+		bucket:    bucket,
 		bootstrap: bootstrap,
 	}
 }
@@ -23,17 +23,19 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 
 	jc.Progress("figures_plan", 2, "Planning figures")
 	out, err := learningmod.New(learningmod.UsecasesDeps{
-		DB:        p.db,
-		Log:       p.log,
-		Path:      p.path,
-		PathNodes: p.nodes,
-		Figures:   p.figures,
-		GenRuns:   p.genRuns,
-		Files:     p.files,
-		Chunks:    p.chunks,
-		AI:        p.ai,
-		Vec:       p.vec,
-		Bootstrap: p.bootstrap,
+		DB:          p.db,
+		Log:         p.log,
+		Path:        p.path,
+		PathNodes:   p.nodes,
+		Figures:     p.figures,
+		FigureBlobs: p.blobs,
+		GenRuns:     p.genRuns,
+		Files:       p.files,
+		Chunks:      p.chunks,
+		AI:          p.ai,
+		Vec:         p.vec,
+		Bucket:      p.bucket,
+		Bootstrap:   p.bootstrap,
 	}).NodeFiguresPlanBuild(jc.Ctx, learningmod.NodeFiguresPlanBuildInput{
 		OwnerUserID:   jc.Job.OwnerUserID,
 		MaterialSetID: setID,
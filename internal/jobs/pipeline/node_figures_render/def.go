@@ -16,6 +16,7 @@ type Pipeline struct {
 	path      repos.PathRepo
 	nodes     repos.PathNodeRepo
 	figures   repos.LearningNodeFigureRepo
+	blobs     repos.FigureBlobRepo
 	assets    repos.AssetRepo
 	genRuns   repos.LearningDocGenerationRunRepo
 	ai        openai.Client
@@ -29,6 +30,7 @@ func New(
 	path repos.PathRepo,
 	nodes repos.PathNodeRepo,
 	figures repos.LearningNodeFigureRepo,
+	blobs repos.FigureBlobRepo,
 	assets repos.AssetRepo,
 	genRuns repos.LearningDocGenerationRunRepo,
 	ai openai.Client,
@@ -41,6 +43,7 @@ func New(
 		path:      path,
 		nodes:     nodes,
 		figures:   figures,
+		blobs:     blobs,
 		assets:    assets,
 		genRuns:   genRuns,
 		ai:        ai,
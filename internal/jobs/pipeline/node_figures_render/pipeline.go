@@ -23,16 +23,17 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 
 	jc.Progress("figures_render", 2, "Rendering figures")
 	out, err := learningmod.New(learningmod.UsecasesDeps{
-		DB:        p.db,
-		Log:       p.log,
-		Path:      p.path,
-		PathNodes: p.nodes,
-		Figures:   p.figures,
-		Assets:    p.assets,
-		GenRuns:   p.genRuns,
-		AI:        p.ai,
-		Bucket:    p.bucket,
-		Bootstrap: p.bootstrap,
+		DB:          p.db,
+		Log:         p.log,
+		Path:        p.path,
+		PathNodes:   p.nodes,
+		Figures:     p.figures,
+		FigureBlobs: p.blobs,
+		Assets:      p.assets,
+		GenRuns:     p.genRuns,
+		AI:          p.ai,
+		Bucket:      p.bucket,
+		Bootstrap:   p.bootstrap,
 	}).NodeFiguresRender(jc.Ctx, learningmod.NodeFiguresRenderInput{
 		OwnerUserID:   jc.Job.OwnerUserID,
 		MaterialSetID: setID,
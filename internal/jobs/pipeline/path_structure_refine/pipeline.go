@@ -21,6 +21,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/jobs/pipeline/structuraltrace"
 	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
@@ -223,7 +224,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	nestMin := envFloat("PATH_STRUCTURE_REFINE_NEST_MIN_CONTAINMENT", 0.82)
 	nestMaxReverse := envFloat("PATH_STRUCTURE_REFINE_NEST_MAX_REVERSE_CONTAINMENT", 0.68)
 	contentType := "mixed"
-	if adaptiveParamsEnabledForStage("path_structure_refine") && p.files != nil {
+	if adaptiveParamsEnabledForStage(jc.Ctx, "path_structure_refine") && p.files != nil {
 		if ct := detectContentTypeForPaths(dbc, p.files, withGraph); ct != "" {
 			contentType = ct
 		}
@@ -747,11 +748,18 @@ func appendRefinementMessage(
 	return created, nil
 }
 
-func adaptiveParamsEnabledForStage(stage string) bool {
+// adaptiveParamsEnabledForStage mirrors steps.adaptiveParamsEnabledForStage: the
+// "adaptive_params.<stage>" flag, with the env switches as its default.
+func adaptiveParamsEnabledForStage(ctx context.Context, stage string) bool {
+	stage = strings.TrimSpace(stage)
+	enabled, _ := featureflag.Bool(ctx, "adaptive_params."+strings.ToLower(stage), adaptiveParamsEnvEnabled(stage))
+	return enabled
+}
+
+func adaptiveParamsEnvEnabled(stage string) bool {
 	if !envBool("ADAPTIVE_PARAMS_ENABLED", true) {
 		return false
 	}
-	stage = strings.TrimSpace(stage)
 	if stage == "" {
 		return true
 	}
@@ -5,13 +5,16 @@ import (
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
 type Pipeline struct {
-	db     *gorm.DB
-	log    *logger.Logger
-	traces repos.DecisionTraceRepo
-	evals  repos.PolicyEvalSnapshotRepo
+	db        *gorm.DB
+	log       *logger.Logger
+	traces    repos.DecisionTraceRepo
+	evals     repos.PolicyEvalSnapshotRepo
+	exposures repos.DocVariantExposureRepo
+	notes     services.NotificationService
 }
 
 func New(
@@ -19,12 +22,16 @@ func New(
 	baseLog *logger.Logger,
 	traces repos.DecisionTraceRepo,
 	evals repos.PolicyEvalSnapshotRepo,
+	exposures repos.DocVariantExposureRepo,
+	notes services.NotificationService,
 ) *Pipeline {
 	return &Pipeline{
-		db:     db,
-		log:    baseLog.With("job", "policy_eval_refresh"),
-		traces: traces,
-		evals:  evals,
+		db:        db,
+		log:       baseLog.With("job", "policy_eval_refresh"),
+		traces:    traces,
+		evals:     evals,
+		exposures: exposures,
+		notes:     notes,
 	}
 }
 
@@ -145,10 +145,12 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		Lift:       lift,
 		MetricsJSON: datatypes.JSON(mustJSON(metrics)),
 	}
+	prev, _ := p.evals.GetLatestByKey(dbc, policyKey)
 	if err := p.evals.Create(dbc, snap); err != nil {
 		jc.Fail("persist", err)
 		return nil
 	}
+	rollbackNotified := p.notifyVariantRollback(dbc, prev, snap)
 
 	res := map[string]any{
 		"policy_key":   policyKey,
@@ -158,6 +160,9 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		"ips":          ips,
 		"lift":         lift,
 	}
+	if rollbackNotified > 0 {
+		res["rollback_notified"] = rollbackNotified
+	}
 	jc.Succeed("done", res)
 	return nil
 }
@@ -0,0 +1,66 @@
+package policy_eval_refresh
+
+import (
+	"time"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// variantRollbackReaderWindow bounds who hears about a rollback: only readers served a
+// variant this recently saw content that is now replaced by the base doc.
+const variantRollbackReaderWindow = 7 * 24 * time.Hour
+
+// notifyVariantRollback tells recent variant readers that their lessons reverted to the base
+// doc. A rollback is the doc policy's safety gate flipping from safe to unsafe; doc serving
+// stops serving variants at that point (see PathHandler.GetPathNodeDoc).
+func (p *Pipeline) notifyVariantRollback(dbc dbctx.Context, prev, next *types.PolicyEvalSnapshot) int {
+	if p.exposures == nil || p.notes == nil || prev == nil || next == nil {
+		return 0
+	}
+	policy := docgen.DocPolicy(dbc.Ctx)
+	if next.PolicyKey != policy.PolicyKey || policy.Mode != "active" || !policy.RequireSafe {
+		return 0
+	}
+	if !policy.SnapshotSafe(prev) || policy.SnapshotSafe(next) {
+		return 0
+	}
+
+	limit := envutil.Int("DOC_VARIANT_ROLLBACK_NOTIFY_MAX", 5000)
+	readers, err := p.exposures.ListServedReadersSince(dbc, next.WindowEnd.Add(-variantRollbackReaderWindow), limit)
+	if err != nil {
+		p.log.Warn("Failed to list variant readers for rollback", "error", err, "policy_key", next.PolicyKey)
+		return 0
+	}
+	notified := 0
+	for _, r := range readers {
+		nodeID := r.PathNodeID
+		n, err := p.notes.Notify(dbc, services.NotificationInput{
+			UserID:   r.UserID,
+			Kind:     services.NotificationKindDocVariantRolledBack,
+			Title:    "A lesson you read was updated",
+			Body:     "A personalized version of this lesson was withdrawn; you'll now see the standard version.",
+			LinkType: "path_node",
+			LinkID:   &nodeID,
+			DedupKey: "variant_rollback:" + next.ID.String() + ":" + nodeID.String(),
+			Metadata: map[string]any{
+				"path_id":        r.PathID.String(),
+				"path_node_id":   nodeID.String(),
+				"policy_key":     next.PolicyKey,
+				"snapshot_id":    next.ID.String(),
+				"last_served_at": r.LastServed.UTC().Format(time.RFC3339),
+			},
+		})
+		if err != nil {
+			p.log.Warn("Failed to record rollback notification", "error", err, "user_id", r.UserID, "path_node_id", nodeID)
+			continue
+		}
+		if n != nil {
+			notified++
+		}
+	}
+	return notified
+}
@@ -42,6 +42,7 @@ type Pipeline struct {
 	evals            repos.PolicyEvalSnapshotRepo
 	jobSvc           services.JobService
 	notify           services.RuntimeNotifier
+	notes            services.NotificationService
 	metrics          *observability.Metrics
 }
 
@@ -78,6 +79,7 @@ func New(
 	evals repos.PolicyEvalSnapshotRepo,
 	jobSvc services.JobService,
 	notify services.RuntimeNotifier,
+	notes services.NotificationService,
 	metrics *observability.Metrics,
 ) *Pipeline {
 	return &Pipeline{
@@ -113,6 +115,7 @@ func New(
 		evals:            evals,
 		jobSvc:           jobSvc,
 		notify:           notify,
+		notes:            notes,
 		metrics:          metrics,
 	}
 }
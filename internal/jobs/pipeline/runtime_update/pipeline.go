@@ -46,6 +46,7 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	processed := 0
 	start := time.Now()
 	progressiveCandidates := map[uuid.UUID]progressiveDocCandidate{}
+	quizPaths := map[uuid.UUID]bool{}
 
 	jc.Progress("scan", 1, "Scanning runtime events")
 
@@ -183,6 +184,9 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 						return err
 					}
 				}
+				if typ == types.EventQuizCompleted {
+					quizPaths[pathID] = true
+				}
 				if nodeID != uuid.Nil && shouldConsiderProgressiveDoc(typ) {
 					progressiveCandidates[pathID] = progressiveDocCandidate{
 						PathID:        pathID,
@@ -248,6 +252,12 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 			}
 		}
 
+		// Quiz results move mastery; re-check the gates ahead of the user on those paths.
+		for pathID := range quizPaths {
+			p.maybeEnqueuePrereqGatePrefetch(dbctx.Context{Ctx: jc.Ctx}, userID, pathID)
+			delete(quizPaths, pathID)
+		}
+
 		afterAt = pageLastAt
 		afterID = pageLastID
 	}
@@ -15,6 +15,7 @@ import (
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
 const prereqGateSchemaVersion = 1
@@ -73,6 +74,13 @@ func (p *Pipeline) applyPrereqGate(dbc dbctx.Context, userID uuid.UUID, pathID u
 		}
 	}
 
+	return p.evaluatePrereqGate(dbc, userID, pathID, nodeID, failStreak, now)
+}
+
+// evaluatePrereqGate computes the node's readiness from stored mastery, edge and misconception
+// data (no LLM calls) and persists the readiness snapshot and gate decision. Callers own
+// debouncing; the prefetch job calls it directly for nodes the user has not opened yet.
+func (p *Pipeline) evaluatePrereqGate(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID, nodeID uuid.UUID, failStreak int, now time.Time) error {
 	node, err := p.pathNodes.GetByID(dbc, nodeID)
 	if err != nil || node == nil {
 		return nil
@@ -362,6 +370,7 @@ func (p *Pipeline) applyPrereqGate(dbc dbctx.Context, userID uuid.UUID, pathID u
 			weightsByKey[key] = weight
 		}
 	}
+	masteryBaseline := prereqMasteryBaseline(weightsByID, stateByID, now)
 
 	snapshot := prereqReadinessSnapshot{
 		Status:                status,
@@ -516,9 +525,14 @@ func (p *Pipeline) applyPrereqGate(dbc dbctx.Context, userID uuid.UUID, pathID u
 		"weights":                snapshot.Weights,
 		"actions":                actions,
 		"computed_at":            snapshot.ComputedAt,
+		"mastery_baseline":       masteryBaseline,
 	}
 
 	if p.gates != nil {
+		prev, _ := p.gates.GetLatestByUserAndNode(dbc, userID, nodeID)
+		if prev != nil && prev.Decision == "blocked" && decision != "blocked" {
+			p.notifyPrereqUnblocked(dbc, userID, pathID, nodeID, prev.SnapshotID, snapshotID)
+		}
 		_ = p.gates.Upsert(dbc, &types.PrereqGateDecision{
 			UserID:          userID,
 			PathID:          pathID,
@@ -567,6 +581,34 @@ func (p *Pipeline) applyPrereqGate(dbc dbctx.Context, userID uuid.UUID, pathID u
 	return nil
 }
 
+// notifyPrereqUnblocked tells the user a hard-gated node opened up. The dedup key pins the
+// blocked->open transition so re-evaluating the same snapshots does not repeat it.
+func (p *Pipeline) notifyPrereqUnblocked(dbc dbctx.Context, userID, pathID, nodeID uuid.UUID, fromSnapshot, toSnapshot string) {
+	if p.notes == nil {
+		return
+	}
+	title := "A new lesson is unlocked"
+	if p.pathNodes != nil {
+		if node, err := p.pathNodes.GetByID(dbc, nodeID); err == nil && node != nil && strings.TrimSpace(node.Title) != "" {
+			title = "Unlocked: " + strings.TrimSpace(node.Title)
+		}
+	}
+	id := nodeID
+	_, err := p.notes.Notify(dbc, services.NotificationInput{
+		UserID:   userID,
+		Kind:     services.NotificationKindPrereqUnblocked,
+		Title:    title,
+		Body:     "You've met the prerequisites for this lesson.",
+		LinkType: "path_node",
+		LinkID:   &id,
+		DedupKey: "prereq_unblocked:" + nodeID.String() + ":" + fromSnapshot + ":" + toSnapshot,
+		Metadata: map[string]any{"path_id": pathID.String(), "path_node_id": nodeID.String()},
+	})
+	if err != nil && p.log != nil {
+		p.log.Warn("Failed to record prereq unblock notification", "error", err, "path_node_id", nodeID)
+	}
+}
+
 func computePrereqSnapshotID(snapshot map[string]any) string {
 	if snapshot == nil {
 		return ""
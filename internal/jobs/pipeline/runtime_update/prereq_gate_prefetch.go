@@ -0,0 +1,329 @@
+package runtime_update
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+	jobrt "github.com/yungbote/neurobridge-backend/internal/jobs/runtime"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// Reasons a stored gate decision is re-evaluated by the prefetch job.
+const (
+	prereqReevalMissing      = "missing"
+	prereqReevalPolicy       = "policy_changed"
+	prereqReevalStale        = "stale"
+	prereqReevalNoBaseline   = "no_baseline"
+	prereqReevalMasteryDelta = "mastery_delta"
+)
+
+// PrereqGatePrefetch is the prereq_gate_prefetch job. It evaluates prereq gates ahead of the
+// user for the next unopened nodes of their active paths, so a doc read finds a current decision
+// instead of a surprise block. It runs nightly for every user and for one path after quiz
+// events; evaluation reuses the runtime pipeline's LLM-free gate and is bounded per run.
+type PrereqGatePrefetch struct {
+	p   *Pipeline
+	log *logger.Logger
+}
+
+func NewPrereqGatePrefetch(p *Pipeline) *PrereqGatePrefetch {
+	return &PrereqGatePrefetch{p: p, log: p.log.With("job", "prereq_gate_prefetch")}
+}
+
+func (j *PrereqGatePrefetch) Type() string { return "prereq_gate_prefetch" }
+
+func (j *PrereqGatePrefetch) Run(jc *jobrt.Context) error {
+	if jc == nil || jc.Job == nil {
+		return nil
+	}
+	p := j.p
+	userID := jc.Job.OwnerUserID
+	if userID == uuid.Nil {
+		jc.Fail("validate", fmt.Errorf("prereq_gate_prefetch: missing owner_user_id"))
+		return nil
+	}
+	if p == nil || p.paths == nil || p.pathNodes == nil || p.nodeRuns == nil || p.concepts == nil || p.conStates == nil || p.gates == nil {
+		jc.Fail("validate", fmt.Errorf("prereq_gate_prefetch: missing deps"))
+		return nil
+	}
+	if !prereqGateEnabled() {
+		jc.Succeed("done", map[string]any{"skipped": "prereq_gate_disabled"})
+		return nil
+	}
+
+	dbc := dbctx.Context{Ctx: jc.Ctx}
+	now := time.Now().UTC()
+
+	jc.Progress("select", 1, "Selecting upcoming nodes")
+	paths, err := j.activePaths(dbc, userID, strings.TrimSpace(fmt.Sprint(jc.Payload()["path_id"])))
+	if err != nil {
+		jc.Fail("select", err)
+		return nil
+	}
+
+	inv := prereqGateInvalidation{
+		PolicyVersion: prereqGatePolicyVersion(),
+		MaxAge:        time.Duration(prereqGateFreshnessHours()) * time.Hour,
+		MasteryDelta:  prereqGateMasteryDelta(),
+	}
+	budget := prereqGatePrefetchMaxEvals()
+	perPath := prereqGatePrefetchNodes()
+	considered, fresh, evaluated, failed := 0, 0, 0, 0
+	reasons := map[string]int{}
+	capped := false
+
+	jc.Progress("evaluate", 10, "Evaluating prereq gates")
+paths:
+	for _, path := range paths {
+		nodes, err := p.pathNodes.GetByPathIDs(dbc, []uuid.UUID{path.ID})
+		if err != nil {
+			j.log.Warn("prereq_gate_prefetch: load nodes failed", "error", err, "path_id", path.ID)
+			continue
+		}
+		ids := make([]uuid.UUID, 0, len(nodes))
+		for _, n := range nodes {
+			if n != nil && n.ID != uuid.Nil {
+				ids = append(ids, n.ID)
+			}
+		}
+		runs := map[uuid.UUID]*types.NodeRun{}
+		if len(ids) > 0 {
+			rows, err := p.nodeRuns.ListByUserAndNodeIDs(dbc, userID, ids)
+			if err != nil {
+				j.log.Warn("prereq_gate_prefetch: load node runs failed", "error", err, "path_id", path.ID)
+				continue
+			}
+			for _, r := range rows {
+				if r != nil {
+					runs[r.NodeID] = r
+				}
+			}
+		}
+
+		for _, node := range upcomingPrereqNodes(nodes, runs, perPath) {
+			considered++
+			prev, err := p.gates.GetLatestByUserAndNode(dbc, userID, node.ID)
+			if err != nil {
+				failed++
+				continue
+			}
+			reason := inv.reason(prev, p.currentPrereqMastery(dbc, userID, prev, now), now)
+			if reason == "" {
+				fresh++
+				continue
+			}
+			if budget <= 0 {
+				capped = true
+				break paths
+			}
+			budget--
+			// Unopened nodes have no node run, so there is no fail streak to carry over.
+			if err := p.evaluatePrereqGate(dbc, userID, path.ID, node.ID, 0, now); err != nil {
+				failed++
+				j.log.Warn("prereq_gate_prefetch: evaluate failed", "error", err, "path_node_id", node.ID)
+				continue
+			}
+			evaluated++
+			reasons[reason]++
+		}
+	}
+
+	if capped {
+		j.log.Info("prereq gate prefetch capped", "user_id", userID, "evaluated", evaluated, "max_evals", prereqGatePrefetchMaxEvals())
+	}
+	jc.Succeed("done", map[string]any{
+		"paths":      len(paths),
+		"considered": considered,
+		"fresh":      fresh,
+		"evaluated":  evaluated,
+		"failed":     failed,
+		"reasons":    reasons,
+		"capped":     capped,
+	})
+	return nil
+}
+
+// maybeEnqueuePrereqGatePrefetch queues an incremental prefetch for one path; the prefetch job
+// itself skips decisions the new mastery did not move.
+func (p *Pipeline) maybeEnqueuePrereqGatePrefetch(dbc dbctx.Context, userID uuid.UUID, pathID uuid.UUID) {
+	if p == nil || p.jobSvc == nil || userID == uuid.Nil || pathID == uuid.Nil || !prereqGateEnabled() {
+		return
+	}
+	if _, _, err := p.jobSvc.EnqueuePrereqGatePrefetchIfNeeded(dbc, userID, pathID, "quiz_completed"); err != nil && p.log != nil {
+		p.log.Warn("Failed to enqueue prereq gate prefetch", "error", err, "path_id", pathID)
+	}
+}
+
+// activePaths lists the user's non-archived paths, most recently viewed first and capped, or
+// just pathID when the run is scoped to one path.
+func (j *PrereqGatePrefetch) activePaths(dbc dbctx.Context, userID uuid.UUID, pathID string) ([]*types.Path, error) {
+	var rows []*types.Path
+	if id, err := uuid.Parse(pathID); err == nil && id != uuid.Nil {
+		row, err := j.p.paths.GetByID(dbc, id)
+		if err != nil {
+			return nil, err
+		}
+		rows = []*types.Path{row}
+	} else {
+		all, err := j.p.paths.ListByUser(dbc, &userID)
+		if err != nil {
+			return nil, err
+		}
+		rows = all
+	}
+	out := make([]*types.Path, 0, len(rows))
+	for _, row := range rows {
+		if row == nil || row.UserID == nil || *row.UserID != userID {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(row.Status), "archived") {
+			continue
+		}
+		out = append(out, row)
+	}
+	sort.SliceStable(out, func(a, b int) bool {
+		la, lb := out[a].LastViewedAt, out[b].LastViewedAt
+		if la == nil || lb == nil {
+			return la != nil
+		}
+		return la.After(*lb)
+	})
+	if limit := prereqGatePrefetchMaxPaths(); len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// currentPrereqMastery reads today's mastery for the concepts in prev's baseline, so it can be
+// compared against what the decision was computed from. Concepts without state read as 0.
+func (p *Pipeline) currentPrereqMastery(dbc dbctx.Context, userID uuid.UUID, prev *types.PrereqGateDecision, now time.Time) map[string]float64 {
+	baseline, ok := prereqDecisionBaseline(prev)
+	if !ok || len(baseline) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(baseline))
+	for raw := range baseline {
+		if id, err := uuid.Parse(raw); err == nil && id != uuid.Nil {
+			ids = append(ids, id)
+		}
+	}
+	stateByID := map[uuid.UUID]*types.UserConceptState{}
+	if states, err := p.conStates.ListByUserAndConceptIDs(dbc, userID, ids); err == nil {
+		for _, st := range states {
+			if st != nil {
+				stateByID[st.ConceptID] = st
+			}
+		}
+	}
+	out := make(map[string]float64, len(ids))
+	for _, id := range ids {
+		out[id.String()] = prereqBaselineMastery(stateByID[id], now)
+	}
+	return out
+}
+
+// upcomingPrereqNodes returns the first k nodes in path order the user has not opened yet.
+func upcomingPrereqNodes(nodes []*types.PathNode, runs map[uuid.UUID]*types.NodeRun, k int) []*types.PathNode {
+	ordered := make([]*types.PathNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n != nil && n.ID != uuid.Nil {
+			ordered = append(ordered, n)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+	out := []*types.PathNode{}
+	for _, n := range ordered {
+		if len(out) >= k {
+			break
+		}
+		if run := runs[n.ID]; run != nil && run.State != "" && run.State != runtime.NodeRunNotStarted {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// prereqGateInvalidation decides when a stored gate decision no longer stands.
+type prereqGateInvalidation struct {
+	PolicyVersion string
+	MaxAge        time.Duration
+	// MasteryDelta is the smallest change in any prereq concept's mastery that invalidates a
+	// decision before it ages out.
+	MasteryDelta float64
+}
+
+// reason returns why prev needs re-evaluation, or "" while it is still current. current holds
+// today's mastery for the concepts in prev's baseline (see currentPrereqMastery).
+func (inv prereqGateInvalidation) reason(prev *types.PrereqGateDecision, current map[string]float64, now time.Time) string {
+	if prev == nil {
+		return prereqReevalMissing
+	}
+	if inv.PolicyVersion != "" && prev.PolicyVersion != inv.PolicyVersion {
+		return prereqReevalPolicy
+	}
+	computedAt := prev.CreatedAt
+	if ts := timeFromAny(decodeJSONMap(prev.EvidenceJSON)["computed_at"]); ts != nil {
+		computedAt = *ts
+	}
+	if inv.MaxAge > 0 && now.Sub(computedAt) > inv.MaxAge {
+		return prereqReevalStale
+	}
+	baseline, ok := prereqDecisionBaseline(prev)
+	if !ok {
+		return prereqReevalNoBaseline
+	}
+	for id, was := range baseline {
+		// Baselines are rounded to 4 places; the epsilon keeps a change of exactly delta counting.
+		if math.Abs(current[id]-was) >= inv.MasteryDelta-1e-9 {
+			return prereqReevalMasteryDelta
+		}
+	}
+	return ""
+}
+
+// prereqDecisionBaseline reads the per-concept mastery a decision was computed from; ok is false
+// for decisions recorded before baselines were kept.
+func prereqDecisionBaseline(prev *types.PrereqGateDecision) (map[string]float64, bool) {
+	if prev == nil {
+		return nil, false
+	}
+	raw, ok := decodeJSONMap(prev.EvidenceJSON)["mastery_baseline"]
+	if !ok {
+		return nil, false
+	}
+	out := map[string]float64{}
+	for id, v := range mapFromAny(raw) {
+		out[id] = floatFromAny(v, 0)
+	}
+	return out, true
+}
+
+// prereqMasteryBaseline records the mastery of each weighted prereq concept at evaluation time,
+// keyed by concept id; the prefetch job compares it to current mastery to spot stale gates.
+func prereqMasteryBaseline(weightsByID map[uuid.UUID]float64, stateByID map[uuid.UUID]*types.UserConceptState, now time.Time) map[string]float64 {
+	out := make(map[string]float64, len(weightsByID))
+	for id, weight := range weightsByID {
+		if weight <= 0 {
+			continue
+		}
+		out[id.String()] = prereqBaselineMastery(stateByID[id], now)
+	}
+	return out
+}
+
+func prereqBaselineMastery(st *types.UserConceptState, now time.Time) float64 {
+	if st == nil {
+		return 0
+	}
+	mastery, _, _, _, _ := deriveConceptSignals(st, now)
+	return math.Round(mastery*1e4) / 1e4
+}
@@ -0,0 +1,117 @@
+package runtime_update
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+)
+
+func TestPrereqGateInvalidationReason(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	conceptA := uuid.New().String()
+	conceptB := uuid.New().String()
+	inv := prereqGateInvalidation{PolicyVersion: "prereq_gate_v1", MaxAge: 20 * time.Hour, MasteryDelta: 0.1}
+
+	decision := func(computedAt time.Time, evidence map[string]any) *types.PrereqGateDecision {
+		if evidence == nil {
+			evidence = map[string]any{}
+		}
+		evidence["computed_at"] = computedAt.Format(time.RFC3339)
+		return &types.PrereqGateDecision{
+			PolicyVersion: "prereq_gate_v1",
+			EvidenceJSON:  encodeJSONMap(evidence),
+			CreatedAt:     computedAt,
+		}
+	}
+	baseline := map[string]any{"mastery_baseline": map[string]any{conceptA: 0.4, conceptB: 0.8}}
+	recent := now.Add(-2 * time.Hour)
+
+	oldPolicy := decision(recent, baseline)
+	oldPolicy.PolicyVersion = "prereq_gate_v0"
+
+	// created_at lags when a re-evaluation lands on an existing snapshot; computed_at wins.
+	reupserted := decision(recent, map[string]any{"mastery_baseline": map[string]any{conceptA: 0.4}})
+	reupserted.CreatedAt = now.Add(-72 * time.Hour)
+
+	tests := []struct {
+		name    string
+		prev    *types.PrereqGateDecision
+		current map[string]float64
+		want    string
+	}{
+		{name: "no decision yet", prev: nil, want: prereqReevalMissing},
+		{name: "policy changed", prev: oldPolicy, current: map[string]float64{conceptA: 0.4, conceptB: 0.8}, want: prereqReevalPolicy},
+		{name: "older than the window", prev: decision(now.Add(-21*time.Hour), baseline), current: map[string]float64{conceptA: 0.4, conceptB: 0.8}, want: prereqReevalStale},
+		{name: "inside the window and unchanged", prev: decision(now.Add(-19*time.Hour), baseline), current: map[string]float64{conceptA: 0.4, conceptB: 0.8}, want: ""},
+		{name: "evidence timestamp over created_at", prev: reupserted, current: map[string]float64{conceptA: 0.4}, want: ""},
+		{name: "decision without a baseline", prev: decision(recent, nil), want: prereqReevalNoBaseline},
+		{name: "change below the delta", prev: decision(recent, baseline), current: map[string]float64{conceptA: 0.49, conceptB: 0.75}, want: ""},
+		{name: "gain at the delta", prev: decision(recent, baseline), current: map[string]float64{conceptA: 0.5, conceptB: 0.8}, want: prereqReevalMasteryDelta},
+		{name: "decay past the delta", prev: decision(recent, baseline), current: map[string]float64{conceptA: 0.4, conceptB: 0.6}, want: prereqReevalMasteryDelta},
+		{name: "state gone reads as zero", prev: decision(recent, baseline), current: map[string]float64{conceptA: 0.4}, want: prereqReevalMasteryDelta},
+		{name: "empty baseline has nothing to drift", prev: decision(recent, map[string]any{"mastery_baseline": map[string]any{}}), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inv.reason(tt.prev, tt.current, now); got != tt.want {
+				t.Fatalf("reason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrereqMasteryBaselineRoundTrip(t *testing.T) {
+	now := time.Now().UTC()
+	seen := now
+	known, unknown, unweighted := uuid.New(), uuid.New(), uuid.New()
+	base := prereqMasteryBaseline(
+		map[uuid.UUID]float64{known: 1, unknown: 0.5, unweighted: 0},
+		map[uuid.UUID]*types.UserConceptState{known: {ConceptID: known, Mastery: 0.73456, Confidence: 0.9, LastSeenAt: &seen}},
+		now,
+	)
+	if len(base) != 2 || base[known.String()] != 0.7346 || base[unknown.String()] != 0 {
+		t.Fatalf("baseline = %v", base)
+	}
+
+	prev := &types.PrereqGateDecision{EvidenceJSON: encodeJSONMap(map[string]any{"mastery_baseline": base})}
+	got, ok := prereqDecisionBaseline(prev)
+	if !ok || len(got) != 2 || got[known.String()] != 0.7346 {
+		t.Fatalf("decoded baseline = %v (ok=%v)", got, ok)
+	}
+}
+
+func TestUpcomingPrereqNodes(t *testing.T) {
+	nodes := []*types.PathNode{}
+	for i := 5; i >= 1; i-- {
+		nodes = append(nodes, &types.PathNode{ID: uuid.New(), Index: i})
+	}
+	byIndex := map[int]*types.PathNode{}
+	for _, n := range nodes {
+		byIndex[n.Index] = n
+	}
+	runs := map[uuid.UUID]*types.NodeRun{
+		byIndex[1].ID: {State: runtime.NodeRunCompleted},
+		byIndex[2].ID: {State: runtime.NodeRunReading},
+		byIndex[3].ID: {State: runtime.NodeRunNotStarted},
+	}
+
+	got := upcomingPrereqNodes(append(nodes, nil), runs, 2)
+	if len(got) != 2 || got[0].Index != 3 || got[1].Index != 4 {
+		t.Fatalf("upcoming = %v", nodeIndexes(got))
+	}
+	if got := upcomingPrereqNodes(nodes, runs, 10); len(got) != 3 {
+		t.Fatalf("upcoming past the end = %v", nodeIndexes(got))
+	}
+}
+
+func nodeIndexes(nodes []*types.PathNode) []int {
+	out := make([]int, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, n.Index)
+	}
+	return out
+}
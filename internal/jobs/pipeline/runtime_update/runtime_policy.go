@@ -186,6 +186,29 @@ func prereqGatePolicyVersion() string {
 	return v
 }
 
+// prereqGatePrefetchNodes is how many unopened nodes per path the prefetch job evaluates ahead.
+func prereqGatePrefetchNodes() int {
+	return envInt("RUNTIME_PREREQ_GATE_PREFETCH_NODES", 3, 1, 20)
+}
+
+// prereqGatePrefetchMaxPaths caps the active paths one prefetch run looks at.
+func prereqGatePrefetchMaxPaths() int {
+	return envInt("RUNTIME_PREREQ_GATE_PREFETCH_MAX_PATHS", 10, 1, 100)
+}
+
+// prereqGatePrefetchMaxEvals caps gate evaluations per user per prefetch run.
+func prereqGatePrefetchMaxEvals() int {
+	return envInt("RUNTIME_PREREQ_GATE_PREFETCH_MAX_EVALS", 20, 1, 200)
+}
+
+func prereqGateFreshnessHours() int {
+	return envInt("RUNTIME_PREREQ_GATE_FRESHNESS_HOURS", 20, 1, 24*30)
+}
+
+func prereqGateMasteryDelta() float64 {
+	return envFloat("RUNTIME_PREREQ_GATE_MASTERY_DELTA", 0.1, 0.01, 1)
+}
+
 func misconResolveMinCorrect() int {
 	return envInt("RUNTIME_MISCON_RESOLVE_MIN_CORRECT", 2, 1, 10)
 }
@@ -15,6 +15,9 @@ type Pipeline struct {
 	sagas  repos.SagaRunRepo
 	saga   services.SagaService
 	bucket gcp.BucketService
+	notes  repos.UserNotificationRepo
+
+	budgetStats repos.ContextBudgetStatRepo
 }
 
 func New(
@@ -23,6 +26,8 @@ func New(
 	sagas repos.SagaRunRepo,
 	saga services.SagaService,
 	bucket gcp.BucketService,
+	notes repos.UserNotificationRepo,
+	budgetStats repos.ContextBudgetStatRepo,
 ) *Pipeline {
 	return &Pipeline{
 		db:     db,
@@ -30,6 +35,9 @@ func New(
 		sagas:  sagas,
 		saga:   saga,
 		bucket: bucket,
+		notes:  notes,
+
+		budgetStats: budgetStats,
 	}
 }
 
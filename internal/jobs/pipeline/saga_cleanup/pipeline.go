@@ -12,11 +12,13 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 
 	jc.Progress("cleanup", 2, "Cleaning up old sagas")
 	out, err := learningmod.New(learningmod.UsecasesDeps{
-		DB:     p.db,
-		Log:    p.log,
-		Sagas:  p.sagas,
-		Saga:   p.saga,
-		Bucket: p.bucket,
+		DB:            p.db,
+		Log:           p.log,
+		Sagas:         p.sagas,
+		Saga:          p.saga,
+		Bucket:        p.bucket,
+		Notifications: p.notes,
+		BudgetStats:   p.budgetStats,
 	}).SagaCleanup(jc.Ctx, learningmod.SagaCleanupInput{
 		OwnerUserID: jc.Job.OwnerUserID,
 	})
@@ -26,8 +28,10 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 	}
 
 	jc.Succeed("done", map[string]any{
-		"sagas_scanned":    out.SagasScanned,
-		"prefixes_deleted": out.PrefixesDeleted,
+		"sagas_scanned":         out.SagasScanned,
+		"prefixes_deleted":      out.PrefixesDeleted,
+		"notifications_deleted": out.NotificationsDeleted,
+		"budget_stats_deleted":  out.BudgetStatsDeleted,
 	})
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/observability"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/metabound"
 )
 
 type GraphVersionWriter interface {
@@ -194,7 +195,7 @@ func writeGraphVersion(ctx context.Context, deps Deps, input TraceInput, tags Ve
 		TaxonomyVersion:    tags.Taxonomy,
 		ClusteringVersion:  tags.Clustering,
 		CalibrationVersion: tags.Calibration,
-		Metadata:           datatypes.JSON(mustJSON(metadata)),
+		Metadata:           datatypes.JSON(metabound.New().JSON(metadata)),
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
@@ -237,7 +238,7 @@ func writeStructuralTrace(ctx context.Context, deps Deps, input TraceInput, tags
 		Thresholds:         datatypes.JSON(mustJSON(input.Thresholds)),
 		Invariants:         datatypes.JSON(mustJSON(report)),
 		ValidationStatus:   report.Status,
-		Metadata:           datatypes.JSON(mustJSON(input.Metadata)),
+		Metadata:           datatypes.JSON(metabound.New().JSON(input.Metadata)),
 	}
 	if trace.DecisionPhase == "" {
 		trace.DecisionPhase = "build"
@@ -0,0 +1,219 @@
+package user_model_update
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// Distractor misconceptions: a quick_check option tagged with misconception_key names the
+// misconception a learner most likely holds when picking it. Each wrong pick of a tagged
+// distractor reinforces one instance per (concept, key); correct answers on the same concept
+// decay it, and once confidence falls below the resolve threshold the instance is marked
+// resolved (inactive) rather than deleted so a relapse reactivates the same row.
+const (
+	distractorPatternPrefix       = "distractor:"
+	distractorInitialConfidence   = 0.45
+	distractorReinforceRate       = 0.4
+	distractorDecayFactor         = 0.6
+	distractorResolveBelow        = 0.2
+	distractorSupportSourceWrong  = "user_event"
+	distractorSupportSourceDecay  = "user_event_correct"
+	distractorSupportMaxPointers  = 20
+	distractorTriggerContextLimit = 12
+)
+
+func distractorMisconceptionKey(data map[string]any) string {
+	if data == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(stringFromAny(data["misconception_key"])))
+}
+
+func distractorPatternID(key string) string {
+	return distractorPatternPrefix + key
+}
+
+func isDistractorMisconception(row *types.UserMisconceptionInstance) bool {
+	return row != nil && row.PatternID != nil && strings.HasPrefix(*row.PatternID, distractorPatternPrefix)
+}
+
+func distractorInstanceKey(conceptID uuid.UUID, patternID string) string {
+	return conceptID.String() + "|" + patternID
+}
+
+// hasSupportPointer makes the fold idempotent per event: the same event id is never applied
+// twice to an instance, whether the batch is retried or the event is replayed.
+func hasSupportPointer(sup types.MisconceptionSupport, sourceType string, sourceID string) bool {
+	for _, p := range sup.Support {
+		if p.SourceType == sourceType && p.SourceID == sourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// reinforceDistractorMisconception records a wrong answer that selected a distractor tagged
+// with key. It creates the instance on first sight and otherwise moves confidence toward 1
+// (c += (1-c)*rate), reactivating a resolved instance. It reports whether the row changed.
+func reinforceDistractorMisconception(row *types.UserMisconceptionInstance, userID uuid.UUID, conceptID uuid.UUID, key string, seenAt time.Time, sourceID string, data map[string]any) (*types.UserMisconceptionInstance, bool) {
+	if userID == uuid.Nil || conceptID == uuid.Nil || key == "" || sourceID == "" {
+		return row, false
+	}
+	seenAt = seenAt.UTC()
+	if row == nil {
+		pattern := distractorPatternID(key)
+		row = &types.UserMisconceptionInstance{
+			UserID:             userID,
+			CanonicalConceptID: conceptID,
+			PatternID:          &pattern,
+			Description:        "distractor misconception_key=" + key,
+		}
+	}
+	sup := types.DecodeMisconceptionSupport(row.Support)
+	if hasSupportPointer(sup, distractorSupportSourceWrong, sourceID) {
+		return row, false
+	}
+
+	if row.Confidence <= 0 || row.FirstSeenAt == nil {
+		row.Confidence = distractorInitialConfidence
+	} else {
+		row.Confidence = clamp01(row.Confidence + (1-row.Confidence)*distractorReinforceRate)
+	}
+	row.Status = "active"
+	row.ClearedAt = nil
+	if row.FirstSeenAt == nil {
+		row.FirstSeenAt = &seenAt
+	}
+	row.LastSeenAt = &seenAt
+
+	if sup.SignatureType == "" || sup.SignatureType == "unknown" {
+		sup.SignatureType = inferMisconceptionSignature(types.EventQuestionAnswered, data, "frame_error")
+	}
+	sup = types.MergeMisconceptionSupportPointer(sup, types.MisconceptionSupportPointer{
+		SourceType: distractorSupportSourceWrong,
+		SourceID:   sourceID,
+		OccurredAt: seenAt.Format(time.RFC3339Nano),
+		Confidence: row.Confidence,
+	}, distractorSupportMaxPointers)
+	if ctx := misconceptionContextFromData(data); ctx != "" {
+		sup = types.AddMisconceptionTriggerContext(sup, ctx, distractorTriggerContextLimit)
+	}
+	row.Support = types.EncodeMisconceptionSupport(sup)
+	return row, true
+}
+
+// decayDistractorMisconception applies a correct answer on the instance's concept
+// (c *= decay). Only active instances decay; falling below the threshold resolves them.
+func decayDistractorMisconception(row *types.UserMisconceptionInstance, seenAt time.Time, sourceID string) bool {
+	if row == nil || row.Status != "active" || sourceID == "" {
+		return false
+	}
+	sup := types.DecodeMisconceptionSupport(row.Support)
+	if hasSupportPointer(sup, distractorSupportSourceDecay, sourceID) {
+		return false
+	}
+	seenAt = seenAt.UTC()
+	row.Confidence = clamp01(row.Confidence * distractorDecayFactor)
+	sup.ResolutionEvidenceCount++
+	sup.ResolutionConfidence = clamp01(1 - row.Confidence)
+	if row.Confidence < distractorResolveBelow {
+		row.Status = "resolved"
+		row.ClearedAt = &seenAt
+	}
+	sup = types.MergeMisconceptionSupportPointer(sup, types.MisconceptionSupportPointer{
+		SourceType: distractorSupportSourceDecay,
+		SourceID:   sourceID,
+		OccurredAt: seenAt.Format(time.RFC3339Nano),
+		Confidence: row.Confidence,
+	}, distractorSupportMaxPointers)
+	row.Support = types.EncodeMisconceptionSupport(sup)
+	return true
+}
+
+// distractorMisconceptionFold accumulates distractor instance updates across one batch of
+// question_answered events, seeded with the user's existing distractor instances.
+type distractorMisconceptionFold struct {
+	userID uuid.UUID
+	rows   map[string]*types.UserMisconceptionInstance
+	dirty  map[string]bool
+	order  []string
+}
+
+func newDistractorMisconceptionFold(userID uuid.UUID, existing []*types.UserMisconceptionInstance) *distractorMisconceptionFold {
+	f := &distractorMisconceptionFold{
+		userID: userID,
+		rows:   map[string]*types.UserMisconceptionInstance{},
+		dirty:  map[string]bool{},
+	}
+	for _, row := range existing {
+		if !isDistractorMisconception(row) || row.UserID != userID {
+			continue
+		}
+		f.rows[distractorInstanceKey(row.CanonicalConceptID, *row.PatternID)] = row
+	}
+	return f
+}
+
+// apply folds one answer on conceptIDs into the instances. A wrong pick of a tagged distractor
+// reinforces its key; a correct answer decays every active distractor instance on the concept.
+func (f *distractorMisconceptionFold) apply(conceptIDs []uuid.UUID, data map[string]any, isCorrect bool, seenAt time.Time, sourceID string) int {
+	if f == nil || sourceID == "" {
+		return 0
+	}
+	changed := 0
+	if !isCorrect {
+		key := distractorMisconceptionKey(data)
+		if key == "" {
+			return 0
+		}
+		for _, cc := range conceptIDs {
+			k := distractorInstanceKey(cc, distractorPatternID(key))
+			row, ok := reinforceDistractorMisconception(f.rows[k], f.userID, cc, key, seenAt, sourceID, data)
+			if ok {
+				f.rows[k] = row
+				f.markDirty(k)
+				changed++
+			}
+		}
+		return changed
+	}
+	want := map[uuid.UUID]bool{}
+	for _, cc := range conceptIDs {
+		want[cc] = true
+	}
+	for k, row := range f.rows {
+		if row == nil || !want[row.CanonicalConceptID] {
+			continue
+		}
+		if decayDistractorMisconception(row, seenAt, sourceID) {
+			f.markDirty(k)
+			changed++
+		}
+	}
+	return changed
+}
+
+func (f *distractorMisconceptionFold) markDirty(k string) {
+	if !f.dirty[k] {
+		f.dirty[k] = true
+		f.order = append(f.order, k)
+	}
+}
+
+// changedRows returns the instances touched by the batch, in first-touched order.
+func (f *distractorMisconceptionFold) changedRows() []*types.UserMisconceptionInstance {
+	if f == nil {
+		return nil
+	}
+	out := make([]*types.UserMisconceptionInstance, 0, len(f.order))
+	for _, k := range f.order {
+		if row := f.rows[k]; row != nil {
+			out = append(out, row)
+		}
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package user_model_update
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestDistractorMisconceptionFoldSequence(t *testing.T) {
+	userID, conceptID, otherConcept := uuid.New(), uuid.New(), uuid.New()
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	wrong := func(key string) map[string]any {
+		return map[string]any{"question_id": "qc1", "is_correct": false, "misconception_key": key}
+	}
+	correct := map[string]any{"question_id": "qc2", "is_correct": true}
+	concepts := []uuid.UUID{conceptID}
+
+	f := newDistractorMisconceptionFold(userID, nil)
+	confidence := func() (*types.UserMisconceptionInstance, float64) {
+		rows := f.changedRows()
+		if len(rows) != 1 {
+			t.Fatalf("expected one instance, got %d", len(rows))
+		}
+		return rows[0], rows[0].Confidence
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+	// Detection: the first tagged wrong pick creates an active instance.
+	if n := f.apply(concepts, wrong("confuses_mean_with_median"), false, t0, "e1"); n != 1 {
+		t.Fatalf("detection changed %d rows", n)
+	}
+	row, c := confidence()
+	if row.Status != "active" || !near(c, distractorInitialConfidence) || row.PatternID == nil || *row.PatternID != "distractor:confuses_mean_with_median" {
+		t.Fatalf("unexpected detected row: %+v", row)
+	}
+
+	// Idempotency: the same event is never applied twice.
+	if n := f.apply(concepts, wrong("confuses_mean_with_median"), false, t0, "e1"); n != 0 {
+		t.Fatalf("replayed event changed %d rows", n)
+	}
+
+	// Reinforcement: repeated selection moves confidence toward 1.
+	f.apply(concepts, wrong("confuses_mean_with_median"), false, t0.Add(time.Minute), "e2")
+	want := distractorInitialConfidence + (1-distractorInitialConfidence)*distractorReinforceRate
+	if _, c = confidence(); !near(c, want) {
+		t.Fatalf("reinforced confidence = %v, want %v", c, want)
+	}
+
+	// Untagged wrong answers and answers on other concepts leave it alone.
+	f.apply(concepts, wrong(""), false, t0.Add(2*time.Minute), "e3")
+	f.apply([]uuid.UUID{otherConcept}, correct, true, t0.Add(3*time.Minute), "e4")
+	if _, c = confidence(); !near(c, want) {
+		t.Fatalf("unrelated events changed confidence to %v", c)
+	}
+
+	// Decay and resolution: correct answers on the concept shrink confidence until resolved.
+	at := t0.Add(10 * time.Minute)
+	for i, id := range []string{"e5", "e6", "e7"} {
+		f.apply(concepts, correct, true, at.Add(time.Duration(i)*time.Minute), id)
+		want *= distractorDecayFactor
+	}
+	row, c = confidence()
+	if !near(c, want) || want >= distractorResolveBelow {
+		t.Fatalf("decayed confidence = %v, want %v below %v", c, want, distractorResolveBelow)
+	}
+	if row.Status != "resolved" || row.ClearedAt == nil || !row.ClearedAt.Equal(at.Add(2*time.Minute)) {
+		t.Fatalf("expected resolved instance, got status=%q cleared=%v", row.Status, row.ClearedAt)
+	}
+	sup := types.DecodeMisconceptionSupport(row.Support)
+	if sup.ResolutionEvidenceCount != 3 {
+		t.Fatalf("resolution evidence = %d", sup.ResolutionEvidenceCount)
+	}
+
+	// Resolved instances are inactive: further correct answers do not touch them.
+	if n := f.apply(concepts, correct, true, at.Add(time.Hour), "e8"); n != 0 {
+		t.Fatalf("resolved instance decayed again")
+	}
+
+	// A later batch seeded from storage ignores replays and reactivates on relapse.
+	next := newDistractorMisconceptionFold(userID, []*types.UserMisconceptionInstance{row})
+	if n := next.apply(concepts, wrong("confuses_mean_with_median"), false, t0.Add(time.Minute), "e2"); n != 0 {
+		t.Fatalf("replay across batches changed %d rows", n)
+	}
+	next.apply(concepts, wrong("confuses_mean_with_median"), false, at.Add(2*time.Hour), "e9")
+	relapsed := next.changedRows()
+	if len(relapsed) != 1 || relapsed[0].Status != "active" || relapsed[0].ClearedAt != nil {
+		t.Fatalf("expected reactivated instance, got %+v", relapsed)
+	}
+	if got, want := relapsed[0].Confidence, c+(1-c)*distractorReinforceRate; !near(got, want) {
+		t.Fatalf("relapse confidence = %v, want %v", got, want)
+	}
+	if relapsed[0].FirstSeenAt == nil || !relapsed[0].FirstSeenAt.Equal(t0) {
+		t.Fatalf("first_seen_at moved: %v", relapsed[0].FirstSeenAt)
+	}
+}
+
+func TestDistractorMisconceptionFoldIgnoresOtherPatterns(t *testing.T) {
+	userID, conceptID := uuid.New(), uuid.New()
+	generic := "incorrect_answer"
+	f := newDistractorMisconceptionFold(userID, []*types.UserMisconceptionInstance{{
+		UserID:             userID,
+		CanonicalConceptID: conceptID,
+		PatternID:          &generic,
+		Status:             "active",
+		Confidence:         0.6,
+	}})
+	if n := f.apply([]uuid.UUID{conceptID}, map[string]any{"is_correct": true}, true, time.Now(), "e1"); n != 0 {
+		t.Fatalf("non-distractor instance decayed")
+	}
+}
@@ -436,6 +436,32 @@ func (p *Pipeline) Run(ctx *jobrt.Context) error {
 				}
 			}
 
+			// Preload distractor misconception instances for concepts on answered questions.
+			var distractorFold *distractorMisconceptionFold
+			if p.misconRepo != nil {
+				answeredConcepts := []uuid.UUID{}
+				for _, it := range items {
+					if it.ev == nil || strings.TrimSpace(it.ev.Type) != types.EventQuestionAnswered {
+						continue
+					}
+					cids := extractUUIDsFromAny(it.data["concept_ids"])
+					if it.ev.ConceptID != nil && *it.ev.ConceptID != uuid.Nil && len(cids) == 0 {
+						cids = []uuid.UUID{*it.ev.ConceptID}
+					}
+					for _, rawID := range cids {
+						if cc := canonicalByRaw[rawID]; cc != uuid.Nil {
+							answeredConcepts = append(answeredConcepts, cc)
+						}
+					}
+				}
+				answeredConcepts = dedupeUUIDs(answeredConcepts)
+				if len(answeredConcepts) > 0 {
+					if rows, err := p.misconRepo.ListByUserAndConceptIDs(tdbc, userID, answeredConcepts); err == nil {
+						distractorFold = newDistractorMisconceptionFold(userID, rows)
+					}
+				}
+			}
+
 			dirty := map[uuid.UUID]bool{}
 			dirtyModel := map[uuid.UUID]bool{}
 			misconRows := []*types.UserMisconceptionInstance{}
@@ -512,6 +538,9 @@ func (p *Pipeline) Run(ctx *jobrt.Context) error {
 						}
 					}
 					ccIDs = dedupeUUIDs(ccIDs)
+					if distractorFold != nil && len(ccIDs) > 0 {
+						distractorFold.apply(ccIDs, it.data, isCorrect, seenAt, ev.ID.String())
+					}
 					if !isCorrect && len(ccIDs) > 0 {
 						sig := inferMisconceptionSignature(typ, it.data, "procedural_gap")
 						conf := clamp01(floatFromAny(it.data["grader_confidence"], floatFromAny(it.data["confidence"], 0.6)))
@@ -1419,6 +1448,7 @@ func (p *Pipeline) Run(ctx *jobrt.Context) error {
 			}
 
 			// Persist misconception instances.
+			misconRows = append(misconRows, distractorFold.changedRows()...)
 			if p.misconRepo != nil && len(misconRows) > 0 {
 				for _, row := range misconRows {
 					if row != nil {
@@ -187,9 +187,18 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		return nil
 	}
 
+	// A structured selection comes from a chat action tool the assistant already executed for this
+	// message (the tool ledger dedupes it), so it bypasses the classifier and the per-message
+	// idempotency check; the child leaving waiting_user keeps it from resuming twice.
+	selection := structuredSelection(jc.Payload())
+	if selection != nil && strings.TrimSpace(env.Waitpoint.Kind) != waitcfg.PathIntakeStructureKind {
+		jc.Succeed("done", map[string]any{"mode": "selection_unsupported", "waitpoint_kind": env.Waitpoint.Kind})
+		return nil
+	}
+
 	// Idempotency
-	if env.State.LastUserMessageID == userMsg.ID.String() ||
-		(env.State.LastUserSeqHandled > 0 && userMsg.Seq <= env.State.LastUserSeqHandled) {
+	if selection == nil && (env.State.LastUserMessageID == userMsg.ID.String() ||
+		(env.State.LastUserSeqHandled > 0 && userMsg.Seq <= env.State.LastUserSeqHandled)) {
 		jc.Succeed("done", map[string]any{"mode": "already_handled"})
 		return nil
 	}
@@ -228,10 +237,23 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 		AI:          p.ai,
 	}
 
-	decision, cr, ierr := interp.Run(ic)
-	if ierr != nil {
-		jc.Fail("interpret", ierr)
-		return nil
+	var (
+		decision waitpoint.Decision
+		cr       waitpoint.ClassifierResult
+	)
+	if selection != nil {
+		decision = waitpoint.Decision{Kind: waitpoint.DecisionConfirmResume, Selection: selection}
+		cr = waitpoint.ClassifierResult{Case: waitpoint.CaseCommitted, Confidence: 1, Reason: "structured_selection"}
+		if ct, ok := selection["commit_type"].(string); ok {
+			cr.CommitType = ct
+		}
+	} else {
+		var ierr error
+		decision, cr, ierr = interp.Run(ic)
+		if ierr != nil {
+			jc.Fail("interpret", ierr)
+			return nil
+		}
 	}
 
 	if p.traces != nil {
@@ -356,6 +378,22 @@ func (p *Pipeline) Run(jc *jobrt.Context) error {
 // Helper functions
 // ─────────────────────────────────────────────────────────────
 
+// structuredSelection returns the payload's "selection" object when the caller already knows the
+// user's decision (e.g. the confirm_intake chat action). Only confirm/change commits are accepted.
+func structuredSelection(payload map[string]any) map[string]any {
+	sel, ok := payload["selection"].(map[string]any)
+	if !ok || len(sel) == 0 {
+		return nil
+	}
+	ct, _ := sel["commit_type"].(string)
+	switch strings.ToLower(strings.TrimSpace(ct)) {
+	case "confirm", "change":
+		return sel
+	default:
+		return nil
+	}
+}
+
 // pausedStageFromJobStage extracts the paused stage name from job.Stage.
 // Format: "waiting_user_{stage}" -> "{stage}"
 func pausedStageFromJobStage(stage string) string {
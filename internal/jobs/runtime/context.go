@@ -15,6 +15,7 @@ import (
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
@@ -60,6 +61,8 @@ func NewContext(ctx context.Context, db *gorm.DB, job *types.JobRun, repo repos.
 	}
 	_ = c.decodePayload()
 	c.applyTraceData()
+	c.applyObjectTags()
+	c.applyFlagSubject()
 	return c
 }
 
@@ -88,20 +91,68 @@ func (c *Context) decodePayload() error {
 	return nil
 }
 
+// applyTraceData restores the trace from the job_run envelope, with the job run as the span.
+// Jobs enqueued before the envelope existed carried trace_id/request_id in the payload; jobs
+// enqueued outside any request (schedulers, sweeps) root a new trace at themselves so the jobs
+// they spawn can still be followed.
 func (c *Context) applyTraceData() {
-	if c == nil || c.Ctx == nil {
+	if c == nil || c.Ctx == nil || c.Job == nil {
 		return
 	}
-	payload := c.Payload()
-	traceID := strings.TrimSpace(fmt.Sprint(payload["trace_id"]))
-	reqID := strings.TrimSpace(fmt.Sprint(payload["request_id"]))
+	traceID := strings.TrimSpace(c.Job.TraceID)
+	reqID := strings.TrimSpace(c.Job.RequestID)
+	if traceID == "" && reqID == "" {
+		payload := c.Payload()
+		if v, ok := payload["trace_id"].(string); ok {
+			traceID = strings.TrimSpace(v)
+		}
+		if v, ok := payload["request_id"].(string); ok {
+			reqID = strings.TrimSpace(v)
+		}
+	}
+	if traceID == "" && c.Job.ID != uuid.Nil {
+		traceID = c.Job.ID.String()
+	}
 	if traceID == "" && reqID == "" {
 		return
 	}
-	c.Ctx = ctxutil.WithTraceData(c.Ctx, &ctxutil.TraceData{
+	td := &ctxutil.TraceData{
 		TraceID:   traceID,
 		RequestID: reqID,
-	})
+		JobID:     c.Job.ID,
+		Depth:     c.Job.TraceDepth,
+	}
+	if c.Job.ID != uuid.Nil {
+		td.SpanID = c.Job.ID.String()
+	}
+	c.Ctx = ctxutil.WithTraceData(c.Ctx, td)
+}
+
+// applyObjectTags tags every object this job uploads with its owner and producing job.
+func (c *Context) applyObjectTags() {
+	if c == nil || c.Ctx == nil || c.Job == nil {
+		return
+	}
+	tags := gcp.ObjectTags{ProducerJob: c.Job.JobType}
+	if c.Job.ID != uuid.Nil {
+		tags.ProducerJob = c.Job.JobType + ":" + c.Job.ID.String()
+	}
+	if c.Job.OwnerUserID != uuid.Nil {
+		tags.UserID = c.Job.OwnerUserID.String()
+	}
+	c.Ctx = gcp.WithObjectTags(c.Ctx, tags)
+}
+
+// applyFlagSubject evaluates feature flags in this job for its owner and payload path.
+func (c *Context) applyFlagSubject() {
+	if c == nil || c.Ctx == nil || c.Job == nil {
+		return
+	}
+	subject := ctxutil.FlagSubject{UserID: c.Job.OwnerUserID}
+	if pathID, ok := c.PayloadUUID("path_id"); ok {
+		subject.PathID = pathID
+	}
+	c.Ctx = ctxutil.WithFlagSubject(c.Ctx, subject)
 }
 
 /*
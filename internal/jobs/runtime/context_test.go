@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+func TestNewContextRestoresTraceEnvelope(t *testing.T) {
+	parent := uuid.New()
+	job := &types.JobRun{
+		ID:          uuid.New(),
+		JobType:     "chat_path_node_index",
+		Payload:     datatypes.JSON(`{"path_node_id":"x"}`),
+		TraceID:     "trace-1",
+		RequestID:   "req-1",
+		ParentJobID: &parent,
+		TraceDepth:  2,
+	}
+	td := ctxutil.GetTraceData(NewContext(context.Background(), nil, job, nil, nil).Ctx)
+	if td == nil || td.TraceID != "trace-1" || td.RequestID != "req-1" || td.SpanID != job.ID.String() || td.JobID != job.ID || td.Depth != 2 {
+		t.Fatalf("trace = %+v", td)
+	}
+
+	// Jobs queued before the envelope carried the ids in their payload.
+	legacy := &types.JobRun{ID: uuid.New(), Payload: datatypes.JSON(`{"trace_id":"trace-0","request_id":"req-0"}`)}
+	td = ctxutil.GetTraceData(NewContext(context.Background(), nil, legacy, nil, nil).Ctx)
+	if td == nil || td.TraceID != "trace-0" || td.RequestID != "req-0" || td.SpanID != legacy.ID.String() {
+		t.Fatalf("legacy trace = %+v", td)
+	}
+
+	// A job outside any request roots a trace at itself.
+	cron := &types.JobRun{ID: uuid.New(), Payload: datatypes.JSON(`{}`)}
+	td = ctxutil.GetTraceData(NewContext(context.Background(), nil, cron, nil, nil).Ctx)
+	if td == nil || td.TraceID != cron.ID.String() || td.RequestID != "" || td.Depth != 0 {
+		t.Fatalf("root trace = %+v", td)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
 )
 
 func extractTextItems(val any) []string {
@@ -138,19 +139,7 @@ func blockTextForContext(block map[string]any) (string, string) {
 }
 
 func blockTitleForContext(block map[string]any) string {
-	if block == nil {
-		return ""
-	}
-	if title := strings.TrimSpace(stringFromAnyCtx(block["title"])); title != "" {
-		return title
-	}
-	if text := strings.TrimSpace(stringFromAnyCtx(block["text"])); text != "" {
-		return text
-	}
-	if label := strings.TrimSpace(stringFromAnyCtx(block["label"])); label != "" {
-		return label
-	}
-	return ""
+	return content.BlockTitle(block)
 }
 
 func buildBlockDocBody(node *types.PathNode, blockID string, block map[string]any) (string, string, string, string) {
@@ -204,3 +193,15 @@ func parseBlockIDFromText(text string) string {
 	}
 	return ""
 }
+
+// uniqueRawBlockIDs resolves the IDs of raw doc blocks the way served docs do
+// (content.UniqueBlockIDs), so duplicate IDs don't make block lookups pick arbitrary blocks.
+func uniqueRawBlockIDs(rawBlocks []any) []string {
+	ids := make([]string, len(rawBlocks))
+	for i, raw := range rawBlocks {
+		if m, ok := raw.(map[string]any); ok {
+			ids[i] = stringFromAnyCtx(m["id"])
+		}
+	}
+	return content.UniqueBlockIDs(ids)
+}
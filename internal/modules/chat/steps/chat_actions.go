@@ -0,0 +1,625 @@
+package steps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	chatrepo "github.com/yungbote/neurobridge-backend/internal/data/repos/chat"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// Chat actions are server-executed tools the assistant can call on the user's behalf. Unlike the
+// job tools in chatToolRegistry they carry a JSON schema for their arguments, are only exposed
+// when the turn makes them relevant, and every execution is recorded in a per-message ledger so
+// a re-delivered generation replays the recorded result instead of acting twice.
+const (
+	chatActionConfirmIntake    = "confirm_intake"
+	chatActionMarkConceptKnown = "mark_concept_known"
+	chatActionDocPatchPreview  = "enqueue_doc_patch_preview"
+	chatActionCreateDrill      = "create_drill"
+)
+
+const (
+	chatActionStatusRejected = "rejected"
+	chatActionStatusRunning  = chatrepo.ChatToolExecutionRunning
+	chatActionStatusOK       = chatrepo.ChatToolExecutionSucceeded
+	chatActionStatusFailed   = chatrepo.ChatToolExecutionFailed
+)
+
+// PathActions is the learning usecase surface chat actions execute through; it is the same layer
+// the HTTP endpoints call, so a tool gets exactly the endpoint's ownership and input checks.
+type PathActions interface {
+	MarkConceptKnown(ctx context.Context, in learningmod.MarkConceptKnownInput) (learningmod.MarkConceptKnownOutput, error)
+	GeneratePathNodeDrill(ctx context.Context, in learningmod.GeneratePathNodeDrillInput) (json.RawMessage, error)
+	LoadPatchableNodeDoc(ctx context.Context, userID uuid.UUID, nodeID uuid.UUID, track string) (*types.PathNode, *types.LearningNodeDoc, error)
+}
+
+// chatActionScope is what exposure and execution know about the turn.
+type chatActionScope struct {
+	UserID             uuid.UUID
+	Thread             *types.ChatThread
+	UserMessageID      uuid.UUID
+	AssistantMessageID uuid.UUID
+
+	// Mode is the heuristic context route mode ("edit" exposes the doc patch tool).
+	Mode string
+	// IntakePending is set while the thread's build waits on path intake confirmation.
+	IntakePending bool
+	// EditTarget is the planner-resolved on-screen block for edit turns.
+	EditTarget *EditTarget
+	// ActivePathNodeID is the node the user had open when sending the message.
+	ActivePathNodeID uuid.UUID
+}
+
+func (s chatActionScope) pathID() uuid.UUID {
+	if s.Thread == nil || s.Thread.PathID == nil {
+		return uuid.Nil
+	}
+	return *s.Thread.PathID
+}
+
+type chatActionOutcome struct {
+	Text   string
+	Result map[string]any
+}
+
+type chatAction struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+
+	exposed  func(scope chatActionScope) bool
+	validate func(args map[string]any) (map[string]any, error)
+	execute  func(ctx context.Context, deps RespondDeps, scope chatActionScope, args map[string]any) (chatActionOutcome, error)
+}
+
+// chatActionRecord is the structured record stored on the assistant message under
+// "tool_executions" so the UI can render what ran and with which result.
+type chatActionRecord struct {
+	ToolName  string         `json:"tool_name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Status    string         `json:"status"`
+	Message   string         `json:"message,omitempty"`
+	Result    map[string]any `json:"result,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Replayed  bool           `json:"replayed,omitempty"`
+}
+
+var chatActionRegistry = []chatAction{
+	{
+		Name:        chatActionConfirmIntake,
+		Description: "Confirm the proposed grouping of uploaded files into learning paths and resume the build.",
+		Parameters:  objectSchema(map[string]any{}),
+		exposed:     func(s chatActionScope) bool { return s.IntakePending },
+		validate:    func(args map[string]any) (map[string]any, error) { return normalizeActionArgs(args, nil) },
+		execute:     executeConfirmIntake,
+	},
+	{
+		Name:        chatActionMarkConceptKnown,
+		Description: "Record that the user already knows a concept of this path.",
+		Parameters: objectSchema(map[string]any{
+			"concept_key": map[string]any{"type": "string", "description": "Concept key from the path's concept graph."},
+		}, "concept_key"),
+		exposed:  func(s chatActionScope) bool { return s.pathID() != uuid.Nil },
+		validate: validateMarkConceptKnownArgs,
+		execute:  executeMarkConceptKnown,
+	},
+	{
+		Name:        chatActionDocPatchPreview,
+		Description: "Draft a revision of the block the user is looking at and show a diff for confirmation.",
+		Parameters: objectSchema(map[string]any{
+			"instruction":  map[string]any{"type": "string", "description": "What to change, in the user's words."},
+			"path_node_id": map[string]any{"type": "string", "description": "Defaults to the node of the on-screen block."},
+			"block_id":     map[string]any{"type": "string", "description": "Defaults to the on-screen block."},
+		}, "instruction"),
+		exposed:  func(s chatActionScope) bool { return strings.EqualFold(strings.TrimSpace(s.Mode), "edit") },
+		validate: validateDocPatchPreviewArgs,
+		execute:  executeDocPatchPreview,
+	},
+	{
+		Name:        chatActionCreateDrill,
+		Description: "Generate a quiz or flashcards drill for the lesson the user has open.",
+		Parameters: objectSchema(map[string]any{
+			"kind":         map[string]any{"type": "string", "enum": []any{"quiz", "flashcards"}},
+			"count":        map[string]any{"type": "integer", "minimum": 1, "maximum": chatDrillMaxCount},
+			"path_node_id": map[string]any{"type": "string", "description": "Defaults to the lesson the user has open."},
+		}, "kind"),
+		exposed:  func(s chatActionScope) bool { return s.pathID() != uuid.Nil },
+		validate: validateCreateDrillArgs,
+		execute:  executeCreateDrill,
+	},
+}
+
+const (
+	chatActionMaxInstruction = 2000
+	chatActionMaxKey         = 200
+	chatDrillMaxCount        = 30
+)
+
+func chatActionByName(name string) (chatAction, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, a := range chatActionRegistry {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return chatAction{}, false
+}
+
+// exposedChatActions returns the actions the model may call for this turn.
+func exposedChatActions(scope chatActionScope) []chatAction {
+	out := []chatAction{}
+	for _, a := range chatActionRegistry {
+		if a.exposed != nil && a.exposed(scope) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// splitChatToolCalls separates chat action calls from job tool calls.
+func splitChatToolCalls(calls []chatToolCall) ([]chatToolCall, []chatToolCall) {
+	var actions, jobs []chatToolCall
+	for _, call := range calls {
+		call.ToolName = strings.ToLower(strings.TrimSpace(call.ToolName))
+		if _, ok := chatActionByName(call.ToolName); ok {
+			actions = append(actions, call)
+		} else {
+			jobs = append(jobs, call)
+		}
+	}
+	return actions, jobs
+}
+
+// runChatAction validates and executes one action call through the execution ledger.
+func runChatAction(ctx context.Context, deps RespondDeps, scope chatActionScope, name string, rawArgs map[string]any) chatActionRecord {
+	rec := chatActionRecord{ToolName: strings.ToLower(strings.TrimSpace(name)), Arguments: rawArgs}
+	action, ok := chatActionByName(name)
+	if !ok {
+		rec.Status, rec.Error = chatActionStatusRejected, "unsupported_tool"
+		return rec
+	}
+	if action.exposed == nil || !action.exposed(scope) {
+		rec.Status, rec.Error = chatActionStatusRejected, "not_exposed"
+		return rec
+	}
+	args, err := action.validate(rawArgs)
+	if err != nil {
+		rec.Status, rec.Error = chatActionStatusRejected, err.Error()
+		return rec
+	}
+	rec.Arguments = args
+	if deps.ToolExecs == nil || scope.AssistantMessageID == uuid.Nil || scope.Thread == nil {
+		rec.Status, rec.Error = chatActionStatusFailed, "tool_ledger_unavailable"
+		return rec
+	}
+
+	argsJSON, _ := json.Marshal(args)
+	dbc := dbctx.Context{Ctx: ctx, Tx: deps.DB}
+	row, claimed, err := deps.ToolExecs.Claim(dbc, &types.ChatToolExecution{
+		UserID:    scope.UserID,
+		ThreadID:  scope.Thread.ID,
+		MessageID: scope.AssistantMessageID,
+		ToolName:  action.Name,
+		ArgsHash:  chatActionArgsHash(argsJSON),
+		Arguments: datatypes.JSON(argsJSON),
+	})
+	if err != nil {
+		rec.Status, rec.Error = chatActionStatusFailed, "tool_ledger_failed"
+		return rec
+	}
+	if !claimed {
+		return replayChatAction(rec, row)
+	}
+
+	outcome, execErr := action.execute(ctx, deps, scope, args)
+	result := map[string]any{}
+	for k, v := range outcome.Result {
+		result[k] = v
+	}
+	if strings.TrimSpace(outcome.Text) != "" {
+		result["message"] = strings.TrimSpace(outcome.Text)
+	}
+	resultJSON, _ := json.Marshal(result)
+	rec.Message = strings.TrimSpace(outcome.Text)
+	rec.Result = outcome.Result
+	if execErr != nil {
+		rec.Status, rec.Error = chatActionStatusFailed, chatActionErrorCode(execErr)
+		_ = deps.ToolExecs.Finish(dbc, row.ID, chatActionStatusFailed, datatypes.JSON(resultJSON), rec.Error)
+		return rec
+	}
+	rec.Status = chatActionStatusOK
+	_ = deps.ToolExecs.Finish(dbc, row.ID, chatActionStatusOK, datatypes.JSON(resultJSON), "")
+	return rec
+}
+
+// replayChatAction turns an existing ledger row into the record of this delivery.
+func replayChatAction(rec chatActionRecord, row *types.ChatToolExecution) chatActionRecord {
+	rec.Replayed = true
+	if row == nil {
+		rec.Status = chatActionStatusRunning
+		return rec
+	}
+	rec.Status = row.Status
+	rec.Error = row.Error
+	if len(row.Result) > 0 {
+		result := map[string]any{}
+		if err := json.Unmarshal(row.Result, &result); err == nil {
+			if msg, ok := result["message"].(string); ok {
+				rec.Message = msg
+				delete(result, "message")
+			}
+			if len(result) > 0 {
+				rec.Result = result
+			}
+		}
+	}
+	return rec
+}
+
+func chatActionArgsHash(argsJSON []byte) string {
+	sum := sha256.Sum256(argsJSON)
+	return hex.EncodeToString(sum[:])
+}
+
+func chatActionErrorCode(err error) string {
+	var ae *apierr.Error
+	if errors.As(err, &ae) && strings.TrimSpace(ae.Code) != "" {
+		return ae.Code
+	}
+	return err.Error()
+}
+
+// runChatActionCalls executes the routed action calls in confidence order, up to the tool cap.
+func runChatActionCalls(ctx context.Context, deps RespondDeps, scope chatActionScope, calls []chatToolCall) []chatActionRecord {
+	maxCalls := resolveChatToolMaxCalls()
+	out := []chatActionRecord{}
+	seen := map[string]bool{}
+	for _, call := range pickToolCalls(calls) {
+		if len(out) >= maxCalls {
+			break
+		}
+		name := strings.ToLower(strings.TrimSpace(call.ToolName))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, runChatAction(ctx, deps, scope, name, call.Arguments))
+	}
+	return out
+}
+
+// chatActionReply summarizes executed actions for the assistant message.
+func chatActionReply(records []chatActionRecord) string {
+	lines := []string{}
+	for _, r := range records {
+		switch {
+		case strings.TrimSpace(r.Message) != "":
+			lines = append(lines, r.Message)
+		case r.Status == chatActionStatusRunning:
+			lines = append(lines, "That action is already in progress.")
+		case r.Status == chatActionStatusRejected || r.Status == chatActionStatusFailed:
+			lines = append(lines, fmt.Sprintf("I couldn’t run %s (%s).", r.ToolName, r.Error))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ─────────────────────────────────────────────────────────────
+// Argument validation
+// ─────────────────────────────────────────────────────────────
+
+func objectSchema(props map[string]any, required ...string) map[string]any {
+	req := make([]any, 0, len(required))
+	for _, r := range required {
+		req = append(req, r)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           props,
+		"required":             req,
+	}
+}
+
+// normalizeActionArgs rejects keys outside allowed and drops null values.
+func normalizeActionArgs(args map[string]any, allowed []string) (map[string]any, error) {
+	ok := map[string]bool{}
+	for _, k := range allowed {
+		ok[k] = true
+	}
+	out := map[string]any{}
+	for k, v := range args {
+		key := strings.ToLower(strings.TrimSpace(k))
+		if !ok[key] {
+			return nil, fmt.Errorf("unexpected_argument:%s", key)
+		}
+		if v != nil {
+			out[key] = v
+		}
+	}
+	return out, nil
+}
+
+func requiredStringArg(args map[string]any, key string, maxLen int) (string, error) {
+	s, err := optionalStringArg(args, key, maxLen)
+	if err != nil {
+		return "", err
+	}
+	if s == "" {
+		return "", fmt.Errorf("missing_argument:%s", key)
+	}
+	return s, nil
+}
+
+func optionalStringArg(args map[string]any, key string, maxLen int) (string, error) {
+	v, ok := args[key]
+	if !ok {
+		return "", nil
+	}
+	s, isStr := v.(string)
+	if !isStr {
+		return "", fmt.Errorf("invalid_argument:%s", key)
+	}
+	s = strings.TrimSpace(s)
+	if maxLen > 0 && len(s) > maxLen {
+		return "", fmt.Errorf("invalid_argument:%s", key)
+	}
+	return s, nil
+}
+
+func validateMarkConceptKnownArgs(args map[string]any) (map[string]any, error) {
+	args, err := normalizeActionArgs(args, []string{"concept_key"})
+	if err != nil {
+		return nil, err
+	}
+	key, err := requiredStringArg(args, "concept_key", chatActionMaxKey)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"concept_key": strings.ToLower(key)}, nil
+}
+
+func validateDocPatchPreviewArgs(args map[string]any) (map[string]any, error) {
+	args, err := normalizeActionArgs(args, []string{"instruction", "path_node_id", "block_id"})
+	if err != nil {
+		return nil, err
+	}
+	instruction, err := requiredStringArg(args, "instruction", chatActionMaxInstruction)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{"instruction": instruction}
+	if raw, ok := args["path_node_id"]; ok {
+		id := parseUUIDFromAny(raw)
+		if id == uuid.Nil {
+			return nil, fmt.Errorf("invalid_argument:path_node_id")
+		}
+		out["path_node_id"] = id.String()
+	}
+	blockID, err := optionalStringArg(args, "block_id", chatActionMaxKey)
+	if err != nil {
+		return nil, err
+	}
+	if blockID != "" {
+		out["block_id"] = blockID
+	}
+	return out, nil
+}
+
+func validateCreateDrillArgs(args map[string]any) (map[string]any, error) {
+	args, err := normalizeActionArgs(args, []string{"kind", "count", "path_node_id"})
+	if err != nil {
+		return nil, err
+	}
+	kind, err := requiredStringArg(args, "kind", 0)
+	if err != nil {
+		return nil, err
+	}
+	kind = strings.ToLower(kind)
+	if kind != "quiz" && kind != "flashcards" {
+		return nil, fmt.Errorf("invalid_argument:kind")
+	}
+	out := map[string]any{"kind": kind}
+	if raw, ok := args["count"]; ok {
+		n, isNum := raw.(float64)
+		if !isNum {
+			if i, isInt := raw.(int); isInt {
+				n, isNum = float64(i), true
+			}
+		}
+		if !isNum || n != math.Trunc(n) || n < 1 || n > chatDrillMaxCount {
+			return nil, fmt.Errorf("invalid_argument:count")
+		}
+		out["count"] = int(n)
+	}
+	if raw, ok := args["path_node_id"]; ok {
+		id := parseUUIDFromAny(raw)
+		if id == uuid.Nil {
+			return nil, fmt.Errorf("invalid_argument:path_node_id")
+		}
+		out["path_node_id"] = id.String()
+	}
+	return out, nil
+}
+
+// ─────────────────────────────────────────────────────────────
+// Execution
+// ─────────────────────────────────────────────────────────────
+
+// executeConfirmIntake hands a structured confirm selection to waitpoint_interpret, which applies
+// it exactly like a classified confirmation and resumes the paused build.
+func executeConfirmIntake(ctx context.Context, deps RespondDeps, scope chatActionScope, _ map[string]any) (chatActionOutcome, error) {
+	out := chatActionOutcome{}
+	if deps.Jobs == nil || scope.Thread == nil || scope.Thread.JobID == nil || *scope.Thread.JobID == uuid.Nil {
+		return out, fmt.Errorf("intake_unavailable")
+	}
+	payload := map[string]any{
+		"thread_id":       scope.Thread.ID.String(),
+		"user_message_id": scope.UserMessageID.String(),
+		"build_job_id":    scope.Thread.JobID.String(),
+		"selection":       map[string]any{"commit_type": "confirm"},
+	}
+	entityID := scope.Thread.ID
+	job, err := deps.Jobs.Enqueue(dbctx.Context{Ctx: ctx, Tx: deps.DB}, scope.UserID, "waitpoint_interpret", "chat_thread", &entityID, payload)
+	if err != nil {
+		return out, fmt.Errorf("enqueue_failed")
+	}
+	out.Text = "Got it — I'll proceed with these paths and continue."
+	out.Result = map[string]any{"job_id": job.ID.String(), "build_job_id": scope.Thread.JobID.String()}
+	return out, nil
+}
+
+func executeMarkConceptKnown(ctx context.Context, deps RespondDeps, scope chatActionScope, args map[string]any) (chatActionOutcome, error) {
+	out := chatActionOutcome{}
+	if deps.Actions == nil {
+		return out, fmt.Errorf("actions_unavailable")
+	}
+	key, _ := args["concept_key"].(string)
+	res, err := deps.Actions.MarkConceptKnown(ctx, learningmod.MarkConceptKnownInput{
+		UserID:     scope.UserID,
+		PathID:     scope.pathID(),
+		ConceptKey: key,
+	})
+	if err != nil {
+		return out, err
+	}
+	out.Text = fmt.Sprintf("Noted — I’ve marked %s as something you already know.", res.ConceptKey)
+	out.Result = map[string]any{
+		"concept_id":  res.ConceptID.String(),
+		"concept_key": res.ConceptKey,
+		"mastery":     res.Mastery,
+		"confidence":  res.Confidence,
+	}
+	return out, nil
+}
+
+// executeDocPatchPreview enqueues a node_doc_edit draft for the targeted block. The target comes
+// from the arguments when given, else from the planner's edit target for the turn.
+func executeDocPatchPreview(ctx context.Context, deps RespondDeps, scope chatActionScope, args map[string]any) (chatActionOutcome, error) {
+	out := chatActionOutcome{}
+	if deps.Actions == nil || deps.Jobs == nil || scope.Thread == nil {
+		return out, fmt.Errorf("actions_unavailable")
+	}
+	nodeID := parseUUIDFromAny(args["path_node_id"])
+	blockID, _ := args["block_id"].(string)
+	blockIndex := -1
+	track := ""
+	if t := scope.EditTarget; t != nil {
+		targetNode, _ := uuid.Parse(strings.TrimSpace(t.PathNodeID))
+		if nodeID == uuid.Nil {
+			nodeID = targetNode
+		}
+		if nodeID == targetNode {
+			track = t.Track
+		}
+		if blockID == "" && nodeID == targetNode {
+			blockID = strings.TrimSpace(t.BlockID)
+		}
+		if nodeID == targetNode && blockID == strings.TrimSpace(t.BlockID) {
+			blockIndex = t.BlockIndex
+		}
+	}
+	if nodeID == uuid.Nil || blockID == "" {
+		return out, fmt.Errorf("missing_block_target")
+	}
+
+	_, docRow, err := deps.Actions.LoadPatchableNodeDoc(ctx, scope.UserID, nodeID, track)
+	if err != nil {
+		return out, err
+	}
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		return out, fmt.Errorf("doc_invalid_json")
+	}
+	if !nodeDocHasBlock(doc, blockID) {
+		return out, fmt.Errorf("block_not_found")
+	}
+
+	instruction, _ := args["instruction"].(string)
+	payload := map[string]any{
+		"thread_id":       scope.Thread.ID.String(),
+		"path_node_id":    nodeID.String(),
+		"block_id":        blockID,
+		"action":          "rewrite",
+		"citation_policy": "reuse_only",
+		"instruction":     instruction,
+		"track":           docRow.Track,
+	}
+	if blockIndex >= 0 {
+		payload["block_index"] = blockIndex
+	}
+	entityID := nodeID
+	job, err := deps.Jobs.Enqueue(dbctx.Context{Ctx: ctx, Tx: deps.DB}, scope.UserID, "node_doc_edit", "path_node", &entityID, payload)
+	if err != nil {
+		return out, fmt.Errorf("enqueue_failed")
+	}
+	out.Text = "Drafting a revision for the current block. I’ll show a diff for confirmation."
+	out.Result = map[string]any{
+		"job_id":       job.ID.String(),
+		"path_node_id": nodeID.String(),
+		"block_id":     blockID,
+	}
+	return out, nil
+}
+
+func nodeDocHasBlock(doc content.NodeDocV1, blockID string) bool {
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		if id, _ := b["id"].(string); strings.TrimSpace(id) == blockID {
+			return true
+		}
+	}
+	return false
+}
+
+func executeCreateDrill(ctx context.Context, deps RespondDeps, scope chatActionScope, args map[string]any) (chatActionOutcome, error) {
+	out := chatActionOutcome{}
+	if deps.Actions == nil {
+		return out, fmt.Errorf("actions_unavailable")
+	}
+	nodeID := parseUUIDFromAny(args["path_node_id"])
+	if nodeID == uuid.Nil {
+		nodeID = scope.ActivePathNodeID
+	}
+	if nodeID == uuid.Nil {
+		return out, fmt.Errorf("missing_path_node_id")
+	}
+	kind, _ := args["kind"].(string)
+	count, _ := args["count"].(int)
+	drill, err := deps.Actions.GeneratePathNodeDrill(ctx, learningmod.GeneratePathNodeDrillInput{
+		UserID:     scope.UserID,
+		PathNodeID: nodeID,
+		Kind:       kind,
+		Count:      count,
+	})
+	if err != nil {
+		return out, err
+	}
+	label := "quiz"
+	if kind == "flashcards" {
+		label = "flashcards set"
+	}
+	out.Text = fmt.Sprintf("Here’s a %s for this lesson.", label)
+	out.Result = map[string]any{
+		"path_node_id": nodeID.String(),
+		"kind":         kind,
+		"drill":        json.RawMessage(drill),
+	}
+	return out, nil
+}
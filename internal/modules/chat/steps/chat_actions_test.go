@@ -0,0 +1,253 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	chatrepo "github.com/yungbote/neurobridge-backend/internal/data/repos/chat"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	learningmod "github.com/yungbote/neurobridge-backend/internal/modules/learning"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// memToolLedger mirrors ChatToolExecutionRepo's claim semantics in memory.
+type memToolLedger struct {
+	rows map[string]*types.ChatToolExecution
+}
+
+func (l *memToolLedger) key(messageID uuid.UUID, tool, hash string) string {
+	return messageID.String() + "|" + tool + "|" + hash
+}
+
+func (l *memToolLedger) Claim(dbc dbctx.Context, row *types.ChatToolExecution) (*types.ChatToolExecution, bool, error) {
+	if l.rows == nil {
+		l.rows = map[string]*types.ChatToolExecution{}
+	}
+	k := l.key(row.MessageID, row.ToolName, row.ArgsHash)
+	if existing, ok := l.rows[k]; ok {
+		if existing.Status == chatrepo.ChatToolExecutionFailed {
+			existing.Status = chatrepo.ChatToolExecutionRunning
+			return existing, true, nil
+		}
+		return existing, false, nil
+	}
+	cp := *row
+	cp.ID = uuid.New()
+	cp.Status = chatrepo.ChatToolExecutionRunning
+	l.rows[k] = &cp
+	return &cp, true, nil
+}
+
+func (l *memToolLedger) Finish(dbc dbctx.Context, id uuid.UUID, status string, result datatypes.JSON, errMsg string) error {
+	for _, r := range l.rows {
+		if r.ID == id {
+			r.Status, r.Result, r.Error = status, result, errMsg
+		}
+	}
+	return nil
+}
+
+func (l *memToolLedger) ListByMessageID(dbc dbctx.Context, userID uuid.UUID, messageID uuid.UUID) ([]*types.ChatToolExecution, error) {
+	return nil, nil
+}
+
+type countingPathActions struct {
+	markCalls  int
+	drillCalls int
+	failMark   bool
+	doc        *types.LearningNodeDoc
+}
+
+func (a *countingPathActions) MarkConceptKnown(ctx context.Context, in learningmod.MarkConceptKnownInput) (learningmod.MarkConceptKnownOutput, error) {
+	a.markCalls++
+	if a.failMark {
+		return learningmod.MarkConceptKnownOutput{}, apierr.New(http.StatusNotFound, "concept_not_found", nil)
+	}
+	return learningmod.MarkConceptKnownOutput{ConceptID: uuid.New(), ConceptKey: in.ConceptKey, Mastery: 0.9, Confidence: 0.65}, nil
+}
+
+func (a *countingPathActions) GeneratePathNodeDrill(ctx context.Context, in learningmod.GeneratePathNodeDrillInput) (json.RawMessage, error) {
+	a.drillCalls++
+	return json.RawMessage(`{"kind":"` + in.Kind + `","items":[]}`), nil
+}
+
+func (a *countingPathActions) LoadPatchableNodeDoc(ctx context.Context, userID uuid.UUID, nodeID uuid.UUID, track string) (*types.PathNode, *types.LearningNodeDoc, error) {
+	return &types.PathNode{ID: nodeID}, a.doc, nil
+}
+
+type countingJobService struct {
+	services.JobService
+	enqueued []string
+}
+
+func (s *countingJobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error) {
+	s.enqueued = append(s.enqueued, jobType)
+	return &types.JobRun{ID: uuid.New(), JobType: jobType, Status: "queued"}, nil
+}
+
+func TestChatActionArgumentValidation(t *testing.T) {
+	nodeID := uuid.New().String()
+	cases := []struct {
+		tool    string
+		args    map[string]any
+		wantErr string
+	}{
+		{chatActionConfirmIntake, map[string]any{}, ""},
+		{chatActionConfirmIntake, map[string]any{"paths": "all"}, "unexpected_argument:paths"},
+		{chatActionMarkConceptKnown, map[string]any{"concept_key": " Recursion "}, ""},
+		{chatActionMarkConceptKnown, map[string]any{}, "missing_argument:concept_key"},
+		{chatActionMarkConceptKnown, map[string]any{"concept_key": 7}, "invalid_argument:concept_key"},
+		{chatActionMarkConceptKnown, map[string]any{"concept_key": strings.Repeat("k", chatActionMaxKey+1)}, "invalid_argument:concept_key"},
+		{chatActionDocPatchPreview, map[string]any{"instruction": "shorter"}, ""},
+		{chatActionDocPatchPreview, map[string]any{"instruction": "  "}, "missing_argument:instruction"},
+		{chatActionDocPatchPreview, map[string]any{"instruction": "shorter", "path_node_id": "node-1"}, "invalid_argument:path_node_id"},
+		{chatActionDocPatchPreview, map[string]any{"instruction": "shorter", "action": "regen_media"}, "unexpected_argument:action"},
+		{chatActionCreateDrill, map[string]any{"kind": "Quiz", "count": float64(5), "path_node_id": nodeID}, ""},
+		{chatActionCreateDrill, map[string]any{"kind": "essay"}, "invalid_argument:kind"},
+		{chatActionCreateDrill, map[string]any{"kind": "quiz", "count": float64(2.5)}, "invalid_argument:count"},
+		{chatActionCreateDrill, map[string]any{"kind": "quiz", "count": float64(chatDrillMaxCount + 1)}, "invalid_argument:count"},
+		{chatActionCreateDrill, map[string]any{"count": float64(3)}, "missing_argument:kind"},
+	}
+	for _, tc := range cases {
+		action, ok := chatActionByName(tc.tool)
+		if !ok {
+			t.Fatalf("%s not registered", tc.tool)
+		}
+		_, err := action.validate(tc.args)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.wantErr {
+			t.Errorf("%s(%v): err = %q, want %q", tc.tool, tc.args, got, tc.wantErr)
+		}
+	}
+
+	args, _ := validateCreateDrillArgs(map[string]any{"kind": "Quiz", "count": float64(5)})
+	if args["kind"] != "quiz" || args["count"] != 5 {
+		t.Fatalf("normalized drill args = %v", args)
+	}
+}
+
+func TestExposedChatActions(t *testing.T) {
+	pathID := uuid.New()
+	names := func(scope chatActionScope) string {
+		out := []string{}
+		for _, a := range exposedChatActions(scope) {
+			out = append(out, a.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := names(chatActionScope{Thread: &types.ChatThread{}}); got != "" {
+		t.Fatalf("pathless thread exposed %q", got)
+	}
+	if got := names(chatActionScope{Thread: &types.ChatThread{PathID: &pathID}}); got != "mark_concept_known,create_drill" {
+		t.Fatalf("path thread exposed %q", got)
+	}
+	if got := names(chatActionScope{Thread: &types.ChatThread{PathID: &pathID}, Mode: "edit"}); got != "mark_concept_known,enqueue_doc_patch_preview,create_drill" {
+		t.Fatalf("edit turn exposed %q", got)
+	}
+	if got := names(chatActionScope{Thread: &types.ChatThread{}, IntakePending: true}); got != "confirm_intake" {
+		t.Fatalf("intake-pending thread exposed %q", got)
+	}
+
+	rec := runChatAction(context.Background(), RespondDeps{}, chatActionScope{Thread: &types.ChatThread{}}, chatActionConfirmIntake, map[string]any{})
+	if rec.Status != chatActionStatusRejected || rec.Error != "not_exposed" {
+		t.Fatalf("unexposed action record = %+v", rec)
+	}
+}
+
+func TestRunChatActionIdempotentPerMessage(t *testing.T) {
+	pathID, buildID, nodeID := uuid.New(), uuid.New(), uuid.New()
+	thread := &types.ChatThread{ID: uuid.New(), PathID: &pathID, JobID: &buildID}
+	doc := &types.LearningNodeDoc{DocJSON: datatypes.JSON(`{"schema_version":1,"blocks":[{"id":"p1","type":"paragraph","md":"x"}]}`)}
+
+	cases := []struct {
+		tool  string
+		scope chatActionScope
+		args  map[string]any
+		calls func(a *countingPathActions, j *countingJobService) int
+	}{
+		{
+			tool:  chatActionConfirmIntake,
+			scope: chatActionScope{IntakePending: true},
+			args:  map[string]any{},
+			calls: func(a *countingPathActions, j *countingJobService) int { return len(j.enqueued) },
+		},
+		{
+			tool:  chatActionMarkConceptKnown,
+			args:  map[string]any{"concept_key": "recursion"},
+			calls: func(a *countingPathActions, j *countingJobService) int { return a.markCalls },
+		},
+		{
+			tool:  chatActionDocPatchPreview,
+			scope: chatActionScope{Mode: "edit", EditTarget: &EditTarget{PathNodeID: nodeID.String(), BlockID: "p1", BlockIndex: 0}},
+			args:  map[string]any{"instruction": "shorter"},
+			calls: func(a *countingPathActions, j *countingJobService) int { return len(j.enqueued) },
+		},
+		{
+			tool:  chatActionCreateDrill,
+			scope: chatActionScope{ActivePathNodeID: nodeID},
+			args:  map[string]any{"kind": "flashcards"},
+			calls: func(a *countingPathActions, j *countingJobService) int { return a.drillCalls },
+		},
+	}
+	for _, tc := range cases {
+		actions := &countingPathActions{doc: doc}
+		jobs := &countingJobService{}
+		deps := RespondDeps{ToolExecs: &memToolLedger{}, Actions: actions, Jobs: jobs}
+		scope := tc.scope
+		scope.UserID, scope.Thread, scope.UserMessageID, scope.AssistantMessageID = uuid.New(), thread, uuid.New(), uuid.New()
+
+		first := runChatAction(context.Background(), deps, scope, tc.tool, tc.args)
+		if first.Status != chatActionStatusOK || first.Replayed || first.Message == "" {
+			t.Fatalf("%s first run = %+v", tc.tool, first)
+		}
+		again := runChatAction(context.Background(), deps, scope, tc.tool, tc.args)
+		if !again.Replayed || again.Status != chatActionStatusOK || again.Message != first.Message {
+			t.Fatalf("%s re-delivery = %+v, want replay of %+v", tc.tool, again, first)
+		}
+		if n := tc.calls(actions, jobs); n != 1 {
+			t.Fatalf("%s executed %d times for one message", tc.tool, n)
+		}
+
+		scope.AssistantMessageID = uuid.New()
+		if rec := runChatAction(context.Background(), deps, scope, tc.tool, tc.args); rec.Replayed || rec.Status != chatActionStatusOK {
+			t.Fatalf("%s on a new message = %+v", tc.tool, rec)
+		}
+		if n := tc.calls(actions, jobs); n != 2 {
+			t.Fatalf("%s executed %d times for two messages", tc.tool, n)
+		}
+	}
+}
+
+func TestRunChatActionRetriesFailedExecution(t *testing.T) {
+	pathID := uuid.New()
+	actions := &countingPathActions{failMark: true}
+	deps := RespondDeps{ToolExecs: &memToolLedger{}, Actions: actions}
+	scope := chatActionScope{UserID: uuid.New(), Thread: &types.ChatThread{ID: uuid.New(), PathID: &pathID}, AssistantMessageID: uuid.New()}
+	args := map[string]any{"concept_key": "recursion"}
+
+	rec := runChatAction(context.Background(), deps, scope, chatActionMarkConceptKnown, args)
+	if rec.Status != chatActionStatusFailed || rec.Error != "concept_not_found" {
+		t.Fatalf("failed run = %+v", rec)
+	}
+	actions.failMark = false
+	rec = runChatAction(context.Background(), deps, scope, chatActionMarkConceptKnown, args)
+	if rec.Status != chatActionStatusOK || rec.Replayed || actions.markCalls != 2 {
+		t.Fatalf("retry after failure = %+v (calls %d)", rec, actions.markCalls)
+	}
+
+	if rec := runChatAction(context.Background(), RespondDeps{Actions: actions}, scope, chatActionMarkConceptKnown, args); rec.Status != chatActionStatusFailed || rec.Error != "tool_ledger_unavailable" {
+		t.Fatalf("run without ledger = %+v", rec)
+	}
+}
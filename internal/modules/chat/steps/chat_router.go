@@ -9,6 +9,7 @@ import (
     "github.com/google/uuid"
 
     types "github.com/yungbote/neurobridge-backend/internal/domain"
+    "github.com/yungbote/neurobridge-backend/internal/platform/openai"
 )
 
 type chatRouteDecision struct {
@@ -31,7 +32,9 @@ func resolveChatFastModel() string {
     return strings.TrimSpace(os.Getenv("CHAT_FAST_MODEL"))
 }
 
-func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatThread, userText string, recent string) (chatRouteDecision, error) {
+// routeChatMessage picks the route for a turn. tools is the full list the model may call:
+// job tools plus whichever chat actions the turn exposes (see chatRouteTools).
+func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatThread, userText string, recent string, tools []chatToolSpec) (chatRouteDecision, error) {
     out := chatRouteDecision{Route: "product"}
     if deps.AI == nil || thread == nil {
         return out, nil
@@ -41,7 +44,6 @@ func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatT
         return out, nil
     }
 
-    tools := allowedChatTools()
     toolJSON := "[]"
     if b, err := json.Marshal(tools); err == nil {
         toolJSON = string(b)
@@ -58,10 +60,11 @@ func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatT
         "OUTPUT: Return ONLY JSON matching the schema.",
         "DECISION: If unsure, choose product.",
         "ROUTES:",
-        "- tool: user explicitly asks to trigger a pipeline (build, reindex, rebuild)",
+        "- tool: user explicitly asks to trigger a pipeline (build, reindex, rebuild) or asks for one of the allowed actions",
         "- product: questions about learning content, paths, materials, progress, or the app",
         "- smalltalk: off-topic or casual chat unrelated to learning",
         "If route=tool, include at most 1 tool call from the allowed list.",
+        "Tools with an arguments_schema take arguments matching it; never invent ids the user did not give.",
     }, "\n"))
 
     user := strings.TrimSpace(strings.Join([]string{
@@ -91,7 +94,7 @@ func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatT
                     "type":                 "object",
                     "additionalProperties": false,
                     "properties": map[string]any{
-                        "tool_name": map[string]any{"type": "string", "enum": toolNamesForSchema()},
+                        "tool_name": map[string]any{"type": "string", "enum": toolNamesForSchema(tools)},
                         "arguments": map[string]any{"type": "object"},
                         "confidence": map[string]any{"type": "number", "minimum": 0, "maximum": 1},
                     },
@@ -102,12 +105,14 @@ func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatT
         "required": []any{"route", "respond_fast", "tool_calls"},
     }
 
-    obj, err := deps.AI.GenerateJSON(ctx, system, user, "chat_route_v1", schema)
-    if err != nil {
+    var dec chatRouteDecision
+    if err := openai.GenerateJSONValidated(ctx, deps.AI, system, user, "chat_route_v1", schema, &dec); err != nil {
         return out, err
     }
-    b, _ := json.Marshal(obj)
-    _ = json.Unmarshal(b, &out)
+    openai.ReportJSONUnusable(ctx, deps.Log, deps.AI, "chat_route_v1", chatRouteUnusable(dec, tools), map[string]any{
+        "thread_id": thread.ID.String(),
+    })
+    out = dec
     out.Route = strings.TrimSpace(strings.ToLower(out.Route))
     if out.Route == "" {
         out.Route = "product"
@@ -115,8 +120,34 @@ func routeChatMessage(ctx context.Context, deps RespondDeps, thread *types.ChatT
     return out, nil
 }
 
-func toolNamesForSchema() []any {
-    tools := allowedChatTools()
+// chatRouteUnusable flags route decisions the schema accepts but respond cannot act on: a tool
+// route without a tool call, or an action call missing an argument its schema requires. Job
+// tool arguments are left out because the executor resolves them from the thread.
+func chatRouteUnusable(dec chatRouteDecision, tools []chatToolSpec) []openai.JSONUnusable {
+    if !strings.EqualFold(strings.TrimSpace(dec.Route), "tool") {
+        return nil
+    }
+    if len(dec.ToolCalls) == 0 {
+        return []openai.JSONUnusable{{Kind: openai.JSONUnusableEmptyArray, Field: "tool_calls"}}
+    }
+    byName := make(map[string]chatToolSpec, len(tools))
+    for _, t := range tools {
+        byName[t.Name] = t
+    }
+    var out []openai.JSONUnusable
+    for _, call := range dec.ToolCalls {
+        required, _ := byName[call.ToolName].Arguments["required"].([]any)
+        for _, r := range required {
+            arg, _ := r.(string)
+            if v, ok := call.Arguments[arg]; arg != "" && (!ok || v == nil) {
+                out = append(out, openai.JSONUnusable{Kind: openai.JSONUnusableMissingKey, Field: "arguments." + arg})
+            }
+        }
+    }
+    return out
+}
+
+func toolNamesForSchema(tools []chatToolSpec) []any {
     out := make([]any, 0, len(tools))
     for _, t := range tools {
         out = append(out, t.Name)
@@ -129,6 +160,7 @@ type chatToolSpec struct {
     Description string `json:"description"`
     Required    []string `json:"required_args"`
     Optional    []string `json:"optional_args"`
+    Arguments   map[string]any `json:"arguments_schema,omitempty"`
 }
 
 func allowedChatTools() []chatToolSpec {
@@ -160,6 +192,25 @@ func allowedChatTools() []chatToolSpec {
     }
 }
 
+// chatRouteTools lists what the router may call: the job tools (unless withheld, e.g. while a
+// waitpoint is pending) followed by the exposed chat actions with their argument schemas.
+func chatRouteTools(actions []chatAction, includeJobTools bool) []chatToolSpec {
+    out := []chatToolSpec{}
+    if includeJobTools {
+        out = append(out, allowedChatTools()...)
+    }
+    for _, a := range actions {
+        out = append(out, chatToolSpec{
+            Name:        a.Name,
+            Description: a.Description,
+            Required:    []string{},
+            Optional:    []string{},
+            Arguments:   a.Parameters,
+        })
+    }
+    return out
+}
+
 func defaultString(v string, fallback string) string {
     if strings.TrimSpace(v) == "" {
         return fallback
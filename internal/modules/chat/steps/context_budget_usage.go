@@ -0,0 +1,87 @@
+package steps
+
+import (
+	"math"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// laneBudgetUsage accumulates per-lane token accounting while a context plan is trimmed.
+// A lane may be charged several times (the path lane renders overview, concepts and
+// materials docs against separate budgets); its parts are summed.
+type laneBudgetUsage struct {
+	order []string
+	lanes map[string]*types.ContextBudgetLaneUsage
+}
+
+func newLaneBudgetUsage() *laneBudgetUsage {
+	return &laneBudgetUsage{lanes: map[string]*types.ContextBudgetLaneUsage{}}
+}
+
+// add charges one part of a lane: its budget, the tokens it wanted, and the tokens kept.
+func (u *laneBudgetUsage) add(lane string, allocated, demand, consumed int) {
+	row := u.lanes[lane]
+	if row == nil {
+		row = &types.ContextBudgetLaneUsage{Lane: lane}
+		u.lanes[lane] = row
+		u.order = append(u.order, lane)
+	}
+	if allocated < 0 {
+		allocated = 0
+	}
+	row.Allocated += allocated
+	row.Consumed += consumed
+	if demand > consumed {
+		row.Truncated += demand - consumed
+	}
+	row.Dropped = row.Consumed == 0 && row.Truncated > 0
+}
+
+// addText charges a lane whose text was trimmed from before to after.
+func (u *laneBudgetUsage) addText(lane string, allocated int, before, after string) {
+	u.add(lane, allocated, estimateTokens(before), estimateTokens(after))
+}
+
+func (u *laneBudgetUsage) rows() []types.ContextBudgetLaneUsage {
+	out := make([]types.ContextBudgetLaneUsage, 0, len(u.order))
+	for _, lane := range u.order {
+		out = append(out, *u.lanes[lane])
+	}
+	return out
+}
+
+func (u *laneBudgetUsage) anyDropped() bool {
+	for _, row := range u.lanes {
+		if row.Dropped {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *laneBudgetUsage) trace(maxContextTokens int) map[string]any {
+	return map[string]any{
+		"max_context_tokens": maxContextTokens,
+		"lanes":              u.rows(),
+		"any_dropped":        u.anyDropped(),
+	}
+}
+
+// renderedDocTokens is the token demand of docs rendered without a budget.
+func renderedDocTokens(docs []*types.ChatDoc) int {
+	if len(docs) == 0 {
+		return 0
+	}
+	return estimateTokens(renderDocsBudgeted(docs, math.MaxInt))
+}
+
+// summaryDemand is the thread summary's token demand: the full root when only recent nodes fit.
+func summaryDemand(s repos.BudgetedSummary) int {
+	if s.Mode == "recent" {
+		if root := estimateTokens(s.RootText); root > s.Tokens {
+			return root
+		}
+	}
+	return s.Tokens
+}
@@ -0,0 +1,40 @@
+package steps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+)
+
+func TestLaneBudgetUsage(t *testing.T) {
+	u := newLaneBudgetUsage()
+	long := strings.Repeat("x", 400) // 100 tokens
+	u.add("materials", 40, 100, 40)
+	u.add("path", 50, 30, 30)
+	u.add("path", 20, 60, 20)
+	u.addText("web", 10, long, "")
+	u.add("summary", 80, summaryDemand(repos.BudgetedSummary{Mode: "recent", RootText: long, Tokens: 70}), 70)
+
+	rows := u.rows()
+	if len(rows) != 4 || rows[0].Lane != "materials" || rows[1].Lane != "path" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if m := rows[0]; m.Allocated != 40 || m.Consumed != 40 || m.Truncated != 60 || m.Dropped || !m.Saturated() {
+		t.Fatalf("materials = %+v", m)
+	}
+	// Parts of one lane are summed; the path lane only truncated in its second part.
+	if p := rows[1]; p.Allocated != 70 || p.Consumed != 50 || p.Truncated != 40 || p.Dropped {
+		t.Fatalf("path = %+v", p)
+	}
+	if w := rows[2]; !w.Dropped || w.Truncated != 100 {
+		t.Fatalf("web = %+v", w)
+	}
+	// Under the ceiling, but the full root summary was cut: that still counts as pressure.
+	if s := rows[3]; s.Truncated != 30 || !s.Saturated() {
+		t.Fatalf("summary = %+v", s)
+	}
+	if !u.anyDropped() {
+		t.Fatalf("expected a dropped lane")
+	}
+}
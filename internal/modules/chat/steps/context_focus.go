@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// focusSection heads the instructions describing the learner's active path focus.
+const focusSection = "## Current focus"
+
+// loadChatFocus returns the user's active focus on the thread's path, or nil. Focus only
+// biases the plan, so a failed read is logged and ignored.
+func loadChatFocus(dbc dbctx.Context, deps ContextPlanDeps, userID uuid.UUID, thread *types.ChatThread) *types.PathFocus {
+	if deps.Focus == nil || thread == nil || thread.PathID == nil || *thread.PathID == uuid.Nil {
+		return nil
+	}
+	focus, err := deps.Focus.GetActive(dbc, userID, *thread.PathID, time.Now().UTC())
+	if err != nil {
+		if deps.Log != nil {
+			deps.Log.WarnThrottled("chat_context_plan.focus", time.Minute, "load path focus failed", "error", err, "thread_id", thread.ID.String())
+		}
+		return nil
+	}
+	return focus
+}
+
+// focusQuery appends the focus goal terms and concept names the query doesn't already mention,
+// so retrieval and materials search lean toward the goal without losing the question.
+func focusQuery(q string, focus *types.PathFocus) string {
+	if focus == nil {
+		return q
+	}
+	lower := strings.ToLower(q)
+	extra := []string{}
+	seen := map[string]bool{}
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		if term == "" || seen[term] || strings.Contains(lower, term) {
+			return
+		}
+		seen[term] = true
+		extra = append(extra, term)
+	}
+	for _, t := range focus.GoalTerms() {
+		add(t)
+	}
+	for _, k := range focus.ConceptKeyList() {
+		add(strings.ReplaceAll(k, "_", " "))
+	}
+	if len(extra) == 0 {
+		return q
+	}
+	return strings.TrimSpace(q + " " + strings.Join(extra, " "))
+}
+
+// focusInstructions tells the model what the learner is working toward and until when.
+func focusInstructions(focus *types.PathFocus) string {
+	if focus == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "The learner declared a study goal for this path: %q (until %s).\n", focus.GoalText, focus.Until.UTC().Format("2006-01-02 15:04 MST"))
+	if keys := focus.ConceptKeyList(); len(keys) > 0 {
+		fmt.Fprintf(&b, "Focus concepts: %s.\n", strings.Join(keys, ", "))
+	}
+	b.WriteString("Answer the question asked, but prefer examples, connections and follow-ups that serve this goal. " +
+		"If the question is unrelated to the goal, answer it normally and don't steer the learner back.")
+	return b.String()
+}
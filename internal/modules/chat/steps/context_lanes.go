@@ -0,0 +1,154 @@
+package steps
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// contextLaneNames are the lanes classifyContextRoute and the LLM router can enable.
+var contextLaneNames = []string{"viewport", "unit", "path", "concept", "user", "retrieve", "materials", "graph", "web"}
+
+// ParseContextLanes normalizes client-supplied lane names (case-insensitive, deduped, sorted).
+// It reports false if any name is not a known lane.
+func ParseContextLanes(names []string) ([]string, bool) {
+	known := map[string]bool{}
+	for _, n := range contextLaneNames {
+		known[n] = true
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if n == "" || seen[n] {
+			continue
+		}
+		if !known[n] {
+			return nil, false
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out, true
+}
+
+// disabledLanesFromMessage reads the lanes the client disabled for the user message.
+func disabledLanesFromMessage(msg *types.ChatMessage) []string {
+	if msg == nil || len(msg.Metadata) == 0 || string(msg.Metadata) == "null" {
+		return nil
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(msg.Metadata, &meta); err != nil || meta == nil {
+		return nil
+	}
+	raw, _ := meta["disabled_lanes"].([]any)
+	names := make([]string, 0, len(raw))
+	for _, v := range raw {
+		names = append(names, stringFromAnyCtx(v))
+	}
+	return newContextLaneMask(names).names()
+}
+
+// contextLaneMask is the set of lanes a client hard-disabled for a turn.
+type contextLaneMask map[string]bool
+
+func newContextLaneMask(disabled []string) contextLaneMask {
+	m := contextLaneMask{}
+	for _, n := range disabled {
+		// Unknown names are dropped rather than failing the turn; the API rejects them.
+		if lanes, ok := ParseContextLanes([]string{n}); ok {
+			for _, l := range lanes {
+				m[l] = true
+			}
+		}
+	}
+	return m
+}
+
+func (m contextLaneMask) names() []string {
+	out := make([]string, 0, len(m))
+	for n := range m {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// apply turns the disabled lanes off in route, whatever the router decided.
+func (m contextLaneMask) apply(route contextRoute) {
+	for name := range m {
+		if ln, ok := route.Lanes[name]; ok && ln.Enabled {
+			ln.Enabled = false
+			ln.Reason = "disabled by client"
+			route.Lanes[name] = ln
+		}
+	}
+}
+
+// contextLaneFlags are the context sections a plan includes, after routing and overrides.
+type contextLaneFlags struct {
+	Unit      bool
+	Path      bool
+	Concept   bool
+	User      bool
+	Retrieval bool
+	Materials bool
+	Graph     bool
+	Web       bool
+}
+
+// restrict switches off every section fed by a disabled lane. It runs after the router hints
+// and heuristics (LLM retrieval scopes, verbatim quotes, session viewport) that can turn
+// sections back on. Unit context comes from the viewport, so either lane disables it.
+func (f contextLaneFlags) restrict(mask contextLaneMask) contextLaneFlags {
+	if mask["unit"] || mask["viewport"] {
+		f.Unit = false
+	}
+	if mask["path"] {
+		f.Path = false
+	}
+	if mask["concept"] {
+		f.Concept = false
+	}
+	if mask["user"] {
+		f.User = false
+	}
+	if mask["retrieve"] {
+		f.Retrieval = false
+		f.Materials = false
+	}
+	if mask["materials"] {
+		f.Materials = false
+	}
+	if mask["graph"] {
+		f.Graph = false
+	}
+	if mask["web"] {
+		f.Web = false
+	}
+	return f
+}
+
+// lanes lists the lanes the plan effectively runs, for the trace.
+func (f contextLaneFlags) lanes(route contextRoute) []string {
+	out := make([]string, 0, len(contextLaneNames))
+	add := func(name string, on bool) {
+		if on {
+			out = append(out, name)
+		}
+	}
+	add("viewport", f.Unit && route.Enabled("viewport"))
+	add("unit", f.Unit)
+	add("path", f.Path)
+	add("concept", f.Concept)
+	add("user", f.User)
+	add("retrieve", f.Retrieval)
+	add("materials", f.Materials)
+	add("graph", f.Graph)
+	add("web", f.Web)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,46 @@
+package steps
+
+import (
+	"reflect"
+	"testing"
+
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestContextLaneMask(t *testing.T) {
+	if _, ok := ParseContextLanes([]string{"materials", "pinecone"}); ok {
+		t.Fatalf("unknown lane accepted")
+	}
+	lanes, ok := ParseContextLanes([]string{" Graph", "materials", "graph", ""})
+	if !ok || !reflect.DeepEqual(lanes, []string{"graph", "materials"}) {
+		t.Fatalf("lanes = %v, %v", lanes, ok)
+	}
+
+	msg := &types.ChatMessage{Metadata: datatypes.JSON(`{"verbosity":"terse","disabled_lanes":["graph","materials","bogus"]}`)}
+	mask := newContextLaneMask(disabledLanesFromMessage(msg))
+	if got := mask.names(); !reflect.DeepEqual(got, []string{"graph", "materials"}) {
+		t.Fatalf("mask = %v", got)
+	}
+
+	// The router turns the lanes on; the client-disabled ones stay off.
+	route := classifyContextRoute("find the prerequisite concepts in the slides")
+	if !route.Enabled("materials") || !route.Enabled("graph") {
+		t.Fatalf("fixture query should route materials and graph: %+v", route.Lanes)
+	}
+	mask.apply(route)
+	if route.Enabled("materials") || route.Enabled("graph") || !route.Enabled("retrieve") || !route.Enabled("concept") {
+		t.Fatalf("route after mask = %+v", route.Lanes)
+	}
+
+	// Overrides after routing (e.g. a verbatim-quote request forcing materials) are restricted too.
+	flags := contextLaneFlags{Unit: true, Path: true, Concept: true, Retrieval: true, Materials: true, Graph: true}.restrict(mask)
+	if got, want := flags.lanes(route), []string{"concept", "path", "retrieve", "unit"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("effective lanes = %v, want %v", got, want)
+	}
+	flags = contextLaneFlags{Retrieval: true, Materials: true}.restrict(newContextLaneMask([]string{"retrieve"}))
+	if flags.Retrieval || flags.Materials {
+		t.Fatalf("materials without retrieval: %+v", flags)
+	}
+}
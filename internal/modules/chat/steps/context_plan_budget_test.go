@@ -0,0 +1,67 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbstats"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// budgetAI embeds queries but fails every generation, so routing and contextualization fall
+// back to their heuristic paths.
+type budgetAI struct {
+	openai.Client
+}
+
+func (budgetAI) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	out := make([][]float32, len(inputs))
+	for i := range inputs {
+		out[i] = []float32{0.1, 0.2, 0.3}
+	}
+	return out, nil
+}
+
+func (budgetAI) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
+	return nil, errors.New("generation disabled in budget test")
+}
+
+// Query budget for a plan on a thread without a path and without a vector store: hot window,
+// latest session, thread summary, dense SQL + lexical per retrieval scope (thread, user), and
+// the chat_message lexical fallback. Raise it only with a reason.
+const buildContextPlanQueryBudget = 8
+
+func TestBuildContextPlanQueryBudget(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+
+	budgets := dbstats.ExpectQueryBudgets(t, map[string]int{"BuildContextPlan": buildContextPlanQueryBudget})
+
+	userID := uuid.New()
+	thread := &types.ChatThread{ID: uuid.New(), UserID: userID}
+	_, err := BuildContextPlan(budgets.Context(context.Background()), ContextPlanDeps{
+		DB:        tx,
+		AI:        budgetAI{},
+		Docs:      repos.NewChatDocRepo(tx, log),
+		Messages:  repos.NewChatMessageRepo(tx, log),
+		Summaries: repos.NewChatSummaryNodeRepo(tx, log),
+		Sessions:  repos.NewUserSessionStateRepo(tx, log),
+	}, ContextPlanInput{
+		UserID:   userID,
+		Thread:   thread,
+		UserText: "How does recursion unwind after the base case?",
+	})
+	if err != nil {
+		t.Fatalf("BuildContextPlan: %v", err)
+	}
+	if budgets.Tally().Count("BuildContextPlan") == 0 {
+		t.Fatal("expected queries attributed to BuildContextPlan")
+	}
+}
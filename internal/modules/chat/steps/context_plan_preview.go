@@ -0,0 +1,189 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+var (
+	ErrPreviewThreadNotFound  = errors.New("thread not found")
+	ErrPreviewMessageNotFound = errors.New("message not found")
+	ErrPreviewEmptyText       = errors.New("empty user text")
+)
+
+// ContextPlanPreviewer runs the same context assembly a chat_respond turn does, stopping before
+// generation, so a bad answer can be traced back to the instructions and evidence it was given.
+type ContextPlanPreviewer struct {
+	Deps    ContextPlanDeps
+	Threads repos.ChatThreadRepo
+}
+
+type ContextPlanPreviewInput struct {
+	UserID   uuid.UUID
+	ThreadID uuid.UUID
+	// MessageID selects the user message to plan for; Text overrides its content (or stands in
+	// for it when MessageID is empty) to try a different phrasing against the same thread.
+	MessageID uuid.UUID
+	Text      string
+	// DisabledLanes, when set, replaces the lanes the message was sent with disabled.
+	DisabledLanes []string
+}
+
+type ContextPlanPreviewDoc struct {
+	ID         uuid.UUID  `json:"id"`
+	DocType    string     `json:"doc_type"`
+	Scope      string     `json:"scope"`
+	ScopeID    *uuid.UUID `json:"scope_id,omitempty"`
+	SourceID   *uuid.UUID `json:"source_id,omitempty"`
+	SourceSeq  *int64     `json:"source_seq,omitempty"`
+	ChunkIndex int        `json:"chunk_index"`
+	Text       string     `json:"text"`
+}
+
+type ContextPlanPreviewEvidence struct {
+	ID    string         `json:"id"`
+	Type  string         `json:"type"`
+	Title string         `json:"title,omitempty"`
+	Text  string         `json:"text"`
+	Meta  map[string]any `json:"meta,omitempty"`
+}
+
+type ContextPlanPreview struct {
+	ThreadID            uuid.UUID                    `json:"thread_id"`
+	MessageID           *uuid.UUID                   `json:"message_id,omitempty"`
+	UserText            string                       `json:"user_text"`
+	Mode                string                       `json:"mode"`
+	RetrievalMode       string                       `json:"retrieval_mode"`
+	Instructions        string                       `json:"instructions"`
+	UserPayload         string                       `json:"user_payload"`
+	UsedDocs            []ContextPlanPreviewDoc      `json:"used_docs"`
+	EvidenceSources     []ContextPlanPreviewEvidence `json:"evidence_sources"`
+	EvidenceTokenBudget int                          `json:"evidence_token_budget"`
+	EditTarget          *EditTarget                  `json:"edit_target,omitempty"`
+	Trace               map[string]any               `json:"trace"`
+}
+
+// Preview builds the full context plan for a thread owned by in.UserID. Thread state is read but
+// never created, and no turn, message or generation call is made.
+func (p ContextPlanPreviewer) Preview(ctx context.Context, in ContextPlanPreviewInput) (*ContextPlanPreview, error) {
+	deps := p.Deps
+	if deps.DB == nil || p.Threads == nil {
+		return nil, fmt.Errorf("chat context plan preview: missing deps")
+	}
+	if in.UserID == uuid.Nil || in.ThreadID == uuid.Nil {
+		return nil, fmt.Errorf("chat context plan preview: missing ids")
+	}
+	dbc := dbctx.Context{Ctx: ctx, Tx: deps.DB}
+
+	threads, err := p.Threads.GetByIDs(dbc, []uuid.UUID{in.ThreadID})
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 || threads[0] == nil || threads[0].UserID != in.UserID {
+		return nil, ErrPreviewThreadNotFound
+	}
+	thread := threads[0]
+
+	var userMsg *types.ChatMessage
+	text := strings.TrimSpace(in.Text)
+	if in.MessageID != uuid.Nil {
+		var msg types.ChatMessage
+		err := deps.DB.WithContext(ctx).
+			Model(&types.ChatMessage{}).
+			Where("id = ? AND thread_id = ? AND user_id = ? AND role = ?", in.MessageID, in.ThreadID, in.UserID, "user").
+			First(&msg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPreviewMessageNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		userMsg = &msg
+		if text == "" {
+			text = strings.TrimSpace(msg.Content)
+		}
+	}
+	if text == "" {
+		return nil, ErrPreviewEmptyText
+	}
+
+	var state *types.ChatThreadState
+	var row types.ChatThreadState
+	err = deps.DB.WithContext(ctx).Model(&types.ChatThreadState{}).Where("thread_id = ?", in.ThreadID).Limit(1).Find(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	if row.ThreadID != uuid.Nil {
+		state = &row
+	}
+
+	disabledLanes := in.DisabledLanes
+	if disabledLanes == nil {
+		disabledLanes = disabledLanesFromMessage(userMsg)
+	}
+	plan, err := ContextPlanner{Deps: deps}.BuildFull(ctx, ContextPlanInput{
+		UserID:        in.UserID,
+		Thread:        thread,
+		State:         state,
+		UserText:      text,
+		UserMsg:       userMsg,
+		Verbosity:     answerVerbosityFromMessage(userMsg),
+		DisabledLanes: disabledLanes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ContextPlanPreview{
+		ThreadID:            thread.ID,
+		UserText:            text,
+		Mode:                plan.Mode,
+		RetrievalMode:       plan.RetrievalMode,
+		Instructions:        plan.Instructions,
+		UserPayload:         plan.UserPayload,
+		UsedDocs:            make([]ContextPlanPreviewDoc, 0, len(plan.UsedDocs)),
+		EvidenceSources:     make([]ContextPlanPreviewEvidence, 0, len(plan.EvidenceSources)),
+		EvidenceTokenBudget: plan.EvidenceTokenBudget,
+		EditTarget:          plan.EditTarget,
+		Trace:               plan.Trace,
+	}
+	if userMsg != nil {
+		id := userMsg.ID
+		out.MessageID = &id
+	}
+	for _, d := range plan.UsedDocs {
+		if d == nil {
+			continue
+		}
+		// Embeddings are dropped: they dwarf the rest of the response and explain nothing.
+		out.UsedDocs = append(out.UsedDocs, ContextPlanPreviewDoc{
+			ID:         d.ID,
+			DocType:    d.DocType,
+			Scope:      d.Scope,
+			ScopeID:    d.ScopeID,
+			SourceID:   d.SourceID,
+			SourceSeq:  d.SourceSeq,
+			ChunkIndex: d.ChunkIndex,
+			Text:       d.Text,
+		})
+	}
+	for _, ev := range plan.EvidenceSources {
+		out.EvidenceSources = append(out.EvidenceSources, ContextPlanPreviewEvidence{
+			ID:    ev.ID,
+			Type:  ev.Type,
+			Title: ev.Title,
+			Text:  ev.Text,
+			Meta:  ev.Meta,
+		})
+	}
+	return out, nil
+}
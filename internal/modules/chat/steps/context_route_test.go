@@ -0,0 +1,122 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// routeFixtureAI replays raw router outputs in order, parsing them like the inference client.
+type routeFixtureAI struct {
+	openai.Client
+	outputs []string
+	calls   int
+}
+
+func (f *routeFixtureAI) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
+	if f.calls >= len(f.outputs) {
+		return nil, fmt.Errorf("no fixture for call %d", f.calls)
+	}
+	text := f.outputs[f.calls]
+	f.calls++
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse json output: %w", err)
+	}
+	return obj, nil
+}
+
+const (
+	contextRouteFixture = `{"mode":"explain",
+		"lanes":{"viewport":true,"unit":true,"path":false,"concept":false,"user":false,"retrieve":true,"materials":false,"graph":false,"web":false},
+		"unit":{"current_block":"full","include_visible":true,"include_lesson_index":false},
+		"retrieval":{"scope_thread":false,"scope_path":true,"scope_user":false,"scope_node":true,"materials_query":" limits "},
+		"confidence":0.9,"reason":"asks about the current block"}`
+	// Booleans as strings and no retrieval object: previously coerced or silently dropped.
+	contextRouteLooseFixture = `{"mode":"explain",
+		"lanes":{"viewport":"true","unit":true,"path":false,"concept":false,"user":false,"retrieve":true,"materials":false,"graph":false,"web":false},
+		"unit":{"current_block":"full","include_visible":true,"include_lesson_index":false},
+		"confidence":0.9,"reason":"asks about the current block"}`
+	contextRouteTruncatedFixture = `{"mode":"explain","lanes":{"viewport":true,"unit":tr`
+	// Schema-valid but selects nothing: every lane off.
+	contextRouteEmptyFixture = `{"mode":"explain",
+		"lanes":{"viewport":false,"unit":false,"path":false,"concept":false,"user":false,"retrieve":false,"materials":false,"graph":false,"web":false},
+		"unit":{"current_block":"none","include_visible":false,"include_lesson_index":false},
+		"retrieval":{"scope_thread":false,"scope_path":false,"scope_user":false,"scope_node":false,"materials_query":""},
+		"confidence":0.9,"reason":"nothing needed"}`
+)
+
+func TestRouteContextPlanLLMValidatedOutput(t *testing.T) {
+	pathID := uuid.New()
+	in := ContextPlanInput{
+		UserID:   uuid.New(),
+		Thread:   &types.ChatThread{ID: uuid.New(), PathID: &pathID},
+		UserText: "is this block right?",
+	}
+
+	ai := &routeFixtureAI{outputs: []string{contextRouteLooseFixture, contextRouteFixture}}
+	route, hints, trace, ok := routeContextPlanLLM(context.Background(), ContextPlanDeps{AI: ai}, in, "", nil, false)
+	if !ok || ai.calls != 2 {
+		t.Fatalf("ok=%v calls=%d trace=%v", ok, ai.calls, trace)
+	}
+	if !route.Lanes["viewport"].Enabled || route.Lanes["path"].Enabled {
+		t.Fatalf("lanes = %+v", route.Lanes)
+	}
+	if hints.UnitCurrent != "full" || !hints.RetrievalScopes.ScopeNode || !hints.RetrievalScopes.ScopePath || hints.MaterialsQuery != "limits" {
+		t.Fatalf("hints = %+v", hints)
+	}
+
+	ai = &routeFixtureAI{outputs: []string{contextRouteTruncatedFixture, contextRouteTruncatedFixture}}
+	_, _, trace, ok = routeContextPlanLLM(context.Background(), ContextPlanDeps{AI: ai}, in, "", nil, false)
+	if ok || trace["failure_class"] != string(openai.JSONFailureTruncation) {
+		t.Fatalf("ok=%v trace=%v, want truncation recorded", ok, trace)
+	}
+}
+
+func TestLLMRouteUnusableFindings(t *testing.T) {
+	pathID := uuid.New()
+	in := ContextPlanInput{Thread: &types.ChatThread{ID: uuid.New(), PathID: &pathID}, UserText: "hi"}
+	ai := &routeFixtureAI{outputs: []string{contextRouteEmptyFixture}}
+	_, _, trace, _ := routeContextPlanLLM(context.Background(), ContextPlanDeps{AI: ai}, in, "", nil, false)
+	if trace["unusable"] != 1 {
+		t.Fatalf("trace = %v, want the empty lane selection flagged", trace)
+	}
+	if got := contextRouteUnusable(contextRouteDecision{Lanes: map[string]bool{"retrieve": true}}); len(got) != 1 || got[0].Field != "retrieval" {
+		t.Fatalf("retrieve without scopes = %+v", got)
+	}
+
+	tools := chatRouteTools([]chatAction{{Name: "open_node", Parameters: objectSchema(map[string]any{}, "node_id")}}, true)
+	if got := chatRouteUnusable(chatRouteDecision{Route: "tool"}, tools); len(got) != 1 || got[0].Kind != openai.JSONUnusableEmptyArray {
+		t.Fatalf("tool route without calls = %+v", got)
+	}
+	dec := chatRouteDecision{Route: "tool", ToolCalls: []chatToolCall{
+		{ToolName: "open_node", Arguments: map[string]any{}},
+		{ToolName: "chat_rebuild", Arguments: map[string]any{}},
+	}}
+	if got := chatRouteUnusable(dec, tools); len(got) != 1 || got[0].Field != "arguments.node_id" {
+		t.Fatalf("missing action arg = %+v, want only the action's required arg", got)
+	}
+	if got := chatRouteUnusable(chatRouteDecision{Route: "product"}, tools); got != nil {
+		t.Fatalf("product route = %+v", got)
+	}
+}
+
+func TestResolveHotWindowConfig(t *testing.T) {
+	if got := resolveHotWindowConfig(ContextPlanInput{}); got != (hotWindowConfig{Fetch: 30, Hot: 18, RouterRecent: 6}) {
+		t.Fatalf("defaults = %+v", got)
+	}
+	t.Setenv("CHAT_HOT_WINDOW", "40")
+	t.Setenv("CHAT_ROUTER_RECENT", "bogus")
+	if got := resolveHotWindowConfig(ContextPlanInput{}); got != (hotWindowConfig{Fetch: 40, Hot: 40, RouterRecent: 6}) {
+		t.Fatalf("env = %+v", got)
+	}
+	if got := resolveHotWindowConfig(ContextPlanInput{HistoryFetch: 12, HotWindow: 8, RouterRecent: 3}); got != (hotWindowConfig{Fetch: 12, Hot: 8, RouterRecent: 3}) {
+		t.Fatalf("input overrides = %+v", got)
+	}
+}
@@ -0,0 +1,85 @@
+package steps
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// maxEmbeddingDimMismatchIDs caps how many mismatched doc IDs are kept for the trace.
+const maxEmbeddingDimMismatchIDs = 20
+
+// embeddingDimGuard compares stored ChatDoc vectors against the query embedding's dimension.
+// After an embedding model change, old vectors can't be scored against new query vectors (cosine
+// over different lengths is 0), which silently pushes every dense hit below the rerank gate.
+type embeddingDimGuard struct {
+	queryDim   int
+	storedDims map[int]int
+	seen       map[uuid.UUID]bool
+	compatible int
+	mismatched int
+	sampleIDs  []string
+	// denseSkipped is set once a scope's stored vectors were all incompatible, after which
+	// retrieval goes lexical-only.
+	denseSkipped bool
+}
+
+func newEmbeddingDimGuard(queryDim int) *embeddingDimGuard {
+	return &embeddingDimGuard{
+		queryDim:   queryDim,
+		storedDims: map[int]int{},
+		seen:       map[uuid.UUID]bool{},
+	}
+}
+
+// check reports whether emb can be compared with the query embedding. Empty vectors (never
+// embedded) are neither compatible nor mismatched.
+func (g *embeddingDimGuard) check(docID uuid.UUID, emb []float32) bool {
+	if len(emb) == 0 || g.queryDim == 0 {
+		return false
+	}
+	ok := len(emb) == g.queryDim
+	if g.seen[docID] {
+		return ok
+	}
+	g.seen[docID] = true
+	g.storedDims[len(emb)]++
+	if ok {
+		g.compatible++
+		return true
+	}
+	g.mismatched++
+	if len(g.sampleIDs) < maxEmbeddingDimMismatchIDs {
+		g.sampleIDs = append(g.sampleIDs, docID.String())
+	}
+	return false
+}
+
+// incompatible reports whether vectors were seen and none of them can be compared.
+func (g *embeddingDimGuard) incompatible() bool {
+	return g.mismatched > 0 && g.compatible == 0
+}
+
+// trace returns the embedding_dim_mismatch trace entry, or nil when every vector matched.
+func (g *embeddingDimGuard) trace() map[string]any {
+	if g.mismatched == 0 {
+		return nil
+	}
+	dims := make([]int, 0, len(g.storedDims))
+	for d := range g.storedDims {
+		dims = append(dims, d)
+	}
+	sort.Ints(dims)
+	stored := make([]map[string]any, 0, len(dims))
+	for _, d := range dims {
+		stored = append(stored, map[string]any{"dim": d, "count": g.storedDims[d]})
+	}
+	return map[string]any{
+		"query_dim":      g.queryDim,
+		"stored_dims":    stored,
+		"mismatched":     g.mismatched,
+		"compatible":     g.compatible,
+		"mismatched_ids": g.sampleIDs,
+		"dense_skipped":  g.denseSkipped,
+	}
+}
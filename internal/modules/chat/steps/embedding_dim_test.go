@@ -0,0 +1,45 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEmbeddingDimGuard(t *testing.T) {
+	g := newEmbeddingDimGuard(3)
+	if tr := g.trace(); tr != nil {
+		t.Fatalf("expected no trace before any mismatch, got %v", tr)
+	}
+
+	oldA, oldB, empty := uuid.New(), uuid.New(), uuid.New()
+	if g.check(oldA, []float32{1, 2}) || g.check(oldB, []float32{1, 2}) {
+		t.Fatal("expected 2-dim vectors to be rejected for a 3-dim query")
+	}
+	if g.check(empty, nil) {
+		t.Fatal("expected empty vector to be rejected")
+	}
+	// Seeing the same doc again (scope pass, then MMR pass) must not double count.
+	g.check(oldA, []float32{1, 2})
+	if !g.incompatible() {
+		t.Fatal("expected guard to report incompatible stored vectors")
+	}
+
+	if !g.check(uuid.New(), []float32{1, 2, 3}) {
+		t.Fatal("expected matching vector to pass")
+	}
+	if g.incompatible() {
+		t.Fatal("expected a compatible vector to clear the incompatible state")
+	}
+
+	tr := g.trace()
+	if tr["query_dim"] != 3 || tr["mismatched"] != 2 || tr["compatible"] != 1 {
+		t.Fatalf("unexpected trace: %v", tr)
+	}
+	if ids, _ := tr["mismatched_ids"].([]string); len(ids) != 2 {
+		t.Fatalf("expected 2 mismatched ids, got %v", tr["mismatched_ids"])
+	}
+	if dims, _ := tr["stored_dims"].([]map[string]any); len(dims) != 2 || dims[0]["dim"] != 2 || dims[0]["count"] != 2 {
+		t.Fatalf("unexpected stored dims: %v", tr["stored_dims"])
+	}
+}
@@ -107,6 +107,68 @@ func parseTitleFromText(text string) string {
 	return ""
 }
 
+// evidenceTrimSlackTokens covers the ellipsis and separator added when a body is cut.
+const evidenceTrimSlackTokens = 6
+
+// RenderEvidence formats one source for a prompt. The layout is fixed so citations can be
+// mapped back to sources:
+//
+//	[source_id=<id>] (type=<type>, block=<block_type>, via=<source>) <title> — <locator>[ (partial)]
+//	<text>
+//
+// Attributes and the title/locator are omitted when empty. With maxTokens > 0 the body is cut at
+// a sentence or word boundary and the header marked (partial); "" means nothing useful fits.
+func RenderEvidence(src EvidenceSource, maxTokens int) string {
+	id := strings.TrimSpace(src.ID)
+	body := strings.TrimSpace(src.Text)
+	if id == "" || body == "" {
+		return ""
+	}
+	header := evidenceHeader(src)
+	block := header + "\n" + body
+	if maxTokens <= 0 || estimateTokens(block) <= maxTokens {
+		return block
+	}
+	header += " (partial)"
+	remain := maxTokens - estimateTokens(header) - evidenceTrimSlackTokens
+	if remain <= 0 {
+		return ""
+	}
+	body = strings.TrimSpace(trimToTokensAtBoundary(body, remain))
+	if body == "" {
+		return ""
+	}
+	block = header + "\n" + body
+	if estimateTokens(block) > maxTokens {
+		return ""
+	}
+	return block
+}
+
+func evidenceHeader(src EvidenceSource) string {
+	header := "[source_id=" + strings.TrimSpace(src.ID) + "]"
+	attrs := make([]string, 0, 3)
+	if t := strings.TrimSpace(src.Type); t != "" {
+		attrs = append(attrs, "type="+t)
+	}
+	if bt := stringFromAnyCtx(src.Meta["block_type"]); bt != "" {
+		attrs = append(attrs, "block="+bt)
+	}
+	if via := stringFromAnyCtx(src.Meta["source"]); via != "" {
+		attrs = append(attrs, "via="+via)
+	}
+	if len(attrs) > 0 {
+		header += " (" + strings.Join(attrs, ", ") + ")"
+	}
+	if title := strings.TrimSpace(src.Title); title != "" {
+		header += " " + title
+	}
+	if loc := stringFromAnyCtx(src.Meta["locator"]); loc != "" {
+		header += " — " + loc
+	}
+	return header
+}
+
 func renderEvidenceSources(sources []EvidenceSource, maxTokens int) string {
 	if len(sources) == 0 {
 		return ""
@@ -114,40 +176,23 @@ func renderEvidenceSources(sources []EvidenceSource, maxTokens int) string {
 	var b strings.Builder
 	used := 0
 	for _, s := range sources {
-		if strings.TrimSpace(s.ID) == "" || strings.TrimSpace(s.Text) == "" {
-			continue
-		}
-		header := "[source_id=" + s.ID + "]"
-		if s.Type != "" {
-			header += " (type=" + s.Type + ")"
-		}
-		if s.Title != "" {
-			header += " " + s.Title
-		}
-		if loc := stringFromAnyCtx(s.Meta["locator"]); loc != "" {
-			header += " — " + loc
-		}
-		header += "\n"
-		body := strings.TrimSpace(s.Text)
-		block := header + body + "\n\n"
-		blockTokens := estimateTokens(block)
-		if maxTokens > 0 && used+blockTokens > maxTokens {
-			remain := maxTokens - used - estimateTokens(header) - 6
-			if remain <= 0 {
-				break
-			}
-			body = trimToTokens(body, remain)
-			if strings.TrimSpace(body) == "" {
+		budget := 0
+		if maxTokens > 0 {
+			budget = maxTokens - used
+			if budget <= 0 {
 				break
 			}
-			block = header + body + "\n\n"
-			blockTokens = estimateTokens(block)
-			if used+blockTokens > maxTokens {
-				break
+		}
+		block := RenderEvidence(s, budget)
+		if block == "" {
+			if strings.TrimSpace(s.ID) == "" || strings.TrimSpace(s.Text) == "" {
+				continue
 			}
+			break
 		}
+		block += "\n\n"
 		b.WriteString(block)
-		used += blockTokens
+		used += estimateTokens(block)
 	}
 	return strings.TrimSpace(b.String())
 }
@@ -0,0 +1,171 @@
+package steps
+
+import (
+	"sort"
+	"strings"
+)
+
+// evidenceLaneOrder ranks evidence by the lane that produced it, following the route schema's
+// lane order. Lanes without evidence sources of their own (path, concept, user) are kept so the
+// ranking stays aligned with the router if they start emitting sources.
+var evidenceLaneOrder = []string{"viewport", "unit", "path", "concept", "user", "retrieve", "materials", "graph", "web"}
+
+const (
+	evidenceIncluded  = "included"
+	evidenceTruncated = "truncated"
+	evidenceDropped   = "dropped"
+)
+
+// evidenceDisposition records what budget enforcement did with one source. KeptTokens +
+// DroppedTokens always equals Tokens.
+type evidenceDisposition struct {
+	ID            string `json:"id"`
+	Lane          string `json:"lane"`
+	Disposition   string `json:"disposition"`
+	Tokens        int    `json:"tokens"`
+	KeptTokens    int    `json:"kept_tokens"`
+	DroppedTokens int    `json:"dropped_tokens"`
+	Pinned        bool   `json:"pinned,omitempty"`
+}
+
+// evidenceLane maps a source to its context lane by ID prefix. The current block is reported
+// as "viewport": it is what the user is looking at, and it outranks the rest of the unit.
+func evidenceLane(s EvidenceSource) string {
+	id := strings.TrimSpace(s.ID)
+	switch {
+	case strings.HasPrefix(id, "unit:"):
+		if isCurrentBlockEvidence(s) {
+			return "viewport"
+		}
+		return "unit"
+	case strings.HasPrefix(id, "doc:"):
+		return "retrieve"
+	case strings.HasPrefix(id, "material:"):
+		return "materials"
+	case strings.HasPrefix(id, "concept_graph:"):
+		return "graph"
+	case strings.HasPrefix(id, "web:"):
+		return "web"
+	default:
+		return ""
+	}
+}
+
+func evidenceLaneRank(lane string) int {
+	for i, name := range evidenceLaneOrder {
+		if name == lane {
+			return i
+		}
+	}
+	return len(evidenceLaneOrder)
+}
+
+func isCurrentBlockEvidence(s EvidenceSource) bool {
+	return strings.HasPrefix(strings.TrimSpace(s.ID), "unit:") && stringFromAnyCtx(s.Meta["source"]) == "current"
+}
+
+// enforceEvidenceBudget ranks sources by lane (ties by evidence ID) and keeps them greedily
+// until budget tokens are spent: the source that crosses the budget is trimmed to fit and the
+// rest are dropped. The current block is never dropped or trimmed; it still counts against the
+// budget. A budget <= 0 keeps everything.
+func enforceEvidenceBudget(sources []EvidenceSource, budget int) ([]EvidenceSource, []evidenceDisposition) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	ranked := make([]EvidenceSource, 0, len(sources))
+	ranked = append(ranked, sources...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ri, rj := evidenceLaneRank(evidenceLane(ranked[i])), evidenceLaneRank(evidenceLane(ranked[j]))
+		if ri != rj {
+			return ri < rj
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+
+	kept := make([]EvidenceSource, 0, len(ranked))
+	dispositions := make([]evidenceDisposition, 0, len(ranked))
+	used, full := 0, false
+	for _, s := range ranked {
+		tokens := estimateTokens(s.Text)
+		d := evidenceDisposition{ID: s.ID, Lane: evidenceLane(s), Tokens: tokens}
+		remaining := budget - used
+		switch {
+		case budget <= 0 || isCurrentBlockEvidence(s) || (!full && tokens <= remaining):
+			d.Disposition = evidenceIncluded
+			d.KeptTokens = tokens
+			d.Pinned = isCurrentBlockEvidence(s)
+			kept = append(kept, s)
+		case remaining > 0 && !full:
+			// This source crosses the budget; whatever it keeps, everything after it is dropped.
+			full = true
+			text := trimToTokensAtBoundary(s.Text, remaining)
+			if keptTokens := estimateTokens(text); text != "" && keptTokens < tokens {
+				d.Disposition = evidenceTruncated
+				d.KeptTokens = keptTokens
+				kept = append(kept, truncatedEvidence(s, text, tokens))
+				break
+			}
+			d.Disposition = evidenceDropped
+		default:
+			d.Disposition = evidenceDropped
+		}
+		d.DroppedTokens = d.Tokens - d.KeptTokens
+		used += d.KeptTokens
+		dispositions = append(dispositions, d)
+	}
+	return kept, dispositions
+}
+
+// enforceEvidenceBudgets enforces budget over the user's evidence and webBudget over web
+// sources separately, so external pages never take room from the materials (or the reverse).
+func enforceEvidenceBudgets(sources []EvidenceSource, budget, webBudget int) ([]EvidenceSource, []evidenceDisposition) {
+	var own, web []EvidenceSource
+	for _, s := range sources {
+		if evidenceLane(s) == "web" {
+			web = append(web, s)
+		} else {
+			own = append(own, s)
+		}
+	}
+	kept, dispositions := enforceEvidenceBudget(own, budget)
+	if len(web) > 0 {
+		webKept, webDispositions := enforceEvidenceBudget(web, webBudget)
+		kept = append(kept, webKept...)
+		dispositions = append(dispositions, webDispositions...)
+	}
+	return kept, dispositions
+}
+
+// truncatedEvidence returns a copy of s carrying the trimmed text (which ends in "…") and a
+// truncated marker in Meta, leaving the caller's Meta map untouched.
+func truncatedEvidence(s EvidenceSource, text string, originalTokens int) EvidenceSource {
+	meta := make(map[string]any, len(s.Meta)+2)
+	for k, v := range s.Meta {
+		meta[k] = v
+	}
+	meta["truncated"] = true
+	meta["original_tokens"] = originalTokens
+	s.Text = text
+	s.Meta = meta
+	return s
+}
+
+// evidenceBudgetTrace summarizes enforcement for the context plan trace.
+func evidenceBudgetTrace(budget int, dispositions []evidenceDisposition) map[string]any {
+	total, kept := 0, 0
+	counts := map[string]int{}
+	for _, d := range dispositions {
+		total += d.Tokens
+		kept += d.KeptTokens
+		counts[d.Disposition]++
+	}
+	return map[string]any{
+		"budget":       budget,
+		"total_tokens": total,
+		"kept_tokens":  kept,
+		"included":     counts[evidenceIncluded],
+		"truncated":    counts[evidenceTruncated],
+		"dropped":      counts[evidenceDropped],
+		"sources":      dispositions,
+	}
+}
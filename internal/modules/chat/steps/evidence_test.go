@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEvidenceHeader(t *testing.T) {
+	src := EvidenceSource{
+		ID:    "unit:b1",
+		Type:  DocTypePathUnitBlock,
+		Title: "Loops",
+		Text:  "Loops repeat work.",
+		Meta:  map[string]any{"block_type": "paragraph", "source": "current", "locator": "block:b1"},
+	}
+	want := "[source_id=unit:b1] (type=path_unit_block, block=paragraph, via=current) Loops — block:b1\nLoops repeat work."
+	if got := RenderEvidence(src, 0); got != want {
+		t.Fatalf("RenderEvidence:\n got %q\nwant %q", got, want)
+	}
+
+	bare := EvidenceSource{ID: "material:c1", Text: "Body."}
+	if got := RenderEvidence(bare, 0); got != "[source_id=material:c1]\nBody." {
+		t.Fatalf("bare: %q", got)
+	}
+	if got := RenderEvidence(EvidenceSource{ID: "x", Text: "  "}, 0); got != "" {
+		t.Fatalf("empty text should render nothing: %q", got)
+	}
+}
+
+func TestRenderEvidenceTrimsToBudget(t *testing.T) {
+	text := strings.Repeat("One sentence about loops. ", 60)
+	src := EvidenceSource{ID: "unit:b1", Type: "paragraph", Title: "Loops", Text: text}
+
+	got := RenderEvidence(src, 40)
+	if got == "" {
+		t.Fatalf("expected a partial block")
+	}
+	if estimateTokens(got) > 40 {
+		t.Fatalf("block over budget: %d tokens", estimateTokens(got))
+	}
+	header, body, _ := strings.Cut(got, "\n")
+	if !strings.HasSuffix(header, " (partial)") {
+		t.Fatalf("header not marked partial: %q", header)
+	}
+	if !strings.HasPrefix(body, "One sentence about loops.") {
+		t.Fatalf("body: %q", body)
+	}
+	if got := RenderEvidence(src, 3); got != "" {
+		t.Fatalf("budget smaller than the header should render nothing: %q", got)
+	}
+}
+
+func TestRenderEvidenceSourcesStopsAtBudget(t *testing.T) {
+	sources := []EvidenceSource{
+		{ID: "a", Text: "First."},
+		{ID: "", Text: "skipped"},
+		{ID: "b", Text: strings.Repeat("Second body words. ", 200)},
+		{ID: "c", Text: "Third."},
+	}
+	got := renderEvidenceSources(sources, 60)
+	if !strings.HasPrefix(got, "[source_id=a]\nFirst.\n\n[source_id=b] (partial)\n") {
+		t.Fatalf("unexpected render: %q", got)
+	}
+	if estimateTokens(got) > 60 {
+		t.Fatalf("render over budget: %d tokens", estimateTokens(got))
+	}
+}
+
+func TestEnforceEvidenceBudgetRanksTruncatesAndDrops(t *testing.T) {
+	sentence := "Binary search halves the interval each step. "
+	sources := []EvidenceSource{
+		{ID: "concept_graph:p1", Type: "concept_graph", Text: strings.Repeat(sentence, 4)},
+		{ID: "material:m2", Type: "material_chunk", Text: strings.Repeat(sentence, 4)},
+		{ID: "doc:d1", Type: DocTypePathOverview, Text: strings.Repeat(sentence, 4)},
+		{ID: "material:m1", Type: "material_chunk", Text: strings.Repeat(sentence, 4)},
+		{ID: "unit:b2", Type: DocTypePathUnitBlock, Text: strings.Repeat(sentence, 4), Meta: map[string]any{"source": "visible"}},
+		// The current block alone exceeds the budget and must still be kept whole.
+		{ID: "unit:b1", Type: DocTypePathUnitBlock, Text: strings.Repeat(sentence, 8), Meta: map[string]any{"source": "current"}},
+	}
+	total := 0
+	for _, s := range sources {
+		total += estimateTokens(s.Text)
+	}
+	budget := estimateTokens(sources[5].Text) + estimateTokens(sources[4].Text) + 20
+
+	kept, dispositions := enforceEvidenceBudget(sources, budget)
+
+	wantOrder := []string{"unit:b1", "unit:b2", "doc:d1", "material:m1", "material:m2", "concept_graph:p1"}
+	wantDisp := []string{evidenceIncluded, evidenceIncluded, evidenceTruncated, evidenceDropped, evidenceDropped, evidenceDropped}
+	if len(dispositions) != len(wantOrder) {
+		t.Fatalf("expected %d dispositions, got %d", len(wantOrder), len(dispositions))
+	}
+	sum := 0
+	for i, d := range dispositions {
+		if d.ID != wantOrder[i] || d.Disposition != wantDisp[i] {
+			t.Fatalf("disposition %d = %s/%s, want %s/%s", i, d.ID, d.Disposition, wantOrder[i], wantDisp[i])
+		}
+		if d.KeptTokens+d.DroppedTokens != d.Tokens {
+			t.Fatalf("%s: kept %d + dropped %d != %d", d.ID, d.KeptTokens, d.DroppedTokens, d.Tokens)
+		}
+		sum += d.KeptTokens + d.DroppedTokens
+	}
+	if sum != total {
+		t.Fatalf("disposition tokens sum to %d, want %d", sum, total)
+	}
+	if !dispositions[0].Pinned {
+		t.Fatalf("expected current block to be pinned")
+	}
+
+	if len(kept) != 3 || kept[0].ID != "unit:b1" || kept[2].ID != "doc:d1" {
+		t.Fatalf("unexpected kept sources: %+v", kept)
+	}
+	truncated := kept[2]
+	if truncated.Meta["truncated"] != true || !strings.HasSuffix(truncated.Text, "…") {
+		t.Fatalf("expected truncation marker, got %q meta=%v", truncated.Text, truncated.Meta)
+	}
+	if sources[2].Meta != nil {
+		t.Fatalf("truncation must not mutate the input source")
+	}
+
+	// Citations resolve only against kept sources.
+	if cites := buildCitations([]string{"material:m1", "doc:d1"}, kept); len(cites) != 1 || cites[0].SourceID != "doc:d1" {
+		t.Fatalf("expected only the truncated source to be citable, got %+v", cites)
+	}
+}
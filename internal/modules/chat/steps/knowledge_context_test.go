@@ -0,0 +1,60 @@
+package steps
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	learningsteps "github.com/yungbote/neurobridge-backend/internal/modules/learning/steps"
+)
+
+func TestFitUserKnowledgeContextKeepsValidJSON(t *testing.T) {
+	kc := learningsteps.UserKnowledgeContextV2{Version: 2}
+	for i := 0; i < 40; i++ {
+		key := fmt.Sprintf("concept_%02d", i)
+		mastery := float64(i) / 40
+		kc.Concepts = append(kc.Concepts, learningsteps.ConceptKnowledgeV1{
+			Key:        key,
+			Mastery:    mastery,
+			Confidence: 0.5,
+			Status:     "learning",
+		})
+		if mastery >= 0.85 {
+			kc.KnownConceptKeys = append(kc.KnownConceptKeys, key)
+		}
+		kc.ActiveMisconceptions = append(kc.ActiveMisconceptions, key+": confuses base case")
+	}
+
+	raw, kept := fitUserKnowledgeContext(kc, 200)
+	if raw == "" || kept == 0 || kept >= len(kc.Concepts) {
+		t.Fatalf("kept %d of %d concepts (raw %q)", kept, len(kc.Concepts), raw)
+	}
+	if estimateTokens(raw) > 200 {
+		t.Fatalf("fitted context is %d tokens, budget 200", estimateTokens(raw))
+	}
+	var got learningsteps.UserKnowledgeContextV2
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatalf("truncated context is not valid JSON: %v\n%s", err, raw)
+	}
+	if len(got.Concepts) != kept {
+		t.Fatalf("decoded %d concepts, want %d", len(got.Concepts), kept)
+	}
+	for i, c := range got.Concepts {
+		if want := fmt.Sprintf("concept_%02d", i); c.Key != want {
+			t.Fatalf("concept %d = %s, want weakest-first %s", i, c.Key, want)
+		}
+	}
+	if len(got.KnownConceptKeys) != 0 {
+		t.Fatalf("known keys for dropped concepts survived: %v", got.KnownConceptKeys)
+	}
+	if len(got.ActiveMisconceptions) != kept {
+		t.Fatalf("misconceptions = %d, want one per kept concept (%d)", len(got.ActiveMisconceptions), kept)
+	}
+
+	if raw, _ := fitUserKnowledgeContext(kc, 2); raw != "" {
+		t.Fatalf("context under an impossible budget = %q, want empty", raw)
+	}
+	if raw, kept := fitUserKnowledgeContext(kc, 0); kept != len(kc.Concepts) || raw != kc.JSON() {
+		t.Fatalf("unbounded context was truncated to %d concepts", kept)
+	}
+}
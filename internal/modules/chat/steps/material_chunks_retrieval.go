@@ -369,7 +369,7 @@ func denseChunkCandidatesSQL(ctx context.Context, db *gorm.DB, sourceMaterialSet
 	var rows []*types.MaterialChunk
 	q := db.WithContext(ctx).
 		Model(&types.MaterialChunk{}).
-		Joins("JOIN material_file ON material_chunk.material_file_id = material_file.id").
+		Joins("JOIN material_file ON material_chunk.material_file_id = material_file.id AND material_file.deleted_at IS NULL").
 		Where("material_file.material_set_id = ?", sourceMaterialSetID).
 		Where("material_chunk.embedding <> '[]'::jsonb")
 	if len(allowFiles) > 0 {
@@ -441,8 +441,9 @@ func lexicalChunkCandidatesSQL(ctx context.Context, db *gorm.DB, sourceMaterialS
 		SELECT material_chunk.*,
 		       ts_rank(to_tsvector('english', material_chunk.text), plainto_tsquery('english', ?)) AS rank
 		FROM material_chunk
-		JOIN material_file ON material_chunk.material_file_id = material_file.id
+		JOIN material_file ON material_chunk.material_file_id = material_file.id AND material_file.deleted_at IS NULL
 		WHERE material_file.material_set_id = ?
+			AND material_chunk.deleted_at IS NULL
 			AND to_tsvector('english', material_chunk.text) @@ plainto_tsquery('english', ?)
 			%s
 		ORDER BY rank DESC, material_chunk.created_at DESC
@@ -494,7 +495,7 @@ func loadMaterialHitsByChunkIDs(
 	var chunks []*types.MaterialChunk
 	q := db.WithContext(ctx).
 		Model(&types.MaterialChunk{}).
-		Joins("JOIN material_file ON material_chunk.material_file_id = material_file.id").
+		Joins("JOIN material_file ON material_chunk.material_file_id = material_file.id AND material_file.deleted_at IS NULL").
 		Where("material_file.material_set_id = ?", sourceMaterialSetID).
 		Where("material_chunk.id IN ?", chunkIDs)
 	if len(allowFiles) > 0 {
@@ -0,0 +1,220 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// pathBuildStatusTTL bounds how stale the build status shown in chat can be. Builds move through
+// stages over minutes, so a short cache keeps every turn in a busy thread off job_run.
+const pathBuildStatusTTL = 30 * time.Second
+
+var pathBuildJobTypes = []string{"learning_build", "learning_build_progressive"}
+
+var pathBuildActiveStatuses = []string{"queued", "running", "waiting_user", "waiting_child"}
+
+// pathBuildStatus is the compact view of an in-flight path build the context planner shows the
+// model: where the build is and which artifacts already exist.
+type pathBuildStatus struct {
+	JobID         uuid.UUID `json:"job_id"`
+	JobType       string    `json:"job_type"`
+	Status        string    `json:"status"`
+	Stage         string    `json:"stage"`
+	Progress      int       `json:"progress"`
+	ConceptGraph  bool      `json:"concept_graph"`
+	DocsGenerated int       `json:"docs_generated"`
+	NodesTotal    int       `json:"nodes_total"`
+}
+
+func isActiveBuildStatus(status string) bool {
+	s := strings.ToLower(strings.TrimSpace(status))
+	for _, active := range pathBuildActiveStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+type pathBuildStatusLoader func(ctx context.Context, userID uuid.UUID, pathID uuid.UUID) (*pathBuildStatus, error)
+
+type pathBuildStatusEntry struct {
+	status  *pathBuildStatus
+	expires time.Time
+}
+
+// pathBuildStatusCache memoizes lookups per path, including "no active build", so the common
+// case of a finished path costs nothing after the first turn.
+type pathBuildStatusCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[uuid.UUID]pathBuildStatusEntry
+}
+
+func newPathBuildStatusCache(ttl time.Duration, now func() time.Time) *pathBuildStatusCache {
+	if now == nil {
+		now = time.Now
+	}
+	return &pathBuildStatusCache{ttl: ttl, now: now, entries: map[uuid.UUID]pathBuildStatusEntry{}}
+}
+
+// get returns the cached status for pathID, calling load on a miss. Load errors are not cached.
+func (c *pathBuildStatusCache) get(ctx context.Context, userID uuid.UUID, pathID uuid.UUID, load pathBuildStatusLoader) (*pathBuildStatus, bool, error) {
+	now := c.now()
+	c.mu.Lock()
+	if e, ok := c.entries[pathID]; ok && now.Before(e.expires) {
+		c.mu.Unlock()
+		return e.status, true, nil
+	}
+	c.mu.Unlock()
+
+	st, err := load(ctx, userID, pathID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[pathID] = pathBuildStatusEntry{status: st, expires: now.Add(c.ttl)}
+	return st, false, nil
+}
+
+var defaultPathBuildStatusCache = newPathBuildStatusCache(pathBuildStatusTTL, time.Now)
+
+// loadPathBuildStatus finds the newest non-terminal build job for the path (served by
+// idx_job_run_entity_status) and, only when one exists, counts the artifacts built so far.
+func loadPathBuildStatus(db *gorm.DB) pathBuildStatusLoader {
+	return func(ctx context.Context, userID uuid.UUID, pathID uuid.UUID) (*pathBuildStatus, error) {
+		type jobRow struct {
+			ID       uuid.UUID
+			JobType  string
+			Status   string
+			Stage    string
+			Progress int
+		}
+		var job jobRow
+		if err := db.WithContext(ctx).
+			Table("job_run").
+			Select("id", "job_type", "status", "stage", "progress").
+			Where("entity_type = ? AND entity_id = ? AND status IN ? AND owner_user_id = ? AND job_type IN ? AND deleted_at IS NULL",
+				"path", pathID, pathBuildActiveStatuses, userID, pathBuildJobTypes).
+			Order("created_at DESC").
+			Limit(1).
+			Find(&job).Error; err != nil {
+			return nil, err
+		}
+		if job.ID == uuid.Nil || !isActiveBuildStatus(job.Status) {
+			return nil, nil
+		}
+
+		var counts struct {
+			NodesTotal    int
+			DocsGenerated int
+			ConceptGraph  bool
+		}
+		if err := db.WithContext(ctx).Raw(`
+SELECT
+  (SELECT COUNT(*) FROM path_node WHERE path_id = ? AND deleted_at IS NULL) AS nodes_total,
+  (SELECT COUNT(*) FROM learning_node_doc WHERE path_id = ? AND track = 'primary') AS docs_generated,
+  EXISTS (SELECT 1 FROM concept WHERE scope = 'path' AND scope_id = ? AND deleted_at IS NULL) AS concept_graph`,
+			pathID, pathID, pathID).Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+		return &pathBuildStatus{
+			JobID:         job.ID,
+			JobType:       job.JobType,
+			Status:        job.Status,
+			Stage:         job.Stage,
+			Progress:      job.Progress,
+			ConceptGraph:  counts.ConceptGraph,
+			DocsGenerated: counts.DocsGenerated,
+			NodesTotal:    counts.NodesTotal,
+		}, nil
+	}
+}
+
+// pathBuildContext returns the "## Path build status" section for the thread's path, or "" when
+// the path has no active build. The trace records the status either way so the turn can be
+// excluded from quality analysis.
+func pathBuildContext(ctx context.Context, deps ContextPlanDeps, in ContextPlanInput, trace map[string]any) string {
+	if deps.DB == nil || in.Thread == nil || in.Thread.PathID == nil || *in.Thread.PathID == uuid.Nil {
+		return ""
+	}
+	st, cached, err := defaultPathBuildStatusCache.get(ctx, in.UserID, *in.Thread.PathID, loadPathBuildStatus(deps.DB))
+	if err != nil {
+		if trace != nil {
+			trace["path_build"] = map[string]any{"error": err.Error()}
+		}
+		return ""
+	}
+	if st == nil {
+		if trace != nil {
+			trace["path_build"] = map[string]any{"active": false, "cached": cached}
+		}
+		return ""
+	}
+	if trace != nil {
+		trace["path_build"] = map[string]any{
+			"active":         true,
+			"cached":         cached,
+			"job_id":         st.JobID.String(),
+			"job_type":       st.JobType,
+			"status":         st.Status,
+			"stage":          st.Stage,
+			"progress":       st.Progress,
+			"concept_graph":  st.ConceptGraph,
+			"docs_generated": st.DocsGenerated,
+			"nodes_total":    st.NodesTotal,
+		}
+	}
+	return renderPathBuildStatus(st)
+}
+
+func renderPathBuildStatus(st *pathBuildStatus) string {
+	if st == nil {
+		return ""
+	}
+	stage := strings.TrimSpace(st.Stage)
+	if stage == "" {
+		stage = "queued"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "This path is still being built (stage: %s, %d%% complete, status: %s).\n", stage, st.Progress, st.Status)
+	if st.ConceptGraph {
+		b.WriteString("- Concept graph: ready\n")
+	} else {
+		b.WriteString("- Concept graph: not built yet\n")
+	}
+	if st.NodesTotal > 0 {
+		fmt.Fprintf(&b, "- Unit docs: %d of %d generated\n", st.DocsGenerated, st.NodesTotal)
+	} else {
+		b.WriteString("- Unit docs: outline not generated yet\n")
+	}
+	b.WriteString("Answer from what already exists. If the user asks about a unit or topic that has not been generated yet, say it is still being built and roughly where the build is; do not invent its content.")
+	return b.String()
+}
+
+// planTraceBuildInProgress reports whether the plan that served a turn saw an active path build.
+func planTraceBuildInProgress(trace map[string]any) bool {
+	if trace == nil {
+		return false
+	}
+	pb, ok := trace["path_build"].(map[string]any)
+	if !ok {
+		return false
+	}
+	active, _ := pb["active"].(bool)
+	return active
+}
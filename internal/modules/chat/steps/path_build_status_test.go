@@ -0,0 +1,94 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIsActiveBuildStatus(t *testing.T) {
+	cases := map[string]bool{
+		"queued":        true,
+		"running":       true,
+		"waiting_user":  true,
+		"waiting_child": true,
+		" Running ":     true,
+		"succeeded":     false,
+		"failed":        false,
+		"canceled":      false,
+		"":              false,
+	}
+	for status, want := range cases {
+		if got := isActiveBuildStatus(status); got != want {
+			t.Errorf("isActiveBuildStatus(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestPathBuildStatusCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newPathBuildStatusCache(30*time.Second, func() time.Time { return now })
+	userID, pathID := uuid.New(), uuid.New()
+
+	calls := 0
+	var next *pathBuildStatus
+	var nextErr error
+	load := func(context.Context, uuid.UUID, uuid.UUID) (*pathBuildStatus, error) {
+		calls++
+		return next, nextErr
+	}
+
+	next = &pathBuildStatus{Status: "running", Stage: "node_docs", Progress: 40}
+	st, cached, err := cache.get(context.Background(), userID, pathID, load)
+	if err != nil || cached || st == nil || st.Progress != 40 {
+		t.Fatalf("first get = %+v cached=%v err=%v", st, cached, err)
+	}
+
+	// Within the TTL the loader is not consulted, even though the build has moved on.
+	next = nil
+	now = now.Add(29 * time.Second)
+	st, cached, _ = cache.get(context.Background(), userID, pathID, load)
+	if !cached || st == nil || calls != 1 {
+		t.Fatalf("expected cached hit, got %+v cached=%v calls=%d", st, cached, calls)
+	}
+
+	// After expiry the finished build ("no active build") is loaded and cached too.
+	now = now.Add(2 * time.Second)
+	st, cached, _ = cache.get(context.Background(), userID, pathID, load)
+	if cached || st != nil || calls != 2 {
+		t.Fatalf("expected reload to nil, got %+v cached=%v calls=%d", st, cached, calls)
+	}
+	if _, cached, _ = cache.get(context.Background(), userID, pathID, load); !cached || calls != 2 {
+		t.Fatalf("expected negative result to be cached, calls=%d", calls)
+	}
+
+	// Errors are never cached.
+	other := uuid.New()
+	nextErr = errors.New("db down")
+	if _, _, err := cache.get(context.Background(), userID, other, load); err == nil {
+		t.Fatal("expected load error")
+	}
+	nextErr = nil
+	if _, cached, err := cache.get(context.Background(), userID, other, load); err != nil || cached || calls != 4 {
+		t.Fatalf("expected retry after error, cached=%v calls=%d err=%v", cached, calls, err)
+	}
+}
+
+func TestRenderPathBuildStatus(t *testing.T) {
+	text := renderPathBuildStatus(&pathBuildStatus{Status: "running", Stage: "node_docs", Progress: 55, ConceptGraph: true, DocsGenerated: 3, NodesTotal: 12})
+	for _, want := range []string{"stage: node_docs", "55%", "Concept graph: ready", "3 of 12", "do not invent"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rendered status missing %q:\n%s", want, text)
+		}
+	}
+	if !planTraceBuildInProgress(map[string]any{"path_build": map[string]any{"active": true}}) {
+		t.Error("expected active build trace to be detected")
+	}
+	if planTraceBuildInProgress(map[string]any{"path_build": map[string]any{"active": false}}) {
+		t.Error("inactive build trace reported as in progress")
+	}
+}
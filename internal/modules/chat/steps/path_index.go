@@ -134,7 +134,7 @@ func IndexPathDocsForChat(ctx context.Context, deps PathIndexDeps, in PathIndexI
 			}
 		}
 		if len(nodeIDs) > 0 {
-			if rows, err := deps.NodeDocs.GetByPathNodeIDs(dbc, nodeIDs); err == nil {
+			if rows, err := deps.NodeDocs.GetLatestByPathNodeIDs(dbc, nodeIDs); err == nil {
 				for _, d := range rows {
 					if d != nil && d.PathNodeID != uuid.Nil && strings.TrimSpace(d.DocText) != "" {
 						nodeDocByNodeID[d.PathNodeID] = d
@@ -485,7 +485,7 @@ func renderPathOverview(path *types.Path, nodes []*types.PathNode, concepts []*t
 		b.WriteString("Description: " + desc + "\n")
 	}
 	if len(nodes) > 0 {
-		b.WriteString("\nUnit titles (in order):\n")
+		b.WriteString("\nUnits (in order):\n")
 		for _, n := range nodes {
 			if n == nil || n.ID == uuid.Nil {
 				continue
@@ -494,7 +494,12 @@ func renderPathOverview(path *types.Path, nodes []*types.PathNode, concepts []*t
 			if t == "" {
 				t = "Untitled unit"
 			}
-			b.WriteString(fmt.Sprintf("- %d. %s\n", n.Index, t))
+			line := fmt.Sprintf("- %d. %s", n.Index, t)
+			// Prefers the description synced from the unit's doc over the build goal.
+			if desc, _ := types.PathNodeDescription(n.Metadata); desc != "" {
+				line += " — " + trimToChars(desc, 280)
+			}
+			b.WriteString(line + "\n")
 		}
 	}
 	if len(concepts) > 0 {
@@ -530,6 +535,9 @@ func renderPathNode(n *types.PathNode, joins []*types.PathNodeActivity, activity
 
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("Unit %d: %s\n", n.Index, title))
+	if desc, _ := types.PathNodeDescription(n.Metadata); desc != "" {
+		b.WriteString("Description: " + desc + "\n")
+	}
 	if len(n.Gating) > 0 && string(n.Gating) != "null" {
 		b.WriteString("Gating: " + trimToChars(string(n.Gating), 800) + "\n")
 	}
@@ -56,7 +56,7 @@ func IndexPathNodeBlocksForChat(ctx context.Context, deps PathIndexDeps, in Path
 		return out, fmt.Errorf("path node mismatch")
 	}
 
-	docRows, err := deps.NodeDocs.GetByPathNodeIDs(dbc, []uuid.UUID{in.PathNodeID})
+	docRows, err := deps.NodeDocs.GetLatestByPathNodeIDs(dbc, []uuid.UUID{in.PathNodeID})
 	if err != nil || len(docRows) == 0 || docRows[0] == nil {
 		return out, fmt.Errorf("node doc not found")
 	}
@@ -0,0 +1,227 @@
+package steps
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progressive generation: the skeleton plan (hot window + summary + pinned intake) starts streaming
+// immediately while the full plan is built. If the full plan lands inside the window and the model
+// hasn't produced substantive output yet, the skeleton answer is discarded and generation restarts
+// with the full instructions (upgrade); otherwise the skeleton answer stands (downgrade). The
+// skeleton streams statelessly since it may be thrown away, so a downgraded answer is recorded
+// into the conversation afterwards.
+
+type progressiveConfig struct {
+	Enabled          bool
+	Window           time.Duration
+	RestartMaxTokens int
+}
+
+func resolveProgressiveConfig() progressiveConfig {
+	cfg := progressiveConfig{Enabled: true, Window: 2500 * time.Millisecond, RestartMaxTokens: 24}
+	if raw := strings.TrimSpace(os.Getenv("CHAT_PROGRESSIVE_ENABLED")); raw != "" {
+		if v, err := strconv.ParseBool(raw); err == nil {
+			cfg.Enabled = v
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("CHAT_PROGRESSIVE_WINDOW_MS")); raw != "" {
+		if ms, err := strconv.Atoi(raw); err == nil && ms >= 0 {
+			cfg.Window = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("CHAT_PROGRESSIVE_RESTART_MAX_TOKENS")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			cfg.RestartMaxTokens = n
+		}
+	}
+	if cfg.Window <= 0 {
+		cfg.Enabled = false
+	}
+	return cfg
+}
+
+// restartableStream forwards model deltas to sink and lets the caller replace the generation in
+// flight, but only while the client has seen fewer than maxTokens tokens. Once that many tokens
+// are out (or Commit is called) the stream is committed and TryRestart always fails. Deltas from a
+// generation that has been replaced are dropped, so a cancelled stream can't leak into the new one.
+type restartableStream struct {
+	mu        sync.Mutex
+	maxTokens int
+	gen       int
+	emitted   strings.Builder
+	committed bool
+	sink      func(delta string)
+}
+
+func newRestartableStream(maxTokens int, sink func(delta string)) *restartableStream {
+	return &restartableStream{maxTokens: maxTokens, sink: sink}
+}
+
+// OnDelta returns the delta callback for the current generation.
+func (s *restartableStream) OnDelta() func(string) {
+	s.mu.Lock()
+	gen := s.gen
+	s.mu.Unlock()
+	return func(delta string) {
+		if delta == "" {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if gen != s.gen {
+			return
+		}
+		s.emitted.WriteString(delta)
+		if !s.committed && estimateTokens(strings.TrimSpace(s.emitted.String())) >= s.maxTokens {
+			s.committed = true
+		}
+		if s.sink != nil {
+			s.sink(delta)
+		}
+	}
+}
+
+// TryRestart retires the current generation and reports how many tokens it had emitted.
+func (s *restartableStream) TryRestart() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.committed {
+		return 0, false
+	}
+	discarded := estimateTokens(strings.TrimSpace(s.emitted.String()))
+	s.gen++
+	s.emitted.Reset()
+	return discarded, true
+}
+
+func (s *restartableStream) Commit() {
+	s.mu.Lock()
+	s.committed = true
+	s.mu.Unlock()
+}
+
+type streamTextFunc func(ctx context.Context, instructions string, userPayload string, onDelta func(delta string)) (string, error)
+
+type progressiveOutcome struct {
+	Text   string
+	Served productPlan
+	Phase  string
+	Trace  map[string]any
+}
+
+// streamProgressive runs the skeleton generation and the full plan build concurrently and returns
+// the answer together with the plan that actually produced it. onRestart is called (after the
+// skeleton stream has stopped) just before the full generation starts, so the caller can reset
+// whatever it already showed the client. recordDowngrade, when set, is called with the served
+// skeleton answer on every downgrade so conversation state still sees the turn.
+func streamProgressive(
+	ctx context.Context,
+	cfg progressiveConfig,
+	skeleton productPlan,
+	buildFull func(ctx context.Context) (productPlan, error),
+	streamSkeleton streamTextFunc,
+	streamFull streamTextFunc,
+	sink func(delta string),
+	onRestart func(),
+	recordDowngrade func(ctx context.Context, userPayload string, text string) error,
+) (progressiveOutcome, error) {
+	start := time.Now()
+	trace := map[string]any{
+		"window_ms":          cfg.Window.Milliseconds(),
+		"restart_max_tokens": cfg.RestartMaxTokens,
+	}
+
+	type fullResult struct {
+		plan productPlan
+		err  error
+	}
+	type streamResult struct {
+		text string
+		err  error
+	}
+
+	fullCtx, cancelFull := context.WithCancel(ctx)
+	defer cancelFull()
+	fullCh := make(chan fullResult, 1)
+	go func() {
+		p, err := buildFull(fullCtx)
+		fullCh <- fullResult{plan: p, err: err}
+	}()
+
+	rs := newRestartableStream(cfg.RestartMaxTokens, sink)
+	skelCtx, cancelSkel := context.WithCancel(ctx)
+	defer cancelSkel()
+	skelCh := make(chan streamResult, 1)
+	onSkelDelta := rs.OnDelta()
+	go func() {
+		text, err := streamSkeleton(skelCtx, skeleton.Instructions, skeleton.UserPayload, onSkelDelta)
+		skelCh <- streamResult{text: text, err: err}
+	}()
+
+	timer := time.NewTimer(cfg.Window)
+	defer timer.Stop()
+
+	var skelRes *streamResult
+	reason := ""
+	for reason == "" {
+		select {
+		case r := <-fullCh:
+			trace["full_ready_ms"] = time.Since(start).Milliseconds()
+			if r.err != nil {
+				trace["full_error"] = r.err.Error()
+				reason = "full_plan_failed"
+				continue
+			}
+			if r.plan.Plan.Mode == "edit" {
+				// Edit turns need the synchronous edit handler; don't swap them in mid-stream.
+				reason = "full_plan_edit_mode"
+				continue
+			}
+			discarded, ok := rs.TryRestart()
+			if !ok {
+				reason = "output_committed"
+				continue
+			}
+			cancelSkel()
+			<-skelCh
+			if onRestart != nil {
+				onRestart()
+			}
+			trace["upgraded"] = true
+			trace["discarded_tokens"] = discarded
+			text, err := streamFull(ctx, r.plan.Instructions, r.plan.UserPayload, rs.OnDelta())
+			return progressiveOutcome{Text: text, Served: r.plan, Phase: "full", Trace: trace}, err
+		case r := <-skelCh:
+			skelRes = &r
+			if r.err != nil {
+				return progressiveOutcome{Served: skeleton, Phase: "skeleton", Trace: trace}, r.err
+			}
+			reason = "skeleton_complete"
+		case <-timer.C:
+			reason = "window_elapsed"
+		}
+	}
+
+	// Downgrade: the skeleton answer stands and the full lanes are skipped for this turn.
+	rs.Commit()
+	cancelFull()
+	if skelRes == nil {
+		r := <-skelCh
+		skelRes = &r
+	}
+	trace["downgraded"] = true
+	trace["downgrade_reason"] = reason
+	if recordDowngrade != nil && skelRes.err == nil && strings.TrimSpace(skelRes.text) != "" {
+		if err := recordDowngrade(ctx, skeleton.UserPayload, skelRes.text); err != nil {
+			trace["record_error"] = err.Error()
+		} else {
+			trace["recorded"] = true
+		}
+	}
+	return progressiveOutcome{Text: skelRes.text, Served: skeleton, Phase: "skeleton", Trace: trace}, skelRes.err
+}
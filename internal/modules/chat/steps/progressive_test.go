@@ -0,0 +1,218 @@
+package steps
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	text     strings.Builder
+	restarts int
+}
+
+func (s *recordingSink) delta(d string) { s.text.WriteString(d) }
+
+func (s *recordingSink) restart() {
+	s.restarts++
+	s.text.Reset()
+}
+
+// blockingStream emits its deltas and then holds the generation open until cancelled, like a
+// model that is still producing its answer.
+func blockingStream(deltas ...string) streamTextFunc {
+	return func(ctx context.Context, instructions string, userPayload string, onDelta func(string)) (string, error) {
+		for _, d := range deltas {
+			onDelta(d)
+		}
+		<-ctx.Done()
+		return strings.Join(deltas, ""), ctx.Err()
+	}
+}
+
+func pacedStream(every time.Duration, deltas ...string) streamTextFunc {
+	return func(ctx context.Context, instructions string, userPayload string, onDelta func(string)) (string, error) {
+		for _, d := range deltas {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(every):
+			}
+			onDelta(d)
+		}
+		return strings.Join(deltas, ""), nil
+	}
+}
+
+// conversationLog stands in for the OpenAI conversation a downgraded turn is appended to.
+type conversationLog struct {
+	turns [][2]string
+}
+
+func (l *conversationLog) record(ctx context.Context, userPayload string, text string) error {
+	l.turns = append(l.turns, [2]string{userPayload, text})
+	return nil
+}
+
+func skeletonPlan() productPlan {
+	plan := ContextPlanOutput{Instructions: "SKELETON", UserPayload: "what is a loop?", Trace: map[string]any{"plan_phase": "skeleton"}}
+	return productPlan{Plan: plan, Instructions: plan.Instructions, UserPayload: plan.UserPayload, Trace: plan.Trace}
+}
+
+func fullPlan() productPlan {
+	evidence := []EvidenceSource{{ID: "chunk:1", Type: "chunk", Text: "A loop repeats a block."}}
+	plan := ContextPlanOutput{Instructions: "FULL", UserPayload: "what is a loop?", Trace: map[string]any{"plan_phase": "full"}, EvidenceSources: evidence}
+	return productPlan{Plan: plan, Instructions: "FULL\n\n## Evidence Sources", UserPayload: plan.UserPayload, Trace: plan.Trace, SelectedEvidence: evidence, EvidenceText: "[source_id=chunk:1]"}
+}
+
+func TestStreamProgressiveUpgradesWhenFullPlanIsReadyInWindow(t *testing.T) {
+	sink := &recordingSink{}
+	cfg := progressiveConfig{Enabled: true, Window: time.Second, RestartMaxTokens: 24}
+
+	var fullInstructions string
+	streamFull := func(ctx context.Context, instructions string, userPayload string, onDelta func(string)) (string, error) {
+		fullInstructions = instructions
+		onDelta("A loop repeats a block [[source:chunk:1]].")
+		return "A loop repeats a block [[source:chunk:1]].", nil
+	}
+	buildFull := func(ctx context.Context) (productPlan, error) {
+		time.Sleep(20 * time.Millisecond) // retrieval
+		return fullPlan(), nil
+	}
+
+	convo := &conversationLog{}
+	res, err := streamProgressive(context.Background(), cfg, skeletonPlan(), buildFull, blockingStream("Sure"), streamFull, sink.delta, sink.restart, convo.record)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if res.Phase != "full" || res.Trace["upgraded"] != true || res.Trace["downgraded"] != nil {
+		t.Fatalf("expected upgrade, got phase=%s trace=%v", res.Phase, res.Trace)
+	}
+	if sink.restarts != 1 || sink.text.String() != res.Text {
+		t.Fatalf("client should only keep the full answer: restarts=%d text=%q", sink.restarts, sink.text.String())
+	}
+	if !strings.HasPrefix(fullInstructions, "FULL") {
+		t.Fatalf("full generation used %q", fullInstructions)
+	}
+	if res.Trace["discarded_tokens"] != 1 {
+		t.Fatalf("discarded tokens: %v", res.Trace["discarded_tokens"])
+	}
+	if len(convo.turns) != 0 {
+		t.Fatalf("an upgraded answer is generated in the conversation, not appended: %v", convo.turns)
+	}
+
+	meta := assistantMessageMeta(res.Phase, res.Served.SelectedEvidence, nil, true, false)
+	if meta["context_plan"] != "full" || !reflect.DeepEqual(meta["evidence_ids"], []string{"chunk:1"}) {
+		t.Fatalf("metadata should reflect the full plan: %v", meta)
+	}
+	if res.Served.Trace["plan_phase"] != "full" {
+		t.Fatalf("served trace: %v", res.Served.Trace)
+	}
+}
+
+func TestStreamProgressiveDowngradesOnSlowRetrieval(t *testing.T) {
+	sink := &recordingSink{}
+	cfg := progressiveConfig{Enabled: true, Window: 30 * time.Millisecond, RestartMaxTokens: 24}
+
+	fullCancelled := make(chan struct{})
+	buildFull := func(ctx context.Context) (productPlan, error) {
+		select {
+		case <-ctx.Done():
+			close(fullCancelled)
+			return productPlan{}, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return fullPlan(), nil
+		}
+	}
+	streamFull := func(ctx context.Context, instructions string, userPayload string, onDelta func(string)) (string, error) {
+		t.Fatalf("full generation must not run on a downgrade")
+		return "", nil
+	}
+
+	convo := &conversationLog{}
+	res, err := streamProgressive(context.Background(), cfg, skeletonPlan(), buildFull, pacedStream(20*time.Millisecond, "A loop ", "repeats ", "work."), streamFull, sink.delta, sink.restart, convo.record)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if res.Phase != "skeleton" || res.Trace["downgraded"] != true || res.Trace["downgrade_reason"] != "window_elapsed" {
+		t.Fatalf("expected window downgrade, got phase=%s trace=%v", res.Phase, res.Trace)
+	}
+	if sink.restarts != 0 || sink.text.String() != "A loop repeats work." || res.Text != sink.text.String() {
+		t.Fatalf("skeleton answer should stream untouched: restarts=%d text=%q", sink.restarts, sink.text.String())
+	}
+	if len(convo.turns) != 1 || convo.turns[0] != [2]string{"what is a loop?", "A loop repeats work."} || res.Trace["recorded"] != true {
+		t.Fatalf("downgraded turn must be recorded in the conversation: turns=%v trace=%v", convo.turns, res.Trace)
+	}
+	select {
+	case <-fullCancelled:
+	case <-time.After(time.Second):
+		t.Fatalf("full plan build was not cancelled")
+	}
+
+	meta := assistantMessageMeta(res.Phase, res.Served.SelectedEvidence, nil, true, false)
+	if meta["context_plan"] != "skeleton" {
+		t.Fatalf("context_plan: %v", meta)
+	}
+	if _, ok := meta["evidence_ids"]; ok {
+		t.Fatalf("downgraded answer must not claim full-plan evidence: %v", meta)
+	}
+	if res.Served.Trace["plan_phase"] != "skeleton" {
+		t.Fatalf("served trace: %v", res.Served.Trace)
+	}
+}
+
+func TestStreamProgressiveKeepsSkeletonOnceOutputIsSubstantive(t *testing.T) {
+	sink := &recordingSink{}
+	cfg := progressiveConfig{Enabled: true, Window: time.Second, RestartMaxTokens: 4}
+
+	long := "A loop runs the same block of code again and again."
+	streamSkeleton := func(ctx context.Context, instructions string, userPayload string, onDelta func(string)) (string, error) {
+		onDelta(long)
+		time.Sleep(40 * time.Millisecond)
+		return long, nil
+	}
+	buildFull := func(ctx context.Context) (productPlan, error) {
+		time.Sleep(10 * time.Millisecond)
+		return fullPlan(), nil
+	}
+
+	convo := &conversationLog{}
+	res, err := streamProgressive(context.Background(), cfg, skeletonPlan(), buildFull, streamSkeleton, nil, sink.delta, sink.restart, convo.record)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if res.Phase != "skeleton" || res.Trace["downgrade_reason"] != "output_committed" {
+		t.Fatalf("expected committed downgrade, got phase=%s trace=%v", res.Phase, res.Trace)
+	}
+	if sink.restarts != 0 || res.Text != long {
+		t.Fatalf("restarts=%d text=%q", sink.restarts, res.Text)
+	}
+	if len(convo.turns) != 1 || convo.turns[0][1] != long {
+		t.Fatalf("committed skeleton answer must be recorded: %v", convo.turns)
+	}
+	if _, ok := res.Trace["full_ready_ms"]; !ok {
+		t.Fatalf("trace should record when the full plan was ready: %v", res.Trace)
+	}
+}
+
+func TestRestartableStreamDropsRetiredGeneration(t *testing.T) {
+	var got strings.Builder
+	rs := newRestartableStream(8, func(d string) { got.WriteString(d) })
+	first := rs.OnDelta()
+	first("hi ")
+	if _, ok := rs.TryRestart(); !ok {
+		t.Fatalf("restart before any substantive output should succeed")
+	}
+	second := rs.OnDelta()
+	first("stale")
+	second("fresh")
+	if got.String() != "hi fresh" {
+		t.Fatalf("got %q", got.String())
+	}
+	second(" text that is long enough to commit the stream")
+	if _, ok := rs.TryRestart(); ok {
+		t.Fatalf("restart after substantive output must fail")
+	}
+}
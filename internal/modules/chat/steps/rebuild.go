@@ -123,3 +123,60 @@ func PurgeThreadArtifacts(ctx context.Context, deps RebuildDeps, in RebuildInput
 	}
 	return nil
 }
+
+type MaterialFileProjectionsInput struct {
+	UserID         uuid.UUID
+	MaterialFileID uuid.UUID
+}
+
+// PurgeMaterialFileProjections drops the user's pinned path_materials docs (and their vectors)
+// for every material set containing the file: the upload batch and any derived set. They are
+// rebuilt on the next context plan from the remaining files.
+func PurgeMaterialFileProjections(ctx context.Context, deps RebuildDeps, in MaterialFileProjectionsInput) (int, error) {
+	if deps.DB == nil {
+		return 0, fmt.Errorf("chat material purge: missing deps")
+	}
+	if in.UserID == uuid.Nil || in.MaterialFileID == uuid.Nil {
+		return 0, fmt.Errorf("chat material purge: missing ids")
+	}
+
+	var setIDs []uuid.UUID
+	if err := deps.DB.WithContext(ctx).
+		Model(&types.MaterialFile{}).
+		Unscoped().
+		Where("id = ?", in.MaterialFileID).
+		Pluck("material_set_id", &setIDs).Error; err != nil {
+		return 0, err
+	}
+	var derived []uuid.UUID
+	if err := deps.DB.WithContext(ctx).
+		Model(&types.MaterialSetFile{}).
+		Unscoped().
+		Where("material_file_id = ?", in.MaterialFileID).
+		Pluck("material_set_id", &derived).Error; err != nil {
+		return 0, err
+	}
+	setIDs = append(setIDs, derived...)
+	if len(setIDs) == 0 {
+		return 0, nil
+	}
+
+	q := deps.DB.WithContext(ctx).
+		Where("user_id = ? AND doc_type = ? AND source_id IN ?", in.UserID, DocTypePathMaterials, setIDs)
+	var vectorIDs []string
+	if err := q.Session(&gorm.Session{}).Model(&types.ChatDoc{}).Pluck("vector_id", &vectorIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(vectorIDs) == 0 {
+		return 0, nil
+	}
+	if err := q.Session(&gorm.Session{}).Delete(&types.ChatDoc{}).Error; err != nil {
+		return 0, err
+	}
+	if deps.Vec != nil {
+		delCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		_ = deps.Vec.DeleteIDs(delCtx, chatIndex.ChatUserNamespace(in.UserID), vectorIDs)
+		cancel()
+	}
+	return len(vectorIDs), nil
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/platform/websearch"
 	"github.com/yungbote/neurobridge-backend/internal/services"
+	waitcfg "github.com/yungbote/neurobridge-backend/internal/waitpoint/configs"
 )
 
 type RespondDeps struct {
@@ -40,14 +42,28 @@ type RespondDeps struct {
 	NodeDocs  repos.LearningNodeDocRepo
 	Concepts  repos.ConceptRepo
 	Edges     repos.ConceptEdgeRepo
+	Evidence  repos.ConceptEvidenceRepo
 	Mastery   repos.UserConceptStateRepo
 	Models    repos.UserConceptModelRepo
 	Miscon    repos.UserMisconceptionInstanceRepo
 	Sessions  repos.UserSessionStateRepo
+	Outlines  services.PathOutlineService
+	// Web is optional; nil keeps the web lane off.
+	Web websearch.Provider
+	// Focus is optional; nil ignores path focus sessions.
+	Focus repos.PathFocusRepo
+	// BudgetStats is optional; nil skips context budget sampling.
+	BudgetStats services.ContextBudgetSampler
+	// Profiles is optional; nil grounds unit context on the primary doc track.
+	Profiles repos.LearningProfileRepo
 
 	JobRuns repos.JobRunRepo
 	Jobs    services.JobService
 
+	// ToolExecs is the per-message ledger for chat actions; Actions executes them.
+	ToolExecs repos.ChatToolExecutionRepo
+	Actions   PathActions
+
 	Notify services.ChatNotifier
 }
 
@@ -96,25 +112,7 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 
 	// If this is a retry, reset the assistant placeholder so clients can safely restart streaming.
 	if in.Attempt > 0 {
-		_ = deps.Messages.UpdateFields(dbc, in.AssistantMessageID, map[string]interface{}{
-			"content":    "",
-			"status":     MessageStatusStreaming,
-			"updated_at": time.Now().UTC(),
-		})
-		if deps.Notify != nil {
-			var asst types.ChatMessage
-			_ = deps.DB.WithContext(ctx).
-				Model(&types.ChatMessage{}).
-				Where("id = ? AND thread_id = ? AND user_id = ?", in.AssistantMessageID, in.ThreadID, in.UserID).
-				First(&asst).Error
-			if asst.ID != uuid.Nil {
-				deps.Notify.MessageCreated(in.UserID, in.ThreadID, &asst, map[string]any{
-					"turn_id": in.TurnID.String(),
-					"attempt": in.Attempt,
-					"job_id":  in.JobID.String(),
-				})
-			}
-		}
+		resetAssistantStream(ctx, deps, in, nil)
 	}
 
 	// Load the user message content (canonical).
@@ -158,12 +156,50 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		}
 	}
 
+	// Chat actions are exposed per turn; while a waitpoint is pending only the exposed actions
+	// (e.g. confirm_intake) are routable and anything but an action call stays on the product path.
+	actionScope := chatActionScope{
+		UserID:             in.UserID,
+		Thread:             thread,
+		UserMessageID:      in.UserMessageID,
+		AssistantMessageID: in.AssistantMessageID,
+		Mode:               classifyContextRoute(userText).Mode,
+	}
+	if sc := parseSessionContext(&userMsg); sc != nil {
+		actionScope.ActivePathNodeID, _ = uuid.Parse(strings.TrimSpace(sc.ActivePathNodeID))
+	}
+	waitpointActive := threadHasActiveWaitpoint(ctx, deps, thread, in.UserID)
+	if waitpointActive {
+		actionScope.IntakePending = threadPendingWaitpointKind(ctx, deps, thread, in.UserID) == waitcfg.PathIntakeStructureKind
+	}
+	actions := exposedChatActions(actionScope)
+
 	route := chatRouteDecision{Route: "product"}
-	if !threadHasActiveWaitpoint(ctx, deps, thread, in.UserID) {
-		if r, err := routeChatMessage(ctx, deps, thread, userText, recent); err == nil {
+	if !waitpointActive || len(actions) > 0 {
+		if r, err := routeChatMessage(ctx, deps, thread, userText, recent, chatRouteTools(actions, !waitpointActive)); err == nil {
 			route = r
 		}
 	}
+	actionCalls, jobCalls := splitChatToolCalls(route.ToolCalls)
+	if waitpointActive && (route.Route != "tool" || len(actionCalls) == 0) {
+		route = chatRouteDecision{Route: "product"}
+	}
+	// Doc patch previews need the planner's on-screen block, so they run from the edit turn.
+	var editCall *chatToolCall
+	if strings.EqualFold(strings.TrimSpace(route.Route), "tool") {
+		kept := actionCalls[:0]
+		for i := range actionCalls {
+			if actionCalls[i].ToolName == chatActionDocPatchPreview {
+				editCall = &actionCalls[i]
+				continue
+			}
+			kept = append(kept, actionCalls[i])
+		}
+		actionCalls = kept
+		if editCall != nil && len(actionCalls) == 0 && len(jobCalls) == 0 {
+			route.Route = "product"
+		}
+	}
 
 	var (
 		instructions     string
@@ -171,10 +207,15 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		trace            map[string]any
 		aiClient         openai.Client
 		useConversation  bool
-		evidenceSources  []EvidenceSource
 		selectedEvidence []EvidenceSource
 		evidenceText     string
 		evidenceBudget   int
+
+		progressive progressiveConfig
+		skeleton    *productPlan
+		served      productPlan
+		buildFull   func(ctx context.Context) (productPlan, error)
+		contextPlan string
 	)
 	trace = map[string]any{}
 	aiClient = deps.AI
@@ -191,9 +232,20 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		trace["context_messages"] = 6
 		useConversation = false
 	case "tool":
-		toolRes, err := executeChatToolCalls(ctx, deps, thread, route.ToolCalls)
-		if err != nil {
-			return out, err
+		toolRes := toolExecResult{Metadata: map[string]any{}}
+		var records []chatActionRecord
+		if len(actionCalls) > 0 {
+			records = runChatActionCalls(ctx, deps, actionScope, actionCalls)
+			toolRes.Text = chatActionReply(records)
+		}
+		if len(jobCalls) > 0 || len(actionCalls) == 0 {
+			jobRes, err := executeChatToolCalls(ctx, deps, thread, jobCalls)
+			if err != nil {
+				return out, err
+			}
+			toolRes.Metadata = jobRes.Metadata
+			toolRes.EnqueuedIDs = jobRes.EnqueuedIDs
+			toolRes.Text = strings.TrimSpace(strings.Join([]string{toolRes.Text, jobRes.Text}, "\n"))
 		}
 		// Persist tool metadata into assistant message and finish without streaming.
 		meta := map[string]any{
@@ -203,6 +255,10 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		for k, v := range toolRes.Metadata {
 			meta[k] = v
 		}
+		if len(records) > 0 {
+			meta["tool_executions"] = records
+			trace["tool_executions"] = records
+		}
 		metaJSON, _ := json.Marshal(meta)
 		_ = deps.Messages.UpdateFields(dbc, in.AssistantMessageID, map[string]interface{}{
 			"content":    toolRes.Text,
@@ -245,7 +301,7 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		}
 		return out, nil
 	default:
-		plan, err := BuildContextPlan(ctx, ContextPlanDeps{
+		planner := ContextPlanner{Deps: ContextPlanDeps{
 			DB:        deps.DB,
 			AI:        deps.AI,
 			Vec:       deps.Vec,
@@ -257,74 +313,72 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 			NodeDocs:  deps.NodeDocs,
 			Concepts:  deps.Concepts,
 			Edges:     deps.Edges,
+			Evidence:  deps.Evidence,
 			Mastery:   deps.Mastery,
 			Models:    deps.Models,
 			Miscon:    deps.Miscon,
 			Sessions:  deps.Sessions,
-		}, ContextPlanInput{
-			UserID:   in.UserID,
-			Thread:   thread,
-			State:    state,
-			UserText: userText,
-			UserMsg:  &userMsg,
-		})
-		if err != nil {
-			return out, err
+			Outlines:  deps.Outlines,
+			Web:       deps.Web,
+			Focus:     deps.Focus,
+			Log:       deps.Log,
+
+			BudgetStats: deps.BudgetStats,
+			Profiles:    deps.Profiles,
+		}}
+		planIn := ContextPlanInput{
+			UserID:        in.UserID,
+			Thread:        thread,
+			State:         state,
+			UserText:      userText,
+			UserMsg:       &userMsg,
+			Verbosity:     answerVerbosityFromMessage(&userMsg),
+			DisabledLanes: disabledLanesFromMessage(&userMsg),
 		}
-		if editRes := maybeHandleEditRequest(ctx, deps, in, thread, plan, userText); editRes.Handled {
-			if editRes.Err != nil {
-				return out, editRes.Err
+		// Edit turns and verbatim material quotes need the full plan before anything is said.
+		progressive = resolveProgressiveConfig()
+		if progressive.Enabled && editCall == nil && classifyContextRoute(userText).Mode != "edit" && !wantsMaterialQuotes(userText) {
+			if skel, err := planner.BuildSkeleton(ctx, planIn); err == nil {
+				skeleton = &productPlan{Plan: skel, Instructions: skel.Instructions, UserPayload: skel.UserPayload, Trace: skel.Trace}
+				served = *skeleton
 			}
-			if err := finalizeImmediateReply(ctx, deps, in, editRes.Reply, editRes.Meta); err != nil {
+		}
+		if skeleton == nil {
+			plan, err := planner.BuildFull(ctx, planIn)
+			if err != nil {
 				return out, err
 			}
-			out.AssistantText = strings.TrimSpace(editRes.Reply)
-			return out, nil
+			actionScope.EditTarget = plan.EditTarget
+			if editRes := maybeHandleEditRequest(ctx, deps, actionScope, plan, userText, editCall); editRes.Handled {
+				if editRes.Err != nil {
+					return out, editRes.Err
+				}
+				if err := finalizeImmediateReply(ctx, deps, in, editRes.Reply, editRes.Meta); err != nil {
+					return out, err
+				}
+				out.AssistantText = strings.TrimSpace(editRes.Reply)
+				return out, nil
+			}
+			served = prepareProductPlan(ctx, deps.AI, userText, plan)
+			contextPlan = "full"
 		}
-		instructions = plan.Instructions
-		userPayload = plan.UserPayload
-		trace = plan.Trace
+		instructions = served.Instructions
+		userPayload = served.UserPayload
+		trace = served.Trace
 		if trace == nil {
 			trace = map[string]any{}
 		}
 		trace["route"] = "product"
-		evidenceSources = plan.EvidenceSources
-		evidenceBudget = plan.EvidenceTokenBudget
-		if len(evidenceSources) > 0 {
-			selected, etrace := selectEvidenceSources(ctx, deps.AI, userText, evidenceSources)
-			if len(etrace) > 0 {
-				trace["evidence_select"] = etrace
-			}
-			if len(selected) == 0 {
-				selected = evidenceSources
-				trace["evidence_select_fallback"] = true
-			}
-			selectedEvidence = selected
-			if wantsVerbatimQuote(userText) && wantsMaterialQuotes(userText) {
-				matSources := filterQuoteSources(evidenceSources, "materials")
-				if len(matSources) > 0 {
-					seen := map[string]bool{}
-					merged := make([]EvidenceSource, 0, len(selectedEvidence)+len(matSources))
-					for _, s := range selectedEvidence {
-						if strings.TrimSpace(s.ID) != "" {
-							seen[s.ID] = true
-						}
-						merged = append(merged, s)
-					}
-					for _, s := range matSources {
-						if strings.TrimSpace(s.ID) == "" || seen[s.ID] {
-							continue
-						}
-						merged = append(merged, s)
-					}
-					selectedEvidence = merged
-					trace["evidence_select_materials_forced"] = true
+		selectedEvidence = served.SelectedEvidence
+		evidenceText = served.EvidenceText
+		evidenceBudget = served.EvidenceBudget
+		if skeleton != nil {
+			buildFull = func(fctx context.Context) (productPlan, error) {
+				plan, err := planner.BuildFull(fctx, planIn)
+				if err != nil {
+					return productPlan{}, err
 				}
-			}
-			evidenceText = renderEvidenceSources(selectedEvidence, plan.EvidenceTokenBudget)
-			if strings.TrimSpace(evidenceText) != "" {
-				instructions = strings.TrimSpace(instructions) + "\n\n## Evidence Sources (use for factual claims)\n" + evidenceText + "\n\nWhen stating facts or quoting, add citation markers like [[source:ID]]."
-				trace["evidence_sources"] = len(selected)
+				return prepareProductPlan(fctx, deps.AI, userText, plan), nil
 			}
 		}
 	}
@@ -436,11 +490,54 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 		flushDB()
 	}
 
-	var text string
+	streamStateless := func(sctx context.Context, instr string, payload string, cb func(string)) (string, error) {
+		return aiClient.StreamText(sctx, instr, payload, cb)
+	}
+	streamTurn := streamStateless
 	if useConversation && strings.TrimSpace(conversationID) != "" {
-		text, err = aiClient.StreamTextInConversation(ctx, conversationID, instructions, userPayload, onDelta)
+		streamTurn = func(sctx context.Context, instr string, payload string, cb func(string)) (string, error) {
+			return aiClient.StreamTextInConversation(sctx, conversationID, instr, payload, cb)
+		}
+	}
+
+	var text string
+	if skeleton != nil {
+		// The skeleton generation may be thrown away, so it never writes to the conversation; an
+		// upgraded answer is generated in it, and a downgraded one is appended afterwards.
+		onRestart := func() {
+			full.Reset()
+			pending.Reset()
+			pendingBytes = 0
+			lastFlushSize = 0
+			resetAssistantStream(ctx, deps, in, map[string]any{"restart_reason": "context_upgrade"})
+		}
+		var recordDowngrade func(context.Context, string, string) error
+		if useConversation && strings.TrimSpace(conversationID) != "" {
+			recordDowngrade = func(rctx context.Context, payload string, answer string) error {
+				return aiClient.AppendConversationTurn(rctx, conversationID, payload, answer)
+			}
+		}
+		var res progressiveOutcome
+		res, err = streamProgressive(ctx, progressive, *skeleton, buildFull, streamStateless, streamTurn, onDelta, onRestart, recordDowngrade)
+		text = res.Text
+		served = res.Served
+		contextPlan = res.Phase
+		selectedEvidence = served.SelectedEvidence
+		evidenceText = served.EvidenceText
+		evidenceBudget = served.EvidenceBudget
+		trace = map[string]any{}
+		for k, v := range served.Trace {
+			trace[k] = v
+		}
+		trace["route"] = "product"
+		trace["progressive"] = res.Trace
+		if b, merr := json.Marshal(trace); merr == nil {
+			_ = deps.Turns.UpdateFields(dbc, in.UserID, in.TurnID, map[string]interface{}{
+				"retrieval_trace": datatypes.JSON(b),
+			})
+		}
 	} else {
-		text, err = aiClient.StreamText(ctx, instructions, userPayload, onDelta)
+		text, err = streamTurn(ctx, instructions, userPayload, onDelta)
 	}
 	if err != nil {
 		flushNotify()
@@ -541,21 +638,7 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 	}
 
 	// Persist final message content + status.
-	meta := map[string]any{}
-	if len(citations) > 0 {
-		meta["citations"] = citations
-	}
-	if len(selectedEvidence) > 0 {
-		ids := make([]string, 0, len(selectedEvidence))
-		for _, s := range selectedEvidence {
-			if strings.TrimSpace(s.ID) != "" {
-				ids = append(ids, s.ID)
-			}
-		}
-		meta["evidence_ids"] = ids
-	}
-	meta["quote_verified"] = quoteVerified
-	metaJSON, _ := json.Marshal(meta)
+	metaJSON, _ := json.Marshal(assistantMessageMeta(contextPlan, selectedEvidence, citations, quoteVerified, planTraceBuildInProgress(trace)))
 	if err := deps.Messages.UpdateFields(dbc, in.AssistantMessageID, map[string]interface{}{
 		"content":    text,
 		"status":     MessageStatusDone,
@@ -596,3 +679,129 @@ func Respond(ctx context.Context, deps RespondDeps, in RespondInput) (RespondOut
 
 	return out, nil
 }
+
+// productPlan is a context plan with its evidence selected and rendered into the instructions.
+type productPlan struct {
+	Plan             ContextPlanOutput
+	Instructions     string
+	UserPayload      string
+	Trace            map[string]any
+	SelectedEvidence []EvidenceSource
+	EvidenceText     string
+	EvidenceBudget   int
+}
+
+func prepareProductPlan(ctx context.Context, ai openai.Client, userText string, plan ContextPlanOutput) productPlan {
+	out := productPlan{
+		Plan:           plan,
+		Instructions:   plan.Instructions,
+		UserPayload:    plan.UserPayload,
+		Trace:          plan.Trace,
+		EvidenceBudget: plan.EvidenceTokenBudget,
+	}
+	if out.Trace == nil {
+		out.Trace = map[string]any{}
+	}
+	evidenceSources := plan.EvidenceSources
+	if len(evidenceSources) == 0 {
+		return out
+	}
+	selected, etrace := selectEvidenceSources(ctx, ai, userText, evidenceSources)
+	if len(etrace) > 0 {
+		out.Trace["evidence_select"] = etrace
+	}
+	if len(selected) == 0 {
+		selected = evidenceSources
+		out.Trace["evidence_select_fallback"] = true
+	}
+	selectedEvidence := selected
+	if wantsVerbatimQuote(userText) && wantsMaterialQuotes(userText) {
+		matSources := filterQuoteSources(evidenceSources, "materials")
+		if len(matSources) > 0 {
+			seen := map[string]bool{}
+			merged := make([]EvidenceSource, 0, len(selectedEvidence)+len(matSources))
+			for _, s := range selectedEvidence {
+				if strings.TrimSpace(s.ID) != "" {
+					seen[s.ID] = true
+				}
+				merged = append(merged, s)
+			}
+			for _, s := range matSources {
+				if strings.TrimSpace(s.ID) == "" || seen[s.ID] {
+					continue
+				}
+				merged = append(merged, s)
+			}
+			selectedEvidence = merged
+			out.Trace["evidence_select_materials_forced"] = true
+		}
+	}
+	out.SelectedEvidence = selectedEvidence
+	out.EvidenceText = renderEvidenceSources(selectedEvidence, plan.EvidenceTokenBudget)
+	if strings.TrimSpace(out.EvidenceText) != "" {
+		out.Instructions = strings.TrimSpace(out.Instructions) + "\n\n## Evidence Sources (use for factual claims)\n" + out.EvidenceText + "\n\nWhen stating facts or quoting, add citation markers like [[source:ID]]."
+		if hasWebEvidence(selectedEvidence) {
+			out.Instructions += "\nSources of type web are unverified external pages, not the user's materials: cite each with [[source:ID]], name its site and URL explicitly, and never present them as coming from the user's materials."
+		}
+		out.Trace["evidence_sources"] = len(selected)
+	}
+	return out
+}
+
+// assistantMessageMeta describes the answer against the plan that served it, so a downgraded
+// turn never claims evidence that only the discarded full plan had.
+func assistantMessageMeta(contextPlan string, selectedEvidence []EvidenceSource, citations []EvidenceCitation, quoteVerified bool, buildInProgress bool) map[string]any {
+	meta := map[string]any{}
+	if contextPlan != "" {
+		meta["context_plan"] = contextPlan
+	}
+	if len(citations) > 0 {
+		meta["citations"] = citations
+	}
+	if len(selectedEvidence) > 0 {
+		ids := make([]string, 0, len(selectedEvidence))
+		for _, s := range selectedEvidence {
+			if strings.TrimSpace(s.ID) != "" {
+				ids = append(ids, s.ID)
+			}
+		}
+		meta["evidence_ids"] = ids
+	}
+	meta["quote_verified"] = quoteVerified
+	if buildInProgress {
+		// Answers given mid-build see a partial path; quality analysis excludes them.
+		meta["build_in_progress"] = true
+	}
+	return meta
+}
+
+// resetAssistantStream clears the assistant placeholder and re-announces it so clients drop any
+// streamed text and restart rendering.
+func resetAssistantStream(ctx context.Context, deps RespondDeps, in RespondInput, extra map[string]any) {
+	dbc := dbctx.Context{Ctx: ctx, Tx: deps.DB}
+	_ = deps.Messages.UpdateFields(dbc, in.AssistantMessageID, map[string]interface{}{
+		"content":    "",
+		"status":     MessageStatusStreaming,
+		"updated_at": time.Now().UTC(),
+	})
+	if deps.Notify == nil {
+		return
+	}
+	var asst types.ChatMessage
+	_ = deps.DB.WithContext(ctx).
+		Model(&types.ChatMessage{}).
+		Where("id = ? AND thread_id = ? AND user_id = ?", in.AssistantMessageID, in.ThreadID, in.UserID).
+		First(&asst).Error
+	if asst.ID == uuid.Nil {
+		return
+	}
+	payload := map[string]any{
+		"turn_id": in.TurnID.String(),
+		"attempt": in.Attempt,
+		"job_id":  in.JobID.String(),
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	deps.Notify.MessageCreated(in.UserID, in.ThreadID, &asst, payload)
+}
@@ -21,16 +21,18 @@ type editHandleResult struct {
 	Err     error
 }
 
+// maybeHandleEditRequest handles edit turns by running the enqueue_doc_patch_preview action
+// against the planner's edit target. call is the router's action call when it picked the tool.
 func maybeHandleEditRequest(
 	ctx context.Context,
 	deps RespondDeps,
-	in RespondInput,
-	thread *types.ChatThread,
+	scope chatActionScope,
 	plan ContextPlanOutput,
 	userText string,
+	call *chatToolCall,
 ) editHandleResult {
 	out := editHandleResult{}
-	if !strings.EqualFold(strings.TrimSpace(plan.Mode), "edit") {
+	if call == nil && !strings.EqualFold(strings.TrimSpace(plan.Mode), "edit") {
 		return out
 	}
 	if isWaitpointCommand(userText) {
@@ -56,28 +58,34 @@ func maybeHandleEditRequest(
 		out.Meta = map[string]any{"kind": "node_doc_edit_missing_target"}
 		return out
 	}
-	payload := map[string]any{
-		"thread_id":       thread.ID.String(),
-		"path_node_id":    nodeID.String(),
-		"block_id":        strings.TrimSpace(plan.EditTarget.BlockID),
-		"block_index":     plan.EditTarget.BlockIndex,
-		"action":          "rewrite",
-		"citation_policy": "reuse_only",
-		"instruction":     strings.TrimSpace(userText),
+
+	instruction := strings.TrimSpace(userText)
+	if call != nil {
+		if v, ok := call.Arguments["instruction"].(string); ok && strings.TrimSpace(v) != "" {
+			instruction = strings.TrimSpace(v)
+		}
 	}
-	entityID := nodeID
-	job, err := deps.Jobs.Enqueue(dbctx.Context{Ctx: ctx, Tx: deps.DB}, in.UserID, "node_doc_edit", "path_node", &entityID, payload)
-	if err != nil {
+	scope.Mode = "edit"
+	scope.EditTarget = plan.EditTarget
+	rec := runChatAction(ctx, deps, scope, chatActionDocPatchPreview, map[string]any{
+		"instruction":  instruction,
+		"path_node_id": nodeID.String(),
+		"block_id":     strings.TrimSpace(plan.EditTarget.BlockID),
+	})
+	if rec.Status != chatActionStatusOK && !(rec.Replayed && rec.Status == chatActionStatusRunning) {
 		out.Reply = "I couldn’t start the edit draft. Please try again."
-		out.Meta = map[string]any{"kind": "node_doc_edit_enqueue_failed", "error": err.Error()}
+		out.Meta = map[string]any{"kind": "node_doc_edit_enqueue_failed", "error": rec.Error, "tool_executions": []chatActionRecord{rec}}
 		return out
 	}
 	out.Reply = "Drafting a revision for the current block. I’ll show a diff for confirmation."
 	out.Meta = map[string]any{
-		"kind":         "node_doc_edit_pending",
-		"job_id":       job.ID.String(),
-		"path_node_id": nodeID.String(),
-		"block_id":     strings.TrimSpace(plan.EditTarget.BlockID),
+		"kind":            "node_doc_edit_pending",
+		"path_node_id":    nodeID.String(),
+		"block_id":        strings.TrimSpace(plan.EditTarget.BlockID),
+		"tool_executions": []chatActionRecord{rec},
+	}
+	if jobID, ok := rec.Result["job_id"]; ok {
+		out.Meta["job_id"] = jobID
 	}
 	return out
 }
@@ -36,6 +36,8 @@ type retrievalCandidate struct {
 	LexicalHit  bool
 
 	RerankScore float64
+	// ConceptBoost is added to the score when the doc cites evidence of the active node's concepts.
+	ConceptBoost float64
 
 	InjectionFlag bool
 	DropReason    string
@@ -88,6 +90,7 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 	candidates := map[uuid.UUID]*retrievalCandidate{}
 	degradedDense := false
 	degradedLex := false
+	dimGuard := newEmbeddingDimGuard(len(qEmb))
 
 	addCandidate := func(c *retrievalCandidate) {
 		if c == nil || c.Doc == nil {
@@ -112,17 +115,27 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 		}
 	}
 
-	addScope := func(scope string, scopeID *uuid.UUID) error {
+	// sourceID optionally narrows a scope to docs derived from one source (the node scope).
+	addScope := func(scope string, scopeID *uuid.UUID, sourceID *uuid.UUID) error {
 		scopeTrace := map[string]any{
 			"scope": scope,
 		}
 		if scopeID != nil && *scopeID != uuid.Nil {
 			scopeTrace["scope_id"] = scopeID.String()
 		}
+		if sourceID != nil && *sourceID == uuid.Nil {
+			sourceID = nil
+		}
+		if sourceID != nil {
+			scopeTrace["source_id"] = sourceID.String()
+		}
 
 		// Dense: Pinecone first, SQL fallback if Pinecone is unavailable/degraded.
 		denseStart := time.Now()
-		if deps.Vec != nil && len(qEmb) > 0 {
+		if dimGuard.denseSkipped {
+			scopeTrace["dense_skipped"] = "embedding_dim_mismatch"
+		}
+		if deps.Vec != nil && len(qEmb) > 0 && !dimGuard.denseSkipped {
 			filter := map[string]any{
 				"user_id": thread.UserID.String(),
 				"scope":   scope,
@@ -130,6 +143,9 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 			if scopeID != nil && *scopeID != uuid.Nil {
 				filter["scope_id"] = scopeID.String()
 			}
+			if sourceID != nil {
+				filter["source_id"] = sourceID.String()
+			}
 
 			denseCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
 			matches, qErr := deps.Vec.QueryMatches(denseCtx, chatIndex.ChatUserNamespace(thread.UserID), qEmb, maxCandidatesPerScope, filter)
@@ -154,7 +170,7 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 					scoreByID[uid] = m.Score
 				}
 				if len(docIDs) > 0 {
-					rows, err := deps.Docs.GetByIDs(dbctx.Context{Ctx: ctx, Tx: deps.DB}, thread.UserID, docIDs)
+					rows, err := deps.Docs.GetByIDsOrdered(dbctx.Context{Ctx: ctx, Tx: deps.DB}, thread.UserID, docIDs)
 					if err != nil {
 						return err
 					}
@@ -173,6 +189,9 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 						} else if d.ScopeID != nil && *d.ScopeID != uuid.Nil {
 							continue
 						}
+						if sourceID != nil && (d.SourceID == nil || *d.SourceID != *sourceID) {
+							continue
+						}
 						addCandidate(&retrievalCandidate{
 							Doc:        d,
 							DenseHit:   true,
@@ -184,7 +203,7 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 		}
 
 		// SQL dense fallback if Pinecone degraded/unavailable and we have embeddings.
-		if (deps.Vec == nil || degradedDense) && len(qEmb) > 0 && deps.DB != nil {
+		if (deps.Vec == nil || degradedDense) && len(qEmb) > 0 && deps.DB != nil && !dimGuard.denseSkipped {
 			sqlStart := time.Now()
 			limit := 1200
 			var rows []*types.ChatDoc
@@ -196,6 +215,9 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 			} else {
 				q = q.Where("scope_id IS NULL")
 			}
+			if sourceID != nil {
+				q = q.Where("source_id = ?", *sourceID)
+			}
 			if len(docTypes) > 0 {
 				q = q.Where("doc_type IN ?", docTypes)
 			}
@@ -211,11 +233,17 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 					continue
 				}
 				emb, _ := chatrepo.ParseEmbeddingJSON(d.Embedding)
-				if len(emb) == 0 || len(emb) != len(qEmb) {
+				if !dimGuard.check(d.ID, emb) {
 					continue
 				}
 				scoredRows = append(scoredRows, scored{d: d, score: cosine(qEmb, emb)})
 			}
+			// Stored vectors from a previous embedding model: dense scores would all be 0, so
+			// stop dense search for the remaining scopes and retrieve lexically.
+			if dimGuard.incompatible() {
+				dimGuard.denseSkipped = true
+				degradedDense = true
+			}
 			sort.Slice(scoredRows, func(i, j int) bool { return scoredRows[i].score > scoredRows[j].score })
 			if len(scoredRows) > maxCandidatesPerScope {
 				scoredRows = scoredRows[:maxCandidatesPerScope]
@@ -237,6 +265,7 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 			UserID:   thread.UserID,
 			Scope:    scope,
 			ScopeID:  scopeID,
+			SourceID: sourceID,
 			DocTypes: docTypes,
 			Query:    query,
 			Limit:    maxCandidatesPerScope,
@@ -266,19 +295,31 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 		return nil
 	}
 
-	// Expansion order: thread -> path -> user (subject to scope plan).
+	// Expansion order: thread -> node -> path -> user (subject to scope plan).
 	if scopes.ScopeThread {
-		if err := addScope(ScopeThread, &thread.ID); err != nil {
+		if err := addScope(ScopeThread, &thread.ID, nil); err != nil {
 			return out, err
 		}
 	}
-	if scopes.ScopePath && thread.PathID != nil && *thread.PathID != uuid.Nil {
-		if err := addScope(ScopePath, thread.PathID); err != nil {
+	hasPath := thread.PathID != nil && *thread.PathID != uuid.Nil
+	nodeHits := 0
+	if scopes.ScopeNode && scopes.NodeID != uuid.Nil && hasPath {
+		before := len(candidates)
+		nodeID := scopes.NodeID
+		if err := addScope(ScopePath, thread.PathID, &nodeID); err != nil {
+			return out, err
+		}
+		nodeHits = len(candidates) - before
+		out.Trace["node_scope"] = map[string]any{"path_node_id": nodeID.String(), "hits": nodeHits}
+	}
+	// The node scope replaces the path scope; widen only when the node has no indexed docs.
+	if scopes.ScopePath && hasPath && nodeHits == 0 {
+		if err := addScope(ScopePath, thread.PathID, nil); err != nil {
 			return out, err
 		}
 	}
 	if scopes.ScopeUser && len(candidates) < 30 {
-		if err := addScope(ScopeUser, nil); err != nil {
+		if err := addScope(ScopeUser, nil, nil); err != nil {
 			return out, err
 		}
 	}
@@ -335,6 +376,13 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 	if degradedLex {
 		out.Trace["degraded_lexical"] = true
 	}
+	if tr := dimGuard.trace(); tr != nil {
+		out.Trace["embedding_dim_mismatch"] = tr
+	}
+
+	if tr := applyConceptBoost(ctx, deps, all, scopes.BoostConceptIDs); tr != nil {
+		out.Trace["concept_boost"] = tr
+	}
 
 	if len(all) == 0 {
 		if droppedInjection > 0 {
@@ -411,6 +459,7 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 		case ScopePath:
 			base += 2
 		}
+		base += c.ConceptBoost
 		c.RerankScore = base
 
 		if base > bestScore {
@@ -421,8 +470,15 @@ func hybridRetrieve(ctx context.Context, deps ContextPlanDeps, thread *types.Cha
 		}
 
 		emb, _ := chatrepo.ParseEmbeddingJSON(c.Doc.Embedding)
+		if len(emb) > 0 && !dimGuard.check(c.Doc.ID, emb) {
+			// MMR diversity can't compare vectors of another dimension; rank this doc by score only.
+			emb = nil
+		}
 		scored = append(scored, scoredDoc{Doc: c.Doc, Score: base, Emb: emb})
 	}
+	if tr := dimGuard.trace(); tr != nil {
+		out.Trace["embedding_dim_mismatch"] = tr
+	}
 
 	out.Trace["rerank_top_score"] = bestScore
 
@@ -0,0 +1,193 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const (
+	// conceptBoostPerWeight is the score added per unit of ConceptEvidence weight a candidate cites.
+	conceptBoostPerWeight = 3.0
+	// conceptBoostMax caps the boost so grounding can break ties but not outrank relevance.
+	conceptBoostMax = 8.0
+	// conceptBoostTraceDocs bounds the per-doc entries recorded in the trace.
+	conceptBoostTraceDocs = 20
+)
+
+// conceptIDsForKeys resolves concept keys against the path's concepts.
+func conceptIDsForKeys(concepts []*types.Concept, keys []string) []uuid.UUID {
+	byKey := map[string]uuid.UUID{}
+	for _, c := range concepts {
+		if c == nil || c.ID == uuid.Nil {
+			continue
+		}
+		if key := strings.TrimSpace(strings.ToLower(c.Key)); key != "" {
+			byKey[key] = c.ID
+		}
+	}
+	seen := map[uuid.UUID]bool{}
+	out := make([]uuid.UUID, 0, len(keys))
+	for _, k := range keys {
+		id, ok := byKey[strings.TrimSpace(strings.ToLower(k))]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+// applyConceptBoost sets ConceptBoost on unit block candidates whose citations include chunks
+// that ground the given concepts (ConceptEvidence), weighted by evidence weight and capped at
+// conceptBoostMax. Other doc types carry no chunk citations and are never boosted. Best-effort:
+// load errors are traced and leave every boost at zero.
+func applyConceptBoost(ctx context.Context, deps ContextPlanDeps, candidates []*retrievalCandidate, conceptIDs []uuid.UUID) map[string]any {
+	if len(conceptIDs) == 0 || len(candidates) == 0 || deps.Evidence == nil || deps.NodeDocs == nil {
+		return nil
+	}
+	trace := map[string]any{"concepts": len(conceptIDs)}
+	dbc := dbctx.Context{Ctx: ctx, Tx: deps.DB}
+
+	evidence, err := deps.Evidence.GetByConceptIDs(dbc, conceptIDs)
+	if err != nil {
+		trace["evidence_err"] = err.Error()
+		return trace
+	}
+	weightByChunk := map[string]float64{}
+	for _, ev := range evidence {
+		if ev == nil || ev.MaterialChunkID == uuid.Nil {
+			continue
+		}
+		w := ev.Weight
+		if w <= 0 {
+			w = 1
+		}
+		weightByChunk[ev.MaterialChunkID.String()] += w
+	}
+	trace["evidence_chunks"] = len(weightByChunk)
+	if len(weightByChunk) == 0 {
+		return trace
+	}
+
+	nodeSet := map[uuid.UUID]bool{}
+	for _, c := range candidates {
+		if c != nil && c.Doc != nil && strings.TrimSpace(c.Doc.DocType) == DocTypePathUnitBlock && c.Doc.SourceID != nil && *c.Doc.SourceID != uuid.Nil {
+			nodeSet[*c.Doc.SourceID] = true
+		}
+	}
+	if len(nodeSet) == 0 {
+		return trace
+	}
+	nodeIDs := make([]uuid.UUID, 0, len(nodeSet))
+	for id := range nodeSet {
+		nodeIDs = append(nodeIDs, id)
+	}
+	docRows, err := deps.NodeDocs.GetLatestByPathNodeIDs(dbc, nodeIDs)
+	if err != nil {
+		trace["node_docs_err"] = err.Error()
+		return trace
+	}
+	blocksByNode := map[uuid.UUID]map[string]map[string]any{}
+	for _, row := range docRows {
+		if row == nil || len(row.DocJSON) == 0 {
+			continue
+		}
+		var doc struct {
+			Blocks []map[string]any `json:"blocks"`
+		}
+		if json.Unmarshal(row.DocJSON, &doc) != nil {
+			continue
+		}
+		blocks := map[string]map[string]any{}
+		for i, b := range doc.Blocks {
+			if b == nil {
+				continue
+			}
+			id := stringFromAnyCtx(b["id"])
+			if id == "" {
+				id = strconv.Itoa(i)
+			}
+			blocks[id] = b
+		}
+		blocksByNode[row.PathNodeID] = blocks
+	}
+
+	type boosted struct {
+		DocID  string
+		Boost  float64
+		Chunks int
+	}
+	rows := make([]boosted, 0)
+	for _, c := range candidates {
+		if c == nil || c.Doc == nil || strings.TrimSpace(c.Doc.DocType) != DocTypePathUnitBlock || c.Doc.SourceID == nil {
+			continue
+		}
+		blockID := parseBlockIDFromText(c.Doc.Text)
+		if blockID == "" {
+			blockID = strconv.Itoa(c.Doc.ChunkIndex)
+		}
+		block := blocksByNode[*c.Doc.SourceID][blockID]
+		if block == nil {
+			continue
+		}
+		var weight float64
+		chunks := 0
+		for _, chunkID := range blockCitationChunkIDs(block) {
+			if w, ok := weightByChunk[chunkID]; ok {
+				weight += w
+				chunks++
+			}
+		}
+		if chunks == 0 {
+			continue
+		}
+		c.ConceptBoost = math.Min(conceptBoostMax, weight*conceptBoostPerWeight)
+		rows = append(rows, boosted{DocID: c.Doc.ID.String(), Boost: c.ConceptBoost, Chunks: chunks})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Boost != rows[j].Boost {
+			return rows[i].Boost > rows[j].Boost
+		}
+		return rows[i].DocID < rows[j].DocID
+	})
+	trace["boosted"] = len(rows)
+	if len(rows) > conceptBoostTraceDocs {
+		rows = rows[:conceptBoostTraceDocs]
+	}
+	docs := make([]any, 0, len(rows))
+	for _, r := range rows {
+		docs = append(docs, map[string]any{"doc_id": r.DocID, "boost": r.Boost, "cited_evidence_chunks": r.Chunks})
+	}
+	trace["docs"] = docs
+	return trace
+}
+
+// blockCitationChunkIDs returns the unique chunk IDs a doc block cites.
+func blockCitationChunkIDs(block map[string]any) []string {
+	arr, _ := block["citations"].([]any)
+	seen := map[string]bool{}
+	out := make([]string, 0, len(arr))
+	for _, x := range arr {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		id := strings.TrimSpace(stringFromAnyCtx(m["chunk_id"]))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	chatrepo "github.com/yungbote/neurobridge-backend/internal/data/repos/chat"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestApplyNodeRetrievalScope(t *testing.T) {
+	pathID := uuid.New()
+	nodeID := uuid.New()
+	thread := &types.ChatThread{ID: uuid.New(), PathID: &pathID}
+	session := &sessionContextSnapshot{ActivePathID: pathID.String(), ActivePathNodeID: nodeID.String()}
+	base := retrievalPlan{ScopeThread: true, ScopePath: true}
+
+	onScreen := classifyContextRoute("is the current block accurate?")
+	got := applyNodeRetrievalScope(base, onScreen, thread, session)
+	if !got.ScopeNode || got.NodeID != nodeID || !got.ScopePath {
+		t.Fatalf("viewport question should scope to the node: %+v", got)
+	}
+
+	broad := classifyContextRoute("how does this path fit together overall?")
+	if got := applyNodeRetrievalScope(base, broad, thread, session); got.ScopeNode {
+		t.Fatalf("broad question should not scope to the node: %+v", got)
+	}
+
+	// Router-requested node scope still needs an active node on the thread's path.
+	requested := base
+	requested.ScopeNode = true
+	if got := applyNodeRetrievalScope(requested, broad, thread, session); !got.ScopeNode {
+		t.Fatalf("requested node scope should apply: %+v", got)
+	}
+	other := &sessionContextSnapshot{ActivePathID: uuid.NewString(), ActivePathNodeID: nodeID.String()}
+	if got := applyNodeRetrievalScope(requested, onScreen, thread, other); got.ScopeNode || got.NodeID != uuid.Nil {
+		t.Fatalf("node on another path should be ignored: %+v", got)
+	}
+	if got := applyNodeRetrievalScope(requested, onScreen, thread, nil); got.ScopeNode {
+		t.Fatalf("no session should clear the node scope: %+v", got)
+	}
+}
+
+// scopedLexicalDocs answers lexical search with one doc per (scope, source) and records queries.
+type scopedLexicalDocs struct {
+	repos.ChatDocRepo
+	nodeDocs bool
+	queries  []chatrepo.ChatLexicalQuery
+}
+
+func (r *scopedLexicalDocs) LexicalSearchHits(dbc dbctx.Context, q chatrepo.ChatLexicalQuery) ([]chatrepo.ChatLexicalHit, error) {
+	r.queries = append(r.queries, q)
+	if q.Scope != ScopePath || (q.SourceID != nil && !r.nodeDocs) {
+		return nil, nil
+	}
+	doc := &types.ChatDoc{
+		ID:             uuid.New(),
+		UserID:         q.UserID,
+		DocType:        DocTypePathUnitBlock,
+		Scope:          q.Scope,
+		ScopeID:        q.ScopeID,
+		SourceID:       q.SourceID,
+		Text:           "Loops repeat a block of statements while a condition holds.",
+		ContextualText: "Loops repeat a block of statements while a condition holds.",
+	}
+	return []chatrepo.ChatLexicalHit{{Doc: doc, Rank: 0.5}}, nil
+}
+
+func TestHybridRetrieveNodeScope(t *testing.T) {
+	pathID := uuid.New()
+	nodeID := uuid.New()
+	thread := &types.ChatThread{ID: uuid.New(), UserID: uuid.New(), PathID: &pathID}
+	plan := retrievalPlan{ScopeThread: true, ScopePath: true, ScopeNode: true, NodeID: nodeID}
+
+	docs := &scopedLexicalDocs{nodeDocs: true}
+	out, err := hybridRetrieve(context.Background(), ContextPlanDeps{AI: budgetAI{}, Docs: docs}, thread, "how do loops work", plan)
+	if err != nil {
+		t.Fatalf("hybridRetrieve: %v", err)
+	}
+	if len(docs.queries) != 2 || docs.queries[1].SourceID == nil || *docs.queries[1].SourceID != nodeID {
+		t.Fatalf("expected thread + node-scoped queries, got %+v", docs.queries)
+	}
+	for _, d := range out.Docs {
+		if d.Scope == ScopePath && (d.SourceID == nil || *d.SourceID != nodeID) {
+			t.Fatalf("retrieved a path doc outside the active node: %+v", d)
+		}
+	}
+
+	// A node with no indexed docs widens back to the whole path.
+	docs = &scopedLexicalDocs{}
+	if _, err := hybridRetrieve(context.Background(), ContextPlanDeps{AI: budgetAI{}, Docs: docs}, thread, "how do loops work", plan); err != nil {
+		t.Fatalf("hybridRetrieve: %v", err)
+	}
+	if len(docs.queries) != 3 || docs.queries[2].SourceID != nil {
+		t.Fatalf("expected fallback to the unscoped path query, got %+v", docs.queries)
+	}
+}
+
+// evidenceBlockDocs returns two equally ranked unit blocks of one node; only p1 cites evidence.
+type evidenceBlockDocs struct {
+	repos.ChatDocRepo
+	nodeID uuid.UUID
+}
+
+func (r *evidenceBlockDocs) LexicalSearchHits(dbc dbctx.Context, q chatrepo.ChatLexicalQuery) ([]chatrepo.ChatLexicalHit, error) {
+	if q.Scope != ScopePath {
+		return nil, nil
+	}
+	hits := make([]chatrepo.ChatLexicalHit, 0, 2)
+	for i, blockID := range []string{"p1", "p2"} {
+		text := "Unit 1: Loops\nBlock ID: " + blockID + "\n\nLoops repeat a block of statements while a condition holds."
+		hits = append(hits, chatrepo.ChatLexicalHit{Doc: &types.ChatDoc{
+			ID: uuid.New(), UserID: q.UserID, DocType: DocTypePathUnitBlock, Scope: q.Scope, ScopeID: q.ScopeID,
+			SourceID: &r.nodeID, ChunkIndex: i, Text: text, ContextualText: text,
+		}, Rank: 0.5})
+	}
+	return hits, nil
+}
+
+type fixedEvidence struct {
+	repos.ConceptEvidenceRepo
+	rows []*types.ConceptEvidence
+}
+
+func (r fixedEvidence) GetByConceptIDs(dbc dbctx.Context, conceptIDs []uuid.UUID) ([]*types.ConceptEvidence, error) {
+	return r.rows, nil
+}
+
+type fixedNodeDocs struct {
+	repos.LearningNodeDocRepo
+	rows []*types.LearningNodeDoc
+}
+
+func (r fixedNodeDocs) GetLatestByPathNodeIDs(dbc dbctx.Context, ids []uuid.UUID) ([]*types.LearningNodeDoc, error) {
+	return r.rows, nil
+}
+
+func TestHybridRetrieveConceptBoost(t *testing.T) {
+	pathID := uuid.New()
+	nodeID := uuid.New()
+	conceptID := uuid.New()
+	chunkID := uuid.New()
+	thread := &types.ChatThread{ID: uuid.New(), UserID: uuid.New(), PathID: &pathID}
+	deps := ContextPlanDeps{
+		AI:       budgetAI{},
+		Docs:     &evidenceBlockDocs{nodeID: nodeID},
+		Evidence: fixedEvidence{rows: []*types.ConceptEvidence{{ConceptID: conceptID, MaterialChunkID: chunkID, Weight: 1}}},
+		NodeDocs: fixedNodeDocs{rows: []*types.LearningNodeDoc{{PathNodeID: nodeID, DocJSON: []byte(`{"blocks":[
+			{"id":"p1","type":"paragraph","md":"Loops.","citations":[{"chunk_id":"` + chunkID.String() + `"}]},
+			{"id":"p2","type":"paragraph","md":"Loops.","citations":[{"chunk_id":"` + uuid.NewString() + `"}]}]}`)}}},
+	}
+
+	out, err := hybridRetrieve(context.Background(), deps, thread, "how do loops work", retrievalPlan{ScopePath: true, BoostConceptIDs: []uuid.UUID{conceptID}})
+	if err != nil {
+		t.Fatalf("hybridRetrieve: %v", err)
+	}
+	if len(out.Docs) != 2 || parseBlockIDFromText(out.Docs[0].Text) != "p1" {
+		t.Fatalf("expected the evidence-citing block first, got %+v", out.Docs)
+	}
+	boost, _ := out.Trace["concept_boost"].(map[string]any)
+	docs, _ := boost["docs"].([]any)
+	if len(docs) != 1 || docs[0].(map[string]any)["doc_id"] != out.Docs[0].ID.String() || docs[0].(map[string]any)["boost"] != conceptBoostPerWeight {
+		t.Fatalf("concept_boost trace = %+v", boost)
+	}
+
+	// Without the option nothing is boosted or traced.
+	out, err = hybridRetrieve(context.Background(), deps, thread, "how do loops work", retrievalPlan{ScopePath: true})
+	if err != nil || out.Trace["concept_boost"] != nil {
+		t.Fatalf("unexpected boost without concepts: %v %+v", err, out.Trace["concept_boost"])
+	}
+}
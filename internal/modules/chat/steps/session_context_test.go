@@ -0,0 +1,87 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type fakeSessionStates struct {
+	repos.UserSessionStateRepo
+	bySession map[uuid.UUID]*types.UserSessionState
+	latest    *types.UserSessionState
+	bySIDHits int
+}
+
+func (f *fakeSessionStates) GetBySessionID(dbc dbctx.Context, sessionID uuid.UUID) (*types.UserSessionState, error) {
+	f.bySIDHits++
+	return f.bySession[sessionID], nil
+}
+
+func (f *fakeSessionStates) GetLatestByUserID(dbc dbctx.Context, userID uuid.UUID) (*types.UserSessionState, error) {
+	if f.latest != nil && f.latest.UserID == userID {
+		return f.latest, nil
+	}
+	return nil, nil
+}
+
+func TestResolveSessionContextFallsBackOnCorruptSessionCtx(t *testing.T) {
+	userID := uuid.New()
+	pathID, nodeID := uuid.New(), uuid.New()
+	latest := &types.UserSessionState{
+		SessionID:        uuid.New(),
+		UserID:           userID,
+		ActivePathID:     &pathID,
+		ActivePathNodeID: &nodeID,
+		LastSeenAt:       time.Now().UTC().Add(-time.Hour),
+	}
+	dbc := dbctx.Context{Ctx: context.Background()}
+
+	cases := []struct {
+		name     string
+		metadata string
+	}{
+		{"truncated_metadata", `{"session_ctx":{"session_id":"` + uuid.NewString() + `","active_path_id":`},
+		{"session_ctx_not_object", `{"session_ctx":42}`},
+		{"session_ctx_bad_json_string", `{"session_ctx":"{\"active_path_id\": nope"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sessions := &fakeSessionStates{latest: latest}
+			msg := &types.ChatMessage{ID: uuid.New(), Metadata: datatypes.JSON(tc.metadata)}
+			got, source, corrupt := resolveSessionContext(dbc, ContextPlanDeps{Sessions: sessions}, userID, msg)
+			if !corrupt {
+				t.Fatalf("corrupt session_ctx not detected")
+			}
+			if got == nil || source != "server" || got.ActivePathNodeID != nodeID.String() {
+				t.Fatalf("fallback = %+v (source %q), want the latest server session", got, source)
+			}
+			if sessions.bySIDHits != 0 {
+				t.Fatalf("session id from a corrupt payload was trusted")
+			}
+		})
+	}
+
+	// A well-formed session_ctx still wins when it is fresher than the server state.
+	sessions := &fakeSessionStates{latest: latest}
+	msg := &types.ChatMessage{Metadata: datatypes.JSON(`{"session_ctx":{"active_path_node_id":"n1","last_seen_at":"` + time.Now().UTC().Format(time.RFC3339) + `"}}`)}
+	got, source, corrupt := resolveSessionContext(dbc, ContextPlanDeps{Sessions: sessions}, userID, msg)
+	if corrupt || source != "message" || got == nil || got.ActivePathNodeID != "n1" {
+		t.Fatalf("valid session_ctx = %+v (source %q, corrupt %v)", got, source, corrupt)
+	}
+
+	// A session id naming another user's session is ignored in favour of the user's own.
+	foreign := &types.UserSessionState{SessionID: uuid.New(), UserID: uuid.New(), LastSeenAt: time.Now().UTC()}
+	sessions = &fakeSessionStates{latest: latest, bySession: map[uuid.UUID]*types.UserSessionState{foreign.SessionID: foreign}}
+	msg = &types.ChatMessage{Metadata: datatypes.JSON(`{"session_id":"` + foreign.SessionID.String() + `"}`)}
+	if got, _, _ := resolveSessionContext(dbc, ContextPlanDeps{Sessions: sessions}, userID, msg); got == nil || got.SessionID != latest.SessionID.String() {
+		t.Fatalf("foreign session resolved to %+v", got)
+	}
+}
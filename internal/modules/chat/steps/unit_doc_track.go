@@ -0,0 +1,46 @@
+package steps
+
+import (
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// preferredDocTrack is the user's default doc track, or primary when unset or unknown.
+func preferredDocTrack(dbc dbctx.Context, deps ContextPlanDeps, userID uuid.UUID) string {
+	if deps.Profiles == nil || userID == uuid.Nil {
+		return types.NodeDocTrackPrimary
+	}
+	profile, err := deps.Profiles.GetByUserID(dbc, userID)
+	if err != nil || profile == nil {
+		return types.NodeDocTrackPrimary
+	}
+	track, ok := types.NormalizeNodeDocTrack(profile.DefaultDocTrack)
+	if !ok {
+		return types.NodeDocTrackPrimary
+	}
+	return track
+}
+
+// loadUnitDoc loads the doc chat grounds a unit on: the user's preferred track when that doc
+// exists, else the primary doc. Returns nil when the node has no usable doc.
+func loadUnitDoc(dbc dbctx.Context, deps ContextPlanDeps, userID uuid.UUID, nodeID uuid.UUID) *types.LearningNodeDoc {
+	if deps.NodeDocs == nil || nodeID == uuid.Nil {
+		return nil
+	}
+	if track := preferredDocTrack(dbc, deps, userID); track != types.NodeDocTrackPrimary {
+		if doc, err := deps.NodeDocs.GetByPathNodeIDAndTrack(dbc, nodeID, track); err == nil && nodeDocUsable(doc) {
+			return doc
+		}
+	}
+	docRows, err := deps.NodeDocs.GetLatestByPathNodeIDs(dbc, []uuid.UUID{nodeID})
+	if err != nil || len(docRows) == 0 || !nodeDocUsable(docRows[0]) {
+		return nil
+	}
+	return docRows[0]
+}
+
+func nodeDocUsable(doc *types.LearningNodeDoc) bool {
+	return doc != nil && len(doc.DocJSON) > 0 && string(doc.DocJSON) != "null"
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 
@@ -69,6 +70,79 @@ func trimToTokens(s string, n int) string {
 	return trimToChars(s, n*4)
 }
 
+// trimToTokensAtBoundary is trimToTokens for quotable lanes (material excerpts, unit text):
+// it never ends inside a [citation] marker and never leaves a "quote" dangling, preferring
+// to back off to the last complete sentence so the model can't quote a fragment.
+func trimToTokensAtBoundary(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if estimateTokens(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	limit := n * 4
+	if limit >= len(r) {
+		return s
+	}
+	cut := r[:limit]
+
+	// Drop a citation marker the limit landed in; a half marker is worse than none.
+	if open := lastUnclosedRune(cut, '[', ']'); open >= 0 {
+		cut = cut[:open]
+	}
+
+	inQuote := false
+	var closeQuote rune
+	sentenceEnd := -1
+	for i, ch := range cut {
+		switch {
+		case ch == '"' && !inQuote:
+			inQuote, closeQuote = true, '"'
+		case ch == '“' && !inQuote:
+			inQuote, closeQuote = true, '”'
+		case inQuote && ch == closeQuote:
+			inQuote = false
+		case inQuote:
+		case ch == '\n':
+			sentenceEnd = i
+		case ch == '.' || ch == '!' || ch == '?':
+			if i+1 == len(cut) || unicode.IsSpace(cut[i+1]) {
+				sentenceEnd = i
+			}
+		}
+	}
+
+	// Backing off is only worth it when it keeps most of the budget.
+	if sentenceEnd >= len(cut)/2 {
+		return strings.TrimSpace(string(cut[:sentenceEnd+1])) + " …"
+	}
+	out := strings.TrimSpace(string(cut))
+	if i := strings.LastIndexFunc(out, unicode.IsSpace); i > 0 {
+		out = strings.TrimSpace(out[:i])
+	}
+	if inQuote {
+		return out + "…" + string(closeQuote)
+	}
+	return out + "…"
+}
+
+func lastUnclosedRune(r []rune, open, close rune) int {
+	for i := len(r) - 1; i >= 0; i-- {
+		switch r[i] {
+		case close:
+			return -1
+		case open:
+			return i
+		}
+	}
+	return -1
+}
+
 func formatRecent(msgs []*types.ChatMessage, max int) string {
 	if len(msgs) == 0 {
 		return ""
@@ -0,0 +1,50 @@
+package steps
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrimToTokensAtBoundaryBacksOffBeforeQuote(t *testing.T) {
+	excerpt := `Gradient descent updates weights iteratively. The paper states "the learning rate must decay to guarantee convergence under noisy gradients" and then moves on.`
+	// 20 tokens ≈ 80 chars: the limit falls inside the quoted phrase.
+	if i := strings.Index(excerpt, `"`); i >= 80 {
+		t.Fatalf("fixture: quote must start before the limit (at %d)", i)
+	}
+	got := trimToTokensAtBoundary(excerpt, 20)
+	if got != "Gradient descent updates weights iteratively. …" {
+		t.Fatalf("got %q", got)
+	}
+	if plain := trimToTokens(excerpt, 20); strings.Count(plain, `"`)%2 == 0 {
+		t.Fatalf("fixture no longer splits the quote with plain trim: %q", plain)
+	}
+}
+
+func TestTrimToTokensAtBoundaryClosesQuoteWithoutSentence(t *testing.T) {
+	excerpt := `Key definition "a convex function has every local minimum equal to its global minimum over the domain" applies here.`
+	got := trimToTokensAtBoundary(excerpt, 15)
+	if !strings.HasSuffix(got, `…"`) {
+		t.Fatalf("expected closed quote, got %q", got)
+	}
+	if strings.Count(got, `"`)%2 != 0 {
+		t.Fatalf("unbalanced quotes in %q", got)
+	}
+	curly := "Definition “a convex function has every local minimum equal to its global minimum” applies."
+	if got := trimToTokensAtBoundary(curly, 12); !strings.HasSuffix(got, "…”") {
+		t.Fatalf("expected closed curly quote, got %q", got)
+	}
+}
+
+func TestTrimToTokensAtBoundaryDropsPartialCitation(t *testing.T) {
+	excerpt := "- [chunk_id=3f1c9a2e-7b44-4c1e-9a55-0e2d4b6f8a10] Lecture 4 (pdf) — page 12\n  Momentum smooths updates."
+	got := trimToTokensAtBoundary(excerpt, 8)
+	if strings.Contains(got, "[") {
+		t.Fatalf("partial citation marker kept: %q", got)
+	}
+	if got := trimToTokensAtBoundary("short text", 100); got != "short text" {
+		t.Fatalf("under budget should be unchanged, got %q", got)
+	}
+	if got := trimToTokensAtBoundary("anything", 0); got != "" {
+		t.Fatalf("zero budget should be empty, got %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package steps
+
+import (
+	"encoding/json"
+	"strings"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// AnswerVerbosity selects how long and deep the assistant's answers should be. It only adjusts
+// the answer-style instruction; grounding and firewall rules are unchanged.
+type AnswerVerbosity string
+
+const (
+	VerbosityTerse    AnswerVerbosity = "terse"
+	VerbosityNormal   AnswerVerbosity = "normal"
+	VerbosityDetailed AnswerVerbosity = "detailed"
+)
+
+// ParseAnswerVerbosity accepts terse/normal/detailed (case-insensitive). Empty means normal.
+func ParseAnswerVerbosity(s string) (AnswerVerbosity, bool) {
+	switch v := AnswerVerbosity(strings.ToLower(strings.TrimSpace(s))); v {
+	case "":
+		return VerbosityNormal, true
+	case VerbosityTerse, VerbosityNormal, VerbosityDetailed:
+		return v, true
+	default:
+		return VerbosityNormal, false
+	}
+}
+
+// answerVerbosityFromMessage reads the verbosity the client sent with the user message.
+func answerVerbosityFromMessage(msg *types.ChatMessage) AnswerVerbosity {
+	if msg == nil || len(msg.Metadata) == 0 || string(msg.Metadata) == "null" {
+		return VerbosityNormal
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(msg.Metadata, &meta); err != nil || meta == nil {
+		return VerbosityNormal
+	}
+	v, _ := ParseAnswerVerbosity(stringFromAnyCtx(meta["verbosity"]))
+	return v
+}
+
+// answerStyleInstructions is appended after the preamble; normal keeps the default behavior.
+func answerStyleInstructions(v AnswerVerbosity) string {
+	switch v {
+	case VerbosityTerse:
+		return "\n\n## Answer style\nTERSE: answer in 1-3 sentences or a short list with only the essential point. Skip background, analogies, and worked examples unless asked. If more depth would help, offer it in one short line. Quoting and sourcing rules above still apply."
+	case VerbosityDetailed:
+		return "\n\n## Answer style\nDETAILED: give a thorough explanation. Build intuition first, then state the idea precisely, walk through a worked example, and call out common mistakes. Use short headings or numbered steps when it helps. Stay grounded in the context above; do not pad with unsupported material."
+	default:
+		return ""
+	}
+}
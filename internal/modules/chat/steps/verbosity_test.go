@@ -0,0 +1,37 @@
+package steps
+
+import (
+	"strings"
+	"testing"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestAnswerVerbosity(t *testing.T) {
+	for in, want := range map[string]AnswerVerbosity{"": VerbosityNormal, " Terse ": VerbosityTerse, "DETAILED": VerbosityDetailed} {
+		if got, ok := ParseAnswerVerbosity(in); !ok || got != want {
+			t.Fatalf("ParseAnswerVerbosity(%q) = %q, %v", in, got, ok)
+		}
+	}
+	if _, ok := ParseAnswerVerbosity("verbose"); ok {
+		t.Fatal("unknown verbosity should be rejected")
+	}
+
+	msg := &types.ChatMessage{Metadata: []byte(`{"session_id":"s","verbosity":"terse"}`)}
+	if got := answerVerbosityFromMessage(msg); got != VerbosityTerse {
+		t.Fatalf("from message = %q", got)
+	}
+	if got := answerVerbosityFromMessage(&types.ChatMessage{}); got != VerbosityNormal {
+		t.Fatalf("from empty message = %q", got)
+	}
+
+	if answerStyleInstructions(VerbosityNormal) != "" {
+		t.Fatal("normal should keep the default instructions")
+	}
+	for _, v := range []AnswerVerbosity{VerbosityTerse, VerbosityDetailed} {
+		s := answerStyleInstructions(v)
+		if !strings.Contains(s, "## Answer style") || !strings.Contains(s, strings.ToUpper(string(v))) {
+			t.Fatalf("style for %q = %q", v, s)
+		}
+	}
+}
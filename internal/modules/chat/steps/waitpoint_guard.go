@@ -66,6 +66,48 @@ func threadHasActiveWaitpoint(ctx context.Context, deps RespondDeps, thread *typ
     return false
 }
 
+// threadPendingWaitpointKind returns the waitpoint kind (e.g. path_intake.structure_v1) of the
+// thread's build child that is currently waiting on the user, or "" when nothing is pending.
+func threadPendingWaitpointKind(ctx context.Context, deps RespondDeps, thread *types.ChatThread, userID uuid.UUID) string {
+    if thread == nil || thread.JobID == nil || *thread.JobID == uuid.Nil || deps.JobRuns == nil {
+        return ""
+    }
+    dbc := dbctx.Context{Ctx: ctx, Tx: deps.DB}
+    rows, err := deps.JobRuns.GetByIDs(dbc, []uuid.UUID{*thread.JobID})
+    if err != nil || len(rows) == 0 || rows[0] == nil || rows[0].OwnerUserID != userID {
+        return ""
+    }
+    ids := []uuid.UUID{}
+    for id := range waitpointChildCandidates(rows[0].Result) {
+        if id != uuid.Nil {
+            ids = append(ids, id)
+        }
+    }
+    if len(ids) == 0 {
+        return ""
+    }
+    childRows, err := deps.JobRuns.GetByIDs(dbc, ids)
+    if err != nil {
+        return ""
+    }
+    for _, row := range childRows {
+        if row == nil || row.OwnerUserID != userID || !strings.EqualFold(strings.TrimSpace(row.Status), "waiting_user") {
+            continue
+        }
+        var env struct {
+            Waitpoint struct {
+                Kind string `json:"kind"`
+            } `json:"waitpoint"`
+        }
+        if len(row.Result) > 0 && json.Unmarshal(row.Result, &env) == nil {
+            if kind := strings.TrimSpace(env.Waitpoint.Kind); kind != "" {
+                return kind
+            }
+        }
+    }
+    return ""
+}
+
 func waitpointChildCandidates(raw datatypes.JSON) map[uuid.UUID]string {
     out := map[uuid.UUID]string{}
     if len(raw) == 0 {
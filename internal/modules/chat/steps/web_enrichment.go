@@ -0,0 +1,194 @@
+package steps
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/websearch"
+)
+
+// webLaneSection heads the web results in the plan instructions. The grounding rules in
+// prepareProductPlan key off the "web" evidence type, not this title.
+const webLaneSection = "## External web results (unverified)"
+
+// webLaneNote follows webLaneSection so the model reads the results as unverified.
+const webLaneNote = "These come from the public web, not the user's materials; they may be wrong or outdated. " +
+	"Attribute every claim taken from them to its site and URL in the same sentence."
+
+// Reasons the web lane is off regardless of what the router picked.
+const (
+	webLaneNoProvider = "web search not configured"
+	webLaneNoPath     = "thread has no path"
+	webLaneDisabled   = "path setting disabled"
+)
+
+// webLaneGate reports whether the thread's path opted into web enrichment and a provider is
+// wired. When it is not allowed, reason says why.
+func webLaneGate(dbc dbctx.Context, deps ContextPlanDeps, thread *types.ChatThread) (bool, string) {
+	if deps.Web == nil {
+		return false, webLaneNoProvider
+	}
+	if thread == nil || thread.PathID == nil || *thread.PathID == uuid.Nil || deps.Path == nil {
+		return false, webLaneNoPath
+	}
+	path, err := deps.Path.GetByID(dbc, *thread.PathID)
+	if err != nil || path == nil || !types.PathChatWebEnrichmentEnabled(path.Metadata) {
+		return false, webLaneDisabled
+	}
+	return true, ""
+}
+
+// restrictWebLane turns the web lane off when the gate is closed, whatever the router decided.
+func restrictWebLane(route contextRoute, allowed bool, reason string) {
+	if allowed {
+		return
+	}
+	if ln, ok := route.Lanes["web"]; ok && ln.Enabled {
+		ln.Enabled = false
+		ln.Reason = reason
+		route.Lanes["web"] = ln
+	}
+}
+
+type webContextConfig struct {
+	Results      int
+	FetchTimeout time.Duration
+}
+
+// resolveWebContextConfig reads CHAT_WEB_RESULTS (default 3, at most 5) and
+// CHAT_WEB_FETCH_TIMEOUT_SECONDS (default 6), the wall-clock limit for fetching all pages.
+func resolveWebContextConfig() webContextConfig {
+	cfg := webContextConfig{
+		Results:      envutil.Int("CHAT_WEB_RESULTS", 3),
+		FetchTimeout: time.Duration(envutil.Int("CHAT_WEB_FETCH_TIMEOUT_SECONDS", 6)) * time.Second,
+	}
+	if cfg.Results <= 0 {
+		cfg.Results = 3
+	}
+	if cfg.Results > 5 {
+		cfg.Results = 5
+	}
+	if cfg.FetchTimeout <= 0 {
+		cfg.FetchTimeout = 6 * time.Second
+	}
+	return cfg
+}
+
+// buildWebContext searches the web for query, reads the top results and renders them within
+// tokenBudget, split evenly across results. A page that cannot be fetched falls back to its
+// search snippet. Every rendered result becomes a "web" evidence source carrying its URL.
+func buildWebContext(ctx context.Context, provider websearch.Provider, query string, tokenBudget int) (string, map[string]any, []EvidenceSource) {
+	trace := map[string]any{}
+	if provider == nil || strings.TrimSpace(query) == "" || tokenBudget <= 0 {
+		return "", trace, nil
+	}
+	cfg := resolveWebContextConfig()
+	trace["provider"] = provider.Name()
+	trace["budget"] = tokenBudget
+
+	start := time.Now()
+	results, err := provider.Search(ctx, query, cfg.Results)
+	trace["search_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		trace["error"] = err.Error()
+		return "", trace, nil
+	}
+	trace["results"] = len(results)
+	if len(results) == 0 {
+		return "", trace, nil
+	}
+
+	pages := make([]*websearch.Page, len(results))
+	fetchCtx, cancel := context.WithTimeout(ctx, cfg.FetchTimeout)
+	defer cancel()
+	g, gctx := errgroup.WithContext(fetchCtx)
+	for i, r := range results {
+		i, r := i, r
+		g.Go(func() error {
+			// A failed page is not fatal; the snippet stands in for it.
+			if page, err := provider.Fetch(gctx, r.URL); err == nil && page != nil {
+				pages[i] = page
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	perResult := tokenBudget / len(results)
+	var b strings.Builder
+	evidence := make([]EvidenceSource, 0, len(results))
+	fetched, dropped := 0, 0
+	for i, r := range results {
+		link := strings.TrimSpace(r.URL)
+		title := strings.TrimSpace(r.Title)
+		text := strings.TrimSpace(r.Snippet)
+		if p := pages[i]; p != nil && strings.TrimSpace(p.Text) != "" {
+			text = strings.TrimSpace(p.Text)
+			if title == "" {
+				title = strings.TrimSpace(p.Title)
+			}
+			fetched++
+		}
+		// Page text is untrusted; a page that tries to instruct the assistant is left out.
+		if looksLikePromptInjection(text) {
+			dropped++
+			continue
+		}
+		text = trimToTokensAtBoundary(text, perResult)
+		if link == "" || text == "" {
+			continue
+		}
+		if title == "" {
+			title = webHost(link)
+		}
+		id := webEvidenceID(link)
+		fmt.Fprintf(&b, "[source_id=%s] %s — %s\n%s\n\n", id, title, link, text)
+		evidence = append(evidence, EvidenceSource{
+			ID:    id,
+			Type:  "web",
+			Title: title,
+			Text:  text,
+			Meta:  map[string]any{"url": link, "locator": link, "site": webHost(link), "provider": provider.Name()},
+		})
+	}
+	trace["fetched"] = fetched
+	if dropped > 0 {
+		trace["dropped_injection"] = dropped
+	}
+	trace["used"] = len(evidence)
+	return strings.TrimSpace(b.String()), trace, evidence
+}
+
+func webEvidenceID(link string) string {
+	sum := sha1.Sum([]byte(link))
+	return "web:" + hex.EncodeToString(sum[:6])
+}
+
+func webHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u == nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// hasWebEvidence reports whether any selected source came from the web lane.
+func hasWebEvidence(sources []EvidenceSource) bool {
+	for _, s := range sources {
+		if s.Type == "web" {
+			return true
+		}
+	}
+	return false
+}
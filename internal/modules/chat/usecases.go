@@ -12,6 +12,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/platform/neo4jdb"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/platform/websearch"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
@@ -40,6 +41,10 @@ type UsecasesDeps struct {
 	Jobs    services.JobService
 	Notify  services.ChatNotifier
 
+	// Optional: chat action tools (per-message execution ledger + learning usecases).
+	ToolExecs   repos.ChatToolExecutionRepo
+	PathActions steps.PathActions
+
 	// Optional: path docs projection used for hybrid retrieval in chat threads.
 	Path         repos.PathRepo
 	PathNodes    repos.PathNodeRepo
@@ -48,10 +53,21 @@ type UsecasesDeps struct {
 	Concepts     repos.ConceptRepo
 	NodeDocs     repos.LearningNodeDocRepo
 	ConceptEdges repos.ConceptEdgeRepo
+	Evidence     repos.ConceptEvidenceRepo
 	ConceptState repos.UserConceptStateRepo
 	ConceptModel repos.UserConceptModelRepo
 	Sessions     repos.UserSessionStateRepo
 	MisconRepo   repos.UserMisconceptionInstanceRepo
+	// Outlines is the shared path outline read model (node lookups, ordering, concept keys).
+	Outlines services.PathOutlineService
+	// Web is the optional web search provider for the chat web lane.
+	Web websearch.Provider
+	// Focus is the optional path focus repo; active focus sessions bias chat context.
+	Focus repos.PathFocusRepo
+	// BudgetStats optionally samples context plan lane usage for the budget summary.
+	BudgetStats services.ContextBudgetSampler
+	// Profiles optionally supplies the user's default doc track for unit context.
+	Profiles repos.LearningProfileRepo
 
 	UserLibraryIndex     repos.UserLibraryIndexRepo
 	MaterialFiles        repos.MaterialFileRepo
@@ -82,6 +98,8 @@ type (
 	PathNodeIndexOutput = steps.PathNodeIndexOutput
 
 	RebuildInput = steps.RebuildInput
+
+	MaterialFileProjectionsInput = steps.MaterialFileProjectionsInput
 )
 
 func (u Usecases) Respond(ctx context.Context, in RespondInput) (RespondOutput, error) {
@@ -102,13 +120,22 @@ func (u Usecases) Respond(ctx context.Context, in RespondInput) (RespondOutput,
 		NodeDocs:  u.deps.NodeDocs,
 		Concepts:  u.deps.Concepts,
 		Edges:     u.deps.ConceptEdges,
+		Evidence:  u.deps.Evidence,
 		Mastery:   u.deps.ConceptState,
 		Models:    u.deps.ConceptModel,
 		Miscon:    u.deps.MisconRepo,
 		Sessions:  u.deps.Sessions,
+		Outlines:  u.deps.Outlines,
+		Web:       u.deps.Web,
+		Focus:     u.deps.Focus,
 		JobRuns:   u.deps.JobRuns,
 		Jobs:      u.deps.Jobs,
+		ToolExecs: u.deps.ToolExecs,
+		Actions:   u.deps.PathActions,
 		Notify:    u.deps.Notify,
+
+		BudgetStats: u.deps.BudgetStats,
+		Profiles:    u.deps.Profiles,
 	}, steps.RespondInput(in))
 }
 
@@ -177,6 +204,14 @@ func (u Usecases) RebuildThreadProjections(ctx context.Context, in RebuildInput)
 	}, steps.RebuildInput(in))
 }
 
+func (u Usecases) PurgeMaterialFileProjections(ctx context.Context, in MaterialFileProjectionsInput) (int, error) {
+	return steps.PurgeMaterialFileProjections(ctx, steps.RebuildDeps{
+		DB:  u.deps.DB,
+		Log: u.deps.Log,
+		Vec: u.deps.Vec,
+	}, steps.MaterialFileProjectionsInput(in))
+}
+
 func (u Usecases) PurgeThreadArtifacts(ctx context.Context, in RebuildInput) error {
 	return steps.PurgeThreadArtifacts(ctx, steps.RebuildDeps{
 		DB:  u.deps.DB,
@@ -0,0 +1,108 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// Self-reported knowledge is floored just above the "known" cut used by the user knowledge
+// context (mastery >= 0.85, confidence >= 0.60) so the concept reads as known without
+// claiming more certainty than a real assessment would.
+const (
+	selfReportedKnownMastery    = 0.9
+	selfReportedKnownConfidence = 0.65
+)
+
+type MarkConceptKnownInput struct {
+	UserID     uuid.UUID
+	PathID     uuid.UUID
+	ConceptKey string
+}
+
+type MarkConceptKnownOutput struct {
+	ConceptID  uuid.UUID `json:"concept_id"`
+	ConceptKey string    `json:"concept_key"`
+	Mastery    float64   `json:"mastery"`
+	Confidence float64   `json:"confidence"`
+}
+
+// MarkConceptKnown records the user's claim that they already know a path concept. Existing
+// state is only raised, never lowered, so repeating the call is a no-op.
+func (u Usecases) MarkConceptKnown(ctx context.Context, in MarkConceptKnownInput) (MarkConceptKnownOutput, error) {
+	out := MarkConceptKnownOutput{}
+	if in.UserID == uuid.Nil {
+		return out, apierr.New(http.StatusUnauthorized, "unauthorized", nil)
+	}
+	if in.PathID == uuid.Nil {
+		return out, apierr.New(http.StatusBadRequest, "invalid_path_id", fmt.Errorf("missing path_id"))
+	}
+	key := strings.TrimSpace(strings.ToLower(in.ConceptKey))
+	if key == "" {
+		return out, apierr.New(http.StatusBadRequest, "missing_concept_key", nil)
+	}
+	if u.deps.Path == nil || u.deps.Concepts == nil || u.deps.ConceptState == nil {
+		return out, apierr.New(http.StatusInternalServerError, "concept_repo_missing", fmt.Errorf("missing deps"))
+	}
+
+	dbc := dbctx.Context{Ctx: ctx}
+	pathRow, err := u.deps.Path.GetByID(dbc, in.PathID)
+	if err != nil {
+		return out, apierr.New(http.StatusInternalServerError, "load_path_failed", err)
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != in.UserID {
+		return out, apierr.New(http.StatusNotFound, "path_not_found", nil)
+	}
+
+	pathID := in.PathID
+	rows, err := u.deps.Concepts.GetByScopeAndKeys(dbc, "path", &pathID, []string{key})
+	if err != nil {
+		return out, apierr.New(http.StatusInternalServerError, "load_concepts_failed", err)
+	}
+	var concept *types.Concept
+	for _, c := range rows {
+		if c != nil && c.ID != uuid.Nil {
+			concept = c
+			break
+		}
+	}
+	if concept == nil {
+		return out, apierr.New(http.StatusNotFound, "concept_not_found", nil)
+	}
+
+	// Learner state is keyed by the canonical concept when one exists.
+	stateConceptID := concept.ID
+	if concept.CanonicalConceptID != nil && *concept.CanonicalConceptID != uuid.Nil {
+		stateConceptID = *concept.CanonicalConceptID
+	}
+
+	st, err := u.deps.ConceptState.Get(dbc, in.UserID, stateConceptID)
+	if err != nil {
+		return out, apierr.New(http.StatusInternalServerError, "load_concept_state_failed", err)
+	}
+	if st == nil {
+		st = &types.UserConceptState{UserID: in.UserID, ConceptID: stateConceptID}
+	}
+	now := time.Now().UTC()
+	st.Mastery = math.Max(st.Mastery, selfReportedKnownMastery)
+	st.Confidence = math.Max(st.Confidence, selfReportedKnownConfidence)
+	st.LastSeenAt = &now
+	if err := u.deps.ConceptState.Upsert(dbc, st); err != nil {
+		return out, apierr.New(http.StatusInternalServerError, "save_concept_state_failed", err)
+	}
+
+	out.ConceptID = concept.ID
+	out.ConceptKey = concept.Key
+	out.Mastery = st.Mastery
+	out.Confidence = st.Confidence
+	return out, nil
+}
@@ -0,0 +1,267 @@
+package content
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BlockAlignTextThreshold is the minimum shingle similarity for two blocks that share neither
+// an ID nor a title to be considered the same block.
+const BlockAlignTextThreshold = 0.5
+
+// blockAlignShingleSize is the word n-gram size used for text similarity.
+const blockAlignShingleSize = 3
+
+// BlockAlignment pairs one variant block with the base block it was matched to. BaseIndex is -1
+// (and MatchedBy empty) when the variant block has no counterpart in the base doc.
+type BlockAlignment struct {
+	VariantBlockID string  `json:"variant_block_id"`
+	VariantIndex   int     `json:"variant_index"`
+	BaseBlockID    string  `json:"base_block_id,omitempty"`
+	BaseIndex      int     `json:"base_index"`
+	MatchedBy      string  `json:"matched_by,omitempty"` // id|title|text
+	Similarity     float64 `json:"similarity,omitempty"`
+}
+
+// DocAlignment is the block-level mapping from a variant doc onto its base doc.
+type DocAlignment struct {
+	Blocks        []BlockAlignment `json:"blocks"`
+	Aligned       int              `json:"aligned"`
+	VariantBlocks int              `json:"variant_blocks"`
+	BaseBlocks    int              `json:"base_blocks"`
+	// UnmatchedBaseIDs are base blocks the variant dropped or rewrote beyond recognition.
+	UnmatchedBaseIDs []string `json:"unmatched_base_block_ids"`
+	// Diverged is set when fewer than half the variant blocks align.
+	Diverged bool `json:"diverged"`
+}
+
+// AlignNodeDocBlocks matches variant blocks to base blocks one-to-one: first by block ID, then
+// by (type, title), then by shingled-text similarity at or above BlockAlignTextThreshold. Each
+// pass only considers blocks the earlier passes left unmatched, and ties resolve to the earliest
+// base block so the result is deterministic.
+func AlignNodeDocBlocks(variant, base NodeDocV1) DocAlignment {
+	out := DocAlignment{
+		Blocks:        make([]BlockAlignment, len(variant.Blocks)),
+		VariantBlocks: len(variant.Blocks),
+		BaseBlocks:    len(base.Blocks),
+	}
+	baseUsed := make([]bool, len(base.Blocks))
+	for i, b := range variant.Blocks {
+		out.Blocks[i] = BlockAlignment{
+			VariantBlockID: blockAlignID(b),
+			VariantIndex:   i,
+			BaseIndex:      -1,
+		}
+	}
+	match := func(vi, bi int, by string, sim float64) {
+		out.Blocks[vi].BaseIndex = bi
+		out.Blocks[vi].BaseBlockID = blockAlignID(base.Blocks[bi])
+		out.Blocks[vi].MatchedBy = by
+		out.Blocks[vi].Similarity = sim
+		baseUsed[bi] = true
+		out.Aligned++
+	}
+
+	// 1) Stable IDs survive most variant rewrites.
+	baseByID := map[string]int{}
+	for i, b := range base.Blocks {
+		if id := blockAlignID(b); id != "" {
+			if _, dup := baseByID[id]; !dup {
+				baseByID[id] = i
+			}
+		}
+	}
+	for vi := range out.Blocks {
+		id := out.Blocks[vi].VariantBlockID
+		if id == "" {
+			continue
+		}
+		if bi, ok := baseByID[id]; ok && !baseUsed[bi] {
+			match(vi, bi, "id", 1)
+		}
+	}
+
+	// 2) Same type and title ("Worked example", a section heading) with a regenerated ID.
+	for vi, vb := range variant.Blocks {
+		if out.Blocks[vi].BaseIndex >= 0 {
+			continue
+		}
+		title := blockAlignTitle(vb)
+		if title == "" {
+			continue
+		}
+		for bi, bb := range base.Blocks {
+			if baseUsed[bi] || BlockType(bb) != BlockType(vb) {
+				continue
+			}
+			if blockAlignTitle(bb) == title {
+				match(vi, bi, "title", 1)
+				break
+			}
+		}
+	}
+
+	// 3) Rewritten blocks: best remaining pairs by shingle similarity.
+	type candidate struct {
+		vi, bi int
+		sim    float64
+	}
+	baseShingles := make([]map[string]bool, len(base.Blocks))
+	for bi, bb := range base.Blocks {
+		if !baseUsed[bi] {
+			baseShingles[bi] = textShingles(blockAlignText(bb), blockAlignShingleSize)
+		}
+	}
+	cands := make([]candidate, 0)
+	for vi, vb := range variant.Blocks {
+		if out.Blocks[vi].BaseIndex >= 0 {
+			continue
+		}
+		vs := textShingles(blockAlignText(vb), blockAlignShingleSize)
+		if len(vs) == 0 {
+			continue
+		}
+		for bi := range base.Blocks {
+			if baseUsed[bi] {
+				continue
+			}
+			if sim := jaccard(vs, baseShingles[bi]); sim >= BlockAlignTextThreshold {
+				cands = append(cands, candidate{vi: vi, bi: bi, sim: sim})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].sim != cands[j].sim {
+			return cands[i].sim > cands[j].sim
+		}
+		if cands[i].vi != cands[j].vi {
+			return cands[i].vi < cands[j].vi
+		}
+		return cands[i].bi < cands[j].bi
+	})
+	for _, c := range cands {
+		if out.Blocks[c.vi].BaseIndex >= 0 || baseUsed[c.bi] {
+			continue
+		}
+		match(c.vi, c.bi, "text", c.sim)
+	}
+
+	out.UnmatchedBaseIDs = make([]string, 0)
+	for bi, bb := range base.Blocks {
+		if !baseUsed[bi] {
+			out.UnmatchedBaseIDs = append(out.UnmatchedBaseIDs, blockAlignID(bb))
+		}
+	}
+	out.Diverged = out.VariantBlocks > 0 && out.Aligned*2 < out.VariantBlocks
+	return out
+}
+
+func blockAlignID(b map[string]any) string {
+	if b == nil {
+		return ""
+	}
+	return strings.TrimSpace(stringFromAny(b["id"]))
+}
+
+// blockAlignTitle is the block's visible title: "title" for titled blocks, "text" for headings.
+func blockAlignTitle(b map[string]any) string {
+	if b == nil {
+		return ""
+	}
+	title := stringFromAny(b["title"])
+	if BlockType(b) == "heading" {
+		title = stringFromAny(b["text"])
+	}
+	return strings.Join(alignWords(title), " ")
+}
+
+// blockAlignSkipKeys are structural fields that say nothing about what a block teaches.
+var blockAlignSkipKeys = map[string]bool{
+	"id":                      true,
+	"type":                    true,
+	"citations":               true,
+	"concept_keys":            true,
+	"see_also":                true,
+	"trigger_after_block_ids": true,
+	"render_hint":             true,
+	"asset":                   true,
+	"url":                     true,
+	"answer_id":               true,
+}
+
+// blockAlignText flattens every prose field of the block (md, captions, list items, qas, ...).
+func blockAlignText(b map[string]any) string {
+	if b == nil || BlockType(b) == "user_md" {
+		return ""
+	}
+	parts := make([]string, 0, 4)
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case string:
+			if s := strings.TrimSpace(t); s != "" {
+				parts = append(parts, s)
+			}
+		case []any:
+			for _, x := range t {
+				walk(x)
+			}
+		case []string:
+			for _, x := range t {
+				walk(x)
+			}
+		case map[string]any:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if blockAlignSkipKeys[k] {
+					continue
+				}
+				walk(t[k])
+			}
+		}
+	}
+	walk(b)
+	return strings.Join(parts, " ")
+}
+
+func alignWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// textShingles returns the set of word n-grams of s. Texts shorter than n words are a single
+// shingle so short captions can still match exactly.
+func textShingles(s string, n int) map[string]bool {
+	words := alignWords(s)
+	out := map[string]bool{}
+	if len(words) == 0 {
+		return out
+	}
+	if len(words) < n {
+		out[strings.Join(words, " ")] = true
+		return out
+	}
+	for i := 0; i+n <= len(words); i++ {
+		out[strings.Join(words[i:i+n], " ")] = true
+	}
+	return out
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
@@ -0,0 +1,64 @@
+package content
+
+import "testing"
+
+func TestAlignNodeDocBlocksVariantFixture(t *testing.T) {
+	base := loadNodeDocFixture(t, "node_doc_binary_search.json")
+	variant := loadNodeDocFixture(t, "node_doc_binary_search_variant.json")
+
+	got := AlignNodeDocBlocks(variant, base)
+	want := []struct {
+		baseID string
+		by     string
+	}{
+		{"b-obj", "id"},
+		{"b-h1", "title"},
+		{"b-p1", "text"},
+		{"b-c1", "title"},
+		{"", ""}, // new "Common mistake" callout
+		{"b-code", "id"},
+		{"b-qc", "text"},
+		{"", ""}, // new paragraph
+		{"b-kt", "title"},
+	}
+	if len(got.Blocks) != len(want) {
+		t.Fatalf("expected %d alignments, got %d", len(want), len(got.Blocks))
+	}
+	for i, w := range want {
+		a := got.Blocks[i]
+		if a.BaseBlockID != w.baseID || a.MatchedBy != w.by {
+			t.Errorf("block %d (%s): got base=%q by=%q, want base=%q by=%q", i, a.VariantBlockID, a.BaseBlockID, a.MatchedBy, w.baseID, w.by)
+		}
+		if w.baseID == "" && a.BaseIndex != -1 {
+			t.Errorf("block %d: expected unmatched base index, got %d", i, a.BaseIndex)
+		}
+	}
+	if got.Blocks[2].Similarity < BlockAlignTextThreshold || got.Blocks[2].Similarity >= 1 {
+		t.Errorf("rewritten paragraph similarity out of range: %v", got.Blocks[2].Similarity)
+	}
+	if got.Aligned != 7 || got.Diverged {
+		t.Fatalf("expected 7 aligned and not diverged, got %d diverged=%v", got.Aligned, got.Diverged)
+	}
+	if len(got.UnmatchedBaseIDs) != len(base.Blocks)-7 {
+		t.Fatalf("expected %d unmatched base blocks, got %v", len(base.Blocks)-7, got.UnmatchedBaseIDs)
+	}
+}
+
+func TestAlignNodeDocBlocksDiverged(t *testing.T) {
+	base := loadNodeDocFixture(t, "node_doc_binary_search.json")
+	other := NodeDocV1{Blocks: []map[string]any{
+		{"id": "x1", "type": "paragraph", "md": "Hash tables trade memory for constant-time lookups."},
+		{"id": "x2", "type": "callout", "title": "Collisions", "md": "Chaining keeps a list per bucket."},
+		{"id": "b-obj", "type": "objectives", "title": "What you'll learn", "items_md": []any{"Explain load factor"}},
+	}}
+
+	got := AlignNodeDocBlocks(other, base)
+	if got.Aligned != 1 || !got.Diverged {
+		t.Fatalf("expected 1 aligned and diverged, got %d diverged=%v", got.Aligned, got.Diverged)
+	}
+
+	same := AlignNodeDocBlocks(base, base)
+	if same.Aligned != len(base.Blocks) || same.Diverged || len(same.UnmatchedBaseIDs) != 0 {
+		t.Fatalf("expected identical docs to fully align, got %+v", same)
+	}
+}
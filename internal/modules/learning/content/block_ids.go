@@ -1,35 +1,98 @@
 package content
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 )
 
-// EnsureNodeDocBlockIDs assigns stable IDs to any blocks missing them.
+// EnsureNodeDocBlockIDs assigns stable IDs to any blocks missing them and makes IDs unique: the
+// first block keeps a shared ID and later ones are renamed deterministically (see
+// UniqueBlockIDs), so the same doc always resolves to the same IDs.
 // Returns the updated doc and whether any changes were made.
 func EnsureNodeDocBlockIDs(doc NodeDocV1) (NodeDocV1, bool) {
 	changed := false
-	seen := map[string]bool{}
+	ids := make([]string, len(doc.Blocks))
+	for i, b := range doc.Blocks {
+		if b != nil {
+			ids[i] = strings.TrimSpace(stringFromAny(b["id"]))
+		}
+	}
+	unique := UniqueBlockIDs(ids)
 
 	for i := range doc.Blocks {
 		b := doc.Blocks[i]
 		if b == nil {
 			continue
 		}
-		t := strings.ToLower(strings.TrimSpace(stringFromAny(b["type"])))
-		id := strings.TrimSpace(stringFromAny(b["id"]))
-		if id == "" || seen[id] {
+		id := unique[i]
+		if id == "" {
+			t := strings.ToLower(strings.TrimSpace(stringFromAny(b["type"])))
 			if t == "" {
 				t = "block"
 			}
 			id = t + "_" + uuid.New().String()
+		}
+		if id != ids[i] {
 			b["id"] = id
 			doc.Blocks[i] = b
 			changed = true
 		}
-		seen[id] = true
 	}
 
 	return doc, changed
 }
+
+// UniqueBlockIDs resolves duplicate block IDs in document order. The first occurrence of an ID
+// keeps it; each later one becomes "<id>_<n>" with the smallest n >= 2 not used anywhere in the
+// doc. Empty IDs stay empty.
+func UniqueBlockIDs(ids []string) []string {
+	taken := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" {
+			taken[id] = true
+		}
+	}
+	out := make([]string, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if seen[id] {
+			base := id
+			for n := 2; ; n++ {
+				id = base + "_" + strconv.Itoa(n)
+				if !taken[id] {
+					break
+				}
+			}
+			taken[id] = true
+		}
+		seen[id] = true
+		out[i] = id
+	}
+	return out
+}
+
+// DuplicateNodeDocBlockIDs lists block IDs used by more than one block, in document order.
+func DuplicateNodeDocBlockIDs(doc NodeDocV1) []string {
+	count := map[string]int{}
+	var out []string
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		id := strings.TrimSpace(stringFromAny(b["id"]))
+		if id == "" {
+			continue
+		}
+		count[id]++
+		if count[id] == 2 {
+			out = append(out, id)
+		}
+	}
+	return out
+}
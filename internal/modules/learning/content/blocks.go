@@ -0,0 +1,496 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// BlockHeader is the part every NodeDocV1 block shares. Typed blocks embed it.
+//
+// Extra holds top-level keys the typed struct doesn't know about; they are written back
+// untouched on encode so older readers and newer writers never drop each other's fields.
+type BlockHeader struct {
+	ID          string         `json:"id"`
+	Type        string         `json:"type"`
+	ConceptKeys []string       `json:"concept_keys,omitempty"`
+	Extra       map[string]any `json:"-"`
+
+	// orig keeps the decoded values of known keys and snap their typed form at decode time,
+	// so unchanged fields re-encode to the exact original value.
+	orig map[string]any
+	snap map[string]json.RawMessage
+}
+
+func (h *BlockHeader) Header() *BlockHeader { return h }
+
+// Block is the typed form of one NodeDocV1 block.
+type Block interface {
+	Header() *BlockHeader
+}
+
+type MdBlock struct { // paragraph|intuition|mental_model|why_it_matters
+	BlockHeader
+	Title     string          `json:"title"`
+	MD        string          `json:"md"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type HeadingBlock struct {
+	BlockHeader
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+type CalloutBlock struct {
+	BlockHeader
+	Variant   string          `json:"variant"`
+	Title     string          `json:"title"`
+	MD        string          `json:"md"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type CodeBlock struct {
+	BlockHeader
+	Language string `json:"language"`
+	Filename string `json:"filename"`
+	Code     string `json:"code"`
+}
+
+type FigureBlock struct {
+	BlockHeader
+	Asset     MediaRefV1      `json:"asset"`
+	Caption   string          `json:"caption"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type VideoBlock struct {
+	BlockHeader
+	URL      string  `json:"url"`
+	StartSec float64 `json:"start_sec"`
+	Caption  string  `json:"caption"`
+}
+
+type DiagramBlock struct {
+	BlockHeader
+	Kind      string          `json:"kind"`
+	Source    string          `json:"source"`
+	Caption   string          `json:"caption"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type TableBlock struct {
+	BlockHeader
+	Caption   string          `json:"caption"`
+	Columns   []string        `json:"columns"`
+	Rows      [][]string      `json:"rows"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type EquationBlock struct {
+	BlockHeader
+	Latex     string          `json:"latex"`
+	Display   *bool           `json:"display"`
+	Caption   string          `json:"caption"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type QuizBlock struct { // quick_check
+	BlockHeader
+	Kind                 string                  `json:"kind"` // short_answer|true_false|mcq|""
+	PromptMD             string                  `json:"prompt_md"`
+	Options              []DrillQuestionOptionV1 `json:"options"`
+	AnswerID             string                  `json:"answer_id"`
+	AnswerMD             string                  `json:"answer_md"`
+	TriggerAfterBlockIDs []string                `json:"trigger_after_block_ids"`
+	Citations            []CitationRefV1         `json:"citations"`
+}
+
+type FlashcardBlock struct {
+	BlockHeader
+	FrontMD              string          `json:"front_md"`
+	BackMD               string          `json:"back_md"`
+	TriggerAfterBlockIDs []string        `json:"trigger_after_block_ids"`
+	Citations            []CitationRefV1 `json:"citations"`
+}
+
+type ListBlock struct { // objectives|prerequisites|key_takeaways|common_mistakes|...
+	BlockHeader
+	Title     string          `json:"title"`
+	ItemsMD   []string        `json:"items_md"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type StepsBlock struct {
+	BlockHeader
+	Title     string          `json:"title"`
+	StepsMD   []string        `json:"steps_md"`
+	Citations []CitationRefV1 `json:"citations"`
+}
+
+type GlossaryBlock struct {
+	BlockHeader
+	Title     string                     `json:"title"`
+	Terms     []NodeDocGenGlossaryTermV1 `json:"terms"`
+	Citations []CitationRefV1            `json:"citations"`
+}
+
+type FAQBlock struct {
+	BlockHeader
+	Title     string                `json:"title"`
+	QAs       []NodeDocGenFAQItemV1 `json:"qas"`
+	Citations []CitationRefV1       `json:"citations"`
+}
+
+type DividerBlock struct {
+	BlockHeader
+}
+
+// UserMdBlock is learner-authored markdown. Its body is sealed at rest (see SealUserBlocks)
+// and never feeds search or indexing text.
+type UserMdBlock struct {
+	BlockHeader
+	Title string `json:"title"`
+	MD    string `json:"md"`
+}
+
+// UnknownBlock carries a block whose type isn't registered; everything but the header
+// lives in Extra.
+type UnknownBlock struct {
+	BlockHeader
+}
+
+var (
+	blockRegistryMu sync.RWMutex
+	blockRegistry   = map[string]func() Block{
+		"heading":         func() Block { return &HeadingBlock{} },
+		"paragraph":       func() Block { return &MdBlock{} },
+		"intuition":       func() Block { return &MdBlock{} },
+		"mental_model":    func() Block { return &MdBlock{} },
+		"why_it_matters":  func() Block { return &MdBlock{} },
+		"callout":         func() Block { return &CalloutBlock{} },
+		"code":            func() Block { return &CodeBlock{} },
+		"figure":          func() Block { return &FigureBlock{} },
+		"video":           func() Block { return &VideoBlock{} },
+		"diagram":         func() Block { return &DiagramBlock{} },
+		"table":           func() Block { return &TableBlock{} },
+		"equation":        func() Block { return &EquationBlock{} },
+		"quick_check":     func() Block { return &QuizBlock{} },
+		"flashcard":       func() Block { return &FlashcardBlock{} },
+		"divider":         func() Block { return &DividerBlock{} },
+		"objectives":      func() Block { return &ListBlock{} },
+		"prerequisites":   func() Block { return &ListBlock{} },
+		"key_takeaways":   func() Block { return &ListBlock{} },
+		"common_mistakes": func() Block { return &ListBlock{} },
+		"misconceptions":  func() Block { return &ListBlock{} },
+		"edge_cases":      func() Block { return &ListBlock{} },
+		"heuristics":      func() Block { return &ListBlock{} },
+		"checklist":       func() Block { return &ListBlock{} },
+		"connections":     func() Block { return &ListBlock{} },
+		"steps":           func() Block { return &StepsBlock{} },
+		"glossary":        func() Block { return &GlossaryBlock{} },
+		"faq":             func() Block { return &FAQBlock{} },
+		"user_md":         func() Block { return &UserMdBlock{} },
+	}
+)
+
+// RegisterBlockType maps a block type to the struct it decodes into. newBlock must return a
+// fresh pointer to a struct embedding BlockHeader.
+func RegisterBlockType(typ string, newBlock func() Block) {
+	typ = normalizeBlockType(typ)
+	if typ == "" || newBlock == nil {
+		return
+	}
+	blockRegistryMu.Lock()
+	blockRegistry[typ] = newBlock
+	blockRegistryMu.Unlock()
+}
+
+// BlockType returns the normalized type of a raw block without decoding it.
+func BlockType(raw map[string]any) string {
+	if raw == nil {
+		return ""
+	}
+	return normalizeBlockType(stringFromAny(raw["type"]))
+}
+
+// BlockTitle returns the label a reader sees for a raw block: its title, a heading's text, or
+// a label, in that order. Blocks without one (quick checks, code, figures) return "".
+func BlockTitle(raw map[string]any) string {
+	if raw == nil {
+		return ""
+	}
+	for _, key := range []string{"title", "text", "label"} {
+		if s := strings.TrimSpace(stringFromAny(raw[key])); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func normalizeBlockType(t string) string {
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// DecodeBlock converts a raw block into its registered typed form. It never fails: values
+// that don't fit a typed field are left zero in the struct but preserved for EncodeBlock.
+func DecodeBlock(raw map[string]any) Block {
+	blockRegistryMu.RLock()
+	newBlock := blockRegistry[BlockType(raw)]
+	blockRegistryMu.RUnlock()
+	var b Block = &UnknownBlock{}
+	if newBlock != nil {
+		b = newBlock()
+	}
+	h := b.Header()
+	known := blockJSONKeys(b)
+	h.orig = map[string]any{}
+	for k, v := range raw {
+		if known[k] {
+			h.orig[k] = v
+			continue
+		}
+		if h.Extra == nil {
+			h.Extra = map[string]any{}
+		}
+		h.Extra[k] = v
+	}
+	// Decode field by field so one malformed value doesn't blank its neighbours.
+	fields := map[string]json.RawMessage{}
+	for k, v := range h.orig {
+		if enc, err := json.Marshal(v); err == nil {
+			fields[k] = enc
+		}
+	}
+	for k, enc := range fields {
+		one, _ := json.Marshal(map[string]json.RawMessage{k: enc})
+		_ = json.Unmarshal(one, b)
+	}
+	h.snap = encodeBlockFields(b)
+	return b
+}
+
+// EncodeBlock converts a typed block back to its raw form. Fields that weren't modified since
+// DecodeBlock are emitted exactly as they were read; new zero-valued fields are omitted.
+func EncodeBlock(b Block) map[string]any {
+	if b == nil {
+		return nil
+	}
+	h := b.Header()
+	out := make(map[string]any, len(h.Extra)+len(h.orig))
+	for k, v := range h.Extra {
+		out[k] = v
+	}
+	cur := encodeBlockFields(b)
+	for k, enc := range cur {
+		orig, had := h.orig[k]
+		if prev, ok := h.snap[k]; ok && bytes.Equal(prev, enc) {
+			if had {
+				out[k] = orig
+			}
+			continue
+		}
+		var val any
+		if err := json.Unmarshal(enc, &val); err != nil {
+			continue
+		}
+		if !had && isZeroJSON(val) {
+			continue
+		}
+		out[k] = overlayJSON(orig, val)
+	}
+	// An omitempty field missing from both snapshots was never touched (e.g. "concept_keys": []);
+	// one that was in snap but not cur was cleared and stays dropped.
+	for k, v := range h.orig {
+		_, inCur := cur[k]
+		_, inSnap := h.snap[k]
+		if !inCur && !inSnap {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// DecodeBlocks decodes every block of doc, in order.
+func DecodeBlocks(doc NodeDocV1) []Block {
+	out := make([]Block, 0, len(doc.Blocks))
+	for _, raw := range doc.Blocks {
+		if raw == nil {
+			continue
+		}
+		out = append(out, DecodeBlock(raw))
+	}
+	return out
+}
+
+// EncodeBlocks encodes blocks back into NodeDocV1 form.
+func EncodeBlocks(blocks []Block) []map[string]any {
+	out := make([]map[string]any, 0, len(blocks))
+	for _, b := range blocks {
+		if raw := EncodeBlock(b); raw != nil {
+			out = append(out, raw)
+		}
+	}
+	return out
+}
+
+// ForEachBlock decodes each block and calls fn; blocks for which fn returns true are encoded
+// back into doc.Blocks in place. It reports whether any block was written.
+func ForEachBlock(doc *NodeDocV1, fn func(i int, b Block) bool) bool {
+	if doc == nil || fn == nil {
+		return false
+	}
+	changed := false
+	for i, raw := range doc.Blocks {
+		if raw == nil {
+			continue
+		}
+		b := DecodeBlock(raw)
+		if fn(i, b) {
+			doc.Blocks[i] = EncodeBlock(b)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// ForEachFigure visits figure blocks; see ForEachBlock for the write-back contract.
+func ForEachFigure(doc *NodeDocV1, fn func(i int, b *FigureBlock) bool) bool {
+	return ForEachBlock(doc, func(i int, b Block) bool {
+		f, ok := b.(*FigureBlock)
+		return ok && fn(i, f)
+	})
+}
+
+// ForEachBlockAsset visits the asset of every block that carries one: figures, plus any other
+// block type with a top-level "asset" object (e.g. diagram, video_frame). Asset fields
+// MediaRefV1 doesn't know are kept on write-back; see ForEachBlock for the contract.
+func ForEachBlockAsset(doc *NodeDocV1, fn func(i int, blockType string, asset *MediaRefV1) bool) bool {
+	return ForEachBlock(doc, func(i int, b Block) bool {
+		if f, ok := b.(*FigureBlock); ok {
+			return fn(i, f.Type, &f.Asset)
+		}
+		h := b.Header()
+		raw, ok := h.Extra["asset"].(map[string]any)
+		if !ok {
+			return false
+		}
+		var asset MediaRefV1
+		enc, err := json.Marshal(raw)
+		if err != nil || json.Unmarshal(enc, &asset) != nil {
+			return false
+		}
+		before, _ := json.Marshal(asset)
+		if !fn(i, h.Type, &asset) {
+			return false
+		}
+		after, _ := json.Marshal(asset)
+		if bytes.Equal(before, after) {
+			return false
+		}
+		var val any
+		if err := json.Unmarshal(after, &val); err != nil {
+			return false
+		}
+		h.Extra["asset"] = overlayJSON(raw, val)
+		return true
+	})
+}
+
+// ForEachQuiz visits quick_check blocks; see ForEachBlock for the write-back contract.
+func ForEachQuiz(doc *NodeDocV1, fn func(i int, b *QuizBlock) bool) bool {
+	return ForEachBlock(doc, func(i int, b Block) bool {
+		q, ok := b.(*QuizBlock)
+		return ok && fn(i, q)
+	})
+}
+
+func encodeBlockFields(b Block) map[string]json.RawMessage {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return nil
+	}
+	out := map[string]json.RawMessage{}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+var blockKeysCache sync.Map // reflect.Type -> map[string]bool
+
+// blockJSONKeys lists the JSON keys a typed block owns, including the embedded header's.
+func blockJSONKeys(b Block) map[string]bool {
+	t := reflect.TypeOf(b)
+	if v, ok := blockKeysCache.Load(t); ok {
+		return v.(map[string]bool)
+	}
+	keys := map[string]bool{}
+	collectJSONKeys(t.Elem(), keys)
+	blockKeysCache.Store(t, keys)
+	return keys
+}
+
+func collectJSONKeys(t reflect.Type, keys map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			collectJSONKeys(f.Type, keys)
+			continue
+		}
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		keys[name] = true
+	}
+}
+
+// overlayJSON writes next over prev, keeping keys of nested objects that next doesn't set
+// (e.g. extra figure asset fields) so editing one field never strips unknown siblings.
+func overlayJSON(prev, next any) any {
+	pm, ok1 := prev.(map[string]any)
+	nm, ok2 := next.(map[string]any)
+	if !ok1 || !ok2 {
+		return next
+	}
+	out := make(map[string]any, len(pm)+len(nm))
+	for k, v := range pm {
+		out[k] = v
+	}
+	for k, v := range nm {
+		old, had := pm[k]
+		if !had && isZeroJSON(v) {
+			continue
+		}
+		out[k] = overlayJSON(old, v)
+	}
+	return out
+}
+
+func isZeroJSON(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	case []any:
+		return len(t) == 0
+	case map[string]any:
+		for _, x := range t {
+			if !isZeroJSON(x) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
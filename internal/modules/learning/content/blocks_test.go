@@ -0,0 +1,253 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadNodeDocFixture(t *testing.T, name string) NodeDocV1 {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var doc NodeDocV1
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	return doc
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
+
+func TestBlocksRoundTripIsByteIdentical(t *testing.T) {
+	for _, name := range []string{"node_doc_binary_search.json", "node_doc_legacy_shapes.json"} {
+		t.Run(name, func(t *testing.T) {
+			doc := loadNodeDocFixture(t, name)
+			want := mustMarshal(t, doc)
+
+			typed := DecodeBlocks(doc)
+			if len(typed) != len(doc.Blocks) {
+				t.Fatalf("decoded %d of %d blocks", len(typed), len(doc.Blocks))
+			}
+			doc.Blocks = EncodeBlocks(typed)
+			if got := mustMarshal(t, doc); !bytes.Equal(got, want) {
+				t.Fatalf("round trip changed the doc:\n got %s\nwant %s", got, want)
+			}
+
+			// Visiting without changes must not write anything either.
+			if ForEachBlock(&doc, func(int, Block) bool { return false }) {
+				t.Fatalf("no-op visitor reported a change")
+			}
+		})
+	}
+}
+
+func TestDecodeBlockTypes(t *testing.T) {
+	doc := loadNodeDocFixture(t, "node_doc_binary_search.json")
+	typed := DecodeBlocks(doc)
+
+	fig, ok := typed[6].(*FigureBlock)
+	if !ok {
+		t.Fatalf("block 6 decoded as %T", typed[6])
+	}
+	if fig.ID != "b-fig" || fig.Asset.StorageKey != "generated/figures/p/n/fig1.png" || len(fig.Citations) != 1 {
+		t.Fatalf("figure: %+v", fig)
+	}
+	if _, ok := fig.Extra["render_hint"]; !ok {
+		t.Fatalf("unknown block field not kept in Extra: %v", fig.Extra)
+	}
+	if q, ok := typed[11].(*QuizBlock); !ok || q.AnswerID != "a" || len(q.Options) != 2 {
+		t.Fatalf("quick_check: %#v", typed[11])
+	}
+	if p, ok := typed[3].(*MdBlock); !ok || strings.Join(p.ConceptKeys, ",") != "binary_search" {
+		t.Fatalf("paragraph header concept keys: %#v", typed[3])
+	}
+
+	legacy := DecodeBlocks(loadNodeDocFixture(t, "node_doc_legacy_shapes.json"))
+	if h, ok := legacy[0].(*HeadingBlock); !ok || h.Level != 3 {
+		t.Fatalf("mixed-case heading: %#v", legacy[0])
+	}
+	if u, ok := legacy[2].(*UnknownBlock); !ok || u.Extra["payload"] == nil {
+		t.Fatalf("unregistered type should keep its payload: %#v", legacy[2])
+	}
+}
+
+func TestEncodeBlockKeepsUnknownFieldsOnEdit(t *testing.T) {
+	doc := loadNodeDocFixture(t, "node_doc_binary_search.json")
+
+	changed := ForEachFigure(&doc, func(_ int, f *FigureBlock) bool {
+		f.Asset.URL = "/api/path-nodes/n/assets/view?key=k"
+		return true
+	})
+	if !changed {
+		t.Fatalf("expected a figure edit")
+	}
+	fig := doc.Blocks[6]
+	asset, _ := fig["asset"].(map[string]any)
+	if asset["url"] != "/api/path-nodes/n/assets/view?key=k" {
+		t.Fatalf("url not written: %v", asset)
+	}
+	if asset["width"] != float64(1024) || asset["alt"] == nil {
+		t.Fatalf("nested unknown asset fields dropped: %v", asset)
+	}
+	if _, ok := fig["render_hint"]; !ok {
+		t.Fatalf("block-level unknown field dropped: %v", fig)
+	}
+
+	// Only the figure block may differ from the fixture.
+	orig := loadNodeDocFixture(t, "node_doc_binary_search.json")
+	for i := range orig.Blocks {
+		if i == 6 {
+			continue
+		}
+		if !bytes.Equal(mustMarshal(t, orig.Blocks[i]), mustMarshal(t, doc.Blocks[i])) {
+			t.Fatalf("block %d changed", i)
+		}
+	}
+}
+
+func TestEncodeNewBlockOmitsZeroFields(t *testing.T) {
+	raw := EncodeBlock(&CalloutBlock{
+		BlockHeader: BlockHeader{ID: "c1", Type: "callout"},
+		Variant:     "info",
+		Title:       "Heads up",
+		MD:          "Body",
+	})
+	got := string(mustMarshal(t, raw))
+	want := `{"id":"c1","md":"Body","title":"Heads up","type":"callout","variant":"info"}`
+	if got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestEncodeBlockClearsOmitemptyField(t *testing.T) {
+	b := DecodeBlock(map[string]any{"id": "f1", "type": "flashcard", "front_md": "F", "back_md": "B", "concept_keys": []any{"x"}})
+	b.Header().ConceptKeys = nil
+	raw := EncodeBlock(b)
+	if _, ok := raw["concept_keys"]; ok {
+		t.Fatalf("cleared concept_keys still encoded: %v", raw)
+	}
+	if raw["front_md"] != "F" {
+		t.Fatalf("untouched field lost: %v", raw)
+	}
+}
+
+func TestValidateNodeDocV1TypedBlocks(t *testing.T) {
+	doc := loadNodeDocFixture(t, "node_doc_binary_search.json")
+	req := NodeDocRequirements{RequireExample: true}
+	errs, _ := ValidateNodeDocV1(doc, nil, req)
+	if len(errs) != 0 {
+		t.Fatalf("clean fixture should validate, got %v", errs)
+	}
+
+	legacy := loadNodeDocFixture(t, "node_doc_legacy_shapes.json")
+	errs, _ = ValidateNodeDocV1(legacy, nil, NodeDocRequirements{})
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{
+		`block[1] citations missing`,
+		`block[2] unknown type "drill"`,
+		`block[3] callout.variant invalid ("note")`,
+		`block[4] heading.level must be 2-4 (got 0)`,
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("missing %q in %v", want, errs)
+		}
+	}
+	if strings.Contains(joined, "block[5] figure.asset.url missing") {
+		t.Fatalf("figure with a url flagged: %v", errs)
+	}
+}
+
+func TestValidateNodeDocV1MisconceptionKeys(t *testing.T) {
+	doc := NodeDocV1{Blocks: []map[string]any{{
+		"id":        "qc1",
+		"type":      "quick_check",
+		"kind":      "mcq",
+		"prompt_md": "Which is the median of 1, 2, 9?",
+		"answer_md": "2 is the middle value.",
+		"answer_id": "b",
+		"options": []any{
+			map[string]any{"id": "a", "text": "4", "misconception_key": "confuses_mean_with_median"},
+			map[string]any{"id": "b", "text": "2", "misconception_key": "picks_middle"},
+			map[string]any{"id": "c", "text": "9", "misconception_key": "Largest Value"},
+			map[string]any{"id": "d", "text": "1"},
+		},
+	}}}
+	errs, _ := ValidateNodeDocV1(doc, nil, NodeDocRequirements{})
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{
+		`block[0] quick_check.options[1].misconception_key set on the correct answer`,
+		`block[0] quick_check.options[2].misconception_key "Largest Value" must be a lowercase slug`,
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("missing %q in %v", want, errs)
+		}
+	}
+	if strings.Contains(joined, "options[0].misconception_key") || strings.Contains(joined, "options[3].misconception_key") {
+		t.Fatalf("valid or absent keys flagged: %v", errs)
+	}
+	if got := NormalizeMisconceptionKey(" Largest Value "); got != "largest_value" {
+		t.Fatalf("NormalizeMisconceptionKey = %q", got)
+	}
+}
+
+func TestEnsureNodeDocBlockIDsDuplicates(t *testing.T) {
+	newDoc := func() NodeDocV1 {
+		return NodeDocV1{Blocks: []map[string]any{
+			{"id": "intro", "type": "paragraph", "md": "a"},
+			{"id": "intro", "type": "paragraph", "md": "b"},
+			{"id": "intro_2", "type": "paragraph", "md": "c"},
+			{"id": "intro", "type": "callout", "md": "d"},
+		}}
+	}
+	if got := DuplicateNodeDocBlockIDs(newDoc()); len(got) != 1 || got[0] != "intro" {
+		t.Fatalf("duplicates = %v", got)
+	}
+
+	doc, changed := EnsureNodeDocBlockIDs(newDoc())
+	if !changed {
+		t.Fatalf("duplicates not reported as a change")
+	}
+	want := []string{"intro", "intro_3", "intro_2", "intro_4"}
+	for i, b := range doc.Blocks {
+		if b["id"] != want[i] {
+			t.Fatalf("block %d id = %v, want %s", i, b["id"], want[i])
+		}
+	}
+	if dups := DuplicateNodeDocBlockIDs(doc); len(dups) != 0 {
+		t.Fatalf("duplicates left: %v", dups)
+	}
+
+	again, _ := EnsureNodeDocBlockIDs(newDoc())
+	for i := range again.Blocks {
+		if again.Blocks[i]["id"] != doc.Blocks[i]["id"] {
+			t.Fatalf("ids not deterministic at %d: %v vs %v", i, again.Blocks[i]["id"], doc.Blocks[i]["id"])
+		}
+	}
+	if _, changed := EnsureNodeDocBlockIDs(doc); changed {
+		t.Fatalf("unique ids rewritten")
+	}
+
+	errs, _ := ValidateNodeDocV1(newDoc(), nil, NodeDocRequirements{})
+	found := false
+	for _, e := range errs {
+		if strings.Contains(e, `block id duplicate "intro"`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("validator did not flag duplicates: %v", errs)
+	}
+}
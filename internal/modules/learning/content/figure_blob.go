@@ -0,0 +1,43 @@
+package content
+
+import "strings"
+
+// FigureBlobPrefix is the content-addressed storage prefix for shared generated figures.
+// Keys look like generated/figures_cas/<sha256>.<ext>.
+const FigureBlobPrefix = "generated/figures_cas/"
+
+// figureBlobExtMimes maps each extension FigureBlobStorageKey writes to a mime type that yields it.
+var figureBlobExtMimes = map[string]string{".png": "image/png", ".jpg": "image/jpeg", ".webp": "image/webp"}
+
+// FigureBlobStorageKey returns the shared storage key for an image with the given content hash.
+func FigureBlobStorageKey(contentHash string, mime string) string {
+	ext := ".png"
+	switch strings.ToLower(strings.TrimSpace(mime)) {
+	case "image/jpeg", "image/jpg":
+		ext = ".jpg"
+	case "image/webp":
+		ext = ".webp"
+	}
+	return FigureBlobPrefix + strings.TrimSpace(contentHash) + ext
+}
+
+// FigureBlobHashFromKey extracts the content hash from a content-addressed figure key. Only
+// keys FigureBlobStorageKey could have produced are accepted: one hash segment followed by a
+// known extension.
+func FigureBlobHashFromKey(storageKey string) (string, bool) {
+	storageKey = strings.TrimSpace(storageKey)
+	if !strings.HasPrefix(storageKey, FigureBlobPrefix) {
+		return "", false
+	}
+	rest := strings.TrimPrefix(storageKey, FigureBlobPrefix)
+	i := strings.IndexByte(rest, '.')
+	if i <= 0 {
+		return "", false
+	}
+	hash, ext := rest[:i], rest[i:]
+	mime, ok := figureBlobExtMimes[ext]
+	if !ok || strings.ContainsAny(hash, "/\\ \t\r\n") || storageKey != FigureBlobStorageKey(hash, mime) {
+		return "", false
+	}
+	return hash, true
+}
@@ -0,0 +1,43 @@
+package content
+
+import "testing"
+
+func TestFigureBlobStorageKeyRoundTrip(t *testing.T) {
+	hash := HashBytes([]byte("png-bytes"))
+	key := FigureBlobStorageKey(hash, "image/png")
+	if key != FigureBlobPrefix+hash+".png" {
+		t.Fatalf("unexpected key %q", key)
+	}
+	got, ok := FigureBlobHashFromKey(key)
+	if !ok || got != hash {
+		t.Fatalf("FigureBlobHashFromKey(%q) = %q, %v", key, got, ok)
+	}
+	if FigureBlobStorageKey(hash, "image/jpeg") != FigureBlobPrefix+hash+".jpg" {
+		t.Fatalf("expected jpg extension for image/jpeg")
+	}
+	for _, mime := range []string{"image/jpeg", "image/webp"} {
+		if got, ok := FigureBlobHashFromKey(FigureBlobStorageKey(hash, mime)); !ok || got != hash {
+			t.Fatalf("FigureBlobHashFromKey(%s key) = %q, %v", mime, got, ok)
+		}
+	}
+}
+
+func TestFigureBlobHashFromKeyRejectsOtherKeys(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"generated/node_figures/p/n/slot_1_abc.png",
+		FigureBlobPrefix,
+		FigureBlobPrefix + "nested/abc.png",
+		FigureBlobPrefix + "abc",
+		FigureBlobPrefix + ".png",
+		FigureBlobPrefix + "abc.gif",
+		FigureBlobPrefix + "abc.png.html",
+		FigureBlobPrefix + "abc.png/../../materials/x.png",
+		FigureBlobPrefix + "abc.PNG",
+		FigureBlobPrefix + "a bc.png",
+	} {
+		if got, ok := FigureBlobHashFromKey(key); ok {
+			t.Fatalf("expected %q to be rejected, got %q", key, got)
+		}
+	}
+}
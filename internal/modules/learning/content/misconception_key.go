@@ -0,0 +1,43 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A quick_check option may carry a misconception_key naming the misconception a learner most
+// likely holds when choosing that distractor. Keys are short lowercase slugs so the same
+// misconception tagged in different docs folds into one misconception instance per concept.
+var misconceptionKeyRE = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+var misconceptionKeySepRE = regexp.MustCompile(`[\s\-/]+`)
+
+// NormalizeMisconceptionKey lowercases the key and folds spaces, dashes and slashes into
+// underscores. It returns "" when the result is still not a valid key.
+func NormalizeMisconceptionKey(key string) string {
+	k := strings.ToLower(strings.TrimSpace(key))
+	if k == "" {
+		return ""
+	}
+	k = strings.Trim(misconceptionKeySepRE.ReplaceAllString(k, "_"), "_")
+	if !misconceptionKeyRE.MatchString(k) {
+		return ""
+	}
+	return k
+}
+
+func ValidMisconceptionKey(key string) bool {
+	return misconceptionKeyRE.MatchString(key)
+}
+
+func normalizeOptionMisconceptionKeys(opts []DrillQuestionOptionV1) []DrillQuestionOptionV1 {
+	if len(opts) == 0 {
+		return opts
+	}
+	out := make([]DrillQuestionOptionV1, len(opts))
+	for i, o := range opts {
+		o.MisconceptionKey = NormalizeMisconceptionKey(o.MisconceptionKey)
+		out[i] = o
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+)
+
+// NodeAudioPrefix is the storage prefix holding a node's narration segments.
+// Keys look like generated/node_audio/<path_id>/<node_id>/<text_hash>.<ext>.
+func NodeAudioPrefix(pathID, nodeID string) string {
+	return "generated/node_audio/" + pathID + "/" + nodeID + "/"
+}
+
+// NarrationSegment is one synthesizable chunk of a node doc. Segments never span blocks, so a
+// playback position always maps back to exactly one block; long blocks split into parts.
+type NarrationSegment struct {
+	BlockID   string `json:"block_id,omitempty"` // empty for the title/summary intro
+	BlockType string `json:"block_type"`
+	Part      int    `json:"part"`
+	Text      string `json:"text"`
+}
+
+// Quiz and code blocks are interactive or unreadable aloud; equations are LaTeX.
+var narrationSkippedBlockTypes = map[string]bool{
+	"quick_check": true,
+	"flashcard":   true,
+	"code":        true,
+	"equation":    true,
+	"user_md":     true,
+}
+
+// RenderNodeDocPlainText renders the doc as speakable plain text (no quiz, code, or markdown).
+func RenderNodeDocPlainText(doc NodeDocV1) string {
+	segs := NodeDocNarrationSegments(doc, 0)
+	parts := make([]string, 0, len(segs))
+	for _, s := range segs {
+		parts = append(parts, s.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// NodeDocNarrationSegments splits the doc into block-aligned segments of at most maxChars
+// bytes each (0 = no limit), in reading order.
+func NodeDocNarrationSegments(doc NodeDocV1, maxChars int) []NarrationSegment {
+	out := []NarrationSegment{}
+	add := func(blockID, blockType, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		for i, chunk := range splitNarrationText(text, maxChars) {
+			out = append(out, NarrationSegment{BlockID: blockID, BlockType: blockType, Part: i, Text: chunk})
+		}
+	}
+
+	add("", "title", joinSentences(speakableMarkdown(doc.Title), speakableMarkdown(doc.Summary)))
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		t := strings.ToLower(strings.TrimSpace(stringFromAny(b["type"])))
+		if narrationSkippedBlockTypes[t] {
+			continue
+		}
+		id, _ := b["id"].(string)
+		add(strings.TrimSpace(id), t, narrationBlockText(t, b))
+	}
+	return out
+}
+
+func narrationBlockText(t string, b map[string]any) string {
+	str := func(key string) string { return speakableMarkdown(stringFromAny(b[key])) }
+	switch t {
+	case "heading":
+		return str("text")
+	case "paragraph":
+		return str("md")
+	case "callout", "intuition", "mental_model", "why_it_matters":
+		return joinSentences(str("title"), str("md"))
+	case "figure", "video", "diagram", "table":
+		return str("caption")
+	case "objectives", "prerequisites", "key_takeaways", "common_mistakes", "misconceptions", "edge_cases", "heuristics", "checklist", "connections":
+		return joinSentences(append([]string{str("title")}, speakableList(stringSliceFromAny(b["items_md"]))...)...)
+	case "steps":
+		return joinSentences(append([]string{str("title")}, speakableList(stringSliceFromAny(b["steps_md"]))...)...)
+	case "glossary":
+		parts := []string{str("title")}
+		for _, m := range mapsFromAny(b["terms"]) {
+			term := speakableMarkdown(stringFromAny(m["term"]))
+			def := speakableMarkdown(stringFromAny(m["definition_md"]))
+			if term != "" && def != "" {
+				parts = append(parts, term+": "+def)
+			}
+		}
+		return joinSentences(parts...)
+	case "faq":
+		parts := []string{str("title")}
+		for _, m := range mapsFromAny(b["qas"]) {
+			parts = append(parts, speakableMarkdown(stringFromAny(m["question_md"])), speakableMarkdown(stringFromAny(m["answer_md"])))
+		}
+		return joinSentences(parts...)
+	}
+	return ""
+}
+
+func mapsFromAny(v any) []map[string]any {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(arr))
+	for _, it := range arr {
+		if m, ok := it.(map[string]any); ok && m != nil {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func speakableList(items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, speakableMarkdown(it))
+	}
+	return out
+}
+
+// joinSentences joins non-empty parts, ending each with punctuation so TTS pauses between them.
+func joinSentences(parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.ContainsAny(p[len(p)-1:], ".!?:;") {
+			p += "."
+		}
+		out = append(out, p)
+	}
+	return strings.Join(out, " ")
+}
+
+var (
+	narrationImageRe      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	narrationLinkRe       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	narrationInlineMathRe = regexp.MustCompile(`\$[^$\n]+\$`)
+	narrationListMarkRe   = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+[.)])\s+`)
+	narrationSpaceRe      = regexp.MustCompile(`\s+`)
+)
+
+func speakableMarkdown(s string) string {
+	s = narrationImageRe.ReplaceAllString(s, " ")
+	s = narrationLinkRe.ReplaceAllString(s, "$1")
+	s = narrationInlineMathRe.ReplaceAllString(s, " ")
+	s = narrationListMarkRe.ReplaceAllString(s, "")
+	s = strings.NewReplacer("`", "", "**", "", "__", "", "*", "", "#", "", ">", " ").Replace(s)
+	return strings.TrimSpace(narrationSpaceRe.ReplaceAllString(s, " "))
+}
+
+// splitNarrationText breaks text at sentence boundaries (falling back to word boundaries) so
+// every chunk is at most maxChars bytes.
+func splitNarrationText(text string, maxChars int) []string {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return []string{text}
+	}
+	var out []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			out = append(out, s)
+		}
+		cur.Reset()
+	}
+	for _, sentence := range splitSentences(text) {
+		if cur.Len() > 0 && cur.Len()+1+len(sentence) > maxChars {
+			flush()
+		}
+		if len(sentence) > maxChars {
+			for _, w := range strings.Fields(sentence) {
+				if cur.Len() > 0 && cur.Len()+1+len(w) > maxChars {
+					flush()
+				}
+				if cur.Len() > 0 {
+					cur.WriteByte(' ')
+				}
+				cur.WriteString(w)
+			}
+			continue
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(sentence)
+	}
+	flush()
+	return out
+}
+
+func splitSentences(text string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				if s := strings.TrimSpace(text[start : i+1]); s != "" {
+					out = append(out, s)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
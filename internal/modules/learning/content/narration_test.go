@@ -0,0 +1,68 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNodeDocNarrationSegments(t *testing.T) {
+	doc := NodeDocV1{
+		Title:   "Loops",
+		Summary: "Repeat work with `for`",
+		Blocks: []map[string]any{
+			{"id": "h1", "type": "heading", "text": "## Why loops"},
+			{"id": "p1", "type": "paragraph", "md": "A **loop** repeats. See [docs](https://example.com) and $x^2$ here."},
+			{"id": "c1", "type": "code", "code": "for {}"},
+			{"id": "q1", "type": "quick_check", "prompt_md": "What repeats?", "answer_md": "A loop"},
+			{"id": "k1", "type": "key_takeaways", "title": "Takeaways", "items_md": []any{"- Loops repeat", "Stop conditions matter"}},
+			{"id": "f1", "type": "figure", "caption": ""},
+		},
+	}
+	segs := NodeDocNarrationSegments(doc, 0)
+	want := []NarrationSegment{
+		{BlockID: "", BlockType: "title", Text: "Loops. Repeat work with for."},
+		{BlockID: "h1", BlockType: "heading", Text: "Why loops"},
+		{BlockID: "p1", BlockType: "paragraph", Text: "A loop repeats. See docs and here."},
+		{BlockID: "k1", BlockType: "key_takeaways", Text: "Takeaways. Loops repeat. Stop conditions matter."},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments %+v, want %d", len(segs), segs, len(want))
+	}
+	for i := range want {
+		if segs[i] != want[i] {
+			t.Fatalf("segment %d: got %+v want %+v", i, segs[i], want[i])
+		}
+	}
+
+	plain := RenderNodeDocPlainText(doc)
+	if strings.Contains(plain, "for {}") || strings.Contains(plain, "What repeats") {
+		t.Fatalf("plain text leaked code or quiz content: %q", plain)
+	}
+}
+
+func TestNodeDocNarrationSegmentsSplitsLongBlocks(t *testing.T) {
+	sentence := "This sentence is exactly fifty bytes long, really. "
+	doc := NodeDocV1{Blocks: []map[string]any{
+		{"id": "p1", "type": "paragraph", "md": strings.Repeat(sentence, 5)},
+		{"id": "p2", "type": "paragraph", "md": strings.Repeat("word ", 40)},
+	}}
+	segs := NodeDocNarrationSegments(doc, 120)
+	var p1, p2 int
+	for i, s := range segs {
+		if len(s.Text) > 120 {
+			t.Fatalf("segment %d exceeds limit: %d bytes", i, len(s.Text))
+		}
+		switch s.BlockID {
+		case "p1":
+			if s.Part != p1 {
+				t.Fatalf("p1 part order: got %d want %d", s.Part, p1)
+			}
+			p1++
+		case "p2":
+			p2++
+		}
+	}
+	if p1 != 3 || p2 < 2 {
+		t.Fatalf("expected p1 split into 3 parts and p2 split on words, got p1=%d p2=%d", p1, p2)
+	}
+}
@@ -0,0 +1,142 @@
+package content
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NodeDocConceptStaleKey holds, in the doc row's metadata, the concepts an incremental concept
+// graph update changed after the doc was written.
+const NodeDocConceptStaleKey = "concept_stale"
+
+// Concept change kinds recorded by the incremental concept graph update.
+const (
+	ConceptChangeSummary     = "summary_changed"
+	ConceptChangePrereqAdded = "prereq_added"
+	ConceptChangeMerged      = "merged"
+)
+
+// NodeDocConceptChange is one changed concept in a doc's concept_stale entry.
+type NodeDocConceptChange struct {
+	ConceptID string    `json:"concept_id"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name,omitempty"`
+	Kinds     []string  `json:"kinds"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type nodeDocConceptStale struct {
+	Concepts []NodeDocConceptChange `json:"concepts"`
+}
+
+// NodeDocConceptStale returns the changed concepts meta records for the doc, ordered by key.
+func NodeDocConceptStale(meta []byte) []NodeDocConceptChange {
+	if len(meta) == 0 || string(meta) == "null" {
+		return nil
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(meta, &m) != nil {
+		return nil
+	}
+	raw, ok := m[NodeDocConceptStaleKey]
+	if !ok {
+		return nil
+	}
+	var entry nodeDocConceptStale
+	if json.Unmarshal(raw, &entry) != nil {
+		return nil
+	}
+	return entry.Concepts
+}
+
+// MergeNodeDocConceptChanges folds changes into the doc's current entries: a concept already
+// listed gains the new kinds and the later changed_at. The result is ordered by key.
+func MergeNodeDocConceptChanges(current, changes []NodeDocConceptChange) []NodeDocConceptChange {
+	byKey := map[string]*NodeDocConceptChange{}
+	for _, list := range [][]NodeDocConceptChange{current, changes} {
+		for _, c := range list {
+			key := strings.TrimSpace(c.Key)
+			if key == "" {
+				continue
+			}
+			prev := byKey[key]
+			if prev == nil {
+				c.Key = key
+				c.Kinds = append([]string(nil), c.Kinds...)
+				byKey[key] = &c
+				continue
+			}
+			if strings.TrimSpace(c.ConceptID) != "" {
+				prev.ConceptID = c.ConceptID
+			}
+			if strings.TrimSpace(c.Name) != "" {
+				prev.Name = c.Name
+			}
+			for _, k := range c.Kinds {
+				if !containsString(prev.Kinds, k) {
+					prev.Kinds = append(prev.Kinds, k)
+				}
+			}
+			if c.ChangedAt.After(prev.ChangedAt) {
+				prev.ChangedAt = c.ChangedAt
+			}
+		}
+	}
+	out := make([]NodeDocConceptChange, 0, len(byKey))
+	for _, c := range byKey {
+		sort.Strings(c.Kinds)
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// NodeDocConceptStaleValue is the metadata value for entries; nil (JSON null) when empty.
+func NodeDocConceptStaleValue(entries []NodeDocConceptChange) any {
+	if len(entries) == 0 {
+		return nil
+	}
+	return nodeDocConceptStale{Concepts: entries}
+}
+
+// WithoutNodeDocConceptStale drops the entries for keys from meta, removing the concept_stale
+// key once none are left. It reports whether anything was dropped; other keys are kept.
+func WithoutNodeDocConceptStale(meta []byte, keys []string) ([]byte, bool) {
+	current := NodeDocConceptStale(meta)
+	if len(current) == 0 || len(keys) == 0 {
+		return meta, false
+	}
+	drop := map[string]bool{}
+	for _, k := range keys {
+		drop[strings.TrimSpace(k)] = true
+	}
+	kept := make([]NodeDocConceptChange, 0, len(current))
+	for _, c := range current {
+		if !drop[strings.TrimSpace(c.Key)] {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == len(current) {
+		return meta, false
+	}
+	m := map[string]any{}
+	_ = json.Unmarshal(meta, &m)
+	if len(kept) == 0 {
+		delete(m, NodeDocConceptStaleKey)
+	} else {
+		m[NodeDocConceptStaleKey] = nodeDocConceptStale{Concepts: kept}
+	}
+	out, _ := json.Marshal(m)
+	return out, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
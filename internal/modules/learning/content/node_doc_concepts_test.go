@@ -0,0 +1,42 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNodeDocConceptStaleMergeAndClear(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+	entries := MergeNodeDocConceptChanges(nil, []NodeDocConceptChange{
+		{ConceptID: "c1", Key: "bayes_theorem", Name: "Bayes' theorem", Kinds: []string{ConceptChangeSummary}, ChangedAt: t0},
+		{ConceptID: "c2", Key: "priors", Kinds: []string{ConceptChangeMerged}, ChangedAt: t0},
+	})
+	entries = MergeNodeDocConceptChanges(entries, []NodeDocConceptChange{
+		{ConceptID: "c1", Key: "bayes_theorem", Kinds: []string{ConceptChangePrereqAdded, ConceptChangeSummary}, ChangedAt: t1},
+	})
+	if len(entries) != 2 || entries[0].Key != "bayes_theorem" || entries[0].Name != "Bayes' theorem" || !entries[0].ChangedAt.Equal(t1) {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if want := []string{ConceptChangePrereqAdded, ConceptChangeSummary}; !reflect.DeepEqual(entries[0].Kinds, want) {
+		t.Fatalf("kinds = %v, want %v", entries[0].Kinds, want)
+	}
+
+	meta, _ := json.Marshal(map[string]any{"summary_stale": true, NodeDocConceptStaleKey: NodeDocConceptStaleValue(entries)})
+	if got := NodeDocConceptStale(meta); !reflect.DeepEqual(got, entries) {
+		t.Fatalf("round trip = %+v", got)
+	}
+	if _, ok := WithoutNodeDocConceptStale(meta, []string{"unrelated"}); ok {
+		t.Fatalf("cleared entries for a concept the doc does not list")
+	}
+	meta, ok := WithoutNodeDocConceptStale(meta, []string{"bayes_theorem"})
+	if got := NodeDocConceptStale(meta); !ok || len(got) != 1 || got[0].Key != "priors" {
+		t.Fatalf("after clearing bayes_theorem: %+v", got)
+	}
+	meta, _ = WithoutNodeDocConceptStale(meta, []string{"priors"})
+	if string(meta) != `{"summary_stale":true}` {
+		t.Fatalf("meta = %s", meta)
+	}
+}
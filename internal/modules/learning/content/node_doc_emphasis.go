@@ -0,0 +1,98 @@
+package content
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Doc row metadata keys recording the emphasis profile the doc was last regenerated with. A
+// full rebuild without a profile clears them.
+const (
+	NodeDocEmphasisProfileKey  = "emphasis_profile"
+	NodeDocEmphasisFreeTextKey = "emphasis_free_text"
+)
+
+// NodeDocEmphasisOf returns the emphasis profile and free text recorded in meta, if any.
+func NodeDocEmphasisOf(meta []byte) (profile, freeText string) {
+	if len(meta) == 0 || string(meta) == "null" {
+		return "", ""
+	}
+	var m map[string]any
+	if json.Unmarshal(meta, &m) != nil {
+		return "", ""
+	}
+	profile, _ = m[NodeDocEmphasisProfileKey].(string)
+	freeText, _ = m[NodeDocEmphasisFreeTextKey].(string)
+	return strings.TrimSpace(profile), strings.TrimSpace(freeText)
+}
+
+// WithNodeDocEmphasis returns meta recording the emphasis; other keys are kept.
+func WithNodeDocEmphasis(meta []byte, profile, freeText string) []byte {
+	m := map[string]any{}
+	if len(meta) > 0 && string(meta) != "null" {
+		_ = json.Unmarshal(meta, &m)
+		if m == nil {
+			m = map[string]any{}
+		}
+	}
+	m[NodeDocEmphasisProfileKey] = strings.TrimSpace(profile)
+	if strings.TrimSpace(freeText) != "" {
+		m[NodeDocEmphasisFreeTextKey] = strings.TrimSpace(freeText)
+	} else {
+		delete(m, NodeDocEmphasisFreeTextKey)
+	}
+	out, _ := json.Marshal(m)
+	return out
+}
+
+// CarryOverUserBlocks copies the user-authored blocks of prev (provenance source "user") into
+// next, which replaces prev. Each lands at the same relative position it held in prev; a block
+// ID already used by next gets a "_user" suffix. next is not modified. It returns the merged doc
+// and the number of blocks carried.
+func CarryOverUserBlocks(prev, next NodeDocV1) (NodeDocV1, int) {
+	type carried struct {
+		block map[string]any
+		at    int
+	}
+	var keep []carried
+	for i, b := range prev.Blocks {
+		if p, ok := BlockProvenanceOf(b); ok && p.Source == ProvenanceSourceUser {
+			// Round i/len(prev) of the way through next.
+			at := (2*i*len(next.Blocks) + len(prev.Blocks)) / (2 * len(prev.Blocks))
+			keep = append(keep, carried{block: b, at: at})
+		}
+	}
+	if len(keep) == 0 {
+		return next, 0
+	}
+
+	used := map[string]bool{}
+	for _, b := range next.Blocks {
+		used[strings.TrimSpace(stringFromAny(b["id"]))] = true
+	}
+	out := next
+	out.Blocks = make([]map[string]any, 0, len(next.Blocks)+len(keep))
+	k := 0
+	for i := 0; i <= len(next.Blocks); i++ {
+		for k < len(keep) && keep[k].at <= i {
+			b := make(map[string]any, len(keep[k].block))
+			for key, v := range keep[k].block {
+				b[key] = v
+			}
+			id := strings.TrimSpace(stringFromAny(b["id"]))
+			for id != "" && used[id] {
+				id += "_user"
+			}
+			if id != "" {
+				b["id"] = id
+				used[id] = true
+			}
+			out.Blocks = append(out.Blocks, b)
+			k++
+		}
+		if i < len(next.Blocks) {
+			out.Blocks = append(out.Blocks, next.Blocks[i])
+		}
+	}
+	return out, len(keep)
+}
@@ -503,6 +503,7 @@ func ConvertNodeDocGenV1ToV1(gen NodeDocGenV1) (NodeDocV1, []string) {
 				continue
 			}
 			q.ID = id
+			q.Options = normalizeOptionMisconceptionKeys(q.Options)
 			qcs[id] = q
 			qcSeq = append(qcSeq, q)
 		}
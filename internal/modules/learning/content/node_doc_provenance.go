@@ -0,0 +1,131 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BlockProvenanceKey is the reserved block key holding the block's provenance stamp. The stamp
+// is metadata, not content: it is kept out of prompts and the chat unit context.
+const BlockProvenanceKey = "_provenance"
+
+// Provenance sources.
+const (
+	ProvenanceSourceGeneration = "generation"
+	ProvenanceSourcePatch      = "patch"
+	ProvenanceSourceUser       = "user"
+)
+
+// BlockProvenance records what produced a block. It holds IDs only; readers resolve them.
+type BlockProvenance struct {
+	Source          string   `json:"source"`
+	GenerationRunID string   `json:"generation_run_id,omitempty"`
+	RevisionID      string   `json:"revision_id,omitempty"`
+	PromptVersion   string   `json:"prompt_version,omitempty"`
+	PolicyVersion   string   `json:"policy_version,omitempty"`
+	ChunkIDs        []string `json:"chunk_ids,omitempty"`
+}
+
+// StampBlockProvenance writes p onto block, replacing any earlier stamp. ChunkIDs are taken from
+// the block's own citations. The stamp is stored in its decoded JSON form so a stamped doc
+// compares equal before and after a canonicalization round trip.
+func StampBlockProvenance(block map[string]any, p BlockProvenance) {
+	if block == nil {
+		return
+	}
+	p.ChunkIDs = blockCitedChunkIDs(block)
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	var m map[string]any
+	if json.Unmarshal(raw, &m) != nil {
+		return
+	}
+	block[BlockProvenanceKey] = m
+}
+
+// StampNodeDocProvenance stamps every block of doc with p.
+func StampNodeDocProvenance(doc NodeDocV1, p BlockProvenance) {
+	for _, b := range doc.Blocks {
+		StampBlockProvenance(b, p)
+	}
+}
+
+// BlockProvenanceOf returns the stamp on block, if any.
+func BlockProvenanceOf(block map[string]any) (BlockProvenance, bool) {
+	var p BlockProvenance
+	v, ok := block[BlockProvenanceKey]
+	if !ok || v == nil {
+		return p, false
+	}
+	raw, err := json.Marshal(v)
+	if err != nil || json.Unmarshal(raw, &p) != nil || strings.TrimSpace(p.Source) == "" {
+		return BlockProvenance{}, false
+	}
+	return p, true
+}
+
+// WithoutBlockProvenance returns a shallow copy of block without the stamp, for prompts.
+func WithoutBlockProvenance(block map[string]any) map[string]any {
+	if _, ok := block[BlockProvenanceKey]; !ok {
+		return block
+	}
+	out := make(map[string]any, len(block))
+	for k, v := range block {
+		if k != BlockProvenanceKey {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// LintNodeDocProvenance warns about blocks without a provenance stamp. Docs with no stamped
+// block at all predate provenance and are not linted.
+func LintNodeDocProvenance(doc NodeDocV1) []string {
+	stamped := false
+	for _, b := range doc.Blocks {
+		if _, ok := BlockProvenanceOf(b); ok {
+			stamped = true
+			break
+		}
+	}
+	if !stamped {
+		return nil
+	}
+	var warnings []string
+	for i, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		if _, ok := BlockProvenanceOf(b); !ok {
+			warnings = append(warnings, fmt.Sprintf("block[%d] %s missing provenance", i, strings.TrimSpace(stringFromAny(b["id"]))))
+		}
+	}
+	return warnings
+}
+
+func blockCitedChunkIDs(block map[string]any) []string {
+	raw, err := json.Marshal(block["citations"])
+	if err != nil {
+		return nil
+	}
+	var refs []CitationRefV1
+	if json.Unmarshal(raw, &refs) != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, r := range refs {
+		id := strings.TrimSpace(r.ChunkID)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
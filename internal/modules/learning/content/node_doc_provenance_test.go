@@ -0,0 +1,85 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const provenanceDocJSON = `{"schema_version":1,"title":"Loops","blocks":[
+	{"id":"h1","type":"heading","level":2,"text":"For loops"},
+	{"id":"p1","type":"paragraph","md":"A for loop repeats.","citations":[{"chunk_id":"c2"},{"chunk_id":"c1"},{"chunk_id":"c2"}]}
+]}`
+
+func TestBlockProvenanceSurvivesCanonicalization(t *testing.T) {
+	var doc NodeDocV1
+	if err := json.Unmarshal([]byte(provenanceDocJSON), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	stamp := BlockProvenance{Source: ProvenanceSourceGeneration, GenerationRunID: "run-1", PromptVersion: "p@1", PolicyVersion: "pol@2"}
+	StampNodeDocProvenance(doc, stamp)
+
+	got, ok := BlockProvenanceOf(doc.Blocks[1])
+	if !ok || !reflect.DeepEqual(got.ChunkIDs, []string{"c1", "c2"}) || got.GenerationRunID != "run-1" {
+		t.Fatalf("stamp = %+v ok=%v", got, ok)
+	}
+
+	canon, err := CanonicalizeJSON(doc)
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+	var back NodeDocV1
+	if err := json.Unmarshal(canon, &back); err != nil {
+		t.Fatalf("decode canon: %v", err)
+	}
+	if !reflect.DeepEqual(back.Blocks, doc.Blocks) {
+		t.Fatalf("stamped blocks changed across canonicalization:\n got %v\nwant %v", back.Blocks, doc.Blocks)
+	}
+	again, _ := CanonicalizeJSON(back)
+	if string(again) != string(canon) {
+		t.Fatalf("canonical form not stable:\n%s\n%s", again, canon)
+	}
+
+	// The typed block layer keeps the stamp as an extra key.
+	for i, raw := range back.Blocks {
+		enc := EncodeBlock(DecodeBlock(raw))
+		if p, ok := BlockProvenanceOf(enc); !ok || !reflect.DeepEqual(p, mustProvenance(t, back.Blocks[i])) {
+			t.Fatalf("block %d lost provenance through DecodeBlock/EncodeBlock: %v", i, enc)
+		}
+	}
+
+	if _, ok := WithoutBlockProvenance(doc.Blocks[1])[BlockProvenanceKey]; ok {
+		t.Fatalf("WithoutBlockProvenance kept the stamp")
+	}
+	if _, ok := doc.Blocks[1][BlockProvenanceKey]; !ok {
+		t.Fatalf("WithoutBlockProvenance mutated its input")
+	}
+}
+
+func TestLintNodeDocProvenance(t *testing.T) {
+	var doc NodeDocV1
+	if err := json.Unmarshal([]byte(provenanceDocJSON), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if w := LintNodeDocProvenance(doc); w != nil {
+		t.Fatalf("legacy doc should not be linted: %v", w)
+	}
+	StampBlockProvenance(doc.Blocks[1], BlockProvenance{Source: ProvenanceSourcePatch, RevisionID: "rev-1"})
+	w := LintNodeDocProvenance(doc)
+	if len(w) != 1 || w[0] != "block[0] h1 missing provenance" {
+		t.Fatalf("warnings = %v", w)
+	}
+	StampNodeDocProvenance(doc, BlockProvenance{Source: ProvenanceSourceUser})
+	if w := LintNodeDocProvenance(doc); len(w) != 0 {
+		t.Fatalf("fully stamped doc warned: %v", w)
+	}
+}
+
+func mustProvenance(t *testing.T, block map[string]any) BlockProvenance {
+	t.Helper()
+	p, ok := BlockProvenanceOf(block)
+	if !ok {
+		t.Fatalf("block has no provenance: %v", block)
+	}
+	return p
+}
@@ -0,0 +1,226 @@
+package content
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// NodeDocSeeAlsoKey is the block field holding cross-references to earlier units that already
+// taught one of the block's concepts. The frontend renders them as "covered in <unit>" chips.
+const NodeDocSeeAlsoKey = "see_also"
+
+// maxSeeAlsoRefs caps the chips on one block.
+const maxSeeAlsoRefs = 3
+
+// SeeAlsoRefV1 points at the earlier unit (and, when one teaches the concept, the block) that
+// covered a concept. References are one-directional: only the later doc carries them.
+type SeeAlsoRefV1 struct {
+	NodeID    string `json:"node_id"`
+	NodeTitle string `json:"node_title"`
+	BlockID   string `json:"block_id,omitempty"`
+}
+
+// seeAlsoSkipTypes are blocks that never make a good cross-reference target: structure and
+// practice rather than teaching.
+var seeAlsoSkipTypes = map[string]bool{
+	"divider":     true,
+	"quick_check": true,
+	"flashcard":   true,
+}
+
+func seeAlsoConceptKey(k string) string { return strings.ToLower(strings.TrimSpace(k)) }
+
+func blockConceptKeySet(b map[string]any) map[string]bool {
+	keys := stringSliceFromAny(b["concept_keys"])
+	if len(keys) == 0 {
+		return nil
+	}
+	out := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k = seeAlsoConceptKey(k); k != "" {
+			out[k] = true
+		}
+	}
+	return out
+}
+
+func blockCitationCount(b map[string]any) int {
+	arr, _ := b["citations"].([]any)
+	seen := map[string]bool{}
+	for _, x := range arr {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id := strings.TrimSpace(stringFromAny(m["chunk_id"])); id != "" {
+			seen[id] = true
+		}
+	}
+	return len(seen)
+}
+
+// BestConceptBlockID returns the ID of the block in doc with the most evidence for conceptKey,
+// or "" when no block is about it. A block is about a concept when its own concept_keys list
+// it, or, lacking concept_keys, when the heading of its section does. Evidence is the number of
+// distinct chunks the block cites; ties go to the earlier block. Practice blocks and dividers
+// are never chosen.
+func BestConceptBlockID(doc NodeDocV1, conceptKey string) string {
+	key := seeAlsoConceptKey(conceptKey)
+	if key == "" {
+		return ""
+	}
+	bestID := ""
+	bestScore := -1
+	var section map[string]bool
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		typ := BlockType(b)
+		own := blockConceptKeySet(b)
+		if typ == "heading" {
+			section = own
+		}
+		keys := own
+		if keys == nil {
+			keys = section
+		}
+		if !keys[key] || seeAlsoSkipTypes[typ] {
+			continue
+		}
+		id := strings.TrimSpace(stringFromAny(b["id"]))
+		if id == "" {
+			continue
+		}
+		if score := blockCitationCount(b); score > bestScore {
+			bestID, bestScore = id, score
+		}
+	}
+	return bestID
+}
+
+// ApplyNodeDocSeeAlso sets see_also on every block whose concept_keys include a key of refs
+// (concept key -> reference) and drops it from blocks that no longer qualify. A block points at
+// each earlier unit once, in concept_keys order, up to maxSeeAlsoRefs. Returns whether any
+// block changed.
+func ApplyNodeDocSeeAlso(doc NodeDocV1, refs map[string]SeeAlsoRefV1) (NodeDocV1, bool) {
+	byKey := make(map[string]SeeAlsoRefV1, len(refs))
+	for k, ref := range refs {
+		if k = seeAlsoConceptKey(k); k != "" && strings.TrimSpace(ref.NodeID) != "" {
+			byKey[k] = ref
+		}
+	}
+	changed := false
+	for i, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		var next []SeeAlsoRefV1
+		seenNode := map[string]bool{}
+		for _, k := range stringSliceFromAny(b["concept_keys"]) {
+			ref, ok := byKey[seeAlsoConceptKey(k)]
+			if !ok || seenNode[ref.NodeID] || len(next) >= maxSeeAlsoRefs {
+				continue
+			}
+			seenNode[ref.NodeID] = true
+			next = append(next, ref)
+		}
+		if sameSeeAlso(b[NodeDocSeeAlsoKey], next) {
+			continue
+		}
+		if len(next) == 0 {
+			delete(b, NodeDocSeeAlsoKey)
+		} else {
+			b[NodeDocSeeAlsoKey] = seeAlsoToAny(next)
+		}
+		doc.Blocks[i] = b
+		changed = true
+	}
+	return doc, changed
+}
+
+// NodeDocSeeAlsoOf returns the block's see_also references.
+func NodeDocSeeAlsoOf(b map[string]any) []SeeAlsoRefV1 {
+	raw, ok := b[NodeDocSeeAlsoKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var refs []SeeAlsoRefV1
+	if json.Unmarshal(buf, &refs) != nil {
+		return nil
+	}
+	return refs
+}
+
+func sameSeeAlso(current any, next []SeeAlsoRefV1) bool {
+	if current == nil {
+		return len(next) == 0
+	}
+	have := NodeDocSeeAlsoOf(map[string]any{NodeDocSeeAlsoKey: current})
+	if len(have) != len(next) {
+		return false
+	}
+	for i := range have {
+		if have[i] != next[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func seeAlsoToAny(refs []SeeAlsoRefV1) []any {
+	out := make([]any, 0, len(refs))
+	for _, r := range refs {
+		m := map[string]any{
+			"node_id":    r.NodeID,
+			"node_title": r.NodeTitle,
+		}
+		if r.BlockID != "" {
+			m["block_id"] = r.BlockID
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// NodeDocContentHash hashes canonical doc JSON for the doc row's content_hash. see_also
+// annotations are left out: they only point at other units, so re-resolving them must not make
+// anything keyed on this doc's content (narration, staleness checks) look changed.
+func NodeDocContentHash(canon []byte) string {
+	var top map[string]json.RawMessage
+	if json.Unmarshal(canon, &top) != nil || top == nil {
+		return HashBytes(canon)
+	}
+	raw, ok := top["blocks"]
+	if !ok {
+		return HashBytes(canon)
+	}
+	var blocks []map[string]json.RawMessage
+	if json.Unmarshal(raw, &blocks) != nil {
+		return HashBytes(canon)
+	}
+	stripped := false
+	for _, b := range blocks {
+		if _, ok := b[NodeDocSeeAlsoKey]; ok {
+			delete(b, NodeDocSeeAlsoKey)
+			stripped = true
+		}
+	}
+	if !stripped {
+		return HashBytes(canon)
+	}
+	rawBlocks, err := json.Marshal(blocks)
+	if err != nil {
+		return HashBytes(canon)
+	}
+	top["blocks"] = rawBlocks
+	out, err := CanonicalizeJSON(top)
+	if err != nil {
+		return HashBytes(canon)
+	}
+	return HashBytes(out)
+}
@@ -0,0 +1,144 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const seeAlsoEarlierDocJSON = `{
+  "schema_version": 1,
+  "title": "Forces",
+  "concept_keys": ["net_force", "mass"],
+  "blocks": [
+    {"id": "h1", "type": "heading", "level": 2, "text": "Net force", "concept_keys": ["net_force"]},
+    {"id": "p1", "type": "paragraph", "md": "Intro.", "citations": [{"chunk_id": "c1", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}}]},
+    {"id": "p2", "type": "paragraph", "md": "Sum the forces.", "citations": [
+      {"chunk_id": "c1", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}},
+      {"chunk_id": "c2", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}}
+    ]},
+    {"id": "fc1", "type": "flashcard", "front_md": "?", "back_md": "!", "concept_keys": ["net_force"], "citations": [
+      {"chunk_id": "c1", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}},
+      {"chunk_id": "c2", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}},
+      {"chunk_id": "c3", "quote": "", "loc": {"page": 0, "start": 0, "end": 0}}
+    ]},
+    {"id": "h2", "type": "heading", "level": 2, "text": "Mass"},
+    {"id": "p3", "type": "paragraph", "md": "Mass resists.", "concept_keys": ["Mass"], "citations": []}
+  ]
+}`
+
+func decodeSeeAlsoDoc(t *testing.T, raw string) NodeDocV1 {
+	t.Helper()
+	var doc NodeDocV1
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return doc
+}
+
+func TestBestConceptBlockID(t *testing.T) {
+	doc := decodeSeeAlsoDoc(t, seeAlsoEarlierDocJSON)
+	cases := map[string]string{
+		// Inherited from the section heading; p2 cites the most chunks, the flashcard is practice.
+		"net_force": "p2",
+		// Own concept_keys match case-insensitively even with no citations.
+		"mass":    "p3",
+		"inertia": "",
+		"":        "",
+	}
+	for key, want := range cases {
+		if got := BestConceptBlockID(doc, key); got != want {
+			t.Fatalf("BestConceptBlockID(%q) = %q, want %q", key, got, want)
+		}
+	}
+
+	// Ties go to the earlier block.
+	tie := NodeDocV1{Blocks: []map[string]any{
+		{"id": "a", "type": "paragraph", "concept_keys": []any{"k"}},
+		{"id": "b", "type": "paragraph", "concept_keys": []any{"k"}},
+	}}
+	if got := BestConceptBlockID(tie, "k"); got != "a" {
+		t.Fatalf("tie = %q, want a", got)
+	}
+}
+
+func TestApplyNodeDocSeeAlso(t *testing.T) {
+	doc := NodeDocV1{Blocks: []map[string]any{
+		{"id": "h1", "type": "heading", "text": "Recap", "concept_keys": []any{"net_force", "mass", "new_idea"}},
+		{"id": "p1", "type": "paragraph", "md": "x"},
+		{"id": "p2", "type": "paragraph", "md": "y", "concept_keys": []any{"new_idea"}, "see_also": []any{map[string]any{"node_id": "stale", "node_title": "Old"}}},
+	}}
+	refs := map[string]SeeAlsoRefV1{
+		"net_force": {NodeID: "n2", NodeTitle: "Forces", BlockID: "p2"},
+		"MASS":      {NodeID: "n2", NodeTitle: "Forces", BlockID: "p3"},
+	}
+	doc, changed := ApplyNodeDocSeeAlso(doc, refs)
+	if !changed {
+		t.Fatalf("expected a change")
+	}
+	want := []SeeAlsoRefV1{{NodeID: "n2", NodeTitle: "Forces", BlockID: "p2"}}
+	if got := NodeDocSeeAlsoOf(doc.Blocks[0]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("heading see_also = %+v, want one ref per earlier unit %+v", got, want)
+	}
+	if _, ok := doc.Blocks[1][NodeDocSeeAlsoKey]; ok {
+		t.Fatalf("block without concept_keys got see_also")
+	}
+	if _, ok := doc.Blocks[2][NodeDocSeeAlsoKey]; ok {
+		t.Fatalf("stale see_also was not dropped")
+	}
+	if _, again := ApplyNodeDocSeeAlso(doc, refs); again {
+		t.Fatalf("re-applying the same refs reported a change")
+	}
+}
+
+func TestNodeDocSeeAlsoSurvivesCanonicalization(t *testing.T) {
+	doc := NodeDocV1{SchemaVersion: 1, Blocks: []map[string]any{
+		{"id": "p1", "type": "paragraph", "md": "x", "concept_keys": []any{"k"}, "citations": []any{}},
+	}}
+	doc, _ = ApplyNodeDocSeeAlso(doc, map[string]SeeAlsoRefV1{"k": {NodeID: "n1", NodeTitle: "Unit 1"}})
+	want := NodeDocSeeAlsoOf(doc.Blocks[0])
+
+	canon, err := CanonicalizeJSON(doc)
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+	var back NodeDocV1
+	if err := json.Unmarshal(canon, &back); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := NodeDocSeeAlsoOf(back.Blocks[0]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after canonicalize see_also = %+v, want %+v", got, want)
+	}
+	back.Blocks = EncodeBlocks(DecodeBlocks(back))
+	if got := NodeDocSeeAlsoOf(back.Blocks[0]); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after typed round trip see_also = %+v, want %+v", got, want)
+	}
+}
+
+func TestNodeDocContentHashIgnoresSeeAlso(t *testing.T) {
+	plain := NodeDocV1{SchemaVersion: 1, Title: "T", Blocks: []map[string]any{
+		{"id": "p1", "type": "paragraph", "md": "x", "concept_keys": []any{"k"}},
+	}}
+	plainCanon, _ := CanonicalizeJSON(plain)
+
+	annotated := decodeSeeAlsoDoc(t, string(plainCanon))
+	annotated, _ = ApplyNodeDocSeeAlso(annotated, map[string]SeeAlsoRefV1{"k": {NodeID: "n1", NodeTitle: "Unit 1", BlockID: "p9"}})
+	annotatedCanon, _ := CanonicalizeJSON(annotated)
+
+	if string(plainCanon) == string(annotatedCanon) {
+		t.Fatalf("annotation did not change the doc JSON")
+	}
+	if NodeDocContentHash(plainCanon) != NodeDocContentHash(annotatedCanon) {
+		t.Fatalf("see_also changed the content hash")
+	}
+	if NodeDocContentHash(plainCanon) != HashBytes(plainCanon) {
+		t.Fatalf("hash of a doc without see_also should match HashBytes")
+	}
+
+	edited := decodeSeeAlsoDoc(t, string(annotatedCanon))
+	edited.Blocks[0]["md"] = "changed"
+	editedCanon, _ := CanonicalizeJSON(edited)
+	if NodeDocContentHash(editedCanon) == NodeDocContentHash(annotatedCanon) {
+		t.Fatalf("content edit did not change the hash")
+	}
+}
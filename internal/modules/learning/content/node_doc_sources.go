@@ -0,0 +1,74 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NodeDocSourcesStaleKey flags, in the doc row's metadata, that the doc cites chunks of a
+// material file the user has since deleted.
+const NodeDocSourcesStaleKey = "sources_stale"
+
+// NodeDocSourcesStale reports whether meta flags the doc's sources as stale.
+func NodeDocSourcesStale(meta []byte) bool {
+	return nodeDocMetaFlag(meta, NodeDocSourcesStaleKey)
+}
+
+// WithNodeDocSourcesStale returns meta with the sources_stale flag set; other keys are kept.
+func WithNodeDocSourcesStale(meta []byte, stale bool) []byte {
+	return withNodeDocMetaFlag(meta, NodeDocSourcesStaleKey, stale)
+}
+
+// RelinkNodeDocCitations rewrites block citation chunk IDs through relink (old -> new) and
+// returns the canonical doc JSON with the number of citations rewritten. Provenance stamps are
+// remapped too so they keep naming the chunks the block cites. Nothing else is touched.
+func RelinkNodeDocCitations(docJSON []byte, relink map[string]string) ([]byte, int, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(docJSON, &top); err != nil {
+		return nil, 0, err
+	}
+	if top == nil {
+		return nil, 0, fmt.Errorf("node doc is not an object")
+	}
+	var blocks []map[string]any
+	if raw, ok := top["blocks"]; ok {
+		if err := json.Unmarshal(raw, &blocks); err != nil {
+			return nil, 0, err
+		}
+	}
+	n := 0
+	for _, b := range blocks {
+		cites, _ := b["citations"].([]any)
+		changed := false
+		for _, c := range cites {
+			m, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			id, _ := m["chunk_id"].(string)
+			if to, ok := relink[strings.TrimSpace(id)]; ok && to != "" {
+				m["chunk_id"] = to
+				n++
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if p, ok := BlockProvenanceOf(b); ok {
+			StampBlockProvenance(b, p)
+		}
+	}
+	if n == 0 {
+		canon, err := CanonicalizeJSON(docJSON)
+		return canon, 0, err
+	}
+	raw, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, 0, err
+	}
+	top["blocks"] = raw
+	canon, err := CanonicalizeJSON(top)
+	return canon, n, err
+}
@@ -0,0 +1,106 @@
+package content
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRelinkNodeDocCitations(t *testing.T) {
+	var doc NodeDocV1
+	if err := json.Unmarshal([]byte(provenanceDocJSON), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	StampNodeDocProvenance(doc, BlockProvenance{Source: ProvenanceSourceGeneration, GenerationRunID: "run-1"})
+	raw, err := CanonicalizeJSON(doc)
+	if err != nil {
+		t.Fatalf("canonicalize: %v", err)
+	}
+
+	canon, n, err := RelinkNodeDocCitations(raw, map[string]string{"c2": "c9"})
+	if err != nil || n != 2 {
+		t.Fatalf("relink = %d, %v; want 2 rewrites", n, err)
+	}
+	var back NodeDocV1
+	if err := json.Unmarshal(canon, &back); err != nil {
+		t.Fatalf("decode relinked: %v", err)
+	}
+	if got := blockCitedChunkIDs(back.Blocks[1]); !reflect.DeepEqual(got, []string{"c1", "c9"}) {
+		t.Fatalf("citations = %v", got)
+	}
+	if p := mustProvenance(t, back.Blocks[1]); !reflect.DeepEqual(p.ChunkIDs, []string{"c1", "c9"}) || p.GenerationRunID != "run-1" {
+		t.Fatalf("provenance not remapped: %+v", p)
+	}
+
+	same, n, err := RelinkNodeDocCitations(raw, map[string]string{"zz": "c9"})
+	if err != nil || n != 0 || string(same) != string(raw) {
+		t.Fatalf("no-op relink changed the doc: n=%d err=%v", n, err)
+	}
+}
+
+func TestNodeDocSourcesStaleFlag(t *testing.T) {
+	meta := WithNodeDocSourcesStale([]byte(`{"summary_stale":true}`), true)
+	if !NodeDocSourcesStale(meta) || !NodeDocSummaryStale(meta) {
+		t.Fatalf("flags lost: %s", meta)
+	}
+	if NodeDocSourcesStale(WithNodeDocSourcesStale(meta, false)) {
+		t.Fatalf("flag not cleared")
+	}
+	if NodeDocSourcesStale(nil) {
+		t.Fatalf("empty metadata reported stale")
+	}
+}
+
+func TestCarryOverUserBlocks(t *testing.T) {
+	userBlock := map[string]any{"id": "b2", "type": "paragraph", "md": "my note"}
+	StampBlockProvenance(userBlock, BlockProvenance{Source: ProvenanceSourceUser})
+	prev := NodeDocV1{Blocks: []map[string]any{
+		{"id": "b1", "type": "paragraph"},
+		userBlock,
+		{"id": "b3", "type": "paragraph"},
+		{"id": "b4", "type": "paragraph"},
+	}}
+	next := NodeDocV1{Blocks: []map[string]any{
+		{"id": "b1", "type": "paragraph"},
+		{"id": "b2", "type": "paragraph"},
+		{"id": "n3", "type": "paragraph"},
+		{"id": "n4", "type": "paragraph"},
+		{"id": "n5", "type": "paragraph"},
+		{"id": "n6", "type": "paragraph"},
+		{"id": "n7", "type": "paragraph"},
+		{"id": "n8", "type": "paragraph"},
+	}}
+
+	merged, n := CarryOverUserBlocks(prev, next)
+	if n != 1 || len(merged.Blocks) != 9 || len(next.Blocks) != 8 {
+		t.Fatalf("carried %d, merged %d blocks, next %d", n, len(merged.Blocks), len(next.Blocks))
+	}
+	got := merged.Blocks[2]
+	if got["id"] != "b2_user" || got["md"] != "my note" {
+		t.Fatalf("block at 2 = %v", got)
+	}
+	if userBlock["id"] != "b2" {
+		t.Fatalf("prev block modified")
+	}
+
+	if _, n := CarryOverUserBlocks(next, prev); n != 0 {
+		t.Fatalf("carried %d blocks from a doc without user blocks", n)
+	}
+}
+
+func TestNodeDocEmphasisMetadata(t *testing.T) {
+	meta := WithNodeDocEmphasis([]byte(`{"summary_stale":true}`), "exam_prep", " past papers ")
+	if p, ft := NodeDocEmphasisOf(meta); p != "exam_prep" || ft != "past papers" {
+		t.Fatalf("emphasis = %q %q", p, ft)
+	}
+	if !NodeDocSummaryStale(meta) {
+		t.Fatalf("other keys dropped: %s", meta)
+	}
+	meta = WithNodeDocEmphasis(meta, "eli5", "")
+	if p, ft := NodeDocEmphasisOf(meta); p != "eli5" || ft != "" {
+		t.Fatalf("emphasis = %q %q", p, ft)
+	}
+	if p, _ := NodeDocEmphasisOf(nil); p != "" {
+		t.Fatalf("emphasis of nil = %q", p)
+	}
+}
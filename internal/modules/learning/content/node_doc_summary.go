@@ -0,0 +1,65 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NodeDocSummaryStaleKey flags, in the doc row's metadata, that block patches have drifted far
+// enough from the doc summary that it should be refreshed.
+const NodeDocSummaryStaleKey = "summary_stale"
+
+// NodeDocSummaryStale reports whether meta flags the doc summary as stale.
+func NodeDocSummaryStale(meta []byte) bool {
+	return nodeDocMetaFlag(meta, NodeDocSummaryStaleKey)
+}
+
+// WithNodeDocSummaryStale returns meta with the summary_stale flag set; other keys are kept.
+func WithNodeDocSummaryStale(meta []byte, stale bool) []byte {
+	return withNodeDocMetaFlag(meta, NodeDocSummaryStaleKey, stale)
+}
+
+func nodeDocMetaFlag(meta []byte, key string) bool {
+	if len(meta) == 0 || string(meta) == "null" {
+		return false
+	}
+	var m map[string]any
+	if json.Unmarshal(meta, &m) != nil {
+		return false
+	}
+	v, _ := m[key].(bool)
+	return v
+}
+
+func withNodeDocMetaFlag(meta []byte, key string, v bool) []byte {
+	m := map[string]any{}
+	if len(meta) > 0 && string(meta) != "null" {
+		_ = json.Unmarshal(meta, &m)
+		if m == nil {
+			m = map[string]any{}
+		}
+	}
+	m[key] = v
+	out, _ := json.Marshal(m)
+	return out
+}
+
+// ReplaceNodeDocSummary sets the top-level summary of a stored doc and returns the canonical JSON.
+// Only the summary key is touched; everything else (blocks, block IDs, unknown top-level keys) is
+// carried over as stored, so a summary refresh can never rewrite a block.
+func ReplaceNodeDocSummary(docJSON []byte, summary string) ([]byte, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(docJSON, &top); err != nil {
+		return nil, err
+	}
+	if top == nil {
+		return nil, fmt.Errorf("node doc is not an object")
+	}
+	raw, err := json.Marshal(strings.TrimSpace(summary))
+	if err != nil {
+		return nil, err
+	}
+	top["summary"] = raw
+	return CanonicalizeJSON(top)
+}
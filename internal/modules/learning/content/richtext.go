@@ -0,0 +1,605 @@
+package content
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rich-text issue codes reported by ValidateRichText.
+const (
+	RichTextUnterminatedFence = "unterminated_code_fence"
+	RichTextUnbalancedMath    = "unbalanced_display_math"
+	RichTextUnbalancedDelim   = "unbalanced_math_delimiter"
+	RichTextUnbalancedEnv     = "unbalanced_latex_env"
+	RichTextMalformedTable    = "malformed_table"
+)
+
+// RichTextIssue is one rendering problem found in a markdown field. Start/End are character (rune)
+// offsets into the field text as it was before any repair.
+type RichTextIssue struct {
+	BlockID  string `json:"block_id,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Repaired bool   `json:"repaired,omitempty"`
+}
+
+// RichTextReport splits a doc's issues into the ones that were mechanically repaired and the ones
+// left for a human (or a regeneration) to fix.
+type RichTextReport struct {
+	Repairs []RichTextIssue `json:"repairs,omitempty"`
+	Issues  []RichTextIssue `json:"issues,omitempty"`
+}
+
+func (r RichTextReport) Empty() bool { return len(r.Repairs) == 0 && len(r.Issues) == 0 }
+
+// Clean reports whether everything found was repaired.
+func (r RichTextReport) Clean() bool { return len(r.Issues) == 0 }
+
+func (r *RichTextReport) add(issues []RichTextIssue) {
+	for _, is := range issues {
+		if is.Repaired {
+			r.Repairs = append(r.Repairs, is)
+		} else {
+			r.Issues = append(r.Issues, is)
+		}
+	}
+}
+
+// ValidateRichText scans markdown for unterminated code fences, unbalanced math delimiters and
+// LaTeX environments, and malformed pipe tables. Safe mechanical repairs are applied (closing a
+// dangling fence, closing a lone $$ block, fixing a table's delimiter row); everything else is
+// reported unrepaired. Content inside code fences and inline code is never inspected for math, and
+// single $ is ignored entirely since it is indistinguishable from currency.
+func ValidateRichText(md string) (string, []RichTextIssue) {
+	if strings.TrimSpace(md) == "" {
+		return md, nil
+	}
+	s := newRichTextScan(md)
+	s.scanFences()
+	s.maskInlineCode()
+	s.scanMath(true)
+	s.scanTables()
+	return s.finish()
+}
+
+// validateLatex checks a raw LaTeX source (e.g. an equation block) for unbalanced environments and
+// delimiters. It never repairs.
+func validateLatex(src string) []RichTextIssue {
+	if strings.TrimSpace(src) == "" {
+		return nil
+	}
+	s := newRichTextScan(src)
+	s.scanMath(false)
+	_, issues := s.finish()
+	return issues
+}
+
+// ValidateBlockRichText runs ValidateRichText over every markdown field of a block (md, *_md,
+// caption, and the same keys inside list items such as terms/qas/options), writing repairs back in
+// place. Equation sources under "latex" are checked but never rewritten.
+func ValidateBlockRichText(block map[string]any) []RichTextIssue {
+	if block == nil {
+		return nil
+	}
+	blockID := strings.TrimSpace(stringFromAny(block["id"]))
+	var out []RichTextIssue
+	tag := func(field string, issues []RichTextIssue) {
+		for _, is := range issues {
+			is.BlockID = blockID
+			is.Field = field
+			out = append(out, is)
+		}
+	}
+
+	for _, key := range sortedKeys(block) {
+		switch v := block[key].(type) {
+		case string:
+			if key == "latex" {
+				tag(key, validateLatex(v))
+				continue
+			}
+			if !isRichTextKey(key) {
+				continue
+			}
+			fixed, issues := ValidateRichText(v)
+			if fixed != v {
+				block[key] = fixed
+			}
+			tag(key, issues)
+		case []any:
+			for j := range v {
+				switch item := v[j].(type) {
+				case string:
+					if !isRichTextKey(key) {
+						continue
+					}
+					fixed, issues := ValidateRichText(item)
+					if fixed != item {
+						v[j] = fixed
+					}
+					tag(fmt.Sprintf("%s[%d]", key, j), issues)
+				case map[string]any:
+					for _, sub := range sortedKeys(item) {
+						str, ok := item[sub].(string)
+						if !ok || !isRichTextKey(sub) {
+							continue
+						}
+						fixed, issues := ValidateRichText(str)
+						if fixed != str {
+							item[sub] = fixed
+						}
+						tag(fmt.Sprintf("%s[%d].%s", key, j, sub), issues)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// ValidateNodeDocRichText applies ValidateBlockRichText to every block of doc. Blocks are repaired
+// in place, so callers that need the original must copy first.
+func ValidateNodeDocRichText(doc NodeDocV1) (NodeDocV1, RichTextReport) {
+	var report RichTextReport
+	for _, b := range doc.Blocks {
+		report.add(ValidateBlockRichText(b))
+	}
+	return doc, report
+}
+
+func isRichTextKey(k string) bool {
+	return k == "md" || k == "caption" || strings.HasSuffix(k, "_md")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type richTextLine struct {
+	start int
+	end   int // exclusive, without the newline
+}
+
+type richTextEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+type richTextScan struct {
+	src string
+	// mask is src with fenced code and inline code blanked to spaces (newlines kept), so offsets
+	// line up with src while code content is invisible to the math and table passes.
+	mask   []byte
+	lines  []richTextLine
+	edits  []richTextEdit
+	issues []RichTextIssue
+}
+
+func newRichTextScan(src string) *richTextScan {
+	s := &richTextScan{src: src, mask: []byte(src)}
+	start := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			s.lines = append(s.lines, richTextLine{start: start, end: i})
+			start = i + 1
+		}
+	}
+	s.lines = append(s.lines, richTextLine{start: start, end: len(src)})
+	return s
+}
+
+func (s *richTextScan) report(code, msg string, start, end int, repaired bool) {
+	s.issues = append(s.issues, RichTextIssue{Code: code, Message: msg, Start: start, End: end, Repaired: repaired})
+}
+
+func (s *richTextScan) blank(start, end int) {
+	for i := start; i < end && i < len(s.mask); i++ {
+		if s.mask[i] != '\n' {
+			s.mask[i] = ' '
+		}
+	}
+}
+
+func (s *richTextScan) maskedLine(i int) string {
+	ln := s.lines[i]
+	return string(s.mask[ln.start:ln.end])
+}
+
+// finish applies edits back to front and converts byte offsets to rune offsets.
+func (s *richTextScan) finish() (string, []RichTextIssue) {
+	out := s.src
+	sort.SliceStable(s.edits, func(i, j int) bool { return s.edits[i].start > s.edits[j].start })
+	for _, e := range s.edits {
+		out = out[:e.start] + e.text + out[e.end:]
+	}
+	for i := range s.issues {
+		s.issues[i].Start = utf8.RuneCountInString(s.src[:s.issues[i].Start])
+		s.issues[i].End = utf8.RuneCountInString(s.src[:s.issues[i].End])
+	}
+	sort.SliceStable(s.issues, func(i, j int) bool { return s.issues[i].Start < s.issues[j].Start })
+	return out, s.issues
+}
+
+// containerPrefix returns the leading whitespace, blockquote markers and list markers of a line.
+func containerPrefix(line string) string {
+	i := 0
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		if i < len(line) && line[i] == '>' {
+			i++
+			continue
+		}
+		if i+1 < len(line) && (line[i] == '-' || line[i] == '*' || line[i] == '+') && line[i+1] == ' ' {
+			i += 2
+			continue
+		}
+		j := i
+		for j < len(line) && j-i < 9 && line[j] >= '0' && line[j] <= '9' {
+			j++
+		}
+		if j > i && j+1 < len(line) && (line[j] == '.' || line[j] == ')') && line[j+1] == ' ' {
+			i = j + 2
+			continue
+		}
+		return line[:i]
+	}
+}
+
+func fenceRun(s string) (byte, int) {
+	if s == "" || (s[0] != '`' && s[0] != '~') {
+		return 0, 0
+	}
+	n := 0
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	if n < 3 {
+		return 0, 0
+	}
+	return s[0], n
+}
+
+func (s *richTextScan) scanFences() {
+	openLine := -1
+	var openCh byte
+	openN := 0
+	openIndent := ""
+	for i, ln := range s.lines {
+		text := s.src[ln.start:ln.end]
+		prefix := containerPrefix(text)
+		rest := text[len(prefix):]
+		ch, n := fenceRun(rest)
+		if openLine < 0 {
+			if n == 0 {
+				continue
+			}
+			// A backtick run followed by more backticks on the line is inline code, not a fence.
+			if ch == '`' && strings.Contains(rest[n:], "`") {
+				continue
+			}
+			openLine, openCh, openN = i, ch, n
+			openIndent = strings.Repeat(" ", utf8.RuneCountInString(prefix))
+			continue
+		}
+		if ch == openCh && n >= openN && strings.TrimSpace(rest[n:]) == "" {
+			s.blank(s.lines[openLine].start, ln.end)
+			openLine = -1
+		}
+	}
+	if openLine < 0 {
+		return
+	}
+	start := s.lines[openLine].start
+	s.blank(start, len(s.src))
+	closer := openIndent + strings.Repeat(string(openCh), openN)
+	if strings.HasSuffix(s.src, "\n") {
+		closer += "\n"
+	} else {
+		closer = "\n" + closer
+	}
+	s.edits = append(s.edits, richTextEdit{start: len(s.src), end: len(s.src), text: closer})
+	s.report(RichTextUnterminatedFence, "code fence is never closed", start, s.lines[openLine].end, true)
+}
+
+func (s *richTextScan) maskInlineCode() {
+	m := s.mask
+	for i := 0; i < len(m); {
+		if m[i] != '`' {
+			i++
+			continue
+		}
+		k := 0
+		for i+k < len(m) && m[i+k] == '`' {
+			k++
+		}
+		closeAt := -1
+		for j := i + k; j < len(m); {
+			if m[j] != '`' {
+				j++
+				continue
+			}
+			n := 0
+			for j+n < len(m) && m[j+n] == '`' {
+				n++
+			}
+			if n == k {
+				closeAt = j
+				break
+			}
+			j += n
+		}
+		if closeAt < 0 {
+			i += k
+			continue
+		}
+		s.blank(i, closeAt+k)
+		i = closeAt + k
+	}
+}
+
+type latexEnv struct {
+	name  string
+	start int
+	end   int
+}
+
+func latexEnvAt(m string, i int, cmd string) (string, int, bool) {
+	if !strings.HasPrefix(m[i:], cmd) {
+		return "", 0, false
+	}
+	close := strings.IndexByte(m[i+len(cmd):], '}')
+	if close <= 0 {
+		return "", 0, false
+	}
+	name := m[i+len(cmd) : i+len(cmd)+close]
+	if strings.ContainsAny(name, " \n\\{") {
+		return "", 0, false
+	}
+	return name, len(cmd) + close + 1, true
+}
+
+// scanMath checks \( \) and \[ \] pairs and \begin/\end environments; with dollars set it also
+// pairs $$ display delimiters.
+func (s *richTextScan) scanMath(dollars bool) {
+	m := string(s.mask)
+	var displays []int
+	type delim struct {
+		pos  int
+		kind byte
+	}
+	var delims []delim
+	var envs []latexEnv
+
+	for i := 0; i < len(m); i++ {
+		switch m[i] {
+		case '$':
+			if dollars && i+1 < len(m) && m[i+1] == '$' {
+				displays = append(displays, i)
+				i++
+			}
+		case '\\':
+			if i+1 >= len(m) {
+				continue
+			}
+			switch next := m[i+1]; next {
+			case '(', '[':
+				delims = append(delims, delim{pos: i, kind: next})
+				i++
+			case ')', ']':
+				want := byte('(')
+				if next == ']' {
+					want = '['
+				}
+				if len(delims) > 0 && delims[len(delims)-1].kind == want {
+					delims = delims[:len(delims)-1]
+				} else {
+					s.report(RichTextUnbalancedDelim, fmt.Sprintf("\\%c has no matching \\%c", next, want), i, i+2, false)
+				}
+				i++
+			case 'b', 'e':
+				if name, n, ok := latexEnvAt(m, i, `\begin{`); ok {
+					envs = append(envs, latexEnv{name: name, start: i, end: i + n})
+					i += n - 1
+					continue
+				}
+				if name, n, ok := latexEnvAt(m, i, `\end{`); ok {
+					match := -1
+					for k := len(envs) - 1; k >= 0; k-- {
+						if envs[k].name == name {
+							match = k
+							break
+						}
+					}
+					if match < 0 {
+						s.report(RichTextUnbalancedEnv, fmt.Sprintf("\\end{%s} has no matching \\begin", name), i, i+n, false)
+					} else {
+						for _, e := range envs[match+1:] {
+							s.report(RichTextUnbalancedEnv, fmt.Sprintf("\\begin{%s} is never closed", e.name), e.start, e.end, false)
+						}
+						envs = envs[:match]
+					}
+					i += n - 1
+					continue
+				}
+				i++
+			default:
+				// Skip the escaped character (covers \$ and \\).
+				i++
+			}
+		}
+	}
+	for _, d := range delims {
+		closer := byte(')')
+		if d.kind == '[' {
+			closer = ']'
+		}
+		s.report(RichTextUnbalancedDelim, fmt.Sprintf("\\%c is never closed with \\%c", d.kind, closer), d.pos, d.pos+2, false)
+	}
+	for _, e := range envs {
+		s.report(RichTextUnbalancedEnv, fmt.Sprintf("\\begin{%s} is never closed", e.name), e.start, e.end, false)
+	}
+	if len(displays)%2 == 0 {
+		return
+	}
+	last := displays[len(displays)-1]
+	if len(displays) == 1 && s.closeDisplayMath(last) {
+		s.report(RichTextUnbalancedMath, "$$ block is never closed", last, last+2, true)
+		return
+	}
+	s.report(RichTextUnbalancedMath, "odd number of $$ delimiters", last, last+2, false)
+}
+
+// closeDisplayMath closes a lone $$ that opens a line (after any list/quote markers) at the end of
+// its paragraph. Anything else is ambiguous and left alone.
+func (s *richTextScan) closeDisplayMath(pos int) bool {
+	li := s.lineAt(pos)
+	ln := s.lines[li]
+	prefix := s.src[ln.start:pos]
+	if containerPrefix(prefix) != prefix {
+		return false
+	}
+	last := li
+	for last+1 < len(s.lines) {
+		next := s.maskedLine(last + 1)
+		// A blank line or the next list item ends the paragraph.
+		if strings.TrimSpace(next) == "" || strings.Trim(containerPrefix(next), " \t>") != "" {
+			break
+		}
+		last++
+	}
+	end := s.lines[last].end
+	for end > pos+2 && (s.mask[end-1] == ' ' || s.mask[end-1] == '\t') {
+		end--
+	}
+	body := string(s.mask[pos+2 : end])
+	if strings.TrimSpace(body) == "" {
+		return false
+	}
+	text := "$$"
+	if strings.TrimSpace(s.src[pos+2:ln.end]) == "" {
+		text = "\n" + strings.Repeat(" ", utf8.RuneCountInString(prefix)) + "$$"
+	}
+	s.edits = append(s.edits, richTextEdit{start: end, end: end, text: text})
+	return true
+}
+
+func (s *richTextScan) lineAt(pos int) int {
+	return sort.Search(len(s.lines), func(i int) bool { return s.lines[i].end >= pos })
+}
+
+func tableRow(line string) bool {
+	t := strings.TrimSpace(line)
+	return len(t) >= 2 && t[0] == '|' && t[len(t)-1] == '|'
+}
+
+func tableCells(line string) []string {
+	t := strings.TrimSpace(line)
+	t = strings.TrimPrefix(t, "|")
+	if strings.HasSuffix(t, "|") && !strings.HasSuffix(t, `\|`) {
+		t = t[:len(t)-1]
+	}
+	var cells []string
+	start := 0
+	for i := 0; i < len(t); i++ {
+		if t[i] == '\\' {
+			i++
+			continue
+		}
+		if t[i] == '|' {
+			cells = append(cells, strings.TrimSpace(t[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(t[start:]))
+}
+
+func delimiterCell(c string) bool {
+	c = strings.TrimSuffix(strings.TrimPrefix(c, ":"), ":")
+	return c != "" && strings.Trim(c, "-") == ""
+}
+
+func delimiterRow(line string) bool {
+	if !tableRow(line) {
+		return false
+	}
+	for _, c := range tableCells(line) {
+		if !delimiterCell(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func buildDelimiterRow(indent string, cols int, align []string) string {
+	cells := make([]string, cols)
+	for i := range cells {
+		cells[i] = "---"
+		if i < len(align) && delimiterCell(align[i]) {
+			cells[i] = align[i]
+		}
+	}
+	return indent + "| " + strings.Join(cells, " | ") + " |"
+}
+
+// scanTables looks at runs of pipe-delimited lines. GFM only renders a table when the second row
+// is a delimiter row with the header's column count; both are repaired when the fix is obvious.
+func (s *richTextScan) scanTables() {
+	for i := 0; i < len(s.lines); {
+		if !tableRow(s.maskedLine(i)) {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(s.lines) && tableRow(s.maskedLine(j+1)) {
+			j++
+		}
+		if j > i {
+			s.checkTable(i, j)
+		}
+		i = j + 1
+	}
+}
+
+func (s *richTextScan) checkTable(first, last int) {
+	header := s.maskedLine(first)
+	indent := header[:len(header)-len(strings.TrimLeft(header, " \t"))]
+	headerCells := tableCells(header)
+	start, end := s.lines[first].start, s.lines[last].end
+	second := s.maskedLine(first + 1)
+
+	switch {
+	case delimiterRow(header):
+		s.report(RichTextMalformedTable, "table has no header row", start, end, false)
+	case delimiterRow(second):
+		delimCells := tableCells(second)
+		if len(delimCells) == len(headerCells) {
+			return
+		}
+		ln := s.lines[first+1]
+		s.edits = append(s.edits, richTextEdit{start: ln.start, end: ln.end, text: buildDelimiterRow(indent, len(headerCells), delimCells)})
+		s.report(RichTextMalformedTable, fmt.Sprintf("delimiter row has %d columns, header has %d", len(delimCells), len(headerCells)), start, end, true)
+	default:
+		for k := first + 1; k <= last; k++ {
+			if len(tableCells(s.maskedLine(k))) != len(headerCells) {
+				s.report(RichTextMalformedTable, "table is missing its delimiter row", start, end, false)
+				return
+			}
+		}
+		at := s.lines[first].end
+		s.edits = append(s.edits, richTextEdit{start: at, end: at, text: "\n" + buildDelimiterRow(indent, len(headerCells), nil)})
+		s.report(RichTextMalformedTable, "table is missing its delimiter row", start, end, true)
+	}
+}
@@ -0,0 +1,204 @@
+package content
+
+import (
+	"testing"
+)
+
+func TestValidateRichText(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		want     string
+		codes    []string
+		repaired []bool
+	}{
+		{
+			name: "clean prose with math and code",
+			in:   "Use $$x^2$$ and `$$` in code.\n\n```go\nfmt.Println(\"$$\")\n```\n",
+			want: "Use $$x^2$$ and `$$` in code.\n\n```go\nfmt.Println(\"$$\")\n```\n",
+		},
+		{
+			name: "dollar as currency",
+			in:   "It costs $5 and $10, or $$ for short.\n\nA latte is $4.50.",
+			want: "It costs $5 and $10, or $$ for short.\n\nA latte is $4.50.",
+			// "$$ for short" is mid-line, so it is reported rather than guessed at.
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{false},
+		},
+		{
+			name: "single dollars never flagged",
+			in:   "Prices: $3, $7 and $12.",
+			want: "Prices: $3, $7 and $12.",
+		},
+		{
+			name:     "unterminated fence is closed",
+			in:       "Example:\n\n```python\nprint(1)",
+			want:     "Example:\n\n```python\nprint(1)\n```",
+			codes:    []string{RichTextUnterminatedFence},
+			repaired: []bool{true},
+		},
+		{
+			name: "nested fences keep inner fence as content",
+			in:   "````md\n```go\nx := \"$$\"\n```\n````\n\n$$y$$",
+			want: "````md\n```go\nx := \"$$\"\n```\n````\n\n$$y$$",
+		},
+		{
+			name:     "nested fence with missing outer close",
+			in:       "````md\n```go\n$$ \\begin{align}\n```\n",
+			want:     "````md\n```go\n$$ \\begin{align}\n```\n````\n",
+			codes:    []string{RichTextUnterminatedFence},
+			repaired: []bool{true},
+		},
+		{
+			name:     "dangling display math closed at paragraph end",
+			in:       "$$\nE = mc^2\n\nNext paragraph.",
+			want:     "$$\nE = mc^2\n$$\n\nNext paragraph.",
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{true},
+		},
+		{
+			name:     "math inside list item",
+			in:       "- First\n- $$a^2 + b^2\n- Third",
+			want:     "- First\n- $$a^2 + b^2$$\n- Third",
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{true},
+		},
+		{
+			name:     "own-line math inside list keeps indent",
+			in:       "1. Area:\n   $$\n   \\pi r^2\n2. Done",
+			want:     "1. Area:\n   $$\n   \\pi r^2\n   $$\n2. Done",
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{true},
+		},
+		{
+			name:     "three delimiters are ambiguous",
+			in:       "$$a$$ then $$b",
+			want:     "$$a$$ then $$b",
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{false},
+		},
+		{
+			name:     "math delimiters inside code fence ignored when balancing",
+			in:       "$$\nx\n```\n$$\n```",
+			want:     "$$\nx\n$$\n```\n$$\n```",
+			codes:    []string{RichTextUnbalancedMath},
+			repaired: []bool{true},
+		},
+		{
+			name:     "begin without end",
+			in:       "$$\\begin{aligned} a &= b $$",
+			want:     "$$\\begin{aligned} a &= b $$",
+			codes:    []string{RichTextUnbalancedEnv},
+			repaired: []bool{false},
+		},
+		{
+			name:     "mismatched environments",
+			in:       "\\begin{cases} x \\end{matrix}",
+			want:     "\\begin{cases} x \\end{matrix}",
+			codes:    []string{RichTextUnbalancedEnv, RichTextUnbalancedEnv},
+			repaired: []bool{false, false},
+		},
+		{
+			name:     "unclosed inline delimiter",
+			in:       "Here \\( x + 1 is open.",
+			want:     "Here \\( x + 1 is open.",
+			codes:    []string{RichTextUnbalancedDelim},
+			repaired: []bool{false},
+		},
+		{
+			name: "escaped dollars and backslashes",
+			in:   "Literal \\$\\$ and a line break \\\\ here.",
+			want: "Literal \\$\\$ and a line break \\\\ here.",
+		},
+		{
+			name:     "table missing delimiter row",
+			in:       "| a | b |\n| 1 | 2 |",
+			want:     "| a | b |\n| --- | --- |\n| 1 | 2 |",
+			codes:    []string{RichTextMalformedTable},
+			repaired: []bool{true},
+		},
+		{
+			name:     "table delimiter column mismatch",
+			in:       "| a | b | c |\n|:--|--:|\n| 1 | 2 | 3 |",
+			want:     "| a | b | c |\n| :-- | --: | --- |\n| 1 | 2 | 3 |",
+			codes:    []string{RichTextMalformedTable},
+			repaired: []bool{true},
+		},
+		{
+			name:     "ragged table without delimiter is reported",
+			in:       "| a | b |\n| 1 | 2 | 3 |",
+			want:     "| a | b |\n| 1 | 2 | 3 |",
+			codes:    []string{RichTextMalformedTable},
+			repaired: []bool{false},
+		},
+		{
+			name: "well formed table",
+			in:   "| a | b |\n|---|---|\n| `x|y` | $$z$$ |",
+			want: "| a | b |\n|---|---|\n| `x|y` | $$z$$ |",
+		},
+		{
+			name: "table inside fence ignored",
+			in:   "```\n| a | b |\n| 1 | 2 |\n```",
+			want: "```\n| a | b |\n| 1 | 2 |\n```",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, issues := ValidateRichText(tc.in)
+			if got != tc.want {
+				t.Fatalf("repaired text:\n got %q\nwant %q", got, tc.want)
+			}
+			if len(issues) != len(tc.codes) {
+				t.Fatalf("issues: got %+v, want codes %v", issues, tc.codes)
+			}
+			for i, is := range issues {
+				if is.Code != tc.codes[i] || is.Repaired != tc.repaired[i] {
+					t.Fatalf("issue %d: got %+v, want code=%s repaired=%v", i, is, tc.codes[i], tc.repaired[i])
+				}
+				if is.Start < 0 || is.End < is.Start {
+					t.Fatalf("issue %d has bad offsets: %+v", i, is)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRichTextOffsetsAreRunes(t *testing.T) {
+	_, issues := ValidateRichText("héllo \\begin{x}")
+	if len(issues) != 1 {
+		t.Fatalf("got %+v", issues)
+	}
+	if issues[0].Start != 6 || issues[0].End != 15 {
+		t.Fatalf("got offsets %d-%d, want 6-15", issues[0].Start, issues[0].End)
+	}
+}
+
+func TestValidateNodeDocRichText(t *testing.T) {
+	doc := NodeDocV1{Blocks: []map[string]any{
+		{"id": "p1", "type": "paragraph", "md": "```go\nfmt.Println()"},
+		{"id": "k1", "type": "key_takeaways", "items_md": []any{"ok", "$$x"}},
+		{"id": "g1", "type": "glossary", "terms": []any{map[string]any{"term": "T", "definition_md": "\\begin{cases}"}}},
+		{"id": "e1", "type": "equation", "latex": "\\begin{aligned} x"},
+		{"id": "c1", "type": "code", "code": "```"},
+	}}
+	doc, report := ValidateNodeDocRichText(doc)
+	if got := doc.Blocks[0]["md"]; got != "```go\nfmt.Println()\n```" {
+		t.Fatalf("paragraph not repaired: %q", got)
+	}
+	if got := doc.Blocks[1]["items_md"].([]any)[1]; got != "$$x$$" {
+		t.Fatalf("list item not repaired: %q", got)
+	}
+	if doc.Blocks[4]["code"] != "```" {
+		t.Fatalf("code block source must not be touched")
+	}
+	if len(report.Repairs) != 2 || len(report.Issues) != 2 || report.Clean() {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if is := report.Issues[0]; is.BlockID != "g1" || is.Field != "terms[0].definition_md" {
+		t.Fatalf("issue not attributed to block/field: %+v", is)
+	}
+	if is := report.Issues[1]; is.BlockID != "e1" || is.Field != "latex" {
+		t.Fatalf("equation issue not attributed: %+v", is)
+	}
+}
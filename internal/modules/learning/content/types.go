@@ -148,6 +148,8 @@ type DrillFlashcardV1 struct {
 type DrillQuestionOptionV1 struct {
 	ID   string `json:"id"`
 	Text string `json:"text"`
+	// MisconceptionKey tags a distractor with the misconception that choosing it suggests.
+	MisconceptionKey string `json:"misconception_key,omitempty"`
 }
 
 type DrillQuestionV1 struct {
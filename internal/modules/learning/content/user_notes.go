@@ -0,0 +1,91 @@
+package content
+
+import (
+	"context"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
+)
+
+// UserNotePlaceholder stands in for learner-authored block bodies in doc_text, so search and
+// embeddings never see (or index ciphertext of) a user's notes.
+const UserNotePlaceholder = "[private note]"
+
+// SealUserBlocks encrypts the body of every user_md block that isn't sealed yet. Call it before
+// persisting a doc that may carry new learner-authored text; it reports whether doc changed.
+func SealUserBlocks(ctx context.Context, doc *NodeDocV1, env *fieldcrypt.Envelope) (bool, error) {
+	return transformUserBlocks(doc, func(md string) (string, error) {
+		return env.Seal(ctx, md)
+	})
+}
+
+// OpenUserBlocks decrypts user_md bodies for serving. Bodies that fail to open are replaced
+// by UserNotePlaceholder rather than leaking ciphertext; the first such error is returned.
+func OpenUserBlocks(ctx context.Context, doc *NodeDocV1, env *fieldcrypt.Envelope) (bool, error) {
+	var firstErr error
+	changed, _ := transformUserBlocks(doc, func(md string) (string, error) {
+		pt, err := env.Open(ctx, md)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return UserNotePlaceholder, nil
+		}
+		return pt, nil
+	})
+	return changed, firstErr
+}
+
+// ResealUserBlocks re-encrypts sealed user_md bodies under the current key version.
+func ResealUserBlocks(ctx context.Context, doc *NodeDocV1, env *fieldcrypt.Envelope) (bool, error) {
+	return transformUserBlocks(doc, func(md string) (string, error) {
+		out, _, err := env.Reseal(ctx, md)
+		return out, err
+	})
+}
+
+// StripUserBlocks drops user_md blocks from doc, for views served to anyone but the note's
+// author; it reports whether any were dropped.
+func StripUserBlocks(doc *NodeDocV1) bool {
+	if doc == nil {
+		return false
+	}
+	kept := make([]map[string]any, 0, len(doc.Blocks))
+	for _, raw := range doc.Blocks {
+		if BlockType(raw) == "user_md" {
+			continue
+		}
+		kept = append(kept, raw)
+	}
+	if len(kept) == len(doc.Blocks) {
+		return false
+	}
+	doc.Blocks = kept
+	return true
+}
+
+func transformUserBlocks(doc *NodeDocV1, fn func(md string) (string, error)) (bool, error) {
+	if doc == nil {
+		return false, nil
+	}
+	// Served docs share their block slice with the stored and exposure-logged copies; rewrite
+	// a private one.
+	doc.Blocks = append([]map[string]any(nil), doc.Blocks...)
+	var err error
+	changed := ForEachBlock(doc, func(i int, b Block) bool {
+		ub, ok := b.(*UserMdBlock)
+		if !ok || err != nil || ub.MD == "" {
+			return false
+		}
+		next, ferr := fn(ub.MD)
+		if ferr != nil {
+			err = ferr
+			return false
+		}
+		if next == ub.MD {
+			return false
+		}
+		ub.MD = next
+		return true
+	})
+	return changed, err
+}
@@ -0,0 +1,66 @@
+package content
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
+)
+
+func testEnvelope(t *testing.T) *fieldcrypt.Envelope {
+	t.Helper()
+	ring, err := fieldcrypt.NewLocalKeyring(map[string][]byte{"v1": bytes.Repeat([]byte{7}, 32)}, "v1")
+	if err != nil {
+		t.Fatalf("NewLocalKeyring: %v", err)
+	}
+	return fieldcrypt.New(ring, fieldcrypt.Options{})
+}
+
+func TestUserNotesSealedAndExcludedFromDocText(t *testing.T) {
+	ctx := context.Background()
+	env := testEnvelope(t)
+	doc := NodeDocV1{
+		Title: "Hash tables",
+		Blocks: []map[string]any{
+			{"id": "p1", "type": "paragraph", "md": "Buckets hold entries."},
+			{"id": "n1", "type": "user_md", "title": "My note", "md": "Ask Dr. Patel at Initech about this"},
+		},
+	}
+	served := doc
+	if changed, err := SealUserBlocks(ctx, &doc, env); err != nil || !changed {
+		t.Fatalf("SealUserBlocks = %v, %v", changed, err)
+	}
+	body, _ := doc.Blocks[1]["md"].(string)
+	if !fieldcrypt.IsSealed(body) || doc.Blocks[1]["title"] != "My note" {
+		t.Fatalf("user_md block after seal = %+v", doc.Blocks[1])
+	}
+	if served.Blocks[1]["md"] != "Ask Dr. Patel at Initech about this" {
+		t.Fatalf("sealing rewrote a shared block slice")
+	}
+
+	for _, d := range []NodeDocV1{served, doc} {
+		text, _ := NodeDocMetrics(d)["doc_text"].(string)
+		if strings.Contains(text, "Patel") || strings.Contains(text, "nbenc1.") || !strings.Contains(text, UserNotePlaceholder) {
+			t.Fatalf("doc_text = %q", text)
+		}
+		if !strings.Contains(text, "Buckets hold entries.") {
+			t.Fatalf("doc_text lost generated content: %q", text)
+		}
+	}
+
+	opened := doc
+	if _, err := OpenUserBlocks(ctx, &opened, env); err != nil {
+		t.Fatalf("OpenUserBlocks: %v", err)
+	}
+	if opened.Blocks[1]["md"] != "Ask Dr. Patel at Initech about this" || !fieldcrypt.IsSealed(doc.Blocks[1]["md"].(string)) {
+		t.Fatalf("opened = %+v, stored = %+v", opened.Blocks[1], doc.Blocks[1])
+	}
+
+	// Without the key the note is served as the placeholder, never as ciphertext.
+	blind := doc
+	if _, err := OpenUserBlocks(ctx, &blind, nil); err == nil || blind.Blocks[1]["md"] != UserNotePlaceholder {
+		t.Fatalf("keyless open = %+v, %v", blind.Blocks[1], err)
+	}
+}
@@ -149,6 +149,9 @@ func NodeDocMetrics(doc NodeDocV1) map[string]any {
 		case "intuition", "mental_model", "why_it_matters":
 			concat = append(concat, stringFromAny(b["title"]), stringFromAny(b["md"]))
 			wordCount += WordCount(stripMD(stringFromAny(b["title"]) + " " + stringFromAny(b["md"])))
+		case "user_md":
+			// Learner notes are encrypted at rest; keep them out of doc_text and search.
+			concat = append(concat, UserNotePlaceholder)
 		}
 	}
 
@@ -215,6 +218,9 @@ func ValidateNodeDocV1(doc NodeDocV1, allowedChunkIDs map[string]bool, req NodeD
 	if len(doc.Blocks) == 0 {
 		errs = append(errs, "blocks missing")
 	}
+	for _, id := range DuplicateNodeDocBlockIDs(doc) {
+		errs = append(errs, fmt.Sprintf("block id duplicate %q", id))
+	}
 
 	metrics := NodeDocMetrics(doc)
 	wordCount, _ := metrics["word_count"].(int)
@@ -287,190 +293,163 @@ func ValidateNodeDocV1(doc NodeDocV1, allowedChunkIDs map[string]bool, req NodeD
 	}
 
 	// Per-block validation (citations + required fields).
-	for i, b := range doc.Blocks {
-		t := strings.ToLower(strings.TrimSpace(stringFromAny(b["type"])))
-		switch t {
-		case "heading":
-			level := intFromAny(b["level"], 0)
-			if level < 2 || level > 4 {
-				errs = append(errs, fmt.Sprintf("block[%d] heading.level must be 2-4 (got %d)", i, level))
-			}
-			if strings.TrimSpace(stringFromAny(b["text"])) == "" {
+	for i, raw := range doc.Blocks {
+		block := DecodeBlock(raw)
+		t := normalizeBlockType(block.Header().Type)
+		switch b := block.(type) {
+		case *HeadingBlock:
+			if b.Level < 2 || b.Level > 4 {
+				errs = append(errs, fmt.Sprintf("block[%d] heading.level must be 2-4 (got %d)", i, b.Level))
+			}
+			if strings.TrimSpace(b.Text) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] heading.text missing", i))
 			}
-		case "paragraph":
-			if strings.TrimSpace(stringFromAny(b["md"])) == "" {
-				errs = append(errs, fmt.Sprintf("block[%d] paragraph.md missing", i))
+		case *MdBlock:
+			if strings.TrimSpace(b.MD) == "" {
+				errs = append(errs, fmt.Sprintf("block[%d] %s.md missing", i, t))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "callout":
-			variant := strings.ToLower(strings.TrimSpace(stringFromAny(b["variant"])))
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *CalloutBlock:
+			variant := strings.ToLower(strings.TrimSpace(b.Variant))
 			if variant != "info" && variant != "tip" && variant != "warning" {
 				errs = append(errs, fmt.Sprintf("block[%d] callout.variant invalid (%q)", i, variant))
 			}
-			if strings.TrimSpace(stringFromAny(b["md"])) == "" {
+			if strings.TrimSpace(b.MD) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] callout.md missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "code":
-			if strings.TrimSpace(stringFromAny(b["code"])) == "" {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *CodeBlock:
+			if strings.TrimSpace(b.Code) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] code.code missing", i))
 			}
-		case "figure":
-			asset, _ := b["asset"].(map[string]any)
-			url := strings.TrimSpace(stringFromAny(asset["url"]))
-			if url == "" {
+		case *FigureBlock:
+			if strings.TrimSpace(b.Asset.URL) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] figure.asset.url missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "video":
-			if strings.TrimSpace(stringFromAny(b["url"])) == "" {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *VideoBlock:
+			if strings.TrimSpace(b.URL) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] video.url missing", i))
 			}
-		case "diagram":
-			kind := strings.ToLower(strings.TrimSpace(stringFromAny(b["kind"])))
+		case *DiagramBlock:
+			kind := strings.ToLower(strings.TrimSpace(b.Kind))
 			if kind != "svg" && kind != "mermaid" {
 				errs = append(errs, fmt.Sprintf("block[%d] diagram.kind invalid (%q)", i, kind))
 			}
-			if strings.TrimSpace(stringFromAny(b["source"])) == "" {
+			if strings.TrimSpace(b.Source) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] diagram.source missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "table":
-			cols := stringSliceFromAny(b["columns"])
-			rows := stringMatrixFromAny(b["rows"])
-			if len(cols) == 0 {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *TableBlock:
+			if len(nonEmptyStrings(b.Columns)) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] table.columns missing", i))
 			}
-			if len(rows) == 0 {
+			if len(b.Rows) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] table.rows missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "equation":
-			if strings.TrimSpace(stringFromAny(b["latex"])) == "" {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *EquationBlock:
+			if strings.TrimSpace(b.Latex) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] equation.latex missing", i))
 			}
-			// display is required by schema; best-effort validate type
-			if _, ok := b["display"].(bool); !ok {
-				if _, okFloat := b["display"].(float64); !okFloat {
-					errs = append(errs, fmt.Sprintf("block[%d] equation.display missing", i))
-				}
+			if b.Display == nil {
+				errs = append(errs, fmt.Sprintf("block[%d] equation.display missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "quick_check":
-			if strings.TrimSpace(stringFromAny(b["prompt_md"])) == "" {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *QuizBlock:
+			if strings.TrimSpace(b.PromptMD) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] quick_check.prompt_md missing", i))
 			}
-			if strings.TrimSpace(stringFromAny(b["answer_md"])) == "" {
+			if strings.TrimSpace(b.AnswerMD) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] quick_check.answer_md missing", i))
 			}
-			kind := strings.ToLower(strings.TrimSpace(stringFromAny(b["kind"])))
-			answerID := strings.TrimSpace(stringFromAny(b["answer_id"]))
-			rawOptions, _ := b["options"].([]any)
-			isChoice := kind == "mcq" || kind == "true_false" || len(rawOptions) > 0 || answerID != ""
+			kind := strings.ToLower(strings.TrimSpace(b.Kind))
+			answerID := strings.TrimSpace(b.AnswerID)
+			isChoice := kind == "mcq" || kind == "true_false" || len(b.Options) > 0 || answerID != ""
 			if isChoice {
 				label := kind
 				if strings.TrimSpace(label) == "" {
 					label = "choice"
 				}
-				if len(rawOptions) < 2 {
+				if len(b.Options) < 2 {
 					errs = append(errs, fmt.Sprintf("block[%d] quick_check.options needs >=2 options for %s", i, label))
 				}
 				optIDs := map[string]bool{}
-				for j, x := range rawOptions {
-					m, ok := x.(map[string]any)
-					if !ok || m == nil {
-						errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d] invalid", i, j))
-						continue
-					}
-					oid := strings.TrimSpace(stringFromAny(m["id"]))
-					txt := strings.TrimSpace(stringFromAny(m["text"]))
+				for j, opt := range b.Options {
+					oid := strings.TrimSpace(opt.ID)
 					if oid == "" {
 						errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d].id missing", i, j))
 					} else if optIDs[oid] {
 						errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d].id duplicate %q", i, j, oid))
 					}
-					if txt == "" {
+					if strings.TrimSpace(opt.Text) == "" {
 						errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d].text missing", i, j))
 					}
 					if oid != "" {
 						optIDs[oid] = true
 					}
+					if mk := opt.MisconceptionKey; mk != "" {
+						if !ValidMisconceptionKey(mk) {
+							errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d].misconception_key %q must be a lowercase slug", i, j, mk))
+						}
+						if oid != "" && oid == answerID {
+							errs = append(errs, fmt.Sprintf("block[%d] quick_check.options[%d].misconception_key set on the correct answer", i, j))
+						}
+					}
 				}
-				if strings.TrimSpace(answerID) == "" {
+				if answerID == "" {
 					errs = append(errs, fmt.Sprintf("block[%d] quick_check.answer_id missing", i))
 				} else if len(optIDs) > 0 && !optIDs[answerID] {
 					errs = append(errs, fmt.Sprintf("block[%d] quick_check.answer_id %q not in options", i, answerID))
 				}
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "flashcard":
-			if strings.TrimSpace(stringFromAny(b["front_md"])) == "" {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *FlashcardBlock:
+			if strings.TrimSpace(b.FrontMD) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] flashcard.front_md missing", i))
 			}
-			if strings.TrimSpace(stringFromAny(b["back_md"])) == "" {
+			if strings.TrimSpace(b.BackMD) == "" {
 				errs = append(errs, fmt.Sprintf("block[%d] flashcard.back_md missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "divider":
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *DividerBlock:
 			// ok
-		case "objectives", "prerequisites", "key_takeaways", "common_mistakes", "misconceptions", "edge_cases", "heuristics", "checklist", "connections":
-			items := stringSliceFromAny(b["items_md"])
-			if len(items) == 0 {
+		case *ListBlock:
+			if len(nonEmptyStrings(b.ItemsMD)) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] %s.items_md missing", i, t))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "steps":
-			steps := stringSliceFromAny(b["steps_md"])
-			if len(steps) == 0 {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *StepsBlock:
+			if len(nonEmptyStrings(b.StepsMD)) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] steps.steps_md missing", i))
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "glossary":
-			arr, ok := b["terms"].([]any)
-			if !ok || len(arr) == 0 {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *GlossaryBlock:
+			if len(b.Terms) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] glossary.terms missing", i))
-			} else {
-				for j, it := range arr {
-					m, ok := it.(map[string]any)
-					if !ok {
-						errs = append(errs, fmt.Sprintf("block[%d] glossary.terms[%d] invalid", i, j))
-						continue
-					}
-					if strings.TrimSpace(stringFromAny(m["term"])) == "" {
-						errs = append(errs, fmt.Sprintf("block[%d] glossary.terms[%d].term missing", i, j))
-					}
-					if strings.TrimSpace(stringFromAny(m["definition_md"])) == "" {
-						errs = append(errs, fmt.Sprintf("block[%d] glossary.terms[%d].definition_md missing", i, j))
-					}
+			}
+			for j, term := range b.Terms {
+				if strings.TrimSpace(term.Term) == "" {
+					errs = append(errs, fmt.Sprintf("block[%d] glossary.terms[%d].term missing", i, j))
+				}
+				if strings.TrimSpace(term.DefinitionMD) == "" {
+					errs = append(errs, fmt.Sprintf("block[%d] glossary.terms[%d].definition_md missing", i, j))
 				}
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "faq":
-			arr, ok := b["qas"].([]any)
-			if !ok || len(arr) == 0 {
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
+		case *FAQBlock:
+			if len(b.QAs) == 0 {
 				errs = append(errs, fmt.Sprintf("block[%d] faq.qas missing", i))
-			} else {
-				for j, it := range arr {
-					m, ok := it.(map[string]any)
-					if !ok {
-						errs = append(errs, fmt.Sprintf("block[%d] faq.qas[%d] invalid", i, j))
-						continue
-					}
-					if strings.TrimSpace(stringFromAny(m["question_md"])) == "" {
-						errs = append(errs, fmt.Sprintf("block[%d] faq.qas[%d].question_md missing", i, j))
-					}
-					if strings.TrimSpace(stringFromAny(m["answer_md"])) == "" {
-						errs = append(errs, fmt.Sprintf("block[%d] faq.qas[%d].answer_md missing", i, j))
-					}
-				}
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
-		case "intuition", "mental_model", "why_it_matters":
-			if strings.TrimSpace(stringFromAny(b["md"])) == "" {
-				errs = append(errs, fmt.Sprintf("block[%d] %s.md missing", i, t))
+			for j, qa := range b.QAs {
+				if strings.TrimSpace(qa.QuestionMD) == "" {
+					errs = append(errs, fmt.Sprintf("block[%d] faq.qas[%d].question_md missing", i, j))
+				}
+				if strings.TrimSpace(qa.AnswerMD) == "" {
+					errs = append(errs, fmt.Sprintf("block[%d] faq.qas[%d].answer_md missing", i, j))
+				}
 			}
-			errs = append(errs, validateCitations(i, b["citations"], allowedChunkIDs)...)
+			errs = append(errs, validateBlockCitations(i, b.Citations, allowedChunkIDs)...)
 		default:
 			errs = append(errs, fmt.Sprintf("block[%d] unknown type %q", i, t))
 		}
@@ -708,30 +687,14 @@ func hasWorkedExample(doc NodeDocV1) bool {
 	return false
 }
 
-func validateCitations(blockIndex int, raw any, allowed map[string]bool) []string {
-	arr, ok := raw.([]any)
-	if !ok {
-		// If missing, treat as empty.
-		arr = nil
+func validateBlockCitations(blockIndex int, refs []CitationRefV1, allowed map[string]bool) []string {
+	trimmed := make([]CitationRefV1, 0, len(refs))
+	for _, c := range refs {
+		c.ChunkID = strings.TrimSpace(c.ChunkID)
+		c.Quote = strings.TrimSpace(c.Quote)
+		trimmed = append(trimmed, c)
 	}
-	refs := make([]CitationRefV1, 0, len(arr))
-	for _, x := range arr {
-		m, ok := x.(map[string]any)
-		if !ok {
-			continue
-		}
-		locAny, _ := m["loc"].(map[string]any)
-		refs = append(refs, CitationRefV1{
-			ChunkID: strings.TrimSpace(stringFromAny(m["chunk_id"])),
-			Quote:   strings.TrimSpace(stringFromAny(m["quote"])),
-			Loc: CitationLocV1{
-				Page:  intFromAny(locAny["page"], 0),
-				Start: intFromAny(locAny["start"], 0),
-				End:   intFromAny(locAny["end"], 0),
-			},
-		})
-	}
-	return validateCitationRefs(fmt.Sprintf("block[%d]", blockIndex), refs, allowed)
+	return validateCitationRefs(fmt.Sprintf("block[%d]", blockIndex), trimmed, allowed)
 }
 
 func validateCitationRefs(prefix string, refs []CitationRefV1, allowed map[string]bool) []string {
@@ -828,14 +791,12 @@ func stringSliceFromAny(v any) []string {
 	}
 }
 
-func stringMatrixFromAny(v any) [][]string {
-	arr, ok := v.([]any)
-	if !ok {
-		return nil
-	}
-	out := make([][]string, 0, len(arr))
-	for _, row := range arr {
-		out = append(out, stringSliceFromAny(row))
+func nonEmptyStrings(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
 	}
 	return out
 }
@@ -0,0 +1,61 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/apierr"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// LoadPatchableNodeDoc loads a path node's doc for a patch request, enforcing path ownership and
+// rejecting missing or frozen docs. Both the doc patch endpoint and chat edit actions go through it.
+// track selects the node's doc track; "" is primary.
+func (u Usecases) LoadPatchableNodeDoc(ctx context.Context, userID uuid.UUID, nodeID uuid.UUID, track string) (*types.PathNode, *types.LearningNodeDoc, error) {
+	if userID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusUnauthorized, "unauthorized", nil)
+	}
+	if nodeID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusBadRequest, "invalid_path_node_id", fmt.Errorf("missing path_node_id"))
+	}
+	track, ok := types.NormalizeNodeDocTrack(track)
+	if !ok {
+		return nil, nil, apierr.New(http.StatusBadRequest, "invalid_track", fmt.Errorf("unknown track"))
+	}
+	if u.deps.PathNodes == nil || u.deps.Path == nil || u.deps.NodeDocs == nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "path_repo_missing", fmt.Errorf("missing deps"))
+	}
+
+	dbc := dbctx.Context{Ctx: ctx}
+	node, err := u.deps.PathNodes.GetByID(dbc, nodeID)
+	if err != nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "load_node_failed", err)
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		return nil, nil, apierr.New(http.StatusNotFound, "node_not_found", nil)
+	}
+
+	pathRow, err := u.deps.Path.GetByID(dbc, node.PathID)
+	if err != nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "load_path_failed", err)
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != userID {
+		return nil, nil, apierr.New(http.StatusNotFound, "path_not_found", nil)
+	}
+
+	docRow, err := u.deps.NodeDocs.GetByPathNodeIDAndTrack(dbc, nodeID, track)
+	if err != nil {
+		return nil, nil, apierr.New(http.StatusInternalServerError, "load_doc_failed", err)
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		return nil, nil, apierr.New(http.StatusNotFound, "doc_not_found", nil)
+	}
+	if docRow.Frozen {
+		return nil, nil, apierr.New(http.StatusConflict, "doc_frozen", nil)
+	}
+	return node, docRow, nil
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	domainagg "github.com/yungbote/neurobridge-backend/internal/domain/aggregates"
 )
 
 const (
@@ -33,6 +35,7 @@ const (
 	EnvDocVariantSafeMinSamples = "DOC_VARIANT_SAFE_MIN_SAMPLES"
 	EnvDocVariantSafeMinIPS     = "DOC_VARIANT_SAFE_MIN_IPS"
 	EnvDocVariantSafeMinLift    = "DOC_VARIANT_SAFE_MIN_LIFT"
+	EnvDocVariantOutcomeLabels  = "DOC_VARIANT_OUTCOME_LABELS"
 )
 
 func DocPolicyVersion() string {
@@ -118,12 +121,21 @@ func DocProbePrereqBoost() float64 {
 }
 
 func DocVariantPolicyMode() string {
-	mode := strings.ToLower(strings.TrimSpace(os.Getenv(EnvDocVariantPolicyMode)))
+	mode, ok := ParseDocVariantPolicyMode(os.Getenv(EnvDocVariantPolicyMode))
+	if !ok {
+		return "off"
+	}
+	return mode
+}
+
+// ParseDocVariantPolicyMode accepts off/shadow/active (case-insensitive).
+func ParseDocVariantPolicyMode(s string) (string, bool) {
+	mode := strings.ToLower(strings.TrimSpace(s))
 	switch mode {
 	case "off", "shadow", "active":
-		return mode
+		return mode, true
 	default:
-		return "off"
+		return "", false
 	}
 }
 
@@ -162,6 +174,45 @@ func DocVariantSafeMinLift() float64 {
 	return envFloat(EnvDocVariantSafeMinLift, -0.02, -1, 1)
 }
 
+// DocVariantOutcomeLabels returns the accepted outcome labels and their reward weights.
+// DOC_VARIANT_OUTCOME_LABELS replaces the defaults with a "label=reward,label=reward" list; malformed
+// entries are skipped, rewards are clamped to [-1, 1], and an empty result falls back to defaults.
+func DocVariantOutcomeLabels() []domainagg.DocVariantOutcomeLabel {
+	raw := strings.TrimSpace(os.Getenv(EnvDocVariantOutcomeLabels))
+	if raw == "" {
+		return domainagg.DefaultDocVariantOutcomeLabels()
+	}
+	out := []domainagg.DocVariantOutcomeLabel{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		name, weight, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		label := domainagg.NormalizeDocVariantOutcomeLabel(name)
+		reward, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if label == "" || err != nil || seen[label] {
+			continue
+		}
+		seen[label] = true
+		if reward < -1 {
+			reward = -1
+		}
+		if reward > 1 {
+			reward = 1
+		}
+		entry := domainagg.DocVariantOutcomeLabel{Label: label, Reward: reward}
+		if def, ok := domainagg.FindDocVariantOutcomeLabel(domainagg.DefaultDocVariantOutcomeLabels(), label); ok {
+			entry.Description = def.Description
+		}
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		return domainagg.DefaultDocVariantOutcomeLabels()
+	}
+	return out
+}
+
 func envFloat(key string, def float64, min float64, max float64) float64 {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
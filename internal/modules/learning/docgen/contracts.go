@@ -2,6 +2,8 @@ package docgen
 
 import (
 	"strings"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
 )
 
 const (
@@ -162,16 +164,17 @@ type DocDelta struct {
 
 // DocGenerationTraceV1 records generation inputs and validation output.
 type DocGenerationTraceV1 struct {
-	SchemaVersion    int                   `json:"schema_version"`
-	TraceID          string                `json:"trace_id"`
-	PolicyVersion    string                `json:"policy_version"`
-	Model            string                `json:"model"`
-	PromptHash       string                `json:"prompt_hash"`
-	RetrievalPackID  string                `json:"retrieval_pack_id"`
-	BlueprintVersion string                `json:"blueprint_version"`
-	SlotFills        []DocSlotFill         `json:"slot_fills,omitempty"`
-	ConstraintReport DocConstraintReportV1 `json:"constraint_report"`
-	CreatedAt        string                `json:"created_at"`
+	SchemaVersion    int                     `json:"schema_version"`
+	TraceID          string                  `json:"trace_id"`
+	PolicyVersion    string                  `json:"policy_version"`
+	Model            string                  `json:"model"`
+	PromptHash       string                  `json:"prompt_hash"`
+	RetrievalPackID  string                  `json:"retrieval_pack_id"`
+	BlueprintVersion string                  `json:"blueprint_version"`
+	SlotFills        []DocSlotFill           `json:"slot_fills,omitempty"`
+	ConstraintReport DocConstraintReportV1   `json:"constraint_report"`
+	RichTextRepairs  []content.RichTextIssue `json:"rich_text_repairs,omitempty"`
+	CreatedAt        string                  `json:"created_at"`
 }
 
 // DocConstraintReportV1 is the authoritative constraint check result.
@@ -184,10 +187,18 @@ type DocConstraintReportV1 struct {
 }
 
 type DocConstraintViolation struct {
-	Code     string `json:"code"`
-	Severity string `json:"severity"`
-	Message  string `json:"message"`
-	BlockID  string `json:"block_id"`
+	Code     string       `json:"code"`
+	Severity string       `json:"severity"`
+	Message  string       `json:"message"`
+	BlockID  string       `json:"block_id"`
+	Span     *DocTextSpan `json:"span,omitempty"`
+}
+
+// DocTextSpan locates a violation inside a block field (character offsets).
+type DocTextSpan struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
 }
 
 func (b DocBlueprintV1) Validate() []string {
@@ -0,0 +1,168 @@
+package docgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Emphasis profile names accepted by doc regeneration.
+const (
+	EmphasisExamplesHeavy = "examples_heavy"
+	EmphasisTheoryLight   = "theory_light"
+	EmphasisExamPrep      = "exam_prep"
+	EmphasisELI5          = "eli5"
+)
+
+// MaxEmphasisFreeTextRunes caps the learner's free-text emphasis note.
+const MaxEmphasisFreeTextRunes = 400
+
+// EmphasisProfile is a named bundle of prompt parameters that changes a doc's flavor without
+// touching its grounding, outline or citation rules.
+type EmphasisProfile struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+
+	// ExampleDensity is "normal" or "high"; TheoryDepth is "light", "normal" or "deep".
+	ExampleDensity string `json:"example_density"`
+	TheoryDepth    string `json:"theory_depth"`
+	// ReadingLevel is "standard" or "simple".
+	ReadingLevel string `json:"reading_level"`
+	// PracticeFocus is "normal" or "exam".
+	PracticeFocus string `json:"practice_focus"`
+
+	Directives []string `json:"directives"`
+}
+
+var emphasisProfiles = map[string]EmphasisProfile{
+	EmphasisExamplesHeavy: {
+		Name:           EmphasisExamplesHeavy,
+		Label:          "More worked examples",
+		Description:    "Teach mostly through worked examples; keep theory to what the examples need.",
+		ExampleDensity: "high",
+		TheoryDepth:    "normal",
+		ReadingLevel:   "standard",
+		PracticeFocus:  "normal",
+		Directives: []string{
+			"Lead every section with a concrete worked example before generalizing.",
+			"Include at least two \"Worked example\" callouts, each solved step by step.",
+			"Prefer steps blocks for procedures demonstrated in examples.",
+		},
+	},
+	EmphasisTheoryLight: {
+		Name:           EmphasisTheoryLight,
+		Label:          "Less theory",
+		Description:    "Keep formal theory short; focus on intuition and how to apply the idea.",
+		ExampleDensity: "normal",
+		TheoryDepth:    "light",
+		ReadingLevel:   "standard",
+		PracticeFocus:  "normal",
+		Directives: []string{
+			"State definitions and results briefly; skip derivations unless a step is needed to apply the idea.",
+			"Favor intuition and mental_model blocks over formal exposition.",
+		},
+	},
+	EmphasisExamPrep: {
+		Name:           EmphasisExamPrep,
+		Label:          "Exam-focused",
+		Description:    "Organize around what is likely to be tested, with more practice and common mistakes.",
+		ExampleDensity: "normal",
+		TheoryDepth:    "normal",
+		ReadingLevel:   "standard",
+		PracticeFocus:  "exam",
+		Directives: []string{
+			"Frame key results as things to recognize and apply under time pressure.",
+			"Add extra quick checks in exam style and a common_mistakes block.",
+			"End with key takeaways phrased as a last-minute review list.",
+		},
+	},
+	EmphasisELI5: {
+		Name:           EmphasisELI5,
+		Label:          "Explain simply",
+		Description:    "Plain language and everyday analogies, with jargon introduced only when needed.",
+		ExampleDensity: "normal",
+		TheoryDepth:    "light",
+		ReadingLevel:   "simple",
+		PracticeFocus:  "normal",
+		Directives: []string{
+			"Use short sentences and everyday words; define every technical term the first time it appears.",
+			"Open each section with a familiar analogy before the precise statement.",
+		},
+	},
+}
+
+// NormalizeEmphasisProfileName is the canonical form profile names are compared in.
+func NormalizeEmphasisProfileName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// LookupEmphasisProfile returns the named profile.
+func LookupEmphasisProfile(name string) (EmphasisProfile, bool) {
+	p, ok := emphasisProfiles[NormalizeEmphasisProfileName(name)]
+	return p, ok
+}
+
+// EmphasisProfiles lists the profiles ordered by name.
+func EmphasisProfiles() []EmphasisProfile {
+	out := make([]EmphasisProfile, 0, len(emphasisProfiles))
+	for _, p := range emphasisProfiles {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// NodeDocEmphasis is a regeneration request's flavor: a named profile plus an optional learner
+// note. Build one with NewNodeDocEmphasis.
+type NodeDocEmphasis struct {
+	Profile  EmphasisProfile
+	FreeText string
+}
+
+// NewNodeDocEmphasis validates the profile name and free text.
+func NewNodeDocEmphasis(profile, freeText string) (NodeDocEmphasis, error) {
+	p, ok := LookupEmphasisProfile(profile)
+	if !ok {
+		return NodeDocEmphasis{}, fmt.Errorf("unknown emphasis profile %q", strings.TrimSpace(profile))
+	}
+	freeText = strings.Join(strings.Fields(freeText), " ")
+	if utf8.RuneCountInString(freeText) > MaxEmphasisFreeTextRunes {
+		return NodeDocEmphasis{}, fmt.Errorf("free_text_emphasis longer than %d characters", MaxEmphasisFreeTextRunes)
+	}
+	return NodeDocEmphasis{Profile: p, FreeText: freeText}, nil
+}
+
+// Key identifies the emphasis for hashing and deduplication.
+func (e NodeDocEmphasis) Key() string {
+	if e.FreeText == "" {
+		return e.Profile.Name
+	}
+	sum := sha256.Sum256([]byte(e.FreeText))
+	return e.Profile.Name + ":" + hex.EncodeToString(sum[:8])
+}
+
+// PromptSection renders the emphasis as a prompt section for the doc generator.
+func (e NodeDocEmphasis) PromptSection() string {
+	var b strings.Builder
+	b.WriteString("EMPHASIS_PROFILE (learner-requested flavor; apply it within every rule above, never at the cost of grounding or citations; do not mention it):\n")
+	fmt.Fprintf(&b, "- profile: %s (%s)\n", e.Profile.Name, e.Profile.Description)
+	fmt.Fprintf(&b, "- example_density: %s\n", e.Profile.ExampleDensity)
+	fmt.Fprintf(&b, "- theory_depth: %s\n", e.Profile.TheoryDepth)
+	fmt.Fprintf(&b, "- reading_level: %s\n", e.Profile.ReadingLevel)
+	fmt.Fprintf(&b, "- practice_focus: %s\n", e.Profile.PracticeFocus)
+	for _, d := range e.Profile.Directives {
+		b.WriteString("- ")
+		b.WriteString(d)
+		b.WriteString("\n")
+	}
+	if e.FreeText != "" {
+		b.WriteString("- learner note: ")
+		b.WriteString(e.FreeText)
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,59 @@
+package docgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewNodeDocEmphasis(t *testing.T) {
+	e, err := NewNodeDocEmphasis("  Exam_Prep ", "  focus on\n proofs ")
+	if err != nil {
+		t.Fatalf("NewNodeDocEmphasis: %v", err)
+	}
+	if e.Profile.Name != EmphasisExamPrep || e.FreeText != "focus on proofs" {
+		t.Fatalf("emphasis = %+v", e)
+	}
+
+	if _, err := NewNodeDocEmphasis("very_fun", ""); err == nil {
+		t.Fatalf("unknown profile accepted")
+	}
+	if _, err := NewNodeDocEmphasis("", ""); err == nil {
+		t.Fatalf("empty profile accepted")
+	}
+	if _, err := NewNodeDocEmphasis(EmphasisELI5, strings.Repeat("é", MaxEmphasisFreeTextRunes+1)); err == nil {
+		t.Fatalf("overlong free text accepted")
+	}
+	if _, err := NewNodeDocEmphasis(EmphasisELI5, strings.Repeat("é", MaxEmphasisFreeTextRunes)); err != nil {
+		t.Fatalf("free text at the limit rejected: %v", err)
+	}
+}
+
+func TestNodeDocEmphasisKey(t *testing.T) {
+	plain, _ := NewNodeDocEmphasis(EmphasisTheoryLight, "")
+	if plain.Key() != EmphasisTheoryLight {
+		t.Fatalf("key = %q", plain.Key())
+	}
+	a, _ := NewNodeDocEmphasis(EmphasisTheoryLight, "more diagrams")
+	b, _ := NewNodeDocEmphasis(EmphasisTheoryLight, "more  diagrams ")
+	c, _ := NewNodeDocEmphasis(EmphasisTheoryLight, "fewer diagrams")
+	if a.Key() != b.Key() {
+		t.Fatalf("whitespace changed the key: %q vs %q", a.Key(), b.Key())
+	}
+	if a.Key() == c.Key() || a.Key() == plain.Key() || !strings.HasPrefix(a.Key(), EmphasisTheoryLight+":") {
+		t.Fatalf("keys not distinct: %q %q %q", a.Key(), c.Key(), plain.Key())
+	}
+}
+
+func TestEmphasisProfilesComplete(t *testing.T) {
+	names := []string{}
+	for _, p := range EmphasisProfiles() {
+		names = append(names, p.Name)
+		if p.ExampleDensity == "" || p.TheoryDepth == "" || p.ReadingLevel == "" || p.PracticeFocus == "" || len(p.Directives) == 0 {
+			t.Fatalf("profile %s incomplete: %+v", p.Name, p)
+		}
+	}
+	want := []string{EmphasisELI5, EmphasisExamPrep, EmphasisExamplesHeavy, EmphasisTheoryLight}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("profiles = %v, want %v", names, want)
+	}
+}
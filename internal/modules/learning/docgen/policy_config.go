@@ -0,0 +1,210 @@
+package docgen
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+)
+
+const EnvDocPolicyConfigTTLSeconds = "DOC_POLICY_CONFIG_TTL_SECONDS"
+
+// DocPolicyConfig is the doc variant policy configuration read on the doc serving hot path.
+type DocPolicyConfig struct {
+	PolicyVersion  string    `json:"policy_version"`
+	PolicyKey      string    `json:"policy_key"`
+	Mode           string    `json:"mode"`
+	RolloutPct     float64   `json:"rollout_pct"`
+	RequireSafe    bool      `json:"require_safe"`
+	SafeMinSamples int       `json:"safe_min_samples"`
+	SafeMinIPS     float64   `json:"safe_min_ips"`
+	SafeMinLift    float64   `json:"safe_min_lift"`
+	LoadedAt       time.Time `json:"loaded_at"`
+}
+
+// DocPolicyConfigSource loads the current policy configuration. The default reads the
+// environment; a DB-backed source can be swapped in without touching readers.
+type DocPolicyConfigSource func(ctx context.Context) (DocPolicyConfig, error)
+
+// EnvDocPolicyConfig reads the policy configuration from the DOC_* environment variables.
+func EnvDocPolicyConfig(ctx context.Context) (DocPolicyConfig, error) {
+	return DocPolicyConfig{
+		PolicyVersion:  DocPolicyVersion(),
+		PolicyKey:      DocVariantPolicyKey(),
+		Mode:           DocVariantPolicyMode(),
+		RolloutPct:     DocVariantRolloutPct(),
+		RequireSafe:    DocVariantRequireSafe(),
+		SafeMinSamples: DocVariantSafeMinSamples(),
+		SafeMinIPS:     DocVariantSafeMinIPS(),
+		SafeMinLift:    DocVariantSafeMinLift(),
+	}, nil
+}
+
+// SnapshotSafe reports whether an evaluation snapshot clears the policy's safety bar
+// (minimum samples, IPS and lift). A nil snapshot is never safe.
+func (c DocPolicyConfig) SnapshotSafe(snap *types.PolicyEvalSnapshot) bool {
+	if snap == nil {
+		return false
+	}
+	if snap.Samples < c.SafeMinSamples {
+		return false
+	}
+	if snap.IPS < c.SafeMinIPS {
+		return false
+	}
+	if snap.Lift < c.SafeMinLift {
+		return false
+	}
+	return true
+}
+
+// FlagDocVariantPolicyMode targets the doc variant policy mode per user or path. The cached
+// config's Mode (DOC_VARIANT_POLICY_MODE) is its env default.
+const FlagDocVariantPolicyMode = "doc_variant_policy_mode"
+
+// ModeFor resolves the policy mode for the flag subject on ctx. A flag value that isn't a valid
+// mode keeps c.Mode.
+func (c DocPolicyConfig) ModeFor(ctx context.Context) (string, featureflag.Evaluation) {
+	ev := featureflag.Eval(ctx, FlagDocVariantPolicyMode, c.Mode)
+	mode, ok := ParseDocVariantPolicyMode(ev.Value)
+	if !ok {
+		ev.Value, ev.Source = c.Mode, featureflag.SourceEnv
+		return c.Mode, ev
+	}
+	ev.Value = mode
+	return mode, ev
+}
+
+func DocPolicyConfigTTL() time.Duration {
+	return time.Duration(envInt(EnvDocPolicyConfigTTLSeconds, 30, 1, 3600)) * time.Second
+}
+
+// DocPolicyConfigCache holds the last loaded policy configuration. Get never blocks on the
+// source: it returns the current snapshot and, at most once per TTL, kicks off a refresh.
+// A failed refresh keeps serving the previous snapshot.
+type DocPolicyConfigCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu         sync.Mutex
+	source     DocPolicyConfigSource
+	refreshing bool
+
+	cur atomic.Pointer[DocPolicyConfig]
+}
+
+func NewDocPolicyConfigCache(source DocPolicyConfigSource, ttl time.Duration) *DocPolicyConfigCache {
+	if source == nil {
+		source = EnvDocPolicyConfig
+	}
+	if ttl <= 0 {
+		ttl = DocPolicyConfigTTL()
+	}
+	return &DocPolicyConfigCache{ttl: ttl, now: time.Now, source: source}
+}
+
+// Get returns the cached configuration, loading it synchronously only on first use.
+func (c *DocPolicyConfigCache) Get(ctx context.Context) DocPolicyConfig {
+	cfg := c.cur.Load()
+	if cfg == nil {
+		if err := c.Refresh(ctx); err != nil || c.cur.Load() == nil {
+			fallback, _ := EnvDocPolicyConfig(ctx)
+			return fallback
+		}
+		return *c.cur.Load()
+	}
+	if c.now().Sub(cfg.LoadedAt) >= c.ttl {
+		c.refreshAsync()
+	}
+	return *cfg
+}
+
+// Refresh reloads the configuration immediately. Setters call it after writing new config so
+// the change is visible without waiting for the TTL.
+func (c *DocPolicyConfigCache) Refresh(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.mu.Lock()
+	source := c.source
+	c.mu.Unlock()
+	cfg, err := source(ctx)
+	if err != nil {
+		return err
+	}
+	cfg.LoadedAt = c.now()
+	c.cur.Store(&cfg)
+	return nil
+}
+
+// SetSource replaces the configuration source and reloads from it.
+func (c *DocPolicyConfigCache) SetSource(ctx context.Context, source DocPolicyConfigSource) error {
+	if source == nil {
+		source = EnvDocPolicyConfig
+	}
+	c.mu.Lock()
+	c.source = source
+	c.mu.Unlock()
+	return c.Refresh(ctx)
+}
+
+// Run refreshes the configuration every TTL until ctx is done, so readers rarely see an
+// expired snapshot.
+func (c *DocPolicyConfigCache) Run(ctx context.Context) {
+	_ = c.Refresh(ctx)
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Refresh(ctx)
+		}
+	}
+}
+
+func (c *DocPolicyConfigCache) refreshAsync() {
+	c.mu.Lock()
+	if c.refreshing {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing = true
+	c.mu.Unlock()
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			c.refreshing = false
+			c.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = c.Refresh(ctx)
+	}()
+}
+
+var defaultDocPolicyConfigCache = NewDocPolicyConfigCache(EnvDocPolicyConfig, 0)
+
+// DocPolicy returns the shared cached policy configuration.
+func DocPolicy(ctx context.Context) DocPolicyConfig {
+	return defaultDocPolicyConfigCache.Get(ctx)
+}
+
+// RefreshDocPolicy forces the shared cache to reload; call it from any policy config setter.
+func RefreshDocPolicy(ctx context.Context) error {
+	return defaultDocPolicyConfigCache.Refresh(ctx)
+}
+
+// SetDocPolicySource swaps the source behind the shared cache.
+func SetDocPolicySource(ctx context.Context, source DocPolicyConfigSource) error {
+	return defaultDocPolicyConfigCache.SetSource(ctx, source)
+}
+
+// RunDocPolicyRefresher keeps the shared cache warm until ctx is done.
+func RunDocPolicyRefresher(ctx context.Context) {
+	defaultDocPolicyConfigCache.Run(ctx)
+}
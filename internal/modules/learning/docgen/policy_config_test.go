@@ -0,0 +1,111 @@
+package docgen
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+)
+
+func TestDocPolicyConfigCache(t *testing.T) {
+	var loads atomic.Int32
+	var fail atomic.Bool
+	mode := atomic.Value{}
+	mode.Store("shadow")
+	source := func(ctx context.Context) (DocPolicyConfig, error) {
+		loads.Add(1)
+		if fail.Load() {
+			return DocPolicyConfig{}, errors.New("config store unavailable")
+		}
+		return DocPolicyConfig{Mode: mode.Load().(string), PolicyKey: "k1"}, nil
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var clock atomic.Pointer[time.Time]
+	clock.Store(&now)
+	c := NewDocPolicyConfigCache(source, time.Minute)
+	c.now = func() time.Time { return *clock.Load() }
+	ctx := context.Background()
+
+	if got := c.Get(ctx); got.Mode != "shadow" || loads.Load() != 1 {
+		t.Fatalf("first Get = %+v, loads=%d", got, loads.Load())
+	}
+	mode.Store("active")
+	for i := 0; i < 5; i++ {
+		if got := c.Get(ctx); got.Mode != "shadow" {
+			t.Fatalf("expected cached mode within TTL, got %q", got.Mode)
+		}
+	}
+	if loads.Load() != 1 {
+		t.Fatalf("expected no reloads within TTL, got %d", loads.Load())
+	}
+
+	// A setter's forced refresh is visible immediately.
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := c.Get(ctx); got.Mode != "active" {
+		t.Fatalf("expected refreshed mode, got %q", got.Mode)
+	}
+
+	// A failing source keeps serving the last good snapshot.
+	fail.Store(true)
+	if err := c.Refresh(ctx); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if got := c.Get(ctx); got.Mode != "active" {
+		t.Fatalf("expected last good config after failed refresh, got %q", got.Mode)
+	}
+
+	// Past the TTL, Get still answers from memory and refreshes in the background.
+	fail.Store(false)
+	mode.Store("off")
+	later := now.Add(2 * time.Minute)
+	clock.Store(&later)
+	if got := c.Get(ctx); got.Mode != "active" {
+		t.Fatalf("stale Get should not block on the source, got %q", got.Mode)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Get(ctx).Mode != "off" {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not complete")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDocPolicyModeFlag(t *testing.T) {
+	beta, pathID := uuid.New(), uuid.New()
+	ctx := context.Background()
+	if err := featureflag.SetSource(ctx, func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		return []*types.FeatureFlag{{
+			Name:  FlagDocVariantPolicyMode,
+			Rules: datatypes.JSON(`{"users":[{"value":"active","user_ids":["` + beta.String() + `"]}],"paths":{"` + pathID.String() + `":"bogus"}}`),
+		}}, nil
+	}); err != nil {
+		t.Fatalf("set source: %v", err)
+	}
+	t.Cleanup(func() { _ = featureflag.SetSource(ctx, nil) })
+
+	cfg := DocPolicyConfig{Mode: "shadow"}
+	if mode, ev := cfg.ModeFor(ctx); mode != "shadow" || ev.Source != featureflag.SourceEnv {
+		t.Fatalf("no subject = %q %+v", mode, ev)
+	}
+	betaCtx := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: beta})
+	if mode, ev := cfg.ModeFor(betaCtx); mode != "active" || ev.Source != featureflag.SourceUser {
+		t.Fatalf("beta user = %q %+v", mode, ev)
+	}
+	// An invalid targeted value keeps the env mode.
+	pathCtx := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: beta, PathID: pathID})
+	if mode, ev := cfg.ModeFor(pathCtx); mode != "shadow" || ev.Source != featureflag.SourceEnv {
+		t.Fatalf("invalid path value = %q %+v", mode, ev)
+	}
+}
@@ -0,0 +1,64 @@
+package docgen
+
+import (
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+// RichTextViolations turns unrepaired rich-text issues into warning-level constraint violations.
+// Warnings never flip a report's Passed flag: broken math or tables degrade rendering but the doc is
+// still usable, so they are surfaced rather than forcing a regeneration.
+func RichTextViolations(issues []content.RichTextIssue) []DocConstraintViolation {
+	out := make([]DocConstraintViolation, 0, len(issues))
+	for _, is := range issues {
+		if is.Repaired {
+			continue
+		}
+		out = append(out, DocConstraintViolation{
+			Code:     is.Code,
+			Severity: "warning",
+			Message:  is.Message,
+			BlockID:  is.BlockID,
+			Span:     &DocTextSpan{Field: is.Field, Start: is.Start, End: is.End},
+		})
+	}
+	return out
+}
+
+// RichTextConstraintReport builds a standalone report for edit paths that have no blueprint check.
+// It returns nil when there is nothing to report.
+func RichTextConstraintReport(issues []content.RichTextIssue) *DocConstraintReportV1 {
+	violations := RichTextViolations(issues)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &DocConstraintReportV1{
+		SchemaVersion: DocConstraintReportSchemaVersion,
+		Passed:        true,
+		Violations:    violations,
+		CheckedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// RichTextRevisionMetadata is the revision metadata recorded when a patch or manual edit went
+// through rich-text validation; nil when nothing was found.
+func RichTextRevisionMetadata(issues []content.RichTextIssue) map[string]any {
+	if len(issues) == 0 {
+		return nil
+	}
+	meta := map[string]any{}
+	var repairs []content.RichTextIssue
+	for _, is := range issues {
+		if is.Repaired {
+			repairs = append(repairs, is)
+		}
+	}
+	if len(repairs) > 0 {
+		meta["rich_text_repairs"] = repairs
+	}
+	if report := RichTextConstraintReport(issues); report != nil {
+		meta["constraint_report"] = report
+	}
+	return meta
+}
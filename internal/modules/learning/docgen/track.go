@@ -0,0 +1,44 @@
+package docgen
+
+import (
+	"strings"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// trackDirectives shape a non-primary doc track. Tracks share the primary doc's grounding,
+// outline and citation rules; only the way the material is presented changes.
+var trackDirectives = map[string][]string{
+	types.NodeDocTrackReading: {
+		"Present the material as continuous explanatory prose; keep lists, callouts and quick checks to a minimum.",
+		"Connect ideas with transitions so the doc reads start to finish like a well-edited chapter.",
+	},
+	types.NodeDocTrackHandsOn: {
+		"Teach by doing: open with a task, then walk through it with steps blocks the learner can follow along.",
+		"Put a practice exercise after every key idea and keep exposition to what the exercises need.",
+	},
+	types.NodeDocTrackVisual: {
+		"Carry each key idea with a diagram, figure or table, and keep the surrounding prose short.",
+		"Prefer side-by-side comparisons and labelled structures over long paragraphs.",
+	},
+}
+
+// TrackPromptSection renders the directives for a doc track; primary and unknown tracks have
+// none and return "".
+func TrackPromptSection(track string) string {
+	directives := trackDirectives[strings.TrimSpace(track)]
+	if len(directives) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("DOC_TRACK (alternate presentation of the same unit; apply it within every rule above, never at the cost of grounding or citations; do not mention it):\n")
+	b.WriteString("- track: ")
+	b.WriteString(strings.TrimSpace(track))
+	b.WriteString("\n")
+	for _, d := range directives {
+		b.WriteString("- ")
+		b.WriteString(d)
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -161,6 +161,11 @@ func (u Usecases) generateDrillV1(ctx context.Context, userID uuid.UUID, node *t
 		allowedConceptKeys = extractConceptKeysFromNodeDocJSON(docRow.DocJSON)
 	}
 	allowedConceptKeys = normalizeConceptKeys(allowedConceptKeys)
+	// An active focus narrows the drill to the node's focus concepts (if it covers any).
+	focusKeys := u.drillFocusConceptKeys(ctx, userID, node.PathID, allowedConceptKeys)
+	if len(focusKeys) > 0 {
+		allowedConceptKeys = focusKeys
+	}
 	if len(allowedConceptKeys) > 25 {
 		allowedConceptKeys = allowedConceptKeys[:25]
 	}
@@ -242,6 +247,10 @@ func (u Usecases) generateDrillV1(ctx context.Context, userID uuid.UUID, node *t
 	if sourcesHash == "" {
 		sourcesHash = content.HashSources("unknown_sources", 1, uuidStrings(evidenceChunkIDs))
 	}
+	// Focused drills are cached apart from unfocused ones (and per focus concept set).
+	if len(focusKeys) > 0 {
+		sourcesHash = content.HashSources("drill_focus|"+sourcesHash, 1, focusKeys)
+	}
 
 	// Cache lookup.
 	if cached, err := u.deps.Drills.GetByKey(dbctx.Context{Ctx: ctx}, userID, node.ID, kind, count, sourcesHash); err == nil && cached != nil && len(cached.PayloadJSON) > 0 && string(cached.PayloadJSON) != "null" {
@@ -561,6 +570,32 @@ func extractConceptKeysFromNodeDocJSON(raw datatypes.JSON) []string {
 	return normalizeConceptKeys(keys)
 }
 
+// drillFocusConceptKeys returns the node concept keys that the user's active focus on the path
+// names, or nil when there is no focus, it has no concept keys, or none are on the node.
+func (u Usecases) drillFocusConceptKeys(ctx context.Context, userID uuid.UUID, pathID uuid.UUID, nodeKeys []string) []string {
+	if u.deps.PathFocus == nil || len(nodeKeys) == 0 {
+		return nil
+	}
+	focus, err := u.deps.PathFocus.GetActive(dbctx.Context{Ctx: ctx}, userID, pathID, time.Now().UTC())
+	if err != nil {
+		if u.deps.Log != nil {
+			u.deps.Log.WarnThrottled("drills.path_focus", time.Minute, "load path focus failed", "error", err, "path_id", pathID)
+		}
+		return nil
+	}
+	inFocus := map[string]bool{}
+	for _, k := range focus.ConceptKeyList() {
+		inFocus[k] = true
+	}
+	var out []string
+	for _, k := range nodeKeys {
+		if inFocus[k] {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
 func normalizeConceptKeys(in []string) []string {
 	if len(in) == 0 {
 		return nil
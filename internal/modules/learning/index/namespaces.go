@@ -2,6 +2,9 @@ package index
 
 import (
 	"fmt"
+	"os"
+	"strings"
+
 	"github.com/google/uuid"
 )
 
@@ -27,18 +30,76 @@ func ChunksNamespace(materialSetID uuid.UUID) string {
 	return fmt.Sprintf("chunks:material_set:%s", materialSetID.String())
 }
 
+// Concept vectors are partitioned by ConceptNamespaceSuffix so both upserts and queries move to a
+// fresh namespace together when the embedding model changes.
 func ConceptsNamespace(scope string, scopeID *uuid.UUID) string {
+	return ConceptsNamespaceWithSuffix(scope, scopeID, ConceptNamespaceSuffix())
+}
+
+// ConceptsNamespaceWithSuffix is ConceptsNamespace with an explicit partition suffix (e.g. to read
+// the previous model's namespace during a migration). An empty suffix is the legacy namespace.
+func ConceptsNamespaceWithSuffix(scope string, scopeID *uuid.UUID, suffix string) string {
 	if scope == "global" || scopeID == nil || *scopeID == uuid.Nil {
-		return "concepts:global"
+		return withNamespaceSuffix("concepts:global", suffix)
 	}
-	return fmt.Sprintf("concepts:%s:%s", scope, scopeID.String())
+	return withNamespaceSuffix(fmt.Sprintf("concepts:%s:%s", scope, scopeID.String()), suffix)
 }
 
+// Cluster centroids are built from concept embeddings, so they share the concept partition.
 func ConceptClustersNamespace(scope string, scopeID *uuid.UUID) string {
 	if scope == "global" || scopeID == nil || *scopeID == uuid.Nil {
-		return "concept_clusters:global"
+		return withNamespaceSuffix("concept_clusters:global", ConceptNamespaceSuffix())
+	}
+	return withNamespaceSuffix(fmt.Sprintf("concept_clusters:%s:%s", scope, scopeID.String()), ConceptNamespaceSuffix())
+}
+
+// ConceptNamespaceSuffix resolves the concept namespace partition. CONCEPT_NAMESPACE_SUFFIX wins;
+// otherwise CONCEPT_NAMESPACE_BY_MODEL=true derives it from EMBEDDING_VERSION (falling back to
+// OPENAI_EMBED_MODEL). The default is no suffix, which keeps existing namespaces unchanged.
+func ConceptNamespaceSuffix() string {
+	if v := sanitizeNamespaceSuffix(os.Getenv("CONCEPT_NAMESPACE_SUFFIX")); v != "" {
+		return v
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("CONCEPT_NAMESPACE_BY_MODEL"))) {
+	case "1", "true", "yes", "on":
+	default:
+		return ""
+	}
+	if v := sanitizeNamespaceSuffix(os.Getenv("EMBEDDING_VERSION")); v != "" {
+		return v
+	}
+	return sanitizeNamespaceSuffix(os.Getenv("OPENAI_EMBED_MODEL"))
+}
+
+func withNamespaceSuffix(ns string, suffix string) string {
+	suffix = sanitizeNamespaceSuffix(suffix)
+	if suffix == "" {
+		return ns
+	}
+	return ns + ":" + suffix
+}
+
+// sanitizeNamespaceSuffix keeps suffixes to [a-z0-9._-] so a model name like "text-embedding-3-large"
+// or "v2/large" can't introduce extra ":" separators.
+func sanitizeNamespaceSuffix(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	out := strings.Trim(b.String(), "-")
+	if len(out) > 64 {
+		out = out[:64]
 	}
-	return fmt.Sprintf("concept_clusters:%s:%s", scope, scopeID.String())
+	return out
 }
 
 func ChainsNamespace(scope string, scopeID *uuid.UUID) string {
@@ -0,0 +1,39 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestConceptsNamespaceSuffix(t *testing.T) {
+	pathID := uuid.New()
+	t.Setenv("CONCEPT_NAMESPACE_SUFFIX", "")
+	t.Setenv("CONCEPT_NAMESPACE_BY_MODEL", "")
+	t.Setenv("EMBEDDING_VERSION", "")
+	t.Setenv("OPENAI_EMBED_MODEL", "text-embedding-3-large")
+
+	if got := ConceptsNamespace("global", nil); got != "concepts:global" {
+		t.Fatalf("default global: %q", got)
+	}
+	if got := ConceptsNamespace("path", &pathID); got != "concepts:path:"+pathID.String() {
+		t.Fatalf("default path: %q", got)
+	}
+
+	t.Setenv("CONCEPT_NAMESPACE_BY_MODEL", "true")
+	if got := ConceptsNamespace("global", nil); got != "concepts:global:text-embedding-3-large" {
+		t.Fatalf("model suffix: %q", got)
+	}
+	t.Setenv("EMBEDDING_VERSION", "Emb v2/Large")
+	if got := ConceptClustersNamespace("path", &pathID); got != "concept_clusters:path:"+pathID.String()+":emb-v2-large" {
+		t.Fatalf("embedding version suffix: %q", got)
+	}
+
+	t.Setenv("CONCEPT_NAMESPACE_SUFFIX", "tenant-a")
+	if got := ConceptsNamespace("path", &pathID); got != "concepts:path:"+pathID.String()+":tenant-a" {
+		t.Fatalf("explicit suffix: %q", got)
+	}
+	if got := ConceptsNamespaceWithSuffix("global", nil, ""); got != "concepts:global" {
+		t.Fatalf("explicit empty suffix should read the legacy namespace: %q", got)
+	}
+}
@@ -207,6 +207,10 @@ func (f *fakeBucketService) ListKeys(ctx context.Context, category gcp.BucketCat
 	return nil, nil
 }
 
+func (f *fakeBucketService) ListObjects(ctx context.Context, category gcp.BucketCategory, prefix, cursor string, limit int) (gcp.ObjectPage, error) {
+	return gcp.ObjectPage{}, nil
+}
+
 func (f *fakeBucketService) DeletePrefix(ctx context.Context, category gcp.BucketCategory, prefix string) error {
 	return nil
 }
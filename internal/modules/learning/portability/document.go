@@ -0,0 +1,360 @@
+package portability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion identifies the learning-state document layout. Bump it on any breaking change to
+// the record shapes; imports reject documents with a different version.
+const SchemaVersion = "learning_state.v1"
+
+var (
+	ErrSchemaVersion = errors.New("unsupported learning state schema version")
+	ErrTooManyItems  = errors.New("learning state document has too many records")
+	ErrMalformed     = errors.New("malformed learning state document")
+)
+
+// ConceptRef identifies a canonical concept in the exporting environment. IDs are only stable
+// within one environment, so the key and name travel with every record for re-mapping on import.
+type ConceptRef struct {
+	ID   uuid.UUID `json:"concept_id"`
+	Key  string    `json:"concept_key"`
+	Name string    `json:"concept_name"`
+}
+
+type ConceptStateRecord struct {
+	ConceptRef
+	Mastery              float64         `json:"mastery"`
+	Confidence           float64         `json:"confidence"`
+	BktPLearn            float64         `json:"bkt_p_learn,omitempty"`
+	BktPGuess            float64         `json:"bkt_p_guess,omitempty"`
+	BktPSlip             float64         `json:"bkt_p_slip,omitempty"`
+	BktPForget           float64         `json:"bkt_p_forget,omitempty"`
+	EpistemicUncertainty float64         `json:"epistemic_uncertainty,omitempty"`
+	AleatoricUncertainty float64         `json:"aleatoric_uncertainty,omitempty"`
+	HalfLifeDays         float64         `json:"half_life_days,omitempty"`
+	DecayRate            float64         `json:"decay_rate,omitempty"`
+	LastSeenAt           *time.Time      `json:"last_seen_at,omitempty"`
+	NextReviewAt         *time.Time      `json:"next_review_at,omitempty"`
+	Misconceptions       json.RawMessage `json:"misconceptions,omitempty"`
+	Attempts             int             `json:"attempts"`
+	Correct              int             `json:"correct"`
+}
+
+type ConceptModelRecord struct {
+	ConceptRef
+	ModelVersion     int             `json:"model_version"`
+	ActiveFrames     json.RawMessage `json:"active_frames,omitempty"`
+	Uncertainty      json.RawMessage `json:"uncertainty,omitempty"`
+	Assumptions      json.RawMessage `json:"assumptions,omitempty"`
+	Support          json.RawMessage `json:"support,omitempty"`
+	LastStructuralAt *time.Time      `json:"last_structural_evidence_at,omitempty"`
+}
+
+type MisconceptionRecord struct {
+	ConceptRef
+	PatternID   *string         `json:"pattern_id,omitempty"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Confidence  float64         `json:"confidence"`
+	FirstSeenAt *time.Time      `json:"first_seen_at,omitempty"`
+	LastSeenAt  *time.Time      `json:"last_seen_at,omitempty"`
+	ClearedAt   *time.Time      `json:"cleared_at,omitempty"`
+	Support     json.RawMessage `json:"support,omitempty"`
+}
+
+// BlockProgressRecord is the per-node reading state kept in NodeRun.metadata.runtime. Nodes are
+// not concept-keyed, so it only applies where the node exists for the importing user.
+type BlockProgressRecord struct {
+	PathID          uuid.UUID  `json:"path_id"`
+	NodeID          uuid.UUID  `json:"node_id"`
+	NodeTitle       string     `json:"node_title,omitempty"`
+	State           string     `json:"state"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	ReadBlocks      []string   `json:"read_blocks,omitempty"`
+	CompletedBlocks []string   `json:"completed_blocks,omitempty"`
+	ViewedBlocks    []string   `json:"viewed_blocks,omitempty"`
+}
+
+type CompletedUnitRecord struct {
+	ChainKey             string          `json:"chain_key"`
+	CompletedAt          *time.Time      `json:"completed_at,omitempty"`
+	CompletionConfidence float64         `json:"completion_confidence"`
+	MasteryAt            float64         `json:"mastery_at"`
+	AvgScore             float64         `json:"avg_score"`
+	TotalDwellMS         int             `json:"total_dwell_ms"`
+	Attempts             int             `json:"attempts"`
+	Metadata             json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Document is the decoded form of an export. Export writes the same layout section by section
+// rather than marshalling this struct, so large states never sit in memory twice.
+type Document struct {
+	SchemaVersion  string                `json:"schema_version"`
+	ExportedAt     *time.Time            `json:"exported_at,omitempty"`
+	ConceptStates  []ConceptStateRecord  `json:"concept_states"`
+	ConceptModels  []ConceptModelRecord  `json:"concept_models"`
+	Misconceptions []MisconceptionRecord `json:"misconceptions"`
+	BlockProgress  []BlockProgressRecord `json:"block_progress"`
+	CompletedUnits []CompletedUnitRecord `json:"completed_units"`
+}
+
+// Concepts returns the distinct concept refs referenced by the concept-keyed sections.
+func (d *Document) Concepts() []ConceptRef {
+	if d == nil {
+		return nil
+	}
+	out := []ConceptRef{}
+	seen := map[string]bool{}
+	add := func(ref ConceptRef) {
+		k := refKey(ref)
+		if k == "" || seen[k] {
+			return
+		}
+		seen[k] = true
+		out = append(out, ref)
+	}
+	for _, r := range d.ConceptStates {
+		add(r.ConceptRef)
+	}
+	for _, r := range d.ConceptModels {
+		add(r.ConceptRef)
+	}
+	for _, r := range d.Misconceptions {
+		add(r.ConceptRef)
+	}
+	return out
+}
+
+func refKey(ref ConceptRef) string {
+	if ref.ID != uuid.Nil {
+		return ref.ID.String()
+	}
+	if k := normalizeKey(ref.Key); k != "" {
+		return "key:" + k
+	}
+	return ""
+}
+
+// documentWriter streams an export one record at a time. The first write error sticks and every
+// later call becomes a no-op, so callers only check the error returned by End.
+type documentWriter struct {
+	w       io.Writer
+	flush   func()
+	inArray bool
+	first   bool
+	err     error
+}
+
+func newDocumentWriter(w io.Writer, flush func()) *documentWriter {
+	return &documentWriter{w: w, flush: flush}
+}
+
+func (d *documentWriter) raw(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.WriteString(d.w, s)
+}
+
+func (d *documentWriter) value(v any) {
+	if d.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		d.err = err
+		return
+	}
+	_, d.err = d.w.Write(b)
+}
+
+func (d *documentWriter) Begin(exportedAt time.Time) {
+	d.raw(`{"schema_version":`)
+	d.value(SchemaVersion)
+	d.raw(`,"exported_at":`)
+	d.value(exportedAt.UTC())
+}
+
+func (d *documentWriter) Section(name string) {
+	d.closeSection()
+	d.raw(",")
+	d.value(name)
+	d.raw(":[")
+	d.inArray = true
+	d.first = true
+}
+
+func (d *documentWriter) Item(v any) {
+	if !d.inArray {
+		d.err = fmt.Errorf("learning state export: item written outside a section")
+		return
+	}
+	if !d.first {
+		d.raw(",")
+	}
+	d.first = false
+	d.value(v)
+}
+
+func (d *documentWriter) closeSection() {
+	if !d.inArray {
+		return
+	}
+	d.raw("]")
+	d.inArray = false
+	if d.err == nil && d.flush != nil {
+		d.flush()
+	}
+}
+
+func (d *documentWriter) End() error {
+	d.closeSection()
+	d.raw("}\n")
+	if d.err == nil && d.flush != nil {
+		d.flush()
+	}
+	return d.err
+}
+
+// DecodeDocument reads a learning-state document without buffering the raw body: section arrays
+// are decoded element by element and the total record count is capped at maxItems. Unknown top
+// level fields are skipped so newer exporters can add sections without breaking older importers
+// of the same schema version.
+func DecodeDocument(r io.Reader, maxItems int) (*Document, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	items := 0
+	count := func() error {
+		items++
+		if maxItems > 0 && items > maxItems {
+			return fmt.Errorf("%w (limit %d)", ErrTooManyItems, maxItems)
+		}
+		return nil
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		key, _ := tok.(string)
+		switch key {
+		case "schema_version":
+			if err := dec.Decode(&doc.SchemaVersion); err != nil {
+				return nil, fmt.Errorf("%w: schema_version: %w", ErrMalformed, err)
+			}
+			if strings.TrimSpace(doc.SchemaVersion) != SchemaVersion {
+				return nil, fmt.Errorf("%w: %q (want %q)", ErrSchemaVersion, doc.SchemaVersion, SchemaVersion)
+			}
+		case "exported_at":
+			if err := dec.Decode(&doc.ExportedAt); err != nil {
+				return nil, fmt.Errorf("%w: exported_at: %w", ErrMalformed, err)
+			}
+		case "concept_states":
+			err = decodeSection(dec, key, count, func(d *json.Decoder) error {
+				var rec ConceptStateRecord
+				if err := d.Decode(&rec); err != nil {
+					return err
+				}
+				doc.ConceptStates = append(doc.ConceptStates, rec)
+				return nil
+			})
+		case "concept_models":
+			err = decodeSection(dec, key, count, func(d *json.Decoder) error {
+				var rec ConceptModelRecord
+				if err := d.Decode(&rec); err != nil {
+					return err
+				}
+				doc.ConceptModels = append(doc.ConceptModels, rec)
+				return nil
+			})
+		case "misconceptions":
+			err = decodeSection(dec, key, count, func(d *json.Decoder) error {
+				var rec MisconceptionRecord
+				if err := d.Decode(&rec); err != nil {
+					return err
+				}
+				doc.Misconceptions = append(doc.Misconceptions, rec)
+				return nil
+			})
+		case "block_progress":
+			err = decodeSection(dec, key, count, func(d *json.Decoder) error {
+				var rec BlockProgressRecord
+				if err := d.Decode(&rec); err != nil {
+					return err
+				}
+				doc.BlockProgress = append(doc.BlockProgress, rec)
+				return nil
+			})
+		case "completed_units":
+			err = decodeSection(dec, key, count, func(d *json.Decoder) error {
+				var rec CompletedUnitRecord
+				if err := d.Decode(&rec); err != nil {
+					return err
+				}
+				doc.CompletedUnits = append(doc.CompletedUnits, rec)
+				return nil
+			})
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			if errors.Is(err, ErrTooManyItems) || errors.Is(err, ErrMalformed) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %s: %w", ErrMalformed, key, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if doc.SchemaVersion == "" {
+		return nil, fmt.Errorf("%w: missing schema_version", ErrSchemaVersion)
+	}
+	return doc, nil
+}
+
+func decodeSection(dec *json.Decoder, name string, count func() error, one func(*json.Decoder) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("%w: %s must be an array", ErrMalformed, name)
+	}
+	for dec.More() {
+		if err := count(); err != nil {
+			return err
+		}
+		if err := one(dec); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("%w: expected %q", ErrMalformed, string(want))
+	}
+	return nil
+}
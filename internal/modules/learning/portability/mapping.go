@@ -0,0 +1,303 @@
+package portability
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+const (
+	MatchByID        = "id"
+	MatchByKeyName   = "key_name"
+	MatchByKey       = "key"
+	MatchByEmbedding = "embedding"
+
+	MatchStatusMatched     = "matched"
+	MatchStatusNeedsReview = "needs_review"
+	MatchStatusUnmatched   = "unmatched"
+)
+
+// MatchThresholds decide when an embedding match is trusted. Accept and Margin both have to hold:
+// a close runner-up means the key/name is ambiguous in this environment, and writing mastery onto
+// the wrong concept is worse than skipping it. Scores between Review and Accept are reported but
+// never applied.
+type MatchThresholds struct {
+	Accept float64
+	Review float64
+	Margin float64
+	// MaxCandidates bounds how many target concepts are embedded per import.
+	MaxCandidates int
+}
+
+func DefaultMatchThresholds() MatchThresholds {
+	th := MatchThresholds{
+		Accept:        envutil.Float("LEARNING_STATE_IMPORT_MATCH_ACCEPT", 0.92),
+		Review:        envutil.Float("LEARNING_STATE_IMPORT_MATCH_REVIEW", 0.85),
+		Margin:        envutil.Float("LEARNING_STATE_IMPORT_MATCH_MARGIN", 0.03),
+		MaxCandidates: envutil.Int("LEARNING_STATE_IMPORT_MAX_EMBED_CANDIDATES", 2000),
+	}
+	if th.Accept <= 0 || th.Accept > 1 {
+		th.Accept = 0.92
+	}
+	if th.Review <= 0 || th.Review > th.Accept {
+		th.Review = th.Accept
+	}
+	if th.Margin < 0 {
+		th.Margin = 0
+	}
+	if th.MaxCandidates <= 0 {
+		th.MaxCandidates = 2000
+	}
+	return th
+}
+
+// Candidate is a concept in the importing environment. RootID is the canonical concept that
+// state should be written to; it differs from Ref.ID when the concept is an alias of another.
+type Candidate struct {
+	Ref    ConceptRef
+	RootID uuid.UUID
+}
+
+type ConceptMatch struct {
+	Source     ConceptRef `json:"source"`
+	TargetID   uuid.UUID  `json:"target_concept_id,omitempty"`
+	TargetKey  string     `json:"target_concept_key,omitempty"`
+	TargetName string     `json:"target_concept_name,omitempty"`
+	Method     string     `json:"method,omitempty"`
+	Confidence float64    `json:"confidence"`
+	RunnerUp   float64    `json:"runner_up,omitempty"`
+	Status     string     `json:"status"`
+}
+
+func (m ConceptMatch) Applied() bool {
+	return m.Status == MatchStatusMatched && m.TargetID != uuid.Nil
+}
+
+type EmbedFunc func(ctx context.Context, inputs []string) ([][]float32, error)
+
+// MatchConcepts maps exported concepts onto candidates, cheapest signal first: the exported ID
+// when it exists here, then the normalized key (exact name too scores 1.0), then cosine similarity
+// of "name (key)" embeddings for whatever is left. embed may be nil, in which case anything not
+// matched by ID or key is reported unmatched. The result is in the order of sources.
+func MatchConcepts(ctx context.Context, sources []ConceptRef, candidates []Candidate, embed EmbedFunc, th MatchThresholds) ([]ConceptMatch, error) {
+	byID := map[uuid.UUID]Candidate{}
+	byKey := map[string][]Candidate{}
+	for _, c := range candidates {
+		if c.RootID == uuid.Nil {
+			continue
+		}
+		if c.Ref.ID != uuid.Nil {
+			byID[c.Ref.ID] = c
+		}
+		if k := normalizeKey(c.Ref.Key); k != "" {
+			byKey[k] = append(byKey[k], c)
+		}
+	}
+
+	out := make([]ConceptMatch, len(sources))
+	pending := []int{}
+	for i, src := range sources {
+		out[i] = ConceptMatch{Source: src, Status: MatchStatusUnmatched}
+		if c, ok := byID[src.ID]; ok && src.ID != uuid.Nil {
+			out[i] = matched(src, c, MatchByID, 1)
+			continue
+		}
+		if cs := byKey[normalizeKey(src.Key)]; len(cs) > 0 {
+			if c, ok := uniqueRoot(cs); ok {
+				if sameName(src.Name, c.Ref.Name) {
+					out[i] = matched(src, c, MatchByKeyName, 1)
+				} else {
+					out[i] = matched(src, c, MatchByKey, 0.97)
+				}
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 || embed == nil || len(candidates) == 0 {
+		return out, nil
+	}
+
+	pool := shortlist(sources, pending, candidates, th.MaxCandidates)
+	inputs := make([]string, 0, len(pending)+len(pool))
+	for _, i := range pending {
+		inputs = append(inputs, embedText(sources[i]))
+	}
+	for _, c := range pool {
+		inputs = append(inputs, embedText(c.Ref))
+	}
+	vecs, err := embed(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(inputs) {
+		return nil, fmt.Errorf("concept match embeddings: count mismatch (got %d want %d)", len(vecs), len(inputs))
+	}
+	srcVecs, poolVecs := vecs[:len(pending)], vecs[len(pending):]
+
+	for n, i := range pending {
+		// Best score per root, so aliases of one concept don't count as competing matches.
+		bestByRoot := map[uuid.UUID]float64{}
+		bestRef := map[uuid.UUID]ConceptRef{}
+		for j, c := range pool {
+			s := cosine(srcVecs[n], poolVecs[j])
+			if prev, ok := bestByRoot[c.RootID]; !ok || s > prev {
+				bestByRoot[c.RootID] = s
+				bestRef[c.RootID] = c.Ref
+			}
+		}
+		type scored struct {
+			root  uuid.UUID
+			score float64
+		}
+		ranked := make([]scored, 0, len(bestByRoot))
+		for root, s := range bestByRoot {
+			ranked = append(ranked, scored{root: root, score: s})
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			if ranked[a].score != ranked[b].score {
+				return ranked[a].score > ranked[b].score
+			}
+			return ranked[a].root.String() < ranked[b].root.String()
+		})
+		if len(ranked) == 0 {
+			continue
+		}
+		top := ranked[0]
+		runnerUp := 0.0
+		if len(ranked) > 1 {
+			runnerUp = ranked[1].score
+		}
+		m := matched(sources[i], Candidate{Ref: bestRef[top.root], RootID: top.root}, MatchByEmbedding, round3(top.score))
+		m.RunnerUp = round3(runnerUp)
+		switch {
+		case top.score >= th.Accept && top.score-runnerUp >= th.Margin:
+			m.Status = MatchStatusMatched
+		case top.score >= th.Review:
+			m.Status = MatchStatusNeedsReview
+		default:
+			m.Status = MatchStatusUnmatched
+		}
+		out[i] = m
+	}
+	return out, nil
+}
+
+func matched(src ConceptRef, c Candidate, method string, confidence float64) ConceptMatch {
+	return ConceptMatch{
+		Source:     src,
+		TargetID:   c.RootID,
+		TargetKey:  c.Ref.Key,
+		TargetName: c.Ref.Name,
+		Method:     method,
+		Confidence: confidence,
+		Status:     MatchStatusMatched,
+	}
+}
+
+// uniqueRoot reports the candidate when every key hit resolves to the same canonical concept.
+func uniqueRoot(cs []Candidate) (Candidate, bool) {
+	best := cs[0]
+	for _, c := range cs[1:] {
+		if c.RootID != best.RootID {
+			return Candidate{}, false
+		}
+		// Prefer the root row itself for the reported key/name.
+		if c.Ref.ID == c.RootID {
+			best = c
+		}
+	}
+	return best, true
+}
+
+// shortlist keeps at most max candidates, ranked by token overlap with any pending source, so
+// very large concept tables don't all go through the embedder on every import.
+func shortlist(sources []ConceptRef, pending []int, candidates []Candidate, max int) []Candidate {
+	pool := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.RootID != uuid.Nil {
+			pool = append(pool, c)
+		}
+	}
+	if max <= 0 || len(pool) <= max {
+		return pool
+	}
+	want := map[string]bool{}
+	for _, i := range pending {
+		for _, t := range tokens(sources[i].Key + " " + sources[i].Name) {
+			want[t] = true
+		}
+	}
+	score := make([]int, len(pool))
+	for j, c := range pool {
+		for _, t := range tokens(c.Ref.Key + " " + c.Ref.Name) {
+			if want[t] {
+				score[j]++
+			}
+		}
+	}
+	idx := make([]int, len(pool))
+	for j := range idx {
+		idx[j] = j
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return score[idx[a]] > score[idx[b]] })
+	out := make([]Candidate, 0, max)
+	for _, j := range idx[:max] {
+		out = append(out, pool[j])
+	}
+	return out
+}
+
+func embedText(ref ConceptRef) string {
+	name := strings.TrimSpace(ref.Name)
+	key := strings.Join(tokens(ref.Key), " ")
+	switch {
+	case name == "":
+		return key
+	case key == "" || strings.EqualFold(key, name):
+		return name
+	default:
+		return name + " (" + key + ")"
+	}
+}
+
+func normalizeKey(s string) string {
+	return strings.Join(tokens(s), "_")
+}
+
+func sameName(a, b string) bool {
+	return strings.Join(tokens(a), " ") == strings.Join(tokens(b), " ") && strings.TrimSpace(a) != ""
+}
+
+func tokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
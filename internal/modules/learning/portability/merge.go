@@ -0,0 +1,602 @@
+package portability
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/domain/learning/runtime"
+)
+
+// ProvenanceImported marks rows (and evidence) written by a learning-state import.
+const ProvenanceImported = "imported"
+
+// Existing is the importing user's current state for everything the document touches, keyed the
+// way BuildImportPlan looks it up.
+type Existing struct {
+	States         map[uuid.UUID]*types.UserConceptState
+	Models         map[uuid.UUID]*types.UserConceptModel
+	Misconceptions map[string]*types.UserMisconceptionInstance // misconceptionKey
+	CompletedUnits map[string]*types.UserCompletedUnit         // chain key
+	NodeRuns       map[uuid.UUID]*types.NodeRun                // node id
+	// OwnedNodes maps node id -> path id for nodes on paths the user owns.
+	OwnedNodes map[uuid.UUID]uuid.UUID
+}
+
+type SectionReport struct {
+	Received    int `json:"received"`
+	Applied     int `json:"applied"`
+	Unchanged   int `json:"unchanged"`
+	Unmatched   int `json:"unmatched"`
+	NeedsReview int `json:"needs_review"`
+	Invalid     int `json:"invalid"`
+}
+
+type ImportReport struct {
+	SchemaVersion  string         `json:"schema_version"`
+	DryRun         bool           `json:"dry_run"`
+	ConceptStates  SectionReport  `json:"concept_states"`
+	ConceptModels  SectionReport  `json:"concept_models"`
+	Misconceptions SectionReport  `json:"misconceptions"`
+	BlockProgress  SectionReport  `json:"block_progress"`
+	CompletedUnits SectionReport  `json:"completed_units"`
+	Matches        []ConceptMatch `json:"matches"`
+}
+
+// ImportPlan holds the rows to upsert. Rows are only present when the merge changed something,
+// so applying a plan built from an already-imported document is a no-op.
+type ImportPlan struct {
+	States         []*types.UserConceptState
+	Evidence       []*types.UserConceptEvidence
+	Models         []*types.UserConceptModel
+	Misconceptions []*types.UserMisconceptionInstance
+	CompletedUnits []*types.UserCompletedUnit
+	NodeRuns       []*types.NodeRun
+	Report         ImportReport
+}
+
+func (p *ImportPlan) Empty() bool {
+	return p == nil || len(p.States)+len(p.Models)+len(p.Misconceptions)+len(p.CompletedUnits)+len(p.NodeRuns) == 0
+}
+
+// BuildImportPlan merges doc into ex. The merge never regresses the user: mastery, confidence
+// and counters take the max of both sides, timestamps take whichever end of the range keeps the
+// most history, and structural models / node progress only fill gaps or add blocks. Records
+// whose concept did not map with status "matched" are counted and skipped.
+func BuildImportPlan(userID uuid.UUID, doc *Document, matches []ConceptMatch, ex Existing, now time.Time) *ImportPlan {
+	plan := &ImportPlan{Report: ImportReport{SchemaVersion: SchemaVersion, Matches: matches}}
+	if doc == nil {
+		return plan
+	}
+	byRef := map[string]ConceptMatch{}
+	for _, m := range matches {
+		byRef[refKey(m.Source)] = m
+	}
+	resolve := func(ref ConceptRef, rep *SectionReport) (ConceptMatch, bool) {
+		m, ok := byRef[refKey(ref)]
+		switch {
+		case !ok || m.Status == MatchStatusUnmatched:
+			rep.Unmatched++
+			return m, false
+		case m.Status == MatchStatusNeedsReview:
+			rep.NeedsReview++
+			return m, false
+		case !m.Applied():
+			rep.Unmatched++
+			return m, false
+		}
+		return m, true
+	}
+
+	// Concept states. Later records for the same target fold into the first.
+	states := map[uuid.UUID]*types.UserConceptState{}
+	stateOrder := []uuid.UUID{}
+	for _, rec := range doc.ConceptStates {
+		rep := &plan.Report.ConceptStates
+		rep.Received++
+		if !validUnit(rec.Mastery) || !validUnit(rec.Confidence) || rec.Attempts < 0 || rec.Correct < 0 {
+			rep.Invalid++
+			continue
+		}
+		m, ok := resolve(rec.ConceptRef, rep)
+		if !ok {
+			continue
+		}
+		cur := states[m.TargetID]
+		if cur == nil {
+			cur = cloneState(ex.States[m.TargetID])
+		}
+		next, changed := mergeConceptState(userID, m.TargetID, cur, rec)
+		if !changed {
+			rep.Unchanged++
+			continue
+		}
+		rep.Applied++
+		if _, seen := states[m.TargetID]; !seen {
+			stateOrder = append(stateOrder, m.TargetID)
+		}
+		prior := ex.States[m.TargetID]
+		plan.Evidence = append(plan.Evidence, importEvidence(userID, m, prior, next, rec, now))
+		states[m.TargetID] = next
+	}
+	for _, id := range stateOrder {
+		plan.States = append(plan.States, states[id])
+	}
+
+	// Structural models only fill concepts the user has no model for yet.
+	seenModel := map[uuid.UUID]bool{}
+	for _, rec := range doc.ConceptModels {
+		rep := &plan.Report.ConceptModels
+		rep.Received++
+		m, ok := resolve(rec.ConceptRef, rep)
+		if !ok {
+			continue
+		}
+		if ex.Models[m.TargetID] != nil || seenModel[m.TargetID] {
+			rep.Unchanged++
+			continue
+		}
+		seenModel[m.TargetID] = true
+		version := rec.ModelVersion
+		if version <= 0 {
+			version = 1
+		}
+		plan.Models = append(plan.Models, &types.UserConceptModel{
+			UserID:             userID,
+			CanonicalConceptID: m.TargetID,
+			ModelVersion:       version,
+			ActiveFrames:       rawJSON(rec.ActiveFrames),
+			Uncertainty:        rawJSON(rec.Uncertainty),
+			Assumptions:        rawJSON(rec.Assumptions),
+			Support:            withProvenance(rec.Support, m),
+			LastStructuralAt:   rec.LastStructuralAt,
+		})
+		rep.Applied++
+	}
+
+	// Misconceptions keep the existing status; an import can raise confidence and widen the
+	// seen window but can't reopen or clear an instance.
+	miscons := map[string]*types.UserMisconceptionInstance{}
+	misconOrder := []string{}
+	for _, rec := range doc.Misconceptions {
+		rep := &plan.Report.Misconceptions
+		rep.Received++
+		desc := strings.TrimSpace(rec.Description)
+		if desc == "" || !validUnit(rec.Confidence) {
+			rep.Invalid++
+			continue
+		}
+		m, ok := resolve(rec.ConceptRef, rep)
+		if !ok {
+			continue
+		}
+		key := misconceptionKey(m.TargetID, rec.PatternID, desc)
+		cur := miscons[key]
+		if cur == nil {
+			if prev := ex.Misconceptions[key]; prev != nil {
+				c := *prev
+				cur = &c
+			}
+		}
+		next, changed := mergeMisconception(userID, m, cur, rec, desc)
+		if !changed {
+			rep.Unchanged++
+			continue
+		}
+		rep.Applied++
+		if _, seen := miscons[key]; !seen {
+			misconOrder = append(misconOrder, key)
+		}
+		miscons[key] = next
+	}
+	for _, k := range misconOrder {
+		plan.Misconceptions = append(plan.Misconceptions, miscons[k])
+	}
+
+	// Node progress: nodes are environment-local, so only nodes on the user's own paths apply.
+	runs := map[uuid.UUID]*types.NodeRun{}
+	runOrder := []uuid.UUID{}
+	for _, rec := range doc.BlockProgress {
+		rep := &plan.Report.BlockProgress
+		rep.Received++
+		if rec.NodeID == uuid.Nil {
+			rep.Invalid++
+			continue
+		}
+		pathID, owned := ex.OwnedNodes[rec.NodeID]
+		if !owned {
+			rep.Unmatched++
+			continue
+		}
+		cur := runs[rec.NodeID]
+		if cur == nil {
+			cur = cloneNodeRun(ex.NodeRuns[rec.NodeID])
+		}
+		next, changed := mergeBlockProgress(userID, pathID, cur, rec)
+		if !changed {
+			rep.Unchanged++
+			continue
+		}
+		rep.Applied++
+		if _, seen := runs[rec.NodeID]; !seen {
+			runOrder = append(runOrder, rec.NodeID)
+		}
+		runs[rec.NodeID] = next
+	}
+	for _, id := range runOrder {
+		plan.NodeRuns = append(plan.NodeRuns, runs[id])
+	}
+
+	units := map[string]*types.UserCompletedUnit{}
+	unitOrder := []string{}
+	for _, rec := range doc.CompletedUnits {
+		rep := &plan.Report.CompletedUnits
+		rep.Received++
+		key := strings.TrimSpace(rec.ChainKey)
+		if key == "" || !validUnit(rec.CompletionConfidence) {
+			rep.Invalid++
+			continue
+		}
+		cur := units[key]
+		if cur == nil {
+			if prev := ex.CompletedUnits[key]; prev != nil {
+				c := *prev
+				cur = &c
+			}
+		}
+		next, changed := mergeCompletedUnit(userID, key, cur, rec)
+		if !changed {
+			rep.Unchanged++
+			continue
+		}
+		rep.Applied++
+		if _, seen := units[key]; !seen {
+			unitOrder = append(unitOrder, key)
+		}
+		units[key] = next
+	}
+	for _, k := range unitOrder {
+		plan.CompletedUnits = append(plan.CompletedUnits, units[k])
+	}
+	return plan
+}
+
+// mergeConceptState applies the no-regression rule. When the import carries higher mastery the
+// learner model parameters come with it (they produced that mastery); otherwise only confidence,
+// counters and recency can move up.
+func mergeConceptState(userID, conceptID uuid.UUID, cur *types.UserConceptState, rec ConceptStateRecord) (*types.UserConceptState, bool) {
+	if cur == nil {
+		return &types.UserConceptState{
+			UserID:               userID,
+			ConceptID:            conceptID,
+			Mastery:              rec.Mastery,
+			Confidence:           rec.Confidence,
+			BktPLearn:            rec.BktPLearn,
+			BktPGuess:            rec.BktPGuess,
+			BktPSlip:             rec.BktPSlip,
+			BktPForget:           rec.BktPForget,
+			EpistemicUncertainty: rec.EpistemicUncertainty,
+			AleatoricUncertainty: rec.AleatoricUncertainty,
+			HalfLifeDays:         rec.HalfLifeDays,
+			DecayRate:            rec.DecayRate,
+			LastSeenAt:           rec.LastSeenAt,
+			NextReviewAt:         rec.NextReviewAt,
+			Misconceptions:       rawJSON(rec.Misconceptions),
+			Attempts:             rec.Attempts,
+			Correct:              minInt(rec.Correct, rec.Attempts),
+		}, true
+	}
+	next := *cur
+	if rec.Mastery > cur.Mastery {
+		next.Mastery = rec.Mastery
+		next.BktPLearn = rec.BktPLearn
+		next.BktPGuess = rec.BktPGuess
+		next.BktPSlip = rec.BktPSlip
+		next.BktPForget = rec.BktPForget
+		next.EpistemicUncertainty = rec.EpistemicUncertainty
+		next.AleatoricUncertainty = rec.AleatoricUncertainty
+		next.HalfLifeDays = rec.HalfLifeDays
+		next.DecayRate = rec.DecayRate
+		next.NextReviewAt = rec.NextReviewAt
+	}
+	next.Confidence = math.Max(cur.Confidence, rec.Confidence)
+	next.Attempts = maxInt(cur.Attempts, rec.Attempts)
+	next.Correct = minInt(maxInt(cur.Correct, rec.Correct), next.Attempts)
+	next.LastSeenAt = laterTime(cur.LastSeenAt, rec.LastSeenAt)
+	if len(cur.Misconceptions) == 0 && len(rec.Misconceptions) > 0 {
+		next.Misconceptions = rawJSON(rec.Misconceptions)
+	}
+	changed := next.Mastery != cur.Mastery ||
+		next.Confidence != cur.Confidence ||
+		next.Attempts != cur.Attempts ||
+		next.Correct != cur.Correct ||
+		!sameTime(next.LastSeenAt, cur.LastSeenAt) ||
+		len(next.Misconceptions) != len(cur.Misconceptions)
+	return &next, changed
+}
+
+func mergeMisconception(userID uuid.UUID, m ConceptMatch, cur *types.UserMisconceptionInstance, rec MisconceptionRecord, desc string) (*types.UserMisconceptionInstance, bool) {
+	if cur == nil {
+		status := strings.TrimSpace(rec.Status)
+		if status == "" {
+			status = "active"
+		}
+		return &types.UserMisconceptionInstance{
+			UserID:             userID,
+			CanonicalConceptID: m.TargetID,
+			PatternID:          trimmedPtr(rec.PatternID),
+			Description:        desc,
+			Status:             status,
+			Confidence:         rec.Confidence,
+			FirstSeenAt:        rec.FirstSeenAt,
+			LastSeenAt:         rec.LastSeenAt,
+			ClearedAt:          rec.ClearedAt,
+			Support:            withProvenance(rec.Support, m),
+		}, true
+	}
+	next := *cur
+	next.Confidence = math.Max(cur.Confidence, rec.Confidence)
+	next.FirstSeenAt = earlierTime(cur.FirstSeenAt, rec.FirstSeenAt)
+	next.LastSeenAt = laterTime(cur.LastSeenAt, rec.LastSeenAt)
+	changed := next.Confidence != cur.Confidence ||
+		!sameTime(next.FirstSeenAt, cur.FirstSeenAt) ||
+		!sameTime(next.LastSeenAt, cur.LastSeenAt)
+	return &next, changed
+}
+
+func mergeCompletedUnit(userID uuid.UUID, chainKey string, cur *types.UserCompletedUnit, rec CompletedUnitRecord) (*types.UserCompletedUnit, bool) {
+	if cur == nil {
+		return &types.UserCompletedUnit{
+			UserID:               userID,
+			ChainKey:             chainKey,
+			CompletedAt:          rec.CompletedAt,
+			CompletionConfidence: rec.CompletionConfidence,
+			MasteryAt:            rec.MasteryAt,
+			AvgScore:             rec.AvgScore,
+			TotalDwellMS:         maxInt(rec.TotalDwellMS, 0),
+			Attempts:             maxInt(rec.Attempts, 0),
+			Metadata:             withProvenance(rec.Metadata, ConceptMatch{}),
+		}, true
+	}
+	next := *cur
+	next.CompletedAt = earlierTime(cur.CompletedAt, rec.CompletedAt)
+	next.CompletionConfidence = math.Max(cur.CompletionConfidence, rec.CompletionConfidence)
+	next.MasteryAt = math.Max(cur.MasteryAt, rec.MasteryAt)
+	next.AvgScore = math.Max(cur.AvgScore, rec.AvgScore)
+	next.TotalDwellMS = maxInt(cur.TotalDwellMS, rec.TotalDwellMS)
+	next.Attempts = maxInt(cur.Attempts, rec.Attempts)
+	changed := !sameTime(next.CompletedAt, cur.CompletedAt) ||
+		next.CompletionConfidence != cur.CompletionConfidence ||
+		next.MasteryAt != cur.MasteryAt ||
+		next.AvgScore != cur.AvgScore ||
+		next.TotalDwellMS != cur.TotalDwellMS ||
+		next.Attempts != cur.Attempts
+	return &next, changed
+}
+
+// mergeBlockProgress unions block ids into metadata.runtime and only ever moves the node state
+// forward to completed; it never rewinds reading/practice progress.
+func mergeBlockProgress(userID, pathID uuid.UUID, cur *types.NodeRun, rec BlockProgressRecord) (*types.NodeRun, bool) {
+	created := cur == nil
+	if created {
+		cur = &types.NodeRun{UserID: userID, PathID: pathID, NodeID: rec.NodeID, State: runtime.NodeRunNotStarted}
+	}
+	next := *cur
+	meta := map[string]any{}
+	if len(cur.Metadata) > 0 {
+		_ = json.Unmarshal(cur.Metadata, &meta)
+	}
+	rt, _ := meta["runtime"].(map[string]any)
+	if rt == nil {
+		rt = map[string]any{}
+	}
+	changed := created
+	for field, add := range map[string][]string{
+		"read_blocks":      rec.ReadBlocks,
+		"completed_blocks": rec.CompletedBlocks,
+		"viewed_blocks":    rec.ViewedBlocks,
+	} {
+		merged, grew := unionStrings(stringList(rt[field]), add)
+		if grew {
+			rt[field] = merged
+			changed = true
+		}
+	}
+	if runtime.NodeRunState(rec.State) == runtime.NodeRunCompleted && cur.State != runtime.NodeRunCompleted {
+		next.State = runtime.NodeRunCompleted
+		next.CompletedAt = rec.CompletedAt
+		changed = true
+	} else if next.State == runtime.NodeRunNotStarted && len(rec.ReadBlocks)+len(rec.ViewedBlocks) > 0 {
+		next.State = runtime.NodeRunReading
+		changed = true
+	}
+	if !changed {
+		return cur, false
+	}
+	meta["runtime"] = rt
+	if created {
+		meta["provenance"] = ProvenanceImported
+	}
+	b, _ := json.Marshal(meta)
+	next.Metadata = datatypes.JSON(b)
+	return &next, true
+}
+
+// importEvidence records the merge in the concept evidence log. SourceRef hashes the values that
+// were merged, so replaying the same document hits the (user, concept, source, source_ref) unique
+// key and writes nothing.
+func importEvidence(userID uuid.UUID, m ConceptMatch, prior, next *types.UserConceptState, rec ConceptStateRecord, now time.Time) *types.UserConceptEvidence {
+	var priorMastery, priorConfidence float64
+	if prior != nil {
+		priorMastery, priorConfidence = prior.Mastery, prior.Confidence
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%.6f|%.6f|%d|%d", refKey(m.Source), rec.Mastery, rec.Confidence, rec.Attempts, rec.Correct)))
+	payload, _ := json.Marshal(map[string]any{
+		"provenance":        ProvenanceImported,
+		"source_concept_id": m.Source.ID,
+		"source_key":        m.Source.Key,
+		"match_method":      m.Method,
+		"match_confidence":  m.Confidence,
+	})
+	return &types.UserConceptEvidence{
+		UserID:          userID,
+		ConceptID:       m.TargetID,
+		Source:          ProvenanceImported,
+		SourceRef:       "learning_state:" + hex.EncodeToString(sum[:12]),
+		EventType:       "learning_state_import",
+		OccurredAt:      now,
+		PriorMastery:    priorMastery,
+		PriorConfidence: priorConfidence,
+		PostMastery:     next.Mastery,
+		PostConfidence:  next.Confidence,
+		MasteryDelta:    next.Mastery - priorMastery,
+		ConfidenceDelta: next.Confidence - priorConfidence,
+		Payload:         datatypes.JSON(payload),
+	}
+}
+
+func misconceptionKey(conceptID uuid.UUID, patternID *string, desc string) string {
+	p := ""
+	if patternID != nil {
+		p = strings.TrimSpace(*patternID)
+	}
+	return conceptID.String() + "|" + p + "|" + strings.TrimSpace(desc)
+}
+
+func withProvenance(raw json.RawMessage, m ConceptMatch) datatypes.JSON {
+	obj := map[string]any{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+			obj = map[string]any{"original": json.RawMessage(raw)}
+		}
+	}
+	obj["provenance"] = ProvenanceImported
+	if m.Method != "" {
+		obj["import_match"] = map[string]any{"method": m.Method, "confidence": m.Confidence, "source_concept_id": m.Source.ID}
+	}
+	b, _ := json.Marshal(obj)
+	return datatypes.JSON(b)
+}
+
+func rawJSON(raw json.RawMessage) datatypes.JSON {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return datatypes.JSON(raw)
+}
+
+func cloneState(s *types.UserConceptState) *types.UserConceptState {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func cloneNodeRun(r *types.NodeRun) *types.NodeRun {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+func stringList(v any) []string {
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func unionStrings(base, add []string) ([]string, bool) {
+	seen := make(map[string]bool, len(base)+len(add))
+	out := make([]string, 0, len(base)+len(add))
+	for _, s := range base {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	grew := false
+	extra := []string{}
+	for _, s := range add {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		extra = append(extra, s)
+		grew = true
+	}
+	sort.Strings(extra)
+	return append(out, extra...), grew
+}
+
+func validUnit(v float64) bool { return !math.IsNaN(v) && v >= 0 && v <= 1 }
+
+func trimmedPtr(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	return &v
+}
+
+func laterTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b != nil && b.After(*a) {
+		return b
+	}
+	return a
+}
+
+func earlierTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b != nil && b.Before(*a) {
+		return b
+	}
+	return a
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,264 @@
+package portability
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// fakeEmbed maps each input to a fixed vector by its first word, so tests control similarity.
+func fakeEmbed(vecs map[string][]float32) EmbedFunc {
+	return func(ctx context.Context, inputs []string) ([][]float32, error) {
+		out := make([][]float32, len(inputs))
+		for i, in := range inputs {
+			word := strings.ToLower(strings.Fields(in)[0])
+			v, ok := vecs[word]
+			if !ok {
+				v = []float32{0, 0, 0, 1}
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+}
+
+var testThresholds = MatchThresholds{Accept: 0.92, Review: 0.85, Margin: 0.03, MaxCandidates: 100}
+
+func TestMatchConceptsPartialMatches(t *testing.T) {
+	loopsID, recursionID, recursionAlias, stackID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	candidates := []Candidate{
+		{Ref: ConceptRef{ID: loopsID, Key: "for_loops", Name: "For loops"}, RootID: loopsID},
+		{Ref: ConceptRef{ID: recursionID, Key: "recursion", Name: "Recursion"}, RootID: recursionID},
+		{Ref: ConceptRef{ID: recursionAlias, Key: "recursive_functions", Name: "Recursive functions"}, RootID: recursionID},
+		{Ref: ConceptRef{ID: stackID, Key: "call_stack", Name: "Call stack"}, RootID: stackID},
+	}
+	sources := []ConceptRef{
+		{ID: loopsID, Key: "for_loops", Name: "For loops"},                        // same environment
+		{ID: uuid.New(), Key: "Recursive-Functions", Name: "Recursive functions"}, // alias key, other env
+		{ID: uuid.New(), Key: "self_reference", Name: "Selfreference in code"},    // embedding, clear winner
+		{ID: uuid.New(), Key: "stack_frames", Name: "Stackish things"},            // embedding, below accept
+		{ID: uuid.New(), Key: "monads", Name: "Monads"},                           // nothing close
+	}
+	embed := fakeEmbed(map[string][]float32{
+		"recursion":     {1, 0, 0, 0},
+		"recursive":     {1, 0, 0, 0},
+		"selfreference": {0.99, 0.1, 0, 0},
+		"call":          {0, 1, 0, 0},
+		"stackish":      {0.4, 0.85, 0, 0.3},
+		"for":           {0, 0, 1, 0},
+		"monads":        {0, 0, 0, 1},
+	})
+
+	got, err := MatchConcepts(context.Background(), sources, candidates, embed, testThresholds)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	want := []struct {
+		method, status string
+		target         uuid.UUID
+	}{
+		{MatchByID, MatchStatusMatched, loopsID},
+		{MatchByKeyName, MatchStatusMatched, recursionID},
+		{MatchByEmbedding, MatchStatusMatched, recursionID},
+		{MatchByEmbedding, MatchStatusNeedsReview, stackID},
+		{MatchByEmbedding, MatchStatusUnmatched, uuid.Nil},
+	}
+	for i, w := range want {
+		m := got[i]
+		if m.Status != w.status || (w.status != MatchStatusUnmatched && (m.Method != w.method || m.TargetID != w.target)) {
+			t.Fatalf("source %d (%s): got %+v, want %+v", i, sources[i].Key, m, w)
+		}
+	}
+	if got[1].Confidence != 1 || got[2].Confidence < testThresholds.Accept {
+		t.Fatalf("confidence: key=%v embedding=%v", got[1].Confidence, got[2].Confidence)
+	}
+	if got[3].Applied() || got[4].Applied() {
+		t.Fatalf("review / unmatched concepts must not be applied: %+v %+v", got[3], got[4])
+	}
+
+	// Two roots scoring within the margin is ambiguous even above the accept threshold.
+	twinA, twinB := uuid.New(), uuid.New()
+	ambiguous, err := MatchConcepts(context.Background(),
+		[]ConceptRef{{ID: uuid.New(), Key: "sorting", Name: "Sorting"}},
+		[]Candidate{
+			{Ref: ConceptRef{ID: twinA, Key: "sort_algorithms", Name: "Sort algorithms"}, RootID: twinA},
+			{Ref: ConceptRef{ID: twinB, Key: "sorting_methods", Name: "Sorting methods"}, RootID: twinB},
+		},
+		fakeEmbed(map[string][]float32{"sorting": {1, 0, 0, 0}, "sort": {0.99, 0.05, 0, 0}}),
+		testThresholds)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if ambiguous[0].Status != MatchStatusNeedsReview || ambiguous[0].RunnerUp == 0 {
+		t.Fatalf("ambiguous match should need review: %+v", ambiguous[0])
+	}
+}
+
+func TestMatchConceptsWithoutEmbedderOnlyUsesIDsAndKeys(t *testing.T) {
+	id := uuid.New()
+	got, err := MatchConcepts(context.Background(),
+		[]ConceptRef{{ID: uuid.New(), Key: "graphs", Name: "Graph theory"}, {ID: uuid.New(), Key: "trees", Name: "Trees"}},
+		[]Candidate{{Ref: ConceptRef{ID: id, Key: "graphs", Name: "Graphs"}, RootID: id}},
+		nil, testThresholds)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	if got[0].Method != MatchByKey || got[0].Confidence != 0.97 || !got[0].Applied() {
+		t.Fatalf("key match with a different name: %+v", got[0])
+	}
+	if got[1].Status != MatchStatusUnmatched {
+		t.Fatalf("expected unmatched: %+v", got[1])
+	}
+}
+
+func TestBuildImportPlanNeverLowersMasteryAndIsIdempotent(t *testing.T) {
+	userID := uuid.New()
+	strongID, weakID, newID := uuid.New(), uuid.New(), uuid.New()
+	seen := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	later := seen.Add(48 * time.Hour)
+
+	ex := Existing{
+		States: map[uuid.UUID]*types.UserConceptState{
+			strongID: {UserID: userID, ConceptID: strongID, Mastery: 0.9, Confidence: 0.4, Attempts: 10, Correct: 9, LastSeenAt: &seen},
+			weakID:   {UserID: userID, ConceptID: weakID, Mastery: 0.2, Confidence: 0.5, Attempts: 2, Correct: 1, BktPLearn: 0.1},
+		},
+		Models:         map[uuid.UUID]*types.UserConceptModel{},
+		Misconceptions: map[string]*types.UserMisconceptionInstance{},
+		CompletedUnits: map[string]*types.UserCompletedUnit{},
+		NodeRuns:       map[uuid.UUID]*types.NodeRun{},
+		OwnedNodes:     map[uuid.UUID]uuid.UUID{},
+	}
+	doc := &Document{
+		SchemaVersion: SchemaVersion,
+		ConceptStates: []ConceptStateRecord{
+			{ConceptRef: ConceptRef{ID: strongID, Key: "strong"}, Mastery: 0.5, Confidence: 0.8, Attempts: 4, Correct: 2, LastSeenAt: &later},
+			{ConceptRef: ConceptRef{ID: weakID, Key: "weak"}, Mastery: 0.7, Confidence: 0.3, Attempts: 6, Correct: 5, BktPLearn: 0.3},
+			{ConceptRef: ConceptRef{ID: newID, Key: "fresh"}, Mastery: 0.6, Confidence: 0.6, Attempts: 3, Correct: 2},
+			{ConceptRef: ConceptRef{ID: uuid.New(), Key: "elsewhere"}, Mastery: 1, Confidence: 1},
+		},
+		Misconceptions: []MisconceptionRecord{
+			{ConceptRef: ConceptRef{ID: weakID, Key: "weak"}, Description: "off by one", Status: "active", Confidence: 0.6},
+		},
+		CompletedUnits: []CompletedUnitRecord{{ChainKey: "chain:abc", CompletionConfidence: 0.8, Attempts: 3}},
+	}
+	matches := []ConceptMatch{
+		{Source: doc.ConceptStates[0].ConceptRef, TargetID: strongID, Method: MatchByID, Confidence: 1, Status: MatchStatusMatched},
+		{Source: doc.ConceptStates[1].ConceptRef, TargetID: weakID, Method: MatchByID, Confidence: 1, Status: MatchStatusMatched},
+		{Source: doc.ConceptStates[2].ConceptRef, TargetID: newID, Method: MatchByEmbedding, Confidence: 0.95, Status: MatchStatusMatched},
+		{Source: doc.ConceptStates[3].ConceptRef, Status: MatchStatusUnmatched},
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	plan := BuildImportPlan(userID, doc, matches, ex, now)
+	rep := plan.Report.ConceptStates
+	if rep.Received != 4 || rep.Applied != 3 || rep.Unmatched != 1 {
+		t.Fatalf("state report: %+v", rep)
+	}
+	got := map[uuid.UUID]*types.UserConceptState{}
+	for _, s := range plan.States {
+		got[s.ConceptID] = s
+	}
+	if s := got[strongID]; s.Mastery != 0.9 || s.Confidence != 0.8 || s.Attempts != 10 || s.Correct != 9 || !s.LastSeenAt.Equal(later) {
+		t.Fatalf("higher existing mastery must stand: %+v", s)
+	}
+	if s := got[weakID]; s.Mastery != 0.7 || s.BktPLearn != 0.3 || s.Confidence != 0.5 || s.Attempts != 6 {
+		t.Fatalf("higher imported mastery should win with its params: %+v", s)
+	}
+	if s := got[newID]; s == nil || s.UserID != userID || s.Mastery != 0.6 {
+		t.Fatalf("new concept state: %+v", s)
+	}
+	if len(plan.Evidence) != 3 {
+		t.Fatalf("expected one evidence row per applied state, got %d", len(plan.Evidence))
+	}
+	for _, ev := range plan.Evidence {
+		if ev.Source != ProvenanceImported || !strings.HasPrefix(ev.SourceRef, "learning_state:") {
+			t.Fatalf("evidence provenance: %+v", ev)
+		}
+	}
+	if len(plan.Misconceptions) != 1 || !strings.Contains(string(plan.Misconceptions[0].Support), `"provenance":"imported"`) {
+		t.Fatalf("misconception: %+v", plan.Misconceptions)
+	}
+
+	// Apply the plan and import the same document again: nothing should change.
+	for _, s := range plan.States {
+		ex.States[s.ConceptID] = s
+	}
+	for _, m := range plan.Misconceptions {
+		ex.Misconceptions[misconceptionKey(m.CanonicalConceptID, m.PatternID, m.Description)] = m
+	}
+	for _, u := range plan.CompletedUnits {
+		ex.CompletedUnits[u.ChainKey] = u
+	}
+	again := BuildImportPlan(userID, doc, matches, ex, now.Add(time.Hour))
+	if !again.Empty() || len(again.Evidence) != 0 {
+		t.Fatalf("re-import should be a no-op, got states=%d miscons=%d units=%d evidence=%d",
+			len(again.States), len(again.Misconceptions), len(again.CompletedUnits), len(again.Evidence))
+	}
+	if again.Report.ConceptStates.Unchanged != 3 || again.Report.CompletedUnits.Unchanged != 1 {
+		t.Fatalf("re-import report: %+v", again.Report)
+	}
+}
+
+func TestBuildImportPlanBlockProgressOnlyForOwnedNodes(t *testing.T) {
+	userID, pathID, nodeID := uuid.New(), uuid.New(), uuid.New()
+	ex := Existing{
+		NodeRuns: map[uuid.UUID]*types.NodeRun{
+			nodeID: {UserID: userID, PathID: pathID, NodeID: nodeID, State: "reading", Metadata: []byte(`{"runtime":{"read_blocks":["b1"]}}`)},
+		},
+		OwnedNodes: map[uuid.UUID]uuid.UUID{nodeID: pathID},
+	}
+	doc := &Document{SchemaVersion: SchemaVersion, BlockProgress: []BlockProgressRecord{
+		{NodeID: nodeID, State: "reading", ReadBlocks: []string{"b2", "b1"}},
+		{NodeID: uuid.New(), State: "completed", ReadBlocks: []string{"x"}},
+	}}
+	plan := BuildImportPlan(userID, doc, nil, ex, time.Now())
+	if plan.Report.BlockProgress.Applied != 1 || plan.Report.BlockProgress.Unmatched != 1 {
+		t.Fatalf("block progress report: %+v", plan.Report.BlockProgress)
+	}
+	if got := string(plan.NodeRuns[0].Metadata); !strings.Contains(got, `"read_blocks":["b1","b2"]`) {
+		t.Fatalf("blocks not unioned: %s", got)
+	}
+
+	ex.NodeRuns[nodeID] = plan.NodeRuns[0]
+	if again := BuildImportPlan(userID, doc, nil, ex, time.Now()); len(again.NodeRuns) != 0 {
+		t.Fatalf("re-import should not touch node runs: %+v", again.NodeRuns)
+	}
+}
+
+func TestDocumentWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := newDocumentWriter(&buf, nil)
+	w.Begin(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	w.Section("concept_states")
+	w.Item(ConceptStateRecord{ConceptRef: ConceptRef{ID: uuid.New(), Key: "k", Name: "K"}, Mastery: 0.4})
+	w.Item(ConceptStateRecord{ConceptRef: ConceptRef{ID: uuid.New(), Key: "j", Name: "J"}, Mastery: 0.6})
+	w.Section("concept_models")
+	w.Section("future_section")
+	w.Item(map[string]any{"x": 1})
+	if err := w.End(); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	doc, err := DecodeDocument(bytes.NewReader(buf.Bytes()), 10)
+	if err != nil {
+		t.Fatalf("decode: %v\n%s", err, buf.String())
+	}
+	if len(doc.ConceptStates) != 2 || doc.ConceptStates[1].Key != "j" || len(doc.Concepts()) != 2 {
+		t.Fatalf("decoded: %+v", doc)
+	}
+
+	if _, err := DecodeDocument(bytes.NewReader(buf.Bytes()), 1); !errors.Is(err, ErrTooManyItems) {
+		t.Fatalf("expected item cap error, got %v", err)
+	}
+	bad := strings.Replace(buf.String(), SchemaVersion, "learning_state.v0", 1)
+	if _, err := DecodeDocument(strings.NewReader(bad), 10); !errors.Is(err, ErrSchemaVersion) {
+		t.Fatalf("expected schema version error, got %v", err)
+	}
+}
@@ -0,0 +1,487 @@
+package portability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type Deps struct {
+	DB    *gorm.DB
+	Log   *logger.Logger
+	Embed EmbedFunc
+
+	Concepts       repos.ConceptRepo
+	ConceptStates  repos.UserConceptStateRepo
+	ConceptModels  repos.UserConceptModelRepo
+	Misconceptions repos.UserMisconceptionInstanceRepo
+	Evidence       repos.UserConceptEvidenceRepo
+	CompletedUnits repos.UserCompletedUnitRepo
+	NodeRuns       repos.NodeRunRepo
+	Paths          repos.PathRepo
+	PathNodes      repos.PathNodeRepo
+}
+
+// Service exports a user's learning state as a portable document and merges such documents
+// back in, possibly in another environment where concept IDs differ.
+type Service struct {
+	deps       Deps
+	log        *logger.Logger
+	thresholds MatchThresholds
+	maxRows    int
+}
+
+type ImportOptions struct {
+	DryRun bool
+}
+
+func New(deps Deps) *Service {
+	maxRows := envutil.Int("LEARNING_STATE_EXPORT_MAX_ROWS", 5000)
+	if maxRows <= 0 {
+		maxRows = 5000
+	}
+	s := &Service{deps: deps, thresholds: DefaultMatchThresholds(), maxRows: maxRows}
+	if deps.Log != nil {
+		s.log = deps.Log.With("service", "LearningStatePortability")
+	}
+	return s
+}
+
+// Export streams the document to w section by section; flush (optional) is called after each
+// section so the client sees progress on large states.
+func (s *Service) Export(ctx context.Context, userID uuid.UUID, w io.Writer, flush func()) error {
+	if userID == uuid.Nil {
+		return fmt.Errorf("missing user id")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	states, err := s.deps.ConceptStates.ListByUserID(dbc, userID, s.maxRows)
+	if err != nil {
+		return err
+	}
+	models, err := s.deps.ConceptModels.ListByUserID(dbc, userID, s.maxRows)
+	if err != nil {
+		return err
+	}
+	miscons, err := s.deps.Misconceptions.ListByUserID(dbc, userID, s.maxRows)
+	if err != nil {
+		return err
+	}
+	conceptIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	addID := func(id uuid.UUID) {
+		if id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			conceptIDs = append(conceptIDs, id)
+		}
+	}
+	for _, r := range states {
+		addID(r.ConceptID)
+	}
+	for _, r := range models {
+		addID(r.CanonicalConceptID)
+	}
+	for _, r := range miscons {
+		addID(r.CanonicalConceptID)
+	}
+	refs := map[uuid.UUID]ConceptRef{}
+	if len(conceptIDs) > 0 {
+		rows, err := s.deps.Concepts.GetByIDs(dbc, conceptIDs)
+		if err != nil {
+			return err
+		}
+		for _, c := range rows {
+			if c != nil {
+				refs[c.ID] = ConceptRef{ID: c.ID, Key: c.Key, Name: c.Name}
+			}
+		}
+	}
+	ref := func(id uuid.UUID) ConceptRef {
+		if r, ok := refs[id]; ok {
+			return r
+		}
+		return ConceptRef{ID: id}
+	}
+
+	out := newDocumentWriter(w, flush)
+	out.Begin(time.Now())
+
+	out.Section("concept_states")
+	for _, r := range states {
+		if r == nil {
+			continue
+		}
+		out.Item(ConceptStateRecord{
+			ConceptRef:           ref(r.ConceptID),
+			Mastery:              r.Mastery,
+			Confidence:           r.Confidence,
+			BktPLearn:            r.BktPLearn,
+			BktPGuess:            r.BktPGuess,
+			BktPSlip:             r.BktPSlip,
+			BktPForget:           r.BktPForget,
+			EpistemicUncertainty: r.EpistemicUncertainty,
+			AleatoricUncertainty: r.AleatoricUncertainty,
+			HalfLifeDays:         r.HalfLifeDays,
+			DecayRate:            r.DecayRate,
+			LastSeenAt:           r.LastSeenAt,
+			NextReviewAt:         r.NextReviewAt,
+			Misconceptions:       json.RawMessage(r.Misconceptions),
+			Attempts:             r.Attempts,
+			Correct:              r.Correct,
+		})
+	}
+
+	out.Section("concept_models")
+	for _, r := range models {
+		if r == nil {
+			continue
+		}
+		out.Item(ConceptModelRecord{
+			ConceptRef:       ref(r.CanonicalConceptID),
+			ModelVersion:     r.ModelVersion,
+			ActiveFrames:     json.RawMessage(r.ActiveFrames),
+			Uncertainty:      json.RawMessage(r.Uncertainty),
+			Assumptions:      json.RawMessage(r.Assumptions),
+			Support:          json.RawMessage(r.Support),
+			LastStructuralAt: r.LastStructuralAt,
+		})
+	}
+
+	out.Section("misconceptions")
+	for _, r := range miscons {
+		if r == nil {
+			continue
+		}
+		out.Item(MisconceptionRecord{
+			ConceptRef:  ref(r.CanonicalConceptID),
+			PatternID:   r.PatternID,
+			Description: r.Description,
+			Status:      r.Status,
+			Confidence:  r.Confidence,
+			FirstSeenAt: r.FirstSeenAt,
+			LastSeenAt:  r.LastSeenAt,
+			ClearedAt:   r.ClearedAt,
+			Support:     json.RawMessage(r.Support),
+		})
+	}
+
+	// Block progress and completed units are loaded after the concept sections are out, so the
+	// first bytes reach the client before the slower node lookups.
+	out.Section("block_progress")
+	if err := s.exportBlockProgress(dbc, userID, out); err != nil {
+		return err
+	}
+
+	out.Section("completed_units")
+	units, err := s.deps.CompletedUnits.ListByUser(dbc, userID, s.maxRows)
+	if err != nil {
+		return err
+	}
+	for _, r := range units {
+		if r == nil {
+			continue
+		}
+		out.Item(CompletedUnitRecord{
+			ChainKey:             r.ChainKey,
+			CompletedAt:          r.CompletedAt,
+			CompletionConfidence: r.CompletionConfidence,
+			MasteryAt:            r.MasteryAt,
+			AvgScore:             r.AvgScore,
+			TotalDwellMS:         r.TotalDwellMS,
+			Attempts:             r.Attempts,
+			Metadata:             json.RawMessage(r.Metadata),
+		})
+	}
+	return out.End()
+}
+
+func (s *Service) exportBlockProgress(dbc dbctx.Context, userID uuid.UUID, out *documentWriter) error {
+	runs, err := s.deps.NodeRuns.ListByUser(dbc, userID, s.maxRows)
+	if err != nil {
+		return err
+	}
+	nodeIDs := make([]uuid.UUID, 0, len(runs))
+	for _, r := range runs {
+		if r != nil {
+			nodeIDs = append(nodeIDs, r.NodeID)
+		}
+	}
+	titles := map[uuid.UUID]string{}
+	if len(nodeIDs) > 0 && s.deps.PathNodes != nil {
+		nodes, err := s.deps.PathNodes.GetByIDs(dbc, nodeIDs)
+		if err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			if n != nil {
+				titles[n.ID] = n.Title
+			}
+		}
+	}
+	for _, r := range runs {
+		if r == nil {
+			continue
+		}
+		meta := map[string]any{}
+		if len(r.Metadata) > 0 {
+			_ = json.Unmarshal(r.Metadata, &meta)
+		}
+		rt, _ := meta["runtime"].(map[string]any)
+		out.Item(BlockProgressRecord{
+			PathID:          r.PathID,
+			NodeID:          r.NodeID,
+			NodeTitle:       titles[r.NodeID],
+			State:           string(r.State),
+			CompletedAt:     r.CompletedAt,
+			ReadBlocks:      stringList(rt["read_blocks"]),
+			CompletedBlocks: stringList(rt["completed_blocks"]),
+			ViewedBlocks:    stringList(rt["viewed_blocks"]),
+		})
+	}
+	return nil
+}
+
+// Import decodes r, maps its concepts onto this environment and merges it into the user's state.
+// With DryRun the report (including per-concept match confidence) is computed but nothing is
+// written.
+func (s *Service) Import(ctx context.Context, userID uuid.UUID, r io.Reader, maxItems int, opts ImportOptions) (*ImportReport, error) {
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("missing user id")
+	}
+	doc, err := DecodeDocument(r, maxItems)
+	if err != nil {
+		return nil, err
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	candidates, err := s.candidates(dbc)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := MatchConcepts(ctx, doc.Concepts(), candidates, s.deps.Embed, s.thresholds)
+	if err != nil {
+		return nil, fmt.Errorf("map concepts: %w", err)
+	}
+
+	if opts.DryRun {
+		existing, err := s.loadExisting(dbc, userID, doc, matches, false)
+		if err != nil {
+			return nil, err
+		}
+		plan := BuildImportPlan(userID, doc, matches, existing, time.Now().UTC())
+		plan.Report.DryRun = true
+		return &plan.Report, nil
+	}
+
+	// Existing state is read and locked in the write transaction, so a concurrent mastery or
+	// completion update can't land between the merge and the write and be lowered by it.
+	var plan *ImportPlan
+	err = s.deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txc := dbctx.Context{Ctx: ctx, Tx: tx}
+		existing, err := s.loadExisting(txc, userID, doc, matches, true)
+		if err != nil {
+			return err
+		}
+		plan = BuildImportPlan(userID, doc, matches, existing, time.Now().UTC())
+		if plan.Empty() {
+			return nil
+		}
+		for _, row := range plan.States {
+			if err := s.deps.ConceptStates.Upsert(txc, row); err != nil {
+				return err
+			}
+		}
+		if err := s.deps.Evidence.CreateIgnoreDuplicates(txc, plan.Evidence); err != nil {
+			return err
+		}
+		for _, row := range plan.Models {
+			if err := s.deps.ConceptModels.Upsert(txc, row); err != nil {
+				return err
+			}
+		}
+		for _, row := range plan.Misconceptions {
+			if err := s.deps.Misconceptions.Upsert(txc, row); err != nil {
+				return err
+			}
+		}
+		for _, row := range plan.CompletedUnits {
+			if err := s.deps.CompletedUnits.Upsert(txc, row); err != nil {
+				return err
+			}
+		}
+		for _, row := range plan.NodeRuns {
+			if err := s.deps.NodeRuns.Upsert(txc, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.log != nil {
+		s.log.Info("learning state imported",
+			"user_id", userID.String(),
+			"concept_states", plan.Report.ConceptStates.Applied,
+			"concept_models", plan.Report.ConceptModels.Applied,
+			"misconceptions", plan.Report.Misconceptions.Applied,
+			"block_progress", plan.Report.BlockProgress.Applied,
+			"completed_units", plan.Report.CompletedUnits.Applied,
+		)
+	}
+	return &plan.Report, nil
+}
+
+// candidates lists global concepts with their root canonical ID (following alias redirects).
+func (s *Service) candidates(dbc dbctx.Context) ([]Candidate, error) {
+	rows, err := s.deps.Concepts.GetByScope(dbc, "global", nil)
+	if err != nil {
+		return nil, err
+	}
+	parent := map[uuid.UUID]uuid.UUID{}
+	for _, c := range rows {
+		if c != nil && c.CanonicalConceptID != nil && *c.CanonicalConceptID != uuid.Nil && *c.CanonicalConceptID != c.ID {
+			parent[c.ID] = *c.CanonicalConceptID
+		}
+	}
+	root := func(id uuid.UUID) uuid.UUID {
+		for i := 0; i < 8; i++ {
+			next, ok := parent[id]
+			if !ok {
+				break
+			}
+			id = next
+		}
+		return id
+	}
+	out := make([]Candidate, 0, len(rows))
+	for _, c := range rows {
+		if c == nil || strings.TrimSpace(c.Key) == "" {
+			continue
+		}
+		out = append(out, Candidate{Ref: ConceptRef{ID: c.ID, Key: c.Key, Name: c.Name}, RootID: root(c.ID)})
+	}
+	return out, nil
+}
+
+// loadExisting reads the user's current state for everything doc touches; lock holds the
+// concept state and completed unit rows until dbc.Tx ends.
+func (s *Service) loadExisting(dbc dbctx.Context, userID uuid.UUID, doc *Document, matches []ConceptMatch, lock bool) (Existing, error) {
+	ex := Existing{
+		States:         map[uuid.UUID]*types.UserConceptState{},
+		Models:         map[uuid.UUID]*types.UserConceptModel{},
+		Misconceptions: map[string]*types.UserMisconceptionInstance{},
+		CompletedUnits: map[string]*types.UserCompletedUnit{},
+		NodeRuns:       map[uuid.UUID]*types.NodeRun{},
+		OwnedNodes:     map[uuid.UUID]uuid.UUID{},
+	}
+	targets := []uuid.UUID{}
+	for _, m := range matches {
+		if m.Applied() {
+			targets = append(targets, m.TargetID)
+		}
+	}
+	if len(targets) > 0 {
+		listStates := s.deps.ConceptStates.ListByUserAndConceptIDs
+		if lock {
+			listStates = s.deps.ConceptStates.ListByUserAndConceptIDsForUpdate
+		}
+		states, err := listStates(dbc, userID, targets)
+		if err != nil {
+			return ex, err
+		}
+		for _, r := range states {
+			ex.States[r.ConceptID] = r
+		}
+		models, err := s.deps.ConceptModels.ListByUserAndConceptIDs(dbc, userID, targets)
+		if err != nil {
+			return ex, err
+		}
+		for _, r := range models {
+			ex.Models[r.CanonicalConceptID] = r
+		}
+		miscons, err := s.deps.Misconceptions.ListByUserAndConceptIDs(dbc, userID, targets)
+		if err != nil {
+			return ex, err
+		}
+		for _, r := range miscons {
+			ex.Misconceptions[misconceptionKey(r.CanonicalConceptID, r.PatternID, r.Description)] = r
+		}
+	}
+	chainKeys := make([]string, 0, len(doc.CompletedUnits))
+	for _, rec := range doc.CompletedUnits {
+		if key := strings.TrimSpace(rec.ChainKey); key != "" {
+			chainKeys = append(chainKeys, key)
+		}
+	}
+	if len(chainKeys) > 0 {
+		listUnits := s.deps.CompletedUnits.ListByUserAndChainKeys
+		if lock {
+			listUnits = s.deps.CompletedUnits.ListByUserAndChainKeysForUpdate
+		}
+		units, err := listUnits(dbc, userID, chainKeys)
+		if err != nil {
+			return ex, err
+		}
+		for _, r := range units {
+			ex.CompletedUnits[r.ChainKey] = r
+		}
+	}
+
+	nodeIDs := []uuid.UUID{}
+	for _, rec := range doc.BlockProgress {
+		if rec.NodeID != uuid.Nil {
+			nodeIDs = append(nodeIDs, rec.NodeID)
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return ex, nil
+	}
+	nodes, err := s.deps.PathNodes.GetByIDs(dbc, nodeIDs)
+	if err != nil {
+		return ex, err
+	}
+	pathIDs := []uuid.UUID{}
+	for _, n := range nodes {
+		if n != nil {
+			pathIDs = append(pathIDs, n.PathID)
+		}
+	}
+	owned := map[uuid.UUID]bool{}
+	if len(pathIDs) > 0 {
+		paths, err := s.deps.Paths.GetByIDs(dbc, pathIDs)
+		if err != nil {
+			return ex, err
+		}
+		for _, p := range paths {
+			if p != nil && p.UserID != nil && *p.UserID == userID {
+				owned[p.ID] = true
+			}
+		}
+	}
+	for _, n := range nodes {
+		if n != nil && owned[n.PathID] {
+			ex.OwnedNodes[n.ID] = n.PathID
+		}
+	}
+	runs, err := s.deps.NodeRuns.ListByUserAndNodeIDs(dbc, userID, nodeIDs)
+	if err != nil {
+		return ex, err
+	}
+	for _, r := range runs {
+		ex.NodeRuns[r.NodeID] = r
+	}
+	return ex, nil
+}
@@ -36,6 +36,8 @@ type Input struct {
 	NodeDocJSON          string
 	PathStructureJSON    string
 	PatternHierarchyJSON string
+	// Optional output language for concept names/summaries (e.g. "es", "German").
+	OutputLanguage string
 	// User profile
 	UserFactsJSON        string
 	RecentEventsSummary  string
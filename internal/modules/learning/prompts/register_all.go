@@ -212,7 +212,7 @@ Output rules:
 
 	RegisterSpec(Spec{
 		Name:       PromptConceptInventory,
-		Version:    3, // schema version is inside ConceptInventorySchema() (currently const 3)
+		Version:    3, // result schema version is ConceptInventorySchemaVersion
 		SchemaName: "concept_inventory",
 		Schema:     ConceptInventorySchema,
 		System: `
@@ -230,6 +230,9 @@ CROSS_DOC_SECTION_GRAPH_JSON (optional; related sections across files):
 SEED_CONCEPT_KEYS_JSON (optional; hint list from file signatures):
 {{.SeedConceptKeysJSON}}
 
+OUTPUT_LANGUAGE (optional; language of the source material):
+{{.OutputLanguage}}
+
 EXCERPTS (each line includes chunk_id):
 {{.Excerpts}}
 
@@ -241,6 +244,7 @@ Task:
 - Provide summary + key_points + aliases + importance.
   - Prefer full descriptive concept keys over abbreviations (put abbreviations/acronyms in aliases).
   - aliases should include common shorthands and expanded names (e.g. "SGD" and "stochastic gradient descent").
+- If OUTPUT_LANGUAGE is provided, write name, summary, key_points, and aliases in that language; keys stay ASCII snake_case (transliterate if needed).
 - citations must be chunk_id strings actually used.
 - coverage: estimate completeness and list suspected missing topics.`,
 		Validators: []Validator{
@@ -498,6 +502,9 @@ PATH_INTENT_MD (optional; user goal context for relevance/noise filtering):
 EXISTING_CONCEPTS_JSON (do not repeat these; use these keys for parent_key when appropriate):
 {{.ConceptsJSON}}
 
+OUTPUT_LANGUAGE (optional; language of the source material):
+{{.OutputLanguage}}
+
 NEW_EXCERPTS (each line includes chunk_id):
 {{.Excerpts}}
 
@@ -508,6 +515,7 @@ Task:
 - Provide summary + key_points + aliases + importance.
   - Prefer full descriptive concept keys over abbreviations (put abbreviations/acronyms in aliases).
   - aliases should include common shorthands and expanded names (e.g. "SGD" and "stochastic gradient descent").
+- If OUTPUT_LANGUAGE is provided, write name, summary, key_points, and aliases in that language; keys stay ASCII snake_case (transliterate if needed).
 - citations must be chunk_id strings actually used.
 - coverage: estimate whether more passes are needed and list suspected missing topics.`,
 		Validators: []Validator{
@@ -317,6 +317,10 @@ func FileSignatureSchema() map[string]any {
 	})
 }
 
+// ConceptInventorySchemaVersion is the "version" const concept_inventory results carry; bump it
+// whenever the result shape changes.
+const ConceptInventorySchemaVersion = 3
+
 func ConceptInventorySchema() map[string]any {
 	concept := map[string]any{
 		"type": "object",
@@ -337,7 +341,7 @@ func ConceptInventorySchema() map[string]any {
 		},
 		"additionalProperties": false,
 	}
-	return SchemaVersionedObject(3, map[string]any{
+	return SchemaVersionedObject(ConceptInventorySchemaVersion, map[string]any{
 		"concepts": map[string]any{"type": "array", "items": concept},
 		"coverage": map[string]any{
 			"type": "object",
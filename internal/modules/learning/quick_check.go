@@ -30,6 +30,10 @@ type QuickCheckAttemptOutput struct {
 	Confidence   float64 `json:"confidence"`
 	QuestionType string  `json:"question_type,omitempty"`
 	OptionsCount int     `json:"options_count,omitempty"`
+	// SelectedOptionID and MisconceptionKey are set for choice questions; the key only when the
+	// learner picked a distractor tagged with one.
+	SelectedOptionID string `json:"selected_option_id,omitempty"`
+	MisconceptionKey string `json:"misconception_key,omitempty"`
 }
 
 func (u Usecases) QuickCheckAttempt(ctx context.Context, in QuickCheckAttemptInput) (QuickCheckAttemptOutput, error) {
@@ -190,9 +194,11 @@ func gradeChoiceQuickCheck(q quickCheckBlock, action string, answer string) Quic
 	}
 
 	valid := false
+	var selected content.DrillQuestionOptionV1
 	for _, o := range q.Options {
 		if strings.TrimSpace(o.ID) == answer {
 			valid = true
+			selected = o
 			break
 		}
 	}
@@ -208,20 +214,23 @@ func gradeChoiceQuickCheck(q quickCheckBlock, action string, answer string) Quic
 
 	if strings.TrimSpace(q.AnswerID) != "" && answer == strings.TrimSpace(q.AnswerID) {
 		return QuickCheckAttemptOutput{
-			Status:     "correct",
-			IsCorrect:  true,
-			FeedbackMD: clampText(strings.TrimSpace(q.AnswerMD), 1400),
-			HintMD:     "",
-			Confidence: 1,
+			Status:           "correct",
+			IsCorrect:        true,
+			FeedbackMD:       clampText(strings.TrimSpace(q.AnswerMD), 1400),
+			HintMD:           "",
+			Confidence:       1,
+			SelectedOptionID: answer,
 		}
 	}
 
 	return QuickCheckAttemptOutput{
-		Status:     "try_again",
-		IsCorrect:  false,
-		FeedbackMD: "Not quite.",
-		HintMD:     "Try again: look for the exact wording in the excerpt that the correct option matches. Eliminate any option that introduces extra conditions or claims not stated in the text.",
-		Confidence: 0.85,
+		Status:           "try_again",
+		IsCorrect:        false,
+		FeedbackMD:       "Not quite.",
+		HintMD:           "Try again: look for the exact wording in the excerpt that the correct option matches. Eliminate any option that introduces extra conditions or claims not stated in the text.",
+		Confidence:       0.85,
+		SelectedOptionID: answer,
+		MisconceptionKey: content.NormalizeMisconceptionKey(selected.MisconceptionKey),
 	}
 }
 
@@ -256,8 +265,9 @@ func findQuickCheckBlockByID(doc content.NodeDocV1, blockID string) (quickCheckB
 					continue
 				}
 				opts = append(opts, content.DrillQuestionOptionV1{
-					ID:   strings.TrimSpace(anyString(m["id"])),
-					Text: strings.TrimSpace(anyString(m["text"])),
+					ID:               strings.TrimSpace(anyString(m["id"])),
+					Text:             strings.TrimSpace(anyString(m["text"])),
+					MisconceptionKey: strings.TrimSpace(anyString(m["misconception_key"])),
 				})
 			}
 			out.Options = opts
@@ -211,3 +211,27 @@ func TestGradeChoiceQuickCheck_HintActionReturnsHint(t *testing.T) {
 		t.Fatalf("expected hint_md")
 	}
 }
+
+func TestGradeChoiceQuickCheck_TaggedDistractorReportsMisconceptionKey(t *testing.T) {
+	qc := quickCheckBlock{
+		Kind:     "mcq",
+		PromptMD: "Q?",
+		AnswerMD: "Explanation.",
+		Options: []content.DrillQuestionOptionV1{
+			{ID: "A", Text: "Option A", MisconceptionKey: "Confuses Mean-With Median"},
+			{ID: "B", Text: "Option B"},
+			{ID: "C", Text: "Option C"},
+		},
+		AnswerID: "B",
+	}
+	out := gradeChoiceQuickCheck(qc, "submit", "A")
+	if out.SelectedOptionID != "A" || out.MisconceptionKey != "confuses_mean_with_median" {
+		t.Fatalf("unexpected result: %#v", out)
+	}
+	if out = gradeChoiceQuickCheck(qc, "submit", "C"); out.MisconceptionKey != "" {
+		t.Fatalf("untagged distractor reported key %q", out.MisconceptionKey)
+	}
+	if out = gradeChoiceQuickCheck(qc, "submit", "B"); out.MisconceptionKey != "" || out.SelectedOptionID != "B" {
+		t.Fatalf("unexpected correct result: %#v", out)
+	}
+}
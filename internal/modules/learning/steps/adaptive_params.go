@@ -11,6 +11,8 @@ import (
 	"gorm.io/gorm"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+	"github.com/yungbote/neurobridge-backend/internal/platform/metabound"
 )
 
 type AdaptiveSignals struct {
@@ -44,7 +46,27 @@ func adaptiveParamsEnabled() bool {
 	return envBool("ADAPTIVE_PARAMS_ENABLED", true)
 }
 
-func adaptiveParamsEnabledForStage(stage string) bool {
+// adaptiveParamsEnabledForStage evaluates the stage's adaptive params flag for the job's
+// owner/path (see adaptiveParamsFlag).
+func adaptiveParamsEnabledForStage(ctx context.Context, stage string) bool {
+	enabled, _ := adaptiveParamsFlag(ctx, stage)
+	return enabled
+}
+
+// adaptiveParamsFlag evaluates "adaptive_params.<stage>"; the env switches below are its
+// default, so a flag row can enable or disable one stage for specific users or paths.
+func adaptiveParamsFlag(ctx context.Context, stage string) (bool, featureflag.Evaluation) {
+	stage = strings.TrimSpace(stage)
+	name := "adaptive_params"
+	if stage != "" {
+		name += "." + strings.ToLower(stage)
+	}
+	return featureflag.Bool(ctx, name, adaptiveParamsEnvEnabled(stage))
+}
+
+// adaptiveParamsEnvEnabled reads ADAPTIVE_PARAMS_ENABLED, ADAPTIVE_PARAMS_DISABLE_<STAGE> and
+// ADAPTIVE_PARAMS_DISABLE_STAGES.
+func adaptiveParamsEnvEnabled(stage string) bool {
 	if !adaptiveParamsEnabled() {
 		return false
 	}
@@ -119,13 +141,16 @@ func adaptiveSignalsMeta(signals AdaptiveSignals) map[string]any {
 	}
 }
 
-func adaptiveStageMeta(stage string, enabled bool, signals AdaptiveSignals, params map[string]any) map[string]any {
-	return map[string]any{
+func adaptiveStageMeta(ctx context.Context, stage string, enabled bool, signals AdaptiveSignals, params map[string]any) map[string]any {
+	meta := map[string]any{
 		"stage":   stage,
 		"enabled": enabled,
 		"signals": adaptiveSignalsMeta(signals),
-		"params":  params,
+		"params":  metabound.Bound(params),
 	}
+	_, ev := adaptiveParamsFlag(ctx, stage)
+	ev.Annotate(meta)
+	return meta
 }
 
 type AdaptiveParam struct {
@@ -0,0 +1,57 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/featureflag"
+)
+
+func TestAdaptiveParamsStageFlag(t *testing.T) {
+	t.Setenv("ADAPTIVE_PARAMS_ENABLED", "true")
+	t.Setenv("ADAPTIVE_PARAMS_DISABLE_STAGES", "node_doc_build")
+	beta, pathID := uuid.New(), uuid.New()
+	ctx := context.Background()
+	if err := featureflag.SetSource(ctx, func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		off := "false"
+		return []*types.FeatureFlag{
+			{Name: "adaptive_params.node_doc_build", Rules: datatypes.JSON(`{"users":[{"value":"true","user_ids":["` + beta.String() + `"]}]}`)},
+			{Name: "adaptive_params.embed_chunks", DefaultValue: &off, Rules: datatypes.JSON(`{"paths":{"` + pathID.String() + `":"true"}}`)},
+		}, nil
+	}); err != nil {
+		t.Fatalf("set source: %v", err)
+	}
+	t.Cleanup(func() { _ = featureflag.SetSource(ctx, nil) })
+
+	other := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: uuid.New()})
+	betaCtx := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: beta})
+	onPath := ctxutil.WithFlagSubject(ctx, ctxutil.FlagSubject{UserID: uuid.New(), PathID: pathID})
+
+	// Env disables node_doc_build; the allowlist re-enables it for one user.
+	if adaptiveParamsEnabledForStage(other, "node_doc_build") {
+		t.Fatal("env disable should apply without a matching rule")
+	}
+	if !adaptiveParamsEnabledForStage(betaCtx, "node_doc_build") {
+		t.Fatal("allowlisted user should get adaptive params")
+	}
+	// Table default turns embed_chunks off except on one path.
+	if adaptiveParamsEnabledForStage(other, "embed_chunks") || !adaptiveParamsEnabledForStage(onPath, "embed_chunks") {
+		t.Fatal("embed_chunks default/path override not applied")
+	}
+	// Stages without a row keep the env behavior.
+	if !adaptiveParamsEnabledForStage(other, "path_plan_build") {
+		t.Fatal("unflagged stage should follow env")
+	}
+
+	meta := adaptiveStageMeta(betaCtx, "node_doc_build", true, AdaptiveSignals{}, map[string]any{})
+	flags, _ := meta["flags"].(map[string]any)
+	entry, _ := flags["adaptive_params.node_doc_build"].(map[string]any)
+	if entry["source"] != featureflag.SourceUser {
+		t.Fatalf("stage meta flags = %v", meta["flags"])
+	}
+}
@@ -245,7 +245,7 @@ func applyChunkSignalWeights(ctx context.Context, db *gorm.DB, materialSetID uui
 	if db == nil || len(scores) == 0 || !envBool("MATERIAL_SIGNAL_RETRIEVAL_ENABLED", true) {
 		return
 	}
-	adaptiveEnabled := adaptiveParamsEnabledForStage(strings.TrimSpace(adaptiveStage))
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, strings.TrimSpace(adaptiveStage))
 	weight := envFloatAllowZero("MATERIAL_SIGNAL_RETRIEVAL_WEIGHT", 0.35)
 	if weight <= 0 {
 		return
@@ -0,0 +1,539 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+)
+
+// CanonicalReconcileDeps wires the duplicate canonical concept merge. AI and Vec are optional;
+// without them the global vectors are left for the next concept graph build to rewrite.
+type CanonicalReconcileDeps struct {
+	DB  *gorm.DB
+	Log *logger.Logger
+	AI  openai.Client
+	Vec pc.VectorStore
+}
+
+type CanonicalReconcileOptions struct {
+	// DryRun merges each group inside a transaction that is rolled back, so the report shows
+	// exactly what an apply would change.
+	DryRun bool
+	// Limit caps how many duplicate groups are processed (0 = all).
+	Limit int
+}
+
+// CanonicalDuplicateGroup is one natural key owned by more than one live global concept.
+type CanonicalDuplicateGroup struct {
+	Key      string      `json:"key"`
+	WinnerID uuid.UUID   `json:"winner_id"`
+	LoserIDs []uuid.UUID `json:"loser_ids"`
+	// Repointed counts rows moved from a loser to the winner, by table.column.
+	Repointed map[string]int64 `json:"repointed"`
+	// Dropped counts loser rows soft-deleted because the winner already had the same unique row.
+	Dropped map[string]int64 `json:"dropped,omitempty"`
+	// StatesMerged counts user_concept_state rows combined into an existing winner row.
+	StatesMerged int64  `json:"states_merged"`
+	Error        string `json:"error,omitempty"`
+}
+
+type CanonicalReconcileReport struct {
+	DryRun         bool                      `json:"dry_run"`
+	Groups         []CanonicalDuplicateGroup `json:"groups"`
+	VectorsDeleted int                       `json:"vectors_deleted"`
+	VectorsWritten int                       `json:"vectors_written"`
+}
+
+// canonicalRef is a column holding a canonical concept id.
+type canonicalRef struct {
+	Table  string
+	Column string
+	// Unique lists the other columns of a unique index over Column. A loser row that would
+	// collide with an existing winner row is soft-deleted instead of repointed.
+	Unique []string
+}
+
+var canonicalRefs = []canonicalRef{
+	{Table: "concept", Column: "canonical_concept_id"},
+	{Table: "concept_representation", Column: "canonical_concept_id"},
+	{Table: "concept_mapping_override", Column: "canonical_concept_id"},
+	{Table: "material_set_concept_coverage", Column: "canonical_concept_id"},
+	{Table: "user_concept_model", Column: "canonical_concept_id", Unique: []string{"user_id"}},
+	{Table: "user_misconception_instance", Column: "canonical_concept_id", Unique: []string{"user_id", "pattern_id", "description"}},
+	{Table: "user_concept_evidence", Column: "concept_id", Unique: []string{"user_id", "source", "source_ref"}},
+	{Table: "global_concept_coverage", Column: "global_concept_id", Unique: []string{"user_id"}},
+}
+
+var errCanonicalDryRun = errors.New("canonical reconcile dry run")
+
+// ReconcileCanonicalConcepts finds global concepts that share a natural key (left behind by
+// builds that raced before the natural-key index existed) and merges each group into one
+// survivor: references are repointed, user_concept_state rows are combined, losers are
+// soft-deleted as redirects to the winner, and the global vectors are updated. Groups are
+// merged in separate transactions; a failed group is reported and skipped.
+func ReconcileCanonicalConcepts(ctx context.Context, deps CanonicalReconcileDeps, opts CanonicalReconcileOptions) (CanonicalReconcileReport, error) {
+	report := CanonicalReconcileReport{DryRun: opts.DryRun, Groups: []CanonicalDuplicateGroup{}}
+	if deps.DB == nil {
+		return report, fmt.Errorf("canonical reconcile: missing db")
+	}
+
+	groups, err := findCanonicalDuplicateGroups(ctx, deps.DB)
+	if err != nil {
+		return report, err
+	}
+	if opts.Limit > 0 && len(groups) > opts.Limit {
+		groups = groups[:opts.Limit]
+	}
+
+	var winners []*types.Concept
+	var deadVectorIDs []string
+	for _, members := range groups {
+		winner, losers := pickCanonicalWinner(members)
+		g := CanonicalDuplicateGroup{
+			Key:       repos.ConceptNaturalKey(winner.Key),
+			WinnerID:  winner.ID,
+			Repointed: map[string]int64{},
+			Dropped:   map[string]int64{},
+		}
+		for _, l := range losers {
+			g.LoserIDs = append(g.LoserIDs, l.ID)
+		}
+
+		err := deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := mergeCanonicalGroup(tx, winner, losers, &g, time.Now().UTC()); err != nil {
+				return err
+			}
+			if opts.DryRun {
+				return errCanonicalDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errCanonicalDryRun) {
+			g.Error = err.Error()
+			if deps.Log != nil {
+				deps.Log.Warn("canonical reconcile: group merge failed", "key", g.Key, "winner_id", winner.ID.String(), "error", err)
+			}
+		} else if !opts.DryRun {
+			winners = append(winners, winner)
+			for _, id := range g.LoserIDs {
+				deadVectorIDs = append(deadVectorIDs, "concept:"+id.String())
+			}
+		}
+		report.Groups = append(report.Groups, g)
+	}
+
+	if !opts.DryRun && deps.Vec != nil {
+		report.VectorsWritten, report.VectorsDeleted = refreshCanonicalVectors(ctx, deps, winners, deadVectorIDs)
+	}
+	return report, nil
+}
+
+// findCanonicalDuplicateGroups returns live global concepts grouped by natural key, only for
+// keys with more than one row.
+func findCanonicalDuplicateGroups(ctx context.Context, db *gorm.DB) ([][]*types.Concept, error) {
+	var rows []*types.Concept
+	if err := db.WithContext(ctx).
+		Where(`scope = ? AND scope_id IS NULL AND lower(btrim(key)) IN (
+			SELECT lower(btrim(key)) FROM concept
+			WHERE scope = 'global' AND scope_id IS NULL AND deleted_at IS NULL
+			GROUP BY lower(btrim(key)) HAVING COUNT(*) > 1
+		)`, "global").
+		Order("lower(btrim(key)) ASC, created_at ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	var out [][]*types.Concept
+	byKey := map[string]int{}
+	for _, r := range rows {
+		k := repos.ConceptNaturalKey(r.Key)
+		i, ok := byKey[k]
+		if !ok {
+			i = len(out)
+			byKey[k] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], r)
+	}
+	return out, nil
+}
+
+// pickCanonicalWinner keeps the oldest root (a row that is not itself a redirect); if every
+// row is a redirect, the oldest row wins. members must be ordered oldest first.
+func pickCanonicalWinner(members []*types.Concept) (*types.Concept, []*types.Concept) {
+	winner := members[0]
+	for _, m := range members {
+		if m.CanonicalConceptID == nil || *m.CanonicalConceptID == uuid.Nil {
+			winner = m
+			break
+		}
+	}
+	losers := make([]*types.Concept, 0, len(members)-1)
+	for _, m := range members {
+		if m.ID != winner.ID {
+			losers = append(losers, m)
+		}
+	}
+	return winner, losers
+}
+
+func mergeCanonicalGroup(tx *gorm.DB, winner *types.Concept, losers []*types.Concept, g *CanonicalDuplicateGroup, now time.Time) error {
+	loserSet := map[uuid.UUID]*types.Concept{}
+	loserIDs := make([]uuid.UUID, 0, len(losers))
+	for _, l := range losers {
+		loserSet[l.ID] = l
+		loserIDs = append(loserIDs, l.ID)
+	}
+	groupIDs := append([]uuid.UUID{winner.ID}, loserIDs...)
+
+	// A winner that redirected to a loser would now point at a deleted row; point it at the
+	// loser's own target instead (or make it a root).
+	if winner.CanonicalConceptID != nil {
+		target := winner.CanonicalConceptID
+		for hops := 0; target != nil && loserSet[*target] != nil && hops < maxCanonicalHops; hops++ {
+			target = loserSet[*target].CanonicalConceptID
+		}
+		if target != nil && (*target == winner.ID || loserSet[*target] != nil) {
+			target = nil
+		}
+		if target == nil || *target != *winner.CanonicalConceptID {
+			if err := tx.Model(&types.Concept{}).Where("id = ?", winner.ID).
+				Updates(map[string]any{"canonical_concept_id": target, "updated_at": now}).Error; err != nil {
+				return fmt.Errorf("fix winner redirect: %w", err)
+			}
+			winner.CanonicalConceptID = target
+		}
+	}
+
+	for _, ref := range canonicalRefs {
+		label := ref.Table + "." + ref.Column
+		for _, loserID := range loserIDs {
+			dropped, moved, err := repointCanonicalRef(tx, ref, loserID, winner.ID, groupIDs, now)
+			if err != nil {
+				return fmt.Errorf("repoint %s: %w", label, err)
+			}
+			if dropped > 0 {
+				g.Dropped[label] += dropped
+			}
+			if moved > 0 {
+				g.Repointed[label] += moved
+			}
+		}
+	}
+
+	merged, moved, err := mergeCanonicalUserStates(tx, loserIDs, winner.ID, now)
+	if err != nil {
+		return fmt.Errorf("merge user_concept_state: %w", err)
+	}
+	g.StatesMerged = merged
+	if moved > 0 {
+		g.Repointed["user_concept_state.concept_id"] += moved
+	}
+
+	n, err := rewriteDerivedCanonicalIDs(tx, loserIDs, winner.ID, now)
+	if err != nil {
+		return fmt.Errorf("rewrite path_structural_unit.derived_canonical_concept_ids: %w", err)
+	}
+	if n > 0 {
+		g.Repointed["path_structural_unit.derived_canonical_concept_ids"] += n
+	}
+
+	// Losers stay as soft-deleted redirects so stale ids (vectors, caches) still resolve.
+	if err := tx.Model(&types.Concept{}).Where("id IN ?", loserIDs).
+		Updates(map[string]any{"canonical_concept_id": winner.ID, "deleted_at": now, "updated_at": now}).Error; err != nil {
+		return fmt.Errorf("retire losers: %w", err)
+	}
+	return nil
+}
+
+// repointCanonicalRef moves ref rows from loserID to winnerID. Rows in exclude (the duplicate
+// group itself) are left alone.
+func repointCanonicalRef(tx *gorm.DB, ref canonicalRef, loserID, winnerID uuid.UUID, exclude []uuid.UUID, now time.Time) (int64, int64, error) {
+	clash := ""
+	if len(ref.Unique) > 0 {
+		conds := make([]string, 0, len(ref.Unique))
+		for _, c := range ref.Unique {
+			conds = append(conds, fmt.Sprintf("w.%s IS NOT DISTINCT FROM t.%s", c, c))
+		}
+		clash = fmt.Sprintf("EXISTS (SELECT 1 FROM %s w WHERE w.%s = @winner AND %s)", ref.Table, ref.Column, strings.Join(conds, " AND "))
+	}
+	args := map[string]any{"loser": loserID, "winner": winnerID, "exclude": exclude, "now": now}
+
+	var dropped int64
+	if clash != "" {
+		res := tx.Exec(fmt.Sprintf("UPDATE %s t SET deleted_at = @now WHERE t.%s = @loser AND t.deleted_at IS NULL AND %s", ref.Table, ref.Column, clash), args)
+		if res.Error != nil {
+			return 0, 0, res.Error
+		}
+		dropped = res.RowsAffected
+	}
+
+	q := fmt.Sprintf("UPDATE %s t SET %s = @winner WHERE t.%s = @loser", ref.Table, ref.Column, ref.Column)
+	if ref.Table == "concept" {
+		q += " AND t.id NOT IN @exclude"
+	}
+	if clash != "" {
+		q += " AND NOT " + clash
+	}
+	res := tx.Exec(q, args)
+	if res.Error != nil {
+		return 0, 0, res.Error
+	}
+	return dropped, res.RowsAffected, nil
+}
+
+// mergeCanonicalUserStates moves each user's loser state onto the winner concept, combining it
+// with the winner's row when the user already has one.
+func mergeCanonicalUserStates(tx *gorm.DB, loserIDs []uuid.UUID, winnerID uuid.UUID, now time.Time) (int64, int64, error) {
+	var rows []*types.UserConceptState
+	if err := tx.Unscoped().Where("concept_id IN ?", loserIDs).Order("updated_at ASC, id ASC").Find(&rows).Error; err != nil {
+		return 0, 0, err
+	}
+	var merged, moved int64
+	for _, l := range rows {
+		var w types.UserConceptState
+		if err := tx.Unscoped().Where("user_id = ? AND concept_id = ?", l.UserID, winnerID).Limit(1).Find(&w).Error; err != nil {
+			return merged, moved, err
+		}
+		if w.ID == uuid.Nil {
+			if err := tx.Unscoped().Model(&types.UserConceptState{}).Where("id = ?", l.ID).
+				Updates(map[string]any{"concept_id": winnerID, "updated_at": now}).Error; err != nil {
+				return merged, moved, err
+			}
+			moved++
+			continue
+		}
+		m := mergeUserConceptState(&w, l)
+		m.UpdatedAt = now
+		if err := tx.Unscoped().Save(m).Error; err != nil {
+			return merged, moved, err
+		}
+		if err := tx.Unscoped().Model(&types.UserConceptState{}).Where("id = ?", l.ID).
+			Updates(map[string]any{"deleted_at": now, "updated_at": now}).Error; err != nil {
+			return merged, moved, err
+		}
+		merged++
+	}
+	return merged, moved, nil
+}
+
+// mergeUserConceptState combines one user's state for two concepts that turned out to be the
+// same. Evidence accumulates (max mastery and confidence, summed attempts, widest retention,
+// earliest due review); model parameters come from whichever row was updated by fresher
+// evidence. The result keeps w's identity.
+func mergeUserConceptState(w, l *types.UserConceptState) *types.UserConceptState {
+	out := *w
+	fresher := w
+	if userStateSeenAfter(l, w) {
+		fresher = l
+	}
+
+	out.BktPLearn = fresher.BktPLearn
+	out.BktPGuess = fresher.BktPGuess
+	out.BktPSlip = fresher.BktPSlip
+	out.BktPForget = fresher.BktPForget
+	out.EpistemicUncertainty = fresher.EpistemicUncertainty
+	out.AleatoricUncertainty = fresher.AleatoricUncertainty
+	out.DecayRate = fresher.DecayRate
+
+	out.Mastery = max(w.Mastery, l.Mastery)
+	out.Confidence = max(w.Confidence, l.Confidence)
+	out.HalfLifeDays = max(w.HalfLifeDays, l.HalfLifeDays)
+	out.Attempts = w.Attempts + l.Attempts
+	out.Correct = w.Correct + l.Correct
+
+	out.LastSeenAt = latestTime(w.LastSeenAt, l.LastSeenAt)
+	out.NextReviewAt = earliestTime(w.NextReviewAt, l.NextReviewAt)
+	out.Misconceptions = unionJSONArrays(w.Misconceptions, l.Misconceptions)
+
+	if l.CreatedAt.Before(out.CreatedAt) && !l.CreatedAt.IsZero() {
+		out.CreatedAt = l.CreatedAt
+	}
+	// Live if either side was live.
+	if !l.DeletedAt.Valid {
+		out.DeletedAt = gorm.DeletedAt{}
+	}
+	return &out
+}
+
+func userStateSeenAfter(a, b *types.UserConceptState) bool {
+	switch {
+	case a.LastSeenAt != nil && b.LastSeenAt != nil && !a.LastSeenAt.Equal(*b.LastSeenAt):
+		return a.LastSeenAt.After(*b.LastSeenAt)
+	case a.LastSeenAt != nil && b.LastSeenAt == nil:
+		return true
+	case a.LastSeenAt == nil && b.LastSeenAt != nil:
+		return false
+	}
+	return a.UpdatedAt.After(b.UpdatedAt)
+}
+
+func latestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.After(*b) {
+		return a
+	}
+	return b
+}
+
+func earliestTime(a, b *time.Time) *time.Time {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.Before(*b) {
+		return a
+	}
+	return b
+}
+
+// unionJSONArrays concatenates two JSON arrays, dropping repeated elements. Anything that is
+// not an array falls back to a (or b when a is empty).
+func unionJSONArrays(a, b datatypes.JSON) datatypes.JSON {
+	var xa, xb []json.RawMessage
+	errA := json.Unmarshal(a, &xa)
+	errB := json.Unmarshal(b, &xb)
+	if errA != nil || errB != nil {
+		if len(a) == 0 || string(a) == "null" {
+			return b
+		}
+		return a
+	}
+	seen := map[string]bool{}
+	out := make([]json.RawMessage, 0, len(xa)+len(xb))
+	for _, x := range append(xa, xb...) {
+		if seen[string(x)] {
+			continue
+		}
+		seen[string(x)] = true
+		out = append(out, x)
+	}
+	return datatypes.JSON(mustJSON(out))
+}
+
+// rewriteDerivedCanonicalIDs swaps loser ids for the winner in path_structural_unit's jsonb
+// id lists.
+func rewriteDerivedCanonicalIDs(tx *gorm.DB, loserIDs []uuid.UUID, winnerID uuid.UUID, now time.Time) (int64, error) {
+	losers := map[string]bool{}
+	clauses := make([]string, 0, len(loserIDs))
+	args := make([]any, 0, len(loserIDs))
+	for _, id := range loserIDs {
+		losers[id.String()] = true
+		clauses = append(clauses, "derived_canonical_concept_ids @> ?::jsonb")
+		args = append(args, string(mustJSON([]string{id.String()})))
+	}
+	var rows []*types.PathStructuralUnit
+	if err := tx.Unscoped().
+		Select("id", "derived_canonical_concept_ids").
+		Where(strings.Join(clauses, " OR "), args...).
+		Find(&rows).Error; err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, r := range rows {
+		var ids []string
+		if err := json.Unmarshal(r.DerivedCanonicalConceptIDs, &ids); err != nil {
+			continue
+		}
+		next := make([]string, 0, len(ids))
+		seen := map[string]bool{}
+		for _, id := range ids {
+			if losers[id] {
+				id = winnerID.String()
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			next = append(next, id)
+		}
+		if err := tx.Unscoped().Model(&types.PathStructuralUnit{}).Where("id = ?", r.ID).
+			Updates(map[string]any{"derived_canonical_concept_ids": datatypes.JSON(mustJSON(next)), "updated_at": now}).Error; err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// refreshCanonicalVectors re-embeds each winner into the global namespace (when an embedder
+// is wired) and deletes the losers' vectors. Best-effort: failures are logged.
+func refreshCanonicalVectors(ctx context.Context, deps CanonicalReconcileDeps, winners []*types.Concept, deadIDs []string) (int, int) {
+	ns := index.ConceptsNamespace("global", nil)
+	written := 0
+	if deps.AI != nil && len(winners) > 0 {
+		docs := make([]string, 0, len(winners))
+		for _, w := range winners {
+			var keyPoints []string
+			_ = json.Unmarshal(w.KeyPoints, &keyPoints)
+			doc := strings.TrimSpace(w.Name + "\n" + w.Summary + "\n" + strings.Join(keyPoints, "\n"))
+			if doc == "" {
+				doc = w.Key
+			}
+			docs = append(docs, doc)
+		}
+		embs, err := deps.AI.Embed(ctx, docs)
+		if err == nil && len(embs) == len(winners) {
+			vectors := make([]pc.Vector, 0, len(winners))
+			for i, w := range winners {
+				if len(embs[i]) == 0 {
+					continue
+				}
+				name := strings.TrimSpace(w.Name)
+				if name == "" {
+					name = w.Key
+				}
+				vectors = append(vectors, pc.Vector{
+					ID:     "concept:" + w.ID.String(),
+					Values: embs[i],
+					Metadata: map[string]any{
+						"type":         "concept",
+						"scope":        "global",
+						"canonical":    true,
+						"concept_id":   w.ID.String(),
+						"observedKey":  w.Key,
+						"observedName": name,
+					},
+				})
+			}
+			if err := deps.Vec.Upsert(ctx, ns, vectors); err != nil {
+				err = fmt.Errorf("upsert winners: %w", err)
+				if deps.Log != nil {
+					deps.Log.Warn("canonical reconcile: vector upsert failed", "namespace", ns, "error", err)
+				}
+			} else {
+				written = len(vectors)
+			}
+		} else if deps.Log != nil {
+			deps.Log.Warn("canonical reconcile: winner embeddings failed", "error", err)
+		}
+	}
+
+	deleted := 0
+	if len(deadIDs) > 0 {
+		sort.Strings(deadIDs)
+		if err := deps.Vec.DeleteIDs(ctx, ns, deadIDs); err != nil {
+			if deps.Log != nil {
+				deps.Log.Warn("canonical reconcile: vector delete failed", "namespace", ns, "count", len(deadIDs), "error", err)
+			}
+		} else {
+			deleted = len(deadIDs)
+		}
+	}
+	return written, deleted
+}
@@ -0,0 +1,201 @@
+package steps
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	dbpkg "github.com/yungbote/neurobridge-backend/internal/data/db"
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestMergeUserConceptState(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(48 * time.Hour)
+	w := &types.UserConceptState{
+		ID: uuid.New(), Mastery: 0.4, Confidence: 0.9, Attempts: 3, Correct: 1,
+		BktPLearn: 0.1, HalfLifeDays: 2, LastSeenAt: &t0, NextReviewAt: &t1,
+		Misconceptions: datatypes.JSON(`["sign"]`), CreatedAt: t1,
+	}
+	l := &types.UserConceptState{
+		ID: uuid.New(), Mastery: 0.7, Confidence: 0.5, Attempts: 2, Correct: 2,
+		BktPLearn: 0.3, HalfLifeDays: 5, LastSeenAt: &t1, NextReviewAt: &t0,
+		Misconceptions: datatypes.JSON(`["sign","units"]`), CreatedAt: t0,
+	}
+	m := mergeUserConceptState(w, l)
+	if m.ID != w.ID || m.Mastery != 0.7 || m.Confidence != 0.9 || m.Attempts != 5 || m.Correct != 3 || m.HalfLifeDays != 5 {
+		t.Fatalf("merged = %+v", m)
+	}
+	if m.BktPLearn != 0.3 || !m.LastSeenAt.Equal(t1) || !m.NextReviewAt.Equal(t0) || !m.CreatedAt.Equal(t0) {
+		t.Fatalf("merged params/times = %+v", m)
+	}
+	if string(m.Misconceptions) != `["sign","units"]` {
+		t.Fatalf("misconceptions = %s", m.Misconceptions)
+	}
+}
+
+func TestCanonicalizeConcurrentBuildsShareOneGlobalConcept(t *testing.T) {
+	db := testutil.DB(t)
+	log := testutil.Logger(t)
+	ctx := context.Background()
+	if created, dupes, err := dbpkg.EnsureCanonicalConceptKeyIndex(db); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	} else if !created {
+		t.Skipf("test db has %d duplicate canonical keys", dupes)
+	}
+
+	key := "race " + uuid.NewString()[:8]
+	pathConcepts := make([]*types.Concept, 2)
+	for i := range pathConcepts {
+		pathID := uuid.New()
+		pathConcepts[i] = &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &pathID, Key: key, Name: key}
+	}
+	if err := db.Create(&pathConcepts).Error; err != nil {
+		t.Fatalf("seed path concepts: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("lower(btrim(key)) = ?", key).Delete(&types.Concept{})
+		db.Unscoped().Where("path_concept_id IN ?", []uuid.UUID{pathConcepts[0].ID, pathConcepts[1].ID}).Delete(&types.ConceptRepresentation{})
+	})
+
+	var (
+		start = make(chan struct{})
+		wg    sync.WaitGroup
+		got   = make([]uuid.UUID, 2)
+		errs  = make([]error, 2)
+	)
+	for i := range pathConcepts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+				out, err := canonicalizePathConcepts(dbc, tx,
+					repolearning.NewConceptRepo(tx, log),
+					repolearning.NewConceptRepresentationRepo(tx, log),
+					repolearning.NewConceptMappingOverrideRepo(tx, log),
+					[]*types.Concept{pathConcepts[i]}, nil)
+				got[i] = out[key]
+				return err
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("build %d: %v", i, err)
+		}
+	}
+	var globals []*types.Concept
+	if err := db.Where("scope = ? AND scope_id IS NULL AND lower(btrim(key)) = ?", "global", key).Find(&globals).Error; err != nil {
+		t.Fatalf("load globals: %v", err)
+	}
+	if len(globals) != 1 || got[0] != globals[0].ID || got[1] != globals[0].ID {
+		t.Fatalf("globals=%d got=%v", len(globals), got)
+	}
+	for _, pc := range pathConcepts {
+		var row types.Concept
+		if err := db.First(&row, "id = ?", pc.ID).Error; err != nil {
+			t.Fatalf("reload path concept: %v", err)
+		}
+		if row.CanonicalConceptID == nil || *row.CanonicalConceptID != globals[0].ID {
+			t.Fatalf("path concept %s -> %v, want %s", pc.ID, row.CanonicalConceptID, globals[0].ID)
+		}
+	}
+}
+
+func TestReconcileCanonicalConceptsMergesDuplicates(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	ctx := context.Background()
+	if err := tx.Exec(`DROP INDEX IF EXISTS idx_concept_global_natural_key`).Error; err != nil {
+		t.Fatalf("drop index: %v", err)
+	}
+
+	suffix := uuid.NewString()[:8]
+	older := time.Now().UTC().Add(-time.Hour)
+	winner := &types.Concept{ID: uuid.New(), Scope: "global", Key: "Limits " + suffix, Name: "Limits", CreatedAt: older, UpdatedAt: older}
+	loser := &types.Concept{ID: uuid.New(), Scope: "global", Key: "limits " + suffix + " ", Name: "limits"}
+	if err := tx.Create([]*types.Concept{winner, loser}).Error; err != nil {
+		t.Fatalf("seed globals: %v", err)
+	}
+	pathID := uuid.New()
+	pathConcept := &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &pathID, Key: "limits " + suffix, Name: "limits", CanonicalConceptID: &loser.ID}
+	if err := tx.Create(pathConcept).Error; err != nil {
+		t.Fatalf("seed path concept: %v", err)
+	}
+	userID := uuid.New()
+	for _, st := range []*types.UserConceptState{
+		{UserID: userID, ConceptID: winner.ID, Mastery: 0.3, Attempts: 2},
+		{UserID: userID, ConceptID: loser.ID, Mastery: 0.8, Attempts: 4},
+	} {
+		if err := tx.Create(st).Error; err != nil {
+			t.Fatalf("seed state: %v", err)
+		}
+	}
+
+	deps := CanonicalReconcileDeps{DB: tx, Log: testutil.Logger(t)}
+	findGroup := func(r CanonicalReconcileReport) *CanonicalDuplicateGroup {
+		for i := range r.Groups {
+			if r.Groups[i].WinnerID == winner.ID {
+				return &r.Groups[i]
+			}
+		}
+		return nil
+	}
+
+	dry, err := ReconcileCanonicalConcepts(ctx, deps, CanonicalReconcileOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if g := findGroup(dry); g == nil || g.Repointed["concept.canonical_concept_id"] != 1 || g.StatesMerged != 1 {
+		t.Fatalf("dry run group = %+v", g)
+	}
+	var live int64
+	tx.Model(&types.Concept{}).Where("id = ?", loser.ID).Count(&live)
+	if live != 1 {
+		t.Fatal("dry run retired the loser")
+	}
+
+	report, err := ReconcileCanonicalConcepts(ctx, deps, CanonicalReconcileOptions{})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if g := findGroup(report); g == nil || g.Error != "" || strings.TrimSpace(g.Key) != g.Key {
+		t.Fatalf("group = %+v", g)
+	}
+
+	var gotLoser types.Concept
+	if err := tx.Unscoped().First(&gotLoser, "id = ?", loser.ID).Error; err != nil {
+		t.Fatalf("reload loser: %v", err)
+	}
+	if !gotLoser.DeletedAt.Valid || gotLoser.CanonicalConceptID == nil || *gotLoser.CanonicalConceptID != winner.ID {
+		t.Fatalf("loser = %+v", gotLoser)
+	}
+	var gotPath types.Concept
+	if err := tx.First(&gotPath, "id = ?", pathConcept.ID).Error; err != nil {
+		t.Fatalf("reload path concept: %v", err)
+	}
+	if gotPath.CanonicalConceptID == nil || *gotPath.CanonicalConceptID != winner.ID {
+		t.Fatalf("path concept -> %v", gotPath.CanonicalConceptID)
+	}
+	var states []*types.UserConceptState
+	if err := tx.Where("user_id = ?", userID).Find(&states).Error; err != nil {
+		t.Fatalf("load states: %v", err)
+	}
+	if len(states) != 1 || states[0].ConceptID != winner.ID || states[0].Mastery != 0.8 || states[0].Attempts != 6 {
+		t.Fatalf("states = %+v", states)
+	}
+}
@@ -2,14 +2,12 @@ package steps
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
@@ -21,7 +19,8 @@ import (
 //
 // This is a production-oriented primitive:
 // - It is safe to call repeatedly (idempotent).
-// - It is safe under concurrency (ON CONFLICT DO NOTHING on canonical inserts).
+// - It is safe under concurrency without cross-path locks: canonical inserts are ON CONFLICT DO NOTHING
+//   against the natural-key unique index, followed by a fetch that adopts whichever row won.
 // - It keeps path concept IDs stable while enabling cross-path mastery transfer via canonical IDs.
 //
 // semanticMatchByKey (optional): normalized concept_key -> canonical global concept UUID.
@@ -111,36 +110,46 @@ func canonicalizePathConcepts(
 		return out, nil
 	}
 
-	// Load existing global concepts for these keys.
-	existing, err := conceptRepo.GetByScopeAndKeys(dbc, "global", nil, keys)
+	// Load existing global concepts for these keys. Historical duplicates (see
+	// ReconcileCanonicalConcepts) resolve to the oldest row.
+	existing, err := conceptRepo.GetGlobalByNaturalKeys(dbc, keys)
 	if err != nil {
 		return nil, err
 	}
-	globalByKey := map[string]*types.Concept{}
-	for _, g := range existing {
-		if g == nil || g.ID == uuid.Nil {
-			continue
+	globalByKey := firstGlobalByKey(existing)
+	for k := range globalByKey {
+		mappingByKey[k] = mappingInfo{Method: "exact_key", Confidence: 1.0}
+	}
+
+	// Semantic matches may point at alias rows or at canonicals merged away since they were
+	// indexed; always redirect to the current root.
+	if len(semanticMatchByKey) > 0 {
+		ids := make([]uuid.UUID, 0, len(semanticMatchByKey))
+		for _, m := range semanticMatchByKey {
+			ids = append(ids, m.ID)
 		}
-		k := strings.TrimSpace(strings.ToLower(g.Key))
-		if k != "" {
-			globalByKey[k] = g
-			// If this global concept is an alias/redirect, treat the canonical ID as the root.
-			if g.CanonicalConceptID != nil && *g.CanonicalConceptID != uuid.Nil {
-				out[k] = *g.CanonicalConceptID
-			} else {
-				out[k] = g.ID
+		roots, err := resolveCanonicalRoots(dbc, db, ids)
+		if err != nil {
+			return nil, err
+		}
+		resolved := make(map[string]canonicalMatch, len(semanticMatchByKey))
+		for k, m := range semanticMatchByKey {
+			if root := roots[m.ID]; root != uuid.Nil {
+				m.ID = root
 			}
-			mappingByKey[k] = mappingInfo{Method: "exact_key", Confidence: 1.0}
+			resolved[k] = m
 		}
+		semanticMatchByKey = resolved
 	}
 
-	// Create missing global concepts (race-safe). These may be:
+	// Create missing global concepts. These may be:
 	// - canonical (canonical_concept_id NULL), or
 	// - alias/redirect (canonical_concept_id = root_id) when semanticMatchByKey provides a match.
 	now := time.Now().UTC()
 	toCreate := make([]*types.Concept, 0)
+	createdByKey := map[string]*types.Concept{}
 	for _, k := range keys {
-		if k == "" || out[k] != uuid.Nil {
+		if k == "" || globalByKey[k] != nil {
 			continue
 		}
 		meta := infoByKey[k]
@@ -205,85 +214,49 @@ func canonicalizePathConcepts(
 			if method != "" {
 				mappingByKey[k] = mappingInfo{Method: method, Confidence: conf}
 			}
+		} else {
+			mappingByKey[k] = mappingInfo{Method: "created_global", Confidence: 1.0}
 		}
 		// VectorID is a best-effort cache key; keep it stable for this canonical row.
 		row.VectorID = "concept:" + row.ID.String()
 		toCreate = append(toCreate, row)
-		// Optimistically record; if a conflict happens, we'll refresh below.
-		if rootID != uuid.Nil {
-			out[k] = rootID
-		} else {
-			out[k] = row.ID
-			if _, ok := mappingByKey[k]; !ok {
-				mappingByKey[k] = mappingInfo{Method: "created_global", Confidence: 1.0}
-			}
-		}
+		createdByKey[k] = row
 	}
 
 	if len(toCreate) > 0 {
-		// Prefer a DB-level "do nothing" upsert to avoid failing the whole batch on concurrent inserts.
-		tx := dbc.Tx
-		if tx == nil {
-			tx = db
-		}
-		if tx == nil {
-			return nil, fmt.Errorf("canonicalizePathConcepts: missing db")
-		}
-		// Use a target-less ON CONFLICT so this is safe even on installs that haven't created the
-		// partial unique indexes yet (older DBs). When the indexes exist, conflicts are ignored.
-		if err := tx.WithContext(dbc.Ctx).
-			Clauses(clause.OnConflict{DoNothing: true}).
-			Create(&toCreate).Error; err != nil {
-			return nil, err
-		}
-
-		// Refresh canonical IDs to resolve any conflicts (stable truth).
-		existing, err := conceptRepo.GetByScopeAndKeys(dbc, "global", nil, keys)
+		// INSERT ... ON CONFLICT DO NOTHING, then fetch: a concurrent build (any path) that
+		// created the same natural key first wins, and we adopt its row as-is.
+		stored, err := conceptRepo.CreateGlobalIfAbsent(dbc, toCreate)
 		if err != nil {
 			return nil, err
 		}
-		globalByKey = map[string]*types.Concept{}
-		for _, g := range existing {
-			if g == nil || g.ID == uuid.Nil {
+		for k, g := range firstGlobalByKey(stored) {
+			if globalByKey[k] != nil {
 				continue
 			}
-			k := strings.TrimSpace(strings.ToLower(g.Key))
-			if k != "" {
-				globalByKey[k] = g
-				if g.CanonicalConceptID != nil && *g.CanonicalConceptID != uuid.Nil {
-					out[k] = *g.CanonicalConceptID
-				} else {
-					out[k] = g.ID
-				}
-				if _, ok := mappingByKey[k]; !ok {
-					mappingByKey[k] = mappingInfo{Method: "exact_key", Confidence: 1.0}
-				}
+			globalByKey[k] = g
+			if mine := createdByKey[k]; mine == nil || mine.ID != g.ID {
+				// Lost the race. Never rewrite the winner's row (e.g. into an alias of our semantic
+				// match): other paths may already point at it.
+				mappingByKey[k] = mappingInfo{Method: "exact_key", Confidence: 1.0}
 			}
 		}
+	}
 
-		// If a semantic match was requested for a key but a concurrent insert created a canonical row first,
-		// enforce the alias redirect by setting canonical_concept_id (best-effort).
-		if semanticMatchByKey != nil && len(semanticMatchByKey) > 0 {
-			for k, match := range semanticMatchByKey {
-				if match.ID == uuid.Nil {
-					continue
-				}
-				row := globalByKey[k]
-				if row == nil || row.ID == uuid.Nil {
-					continue
-				}
-				// If already redirected, respect existing mapping.
-				if row.CanonicalConceptID != nil && *row.CanonicalConceptID != uuid.Nil {
-					continue
-				}
-				if err := conceptRepo.UpdateFields(dbc, row.ID, map[string]interface{}{"canonical_concept_id": match.ID}); err != nil {
-					return nil, err
-				}
-				out[k] = match.ID
-				if _, ok := mappingByKey[k]; !ok {
-					mappingByKey[k] = mappingInfo{Method: "semantic", Confidence: match.Score}
-				}
-			}
+	// Resolve every key to its root canonical concept.
+	linkIDs := make([]uuid.UUID, 0, len(globalByKey))
+	for _, g := range globalByKey {
+		linkIDs = append(linkIDs, g.ID)
+	}
+	roots, err := resolveCanonicalRoots(dbc, db, linkIDs)
+	if err != nil {
+		return nil, err
+	}
+	for k, g := range globalByKey {
+		if root := roots[g.ID]; root != uuid.Nil {
+			out[k] = root
+		} else {
+			out[k] = g.ID
 		}
 	}
 
@@ -340,3 +313,72 @@ func resolveCanonicalOverride(pathConceptID uuid.UUID, overrides map[uuid.UUID]u
 	}
 	return overrides[pathConceptID]
 }
+
+// firstGlobalByKey indexes global concepts by natural key, keeping the first row per key
+// (callers pass rows oldest first).
+func firstGlobalByKey(rows []*types.Concept) map[string]*types.Concept {
+	out := map[string]*types.Concept{}
+	for _, g := range rows {
+		if g == nil || g.ID == uuid.Nil {
+			continue
+		}
+		k := repos.ConceptNaturalKey(g.Key)
+		if k == "" || out[k] != nil {
+			continue
+		}
+		out[k] = g
+	}
+	return out
+}
+
+// maxCanonicalHops bounds alias chains (and guards against cycles).
+const maxCanonicalHops = 8
+
+// resolveCanonicalRoots follows canonical_concept_id redirects from each global concept id to
+// its root. Soft-deleted rows are followed too, so ids merged away by reconciliation still
+// resolve to the surviving canonical.
+func resolveCanonicalRoots(dbc dbctx.Context, db *gorm.DB, ids []uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	out := map[uuid.UUID]uuid.UUID{}
+	tx := dbc.Tx
+	if tx == nil {
+		tx = db
+	}
+	if tx == nil || len(ids) == 0 {
+		return out, nil
+	}
+	next := map[uuid.UUID]*uuid.UUID{}
+	frontier := dedupeUUIDs(ids)
+	for hop := 0; hop <= maxCanonicalHops && len(frontier) > 0; hop++ {
+		var rows []*types.Concept
+		if err := tx.WithContext(dbc.Ctx).Unscoped().
+			Select("id", "canonical_concept_id").
+			Where("id IN ?", frontier).
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		frontier = frontier[:0]
+		for _, r := range rows {
+			next[r.ID] = r.CanonicalConceptID
+			if r.CanonicalConceptID != nil && *r.CanonicalConceptID != uuid.Nil {
+				if _, seen := next[*r.CanonicalConceptID]; !seen {
+					frontier = append(frontier, *r.CanonicalConceptID)
+				}
+			}
+		}
+	}
+	for _, id := range ids {
+		if id == uuid.Nil {
+			continue
+		}
+		cur := id
+		for hop := 0; hop < maxCanonicalHops; hop++ {
+			p, ok := next[cur]
+			if !ok || p == nil || *p == uuid.Nil || *p == id {
+				break
+			}
+			cur = *p
+		}
+		out[id] = cur
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type conceptDocEmbedStats struct {
+	Docs     int
+	Reused   int
+	Embedded int
+}
+
+func conceptDocEmbedModel() string {
+	model := strings.TrimSpace(os.Getenv("OPENAI_EMBED_MODEL"))
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return "openai:" + model
+}
+
+// embedConceptDocsIncremental embeds docs, reusing stored vectors for any doc whose text hash was
+// embedded before under the same model. Rebuilds usually change a handful of concepts, so only
+// those reach the embedder. Cache failures degrade to embedding everything; they never fail the build.
+func embedConceptDocsIncremental(
+	ctx context.Context,
+	log *logger.Logger,
+	cache repos.ConceptDocEmbeddingRepo,
+	model string,
+	docs []string,
+	embed func(context.Context, []string) ([][]float32, error),
+) ([][]float32, conceptDocEmbedStats, error) {
+	stats := conceptDocEmbedStats{Docs: len(docs)}
+	if cache == nil || len(docs) == 0 {
+		out, err := embed(ctx, docs)
+		stats.Embedded = len(docs)
+		return out, stats, err
+	}
+
+	hashes := make([]string, len(docs))
+	unique := make([]string, 0, len(docs))
+	seen := map[string]bool{}
+	for i, doc := range docs {
+		h := content.HashBytes([]byte(doc))
+		hashes[i] = h
+		if !seen[h] {
+			seen[h] = true
+			unique = append(unique, h)
+		}
+	}
+
+	byHash := map[string][]float32{}
+	rows, err := cache.GetByHashes(dbctx.Context{Ctx: ctx}, model, unique)
+	if err != nil && log != nil {
+		log.Warn("concept doc embedding cache read failed; embedding all docs", "error", err)
+	}
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		if v, ok := decodeEmbedding(row.Embedding); ok {
+			byHash[row.DocHash] = v
+		}
+	}
+
+	missing := make([]string, 0, len(unique))
+	missingDocs := make([]string, 0, len(unique))
+	for i, h := range hashes {
+		if _, ok := byHash[h]; ok {
+			continue
+		}
+		if seen[h] {
+			seen[h] = false
+			missing = append(missing, h)
+			missingDocs = append(missingDocs, docs[i])
+		}
+	}
+
+	if len(missingDocs) > 0 {
+		vecs, err := embed(ctx, missingDocs)
+		if err != nil {
+			return nil, stats, err
+		}
+		if len(vecs) != len(missingDocs) {
+			return nil, stats, fmt.Errorf("concept doc embeddings: count mismatch (got %d want %d)", len(vecs), len(missingDocs))
+		}
+		newRows := make([]*types.ConceptDocEmbedding, 0, len(vecs))
+		for i, v := range vecs {
+			byHash[missing[i]] = v
+			b, _ := json.Marshal(v)
+			newRows = append(newRows, &types.ConceptDocEmbedding{
+				DocHash:   missing[i],
+				Model:     model,
+				Dims:      len(v),
+				Embedding: datatypes.JSON(b),
+			})
+		}
+		if err := cache.CreateIgnoreDuplicates(dbctx.Context{Ctx: ctx}, newRows); err != nil && log != nil {
+			log.Warn("concept doc embedding cache write failed", "error", err)
+		}
+	}
+
+	out := make([][]float32, len(docs))
+	for i, h := range hashes {
+		v := byHash[h]
+		if len(v) == 0 {
+			return nil, stats, fmt.Errorf("concept doc embeddings: empty embedding at index %d", i)
+		}
+		out[i] = v
+	}
+	stats.Embedded = len(missingDocs)
+	stats.Reused = len(docs) - len(missingDocs)
+	return out, stats, nil
+}
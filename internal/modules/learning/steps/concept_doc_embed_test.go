@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type memConceptDocEmbeddingRepo struct {
+	repos.ConceptDocEmbeddingRepo
+	rows    map[string]*types.ConceptDocEmbedding
+	readErr error
+}
+
+func (r *memConceptDocEmbeddingRepo) GetByHashes(dbc dbctx.Context, model string, hashes []string) ([]*types.ConceptDocEmbedding, error) {
+	if r.readErr != nil {
+		return nil, r.readErr
+	}
+	out := []*types.ConceptDocEmbedding{}
+	for _, h := range hashes {
+		if row, ok := r.rows[model+"|"+h]; ok {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *memConceptDocEmbeddingRepo) CreateIgnoreDuplicates(dbc dbctx.Context, rows []*types.ConceptDocEmbedding) error {
+	for _, row := range rows {
+		if _, ok := r.rows[row.Model+"|"+row.DocHash]; !ok {
+			r.rows[row.Model+"|"+row.DocHash] = row
+		}
+	}
+	return nil
+}
+
+type countingEmbedder struct {
+	calls [][]string
+}
+
+func (e *countingEmbedder) embed(ctx context.Context, docs []string) ([][]float32, error) {
+	e.calls = append(e.calls, append([]string(nil), docs...))
+	out := make([][]float32, len(docs))
+	for i, d := range docs {
+		out[i] = []float32{float32(len(d)), 1}
+	}
+	return out, nil
+}
+
+func (e *countingEmbedder) embedded() int {
+	n := 0
+	for _, c := range e.calls {
+		n += len(c)
+	}
+	return n
+}
+
+func TestEmbedConceptDocsIncrementalOnlyEmbedsChangedDocs(t *testing.T) {
+	ctx := context.Background()
+	cache := &memConceptDocEmbeddingRepo{rows: map[string]*types.ConceptDocEmbedding{}}
+	docs := []string{"Loops\nRepeat work", "Arrays\nIndexed storage", "Recursion\nSelf reference"}
+
+	first := &countingEmbedder{}
+	_, stats, err := embedConceptDocsIncremental(ctx, nil, cache, "openai:test", docs, first.embed)
+	if err != nil {
+		t.Fatalf("first build: %v", err)
+	}
+	if first.embedded() != 3 || stats.Reused != 0 {
+		t.Fatalf("first build embedded %d, stats %+v", first.embedded(), stats)
+	}
+
+	// Rebuild with one concept edited.
+	docs[1] = "Arrays\nContiguous indexed storage"
+	second := &countingEmbedder{}
+	embs, stats, err := embedConceptDocsIncremental(ctx, nil, cache, "openai:test", docs, second.embed)
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if second.embedded() != 1 || second.calls[0][0] != docs[1] {
+		t.Fatalf("rebuild should embed only the changed doc, got %v", second.calls)
+	}
+	if stats.Reused != 2 || stats.Embedded != 1 {
+		t.Fatalf("stats: %+v", stats)
+	}
+	if len(embs) != 3 || embs[1][0] != float32(len(docs[1])) || embs[0][0] != float32(len(docs[0])) {
+		t.Fatalf("embeddings out of order: %v", embs)
+	}
+
+	// Nothing changed: the embedder is not called at all.
+	third := &countingEmbedder{}
+	if _, _, err := embedConceptDocsIncremental(ctx, nil, cache, "openai:test", docs, third.embed); err != nil {
+		t.Fatalf("unchanged rebuild: %v", err)
+	}
+	if len(third.calls) != 0 {
+		t.Fatalf("unchanged rebuild called the embedder: %v", third.calls)
+	}
+
+	// A different model never reuses vectors.
+	other := &countingEmbedder{}
+	if _, _, err := embedConceptDocsIncremental(ctx, nil, cache, "openai:other", docs, other.embed); err != nil {
+		t.Fatalf("other model: %v", err)
+	}
+	if other.embedded() != 3 {
+		t.Fatalf("model switch should re-embed everything, got %d", other.embedded())
+	}
+}
+
+func TestEmbedConceptDocsIncrementalDedupesAndSurvivesCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	cache := &memConceptDocEmbeddingRepo{rows: map[string]*types.ConceptDocEmbedding{}, readErr: errors.New("db down")}
+	docs := []string{"same", "same", "other"}
+
+	e := &countingEmbedder{}
+	embs, _, err := embedConceptDocsIncremental(ctx, nil, cache, "openai:test", docs, e.embed)
+	if err != nil {
+		t.Fatalf("cache read error should not fail the build: %v", err)
+	}
+	if e.embedded() != 2 || len(embs) != 3 || embs[0][0] != embs[1][0] {
+		t.Fatalf("duplicate docs should embed once: calls=%v embs=%v", e.calls, embs)
+	}
+}
@@ -16,7 +16,6 @@ import (
 	"unicode"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
@@ -25,6 +24,8 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/errclass"
+	"github.com/yungbote/neurobridge-backend/internal/platform/httpx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/neo4jdb"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
@@ -46,6 +47,8 @@ type ConceptGraphBuildDeps struct {
 	Overrides repos.ConceptMappingOverrideRepo
 	Evidence  repos.ConceptEvidenceRepo
 	Edges     repos.ConceptEdgeRepo
+	// DocEmbeddings is optional; when set, unchanged concept docs reuse their stored vectors.
+	DocEmbeddings repos.ConceptDocEmbeddingRepo
 
 	Graph *neo4jdb.Client
 
@@ -54,6 +57,11 @@ type ConceptGraphBuildDeps struct {
 	Saga      services.SagaService
 	Bootstrap services.LearningBuildBootstrapService
 	Artifacts repos.LearningArtifactRepo
+
+	// PathNodes and NodeDocs are optional; when set, an incremental update marks the docs
+	// covering concepts it changed as concept_stale.
+	PathNodes repos.PathNodeRepo
+	NodeDocs  repos.LearningNodeDocRepo
 }
 
 type ConceptGraphBuildInput struct {
@@ -62,7 +70,10 @@ type ConceptGraphBuildInput struct {
 	SagaID        uuid.UUID
 	PathID        uuid.UUID
 	Mode          string
-	Report        func(stage string, pct int, message string)
+	// Force regenerates the graph even when the path already has one, replacing its concepts
+	// and their vectors.
+	Force  bool
+	Report func(stage string, pct int, message string)
 }
 
 type ConceptGraphBuildOutput struct {
@@ -71,6 +82,12 @@ type ConceptGraphBuildOutput struct {
 	EdgesMade       int            `json:"edges_made"`
 	PineconeBatches int            `json:"pinecone_batches"`
 	Adaptive        map[string]any `json:"adaptive,omitempty"`
+	// Models is the model each LLM subtask was routed to (see conceptGraphModels).
+	Models map[string]string `json:"models,omitempty"`
+	// ConceptChanges are the existing concepts an incremental update changed, and
+	// DocsConceptStaled the node docs it marked concept_stale for them.
+	ConceptChanges    []ConceptGraphChange `json:"concept_changes,omitempty"`
+	DocsConceptStaled int                  `json:"docs_concept_stale,omitempty"`
 }
 
 func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in ConceptGraphBuildInput) (ConceptGraphBuildOutput, error) {
@@ -97,7 +114,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	reporter := newProgressReporter("concept_graph", in.Report, 2, 2*time.Second)
 	reporter.Update(2, "Preparing concept graph")
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("concept_graph_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "concept_graph_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -105,18 +122,21 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("concept_graph_build: adaptive params", "adaptive", adaptiveStageMeta("concept_graph_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("concept_graph_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "concept_graph_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("concept_graph_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "concept_graph_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 	mode := strings.TrimSpace(strings.ToLower(in.Mode))
 	fastMode := mode == "fast"
 	if mode == "" {
 		fastMode = envBool("CONCEPT_GRAPH_FAST_MODE", false)
 	}
-	if model := strings.TrimSpace(os.Getenv("CONCEPT_GRAPH_MODEL")); model != "" && deps.AI != nil {
-		deps.AI = openai.WithModel(deps.AI, model)
-	}
+	models := newConceptGraphModels(deps.AI)
+	out.Models = models.Names()
+	// One provider-wide ceiling for the whole build, whatever each stage's concurrency is.
+	llmLimit := newConceptGraphLLMLimiter()
+	models.limit(llmLimit)
+	deps.AI = limitLLMClient(deps.AI, llmLimit)
 
 	existing, err := deps.Concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
 	if err != nil {
@@ -126,6 +146,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 
 	// Optional: incorporate user intent/intake context (written by path_intake) to improve relevance and reduce noise.
 	intentMD := ""
+	pathLanguage := ""
 	var allowFiles map[uuid.UUID]bool
 	if deps.Path != nil {
 		if row, err := deps.Path.GetByID(dbctx.Context{Ctx: ctx}, pathID); err == nil && row != nil && len(row.Metadata) > 0 && string(row.Metadata) != "null" {
@@ -137,6 +158,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 					}
 				}
 				intentMD = strings.TrimSpace(stringFromAny(meta["intake_md"]))
+				pathLanguage = conceptLanguageFromPathMeta(meta)
 				allowFiles = intakeMaterialAllowlistFromPathMeta(meta)
 			}
 		}
@@ -271,6 +293,15 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	}
 	adaptiveParams["CONCEPT_GRAPH_EXCERPT_MAX_CHARS"] = map[string]any{"actual": excerptMaxChars, "ceiling": excerptMaxCharsCeiling}
 	adaptiveParams["CONCEPT_GRAPH_EXCERPT_MAX_LINES"] = map[string]any{"actual": excerptMaxLines, "ceiling": excerptMaxLinesCeiling}
+	excerptQuality := resolveExcerptQualityFilter()
+	if excerptQuality.Enabled {
+		qp := excerptQuality.Params()
+		qp["filtered"] = excerptQuality.CountFiltered(chunks)
+		adaptiveParams["CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER"] = qp
+		if deps.Log != nil {
+			deps.Log.Info("concept_graph_build: low-signal excerpt chunks filtered", "path_id", pathID.String(), "filtered", qp["filtered"])
+		}
+	}
 	excerpts, excerptChunkIDs := buildConceptGraphExcerpts(
 		chunks,
 		perFile,
@@ -346,6 +377,10 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			}
 		}
 	}
+	outputLanguage := conceptGraphOutputLanguage(pathLanguage, sigsForHash)
+	if outputLanguage != "" {
+		adaptiveParams["CONCEPT_GRAPH_LANGUAGE"] = map[string]any{"actual": outputLanguage}
+	}
 
 	var conceptInputHash string
 	if deps.Artifacts != nil && artifactCacheEnabled() {
@@ -362,6 +397,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			"signatures":  signaturesFingerprint(sigsForHash),
 			"allow_files": allowFileIDs,
 			"intent_md":   intentMD,
+			"language":    outputLanguage,
 			"mode":        mode,
 			"env":         envSnapshot([]string{"CONCEPT_GRAPH_"}, []string{"OPENAI_MODEL"}),
 		}
@@ -370,7 +406,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		}
 	}
 
-	if hasExisting {
+	if hasExisting && !in.Force {
 		if conceptInputHash != "" && deps.Artifacts != nil && artifactCacheEnabled() {
 			if _, hit, err := artifactCacheGet(ctx, deps.Artifacts, in.OwnerUserID, in.MaterialSetID, pathID, "concept_graph_build", conceptInputHash); err == nil && hit {
 				return out, nil
@@ -392,7 +428,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		// Canonical graph already exists. Skip regeneration to preserve stability.
 		if deps.Graph != nil {
 			if err := syncPathConceptGraphToNeo4j(ctx, deps, pathID); err != nil {
-				deps.Log.Warn("neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
+				deps.Log.WarnThrottled("concept_graph_build.neo4j_sync", time.Minute, "neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
 			}
 		}
 		return out, nil
@@ -478,6 +514,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		invErrs        []error
 	)
 
+	rawInv := newConceptInventoryRawLog()
 	var invMu sync.Mutex
 	gInv, gInvCtx := errgroup.WithContext(ctx)
 	gInv.SetLimit(invConc)
@@ -525,6 +562,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 					PathIntentMD:         intentMD,
 					CrossDocSectionsJSON: "",
 					SeedConceptKeysJSON:  seed,
+					OutputLanguage:       outputLanguage,
 				})
 				if err != nil {
 					return conceptCoverage{}, nil, err
@@ -539,17 +577,19 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				if retry != "" {
 					logMeta["retry"] = retry
 				}
-				timer := llmTimer(deps.Log, "concept_inventory", logMeta)
-				invObj, err := deps.AI.GenerateJSON(gInvCtx, invPrompt.System, invPrompt.User, invPrompt.SchemaName, invPrompt.Schema)
+				timer := llmTimer(ctx, deps.Log, "concept_inventory", logMeta)
+				var inv conceptInventoryOutput
+				err = openai.GenerateJSONValidated(gInvCtx, models.For(conceptGraphTaskInventory), invPrompt.System, invPrompt.User, invPrompt.SchemaName, invPrompt.Schema, &inv)
 				timer(err)
-				if err != nil {
-					return conceptCoverage{}, nil, err
+				if err == nil {
+					openai.ReportJSONUnusable(ctx, deps.Log, models.For(conceptGraphTaskInventory), invPrompt.SchemaName, inv.unusable(), logMeta)
 				}
-				cov := parseConceptCoverage(invObj)
-				concepts, err := parseConceptInventory(invObj)
+				rawInv.recordFile(f.ID, invPrompt, len(excerpt), retry, &inv, err)
 				if err != nil {
-					return cov, nil, err
+					return conceptCoverage{}, nil, err
 				}
+				cov := inv.coverage()
+				concepts := inv.items()
 				if len(concepts) == 0 {
 					return cov, nil, fmt.Errorf("concept_graph_build: file inventory returned 0 concepts")
 				}
@@ -557,7 +597,8 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			}
 
 			invCoverage, conceptsOut, err := buildInventory(ex, seedJSON, "")
-			if err != nil && isContextLengthExceeded(err) {
+			switch cls := errclass.Classify(err); {
+			case cls == errclass.ContextLength:
 				retryMax := invMaxTotal
 				if retryMax <= 0 {
 					retryMax = 20000
@@ -569,6 +610,8 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 						invCoverage, conceptsOut, err = buildInventory(ex, seedJSON, "shorter")
 					}
 				}
+			case cls.Retryable() && conceptGraphTransientBackoff(gInvCtx):
+				invCoverage, conceptsOut, err = buildInventory(ex, seedJSON, string(cls))
 			}
 			if err != nil {
 				if errors.Is(err, context.Canceled) && ctx.Err() != nil {
@@ -601,6 +644,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 
 			conceptsOut, _ = normalizeConceptInventory(conceptsOut, allowedChunkIDs)
 			conceptsOut, _ = dedupeConceptInventoryByKey(conceptsOut)
+			rawInv.recordFileNormalized(f.ID, conceptsOut)
 			if len(conceptsOut) == 0 {
 				invMu.Lock()
 				filesFailed++
@@ -726,6 +770,15 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		adaptiveParams["CONCEPT_GRAPH_INVENTORY_SLICE_EXCERPTS_PER_FILE"] = map[string]any{
 			"actual": slicePerFile,
 		}
+		// Default 0 keeps slices disjoint (current cost); overlap trades tokens for boundary recall.
+		sliceOverlapCeiling := envFloatAllowZero("CONCEPT_GRAPH_INVENTORY_SLICE_OVERLAP", 0)
+		sliceOverlap := clampInventorySliceOverlap(sliceOverlapCeiling)
+		adaptiveParams["CONCEPT_GRAPH_INVENTORY_SLICE_OVERLAP"] = map[string]any{
+			"actual":  sliceOverlap,
+			"ceiling": sliceOverlapCeiling,
+		}
+		excerptSampler := resolveExcerptSampler(sigByFile)
+		adaptiveParams["CONCEPT_GRAPH_EXCERPT_STRATEGY"] = excerptSampler.Params()
 		if sampleEstimate > 0 {
 			adaptiveParams["CONCEPT_GRAPH_INVENTORY_SLICE_SAMPLE_ESTIMATE"] = map[string]any{
 				"actual": sampleEstimate,
@@ -760,6 +813,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 					PathIntentMD:         intentMD,
 					CrossDocSectionsJSON: crossDocSectionsJSON,
 					SeedConceptKeysJSON:  seed,
+					OutputLanguage:       outputLanguage,
 				})
 				if err != nil {
 					return globalInvResult{Err: err}
@@ -775,24 +829,26 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				if retry != "" {
 					logMeta["retry"] = retry
 				}
-				timer := llmTimer(deps.Log, "concept_inventory", logMeta)
-				invObj, err := deps.AI.GenerateJSON(invCtx, invPrompt.System, invPrompt.User, invPrompt.SchemaName, invPrompt.Schema)
+				timer := llmTimer(ctx, deps.Log, "concept_inventory", logMeta)
+				var inv conceptInventoryOutput
+				err = openai.GenerateJSONValidated(invCtx, models.For(conceptGraphTaskInventory), invPrompt.System, invPrompt.User, invPrompt.SchemaName, invPrompt.Schema, &inv)
 				timer(err)
-				if err != nil {
-					return globalInvResult{Err: err}
+				if err == nil {
+					openai.ReportJSONUnusable(ctx, deps.Log, models.For(conceptGraphTaskInventory), invPrompt.SchemaName, inv.unusable(), logMeta)
 				}
-				cov := parseConceptCoverage(invObj)
-				concepts, err := parseConceptInventory(invObj)
+				rawInv.recordGlobal(sliceIdx, invPrompt, len(ex), retry, &inv, err)
 				if err != nil {
-					return globalInvResult{Coverage: cov, Err: err}
+					return globalInvResult{Err: err}
 				}
+				cov := inv.coverage()
+				concepts := inv.items()
 				if len(concepts) == 0 {
 					return globalInvResult{Coverage: cov, Err: fmt.Errorf("concept_graph_build: global inventory returned 0 concepts")}
 				}
 				return globalInvResult{Coverage: cov, Concepts: concepts}
 			}
 
-			slices := buildInventorySlices(chunks, sliceTotal)
+			slices := buildInventorySlices(chunks, sliceTotal, sliceOverlap)
 			if len(slices) == 0 {
 				slices = []inventorySlice{{Index: 0, Chunks: chunks}}
 			}
@@ -806,24 +862,27 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				slice := slice
 				gSlices.Go(func() error {
 					fileOrder := sliceFileOrder(slice.Chunks, slice.Index)
-					ex, ids := buildConceptGraphExcerptsOrdered(slice.Chunks, slicePerFile, excerptMaxChars, excerptMaxLines, sliceMaxTotal, fileOrder)
+					ex, ids := buildConceptGraphExcerptsOrdered(slice.Chunks, slicePerFile, excerptMaxChars, excerptMaxLines, sliceMaxTotal, fileOrder, excerptSampler)
 					if strings.TrimSpace(ex) == "" {
 						return nil
 					}
 					res := runInventoryForExcerpts(gSlicesCtx, ex, slice.Index, "")
-					if res.Err != nil && isContextLengthExceeded(res.Err) {
+					switch cls := errclass.Classify(res.Err); {
+					case cls == errclass.ContextLength:
 						retryMax := sliceMaxTotal
 						if retryMax <= 0 {
 							retryMax = 20000
 						}
 						if retryMax > 12000 {
 							shorterMax := maxInt(12000, retryMax/2)
-							if shorter, shorterIDs := buildConceptGraphExcerptsOrdered(slice.Chunks, slicePerFile, excerptMaxChars, excerptMaxLines, shorterMax, fileOrder); strings.TrimSpace(shorter) != "" {
+							if shorter, shorterIDs := buildConceptGraphExcerptsOrdered(slice.Chunks, slicePerFile, excerptMaxChars, excerptMaxLines, shorterMax, fileOrder, excerptSampler); strings.TrimSpace(shorter) != "" {
 								ex = shorter
 								ids = shorterIDs
 								res = runInventoryForExcerpts(gSlicesCtx, ex, slice.Index, "shorter")
 							}
 						}
+					case cls.Retryable() && conceptGraphTransientBackoff(gSlicesCtx):
+						res = runInventoryForExcerpts(gSlicesCtx, ex, slice.Index, string(cls))
 					}
 					if res.Err != nil {
 						return res.Err
@@ -896,7 +955,28 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		guardrailMin := minConceptsGuardrail(signals)
 		weakAfter, _ := conceptInventoryWeak(globalCoverage, globalConcepts, setSeedMeta, signals, signals.ContentType, adaptiveEnabled)
 		if weakAfter && guardrailMin > 0 {
-			boostedCount := boostInventorySliceCount(sliceCount, sliceMax, len(globalConcepts), guardrailMin)
+			boostLimits := resolveInventoryBoostLimits()
+			boost := boostInventorySliceCount(sliceCount, sliceMax, len(globalConcepts), guardrailMin, boostLimits)
+			if boost.ClampedBy != "" {
+				deps.Log.Info(
+					"concept inventory slice boost clamped",
+					"slices", sliceCount,
+					"wanted", boost.Wanted,
+					"actual", boost.Count,
+					"clamped_by", boost.ClampedBy,
+					"max_factor", boostLimits.MaxFactor,
+					"max_slices", boostLimits.MaxSlices,
+					"slice_ceiling", sliceMax,
+				)
+				adaptiveParams["CONCEPT_GRAPH_INVENTORY_BOOST_CLAMP"] = map[string]any{
+					"wanted":     boost.Wanted,
+					"actual":     boost.Count,
+					"clamped_by": boost.ClampedBy,
+					"max_factor": boostLimits.MaxFactor,
+					"max_slices": boostLimits.MaxSlices,
+				}
+			}
+			boostedCount := boost.Count
 			if boostedCount > sliceCount {
 				boostSeed := sliceSeed
 				if boostedCount > 1 {
@@ -955,12 +1035,14 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	if len(conceptsOut) == 0 {
 		return out, fmt.Errorf("concept_graph_build: concept inventory returned 0 unique concepts")
 	}
+	rawInv.persist(ctx, deps.Artifacts, deps.Log, in.OwnerUserID, in.MaterialSetID, pathID, conceptsOut, allowedChunkIDs)
 
 	// ---- Coverage completion (iterative delta passes) ----
 	coverageInput := conceptCoverageInput{
 		PathID:             pathID,
 		MaterialSetID:      in.MaterialSetID,
 		IntentMD:           intentMD,
+		OutputLanguage:     outputLanguage,
 		Chunks:             chunks,
 		ChunkByID:          chunkByID,
 		ChunkEmbs:          chunkEmbs,
@@ -973,12 +1055,28 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		Signals:            signals,
 		Stage:              "concept_graph_build",
 	}
-	if outlineSeeds := outlineSeedTopics(files, sigByFile, signals); len(outlineSeeds) > 0 {
-		coverageInput.SeedTopics = outlineSeeds
-		adaptiveParams["CONCEPT_GRAPH_OUTLINE_SEED_TOPICS"] = map[string]any{
-			"actual": len(outlineSeeds),
+	// Auto-outlines of poorly structured materials seed noise; seeding can be turned off or
+	// limited to outlines extracted with enough confidence.
+	outlineSeedsEnabled := envBool("CONCEPT_GRAPH_OUTLINE_SEEDS_ENABLED", true)
+	outlineSeedMinConf := envFloatAllowZero("CONCEPT_GRAPH_OUTLINE_SEED_MIN_CONFIDENCE", 0)
+	outlineSeedParam := map[string]any{"enabled": outlineSeedsEnabled, "min_confidence": outlineSeedMinConf}
+	if outlineSeedsEnabled {
+		outlineSeeds, seedStats := outlineSeedTopics(files, sigByFile, signals, outlineSeedMinConf)
+		outlineSeedParam["actual"] = len(outlineSeeds)
+		outlineSeedParam["sources_used"] = seedStats.SourcesUsed
+		outlineSeedParam["sources_skipped_low_confidence"] = seedStats.SkippedLowConfidence
+		if len(outlineSeeds) > 0 {
+			coverageInput.SeedTopics = outlineSeeds
+		} else if seedStats.SkippedLowConfidence > 0 {
+			outlineSeedParam["skipped_reason"] = "low_confidence"
+		} else {
+			outlineSeedParam["skipped_reason"] = "no_outline"
 		}
+	} else {
+		outlineSeedParam["skipped_reason"] = "disabled"
 	}
+	outlineSeedParam["used"] = len(coverageInput.SeedTopics) > 0
+	adaptiveParams["CONCEPT_GRAPH_OUTLINE_SEED_TOPICS"] = outlineSeedParam
 	coverageInput.TargetedOnly = envBool("CONCEPT_GRAPH_COVERAGE_TARGETED_ONLY", true)
 	if fastMode {
 		fastPasses := envIntAllowZero("CONCEPT_GRAPH_FAST_COVERAGE_PASSES", 1)
@@ -1102,7 +1200,9 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	}
 	coverageInput.ProgressStart = coverageStart
 	coverageInput.ProgressEnd = coverageEnd
-	coverageResult := completeConceptCoverage(ctx, deps, coverageInput)
+	coverageDeps := deps
+	coverageDeps.AI = models.For(conceptGraphTaskInventory)
+	coverageResult := completeConceptCoverage(ctx, coverageDeps, coverageInput)
 	conceptsOut = coverageResult.Concepts
 	for k, v := range coverageResult.AdaptiveParams {
 		adaptiveParams[k] = v
@@ -1145,14 +1245,14 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				assumedCh <- res
 				return
 			}
-			timer := llmTimer(deps.Log, "assumed_knowledge", map[string]any{
+			timer := llmTimer(ctx, deps.Log, "assumed_knowledge", map[string]any{
 				"stage":         "concept_graph_build",
 				"path_id":       pathID.String(),
 				"concept_count": len(baseConcepts),
 				"excerpt_chars": len(excerpts),
 				"content_type":  signals.ContentType,
 			})
-			assumedObj, err := deps.AI.GenerateJSON(ctx, assumedPrompt.System, assumedPrompt.User, assumedPrompt.SchemaName, assumedPrompt.Schema)
+			assumedObj, err := models.For(conceptGraphTaskAssumed).GenerateJSON(ctx, assumedPrompt.System, assumedPrompt.User, assumedPrompt.SchemaName, assumedPrompt.Schema)
 			timer(err)
 			if err != nil {
 				res.Err = err
@@ -1258,21 +1358,18 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				alignCh <- res
 				return
 			}
-			timer := llmTimer(deps.Log, "concept_alignment", map[string]any{
+			timer := llmTimer(ctx, deps.Log, "concept_alignment", map[string]any{
 				"stage":         "concept_graph_build",
 				"path_id":       pathID.String(),
 				"pass":          "initial",
 				"concept_count": len(baseConcepts),
 				"has_sections":  strings.TrimSpace(crossDocSectionsJSON) != "",
 			})
-			alignObj, err := deps.AI.GenerateJSON(ctx, alignPrompt.System, alignPrompt.User, alignPrompt.SchemaName, alignPrompt.Schema)
+			err = openai.GenerateJSONValidated(ctx, models.For(conceptGraphTaskAlignment), alignPrompt.System, alignPrompt.User, alignPrompt.SchemaName, alignPrompt.Schema, &res.Alignment)
 			timer(err)
 			if err != nil {
 				res.Err = err
-				alignCh <- res
-				return
 			}
-			res.Alignment = parseConceptAlignment(alignObj)
 			alignCh <- res
 		}()
 	} else {
@@ -1303,9 +1400,16 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			CrossDocSectionsJSON: crossDocSectionsJSON,
 		})
 		if err == nil {
-			alignObj, err := deps.AI.GenerateJSON(ctx, alignPrompt.System, alignPrompt.User, alignPrompt.SchemaName, alignPrompt.Schema)
+			var alignment conceptAlignment
+			timer := llmTimer(ctx, deps.Log, "concept_alignment", map[string]any{
+				"stage":         "concept_graph_build",
+				"path_id":       pathID.String(),
+				"pass":          "post_assumed",
+				"concept_count": len(conceptsOut),
+			})
+			err := openai.GenerateJSONValidated(ctx, models.For(conceptGraphTaskAlignment), alignPrompt.System, alignPrompt.User, alignPrompt.SchemaName, alignPrompt.Schema, &alignment)
+			timer(err)
 			if err == nil {
-				alignment := parseConceptAlignment(alignObj)
 				if len(alignment.Aliases) > 0 || len(alignment.Splits) > 0 {
 					conceptsOut = applyConceptAlignment(conceptsOut, alignment, allowedChunkIDs, conceptMetaByKey)
 				}
@@ -1363,7 +1467,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	}
 
 	var (
-		edgesObj map[string]any
+		edgesRes conceptEdgesOutput
 		embs     [][]float32
 	)
 
@@ -1389,7 +1493,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				end = len(docs)
 			}
 			eg.Go(func() error {
-				timer := llmTimer(deps.Log, "concept_embeddings", map[string]any{
+				timer := llmTimer(ctx, deps.Log, "concept_embeddings", map[string]any{
 					"stage":       "concept_graph_build",
 					"path_id":     pathID.String(),
 					"batch_size":  end - start,
@@ -1422,25 +1526,29 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 
 	g, gctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
-		timer := llmTimer(deps.Log, "concept_edges", map[string]any{
+		logMeta := map[string]any{
 			"stage":         "concept_graph_build",
 			"path_id":       pathID.String(),
 			"concept_count": len(conceptsOut),
 			"excerpt_chars": len(edgeExcerpts),
-		})
-		obj, err := deps.AI.GenerateJSON(gctx, edgesPrompt.System, edgesPrompt.User, edgesPrompt.SchemaName, edgesPrompt.Schema)
+		}
+		timer := llmTimer(ctx, deps.Log, "concept_edges", logMeta)
+		err := openai.GenerateJSONValidated(gctx, models.For(conceptGraphTaskEdges), edgesPrompt.System, edgesPrompt.User, edgesPrompt.SchemaName, edgesPrompt.Schema, &edgesRes)
 		timer(err)
-		if err != nil {
-			return err
+		// A single concept has nothing to link, so an empty edge list is only unusable above that.
+		if err == nil && len(conceptsOut) > 1 {
+			openai.ReportJSONUnusable(ctx, deps.Log, models.For(conceptGraphTaskEdges), edgesPrompt.SchemaName, edgesRes.unusable(), logMeta)
 		}
-		edgesObj = obj
-		return nil
+		return err
 	})
 	g.Go(func() error {
-		v, err := embedBatched(gctx, conceptDocs)
+		v, stats, err := embedConceptDocsIncremental(gctx, deps.Log, deps.DocEmbeddings, conceptDocEmbedModel(), conceptDocs, embedBatched)
 		if err != nil {
 			return err
 		}
+		if deps.Log != nil {
+			deps.Log.Info("concept doc embeddings ready", "path_id", pathID.String(), "docs", stats.Docs, "reused", stats.Reused, "embedded", stats.Embedded)
+		}
 		embs = v
 		return nil
 	})
@@ -1449,7 +1557,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	}
 	reporter.Update(80, "Edges + embeddings ready")
 
-	edgesOut := parseConceptEdges(edgesObj)
+	edgesOut := edgesRes.Edges
 	if len(conceptMetaByKey) > 0 && len(conceptsOut) > 0 {
 		known := map[string]bool{}
 		for _, c := range conceptsOut {
@@ -1514,7 +1622,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	semanticProgress := func(done, total int) {
 		reporter.UpdateRange(done, total, semanticStart, semanticEnd, fmt.Sprintf("Matching canonical concepts %d/%d", done, total))
 	}
-	semanticMatchByKey, semanticParams := semanticMatchCanonicalConcepts(ctx, deps, conceptsOut, embs, signals, signals.ContentType, adaptiveEnabled, semanticProgress)
+	semanticMatchByKey, semanticParams := semanticMatchCanonicalConcepts(ctx, deps, conceptsOut, embs, signals, signals.ContentType, adaptiveEnabled, canonicalConceptMinSimilarity(), semanticProgress)
 	for k, v := range semanticParams {
 		adaptiveParams[k] = v
 	}
@@ -1564,6 +1672,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		pineconeBatchSize = 64
 	}
 	skipped := false
+	var retired retiredConceptVectors
 	reporter.Update(90, "Persisting concept graph")
 	txErr := deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		dbc := dbctx.Context{Ctx: ctx, Tx: tx}
@@ -1586,8 +1695,14 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			return err
 		}
 		if len(existing) > 0 {
-			skipped = true
-			return nil
+			if !in.Force {
+				skipped = true
+				return nil
+			}
+			retired, err = retireConceptGraphForRebuild(dbc, deps, in.SagaID, ns, existing, pineconeBatchSize)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Create concepts (canonical).
@@ -1658,27 +1773,15 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 
 		// Append vector-store compensations for all concept vectors (if configured).
 		if deps.Vec != nil {
-			for start := 0; start < len(rows); start += pineconeBatchSize {
-				end := start + pineconeBatchSize
-				if end > len(rows) {
-					end = len(rows)
-				}
-				ids := make([]string, 0, end-start)
-				for _, r := range rows[start:end] {
-					if r.Row != nil {
-						ids = append(ids, r.Row.VectorID)
-					}
-				}
-				if len(ids) == 0 {
-					continue
-				}
-				if err := deps.Saga.AppendAction(dbc, in.SagaID, services.SagaActionKindVectorDeleteIDs, map[string]any{
-					"namespace": ns,
-					"ids":       ids,
-				}); err != nil {
-					return err
+			ids := make([]string, 0, len(rows))
+			for _, r := range rows {
+				if r.Row != nil {
+					ids = append(ids, r.Row.VectorID)
 				}
 			}
+			if err := appendVectorDeleteCompensations(dbc, deps.Saga, in.SagaID, ns, ids, pineconeBatchSize); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -1686,12 +1789,12 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	if txErr != nil {
 		// If another worker won the race (or older installs have a mismatched unique index),
 		// treat as a no-op as long as a canonical graph exists after the error.
-		if isUniqueViolation(txErr, "") {
+		if errclass.Classify(txErr) == errclass.UniqueViolation {
 			existingAfter, err := deps.Concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
 			if err == nil && len(existingAfter) > 0 {
 				if deps.Graph != nil {
 					if err := syncPathConceptGraphToNeo4j(ctx, deps, pathID); err != nil {
-						deps.Log.Warn("neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
+						deps.Log.WarnThrottled("concept_graph_build.neo4j_sync", time.Minute, "neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
 					}
 				}
 				deps.Log.Warn("concept graph insert hit unique violation; graph already exists; skipping", "path_id", pathID.String())
@@ -1748,7 +1851,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				if err == nil && len(existingAfterRestore) > 0 {
 					if deps.Graph != nil {
 						if err := syncPathConceptGraphToNeo4j(ctx, deps, pathID); err != nil {
-							deps.Log.Warn("neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
+							deps.Log.WarnThrottled("concept_graph_build.neo4j_sync", time.Minute, "neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
 						}
 					}
 					deps.Log.Warn("concept graph restored after unique violation; continuing", "path_id", pathID.String())
@@ -1764,7 +1867,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 	if skipped {
 		if deps.Graph != nil {
 			if err := syncPathConceptGraphToNeo4j(ctx, deps, pathID); err != nil {
-				deps.Log.Warn("neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
+				deps.Log.WarnThrottled("concept_graph_build.neo4j_sync", time.Minute, "neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
 			}
 		}
 		return out, nil
@@ -1842,7 +1945,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 			}
 			g.Go(func() error {
 				if err := deps.Vec.Upsert(gctx, ns, pv); err != nil {
-					deps.Log.Warn("pinecone upsert failed (continuing)", "namespace", ns, "err", err.Error())
+					deps.Log.WarnThrottled("concept_graph_build.pinecone_upsert", time.Minute, "pinecone upsert failed (continuing)", "namespace", ns, "err", err.Error())
 					return nil
 				}
 				done := int(atomic.AddInt32(&batches, 1))
@@ -1858,48 +1961,30 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 		// We index by canonical concept ID (vector_id = "concept:<canonical_uuid>") into the global namespace,
 		// which allows new paths to semantically match previously-learned concepts even when their keys differ.
 		globalNS := index.ConceptsNamespace("global", nil)
-		globalVectors := make([]pc.Vector, 0, len(rows))
-		seenGlobal := map[string]bool{}
+		globalConcepts := make([]*types.Concept, 0, len(rows))
+		globalEmbs := make([][]float32, 0, len(rows))
 		for _, r := range rows {
-			if r.Row == nil || len(r.Emb) == 0 || r.Row.CanonicalConceptID == nil || *r.Row.CanonicalConceptID == uuid.Nil {
-				continue
-			}
-			cid := *r.Row.CanonicalConceptID
-			vid := "concept:" + cid.String()
-			if seenGlobal[vid] {
-				continue
-			}
-			seenGlobal[vid] = true
-			globalVectors = append(globalVectors, pc.Vector{
-				ID:     vid,
-				Values: r.Emb,
-				Metadata: map[string]any{
-					"type":        "concept",
-					"scope":       "global",
-					"canonical":   true,
-					"concept_id":  cid.String(),
-					"observedKey": r.Row.Key,
-					"observedName": func() string {
-						if strings.TrimSpace(r.Row.Name) != "" {
-							return r.Row.Name
-						}
-						return r.Row.Key
-					}(),
-				},
-			})
+			globalConcepts = append(globalConcepts, r.Row)
+			globalEmbs = append(globalEmbs, r.Emb)
 		}
+		globalVectors := canonicalConceptVectors(globalConcepts, globalEmbs)
 		if len(globalVectors) > 0 {
 			if err := deps.Vec.Upsert(ctx, globalNS, globalVectors); err != nil {
-				deps.Log.Warn("pinecone global concept upsert failed (continuing)", "namespace", globalNS, "err", err.Error())
+				deps.Log.WarnThrottled("concept_graph_build.pinecone_global_upsert", time.Minute, "pinecone global concept upsert failed (continuing)", "namespace", globalNS, "err", err.Error())
 			}
 		}
 		reporter.Update(pineconeEnd, fmt.Sprintf("Indexed concepts (%d batches)", out.PineconeBatches))
+
+		// Forced rebuild: the replaced vectors are removed only after their successors are written.
+		if err := deleteRetiredConceptVectors(ctx, deps.Vec, retired, pineconeBatchSize); err != nil {
+			deps.Log.WarnThrottled("concept_graph_build.pinecone_delete", time.Minute, "pinecone delete of replaced concept vectors failed (continuing)", "namespace", ns, "count", len(retired.VectorIDs), "err", err.Error())
+		}
 	}
 
 	// ---- Upsert to Neo4j (best-effort; cache only) ----
 	if deps.Graph != nil {
 		if err := syncPathConceptGraphToNeo4j(ctx, deps, pathID); err != nil {
-			deps.Log.Warn("neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
+			deps.Log.WarnThrottled("concept_graph_build.neo4j_sync", time.Minute, "neo4j concept graph sync failed (continuing)", "error", err, "path_id", pathID.String())
 		}
 	}
 	reporter.Update(98, "Concept graph ready")
@@ -1916,6 +2001,7 @@ func ConceptGraphBuild(ctx context.Context, deps ConceptGraphBuildDeps, in Conce
 				"concepts_made":    out.ConceptsMade,
 				"edges_made":       out.EdgesMade,
 				"pinecone_batches": out.PineconeBatches,
+				"models":           out.Models,
 			}),
 		})
 	}
@@ -2296,29 +2382,23 @@ func advisoryKey64(namespace string, id uuid.UUID) int64 {
 	return int64(h.Sum64())
 }
 
-func isUniqueViolation(err error, constraint string) bool {
-	if err == nil {
+// conceptGraphTransientBackoff waits before the one retry of an inventory call that failed with
+// a transient error (timeouts, rate limits). CONCEPT_GRAPH_TRANSIENT_RETRY_BACKOFF_MS sets the
+// wait (default 2000, jittered); 0 disables the retry. It reports false when the retry should be
+// skipped, including when ctx ends while waiting.
+func conceptGraphTransientBackoff(ctx context.Context) bool {
+	ms := envIntAllowZero("CONCEPT_GRAPH_TRANSIENT_RETRY_BACKOFF_MS", 2000)
+	if ms <= 0 {
 		return false
 	}
-	var pgErr *pgconn.PgError
-	if errors.As(err, &pgErr) {
-		if pgErr.Code == "23505" {
-			if strings.TrimSpace(constraint) == "" {
-				return true
-			}
-			return strings.EqualFold(strings.TrimSpace(pgErr.ConstraintName), strings.TrimSpace(constraint))
-		}
-	}
-
-	// Fallback: string match (covers wrapped errors that lose type info).
-	msg := strings.ToLower(err.Error())
-	if strings.Contains(msg, "sqlstate 23505") {
-		if strings.TrimSpace(constraint) == "" {
-			return true
-		}
-		return strings.Contains(msg, strings.ToLower(strings.TrimSpace(constraint)))
+	t := time.NewTimer(httpx.JitterSleep(time.Duration(ms) * time.Millisecond))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
 	}
-	return false
 }
 
 type conceptInvItem struct {
@@ -2342,6 +2422,86 @@ type conceptEdgeItem struct {
 	Citations []string `json:"citations"`
 }
 
+// conceptInventoryOutput is the concept_inventory response. GenerateJSONValidated guarantees
+// its shape; items/coverage only drop empty entries and tidy strings.
+type conceptInventoryOutput struct {
+	Version  int              `json:"version"`
+	Warnings []string         `json:"warnings"`
+	Concepts []conceptInvItem `json:"concepts"`
+	Coverage conceptCoverage  `json:"coverage"`
+}
+
+func (o conceptInventoryOutput) items() []conceptInvItem {
+	out := make([]conceptInvItem, 0, len(o.Concepts))
+	for _, c := range o.Concepts {
+		c.Key = strings.TrimSpace(c.Key)
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Key == "" || c.Name == "" {
+			continue
+		}
+		c.ParentKey = strings.TrimSpace(c.ParentKey)
+		c.Summary = strings.TrimSpace(c.Summary)
+		c.KeyPoints = dedupeStrings(c.KeyPoints)
+		c.Aliases = dedupeStrings(c.Aliases)
+		c.Citations = dedupeStrings(c.Citations)
+		out = append(out, c)
+	}
+	return out
+}
+
+func (o conceptInventoryOutput) coverage() conceptCoverage {
+	cov := o.Coverage
+	cov.Notes = strings.TrimSpace(cov.Notes)
+	cov.MissingTopics = dedupeStrings(cov.MissingTopics)
+	return cov
+}
+
+// unusable lists what the schema let through but items() cannot use: no concepts at all, or
+// concepts dropped for a blank key or name.
+func (o conceptInventoryOutput) unusable() []openai.JSONUnusable {
+	if len(o.Concepts) == 0 {
+		return []openai.JSONUnusable{{Kind: openai.JSONUnusableEmptyArray, Field: "concepts"}}
+	}
+	noKey, noName := 0, 0
+	for _, c := range o.Concepts {
+		if strings.TrimSpace(c.Key) == "" {
+			noKey++
+		} else if strings.TrimSpace(c.Name) == "" {
+			noName++
+		}
+	}
+	var out []openai.JSONUnusable
+	if noKey > 0 {
+		out = append(out, openai.JSONUnusable{Kind: openai.JSONUnusableMissingKey, Field: "concepts.key", Count: noKey})
+	}
+	if noName > 0 {
+		out = append(out, openai.JSONUnusable{Kind: openai.JSONUnusableMissingKey, Field: "concepts.name", Count: noName})
+	}
+	return out
+}
+
+// conceptEdgesOutput is the concept_edges response; normalizeConceptEdges does the cleanup.
+type conceptEdgesOutput struct {
+	Edges []conceptEdgeItem `json:"edges"`
+}
+
+// unusable reports an empty edge list and edges missing an endpoint key.
+func (o conceptEdgesOutput) unusable() []openai.JSONUnusable {
+	if len(o.Edges) == 0 {
+		return []openai.JSONUnusable{{Kind: openai.JSONUnusableEmptyArray, Field: "edges"}}
+	}
+	missing := 0
+	for _, e := range o.Edges {
+		if strings.TrimSpace(e.FromKey) == "" || strings.TrimSpace(e.ToKey) == "" {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+	return []openai.JSONUnusable{{Kind: openai.JSONUnusableMissingKey, Field: "edges.from_key/to_key", Count: missing}}
+}
+
 type conceptSeedMeta struct {
 	TotalFiles     int            `json:"total_files"`
 	FilesWithSeeds int            `json:"files_with_seeds"`
@@ -2531,7 +2691,10 @@ type inventorySlice struct {
 	Chunks []*types.MaterialChunk
 }
 
-func buildInventorySlices(chunks []*types.MaterialChunk, sliceCount int) []inventorySlice {
+// buildInventorySlices deals chunks round-robin (in file/index order) into sliceCount slices.
+// With overlap > 0, each slice also takes that fraction of the chunks owned by the next slice,
+// evenly spaced, so neighbouring chunks of a concept straddling slices land together.
+func buildInventorySlices(chunks []*types.MaterialChunk, sliceCount int, overlap float64) []inventorySlice {
 	if sliceCount <= 1 {
 		return []inventorySlice{{Index: 0, Chunks: chunks}}
 	}
@@ -2550,9 +2713,19 @@ func buildInventorySlices(chunks []*types.MaterialChunk, sliceCount int) []inven
 		}
 		return ai.Index < aj.Index
 	})
+	overlap = clampInventorySliceOverlap(overlap)
 	slices := make([][]*types.MaterialChunk, sliceCount)
 	for i, ch := range sorted {
-		slices[i%sliceCount] = append(slices[i%sliceCount], ch)
+		owner := i % sliceCount
+		slices[owner] = append(slices[owner], ch)
+		if overlap > 0 {
+			// j-th chunk of its owner; share it whenever the running overlap crosses an integer.
+			j := float64(i / sliceCount)
+			if math.Ceil((j+1)*overlap) > math.Ceil(j*overlap) {
+				prev := (owner - 1 + sliceCount) % sliceCount
+				slices[prev] = append(slices[prev], ch)
+			}
+		}
 	}
 	out := make([]inventorySlice, 0, sliceCount)
 	for i := 0; i < sliceCount; i++ {
@@ -2564,6 +2737,17 @@ func buildInventorySlices(chunks []*types.MaterialChunk, sliceCount int) []inven
 	return out
 }
 
+// Past half, slices mostly duplicate each other and the extra LLM cost buys little recall.
+func clampInventorySliceOverlap(v float64) float64 {
+	if math.IsNaN(v) || v <= 0 {
+		return 0
+	}
+	if v > 0.5 {
+		return 0.5
+	}
+	return v
+}
+
 func computeInventorySliceCount(signals AdaptiveSignals, baseSampleCount int, sliceMax int) int {
 	if baseSampleCount <= 0 || signals.ChunkCount <= 0 {
 		return 1
@@ -2806,11 +2990,12 @@ func sliceFileOrder(chunks []*types.MaterialChunk, offset int) []uuid.UUID {
 	return out
 }
 
-func buildConceptGraphExcerptsOrdered(chunks []*types.MaterialChunk, perFile int, maxChars int, maxLines int, maxTotalChars int, fileOrder []uuid.UUID) (string, []uuid.UUID) {
+func buildConceptGraphExcerptsOrdered(chunks []*types.MaterialChunk, perFile int, maxChars int, maxLines int, maxTotalChars int, fileOrder []uuid.UUID, sampler excerptSampler) (string, []uuid.UUID) {
 	useAll := perFile <= 0
 	if maxChars <= 0 {
 		maxChars = 700
 	}
+	quality := resolveExcerptQualityFilter()
 	byFile := map[uuid.UUID][]*types.MaterialChunk{}
 	for _, ch := range chunks {
 		if ch == nil || ch.MaterialFileID == uuid.Nil {
@@ -2822,6 +3007,9 @@ func buildConceptGraphExcerptsOrdered(chunks []*types.MaterialChunk, perFile int
 		if strings.TrimSpace(ch.Text) == "" {
 			continue
 		}
+		if quality.LowSignal(ch) {
+			continue
+		}
 		byFile[ch.MaterialFileID] = append(byFile[ch.MaterialFileID], ch)
 	}
 	if len(byFile) == 0 {
@@ -2882,16 +3070,7 @@ outer:
 			break
 		}
 
-		step := float64(n) / float64(k)
-		for i := 0; i < k; i++ {
-			idx := int(float64(i) * step)
-			if idx < 0 {
-				idx = 0
-			}
-			if idx >= n {
-				idx = n - 1
-			}
-			ch := arr[idx]
+		for _, ch := range sampler.pick(fid, arr, k) {
 			line := buildEnrichedChunkLine(ch, maxChars)
 			if line == "" {
 				continue
@@ -2915,13 +3094,23 @@ outer:
 	return strings.TrimSpace(b.String()), ids
 }
 
-func outlineSeedTopics(files []*types.MaterialFile, sigByFile map[uuid.UUID]*types.MaterialFileSignature, signals AdaptiveSignals) []string {
+// outlineSeedStats counts the outline sources (file signature outlines and extraction
+// diagnostics hints) that seeded topics, and those skipped for low confidence.
+type outlineSeedStats struct {
+	SourcesUsed          int
+	SkippedLowConfidence int
+}
+
+// outlineSeedTopics collects coverage seed topics from file outlines. With minConfidence > 0,
+// outlines whose extraction confidence is below it (or unknown) are ignored.
+func outlineSeedTopics(files []*types.MaterialFile, sigByFile map[uuid.UUID]*types.MaterialFileSignature, signals AdaptiveSignals, minConfidence float64) ([]string, outlineSeedStats) {
+	stats := outlineSeedStats{}
 	if len(files) == 0 {
-		return nil
+		return nil, stats
 	}
 	limit := outlineSeedTopicLimit(signals)
 	if limit <= 0 {
-		return nil
+		return nil, stats
 	}
 	out := make([]string, 0, limit)
 	seen := map[string]bool{}
@@ -2940,6 +3129,28 @@ func outlineSeedTopics(files []*types.MaterialFile, sigByFile map[uuid.UUID]*typ
 		seen[key] = true
 		out = append(out, clean)
 	}
+	// addOutline seeds from one outline source; it reports whether the limit was reached.
+	addOutline := func(outline map[string]any, confidence float64) bool {
+		sections := flattenOutlineSections(outline, limit)
+		if len(sections) == 0 {
+			return false
+		}
+		if minConfidence > 0 && confidence < minConfidence {
+			stats.SkippedLowConfidence++
+			return false
+		}
+		stats.SourcesUsed++
+		for _, sec := range sections {
+			if sec == nil {
+				continue
+			}
+			add(sec.Title)
+			if len(out) >= limit {
+				return true
+			}
+		}
+		return false
+	}
 
 	for _, f := range files {
 		if f == nil || f.ID == uuid.Nil {
@@ -2947,41 +3158,21 @@ func outlineSeedTopics(files []*types.MaterialFile, sigByFile map[uuid.UUID]*typ
 		}
 		if sig := sigByFile[f.ID]; sig != nil && len(sig.OutlineJSON) > 0 && string(sig.OutlineJSON) != "null" {
 			var outline map[string]any
-			if err := json.Unmarshal(sig.OutlineJSON, &outline); err == nil {
-				sections := flattenOutlineSections(outline, limit)
-				for _, sec := range sections {
-					if sec == nil {
-						continue
-					}
-					add(sec.Title)
-					if len(out) >= limit {
-						return out
-					}
+			if err := json.Unmarshal(sig.OutlineJSON, &outline); err == nil && outline != nil {
+				if addOutline(outline, sig.OutlineConfidence) {
+					return out, stats
 				}
 			}
 		}
-		if len(out) >= limit {
-			return out
-		}
 		if len(f.ExtractionDiagnostics) > 0 && string(f.ExtractionDiagnostics) != "null" {
 			if hint := outlineHintFromDiagnostics(f.ExtractionDiagnostics, limit); hint != nil {
-				sections := flattenOutlineSections(hint, limit)
-				for _, sec := range sections {
-					if sec == nil {
-						continue
-					}
-					add(sec.Title)
-					if len(out) >= limit {
-						return out
-					}
+				if addOutline(hint, floatFromAny(hint["confidence"], 0)) {
+					return out, stats
 				}
 			}
 		}
-		if len(out) >= limit {
-			return out
-		}
 	}
-	return out
+	return out, stats
 }
 
 func outlineSeedTopicLimit(signals AdaptiveSignals) int {
@@ -3051,34 +3242,77 @@ func acceptOutlineTitle(title string) bool {
 	return false
 }
 
-func boostInventorySliceCount(base int, ceiling int, currentConcepts int, guardrail int) int {
+const defaultInventoryBoostMaxFactor = 3
+
+// inventoryBoostLimits bound the guardrail-driven inventory re-run. MaxFactor caps the slice
+// multiplier (CONCEPT_GRAPH_INVENTORY_BOOST_MAX_FACTOR, default 3; 1 or less disables the
+// boost). MaxSlices caps the boosted slice count outright (CONCEPT_GRAPH_INVENTORY_BOOST_MAX_SLICES,
+// default 0 = only the regular slice ceiling applies).
+type inventoryBoostLimits struct {
+	MaxFactor int
+	MaxSlices int
+}
+
+func resolveInventoryBoostLimits() inventoryBoostLimits {
+	limits := inventoryBoostLimits{
+		MaxFactor: envIntAllowZero("CONCEPT_GRAPH_INVENTORY_BOOST_MAX_FACTOR", defaultInventoryBoostMaxFactor),
+		MaxSlices: envIntAllowZero("CONCEPT_GRAPH_INVENTORY_BOOST_MAX_SLICES", 0),
+	}
+	if limits.MaxFactor < 1 {
+		limits.MaxFactor = 1
+	}
+	if limits.MaxSlices < 0 {
+		limits.MaxSlices = 0
+	}
+	return limits
+}
+
+// inventoryBoost is a boosted slice count. Wanted is what the uncapped factor asked for and
+// ClampedBy names the last limit that cut it ("max_factor", "slice_ceiling", "max_slices"), or
+// "" when none did.
+type inventoryBoost struct {
+	Count     int
+	Wanted    int
+	ClampedBy string
+}
+
+func boostInventorySliceCount(base int, ceiling int, currentConcepts int, guardrail int, limits inventoryBoostLimits) inventoryBoost {
 	if base < 1 {
 		base = 1
 	}
+	out := inventoryBoost{Count: base, Wanted: base}
 	if guardrail < 1 {
-		return base
+		return out
 	}
 	if currentConcepts < 1 {
 		currentConcepts = 1
 	}
 	if currentConcepts >= guardrail {
-		return base
+		return out
 	}
 	boostFactor := int(math.Ceil(float64(guardrail) / float64(currentConcepts)))
 	if boostFactor < 2 {
-		return base
+		return out
 	}
-	if boostFactor > 3 {
-		boostFactor = 3
+	out.Wanted = base * boostFactor
+	if limits.MaxFactor > 0 && boostFactor > limits.MaxFactor {
+		boostFactor = limits.MaxFactor
+		out.ClampedBy = "max_factor"
 	}
 	boosted := base * boostFactor
 	if ceiling > 0 && boosted > ceiling {
 		boosted = ceiling
+		out.ClampedBy = "slice_ceiling"
+	}
+	if limits.MaxSlices > 0 && boosted > limits.MaxSlices {
+		boosted = limits.MaxSlices
+		out.ClampedBy = "max_slices"
 	}
 	if boosted < base {
 		boosted = base
 	}
-	return boosted
+	out.Count = boosted
+	return out
 }
 
 func appendUniqueChunkIDs(base []uuid.UUID, add []uuid.UUID) []uuid.UUID {
@@ -3101,42 +3335,6 @@ func appendUniqueChunkIDs(base []uuid.UUID, add []uuid.UUID) []uuid.UUID {
 	return base
 }
 
-func parseConceptInventory(obj map[string]any) ([]conceptInvItem, error) {
-	raw, ok := obj["concepts"]
-	if !ok || raw == nil {
-		return nil, fmt.Errorf("concept_inventory: missing concepts")
-	}
-	arr, ok := raw.([]any)
-	if !ok {
-		return nil, fmt.Errorf("concept_inventory: concepts not array")
-	}
-	out := make([]conceptInvItem, 0, len(arr))
-	for _, x := range arr {
-		m, ok := x.(map[string]any)
-		if !ok {
-			continue
-		}
-		key := strings.TrimSpace(stringFromAny(m["key"]))
-		name := strings.TrimSpace(stringFromAny(m["name"]))
-		if key == "" || name == "" {
-			continue
-		}
-		parentKey := strings.TrimSpace(stringFromAny(m["parent_key"]))
-		out = append(out, conceptInvItem{
-			Key:        key,
-			Name:       name,
-			ParentKey:  parentKey,
-			Depth:      intFromAny(m["depth"], 0),
-			Summary:    strings.TrimSpace(stringFromAny(m["summary"])),
-			KeyPoints:  dedupeStrings(stringSliceFromAny(m["key_points"])),
-			Aliases:    dedupeStrings(stringSliceFromAny(m["aliases"])),
-			Importance: intFromAny(m["importance"], 0),
-			Citations:  dedupeStrings(stringSliceFromAny(m["citations"])),
-		})
-	}
-	return out, nil
-}
-
 func dedupeConceptInventoryByKey(in []conceptInvItem) ([]conceptInvItem, int) {
 	if len(in) == 0 {
 		return nil, 0
@@ -3196,36 +3394,3 @@ func dedupeConceptInventoryByKey(in []conceptInvItem) ([]conceptInvItem, int) {
 	}
 	return out, dups
 }
-
-func parseConceptEdges(obj map[string]any) []conceptEdgeItem {
-	raw, ok := obj["edges"]
-	if !ok || raw == nil {
-		return nil
-	}
-	arr, ok := raw.([]any)
-	if !ok {
-		return nil
-	}
-	out := make([]conceptEdgeItem, 0, len(arr))
-	for _, x := range arr {
-		m, ok := x.(map[string]any)
-		if !ok {
-			continue
-		}
-		fk := strings.TrimSpace(stringFromAny(m["from_key"]))
-		tk := strings.TrimSpace(stringFromAny(m["to_key"]))
-		et := strings.TrimSpace(stringFromAny(m["edge_type"]))
-		if fk == "" || tk == "" || et == "" {
-			continue
-		}
-		out = append(out, conceptEdgeItem{
-			FromKey:   fk,
-			ToKey:     tk,
-			EdgeType:  et,
-			Strength:  floatFromAny(m["strength"], 1),
-			Rationale: strings.TrimSpace(stringFromAny(m["rationale"])),
-			Citations: dedupeStrings(stringSliceFromAny(m["citations"])),
-		})
-	}
-	return out
-}
@@ -0,0 +1,253 @@
+package steps
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// ConceptGraphChange is an existing concept an incremental concept graph update changed.
+type ConceptGraphChange struct {
+	ConceptID uuid.UUID `json:"concept_id"`
+	Key       string    `json:"key"`
+	Name      string    `json:"name,omitempty"`
+	Kinds     []string  `json:"kinds"`
+}
+
+// conceptGraphChangeUpdate is a detected change plus the concept columns it persists.
+type conceptGraphChangeUpdate struct {
+	ConceptGraphChange
+	Updates map[string]any
+}
+
+// conceptGraphPatchSummaryMinSimilarity is the token overlap (Jaccard, case and punctuation
+// ignored) below which a rewritten summary counts as a material change. The same bar decides
+// whether an incoming key point is new or a rewording of one the concept already has.
+func conceptGraphPatchSummaryMinSimilarity() float64 {
+	v := envFloatAllowZero("CONCEPT_GRAPH_PATCH_SUMMARY_MIN_SIMILARITY", 0.6)
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// detectConceptContentChanges compares the deduped inventory against the stored concepts. An
+// existing concept changed when:
+//   - summary_changed: its summary was replaced by one sharing less than minSim of its tokens
+//     (an empty stored summary being filled in does not count);
+//   - merged: the inventory folded new key points or aliases into it. Key points that reword
+//     one already stored (similarity >= minSim) and aliases equal to its key or name are ignored.
+//
+// The returned updates carry only the new summary, the grown key points and the grown aliases.
+func detectConceptContentChanges(existingByKey map[string]*types.Concept, concepts []conceptInvItem, minSim float64) []conceptGraphChangeUpdate {
+	out := make([]conceptGraphChangeUpdate, 0)
+	for _, c := range concepts {
+		row := existingByKey[strings.TrimSpace(c.Key)]
+		if row == nil || row.ID == uuid.Nil {
+			continue
+		}
+		ch := conceptGraphChangeUpdate{
+			ConceptGraphChange: ConceptGraphChange{ConceptID: row.ID, Key: strings.TrimSpace(row.Key), Name: strings.TrimSpace(row.Name)},
+			Updates:            map[string]any{},
+		}
+
+		oldSummary, newSummary := strings.TrimSpace(row.Summary), strings.TrimSpace(c.Summary)
+		if oldSummary != "" && newSummary != "" && oldSummary != newSummary &&
+			jaccard(tokenSetForMatch(oldSummary), tokenSetForMatch(newSummary)) < minSim {
+			ch.Kinds = append(ch.Kinds, content.ConceptChangeSummary)
+			ch.Updates["summary"] = newSummary
+		}
+
+		var keyPoints []string
+		if len(row.KeyPoints) > 0 {
+			_ = json.Unmarshal(row.KeyPoints, &keyPoints)
+		}
+		known := make([]map[string]bool, 0, len(keyPoints))
+		for _, kp := range keyPoints {
+			known = append(known, tokenSetForMatch(kp))
+		}
+		grewPoints := false
+		for _, kp := range c.KeyPoints {
+			kp = strings.TrimSpace(kp)
+			toks := tokenSetForMatch(kp)
+			if len(toks) == 0 {
+				continue
+			}
+			dup := false
+			for _, k := range known {
+				if jaccard(k, toks) >= minSim {
+					dup = true
+					break
+				}
+			}
+			if dup {
+				continue
+			}
+			keyPoints = append(keyPoints, kp)
+			known = append(known, toks)
+			grewPoints = true
+		}
+
+		meta := map[string]any{}
+		if len(row.Metadata) > 0 && string(row.Metadata) != "null" {
+			_ = json.Unmarshal(row.Metadata, &meta)
+			if meta == nil {
+				meta = map[string]any{}
+			}
+		}
+		aliases := dedupeStrings(stringSliceFromAny(meta["aliases"]))
+		seenAlias := map[string]bool{
+			strings.ToLower(strings.TrimSpace(row.Key)):  true,
+			strings.ToLower(strings.TrimSpace(row.Name)): true,
+		}
+		for _, a := range aliases {
+			seenAlias[strings.ToLower(a)] = true
+		}
+		grewAliases := false
+		for _, a := range c.Aliases {
+			a = strings.TrimSpace(a)
+			if a == "" || seenAlias[strings.ToLower(a)] {
+				continue
+			}
+			seenAlias[strings.ToLower(a)] = true
+			aliases = append(aliases, a)
+			grewAliases = true
+		}
+
+		if grewPoints || grewAliases {
+			ch.Kinds = append(ch.Kinds, content.ConceptChangeMerged)
+		}
+		if grewPoints {
+			ch.Updates["key_points"] = datatypes.JSON(mustJSON(keyPoints))
+		}
+		if grewAliases {
+			meta["aliases"] = aliases
+			ch.Updates["metadata"] = datatypes.JSON(mustJSON(meta))
+		}
+		if len(ch.Kinds) > 0 {
+			out = append(out, ch)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// withConceptPrereqChanges adds prereq_added to every existing concept the update gave a
+// prerequisite it did not have. existingPrereqs holds the stored prereq edges as "from|to" keys.
+func withConceptPrereqChanges(changes []conceptGraphChangeUpdate, existingByKey map[string]*types.Concept, edges []conceptEdgeItem, existingPrereqs map[string]bool) []conceptGraphChangeUpdate {
+	idx := map[string]int{}
+	for i, ch := range changes {
+		idx[ch.Key] = i
+	}
+	for _, e := range edges {
+		if !strings.EqualFold(strings.TrimSpace(e.EdgeType), "prereq") {
+			continue
+		}
+		from, to := strings.TrimSpace(e.FromKey), strings.TrimSpace(e.ToKey)
+		row := existingByKey[to]
+		if row == nil || row.ID == uuid.Nil || from == "" || from == to || existingPrereqs[from+"|"+to] {
+			continue
+		}
+		i, ok := idx[to]
+		if !ok {
+			changes = append(changes, conceptGraphChangeUpdate{
+				ConceptGraphChange: ConceptGraphChange{ConceptID: row.ID, Key: to, Name: strings.TrimSpace(row.Name)},
+				Updates:            map[string]any{},
+			})
+			i = len(changes) - 1
+			idx[to] = i
+		}
+		if !containsString(changes[i].Kinds, content.ConceptChangePrereqAdded) {
+			changes[i].Kinds = append(changes[i].Kinds, content.ConceptChangePrereqAdded)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// conceptPrereqKeyPairs returns the stored prereq edges as "from|to" concept key pairs.
+func conceptPrereqKeyPairs(edges []*types.ConceptEdge, idToKey map[uuid.UUID]string) map[string]bool {
+	out := map[string]bool{}
+	for _, e := range edges {
+		if e == nil || !strings.EqualFold(strings.TrimSpace(e.EdgeType), "prereq") {
+			continue
+		}
+		from, to := idToKey[e.FromConceptID], idToKey[e.ToConceptID]
+		if from != "" && to != "" {
+			out[from+"|"+to] = true
+		}
+	}
+	return out
+}
+
+// markNodeDocsConceptStale adds the changes to the concept_stale entry of every doc in the path
+// whose node covers a changed concept, keeping entries from earlier updates. It returns the
+// number of docs marked; without node/doc repos it marks nothing.
+func markNodeDocsConceptStale(dbc dbctx.Context, deps ConceptGraphBuildDeps, pathID uuid.UUID, changes []ConceptGraphChange, now time.Time) (int, error) {
+	if deps.PathNodes == nil || deps.NodeDocs == nil || len(changes) == 0 {
+		return 0, nil
+	}
+	byKey := map[string]ConceptGraphChange{}
+	for _, ch := range changes {
+		byKey[strings.ToLower(strings.TrimSpace(ch.Key))] = ch
+	}
+	nodes, err := deps.PathNodes.GetByPathIDs(dbc, []uuid.UUID{pathID})
+	if err != nil {
+		return 0, err
+	}
+	byNode := map[uuid.UUID][]content.NodeDocConceptChange{}
+	nodeIDs := make([]uuid.UUID, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil || n.ID == uuid.Nil {
+			continue
+		}
+		for _, k := range nodeConceptKeysFromMeta(n.Metadata) {
+			ch, ok := byKey[strings.ToLower(strings.TrimSpace(k))]
+			if !ok {
+				continue
+			}
+			if len(byNode[n.ID]) == 0 {
+				nodeIDs = append(nodeIDs, n.ID)
+			}
+			byNode[n.ID] = append(byNode[n.ID], content.NodeDocConceptChange{
+				ConceptID: ch.ConceptID.String(),
+				Key:       ch.Key,
+				Name:      ch.Name,
+				Kinds:     ch.Kinds,
+				ChangedAt: now,
+			})
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return 0, nil
+	}
+	docs, err := deps.NodeDocs.GetByPathNodeIDs(dbc, nodeIDs)
+	if err != nil {
+		return 0, err
+	}
+	marked := 0
+	for _, d := range docs {
+		if d == nil || d.ID == uuid.Nil || len(byNode[d.PathNodeID]) == 0 {
+			continue
+		}
+		entries := content.MergeNodeDocConceptChanges(content.NodeDocConceptStale(d.Metadata), byNode[d.PathNodeID])
+		if err := deps.NodeDocs.MergeMetadata(dbc, d.ID, map[string]any{
+			content.NodeDocConceptStaleKey: content.NodeDocConceptStaleValue(entries),
+		}); err != nil {
+			return marked, err
+		}
+		marked++
+	}
+	return marked, nil
+}
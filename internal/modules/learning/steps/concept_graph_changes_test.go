@@ -0,0 +1,109 @@
+package steps
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+func conceptChangesFixture() map[string]*types.Concept {
+	return map[string]*types.Concept{
+		"bayes_theorem": {
+			ID:        uuid.New(),
+			Key:       "bayes_theorem",
+			Name:      "Bayes' theorem",
+			Summary:   "Bayes' theorem relates a conditional probability to its inverse using the prior.",
+			KeyPoints: datatypes.JSON(`["Posterior is proportional to likelihood times prior"]`),
+			Metadata:  datatypes.JSON(`{"aliases":["Bayes rule"],"importance":5}`),
+		},
+		"conditional_probability": {
+			ID:      uuid.New(),
+			Key:     "conditional_probability",
+			Name:    "Conditional probability",
+			Summary: "The probability of an event given that another event occurred.",
+		},
+	}
+}
+
+func changeKinds(changes []conceptGraphChangeUpdate) map[string][]string {
+	out := map[string][]string{}
+	for _, ch := range changes {
+		out[ch.Key] = ch.Kinds
+	}
+	return out
+}
+
+func TestDetectConceptContentChangesSummary(t *testing.T) {
+	existing := conceptChangesFixture()
+	concepts := []conceptInvItem{
+		// Same definition, reworded and a little longer: not material.
+		{Key: "conditional_probability", Summary: "The probability of an event, given that another event has occurred."},
+		// Rewritten around a different framing.
+		{Key: "bayes_theorem", Summary: "A rule for updating beliefs: combine evidence likelihoods with base rates to get posterior odds.",
+			KeyPoints: []string{"posterior is proportional to the likelihood times the prior"}, Aliases: []string{"bayes rule", "Bayes' theorem"}},
+	}
+	changes := detectConceptContentChanges(existing, concepts, 0.6)
+	if got := changeKinds(changes); !reflect.DeepEqual(got, map[string][]string{"bayes_theorem": {content.ConceptChangeSummary}}) {
+		t.Fatalf("changes = %v", got)
+	}
+	if changes[0].Updates["summary"] != concepts[1].Summary {
+		t.Fatalf("updates = %v", changes[0].Updates)
+	}
+	if _, ok := changes[0].Updates["key_points"]; ok {
+		t.Fatalf("reworded key point or known aliases were treated as new: %v", changes[0].Updates)
+	}
+}
+
+func TestDetectConceptContentChangesMerged(t *testing.T) {
+	existing := conceptChangesFixture()
+	concepts := []conceptInvItem{{
+		Key:       "bayes_theorem",
+		Summary:   existing["bayes_theorem"].Summary,
+		KeyPoints: []string{"Posterior is proportional to likelihood times prior", "Base rate neglect ignores the prior"},
+		Aliases:   []string{"Bayes rule", "Bayes' law"},
+	}}
+	changes := detectConceptContentChanges(existing, concepts, 0.6)
+	if got := changeKinds(changes); !reflect.DeepEqual(got, map[string][]string{"bayes_theorem": {content.ConceptChangeMerged}}) {
+		t.Fatalf("changes = %v", got)
+	}
+	up := changes[0].Updates
+	if string(up["key_points"].(datatypes.JSON)) != `["Posterior is proportional to likelihood times prior","Base rate neglect ignores the prior"]` {
+		t.Fatalf("key_points = %s", up["key_points"])
+	}
+	if string(up["metadata"].(datatypes.JSON)) != `{"aliases":["Bayes rule","Bayes' law"],"importance":5}` {
+		t.Fatalf("metadata = %s", up["metadata"])
+	}
+}
+
+func TestConceptPrereqChanges(t *testing.T) {
+	existing := conceptChangesFixture()
+	idToKey := map[uuid.UUID]string{}
+	for k, c := range existing {
+		idToKey[c.ID] = k
+	}
+	stored := conceptPrereqKeyPairs([]*types.ConceptEdge{{
+		FromConceptID: existing["conditional_probability"].ID,
+		ToConceptID:   existing["bayes_theorem"].ID,
+		EdgeType:      "prereq",
+	}}, idToKey)
+	edges := []conceptEdgeItem{
+		{FromKey: "conditional_probability", ToKey: "bayes_theorem", EdgeType: "prereq"}, // already stored
+		{FromKey: "law_of_total_probability", ToKey: "bayes_theorem", EdgeType: "prereq"},
+		{FromKey: "bayes_theorem", ToKey: "naive_bayes", EdgeType: "prereq"},     // new concept gains it
+		{FromKey: "odds", ToKey: "conditional_probability", EdgeType: "related"}, // not a prereq
+	}
+	prior := detectConceptContentChanges(existing, []conceptInvItem{{Key: "bayes_theorem", Aliases: []string{"Bayes' law"}}}, 0.6)
+	changes := withConceptPrereqChanges(prior, existing, edges, stored)
+	want := map[string][]string{"bayes_theorem": {content.ConceptChangeMerged, content.ConceptChangePrereqAdded}}
+	if got := changeKinds(changes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes = %v, want %v", got, want)
+	}
+	if changes[0].ConceptID != existing["bayes_theorem"].ID {
+		t.Fatalf("concept id = %s", changes[0].ConceptID)
+	}
+}
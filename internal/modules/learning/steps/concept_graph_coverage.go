@@ -19,13 +19,15 @@ import (
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
+	"github.com/yungbote/neurobridge-backend/internal/platform/errclass"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"golang.org/x/sync/errgroup"
 )
 
 type conceptCoverage struct {
-	Confidence    float64
-	Notes         string
-	MissingTopics []string
+	Confidence    float64  `json:"confidence"`
+	Notes         string   `json:"notes"`
+	MissingTopics []string `json:"missing_topics_suspected"`
 }
 
 func parseConceptCoverage(obj map[string]any) conceptCoverage {
@@ -47,6 +49,8 @@ type conceptCoverageInput struct {
 	PathID        uuid.UUID
 	MaterialSetID uuid.UUID
 	IntentMD      string
+	// Optional language for concept names/summaries (see conceptGraphOutputLanguage).
+	OutputLanguage string
 
 	Chunks    []*types.MaterialChunk
 	ChunkByID map[uuid.UUID]*types.MaterialChunk
@@ -465,9 +469,10 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 					return err
 				}
 				p, err := prompts.Build(prompts.PromptConceptInventoryDelta, prompts.Input{
-					PathIntentMD: in.IntentMD,
-					ConceptsJSON: conceptsJSON,
-					Excerpts:     task.Excerpts,
+					PathIntentMD:   in.IntentMD,
+					ConceptsJSON:   conceptsJSON,
+					OutputLanguage: in.OutputLanguage,
+					Excerpts:       task.Excerpts,
 				})
 				if err != nil {
 					if deps.Log != nil {
@@ -476,7 +481,7 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 					return nil
 				}
 
-				timer := llmTimer(deps.Log, "concept_inventory_delta", map[string]any{
+				timer := llmTimer(ctx, deps.Log, "concept_inventory_delta", map[string]any{
 					"stage":         "concept_graph_build",
 					"path_id":       in.PathID.String(),
 					"round":         round,
@@ -485,7 +490,7 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 				})
 				obj, err := deps.AI.GenerateJSON(tctx, p.System, p.User, p.SchemaName, p.Schema)
 				timer(err)
-				if err != nil && isContextLengthExceeded(err) {
+				if err != nil && errclass.IsContextLength(err) {
 					retryMax := extraMaxTotal
 					if retryMax <= 0 {
 						retryMax = 20000
@@ -495,12 +500,13 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 						shorter, _ := renderChunkExcerptsByIDsOrdered(in.ChunkByID, task.CandidateIDs, extraMaxChars, maxTotal)
 						if strings.TrimSpace(shorter) != "" {
 							p2, berr := prompts.Build(prompts.PromptConceptInventoryDelta, prompts.Input{
-								PathIntentMD: in.IntentMD,
-								ConceptsJSON: conceptsJSON,
-								Excerpts:     shorter,
+								PathIntentMD:   in.IntentMD,
+								ConceptsJSON:   conceptsJSON,
+								OutputLanguage: in.OutputLanguage,
+								Excerpts:       shorter,
 							})
 							if berr == nil {
-								timer = llmTimer(deps.Log, "concept_inventory_delta", map[string]any{
+								timer = llmTimer(ctx, deps.Log, "concept_inventory_delta", map[string]any{
 									"stage":         "concept_graph_build",
 									"path_id":       in.PathID.String(),
 									"round":         round,
@@ -521,6 +527,11 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 					return nil
 				}
 
+				openai.ReportJSONUnusable(ctx, deps.Log, deps.AI, p.SchemaName, conceptInventoryDeltaUnusable(obj), map[string]any{
+					"stage":   "concept_graph_build",
+					"path_id": in.PathID.String(),
+					"round":   round,
+				})
 				newConcepts, cov, perr := parseConceptInventoryDelta(obj)
 				if perr != nil {
 					if deps.Log != nil {
@@ -602,7 +613,7 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 					"tasks":    len(sweepTasks),
 				}
 				conceptsJSON := conceptsJSONForDelta(concepts)
-				newConcepts, nextTopics := runCoverageDeltaTasks(ctx, deps, in.PathID, in.IntentMD, in.ChunkByID, sweepTasks, conceptsJSON, extraMaxChars, extraMaxTotal)
+				newConcepts, nextTopics := runCoverageDeltaTasks(ctx, deps, in.PathID, in.IntentMD, in.OutputLanguage, in.ChunkByID, sweepTasks, conceptsJSON, extraMaxChars, extraMaxTotal)
 				if len(newConcepts) > 0 {
 					merged, _ := normalizeConceptInventory(append(concepts, newConcepts...), in.AllowedChunkIDs)
 					merged, _ = dedupeConceptInventoryByKey(merged)
@@ -633,7 +644,7 @@ func completeConceptCoverage(ctx context.Context, deps ConceptGraphBuildDeps, in
 		if stage == "" {
 			stage = "concept_graph_coverage"
 		}
-		deps.Log.Info(stage+": adaptive params", "adaptive", adaptiveStageMeta(stage, adaptiveEnabled, signals, result.AdaptiveParams))
+		deps.Log.Info(stage+": adaptive params", "adaptive", adaptiveStageMeta(ctx, stage, adaptiveEnabled, signals, result.AdaptiveParams))
 	}
 	return result
 }
@@ -988,7 +999,7 @@ func sectionChunkByID(sectionChunks map[string][]*types.MaterialChunk) map[uuid.
 	return out
 }
 
-func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, pathID uuid.UUID, intent string, chunkByID map[uuid.UUID]*types.MaterialChunk, tasks []coverageDeltaTask, conceptsJSON string, maxChars int, maxTotal int) ([]conceptInvItem, []string) {
+func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, pathID uuid.UUID, intent string, language string, chunkByID map[uuid.UUID]*types.MaterialChunk, tasks []coverageDeltaTask, conceptsJSON string, maxChars int, maxTotal int) ([]conceptInvItem, []string) {
 	if deps.AI == nil || len(tasks) == 0 {
 		return nil, nil
 	}
@@ -1019,9 +1030,10 @@ func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, path
 				return err
 			}
 			p, err := prompts.Build(prompts.PromptConceptInventoryDelta, prompts.Input{
-				PathIntentMD: intent,
-				ConceptsJSON: conceptsJSON,
-				Excerpts:     task.Excerpts,
+				PathIntentMD:   intent,
+				ConceptsJSON:   conceptsJSON,
+				OutputLanguage: language,
+				Excerpts:       task.Excerpts,
 			})
 			if err != nil {
 				if deps.Log != nil {
@@ -1038,10 +1050,10 @@ func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, path
 			if task.Label != "" {
 				logMeta["scope"] = task.Label
 			}
-			timer := llmTimer(deps.Log, "concept_inventory_delta", logMeta)
+			timer := llmTimer(ctx, deps.Log, "concept_inventory_delta", logMeta)
 			obj, err := deps.AI.GenerateJSON(tctx, p.System, p.User, p.SchemaName, p.Schema)
 			timer(err)
-			if err != nil && isContextLengthExceeded(err) {
+			if err != nil && errclass.IsContextLength(err) {
 				retryMax := maxTotal
 				if retryMax <= 0 {
 					retryMax = 20000
@@ -1051,12 +1063,13 @@ func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, path
 					shorter, _ := renderChunkExcerptsByIDsOrdered(chunkByID, task.CandidateIDs, maxChars, maxTotal)
 					if strings.TrimSpace(shorter) != "" {
 						p2, berr := prompts.Build(prompts.PromptConceptInventoryDelta, prompts.Input{
-							PathIntentMD: intent,
-							ConceptsJSON: conceptsJSON,
-							Excerpts:     shorter,
+							PathIntentMD:   intent,
+							ConceptsJSON:   conceptsJSON,
+							OutputLanguage: language,
+							Excerpts:       shorter,
 						})
 						if berr == nil {
-							timer = llmTimer(deps.Log, "concept_inventory_delta", map[string]any{
+							timer = llmTimer(ctx, deps.Log, "concept_inventory_delta", map[string]any{
 								"stage":         "concept_graph_build",
 								"path_id":       pathID.String(),
 								"excerpt_chars": len(shorter),
@@ -1076,6 +1089,7 @@ func runCoverageDeltaTasks(ctx context.Context, deps ConceptGraphBuildDeps, path
 				return nil
 			}
 
+			openai.ReportJSONUnusable(ctx, deps.Log, deps.AI, p.SchemaName, conceptInventoryDeltaUnusable(obj), logMeta)
 			newConcepts, cov, perr := parseConceptInventoryDelta(obj)
 			if perr != nil {
 				if deps.Log != nil {
@@ -1164,6 +1178,23 @@ func parseConceptInventoryDelta(obj map[string]any) ([]conceptInvItem, conceptCo
 	return out, cov, nil
 }
 
+// conceptInventoryDeltaUnusable counts new_concepts the parser drops for a blank key or name.
+// An empty delta is a legitimate answer (nothing missing), so it is not reported.
+func conceptInventoryDeltaUnusable(obj map[string]any) []openai.JSONUnusable {
+	arr, _ := obj["new_concepts"].([]any)
+	missing := 0
+	for _, x := range arr {
+		m, ok := x.(map[string]any)
+		if !ok || strings.TrimSpace(stringFromAny(m["key"])) == "" || strings.TrimSpace(stringFromAny(m["name"])) == "" {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+	return []openai.JSONUnusable{{Kind: openai.JSONUnusableMissingKey, Field: "new_concepts.key/name", Count: missing}}
+}
+
 func splitStringBatches(in []string, size int) [][]string {
 	if len(in) == 0 {
 		return nil
@@ -1259,7 +1290,7 @@ func coverageTargetChunkIDs(
 		missingIdx = append(missingIdx, i)
 	}
 	if len(missing) > 0 {
-		timer := llmTimer(deps.Log, "topic_embeddings", map[string]any{
+		timer := llmTimer(ctx, deps.Log, "topic_embeddings", map[string]any{
 			"stage":        "concept_graph_build",
 			"material_set": materialSetID.String(),
 			"topic_count":  len(missing),
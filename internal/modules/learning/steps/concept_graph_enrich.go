@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -191,7 +192,7 @@ func buildCrossDocSectionGraph(
 		docs = append(docs, sections[i].Summary)
 	}
 	if len(pending) > 0 && deps.AI != nil {
-		timer := llmTimer(deps.Log, "section_embeddings", map[string]any{
+		timer := llmTimer(ctx, deps.Log, "section_embeddings", map[string]any{
 			"stage":         "concept_graph_build",
 			"batch_size":    len(docs),
 			"section_count": len(sections),
@@ -310,11 +311,95 @@ func chunkMetaMap(ch *types.MaterialChunk) map[string]any {
 	return meta
 }
 
+// excerptQualityFilter drops low-signal chunks (page numbers, running headers, boilerplate) from
+// concept graph excerpts so they don't dilute the inventory prompt. It is off unless
+// CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER is set.
+type excerptQualityFilter struct {
+	Enabled       bool
+	MinChars      int
+	MinAlnumRatio float64
+}
+
+var (
+	excerptPageNumberRE  = regexp.MustCompile(`(?i)^(page|p\.|slide)?\s*\d+\s*((of|/)\s*\d+)?$`)
+	excerptBoilerplateRE = regexp.MustCompile(`(?i)(all rights reserved|copyright|©|confidential|intentionally left blank|table of contents)`)
+)
+
+func resolveExcerptQualityFilter() excerptQualityFilter {
+	f := excerptQualityFilter{
+		Enabled:       envBool("CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER", false),
+		MinChars:      envIntAllowZero("CONCEPT_GRAPH_EXCERPT_MIN_CHARS", 24),
+		MinAlnumRatio: envFloatAllowZero("CONCEPT_GRAPH_EXCERPT_MIN_ALNUM_RATIO", 0.25),
+	}
+	if f.MinChars < 0 {
+		f.MinChars = 0
+	}
+	if f.MinAlnumRatio < 0 || f.MinAlnumRatio > 1 {
+		f.MinAlnumRatio = 0.25
+	}
+	return f
+}
+
+func (f excerptQualityFilter) Params() map[string]any {
+	return map[string]any{
+		"enabled":         f.Enabled,
+		"min_chars":       f.MinChars,
+		"min_alnum_ratio": f.MinAlnumRatio,
+	}
+}
+
+// LowSignal reports whether ch should be left out of excerpts. Short chunks that carry equations
+// are kept: a lone formula is often the most concept-dense text on a slide.
+func (f excerptQualityFilter) LowSignal(ch *types.MaterialChunk) bool {
+	if !f.Enabled || ch == nil {
+		return false
+	}
+	txt := strings.TrimSpace(ch.Text)
+	if txt == "" || len(chunkEquationLatex(ch)) > 0 {
+		return false
+	}
+	if excerptPageNumberRE.MatchString(txt) {
+		return true
+	}
+	runes := []rune(txt)
+	if len(runes) < f.MinChars {
+		return true
+	}
+	alnum := 0
+	for _, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			alnum++
+		}
+	}
+	if float64(alnum) < float64(len(runes))*f.MinAlnumRatio {
+		return true
+	}
+	return len(runes) < 160 && excerptBoilerplateRE.MatchString(txt)
+}
+
+// CountFiltered reports how many otherwise-eligible chunks LowSignal removes.
+func (f excerptQualityFilter) CountFiltered(chunks []*types.MaterialChunk) int {
+	if !f.Enabled {
+		return 0
+	}
+	n := 0
+	for _, ch := range chunks {
+		if ch == nil || ch.MaterialFileID == uuid.Nil || isUnextractableChunk(ch) {
+			continue
+		}
+		if f.LowSignal(ch) {
+			n++
+		}
+	}
+	return n
+}
+
 func buildConceptGraphExcerpts(chunks []*types.MaterialChunk, perFile int, maxChars int, maxLines int, maxTotalChars int) (string, []uuid.UUID) {
 	useAll := perFile <= 0
 	if maxChars <= 0 {
 		maxChars = 700
 	}
+	quality := resolveExcerptQualityFilter()
 	byFile := map[uuid.UUID][]*types.MaterialChunk{}
 	for _, ch := range chunks {
 		if ch == nil || ch.MaterialFileID == uuid.Nil {
@@ -326,6 +411,9 @@ func buildConceptGraphExcerpts(chunks []*types.MaterialChunk, perFile int, maxCh
 		if strings.TrimSpace(ch.Text) == "" {
 			continue
 		}
+		if quality.LowSignal(ch) {
+			continue
+		}
 		byFile[ch.MaterialFileID] = append(byFile[ch.MaterialFileID], ch)
 	}
 	fileIDs := make([]uuid.UUID, 0, len(byFile))
@@ -516,7 +604,7 @@ func extractFormulasAndPersist(ctx context.Context, deps ConceptGraphBuildDeps,
 	if err != nil {
 		return params
 	}
-	timer := llmTimer(deps.Log, "formula_extraction", map[string]any{
+	timer := llmTimer(ctx, deps.Log, "formula_extraction", map[string]any{
 		"stage":           "concept_graph_build",
 		"candidate_count": len(candidates),
 	})
@@ -620,16 +708,6 @@ func parseAssumedKnowledge(obj map[string]any) assumedKnowledge {
 	return out
 }
 
-func parseConceptAlignment(obj map[string]any) conceptAlignment {
-	if obj == nil {
-		return conceptAlignment{}
-	}
-	raw, _ := json.Marshal(obj)
-	var out conceptAlignment
-	_ = json.Unmarshal(raw, &out)
-	return out
-}
-
 func applyConceptAlignment(
 	concepts []conceptInvItem,
 	alignment conceptAlignment,
@@ -0,0 +1,187 @@
+package steps
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// Per-file excerpt sampling strategies for buildConceptGraphExcerptsOrdered.
+const (
+	// excerptSamplingUniform takes chunks at an even stride through the file.
+	excerptSamplingUniform = "uniform"
+	// excerptSamplingImportance strides through cumulative chunk importance instead of chunk
+	// count, so a dense, on-topic section contributes more excerpts than filler around it.
+	excerptSamplingImportance = "importance"
+)
+
+// excerptImportanceFloor is the weight every chunk keeps under importance sampling, so the
+// least central parts of a file still get sampled when they span most of it.
+const excerptImportanceFloor = 0.2
+
+type excerptSampler struct {
+	Strategy string
+	// Anchors are per-file signature summary embeddings. Importance is similarity to the
+	// anchor when one exists and to the centroid of the file's chunk embeddings otherwise.
+	Anchors map[uuid.UUID][]float32
+}
+
+// resolveExcerptSampler reads CONCEPT_GRAPH_EXCERPT_STRATEGY. Uniform is the default so
+// excerpts stay reproducible across builds unless importance sampling is asked for.
+func resolveExcerptSampler(sigByFile map[uuid.UUID]*types.MaterialFileSignature) excerptSampler {
+	s := excerptSampler{Strategy: excerptSamplingUniform}
+	if strings.ToLower(strings.TrimSpace(os.Getenv("CONCEPT_GRAPH_EXCERPT_STRATEGY"))) == excerptSamplingImportance {
+		s.Strategy = excerptSamplingImportance
+	}
+	if s.Strategy != excerptSamplingImportance {
+		return s
+	}
+	s.Anchors = map[uuid.UUID][]float32{}
+	for fid, sig := range sigByFile {
+		if sig == nil {
+			continue
+		}
+		if emb, ok := decodeEmbedding(sig.SummaryEmbedding); ok {
+			s.Anchors[fid] = emb
+		}
+	}
+	return s
+}
+
+func (s excerptSampler) Params() map[string]any {
+	return map[string]any{
+		"actual":            s.Strategy,
+		"signature_anchors": len(s.Anchors),
+	}
+}
+
+// pick returns k of arr's chunks in index order; arr must already be sorted by index.
+// Importance sampling falls back to uniform when fewer than two chunks have embeddings.
+func (s excerptSampler) pick(fileID uuid.UUID, arr []*types.MaterialChunk, k int) []*types.MaterialChunk {
+	n := len(arr)
+	if k <= 0 || n == 0 {
+		return nil
+	}
+	if k >= n {
+		return arr
+	}
+	if s.Strategy == excerptSamplingImportance {
+		if weights := excerptImportanceWeights(arr, s.Anchors[fileID]); weights != nil {
+			return pickByWeight(arr, weights, k)
+		}
+	}
+	out := make([]*types.MaterialChunk, 0, k)
+	step := float64(n) / float64(k)
+	for i := 0; i < k; i++ {
+		idx := int(float64(i) * step)
+		if idx >= n {
+			idx = n - 1
+		}
+		out = append(out, arr[idx])
+	}
+	return out
+}
+
+// excerptImportanceWeights scores each chunk by cosine similarity to anchor (or, without one,
+// to the centroid of the embedded chunks), rescaled to [excerptImportanceFloor, 1+floor].
+// Chunks without embeddings get the floor. It returns nil when there is nothing to rank.
+func excerptImportanceWeights(arr []*types.MaterialChunk, anchor []float32) []float64 {
+	embs := make([][]float32, len(arr))
+	embedded := 0
+	for i, ch := range arr {
+		if emb, ok := decodeEmbedding(ch.Embedding); ok {
+			embs[i] = emb
+			embedded++
+		}
+	}
+	if embedded < 2 {
+		return nil
+	}
+	if len(anchor) == 0 {
+		anchor = embeddingCentroid(embs)
+	}
+
+	scores := make([]float64, len(arr))
+	lo, hi := 1.0, -1.0
+	for i, emb := range embs {
+		if emb == nil {
+			continue
+		}
+		scores[i] = cosineSim(emb, anchor)
+		lo = math.Min(lo, scores[i])
+		hi = math.Max(hi, scores[i])
+	}
+	weights := make([]float64, len(arr))
+	for i, emb := range embs {
+		weights[i] = excerptImportanceFloor
+		if emb != nil && hi > lo {
+			weights[i] += (scores[i] - lo) / (hi - lo)
+		}
+	}
+	return weights
+}
+
+func embeddingCentroid(embs [][]float32) []float32 {
+	var sum []float64
+	count := 0
+	for _, emb := range embs {
+		if emb == nil {
+			continue
+		}
+		if sum == nil {
+			sum = make([]float64, len(emb))
+		}
+		if len(emb) != len(sum) {
+			continue
+		}
+		for i, v := range emb {
+			sum[i] += float64(v)
+		}
+		count++
+	}
+	out := make([]float32, len(sum))
+	for i, v := range sum {
+		out[i] = float32(v / float64(count))
+	}
+	return out
+}
+
+// pickByWeight takes k chunks at evenly spaced points of the cumulative weight, so the
+// sample still spans the file but lands more often where weight is concentrated. A point
+// that falls on an already picked chunk takes the next unpicked one.
+func pickByWeight(arr []*types.MaterialChunk, weights []float64, k int) []*types.MaterialChunk {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	picked := make([]bool, len(arr))
+	idxs := make([]int, 0, k)
+	cum, j := 0.0, 0
+	for i := 0; i < k; i++ {
+		target := (float64(i) + 0.5) / float64(k) * total
+		for j < len(arr)-1 && cum+weights[j] < target {
+			cum += weights[j]
+			j++
+		}
+		idx := j
+		for idx < len(arr)-1 && picked[idx] {
+			idx++
+		}
+		for picked[idx] {
+			idx--
+		}
+		picked[idx] = true
+		idxs = append(idxs, idx)
+	}
+	sort.Ints(idxs)
+	out := make([]*types.MaterialChunk, 0, k)
+	for _, idx := range idxs {
+		out = append(out, arr[idx])
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package steps
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestConceptGraphExcerptsLowSignalFilter(t *testing.T) {
+	fileID := uuid.New()
+	texts := []string{
+		"Binary search halves the search interval on every comparison.",
+		"Page 12 of 40",
+		"— — — * * * — — —   ...   ---   ===   ***",
+		"© 2024 Example University. All rights reserved.",
+		"Ch. 3",
+		"A sorted array lets us discard half of the remaining candidates.",
+	}
+	chunks := make([]*types.MaterialChunk, 0, len(texts)+1)
+	for i, txt := range texts {
+		chunks = append(chunks, &types.MaterialChunk{ID: uuid.New(), MaterialFileID: fileID, Index: i, Text: txt})
+	}
+	// A bare formula is short but carries the concept; it must survive the filter.
+	chunks = append(chunks, &types.MaterialChunk{
+		ID: uuid.New(), MaterialFileID: fileID, Index: len(texts), Text: "T(n)=T(n/2)+1",
+		Metadata: datatypes.JSON(`{"equations":[{"latex":"T(n)=T(n/2)+1"}]}`),
+	})
+
+	t.Setenv("CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER", "")
+	_, ids := buildConceptGraphExcerpts(chunks, 0, 700, 0, 0)
+	if len(ids) != len(chunks) {
+		t.Fatalf("filter is opt-in; got %d of %d chunks", len(ids), len(chunks))
+	}
+
+	t.Setenv("CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER", "true")
+	quality := resolveExcerptQualityFilter()
+	if got := quality.CountFiltered(chunks); got != 4 {
+		t.Fatalf("filtered count: got %d want 4", got)
+	}
+	excerpts, ids := buildConceptGraphExcerpts(chunks, 0, 700, 0, 0)
+	if len(ids) != 3 {
+		t.Fatalf("kept %d chunks: %s", len(ids), excerpts)
+	}
+	for _, junk := range []string{"Page 12", "All rights reserved", "Ch. 3"} {
+		if strings.Contains(excerpts, junk) {
+			t.Fatalf("low-signal chunk %q leaked into excerpts:\n%s", junk, excerpts)
+		}
+	}
+	ordered, orderedIDs := buildConceptGraphExcerptsOrdered(chunks, 0, 700, 0, 0, []uuid.UUID{fileID}, excerptSampler{Strategy: excerptSamplingUniform})
+	if len(orderedIDs) != 3 || strings.Contains(ordered, "Page 12") {
+		t.Fatalf("ordered builder should apply the same filter: %s", ordered)
+	}
+}
+
+func TestConceptGraphExcerptsImportanceStrategy(t *testing.T) {
+	fileID := uuid.New()
+	// Chunks 10-14 are a dense section on the file's topic; the rest is filler.
+	chunks := make([]*types.MaterialChunk, 0, 20)
+	dense := map[uuid.UUID]bool{}
+	for i := 0; i < 20; i++ {
+		ch := &types.MaterialChunk{ID: uuid.New(), MaterialFileID: fileID, Index: i,
+			Text: fmt.Sprintf("Filler paragraph number %d about course logistics.", i), Embedding: datatypes.JSON(`[0,1]`)}
+		if i >= 10 && i < 15 {
+			ch.Text = fmt.Sprintf("Dynamic programming recurrence, part %d.", i)
+			ch.Embedding = datatypes.JSON(`[1,0]`)
+			dense[ch.ID] = true
+		}
+		chunks = append(chunks, ch)
+	}
+	sigs := map[uuid.UUID]*types.MaterialFileSignature{
+		fileID: {MaterialFileID: fileID, SummaryEmbedding: datatypes.JSON(`[1,0]`)},
+	}
+	countDense := func(ids []uuid.UUID) int {
+		n := 0
+		for _, id := range ids {
+			if dense[id] {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Setenv("CONCEPT_GRAPH_EXCERPT_STRATEGY", "")
+	uniform := resolveExcerptSampler(sigs)
+	if uniform.Strategy != excerptSamplingUniform {
+		t.Fatalf("default strategy = %q", uniform.Strategy)
+	}
+	_, ids := buildConceptGraphExcerptsOrdered(chunks, 5, 700, 0, 0, nil, uniform)
+	if len(ids) != 5 || countDense(ids) != 1 {
+		t.Fatalf("uniform picked %d dense of %d", countDense(ids), len(ids))
+	}
+
+	t.Setenv("CONCEPT_GRAPH_EXCERPT_STRATEGY", "importance")
+	importance := resolveExcerptSampler(sigs)
+	if p := importance.Params(); p["actual"] != excerptSamplingImportance || p["signature_anchors"] != 1 {
+		t.Fatalf("params = %+v", p)
+	}
+	_, ids = buildConceptGraphExcerptsOrdered(chunks, 5, 700, 0, 0, nil, importance)
+	if len(ids) != 5 || countDense(ids) < 3 {
+		t.Fatalf("importance picked %d dense of %d", countDense(ids), len(ids))
+	}
+	if dense[ids[0]] || dense[ids[len(ids)-1]] {
+		t.Fatalf("importance sample should still span the file")
+	}
+	// Without a signature the centroid anchors the weights; the picks are still deterministic.
+	noAnchor := excerptSampler{Strategy: excerptSamplingImportance}
+	_, first := buildConceptGraphExcerptsOrdered(chunks, 5, 700, 0, 0, nil, noAnchor)
+	_, second := buildConceptGraphExcerptsOrdered(chunks, 5, 700, 0, 0, nil, noAnchor)
+	if len(first) != 5 || fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Fatalf("centroid fallback picks %v then %v", first, second)
+	}
+}
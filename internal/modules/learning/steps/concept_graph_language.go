@@ -0,0 +1,74 @@
+package steps
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+// conceptGraphOutputLanguage resolves the language concept names/summaries should be written in.
+// Precedence: CONCEPT_GRAPH_LANGUAGE env override, path metadata override, then the dominant
+// language reported by file signatures. Returns "" when nothing usable is known (prompts default).
+func conceptGraphOutputLanguage(pathOverride string, sigs []*types.MaterialFileSignature) string {
+	if v := normalizeConceptLanguage(os.Getenv("CONCEPT_GRAPH_LANGUAGE")); v != "" {
+		return v
+	}
+	if v := normalizeConceptLanguage(pathOverride); v != "" {
+		return v
+	}
+	return dominantSignatureLanguage(sigs)
+}
+
+// conceptLanguageFromPathMeta reads an explicit language override from path metadata
+// (top-level "language" or intake.language).
+func conceptLanguageFromPathMeta(meta map[string]any) string {
+	if meta == nil {
+		return ""
+	}
+	if v, ok := meta["language"].(string); ok && normalizeConceptLanguage(v) != "" {
+		return normalizeConceptLanguage(v)
+	}
+	if intake := mapFromAny(meta["intake"]); intake != nil {
+		if v, ok := intake["language"].(string); ok {
+			return normalizeConceptLanguage(v)
+		}
+	}
+	return ""
+}
+
+func dominantSignatureLanguage(sigs []*types.MaterialFileSignature) string {
+	counts := map[string]int{}
+	for _, sig := range sigs {
+		if sig == nil {
+			continue
+		}
+		if lang := normalizeConceptLanguage(sig.Language); lang != "" {
+			counts[lang]++
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	return langs[0]
+}
+
+func normalizeConceptLanguage(s string) string {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "", "unknown", "und", "none", "mixed", "n/a":
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package steps
+
+import (
+	"testing"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestConceptGraphOutputLanguage(t *testing.T) {
+	t.Setenv("CONCEPT_GRAPH_LANGUAGE", "")
+	sigs := []*types.MaterialFileSignature{
+		{Language: "es"},
+		{Language: "ES "},
+		{Language: "en"},
+		{Language: "unknown"},
+		nil,
+	}
+	if got := conceptGraphOutputLanguage("", sigs); got != "es" {
+		t.Fatalf("expected dominant signature language es, got %q", got)
+	}
+	if got := conceptGraphOutputLanguage("de", sigs); got != "de" {
+		t.Fatalf("expected path override de, got %q", got)
+	}
+	t.Setenv("CONCEPT_GRAPH_LANGUAGE", "fr")
+	if got := conceptGraphOutputLanguage("de", sigs); got != "fr" {
+		t.Fatalf("expected env override fr, got %q", got)
+	}
+}
+
+func TestConceptLanguageFromPathMeta(t *testing.T) {
+	if got := conceptLanguageFromPathMeta(map[string]any{"intake": map[string]any{"language": "Japanese"}}); got != "japanese" {
+		t.Fatalf("expected intake language, got %q", got)
+	}
+	if got := conceptLanguageFromPathMeta(map[string]any{"language": "mixed"}); got != "" {
+		t.Fatalf("expected empty language for mixed, got %q", got)
+	}
+}
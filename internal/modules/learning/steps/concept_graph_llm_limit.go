@@ -0,0 +1,70 @@
+package steps
+
+import (
+	"context"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// llmLimiter caps in-flight LLM calls across every stage of one concept graph build. Stage
+// concurrency settings (inventory, slices, embeddings) each bound their own goroutines; the
+// limiter bounds what they send to the provider together. A nil limiter does not limit.
+type llmLimiter chan struct{}
+
+// newConceptGraphLLMLimiter reads CONCEPT_GRAPH_GLOBAL_LLM_CONCURRENCY (0 disables the cap).
+func newConceptGraphLLMLimiter() llmLimiter {
+	n := envIntAllowZero("CONCEPT_GRAPH_GLOBAL_LLM_CONCURRENCY", 32)
+	if n <= 0 {
+		return nil
+	}
+	return make(llmLimiter, n)
+}
+
+func (l llmLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l llmLimiter) release() {
+	if l != nil {
+		<-l
+	}
+}
+
+// limitedLLMClient holds a limiter slot for each GenerateJSON and Embed call. Slots are only
+// held for the duration of one provider call, so callers may nest calls without deadlocking.
+type limitedLLMClient struct {
+	openai.Client
+	lim llmLimiter
+}
+
+// limitLLMClient wraps c with lim; nil clients and limiters are returned unchanged.
+func limitLLMClient(c openai.Client, lim llmLimiter) openai.Client {
+	if c == nil || lim == nil {
+		return c
+	}
+	return &limitedLLMClient{Client: c, lim: lim}
+}
+
+func (c *limitedLLMClient) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
+	if err := c.lim.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.lim.release()
+	return c.Client.GenerateJSON(ctx, system, user, schemaName, schema)
+}
+
+func (c *limitedLLMClient) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if err := c.lim.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer c.lim.release()
+	return c.Client.Embed(ctx, inputs)
+}
@@ -0,0 +1,85 @@
+package steps
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// inflightAI records the most concurrent GenerateJSON/Embed calls it saw.
+type inflightAI struct {
+	openai.Client
+	cur, peak atomic.Int32
+}
+
+func (f *inflightAI) enter() {
+	n := f.cur.Add(1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	f.cur.Add(-1)
+}
+
+func (f *inflightAI) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
+	f.enter()
+	return map[string]any{}, nil
+}
+
+func (f *inflightAI) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	f.enter()
+	return make([][]float32, len(inputs)), nil
+}
+
+func TestConceptGraphLLMLimitIsSharedAcrossStages(t *testing.T) {
+	t.Setenv("CONCEPT_GRAPH_GLOBAL_LLM_CONCURRENCY", "3")
+	t.Setenv("CONCEPT_GRAPH_INVENTORY_MODEL", "")
+	t.Setenv("CONCEPT_GRAPH_EDGES_MODEL", "")
+	ai := &inflightAI{}
+	lim := newConceptGraphLLMLimiter()
+	models := newConceptGraphModels(ai).limit(lim)
+	embedder := limitLLMClient(ai, lim)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, _ = models.For(conceptGraphTaskInventory).GenerateJSON(context.Background(), "", "", "", nil)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = models.For(conceptGraphTaskEdges).GenerateJSON(context.Background(), "", "", "", nil)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = embedder.Embed(context.Background(), []string{"a"})
+		}()
+	}
+	wg.Wait()
+	if peak := ai.peak.Load(); peak > 3 || peak == 0 {
+		t.Fatalf("peak in-flight calls = %d, want at most 3", peak)
+	}
+
+	// A caller waiting for a slot gives up with its context.
+	for i := 0; i < cap(lim); i++ {
+		_ = lim.acquire(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := embedder.Embed(ctx, []string{"a"}); err == nil {
+		t.Fatalf("expected the context error while the limiter is full")
+	}
+
+	t.Setenv("CONCEPT_GRAPH_GLOBAL_LLM_CONCURRENCY", "0")
+	if lim := newConceptGraphLLMLimiter(); lim != nil || limitLLMClient(ai, lim) != openai.Client(ai) {
+		t.Fatalf("0 should disable the limiter")
+	}
+}
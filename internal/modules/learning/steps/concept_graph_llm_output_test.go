@@ -0,0 +1,109 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// fixtureAI replays raw model outputs in order and parses them the way the inference client
+// does, so a truncated fixture surfaces as a JSON decode error.
+type fixtureAI struct {
+	openai.Client
+	outputs []string
+	calls   int
+}
+
+func (f *fixtureAI) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
+	if f.calls >= len(f.outputs) {
+		return nil, fmt.Errorf("no fixture for call %d", f.calls)
+	}
+	text := f.outputs[f.calls]
+	f.calls++
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse json output: %w", err)
+	}
+	return obj, nil
+}
+
+const (
+	inventoryFixture = `{"version":3,"warnings":[],"diagnostics":{},
+		"concepts":[
+			{"key":"limits","name":" Limits ","parent_key":null,"depth":0,"summary":"Approaching a value.","key_points":["epsilon","epsilon"],"aliases":[],"importance":5,"citations":["c1"]},
+			{"key":"","name":"Unnamed","parent_key":null,"depth":0,"summary":"","key_points":[],"aliases":[],"importance":1,"citations":[]}
+		],
+		"coverage":{"confidence":0.8,"notes":" ok ","missing_topics_suspected":["series"]}}`
+	// depth and importance as strings: the shape bespoke parsers used to coerce silently.
+	inventoryStringNumbersFixture = `{"version":3,"warnings":[],"diagnostics":{},
+		"concepts":[{"key":"limits","name":"Limits","parent_key":null,"depth":"0","summary":"","key_points":[],"aliases":[],"importance":"5","citations":[]}],
+		"coverage":{"confidence":0.8,"notes":"","missing_topics_suspected":[]}}`
+	edgesFixture = `{"version":1,"warnings":[],"diagnostics":{},
+		"edges":[{"from_key":"limits","to_key":"derivatives","edge_type":"prereq","strength":0.9,"rationale":"defined via limits","citations":["c1"]}]}`
+	// A response cut off by the output token limit.
+	edgesTruncatedFixture = `{"version":1,"warnings":[],"diagnostics":{},"edges":[{"from_key":"limits","to_key":"deriv`
+	alignmentFixture      = `{"version":1,"warnings":[],"diagnostics":{},
+		"aliases":[{"canonical_key":"derivative","alias_keys":["derivatives"],"rationale":"plural"}],"splits":[]}`
+	alignmentMissingSplitsFixture = `{"version":1,"warnings":[],"diagnostics":{},"aliases":[]}`
+)
+
+func TestConceptGraphValidatedOutputs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("inventory repaired", func(t *testing.T) {
+		ai := &fixtureAI{outputs: []string{inventoryStringNumbersFixture, inventoryFixture}}
+		var inv conceptInventoryOutput
+		if err := openai.GenerateJSONValidated(ctx, ai, "sys", "user", "concept_inventory", prompts.ConceptInventorySchema(), &inv); err != nil {
+			t.Fatalf("GenerateJSONValidated: %v", err)
+		}
+		items := inv.items()
+		if ai.calls != 2 || len(items) != 1 {
+			t.Fatalf("calls=%d items=%+v", ai.calls, items)
+		}
+		if items[0].Name != "Limits" || items[0].Importance != 5 || len(items[0].KeyPoints) != 1 {
+			t.Fatalf("item = %+v", items[0])
+		}
+		if cov := inv.coverage(); cov.Confidence != 0.8 || cov.Notes != "ok" || len(cov.MissingTopics) != 1 {
+			t.Fatalf("coverage = %+v", cov)
+		}
+		if got := inv.unusable(); len(got) != 1 || got[0].Kind != openai.JSONUnusableMissingKey || got[0].Field != "concepts.key" || got[0].Count != 1 {
+			t.Fatalf("unusable = %+v, want the keyless concept counted", got)
+		}
+		if got := (conceptInventoryOutput{}).unusable(); len(got) != 1 || got[0].Kind != openai.JSONUnusableEmptyArray {
+			t.Fatalf("empty inventory unusable = %+v", got)
+		}
+	})
+
+	t.Run("edges truncated", func(t *testing.T) {
+		ai := &fixtureAI{outputs: []string{edgesTruncatedFixture, edgesFixture}}
+		var res conceptEdgesOutput
+		if err := openai.GenerateJSONValidated(ctx, ai, "sys", "user", "concept_edges", prompts.ConceptEdgesSchema(), &res); err != nil {
+			t.Fatalf("GenerateJSONValidated: %v", err)
+		}
+		if ai.calls != 2 || len(res.Edges) != 1 || res.Edges[0].Strength != 0.9 {
+			t.Fatalf("calls=%d edges=%+v", ai.calls, res.Edges)
+		}
+
+		ai = &fixtureAI{outputs: []string{edgesTruncatedFixture, edgesTruncatedFixture}}
+		res = conceptEdgesOutput{}
+		err := openai.GenerateJSONValidated(ctx, ai, "sys", "user", "concept_edges", prompts.ConceptEdgesSchema(), &res)
+		if openai.JSONFailureClassOf(err) != openai.JSONFailureTruncation || len(res.Edges) != 0 {
+			t.Fatalf("err=%v edges=%+v, want truncation with no edges", err, res.Edges)
+		}
+	})
+
+	t.Run("alignment", func(t *testing.T) {
+		ai := &fixtureAI{outputs: []string{alignmentMissingSplitsFixture, alignmentFixture}}
+		var alignment conceptAlignment
+		if err := openai.GenerateJSONValidated(ctx, ai, "sys", "user", "concept_alignment", prompts.ConceptAlignmentSchema(), &alignment); err != nil {
+			t.Fatalf("GenerateJSONValidated: %v", err)
+		}
+		if ai.calls != 2 || len(alignment.Aliases) != 1 || alignment.Aliases[0].CanonicalKey != "derivative" {
+			t.Fatalf("calls=%d alignment=%+v", ai.calls, alignment)
+		}
+	})
+}
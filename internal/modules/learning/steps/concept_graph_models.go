@@ -0,0 +1,99 @@
+package steps
+
+import (
+	"os"
+	"strings"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+// Concept graph LLM subtasks that can be routed to their own model.
+const (
+	conceptGraphTaskInventory = "inventory"
+	conceptGraphTaskEdges     = "edges"
+	conceptGraphTaskAlignment = "alignment"
+	conceptGraphTaskAssumed   = "assumed_knowledge"
+	conceptGraphTaskEmbed     = "embed"
+)
+
+// conceptGraphModelEnv maps each generation subtask to the env var that overrides its model.
+var conceptGraphModelEnv = map[string]string{
+	conceptGraphTaskInventory: "CONCEPT_GRAPH_INVENTORY_MODEL",
+	conceptGraphTaskEdges:     "CONCEPT_GRAPH_EDGES_MODEL",
+	conceptGraphTaskAlignment: "CONCEPT_GRAPH_ALIGNMENT_MODEL",
+	conceptGraphTaskAssumed:   "CONCEPT_GRAPH_ASSUMED_MODEL",
+}
+
+// conceptGraphModels routes concept graph subtasks to clients. Each subtask uses its
+// CONCEPT_GRAPH_<TASK>_MODEL, then CONCEPT_GRAPH_MODEL, then the base client's model. Clients are
+// built up front so the router is safe to share across the build's goroutines.
+//
+// Embeddings always use the base client: concept vectors must stay comparable with the rest of
+// the index, so only the embedding model in use is recorded.
+type conceptGraphModels struct {
+	base    openai.Client
+	clients map[string]openai.Client
+	names   map[string]string
+}
+
+func newConceptGraphModels(base openai.Client) *conceptGraphModels {
+	m := &conceptGraphModels{
+		base:    base,
+		clients: map[string]openai.Client{},
+		names:   map[string]string{},
+	}
+	shared := strings.TrimSpace(os.Getenv("CONCEPT_GRAPH_MODEL"))
+	for task, key := range conceptGraphModelEnv {
+		model := strings.TrimSpace(os.Getenv(key))
+		if model == "" {
+			model = shared
+		}
+		c := openai.WithModel(base, model)
+		m.clients[task] = c
+		if name := openai.ModelName(c); name != "" {
+			m.names[task] = name
+		} else if model != "" {
+			m.names[task] = model
+		}
+	}
+	if name := openai.EmbedModelName(base); name != "" {
+		m.names[conceptGraphTaskEmbed] = name
+	}
+	return m
+}
+
+// For returns the client configured for task (the base client for unknown tasks).
+func (m *conceptGraphModels) For(task string) openai.Client {
+	if m == nil {
+		return nil
+	}
+	if c := m.clients[task]; c != nil {
+		return c
+	}
+	return m.base
+}
+
+// limit routes every subtask client (and the base) through lim. Call it after construction:
+// model names are read from the unwrapped clients.
+func (m *conceptGraphModels) limit(lim llmLimiter) *conceptGraphModels {
+	if m == nil || lim == nil {
+		return m
+	}
+	m.base = limitLLMClient(m.base, lim)
+	for task, c := range m.clients {
+		m.clients[task] = limitLLMClient(c, lim)
+	}
+	return m
+}
+
+// Names reports the model each subtask uses, for build output and traces.
+func (m *conceptGraphModels) Names() map[string]string {
+	if m == nil || len(m.names) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m.names))
+	for k, v := range m.names {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package steps
+
+import "testing"
+
+func TestConceptGraphModelsRouting(t *testing.T) {
+	t.Setenv("CONCEPT_GRAPH_MODEL", "shared-model")
+	t.Setenv("CONCEPT_GRAPH_INVENTORY_MODEL", "cheap-model")
+	t.Setenv("CONCEPT_GRAPH_ALIGNMENT_MODEL", "strong-model")
+	t.Setenv("CONCEPT_GRAPH_EDGES_MODEL", "")
+	t.Setenv("CONCEPT_GRAPH_ASSUMED_MODEL", "")
+
+	m := newConceptGraphModels(nil)
+	names := m.Names()
+	want := map[string]string{
+		conceptGraphTaskInventory: "cheap-model",
+		conceptGraphTaskAlignment: "strong-model",
+		conceptGraphTaskEdges:     "shared-model",
+		conceptGraphTaskAssumed:   "shared-model",
+	}
+	for task, model := range want {
+		if names[task] != model {
+			t.Fatalf("%s routed to %q, want %q", task, names[task], model)
+		}
+	}
+	if _, ok := names[conceptGraphTaskEmbed]; ok {
+		t.Fatalf("embed model should only be recorded from a real client, got %q", names[conceptGraphTaskEmbed])
+	}
+	if m.For(conceptGraphTaskEdges) != nil {
+		t.Fatalf("expected nil client when base is nil")
+	}
+}
+
+func TestConceptGraphModelsDefaultToBase(t *testing.T) {
+	for _, key := range conceptGraphModelEnv {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CONCEPT_GRAPH_MODEL", "")
+
+	if names := newConceptGraphModels(nil).Names(); names != nil {
+		t.Fatalf("expected no recorded models without overrides, got %v", names)
+	}
+}
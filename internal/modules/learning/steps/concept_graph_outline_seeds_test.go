@@ -0,0 +1,50 @@
+package steps
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestOutlineSeedTopicsMinConfidence(t *testing.T) {
+	sigFile := &types.MaterialFile{ID: uuid.New()}
+	diagFile := &types.MaterialFile{
+		ID:                    uuid.New(),
+		ExtractionDiagnostics: datatypes.JSON(`{"outline_hint":{"source":"headings","confidence":0.35,"sections":[{"title":"Dynamic Programming"}]}}`),
+	}
+	emptyFile := &types.MaterialFile{ID: uuid.New()}
+	sigByFile := map[uuid.UUID]*types.MaterialFileSignature{
+		sigFile.ID: {
+			OutlineJSON:       datatypes.JSON(`{"sections":[{"title":"Graph Traversal"},{"title":"Table of Contents"},{"title":"Shortest Paths"}]}`),
+			OutlineConfidence: 0.8,
+		},
+		emptyFile.ID: {OutlineJSON: datatypes.JSON(`{"sections":[]}`)},
+	}
+	files := []*types.MaterialFile{sigFile, diagFile, emptyFile}
+
+	cases := []struct {
+		name    string
+		minConf float64
+		want    []string
+		stats   outlineSeedStats
+	}{
+		{"no gate", 0, []string{"Graph Traversal", "Shortest Paths", "Dynamic Programming"}, outlineSeedStats{SourcesUsed: 2}},
+		{"drops weak diagnostics hint", 0.5, []string{"Graph Traversal", "Shortest Paths"}, outlineSeedStats{SourcesUsed: 1, SkippedLowConfidence: 1}},
+		{"drops everything", 0.9, []string{}, outlineSeedStats{SkippedLowConfidence: 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, stats := outlineSeedTopics(files, sigByFile, AdaptiveSignals{}, tc.minConf)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("seeds = %v, want %v", got, tc.want)
+			}
+			if stats != tc.stats {
+				t.Fatalf("stats = %+v, want %+v", stats, tc.stats)
+			}
+		})
+	}
+}
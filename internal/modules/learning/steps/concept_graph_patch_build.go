@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"os"
 	"sort"
 	"strings"
 	"sync/atomic"
@@ -32,6 +31,10 @@ type ConceptGraphPatchBuildInput struct {
 	MaterialSetID uuid.UUID
 	SagaID        uuid.UUID
 	PathID        uuid.UUID
+	// MaterialFileID scopes the patch to one file of the set: only its chunks are inventoried,
+	// the coverage probe never skips the run, and only edges touching concepts the file grounds
+	// are written.
+	MaterialFileID uuid.UUID
 }
 
 type ConceptGraphPatchBuildOutput = ConceptGraphBuildOutput
@@ -65,10 +68,9 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("concept_graph_patch_build")
-	if model := strings.TrimSpace(os.Getenv("CONCEPT_GRAPH_MODEL")); model != "" && deps.AI != nil {
-		deps.AI = openai.WithModel(deps.AI, model)
-	}
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "concept_graph_patch_build")
+	models := newConceptGraphModels(deps.AI)
+	out.Models = models.Names()
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -76,9 +78,9 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("concept_graph_patch_build: adaptive params", "adaptive", adaptiveStageMeta("concept_graph_patch_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("concept_graph_patch_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "concept_graph_patch_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("concept_graph_patch_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "concept_graph_patch_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	existing, err := deps.Concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
@@ -93,6 +95,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	}
 
 	intentMD := ""
+	pathLanguage := ""
 	var allowFiles map[uuid.UUID]bool
 	if deps.Path != nil {
 		if row, err := deps.Path.GetByID(dbctx.Context{Ctx: ctx}, pathID); err == nil && row != nil && len(row.Metadata) > 0 && string(row.Metadata) != "null" {
@@ -104,6 +107,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 					}
 				}
 				intentMD = strings.TrimSpace(stringFromAny(meta["intake_md"]))
+				pathLanguage = conceptLanguageFromPathMeta(meta)
 				allowFiles = intakeMaterialAllowlistFromPathMeta(meta)
 			}
 		}
@@ -121,6 +125,19 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			deps.Log.Warn("concept_graph_patch_build: intake filter excluded all files; ignoring filter", "path_id", pathID.String())
 		}
 	}
+	if in.MaterialFileID != uuid.Nil {
+		var scoped []*types.MaterialFile
+		for _, f := range files {
+			if f != nil && f.ID == in.MaterialFileID {
+				scoped = append(scoped, f)
+			}
+		}
+		if len(scoped) == 0 {
+			return out, fmt.Errorf("concept_graph_patch_build: material file %s not in material set", in.MaterialFileID)
+		}
+		files = scoped
+		allowFiles = map[uuid.UUID]bool{in.MaterialFileID: true}
+	}
 
 	fileIDs := make([]uuid.UUID, 0, len(files))
 	for _, f := range files {
@@ -128,11 +145,19 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			fileIDs = append(fileIDs, f.ID)
 		}
 	}
+	var fileSigs []*types.MaterialFileSignature
+	if deps.FileSigs != nil {
+		fileSigs, _ = deps.FileSigs.GetByMaterialFileIDs(dbctx.Context{Ctx: ctx}, fileIDs)
+	}
+	outputLanguage := conceptGraphOutputLanguage(pathLanguage, fileSigs)
 	chunks, err := deps.Chunks.GetByMaterialFileIDs(dbctx.Context{Ctx: ctx}, fileIDs)
 	if err != nil {
 		return out, err
 	}
 	if len(chunks) == 0 {
+		if in.MaterialFileID != uuid.Nil {
+			return out, fmt.Errorf("concept_graph_patch_build: no chunks for material file %s", in.MaterialFileID)
+		}
 		return out, fmt.Errorf("concept_graph_patch_build: no chunks for material set")
 	}
 
@@ -165,6 +190,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			"concepts":    conceptFP,
 			"allow_files": allowFileIDs,
 			"intent_md":   intentMD,
+			"file_scope":  in.MaterialFileID != uuid.Nil,
 			"env":         envSnapshot([]string{"CONCEPT_GRAPH_"}, []string{"OPENAI_MODEL"}),
 		}
 		if h, err := computeArtifactHash("concept_graph_patch_build", in.MaterialSetID, pathID, payload); err == nil {
@@ -277,6 +303,11 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		"actual":  patchMaxTotal,
 		"ceiling": patchMaxTotalCeiling,
 	}
+	if excerptQuality := resolveExcerptQualityFilter(); excerptQuality.Enabled {
+		qp := excerptQuality.Params()
+		qp["filtered"] = excerptQuality.CountFiltered(chunks)
+		adaptiveParams["CONCEPT_GRAPH_EXCERPT_LOW_SIGNAL_FILTER"] = qp
+	}
 	patchExcerpts, patchChunkIDs := buildConceptGraphExcerpts(chunks, patchPerFile, patchMaxChars, patchMaxLines, patchMaxTotal)
 	if strings.TrimSpace(patchExcerpts) == "" {
 		return out, fmt.Errorf("concept_graph_patch_build: empty excerpts")
@@ -381,17 +412,22 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	var probeNew []conceptInvItem
 	if deps.AI != nil {
 		if p, err := prompts.Build(prompts.PromptConceptInventoryDelta, prompts.Input{
-			PathIntentMD: intentMD,
-			ConceptsJSON: conceptsJSON,
-			Excerpts:     patchExcerpts,
+			PathIntentMD:   intentMD,
+			ConceptsJSON:   conceptsJSON,
+			Excerpts:       patchExcerpts,
+			OutputLanguage: outputLanguage,
 		}); err == nil {
-			timer := llmTimer(deps.Log, "concept_inventory_delta_probe", map[string]any{
+			timer := llmTimer(ctx, deps.Log, "concept_inventory_delta_probe", map[string]any{
 				"stage":         "concept_graph_patch_build",
 				"path_id":       pathID.String(),
 				"excerpt_chars": len(patchExcerpts),
 			})
-			if obj, err := deps.AI.GenerateJSON(ctx, p.System, p.User, p.SchemaName, p.Schema); err == nil {
+			if obj, err := models.For(conceptGraphTaskInventory).GenerateJSON(ctx, p.System, p.User, p.SchemaName, p.Schema); err == nil {
 				timer(err)
+				openai.ReportJSONUnusable(ctx, deps.Log, models.For(conceptGraphTaskInventory), p.SchemaName, conceptInventoryDeltaUnusable(obj), map[string]any{
+					"stage":   "concept_graph_patch_build",
+					"path_id": pathID.String(),
+				})
 				if nc, cov, perr := parseConceptInventoryDelta(obj); perr == nil {
 					probeNew = nc
 					probeCoverage = cov
@@ -402,7 +438,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		}
 	}
 
-	if !envBool("CONCEPT_GRAPH_PATCH_FORCE", false) {
+	if in.MaterialFileID == uuid.Nil && !envBool("CONCEPT_GRAPH_PATCH_FORCE", false) {
 		minConf := envFloatAllowZero("CONCEPT_GRAPH_PATCH_SKIP_MIN_CONF", 0.75)
 		if adaptiveEnabled {
 			minConf = clamp01(adjustThresholdByContentType("CONCEPT_GRAPH_PATCH_SKIP_MIN_CONF", minConf, signals.ContentType))
@@ -455,6 +491,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		PathID:             pathID,
 		MaterialSetID:      in.MaterialSetID,
 		IntentMD:           intentMD,
+		OutputLanguage:     outputLanguage,
 		Chunks:             chunks,
 		ChunkByID:          chunkByID,
 		ChunkEmbs:          chunkEmbs,
@@ -518,7 +555,20 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			newItems = append(newItems, c)
 		}
 	}
-	if len(newItems) == 0 {
+	// A file-scoped patch also refreshes existing concepts the file grounds: those it already has
+	// evidence for and those the inventory cited it for.
+	var touchedKeys map[string]bool
+	if in.MaterialFileID != uuid.Nil {
+		touchedKeys, err = conceptGraphFileTouchedKeys(ctx, deps, existing, chunks, conceptsOut, newItems)
+		if err != nil {
+			return out, err
+		}
+	}
+	// Existing concepts the new material materially rewrote or merged into; prereq changes are
+	// added once edges are known.
+	summaryMinSim := conceptGraphPatchSummaryMinSimilarity()
+	changes := detectConceptContentChanges(existingByKey, conceptsOut, summaryMinSim)
+	if len(newItems) == 0 && len(touchedKeys) == 0 && len(changes) == 0 {
 		if deps.Log != nil {
 			deps.Log.Info("concept_graph_patch_build: no new concepts discovered", "path_id", pathID.String())
 		}
@@ -535,19 +585,36 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	if err != nil {
 		return out, err
 	}
-	timer := llmTimer(deps.Log, "concept_edges", map[string]any{
+	edgesMeta := map[string]any{
 		"stage":         "concept_graph_patch_build",
 		"path_id":       pathID.String(),
 		"concept_count": len(conceptsOut),
 		"excerpt_chars": len(edgeExcerpts),
-	})
-	edgesObj, err := deps.AI.GenerateJSON(ctx, edgesPrompt.System, edgesPrompt.User, edgesPrompt.SchemaName, edgesPrompt.Schema)
+	}
+	timer := llmTimer(ctx, deps.Log, "concept_edges", edgesMeta)
+	var edgesRes conceptEdgesOutput
+	err = openai.GenerateJSONValidated(ctx, models.For(conceptGraphTaskEdges), edgesPrompt.System, edgesPrompt.User, edgesPrompt.SchemaName, edgesPrompt.Schema, &edgesRes)
 	timer(err)
 	if err != nil {
 		return out, err
 	}
-	edgesOut := parseConceptEdges(edgesObj)
+	if len(conceptsOut) > 1 {
+		openai.ReportJSONUnusable(ctx, deps.Log, models.For(conceptGraphTaskEdges), edgesPrompt.SchemaName, edgesRes.unusable(), edgesMeta)
+	}
+	edgesOut := edgesRes.Edges
 	edgesOut, _ = normalizeConceptEdges(edgesOut, conceptsOut, allowedChunkIDs)
+	if touchedKeys != nil {
+		edgesOut = filterConceptEdgesTouching(edgesOut, touchedKeys)
+	}
+	existingIDs := make([]uuid.UUID, 0, len(existingByKey))
+	for _, row := range existingByKey {
+		existingIDs = append(existingIDs, row.ID)
+	}
+	storedEdges, err := deps.Edges.GetByConceptIDs(dbctx.Context{Ctx: ctx}, existingIDs)
+	if err != nil {
+		return out, err
+	}
+	changes = withConceptPrereqChanges(changes, existingByKey, edgesOut, conceptPrereqKeyPairs(storedEdges, idToKey))
 
 	// ---- Embed new concepts for canonical matching + vector upsert ----
 	embedBatchSize := envIntAllowZero("CONCEPT_GRAPH_EMBED_BATCH_SIZE", 128)
@@ -566,7 +633,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		}
 		conceptDocs = append(conceptDocs, doc)
 	}
-	if len(conceptDocs) == 0 {
+	if len(conceptDocs) == 0 && len(touchedKeys) == 0 && len(changes) == 0 {
 		return out, nil
 	}
 
@@ -580,7 +647,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			end = len(conceptDocs)
 		}
 		eg.Go(func() error {
-			timer := llmTimer(deps.Log, "concept_embeddings", map[string]any{
+			timer := llmTimer(ctx, deps.Log, "concept_embeddings", map[string]any{
 				"stage":       "concept_graph_patch_build",
 				"path_id":     pathID.String(),
 				"batch_size":  end - start,
@@ -604,7 +671,7 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		return out, err
 	}
 
-	semanticMatchByKey, semanticParams := semanticMatchCanonicalConcepts(ctx, deps, newItems, embs, signals, signals.ContentType, adaptiveEnabled, nil)
+	semanticMatchByKey, semanticParams := semanticMatchCanonicalConcepts(ctx, deps, newItems, embs, signals, signals.ContentType, adaptiveEnabled, canonicalConceptMinSimilarity(), nil)
 	for k, v := range semanticParams {
 		adaptiveParams[k] = v
 	}
@@ -684,8 +751,16 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 		}
 
 		evRows := make([]*types.ConceptEvidence, 0)
-		for _, c := range newItems {
+		evItems := newItems
+		if touchedKeys != nil {
+			// Existing concepts cited by the file get its chunks as evidence too.
+			evItems = conceptsOut
+		}
+		for _, c := range evItems {
 			cid := keyToID[c.Key]
+			if cid == uuid.Nil || (touchedKeys != nil && !touchedKeys[c.Key]) {
+				continue
+			}
 			for _, sid := range uuidSliceFromStrings(dedupeStrings(filterChunkIDStrings(c.Citations, allowedChunkIDs))) {
 				evRows = append(evRows, &types.ConceptEvidence{
 					ID:              uuid.New(),
@@ -722,6 +797,21 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			out.EdgesMade++
 		}
 
+		out.ConceptChanges = make([]ConceptGraphChange, 0, len(changes))
+		for _, ch := range changes {
+			if len(ch.Updates) > 0 {
+				if err := deps.Concepts.UpdateFields(dbc, ch.ConceptID, ch.Updates); err != nil {
+					return err
+				}
+			}
+			out.ConceptChanges = append(out.ConceptChanges, ch.ConceptGraphChange)
+		}
+		staled, err := markNodeDocsConceptStale(dbc, deps, pathID, out.ConceptChanges, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		out.DocsConceptStaled = staled
+
 		// Guardrail: never commit a patch that violates structural invariants.
 		if envBool("CONCEPT_GRAPH_PATCH_PRECOMMIT_VALIDATE", true) {
 			report := validation.ValidateStructuralInvariants(ctx, tx, pathID)
@@ -771,6 +861,8 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 			out.ConceptsMade = 0
 			out.EdgesMade = 0
 			out.PineconeBatches = 0
+			out.ConceptChanges = nil
+			out.DocsConceptStaled = 0
 			if patchInputHash != "" && deps.Artifacts != nil && artifactCacheEnabled() {
 				_ = artifactCacheUpsert(ctx, deps.Artifacts, &types.LearningArtifact{
 					OwnerUserID:   in.OwnerUserID,
@@ -924,6 +1016,59 @@ func ConceptGraphPatchBuild(ctx context.Context, deps ConceptGraphBuildDeps, in
 	return out, nil
 }
 
+// conceptGraphFileTouchedKeys returns the keys of concepts a file grounds: new concepts, existing
+// concepts with evidence in the file's chunks, and items the inventory cited the file for.
+func conceptGraphFileTouchedKeys(ctx context.Context, deps ConceptGraphBuildDeps, existing []*types.Concept, chunks []*types.MaterialChunk, concepts []conceptInvItem, newItems []conceptInvItem) (map[string]bool, error) {
+	touched := map[string]bool{}
+	for _, c := range newItems {
+		touched[c.Key] = true
+	}
+	for _, c := range concepts {
+		if len(c.Citations) > 0 {
+			touched[c.Key] = true
+		}
+	}
+	chunkIDs := make([]uuid.UUID, 0, len(chunks))
+	for _, ch := range chunks {
+		if ch != nil && ch.ID != uuid.Nil {
+			chunkIDs = append(chunkIDs, ch.ID)
+		}
+	}
+	if len(chunkIDs) == 0 {
+		return touched, nil
+	}
+	evidence, err := deps.Evidence.GetByMaterialChunkIDs(dbctx.Context{Ctx: ctx}, chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+	keyByID := map[uuid.UUID]string{}
+	for _, c := range existing {
+		if c != nil && c.ID != uuid.Nil {
+			keyByID[c.ID] = strings.TrimSpace(c.Key)
+		}
+	}
+	for _, ev := range evidence {
+		if ev == nil {
+			continue
+		}
+		if key := keyByID[ev.ConceptID]; key != "" {
+			touched[key] = true
+		}
+	}
+	return touched, nil
+}
+
+// filterConceptEdgesTouching keeps edges with at least one endpoint in keys.
+func filterConceptEdgesTouching(edges []conceptEdgeItem, keys map[string]bool) []conceptEdgeItem {
+	out := make([]conceptEdgeItem, 0, len(edges))
+	for _, e := range edges {
+		if keys[e.FromKey] || keys[e.ToKey] {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func invariantFailureSummaries(report validation.InvariantReport) []string {
 	if len(report.Checks) == 0 {
 		return nil
@@ -0,0 +1,22 @@
+package steps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterConceptEdgesTouching(t *testing.T) {
+	edges := []conceptEdgeItem{
+		{FromKey: "a", ToKey: "b", EdgeType: "prereq"},
+		{FromKey: "b", ToKey: "c", EdgeType: "related"},
+		{FromKey: "c", ToKey: "d", EdgeType: "prereq"},
+	}
+	got := filterConceptEdgesTouching(edges, map[string]bool{"b": true})
+	want := edges[:2]
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("filtered = %+v, want %+v", got, want)
+	}
+	if got := filterConceptEdgesTouching(edges, map[string]bool{}); len(got) != 0 {
+		t.Fatalf("no touched keys should keep no edges, got %+v", got)
+	}
+}
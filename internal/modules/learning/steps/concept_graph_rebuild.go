@@ -0,0 +1,124 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// retiredConceptVectors are the path concept vectors a forced rebuild replaces.
+type retiredConceptVectors struct {
+	Namespace  string
+	ConceptIDs []uuid.UUID
+	VectorIDs  []string
+}
+
+func (r retiredConceptVectors) empty() bool {
+	return len(r.ConceptIDs) == 0 && len(r.VectorIDs) == 0
+}
+
+func captureConceptVectors(ns string, existing []*types.Concept) retiredConceptVectors {
+	out := retiredConceptVectors{Namespace: ns}
+	seen := map[string]bool{}
+	for _, c := range existing {
+		if c == nil || c.ID == uuid.Nil {
+			continue
+		}
+		out.ConceptIDs = append(out.ConceptIDs, c.ID)
+		vid := strings.TrimSpace(c.VectorID)
+		if vid == "" || seen[vid] {
+			continue
+		}
+		seen[vid] = true
+		out.VectorIDs = append(out.VectorIDs, vid)
+	}
+	return out
+}
+
+// appendVectorDeleteCompensations records delete compensations for ids in batches of batchSize.
+func appendVectorDeleteCompensations(dbc dbctx.Context, saga services.SagaService, sagaID uuid.UUID, ns string, ids []string, batchSize int) error {
+	if saga == nil || len(ids) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(ids)
+	}
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := saga.AppendAction(dbc, sagaID, services.SagaActionKindVectorDeleteIDs, map[string]any{
+			"namespace": ns,
+			"ids":       ids[start:end],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retireConceptGraphForRebuild replaces the path's existing concept graph inside the caller's
+// tx. The old vector ids are captured and their delete compensations appended before any row is
+// removed: saga actions are durable outside the tx, so a rebuild that fails at any later point
+// still has its old vectors removed on compensation instead of leaving them orphaned. (If the tx
+// itself rolls back, the old rows survive without vectors; vectors are a cache and re-index.)
+func retireConceptGraphForRebuild(dbc dbctx.Context, deps ConceptGraphBuildDeps, sagaID uuid.UUID, ns string, existing []*types.Concept, batchSize int) (retiredConceptVectors, error) {
+	retired := captureConceptVectors(ns, existing)
+	if retired.empty() {
+		return retired, nil
+	}
+	if deps.Vec != nil {
+		if err := appendVectorDeleteCompensations(dbc, deps.Saga, sagaID, ns, retired.VectorIDs, batchSize); err != nil {
+			return retired, fmt.Errorf("append rebuild compensations: %w", err)
+		}
+	}
+	if dbc.Tx == nil {
+		return retired, fmt.Errorf("concept graph rebuild requires a transaction")
+	}
+	ids := retired.ConceptIDs
+	// Edges and evidence carry no FK to concept, so they are cleared explicitly. Concepts are hard
+	// deleted because the (scope, scope_id, key) unique index would reject the regenerated keys.
+	if err := dbc.Tx.WithContext(dbc.Ctx).Unscoped().
+		Where("concept_id IN ?", ids).
+		Delete(&types.ConceptEvidence{}).Error; err != nil {
+		return retired, err
+	}
+	if err := dbc.Tx.WithContext(dbc.Ctx).Unscoped().
+		Where("from_concept_id IN ? OR to_concept_id IN ?", ids, ids).
+		Delete(&types.ConceptEdge{}).Error; err != nil {
+		return retired, err
+	}
+	if err := deps.Concepts.FullDeleteByIDs(dbc, ids); err != nil {
+		return retired, err
+	}
+	return retired, nil
+}
+
+// deleteRetiredConceptVectors removes the replaced vectors once the rebuild has committed. A
+// successful saga never compensates, so without this the old vectors would outlive their rows.
+func deleteRetiredConceptVectors(ctx context.Context, vec pc.VectorStore, retired retiredConceptVectors, batchSize int) error {
+	if vec == nil || len(retired.VectorIDs) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(retired.VectorIDs)
+	}
+	for start := 0; start < len(retired.VectorIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(retired.VectorIDs) {
+			end = len(retired.VectorIDs)
+		}
+		if err := vec.DeleteIDs(ctx, retired.Namespace, retired.VectorIDs[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/services"
+)
+
+// recordingSaga keeps appended actions outside any tx, like the real saga aggregate, and
+// replays vector deletes against vec on Compensate (latest first).
+type recordingSaga struct {
+	noopSaga
+	actions []map[string]any
+	vec     *memVectorStore
+}
+
+func (s *recordingSaga) AppendAction(dbc dbctx.Context, sagaID uuid.UUID, kind string, payload map[string]any) error {
+	if kind != services.SagaActionKindVectorDeleteIDs {
+		return errors.New("unexpected saga action kind " + kind)
+	}
+	s.actions = append(s.actions, payload)
+	return nil
+}
+
+func (s *recordingSaga) Compensate(ctx context.Context, sagaID uuid.UUID) error {
+	for i := len(s.actions) - 1; i >= 0; i-- {
+		ns, _ := s.actions[i]["namespace"].(string)
+		ids, _ := s.actions[i]["ids"].([]string)
+		if err := s.vec.DeleteIDs(ctx, ns, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memVectorStore struct {
+	byNS map[string]map[string]bool
+}
+
+func newMemVectorStore() *memVectorStore {
+	return &memVectorStore{byNS: map[string]map[string]bool{}}
+}
+
+func (m *memVectorStore) Upsert(ctx context.Context, namespace string, vectors []pc.Vector) error {
+	if m.byNS[namespace] == nil {
+		m.byNS[namespace] = map[string]bool{}
+	}
+	for _, v := range vectors {
+		m.byNS[namespace][v.ID] = true
+	}
+	return nil
+}
+
+func (m *memVectorStore) QueryMatches(ctx context.Context, namespace string, q []float32, topK int, filter map[string]any) ([]pc.VectorMatch, error) {
+	return nil, nil
+}
+
+func (m *memVectorStore) QueryIDs(ctx context.Context, namespace string, q []float32, topK int, filter map[string]any) ([]string, error) {
+	return nil, nil
+}
+
+func (m *memVectorStore) DeleteIDs(ctx context.Context, namespace string, ids []string) error {
+	for _, id := range ids {
+		delete(m.byNS[namespace], id)
+	}
+	return nil
+}
+
+func (m *memVectorStore) ids(namespace string) []string {
+	out := make([]string, 0, len(m.byNS[namespace]))
+	for id := range m.byNS[namespace] {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestRetireConceptGraphFailureAfterCompensationAppend(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+
+	user := testutil.SeedUser(t, dbc, "rebuild@example.com")
+	set := testutil.SeedMaterialSet(t, dbc, user.ID)
+	file := testutil.SeedMaterialFile(t, dbc, set.ID, "rebuild.pdf")
+	chunk := testutil.SeedMaterialChunk(t, dbc, file.ID, 0)
+
+	pathID := uuid.New()
+	ns := index.ConceptsNamespace("path", &pathID)
+	concepts := repolearning.NewConceptRepo(tx, log)
+	oldRows := make([]*types.Concept, 0, 3)
+	for _, key := range []string{"limits", "derivatives", "integrals"} {
+		id := uuid.New()
+		oldRows = append(oldRows, &types.Concept{
+			ID:       id,
+			Scope:    "path",
+			ScopeID:  &pathID,
+			Key:      key,
+			Name:     key,
+			VectorID: "concept:" + id.String(),
+		})
+	}
+	if _, err := concepts.Create(dbc, oldRows); err != nil {
+		t.Fatalf("seed concepts: %v", err)
+	}
+	if err := tx.Create(&types.ConceptEvidence{ConceptID: oldRows[0].ID, MaterialChunkID: chunk.ID}).Error; err != nil {
+		t.Fatalf("seed evidence: %v", err)
+	}
+	if err := tx.Create(&types.ConceptEdge{FromConceptID: oldRows[0].ID, ToConceptID: oldRows[1].ID, EdgeType: "prereq"}).Error; err != nil {
+		t.Fatalf("seed edge: %v", err)
+	}
+
+	vec := newMemVectorStore()
+	for _, c := range oldRows {
+		_ = vec.Upsert(ctx, ns, []pc.Vector{{ID: c.VectorID}})
+	}
+	saga := &recordingSaga{vec: vec}
+	deps := ConceptGraphBuildDeps{Concepts: concepts, Saga: saga, Vec: vec}
+	sagaID := uuid.New()
+
+	// The rebuild retires the old graph, writes its replacement vector, then fails.
+	newVectorID := "concept:" + uuid.New().String()
+	boom := errors.New("rebuild failed after compensation append")
+	err := tx.Transaction(func(inner *gorm.DB) error {
+		idbc := dbctx.Context{Ctx: ctx, Tx: inner}
+		existing, err := deps.Concepts.GetByScope(idbc, "path", &pathID)
+		if err != nil {
+			return err
+		}
+		if _, err := retireConceptGraphForRebuild(idbc, deps, sagaID, ns, existing, 2); err != nil {
+			return err
+		}
+		left, err := deps.Concepts.GetByScope(idbc, "path", &pathID)
+		if err != nil {
+			return err
+		}
+		if len(left) != 0 {
+			t.Fatalf("expected old concepts removed inside the rebuild tx, got %d", len(left))
+		}
+		if err := appendVectorDeleteCompensations(idbc, deps.Saga, sagaID, ns, []string{newVectorID}, 2); err != nil {
+			return err
+		}
+		_ = vec.Upsert(ctx, ns, []pc.Vector{{ID: newVectorID}})
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected rebuild failure, got %v", err)
+	}
+
+	// The rows roll back, but the delete compensations were recorded before anything changed.
+	existing, err := concepts.GetByScope(dbc, "path", &pathID)
+	if err != nil || len(existing) != len(oldRows) {
+		t.Fatalf("expected old concepts restored by rollback, got %d (err=%v)", len(existing), err)
+	}
+	compensated := map[string]bool{}
+	for _, a := range saga.actions {
+		if a["namespace"] != ns {
+			t.Fatalf("compensation for wrong namespace: %v", a["namespace"])
+		}
+		ids, _ := a["ids"].([]string)
+		if len(ids) > 2 {
+			t.Fatalf("compensation batch exceeds batch size: %v", ids)
+		}
+		for _, id := range ids {
+			compensated[id] = true
+		}
+	}
+	for _, c := range oldRows {
+		if !compensated[c.VectorID] {
+			t.Fatalf("missing delete compensation for %s", c.VectorID)
+		}
+	}
+
+	if err := saga.Compensate(ctx, sagaID); err != nil {
+		t.Fatalf("compensate: %v", err)
+	}
+	if left := vec.ids(ns); len(left) != 0 {
+		t.Fatalf("expected no orphaned vectors after compensation, got %v", left)
+	}
+}
+
+func TestRetireConceptGraphCompensatesBeforeDeleting(t *testing.T) {
+	pathID := uuid.New()
+	ns := index.ConceptsNamespace("path", &pathID)
+	existing := []*types.Concept{
+		{ID: uuid.New(), VectorID: "concept:a"},
+		{ID: uuid.New(), VectorID: "concept:b"},
+		{ID: uuid.New(), VectorID: "concept:a"},
+		{ID: uuid.New()},
+	}
+	vec := newMemVectorStore()
+	_ = vec.Upsert(context.Background(), ns, []pc.Vector{{ID: "concept:a"}, {ID: "concept:b"}})
+	saga := &recordingSaga{vec: vec}
+
+	// Without a tx the rows cannot be replaced, but the compensations are already durable.
+	retired, err := retireConceptGraphForRebuild(dbctx.Context{Ctx: context.Background()}, ConceptGraphBuildDeps{Saga: saga, Vec: vec}, uuid.New(), ns, existing, 64)
+	if err == nil {
+		t.Fatal("expected failure without a transaction")
+	}
+	if len(retired.ConceptIDs) != 4 || len(retired.VectorIDs) != 2 {
+		t.Fatalf("unexpected capture: %+v", retired)
+	}
+	if len(saga.actions) != 1 {
+		t.Fatalf("expected compensation appended before failing, got %d actions", len(saga.actions))
+	}
+	if err := saga.Compensate(context.Background(), uuid.Nil); err != nil {
+		t.Fatalf("compensate: %v", err)
+	}
+	if left := vec.ids(ns); len(left) != 0 {
+		t.Fatalf("expected old vectors deleted, got %v", left)
+	}
+}
@@ -3,6 +3,9 @@ package steps
 import (
 	"context"
 	"math"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,18 +23,49 @@ type canonicalMatch struct {
 	Method string // exact_key | alias | semantic
 }
 
-func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildDeps, concepts []conceptInvItem, embs [][]float32, signals AdaptiveSignals, contentType string, adaptiveEnabled bool, progress func(done, total int)) (map[string]canonicalMatch, map[string]any) {
+// canonicalMinSimilarity is the configured floor on the top match's similarity for a semantic
+// canonical match, and where it came from.
+type canonicalMinSimilarity struct {
+	Value  float64
+	Source string // CONCEPT_CANONICAL_MIN_SIMILARITY | CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE | default
+}
+
+const defaultCanonicalMinSimilarity = 0.885
+
+// canonicalConceptMinSimilarity reads CONCEPT_CANONICAL_MIN_SIMILARITY, falling back to the older
+// CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE. Too low a floor over-merges distinct concepts across
+// paths (and with them, mastery), so deployments tune it explicitly.
+func canonicalConceptMinSimilarity() canonicalMinSimilarity {
+	for _, key := range []string{"CONCEPT_CANONICAL_MIN_SIMILARITY", "CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE"} {
+		raw := strings.TrimSpace(os.Getenv(key))
+		if raw == "" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return canonicalMinSimilarity{Value: clamp01(v), Source: key}
+		}
+	}
+	return canonicalMinSimilarity{Value: defaultCanonicalMinSimilarity, Source: "default"}
+}
+
+func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildDeps, concepts []conceptInvItem, embs [][]float32, signals AdaptiveSignals, contentType string, adaptiveEnabled bool, minSimilarity canonicalMinSimilarity, progress func(done, total int)) (map[string]canonicalMatch, map[string]any) {
 	out := map[string]canonicalMatch{}
 	params := map[string]any{}
 	if deps.Vec == nil || deps.Concepts == nil || len(concepts) == 0 || len(embs) != len(concepts) {
 		return out, params
 	}
 
-	minScore := envFloatAllowZero("CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE", 0.885)
-	if adaptiveEnabled {
+	// An explicit CONCEPT_CANONICAL_MIN_SIMILARITY is used as is; the default and the legacy
+	// setting still get the content-type adjustment.
+	minScore := minSimilarity.Value
+	if adaptiveEnabled && minSimilarity.Source != "CONCEPT_CANONICAL_MIN_SIMILARITY" {
 		minScore = clamp01(adjustThresholdByContentType("CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE", minScore, contentType))
 	}
-	params["CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE"] = map[string]any{"actual": minScore}
+	params["CONCEPT_CANONICAL_MIN_SIMILARITY"] = map[string]any{
+		"actual":     minScore,
+		"configured": minSimilarity.Value,
+		"source":     minSimilarity.Source,
+	}
 	minGap := envFloatAllowZero("CANONICAL_CONCEPT_SEMANTIC_MIN_GAP", 0.02)
 	if adaptiveEnabled {
 		switch strings.ToLower(strings.TrimSpace(contentType)) {
@@ -141,6 +175,9 @@ func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildD
 	}
 
 	semanticMatched := 0
+	// Top-match score per queried concept, kept for the threshold's score distribution.
+	var bestScores []float64
+	ambiguous := 0
 	if minScore > 0 && len(todoIdx) > 0 {
 		if progress != nil {
 			progress(0, len(todoIdx))
@@ -179,10 +216,16 @@ func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildD
 					return nil
 				}
 				best := matches[0]
+				mu.Lock()
+				bestScores = append(bestScores, best.Score)
+				mu.Unlock()
 				if best.Score < minScore {
 					return nil
 				}
 				if len(matches) > 1 && (best.Score-matches[1].Score) < minGap {
+					mu.Lock()
+					ambiguous++
+					mu.Unlock()
 					return nil
 				}
 				idStr := strings.TrimSpace(best.ID)
@@ -201,6 +244,11 @@ func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildD
 			})
 		}
 		_ = eg.Wait()
+		dist := canonicalScoreDistribution(bestScores, minScore)
+		dist["queried"] = len(todoIdx)
+		dist["rejected_ambiguous"] = ambiguous
+		dist["matched"] = semanticMatched
+		params["canonical_semantic_scores"] = dist
 	}
 
 	if len(out) > 0 {
@@ -246,3 +294,51 @@ func semanticMatchCanonicalConcepts(ctx context.Context, deps ConceptGraphBuildD
 
 	return out, params
 }
+
+// canonicalScoreDistribution summarizes top-match scores against the threshold so a floor can
+// be tuned from real builds: quantiles, how many cleared it, and a coarse histogram.
+func canonicalScoreDistribution(scores []float64, minScore float64) map[string]any {
+	out := map[string]any{"count": len(scores), "threshold": minScore}
+	if len(scores) == 0 {
+		return out
+	}
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	at := func(q float64) float64 {
+		return sorted[int(math.Round(q*float64(len(sorted)-1)))]
+	}
+	above := 0
+	buckets := []struct {
+		label string
+		upper float64
+	}{
+		{"lt_0.80", 0.80},
+		{"0.80_0.85", 0.85},
+		{"0.85_0.90", 0.90},
+		{"0.90_0.95", 0.95},
+		{"gte_0.95", math.Inf(1)},
+	}
+	hist := map[string]int{}
+	for _, b := range buckets {
+		hist[b.label] = 0
+	}
+	for _, s := range sorted {
+		if s >= minScore {
+			above++
+		}
+		for _, b := range buckets {
+			if s < b.upper {
+				hist[b.label]++
+				break
+			}
+		}
+	}
+	out["min"] = sorted[0]
+	out["p50"] = at(0.5)
+	out["p90"] = at(0.9)
+	out["max"] = sorted[len(sorted)-1]
+	out["above_threshold"] = above
+	out["below_threshold"] = len(sorted) - above
+	out["histogram"] = hist
+	return out
+}
@@ -0,0 +1,47 @@
+package steps
+
+import "testing"
+
+func TestCanonicalConceptMinSimilarityPrecedence(t *testing.T) {
+	t.Setenv("CONCEPT_CANONICAL_MIN_SIMILARITY", "")
+	t.Setenv("CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE", "")
+	if got := canonicalConceptMinSimilarity(); got.Value != defaultCanonicalMinSimilarity || got.Source != "default" {
+		t.Fatalf("default = %+v", got)
+	}
+
+	t.Setenv("CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE", "0.9")
+	if got := canonicalConceptMinSimilarity(); got.Value != 0.9 || got.Source != "CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE" {
+		t.Fatalf("legacy = %+v", got)
+	}
+
+	t.Setenv("CONCEPT_CANONICAL_MIN_SIMILARITY", "0.93")
+	if got := canonicalConceptMinSimilarity(); got.Value != 0.93 || got.Source != "CONCEPT_CANONICAL_MIN_SIMILARITY" {
+		t.Fatalf("explicit = %+v", got)
+	}
+
+	t.Setenv("CONCEPT_CANONICAL_MIN_SIMILARITY", "not-a-number")
+	if got := canonicalConceptMinSimilarity(); got.Source != "CANONICAL_CONCEPT_SEMANTIC_MIN_SCORE" {
+		t.Fatalf("unparseable value should fall through, got %+v", got)
+	}
+}
+
+func TestCanonicalScoreDistribution(t *testing.T) {
+	if got := canonicalScoreDistribution(nil, 0.9); got["count"] != 0 {
+		t.Fatalf("empty = %+v", got)
+	}
+
+	got := canonicalScoreDistribution([]float64{0.97, 0.7, 0.91, 0.86, 0.82}, 0.9)
+	if got["count"] != 5 || got["above_threshold"] != 2 || got["below_threshold"] != 3 {
+		t.Fatalf("counts = %+v", got)
+	}
+	if got["min"] != 0.7 || got["p50"] != 0.86 || got["max"] != 0.97 {
+		t.Fatalf("quantiles = %+v", got)
+	}
+	hist := got["histogram"].(map[string]int)
+	want := map[string]int{"lt_0.80": 1, "0.80_0.85": 1, "0.85_0.90": 1, "0.90_0.95": 1, "gte_0.95": 1}
+	for k, v := range want {
+		if hist[k] != v {
+			t.Fatalf("histogram[%s] = %d, want %d (%v)", k, hist[k], v, hist)
+		}
+	}
+}
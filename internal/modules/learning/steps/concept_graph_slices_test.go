@@ -0,0 +1,84 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestBuildInventorySlicesOverlap(t *testing.T) {
+	fileID := uuid.New()
+	chunks := make([]*types.MaterialChunk, 0, 12)
+	for i := 0; i < 12; i++ {
+		chunks = append(chunks, &types.MaterialChunk{ID: uuid.New(), MaterialFileID: fileID, Index: i})
+	}
+
+	disjoint := buildInventorySlices(chunks, 3, 0)
+	total := 0
+	for _, s := range disjoint {
+		total += len(s.Chunks)
+	}
+	if len(disjoint) != 3 || total != len(chunks) {
+		t.Fatalf("zero overlap must partition: slices=%d chunks=%d", len(disjoint), total)
+	}
+
+	shared := buildInventorySlices(chunks, 3, 0.5)
+	total = 0
+	for _, s := range shared {
+		total += len(s.Chunks)
+	}
+	// Each slice owns 4 chunks and borrows half of its neighbour's.
+	if total != len(chunks)+6 {
+		t.Fatalf("expected 6 shared chunks, got %d total", total-len(chunks))
+	}
+	// Chunk 1 (owned by slice 1) must also appear next to chunk 0 in slice 0.
+	found := false
+	for _, ch := range shared[0].Chunks {
+		if ch.Index == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("slice 0 should borrow its neighbour's first chunk")
+	}
+
+	if got := clampInventorySliceOverlap(0.9); got != 0.5 {
+		t.Fatalf("overlap should clamp to 0.5, got %v", got)
+	}
+	if got := clampInventorySliceOverlap(-1); got != 0 {
+		t.Fatalf("negative overlap should clamp to 0, got %v", got)
+	}
+}
+
+func TestBoostInventorySliceCountLimits(t *testing.T) {
+	defaults := inventoryBoostLimits{MaxFactor: defaultInventoryBoostMaxFactor}
+
+	// 10 concepts against a guardrail of 50 asks for a 5x boost; the default caps it at 3x.
+	if got := boostInventorySliceCount(4, 0, 10, 50, defaults); got.Count != 12 || got.Wanted != 20 || got.ClampedBy != "max_factor" {
+		t.Fatalf("default cap: %+v", got)
+	}
+	// A 2x boost fits under every limit and is not reported as clamped.
+	if got := boostInventorySliceCount(4, 0, 25, 50, defaults); got.Count != 8 || got.ClampedBy != "" {
+		t.Fatalf("unclamped: %+v", got)
+	}
+	if got := boostInventorySliceCount(4, 10, 10, 50, defaults); got.Count != 10 || got.ClampedBy != "slice_ceiling" {
+		t.Fatalf("slice ceiling: %+v", got)
+	}
+	if got := boostInventorySliceCount(4, 10, 10, 50, inventoryBoostLimits{MaxFactor: 5, MaxSlices: 6}); got.Count != 6 || got.ClampedBy != "max_slices" {
+		t.Fatalf("absolute cap: %+v", got)
+	}
+	if got := boostInventorySliceCount(4, 0, 10, 50, inventoryBoostLimits{MaxFactor: 1}); got.Count != 4 || got.ClampedBy != "max_factor" {
+		t.Fatalf("boost disabled: %+v", got)
+	}
+	if got := boostInventorySliceCount(4, 0, 60, 50, defaults); got.Count != 4 || got.ClampedBy != "" {
+		t.Fatalf("guardrail met: %+v", got)
+	}
+
+	t.Setenv("CONCEPT_GRAPH_INVENTORY_BOOST_MAX_FACTOR", "0")
+	t.Setenv("CONCEPT_GRAPH_INVENTORY_BOOST_MAX_SLICES", "-3")
+	if got := resolveInventoryBoostLimits(); got.MaxFactor != 1 || got.MaxSlices != 0 {
+		t.Fatalf("resolveInventoryBoostLimits = %+v", got)
+	}
+}
@@ -0,0 +1,304 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// Concept graph invariant kinds reported by ValidateConceptGraph.
+const (
+	GraphViolationEdgeMissingConcept = "edge_missing_concept"
+	GraphViolationEdgeSelfLoop       = "edge_self_loop"
+	GraphViolationParentInvalid      = "parent_invalid"
+	GraphViolationDepthMismatch      = "depth_mismatch"
+	GraphViolationCanonicalMissing   = "canonical_missing"
+	GraphViolationCanonicalNotRoot   = "canonical_not_root"
+	GraphViolationEvidenceChunk      = "evidence_missing_chunk"
+)
+
+// ConceptGraphValidateDeps wires ValidateConceptGraph. Log is required when fixing (the
+// canonicalizer's repos log through it).
+type ConceptGraphValidateDeps struct {
+	DB  *gorm.DB
+	Log *logger.Logger
+}
+
+// ConceptGraphViolation is one broken invariant. RowID is the offending concept, edge, or
+// evidence row.
+type ConceptGraphViolation struct {
+	Kind   string    `json:"kind"`
+	Table  string    `json:"table"`
+	RowID  uuid.UUID `json:"row_id"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+type ConceptGraphValidationReport struct {
+	PathID     uuid.UUID               `json:"path_id"`
+	Concepts   int                     `json:"concepts"`
+	Edges      int                     `json:"edges"`
+	Evidence   int                     `json:"evidence"`
+	Violations []ConceptGraphViolation `json:"violations"`
+	// Fixed counts repaired rows by violation kind (only with fix).
+	Fixed map[string]int `json:"fixed,omitempty"`
+}
+
+// Counts tallies violations by kind.
+func (r ConceptGraphValidationReport) Counts() map[string]int {
+	out := map[string]int{}
+	for _, v := range r.Violations {
+		out[v.Kind]++
+	}
+	return out
+}
+
+// ValidateConceptGraph checks a persisted path concept graph against the invariants the build
+// enforces in memory: edges join two live path concepts and are not self-loops, parent links
+// point at live path concepts without cycles and depths follow them, canonical links resolve
+// to a live global root, and evidence rows cite existing chunks.
+//
+// With fix, repairs run in one transaction using the build's own normalizers: hierarchy via
+// normalizeConceptInventory, canonical links via canonicalizePathConcepts; invalid edges and
+// evidence rows are soft-deleted.
+func ValidateConceptGraph(ctx context.Context, deps ConceptGraphValidateDeps, pathID uuid.UUID, fix bool) (ConceptGraphValidationReport, error) {
+	report := ConceptGraphValidationReport{PathID: pathID, Violations: []ConceptGraphViolation{}}
+	if deps.DB == nil || pathID == uuid.Nil {
+		return report, fmt.Errorf("validate concept graph: missing db or path id")
+	}
+	run := func(tx *gorm.DB) error {
+		return validateConceptGraph(dbctx.Context{Ctx: ctx, Tx: tx}, deps, pathID, fix, &report)
+	}
+	if !fix {
+		return report, run(deps.DB.WithContext(ctx))
+	}
+	report.Fixed = map[string]int{}
+	err := deps.DB.WithContext(ctx).Transaction(run)
+	return report, err
+}
+
+func validateConceptGraph(dbc dbctx.Context, deps ConceptGraphValidateDeps, pathID uuid.UUID, fix bool, report *ConceptGraphValidationReport) error {
+	tx := dbc.Tx
+	now := time.Now().UTC()
+
+	// Unscoped: edges and evidence may still reference soft-deleted concepts.
+	var all []*types.Concept
+	if err := tx.Unscoped().Where("scope = ? AND scope_id = ?", "path", pathID).Order("key ASC").Find(&all).Error; err != nil {
+		return fmt.Errorf("load concepts: %w", err)
+	}
+	live := make([]*types.Concept, 0, len(all))
+	liveByID := map[uuid.UUID]*types.Concept{}
+	allIDs := make([]uuid.UUID, 0, len(all))
+	for _, c := range all {
+		allIDs = append(allIDs, c.ID)
+		if !c.DeletedAt.Valid {
+			live = append(live, c)
+			liveByID[c.ID] = c
+		}
+	}
+	report.Concepts = len(live)
+	if len(allIDs) == 0 {
+		return nil
+	}
+	add := func(kind, table string, id uuid.UUID, detail string) {
+		report.Violations = append(report.Violations, ConceptGraphViolation{Kind: kind, Table: table, RowID: id, Detail: detail})
+	}
+
+	// Edges.
+	var edges []*types.ConceptEdge
+	if err := tx.Where("from_concept_id IN ? OR to_concept_id IN ?", allIDs, allIDs).Order("id ASC").Find(&edges).Error; err != nil {
+		return fmt.Errorf("load edges: %w", err)
+	}
+	report.Edges = len(edges)
+	badEdges := map[string][]uuid.UUID{}
+	for _, e := range edges {
+		switch {
+		case e.FromConceptID == e.ToConceptID:
+			add(GraphViolationEdgeSelfLoop, "concept_edge", e.ID, e.FromConceptID.String())
+			badEdges[GraphViolationEdgeSelfLoop] = append(badEdges[GraphViolationEdgeSelfLoop], e.ID)
+		case liveByID[e.FromConceptID] == nil || liveByID[e.ToConceptID] == nil:
+			add(GraphViolationEdgeMissingConcept, "concept_edge", e.ID, e.FromConceptID.String()+" -> "+e.ToConceptID.String())
+			badEdges[GraphViolationEdgeMissingConcept] = append(badEdges[GraphViolationEdgeMissingConcept], e.ID)
+		}
+	}
+
+	// Hierarchy: run the inventory normalizer over id-derived keys so persisted keys can't merge.
+	idKey := func(id uuid.UUID) string { return normalizeConceptKey(id.String()) }
+	items := make([]conceptInvItem, 0, len(live))
+	byKey := map[string]*types.Concept{}
+	for _, c := range live {
+		it := conceptInvItem{Key: idKey(c.ID), Depth: c.Depth}
+		if c.ParentID != nil && *c.ParentID != uuid.Nil {
+			it.ParentKey = idKey(*c.ParentID)
+		}
+		items = append(items, it)
+		byKey[it.Key] = c
+	}
+	normalized, _ := normalizeConceptInventory(items, nil)
+	type hierarchyFix struct {
+		ID       uuid.UUID
+		ParentID *uuid.UUID
+		Depth    int
+	}
+	var hierarchyFixes []hierarchyFix
+	for _, it := range normalized {
+		c := byKey[it.Key]
+		if c == nil {
+			continue
+		}
+		var parent *uuid.UUID
+		if p := byKey[it.ParentKey]; p != nil {
+			id := p.ID
+			parent = &id
+		}
+		parentChanged := (c.ParentID != nil && *c.ParentID != uuid.Nil) != (parent != nil) ||
+			(parent != nil && *c.ParentID != *parent)
+		if parentChanged {
+			add(GraphViolationParentInvalid, "concept", c.ID, fmt.Sprintf("parent %s", c.ParentID))
+		} else if c.Depth != it.Depth {
+			add(GraphViolationDepthMismatch, "concept", c.ID, fmt.Sprintf("depth %d, want %d", c.Depth, it.Depth))
+		}
+		if parentChanged || c.Depth != it.Depth {
+			hierarchyFixes = append(hierarchyFixes, hierarchyFix{ID: c.ID, ParentID: parent, Depth: it.Depth})
+		}
+	}
+
+	// Canonical links must resolve to a live global root.
+	linked := make([]uuid.UUID, 0, len(live))
+	for _, c := range live {
+		if c.CanonicalConceptID != nil && *c.CanonicalConceptID != uuid.Nil {
+			linked = append(linked, *c.CanonicalConceptID)
+		}
+	}
+	roots, err := resolveCanonicalRoots(dbc, tx, linked)
+	if err != nil {
+		return fmt.Errorf("resolve canonical roots: %w", err)
+	}
+	rootIDs := make([]uuid.UUID, 0, len(roots))
+	for _, r := range roots {
+		rootIDs = append(rootIDs, r)
+	}
+	liveGlobal := map[uuid.UUID]bool{}
+	if len(rootIDs) > 0 {
+		var ids []uuid.UUID
+		if err := tx.Model(&types.Concept{}).Where("id IN ? AND scope = ? AND scope_id IS NULL", dedupeUUIDs(rootIDs), "global").Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("load canonical concepts: %w", err)
+		}
+		for _, id := range ids {
+			liveGlobal[id] = true
+		}
+	}
+	canonicalBroken := 0
+	for _, c := range live {
+		if c.CanonicalConceptID == nil || *c.CanonicalConceptID == uuid.Nil {
+			continue
+		}
+		cid := *c.CanonicalConceptID
+		root, ok := roots[cid]
+		switch {
+		case !ok || !liveGlobal[root]:
+			add(GraphViolationCanonicalMissing, "concept", c.ID, cid.String())
+			canonicalBroken++
+		case root != cid:
+			add(GraphViolationCanonicalNotRoot, "concept", c.ID, cid.String()+" -> "+root.String())
+			canonicalBroken++
+		}
+	}
+
+	// Evidence must cite existing chunks.
+	var evidence []*types.ConceptEvidence
+	if err := tx.Where("concept_id IN ?", allIDs).Order("id ASC").Find(&evidence).Error; err != nil {
+		return fmt.Errorf("load evidence: %w", err)
+	}
+	report.Evidence = len(evidence)
+	var badEvidence []uuid.UUID
+	if len(evidence) > 0 {
+		chunkIDs := make([]uuid.UUID, 0, len(evidence))
+		for _, e := range evidence {
+			chunkIDs = append(chunkIDs, e.MaterialChunkID)
+		}
+		var found []uuid.UUID
+		if err := tx.Model(&types.MaterialChunk{}).Where("id IN ?", dedupeUUIDs(chunkIDs)).Pluck("id", &found).Error; err != nil {
+			return fmt.Errorf("load chunks: %w", err)
+		}
+		exists := map[uuid.UUID]bool{}
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, e := range evidence {
+			if !exists[e.MaterialChunkID] {
+				add(GraphViolationEvidenceChunk, "concept_evidence", e.ID, e.MaterialChunkID.String())
+				badEvidence = append(badEvidence, e.ID)
+			}
+		}
+	}
+
+	sort.SliceStable(report.Violations, func(i, j int) bool { return report.Violations[i].Kind < report.Violations[j].Kind })
+	if !fix {
+		return nil
+	}
+
+	for kind, ids := range badEdges {
+		if err := tx.Model(&types.ConceptEdge{}).Where("id IN ?", ids).
+			Updates(map[string]any{"deleted_at": now, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("drop edges: %w", err)
+		}
+		report.Fixed[kind] += len(ids)
+	}
+	for _, f := range hierarchyFixes {
+		if err := tx.Model(&types.Concept{}).Where("id = ?", f.ID).
+			Updates(map[string]any{"parent_id": f.ParentID, "depth": f.Depth, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("repair hierarchy: %w", err)
+		}
+	}
+	if len(hierarchyFixes) > 0 {
+		report.Fixed["hierarchy"] = len(hierarchyFixes)
+	}
+	if canonicalBroken > 0 {
+		// Alias links move straight to their root; dangling links are cleared and re-resolved
+		// by key through the canonicalizer.
+		var relink []*types.Concept
+		for _, c := range live {
+			if c.CanonicalConceptID == nil || *c.CanonicalConceptID == uuid.Nil {
+				continue
+			}
+			root, ok := roots[*c.CanonicalConceptID]
+			switch {
+			case !ok || !liveGlobal[root]:
+				c.CanonicalConceptID = nil
+				relink = append(relink, c)
+			case root != *c.CanonicalConceptID:
+				if err := tx.Model(&types.Concept{}).Where("id = ?", c.ID).
+					Updates(map[string]any{"canonical_concept_id": root, "updated_at": now}).Error; err != nil {
+					return fmt.Errorf("relink canonical root: %w", err)
+				}
+			}
+		}
+		if len(relink) > 0 {
+			if _, err := canonicalizePathConcepts(dbc, tx,
+				repos.NewConceptRepo(tx, deps.Log),
+				repos.NewConceptRepresentationRepo(tx, deps.Log),
+				repos.NewConceptMappingOverrideRepo(tx, deps.Log),
+				relink, nil); err != nil {
+				return fmt.Errorf("canonicalize: %w", err)
+			}
+		}
+		report.Fixed["canonical"] = canonicalBroken
+	}
+	if len(badEvidence) > 0 {
+		if err := tx.Model(&types.ConceptEvidence{}).Where("id IN ?", badEvidence).
+			Updates(map[string]any{"deleted_at": now, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("drop evidence: %w", err)
+		}
+		report.Fixed[GraphViolationEvidenceChunk] = len(badEvidence)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestValidateConceptGraphReportsAndFixes(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	ctx := context.Background()
+
+	pathID := uuid.New()
+	root := &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &pathID, Key: "limits", Name: "Limits"}
+	child := &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &pathID, Key: "one_sided", Name: "One-sided", ParentID: &root.ID, Depth: 3}
+	missing := uuid.New()
+	orphan := &types.Concept{ID: uuid.New(), Scope: "path", ScopeID: &pathID, Key: "continuity", Name: "Continuity", ParentID: &missing, Depth: 1, CanonicalConceptID: &missing}
+	if err := tx.Create([]*types.Concept{root, child, orphan}).Error; err != nil {
+		t.Fatalf("seed concepts: %v", err)
+	}
+	if err := tx.Create([]*types.ConceptEdge{
+		{FromConceptID: root.ID, ToConceptID: child.ID, EdgeType: "prereq", Strength: 1},
+		{FromConceptID: root.ID, ToConceptID: root.ID, EdgeType: "related", Strength: 1},
+		{FromConceptID: child.ID, ToConceptID: missing, EdgeType: "prereq", Strength: 1},
+	}).Error; err != nil {
+		t.Fatalf("seed edges: %v", err)
+	}
+	if err := tx.Create(&types.ConceptEvidence{ConceptID: root.ID, MaterialChunkID: uuid.New()}).Error; err != nil {
+		t.Fatalf("seed evidence: %v", err)
+	}
+
+	deps := ConceptGraphValidateDeps{DB: tx, Log: testutil.Logger(t)}
+	report, err := ValidateConceptGraph(ctx, deps, pathID, false)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	want := map[string]int{
+		GraphViolationEdgeSelfLoop:       1,
+		GraphViolationEdgeMissingConcept: 1,
+		GraphViolationDepthMismatch:      1,
+		GraphViolationParentInvalid:      1,
+		GraphViolationCanonicalMissing:   1,
+		GraphViolationEvidenceChunk:      1,
+	}
+	got := report.Counts()
+	for k, n := range want {
+		if got[k] != n {
+			t.Fatalf("counts = %v, want %v", got, want)
+		}
+	}
+
+	if _, err := ValidateConceptGraph(ctx, deps, pathID, true); err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+	after, err := ValidateConceptGraph(ctx, deps, pathID, false)
+	if err != nil {
+		t.Fatalf("revalidate: %v", err)
+	}
+	if len(after.Violations) != 0 {
+		t.Fatalf("violations after fix = %+v", after.Violations)
+	}
+	var gotChild, gotOrphan types.Concept
+	tx.First(&gotChild, "id = ?", child.ID)
+	tx.First(&gotOrphan, "id = ?", orphan.ID)
+	if gotChild.Depth != 1 || gotOrphan.ParentID != nil || gotOrphan.Depth != 0 {
+		t.Fatalf("hierarchy child=%d orphan=%v/%d", gotChild.Depth, gotOrphan.ParentID, gotOrphan.Depth)
+	}
+	if gotOrphan.CanonicalConceptID == nil || *gotOrphan.CanonicalConceptID == missing {
+		t.Fatalf("orphan canonical = %v", gotOrphan.CanonicalConceptID)
+	}
+}
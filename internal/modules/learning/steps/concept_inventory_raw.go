@@ -0,0 +1,180 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	conceptInventoryRawGlobalArtifact = "concept_inventory_raw:global"
+	conceptInventoryRawFilePrefix     = "concept_inventory_raw:file:"
+)
+
+// conceptInventoryRawEnabled gates keeping every concept_inventory response as a learning
+// artifact, for offline normalization replays and prompt regression runs. Off by default: it
+// stores each model response per file and per path.
+func conceptInventoryRawEnabled() bool {
+	return envBool("CONCEPT_INVENTORY_RAW_ARTIFACTS_ENABLED", false)
+}
+
+type conceptInventoryRawCall struct {
+	Slice             *int            `json:"slice,omitempty"`
+	Retry             string          `json:"retry,omitempty"`
+	ExcerptChars      int             `json:"excerpt_chars"`
+	PromptVersion     int             `json:"prompt_version"`
+	PromptFingerprint string          `json:"prompt_fingerprint"`
+	Output            json.RawMessage `json:"output,omitempty"`
+	Error             string          `json:"error,omitempty"`
+}
+
+// conceptInventoryRawLog collects raw inventory outputs during one concept_graph_build run. A
+// nil log records nothing.
+type conceptInventoryRawLog struct {
+	mu              sync.Mutex
+	files           map[uuid.UUID][]conceptInventoryRawCall
+	filesNormalized map[uuid.UUID][]conceptInvItem
+	global          []conceptInventoryRawCall
+}
+
+func newConceptInventoryRawLog() *conceptInventoryRawLog {
+	if !conceptInventoryRawEnabled() {
+		return nil
+	}
+	return &conceptInventoryRawLog{
+		files:           map[uuid.UUID][]conceptInventoryRawCall{},
+		filesNormalized: map[uuid.UUID][]conceptInvItem{},
+	}
+}
+
+func newConceptInventoryRawCall(p prompts.Prompt, excerptChars int, retry string, inv *conceptInventoryOutput, err error) conceptInventoryRawCall {
+	call := conceptInventoryRawCall{
+		Retry:             retry,
+		ExcerptChars:      excerptChars,
+		PromptVersion:     p.Version,
+		PromptFingerprint: p.Fingerprint(),
+	}
+	if err != nil {
+		call.Error = err.Error()
+	} else if inv != nil {
+		// Marshal now: the caller goes on to clean up inv's concepts.
+		if b, merr := json.Marshal(inv); merr == nil {
+			call.Output = b
+		}
+	}
+	return call
+}
+
+func (l *conceptInventoryRawLog) recordFile(fileID uuid.UUID, p prompts.Prompt, excerptChars int, retry string, inv *conceptInventoryOutput, err error) {
+	if l == nil {
+		return
+	}
+	call := newConceptInventoryRawCall(p, excerptChars, retry, inv, err)
+	l.mu.Lock()
+	l.files[fileID] = append(l.files[fileID], call)
+	l.mu.Unlock()
+}
+
+func (l *conceptInventoryRawLog) recordFileNormalized(fileID uuid.UUID, concepts []conceptInvItem) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.filesNormalized[fileID] = append([]conceptInvItem(nil), concepts...)
+	l.mu.Unlock()
+}
+
+func (l *conceptInventoryRawLog) recordGlobal(slice int, p prompts.Prompt, excerptChars int, retry string, inv *conceptInventoryOutput, err error) {
+	if l == nil {
+		return
+	}
+	call := newConceptInventoryRawCall(p, excerptChars, retry, inv, err)
+	call.Slice = &slice
+	l.mu.Lock()
+	l.global = append(l.global, call)
+	l.mu.Unlock()
+}
+
+// persist upserts one artifact per inventoried file plus a global one holding the slice
+// outputs, the normalized inventory and the chunk ids normalization filtered citations by.
+// Failures are logged; raw outputs never fail the build.
+func (l *conceptInventoryRawLog) persist(ctx context.Context, repo repos.LearningArtifactRepo, log *logger.Logger, ownerID, setID, pathID uuid.UUID, normalized []conceptInvItem, allowedChunkIDs map[string]bool) {
+	if l == nil || repo == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	promptVersion := 0
+	for _, c := range l.global {
+		promptVersion = maxInt(promptVersion, c.PromptVersion)
+	}
+	for _, calls := range l.files {
+		for _, c := range calls {
+			promptVersion = maxInt(promptVersion, c.PromptVersion)
+		}
+	}
+
+	upsert := func(artifactType string, calls []conceptInventoryRawCall, meta map[string]any) {
+		meta["prompt"] = string(prompts.PromptConceptInventory)
+		meta["prompt_version"] = promptVersion
+		meta["schema_version"] = prompts.ConceptInventorySchemaVersion
+		meta["calls"] = calls
+		fingerprints := make([]string, 0, len(calls))
+		for _, c := range calls {
+			fingerprints = append(fingerprints, c.PromptFingerprint)
+		}
+		err := repo.Upsert(dbctx.Context{Ctx: ctx}, &types.LearningArtifact{
+			OwnerUserID:   ownerID,
+			MaterialSetID: setID,
+			PathID:        pathID,
+			ArtifactType:  artifactType,
+			InputHash:     content.HashBytes([]byte(strings.Join(fingerprints, "|"))),
+			Version:       promptVersion,
+			Metadata:      marshalMeta(meta),
+		})
+		if err != nil && log != nil {
+			log.Warn("concept inventory raw artifact upsert failed", "error", err, "artifact_type", artifactType, "path_id", pathID.String())
+		}
+	}
+
+	for fileID, calls := range l.files {
+		upsert(conceptInventoryRawFilePrefix+fileID.String(), calls, map[string]any{
+			"scope":               "file",
+			"file_id":             fileID.String(),
+			"normalized_concepts": l.filesNormalized[fileID],
+		})
+	}
+	if len(l.global) == 0 && len(normalized) == 0 {
+		return
+	}
+	allowed := make([]string, 0, len(allowedChunkIDs))
+	for id, ok := range allowedChunkIDs {
+		if ok {
+			allowed = append(allowed, id)
+		}
+	}
+	sort.Strings(allowed)
+	files := make([]string, 0, len(l.files))
+	for fileID := range l.files {
+		files = append(files, fileID.String())
+	}
+	sort.Strings(files)
+	upsert(conceptInventoryRawGlobalArtifact, l.global, map[string]any{
+		"scope":               "global",
+		"file_ids":            files,
+		"normalized_concepts": normalized,
+		"allowed_chunk_ids":   allowed,
+	})
+}
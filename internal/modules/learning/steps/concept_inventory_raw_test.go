@@ -0,0 +1,86 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type fakeArtifactRepo struct {
+	rows map[string]*types.LearningArtifact
+}
+
+func (r *fakeArtifactRepo) GetByKey(dbc dbctx.Context, ownerUserID, materialSetID, pathID uuid.UUID, artifactType string) (*types.LearningArtifact, error) {
+	return r.rows[artifactType], nil
+}
+
+func (r *fakeArtifactRepo) Upsert(dbc dbctx.Context, row *types.LearningArtifact) error {
+	r.rows[row.ArtifactType] = row
+	return nil
+}
+
+func TestConceptInventoryRawArtifacts(t *testing.T) {
+	if newConceptInventoryRawLog() != nil {
+		t.Fatalf("raw inventory artifacts enabled without the env flag")
+	}
+	t.Setenv("CONCEPT_INVENTORY_RAW_ARTIFACTS_ENABLED", "true")
+	raw := newConceptInventoryRawLog()
+
+	p := prompts.Prompt{Name: string(prompts.PromptConceptInventory), Version: 3, System: "sys", User: "[chunk_id=c1] hash tables"}
+	fileID := uuid.New()
+	inv := conceptInventoryOutput{
+		Version:  prompts.ConceptInventorySchemaVersion,
+		Concepts: []conceptInvItem{{Key: " Hash Tables ", Name: "Hash tables", Citations: []string{"c1", "c9"}}},
+	}
+	raw.recordFile(fileID, p, 40, "", nil, errors.New("context_length_exceeded"))
+	raw.recordFile(fileID, p, 20, "shorter", &inv, nil)
+	raw.recordFileNormalized(fileID, []conceptInvItem{{Key: "hash_tables", Name: "Hash tables", Citations: []string{"c1"}}})
+	raw.recordGlobal(0, p, 60, "", &inv, nil)
+	// The recorded output is a copy; later cleanup of inv must not leak into the artifact.
+	inv.Concepts[0].Key = "mutated"
+
+	repo := &fakeArtifactRepo{rows: map[string]*types.LearningArtifact{}}
+	raw.persist(context.Background(), repo, nil, uuid.New(), uuid.New(), uuid.New(),
+		[]conceptInvItem{{Key: "hash_tables", Name: "Hash tables"}}, map[string]bool{"c1": true})
+
+	fileRow := repo.rows[conceptInventoryRawFilePrefix+fileID.String()]
+	global := repo.rows[conceptInventoryRawGlobalArtifact]
+	if fileRow == nil || global == nil || len(repo.rows) != 2 {
+		t.Fatalf("artifacts = %v", repo.rows)
+	}
+	var meta struct {
+		PromptVersion int                       `json:"prompt_version"`
+		SchemaVersion int                       `json:"schema_version"`
+		Calls         []conceptInventoryRawCall `json:"calls"`
+		Normalized    []conceptInvItem          `json:"normalized_concepts"`
+	}
+	if err := json.Unmarshal(fileRow.Metadata, &meta); err != nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if meta.PromptVersion != p.Version || fileRow.Version != p.Version || meta.SchemaVersion != prompts.ConceptInventorySchemaVersion {
+		t.Fatalf("versions: meta=%+v row=%d", meta, fileRow.Version)
+	}
+	if len(meta.Calls) != 2 || meta.Calls[0].Error == "" || meta.Calls[1].Retry != "shorter" {
+		t.Fatalf("calls = %+v", meta.Calls)
+	}
+	var out conceptInventoryOutput
+	if err := json.Unmarshal(meta.Calls[1].Output, &out); err != nil || len(out.Concepts) != 1 {
+		t.Fatalf("raw output = %s, %v", meta.Calls[1].Output, err)
+	}
+	if got := out.Concepts[0]; got.Key != " Hash Tables " || len(got.Citations) != 2 || out.Version != prompts.ConceptInventorySchemaVersion {
+		t.Fatalf("raw output was not kept as returned: %+v", got)
+	}
+	if len(meta.Normalized) != 1 || meta.Normalized[0].Key != "hash_tables" {
+		t.Fatalf("normalized = %+v", meta.Normalized)
+	}
+	if fileRow.InputHash == "" {
+		t.Fatalf("missing input hash")
+	}
+}
@@ -0,0 +1,215 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+)
+
+type ConceptRecanonicalizeInput struct {
+	PathID uuid.UUID
+}
+
+type ConceptRecanonicalizeOutput struct {
+	PathID   uuid.UUID `json:"path_id"`
+	Concepts int       `json:"concepts"`
+	// SemanticMatches counts concept keys the semantic matcher tied to an existing canonical.
+	SemanticMatches int `json:"semantic_matches"`
+	// Relinked counts path concepts whose canonical_concept_id changed.
+	Relinked         int `json:"relinked"`
+	GlobalVectors    int `json:"global_vectors"`
+	EmbeddingsReused int `json:"embeddings_reused"`
+}
+
+// ConceptRecanonicalize re-links a built path's concepts to the current canonical concept
+// space without rebuilding its graph: it re-embeds the stored concepts (through the concept doc
+// cache, so unchanged concepts are not sent to the embedder), re-runs the semantic canonical
+// match and canonicalizePathConcepts under the per-path canonicalize lock, and re-upserts the
+// global concept vectors. Concepts, edges and evidence are left as they are.
+//
+// Content-type threshold adjustments are not applied: the file signals they come from are
+// not reloaded, so the configured thresholds are used as is.
+func ConceptRecanonicalize(ctx context.Context, deps ConceptGraphBuildDeps, in ConceptRecanonicalizeInput) (ConceptRecanonicalizeOutput, error) {
+	out := ConceptRecanonicalizeOutput{PathID: in.PathID}
+	if deps.DB == nil || deps.Log == nil || deps.Concepts == nil || deps.AI == nil {
+		return out, fmt.Errorf("concept_recanonicalize: missing deps")
+	}
+	if in.PathID == uuid.Nil {
+		return out, fmt.Errorf("concept_recanonicalize: missing path_id")
+	}
+	pathID := in.PathID
+
+	rows, err := deps.Concepts.GetByScope(dbctx.Context{Ctx: ctx}, "path", &pathID)
+	if err != nil {
+		return out, err
+	}
+	concepts := make([]*types.Concept, 0, len(rows))
+	for _, c := range rows {
+		if c != nil && c.ID != uuid.Nil && strings.TrimSpace(c.Key) != "" {
+			concepts = append(concepts, c)
+		}
+	}
+	out.Concepts = len(concepts)
+	if len(concepts) == 0 {
+		return out, nil
+	}
+
+	items := make([]conceptInvItem, 0, len(concepts))
+	docs := make([]string, 0, len(concepts))
+	for _, c := range concepts {
+		item := conceptInvItemFromRow(c)
+		items = append(items, item)
+		// Same doc text as concept_graph_build, so the embedding cache hits.
+		doc := strings.TrimSpace(item.Name + "\n" + item.Summary + "\n" + strings.Join(item.KeyPoints, "\n"))
+		if doc == "" {
+			doc = item.Key
+		}
+		docs = append(docs, doc)
+	}
+
+	embs, stats, err := embedConceptDocsIncremental(ctx, deps.Log, deps.DocEmbeddings, conceptDocEmbedModel(), docs, func(ctx context.Context, docs []string) ([][]float32, error) {
+		return embedConceptDocsBatched(ctx, deps, pathID, docs)
+	})
+	if err != nil {
+		return out, err
+	}
+	out.EmbeddingsReused = stats.Reused
+
+	signals := AdaptiveSignals{ConceptCount: len(concepts)}
+	semanticMatchByKey, _ := semanticMatchCanonicalConcepts(ctx, deps, items, embs, signals, "", false, canonicalConceptMinSimilarity(), nil)
+	out.SemanticMatches = len(semanticMatchByKey)
+
+	before := make(map[uuid.UUID]uuid.UUID, len(concepts))
+	for _, c := range concepts {
+		if c.CanonicalConceptID != nil {
+			before[c.ID] = *c.CanonicalConceptID
+		}
+	}
+	if err := deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+		if err := advisoryXactLock(tx, "concept_canonicalize", pathID); err != nil {
+			return err
+		}
+		_, err := canonicalizePathConcepts(dbc, tx, deps.Concepts, deps.Reps, deps.Overrides, concepts, semanticMatchByKey)
+		return err
+	}); err != nil {
+		return out, fmt.Errorf("concept_recanonicalize: canonicalize: %w", err)
+	}
+	for _, c := range concepts {
+		if c.CanonicalConceptID != nil && before[c.ID] != *c.CanonicalConceptID {
+			out.Relinked++
+		}
+	}
+
+	if deps.Vec != nil {
+		globalNS := index.ConceptsNamespace("global", nil)
+		vectors := canonicalConceptVectors(concepts, embs)
+		if len(vectors) > 0 {
+			if err := deps.Vec.Upsert(ctx, globalNS, vectors); err != nil {
+				deps.Log.WarnThrottled("concept_recanonicalize.pinecone_global_upsert", time.Minute, "pinecone global concept upsert failed (continuing)", "namespace", globalNS, "err", err.Error())
+			} else {
+				out.GlobalVectors = len(vectors)
+			}
+		}
+	}
+	return out, nil
+}
+
+// conceptInvItemFromRow rebuilds the inventory item a persisted path concept came from.
+func conceptInvItemFromRow(c *types.Concept) conceptInvItem {
+	item := conceptInvItem{
+		Key:     strings.TrimSpace(strings.ToLower(c.Key)),
+		Name:    strings.TrimSpace(c.Name),
+		Summary: strings.TrimSpace(c.Summary),
+		Depth:   c.Depth,
+	}
+	if len(c.KeyPoints) > 0 {
+		var pts []string
+		if json.Unmarshal(c.KeyPoints, &pts) == nil {
+			item.KeyPoints = pts
+		}
+	}
+	if len(c.Metadata) > 0 {
+		var meta map[string]any
+		if json.Unmarshal(c.Metadata, &meta) == nil && meta != nil {
+			item.Aliases = dedupeStrings(stringSliceFromAny(meta["aliases"]))
+		}
+	}
+	return item
+}
+
+func embedConceptDocsBatched(ctx context.Context, deps ConceptGraphBuildDeps, pathID uuid.UUID, docs []string) ([][]float32, error) {
+	batchSize := envIntAllowZero("CONCEPT_GRAPH_EMBED_BATCH_SIZE", 128)
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	out := make([][]float32, 0, len(docs))
+	for start := 0; start < len(docs); start += batchSize {
+		end := start + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		timer := llmTimer(ctx, deps.Log, "concept_embeddings", map[string]any{
+			"stage":       "concept_recanonicalize",
+			"path_id":     pathID.String(),
+			"batch_size":  end - start,
+			"batch_start": start,
+		})
+		v, err := deps.AI.Embed(ctx, docs[start:end])
+		timer(err)
+		if err != nil {
+			return nil, err
+		}
+		if len(v) != end-start {
+			return nil, fmt.Errorf("concept_recanonicalize: embedding count mismatch (got %d want %d)", len(v), end-start)
+		}
+		out = append(out, v...)
+	}
+	return out, nil
+}
+
+// canonicalConceptVectors builds one global-namespace vector per canonical concept linked from
+// concepts (vector_id = "concept:<canonical_uuid>"), so new paths can semantically match
+// previously learned concepts even when their keys differ. embs is parallel to concepts.
+func canonicalConceptVectors(concepts []*types.Concept, embs [][]float32) []pc.Vector {
+	out := make([]pc.Vector, 0, len(concepts))
+	seen := map[string]bool{}
+	for i, c := range concepts {
+		if c == nil || i >= len(embs) || len(embs[i]) == 0 || c.CanonicalConceptID == nil || *c.CanonicalConceptID == uuid.Nil {
+			continue
+		}
+		cid := *c.CanonicalConceptID
+		vid := "concept:" + cid.String()
+		if seen[vid] {
+			continue
+		}
+		seen[vid] = true
+		name := c.Key
+		if strings.TrimSpace(c.Name) != "" {
+			name = c.Name
+		}
+		out = append(out, pc.Vector{
+			ID:     vid,
+			Values: embs[i],
+			Metadata: map[string]any{
+				"type":         "concept",
+				"scope":        "global",
+				"canonical":    true,
+				"concept_id":   cid.String(),
+				"observedKey":  c.Key,
+				"observedName": name,
+			},
+		})
+	}
+	return out
+}
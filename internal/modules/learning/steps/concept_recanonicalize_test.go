@@ -0,0 +1,47 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestConceptInvItemFromRow(t *testing.T) {
+	row := &types.Concept{
+		Key:       " Gradient_Descent ",
+		Name:      "Gradient descent",
+		Summary:   "Iterative minimization.",
+		KeyPoints: datatypes.JSON(`["step size","convergence"]`),
+		Metadata:  datatypes.JSON(`{"aliases":["GD","gd","GD"],"importance":8}`),
+	}
+	item := conceptInvItemFromRow(row)
+	if item.Key != "gradient_descent" || item.Name != "Gradient descent" || len(item.KeyPoints) != 2 {
+		t.Fatalf("item = %+v", item)
+	}
+	if len(item.Aliases) != 2 {
+		t.Fatalf("aliases = %v, want deduped", item.Aliases)
+	}
+}
+
+func TestCanonicalConceptVectorsOnePerCanonical(t *testing.T) {
+	shared, other := uuid.New(), uuid.New()
+	concepts := []*types.Concept{
+		{Key: "a", Name: "A", CanonicalConceptID: &shared},
+		{Key: "a_alias", CanonicalConceptID: &shared},
+		{Key: "b", CanonicalConceptID: &other},
+		{Key: "unlinked"},
+	}
+	embs := [][]float32{{1, 0}, {0, 1}, nil, {1, 1}}
+
+	vectors := canonicalConceptVectors(concepts, embs)
+	if len(vectors) != 1 {
+		t.Fatalf("vectors = %+v, want only the linked concept with an embedding", vectors)
+	}
+	v := vectors[0]
+	if v.ID != "concept:"+shared.String() || v.Values[0] != 1 || v.Metadata["observedName"] != "A" {
+		t.Fatalf("vector = %+v", v)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/prompts"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/errclass"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/services"
@@ -124,7 +125,7 @@ func CoverageCoherenceAudit(ctx context.Context, deps CoverageCoherenceAuditDeps
 		return out, err
 	}
 	obj, err := deps.AI.GenerateJSON(ctx, p.System, p.User, p.SchemaName, p.Schema)
-	if err != nil && isContextLengthExceeded(err) {
+	if err != nil && errclass.IsContextLength(err) {
 		p2, pErr := prompts.Build(prompts.PromptCoverageAndCoheranceAudit, prompts.Input{
 			CurriculumSpecJSON: curriculumSpecJSON,
 			ConceptsJSON:       string(conceptsJSON),
@@ -182,14 +183,6 @@ func CoverageCoherenceAudit(ctx context.Context, deps CoverageCoherenceAuditDeps
 	return out, nil
 }
 
-func isContextLengthExceeded(err error) bool {
-	if err == nil {
-		return false
-	}
-	s := strings.ToLower(err.Error())
-	return strings.Contains(s, "context_length_exceeded") || strings.Contains(s, "exceeds the context window")
-}
-
 func compactConceptsForAudit(in []*types.Concept) []map[string]any {
 	out := make([]map[string]any, 0, len(in))
 	for _, c := range in {
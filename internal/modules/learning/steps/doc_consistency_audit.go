@@ -0,0 +1,434 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// docRevisionWriteSlack is how much later than its latest revision a doc may have been written
+// and still count as produced by it: the revision and the doc update share a transaction, but
+// each takes its own timestamp.
+const docRevisionWriteSlack = time.Minute
+
+type DocConsistencyAuditDeps struct {
+	DB   *gorm.DB
+	Log  *logger.Logger
+	Runs repos.DocConsistencyAuditRunRepo
+}
+
+type DocConsistencyAuditInput struct {
+	JobID *uuid.UUID
+	// MaxIDs caps the violating IDs collected (and repaired) per checker; ScanLimit caps the
+	// rows a scanning checker reads; SampleSize is how many IDs the report keeps per checker.
+	MaxIDs     int
+	ScanLimit  int
+	SampleSize int
+}
+
+// DocConsistencyCheckResult is one checker's entry in the audit report.
+type DocConsistencyCheckResult struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Count       int         `json:"count"`
+	SampleIDs   []uuid.UUID `json:"sample_ids,omitempty"`
+	// Partial reports that the scan stopped at its limit, so Count is a lower bound.
+	Partial       bool `json:"partial,omitempty"`
+	PreviousCount *int `json:"previous_count,omitempty"`
+	Increased     bool `json:"increased,omitempty"`
+	// Repairable checkers list their repair as a suggested action unless auto-repair is on.
+	Repairable      bool   `json:"repairable"`
+	Repaired        int    `json:"repaired,omitempty"`
+	RepairError     string `json:"repair_error,omitempty"`
+	SuggestedAction string `json:"suggested_action,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+type DocConsistencyAuditOutput struct {
+	RunID      uuid.UUID                   `json:"run_id"`
+	Checks     []DocConsistencyCheckResult `json:"checks"`
+	Violations int                         `json:"violations"`
+	Repaired   int                         `json:"repaired"`
+	Increased  []string                    `json:"increased,omitempty"`
+	AutoRepair bool                        `json:"auto_repair"`
+}
+
+// docConsistencyFindings is what a checker returns: the number of violations it found and up to
+// maxIDs of the violating row IDs.
+type docConsistencyFindings struct {
+	Count   int
+	IDs     []uuid.UUID
+	Partial bool
+}
+
+type docConsistencyLimits struct {
+	MaxIDs    int
+	ScanLimit int
+}
+
+// docConsistencyChecker is one invariant. Check must only read; Repair, when set, must be safe
+// to run unattended on the IDs Check returned (it re-verifies each row before changing it).
+type docConsistencyChecker struct {
+	Name            string
+	Description     string
+	SuggestedAction string
+	Check           func(ctx context.Context, db *gorm.DB, lim docConsistencyLimits) (docConsistencyFindings, error)
+	Repair          func(ctx context.Context, db *gorm.DB, ids []uuid.UUID) (int, error)
+}
+
+// docConsistencyCheckers is the audit suite, in report order.
+var docConsistencyCheckers = []docConsistencyChecker{
+	{
+		Name:            "variant_base_doc_node_mismatch",
+		Description:     "variant base_doc_id points at the doc of a different path node",
+		SuggestedAction: "retire the variant (it was derived from another node's doc) and regenerate it from the node's doc",
+		Check: sqlDocConsistencyCheck(
+			"learning_node_doc_variant v JOIN learning_node_doc d ON d.id = v.base_doc_id",
+			"d.path_node_id <> v.path_node_id",
+			"v.id",
+		),
+	},
+	{
+		Name:            "exposure_variant_kind_base",
+		Description:     "exposure has variant_id set but variant_kind 'base'",
+		SuggestedAction: "set the exposure's variant_kind to its variant's kind",
+		Check: sqlDocConsistencyCheck(
+			"doc_variant_exposure e",
+			"e.variant_id IS NOT NULL AND e.variant_kind = 'base'",
+			"e.id",
+		),
+		Repair: repairExposureVariantKind,
+	},
+	{
+		Name:            "exposure_node_mismatch",
+		Description:     "exposure path_node_id differs from its variant's or base doc's node",
+		SuggestedAction: "exclude the exposure from variant outcome attribution and trace the writer that recorded it",
+		Check: sqlDocConsistencyCheck(
+			"doc_variant_exposure e LEFT JOIN learning_node_doc_variant v ON v.id = e.variant_id LEFT JOIN learning_node_doc d ON d.id = e.base_doc_id",
+			"(v.id IS NOT NULL AND v.path_node_id <> e.path_node_id) OR (d.id IS NOT NULL AND d.path_node_id <> e.path_node_id)",
+			"e.id",
+		),
+	},
+	{
+		Name:            "doc_content_hash_mismatch",
+		Description:     "doc content_hash does not match a re-canonicalization of its doc_json",
+		SuggestedAction: "recompute content_hash from doc_json",
+		Check:           contentHashDocConsistencyCheck("learning_node_doc"),
+		Repair:          contentHashDocConsistencyRepair("learning_node_doc"),
+	},
+	{
+		Name:            "variant_content_hash_mismatch",
+		Description:     "variant content_hash does not match a re-canonicalization of its doc_json",
+		SuggestedAction: "recompute content_hash from doc_json",
+		Check:           contentHashDocConsistencyCheck("learning_node_doc_variant"),
+		Repair:          contentHashDocConsistencyRepair("learning_node_doc_variant"),
+	},
+	{
+		Name:            "revision_after_hash_mismatch",
+		Description:     "a doc's latest full-doc revision does not hash to the doc it produced",
+		SuggestedAction: "compare the revision's after_json with the doc and find the write that bypassed the revision log",
+		Check:           checkRevisionAfterHash,
+	},
+}
+
+// DocConsistencyAudit runs every invariant checker, persists the report as a
+// DocConsistencyAuditRun, and logs at error level for each checker whose count went up since
+// the previous run. Repairs only run when DOC_CONSISTENCY_AUTO_REPAIR is set; otherwise they
+// are listed as suggested actions.
+func DocConsistencyAudit(ctx context.Context, deps DocConsistencyAuditDeps, in DocConsistencyAuditInput) (DocConsistencyAuditOutput, error) {
+	return runDocConsistencyAudit(ctx, deps, in, docConsistencyCheckers)
+}
+
+func runDocConsistencyAudit(ctx context.Context, deps DocConsistencyAuditDeps, in DocConsistencyAuditInput, checkers []docConsistencyChecker) (DocConsistencyAuditOutput, error) {
+	out := DocConsistencyAuditOutput{AutoRepair: envutil.Bool("DOC_CONSISTENCY_AUTO_REPAIR", false)}
+	if deps.DB == nil || deps.Log == nil || deps.Runs == nil {
+		return out, fmt.Errorf("doc_consistency_audit: missing deps")
+	}
+	if in.MaxIDs <= 0 {
+		in.MaxIDs = envutil.Int("DOC_CONSISTENCY_AUDIT_MAX_IDS", 1000)
+	}
+	if in.ScanLimit <= 0 {
+		in.ScanLimit = envutil.Int("DOC_CONSISTENCY_AUDIT_SCAN_LIMIT", 50000)
+	}
+	if in.SampleSize <= 0 {
+		in.SampleSize = 20
+	}
+	lim := docConsistencyLimits{MaxIDs: in.MaxIDs, ScanLimit: in.ScanLimit}
+
+	dbc := dbctx.Context{Ctx: ctx}
+	previous := map[string]int{}
+	prevRun, err := deps.Runs.GetLatest(dbc)
+	if err != nil {
+		return out, err
+	}
+	if prevRun != nil {
+		var prev DocConsistencyAuditOutput
+		if err := json.Unmarshal(prevRun.ReportJSON, &prev); err == nil {
+			for _, c := range prev.Checks {
+				if c.Error == "" {
+					previous[c.Name] = c.Count
+				}
+			}
+		}
+	}
+
+	db := deps.DB.WithContext(ctx)
+	for _, checker := range checkers {
+		res := DocConsistencyCheckResult{
+			Name:        checker.Name,
+			Description: checker.Description,
+			Repairable:  checker.Repair != nil,
+		}
+		found, err := checker.Check(ctx, db, lim)
+		if err != nil {
+			res.Error = err.Error()
+			deps.Log.Warn("doc_consistency_audit: check failed", "check", checker.Name, "error", err)
+			out.Checks = append(out.Checks, res)
+			continue
+		}
+		res.Count = found.Count
+		res.Partial = found.Partial
+		res.SampleIDs = found.IDs
+		if len(res.SampleIDs) > in.SampleSize {
+			res.SampleIDs = res.SampleIDs[:in.SampleSize]
+		}
+		if prev, ok := previous[checker.Name]; ok {
+			res.PreviousCount = &prev
+			res.Increased = res.Count > prev
+		}
+		if res.Increased {
+			out.Increased = append(out.Increased, checker.Name)
+			deps.Log.ErrorThrottled("doc_consistency_audit.increased."+checker.Name, time.Hour, "doc consistency violations increased",
+				"check", checker.Name, "previous", *res.PreviousCount, "count", res.Count, "sample_ids", res.SampleIDs)
+		}
+		if res.Count > 0 {
+			switch {
+			case checker.Repair != nil && out.AutoRepair && len(found.IDs) > 0:
+				n, err := checker.Repair(ctx, db, found.IDs)
+				res.Repaired = n
+				if err != nil {
+					res.RepairError = err.Error()
+					deps.Log.Warn("doc_consistency_audit: repair failed", "check", checker.Name, "repaired", n, "error", err)
+				}
+			default:
+				res.SuggestedAction = checker.SuggestedAction
+			}
+		}
+		out.Violations += res.Count
+		out.Repaired += res.Repaired
+		out.Checks = append(out.Checks, res)
+	}
+
+	report, err := json.Marshal(out)
+	if err != nil {
+		return out, err
+	}
+	row := &types.DocConsistencyAuditRun{
+		JobID:      in.JobID,
+		Checks:     len(out.Checks),
+		Violations: out.Violations,
+		Repaired:   out.Repaired,
+		AutoRepair: out.AutoRepair,
+		ReportJSON: datatypes.JSON(report),
+	}
+	if err := deps.Runs.Create(dbc, row); err != nil {
+		return out, err
+	}
+	out.RunID = row.ID
+	deps.Log.Info("doc_consistency_audit completed", "run_id", row.ID, "violations", out.Violations, "repaired", out.Repaired, "increased", out.Increased)
+	return out, nil
+}
+
+// sqlDocConsistencyCheck builds a checker over from/where that counts every violation and
+// collects up to MaxIDs of idCol.
+func sqlDocConsistencyCheck(from, where, idCol string) func(context.Context, *gorm.DB, docConsistencyLimits) (docConsistencyFindings, error) {
+	return func(ctx context.Context, db *gorm.DB, lim docConsistencyLimits) (docConsistencyFindings, error) {
+		var out docConsistencyFindings
+		var n int64
+		if err := db.Table(from).Where(where).Count(&n).Error; err != nil {
+			return out, err
+		}
+		out.Count = int(n)
+		if n == 0 {
+			return out, nil
+		}
+		if err := db.Table(from).Where(where).Order(idCol).Limit(lim.MaxIDs).Pluck(idCol, &out.IDs).Error; err != nil {
+			return out, err
+		}
+		return out, nil
+	}
+}
+
+// nodeDocContentHash is the content hash the doc writers store for doc_json.
+func nodeDocContentHash(docJSON []byte) (string, error) {
+	canon, err := content.CanonicalizeJSON(docJSON)
+	if err != nil {
+		return "", err
+	}
+	return content.NodeDocContentHash(canon), nil
+}
+
+type docHashRow struct {
+	ID          uuid.UUID
+	DocJSON     []byte
+	ContentHash string
+}
+
+// contentHashDocConsistencyCheck scans table (learning_node_doc or learning_node_doc_variant) in
+// ID order, up to ScanLimit rows. Rows whose doc_json does not parse count as violations too.
+func contentHashDocConsistencyCheck(table string) func(context.Context, *gorm.DB, docConsistencyLimits) (docConsistencyFindings, error) {
+	return func(ctx context.Context, db *gorm.DB, lim docConsistencyLimits) (docConsistencyFindings, error) {
+		var out docConsistencyFindings
+		const batch = 500
+		scanned := 0
+		cursor := uuid.Nil
+		for scanned < lim.ScanLimit {
+			n := min(batch, lim.ScanLimit-scanned)
+			var rows []docHashRow
+			if err := db.Table(table).Select("id, doc_json, content_hash").
+				Where("id > ?", cursor).Order("id").Limit(n).
+				Scan(&rows).Error; err != nil {
+				return out, err
+			}
+			for _, r := range rows {
+				if hash, err := nodeDocContentHash(r.DocJSON); err != nil || hash != r.ContentHash {
+					out.Count++
+					if len(out.IDs) < lim.MaxIDs {
+						out.IDs = append(out.IDs, r.ID)
+					}
+				}
+				cursor = r.ID
+			}
+			scanned += len(rows)
+			if len(rows) < n {
+				return out, nil
+			}
+		}
+		out.Partial = true
+		return out, nil
+	}
+}
+
+// contentHashDocConsistencyRepair recomputes content_hash for the listed rows under a row lock.
+// Rows whose doc_json does not parse are left alone.
+func contentHashDocConsistencyRepair(table string) func(context.Context, *gorm.DB, []uuid.UUID) (int, error) {
+	return func(ctx context.Context, db *gorm.DB, ids []uuid.UUID) (int, error) {
+		repaired := 0
+		for _, id := range ids {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var row docHashRow
+				if err := tx.Table(table).Select("id, doc_json, content_hash").
+					Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("id = ?", id).Take(&row).Error; err != nil {
+					return err
+				}
+				hash, err := nodeDocContentHash(row.DocJSON)
+				if err != nil || hash == row.ContentHash {
+					return nil
+				}
+				res := tx.Table(table).Where("id = ?", id).Update("content_hash", hash)
+				if res.Error == nil && res.RowsAffected > 0 {
+					repaired++
+				}
+				return res.Error
+			})
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return repaired, err
+			}
+		}
+		return repaired, nil
+	}
+}
+
+// repairExposureVariantKind copies the variant's kind onto exposures recorded as 'base' despite
+// naming a variant. Variants whose own kind is 'base' (or that no longer exist) are skipped.
+func repairExposureVariantKind(ctx context.Context, db *gorm.DB, ids []uuid.UUID) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	res := db.Exec(`
+UPDATE doc_variant_exposure e
+SET variant_kind = v.variant_kind
+FROM learning_node_doc_variant v
+WHERE v.id = e.variant_id
+  AND e.id IN ?
+  AND e.variant_kind = 'base'
+  AND v.variant_kind <> 'base'
+`, ids)
+	return int(res.RowsAffected), res.Error
+}
+
+type revisionHashRow struct {
+	ID           uuid.UUID
+	DocID        uuid.UUID
+	AfterJSON    []byte
+	CreatedAt    time.Time
+	DocHash      string
+	DocUpdatedAt time.Time
+}
+
+// checkRevisionAfterHash compares each doc with its latest succeeded revision, when that
+// revision recorded the full doc (block edits record only the block) and nothing wrote the doc
+// after it. Revision IDs are reported.
+func checkRevisionAfterHash(ctx context.Context, db *gorm.DB, lim docConsistencyLimits) (docConsistencyFindings, error) {
+	var out docConsistencyFindings
+	const batch = 500
+	scanned := 0
+	cursor := uuid.Nil
+	for scanned < lim.ScanLimit {
+		n := min(batch, lim.ScanLimit-scanned)
+		var rows []revisionHashRow
+		if err := db.Raw(`
+SELECT DISTINCT ON (r.doc_id)
+  r.id, r.doc_id, r.after_json, r.created_at,
+  d.content_hash AS doc_hash, d.updated_at AS doc_updated_at
+FROM learning_node_doc_revision r
+JOIN learning_node_doc d ON d.id = r.doc_id
+WHERE r.status = 'succeeded' AND r.doc_id > ?
+ORDER BY r.doc_id, r.created_at DESC, r.id DESC
+LIMIT ?
+`, cursor, n).Scan(&rows).Error; err != nil {
+			return out, err
+		}
+		for _, r := range rows {
+			cursor = r.DocID
+			if r.DocUpdatedAt.After(r.CreatedAt.Add(docRevisionWriteSlack)) || !isFullNodeDocJSON(r.AfterJSON) {
+				continue
+			}
+			if hash, err := nodeDocContentHash(r.AfterJSON); err != nil || hash != r.DocHash {
+				out.Count++
+				if len(out.IDs) < lim.MaxIDs {
+					out.IDs = append(out.IDs, r.ID)
+				}
+			}
+		}
+		scanned += len(rows)
+		if len(rows) < n {
+			return out, nil
+		}
+	}
+	out.Partial = true
+	return out, nil
+}
+
+func isFullNodeDocJSON(raw []byte) bool {
+	var top map[string]json.RawMessage
+	if json.Unmarshal(raw, &top) != nil {
+		return false
+	}
+	_, ok := top["blocks"]
+	return ok
+}
@@ -0,0 +1,209 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const (
+	auditDocJSON      = `{"schema_version":1,"title":"Loops","blocks":[{"id":"p1","type":"paragraph","md":"A for loop repeats."}]}`
+	auditOtherDocJSON = `{"schema_version":1,"title":"Loops","blocks":[{"id":"p1","type":"paragraph","md":"A while loop repeats."}]}`
+)
+
+// auditSeed holds one violating and one clean row ID per checker.
+type auditSeed struct {
+	bad   map[string]uuid.UUID
+	clean map[string]uuid.UUID
+	// hashDoc and kindExposure are the rows the auto-repairs fix.
+	hashDoc      uuid.UUID
+	kindExposure uuid.UUID
+}
+
+func seedAuditViolations(t *testing.T, tx *gorm.DB) auditSeed {
+	t.Helper()
+	hash, err := nodeDocContentHash([]byte(auditDocJSON))
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	userID, pathID := uuid.New(), uuid.New()
+	nodeA, nodeB, nodeC, nodeD := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	doc := func(node uuid.UUID, contentHash string) *types.LearningNodeDoc {
+		return &types.LearningNodeDoc{
+			ID: uuid.New(), UserID: userID, PathID: pathID, PathNodeID: node, SchemaVersion: 1,
+			DocJSON: datatypes.JSON(auditDocJSON), ContentHash: contentHash, SourcesHash: "s",
+			CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	variant := func(node, base uuid.UUID, contentHash string) *types.LearningNodeDocVariant {
+		return &types.LearningNodeDocVariant{
+			ID: uuid.New(), UserID: userID, PathID: pathID, PathNodeID: node, BaseDocID: &base,
+			VariantKind: "progressive", PolicyVersion: "v1", SchemaVersion: 1, SnapshotID: uuid.NewString(),
+			DocJSON: datatypes.JSON(auditDocJSON), ContentHash: contentHash, SourcesHash: "s", Status: "active",
+			CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	exposure := func(node uuid.UUID, variantID *uuid.UUID, kind string) *types.DocVariantExposure {
+		return &types.DocVariantExposure{
+			ID: uuid.New(), UserID: userID, PathID: pathID, PathNodeID: node, VariantID: variantID,
+			VariantKind: kind, ExposureKind: "served", CreatedAt: now,
+		}
+	}
+	revision := func(d *types.LearningNodeDoc, after string) *types.LearningNodeDocRevision {
+		return &types.LearningNodeDocRevision{
+			ID: uuid.New(), DocID: d.ID, UserID: userID, PathID: pathID, PathNodeID: d.PathNodeID,
+			BlockID: "p1", BlockType: "paragraph", Operation: "rewrite", CitationPolicy: "reuse_only",
+			BeforeJSON: datatypes.JSON(auditDocJSON), AfterJSON: datatypes.JSON(after), Status: "succeeded",
+			CreatedAt: now,
+		}
+	}
+
+	docA, docStale, docC, docD := doc(nodeA, hash), doc(nodeB, "stale"), doc(nodeC, hash), doc(nodeD, hash)
+	wrongBase := variant(nodeC, docA.ID, hash)
+	goodVariant := variant(nodeA, docA.ID, hash)
+	staleVariant := variant(nodeA, docA.ID, "stale")
+	goodID, staleID := goodVariant.ID, staleVariant.ID
+	kindBase := exposure(nodeA, &goodID, "base")
+	goodExposure := exposure(nodeA, &goodID, "progressive")
+	wrongNode := exposure(nodeC, &staleID, "progressive")
+	revMismatch := revision(docA, auditOtherDocJSON)
+	revMatch := revision(docC, auditDocJSON)
+	// A block edit records only the block; it is not compared with the doc.
+	revBlock := revision(docD, `{"id":"p1","type":"paragraph","md":"other"}`)
+
+	for _, rows := range []any{
+		[]*types.LearningNodeDoc{docA, docStale, docC, docD},
+		[]*types.LearningNodeDocVariant{wrongBase, goodVariant, staleVariant},
+		[]*types.DocVariantExposure{kindBase, goodExposure, wrongNode},
+		[]*types.LearningNodeDocRevision{revMismatch, revMatch, revBlock},
+	} {
+		if err := tx.Create(rows).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	return auditSeed{
+		bad: map[string]uuid.UUID{
+			"variant_base_doc_node_mismatch": wrongBase.ID,
+			"exposure_variant_kind_base":     kindBase.ID,
+			"exposure_node_mismatch":         wrongNode.ID,
+			"doc_content_hash_mismatch":      docStale.ID,
+			"variant_content_hash_mismatch":  staleVariant.ID,
+			"revision_after_hash_mismatch":   revMismatch.ID,
+		},
+		clean: map[string]uuid.UUID{
+			"variant_base_doc_node_mismatch": goodVariant.ID,
+			"exposure_variant_kind_base":     goodExposure.ID,
+			"exposure_node_mismatch":         goodExposure.ID,
+			"doc_content_hash_mismatch":      docA.ID,
+			"variant_content_hash_mismatch":  goodVariant.ID,
+			"revision_after_hash_mismatch":   revMatch.ID,
+		},
+		hashDoc:      docStale.ID,
+		kindExposure: kindBase.ID,
+	}
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDocConsistencyCheckersFindSeededViolations(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	seed := seedAuditViolations(t, tx)
+
+	lim := docConsistencyLimits{MaxIDs: 1 << 20, ScanLimit: 1 << 30}
+	for _, checker := range docConsistencyCheckers {
+		found, err := checker.Check(context.Background(), tx, lim)
+		if err != nil {
+			t.Fatalf("%s: %v", checker.Name, err)
+		}
+		if !containsUUID(found.IDs, seed.bad[checker.Name]) {
+			t.Fatalf("%s missed the seeded violation (found %d)", checker.Name, found.Count)
+		}
+		if containsUUID(found.IDs, seed.clean[checker.Name]) {
+			t.Fatalf("%s flagged a consistent row", checker.Name)
+		}
+		if found.Count < len(found.IDs) || found.Partial {
+			t.Fatalf("%s: findings = %+v", checker.Name, found)
+		}
+	}
+}
+
+func TestDocConsistencyAuditAlertsAndGatesRepairs(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	ctx := context.Background()
+	seed := seedAuditViolations(t, tx)
+
+	runs := repolearning.NewDocConsistencyAuditRunRepo(tx, log)
+	zero := DocConsistencyAuditOutput{}
+	for _, c := range docConsistencyCheckers {
+		zero.Checks = append(zero.Checks, DocConsistencyCheckResult{Name: c.Name})
+	}
+	if err := runs.Create(dbctx.Context{Ctx: ctx}, &types.DocConsistencyAuditRun{
+		Checks: len(zero.Checks), ReportJSON: mustJSON(zero), CreatedAt: time.Now().UTC().Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("seed previous run: %v", err)
+	}
+	deps := DocConsistencyAuditDeps{DB: tx, Log: log, Runs: runs}
+
+	out, err := DocConsistencyAudit(ctx, deps, DocConsistencyAuditInput{})
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if out.AutoRepair || out.Repaired != 0 || len(out.Increased) != len(docConsistencyCheckers) {
+		t.Fatalf("out = %+v, want every check increased and nothing repaired", out)
+	}
+	for _, c := range out.Checks {
+		if c.PreviousCount == nil || *c.PreviousCount != 0 || c.SuggestedAction == "" {
+			t.Fatalf("check = %+v, want a suggested action against the previous count", c)
+		}
+	}
+	var stale types.LearningNodeDoc
+	if err := tx.First(&stale, "id = ?", seed.hashDoc).Error; err != nil || stale.ContentHash != "stale" {
+		t.Fatalf("doc repaired without the flag: %v %q", err, stale.ContentHash)
+	}
+
+	t.Setenv("DOC_CONSISTENCY_AUTO_REPAIR", "true")
+	out, err = DocConsistencyAudit(ctx, deps, DocConsistencyAuditInput{})
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if !out.AutoRepair || out.Repaired < 3 || len(out.Increased) != 0 {
+		t.Fatalf("out = %+v, want repairs and no new increases", out)
+	}
+	for _, c := range out.Checks {
+		if c.Repairable != (c.Repaired > 0) {
+			t.Fatalf("check = %+v, want exactly the repairable checks repaired", c)
+		}
+	}
+	if err := tx.First(&stale, "id = ?", seed.hashDoc).Error; err != nil || stale.ContentHash == "stale" {
+		t.Fatalf("doc hash not repaired: %v %q", err, stale.ContentHash)
+	}
+	var exp types.DocVariantExposure
+	if err := tx.First(&exp, "id = ?", seed.kindExposure).Error; err != nil || exp.VariantKind != "progressive" {
+		t.Fatalf("exposure kind not repaired: %v %q", err, exp.VariantKind)
+	}
+	latest, err := runs.GetLatest(dbctx.Context{Ctx: ctx})
+	if err != nil || latest == nil || latest.ID != out.RunID || !latest.AutoRepair {
+		t.Fatalf("latest run = %+v err = %v, want the persisted report", latest, err)
+	}
+}
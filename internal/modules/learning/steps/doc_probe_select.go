@@ -624,7 +624,7 @@ func DocProbeSelect(ctx context.Context, deps DocProbeSelectDeps, in DocProbeSel
 				return err
 			}
 			docRow.DocJSON = datatypes.JSON(raw)
-			docRow.ContentHash = content.HashBytes(raw)
+			docRow.ContentHash = content.NodeDocContentHash(raw)
 			docRow.UpdatedAt = now
 			if err := deps.NodeDocs.Upsert(dbc, docRow); err != nil {
 				return err
@@ -681,7 +681,7 @@ func DocProbeSelect(ctx context.Context, deps DocProbeSelectDeps, in DocProbeSel
 							raw, err := content.CanonicalizeJSON(vdoc)
 							if err == nil {
 								variant.DocJSON = datatypes.JSON(raw)
-								variant.ContentHash = content.HashBytes(raw)
+								variant.ContentHash = content.NodeDocContentHash(raw)
 								variant.UpdatedAt = now
 								_ = deps.DocVariants.Upsert(dbc, variant)
 							}
@@ -73,7 +73,7 @@ func EmbedChunks(ctx context.Context, deps EmbedChunksDeps, in EmbedChunksInput)
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("embed_chunks")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "embed_chunks")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -81,9 +81,9 @@ func EmbedChunks(ctx context.Context, deps EmbedChunksDeps, in EmbedChunksInput)
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("embed_chunks: adaptive params", "adaptive", adaptiveStageMeta("embed_chunks", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("embed_chunks: adaptive params", "adaptive", adaptiveStageMeta(ctx, "embed_chunks", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("embed_chunks", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "embed_chunks", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Derived material sets share the underlying chunk vectors namespace with their source upload batch.
@@ -0,0 +1,135 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type FieldCryptRotateDeps struct {
+	DB         *gorm.DB
+	Log        *logger.Logger
+	NodeDocs   repos.LearningNodeDocRepo
+	FieldCrypt *fieldcrypt.Envelope
+}
+
+type FieldCryptRotateInput struct {
+	DryRun    bool
+	Limit     int
+	BatchSize int
+}
+
+type FieldCryptRotateOutput struct {
+	KeyVersion string `json:"key_version"`
+	Scanned    int    `json:"scanned"`
+	Resealed   int    `json:"resealed"`
+	// Stale counts docs another writer changed mid-rotation; the next run picks them up.
+	Stale int `json:"stale"`
+}
+
+// FieldCryptRotate re-seals user_md bodies in learning_node_doc under the newest master key
+// version, in id-ordered batches. Old key versions must stay unwrappable until a run reports
+// nothing left to reseal.
+func FieldCryptRotate(ctx context.Context, deps FieldCryptRotateDeps, in FieldCryptRotateInput) (FieldCryptRotateOutput, error) {
+	out := FieldCryptRotateOutput{}
+	if deps.DB == nil || deps.NodeDocs == nil {
+		return out, fmt.Errorf("field_crypt_rotate: missing deps")
+	}
+	if !deps.FieldCrypt.Enabled() {
+		return out, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if in.BatchSize <= 0 {
+		in.BatchSize = envutil.Int("FIELD_CRYPT_ROTATE_BATCH_SIZE", 200)
+	}
+	if in.BatchSize <= 0 || in.BatchSize > 2000 {
+		in.BatchSize = 200
+	}
+	version, err := deps.FieldCrypt.Refresh(ctx)
+	if err != nil {
+		return out, fmt.Errorf("field_crypt_rotate: current key: %w", err)
+	}
+	out.KeyVersion = version
+
+	lastID := uuid.Nil
+	for {
+		q := deps.DB.WithContext(ctx).
+			Model(&types.LearningNodeDoc{}).
+			// Sealed bodies carry the nbenc1. marker; everything else has nothing to rotate.
+			Where("doc_json::text LIKE ?", `%"nbenc1.%`).
+			Order("id ASC").
+			Limit(in.BatchSize)
+		if lastID != uuid.Nil {
+			q = q.Where("id > ?", lastID)
+		}
+		var rows []*types.LearningNodeDoc
+		if err := q.Find(&rows).Error; err != nil {
+			return out, fmt.Errorf("field_crypt_rotate: list: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			lastID = row.ID
+			out.Scanned++
+			resealed, err := resealNodeDoc(ctx, deps, row, in.DryRun)
+			switch {
+			case errors.Is(err, repos.ErrStaleDoc):
+				out.Stale++
+			case err != nil:
+				return out, fmt.Errorf("field_crypt_rotate: doc %s: %w", row.ID, err)
+			case resealed:
+				out.Resealed++
+			}
+			if in.Limit > 0 && out.Scanned >= in.Limit {
+				return out, nil
+			}
+		}
+	}
+	if deps.Log != nil {
+		deps.Log.Info("field_crypt_rotate completed", "key_version", version, "scanned", out.Scanned, "resealed", out.Resealed, "stale", out.Stale)
+	}
+	return out, nil
+}
+
+func resealNodeDoc(ctx context.Context, deps FieldCryptRotateDeps, row *types.LearningNodeDoc, dryRun bool) (bool, error) {
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(row.DocJSON, &doc); err != nil {
+		return false, err
+	}
+	changed, err := content.ResealUserBlocks(ctx, &doc, deps.FieldCrypt)
+	if err != nil || !changed || dryRun {
+		return changed, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return false, err
+	}
+	canon, err := content.CanonicalizeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+	updated := *row
+	updated.DocJSON = datatypes.JSON(canon)
+	updated.ContentHash = content.NodeDocContentHash(canon)
+	// doc_text is left as is: user_md bodies only ever appear in it as the placeholder.
+	if err := deps.NodeDocs.UpdateWithVersion(dbctx.Context{Ctx: ctx}, &updated, row.Version); err != nil {
+		return false, err
+	}
+	return true, nil
+}
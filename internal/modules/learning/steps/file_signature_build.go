@@ -206,7 +206,7 @@ func FileSignatureBuild(ctx context.Context, deps FileSignatureBuildDeps, in Fil
 		}
 	}
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("file_signature_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "file_signature_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, in.PathID)
@@ -286,7 +286,7 @@ func FileSignatureBuild(ctx context.Context, deps FileSignatureBuildDeps, in Fil
 		minTextChars = clampIntCeiling(adjustMinTextCharsByContentType(minTextChars, signals.ContentType), 0, 0)
 	}
 	adaptiveOut["FILE_SIGNATURE_MIN_TEXT_CHARS"] = map[string]any{"actual": minTextChars}
-	out.Adaptive = adaptiveStageMeta("file_signature_build", adaptiveEnabled, signals, adaptiveOut)
+	out.Adaptive = adaptiveStageMeta(ctx, "file_signature_build", adaptiveEnabled, signals, adaptiveOut)
 	sectionEmbedBatch := envIntAllowZero("FILE_SIGNATURE_SECTION_EMBED_BATCH_SIZE", 64)
 	if sectionEmbedBatch <= 0 {
 		sectionEmbedBatch = 64
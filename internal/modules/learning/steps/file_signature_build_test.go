@@ -106,6 +106,14 @@ func (s *stubOpenAI) StreamTextInConversation(ctx context.Context, conversationI
 	return "", errors.New("not implemented")
 }
 
+func (s *stubOpenAI) AppendConversationTurn(ctx context.Context, conversationID string, user string, assistant string) error {
+	_ = ctx
+	_ = conversationID
+	_ = user
+	_ = assistant
+	return errors.New("not implemented")
+}
+
 type noopSaga struct{}
 
 func (n *noopSaga) CreateOrGetSaga(ctx context.Context, ownerUserID uuid.UUID, rootJobID uuid.UUID) (uuid.UUID, error) {
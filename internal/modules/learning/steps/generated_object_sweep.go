@@ -0,0 +1,324 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// GeneratedTrashPrefix holds unreferenced generated objects until the retention window passes.
+// A trashed object keeps its original key under the prefix, so it can be restored.
+const GeneratedTrashPrefix = "trash/"
+
+// generatedSweepPrefixes are the generated/ prefixes whose objects are tracked by figure, video,
+// narration and figure blob rows or doc asset refs. Other generated/ objects (covers, avatars)
+// are not swept. Kept sorted: the listing cursor walks them in key order.
+var generatedSweepPrefixes = []string{
+	"generated/figures_cas/",
+	"generated/node_audio/",
+	"generated/node_figures/",
+	"generated/node_videos/",
+}
+
+type GeneratedObjectSweepDeps struct {
+	Log    *logger.Logger
+	Bucket gcp.BucketService
+	Refs   repos.NodeAssetRefRepo
+}
+
+type GeneratedObjectSweepInput struct {
+	// Cursor and TrashCursor resume the generated/ and trash/ listings where a previous run
+	// stopped ("" starts over).
+	Cursor      string
+	TrashCursor string
+	PageSize    int
+	MaxPages    int
+	// GraceHours protects fresh objects whose referencing rows may not be committed yet;
+	// RetentionHours is how long trashed objects are kept before deletion.
+	GraceHours     int
+	RetentionHours int
+	DryRun         bool
+	// Now is the sweep clock; zero means time.Now().
+	Now time.Time
+}
+
+type GeneratedObjectSweepOutput struct {
+	Scanned    int `json:"scanned"`
+	Referenced int `json:"referenced"`
+	InGrace    int `json:"in_grace"`
+	Trashed    int `json:"trashed"`
+	// TrashedByProducer counts trashed objects by the producing job type from their upload tags.
+	TrashedByProducer map[string]int `json:"trashed_by_producer,omitempty"`
+	TrashedBytes      int64          `json:"trashed_bytes"`
+
+	TrashScanned int `json:"trash_scanned"`
+	Retained     int `json:"retained"`
+	Restored     int `json:"restored"`
+	Deleted      int `json:"deleted"`
+	Errors       int `json:"errors"`
+
+	NextCursor      string `json:"next_cursor,omitempty"`
+	NextTrashCursor string `json:"next_trash_cursor,omitempty"`
+	// Done reports that both phases finished; otherwise resume with NextCursor/NextTrashCursor.
+	Done bool `json:"done"`
+}
+
+// GeneratedObjectSweep moves generated objects no row or doc references to trash/ once they are
+// older than the grace period, and deletes trashed objects after the retention window (restoring
+// any that became referenced again). Work is bounded by MaxPages per listing; callers resume
+// with the returned cursors until Done.
+func GeneratedObjectSweep(ctx context.Context, deps GeneratedObjectSweepDeps, in GeneratedObjectSweepInput) (GeneratedObjectSweepOutput, error) {
+	out := GeneratedObjectSweepOutput{TrashedByProducer: map[string]int{}}
+	if deps.Log == nil || deps.Bucket == nil || deps.Refs == nil {
+		return out, fmt.Errorf("generated_object_sweep: missing deps")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if in.PageSize <= 0 {
+		in.PageSize = envutil.Int("GENERATED_SWEEP_PAGE_SIZE", 500)
+	}
+	if in.PageSize <= 0 || in.PageSize > 1000 {
+		in.PageSize = 500
+	}
+	if in.MaxPages <= 0 {
+		in.MaxPages = envutil.Int("GENERATED_SWEEP_MAX_PAGES", 20)
+	}
+	if in.MaxPages <= 0 {
+		in.MaxPages = 20
+	}
+	if in.GraceHours <= 0 {
+		in.GraceHours = envutil.Int("GENERATED_SWEEP_GRACE_HOURS", 72)
+	}
+	if in.RetentionHours <= 0 {
+		in.RetentionHours = envutil.Int("GENERATED_SWEEP_RETENTION_HOURS", 168)
+	}
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	graceCutoff := now.Add(-time.Duration(in.GraceHours) * time.Hour)
+	retentionCutoff := now.Add(-time.Duration(in.RetentionHours) * time.Hour)
+	dbc := dbctx.Context{Ctx: ctx}
+
+	// Phase 1: generated/ -> trash/.
+	cursor := in.Cursor
+	sweepDone := false
+	for page := 0; page < in.MaxPages; page++ {
+		prefix, ok := generatedSweepPrefix(cursor)
+		if !ok {
+			sweepDone = true
+			break
+		}
+		start := ""
+		if strings.HasPrefix(cursor, prefix) {
+			start = cursor
+		}
+		res, err := deps.Bucket.ListObjects(ctx, gcp.BucketCategoryMaterial, prefix, start, in.PageSize)
+		if err != nil {
+			return out, fmt.Errorf("generated_object_sweep: list %s: %w", prefix, err)
+		}
+		if len(res.Objects) > 0 {
+			if err := sweepGeneratedPage(dbc, deps, in.DryRun, graceCutoff, res.Objects, &out); err != nil {
+				return out, err
+			}
+			cursor = res.Objects[len(res.Objects)-1].Key
+		}
+		if res.NextCursor == "" {
+			next, more := nextGeneratedSweepPrefix(prefix)
+			if !more {
+				sweepDone = true
+				break
+			}
+			cursor = next
+		}
+	}
+	if !sweepDone {
+		out.NextCursor = cursor
+		out.logSummary(deps.Log, in.DryRun)
+		return out, nil
+	}
+
+	// Phase 2: trash/ -> deleted (or restored). It starts once generated/ is exhausted; until it
+	// finishes, the generated/ cursor is parked past every sweep prefix so resumed runs skip
+	// phase 1.
+	trashCursor := in.TrashCursor
+	trashDone := false
+	for page := 0; page < in.MaxPages; page++ {
+		res, err := deps.Bucket.ListObjects(ctx, gcp.BucketCategoryMaterial, GeneratedTrashPrefix, trashCursor, in.PageSize)
+		if err != nil {
+			return out, fmt.Errorf("generated_object_sweep: list %s: %w", GeneratedTrashPrefix, err)
+		}
+		if len(res.Objects) > 0 {
+			if err := purgeTrashPage(dbc, deps, in.DryRun, retentionCutoff, res.Objects, &out); err != nil {
+				return out, err
+			}
+		}
+		trashCursor = res.NextCursor
+		if trashCursor == "" {
+			trashDone = true
+			break
+		}
+	}
+	if !trashDone {
+		out.NextCursor = GeneratedTrashPrefix
+		out.NextTrashCursor = trashCursor
+	}
+	out.Done = trashDone
+	out.logSummary(deps.Log, in.DryRun)
+	return out, nil
+}
+
+func (out GeneratedObjectSweepOutput) logSummary(log *logger.Logger, dryRun bool) {
+	log.Info("generated object sweep",
+		"scanned", out.Scanned,
+		"trashed", out.Trashed,
+		"trashed_bytes", out.TrashedBytes,
+		"trash_scanned", out.TrashScanned,
+		"deleted", out.Deleted,
+		"restored", out.Restored,
+		"errors", out.Errors,
+		"dry_run", dryRun,
+		"done", out.Done,
+	)
+}
+
+// generatedSweepPrefix returns the sweep prefix a cursor falls in (or precedes); ok is false
+// once the cursor is past every prefix.
+func generatedSweepPrefix(cursor string) (string, bool) {
+	for _, p := range generatedSweepPrefixes {
+		if cursor == "" || cursor < p || strings.HasPrefix(cursor, p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func nextGeneratedSweepPrefix(prefix string) (string, bool) {
+	for i, p := range generatedSweepPrefixes {
+		if p == prefix && i+1 < len(generatedSweepPrefixes) {
+			return generatedSweepPrefixes[i+1], true
+		}
+	}
+	return "", false
+}
+
+func sweepGeneratedPage(dbc dbctx.Context, deps GeneratedObjectSweepDeps, dryRun bool, graceCutoff time.Time, objects []gcp.ObjectInfo, out *GeneratedObjectSweepOutput) error {
+	candidates := make([]gcp.ObjectInfo, 0, len(objects))
+	keys := make([]string, 0, len(objects))
+	for _, o := range objects {
+		out.Scanned++
+		if !objectTime(o).Before(graceCutoff) {
+			out.InGrace++
+			continue
+		}
+		candidates = append(candidates, o)
+		keys = append(keys, o.Key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	referenced, err := deps.Refs.ReferencedKeys(dbc, keys)
+	if err != nil {
+		return fmt.Errorf("generated_object_sweep: referenced keys: %w", err)
+	}
+	for _, o := range candidates {
+		if referenced[o.Key] {
+			out.Referenced++
+			continue
+		}
+		if !dryRun {
+			trashKey := GeneratedTrashPrefix + o.Key
+			if err := deps.Bucket.CopyObject(dbc.Ctx, gcp.BucketCategoryMaterial, o.Key, trashKey); err != nil {
+				deps.Log.Warn("generated object sweep: trash copy failed", "key", o.Key, "error", err)
+				out.Errors++
+				continue
+			}
+			if err := deps.Bucket.DeleteFile(dbc, gcp.BucketCategoryMaterial, o.Key); err != nil {
+				deps.Log.Warn("generated object sweep: delete after trash failed", "key", o.Key, "error", err)
+				out.Errors++
+				continue
+			}
+		}
+		out.Trashed++
+		out.TrashedBytes += o.Size
+		out.TrashedByProducer[producerJobType(o.Metadata)]++
+	}
+	return nil
+}
+
+func purgeTrashPage(dbc dbctx.Context, deps GeneratedObjectSweepDeps, dryRun bool, retentionCutoff time.Time, objects []gcp.ObjectInfo, out *GeneratedObjectSweepOutput) error {
+	expired := make([]gcp.ObjectInfo, 0, len(objects))
+	originals := make([]string, 0, len(objects))
+	for _, o := range objects {
+		out.TrashScanned++
+		if !objectTime(o).Before(retentionCutoff) {
+			out.Retained++
+			continue
+		}
+		expired = append(expired, o)
+		originals = append(originals, strings.TrimPrefix(o.Key, GeneratedTrashPrefix))
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	// A doc restored from an old revision can point at a trashed key again; put it back.
+	referenced, err := deps.Refs.ReferencedKeys(dbc, originals)
+	if err != nil {
+		return fmt.Errorf("generated_object_sweep: referenced keys: %w", err)
+	}
+	for i, o := range expired {
+		original := originals[i]
+		if referenced[original] {
+			if !dryRun {
+				if err := deps.Bucket.CopyObject(dbc.Ctx, gcp.BucketCategoryMaterial, o.Key, original); err != nil {
+					deps.Log.Warn("generated object sweep: restore failed", "key", original, "error", err)
+					out.Errors++
+					continue
+				}
+				if err := deps.Bucket.DeleteFile(dbc, gcp.BucketCategoryMaterial, o.Key); err != nil {
+					deps.Log.Warn("generated object sweep: trash delete after restore failed", "key", o.Key, "error", err)
+					out.Errors++
+					continue
+				}
+			}
+			out.Restored++
+			continue
+		}
+		if !dryRun {
+			if err := deps.Bucket.DeleteFile(dbc, gcp.BucketCategoryMaterial, o.Key); err != nil {
+				deps.Log.Warn("generated object sweep: trash delete failed", "key", o.Key, "error", err)
+				out.Errors++
+				continue
+			}
+		}
+		out.Deleted++
+	}
+	return nil
+}
+
+// objectTime is when the object was written; for trash/ copies that is when it was trashed.
+func objectTime(o gcp.ObjectInfo) time.Time {
+	if !o.Created.IsZero() {
+		return o.Created
+	}
+	return o.Updated
+}
+
+func producerJobType(meta map[string]string) string {
+	job := strings.TrimSpace(meta[gcp.ObjectMetaProducerJob])
+	if i := strings.Index(job, ":"); i >= 0 {
+		job = job[:i]
+	}
+	if job == "" {
+		return "untagged"
+	}
+	return job
+}
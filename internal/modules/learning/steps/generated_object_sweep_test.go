@@ -0,0 +1,175 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// memBucket implements the listing/copy/delete subset of gcp.BucketService the sweeper uses.
+type memBucket struct {
+	gcp.BucketService
+	now     time.Time
+	objects map[string]gcp.ObjectInfo
+}
+
+func (b *memBucket) put(key string, created time.Time, meta map[string]string) {
+	b.objects[key] = gcp.ObjectInfo{Key: key, Size: 10, Created: created, Updated: created, Metadata: meta}
+}
+
+func (b *memBucket) ListObjects(_ context.Context, _ gcp.BucketCategory, prefix, cursor string, limit int) (gcp.ObjectPage, error) {
+	keys := []string{}
+	for k := range b.objects {
+		if strings.HasPrefix(k, prefix) && k > cursor {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var out gcp.ObjectPage
+	for _, k := range keys {
+		if len(out.Objects) == limit {
+			out.NextCursor = out.Objects[len(out.Objects)-1].Key
+			break
+		}
+		out.Objects = append(out.Objects, b.objects[k])
+	}
+	return out, nil
+}
+
+func (b *memBucket) CopyObject(_ context.Context, _ gcp.BucketCategory, src, dst string) error {
+	o, ok := b.objects[src]
+	if !ok {
+		return fmt.Errorf("no object %q", src)
+	}
+	b.put(dst, b.now, o.Metadata)
+	return nil
+}
+
+func (b *memBucket) DeleteFile(_ dbctx.Context, _ gcp.BucketCategory, key string) error {
+	delete(b.objects, key)
+	return nil
+}
+
+type fakeAssetRefs struct {
+	repos.NodeAssetRefRepo
+	referenced map[string]bool
+}
+
+func (f *fakeAssetRefs) ReferencedKeys(_ dbctx.Context, keys []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, k := range keys {
+		if f.referenced[k] {
+			out[k] = true
+		}
+	}
+	return out, nil
+}
+
+func TestGeneratedObjectSweepTrashesThenDeletes(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := t0.Add(-100 * time.Hour)
+
+	const (
+		staleFigure = "generated/node_figures/p/n/slot_1_a.png"
+		liveFigure  = "generated/node_figures/p/n/slot_1_b.png"
+		freshAudio  = "generated/node_audio/p/n/h.wav"
+		staleVideo  = "generated/node_videos/p/n/slot_2_c.mp4"
+		liveBlob    = "generated/figures_cas/abc.png"
+		cover       = "generated/path_covers/p.png"
+	)
+	bucket := &memBucket{objects: map[string]gcp.ObjectInfo{}}
+	bucket.put(staleFigure, old, map[string]string{gcp.ObjectMetaProducerJob: "node_figures_render:1"})
+	bucket.put(liveFigure, old, nil)
+	bucket.put(freshAudio, t0.Add(-time.Hour), nil)
+	bucket.put(staleVideo, old, map[string]string{gcp.ObjectMetaProducerJob: "node_videos_render:2"})
+	bucket.put(liveBlob, old, nil)
+	bucket.put(cover, old, nil)
+	refs := &fakeAssetRefs{referenced: map[string]bool{liveFigure: true, liveBlob: true}}
+	deps := GeneratedObjectSweepDeps{Log: log, Bucket: bucket, Refs: refs}
+
+	// Run to completion in small bounded pages, resuming from the returned cursors.
+	sweepAll := func(now time.Time) GeneratedObjectSweepOutput {
+		bucket.now = now
+		var total GeneratedObjectSweepOutput
+		in := GeneratedObjectSweepInput{PageSize: 1, MaxPages: 2, GraceHours: 24, RetentionHours: 72, Now: now}
+		for run := 0; run < 50; run++ {
+			out, err := GeneratedObjectSweep(context.Background(), deps, in)
+			if err != nil {
+				t.Fatalf("sweep: %v", err)
+			}
+			total.Scanned += out.Scanned
+			total.Trashed += out.Trashed
+			total.InGrace += out.InGrace
+			total.Referenced += out.Referenced
+			total.Deleted += out.Deleted
+			total.Restored += out.Restored
+			total.Retained += out.Retained
+			if out.Done {
+				return total
+			}
+			if out.NextCursor == "" && out.NextTrashCursor == "" {
+				t.Fatalf("sweep not done but returned no cursor: %+v", out)
+			}
+			in.Cursor, in.TrashCursor = out.NextCursor, out.NextTrashCursor
+		}
+		t.Fatalf("sweep did not finish")
+		return total
+	}
+
+	// Phase 1: unreferenced objects past the grace period move to trash/.
+	first := sweepAll(t0)
+	if first.Scanned != 5 || first.Trashed != 2 || first.Referenced != 2 || first.InGrace != 1 {
+		t.Fatalf("unexpected first sweep: %+v", first)
+	}
+	for _, k := range []string{staleFigure, staleVideo} {
+		if _, ok := bucket.objects[k]; ok {
+			t.Fatalf("%s should have left generated/", k)
+		}
+		if _, ok := bucket.objects[GeneratedTrashPrefix+k]; !ok {
+			t.Fatalf("%s should be in trash", k)
+		}
+	}
+	for _, k := range []string{liveFigure, freshAudio, liveBlob, cover} {
+		if _, ok := bucket.objects[k]; !ok {
+			t.Fatalf("%s should be untouched", k)
+		}
+	}
+
+	// The narration row for the fresh audio lands after the first sweep; grace kept it alive.
+	refs.referenced[freshAudio] = true
+
+	// Inside the retention window trash is kept.
+	if second := sweepAll(t0.Add(24 * time.Hour)); second.Deleted != 0 || second.Retained != 2 {
+		t.Fatalf("expected trash retained, got %+v", second)
+	}
+
+	// Phase 2: after retention trash is deleted, unless the key became referenced again.
+	refs.referenced[staleFigure] = true
+	third := sweepAll(t0.Add(100 * time.Hour))
+	if third.Deleted != 1 || third.Restored != 1 {
+		t.Fatalf("unexpected purge: %+v", third)
+	}
+	if _, ok := bucket.objects[GeneratedTrashPrefix+staleVideo]; ok {
+		t.Fatalf("expired trash should be deleted")
+	}
+	if _, ok := bucket.objects[staleFigure]; !ok {
+		t.Fatalf("re-referenced object should be restored")
+	}
+	for k := range bucket.objects {
+		if strings.HasPrefix(k, GeneratedTrashPrefix) {
+			t.Fatalf("trash should be empty, found %s", k)
+		}
+	}
+}
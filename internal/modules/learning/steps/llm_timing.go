@@ -1,24 +1,38 @@
 package steps
 
 import (
+	"context"
 	"time"
 
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 )
 
-func llmTimer(log *logger.Logger, name string, fields map[string]any) func(error) {
+// llmTimer logs an LLM call's duration and outcome, tagged with the trace of the job or
+// request in ctx so worker-side calls can be tied back to the request that caused them.
+func llmTimer(ctx context.Context, log *logger.Logger, name string, fields map[string]any) func(error) {
 	start := time.Now()
+	trace := ctxutil.TraceFields(ctx)
 	return func(err error) {
 		if log == nil {
 			return
 		}
-		kv := make([]any, 0, 4+len(fields)*2+2)
+		kv := make([]any, 0, 4+(len(fields)+len(trace))*2+2)
 		kv = append(kv, "llm_call", name, "elapsed_ms", time.Since(start).Milliseconds())
 		for k, v := range fields {
 			kv = append(kv, k, v)
 		}
+		for k, v := range trace {
+			if _, ok := fields[k]; !ok {
+				kv = append(kv, k, v)
+			}
+		}
 		if err != nil {
 			kv = append(kv, "error", err.Error())
+			if class := openai.JSONFailureClassOf(err); class != "" {
+				kv = append(kv, "failure_class", string(class))
+			}
 			log.Warn("llm call finished", kv...)
 			return
 		}
@@ -0,0 +1,301 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+)
+
+type MaterialFileCleanupDeps struct {
+	DB       *gorm.DB
+	Log      *logger.Logger
+	Files    repos.MaterialFileRepo
+	Chunks   repos.MaterialChunkRepo
+	Evidence repos.ConceptEvidenceRepo
+	NodeDocs repos.LearningNodeDocRepo
+	Vec      pinecone.VectorStore
+}
+
+type MaterialFileCleanupInput struct {
+	OwnerUserID    uuid.UUID
+	MaterialFileID uuid.UUID
+}
+
+type MaterialFileCleanupOutput struct {
+	MaterialSetID     uuid.UUID `json:"material_set_id"`
+	ChunksRemoved     int       `json:"chunks_removed"`
+	VectorsDeleted    int       `json:"vectors_deleted"`
+	EvidenceFlagged   int64     `json:"evidence_flagged"`
+	DocsSourcesStaled int       `json:"docs_sources_stale"`
+}
+
+// MaterialFileCleanup finishes the removal of a soft-deleted material file: it drops the chunk
+// vectors, flags concept evidence citing the chunks as source_removed (rows are kept so counts
+// don't move) and marks every node doc citing them as sources_stale. It is idempotent.
+func MaterialFileCleanup(ctx context.Context, deps MaterialFileCleanupDeps, in MaterialFileCleanupInput) (MaterialFileCleanupOutput, error) {
+	out := MaterialFileCleanupOutput{}
+	if deps.Log == nil || deps.Files == nil || deps.Chunks == nil || deps.Evidence == nil || deps.NodeDocs == nil {
+		return out, fmt.Errorf("material_file_cleanup: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.MaterialFileID == uuid.Nil {
+		return out, fmt.Errorf("material_file_cleanup: missing ids")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	file, err := loadRemovedMaterialFile(dbc, deps.Files, in.MaterialFileID)
+	if err != nil {
+		return out, err
+	}
+	out.MaterialSetID = file.MaterialSetID
+
+	chunks, err := deps.Chunks.GetByMaterialFileIDsIncludingDeleted(dbc, []uuid.UUID{file.ID})
+	if err != nil {
+		return out, err
+	}
+	chunkIDs := make([]uuid.UUID, 0, len(chunks))
+	vectorIDs := make([]string, 0, len(chunks))
+	for _, ch := range chunks {
+		if ch == nil || ch.ID == uuid.Nil {
+			continue
+		}
+		chunkIDs = append(chunkIDs, ch.ID)
+		vectorIDs = append(vectorIDs, ch.ID.String())
+	}
+	out.ChunksRemoved = len(chunkIDs)
+	if len(chunkIDs) == 0 {
+		return out, nil
+	}
+
+	// Chunk vectors live in the upload batch's namespace, which derived sets share.
+	if deps.Vec != nil {
+		delCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		err := deps.Vec.DeleteIDs(delCtx, index.ChunksNamespace(file.MaterialSetID), vectorIDs)
+		cancel()
+		if err != nil {
+			return out, fmt.Errorf("material_file_cleanup: delete vectors: %w", err)
+		}
+		out.VectorsDeleted = len(vectorIDs)
+	}
+
+	flagged, err := deps.Evidence.MarkSourceRemovedByMaterialChunkIDs(dbc, chunkIDs)
+	if err != nil {
+		return out, err
+	}
+	out.EvidenceFlagged = flagged
+
+	docs, err := nodeDocsCitingChunks(dbc, deps.NodeDocs, in.OwnerUserID, chunkIDs)
+	if err != nil {
+		return out, err
+	}
+	for _, d := range docs {
+		if err := deps.NodeDocs.MergeMetadata(dbc, d.ID, map[string]any{content.NodeDocSourcesStaleKey: true}); err != nil {
+			return out, err
+		}
+		out.DocsSourcesStaled++
+	}
+
+	deps.Log.Info("material_file_cleanup: done",
+		"material_file_id", file.ID.String(),
+		"chunks", out.ChunksRemoved,
+		"evidence_flagged", out.EvidenceFlagged,
+		"docs_sources_stale", out.DocsSourcesStaled,
+	)
+	return out, nil
+}
+
+type MaterialFileRelinkDeps struct {
+	DB       *gorm.DB
+	Log      *logger.Logger
+	Files    repos.MaterialFileRepo
+	Chunks   repos.MaterialChunkRepo
+	Evidence repos.ConceptEvidenceRepo
+	NodeDocs repos.LearningNodeDocRepo
+}
+
+type MaterialFileRelinkInput struct {
+	OwnerUserID   uuid.UUID
+	RemovedFileID uuid.UUID
+	NewFileID     uuid.UUID
+}
+
+type MaterialFileRelinkOutput struct {
+	ChunksMatched     int   `json:"chunks_matched"`
+	ChunksUnmatched   int   `json:"chunks_unmatched"`
+	EvidenceRelinked  int64 `json:"evidence_relinked"`
+	CitationsRelinked int   `json:"citations_relinked"`
+	DocsUpdated       int   `json:"docs_updated"`
+}
+
+// MaterialFileRelink restores citations of a removed file onto a re-uploaded one. Chunks are
+// paired by text hash (repos.MatchChunksByTextHash); evidence and doc citations of matched
+// chunks are repointed, and a doc's sources_stale flag is cleared once it no longer cites any
+// chunk of the removed file.
+func MaterialFileRelink(ctx context.Context, deps MaterialFileRelinkDeps, in MaterialFileRelinkInput) (MaterialFileRelinkOutput, error) {
+	out := MaterialFileRelinkOutput{}
+	if deps.DB == nil || deps.Log == nil || deps.Files == nil || deps.Chunks == nil || deps.Evidence == nil || deps.NodeDocs == nil {
+		return out, fmt.Errorf("material_file_relink: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.RemovedFileID == uuid.Nil || in.NewFileID == uuid.Nil {
+		return out, fmt.Errorf("material_file_relink: missing ids")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	if _, err := loadRemovedMaterialFile(dbc, deps.Files, in.RemovedFileID); err != nil {
+		return out, err
+	}
+	removedChunks, err := deps.Chunks.GetByMaterialFileIDsIncludingDeleted(dbc, []uuid.UUID{in.RemovedFileID})
+	if err != nil {
+		return out, err
+	}
+	relink, err := deps.Chunks.MatchRemovedChunks(dbc, in.RemovedFileID, in.NewFileID)
+	if err != nil {
+		return out, err
+	}
+	out.ChunksMatched = len(relink)
+	out.ChunksUnmatched = len(removedChunks) - len(relink)
+	if len(relink) == 0 {
+		return out, nil
+	}
+
+	removedIDs := make([]uuid.UUID, 0, len(removedChunks))
+	removedSet := map[string]bool{}
+	for _, ch := range removedChunks {
+		if ch != nil && ch.ID != uuid.Nil {
+			removedIDs = append(removedIDs, ch.ID)
+			removedSet[ch.ID.String()] = true
+		}
+	}
+	relinkStr := make(map[string]string, len(relink))
+	for from, to := range relink {
+		relinkStr[from.String()] = to.String()
+	}
+
+	docs, err := nodeDocsCitingChunks(dbc, deps.NodeDocs, in.OwnerUserID, removedIDs)
+	if err != nil {
+		return out, err
+	}
+
+	err = deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inner := dbctx.Context{Ctx: ctx, Tx: tx}
+		n, err := deps.Evidence.RelinkMaterialChunks(inner, relink)
+		if err != nil {
+			return err
+		}
+		out.EvidenceRelinked = n
+
+		for _, d := range docs {
+			canon, rewritten, err := content.RelinkNodeDocCitations(d.DocJSON, relinkStr)
+			if err != nil {
+				return err
+			}
+			stillRemoved := false
+			for _, id := range citedChunkIDs(canon) {
+				if removedSet[id] {
+					stillRemoved = true
+					break
+				}
+			}
+			if rewritten == 0 && stillRemoved {
+				continue
+			}
+			row := *d
+			row.DocJSON = datatypes.JSON(canon)
+			row.ContentHash = content.NodeDocContentHash(canon)
+			row.Metadata = datatypes.JSON(content.WithNodeDocSourcesStale(d.Metadata, stillRemoved))
+			if err := deps.NodeDocs.UpdateWithVersion(inner, &row, d.Version); err != nil {
+				return err
+			}
+			out.CitationsRelinked += rewritten
+			out.DocsUpdated++
+		}
+		return nil
+	})
+	if errors.Is(err, repos.ErrStaleDoc) {
+		return out, fmt.Errorf("material_file_relink: a doc changed during relink; retry: %w", err)
+	}
+	if err != nil {
+		return out, err
+	}
+
+	deps.Log.Info("material_file_relink: done",
+		"removed_file_id", in.RemovedFileID.String(),
+		"new_file_id", in.NewFileID.String(),
+		"matched", out.ChunksMatched,
+		"docs_updated", out.DocsUpdated,
+	)
+	return out, nil
+}
+
+func loadRemovedMaterialFile(dbc dbctx.Context, files repos.MaterialFileRepo, id uuid.UUID) (*types.MaterialFile, error) {
+	rows, err := files.GetByIDsIncludingDeleted(dbc, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || rows[0] == nil {
+		return nil, fmt.Errorf("material file %s not found", id)
+	}
+	if !rows[0].DeletedAt.Valid {
+		return nil, fmt.Errorf("material file %s is not removed", id)
+	}
+	return rows[0], nil
+}
+
+// nodeDocsCitingChunks narrows the repo's text match to docs whose block citations really name
+// one of the chunks.
+func nodeDocsCitingChunks(dbc dbctx.Context, docs repos.LearningNodeDocRepo, userID uuid.UUID, chunkIDs []uuid.UUID) ([]*types.LearningNodeDoc, error) {
+	rows, err := docs.ListByUserCitingChunks(dbc, userID, chunkIDs)
+	if err != nil {
+		return nil, err
+	}
+	want := make(map[string]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		want[id.String()] = true
+	}
+	out := make([]*types.LearningNodeDoc, 0, len(rows))
+	for _, d := range rows {
+		if d == nil {
+			continue
+		}
+		for _, id := range citedChunkIDs(d.DocJSON) {
+			if want[id] {
+				out = append(out, d)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func citedChunkIDs(docJSON []byte) []string {
+	var doc content.NodeDocV1
+	if json.Unmarshal(docJSON, &doc) != nil {
+		return nil
+	}
+	var out []string
+	for _, b := range doc.Blocks {
+		cites, _ := b["citations"].([]any)
+		for _, c := range cites {
+			if m, ok := c.(map[string]any); ok {
+				if id, _ := m["chunk_id"].(string); strings.TrimSpace(id) != "" {
+					out = append(out, strings.TrimSpace(id))
+				}
+			}
+		}
+	}
+	return out
+}
@@ -92,7 +92,7 @@ func MaterialKGBuild(ctx context.Context, deps MaterialKGBuildDeps, in MaterialK
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("material_kg_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "material_kg_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -100,12 +100,12 @@ func MaterialKGBuild(ctx context.Context, deps MaterialKGBuildDeps, in MaterialK
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if len(adaptiveParams) > 0 {
-			out.Trace["adaptive"] = adaptiveStageMeta("material_kg_build", adaptiveEnabled, signals, adaptiveParams)
+			out.Trace["adaptive"] = adaptiveStageMeta(ctx, "material_kg_build", adaptiveEnabled, signals, adaptiveParams)
 		} else {
-			out.Trace["adaptive"] = adaptiveStageMeta("material_kg_build", adaptiveEnabled, signals, map[string]any{})
+			out.Trace["adaptive"] = adaptiveStageMeta(ctx, "material_kg_build", adaptiveEnabled, signals, map[string]any{})
 		}
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("material_kg_build: adaptive params", "adaptive", adaptiveStageMeta("material_kg_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("material_kg_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "material_kg_build", adaptiveEnabled, signals, adaptiveParams))
 		}
 	}()
 
@@ -91,16 +91,16 @@ func MaterialSignalBuild(ctx context.Context, deps MaterialSignalBuildDeps, in M
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("material_signal_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "material_signal_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
 	}
 	adaptiveParams := map[string]any{}
 	defer func() {
-		out.Trace["adaptive"] = adaptiveStageMeta("material_signal_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Trace["adaptive"] = adaptiveStageMeta(ctx, "material_signal_build", adaptiveEnabled, signals, adaptiveParams)
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("material_signal_build: adaptive params", "adaptive", adaptiveStageMeta("material_signal_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("material_signal_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "material_signal_build", adaptiveEnabled, signals, adaptiveParams))
 		}
 	}()
 
@@ -0,0 +1,170 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// nodeDescriptionSyncMinSimilarity is the token overlap (Jaccard, case and punctuation ignored)
+// between a node's description and its doc's summary snippet below which the description has
+// drifted from what the doc teaches. Descriptions are short paraphrases, so the bar is low.
+func nodeDescriptionSyncMinSimilarity() float64 {
+	v := envFloatAllowZero("NODE_DESCRIPTION_SYNC_MIN_SIMILARITY", 0.3)
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// Outcomes of nodeDescriptionSyncDecision.
+const (
+	nodeDescriptionUserEdited = "user_edited"
+	nodeDescriptionNoSummary  = "no_doc_summary"
+	nodeDescriptionInSync     = "in_sync"
+	nodeDescriptionDrifted    = "drifted"
+)
+
+// nodeDescriptionSyncDecision decides whether a node's description should be replaced by its
+// doc's summary snippet: never when the user edited it, and otherwise only when it is empty or
+// shares less than minSim of its tokens with the snippet. A description already derived from
+// this doc content is left alone.
+func nodeDescriptionSyncDecision(nodeMeta datatypes.JSON, digest types.NodeDocDigest, docHash string, minSim float64) string {
+	current, source := types.PathNodeDescription(nodeMeta)
+	if source == types.PathNodeDescriptionSourceUser {
+		return nodeDescriptionUserEdited
+	}
+	derived := strings.TrimSpace(digest.Summary)
+	if derived == "" {
+		return nodeDescriptionNoSummary
+	}
+	if current == derived {
+		return nodeDescriptionInSync
+	}
+	if source == types.PathNodeDescriptionSourceDoc && docHash != "" &&
+		stringFromAny(decodeJSONMap(nodeMeta)[types.PathNodeDescriptionDocHashKey]) == docHash {
+		return nodeDescriptionInSync
+	}
+	if current != "" && jaccard(tokenSetForMatch(current), tokenSetForMatch(derived)) >= minSim {
+		return nodeDescriptionInSync
+	}
+	return nodeDescriptionDrifted
+}
+
+type NodeDescriptionSyncDeps struct {
+	Log       *logger.Logger
+	PathNodes repos.PathNodeRepo
+	NodeDocs  repos.LearningNodeDocRepo
+}
+
+type NodeDescriptionSyncInput struct {
+	OwnerUserID uuid.UUID
+	PathID      uuid.UUID
+	// NodeIDs limits the sync to these nodes; without them every node with a doc is checked.
+	NodeIDs []uuid.UUID
+}
+
+type NodeDescriptionSyncOutput struct {
+	NodesChecked int `json:"nodes_checked"`
+	NodesUpdated int `json:"nodes_updated"`
+	// NodesProtected counts user-edited descriptions left as they are.
+	NodesProtected int `json:"nodes_protected"`
+}
+
+// NodeDescriptionSync replaces node descriptions that have drifted from their doc with the
+// doc's summary snippet (see nodeDescriptionSyncDecision), tagged derived_from_doc. The build
+// goal in node metadata is kept; outlines prefer the synced description over it.
+func NodeDescriptionSync(ctx context.Context, deps NodeDescriptionSyncDeps, in NodeDescriptionSyncInput) (NodeDescriptionSyncOutput, error) {
+	out := NodeDescriptionSyncOutput{}
+	if deps.Log == nil || deps.PathNodes == nil || deps.NodeDocs == nil {
+		return out, fmt.Errorf("node_description_sync: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.PathID == uuid.Nil {
+		return out, fmt.Errorf("node_description_sync: missing ids")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	nodes, err := deps.PathNodes.GetByPathIDs(dbc, []uuid.UUID{in.PathID})
+	if err != nil {
+		return out, err
+	}
+	only := map[uuid.UUID]bool{}
+	for _, id := range in.NodeIDs {
+		only[id] = true
+	}
+	nodeIDs := make([]uuid.UUID, 0, len(nodes))
+	for _, n := range nodes {
+		if n != nil && n.ID != uuid.Nil && (len(only) == 0 || only[n.ID]) {
+			nodeIDs = append(nodeIDs, n.ID)
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return out, nil
+	}
+	docs, err := deps.NodeDocs.GetLatestByPathNodeIDs(dbc, nodeIDs)
+	if err != nil {
+		return out, err
+	}
+	docByNodeID := map[uuid.UUID]*types.LearningNodeDoc{}
+	for _, d := range docs {
+		if d != nil && d.UserID == in.OwnerUserID {
+			docByNodeID[d.PathNodeID] = d
+		}
+	}
+
+	minSim := nodeDescriptionSyncMinSimilarity()
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		doc := docByNodeID[n.ID]
+		if doc == nil {
+			continue
+		}
+		out.NodesChecked++
+		digest := types.DecodeNodeDocDigest(doc.Digest)
+		if digest.Version == 0 {
+			// Committed before digests were stored.
+			digest = types.ExtractNodeDocDigest(doc.DocJSON)
+		}
+		switch nodeDescriptionSyncDecision(n.Metadata, digest, doc.ContentHash, minSim) {
+		case nodeDescriptionUserEdited:
+			out.NodesProtected++
+			continue
+		case nodeDescriptionDrifted:
+		default:
+			continue
+		}
+		updated, err := deps.PathNodes.SetDescription(dbc, n.ID, digest.Summary, types.PathNodeDescriptionSourceDoc, map[string]any{
+			types.PathNodeDescriptionDocHashKey: doc.ContentHash,
+		})
+		if err != nil {
+			return out, err
+		}
+		if !updated {
+			// The user edited it since it was read.
+			out.NodesProtected++
+			continue
+		}
+		out.NodesUpdated++
+	}
+
+	deps.Log.Info("node_description_sync: done",
+		"path_id", in.PathID.String(),
+		"nodes_checked", out.NodesChecked,
+		"nodes_updated", out.NodesUpdated,
+		"nodes_protected", out.NodesProtected,
+	)
+	return out, nil
+}
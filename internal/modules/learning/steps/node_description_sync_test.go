@@ -0,0 +1,127 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+const descriptionSyncDocJSON = `{"schema_version":1,"title":"Recursion","summary":"Recursion solves a problem by calling itself on smaller inputs until a base case stops it. Later sections trace the call stack.","concept_keys":["recursion"],
+	"blocks":[
+		{"id":"o1","type":"objectives","title":"Objectives","items_md":["- Identify the base case","Trace a recursive call stack","identify the base case"]},
+		{"id":"p1","type":"paragraph","md":"A function that calls itself."}
+	]}`
+
+func TestNodeDescriptionSyncDecision(t *testing.T) {
+	digest := types.ExtractNodeDocDigest([]byte(descriptionSyncDocJSON))
+	if digest.Version != types.NodeDocDigestVersion || len(digest.Objectives) != 2 || digest.Objectives[0] != "Identify the base case" {
+		t.Fatalf("digest = %+v", digest)
+	}
+	const minSim = 0.3
+	cases := []struct {
+		name string
+		meta string
+		hash string
+		want string
+	}{
+		{"build goal drifted", `{"goal":"Learn loops and iteration"}`, "h1", nodeDescriptionDrifted},
+		{"build goal close", `{"goal":"Recursion solves a problem by calling itself on smaller inputs"}`, "h1", nodeDescriptionInSync},
+		{"no description", `{}`, "h1", nodeDescriptionDrifted},
+		{"derived from this doc", `{"goal":"x","description":"Loops.","description_source":"derived_from_doc","description_doc_hash":"h1"}`, "h1", nodeDescriptionInSync},
+		{"derived from an older doc", `{"goal":"x","description":"Loops.","description_source":"derived_from_doc","description_doc_hash":"h0"}`, "h1", nodeDescriptionDrifted},
+		{"user edited", `{"goal":"x","description":"My own words.","description_source":"user"}`, "h1", nodeDescriptionUserEdited},
+	}
+	for _, tc := range cases {
+		if got := nodeDescriptionSyncDecision(datatypes.JSON(tc.meta), digest, tc.hash, minSim); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+	if got := nodeDescriptionSyncDecision(datatypes.JSON(`{"goal":"x"}`), types.NodeDocDigest{}, "h1", minSim); got != nodeDescriptionNoSummary {
+		t.Fatalf("empty digest = %s", got)
+	}
+}
+
+func TestNodeDescriptionSyncSources(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+
+	user := testutil.SeedUser(t, dbc, "description-sync@example.com")
+	path := &types.Path{ID: uuid.New(), UserID: &user.ID, Title: "Recursion"}
+	build := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "Build",
+		Metadata: datatypes.JSON(`{"goal":"Learn loops and iteration"}`)}
+	derived := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 2, Title: "Derived",
+		Metadata: datatypes.JSON(`{"goal":"x","description":"Loops.","description_source":"derived_from_doc","description_doc_hash":"old"}`)}
+	edited := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 3, Title: "Edited",
+		Metadata: datatypes.JSON(`{"goal":"x","description":"My own words.","description_source":"user"}`)}
+	for _, row := range []any{path, build, derived, edited} {
+		if err := tx.Create(row).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	nodes := repos.NewPathNodeRepo(tx, log)
+	docs := repos.NewLearningNodeDocRepo(tx, log)
+	canon, _ := content.CanonicalizeJSON([]byte(descriptionSyncDocJSON))
+	for _, n := range []*types.PathNode{build, derived, edited} {
+		row := &types.LearningNodeDoc{UserID: user.ID, PathID: path.ID, PathNodeID: n.ID, SchemaVersion: 1,
+			DocJSON: datatypes.JSON(canon), ContentHash: content.HashBytes(canon), SourcesHash: "s"}
+		if err := docs.Upsert(dbc, row); err != nil {
+			t.Fatalf("upsert doc: %v", err)
+		}
+	}
+	stored, err := docs.GetByPathNodeID(dbc, build.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("reload doc: %v", err)
+	}
+	digest := types.DecodeNodeDocDigest(stored.Digest)
+	if digest.Version != types.NodeDocDigestVersion || digest.Summary == "" || len(digest.Objectives) != 2 {
+		t.Fatalf("digest not stored on commit: %s", stored.Digest)
+	}
+
+	out, err := NodeDescriptionSync(ctx, NodeDescriptionSyncDeps{Log: log, PathNodes: nodes, NodeDocs: docs},
+		NodeDescriptionSyncInput{OwnerUserID: user.ID, PathID: path.ID})
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if out.NodesChecked != 3 || out.NodesUpdated != 2 || out.NodesProtected != 1 {
+		t.Fatalf("out = %+v", out)
+	}
+
+	for _, tc := range []struct {
+		node       *types.PathNode
+		wantDesc   string
+		wantSource string
+	}{
+		{build, digest.Summary, types.PathNodeDescriptionSourceDoc},
+		{derived, digest.Summary, types.PathNodeDescriptionSourceDoc},
+		{edited, "My own words.", types.PathNodeDescriptionSourceUser},
+	} {
+		got, err := nodes.GetByID(dbc, tc.node.ID)
+		if err != nil || got == nil {
+			t.Fatalf("reload node: %v", err)
+		}
+		desc, source := types.PathNodeDescription(got.Metadata)
+		if desc != tc.wantDesc || source != tc.wantSource {
+			t.Fatalf("%s: description=%q source=%q", tc.node.Title, desc, source)
+		}
+		if goal := stringFromAny(decodeJSONMap(got.Metadata)["goal"]); goal == "" {
+			t.Fatalf("%s: build goal dropped: %s", tc.node.Title, got.Metadata)
+		}
+	}
+
+	// A derived write racing a user edit loses.
+	if updated, err := nodes.SetDescription(dbc, edited.ID, "derived", types.PathNodeDescriptionSourceDoc, nil); err != nil || updated {
+		t.Fatalf("derived write over user edit: updated=%v err=%v", updated, err)
+	}
+}
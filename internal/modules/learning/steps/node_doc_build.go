@@ -124,7 +124,17 @@ type NodeDocBuildInput struct {
 	VariantSnapshotIDByNode    map[uuid.UUID]string
 	VariantPolicyVersionByNode map[uuid.UUID]string
 	OptionalSlotsByNode        map[uuid.UUID][]docgen.DocOptionalSlot
-	Report                     func(stage string, pct int, message string)
+	// Emphasis regenerates the selected docs in a learner-chosen flavor. The profile reaches the
+	// prompt, the input hash, the generation run and the doc metadata; each replaced doc keeps
+	// its user-authored blocks and gets a "regenerate" revision holding the old content. Docs
+	// already regenerated with a profile keep it on later rebuilds without one.
+	Emphasis *docgen.NodeDocEmphasis
+	// Track selects which of each node's docs is built; "" is primary. Non-primary tracks are
+	// built from the same grounding as primary and need explicit NodeIDs.
+	Track string
+	// JobID is recorded on regenerate revisions.
+	JobID  uuid.UUID
+	Report func(stage string, pct int, message string)
 }
 
 type NodeDocBuildOutput struct {
@@ -167,6 +177,18 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 	if in.MaterialSetID == uuid.Nil {
 		return out, fmt.Errorf("node_doc_build: missing material_set_id")
 	}
+	track, ok := types.NormalizeNodeDocTrack(in.Track)
+	if !ok {
+		return out, fmt.Errorf("node_doc_build: unknown track %q", in.Track)
+	}
+	if track != types.NodeDocTrackPrimary {
+		if in.VariantOnly || in.MarkPending {
+			return out, fmt.Errorf("node_doc_build: track %q does not support variants or pending markers", track)
+		}
+		if len(in.NodeIDs) == 0 {
+			return out, fmt.Errorf("node_doc_build: track %q requires node_ids", track)
+		}
+	}
 
 	pathID, err := resolvePathID(ctx, deps.Bootstrap, in.OwnerUserID, in.MaterialSetID, in.PathID)
 	if err != nil {
@@ -177,7 +199,7 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 	reporter := newProgressReporter("docs", in.Report, 2, 2*time.Second)
 	reporter.Update(2, "Preparing unit docs")
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("node_doc_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "node_doc_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -185,9 +207,9 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("node_doc_build: adaptive params", "adaptive", adaptiveStageMeta("node_doc_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("node_doc_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "node_doc_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("node_doc_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "node_doc_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Safety: don't break legacy installs where migrations haven't created the new tables yet.
@@ -257,7 +279,12 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 		}
 	}
 
-	existingDocs, err := deps.NodeDocs.GetByPathNodeIDs(dbctx.Context{Ctx: ctx}, nodeIDs)
+	var existingDocs []*types.LearningNodeDoc
+	if track == types.NodeDocTrackPrimary {
+		existingDocs, err = deps.NodeDocs.GetByPathNodeIDs(dbctx.Context{Ctx: ctx}, nodeIDs)
+	} else {
+		existingDocs, err = nodeDocsOnTrack(ctx, deps.NodeDocs, in.NodeIDs, track)
+	}
 	if err != nil {
 		return out, err
 	}
@@ -596,6 +623,9 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 		}
 		nodeKindByID[node.ID] = nodeKind
 	}
+	// Concepts each node shares with an earlier node: the prompt references them instead of
+	// re-teaching, and the docs point back at the earlier unit (see NodeDocSeeAlsoRefresh).
+	coveredByNodeID := nodeDocCoveredConcepts(nodeDocContinuityNodes(nodes))
 
 	patternHierarchyJSON := ""
 	if v, ok := pathMeta["pattern_hierarchy"]; ok && v != nil {
@@ -1163,10 +1193,7 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 		}
 	}
 
-	maxConc := envInt("NODE_DOC_BUILD_CONCURRENCY", 4)
-	if maxConc < 1 {
-		maxConc = 1
-	}
+	maxConc := capPathDocGenConcurrency(envInt("NODE_DOC_BUILD_CONCURRENCY", 4))
 
 	// Outline generation (parallel, best-effort).
 	outlineSchema, oerr := schema.NodeDocOutlineV1()
@@ -1174,10 +1201,7 @@ func NodeDocBuild(ctx context.Context, deps NodeDocBuildDeps, in NodeDocBuildInp
 		return out, oerr
 	}
 	outlineByNodeID := map[uuid.UUID]content.NodeDocOutlineV1{}
-	outlineConc := envInt("NODE_DOC_OUTLINE_CONCURRENCY", maxConc)
-	if outlineConc < 1 {
-		outlineConc = 1
-	}
+	outlineConc := capPathDocGenConcurrency(envInt("NODE_DOC_OUTLINE_CONCURRENCY", maxConc))
 	og, octx := errgroup.WithContext(ctx)
 	og.SetLimit(outlineConc)
 	for i := range work {
@@ -1314,10 +1338,7 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 
 	nodeNarrativeByNodeID := map[uuid.UUID]string{}
 	if envBool("NODE_NARRATIVE_ENABLED", true) && deps.AI != nil {
-		narrConc := envInt("NODE_NARRATIVE_CONCURRENCY", maxConc)
-		if narrConc < 1 {
-			narrConc = 1
-		}
+		narrConc := capPathDocGenConcurrency(envInt("NODE_NARRATIVE_CONCURRENCY", maxConc))
 		ng, nctx := errgroup.WithContext(ctx)
 		ng.SetLimit(narrConc)
 		var nmu sync.Mutex
@@ -1493,6 +1514,8 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 	g.SetLimit(maxConc)
 
 	var written int32
+	var writtenMu sync.Mutex
+	var writtenIDs []uuid.UUID
 	var existingCount int32
 	var diagrams int32
 	var figures int32
@@ -1770,6 +1793,13 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 			if intentForPrompt == "" {
 				intentForPrompt = "(none)"
 			}
+			emphasis := nodeDocEmphasisFor(in.Emphasis, w.ExistingDoc)
+			continuityPrompt := nodeDocContinuityPrompt(coveredByNodeID[w.Node.ID])
+			regenerate := in.Emphasis != nil && !in.VariantOnly && w.ExistingDoc != nil
+			if regenerate && w.ExistingDoc.Frozen {
+				atomic.AddInt32(&existingCount, 1)
+				return nil
+			}
 			inputHash := nodeDocInputHash(nodeDocHashInput{
 				PromptVersion:       nodeDocPromptVersion,
 				SchemaVersion:       1,
@@ -1802,6 +1832,9 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 				Requirements:        nodeDocRequirementsFingerprint(reqs, diagramsDisabled, requireDiagrams),
 				MediaPatch:          mediaPatchMode,
 				OptionalSlots:       optionalSlots,
+				Emphasis:            emphasis.key(),
+				Continuity:          continuityPrompt,
+				Track:               track,
 			})
 			variantSnapshotID := ""
 			variantPolicyVersion := strings.TrimSpace(in.VariantPolicyVersion)
@@ -1837,7 +1870,10 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 						}
 					}
 				}
-			} else if w.ExistingDoc != nil && strings.TrimSpace(w.ExistingDoc.SourcesHash) == inputHash {
+			} else if w.ExistingDoc != nil && strings.TrimSpace(w.ExistingDoc.SourcesHash) == inputHash &&
+				(w.ExistingDoc.Frozen || len(content.NodeDocConceptStale(w.ExistingDoc.Metadata)) == 0) {
+				// Unchanged inputs keep the doc unless a concept it covers changed since it was
+				// written (concept_stale); frozen docs are kept either way.
 				atomic.AddInt32(&existingCount, 1)
 				return nil
 			}
@@ -1970,6 +2006,10 @@ PATTERN_CONTEXT_JSON (optional; path/module/lesson teaching patterns):
 		  - short_answer: kind="short_answer", options=[], answer_id=""; answer_md is the reference answer/explanation.
 		  - true_false: kind="true_false", options=[{id:"A",text:"True"},{id:"B",text:"False"}], answer_id="A"|"B".
 		  - mcq: kind="mcq", options has 3-5 options, answer_id matches one option id, answer_md explains why.
+		  - Every option has misconception_key. Set it to "" for the correct answer and for generic distractors.
+		    When a distractor is what a learner holding a specific misconception would pick, set a short
+		    lowercase snake_case key naming it (e.g. "confuses_mean_with_median"); reuse the same key for
+		    the same misconception across questions.
 		  - For each quick_check, include trigger_after_block_ids: 1–3 block IDs that teach the tested idea.
 		    These IDs MUST reference blocks that appear earlier in order (paragraph/callout/etc).
 		- Flashcards are short front/back recall prompts.
@@ -2228,7 +2268,7 @@ Return ONLY JSON matching schema.`,
 					formatChunkIDBullets(chunkIDs),
 					assetsJSON,
 					generatedFigures,
-				) + feedback
+				) + nodeDocEmphasisPrompt(emphasis) + nodeDocTrackPrompt(track) + continuityPrompt + feedback
 
 				promptPayload := strings.TrimSpace(system) + "\n\n" + strings.TrimSpace(user)
 				promptHash := content.HashBytes([]byte(promptPayload))
@@ -2255,7 +2295,7 @@ Return ONLY JSON matching schema.`,
 						lastErrors = []string{"generate_failed: " + genErr.Error()}
 						if deps.GenRuns != nil {
 							_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-								makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil),
+								emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil)),
 							})
 						}
 						// Retry bounded attempts (OpenAI client already retries transient HTTP failures).
@@ -2268,7 +2308,7 @@ Return ONLY JSON matching schema.`,
 						lastErrors = []string{"schema_unmarshal_failed"}
 						if deps.GenRuns != nil {
 							_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-								makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil),
+								emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil)),
 							})
 						}
 						continue
@@ -2287,7 +2327,7 @@ Return ONLY JSON matching schema.`,
 						lastErrors = append([]string{"outline_mismatch"}, outlineErrs...)
 						if deps.GenRuns != nil {
 							_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-								makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil),
+								emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil)),
 							})
 						}
 						continue
@@ -2323,7 +2363,7 @@ Return ONLY JSON matching schema.`,
 						lastErrors = append([]string{"convert_failed"}, convErrs...)
 						if deps.GenRuns != nil {
 							_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-								makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil),
+								emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, lastErrors, nil)),
 							})
 						}
 						continue
@@ -2481,6 +2521,10 @@ Return ONLY JSON matching schema.`,
 					}
 				}
 
+				// Mechanical repair of broken fences/math/tables; what can't be repaired is reported as
+				// constraint warnings below rather than failing the attempt.
+				doc, richText := content.ValidateNodeDocRichText(doc)
+
 				errs, metrics := content.ValidateNodeDocV1(doc, allowedChunkIDs, reqs)
 				if patchedUsed && metrics != nil {
 					metrics["media_patch"] = true
@@ -2501,6 +2545,12 @@ Return ONLY JSON matching schema.`,
 				if threadingInjected {
 					metrics["threading_injected"] = true
 				}
+				if !richText.Empty() {
+					metrics["rich_text"] = map[string]any{
+						"repaired":   len(richText.Repairs),
+						"unrepaired": len(richText.Issues),
+					}
+				}
 				if len(blueprintObjectiveAutofix) > 0 {
 					if metrics == nil {
 						metrics = map[string]any{}
@@ -2537,6 +2587,7 @@ Return ONLY JSON matching schema.`,
 				}
 				if blueprint != nil {
 					report := docgen.ValidateDocAgainstBlueprint(doc, *blueprint)
+					report.Violations = append(report.Violations, docgen.RichTextViolations(richText.Issues)...)
 					constraintReport = &report
 					if deps.ConstraintReports != nil {
 						reportJSON, _ := json.Marshal(report)
@@ -2565,19 +2616,40 @@ Return ONLY JSON matching schema.`,
 					lastErrors = errs
 					if deps.GenRuns != nil {
 						_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-							makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, errs, metrics),
+							emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "failed", nodeDocPromptVersion, attempt, latency, errs, metrics)),
 						})
 					}
 					continue
 				}
 
+				if stamped, changed := stampOutlineConceptKeys(doc, outline); changed {
+					doc = stamped
+				}
+
+				// Stamp every block with the run that produced it; the run row is written after the doc.
+				genRun := emphasis.stamp(makeGenRun("node_doc", nil, in.OwnerUserID, pathID, w.Node.ID, "succeeded", nodeDocPromptVersion, attempt, latency, nil, metrics))
+				content.StampNodeDocProvenance(doc, content.BlockProvenance{
+					Source:          content.ProvenanceSourceGeneration,
+					GenerationRunID: genRun.ID.String(),
+					PromptVersion:   nodeDocPromptVersion,
+					PolicyVersion:   policyVersion,
+				})
+
+				carriedBlocks := 0
+				if regenerate {
+					var prev content.NodeDocV1
+					if err := json.Unmarshal(w.ExistingDoc.DocJSON, &prev); err == nil {
+						doc, carriedBlocks = content.CarryOverUserBlocks(prev, doc)
+					}
+				}
+
 				// Persist the scrubbed-and-validated doc (not the raw model output bytes).
 				rawDocBytes, _ := json.Marshal(doc)
 				canon, cErr := content.CanonicalizeJSON(rawDocBytes)
 				if cErr != nil {
 					return cErr
 				}
-				contentHash := content.HashBytes(canon)
+				contentHash := content.NodeDocContentHash(canon)
 				sourcesHash := inputHash
 
 				docText, _ := metrics["doc_text"].(string)
@@ -2592,6 +2664,7 @@ Return ONLY JSON matching schema.`,
 						FallbackReason: "blueprint_missing",
 						CheckedAt:      time.Now().UTC().Format(time.RFC3339),
 					}
+					traceReport.Violations = append(traceReport.Violations, docgen.RichTextViolations(richText.Issues)...)
 				} else if strings.TrimSpace(traceReport.CheckedAt) == "" {
 					traceReport.CheckedAt = time.Now().UTC().Format(time.RFC3339)
 				}
@@ -2610,6 +2683,7 @@ Return ONLY JSON matching schema.`,
 					BlueprintVersion: blueprintVersion,
 					SlotFills:        slotFills,
 					ConstraintReport: *traceReport,
+					RichTextRepairs:  richText.Repairs,
 					CreatedAt:        time.Now().UTC().Format(time.RFC3339),
 				}
 				trace.TraceID = docgen.ComputeTraceID(trace)
@@ -2649,6 +2723,7 @@ Return ONLY JSON matching schema.`,
 						UserID:        in.OwnerUserID,
 						PathID:        pathID,
 						PathNodeID:    w.Node.ID,
+						Track:         track,
 						SchemaVersion: 1,
 						DocJSON:       datatypes.JSON(canon),
 						DocText:       docText,
@@ -2657,15 +2732,37 @@ Return ONLY JSON matching schema.`,
 						CreatedAt:     now,
 						UpdatedAt:     now,
 					}
-					if err := deps.NodeDocs.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
+					if w.ExistingDoc != nil {
+						// Regeneration replaces the doc we loaded; a concurrent writer makes this
+						// attempt stale and the job retries against the newer doc.
+						row.Version = w.ExistingDoc.Version
+					}
+					if emphasis != nil {
+						row.Metadata = datatypes.JSON(content.WithNodeDocEmphasis(nil, emphasis.Profile.Name, emphasis.FreeText))
+					}
+					if regenerate && deps.Revisions != nil {
+						docID = w.ExistingDoc.ID
+						row.ID = docID
+						revision := nodeDocRegenerateRevision(w.ExistingDoc, canon, *emphasis, genRun, trace.TraceID, carriedBlocks, in.JobID, now)
+						err := deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+							inner := dbctx.Context{Ctx: ctx, Tx: tx}
+							if err := deps.NodeDocs.Upsert(inner, row); err != nil {
+								return err
+							}
+							_, err := deps.Revisions.Create(inner, []*types.LearningNodeDocRevision{revision})
+							return err
+						})
+						if err != nil {
+							return err
+						}
+					} else if err := deps.NodeDocs.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
 						return err
 					}
 				}
 
 				if deps.GenRuns != nil {
-					_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
-						makeGenRun("node_doc", artifactID, in.OwnerUserID, pathID, w.Node.ID, "succeeded", nodeDocPromptVersion, attempt, latency, nil, metrics),
-					})
+					genRun.ArtifactID = artifactID
+					_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{genRun})
 				}
 
 				if deps.DocTraces != nil {
@@ -2719,6 +2816,11 @@ Return ONLY JSON matching schema.`,
 					}
 				}
 				atomic.AddInt32(&written, 1)
+				if !in.VariantOnly && track == types.NodeDocTrackPrimary {
+					writtenMu.Lock()
+					writtenIDs = append(writtenIDs, w.Node.ID)
+					writtenMu.Unlock()
+				}
 				return nil
 			}
 
@@ -2733,6 +2835,16 @@ Return ONLY JSON matching schema.`,
 	if err := g.Wait(); err != nil {
 		return out, err
 	}
+	if len(writtenIDs) > 0 {
+		// Best-effort: a miss only leaves the cross-references for the next refresh.
+		if _, err := NodeDocSeeAlsoRefresh(ctx, NodeDocSeeAlsoRefreshDeps{
+			Log:       deps.Log,
+			PathNodes: deps.PathNodes,
+			NodeDocs:  deps.NodeDocs,
+		}, NodeDocSeeAlsoRefreshInput{OwnerUserID: in.OwnerUserID, PathID: pathID, NodeIDs: writtenIDs}); err != nil {
+			deps.Log.Warn("node_doc_build: see_also refresh failed", "error", err, "path_id", pathID.String())
+		}
+	}
 	reporter.Update(98, "Unit docs ready")
 
 	out.DocsWritten = int(atomic.LoadInt32(&written))
@@ -2815,6 +2927,13 @@ type nodeDocHashInput struct {
 	Requirements        map[string]any
 	MediaPatch          bool
 	OptionalSlots       []docgen.DocOptionalSlot
+	// Emphasis is docgen.NodeDocEmphasis.Key; empty keeps hashes of unflavored docs unchanged.
+	Emphasis string
+	// Continuity is the previously-covered-concepts prompt section. It is built from path
+	// structure only, so rewriting an earlier doc never makes this one stale.
+	Continuity string
+	// Track is the doc track; primary is left out of the hash so existing hashes stay valid.
+	Track string
 }
 
 func nodeDocInputHash(in nodeDocHashInput) string {
@@ -2859,6 +2978,15 @@ func nodeDocInputHash(in nodeDocHashInput) string {
 	if len(in.OptionalSlots) > 0 {
 		payload["optional_slots"] = in.OptionalSlots
 	}
+	if strings.TrimSpace(in.Emphasis) != "" {
+		payload["emphasis"] = strings.TrimSpace(in.Emphasis)
+	}
+	if strings.TrimSpace(in.Continuity) != "" {
+		payload["continuity_hash"] = hashString(in.Continuity)
+	}
+	if t := strings.TrimSpace(in.Track); t != "" && t != types.NodeDocTrackPrimary {
+		payload["track"] = t
+	}
 	canon, err := content.CanonicalizeJSON(payload)
 	if err != nil {
 		return ""
@@ -3423,7 +3551,7 @@ func buildDocDeltasFromRevisions(ctx context.Context, deps NodeDocBuildDeps, nod
 	if max <= 0 || deps.Revisions == nil || node == nil || node.ID == uuid.Nil {
 		return nil
 	}
-	rows, err := deps.Revisions.ListByPathNodeID(dbctx.Context{Ctx: ctx}, node.ID, max*4)
+	rows, err := deps.Revisions.ListByPathNodeID(dbctx.Context{Ctx: ctx}, node.ID, types.NodeDocTrackPrimary, max*4)
 	if err != nil || len(rows) == 0 {
 		return nil
 	}
@@ -3780,3 +3908,15 @@ func requiredBlockKindsFromReqs(req content.NodeDocRequirements) []string {
 	}
 	return out
 }
+
+// capPathDocGenConcurrency bounds a per-path fan-out by the docgen per-path in-flight cap, so
+// a build running inline holds no more model calls for one path than the job scheduler would.
+func capPathDocGenConcurrency(n int) int {
+	if limit := services.DocGenPathInflightCap(); limit > 0 && n > limit {
+		n = limit
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
@@ -0,0 +1,310 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// nodeDocCoveredConcept is a concept of a node that an earlier node in the path already taught.
+type nodeDocCoveredConcept struct {
+	Key       string
+	NodeID    uuid.UUID
+	NodeTitle string
+}
+
+// nodeDocContinuityNode is the part of a path node the continuity pass reads. It is structural
+// only (plan metadata, never generated doc content), so an earlier doc being rewritten never
+// changes what a later node's prompt or input hash sees.
+type nodeDocContinuityNode struct {
+	ID    uuid.UUID
+	Index int
+	Title string
+	// Introduces are the concept keys the node teaches; empty for module overviews.
+	Introduces []string
+	// Uses are the keys checked against earlier nodes: the node's concept and prereq keys.
+	Uses []string
+}
+
+func nodeDocContinuityNodes(nodes []*types.PathNode) []nodeDocContinuityNode {
+	out := make([]nodeDocContinuityNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil || n.ID == uuid.Nil {
+			continue
+		}
+		meta := map[string]any{}
+		if len(n.Metadata) > 0 && string(n.Metadata) != "null" {
+			_ = json.Unmarshal(n.Metadata, &meta)
+		}
+		conceptKeys := dedupeStrings(stringSliceFromAny(meta["concept_keys"]))
+		cn := nodeDocContinuityNode{
+			ID:    n.ID,
+			Index: n.Index,
+			Title: strings.TrimSpace(n.Title),
+			Uses:  dedupeStrings(append(append([]string{}, conceptKeys...), stringSliceFromAny(meta["prereq_concept_keys"])...)),
+		}
+		if normalizePathNodeKind(stringFromAny(meta["node_kind"])) != "module" {
+			cn.Introduces = conceptKeys
+		}
+		out = append(out, cn)
+	}
+	return out
+}
+
+// nodeDocCoveredConcepts maps each node to the keys it uses that a node earlier in path order
+// introduced, attributed to the earliest such node.
+func nodeDocCoveredConcepts(nodes []nodeDocContinuityNode) map[uuid.UUID][]nodeDocCoveredConcept {
+	ordered := append([]nodeDocContinuityNode(nil), nodes...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+
+	introducedBy := map[string]nodeDocContinuityNode{}
+	out := map[uuid.UUID][]nodeDocCoveredConcept{}
+	for _, n := range ordered {
+		for _, k := range n.Uses {
+			key := strings.ToLower(strings.TrimSpace(k))
+			if by, ok := introducedBy[key]; ok && by.ID != n.ID {
+				out[n.ID] = append(out[n.ID], nodeDocCoveredConcept{Key: strings.TrimSpace(k), NodeID: by.ID, NodeTitle: by.Title})
+			}
+		}
+		for _, k := range n.Introduces {
+			key := strings.ToLower(strings.TrimSpace(k))
+			if _, ok := introducedBy[key]; key != "" && !ok {
+				introducedBy[key] = n
+			}
+		}
+	}
+	return out
+}
+
+// nodeDocContinuityPrompt is appended to the doc generation user prompt. It is capped by
+// NODE_DOC_CONTINUITY_MAX_CONCEPTS entries and NODE_DOC_CONTINUITY_PROMPT_CHARS characters.
+func nodeDocContinuityPrompt(covered []nodeDocCoveredConcept) string {
+	if len(covered) == 0 {
+		return ""
+	}
+	maxItems := envInt("NODE_DOC_CONTINUITY_MAX_CONCEPTS", 12)
+	maxChars := envInt("NODE_DOC_CONTINUITY_PROMPT_CHARS", 1200)
+
+	var b strings.Builder
+	b.WriteString("PREVIOUSLY_COVERED_CONCEPTS (taught in earlier units; reference them, don't re-teach):\n")
+	listed, omitted := 0, 0
+	for _, c := range covered {
+		line := fmt.Sprintf("- %s (covered in %q)\n", c.Key, c.NodeTitle)
+		if listed >= maxItems || b.Len()+len(line) > maxChars {
+			omitted++
+			continue
+		}
+		b.WriteString(line)
+		listed++
+	}
+	if listed == 0 {
+		return ""
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "- (+%d more covered earlier)\n", omitted)
+	}
+	b.WriteString("- For these, give at most a one-sentence reminder that names the earlier unit, then build on them; spend the space on what is new here.")
+	return "\n\n" + b.String()
+}
+
+// stampOutlineConceptKeys copies each outline section's concept_keys onto its heading (matched by
+// text) when the heading has none, so later docs can point at the section that teaches a concept.
+func stampOutlineConceptKeys(doc content.NodeDocV1, outline content.NodeDocOutlineV1) (content.NodeDocV1, bool) {
+	keysByHeading := map[string][]string{}
+	for _, sec := range outline.Sections {
+		h := strings.ToLower(strings.TrimSpace(sec.Heading))
+		if keys := dedupeStrings(sec.ConceptKeys); h != "" && len(keys) > 0 {
+			keysByHeading[h] = keys
+		}
+	}
+	if len(keysByHeading) == 0 {
+		return doc, false
+	}
+	changed := false
+	for i, b := range doc.Blocks {
+		if b == nil || content.BlockType(b) != "heading" || len(stringSliceFromAny(b["concept_keys"])) > 0 {
+			continue
+		}
+		keys, ok := keysByHeading[strings.ToLower(strings.TrimSpace(stringFromAny(b["text"])))]
+		if !ok {
+			continue
+		}
+		vals := make([]any, 0, len(keys))
+		for _, k := range keys {
+			vals = append(vals, k)
+		}
+		b["concept_keys"] = vals
+		doc.Blocks[i] = b
+		changed = true
+	}
+	return doc, changed
+}
+
+type NodeDocSeeAlsoRefreshDeps struct {
+	Log       *logger.Logger
+	PathNodes repos.PathNodeRepo
+	NodeDocs  repos.LearningNodeDocRepo
+}
+
+type NodeDocSeeAlsoRefreshInput struct {
+	OwnerUserID uuid.UUID
+	PathID      uuid.UUID
+	// NodeIDs limits the refresh to these nodes' docs; AfterNodeID to docs of nodes ordered after
+	// it. With neither, every doc in the path is refreshed.
+	NodeIDs     []uuid.UUID
+	AfterNodeID uuid.UUID
+}
+
+type NodeDocSeeAlsoRefreshOutput struct {
+	DocsChecked int `json:"docs_checked"`
+	DocsUpdated int `json:"docs_updated"`
+	// DocsSkipped counts frozen docs and docs that changed while being refreshed.
+	DocsSkipped int `json:"docs_skipped"`
+}
+
+// NodeDocSeeAlsoRefresh re-resolves the see_also cross-references of docs: every block whose
+// concept_keys include a concept an earlier node introduced points at that node and its
+// best-evidence block for the concept (content.BestConceptBlockID). Only doc_json changes; the
+// content hash ignores see_also, so nothing keyed on the doc's content goes stale.
+func NodeDocSeeAlsoRefresh(ctx context.Context, deps NodeDocSeeAlsoRefreshDeps, in NodeDocSeeAlsoRefreshInput) (NodeDocSeeAlsoRefreshOutput, error) {
+	out := NodeDocSeeAlsoRefreshOutput{}
+	if deps.Log == nil || deps.PathNodes == nil || deps.NodeDocs == nil {
+		return out, fmt.Errorf("node_doc_see_also_refresh: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.PathID == uuid.Nil {
+		return out, fmt.Errorf("node_doc_see_also_refresh: missing ids")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	nodes, err := deps.PathNodes.GetByPathIDs(dbc, []uuid.UUID{in.PathID})
+	if err != nil {
+		return out, err
+	}
+	nodeIDs := make([]uuid.UUID, 0, len(nodes))
+	indexByID := map[uuid.UUID]int{}
+	for _, n := range nodes {
+		if n != nil && n.ID != uuid.Nil {
+			nodeIDs = append(nodeIDs, n.ID)
+			indexByID[n.ID] = n.Index
+		}
+	}
+	if len(nodeIDs) == 0 {
+		return out, nil
+	}
+	docs, err := deps.NodeDocs.GetByPathNodeIDs(dbc, nodeIDs)
+	if err != nil {
+		return out, err
+	}
+	docByNodeID := map[uuid.UUID]*types.LearningNodeDoc{}
+	for _, d := range docs {
+		if d != nil && d.PathNodeID != uuid.Nil && d.UserID == in.OwnerUserID {
+			docByNodeID[d.PathNodeID] = d
+		}
+	}
+
+	targets := map[uuid.UUID]bool{}
+	switch {
+	case len(in.NodeIDs) > 0:
+		for _, id := range in.NodeIDs {
+			targets[id] = true
+		}
+	case in.AfterNodeID != uuid.Nil:
+		after, ok := indexByID[in.AfterNodeID]
+		if !ok {
+			return out, nil
+		}
+		for id, idx := range indexByID {
+			if idx > after {
+				targets[id] = true
+			}
+		}
+	default:
+		for _, id := range nodeIDs {
+			targets[id] = true
+		}
+	}
+
+	covered := nodeDocCoveredConcepts(nodeDocContinuityNodes(nodes))
+	parsed := map[uuid.UUID]*content.NodeDocV1{}
+	parse := func(nodeID uuid.UUID) *content.NodeDocV1 {
+		if doc, ok := parsed[nodeID]; ok {
+			return doc
+		}
+		var doc *content.NodeDocV1
+		if row := docByNodeID[nodeID]; row != nil {
+			var d content.NodeDocV1
+			if json.Unmarshal(row.DocJSON, &d) == nil {
+				doc = &d
+			}
+		}
+		parsed[nodeID] = doc
+		return doc
+	}
+
+	for _, nodeID := range nodeIDs {
+		row := docByNodeID[nodeID]
+		if !targets[nodeID] || row == nil {
+			continue
+		}
+		out.DocsChecked++
+		if row.Frozen {
+			out.DocsSkipped++
+			continue
+		}
+		var doc content.NodeDocV1
+		if err := json.Unmarshal(row.DocJSON, &doc); err != nil {
+			continue
+		}
+		refs := map[string]content.SeeAlsoRefV1{}
+		for _, c := range covered[nodeID] {
+			ref := content.SeeAlsoRefV1{NodeID: c.NodeID.String(), NodeTitle: c.NodeTitle}
+			if earlier := parse(c.NodeID); earlier != nil {
+				ref.BlockID = content.BestConceptBlockID(*earlier, c.Key)
+			}
+			refs[c.Key] = ref
+		}
+		updated, changed := content.ApplyNodeDocSeeAlso(doc, refs)
+		if !changed {
+			continue
+		}
+		raw, err := json.Marshal(updated)
+		if err != nil {
+			return out, err
+		}
+		canon, err := content.CanonicalizeJSON(raw)
+		if err != nil {
+			return out, err
+		}
+		next := *row
+		next.DocJSON = datatypes.JSON(canon)
+		next.ContentHash = content.NodeDocContentHash(canon)
+		if err := deps.NodeDocs.UpdateWithVersion(dbc, &next, row.Version); err != nil {
+			if errors.Is(err, repos.ErrStaleDoc) {
+				out.DocsSkipped++
+				continue
+			}
+			return out, err
+		}
+		out.DocsUpdated++
+	}
+
+	deps.Log.Info("node_doc_see_also_refresh: done",
+		"path_id", in.PathID.String(),
+		"docs_checked", out.DocsChecked,
+		"docs_updated", out.DocsUpdated,
+		"docs_skipped", out.DocsSkipped,
+	)
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package steps
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+func continuityTestNode(t *testing.T, index int, title string, meta map[string]any) *types.PathNode {
+	t.Helper()
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal meta: %v", err)
+	}
+	return &types.PathNode{ID: uuid.New(), Index: index, Title: title, Metadata: datatypes.JSON(raw)}
+}
+
+func TestNodeDocCoveredConcepts(t *testing.T) {
+	module := continuityTestNode(t, 0, "Module", map[string]any{"node_kind": "module", "concept_keys": []string{"net_force", "mass", "friction"}})
+	unit1 := continuityTestNode(t, 1, "Forces", map[string]any{"concept_keys": []string{"net_force", "mass"}})
+	unit2 := continuityTestNode(t, 2, "Newton's second law", map[string]any{"concept_keys": []string{"acceleration", "Net_Force"}, "prereq_concept_keys": []string{"mass"}})
+	unit3 := continuityTestNode(t, 3, "Friction", map[string]any{"concept_keys": []string{"friction", "acceleration"}})
+
+	// Out of order on purpose: path order comes from Index.
+	covered := nodeDocCoveredConcepts(nodeDocContinuityNodes([]*types.PathNode{unit3, unit1, module, unit2}))
+
+	if got := covered[unit1.ID]; len(got) != 0 {
+		t.Fatalf("module overviews must not count as introducing concepts, got %+v", got)
+	}
+	want2 := []nodeDocCoveredConcept{
+		{Key: "Net_Force", NodeID: unit1.ID, NodeTitle: "Forces"},
+		{Key: "mass", NodeID: unit1.ID, NodeTitle: "Forces"},
+	}
+	if got := covered[unit2.ID]; !reflect.DeepEqual(got, want2) {
+		t.Fatalf("unit2 covered = %+v, want %+v", got, want2)
+	}
+	want3 := []nodeDocCoveredConcept{{Key: "acceleration", NodeID: unit2.ID, NodeTitle: "Newton's second law"}}
+	if got := covered[unit3.ID]; !reflect.DeepEqual(got, want3) {
+		t.Fatalf("unit3 covered = %+v, want %+v (friction is new outside the module overview)", got, want3)
+	}
+}
+
+func TestNodeDocContinuityPromptBudget(t *testing.T) {
+	if nodeDocContinuityPrompt(nil) != "" {
+		t.Fatalf("expected no section without covered concepts")
+	}
+	covered := make([]nodeDocCoveredConcept, 0, 20)
+	for i := 0; i < 20; i++ {
+		covered = append(covered, nodeDocCoveredConcept{Key: "concept_" + strings.Repeat("x", i), NodeTitle: "Unit 1"})
+	}
+
+	t.Setenv("NODE_DOC_CONTINUITY_MAX_CONCEPTS", "5")
+	t.Setenv("NODE_DOC_CONTINUITY_PROMPT_CHARS", "")
+	prompt := nodeDocContinuityPrompt(covered)
+	if !strings.Contains(prompt, "PREVIOUSLY_COVERED_CONCEPTS") || !strings.Contains(prompt, `(covered in "Unit 1")`) {
+		t.Fatalf("prompt missing section: %q", prompt)
+	}
+	if n := strings.Count(prompt, "(covered in "); n != 5 {
+		t.Fatalf("listed %d concepts, want 5", n)
+	}
+	if !strings.Contains(prompt, "(+15 more covered earlier)") {
+		t.Fatalf("prompt should note omitted concepts: %q", prompt)
+	}
+
+	t.Setenv("NODE_DOC_CONTINUITY_MAX_CONCEPTS", "")
+	t.Setenv("NODE_DOC_CONTINUITY_PROMPT_CHARS", "300")
+	prompt = nodeDocContinuityPrompt(covered)
+	list := prompt[:strings.Index(prompt, "- For these")]
+	if len(list) > 300+len("- (+99 more covered earlier)\n")+2 {
+		t.Fatalf("concept list exceeds the char budget: %d chars", len(list))
+	}
+}
+
+func TestStampOutlineConceptKeys(t *testing.T) {
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "h1", "type": "heading", "text": "Net Force "},
+		{"id": "h2", "type": "heading", "text": "Mass", "concept_keys": []any{"own"}},
+		{"id": "p1", "type": "paragraph", "md": "Net force"},
+	}}
+	outline := content.NodeDocOutlineV1{Sections: []content.NodeDocOutlineSectionV1{
+		{Heading: "net force", ConceptKeys: []string{"net_force"}},
+		{Heading: "Mass", ConceptKeys: []string{"mass"}},
+	}}
+	doc, changed := stampOutlineConceptKeys(doc, outline)
+	if !changed {
+		t.Fatalf("expected heading to be stamped")
+	}
+	if got := stringSliceFromAny(doc.Blocks[0]["concept_keys"]); !reflect.DeepEqual(got, []string{"net_force"}) {
+		t.Fatalf("h1 concept_keys = %v", got)
+	}
+	if got := stringSliceFromAny(doc.Blocks[1]["concept_keys"]); !reflect.DeepEqual(got, []string{"own"}) {
+		t.Fatalf("existing heading keys overwritten: %v", got)
+	}
+	if _, ok := doc.Blocks[2]["concept_keys"]; ok {
+		t.Fatalf("non-heading block stamped")
+	}
+}
@@ -0,0 +1,108 @@
+package steps
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+)
+
+// nodeDocRegenerateOperation is the revision operation of a full-doc regeneration.
+const nodeDocRegenerateOperation = "regenerate"
+
+// nodeDocEmphasis is the emphasis a doc is generated with; a nil *nodeDocEmphasis means none.
+type nodeDocEmphasis struct {
+	docgen.NodeDocEmphasis
+}
+
+// nodeDocEmphasisFor resolves the emphasis for one doc: the requested one, else the profile the
+// existing doc was last regenerated with, so a plain rebuild doesn't silently drop it.
+func nodeDocEmphasisFor(requested *docgen.NodeDocEmphasis, existing *types.LearningNodeDoc) *nodeDocEmphasis {
+	if requested != nil {
+		return &nodeDocEmphasis{*requested}
+	}
+	if existing == nil {
+		return nil
+	}
+	profile, freeText := content.NodeDocEmphasisOf(existing.Metadata)
+	if profile == "" {
+		return nil
+	}
+	e, err := docgen.NewNodeDocEmphasis(profile, freeText)
+	if err != nil {
+		return nil
+	}
+	return &nodeDocEmphasis{e}
+}
+
+func (e *nodeDocEmphasis) key() string {
+	if e == nil {
+		return ""
+	}
+	return e.Key()
+}
+
+// stamp records the profile on a generation run.
+func (e *nodeDocEmphasis) stamp(run *types.LearningDocGenerationRun) *types.LearningDocGenerationRun {
+	if e != nil && run != nil {
+		run.EmphasisProfile = e.Profile.Name
+	}
+	return run
+}
+
+// nodeDocEmphasisPrompt is appended to the doc generation user prompt.
+func nodeDocEmphasisPrompt(e *nodeDocEmphasis) string {
+	if e == nil {
+		return ""
+	}
+	return "\n\n" + e.PromptSection()
+}
+
+func nodeDocRegenerateRevision(prev *types.LearningNodeDoc, after []byte, e nodeDocEmphasis, run *types.LearningDocGenerationRun, traceID string, carriedBlocks int, jobID uuid.UUID, now time.Time) *types.LearningNodeDocRevision {
+	before, err := content.CanonicalizeJSON([]byte(prev.DocJSON))
+	if err != nil {
+		before = []byte(prev.DocJSON)
+	}
+	meta := map[string]any{
+		"emphasis_profile":      e.Profile.Name,
+		"trace_id":              traceID,
+		"user_blocks_carried":   carriedBlocks,
+		"previous_content_hash": prev.ContentHash,
+	}
+	if e.FreeText != "" {
+		meta["free_text_emphasis"] = e.FreeText
+	}
+	rev := &types.LearningNodeDocRevision{
+		ID:             uuid.New(),
+		DocID:          prev.ID,
+		UserID:         prev.UserID,
+		PathID:         prev.PathID,
+		PathNodeID:     prev.PathNodeID,
+		Track:          prev.Track,
+		Operation:      nodeDocRegenerateOperation,
+		CitationPolicy: "allow_new",
+		Instruction:    e.FreeText,
+		Selection:      datatypes.JSON([]byte(`null`)),
+		BeforeJSON:     datatypes.JSON(before),
+		AfterJSON:      datatypes.JSON(after),
+		Status:         "succeeded",
+		PromptVersion:  nodeDocPromptVersion,
+		CreatedAt:      now,
+	}
+	if run != nil {
+		rev.Model = run.Model
+		meta["generation_run_id"] = run.ID.String()
+	}
+	if raw, err := json.Marshal(meta); err == nil {
+		rev.Metadata = datatypes.JSON(raw)
+	}
+	if jobID != uuid.Nil {
+		rev.JobID = &jobID
+	}
+	return rev
+}
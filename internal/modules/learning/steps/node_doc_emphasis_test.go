@@ -0,0 +1,91 @@
+package steps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+)
+
+func TestNodeDocEmphasisPromptCarriesProfile(t *testing.T) {
+	if got := nodeDocEmphasisPrompt(nil); got != "" {
+		t.Fatalf("prompt without emphasis = %q", got)
+	}
+
+	e, err := docgen.NewNodeDocEmphasis(docgen.EmphasisExamplesHeavy, "use cooking analogies")
+	if err != nil {
+		t.Fatalf("emphasis: %v", err)
+	}
+	prompt := nodeDocEmphasisPrompt(nodeDocEmphasisFor(&e, nil))
+	for _, want := range append([]string{
+		"EMPHASIS_PROFILE",
+		"profile: examples_heavy",
+		"example_density: high",
+		"theory_depth: normal",
+		"reading_level: standard",
+		"practice_focus: normal",
+		"learner note: use cooking analogies",
+	}, e.Profile.Directives...) {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	eli5, _ := docgen.NewNodeDocEmphasis(docgen.EmphasisELI5, "")
+	prompt = nodeDocEmphasisPrompt(nodeDocEmphasisFor(&eli5, nil))
+	if !strings.Contains(prompt, "reading_level: simple") || strings.Contains(prompt, "learner note") {
+		t.Fatalf("eli5 prompt:\n%s", prompt)
+	}
+}
+
+func TestNodeDocEmphasisForCarriesExistingProfile(t *testing.T) {
+	existing := &types.LearningNodeDoc{
+		Metadata: datatypes.JSON(content.WithNodeDocEmphasis(nil, docgen.EmphasisExamPrep, "past papers")),
+	}
+	got := nodeDocEmphasisFor(nil, existing)
+	if got == nil || got.Profile.Name != docgen.EmphasisExamPrep || got.FreeText != "past papers" {
+		t.Fatalf("carried emphasis = %+v", got)
+	}
+
+	requested, _ := docgen.NewNodeDocEmphasis(docgen.EmphasisTheoryLight, "")
+	if got := nodeDocEmphasisFor(&requested, existing); got.Profile.Name != docgen.EmphasisTheoryLight {
+		t.Fatalf("requested emphasis overridden: %+v", got)
+	}
+	if got := nodeDocEmphasisFor(nil, &types.LearningNodeDoc{}); got != nil {
+		t.Fatalf("emphasis from empty metadata = %+v", got)
+	}
+
+	run := &types.LearningDocGenerationRun{}
+	nodeDocEmphasisFor(&requested, nil).stamp(run)
+	if run.EmphasisProfile != docgen.EmphasisTheoryLight {
+		t.Fatalf("run profile = %q", run.EmphasisProfile)
+	}
+}
+
+func TestNodeDocInputHashEmphasis(t *testing.T) {
+	base := nodeDocHashInput{PromptVersion: "v1", SchemaVersion: 1, NodeID: uuid.New(), NodeTitle: "Limits"}
+	plain := nodeDocInputHash(base)
+
+	var none *nodeDocEmphasis
+	base.Emphasis = none.key()
+	if nodeDocInputHash(base) != plain {
+		t.Fatalf("empty emphasis changed the hash")
+	}
+
+	e, _ := docgen.NewNodeDocEmphasis(docgen.EmphasisExamPrep, "")
+	base.Emphasis = e.Key()
+	exam := nodeDocInputHash(base)
+	if exam == plain {
+		t.Fatalf("emphasis did not change the hash")
+	}
+	e, _ = docgen.NewNodeDocEmphasis(docgen.EmphasisExamPrep, "past papers")
+	base.Emphasis = e.Key()
+	if nodeDocInputHash(base) == exam {
+		t.Fatalf("free text did not change the hash")
+	}
+}
@@ -0,0 +1,210 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/platform/tts"
+)
+
+type NodeDocNarrateDeps struct {
+	DB  *gorm.DB
+	Log *logger.Logger
+
+	Path      repos.PathRepo
+	PathNodes repos.PathNodeRepo
+	NodeDocs  repos.LearningNodeDocRepo
+	NodeAudio repos.LearningNodeAudioRepo
+
+	Bucket gcp.BucketService
+	TTS    tts.Provider
+}
+
+type NodeDocNarrateInput struct {
+	OwnerUserID uuid.UUID
+	PathNodeID  uuid.UUID
+	// Force re-synthesizes even when the stored audio matches the current doc.
+	Force bool
+}
+
+type NodeDocNarrateOutput struct {
+	DocID       uuid.UUID `json:"doc_id"`
+	ContentHash string    `json:"content_hash"`
+	Segments    int       `json:"segments"`
+	Synthesized int       `json:"synthesized"`
+	Reused      int       `json:"reused"`
+	DurationMS  int       `json:"duration_ms"`
+	UpToDate    bool      `json:"up_to_date,omitempty"`
+}
+
+func NodeDocNarrate(ctx context.Context, deps NodeDocNarrateDeps, in NodeDocNarrateInput) (NodeDocNarrateOutput, error) {
+	out := NodeDocNarrateOutput{}
+	start := time.Now()
+	if deps.DB == nil || deps.Log == nil || deps.Path == nil || deps.PathNodes == nil || deps.NodeDocs == nil || deps.NodeAudio == nil || deps.Bucket == nil || deps.TTS == nil {
+		return out, fmt.Errorf("node_doc_narrate: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.PathNodeID == uuid.Nil {
+		return out, fmt.Errorf("node_doc_narrate: missing owner_user_id or path_node_id")
+	}
+	dbc := dbctx.Context{Ctx: ctx}
+
+	node, err := deps.PathNodes.GetByID(dbc, in.PathNodeID)
+	if err != nil {
+		return out, err
+	}
+	if node == nil || node.PathID == uuid.Nil {
+		return out, fmt.Errorf("node_doc_narrate: path node not found")
+	}
+	pathRow, err := deps.Path.GetByID(dbc, node.PathID)
+	if err != nil {
+		return out, err
+	}
+	if pathRow == nil || pathRow.UserID == nil || *pathRow.UserID != in.OwnerUserID {
+		return out, fmt.Errorf("node_doc_narrate: path not found")
+	}
+
+	docRow, err := deps.NodeDocs.GetByPathNodeID(dbc, node.ID)
+	if err != nil {
+		return out, err
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 {
+		return out, fmt.Errorf("node_doc_narrate: doc not found")
+	}
+	var doc content.NodeDocV1
+	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+		return out, fmt.Errorf("node_doc_narrate: invalid doc json: %w", err)
+	}
+	out.DocID = docRow.ID
+	out.ContentHash = docRow.ContentHash
+
+	existing, err := deps.NodeAudio.ListByPathNodeID(dbc, node.ID)
+	if err != nil {
+		return out, err
+	}
+	if !in.Force && nodeAudioUpToDate(existing, docRow.ContentHash) {
+		out.UpToDate = true
+		out.Segments = len(existing)
+		for _, row := range existing {
+			out.DurationMS += row.DurationMS
+		}
+		return out, nil
+	}
+
+	segments := content.NodeDocNarrationSegments(doc, deps.TTS.MaxChars())
+	if len(segments) == 0 {
+		return out, fmt.Errorf("node_doc_narrate: doc has no narratable text")
+	}
+
+	// Unchanged segments (same provider, voice and text) keep their existing audio object.
+	reusable := map[string]*types.LearningNodeAudio{}
+	for _, row := range existing {
+		if row != nil && row.TextHash != "" {
+			reusable[row.TextHash] = row
+		}
+	}
+
+	provider := deps.TTS.Name()
+	voice := deps.TTS.Voice()
+	prefix := content.NodeAudioPrefix(node.PathID.String(), node.ID.String())
+	rows := make([]*types.LearningNodeAudio, 0, len(segments))
+	startMS := 0
+	for i, seg := range segments {
+		textHash := content.HashBytes([]byte(provider + "\n" + voice + "\n" + seg.Text))
+		row := &types.LearningNodeAudio{
+			UserID:         in.OwnerUserID,
+			PathID:         node.PathID,
+			PathNodeID:     node.ID,
+			SegmentIndex:   i,
+			DocID:          docRow.ID,
+			DocContentHash: docRow.ContentHash,
+			BlockID:        seg.BlockID,
+			BlockType:      seg.BlockType,
+			Part:           seg.Part,
+			TextHash:       textHash,
+			StartMS:        startMS,
+			Provider:       provider,
+			Voice:          voice,
+			Status:         repolearning.NodeAudioStatusReady,
+		}
+		if prev := reusable[textHash]; prev != nil && !in.Force {
+			row.StorageKey = prev.StorageKey
+			row.MimeType = prev.MimeType
+			row.DurationMS = prev.DurationMS
+			out.Reused++
+		} else {
+			audio, err := deps.TTS.Synthesize(ctx, tts.Request{Text: seg.Text, Voice: voice})
+			if err != nil {
+				return out, fmt.Errorf("node_doc_narrate: synthesize segment %d: %w", i, err)
+			}
+			key := prefix + textHash + "." + audio.Extension
+			if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: nodeObjectContext(ctx, node.PathID, node.ID)}, gcp.BucketCategoryMaterial, key, bytes.NewReader(audio.Data)); err != nil {
+				return out, fmt.Errorf("node_doc_narrate: upload segment %d: %w", i, err)
+			}
+			row.StorageKey = key
+			row.MimeType = audio.MimeType
+			row.DurationMS = audio.DurationMS
+			out.Synthesized++
+		}
+		startMS += row.DurationMS
+		rows = append(rows, row)
+	}
+
+	replaced, err := deps.NodeAudio.ReplaceForNode(dbc, node.ID, rows)
+	if err != nil {
+		return out, err
+	}
+
+	// Best-effort cleanup of objects no segment references anymore.
+	kept := map[string]bool{}
+	for _, row := range rows {
+		kept[row.StorageKey] = true
+	}
+	for _, old := range replaced {
+		if old == nil || kept[old.StorageKey] || !strings.HasPrefix(old.StorageKey, prefix) {
+			continue
+		}
+		kept[old.StorageKey] = true
+		if err := deps.Bucket.DeleteFile(dbc, gcp.BucketCategoryMaterial, old.StorageKey); err != nil {
+			deps.Log.Warn("node_doc_narrate: failed to delete replaced audio", "error", err, "storage_key", old.StorageKey)
+		}
+	}
+
+	out.Segments = len(rows)
+	out.DurationMS = startMS
+	deps.Log.Info("node_doc_narrate: narrated doc",
+		"path_node_id", node.ID,
+		"segments", out.Segments,
+		"synthesized", out.Synthesized,
+		"reused", out.Reused,
+		"duration_ms", out.DurationMS,
+		"provider", provider,
+		"elapsed_ms", time.Since(start).Milliseconds(),
+	)
+	return out, nil
+}
+
+func nodeAudioUpToDate(rows []*types.LearningNodeAudio, contentHash string) bool {
+	if len(rows) == 0 || strings.TrimSpace(contentHash) == "" {
+		return false
+	}
+	for _, row := range rows {
+		if row == nil || row.Status != repolearning.NodeAudioStatusReady || row.DocContentHash != contentHash {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package steps
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
@@ -61,6 +63,9 @@ type NodeDocPatchInput struct {
 	CitationPolicy string
 	Selection      NodeDocPatchSelection
 	JobID          uuid.UUID
+
+	// Track is the doc track to patch; "" is primary.
+	Track string
 }
 
 type NodeDocPatchOutput struct {
@@ -92,6 +97,12 @@ type NodeDocPatchPreviewOutput struct {
 
 const nodeDocPatchPromptVersion = "node_doc_patch_v1@2"
 
+// nodeDocPatchMaxAttempts bounds optimistic-lock rebases when other writers keep winning.
+const nodeDocPatchMaxAttempts = 4
+
+// errNodeDocFrozen fails patches against a doc its author froze.
+var errNodeDocFrozen = errors.New("node_doc_patch: doc is frozen")
+
 func NodeDocPatch(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInput) (NodeDocPatchOutput, error) {
 	out := NodeDocPatchOutput{}
 	if deps.DB == nil || deps.Log == nil || deps.Path == nil || deps.PathNodes == nil || deps.NodeDocs == nil || deps.Revisions == nil {
@@ -108,7 +119,7 @@ func NodeDocPatch(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInp
 	if action == "" {
 		action = "rewrite"
 	}
-	if action != "rewrite" && action != "regen_media" {
+	if action != "rewrite" && action != "regen_media" && action != nodeDocSummaryRefreshAction {
 		return out, fmt.Errorf("node_doc_patch: invalid action %q", action)
 	}
 
@@ -137,13 +148,19 @@ func NodeDocPatch(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInp
 		}
 	}
 
-	docRow, err := deps.NodeDocs.GetByPathNodeID(dbctx.Context{Ctx: ctx}, in.PathNodeID)
+	docRow, err := deps.NodeDocs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, in.PathNodeID, in.Track)
 	if err != nil {
 		return out, err
 	}
 	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
 		return out, fmt.Errorf("node_doc_patch: doc not found")
 	}
+	if docRow.Frozen {
+		return out, errNodeDocFrozen
+	}
+	if action == nodeDocSummaryRefreshAction {
+		return nodeDocSummaryRefresh(ctx, deps, in, node, docRow)
+	}
 
 	var doc content.NodeDocV1
 	if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
@@ -448,33 +465,11 @@ func NodeDocPatch(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInp
 		}
 	}
 
-	rawDoc, _ := json.Marshal(doc)
-	canon, err := content.CanonicalizeJSON(rawDoc)
-	if err != nil {
-		return out, err
-	}
-	contentHash := content.HashBytes(canon)
-	sourcesHash := content.HashSources(promptVersion, 1, content.CitedChunkIDsFromNodeDocV1(doc))
-	docText, _ := content.NodeDocMetrics(doc)["doc_text"].(string)
-	docText = content.SanitizeStringForPostgres(docText)
-
-	now := time.Now().UTC()
-	docID := docRow.ID
-	if docID == uuid.Nil {
-		docID = uuid.New()
-	}
-	updatedDoc := &types.LearningNodeDoc{
-		ID:            docID,
-		UserID:        in.OwnerUserID,
-		PathID:        node.PathID,
-		PathNodeID:    node.ID,
-		SchemaVersion: 1,
-		DocJSON:       datatypes.JSON(canon),
-		DocText:       docText,
-		ContentHash:   contentHash,
-		SourcesHash:   sourcesHash,
-		CreatedAt:     docRow.CreatedAt,
-		UpdatedAt:     now,
+	// Repair broken fences/math/tables in the patched block; the outcome goes on the revision.
+	richTextIssues := content.ValidateBlockRichText(doc.Blocks[idx])
+	var revisionMeta datatypes.JSON
+	if meta := docgen.RichTextRevisionMetadata(richTextIssues); meta != nil {
+		revisionMeta = mustJSON(meta)
 	}
 
 	selectionJSON := datatypes.JSON([]byte(`null`))
@@ -486,45 +481,118 @@ func NodeDocPatch(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInp
 		})
 	}
 
-	revID := uuid.New()
-	revision := &types.LearningNodeDocRevision{
-		ID:             revID,
-		DocID:          docID,
-		UserID:         in.OwnerUserID,
-		PathID:         node.PathID,
-		PathNodeID:     node.ID,
-		BlockID:        blockID,
-		BlockType:      blockType,
-		Operation:      action,
-		CitationPolicy: resolvedPolicy,
-		Instruction:    strings.TrimSpace(in.Instruction),
-		Selection:      selectionJSON,
-		BeforeJSON:     beforeJSON,
-		AfterJSON:      datatypes.JSON(canon),
-		Status:         "succeeded",
-		Error:          "",
-		Model:          strings.TrimSpace(modelName),
-		PromptVersion:  strings.TrimSpace(promptVersion),
-		TokensIn:       0,
-		TokensOut:      0,
-		CreatedAt:      now,
-	}
-	if in.JobID != uuid.Nil {
-		revision.JobID = &in.JobID
-	}
-
-	err = deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		inner := dbctx.Context{Ctx: ctx, Tx: tx}
-		if err := deps.NodeDocs.Upsert(inner, updatedDoc); err != nil {
-			return err
-		}
-		if _, err := deps.Revisions.Create(inner, []*types.LearningNodeDocRevision{revision}); err != nil {
-			return err
-		}
-		return nil
-	})
-	if err != nil {
-		return out, err
+	// Competing writers (figure regen, manual edits, lazy ID write-backs) can commit while we were
+	// generating. On a stale write, rebase the patched block onto the latest doc and retry.
+	patchedBlock := doc.Blocks[idx]
+	summaryStale := blockPatchStalesSummary(blockType, patchedBlock)
+	var docID, revID uuid.UUID
+	for attempt := 1; ; attempt++ {
+		revID = uuid.New()
+		stampPatchedBlockProvenance(doc, blockID, content.BlockProvenance{
+			Source:        content.ProvenanceSourcePatch,
+			RevisionID:    revID.String(),
+			PromptVersion: strings.TrimSpace(promptVersion),
+			PolicyVersion: docgen.DocPolicyVersion(),
+		})
+		rawDoc, _ := json.Marshal(doc)
+		canon, err := content.CanonicalizeJSON(rawDoc)
+		if err != nil {
+			return out, err
+		}
+		contentHash := content.NodeDocContentHash(canon)
+		sourcesHash := content.HashSources(promptVersion, 1, content.CitedChunkIDsFromNodeDocV1(doc))
+		docText, _ := content.NodeDocMetrics(doc)["doc_text"].(string)
+		docText = content.SanitizeStringForPostgres(docText)
+
+		now := time.Now().UTC()
+		docID = docRow.ID
+		updatedDoc := &types.LearningNodeDoc{
+			ID:            docID,
+			UserID:        in.OwnerUserID,
+			PathID:        node.PathID,
+			PathNodeID:    node.ID,
+			Track:         docRow.Track,
+			SchemaVersion: 1,
+			DocJSON:       datatypes.JSON(canon),
+			DocText:       docText,
+			ContentHash:   contentHash,
+			SourcesHash:   sourcesHash,
+			CreatedAt:     docRow.CreatedAt,
+			UpdatedAt:     now,
+		}
+		// Re-read per attempt so a rebase keeps the latest row's other metadata keys.
+		meta, metaChanged := []byte(docRow.Metadata), false
+		if summaryStale {
+			meta, metaChanged = content.WithNodeDocSummaryStale(meta, true), true
+		}
+		// The rewritten block covers its concepts again, so their concept_stale entries go.
+		if cleared, ok := content.WithoutNodeDocConceptStale(meta, stringSliceFromAny(patchedBlock["concept_keys"])); ok {
+			meta, metaChanged = cleared, true
+		}
+		if metaChanged {
+			updatedDoc.Metadata = datatypes.JSON(meta)
+		}
+
+		revision := &types.LearningNodeDocRevision{
+			ID:             revID,
+			DocID:          docID,
+			UserID:         in.OwnerUserID,
+			PathID:         node.PathID,
+			PathNodeID:     node.ID,
+			Track:          docRow.Track,
+			BlockID:        blockID,
+			BlockType:      blockType,
+			Operation:      action,
+			CitationPolicy: resolvedPolicy,
+			Instruction:    strings.TrimSpace(in.Instruction),
+			Selection:      selectionJSON,
+			BeforeJSON:     beforeJSON,
+			AfterJSON:      datatypes.JSON(canon),
+			Status:         "succeeded",
+			Error:          "",
+			Model:          strings.TrimSpace(modelName),
+			PromptVersion:  strings.TrimSpace(promptVersion),
+			TokensIn:       0,
+			TokensOut:      0,
+			Metadata:       revisionMeta,
+			CreatedAt:      now,
+		}
+		if in.JobID != uuid.Nil {
+			revision.JobID = &in.JobID
+		}
+
+		err = deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			inner := dbctx.Context{Ctx: ctx, Tx: tx}
+			if err := deps.NodeDocs.UpdateWithVersion(inner, updatedDoc, docRow.Version); err != nil {
+				return err
+			}
+			if _, err := deps.Revisions.Create(inner, []*types.LearningNodeDocRevision{revision}); err != nil {
+				return err
+			}
+			return nil
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repos.ErrStaleDoc) || attempt >= nodeDocPatchMaxAttempts {
+			return out, err
+		}
+
+		deps.Log.Info("node_doc_patch: doc changed concurrently; rebasing", "path_node_id", node.ID.String(), "block_id", blockID, "attempt", attempt)
+		docRow, err = deps.NodeDocs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, in.PathNodeID, in.Track)
+		if err != nil {
+			return out, err
+		}
+		if docRow != nil && docRow.Frozen {
+			// Frozen while we were generating.
+			return out, errNodeDocFrozen
+		}
+		doc, err = rebaseNodeDocPatch(docRow, blockID, patchedBlock)
+		if err != nil {
+			return out, err
+		}
+		beforeCanon, _ := content.CanonicalizeJSON([]byte(docRow.DocJSON))
+		beforeJSON = datatypes.JSON(beforeCanon)
 	}
 
 	out.DocID = docID
@@ -585,7 +653,7 @@ func NodeDocPatchPreview(ctx context.Context, deps NodeDocPatchDeps, in NodeDocP
 		}
 	}
 
-	docRow, err := deps.NodeDocs.GetByPathNodeID(dbctx.Context{Ctx: ctx}, in.PathNodeID)
+	docRow, err := deps.NodeDocs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, in.PathNodeID, in.Track)
 	if err != nil {
 		return out, err
 	}
@@ -43,8 +43,41 @@ func findBlockIndex(blocks []map[string]any, blockID string, blockIndex int) (in
 	return -1, ""
 }
 
+// rebaseNodeDocPatch re-applies an already-patched block onto the latest stored doc after a stale
+// write. The target is re-located by block ID only since indexes may have shifted under the other
+// writer; if the block is gone the patch cannot be applied.
+func rebaseNodeDocPatch(current *types.LearningNodeDoc, blockID string, patched map[string]any) (content.NodeDocV1, error) {
+	var doc content.NodeDocV1
+	if current == nil || len(current.DocJSON) == 0 || string(current.DocJSON) == "null" {
+		return doc, fmt.Errorf("node_doc_patch: doc not found")
+	}
+	if err := json.Unmarshal(current.DocJSON, &doc); err != nil {
+		return doc, fmt.Errorf("node_doc_patch: doc invalid json")
+	}
+	if withIDs, changed := content.EnsureNodeDocBlockIDs(doc); changed {
+		doc = withIDs
+	}
+	blockID = strings.TrimSpace(blockID)
+	idx, _ := findBlockIndex(doc.Blocks, blockID, -1)
+	if blockID == "" || idx < 0 {
+		return doc, fmt.Errorf("node_doc_patch: block %q removed by concurrent update", blockID)
+	}
+	doc.Blocks[idx] = patched
+
+	allowed := map[string]bool{}
+	for _, id := range content.CitedChunkIDsFromNodeDocV1(doc) {
+		if id != "" {
+			allowed[id] = true
+		}
+	}
+	if errs, _ := content.ValidateNodeDocV1(doc, allowed, content.NodeDocRequirements{}); len(errs) > 0 {
+		return doc, fmt.Errorf("node_doc_patch: validation failed after rebase: %s", strings.Join(errs, "; "))
+	}
+	return doc, nil
+}
+
 func buildBlockPatchPrompt(doc content.NodeDocV1, blockType string, blockID string, block map[string]any, in NodeDocPatchInput, policy string, allowed map[string]bool, excerpts string) string {
-	blockJSON, _ := json.Marshal(block)
+	blockJSON, _ := json.Marshal(content.WithoutBlockProvenance(block))
 
 	allowedIDs := make([]string, 0, len(allowed))
 	for id := range allowed {
@@ -649,7 +682,7 @@ func regenerateFigure(ctx context.Context, deps NodeDocPatchDeps, row *types.Lea
 		row.Slot,
 		content.HashBytes([]byte(prompt)),
 	)
-	if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(img.Bytes)); err != nil {
+	if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: nodeObjectContext(ctx, row.PathID, row.PathNodeID)}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(img.Bytes)); err != nil {
 		return nil, err
 	}
 	publicURL := deps.Bucket.GetPublicURL(gcp.BucketCategoryMaterial, storageKey)
@@ -765,7 +798,7 @@ func regenerateVideo(ctx context.Context, deps NodeDocPatchDeps, row *types.Lear
 		content.HashBytes([]byte(prompt)),
 		ext,
 	)
-	if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(vid.Bytes)); err != nil {
+	if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: nodeObjectContext(ctx, row.PathID, row.PathNodeID)}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(vid.Bytes)); err != nil {
 		return nil, err
 	}
 	publicURL := deps.Bucket.GetPublicURL(gcp.BucketCategoryMaterial, storageKey)
@@ -865,3 +898,13 @@ func updateVideoBlock(block map[string]any, row *types.LearningNodeVideo) map[st
 	out["url"] = strings.TrimSpace(row.AssetURL)
 	return out
 }
+
+// stampPatchedBlockProvenance stamps the block with blockID; after a rebase it may have moved.
+func stampPatchedBlockProvenance(doc content.NodeDocV1, blockID string, p content.BlockProvenance) {
+	for _, b := range doc.Blocks {
+		if b != nil && strings.TrimSpace(stringFromAny(b["id"])) == blockID {
+			content.StampBlockProvenance(b, p)
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package steps
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+func testNodeDocRow(t *testing.T, blocks []map[string]any) *types.LearningNodeDoc {
+	t.Helper()
+	raw, err := json.Marshal(content.NodeDocV1{
+		SchemaVersion: 1,
+		Title:         "Loops",
+		ConceptKeys:   []string{"loops"},
+		Blocks:        blocks,
+	})
+	if err != nil {
+		t.Fatalf("marshal doc: %v", err)
+	}
+	return &types.LearningNodeDoc{ID: uuid.New(), DocJSON: datatypes.JSON(raw), Version: 3}
+}
+
+func TestRebaseNodeDocPatchKeepsConcurrentChanges(t *testing.T) {
+	// The other writer inserted a block at the top (shifting indexes) and edited a sibling.
+	current := testNodeDocRow(t, []map[string]any{
+		{"id": "heading_new", "type": "heading", "level": 2, "text": "Inserted by figure regen"},
+		{"id": "code_a", "type": "code", "code": "edited concurrently"},
+		{"id": "code_b", "type": "code", "code": "old body"},
+	})
+	patched := map[string]any{"id": "code_b", "type": "code", "code": "patched body"}
+
+	doc, err := rebaseNodeDocPatch(current, "code_b", patched)
+	if err != nil {
+		t.Fatalf("rebase: %v", err)
+	}
+	if len(doc.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(doc.Blocks))
+	}
+	if got := doc.Blocks[2]["code"]; got != "patched body" {
+		t.Fatalf("patched block not applied by id: %v", got)
+	}
+	if got := doc.Blocks[1]["code"]; got != "edited concurrently" {
+		t.Fatalf("concurrent sibling edit lost: %v", got)
+	}
+	if got := doc.Blocks[0]["id"]; got != "heading_new" {
+		t.Fatalf("concurrent insert lost: %v", got)
+	}
+}
+
+func TestRebaseNodeDocPatchBlockRemoved(t *testing.T) {
+	current := testNodeDocRow(t, []map[string]any{
+		{"id": "code_a", "type": "code", "code": "still here"},
+	})
+	_, err := rebaseNodeDocPatch(current, "code_b", map[string]any{"id": "code_b", "type": "code", "code": "x"})
+	if err == nil || !strings.Contains(err.Error(), "removed by concurrent update") {
+		t.Fatalf("expected removed-block error, got %v", err)
+	}
+	if _, err := rebaseNodeDocPatch(nil, "code_b", nil); err == nil {
+		t.Fatalf("expected error for missing doc")
+	}
+}
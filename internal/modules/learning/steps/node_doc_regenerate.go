@@ -0,0 +1,98 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+type NodeDocRegenerateInput struct {
+	OwnerUserID   uuid.UUID
+	MaterialSetID uuid.UUID
+	PathID        uuid.UUID
+	PathNodeID    uuid.UUID
+	// Profile names a docgen emphasis profile; FreeText is the learner's optional note.
+	Profile  string
+	FreeText string
+	JobID    uuid.UUID
+	Report   func(stage string, pct int, message string)
+
+	// Track is the doc track to (re)generate; "" is primary. A non-primary track doc is created
+	// on first request from the primary doc's grounding, and Profile is optional for it.
+	Track string
+}
+
+type NodeDocRegenerateOutput struct {
+	PathID      uuid.UUID `json:"path_id"`
+	Profile     string    `json:"emphasis_profile"`
+	Regenerated bool      `json:"regenerated"`
+	Track       string    `json:"track"`
+}
+
+// NodeDocRegenerate rewrites one existing node doc with an emphasis profile through the regular
+// doc builder (see NodeDocBuildInput.Emphasis). A doc already generated from the same inputs
+// and emphasis is left alone, so a repeated request is a no-op. For a non-primary track the
+// primary doc must exist; the track doc itself is created when missing.
+func NodeDocRegenerate(ctx context.Context, deps NodeDocBuildDeps, in NodeDocRegenerateInput) (NodeDocRegenerateOutput, error) {
+	out := NodeDocRegenerateOutput{}
+	if deps.NodeDocs == nil || deps.Revisions == nil {
+		return out, fmt.Errorf("node_doc_regenerate: missing deps")
+	}
+	if in.OwnerUserID == uuid.Nil || in.PathNodeID == uuid.Nil {
+		return out, fmt.Errorf("node_doc_regenerate: missing ids")
+	}
+	track, ok := types.NormalizeNodeDocTrack(in.Track)
+	if !ok {
+		return out, fmt.Errorf("node_doc_regenerate: unknown track %q", in.Track)
+	}
+	out.Track = track
+	var emphasis *docgen.NodeDocEmphasis
+	if track == types.NodeDocTrackPrimary || strings.TrimSpace(in.Profile) != "" {
+		e, err := docgen.NewNodeDocEmphasis(in.Profile, in.FreeText)
+		if err != nil {
+			return out, fmt.Errorf("node_doc_regenerate: %w", err)
+		}
+		emphasis = &e
+		out.Profile = e.Profile.Name
+	}
+
+	docRow, err := deps.NodeDocs.GetByPathNodeID(dbctx.Context{Ctx: ctx}, in.PathNodeID)
+	if err != nil {
+		return out, err
+	}
+	if docRow == nil || len(docRow.DocJSON) == 0 || string(docRow.DocJSON) == "null" {
+		return out, fmt.Errorf("node_doc_regenerate: doc not found")
+	}
+	if track != types.NodeDocTrackPrimary {
+		docRow, err = deps.NodeDocs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, in.PathNodeID, track)
+		if err != nil {
+			return out, err
+		}
+	}
+	if docRow != nil && docRow.Frozen {
+		return out, errNodeDocFrozen
+	}
+
+	built, err := NodeDocBuild(ctx, deps, NodeDocBuildInput{
+		OwnerUserID:   in.OwnerUserID,
+		MaterialSetID: in.MaterialSetID,
+		PathID:        in.PathID,
+		NodeIDs:       []uuid.UUID{in.PathNodeID},
+		Emphasis:      emphasis,
+		Track:         track,
+		JobID:         in.JobID,
+		Report:        in.Report,
+	})
+	if err != nil {
+		return out, err
+	}
+	out.PathID = built.PathID
+	out.Regenerated = built.DocsWritten > 0
+	return out, nil
+}
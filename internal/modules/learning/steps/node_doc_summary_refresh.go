@@ -0,0 +1,189 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
+)
+
+const (
+	nodeDocSummaryPromptVersion = "node_doc_summary_v1@1"
+
+	// nodeDocSummaryRefreshAction is the patch action that only regenerates the doc summary.
+	nodeDocSummaryRefreshAction = "refresh_summary"
+	// nodeDocSummaryRefreshOperation is the revision operation of a summary-only rewrite.
+	nodeDocSummaryRefreshOperation = "summary_refresh"
+
+	// nodeDocSummaryMaxDocChars bounds the doc text sent to the summary prompt.
+	nodeDocSummaryMaxDocChars = 12000
+
+	envNodeDocSummaryStaleTokens     = "NODE_DOC_SUMMARY_STALE_TOKENS"
+	defaultNodeDocSummaryStaleTokens = 120
+)
+
+// nodeDocSummaryStaleTokens is the patched-block size (estimated tokens) above which a block
+// patch marks the doc summary stale. <= 0 disables the flag.
+func nodeDocSummaryStaleTokens() int {
+	return envutil.Int(envNodeDocSummaryStaleTokens, defaultNodeDocSummaryStaleTokens)
+}
+
+// blockPatchStalesSummary reports whether a patched block is large enough that the doc summary
+// has likely drifted from it.
+func blockPatchStalesSummary(blockType string, block map[string]any) bool {
+	limit := nodeDocSummaryStaleTokens()
+	if limit <= 0 || block == nil {
+		return false
+	}
+	return estimateTokens(blockTextForQuery(blockType, block)) > limit
+}
+
+func nodeDocSummarySchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"summary": map[string]any{"type": "string"},
+		},
+		"required":             []string{"summary"},
+		"additionalProperties": false,
+	}
+}
+
+// generateNodeDocSummary writes a new summary from the doc's blocks alone; the old summary is
+// left out of the prompt so its drift isn't carried forward.
+func generateNodeDocSummary(ctx context.Context, ai openai.Client, doc content.NodeDocV1) (string, error) {
+	doc.Summary = ""
+	blockText, _ := content.NodeDocMetrics(doc)["doc_text"].(string)
+	blockText = shorten(blockText, nodeDocSummaryMaxDocChars)
+	if strings.TrimSpace(blockText) == "" {
+		return "", fmt.Errorf("node_doc_summary_refresh: doc has no text")
+	}
+
+	sys := "You write the top-level summary of a learning document. Return JSON that matches the schema exactly. Summarize only what the document text covers, in at most two plain sentences with no markdown."
+	user := "Document text:\n" + blockText
+	obj, err := ai.GenerateJSON(ctx, sys, user, "node_doc_summary", nodeDocSummarySchema())
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(stringFromAny(obj["summary"]))
+	if obj["summary"] == nil || summary == "" {
+		return "", fmt.Errorf("node_doc_summary_refresh: empty summary")
+	}
+	return summary, nil
+}
+
+// nodeDocSummaryRefresh regenerates only the doc summary and commits it as a "summary_refresh"
+// revision. Blocks are never touched (see content.ReplaceNodeDocSummary); the content hash and
+// doc text change with the summary, and the summary_stale flag is cleared.
+func nodeDocSummaryRefresh(ctx context.Context, deps NodeDocPatchDeps, in NodeDocPatchInput, node *types.PathNode, docRow *types.LearningNodeDoc) (NodeDocPatchOutput, error) {
+	out := NodeDocPatchOutput{}
+	if deps.AI == nil {
+		return out, fmt.Errorf("node_doc_patch: ai client missing")
+	}
+	modelName := openAIModelFromEnv()
+
+	var docID, revID uuid.UUID
+	for attempt := 1; ; attempt++ {
+		var doc content.NodeDocV1
+		if err := json.Unmarshal(docRow.DocJSON, &doc); err != nil {
+			return out, fmt.Errorf("node_doc_patch: doc invalid json")
+		}
+		summary, err := generateNodeDocSummary(ctx, deps.AI, doc)
+		if err != nil {
+			return out, err
+		}
+		canon, err := content.ReplaceNodeDocSummary(docRow.DocJSON, summary)
+		if err != nil {
+			return out, err
+		}
+		beforeCanon, _ := content.CanonicalizeJSON([]byte(docRow.DocJSON))
+		doc.Summary = summary
+		docText, _ := content.NodeDocMetrics(doc)["doc_text"].(string)
+
+		now := time.Now().UTC()
+		docID = docRow.ID
+		updatedDoc := &types.LearningNodeDoc{
+			ID:            docID,
+			UserID:        in.OwnerUserID,
+			PathID:        node.PathID,
+			PathNodeID:    node.ID,
+			Track:         docRow.Track,
+			SchemaVersion: docRow.SchemaVersion,
+			DocJSON:       datatypes.JSON(canon),
+			DocText:       content.SanitizeStringForPostgres(docText),
+			ContentHash:   content.NodeDocContentHash(canon),
+			SourcesHash:   docRow.SourcesHash,
+			Metadata:      datatypes.JSON(content.WithNodeDocSummaryStale(docRow.Metadata, false)),
+			CreatedAt:     docRow.CreatedAt,
+			UpdatedAt:     now,
+		}
+
+		revID = uuid.New()
+		revision := &types.LearningNodeDocRevision{
+			ID:            revID,
+			DocID:         docID,
+			UserID:        in.OwnerUserID,
+			PathID:        node.PathID,
+			PathNodeID:    node.ID,
+			Track:         docRow.Track,
+			Operation:     nodeDocSummaryRefreshOperation,
+			Instruction:   strings.TrimSpace(in.Instruction),
+			Selection:     datatypes.JSON([]byte(`null`)),
+			BeforeJSON:    datatypes.JSON(beforeCanon),
+			AfterJSON:     datatypes.JSON(canon),
+			Status:        "succeeded",
+			Model:         modelName,
+			PromptVersion: nodeDocSummaryPromptVersion,
+			CreatedAt:     now,
+		}
+		if in.JobID != uuid.Nil {
+			revision.JobID = &in.JobID
+		}
+
+		err = deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			inner := dbctx.Context{Ctx: ctx, Tx: tx}
+			if err := deps.NodeDocs.UpdateWithVersion(inner, updatedDoc, docRow.Version); err != nil {
+				return err
+			}
+			_, err := deps.Revisions.Create(inner, []*types.LearningNodeDocRevision{revision})
+			return err
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repos.ErrStaleDoc) || attempt >= nodeDocPatchMaxAttempts {
+			return out, err
+		}
+
+		// The blocks changed under us; summarize the latest ones instead.
+		deps.Log.Info("node_doc_summary_refresh: doc changed concurrently; regenerating", "path_node_id", node.ID.String(), "attempt", attempt)
+		docRow, err = deps.NodeDocs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, in.PathNodeID, in.Track)
+		if err != nil {
+			return out, err
+		}
+		if docRow == nil || len(docRow.DocJSON) == 0 {
+			return out, fmt.Errorf("node_doc_patch: doc not found")
+		}
+		if docRow.Frozen {
+			return out, errNodeDocFrozen
+		}
+	}
+
+	out.DocID = docID
+	out.RevisionID = revID
+	out.Action = nodeDocSummaryRefreshAction
+	return out, nil
+}
@@ -0,0 +1,153 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// summaryRefreshDocJSON has a block without an id on purpose: block patches backfill IDs, a
+// summary refresh must not.
+const summaryRefreshDocJSON = `{"schema_version":1,"title":"Loops","summary":"Old summary about recursion.","concept_keys":["loops"],"x_extra":{"keep":true},
+	"blocks":[
+		{"id":"h1","type":"heading","level":2,"text":"For loops"},
+		{"id":"p1","type":"paragraph","md":"A for loop repeats a body a fixed number of times.","citations":[{"chunk_id":"c1"}]},
+		{"type":"paragraph","md":"While loops repeat until a condition fails."}
+	]}`
+
+func docBlocksJSON(t *testing.T, raw []byte) string {
+	t.Helper()
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &top); err != nil {
+		t.Fatalf("unmarshal doc: %v", err)
+	}
+	canon, err := content.CanonicalizeJSON([]byte(top["blocks"]))
+	if err != nil {
+		t.Fatalf("canonicalize blocks: %v", err)
+	}
+	return string(canon)
+}
+
+func TestSummaryRefreshLeavesBlocksUntouched(t *testing.T) {
+	ai := &fixtureAI{outputs: []string{`{"summary":"  For and while loops repeat work.  "}`}}
+	var doc content.NodeDocV1
+	if err := json.Unmarshal([]byte(summaryRefreshDocJSON), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	summary, err := generateNodeDocSummary(context.Background(), ai, doc)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	after, err := content.ReplaceNodeDocSummary([]byte(summaryRefreshDocJSON), summary)
+	if err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	if got, want := docBlocksJSON(t, after), docBlocksJSON(t, []byte(summaryRefreshDocJSON)); got != want {
+		t.Fatalf("blocks changed:\n got %s\nwant %s", got, want)
+	}
+	var out map[string]any
+	_ = json.Unmarshal(after, &out)
+	if out["summary"] != "For and while loops repeat work." || out["title"] != "Loops" || out["x_extra"] == nil {
+		t.Fatalf("top-level fields = %v", out)
+	}
+	blocks := out["blocks"].([]any)
+	if _, ok := blocks[2].(map[string]any)["id"]; ok {
+		t.Fatalf("summary refresh assigned a block id: %v", blocks[2])
+	}
+
+	empty := &fixtureAI{outputs: []string{`{"summary":"   "}`}}
+	if _, err := generateNodeDocSummary(context.Background(), empty, doc); err == nil {
+		t.Fatalf("expected error for an empty summary")
+	}
+}
+
+func TestBlockPatchStalesSummary(t *testing.T) {
+	long := map[string]any{"type": "paragraph", "md": strings.Repeat("word ", 200)}
+	short := map[string]any{"type": "paragraph", "md": "Tiny fix."}
+	if !blockPatchStalesSummary("paragraph", long) || blockPatchStalesSummary("paragraph", short) {
+		t.Fatalf("default threshold misclassified blocks")
+	}
+	t.Setenv(envNodeDocSummaryStaleTokens, "0")
+	if blockPatchStalesSummary("paragraph", long) {
+		t.Fatalf("threshold 0 should disable the flag")
+	}
+
+	meta := content.WithNodeDocSummaryStale([]byte(`{"other":1}`), true)
+	if !content.NodeDocSummaryStale(meta) || !strings.Contains(string(meta), `"other":1`) {
+		t.Fatalf("stale meta = %s", meta)
+	}
+	if content.NodeDocSummaryStale(content.WithNodeDocSummaryStale(meta, false)) || content.NodeDocSummaryStale(nil) {
+		t.Fatalf("cleared/empty meta should not be stale")
+	}
+}
+
+func TestNodeDocPatchRefreshSummaryCommitsRevision(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+
+	user := testutil.SeedUser(t, dbc, "summary-refresh@example.com")
+	path := &types.Path{ID: uuid.New(), UserID: &user.ID, Title: "Loops"}
+	node := &types.PathNode{ID: uuid.New(), PathID: path.ID, Index: 1, Title: "Loops"}
+	canon, _ := content.CanonicalizeJSON([]byte(summaryRefreshDocJSON))
+	docRow := &types.LearningNodeDoc{
+		ID: uuid.New(), UserID: user.ID, PathID: path.ID, PathNodeID: node.ID, SchemaVersion: 1,
+		DocJSON: datatypes.JSON(canon), ContentHash: content.HashBytes(canon), SourcesHash: "s",
+		Metadata: datatypes.JSON(`{"summary_stale":true}`), Version: 1,
+	}
+	for _, row := range []any{path, node, docRow} {
+		if err := tx.Create(row).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	docs := repos.NewLearningNodeDocRepo(tx, log)
+	revisions := repos.NewLearningNodeDocRevisionRepo(tx, log)
+	out, err := NodeDocPatch(ctx, NodeDocPatchDeps{
+		DB:        tx,
+		Log:       log,
+		Path:      repos.NewPathRepo(tx, log),
+		PathNodes: repos.NewPathNodeRepo(tx, log),
+		NodeDocs:  docs,
+		Revisions: revisions,
+		AI:        &fixtureAI{outputs: []string{`{"summary":"For and while loops repeat work."}`}},
+	}, NodeDocPatchInput{OwnerUserID: user.ID, PathNodeID: node.ID, BlockIndex: -1, Action: "refresh_summary"})
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	got, err := docs.GetByPathNodeID(dbc, node.ID)
+	if err != nil || got == nil {
+		t.Fatalf("reload doc: %v", err)
+	}
+	if docBlocksJSON(t, got.DocJSON) != docBlocksJSON(t, canon) {
+		t.Fatalf("summary refresh modified blocks: %s", got.DocJSON)
+	}
+	if got.ContentHash == docRow.ContentHash || got.ContentHash != content.HashBytes(got.DocJSON) {
+		t.Fatalf("content hash not updated: %s", got.ContentHash)
+	}
+	if content.NodeDocSummaryStale(got.Metadata) || got.Version != 2 {
+		t.Fatalf("metadata=%s version=%d", got.Metadata, got.Version)
+	}
+
+	var rev types.LearningNodeDocRevision
+	if err := tx.Where("id = ?", out.RevisionID).First(&rev).Error; err != nil {
+		t.Fatalf("load revision: %v", err)
+	}
+	if rev.Operation != "summary_refresh" || rev.BlockID != "" || out.Action != "refresh_summary" {
+		t.Fatalf("revision = %+v out = %+v", rev, out)
+	}
+}
@@ -0,0 +1,40 @@
+package steps
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/docgen"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// nodeDocsOnTrack loads the existing docs of one track for the given nodes; nodes without a doc
+// on that track are skipped.
+func nodeDocsOnTrack(ctx context.Context, docs repos.LearningNodeDocRepo, nodeIDs []uuid.UUID, track string) ([]*types.LearningNodeDoc, error) {
+	out := make([]*types.LearningNodeDoc, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		if id == uuid.Nil {
+			continue
+		}
+		doc, err := docs.GetByPathNodeIDAndTrack(dbctx.Context{Ctx: ctx}, id, track)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+// nodeDocTrackPrompt is appended to the doc generation user prompt; primary adds nothing.
+func nodeDocTrackPrompt(track string) string {
+	section := docgen.TrackPromptSection(track)
+	if section == "" {
+		return ""
+	}
+	return "\n\n" + section
+}
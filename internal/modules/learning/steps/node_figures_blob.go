@@ -0,0 +1,91 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
+)
+
+// nodeObjectContext tags uploads made with the returned context with the node they belong to.
+func nodeObjectContext(ctx context.Context, pathID, nodeID uuid.UUID) context.Context {
+	tags := gcp.ObjectTags{}
+	if pathID != uuid.Nil {
+		tags.PathID = pathID.String()
+	}
+	if nodeID != uuid.Nil {
+		tags.NodeID = nodeID.String()
+	}
+	return gcp.WithObjectTags(ctx, tags)
+}
+
+// ensureFigureBlobObject uploads the blob bytes when this caller created the index row or when
+// the object is missing (e.g. a concurrent creator's upload failed). Content-addressed keys make
+// a duplicate upload harmless.
+func ensureFigureBlobObject(ctx context.Context, bucket gcp.BucketService, storageKey string, data []byte, created bool) error {
+	if bucket == nil || strings.TrimSpace(storageKey) == "" {
+		return nil
+	}
+	if !created {
+		if _, err := bucket.GetObjectAttrs(ctx, gcp.BucketCategoryMaterial, storageKey); err == nil {
+			return nil
+		}
+	}
+	return bucket.UploadFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(data))
+}
+
+// acquireFigureBlob takes the slot's reference on row's blob. A slot re-rendered to the bytes it
+// already holds (prevHash) keeps the reference from its previous render instead of taking a
+// second one; acquired reports whether a new reference was taken.
+func acquireFigureBlob(dbc dbctx.Context, blobs repos.FigureBlobRepo, prevHash string, row *types.FigureBlob) (blob *types.FigureBlob, created, acquired bool, err error) {
+	if row != nil && strings.TrimSpace(prevHash) != "" && strings.TrimSpace(prevHash) == strings.TrimSpace(row.ContentHash) {
+		blob, err := blobs.GetByContentHash(dbc, row.ContentHash)
+		if err != nil || blob != nil {
+			return blob, false, false, err
+		}
+		// The previous render's row is gone; fall through and take a fresh reference.
+	}
+	blob, created, err = blobs.Acquire(dbc, row)
+	if err != nil {
+		return nil, false, false, err
+	}
+	return blob, created, blob != nil, nil
+}
+
+// releaseFigureBlob drops one reference to a shared figure. The object is deleted while the index
+// row is still locked so a concurrent Acquire either sees the row (and keeps the object) or
+// re-inserts it after the delete has happened (and re-uploads).
+func releaseFigureBlob(ctx context.Context, db *gorm.DB, blobs repos.FigureBlobRepo, bucket gcp.BucketService, contentHash string) error {
+	contentHash = strings.TrimSpace(contentHash)
+	if db == nil || blobs == nil || contentHash == "" {
+		return nil
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		blob, err := blobs.Release(dbctx.Context{Ctx: ctx, Tx: tx}, contentHash)
+		if err != nil || blob == nil || blob.RefCount > 0 || bucket == nil {
+			return err
+		}
+		return bucket.DeleteFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, blob.StorageKey)
+	})
+}
+
+// releaseReplacedFigureBlobs releases blobs held by previous rows whose slot was overwritten.
+func releaseReplacedFigureBlobs(ctx context.Context, db *gorm.DB, blobs repos.FigureBlobRepo, bucket gcp.BucketService, previous []*types.LearningNodeFigure, slots map[int]bool) []error {
+	var errs []error
+	for _, r := range previous {
+		if r == nil || strings.TrimSpace(r.ContentHash) == "" || !slots[r.Slot] {
+			continue
+		}
+		if err := releaseFigureBlob(ctx, db, blobs, bucket, r.ContentHash); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
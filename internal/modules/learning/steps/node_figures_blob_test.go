@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	repolearning "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+func TestAcquireFigureBlobRerenderSameBytes(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+
+	ctx := context.Background()
+	dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+	blobs := repolearning.NewFigureBlobRepo(db, testutil.Logger(t))
+
+	hash := "hash-" + uuid.NewString()
+	blobFor := func(h string) *types.FigureBlob {
+		return &types.FigureBlob{ContentHash: h, StorageKey: "generated/figures_cas/" + h + ".png", MimeType: "image/png", ByteLen: 10}
+	}
+	refCount := func(h string) int {
+		t.Helper()
+		got, err := blobs.GetByContentHash(dbc, h)
+		if err != nil {
+			t.Fatalf("GetByContentHash(%s): %v", h, err)
+		}
+		if got == nil {
+			return 0
+		}
+		return got.RefCount
+	}
+
+	// First render of the slot takes the reference.
+	blob, created, acquired, err := acquireFigureBlob(dbc, blobs, "", blobFor(hash))
+	if err != nil || blob == nil || !created || !acquired {
+		t.Fatalf("first render: blob=%+v created=%v acquired=%v err=%v", blob, created, acquired, err)
+	}
+
+	// Regenerating to identical bytes reuses the slot's reference.
+	for i := 0; i < 2; i++ {
+		blob, created, acquired, err = acquireFigureBlob(dbc, blobs, hash, blobFor(hash))
+		if err != nil || blob == nil || created || acquired || blob.StorageKey != blobFor(hash).StorageKey {
+			t.Fatalf("re-render %d: blob=%+v created=%v acquired=%v err=%v", i, blob, created, acquired, err)
+		}
+		if n := refCount(hash); n != 1 {
+			t.Fatalf("re-render %d: ref_count = %d, want 1", i, n)
+		}
+	}
+
+	// New bytes take a fresh reference; the caller then releases the previous one.
+	next := "hash-" + uuid.NewString()
+	if _, _, acquired, err := acquireFigureBlob(dbc, blobs, hash, blobFor(next)); err != nil || !acquired {
+		t.Fatalf("changed render: acquired=%v err=%v", acquired, err)
+	}
+	if _, err := blobs.Release(dbc, hash); err != nil {
+		t.Fatalf("Release(prev): %v", err)
+	}
+	if n := refCount(hash); n != 0 {
+		t.Fatalf("previous blob ref_count = %d, want dropped", n)
+	}
+	if n := refCount(next); n != 1 {
+		t.Fatalf("new blob ref_count = %d, want 1", n)
+	}
+
+	// A slot whose previous blob row vanished takes a fresh reference even for identical bytes.
+	if _, created, acquired, err := acquireFigureBlob(dbc, blobs, hash, blobFor(hash)); err != nil || !created || !acquired {
+		t.Fatalf("re-render after drop: created=%v acquired=%v err=%v", created, acquired, err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content/schema"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/index"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	pc "github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
@@ -38,6 +39,10 @@ type NodeFiguresPlanBuildDeps struct {
 	Figures   repos.LearningNodeFigureRepo
 	GenRuns   repos.LearningDocGenerationRunRepo
 
+	// Optional: release shared figure blobs held by replanned slots.
+	FigureBlobs repos.FigureBlobRepo
+	Bucket      gcp.BucketService
+
 	Files  repos.MaterialFileRepo
 	Chunks repos.MaterialChunkRepo
 
@@ -83,7 +88,7 @@ func NodeFiguresPlanBuild(ctx context.Context, deps NodeFiguresPlanBuildDeps, in
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("node_figures_plan_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "node_figures_plan_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -91,9 +96,9 @@ func NodeFiguresPlanBuild(ctx context.Context, deps NodeFiguresPlanBuildDeps, in
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("node_figures_plan_build: adaptive params", "adaptive", adaptiveStageMeta("node_figures_plan_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("node_figures_plan_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "node_figures_plan_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("node_figures_plan_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "node_figures_plan_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Optional: apply intake material allowlist (noise filtering / multi-material alignment).
@@ -485,7 +490,12 @@ Task:
 					CreatedAt:     now,
 					UpdatedAt:     now,
 				}
-				_ = deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row)
+				// The previous slot rows keep their blob references until they are replaced.
+				if err := deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
+					deps.Log.Warn("node_figures_plan_build: persist skipped figure failed", "error", err, "path_node_id", w.Node.ID)
+				} else {
+					releasePlannedFigureBlobs(ctx, deps, existingByNode[w.Node.ID], map[int]bool{0: true})
+				}
 				atomic.AddInt32(&nodesPlanned, 1)
 				return nil
 			}
@@ -494,6 +504,7 @@ Task:
 			now := time.Now().UTC()
 
 			// Persist 1-2 planned rows, or a sentinel "skipped" row to avoid repeated planning.
+			replacedSlots := map[int]bool{}
 			if len(plan.Figures) == 0 {
 				planJSON, _ := json.Marshal(map[string]any{"figures": []any{}, "reason": "no_figures"})
 				row := &types.LearningNodeFigure{
@@ -510,7 +521,11 @@ Task:
 					CreatedAt:     now,
 					UpdatedAt:     now,
 				}
-				_ = deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row)
+				if err := deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
+					deps.Log.Warn("node_figures_plan_build: persist skipped figure failed", "error", err, "path_node_id", w.Node.ID)
+				} else {
+					replacedSlots[0] = true
+				}
 				atomic.AddInt32(&nodesPlanned, 1)
 			} else {
 				for i := range plan.Figures {
@@ -530,11 +545,16 @@ Task:
 						CreatedAt:     now,
 						UpdatedAt:     now,
 					}
-					_ = deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row)
+					if err := deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, row); err != nil {
+						deps.Log.Warn("node_figures_plan_build: persist planned figure failed", "error", err, "path_node_id", w.Node.ID, "slot", row.Slot)
+						continue
+					}
+					replacedSlots[row.Slot] = true
 					atomic.AddInt32(&figsPlanned, 1)
 				}
 				atomic.AddInt32(&nodesPlanned, 1)
 			}
+			releasePlannedFigureBlobs(ctx, deps, existingByNode[w.Node.ID], replacedSlots)
 
 			if deps.GenRuns != nil {
 				_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
@@ -689,3 +709,12 @@ func extractVisualSubjectCandidates(excerpts string, max int) []string {
 
 	return out
 }
+
+func releasePlannedFigureBlobs(ctx context.Context, deps NodeFiguresPlanBuildDeps, previous []*types.LearningNodeFigure, slots map[int]bool) {
+	if deps.FigureBlobs == nil || len(previous) == 0 {
+		return
+	}
+	for _, err := range releaseReplacedFigureBlobs(ctx, deps.DB, deps.FigureBlobs, deps.Bucket, previous, slots) {
+		deps.Log.Warn("node_figures_plan_build: release figure blob failed", "error", err)
+	}
+}
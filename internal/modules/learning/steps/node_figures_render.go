@@ -34,8 +34,10 @@ type NodeFiguresRenderDeps struct {
 	Path      repos.PathRepo
 	PathNodes repos.PathNodeRepo
 	Figures   repos.LearningNodeFigureRepo
-	Assets    repos.AssetRepo
-	GenRuns   repos.LearningDocGenerationRunRepo
+	// Optional: when set, identical renders share one content-addressed object.
+	FigureBlobs repos.FigureBlobRepo
+	Assets      repos.AssetRepo
+	GenRuns     repos.LearningDocGenerationRunRepo
 
 	AI     openai.Client
 	Bucket gcp.BucketService
@@ -78,7 +80,7 @@ func NodeFiguresRender(ctx context.Context, deps NodeFiguresRenderDeps, in NodeF
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("node_figures_render")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "node_figures_render")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -86,9 +88,9 @@ func NodeFiguresRender(ctx context.Context, deps NodeFiguresRenderDeps, in NodeF
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("node_figures_render: adaptive params", "adaptive", adaptiveStageMeta("node_figures_render", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("node_figures_render: adaptive params", "adaptive", adaptiveStageMeta(ctx, "node_figures_render", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("node_figures_render", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "node_figures_render", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Feature gate: require image model + bucket configured; otherwise no-op.
@@ -225,26 +227,34 @@ func NodeFiguresRender(ctx context.Context, deps NodeFiguresRenderDeps, in NodeF
 				return nil
 			}
 
+			mime := strings.TrimSpace(row.AssetMimeType)
+			if mime == "" {
+				mime = strings.TrimSpace(img.MimeType)
+			}
+			if mime == "" {
+				mime = "image/png"
+			}
+
+			// Content-addressed dedupe: identical renders (common for sibling nodes) share one object.
+			// The index row is acquired in the same transaction as the figure row so refcounts never
+			// drift from the rows that reference them.
+			contentHash := ""
 			storageKey := fmt.Sprintf("generated/node_figures/%s/%s/slot_%d_%s.png",
 				pathID.String(),
 				row.PathNodeID.String(),
 				row.Slot,
 				strings.TrimSpace(row.PromptHash),
 			)
-			if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(img.Bytes)); err != nil {
+			if deps.FigureBlobs != nil {
+				contentHash = content.HashBytes(img.Bytes)
+				storageKey = content.FigureBlobStorageKey(contentHash, mime)
+			} else if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: nodeObjectContext(ctx, pathID, row.PathNodeID)}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(img.Bytes)); err != nil {
 				_ = markFigureFailed(gctx, deps, row, "upload_failed: "+err.Error(), latency)
 				atomic.AddInt32(&failed, 1)
 				return nil
 			}
 
 			publicURL := deps.Bucket.GetPublicURL(gcp.BucketCategoryMaterial, storageKey)
-			mime := strings.TrimSpace(row.AssetMimeType)
-			if mime == "" {
-				mime = strings.TrimSpace(img.MimeType)
-			}
-			if mime == "" {
-				mime = "image/png"
-			}
 
 			var assetID *uuid.UUID
 			if deps.Assets != nil {
@@ -293,18 +303,66 @@ func NodeFiguresRender(ctx context.Context, deps NodeFiguresRenderDeps, in NodeF
 				AssetStorageKey: storageKey,
 				AssetURL:        publicURL,
 				AssetMimeType:   mime,
+				ContentHash:     contentHash,
 				Error:           "",
 				CreatedAt:       row.CreatedAt,
 				UpdatedAt:       now,
 			}
-			_ = deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, update)
+			if contentHash == "" {
+				_ = deps.Figures.Upsert(dbctx.Context{Ctx: ctx}, update)
+			} else {
+				created, acquired := false, false
+				err := deps.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+					inner := dbctx.Context{Ctx: ctx, Tx: tx}
+					blob, isNew, took, err := acquireFigureBlob(inner, deps.FigureBlobs, row.ContentHash, &types.FigureBlob{
+						ContentHash: contentHash,
+						StorageKey:  storageKey,
+						MimeType:    mime,
+						ByteLen:     len(img.Bytes),
+					})
+					if err != nil {
+						return err
+					}
+					if blob != nil && strings.TrimSpace(blob.StorageKey) != "" {
+						update.AssetStorageKey = blob.StorageKey
+						update.AssetURL = deps.Bucket.GetPublicURL(gcp.BucketCategoryMaterial, blob.StorageKey)
+					}
+					created, acquired = isNew, took
+					return deps.Figures.Upsert(inner, update)
+				})
+				if err != nil {
+					_ = markFigureFailed(gctx, deps, row, "figure_blob_acquire_failed: "+err.Error(), latency)
+					atomic.AddInt32(&failed, 1)
+					return nil
+				}
+				if err := ensureFigureBlobObject(nodeObjectContext(ctx, pathID, row.PathNodeID), deps.Bucket, update.AssetStorageKey, img.Bytes, created); err != nil {
+					if acquired {
+						if rerr := releaseFigureBlob(ctx, deps.DB, deps.FigureBlobs, deps.Bucket, contentHash); rerr != nil {
+							deps.Log.Warn("node_figures_render: release figure blob failed", "error", rerr, "content_hash", contentHash)
+						}
+					}
+					_ = markFigureFailed(gctx, deps, row, "upload_failed: "+err.Error(), latency)
+					atomic.AddInt32(&failed, 1)
+					return nil
+				}
+				// Regeneration: drop the reference held by the previous render of this slot. Identical
+				// bytes kept that reference in acquireFigureBlob, so there is nothing to drop.
+				if prev := strings.TrimSpace(row.ContentHash); prev != "" && prev != contentHash {
+					if rerr := releaseFigureBlob(ctx, deps.DB, deps.FigureBlobs, deps.Bucket, prev); rerr != nil {
+						deps.Log.Warn("node_figures_render: release previous figure blob failed", "error", rerr, "content_hash", prev)
+					}
+				}
+			}
 
 			if deps.GenRuns != nil {
 				metrics := map[string]any{
-					"storage_key": storageKey,
-					"url":         publicURL,
+					"storage_key": update.AssetStorageKey,
+					"url":         update.AssetURL,
 					"byte_len":    len(img.Bytes),
 				}
+				if contentHash != "" {
+					metrics["content_hash"] = contentHash
+				}
 				_, _ = deps.GenRuns.Create(dbctx.Context{Ctx: ctx}, []*types.LearningDocGenerationRun{
 					makeGenRun("node_figure_asset", &update.ID, in.OwnerUserID, pathID, row.PathNodeID, "succeeded", nodeFigureAssetPromptVersion, 1, latency, nil, metrics),
 				})
@@ -349,6 +407,7 @@ func markFigureFailed(ctx context.Context, deps NodeFiguresRenderDeps, row *type
 		AssetStorageKey: row.AssetStorageKey,
 		AssetURL:        row.AssetURL,
 		AssetMimeType:   row.AssetMimeType,
+		ContentHash:     row.ContentHash,
 		Error:           errMsg,
 		CreatedAt:       row.CreatedAt,
 		UpdatedAt:       now,
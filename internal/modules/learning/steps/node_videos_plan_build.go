@@ -84,7 +84,7 @@ func NodeVideosPlanBuild(ctx context.Context, deps NodeVideosPlanBuildDeps, in N
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("node_videos_plan_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "node_videos_plan_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -92,9 +92,9 @@ func NodeVideosPlanBuild(ctx context.Context, deps NodeVideosPlanBuildDeps, in N
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("node_videos_plan_build: adaptive params", "adaptive", adaptiveStageMeta("node_videos_plan_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("node_videos_plan_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "node_videos_plan_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("node_videos_plan_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "node_videos_plan_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Optional: apply intake material allowlist (noise filtering / multi-material alignment).
@@ -81,7 +81,7 @@ func NodeVideosRender(ctx context.Context, deps NodeVideosRenderDeps, in NodeVid
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("node_videos_render")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "node_videos_render")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -89,9 +89,9 @@ func NodeVideosRender(ctx context.Context, deps NodeVideosRenderDeps, in NodeVid
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("node_videos_render: adaptive params", "adaptive", adaptiveStageMeta("node_videos_render", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("node_videos_render: adaptive params", "adaptive", adaptiveStageMeta(ctx, "node_videos_render", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("node_videos_render", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "node_videos_render", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Feature gate: require video model + bucket configured; otherwise no-op.
@@ -341,7 +341,7 @@ func NodeVideosRender(ctx context.Context, deps NodeVideosRenderDeps, in NodeVid
 				strings.TrimSpace(row.PromptHash),
 				ext,
 			)
-			if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: ctx}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(finalBytes)); err != nil {
+			if err := deps.Bucket.UploadFile(dbctx.Context{Ctx: nodeObjectContext(ctx, pathID, row.PathNodeID)}, gcp.BucketCategoryMaterial, storageKey, bytes.NewReader(finalBytes)); err != nil {
 				_ = markVideoFailed(gctx, deps, row, "upload_failed: "+err.Error(), latency)
 				atomic.AddInt32(&failed, 1)
 				return nil
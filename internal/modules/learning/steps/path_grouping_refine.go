@@ -77,7 +77,7 @@ func PathGroupingRefine(ctx context.Context, deps PathGroupingRefineDeps, in Pat
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("path_grouping_refine")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "path_grouping_refine")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -87,7 +87,7 @@ func PathGroupingRefine(ctx context.Context, deps PathGroupingRefineDeps, in Pat
 		if meta == nil {
 			meta = map[string]any{}
 		}
-		meta["adaptive"] = adaptiveStageMeta("path_grouping_refine", adaptiveEnabled, signals, adaptiveParams)
+		meta["adaptive"] = adaptiveStageMeta(ctx, "path_grouping_refine", adaptiveEnabled, signals, adaptiveParams)
 		return meta
 	}
 	defer func() {
@@ -206,7 +206,7 @@ func PathGroupingRefine(ctx context.Context, deps PathGroupingRefineDeps, in Pat
 	adaptiveParams["PATH_GROUPING_BRIDGE_STRONG"] = map[string]any{"actual": bridgeStrong}
 	adaptiveParams["PATH_GROUPING_BRIDGE_WEAK"] = map[string]any{"actual": bridgeWeak}
 	if deps.Log != nil && adaptiveEnabled {
-		deps.Log.Info("path_grouping_refine: adaptive params", "adaptive", adaptiveStageMeta("path_grouping_refine", adaptiveEnabled, signals, adaptiveParams))
+		deps.Log.Info("path_grouping_refine: adaptive params", "adaptive", adaptiveStageMeta(ctx, "path_grouping_refine", adaptiveEnabled, signals, adaptiveParams))
 	}
 
 	applyGroupingPrefs(&mergeThreshold, &splitThreshold, &bridgeStrong, &bridgeWeak, prefs)
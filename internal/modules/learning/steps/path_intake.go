@@ -89,7 +89,7 @@ func PathIntake(ctx context.Context, deps PathIntakeDeps, in PathIntakeInput) (P
 	out.ThreadID = in.ThreadID
 	out.Now = time.Now().UTC().Format(time.RFC3339Nano)
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("path_intake")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "path_intake")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -99,7 +99,7 @@ func PathIntake(ctx context.Context, deps PathIntakeDeps, in PathIntakeInput) (P
 		if meta == nil {
 			meta = map[string]any{}
 		}
-		meta["adaptive"] = adaptiveStageMeta("path_intake", adaptiveEnabled, signals, adaptiveParams)
+		meta["adaptive"] = adaptiveStageMeta(ctx, "path_intake", adaptiveEnabled, signals, adaptiveParams)
 		return meta
 	}
 
@@ -251,7 +251,7 @@ func PathIntake(ctx context.Context, deps PathIntakeDeps, in PathIntakeInput) (P
 			adaptiveParams = intakeParams
 		}
 		if deps.Log != nil && adaptiveEnabled {
-			deps.Log.Info("path_intake: adaptive params", "adaptive", adaptiveStageMeta("path_intake", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("path_intake: adaptive params", "adaptive", adaptiveStageMeta(ctx, "path_intake", adaptiveEnabled, signals, adaptiveParams))
 		}
 		intake["paths_confirmed"] = true
 		filter := buildIntakeMaterialFilter(files, intake)
@@ -280,7 +280,7 @@ func PathIntake(ctx context.Context, deps PathIntakeDeps, in PathIntakeInput) (P
 		adaptiveParams = intakeParams
 	}
 	if deps.Log != nil && adaptiveEnabled {
-		deps.Log.Info("path_intake: adaptive params", "adaptive", adaptiveStageMeta("path_intake", adaptiveEnabled, signals, adaptiveParams))
+		deps.Log.Info("path_intake: adaptive params", "adaptive", adaptiveStageMeta(ctx, "path_intake", adaptiveEnabled, signals, adaptiveParams))
 	}
 
 	if in.WaitForUser || in.ConfirmExternally {
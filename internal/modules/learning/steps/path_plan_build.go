@@ -74,7 +74,7 @@ func PathPlanBuild(ctx context.Context, deps PathPlanBuildDeps, in PathPlanBuild
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("path_plan_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "path_plan_build")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -82,9 +82,9 @@ func PathPlanBuild(ctx context.Context, deps PathPlanBuildDeps, in PathPlanBuild
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("path_plan_build: adaptive params", "adaptive", adaptiveStageMeta("path_plan_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("path_plan_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "path_plan_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("path_plan_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "path_plan_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	// Idempotency: if nodes already exist, don't rebuild structure (preserve stable IDs/ranks).
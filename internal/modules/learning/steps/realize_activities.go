@@ -100,7 +100,7 @@ func RealizeActivities(ctx context.Context, deps RealizeActivitiesDeps, in Reali
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("realize_activities")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "realize_activities")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -108,9 +108,9 @@ func RealizeActivities(ctx context.Context, deps RealizeActivitiesDeps, in Reali
 	adaptiveParams := map[string]any{}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled && len(adaptiveParams) > 0 {
-			deps.Log.Info("realize_activities: adaptive params", "adaptive", adaptiveStageMeta("realize_activities", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("realize_activities: adaptive params", "adaptive", adaptiveStageMeta(ctx, "realize_activities", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("realize_activities", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "realize_activities", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	up, err := deps.UserProfile.GetByUserID(dbctx.Context{Ctx: ctx}, in.OwnerUserID)
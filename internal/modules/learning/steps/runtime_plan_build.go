@@ -173,7 +173,7 @@ func RuntimePlanBuild(ctx context.Context, deps RuntimePlanBuildDeps, in Runtime
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("runtime_plan_build")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "runtime_plan_build")
 	signals := AdaptiveSignals{}
 	adaptiveParams := map[string]any{}
 	if adaptiveEnabled {
@@ -181,9 +181,9 @@ func RuntimePlanBuild(ctx context.Context, deps RuntimePlanBuildDeps, in Runtime
 	}
 	defer func() {
 		if deps.Log != nil && adaptiveEnabled {
-			deps.Log.Info("runtime_plan_build: adaptive params", "adaptive", adaptiveStageMeta("runtime_plan_build", adaptiveEnabled, signals, adaptiveParams))
+			deps.Log.Info("runtime_plan_build: adaptive params", "adaptive", adaptiveStageMeta(ctx, "runtime_plan_build", adaptiveEnabled, signals, adaptiveParams))
 		}
-		out.Adaptive = adaptiveStageMeta("runtime_plan_build", adaptiveEnabled, signals, adaptiveParams)
+		out.Adaptive = adaptiveStageMeta(ctx, "runtime_plan_build", adaptiveEnabled, signals, adaptiveParams)
 	}()
 
 	var (
@@ -13,6 +13,7 @@ import (
 
 	"github.com/yungbote/neurobridge-backend/internal/data/repos"
 	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/services"
@@ -24,6 +25,11 @@ type SagaCleanupDeps struct {
 	Sagas   repos.SagaRunRepo
 	SagaSvc services.SagaService
 	Bucket  gcp.BucketService
+	// Notes, when set, also purges expired user notifications (NOTIFICATION_RETENTION_DAYS).
+	Notes repos.UserNotificationRepo
+	// BudgetStats, when set, also purges sampled chat context budget stats
+	// (CONTEXT_BUDGET_STATS_RETENTION_DAYS).
+	BudgetStats repos.ContextBudgetStatRepo
 }
 
 type SagaCleanupInput struct {
@@ -33,8 +39,10 @@ type SagaCleanupInput struct {
 }
 
 type SagaCleanupOutput struct {
-	SagasScanned    int `json:"sagas_scanned"`
-	PrefixesDeleted int `json:"prefixes_deleted"`
+	SagasScanned         int   `json:"sagas_scanned"`
+	PrefixesDeleted      int   `json:"prefixes_deleted"`
+	NotificationsDeleted int64 `json:"notifications_deleted"`
+	BudgetStatsDeleted   int64 `json:"budget_stats_deleted"`
 }
 
 func SagaCleanup(ctx context.Context, deps SagaCleanupDeps, in SagaCleanupInput) (SagaCleanupOutput, error) {
@@ -84,5 +92,27 @@ func SagaCleanup(ctx context.Context, deps SagaCleanupDeps, in SagaCleanupInput)
 		}
 	}
 
+	if deps.Notes != nil {
+		if days := envutil.Int("NOTIFICATION_RETENTION_DAYS", 90); days > 0 {
+			notesCutoff := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+			n, err := deps.Notes.DeleteOlderThan(dbctx.Context{Ctx: ctx}, notesCutoff, envutil.Int("NOTIFICATION_RETENTION_BATCH", 5000))
+			if err != nil {
+				deps.Log.Warn("saga_cleanup: notification retention failed", "error", err)
+			}
+			out.NotificationsDeleted = n
+		}
+	}
+
+	if deps.BudgetStats != nil {
+		if days := envutil.Int("CONTEXT_BUDGET_STATS_RETENTION_DAYS", 30); days > 0 {
+			statsCutoff := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+			n, err := deps.BudgetStats.DeleteOlderThan(dbctx.Context{Ctx: ctx}, statsCutoff, envutil.Int("CONTEXT_BUDGET_STATS_RETENTION_BATCH", 5000))
+			if err != nil {
+				deps.Log.Warn("saga_cleanup: context budget stats retention failed", "error", err)
+			}
+			out.BudgetStatsDeleted = n
+		}
+	}
+
 	return out, nil
 }
@@ -115,7 +115,7 @@ func WebResourcesSeed(ctx context.Context, deps WebResourcesSeedDeps, in WebReso
 	}
 	out.PathID = pathID
 
-	adaptiveEnabled := adaptiveParamsEnabledForStage("web_resources_seed")
+	adaptiveEnabled := adaptiveParamsEnabledForStage(ctx, "web_resources_seed")
 	signals := AdaptiveSignals{}
 	if adaptiveEnabled {
 		signals = loadAdaptiveSignals(ctx, deps.DB, in.MaterialSetID, pathID)
@@ -128,7 +128,7 @@ func WebResourcesSeed(ctx context.Context, deps WebResourcesSeedDeps, in WebReso
 				merged[k] = v
 			}
 		}
-		merged["adaptive"] = adaptiveStageMeta("web_resources_seed", adaptiveEnabled, signals, adaptiveParams)
+		merged["adaptive"] = adaptiveStageMeta(ctx, "web_resources_seed", adaptiveEnabled, signals, adaptiveParams)
 		return merged
 	}
 	defer func() {
@@ -243,7 +243,7 @@ func WebResourcesSeed(ctx context.Context, deps WebResourcesSeedDeps, in WebReso
 		maxFetch = 14
 	}
 	if deps.Log != nil && adaptiveEnabled {
-		deps.Log.Info("web_resources_seed: adaptive params", "adaptive", adaptiveStageMeta("web_resources_seed", adaptiveEnabled, signals, adaptiveParams))
+		deps.Log.Info("web_resources_seed: adaptive params", "adaptive", adaptiveStageMeta(ctx, "web_resources_seed", adaptiveEnabled, signals, adaptiveParams))
 	}
 
 	client := newWebHTTPClient()
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"mime"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,6 +13,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/httpx"
 )
 
 func newWebHTTPClient() *http.Client {
@@ -23,20 +24,7 @@ func newWebHTTPClient() *http.Client {
 			timeout = time.Duration(n) * time.Second
 		}
 	}
-	c := &http.Client{Timeout: timeout}
-	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-		if len(via) >= 6 {
-			return fmt.Errorf("too many redirects")
-		}
-		if req == nil || req.URL == nil {
-			return fmt.Errorf("redirect missing url")
-		}
-		if !isAllowedWebURL(context.Background(), req.URL.String()) {
-			return fmt.Errorf("redirect blocked: %s", req.URL.String())
-		}
-		return nil
-	}
-	return c
+	return httpx.NewPublicHTTPSClient(timeout, 6)
 }
 
 func fetchURL(ctx context.Context, client *http.Client, rawURL string, maxBytes int64) ([]byte, string, string, error) {
@@ -172,73 +160,7 @@ func slugify(s string) string {
 }
 
 func isAllowedWebURL(ctx context.Context, raw string) bool {
-	u, err := url.Parse(strings.TrimSpace(raw))
-	if err != nil || u == nil {
-		return false
-	}
-	if strings.ToLower(u.Scheme) != "https" {
-		return false
-	}
-	host := strings.ToLower(strings.TrimSpace(u.Hostname()))
-	if host == "" {
-		return false
-	}
-	if host == "localhost" || strings.HasSuffix(host, ".local") {
-		return false
-	}
-	if ip := net.ParseIP(host); ip != nil {
-		return !isPrivateIP(ip)
-	}
-
-	// Best-effort: resolve and block private IPs (SSRF hardening).
-	resCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-	ips, err := net.DefaultResolver.LookupIP(resCtx, "ip", host)
-	if err != nil || len(ips) == 0 {
-		// If we can't resolve, treat as blocked (safer default).
-		return false
-	}
-	for _, ip := range ips {
-		if isPrivateIP(ip) {
-			return false
-		}
-	}
-	return true
-}
-
-func isPrivateIP(ip net.IP) bool {
-	if ip == nil {
-		return true
-	}
-	ip = ip.To4()
-	if ip == nil {
-		// IPv6: conservatively treat as private unless explicitly global unicast.
-		return true
-	}
-	if ip.IsLoopback() || ip.IsLinkLocalMulticast() || ip.IsLinkLocalUnicast() {
-		return true
-	}
-	// 10.0.0.0/8
-	if ip[0] == 10 {
-		return true
-	}
-	// 172.16.0.0/12
-	if ip[0] == 172 && ip[1] >= 16 && ip[1] <= 31 {
-		return true
-	}
-	// 192.168.0.0/16
-	if ip[0] == 192 && ip[1] == 168 {
-		return true
-	}
-	// 127.0.0.0/8
-	if ip[0] == 127 {
-		return true
-	}
-	// 169.254.0.0/16 (link local)
-	if ip[0] == 169 && ip[1] == 254 {
-		return true
-	}
-	return false
+	return httpx.IsPublicHTTPSURL(ctx, raw)
 }
 
 func envBool(key string, def bool) bool {
@@ -0,0 +1,164 @@
+package studyplan
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+const (
+	EnvStudyReadingWPM               = "STUDY_READING_WPM"
+	EnvStudyQuizSecondsPerQuestion   = "STUDY_QUIZ_SECONDS_PER_QUESTION"
+	EnvStudyReviewSecondsPerConcept  = "STUDY_REVIEW_SECONDS_PER_CONCEPT"
+	EnvStudyMediaBlockSecondsMinimum = "STUDY_MEDIA_BLOCK_SECONDS_MIN"
+)
+
+// minBlockSeconds keeps headings and one-liners from rounding to zero.
+const minBlockSeconds = 5
+
+// mediaBlockTypes are blocks whose text undercounts the time spent on them.
+var mediaBlockTypes = map[string]bool{
+	"figure":   true,
+	"video":    true,
+	"diagram":  true,
+	"table":    true,
+	"code":     true,
+	"equation": true,
+}
+
+// quizBlockTypes are answered rather than read, and are sized per question.
+var quizBlockTypes = map[string]bool{
+	"quick_check": true,
+	"flashcard":   true,
+}
+
+// Estimator converts content into study time. The same numbers back the session planner and
+// any time estimate quoted to the learner, so they stay consistent.
+type Estimator struct {
+	ReadingWPM               int
+	QuizSecondsPerQuestion   int
+	ReviewSecondsPerConcept  int
+	MediaBlockSecondsMinimum int
+}
+
+// EstimatorFromEnv reads the estimator knobs, falling back to defaults.
+func EstimatorFromEnv() Estimator {
+	return Estimator{
+		ReadingWPM:               envInt(EnvStudyReadingWPM, 200, 60, 1000),
+		QuizSecondsPerQuestion:   envInt(EnvStudyQuizSecondsPerQuestion, 45, 5, 600),
+		ReviewSecondsPerConcept:  envInt(EnvStudyReviewSecondsPerConcept, 120, 15, 1800),
+		MediaBlockSecondsMinimum: envInt(EnvStudyMediaBlockSecondsMinimum, 30, 0, 600),
+	}
+}
+
+func (e Estimator) normalized() Estimator {
+	if e.ReadingWPM <= 0 {
+		e.ReadingWPM = 200
+	}
+	if e.QuizSecondsPerQuestion <= 0 {
+		e.QuizSecondsPerQuestion = 45
+	}
+	if e.ReviewSecondsPerConcept <= 0 {
+		e.ReviewSecondsPerConcept = 120
+	}
+	if e.MediaBlockSecondsMinimum < 0 {
+		e.MediaBlockSecondsMinimum = 0
+	}
+	return e
+}
+
+// ReadingSeconds is the time to read words at the configured WPM.
+func (e Estimator) ReadingSeconds(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	e = e.normalized()
+	return (words*60 + e.ReadingWPM - 1) / e.ReadingWPM
+}
+
+// QuizSeconds is the time to answer questions.
+func (e Estimator) QuizSeconds(questions int) int {
+	if questions <= 0 {
+		return 0
+	}
+	return questions * e.normalized().QuizSecondsPerQuestion
+}
+
+// ReviewSeconds is the time for a spaced review of concepts.
+func (e Estimator) ReviewSeconds(concepts int) int {
+	if concepts <= 0 {
+		return 0
+	}
+	return concepts * e.normalized().ReviewSecondsPerConcept
+}
+
+// BlockEstimate is the estimated study time for one node doc block.
+type BlockEstimate struct {
+	BlockID   string `json:"block_id"`
+	BlockType string `json:"block_type"`
+	Words     int    `json:"words,omitempty"`
+	Questions int    `json:"questions,omitempty"`
+	Seconds   int    `json:"seconds"`
+}
+
+// NodeDocBlocks estimates each block of doc in reading order. Blocks without an id are
+// skipped since they can't be targeted or marked read.
+func (e Estimator) NodeDocBlocks(doc content.NodeDocV1) []BlockEstimate {
+	e = e.normalized()
+	words := map[string]int{}
+	for _, seg := range content.NodeDocNarrationSegments(doc, 0) {
+		if seg.BlockID != "" {
+			words[seg.BlockID] += len(strings.Fields(seg.Text))
+		}
+	}
+
+	out := make([]BlockEstimate, 0, len(doc.Blocks))
+	for _, b := range doc.Blocks {
+		if b == nil {
+			continue
+		}
+		id, _ := b["id"].(string)
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		t, _ := b["type"].(string)
+		t = strings.ToLower(strings.TrimSpace(t))
+
+		est := BlockEstimate{BlockID: id, BlockType: t, Words: words[id]}
+		switch {
+		case quizBlockTypes[t]:
+			est.Questions = 1
+			est.Seconds = e.QuizSeconds(1)
+		default:
+			est.Seconds = e.ReadingSeconds(est.Words)
+			if mediaBlockTypes[t] && est.Seconds < e.MediaBlockSecondsMinimum {
+				est.Seconds = e.MediaBlockSecondsMinimum
+			}
+		}
+		if est.Seconds < minBlockSeconds {
+			est.Seconds = minBlockSeconds
+		}
+		out = append(out, est)
+	}
+	return out
+}
+
+func envInt(key string, def int, min int, max int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	if v, err := strconv.Atoi(raw); err == nil {
+		if v < min {
+			return min
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+	return def
+}
@@ -0,0 +1,182 @@
+package studyplan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ItemType string
+
+const (
+	ItemReview ItemType = "review"
+	ItemRead   ItemType = "read"
+	ItemDrill  ItemType = "drill"
+)
+
+const (
+	// drillMinSeconds is the shortest drill worth scheduling after reading.
+	drillMinSeconds = 180
+	// drillMaxSeconds caps the trailing drill so long sessions keep reading.
+	drillMaxSeconds = 600
+	// reviewShare bounds reviews when there is also something new to read.
+	reviewShare = 0.5
+)
+
+// DueConcept is a concept whose spaced review is due.
+type DueConcept struct {
+	ConceptID uuid.UUID
+	DueAt     time.Time
+}
+
+// Input describes what is available to study in one path.
+type Input struct {
+	UserID        uuid.UUID
+	PathID        uuid.UUID
+	BudgetMinutes int
+
+	DueConcepts []DueConcept
+	// NodeID is the next incomplete node; Blocks are its unread blocks in reading order.
+	NodeID *uuid.UUID
+	Blocks []BlockEstimate
+	// DrillNodeID is the node drills target (usually NodeID, else the last node).
+	DrillNodeID *uuid.UUID
+
+	Estimator Estimator
+}
+
+// Item is one step of a session plan.
+type Item struct {
+	Type             ItemType    `json:"type"`
+	PathNodeID       *uuid.UUID  `json:"path_node_id,omitempty"`
+	ConceptIDs       []uuid.UUID `json:"concept_ids,omitempty"`
+	BlockIDs         []string    `json:"block_ids,omitempty"`
+	DrillKind        string      `json:"drill_kind,omitempty"`
+	DrillCount       int         `json:"drill_count,omitempty"`
+	EstimatedSeconds int         `json:"estimated_seconds"`
+	EstimatedMinutes float64     `json:"estimated_minutes"`
+}
+
+// Plan is a packed study session. Item estimates never sum past the budget.
+type Plan struct {
+	PlanID           string  `json:"plan_id"`
+	BudgetMinutes    int     `json:"budget_minutes"`
+	EstimatedSeconds int     `json:"estimated_seconds"`
+	EstimatedMinutes float64 `json:"estimated_minutes"`
+	// MinimumViable is set when nothing was due or fit and the plan fell back to a single drill.
+	MinimumViable bool   `json:"minimum_viable"`
+	Items         []Item `json:"items"`
+}
+
+// Pack fills the budget with due reviews first, then the next node's unread blocks in order,
+// then a short drill if time remains.
+func Pack(in Input) Plan {
+	est := in.Estimator.normalized()
+	budget := in.BudgetMinutes * 60
+	if budget < 0 {
+		budget = 0
+	}
+	used := 0
+	items := []Item{}
+
+	due := append([]DueConcept(nil), in.DueConcepts...)
+	sort.SliceStable(due, func(i, j int) bool {
+		if !due[i].DueAt.Equal(due[j].DueAt) {
+			return due[i].DueAt.Before(due[j].DueAt)
+		}
+		return due[i].ConceptID.String() < due[j].ConceptID.String()
+	})
+	reviewCap := budget
+	if in.NodeID != nil && len(in.Blocks) > 0 {
+		reviewCap = int(float64(budget) * reviewShare)
+	}
+	for _, dc := range due {
+		sec := est.ReviewSeconds(1)
+		if used+sec > reviewCap {
+			break
+		}
+		used += sec
+		items = append(items, newItem(Item{Type: ItemReview, ConceptIDs: []uuid.UUID{dc.ConceptID}}, sec))
+	}
+
+	if in.NodeID != nil {
+		nodeID := *in.NodeID
+		for _, b := range in.Blocks {
+			// Blocks are read in order; stop at the first one that doesn't fit.
+			if used+b.Seconds > budget {
+				break
+			}
+			used += b.Seconds
+			items = append(items, newItem(Item{Type: ItemRead, PathNodeID: &nodeID, BlockIDs: []string{b.BlockID}}, b.Seconds))
+		}
+	}
+
+	minimumViable := false
+	if in.DrillNodeID != nil {
+		remaining := budget - used
+		switch {
+		case remaining >= drillMinSeconds:
+			items = append(items, drillItem(*in.DrillNodeID, "quiz", min(remaining, drillMaxSeconds), est))
+			used += items[len(items)-1].EstimatedSeconds
+		case len(items) == 0 && remaining >= est.QuizSeconds(1):
+			items = append(items, drillItem(*in.DrillNodeID, "flashcards", remaining, est))
+			used += items[len(items)-1].EstimatedSeconds
+			minimumViable = true
+		}
+		if len(items) == 1 && items[0].Type == ItemDrill {
+			minimumViable = true
+		}
+	}
+
+	plan := Plan{
+		BudgetMinutes:    in.BudgetMinutes,
+		EstimatedSeconds: used,
+		EstimatedMinutes: secondsToMinutes(used),
+		MinimumViable:    minimumViable,
+		Items:            items,
+	}
+	plan.PlanID = planID(in.UserID, in.PathID, plan)
+	return plan
+}
+
+// drillItem sizes a drill to whole questions within seconds.
+func drillItem(nodeID uuid.UUID, kind string, seconds int, est Estimator) Item {
+	count := seconds / est.QuizSecondsPerQuestion
+	if count < 1 {
+		count = 1
+	}
+	return newItem(Item{Type: ItemDrill, PathNodeID: &nodeID, DrillKind: kind, DrillCount: count}, est.QuizSeconds(count))
+}
+
+func newItem(it Item, seconds int) Item {
+	it.EstimatedSeconds = seconds
+	it.EstimatedMinutes = secondsToMinutes(seconds)
+	return it
+}
+
+// secondsToMinutes rounds down to a tenth so displayed minutes never sum past the budget.
+func secondsToMinutes(seconds int) float64 {
+	return math.Floor(float64(seconds)/6) / 10
+}
+
+// planID is deterministic for the same user, path, budget, and packed items.
+func planID(userID, pathID uuid.UUID, p Plan) string {
+	raw, _ := json.Marshal(struct {
+		UserID        string
+		PathID        string
+		BudgetMinutes int
+		Items         []Item
+	}{
+		UserID:        userID.String(),
+		PathID:        pathID.String(),
+		BudgetMinutes: p.BudgetMinutes,
+		Items:         p.Items,
+	})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,133 @@
+package studyplan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/modules/learning/content"
+)
+
+var testEstimator = Estimator{ReadingWPM: 200, QuizSecondsPerQuestion: 45, ReviewSecondsPerConcept: 120, MediaBlockSecondsMinimum: 30}
+
+func testInput(budget int, due int) Input {
+	nodeID := uuid.New()
+	blocks := make([]BlockEstimate, 0, 20)
+	for i := 0; i < 20; i++ {
+		blocks = append(blocks, BlockEstimate{BlockID: fmt.Sprintf("b%d", i), BlockType: "paragraph", Words: 400, Seconds: 120})
+	}
+	now := time.Now()
+	concepts := make([]DueConcept, 0, due)
+	for i := 0; i < due; i++ {
+		concepts = append(concepts, DueConcept{ConceptID: uuid.New(), DueAt: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	return Input{
+		UserID:        uuid.New(),
+		PathID:        uuid.New(),
+		BudgetMinutes: budget,
+		DueConcepts:   concepts,
+		NodeID:        &nodeID,
+		Blocks:        blocks,
+		DrillNodeID:   &nodeID,
+		Estimator:     testEstimator,
+	}
+}
+
+func checkBudget(t *testing.T, p Plan) {
+	t.Helper()
+	sum := 0
+	for _, it := range p.Items {
+		sum += it.EstimatedSeconds
+	}
+	if sum != p.EstimatedSeconds || sum > p.BudgetMinutes*60 {
+		t.Fatalf("items sum to %ds (plan says %ds), budget %dm", sum, p.EstimatedSeconds, p.BudgetMinutes)
+	}
+}
+
+func TestPackFiveMinutes(t *testing.T) {
+	in := testInput(5, 3)
+	p := Pack(in)
+	checkBudget(t, p)
+
+	// Half the budget goes to the most overdue review, the rest to the first block.
+	if len(p.Items) != 2 || p.Items[0].Type != ItemReview || p.Items[1].Type != ItemRead {
+		t.Fatalf("items = %+v", p.Items)
+	}
+	if p.Items[0].ConceptIDs[0] != in.DueConcepts[2].ConceptID {
+		t.Fatalf("review should target the most overdue concept")
+	}
+	if p.Items[1].BlockIDs[0] != "b0" {
+		t.Fatalf("read item = %+v", p.Items[1])
+	}
+
+	// Nothing due and no unread blocks: fall back to a drill sized to the budget.
+	in.DueConcepts, in.Blocks = nil, nil
+	p = Pack(in)
+	checkBudget(t, p)
+	if !p.MinimumViable || len(p.Items) != 1 || p.Items[0].Type != ItemDrill || p.Items[0].DrillKind != "quiz" {
+		t.Fatalf("minimum viable plan = %+v", p)
+	}
+}
+
+func TestPackTwoHundredFortyMinutes(t *testing.T) {
+	in := testInput(240, 200)
+	p := Pack(in)
+	checkBudget(t, p)
+
+	var reviews, reads, drills int
+	for i, it := range p.Items {
+		switch it.Type {
+		case ItemReview:
+			reviews++
+			if reads > 0 || drills > 0 {
+				t.Fatalf("review at %d after reading", i)
+			}
+		case ItemRead:
+			reads++
+		case ItemDrill:
+			drills++
+			if i != len(p.Items)-1 {
+				t.Fatalf("drill at %d is not last", i)
+			}
+		}
+	}
+	// Reviews are capped at half the session; all 20 blocks (40m) fit; a capped drill follows.
+	if reviews != 60 || reads != 20 || drills != 1 {
+		t.Fatalf("reviews=%d reads=%d drills=%d", reviews, reads, drills)
+	}
+	if p.MinimumViable {
+		t.Fatal("full plan flagged minimum viable")
+	}
+	if again := Pack(in); again.PlanID != p.PlanID || len(p.PlanID) != 64 {
+		t.Fatalf("plan id not deterministic: %q vs %q", p.PlanID, again.PlanID)
+	}
+	in.BudgetMinutes = 239
+	if Pack(in).PlanID == p.PlanID {
+		t.Fatal("plan id should change with the budget")
+	}
+}
+
+func TestNodeDocBlocks(t *testing.T) {
+	doc := content.NodeDocV1{Blocks: []map[string]any{
+		{"id": "p1", "type": "paragraph", "md": strings.Repeat("word ", 400)},
+		{"id": "q1", "type": "quick_check", "prompt_md": "?"},
+		{"id": "f1", "type": "figure", "caption": "A plot"},
+		{"type": "paragraph", "md": "no id"},
+	}}
+	got := testEstimator.NodeDocBlocks(doc)
+	if len(got) != 3 {
+		t.Fatalf("blocks = %+v", got)
+	}
+	if got[0].Words != 400 || got[0].Seconds != 120 {
+		t.Fatalf("paragraph = %+v", got[0])
+	}
+	if got[1].Questions != 1 || got[1].Seconds != 45 {
+		t.Fatalf("quick check = %+v", got[1])
+	}
+	if got[2].Seconds != 30 {
+		t.Fatalf("figure = %+v", got[2])
+	}
+}
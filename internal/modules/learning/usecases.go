@@ -9,11 +9,13 @@ import (
 	ingestion "github.com/yungbote/neurobridge-backend/internal/modules/learning/ingestion/pipeline"
 	"github.com/yungbote/neurobridge-backend/internal/modules/learning/steps"
 	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/fieldcrypt"
 	"github.com/yungbote/neurobridge-backend/internal/platform/gcp"
 	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
 	"github.com/yungbote/neurobridge-backend/internal/platform/neo4jdb"
 	"github.com/yungbote/neurobridge-backend/internal/platform/openai"
 	"github.com/yungbote/neurobridge-backend/internal/platform/pinecone"
+	"github.com/yungbote/neurobridge-backend/internal/platform/tts"
 	"github.com/yungbote/neurobridge-backend/internal/services"
 )
 
@@ -27,8 +29,10 @@ type UsecasesDeps struct {
 	Vec   pinecone.VectorStore
 	Graph *neo4jdb.Client
 
-	Bucket gcp.BucketService
-	Avatar services.AvatarService
+	Bucket     gcp.BucketService
+	FieldCrypt *fieldcrypt.Envelope
+	Avatar     services.AvatarService
+	TTS        tts.Provider
 
 	Files        repos.MaterialFileRepo
 	FileSigs     repos.MaterialFileSignatureRepo
@@ -42,12 +46,14 @@ type UsecasesDeps struct {
 	PathNodeActivities repos.PathNodeActivityRepo
 	PathRuns           repos.PathRunRepo
 	NodeRuns           repos.NodeRunRepo
+	PathFocus          repos.PathFocusRepo
 
 	Concepts         repos.ConceptRepo
 	Evidence         repos.ConceptEvidenceRepo
 	Edges            repos.ConceptEdgeRepo
 	ConceptReps      repos.ConceptRepresentationRepo
 	MappingOverrides repos.ConceptMappingOverrideRepo
+	DocEmbeddings    repos.ConceptDocEmbeddingRepo
 
 	Clusters repos.ConceptClusterRepo
 	Members  repos.ConceptClusterMemberRepo
@@ -65,15 +71,19 @@ type UsecasesDeps struct {
 	NodeDocs            repos.LearningNodeDocRepo
 	DocVariants         repos.LearningNodeDocVariantRepo
 	Figures             repos.LearningNodeFigureRepo
+	FigureBlobs         repos.FigureBlobRepo
 	Videos              repos.LearningNodeVideoRepo
+	NodeAudio           repos.LearningNodeAudioRepo
 	Revisions           repos.LearningNodeDocRevisionRepo
 	GenRuns             repos.LearningDocGenerationRunRepo
+	NodeAssetRefs       repos.NodeAssetRefRepo
 	Blueprints          repos.LearningNodeDocBlueprintRepo
 	RetrievalPacks      repos.DocRetrievalPackRepo
 	DocTraces           repos.DocGenerationTraceRepo
 	DocSignals          repos.UserDocSignalSnapshotRepo
 	InterventionPlans   repos.InterventionPlanRepo
 	ConstraintReports   repos.DocConstraintReportRepo
+	ConsistencyAudits   repos.DocConsistencyAuditRunRepo
 	DocProbes           repos.DocProbeRepo
 	DocProbeOutcomes    repos.DocProbeOutcomeRepo
 	DocVariantExposures repos.DocVariantExposureRepo
@@ -101,7 +111,9 @@ type UsecasesDeps struct {
 	MisconRepo       repos.UserMisconceptionInstanceRepo
 	UserTestletState repos.UserTestletStateRepo
 
-	Sagas repos.SagaRunRepo
+	Sagas         repos.SagaRunRepo
+	Notifications repos.UserNotificationRepo
+	BudgetStats   repos.ContextBudgetStatRepo
 
 	Threads     repos.ChatThreadRepo
 	Messages    repos.ChatMessageRepo
@@ -161,6 +173,8 @@ type (
 	ConceptGraphBuildOutput      = steps.ConceptGraphBuildOutput
 	ConceptGraphPatchBuildInput  = steps.ConceptGraphPatchBuildInput
 	ConceptGraphPatchBuildOutput = steps.ConceptGraphPatchBuildOutput
+	ConceptRecanonicalizeInput   = steps.ConceptRecanonicalizeInput
+	ConceptRecanonicalizeOutput  = steps.ConceptRecanonicalizeOutput
 	ConceptBridgeBuildInput      = steps.ConceptBridgeBuildInput
 	ConceptBridgeBuildOutput     = steps.ConceptBridgeBuildOutput
 
@@ -202,6 +216,12 @@ type (
 
 	NodeDocBuildInput             = steps.NodeDocBuildInput
 	NodeDocBuildOutput            = steps.NodeDocBuildOutput
+	NodeDocRegenerateInput        = steps.NodeDocRegenerateInput
+	NodeDocRegenerateOutput       = steps.NodeDocRegenerateOutput
+	NodeDocSeeAlsoRefreshInput    = steps.NodeDocSeeAlsoRefreshInput
+	NodeDocSeeAlsoRefreshOutput   = steps.NodeDocSeeAlsoRefreshOutput
+	NodeDescriptionSyncInput      = steps.NodeDescriptionSyncInput
+	NodeDescriptionSyncOutput     = steps.NodeDescriptionSyncOutput
 	NodeDocPrefetchInput          = steps.NodeDocPrefetchInput
 	NodeDocPrefetchOutput         = steps.NodeDocPrefetchOutput
 	NodeDocProgressiveBuildInput  = steps.NodeDocProgressiveBuildInput
@@ -218,6 +238,9 @@ type (
 	NodeDocPatchOutput        = steps.NodeDocPatchOutput
 	NodeDocPatchPreviewOutput = steps.NodeDocPatchPreviewOutput
 
+	NodeDocNarrateInput  = steps.NodeDocNarrateInput
+	NodeDocNarrateOutput = steps.NodeDocNarrateOutput
+
 	RealizeActivitiesInput  = steps.RealizeActivitiesInput
 	RealizeActivitiesOutput = steps.RealizeActivitiesOutput
 
@@ -239,6 +262,17 @@ type (
 	SagaCleanupInput  = steps.SagaCleanupInput
 	SagaCleanupOutput = steps.SagaCleanupOutput
 
+	MaterialFileCleanupInput  = steps.MaterialFileCleanupInput
+	MaterialFileCleanupOutput = steps.MaterialFileCleanupOutput
+	MaterialFileRelinkInput   = steps.MaterialFileRelinkInput
+	MaterialFileRelinkOutput  = steps.MaterialFileRelinkOutput
+
+	GeneratedObjectSweepInput  = steps.GeneratedObjectSweepInput
+	GeneratedObjectSweepOutput = steps.GeneratedObjectSweepOutput
+
+	DocConsistencyAuditInput  = steps.DocConsistencyAuditInput
+	DocConsistencyAuditOutput = steps.DocConsistencyAuditOutput
+
 	PathStructuralUnitBuildInput  = steps.PathStructuralUnitBuildInput
 	PathStructuralUnitBuildOutput = steps.PathStructuralUnitBuildOutput
 
@@ -254,6 +288,9 @@ type (
 	StructuralTraceBackfillInput  = steps.StructuralTraceBackfillInput
 	StructuralTraceBackfillOutput = steps.StructuralTraceBackfillOutput
 
+	FieldCryptRotateInput  = steps.FieldCryptRotateInput
+	FieldCryptRotateOutput = steps.FieldCryptRotateOutput
+
 	TraceCompactInput  = steps.TraceCompactInput
 	TraceCompactOutput = steps.TraceCompactOutput
 
@@ -412,23 +449,24 @@ func (u Usecases) TeachingPatternsSeed(ctx context.Context, in TeachingPatternsS
 
 func (u Usecases) ConceptGraphBuild(ctx context.Context, in ConceptGraphBuildInput) (ConceptGraphBuildOutput, error) {
 	return steps.ConceptGraphBuild(ctx, steps.ConceptGraphBuildDeps{
-		DB:        u.deps.DB,
-		Log:       u.deps.Log,
-		Files:     u.deps.Files,
-		FileSigs:  u.deps.FileSigs,
-		Chunks:    u.deps.Chunks,
-		Path:      u.deps.Path,
-		Concepts:  u.deps.Concepts,
-		Reps:      u.deps.ConceptReps,
-		Overrides: u.deps.MappingOverrides,
-		Evidence:  u.deps.Evidence,
-		Edges:     u.deps.Edges,
-		Graph:     u.deps.Graph,
-		AI:        u.deps.AI,
-		Vec:       u.deps.Vec,
-		Saga:      u.deps.Saga,
-		Bootstrap: u.deps.Bootstrap,
-		Artifacts: u.deps.Artifacts,
+		DB:            u.deps.DB,
+		Log:           u.deps.Log,
+		Files:         u.deps.Files,
+		FileSigs:      u.deps.FileSigs,
+		Chunks:        u.deps.Chunks,
+		Path:          u.deps.Path,
+		Concepts:      u.deps.Concepts,
+		Reps:          u.deps.ConceptReps,
+		Overrides:     u.deps.MappingOverrides,
+		Evidence:      u.deps.Evidence,
+		Edges:         u.deps.Edges,
+		DocEmbeddings: u.deps.DocEmbeddings,
+		Graph:         u.deps.Graph,
+		AI:            u.deps.AI,
+		Vec:           u.deps.Vec,
+		Saga:          u.deps.Saga,
+		Bootstrap:     u.deps.Bootstrap,
+		Artifacts:     u.deps.Artifacts,
 	}, steps.ConceptGraphBuildInput(in))
 }
 
@@ -451,9 +489,24 @@ func (u Usecases) ConceptGraphPatchBuild(ctx context.Context, in ConceptGraphPat
 		Saga:      u.deps.Saga,
 		Bootstrap: u.deps.Bootstrap,
 		Artifacts: u.deps.Artifacts,
+		PathNodes: u.deps.PathNodes,
+		NodeDocs:  u.deps.NodeDocs,
 	}, steps.ConceptGraphPatchBuildInput(in))
 }
 
+func (u Usecases) ConceptRecanonicalize(ctx context.Context, in ConceptRecanonicalizeInput) (ConceptRecanonicalizeOutput, error) {
+	return steps.ConceptRecanonicalize(ctx, steps.ConceptGraphBuildDeps{
+		DB:            u.deps.DB,
+		Log:           u.deps.Log,
+		Concepts:      u.deps.Concepts,
+		Reps:          u.deps.ConceptReps,
+		Overrides:     u.deps.MappingOverrides,
+		DocEmbeddings: u.deps.DocEmbeddings,
+		AI:            u.deps.AI,
+		Vec:           u.deps.Vec,
+	}, steps.ConceptRecanonicalizeInput(in))
+}
+
 func (u Usecases) ConceptBridgeBuild(ctx context.Context, in ConceptBridgeBuildInput) (ConceptBridgeBuildOutput, error) {
 	return steps.ConceptBridgeBuild(ctx, steps.ConceptBridgeBuildDeps{
 		DB:        u.deps.DB,
@@ -567,32 +620,35 @@ func (u Usecases) NodeAvatarRender(ctx context.Context, in NodeAvatarRenderInput
 
 func (u Usecases) NodeFiguresPlanBuild(ctx context.Context, in NodeFiguresPlanBuildInput) (NodeFiguresPlanBuildOutput, error) {
 	return steps.NodeFiguresPlanBuild(ctx, steps.NodeFiguresPlanBuildDeps{
-		DB:        u.deps.DB,
-		Log:       u.deps.Log,
-		Path:      u.deps.Path,
-		PathNodes: u.deps.PathNodes,
-		Figures:   u.deps.Figures,
-		GenRuns:   u.deps.GenRuns,
-		Files:     u.deps.Files,
-		Chunks:    u.deps.Chunks,
-		AI:        u.deps.AI,
-		Vec:       u.deps.Vec,
-		Bootstrap: u.deps.Bootstrap,
+		DB:          u.deps.DB,
+		Log:         u.deps.Log,
+		Path:        u.deps.Path,
+		PathNodes:   u.deps.PathNodes,
+		Figures:     u.deps.Figures,
+		GenRuns:     u.deps.GenRuns,
+		FigureBlobs: u.deps.FigureBlobs,
+		Bucket:      u.deps.Bucket,
+		Files:       u.deps.Files,
+		Chunks:      u.deps.Chunks,
+		AI:          u.deps.AI,
+		Vec:         u.deps.Vec,
+		Bootstrap:   u.deps.Bootstrap,
 	}, steps.NodeFiguresPlanBuildInput(in))
 }
 
 func (u Usecases) NodeFiguresRender(ctx context.Context, in NodeFiguresRenderInput) (NodeFiguresRenderOutput, error) {
 	return steps.NodeFiguresRender(ctx, steps.NodeFiguresRenderDeps{
-		DB:        u.deps.DB,
-		Log:       u.deps.Log,
-		Path:      u.deps.Path,
-		PathNodes: u.deps.PathNodes,
-		Figures:   u.deps.Figures,
-		Assets:    u.deps.Assets,
-		GenRuns:   u.deps.GenRuns,
-		AI:        u.deps.AI,
-		Bucket:    u.deps.Bucket,
-		Bootstrap: u.deps.Bootstrap,
+		DB:          u.deps.DB,
+		Log:         u.deps.Log,
+		Path:        u.deps.Path,
+		PathNodes:   u.deps.PathNodes,
+		Figures:     u.deps.Figures,
+		FigureBlobs: u.deps.FigureBlobs,
+		Assets:      u.deps.Assets,
+		GenRuns:     u.deps.GenRuns,
+		AI:          u.deps.AI,
+		Bucket:      u.deps.Bucket,
+		Bootstrap:   u.deps.Bootstrap,
 	}, steps.NodeFiguresRenderInput(in))
 }
 
@@ -676,6 +732,53 @@ func (u Usecases) NodeDocBuild(ctx context.Context, in NodeDocBuildInput) (NodeD
 	}, steps.NodeDocBuildInput(in))
 }
 
+func (u Usecases) NodeDocRegenerate(ctx context.Context, in NodeDocRegenerateInput) (NodeDocRegenerateOutput, error) {
+	return steps.NodeDocRegenerate(ctx, steps.NodeDocBuildDeps{
+		DB:                u.deps.DB,
+		Log:               u.deps.Log,
+		Path:              u.deps.Path,
+		PathNodes:         u.deps.PathNodes,
+		NodeDocs:          u.deps.NodeDocs,
+		Figures:           u.deps.Figures,
+		Videos:            u.deps.Videos,
+		GenRuns:           u.deps.GenRuns,
+		Blueprints:        u.deps.Blueprints,
+		RetrievalPacks:    u.deps.RetrievalPacks,
+		DocTraces:         u.deps.DocTraces,
+		ConstraintReports: u.deps.ConstraintReports,
+		Revisions:         u.deps.Revisions,
+		Files:             u.deps.Files,
+		Chunks:            u.deps.Chunks,
+		UserProfile:       u.deps.UserProfile,
+		TeachingPatterns:  u.deps.TeachingPatterns,
+		Concepts:          u.deps.Concepts,
+		ConceptState:      u.deps.ConceptState,
+		ConceptModel:      u.deps.ConceptModel,
+		MisconRepo:        u.deps.MisconRepo,
+		Edges:             u.deps.Edges,
+		AI:                u.deps.AI,
+		Vec:               u.deps.Vec,
+		Bucket:            u.deps.Bucket,
+		Bootstrap:         u.deps.Bootstrap,
+	}, steps.NodeDocRegenerateInput(in))
+}
+
+func (u Usecases) NodeDocSeeAlsoRefresh(ctx context.Context, in NodeDocSeeAlsoRefreshInput) (NodeDocSeeAlsoRefreshOutput, error) {
+	return steps.NodeDocSeeAlsoRefresh(ctx, steps.NodeDocSeeAlsoRefreshDeps{
+		Log:       u.deps.Log,
+		PathNodes: u.deps.PathNodes,
+		NodeDocs:  u.deps.NodeDocs,
+	}, steps.NodeDocSeeAlsoRefreshInput(in))
+}
+
+func (u Usecases) NodeDescriptionSync(ctx context.Context, in NodeDescriptionSyncInput) (NodeDescriptionSyncOutput, error) {
+	return steps.NodeDescriptionSync(ctx, steps.NodeDescriptionSyncDeps{
+		Log:       u.deps.Log,
+		PathNodes: u.deps.PathNodes,
+		NodeDocs:  u.deps.NodeDocs,
+	}, steps.NodeDescriptionSyncInput(in))
+}
+
 func (u Usecases) NodeDocPrefetch(ctx context.Context, in NodeDocPrefetchInput) (NodeDocPrefetchOutput, error) {
 	return steps.NodeDocPrefetch(ctx, steps.NodeDocPrefetchDeps{
 		NodeDocBuildDeps: steps.NodeDocBuildDeps{
@@ -799,6 +902,19 @@ func (u Usecases) NodeDocPatch(ctx context.Context, in NodeDocPatchInput) (NodeD
 	}, steps.NodeDocPatchInput(in))
 }
 
+func (u Usecases) NodeDocNarrate(ctx context.Context, in NodeDocNarrateInput) (NodeDocNarrateOutput, error) {
+	return steps.NodeDocNarrate(ctx, steps.NodeDocNarrateDeps{
+		DB:        u.deps.DB,
+		Log:       u.deps.Log,
+		Path:      u.deps.Path,
+		PathNodes: u.deps.PathNodes,
+		NodeDocs:  u.deps.NodeDocs,
+		NodeAudio: u.deps.NodeAudio,
+		Bucket:    u.deps.Bucket,
+		TTS:       u.deps.TTS,
+	}, steps.NodeDocNarrateInput(in))
+}
+
 func (u Usecases) NodeDocPatchPreview(ctx context.Context, in NodeDocPatchInput) (NodeDocPatchPreviewOutput, error) {
 	return steps.NodeDocPatchPreview(ctx, steps.NodeDocPatchDeps{
 		DB:        u.deps.DB,
@@ -922,9 +1038,51 @@ func (u Usecases) SagaCleanup(ctx context.Context, in SagaCleanupInput) (SagaCle
 		Sagas:   u.deps.Sagas,
 		SagaSvc: u.deps.Saga,
 		Bucket:  u.deps.Bucket,
+		Notes:   u.deps.Notifications,
+
+		BudgetStats: u.deps.BudgetStats,
 	}, steps.SagaCleanupInput(in))
 }
 
+func (u Usecases) MaterialFileCleanup(ctx context.Context, in MaterialFileCleanupInput) (MaterialFileCleanupOutput, error) {
+	return steps.MaterialFileCleanup(ctx, steps.MaterialFileCleanupDeps{
+		DB:       u.deps.DB,
+		Log:      u.deps.Log,
+		Files:    u.deps.Files,
+		Chunks:   u.deps.Chunks,
+		Evidence: u.deps.Evidence,
+		NodeDocs: u.deps.NodeDocs,
+		Vec:      u.deps.Vec,
+	}, steps.MaterialFileCleanupInput(in))
+}
+
+func (u Usecases) MaterialFileRelink(ctx context.Context, in MaterialFileRelinkInput) (MaterialFileRelinkOutput, error) {
+	return steps.MaterialFileRelink(ctx, steps.MaterialFileRelinkDeps{
+		DB:       u.deps.DB,
+		Log:      u.deps.Log,
+		Files:    u.deps.Files,
+		Chunks:   u.deps.Chunks,
+		Evidence: u.deps.Evidence,
+		NodeDocs: u.deps.NodeDocs,
+	}, steps.MaterialFileRelinkInput(in))
+}
+
+func (u Usecases) GeneratedObjectSweep(ctx context.Context, in GeneratedObjectSweepInput) (GeneratedObjectSweepOutput, error) {
+	return steps.GeneratedObjectSweep(ctx, steps.GeneratedObjectSweepDeps{
+		Log:    u.deps.Log,
+		Bucket: u.deps.Bucket,
+		Refs:   u.deps.NodeAssetRefs,
+	}, steps.GeneratedObjectSweepInput(in))
+}
+
+func (u Usecases) DocConsistencyAudit(ctx context.Context, in DocConsistencyAuditInput) (DocConsistencyAuditOutput, error) {
+	return steps.DocConsistencyAudit(ctx, steps.DocConsistencyAuditDeps{
+		DB:   u.deps.DB,
+		Log:  u.deps.Log,
+		Runs: u.deps.ConsistencyAudits,
+	}, steps.DocConsistencyAuditInput(in))
+}
+
 func (u Usecases) PathStructuralUnitBuild(ctx context.Context, in PathStructuralUnitBuildInput) (PathStructuralUnitBuildOutput, error) {
 	return steps.PathStructuralUnitBuild(ctx, steps.PathStructuralUnitBuildDeps{
 		DB:        u.deps.DB,
@@ -988,6 +1146,15 @@ func (u Usecases) StructuralTraceBackfill(ctx context.Context, in StructuralTrac
 	}, steps.StructuralTraceBackfillInput(in))
 }
 
+func (u Usecases) FieldCryptRotate(ctx context.Context, in FieldCryptRotateInput) (FieldCryptRotateOutput, error) {
+	return steps.FieldCryptRotate(ctx, steps.FieldCryptRotateDeps{
+		DB:         u.deps.DB,
+		Log:        u.deps.Log,
+		NodeDocs:   u.deps.NodeDocs,
+		FieldCrypt: u.deps.FieldCrypt,
+	}, steps.FieldCryptRotateInput(in))
+}
+
 func (u Usecases) TraceCompact(ctx context.Context, in TraceCompactInput) (TraceCompactOutput, error) {
 	return steps.TraceCompact(ctx, steps.TraceCompactDeps{
 		DB:  u.deps.DB,
@@ -29,6 +29,8 @@ type Metrics struct {
 	llmLatency                  *HistogramVec
 	llmTokens                   *CounterVec
 	llmCost                     *CounterVec
+	llmJSONOutputs              *CounterVec
+	llmJSONUnusable             *CounterVec
 	dataQuality                 *CounterVec
 	clientPerf                  *HistogramVec
 	clientError                 *CounterVec
@@ -73,6 +75,8 @@ type Metrics struct {
 	aggregateLatency            *HistogramVec
 	aggregateConflicts          *CounterVec
 	aggregateRetries            *CounterVec
+	sessionPrewarm              *CounterVec
+	pathOutlineCache            *CounterVec
 	objectStorageModeActive     *GaugeVec
 	objectStorageBootstrapTotal *CounterVec
 	vectorStoreProviderActive   *GaugeVec
@@ -217,8 +221,14 @@ func Init(log *logger.Logger) *Metrics {
 				[]string{"model", "endpoint", "status"},
 				[]float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60, 120},
 			),
-			llmTokens:   NewCounterVec("nb_llm_tokens_total", "LLM tokens by model/direction.", []string{"model", "direction"}),
-			llmCost:     NewCounterVec("nb_llm_cost_usd_total", "Estimated LLM cost (USD) by model/direction.", []string{"model", "direction"}),
+			llmTokens:      NewCounterVec("nb_llm_tokens_total", "LLM tokens by model/direction.", []string{"model", "direction"}),
+			llmCost:        NewCounterVec("nb_llm_cost_usd_total", "Estimated LLM cost (USD) by model/direction.", []string{"model", "direction"}),
+			llmJSONOutputs: NewCounterVec("nb_llm_json_outputs_total", "Validated LLM JSON outputs by schema/outcome.", []string{"schema", "outcome"}),
+			llmJSONUnusable: NewCounterVec(
+				"nb_llm_json_unusable_total",
+				"Schema-valid LLM JSON outputs that carried nothing usable, by schema/kind/field/model.",
+				[]string{"schema", "kind", "field", "model"},
+			),
 			dataQuality: NewCounterVec("nb_data_quality_issues_total", "Data quality issues by stage/issue/key.", []string{"stage", "issue", "key"}),
 			clientPerf: NewHistogramVec(
 				"nb_client_perf_seconds",
@@ -373,6 +383,16 @@ func Init(log *logger.Logger) *Metrics {
 				"Aggregate retry count by operation.",
 				[]string{"operation"},
 			),
+			sessionPrewarm: NewCounterVec(
+				"nb_session_prewarm_total",
+				"Session prewarm outcomes (hit=already warm, miss=loaded, dropped, error).",
+				[]string{"result"},
+			),
+			pathOutlineCache: NewCounterVec(
+				"nb_path_outline_cache_total",
+				"Path outline cache lookups (hit, stale=version moved, miss, error).",
+				[]string{"result"},
+			),
 			objectStorageModeActive: NewGaugeVec(
 				"nb_object_storage_mode_active",
 				"Active object storage mode (1=active).",
@@ -505,6 +525,12 @@ func (m *Metrics) WritePrometheus(w io.Writer) error {
 	if err := m.llmCost.WritePrometheus(w); err != nil {
 		return err
 	}
+	if err := m.llmJSONOutputs.WritePrometheus(w); err != nil {
+		return err
+	}
+	if err := m.llmJSONUnusable.WritePrometheus(w); err != nil {
+		return err
+	}
 	if err := m.dataQuality.WritePrometheus(w); err != nil {
 		return err
 	}
@@ -664,6 +690,12 @@ func (m *Metrics) WritePrometheus(w io.Writer) error {
 	if err := m.aggregateRetries.WritePrometheus(w); err != nil {
 		return err
 	}
+	if err := m.sessionPrewarm.WritePrometheus(w); err != nil {
+		return err
+	}
+	if err := m.pathOutlineCache.WritePrometheus(w); err != nil {
+		return err
+	}
 	if err := m.objectStorageModeActive.WritePrometheus(w); err != nil {
 		return err
 	}
@@ -806,6 +838,28 @@ func (m *Metrics) IncAggregateRetry(operation string) {
 	m.aggregateRetries.Inc(operation)
 }
 
+func (m *Metrics) IncSessionPrewarm(result string) {
+	if m == nil {
+		return
+	}
+	result = strings.TrimSpace(result)
+	if result == "" {
+		result = "unknown"
+	}
+	m.sessionPrewarm.Inc(result)
+}
+
+func (m *Metrics) IncPathOutlineCache(result string) {
+	if m == nil {
+		return
+	}
+	result = strings.TrimSpace(result)
+	if result == "" {
+		result = "unknown"
+	}
+	m.pathOutlineCache.Inc(result)
+}
+
 func normalizeObjectStorageMode(mode string) string {
 	mode = strings.TrimSpace(strings.ToLower(mode))
 	switch mode {
@@ -1269,6 +1323,48 @@ func (m *Metrics) ObserveLLMRequest(model, endpoint, status string, dur time.Dur
 	}
 }
 
+// ObserveLLMJSONOutput counts validated structured outputs. outcome is "ok", "repaired",
+// or the failure class (schema_violation, truncation, refusal).
+func (m *Metrics) ObserveLLMJSONOutput(schema, outcome string) {
+	if m == nil || !llmTelemetryEnabled() {
+		return
+	}
+	schema = strings.TrimSpace(schema)
+	if schema == "" {
+		schema = "unknown"
+	}
+	outcome = strings.TrimSpace(outcome)
+	if outcome == "" {
+		outcome = "unknown"
+	}
+	m.llmJSONOutputs.Inc(schema, outcome)
+}
+
+// ObserveLLMJSONUnusable counts outputs that passed schema validation but were useless to the
+// caller. kind is the openai.JSONUnusableKind; field names the offending key ("" = none).
+func (m *Metrics) ObserveLLMJSONUnusable(schema, kind, field, model string) {
+	if m == nil || !llmTelemetryEnabled() {
+		return
+	}
+	schema = strings.TrimSpace(schema)
+	if schema == "" {
+		schema = "unknown"
+	}
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		kind = "unknown"
+	}
+	field = strings.TrimSpace(field)
+	if field == "" {
+		field = "none"
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		model = "unknown"
+	}
+	m.llmJSONUnusable.Inc(schema, kind, field, model)
+}
+
 func (m *Metrics) IncDataQuality(stage, issue, key string) {
 	if m == nil {
 		return
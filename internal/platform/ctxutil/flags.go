@@ -0,0 +1,34 @@
+package ctxutil
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type flagSubjectKey struct{}
+
+// FlagSubject is who and where feature flags are evaluated for. Handlers set it once the path
+// is known; job runs set it from the job owner and payload.
+type FlagSubject struct {
+	UserID uuid.UUID
+	PathID uuid.UUID
+}
+
+func WithFlagSubject(ctx context.Context, s FlagSubject) context.Context {
+	return context.WithValue(Default(ctx), flagSubjectKey{}, s)
+}
+
+// GetFlagSubject returns the subject set on ctx, falling back to the authenticated user.
+func GetFlagSubject(ctx context.Context) FlagSubject {
+	if ctx == nil {
+		return FlagSubject{}
+	}
+	s, _ := ctx.Value(flagSubjectKey{}).(FlagSubject)
+	if s.UserID == uuid.Nil {
+		if rd := GetRequestData(ctx); rd != nil {
+			s.UserID = rd.UserID
+		}
+	}
+	return s
+}
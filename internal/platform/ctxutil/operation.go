@@ -0,0 +1,26 @@
+package ctxutil
+
+import (
+	"context"
+	"strings"
+)
+
+type operationKey struct{}
+
+// WithOperation names the logical operation (e.g. "GetPathNodeDoc") that DB queries issued with
+// ctx are attributed to. An inner call replaces the outer name.
+func WithOperation(ctx context.Context, name string) context.Context {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Default(ctx)
+	}
+	return context.WithValue(Default(ctx), operationKey{}, name)
+}
+
+func GetOperation(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
@@ -1,12 +1,24 @@
 package ctxutil
 
-import "context"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type traceDataKey struct{}
 
+// TraceData identifies the request a unit of work descends from. HTTP requests start a trace;
+// jobs carry it forward in their job_run envelope, one span per job run.
 type TraceData struct {
 	TraceID   string
 	RequestID string
+	// SpanID is the job-local span (the job run id); empty while serving the HTTP request.
+	SpanID string
+	// JobID is the job run currently executing, recorded as the parent of jobs it enqueues.
+	JobID uuid.UUID
+	// Depth counts job hops from the originating request (0 = the request itself).
+	Depth int
 }
 
 func WithTraceData(ctx context.Context, td *TraceData) context.Context {
@@ -20,3 +32,27 @@ func GetTraceData(ctx context.Context) *TraceData {
 	}
 	return nil
 }
+
+// TraceFields returns the trace identifiers in ctx as log/metadata key-value pairs, omitting
+// empty ones. It returns nil when ctx carries no trace.
+func TraceFields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	td := GetTraceData(ctx)
+	if td == nil || (td.TraceID == "" && td.RequestID == "") {
+		return nil
+	}
+	out := map[string]any{}
+	if td.TraceID != "" {
+		out["trace_id"] = td.TraceID
+	}
+	if td.RequestID != "" {
+		out["request_id"] = td.RequestID
+	}
+	if td.SpanID != "" {
+		out["span_id"] = td.SpanID
+		out["trace_depth"] = td.Depth
+	}
+	return out
+}
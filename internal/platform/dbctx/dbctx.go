@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 )
 
 // Context bundles a request context with an optional GORM transaction.
@@ -11,3 +13,14 @@ type Context struct {
 	Ctx context.Context
 	Tx  *gorm.DB
 }
+
+// WithOperation returns a copy whose queries are attributed to the named operation.
+func (c Context) WithOperation(name string) Context {
+	c.Ctx = ctxutil.WithOperation(c.Ctx, name)
+	return c
+}
+
+// Operation is the logical operation queries issued through c are attributed to.
+func (c Context) Operation() string {
+	return ctxutil.GetOperation(c.Ctx)
+}
@@ -0,0 +1,110 @@
+package dbstats
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+type tallyKey struct{}
+
+// Tally counts the queries issued with one context, per operation, for budget checks.
+type Tally struct {
+	mu           sync.Mutex
+	counts       map[string]int
+	fingerprints map[string][]string
+}
+
+func WithTally(ctx context.Context) (context.Context, *Tally) {
+	t := &Tally{counts: map[string]int{}, fingerprints: map[string][]string{}}
+	return context.WithValue(ctxutil.Default(ctx), tallyKey{}, t), t
+}
+
+func tallyFromContext(ctx context.Context) *Tally {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(tallyKey{}).(*Tally)
+	return t
+}
+
+func (t *Tally) add(op string, sql string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[op]++
+	t.fingerprints[op] = append(t.fingerprints[op], Fingerprint(sql))
+}
+
+// Count is the number of queries attributed to op so far.
+func (t *Tally) Count(op string) int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[op]
+}
+
+// Queries returns the fingerprints attributed to op, in execution order.
+func (t *Tally) Queries(op string) []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.fingerprints[op]...)
+}
+
+// QueryBudgets fails a test when an operation issues more queries than its declared budget.
+// Budgets live in test code next to the operation they guard, so raising one is a reviewed
+// change. The test db must have Register applied (testutil.DB does).
+type QueryBudgets struct {
+	tb      testing.TB
+	budgets map[string]int
+	tally   *Tally
+}
+
+// ExpectQueryBudgets checks budgets (operation name -> max queries) when the test finishes.
+func ExpectQueryBudgets(tb testing.TB, budgets map[string]int) *QueryBudgets {
+	tb.Helper()
+	_, tally := WithTally(context.Background())
+	b := &QueryBudgets{tb: tb, budgets: budgets, tally: tally}
+	tb.Cleanup(b.Check)
+	return b
+}
+
+// Context attaches the budget tally to ctx; queries issued with the result are counted.
+func (b *QueryBudgets) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctxutil.Default(ctx), tallyKey{}, b.tally)
+}
+
+// Middleware counts every query issued while serving a request through next.
+func (b *QueryBudgets) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(b.Context(r.Context())))
+	})
+}
+
+func (b *QueryBudgets) Tally() *Tally { return b.tally }
+
+// Check reports every operation over budget, listing the fingerprints it ran.
+func (b *QueryBudgets) Check() {
+	b.tb.Helper()
+	ops := make([]string, 0, len(b.budgets))
+	for op := range b.budgets {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		max := b.budgets[op]
+		if got := b.tally.Count(op); got > max {
+			b.tb.Errorf("query budget exceeded for %s: %d queries, budget %d:\n  %s",
+				op, got, max, strings.Join(b.tally.Queries(op), "\n  "))
+		}
+	}
+}
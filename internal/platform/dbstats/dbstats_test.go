@@ -0,0 +1,109 @@
+package dbstats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+func TestFingerprintStripsLiterals(t *testing.T) {
+	cases := map[string]string{
+		`SELECT * FROM "path" WHERE id = $1 AND deleted_at IS NULL LIMIT 1`:     `SELECT * FROM "path" WHERE id = ? AND deleted_at IS NULL LIMIT ?`,
+		"SELECT  *\n FROM concept WHERE key = 'it''s'   AND depth > 2.5":        "SELECT * FROM concept WHERE key = ? AND depth > ?",
+		`SELECT * FROM job_run WHERE status IN ($1,$2, $3) ORDER BY created_at`: `SELECT * FROM job_run WHERE status IN (?+) ORDER BY created_at`,
+		`INSERT INTO t (id) VALUES (uuid_generate_v4())`:                        `INSERT INTO t (id) VALUES (uuid_generate_v4())`,
+	}
+	for in, want := range cases {
+		if got := Fingerprint(in); got != want {
+			t.Fatalf("Fingerprint(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRecorderAggregatesAndResets(t *testing.T) {
+	r := NewRecorder()
+	for i := 1; i <= 100; i++ {
+		r.Observe("GetPathNodeDoc", time.Duration(i)*time.Millisecond, 2, i > 95, false)
+	}
+	r.Observe("", 5*time.Millisecond, 0, false, true)
+
+	snap := r.Snapshot()
+	if len(snap.Operations) != 2 || snap.Operations[0].Operation != "GetPathNodeDoc" {
+		t.Fatalf("unexpected snapshot: %+v", snap.Operations)
+	}
+	got := snap.Operations[0]
+	if got.Count != 100 || got.Rows != 200 || got.Slow != 5 || got.TotalMS != 5050 || got.P95MS != 95 || got.MaxMS != 100 {
+		t.Fatalf("unexpected aggregate: %+v", got)
+	}
+	if un := snap.Operations[1]; un.Operation != UnattributedOperation || un.Errors != 1 {
+		t.Fatalf("unexpected unattributed aggregate: %+v", un)
+	}
+
+	r.Reset()
+	if ops := r.Snapshot().Operations; len(ops) != 0 {
+		t.Fatalf("expected empty snapshot after reset, got %+v", ops)
+	}
+}
+
+type budgetTB struct {
+	testing.TB
+	errs []string
+}
+
+func (b *budgetTB) Helper()                      {}
+func (b *budgetTB) Cleanup(func())               {}
+func (b *budgetTB) Errorf(f string, args ...any) { b.errs = append(b.errs, fmt.Sprintf(f, args...)) }
+
+func TestCallbacksAttributeQueriesAndEnforceBudgets(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=dbstats dbname=dbstats"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	rec := NewRecorder()
+	if err := Register(db, Config{Recorder: rec}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	tb := &budgetTB{TB: t}
+	budgets := ExpectQueryBudgets(tb, map[string]int{"GetPathNodeDoc": 2})
+	ctx := ctxutil.WithOperation(budgets.Context(context.Background()), "GetPathNodeDoc")
+
+	type row struct{ ID int }
+	for i := 0; i < 3; i++ {
+		var out []row
+		db.WithContext(ctx).Table("path").Where("id = ?", i).Find(&out)
+	}
+	db.WithContext(context.Background()).Exec("DELETE FROM path WHERE id = 7")
+
+	if n := budgets.Tally().Count("GetPathNodeDoc"); n != 3 {
+		t.Fatalf("expected 3 attributed queries, got %d", n)
+	}
+	snap := rec.Snapshot()
+	byOp := map[string]OperationStats{}
+	for _, op := range snap.Operations {
+		byOp[op.Operation] = op
+	}
+	if byOp["GetPathNodeDoc"].Count != 3 || byOp[UnattributedOperation].Count != 1 {
+		t.Fatalf("unexpected aggregates: %+v", snap.Operations)
+	}
+
+	budgets.Check()
+	if len(tb.errs) != 1 || !strings.Contains(tb.errs[0], "GetPathNodeDoc: 3 queries, budget 2") {
+		t.Fatalf("expected one budget failure, got %q", tb.errs)
+	}
+	if !strings.Contains(tb.errs[0], `SELECT * FROM "path" WHERE id = ?`) {
+		t.Fatalf("expected fingerprints in failure, got %q", tb.errs[0])
+	}
+}
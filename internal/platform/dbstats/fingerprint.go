@@ -0,0 +1,36 @@
+package dbstats
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fpStringRE      = regexp.MustCompile(`'(?:[^']|'')*'`)
+	fpPlaceholderRE = regexp.MustCompile(`\$\d+`)
+	fpNumberRE      = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	fpListRE        = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	fpSpaceRE       = regexp.MustCompile(`\s+`)
+)
+
+// fingerprintMaxLen bounds fingerprints so huge generated statements stay loggable.
+const fingerprintMaxLen = 2000
+
+// Fingerprint normalizes a statement so executions differing only in literals share one shape:
+// string and numeric literals and bind placeholders become ?, value lists collapse to (?+), and
+// whitespace is folded. Identifiers (including ones with digits, like uuid_generate_v4) are kept.
+func Fingerprint(sql string) string {
+	s := strings.TrimSpace(sql)
+	if s == "" {
+		return ""
+	}
+	s = fpStringRE.ReplaceAllString(s, "?")
+	s = fpPlaceholderRE.ReplaceAllString(s, "?")
+	s = fpNumberRE.ReplaceAllString(s, "?")
+	s = fpListRE.ReplaceAllString(s, "(?+)")
+	s = fpSpaceRE.ReplaceAllString(s, " ")
+	if len(s) > fingerprintMaxLen {
+		s = s[:fingerprintMaxLen] + "…"
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+// Package dbstats instruments GORM with per-operation query accounting.
+//
+// Every statement is attributed to the logical operation named on its context via
+// ctxutil.WithOperation (dbctx.Context carries it through repos automatically). Durations and
+// row counts feed a Recorder, statements slower than the threshold are logged with their
+// literal-free fingerprint, and a Tally on the context counts queries for budget checks.
+package dbstats
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	EnvSlowQueryMS = "DB_SLOW_QUERY_MS"
+
+	defaultSlowQueryMS = 250
+	startKey           = "dbstats:start"
+)
+
+type Config struct {
+	// SlowThreshold logs statements at or above this duration; <= 0 disables slow-query logs.
+	SlowThreshold time.Duration
+	Recorder      *Recorder
+	Log           *logger.Logger
+}
+
+// ConfigFromEnv reads the slow-query threshold and records into the default recorder.
+func ConfigFromEnv(log *logger.Logger) Config {
+	return Config{
+		SlowThreshold: time.Duration(envutil.Int(EnvSlowQueryMS, defaultSlowQueryMS)) * time.Millisecond,
+		Recorder:      Default(),
+		Log:           log,
+	}
+}
+
+// Register installs before/after callbacks around every GORM statement kind.
+func Register(db *gorm.DB, cfg Config) error {
+	if db == nil {
+		return errors.New("dbstats: nil db")
+	}
+	if cfg.Recorder == nil {
+		cfg.Recorder = Default()
+	}
+	if cfg.Log != nil {
+		cfg.Log = cfg.Log.With("component", "dbstats")
+	}
+	after := afterCallback(cfg)
+	cb := db.Callback()
+	regs := []struct {
+		name string
+		err  error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register("dbstats:before_create", beforeCallback)},
+		{"create", cb.Create().After("gorm:create").Register("dbstats:after_create", after)},
+		{"query", cb.Query().Before("gorm:query").Register("dbstats:before_query", beforeCallback)},
+		{"query", cb.Query().After("gorm:query").Register("dbstats:after_query", after)},
+		{"update", cb.Update().Before("gorm:update").Register("dbstats:before_update", beforeCallback)},
+		{"update", cb.Update().After("gorm:update").Register("dbstats:after_update", after)},
+		{"delete", cb.Delete().Before("gorm:delete").Register("dbstats:before_delete", beforeCallback)},
+		{"delete", cb.Delete().After("gorm:delete").Register("dbstats:after_delete", after)},
+		{"row", cb.Row().Before("gorm:row").Register("dbstats:before_row", beforeCallback)},
+		{"row", cb.Row().After("gorm:row").Register("dbstats:after_row", after)},
+		{"raw", cb.Raw().Before("gorm:raw").Register("dbstats:before_raw", beforeCallback)},
+		{"raw", cb.Raw().After("gorm:raw").Register("dbstats:after_raw", after)},
+	}
+	for _, r := range regs {
+		if r.err != nil {
+			return fmt.Errorf("dbstats: register %s callback: %w", r.name, r.err)
+		}
+	}
+	return nil
+}
+
+func beforeCallback(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func afterCallback(cfg Config) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil {
+			return
+		}
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		dur := time.Since(start)
+		ctx := db.Statement.Context
+		op := ctxutil.GetOperation(ctx)
+		if op == "" {
+			op = UnattributedOperation
+		}
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		slow := cfg.SlowThreshold > 0 && dur >= cfg.SlowThreshold
+		sql := db.Statement.SQL.String()
+
+		cfg.Recorder.Observe(op, dur, db.Statement.RowsAffected, slow, failed)
+		if t := tallyFromContext(ctx); t != nil {
+			t.add(op, sql)
+		}
+		if slow && cfg.Log != nil {
+			cfg.Log.Warn("slow query",
+				"operation", op,
+				"duration_ms", durationMS(dur),
+				"rows", db.Statement.RowsAffected,
+				"table", db.Statement.Table,
+				"fingerprint", Fingerprint(sql),
+			)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package dbstats
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UnattributedOperation collects queries issued without an operation name on their context.
+const UnattributedOperation = "unattributed"
+
+// recorderSampleSize bounds the per-operation latency window p95 is computed over.
+const recorderSampleSize = 512
+
+// OperationStats aggregates the queries attributed to one logical operation.
+type OperationStats struct {
+	Operation string  `json:"operation"`
+	Count     int64   `json:"count"`
+	Rows      int64   `json:"rows"`
+	Slow      int64   `json:"slow"`
+	Errors    int64   `json:"errors"`
+	TotalMS   float64 `json:"total_ms"`
+	P95MS     float64 `json:"p95_ms"`
+	MaxMS     float64 `json:"max_ms"`
+}
+
+type Snapshot struct {
+	Since      time.Time        `json:"since"`
+	Operations []OperationStats `json:"operations"`
+}
+
+type opAggregate struct {
+	count   int64
+	rows    int64
+	slow    int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+	next    int
+}
+
+// Recorder keeps per-operation query aggregates. p95 is computed over the most recent
+// recorderSampleSize queries of each operation; counts and totals cover everything since Reset.
+type Recorder struct {
+	mu    sync.Mutex
+	ops   map[string]*opAggregate
+	since time.Time
+	now   func() time.Time
+}
+
+func NewRecorder() *Recorder {
+	r := &Recorder{now: time.Now}
+	r.Reset()
+	return r
+}
+
+var defaultRecorder = NewRecorder()
+
+// Default is the process-wide recorder the registered callbacks write to unless configured otherwise.
+func Default() *Recorder { return defaultRecorder }
+
+func (r *Recorder) Observe(op string, dur time.Duration, rows int64, slow bool, failed bool) {
+	if r == nil {
+		return
+	}
+	if op == "" {
+		op = UnattributedOperation
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	agg := r.ops[op]
+	if agg == nil {
+		agg = &opAggregate{samples: make([]time.Duration, 0, 16)}
+		r.ops[op] = agg
+	}
+	agg.count++
+	agg.rows += rows
+	agg.total += dur
+	if dur > agg.max {
+		agg.max = dur
+	}
+	if slow {
+		agg.slow++
+	}
+	if failed {
+		agg.errors++
+	}
+	if len(agg.samples) < recorderSampleSize {
+		agg.samples = append(agg.samples, dur)
+	} else {
+		agg.samples[agg.next] = dur
+		agg.next = (agg.next + 1) % recorderSampleSize
+	}
+}
+
+// Snapshot returns the aggregates ordered by total time, slowest first.
+func (r *Recorder) Snapshot() Snapshot {
+	if r == nil {
+		return Snapshot{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := Snapshot{Since: r.since, Operations: make([]OperationStats, 0, len(r.ops))}
+	for op, agg := range r.ops {
+		out.Operations = append(out.Operations, OperationStats{
+			Operation: op,
+			Count:     agg.count,
+			Rows:      agg.rows,
+			Slow:      agg.slow,
+			Errors:    agg.errors,
+			TotalMS:   durationMS(agg.total),
+			P95MS:     durationMS(percentile(agg.samples, 0.95)),
+			MaxMS:     durationMS(agg.max),
+		})
+	}
+	sort.Slice(out.Operations, func(i, j int) bool {
+		if out.Operations[i].TotalMS != out.Operations[j].TotalMS {
+			return out.Operations[i].TotalMS > out.Operations[j].TotalMS
+		}
+		return out.Operations[i].Operation < out.Operations[j].Operation
+	})
+	return out
+}
+
+func (r *Recorder) Reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = map[string]*opAggregate{}
+	r.since = r.now().UTC()
+}
+
+func percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(float64(len(sorted))*p)) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,151 @@
+// Package errclass sorts provider and database errors into a few categories that callers use to
+// decide between retrying and failing. Typed errors (pgconn.PgError, HTTP status carriers,
+// net.Error, context errors) are checked first; message matching is the fallback for errors
+// whose type was lost to wrapping, and all of it lives here rather than at call sites.
+package errclass
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/httpx"
+)
+
+type Class string
+
+const (
+	// None is the class of a nil error.
+	None Class = ""
+	// Timeout covers transient failures worth retrying as-is: deadlines, network timeouts,
+	// 408/5xx responses, deadlocks, serialization failures and lock timeouts.
+	Timeout Class = "retryable_timeout"
+	// RateLimited is provider backpressure (429, or 503 load shedding); retry after a wait.
+	RateLimited Class = "rate_limited"
+	// ContextLength means the prompt did not fit the model; retry only with a smaller input.
+	ContextLength Class = "context_length"
+	// UniqueViolation is a duplicate-key insert; usually another writer won a race.
+	UniqueViolation Class = "unique_violation"
+	// Fatal is everything else, including cancellation: retrying won't help.
+	Fatal Class = "fatal"
+)
+
+// Retryable reports whether the same call may succeed if retried after a backoff.
+func (c Class) Retryable() bool {
+	return c == Timeout || c == RateLimited
+}
+
+// Postgres SQLSTATE codes.
+const (
+	pgUniqueViolation      = "23505"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+	pgQueryCanceled        = "57014" // statement_timeout
+)
+
+// Classify returns err's class (None for nil).
+func Classify(err error) Class {
+	if err == nil {
+		return None
+	}
+	// Checked before status codes: context overflows arrive as plain 400s.
+	if IsContextLength(err) {
+		return ContextLength
+	}
+	if errors.Is(err, context.Canceled) {
+		return Fatal
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch strings.TrimSpace(pgErr.Code) {
+		case pgUniqueViolation:
+			return UniqueViolation
+		case pgSerializationFailure, pgDeadlockDetected, pgLockNotAvailable, pgQueryCanceled:
+			return Timeout
+		}
+		return Fatal
+	}
+
+	var sc httpx.HTTPStatusCoder
+	if errors.As(err, &sc) {
+		if code := sc.HTTPStatusCode(); code > 0 {
+			return classifyStatus(code)
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+
+	return classifyMessage(strings.ToLower(err.Error()))
+}
+
+func classifyStatus(code int) Class {
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+		return RateLimited
+	case httpx.IsRetryableHTTPStatus(code):
+		return Timeout
+	default:
+		return Fatal
+	}
+}
+
+func classifyMessage(msg string) Class {
+	switch {
+	case strings.Contains(msg, "sqlstate "+pgUniqueViolation),
+		strings.Contains(msg, "duplicate key"),
+		strings.Contains(msg, "already exists"):
+		return UniqueViolation
+	case strings.Contains(msg, "rate limit"),
+		strings.Contains(msg, "rate_limit"),
+		strings.Contains(msg, "too many requests"):
+		return RateLimited
+	case strings.Contains(msg, "deadlock"),
+		strings.Contains(msg, "serialization"),
+		strings.Contains(msg, "timeout"),
+		strings.Contains(msg, "timed out"),
+		strings.Contains(msg, "temporar"),
+		strings.Contains(msg, "connection reset"):
+		return Timeout
+	default:
+		return Fatal
+	}
+}
+
+// IsContextLength reports whether err says the prompt exceeded the model's context window.
+func IsContextLength(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "context_length_exceeded") ||
+		strings.Contains(msg, "exceeds the context window") ||
+		strings.Contains(msg, "maximum context length")
+}
+
+// IsUniqueViolation reports whether err is a unique violation, on constraint when it is not empty.
+func IsUniqueViolation(err error, constraint string) bool {
+	if Classify(err) != UniqueViolation {
+		return false
+	}
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.EqualFold(strings.TrimSpace(pgErr.ConstraintName), constraint)
+	}
+	return strings.Contains(strings.ToLower(err.Error()), strings.ToLower(constraint))
+}
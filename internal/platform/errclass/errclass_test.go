@@ -0,0 +1,82 @@
+package errclass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type statusErr int
+
+func (e statusErr) Error() string       { return fmt.Sprintf("http %d", int(e)) }
+func (e statusErr) HTTPStatusCode() int { return int(e) }
+
+type netTimeout struct{}
+
+func (netTimeout) Error() string   { return "i/o" }
+func (netTimeout) Timeout() bool   { return true }
+func (netTimeout) Temporary() bool { return false }
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, None},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), Timeout},
+		{"canceled", context.Canceled, Fatal},
+		{"net timeout", fmt.Errorf("dial: %w", netTimeout{}), Timeout},
+		{"pg unique", &pgconn.PgError{Code: "23505", ConstraintName: "idx_concept_key"}, UniqueViolation},
+		{"pg deadlock", fmt.Errorf("tx: %w", &pgconn.PgError{Code: "40P01"}), Timeout},
+		{"pg statement timeout", &pgconn.PgError{Code: "57014"}, Timeout},
+		{"pg other", &pgconn.PgError{Code: "23502"}, Fatal},
+		{"http 429", statusErr(429), RateLimited},
+		{"http 503", fmt.Errorf("wrapped: %w", statusErr(503)), RateLimited},
+		{"http 502", statusErr(502), Timeout},
+		{"http 401", statusErr(401), Fatal},
+		{"context length over 400", fmt.Errorf("%w: context_length_exceeded", statusErr(400)), ContextLength},
+		{"context length message", errors.New("This model's maximum context length is 128000 tokens"), ContextLength},
+		{"unique message", errors.New("ERROR: duplicate key value violates unique constraint (SQLSTATE 23505)"), UniqueViolation},
+		{"rate limit message", errors.New("Rate limit reached for requests"), RateLimited},
+		{"timeout message", errors.New("read tcp: i/o timeout"), Timeout},
+		{"other", errors.New("invalid schema"), Fatal},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.err); got != tc.want {
+				t.Fatalf("Classify(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for c, want := range map[Class]bool{
+		Timeout: true, RateLimited: true, ContextLength: false, UniqueViolation: false, Fatal: false, None: false,
+	} {
+		if c.Retryable() != want {
+			t.Fatalf("%q.Retryable() = %v, want %v", c, !want, want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	pgErr := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", ConstraintName: "idx_concept_key"})
+	if !IsUniqueViolation(pgErr, "") || !IsUniqueViolation(pgErr, "IDX_CONCEPT_KEY") {
+		t.Fatalf("expected a unique violation on idx_concept_key")
+	}
+	if IsUniqueViolation(pgErr, "idx_other") {
+		t.Fatalf("matched the wrong constraint")
+	}
+	msgErr := errors.New(`duplicate key value violates unique constraint "idx_concept_key" (SQLSTATE 23505)`)
+	if !IsUniqueViolation(msgErr, "idx_concept_key") || IsUniqueViolation(msgErr, "idx_other") {
+		t.Fatalf("message fallback did not honor the constraint")
+	}
+	if IsUniqueViolation(errors.New("boom"), "") {
+		t.Fatalf("plain error is not a unique violation")
+	}
+}
@@ -0,0 +1,233 @@
+package featureflag
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+const EnvFeatureFlagRefreshSeconds = "FEATURE_FLAG_REFRESH_SECONDS"
+
+// Source loads every flag definition. The default has no flags, so every evaluation falls back
+// to the caller's env default.
+type Source func(ctx context.Context) ([]*types.FeatureFlag, error)
+
+func emptySource(ctx context.Context) ([]*types.FeatureFlag, error) { return nil, nil }
+
+// DBSource reads live rows from the feature_flag table.
+func DBSource(db *gorm.DB) Source {
+	return func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		var rows []*types.FeatureFlag
+		if err := db.WithContext(ctx).Order("name ASC").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+}
+
+func RefreshInterval() time.Duration {
+	n := envutil.Int(EnvFeatureFlagRefreshSeconds, 30)
+	if n < 1 || n > 3600 {
+		n = 30
+	}
+	return time.Duration(n) * time.Second
+}
+
+type snapshot struct {
+	flags    map[string]*compiledFlag
+	loadedAt time.Time
+}
+
+// Evaluator caches flag definitions in process. Evaluate never blocks on the source after the
+// first load: it reads the current snapshot and, at most once per TTL, kicks off a refresh. A
+// failed refresh keeps serving the previous snapshot.
+type Evaluator struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu         sync.Mutex
+	source     Source
+	refreshing bool
+
+	cur atomic.Pointer[snapshot]
+}
+
+func NewEvaluator(source Source, ttl time.Duration) *Evaluator {
+	if source == nil {
+		source = emptySource
+	}
+	if ttl <= 0 {
+		ttl = RefreshInterval()
+	}
+	return &Evaluator{ttl: ttl, now: time.Now, source: source}
+}
+
+// Evaluate resolves name for the flag subject on ctx (see ctxutil.WithFlagSubject).
+// envDefault is the value the gate had before flags: it applies when the flag has no row or no
+// table default and no targeting rule matches.
+func (e *Evaluator) Evaluate(ctx context.Context, name string, envDefault string) Evaluation {
+	name = normalizeName(name)
+	snap := e.snapshot(ctx)
+	var f *compiledFlag
+	if snap != nil {
+		f = snap.flags[name]
+	}
+	return f.evaluate(name, ctxutil.GetFlagSubject(ctx), envDefault)
+}
+
+// Bool evaluates a boolean flag. Values that don't parse as booleans fall back to envDefault.
+func (e *Evaluator) Bool(ctx context.Context, name string, envDefault bool) (bool, Evaluation) {
+	ev := e.Evaluate(ctx, name, strconv.FormatBool(envDefault))
+	v, ok := ParseBool(ev.Value)
+	if !ok {
+		ev.Value, ev.Source = strconv.FormatBool(envDefault), SourceEnv
+		return envDefault, ev
+	}
+	return v, ev
+}
+
+func (e *Evaluator) snapshot(ctx context.Context) *snapshot {
+	snap := e.cur.Load()
+	if snap == nil {
+		if err := e.Refresh(ctx); err != nil {
+			// Serve env defaults until the next TTL rather than hitting the source per call.
+			e.cur.CompareAndSwap(nil, &snapshot{flags: map[string]*compiledFlag{}, loadedAt: e.now()})
+		}
+		return e.cur.Load()
+	}
+	if e.now().Sub(snap.loadedAt) >= e.ttl {
+		e.refreshAsync()
+	}
+	return snap
+}
+
+// Refresh reloads flag definitions immediately.
+func (e *Evaluator) Refresh(ctx context.Context) error {
+	ctx = ctxutil.Default(ctx)
+	e.mu.Lock()
+	source := e.source
+	e.mu.Unlock()
+	rows, err := source(ctx)
+	if err != nil {
+		return err
+	}
+	snap := &snapshot{flags: map[string]*compiledFlag{}, loadedAt: e.now()}
+	for _, row := range rows {
+		if row == nil || strings.TrimSpace(row.Name) == "" {
+			continue
+		}
+		f := compileFlag(row)
+		snap.flags[f.Name] = &f
+	}
+	e.cur.Store(snap)
+	return nil
+}
+
+// SetSource replaces the flag source and reloads from it.
+func (e *Evaluator) SetSource(ctx context.Context, source Source) error {
+	if source == nil {
+		source = emptySource
+	}
+	e.mu.Lock()
+	e.source = source
+	e.mu.Unlock()
+	return e.Refresh(ctx)
+}
+
+// Run refreshes flags every TTL until ctx is done.
+func (e *Evaluator) Run(ctx context.Context) {
+	_ = e.Refresh(ctx)
+	ticker := time.NewTicker(e.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = e.Refresh(ctx)
+		}
+	}
+}
+
+func (e *Evaluator) refreshAsync() {
+	e.mu.Lock()
+	if e.refreshing {
+		e.mu.Unlock()
+		return
+	}
+	e.refreshing = true
+	e.mu.Unlock()
+	go func() {
+		defer func() {
+			e.mu.Lock()
+			e.refreshing = false
+			e.mu.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = e.Refresh(ctx)
+	}()
+}
+
+// FlagInfo describes one cached flag for the admin listing.
+type FlagInfo struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description,omitempty"`
+	DefaultValue *string `json:"default_value,omitempty"`
+	Rules        Rules   `json:"rules"`
+}
+
+type Listing struct {
+	LoadedAt time.Time  `json:"loaded_at"`
+	Flags    []FlagInfo `json:"flags"`
+}
+
+// List returns the cached flags and their rules, sorted by name.
+func (e *Evaluator) List(ctx context.Context) Listing {
+	out := Listing{Flags: []FlagInfo{}}
+	snap := e.snapshot(ctx)
+	if snap == nil {
+		return out
+	}
+	out.LoadedAt = snap.loadedAt
+	for _, f := range snap.flags {
+		out.Flags = append(out.Flags, FlagInfo{Name: f.Name, Description: f.Description, DefaultValue: f.DefaultValue, Rules: f.Rules})
+	}
+	sort.Slice(out.Flags, func(i, j int) bool { return out.Flags[i].Name < out.Flags[j].Name })
+	return out
+}
+
+var defaultEvaluator = NewEvaluator(nil, 0)
+
+// Default returns the shared evaluator.
+func Default() *Evaluator { return defaultEvaluator }
+
+// Eval evaluates name on the shared evaluator.
+func Eval(ctx context.Context, name string, envDefault string) Evaluation {
+	return defaultEvaluator.Evaluate(ctx, name, envDefault)
+}
+
+// Bool evaluates a boolean flag on the shared evaluator.
+func Bool(ctx context.Context, name string, envDefault bool) (bool, Evaluation) {
+	return defaultEvaluator.Bool(ctx, name, envDefault)
+}
+
+// SetSource swaps the source behind the shared evaluator.
+func SetSource(ctx context.Context, source Source) error {
+	return defaultEvaluator.SetSource(ctx, source)
+}
+
+// RunRefresher keeps the shared evaluator warm until ctx is done.
+func RunRefresher(ctx context.Context) {
+	defaultEvaluator.Run(ctx)
+}
@@ -0,0 +1,156 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+func staticSource(rows ...*types.FeatureFlag) Source {
+	return func(ctx context.Context) ([]*types.FeatureFlag, error) { return rows, nil }
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestEvaluatePrecedence(t *testing.T) {
+	beta, other, pathID := uuid.New(), uuid.New(), uuid.New()
+	e := NewEvaluator(staticSource(
+		&types.FeatureFlag{Name: "no_default"},
+		&types.FeatureFlag{Name: "Mode", DefaultValue: strPtr("shadow"), Rules: datatypes.JSON(`{
+			"paths": {"` + pathID.String() + `": "off"},
+			"users": [{"value": "active", "user_ids": ["` + beta.String() + `"]}]
+		}`)},
+	), time.Minute)
+
+	subject := func(user, path uuid.UUID) context.Context {
+		return ctxutil.WithFlagSubject(context.Background(), ctxutil.FlagSubject{UserID: user, PathID: path})
+	}
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		flag   string
+		value  string
+		source string
+	}{
+		{"no row: env", subject(beta, uuid.Nil), "missing", "env-v", SourceEnv},
+		{"row without default: env", subject(other, uuid.Nil), "no_default", "env-v", SourceEnv},
+		{"table default beats env", subject(other, uuid.Nil), "mode", "shadow", SourceDefault},
+		{"allowlist beats default", subject(beta, uuid.Nil), "mode", "active", SourceUser},
+		{"path override beats allowlist", subject(beta, pathID), "mode", "off", SourcePath},
+		{"no subject: default", context.Background(), "mode", "shadow", SourceDefault},
+	}
+	for _, tc := range cases {
+		got := e.Evaluate(tc.ctx, tc.flag, "env-v")
+		if got.Value != tc.value || got.Source != tc.source {
+			t.Fatalf("%s: got %+v", tc.name, got)
+		}
+	}
+
+	// The authenticated user is the fallback subject.
+	ctx := ctxutil.WithRequestData(context.Background(), &ctxutil.RequestData{UserID: beta})
+	if got := e.Evaluate(ctx, "mode", ""); got.Source != SourceUser {
+		t.Fatalf("request user fallback = %+v", got)
+	}
+}
+
+func TestRolloutIsStableAndProportional(t *testing.T) {
+	e := NewEvaluator(staticSource(&types.FeatureFlag{
+		Name:  "beta",
+		Rules: datatypes.JSON(`{"rollout": {"pct": 0.25, "value": "true"}}`),
+	}), time.Minute)
+
+	in := 0
+	for i := 0; i < 4000; i++ {
+		ctx := ctxutil.WithFlagSubject(context.Background(), ctxutil.FlagSubject{UserID: uuid.New()})
+		on, ev := e.Bool(ctx, "beta", false)
+		if again, _ := e.Bool(ctx, "beta", false); again != on {
+			t.Fatal("rollout not stable for a user")
+		}
+		if on {
+			in++
+			if ev.Source != SourceRollout || ev.Bucket >= 0.25 {
+				t.Fatalf("rollout evaluation = %+v", ev)
+			}
+		}
+	}
+	if in < 850 || in > 1150 {
+		t.Fatalf("rollout admitted %d/4000, want ~1000", in)
+	}
+
+	// Unparseable values fall back to the env default.
+	bad := NewEvaluator(staticSource(&types.FeatureFlag{Name: "beta", DefaultValue: strPtr("maybe")}), time.Minute)
+	if on, ev := bad.Bool(context.Background(), "beta", true); !on || ev.Source != SourceEnv {
+		t.Fatalf("bad bool = %v %+v", on, ev)
+	}
+}
+
+func TestEvaluatorRefresh(t *testing.T) {
+	var loads atomic.Int32
+	var fail atomic.Bool
+	def := atomic.Value{}
+	def.Store("a")
+	source := func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		loads.Add(1)
+		if fail.Load() {
+			return nil, errors.New("db down")
+		}
+		return []*types.FeatureFlag{{Name: "f", DefaultValue: strPtr(def.Load().(string))}}, nil
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var clock atomic.Pointer[time.Time]
+	clock.Store(&now)
+	e := NewEvaluator(source, 30*time.Second)
+	e.now = func() time.Time { return *clock.Load() }
+	ctx := context.Background()
+
+	if got := e.Evaluate(ctx, "f", ""); got.Value != "a" || loads.Load() != 1 {
+		t.Fatalf("first = %+v loads=%d", got, loads.Load())
+	}
+	def.Store("b")
+	if got := e.Evaluate(ctx, "f", ""); got.Value != "a" || loads.Load() != 1 {
+		t.Fatalf("within TTL = %+v loads=%d", got, loads.Load())
+	}
+
+	later := now.Add(31 * time.Second)
+	clock.Store(&later)
+	_ = e.Evaluate(ctx, "f", "") // kicks off the async refresh
+	deadline := time.Now().Add(2 * time.Second)
+	for e.Evaluate(ctx, "f", "").Value != "b" {
+		if time.Now().After(deadline) {
+			t.Fatal("refresh after TTL never landed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A failed refresh keeps the last snapshot.
+	fail.Store(true)
+	if err := e.Refresh(ctx); err == nil {
+		t.Fatal("expected refresh error")
+	}
+	if got := e.Evaluate(ctx, "f", ""); got.Value != "b" {
+		t.Fatalf("after failed refresh = %+v", got)
+	}
+
+	// A source that is down at boot serves env defaults instead of failing each call.
+	down := NewEvaluator(func(ctx context.Context) ([]*types.FeatureFlag, error) {
+		loads.Add(1)
+		return nil, errors.New("no table")
+	}, time.Minute)
+	before := loads.Load()
+	for i := 0; i < 3; i++ {
+		if got := down.Evaluate(ctx, "f", "env"); got.Source != SourceEnv {
+			t.Fatalf("down source = %+v", got)
+		}
+	}
+	if loads.Load()-before != 1 {
+		t.Fatalf("down source loaded %d times", loads.Load()-before)
+	}
+}
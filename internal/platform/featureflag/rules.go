@@ -0,0 +1,167 @@
+package featureflag
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+)
+
+// Evaluation sources, lowest precedence first.
+const (
+	SourceEnv     = "env"
+	SourceDefault = "default"
+	SourceRollout = "rollout"
+	SourceUser    = "user"
+	SourcePath    = "path"
+)
+
+// Rules are a flag's targeting rules (feature_flag.rules). The first match wins, in order:
+// path override, user allowlist, percentage rollout. With no match the flag's table default
+// applies, and without one the caller's env default.
+type Rules struct {
+	// Paths maps a path id to the value every user sees on that path.
+	Paths map[string]string `json:"paths,omitempty"`
+	// Users lists allowlists; each user id gets that rule's value.
+	Users []UserRule `json:"users,omitempty"`
+	// Rollout gives Value to a stable fraction of users.
+	Rollout *Rollout `json:"rollout,omitempty"`
+}
+
+type UserRule struct {
+	Value   string   `json:"value"`
+	UserIDs []string `json:"user_ids"`
+}
+
+type Rollout struct {
+	// Pct is the fraction of users (0..1) bucketed into Value.
+	Pct   float64 `json:"pct"`
+	Value string  `json:"value"`
+	// Salt reshuffles buckets without renaming the flag.
+	Salt string `json:"salt,omitempty"`
+}
+
+// Evaluation is one flag decision, recorded in traces next to the decision it gated.
+type Evaluation struct {
+	Flag   string  `json:"flag"`
+	Value  string  `json:"value"`
+	Source string  `json:"source"`
+	Bucket float64 `json:"bucket,omitempty"`
+}
+
+// Annotate records e under meta["flags"][flag].
+func (e Evaluation) Annotate(meta map[string]any) {
+	if meta == nil || e.Flag == "" {
+		return
+	}
+	flags, _ := meta["flags"].(map[string]any)
+	if flags == nil {
+		flags = map[string]any{}
+		meta["flags"] = flags
+	}
+	entry := map[string]any{"value": e.Value, "source": e.Source}
+	if e.Source == SourceRollout || e.Bucket > 0 {
+		entry["bucket"] = e.Bucket
+	}
+	flags[e.Flag] = entry
+}
+
+// compiledFlag is a flag row with its rules decoded once per refresh.
+type compiledFlag struct {
+	Name         string
+	Description  string
+	DefaultValue *string
+	Rules        Rules
+	paths        map[uuid.UUID]string
+	users        map[uuid.UUID]string
+}
+
+func compileFlag(row *types.FeatureFlag) compiledFlag {
+	f := compiledFlag{
+		Name:         normalizeName(row.Name),
+		Description:  row.Description,
+		DefaultValue: row.DefaultValue,
+		paths:        map[uuid.UUID]string{},
+		users:        map[uuid.UUID]string{},
+	}
+	if len(row.Rules) > 0 {
+		// Malformed rules leave the flag on its default rather than failing the refresh.
+		_ = json.Unmarshal(row.Rules, &f.Rules)
+	}
+	for raw, v := range f.Rules.Paths {
+		if id, err := uuid.Parse(strings.TrimSpace(raw)); err == nil && id != uuid.Nil {
+			f.paths[id] = v
+		}
+	}
+	for _, r := range f.Rules.Users {
+		for _, raw := range r.UserIDs {
+			id, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil || id == uuid.Nil {
+				continue
+			}
+			// Earlier allowlists win when a user appears twice.
+			if _, ok := f.users[id]; !ok {
+				f.users[id] = r.Value
+			}
+		}
+	}
+	return f
+}
+
+// evaluate applies the precedence env < table default < targeting for one subject.
+func (f *compiledFlag) evaluate(name string, subject ctxutil.FlagSubject, envDefault string) Evaluation {
+	out := Evaluation{Flag: name, Value: envDefault, Source: SourceEnv}
+	if f == nil {
+		return out
+	}
+	if f.DefaultValue != nil {
+		out.Value, out.Source = *f.DefaultValue, SourceDefault
+	}
+	if subject.PathID != uuid.Nil {
+		if v, ok := f.paths[subject.PathID]; ok {
+			out.Value, out.Source = v, SourcePath
+			return out
+		}
+	}
+	if subject.UserID == uuid.Nil {
+		return out
+	}
+	if v, ok := f.users[subject.UserID]; ok {
+		out.Value, out.Source = v, SourceUser
+		return out
+	}
+	if r := f.Rules.Rollout; r != nil && r.Pct > 0 {
+		b := Bucket(name, r.Salt, subject.UserID)
+		if b < r.Pct {
+			out.Value, out.Source, out.Bucket = r.Value, SourceRollout, b
+		}
+	}
+	return out
+}
+
+// Bucket maps a user to a stable position in [0,1) for a flag, so raising Pct only adds users.
+func Bucket(flag string, salt string, userID uuid.UUID) float64 {
+	sum := sha256.Sum256([]byte(normalizeName(flag) + "|" + salt + "|" + userID.String()))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / float64(uint64(1)<<53)
+}
+
+// ParseBool reads a flag value as a boolean; ok is false for anything unrecognized.
+func ParseBool(v string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "t", "yes", "y", "on":
+		return true, true
+	case "0", "false", "f", "no", "n", "off":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,278 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sealed values look like "nbenc1.<key version>.<wrapped data key>.<nonce+ciphertext>", both
+// binary parts base64url without padding. The key version is the master key version that
+// wrapped the data key; rotation re-seals anything whose version isn't the current one.
+const sealedPrefix = "nbenc1."
+
+var (
+	ErrNoKeys    = errors.New("fieldcrypt: no keys configured")
+	ErrMalformed = errors.New("fieldcrypt: malformed sealed value")
+)
+
+// KeyWrapper wraps data keys under a versioned master key (KMS in production, a local AES
+// keyring in dev).
+type KeyWrapper interface {
+	// CurrentVersion is the master key version new data keys are wrapped under.
+	CurrentVersion(ctx context.Context) (string, error)
+	Wrap(ctx context.Context, dataKey []byte) (version string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, version string, wrapped []byte) ([]byte, error)
+}
+
+type Options struct {
+	// DataKeyTTL bounds how long one data key seals new values before a fresh one is wrapped.
+	DataKeyTTL time.Duration
+	// CacheSize bounds the unwrapped data keys kept for reads.
+	CacheSize int
+	// CacheTTL bounds how long an unwrapped data key stays cached.
+	CacheTTL time.Duration
+}
+
+// Envelope seals individual field values with AES-256-GCM data keys wrapped by a KeyWrapper.
+// A nil *Envelope is valid and disabled: Seal passes plaintext through, Open only accepts
+// values that were never sealed.
+type Envelope struct {
+	wrapper KeyWrapper
+	opts    Options
+
+	mu      sync.Mutex
+	current *dataKey
+	cache   map[string]*dataKey
+}
+
+type dataKey struct {
+	version string
+	wrapped string
+	aead    cipher.AEAD
+	created time.Time
+	used    time.Time
+}
+
+func New(wrapper KeyWrapper, opts Options) *Envelope {
+	if wrapper == nil {
+		return nil
+	}
+	if opts.DataKeyTTL <= 0 {
+		opts.DataKeyTTL = time.Hour
+	}
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = 256
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = time.Hour
+	}
+	return &Envelope{wrapper: wrapper, opts: opts, cache: map[string]*dataKey{}}
+}
+
+// Enabled reports whether Seal actually encrypts.
+func (e *Envelope) Enabled() bool { return e != nil && e.wrapper != nil }
+
+// IsSealed reports whether s is a sealed value.
+func IsSealed(s string) bool { return strings.HasPrefix(s, sealedPrefix) }
+
+// KeyVersion returns the master key version a sealed value was wrapped under ("" otherwise).
+func KeyVersion(s string) string {
+	if !IsSealed(s) {
+		return ""
+	}
+	version, _, _ := strings.Cut(strings.TrimPrefix(s, sealedPrefix), ".")
+	return version
+}
+
+// Seal encrypts plaintext. Already-sealed values are returned unchanged.
+func (e *Envelope) Seal(ctx context.Context, plaintext string) (string, error) {
+	if !e.Enabled() || IsSealed(plaintext) {
+		return plaintext, nil
+	}
+	dk, err := e.sealingKey(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	return seal(dk, plaintext)
+}
+
+// Open decrypts a sealed value; values that were never sealed are returned as-is so rows
+// written before encryption was enabled keep reading.
+func (e *Envelope) Open(ctx context.Context, s string) (string, error) {
+	if !IsSealed(s) {
+		return s, nil
+	}
+	if !e.Enabled() {
+		return "", ErrNoKeys
+	}
+	parts := strings.Split(strings.TrimPrefix(s, sealedPrefix), ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", ErrMalformed
+	}
+	dk, err := e.openingKey(ctx, parts[0], parts[1])
+	if err != nil {
+		return "", err
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(body) < dk.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ct := body[:dk.aead.NonceSize()], body[dk.aead.NonceSize():]
+	pt, err := dk.aead.Open(nil, nonce, ct, []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: open: %w", err)
+	}
+	return string(pt), nil
+}
+
+// Reseal re-encrypts s under the current master key version. It reports whether s changed;
+// plaintext and values already on the current version are left alone.
+func (e *Envelope) Reseal(ctx context.Context, s string) (string, bool, error) {
+	if !e.Enabled() || !IsSealed(s) {
+		return s, false, nil
+	}
+	dk, err := e.sealingKey(ctx, false)
+	if err != nil {
+		return "", false, err
+	}
+	if KeyVersion(s) == dk.version {
+		return s, false, nil
+	}
+	pt, err := e.Open(ctx, s)
+	if err != nil {
+		return "", false, err
+	}
+	out, err := seal(dk, pt)
+	if err != nil {
+		return "", false, err
+	}
+	return out, true, nil
+}
+
+// Refresh asks the wrapper for its current version and drops the sealing data key when the
+// master key has rotated since. Rotation runs call it first so they re-seal to the newest key.
+func (e *Envelope) Refresh(ctx context.Context) (string, error) {
+	if !e.Enabled() {
+		return "", ErrNoKeys
+	}
+	dk, err := e.sealingKey(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	return dk.version, nil
+}
+
+func (e *Envelope) sealingKey(ctx context.Context, checkVersion bool) (*dataKey, error) {
+	e.mu.Lock()
+	dk := e.current
+	e.mu.Unlock()
+	now := time.Now()
+	if dk != nil && now.Sub(dk.created) < e.opts.DataKeyTTL {
+		if !checkVersion {
+			return dk, nil
+		}
+		version, err := e.wrapper.CurrentVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if version == dk.version {
+			return dk, nil
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	version, wrapped, err := e.wrapper.Wrap(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: wrap data key: %w", err)
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	dk = &dataKey{
+		version: version,
+		wrapped: base64.RawURLEncoding.EncodeToString(wrapped),
+		aead:    aead,
+		created: now,
+		used:    now,
+	}
+	e.mu.Lock()
+	e.current = dk
+	e.remember(dk)
+	e.mu.Unlock()
+	return dk, nil
+}
+
+func (e *Envelope) openingKey(ctx context.Context, version, wrapped string) (*dataKey, error) {
+	cacheKey := version + "." + wrapped
+	now := time.Now()
+	e.mu.Lock()
+	if dk, ok := e.cache[cacheKey]; ok && now.Sub(dk.created) < e.opts.CacheTTL {
+		dk.used = now
+		e.mu.Unlock()
+		return dk, nil
+	}
+	e.mu.Unlock()
+
+	wrappedBytes, err := base64.RawURLEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	raw, err := e.wrapper.Unwrap(ctx, version, wrappedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: unwrap data key (version %s): %w", version, err)
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	dk := &dataKey{version: version, wrapped: wrapped, aead: aead, created: now, used: now}
+	e.mu.Lock()
+	e.remember(dk)
+	e.mu.Unlock()
+	return dk, nil
+}
+
+// remember caches dk, evicting the least recently used key when full. Callers hold e.mu.
+func (e *Envelope) remember(dk *dataKey) {
+	key := dk.version + "." + dk.wrapped
+	if _, ok := e.cache[key]; !ok && len(e.cache) >= e.opts.CacheSize {
+		var oldest string
+		var oldestAt time.Time
+		for k, v := range e.cache {
+			if oldest == "" || v.used.Before(oldestAt) {
+				oldest, oldestAt = k, v.used
+			}
+		}
+		delete(e.cache, oldest)
+	}
+	e.cache[key] = dk
+}
+
+func seal(dk *dataKey, plaintext string) (string, error) {
+	nonce := make([]byte, dk.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	body := dk.aead.Seal(nonce, nonce, []byte(plaintext), []byte(dk.version))
+	return sealedPrefix + dk.version + "." + dk.wrapped + "." + base64.RawURLEncoding.EncodeToString(body), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("fieldcrypt: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
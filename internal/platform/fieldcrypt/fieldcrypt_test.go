@@ -0,0 +1,123 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func testKeyring(t *testing.T, current string, versions ...string) *LocalKeyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	for _, v := range versions {
+		// Same version, same key, whichever keyring it appears in.
+		keys[v] = bytes.Repeat([]byte(v), 32)[:32]
+	}
+	k, err := NewLocalKeyring(keys, current)
+	if err != nil {
+		t.Fatalf("NewLocalKeyring: %v", err)
+	}
+	return k
+}
+
+type countingWrapper struct {
+	KeyWrapper
+	unwraps int
+}
+
+func (w *countingWrapper) Unwrap(ctx context.Context, version string, wrapped []byte) ([]byte, error) {
+	w.unwraps++
+	return w.KeyWrapper.Unwrap(ctx, version, wrapped)
+}
+
+func TestDecryptAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	v1 := New(testKeyring(t, "v1", "v1"), Options{})
+	sealed, err := v1.Seal(ctx, "saw my cardiologist about the arrhythmia")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(sealed) || KeyVersion(sealed) != "v1" || strings.Contains(sealed, "cardiologist") {
+		t.Fatalf("sealed value = %q", sealed)
+	}
+
+	// The master key rotates to v2; v1 stays in the keyring until rotation has finished.
+	rotated := New(testKeyring(t, "v2", "v1", "v2"), Options{})
+	if got, err := rotated.Open(ctx, sealed); err != nil || got != "saw my cardiologist about the arrhythmia" {
+		t.Fatalf("Open(v1 value) after rotation = %q, %v", got, err)
+	}
+	resealed, changed, err := rotated.Reseal(ctx, sealed)
+	if err != nil || !changed || KeyVersion(resealed) != "v2" {
+		t.Fatalf("Reseal = %q changed=%v err=%v", resealed, changed, err)
+	}
+	if again, changed, _ := rotated.Reseal(ctx, resealed); changed || again != resealed {
+		t.Fatalf("Reseal of a current-version value changed it")
+	}
+
+	// Once v1 is retired, only the resealed value still opens.
+	retired := New(testKeyring(t, "v2", "v2"), Options{})
+	if got, err := retired.Open(ctx, resealed); err != nil || got != "saw my cardiologist about the arrhythmia" {
+		t.Fatalf("Open(resealed) = %q, %v", got, err)
+	}
+	if _, err := retired.Open(ctx, sealed); err == nil {
+		t.Fatalf("v1 value opened without the v1 key")
+	}
+}
+
+func TestRefreshPicksUpRotatedMasterKey(t *testing.T) {
+	ctx := context.Background()
+	ring := testKeyring(t, "v1", "v1", "v2")
+	env := New(ring, Options{})
+	old, _ := env.Seal(ctx, "note")
+
+	ring.current = "v2"
+	// Until refreshed the cached data key keeps sealing under v1.
+	if s, _ := env.Seal(ctx, "note"); KeyVersion(s) != "v1" {
+		t.Fatalf("sealed under %q before refresh", KeyVersion(s))
+	}
+	if v, err := env.Refresh(ctx); err != nil || v != "v2" {
+		t.Fatalf("Refresh = %q, %v", v, err)
+	}
+	if s, _, _ := env.Reseal(ctx, old); KeyVersion(s) != "v2" {
+		t.Fatalf("Reseal after refresh used %q", KeyVersion(s))
+	}
+}
+
+func TestOpenCachesUnwrappedDataKeys(t *testing.T) {
+	ctx := context.Background()
+	ring := testKeyring(t, "v1", "v1")
+	writer := New(ring, Options{})
+	a, _ := writer.Seal(ctx, "a")
+	b, _ := writer.Seal(ctx, "b")
+
+	w := &countingWrapper{KeyWrapper: ring}
+	reader := New(w, Options{CacheSize: 1})
+	for i := 0; i < 3; i++ {
+		if got, err := reader.Open(ctx, a); err != nil || got != "a" {
+			t.Fatalf("Open = %q, %v", got, err)
+		}
+		if got, _ := reader.Open(ctx, b); got != "b" {
+			t.Fatalf("Open = %q", got)
+		}
+	}
+	// Both values share one data key, so it is unwrapped once.
+	if w.unwraps != 1 {
+		t.Fatalf("unwraps = %d, want 1", w.unwraps)
+	}
+}
+
+func TestDisabledEnvelope(t *testing.T) {
+	ctx := context.Background()
+	var env *Envelope
+	if got, _ := env.Seal(ctx, "plain"); got != "plain" {
+		t.Fatalf("disabled Seal = %q", got)
+	}
+	if got, err := env.Open(ctx, "plain"); err != nil || got != "plain" {
+		t.Fatalf("disabled Open(plain) = %q, %v", got, err)
+	}
+	sealed, _ := New(testKeyring(t, "v1", "v1"), Options{}).Seal(ctx, "x")
+	if _, err := env.Open(ctx, sealed); err != ErrNoKeys {
+		t.Fatalf("disabled Open(sealed) err = %v", err)
+	}
+}
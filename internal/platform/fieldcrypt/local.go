@@ -0,0 +1,122 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+// LocalKeyring is the dev fallback KeyWrapper: master keys are AES-256 keys held in process,
+// keyed by version. Keep every version that still wraps stored values until rotation has
+// re-sealed them.
+type LocalKeyring struct {
+	keys    map[string][]byte
+	current string
+}
+
+func NewLocalKeyring(keys map[string][]byte, current string) (*LocalKeyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	for version, key := range keys {
+		if !validVersion(version) {
+			return nil, fmt.Errorf("fieldcrypt: invalid key version %q", version)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be 32 bytes (got %d)", version, len(key))
+		}
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: current key version %q not in keyring", current)
+	}
+	return &LocalKeyring{keys: keys, current: current}, nil
+}
+
+// LocalKeyringFromEnv reads FIELD_CRYPT_LOCAL_KEYS ("v1=<base64>,v2=<base64>"); the current
+// version is FIELD_CRYPT_LOCAL_KEY_VERSION or, when unset, the last one listed. It returns
+// nil when no keys are configured.
+func LocalKeyringFromEnv() (*LocalKeyring, error) {
+	raw := strings.TrimSpace(os.Getenv("FIELD_CRYPT_LOCAL_KEYS"))
+	if raw == "" {
+		return nil, nil
+	}
+	keys := map[string][]byte{}
+	last := ""
+	for _, part := range strings.Split(raw, ",") {
+		version, encoded, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("fieldcrypt: FIELD_CRYPT_LOCAL_KEYS entry %q is not version=key", part)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q: %w", version, err)
+		}
+		version = strings.TrimSpace(version)
+		keys[version] = key
+		last = version
+	}
+	current := strings.TrimSpace(os.Getenv("FIELD_CRYPT_LOCAL_KEY_VERSION"))
+	if current == "" {
+		current = last
+	}
+	return NewLocalKeyring(keys, current)
+}
+
+func (k *LocalKeyring) CurrentVersion(ctx context.Context) (string, error) {
+	return k.current, nil
+}
+
+func (k *LocalKeyring) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	aead, err := newAEAD(k.keys[k.current])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return k.current, aead.Seal(nonce, nonce, dataKey, []byte(k.current)), nil
+}
+
+func (k *LocalKeyring) Unwrap(ctx context.Context, version string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown key version %q", version)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(version))
+}
+
+// OptionsFromEnv reads the data key lifetimes and cache bound.
+func OptionsFromEnv() Options {
+	return Options{
+		DataKeyTTL: time.Duration(envutil.Int("FIELD_CRYPT_DATA_KEY_TTL_SECONDS", 3600)) * time.Second,
+		CacheSize:  envutil.Int("FIELD_CRYPT_DATA_KEY_CACHE_MAX", 256),
+		CacheTTL:   time.Duration(envutil.Int("FIELD_CRYPT_DATA_KEY_CACHE_TTL_SECONDS", 3600)) * time.Second,
+	}
+}
+
+// validVersion keeps versions safe to embed in the sealed format.
+func validVersion(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -41,6 +41,9 @@ type BucketService interface {
 	GetObjectAttrs(ctx context.Context, category BucketCategory, key string) (*ObjectAttrs, error)
 	CopyObject(ctx context.Context, category BucketCategory, srcKey, dstKey string) error
 	ListKeys(ctx context.Context, category BucketCategory, prefix string) ([]string, error)
+	// ListObjects returns up to limit objects under prefix in key order, starting after cursor
+	// (the previous page's NextCursor, or "" for the first page).
+	ListObjects(ctx context.Context, category BucketCategory, prefix, cursor string, limit int) (ObjectPage, error)
 	DeletePrefix(ctx context.Context, category BucketCategory, prefix string) error
 	GetPublicURL(category BucketCategory, key string) string
 }
@@ -50,6 +53,21 @@ type ObjectAttrs struct {
 	ContentType string
 	Updated     time.Time
 	ETag        string
+	Metadata    map[string]string
+}
+
+type ObjectInfo struct {
+	Key      string
+	Size     int64
+	Created  time.Time
+	Updated  time.Time
+	Metadata map[string]string
+}
+
+// ObjectPage is one page of a listing. NextCursor is "" once the prefix is exhausted.
+type ObjectPage struct {
+	Objects    []ObjectInfo
+	NextCursor string
 }
 
 type bucketService struct {
@@ -191,6 +209,7 @@ func (bs *bucketService) UploadFile(dbc dbctx.Context, category BucketCategory,
 	if ct := contentTypeForKey(key); ct != "" {
 		w.ContentType = ct
 	}
+	w.Metadata = ObjectTagsFrom(dbc.Ctx).Metadata()
 	if _, err := io.Copy(w, file); err != nil {
 		_ = w.Close()
 		return fmt.Errorf("failed to write data to GCS: %w", err)
@@ -298,6 +317,42 @@ func (bs *bucketService) ListKeys(ctx context.Context, category BucketCategory,
 	return out, nil
 }
 
+func (bs *bucketService) ListObjects(ctx context.Context, category BucketCategory, prefix, cursor string, limit int) (ObjectPage, error) {
+	var out ObjectPage
+	cfg, err := bs.getBucketConfig(category)
+	if err != nil {
+		return out, err
+	}
+	if limit <= 0 {
+		limit = 1000
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	// StartOffset is inclusive, so the cursor (the last key returned) is skipped below.
+	it := bs.storageClient.Bucket(cfg.name).Objects(ctx, &storage.Query{Prefix: prefix, StartOffset: cursor})
+	for len(out.Objects) < limit {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return ObjectPage{}, err
+		}
+		if cursor != "" && attrs.Name <= cursor {
+			continue
+		}
+		out.Objects = append(out.Objects, ObjectInfo{
+			Key:      attrs.Name,
+			Size:     attrs.Size,
+			Created:  attrs.Created,
+			Updated:  attrs.Updated,
+			Metadata: attrs.Metadata,
+		})
+	}
+	out.NextCursor = out.Objects[len(out.Objects)-1].Key
+	return out, nil
+}
+
 func (bs *bucketService) DeletePrefix(ctx context.Context, category BucketCategory, prefix string) error {
 	keys, err := bs.ListKeys(ctx, category, prefix)
 	if err != nil {
@@ -491,10 +546,11 @@ func (bs *bucketService) GetObjectAttrs(ctx context.Context, category BucketCate
 		}
 
 		var payload struct {
-			Size        string `json:"size"`
-			ContentType string `json:"contentType"`
-			Updated     string `json:"updated"`
-			ETag        string `json:"etag"`
+			Size        string            `json:"size"`
+			ContentType string            `json:"contentType"`
+			Updated     string            `json:"updated"`
+			ETag        string            `json:"etag"`
+			Metadata    map[string]string `json:"metadata"`
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
 			return nil, fmt.Errorf("decode emulator attrs: %w", err)
@@ -511,6 +567,7 @@ func (bs *bucketService) GetObjectAttrs(ctx context.Context, category BucketCate
 			ContentType: payload.ContentType,
 			Updated:     updated,
 			ETag:        payload.ETag,
+			Metadata:    payload.Metadata,
 		}, nil
 	}
 	ctx2, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -524,5 +581,6 @@ func (bs *bucketService) GetObjectAttrs(ctx context.Context, category BucketCate
 		ContentType: attrs.ContentType,
 		Updated:     attrs.Updated,
 		ETag:        attrs.Etag,
+		Metadata:    attrs.Metadata,
 	}, nil
 }
@@ -0,0 +1,76 @@
+package gcp
+
+import (
+	"context"
+	"strings"
+)
+
+// Object metadata keys written by UploadFile from the context's ObjectTags.
+const (
+	ObjectMetaUserID      = "nb-user-id"
+	ObjectMetaPathID      = "nb-path-id"
+	ObjectMetaNodeID      = "nb-node-id"
+	ObjectMetaProducerJob = "nb-producer-job"
+)
+
+// ObjectTags identifies who an uploaded object belongs to and which job produced it, so
+// objects can be inventoried (and orphans attributed) without a database lookup.
+type ObjectTags struct {
+	UserID      string
+	PathID      string
+	NodeID      string
+	ProducerJob string
+}
+
+type objectTagsKey struct{}
+
+// WithObjectTags returns a context whose uploads carry tags. Non-empty fields override tags
+// already on ctx, so the job runtime can set user/job and steps add path/node.
+func WithObjectTags(ctx context.Context, tags ObjectTags) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	merged := ObjectTagsFrom(ctx)
+	if v := strings.TrimSpace(tags.UserID); v != "" {
+		merged.UserID = v
+	}
+	if v := strings.TrimSpace(tags.PathID); v != "" {
+		merged.PathID = v
+	}
+	if v := strings.TrimSpace(tags.NodeID); v != "" {
+		merged.NodeID = v
+	}
+	if v := strings.TrimSpace(tags.ProducerJob); v != "" {
+		merged.ProducerJob = v
+	}
+	return context.WithValue(ctx, objectTagsKey{}, merged)
+}
+
+func ObjectTagsFrom(ctx context.Context) ObjectTags {
+	if ctx == nil {
+		return ObjectTags{}
+	}
+	if tags, ok := ctx.Value(objectTagsKey{}).(ObjectTags); ok {
+		return tags
+	}
+	return ObjectTags{}
+}
+
+// Metadata returns the tags as object metadata, or nil when no tag is set.
+func (t ObjectTags) Metadata() map[string]string {
+	out := map[string]string{}
+	for k, v := range map[string]string{
+		ObjectMetaUserID:      t.UserID,
+		ObjectMetaPathID:      t.PathID,
+		ObjectMetaNodeID:      t.NodeID,
+		ObjectMetaProducerJob: t.ProducerJob,
+	} {
+		if v != "" {
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
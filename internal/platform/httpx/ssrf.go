@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IsPublicHTTPSURL reports whether raw is an https URL whose host resolves only to public
+// addresses. It guards server-side fetches of user- or model-supplied URLs (SSRF hardening):
+// localhost, .local names, private ranges and hosts that fail to resolve are all refused.
+func IsPublicHTTPSURL(ctx context.Context, raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u == nil {
+		return false
+	}
+	if strings.ToLower(u.Scheme) != "https" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSpace(u.Hostname()))
+	if host == "" {
+		return false
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".local") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return !IsPrivateIP(ip)
+	}
+
+	// Best-effort: resolve and block private IPs.
+	resCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	ips, err := net.DefaultResolver.LookupIP(resCtx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		// If we can't resolve, treat as blocked (safer default).
+		return false
+	}
+	for _, ip := range ips {
+		if IsPrivateIP(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPrivateIP reports whether ip is loopback, link-local or in a private IPv4 range. IPv6
+// addresses are conservatively treated as private.
+func IsPrivateIP(ip net.IP) bool {
+	if ip == nil {
+		return true
+	}
+	ip = ip.To4()
+	if ip == nil {
+		// IPv6: conservatively treat as private unless explicitly global unicast.
+		return true
+	}
+	if ip.IsLoopback() || ip.IsLinkLocalMulticast() || ip.IsLinkLocalUnicast() {
+		return true
+	}
+	// 10.0.0.0/8
+	if ip[0] == 10 {
+		return true
+	}
+	// 172.16.0.0/12
+	if ip[0] == 172 && ip[1] >= 16 && ip[1] <= 31 {
+		return true
+	}
+	// 192.168.0.0/16
+	if ip[0] == 192 && ip[1] == 168 {
+		return true
+	}
+	// 127.0.0.0/8
+	if ip[0] == 127 {
+		return true
+	}
+	// 169.254.0.0/16 (link local)
+	if ip[0] == 169 && ip[1] == 254 {
+		return true
+	}
+	return false
+}
+
+// NewPublicHTTPSClient returns a client that re-checks every redirect with IsPublicHTTPSURL
+// and gives up after maxRedirects hops. The caller still checks the initial URL.
+func NewPublicHTTPSClient(timeout time.Duration, maxRedirects int) *http.Client {
+	c := &http.Client{Timeout: timeout}
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("too many redirects")
+		}
+		if req == nil || req.URL == nil {
+			return fmt.Errorf("redirect missing url")
+		}
+		if !IsPublicHTTPSURL(req.Context(), req.URL.String()) {
+			return fmt.Errorf("redirect blocked: %s", req.URL.String())
+		}
+		return nil
+	}
+	return c
+}
@@ -7,12 +7,16 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
 type Logger struct {
 	SugaredLogger *zap.SugaredLogger
+
+	// throttle dedups WarnThrottled lines; nil uses DefaultThrottle.
+	throttle *Throttle
 }
 
 func New(mode string) (*Logger, error) {
@@ -54,7 +58,36 @@ func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
 }
 func (l *Logger) With(keysAndValues ...interface{}) *Logger {
 	newSugared := l.SugaredLogger.With(sanitizeKVs(keysAndValues)...)
-	return &Logger{SugaredLogger: newSugared}
+	return &Logger{SugaredLogger: newSugared, throttle: l.throttle}
+}
+
+// WithThrottle returns a copy of the logger that dedups WarnThrottled lines through t.
+func (l *Logger) WithThrottle(t *Throttle) *Logger {
+	return &Logger{SugaredLogger: l.SugaredLogger, throttle: t}
+}
+
+// WarnThrottled logs like Warn for recurring conditions on hot paths: the first occurrence of
+// key is logged, repeats within window are counted, and one summary line is logged when the
+// window closes.
+func (l *Logger) WarnThrottled(key string, window time.Duration, msg string, keysAndValues ...interface{}) {
+	t := l.throttle
+	if t == nil {
+		t = defaultThrottle
+	}
+	if t.allow(key, msg, window, l.Warn) {
+		l.Warn(msg, keysAndValues...)
+	}
+}
+
+// ErrorThrottled is WarnThrottled at error level, for recurring conditions that need a human.
+func (l *Logger) ErrorThrottled(key string, window time.Duration, msg string, keysAndValues ...interface{}) {
+	t := l.throttle
+	if t == nil {
+		t = defaultThrottle
+	}
+	if t.allow(key, msg, window, l.Error) {
+		l.Error(msg, keysAndValues...)
+	}
 }
 
 var (
@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultThrottleMaxKeys bounds how many dedup keys a Throttle tracks before evicting the least
+// recently seen one.
+const DefaultThrottleMaxKeys = 1024
+
+// Clock is the time source a Throttle uses; tests swap in a fake to drive windows.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// Throttle deduplicates recurring log lines by key. The first occurrence in a window is logged;
+// repeats inside the window are only counted, and one summary line is emitted when it closes.
+type Throttle struct {
+	mu      sync.Mutex
+	clock   Clock
+	maxKeys int
+	lru     *list.List // front = most recently seen
+	byKey   map[string]*list.Element
+	evicted uint64
+}
+
+type throttleEntry struct {
+	key       string
+	msg       string
+	window    time.Duration
+	windowEnd time.Time
+	lastSeen  time.Time
+	// pending counts repeats in the current window that have not been summarized yet.
+	pending    int
+	emitted    uint64
+	suppressed uint64
+	timer      Timer
+	// warn is the logger of the latest occurrence, so the summary keeps its fields.
+	warn func(msg string, keysAndValues ...interface{})
+}
+
+type throttleSummary struct {
+	warn   func(msg string, keysAndValues ...interface{})
+	key    string
+	msg    string
+	window time.Duration
+	count  int
+}
+
+func (s throttleSummary) emit() {
+	if s.warn == nil || s.count <= 0 {
+		return
+	}
+	s.warn(
+		fmt.Sprintf("suppressed %d occurrences of %s in last %s", s.count, s.key, s.window),
+		"throttle_key", s.key,
+		"suppressed", s.count,
+		"message", s.msg,
+	)
+}
+
+// NewThrottle returns a Throttle tracking at most maxKeys keys (DefaultThrottleMaxKeys when
+// <= 0). A nil clock uses the wall clock.
+func NewThrottle(clock Clock, maxKeys int) *Throttle {
+	if clock == nil {
+		clock = realClock{}
+	}
+	if maxKeys <= 0 {
+		maxKeys = DefaultThrottleMaxKeys
+	}
+	return &Throttle{
+		clock:   clock,
+		maxKeys: maxKeys,
+		lru:     list.New(),
+		byKey:   map[string]*list.Element{},
+	}
+}
+
+var defaultThrottle = NewThrottle(nil, DefaultThrottleMaxKeys)
+
+// DefaultThrottle is the process-wide throttle used by loggers without their own.
+func DefaultThrottle() *Throttle { return defaultThrottle }
+
+// allow reports whether this occurrence of key should be logged. Summaries owed by closed
+// windows or evicted keys are emitted outside the lock before returning.
+func (t *Throttle) allow(key, msg string, window time.Duration, warn func(string, ...interface{})) bool {
+	if window <= 0 {
+		return true
+	}
+	var owed []throttleSummary
+	defer func() {
+		for _, s := range owed {
+			s.emit()
+		}
+	}()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+
+	if el, ok := t.byKey[key]; ok {
+		e := el.Value.(*throttleEntry)
+		t.lru.MoveToFront(el)
+		e.lastSeen = now
+		e.warn = warn
+		if now.Before(e.windowEnd) {
+			e.pending++
+			e.suppressed++
+			if e.timer == nil {
+				e.timer = t.clock.AfterFunc(e.windowEnd.Sub(now), func() { t.flush(e) })
+			}
+			return false
+		}
+		// The window closed but its timer has not run yet: summarize before starting a new one.
+		if s, ok := e.takeSummary(); ok {
+			owed = append(owed, s)
+		}
+		e.msg = msg
+		e.window = window
+		e.windowEnd = now.Add(window)
+		e.emitted++
+		return true
+	}
+
+	e := &throttleEntry{
+		key:       key,
+		msg:       msg,
+		window:    window,
+		windowEnd: now.Add(window),
+		lastSeen:  now,
+		emitted:   1,
+		warn:      warn,
+	}
+	t.byKey[key] = t.lru.PushFront(e)
+	for t.lru.Len() > t.maxKeys {
+		oldest := t.lru.Back()
+		old := oldest.Value.(*throttleEntry)
+		t.lru.Remove(oldest)
+		delete(t.byKey, old.key)
+		t.evicted++
+		if s, ok := old.takeSummary(); ok {
+			owed = append(owed, s)
+		}
+	}
+	return true
+}
+
+// takeSummary stops the entry's timer and returns its pending summary, if any. Callers hold t.mu.
+func (e *throttleEntry) takeSummary() (throttleSummary, bool) {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	if e.pending == 0 {
+		return throttleSummary{}, false
+	}
+	s := throttleSummary{warn: e.warn, key: e.key, msg: e.msg, window: e.window, count: e.pending}
+	e.pending = 0
+	return s, true
+}
+
+// flush runs when an entry's window closes and emits the summary of what it suppressed.
+func (t *Throttle) flush(e *throttleEntry) {
+	t.mu.Lock()
+	var s throttleSummary
+	ok := false
+	if el, tracked := t.byKey[e.key]; tracked && el.Value == e {
+		e.timer = nil
+		s, ok = e.takeSummary()
+	}
+	t.mu.Unlock()
+	if ok {
+		s.emit()
+	}
+}
+
+// ThrottleStat is one key's counters.
+type ThrottleStat struct {
+	Key        string    `json:"key"`
+	Message    string    `json:"message"`
+	Emitted    uint64    `json:"emitted"`
+	Suppressed uint64    `json:"suppressed"`
+	Pending    int       `json:"pending"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+type ThrottleSnapshot struct {
+	Keys    []ThrottleStat `json:"keys"`
+	Evicted uint64         `json:"evicted"`
+}
+
+// Snapshot returns the tracked keys, most suppressed first.
+func (t *Throttle) Snapshot() ThrottleSnapshot {
+	t.mu.Lock()
+	out := ThrottleSnapshot{Keys: make([]ThrottleStat, 0, t.lru.Len()), Evicted: t.evicted}
+	for el := t.lru.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*throttleEntry)
+		out.Keys = append(out.Keys, ThrottleStat{
+			Key:        e.key,
+			Message:    e.msg,
+			Emitted:    e.emitted,
+			Suppressed: e.suppressed,
+			Pending:    e.pending,
+			LastSeen:   e.lastSeen,
+		})
+	}
+	t.mu.Unlock()
+	sort.SliceStable(out.Keys, func(i, j int) bool { return out.Keys[i].Suppressed > out.Keys[j].Suppressed })
+	return out
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	was := !t.stopped
+	t.stopped = true
+	return was
+}
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock and runs due timers outside the clock lock, as time.AfterFunc would.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	due := []*fakeTimer{}
+	kept := c.timers[:0]
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if !t.at.After(c.now) {
+			t.stopped = true
+			due = append(due, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	c.timers = kept
+	c.mu.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func newObservedLogger(th *Throttle) (*Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	return (&Logger{SugaredLogger: zap.New(core).Sugar()}).WithThrottle(th), logs
+}
+
+func TestWarnThrottledSuppressesAndSummarizes(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	log, logs := newObservedLogger(NewThrottle(clock, 8))
+
+	for i := 0; i < 5; i++ {
+		log.WarnThrottled("pinecone.upsert", time.Minute, "pinecone upsert failed (continuing)", "attempt", i)
+		clock.Advance(time.Second)
+	}
+	if got := logs.Len(); got != 1 {
+		t.Fatalf("expected only the first occurrence logged, got %d lines", got)
+	}
+	if first := logs.All()[0]; first.Message != "pinecone upsert failed (continuing)" || first.ContextMap()["attempt"] != int64(0) {
+		t.Fatalf("unexpected first line: %+v", first)
+	}
+
+	clock.Advance(time.Minute)
+	all := logs.All()
+	if len(all) != 2 {
+		t.Fatalf("expected a summary line when the window closed, got %d lines", len(all))
+	}
+	summary := all[1]
+	if summary.Message != "suppressed 4 occurrences of pinecone.upsert in last 1m0s" {
+		t.Fatalf("unexpected summary: %q", summary.Message)
+	}
+	if summary.ContextMap()["suppressed"] != int64(4) {
+		t.Fatalf("unexpected summary fields: %v", summary.ContextMap())
+	}
+
+	// The next occurrence opens a fresh window and is logged immediately.
+	log.WarnThrottled("pinecone.upsert", time.Minute, "pinecone upsert failed (continuing)")
+	if logs.Len() != 3 {
+		t.Fatalf("expected new window to log immediately, got %d lines", logs.Len())
+	}
+	clock.Advance(2 * time.Minute)
+	if logs.Len() != 3 {
+		t.Fatalf("expected no summary for a window without repeats, got %d lines", logs.Len())
+	}
+
+	snap := log.throttle.Snapshot()
+	if len(snap.Keys) != 1 || snap.Keys[0].Emitted != 2 || snap.Keys[0].Suppressed != 4 || snap.Keys[0].Pending != 0 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestWarnThrottledEvictsLeastRecentKey(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	log, logs := newObservedLogger(NewThrottle(clock, 2))
+
+	log.WarnThrottled("a", time.Minute, "a failed")
+	log.WarnThrottled("a", time.Minute, "a failed") // suppressed, summary pending
+	log.WarnThrottled("b", time.Minute, "b failed")
+	log.WarnThrottled("c", time.Minute, "c failed") // evicts "a"
+
+	var lines []string
+	for _, e := range logs.All() {
+		lines = append(lines, e.Message)
+	}
+	want := []string{"a failed", "b failed", "suppressed 1 occurrences of a in last 1m0s", "c failed"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected lines:\n got %q\nwant %q", lines, want)
+	}
+
+	snap := log.throttle.Snapshot()
+	if snap.Evicted != 1 || len(snap.Keys) != 2 {
+		t.Fatalf("unexpected snapshot after eviction: %+v", snap)
+	}
+	for _, k := range snap.Keys {
+		if k.Key == "a" {
+			t.Fatalf("evicted key still tracked: %+v", snap.Keys)
+		}
+	}
+
+	// The evicted key's timer must not emit a second summary.
+	clock.Advance(2 * time.Minute)
+	if logs.Len() != len(want) {
+		t.Fatalf("expected no late summary for evicted key, got %d lines", logs.Len())
+	}
+
+	// An evicted key starts over and logs immediately.
+	log.WarnThrottled("a", time.Minute, "a failed")
+	if logs.Len() != len(want)+1 {
+		t.Fatalf("expected evicted key to log again, got %d lines", logs.Len())
+	}
+}
+
+func TestWarnThrottledConcurrent(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	log, logs := newObservedLogger(NewThrottle(clock, 4))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				log.WarnThrottled("hot", time.Minute, "hot path failed")
+			}
+		}()
+	}
+	wg.Wait()
+	clock.Advance(time.Minute)
+
+	all := logs.All()
+	if len(all) != 2 || all[1].Message != "suppressed 799 occurrences of hot in last 1m0s" {
+		t.Fatalf("expected one line and one summary, got %d lines (%v)", len(all), all)
+	}
+}
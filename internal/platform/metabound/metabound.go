@@ -0,0 +1,236 @@
+// Package metabound sanitizes free-form metadata maps before they are persisted as JSON columns.
+// A Builder keeps only allowlisted top-level keys, truncates long strings, flattens maps nested
+// deeper than MaxDepth and enforces a total serialized budget. Keys dropped for any reason are
+// counted under TruncatedKey so a bounded row is distinguishable from a small one.
+package metabound
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+)
+
+const (
+	DefaultMaxBytes  = 8 << 10
+	DefaultMaxString = 512
+	DefaultMaxDepth  = 2
+	DefaultMaxItems  = 64
+
+	// TruncatedKey holds the number of keys dropped from a map (disallowed, over budget or over
+	// the item cap). It is only present when something was dropped.
+	TruncatedKey = "_truncated"
+	// TruncationMarker is appended to strings cut at MaxString runes.
+	TruncationMarker = "…[truncated]"
+	// DepthMarker replaces maps nested deeper than MaxDepth.
+	DepthMarker = "[depth_limit]"
+	// UnserializableMarker replaces values that cannot be encoded as JSON.
+	UnserializableMarker = "[unserializable]"
+
+	// truncatedReserve is the budget kept back for the TruncatedKey entry itself.
+	truncatedReserve = len(`,"_truncated":`) + 10
+)
+
+// Builder bounds metadata maps. The zero value is not useful; use New.
+type Builder struct {
+	allowed map[string]bool
+
+	MaxString int
+	MaxDepth  int
+	MaxBytes  int
+	MaxItems  int
+}
+
+// New returns a Builder that keeps only the given top-level keys. With no keys every key is
+// allowed and only the size limits apply. METADATA_MAX_BYTES overrides the total budget.
+func New(allowed ...string) *Builder {
+	b := &Builder{
+		MaxString: DefaultMaxString,
+		MaxDepth:  DefaultMaxDepth,
+		MaxBytes:  envutil.Int("METADATA_MAX_BYTES", DefaultMaxBytes),
+		MaxItems:  DefaultMaxItems,
+	}
+	if b.MaxBytes <= 0 {
+		b.MaxBytes = DefaultMaxBytes
+	}
+	if len(allowed) > 0 {
+		b.allowed = make(map[string]bool, len(allowed))
+		for _, k := range allowed {
+			b.allowed[k] = true
+		}
+	}
+	return b
+}
+
+// Bound applies the default limits with no allowlist.
+func Bound(in map[string]any) map[string]any {
+	return New().Build(in)
+}
+
+// Allows reports whether key survives the allowlist.
+func (b *Builder) Allows(key string) bool {
+	return b.allowed == nil || b.allowed[key]
+}
+
+// Keys returns the allowlist in sorted order, or nil when every key is allowed.
+func (b *Builder) Keys() []string {
+	if b.allowed == nil {
+		return nil
+	}
+	out := make([]string, 0, len(b.allowed))
+	for k := range b.allowed {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Build returns a sanitized copy of in whose JSON encoding fits in MaxBytes. Keys are admitted in
+// sorted order until the budget runs out, so which keys survive is deterministic. A nil input
+// returns nil.
+func (b *Builder) Build(in map[string]any) map[string]any {
+	if in == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(in))
+	dropped := 0
+	for k := range in {
+		if k == TruncatedKey || !b.Allows(k) {
+			dropped++
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]any, len(keys))
+	budget := b.MaxBytes - len("{}") - truncatedReserve
+	for _, k := range keys {
+		v := b.value(in[k], 1)
+		cost, ok := entrySize(k, v)
+		if !ok {
+			v = UnserializableMarker
+			cost, _ = entrySize(k, v)
+		}
+		if cost > budget {
+			dropped++
+			continue
+		}
+		budget -= cost
+		out[k] = v
+	}
+	if dropped > 0 {
+		out[TruncatedKey] = dropped
+	}
+	return out
+}
+
+// JSON builds and marshals in. It returns nil when there is nothing to store.
+func (b *Builder) JSON(in map[string]any) []byte {
+	out := b.Build(in)
+	if out == nil {
+		return nil
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+// value sanitizes v found inside a map at the given nesting level (the root map is level 1).
+func (b *Builder) value(v any, level int) any {
+	switch t := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return t
+	case string:
+		return b.truncate(t)
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return b.truncate(t.String())
+	case map[string]any:
+		if level+1 > b.MaxDepth {
+			return DepthMarker
+		}
+		return b.nested(t, level+1)
+	case []any:
+		return b.slice(t, level)
+	case []string:
+		items := make([]any, len(t))
+		for i, s := range t {
+			items[i] = s
+		}
+		return b.slice(items, level)
+	}
+	// Typed maps, slices and structs: normalise through JSON and sanitize the generic form.
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return UnserializableMarker
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return UnserializableMarker
+	}
+	return b.value(generic, level)
+}
+
+func (b *Builder) nested(m map[string]any, level int) map[string]any {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	dropped := 0
+	if b.MaxItems > 0 && len(keys) > b.MaxItems {
+		dropped = len(keys) - b.MaxItems
+		keys = keys[:b.MaxItems]
+	}
+	out := make(map[string]any, len(keys)+1)
+	for _, k := range keys {
+		out[b.truncate(k)] = b.value(m[k], level)
+	}
+	if dropped > 0 {
+		out[TruncatedKey] = dropped
+	}
+	return out
+}
+
+func (b *Builder) slice(items []any, level int) []any {
+	n := len(items)
+	if b.MaxItems > 0 && n > b.MaxItems {
+		n = b.MaxItems
+	}
+	out := make([]any, 0, n+1)
+	for _, it := range items[:n] {
+		out = append(out, b.value(it, level))
+	}
+	if n < len(items) {
+		out = append(out, fmt.Sprintf("%s %d more", TruncationMarker, len(items)-n))
+	}
+	return out
+}
+
+func (b *Builder) truncate(s string) string {
+	if b.MaxString <= 0 || utf8.RuneCountInString(s) <= b.MaxString {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:b.MaxString]) + TruncationMarker
+}
+
+// entrySize is the encoded size of `"key":value,` for one top-level entry.
+func entrySize(key string, v any) (int, bool) {
+	kb, err := json.Marshal(key)
+	if err != nil {
+		return 0, false
+	}
+	vb, err := json.Marshal(v)
+	if err != nil {
+		return 0, false
+	}
+	return len(kb) + len(vb) + 2, true
+}
@@ -0,0 +1,90 @@
+package metabound
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBuildAllowlistAndStringCap(t *testing.T) {
+	b := New("keep", "long")
+	b.MaxString = 8
+	out := b.Build(map[string]any{
+		"keep":  "ok",
+		"long":  "abcdefghijklmnop",
+		"drop":  "not allowed",
+		"other": 1,
+	})
+	if out["keep"] != "ok" {
+		t.Fatalf("keep: %v", out["keep"])
+	}
+	if got := out["long"]; got != "abcdefgh"+TruncationMarker {
+		t.Fatalf("long: %q", got)
+	}
+	if _, ok := out["drop"]; ok {
+		t.Fatalf("disallowed key kept")
+	}
+	if out[TruncatedKey] != 2 {
+		t.Fatalf("%s: %v", TruncatedKey, out[TruncatedKey])
+	}
+	if b.Build(nil) != nil {
+		t.Fatalf("empty input should build nil")
+	}
+}
+
+func TestBuildDepthLimit(t *testing.T) {
+	out := Bound(map[string]any{
+		"params": map[string]any{
+			"A": map[string]any{"actual": 3},
+			"B": 2,
+		},
+		"list": []any{map[string]any{"x": map[string]any{"y": 1}}},
+	})
+	params, ok := out["params"].(map[string]any)
+	if !ok {
+		t.Fatalf("params: %T", out["params"])
+	}
+	if params["A"] != DepthMarker || params["B"] != 2 {
+		t.Fatalf("params: %v", params)
+	}
+	list := out["list"].([]any)
+	if inner := list[0].(map[string]any); inner["x"] != DepthMarker {
+		t.Fatalf("list: %v", list)
+	}
+
+	// Typed maps and structs go through the same limits.
+	typed := Bound(map[string]any{"t": map[string]map[string]int{"a": {"b": 1}}})
+	if inner := typed["t"].(map[string]any); inner["a"] != DepthMarker {
+		t.Fatalf("typed: %v", typed)
+	}
+}
+
+func TestBuildTotalBudget(t *testing.T) {
+	b := New()
+	b.MaxBytes = 1024
+	in := map[string]any{}
+	for i := 0; i < 40; i++ {
+		in[fmt.Sprintf("k%02d", i)] = strings.Repeat("x", 100)
+	}
+	in["bad"] = math.NaN()
+	raw := b.JSON(in)
+	if len(raw) > b.MaxBytes {
+		t.Fatalf("encoded %d bytes, budget %d", len(raw), b.MaxBytes)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out["bad"] != UnserializableMarker {
+		t.Fatalf("bad: %v", out["bad"])
+	}
+	if _, ok := out["k00"]; !ok {
+		t.Fatalf("keys should be admitted in sorted order")
+	}
+	kept := len(out) - 2 // minus "bad" and the counter
+	if dropped := int(out[TruncatedKey].(float64)); dropped != 40-kept || dropped == 0 {
+		t.Fatalf("dropped=%d kept=%d", dropped, kept)
+	}
+}
@@ -81,6 +81,9 @@ type Client interface {
 
 	// Stream output_text deltas for a conversation-backed response. Returns the full text.
 	StreamTextInConversation(ctx context.Context, conversationID string, instructions string, user string, onDelta func(delta string)) (string, error)
+
+	// Append a user/assistant exchange generated outside the conversation (e.g. statelessly).
+	AppendConversationTurn(ctx context.Context, conversationID string, user string, assistant string) error
 }
 
 // ---- Backwards-compat aliases (so you don't break existing imports immediately) ----
@@ -104,6 +107,22 @@ func WithModel(base Client, model string) Client {
 	return base
 }
 
+// ModelName returns the generation model of a client built by NewClient ("" for other Clients).
+func ModelName(c Client) string {
+	if cc, ok := c.(*client); ok && cc != nil {
+		return cc.model
+	}
+	return ""
+}
+
+// EmbedModelName returns the embedding model of a client built by NewClient ("" for other Clients).
+func EmbedModelName(c Client) string {
+	if cc, ok := c.(*client); ok && cc != nil {
+		return cc.embedModel
+	}
+	return ""
+}
+
 type client struct {
 	log             *logger.Logger
 	baseURL         string
@@ -131,6 +150,9 @@ type client struct {
 	noTempMu   sync.RWMutex
 	noTempSeen map[string]time.Time
 	noTempTTL  time.Duration
+
+	// throttle is the provider backpressure state; nil means ProviderThrottle.
+	throttle *Throttle
 }
 
 func NewClient(log *logger.Logger) (Client, error) {
@@ -246,6 +268,7 @@ func NewClient(log *logger.Logger) (Client, error) {
 		noTempPrefixes:     noTempPrefixes,
 		noTempSeen:         map[string]time.Time{},
 		noTempTTL:          noTempTTL,
+		throttle:           providerThrottle,
 	}, nil
 }
 
@@ -288,6 +311,7 @@ func (c *client) cloneWithModel(model string) *client {
 		noTempPrefixes:     c.noTempPrefixes,
 		noTempSeen:         map[string]time.Time{},
 		noTempTTL:          c.noTempTTL,
+		throttle:           c.throttle,
 	}
 
 	c.noTempMu.RLock()
@@ -413,6 +437,16 @@ func (c *client) applyTemperature(req *responsesRequest) {
 type openAIHTTPError struct {
 	StatusCode int
 	Body       string
+	// RetryAfter is the response's Retry-After, if any.
+	RetryAfter time.Duration
+}
+
+func newOpenAIHTTPError(resp *http.Response, raw []byte) *openAIHTTPError {
+	return &openAIHTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       string(raw),
+		RetryAfter: httpx.RetryAfterDuration(resp, 0, maxThrottleWait),
+	}
 }
 
 func (e *openAIHTTPError) Error() string {
@@ -496,7 +530,7 @@ func (c *client) doOnce(ctx context.Context, httpClient *http.Client, method, pa
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return resp, raw, &openAIHTTPError{StatusCode: resp.StatusCode, Body: string(raw)}
+		return resp, raw, newOpenAIHTTPError(resp, raw)
 	}
 	return resp, raw, nil
 }
@@ -513,6 +547,7 @@ func (c *client) doWithClient(ctx context.Context, httpClient *http.Client, meth
 
 		resp, raw, err := c.doOnce(ctx, httpClient, method, path, body)
 		if err == nil {
+			c.throttleState().Clear()
 			if metrics := observability.Current(); metrics != nil {
 				inputTokens, outputTokens := extractUsageFromRaw(raw)
 				metrics.ObserveLLMRequest(model, path, statusFromResp(resp), time.Since(start), inputTokens, outputTokens)
@@ -536,11 +571,14 @@ func (c *client) doWithClient(ctx context.Context, httpClient *http.Client, meth
 			if metrics := observability.Current(); metrics != nil {
 				metrics.ObserveLLMRequest(model, path, statusFromRespErr(resp, err), time.Since(start), 0, 0)
 			}
-			return err
+			return c.throttled(err, backoff)
 		}
 
 		sleepFor := httpx.RetryAfterDuration(resp, backoff, 10*time.Second)
 		sleepFor = httpx.JitterSleep(sleepFor)
+		if resp != nil && isThrottleStatus(resp.StatusCode) {
+			c.throttleState().Note(sleepFor)
+		}
 
 		c.log.Warn("OpenAI request retrying",
 			"path", path,
@@ -973,7 +1011,7 @@ func (c *client) downloadBytes(ctx context.Context, url string) ([]byte, string,
 		return nil, "", readErr
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, "", &openAIHTTPError{StatusCode: resp.StatusCode, Body: string(raw)}
+		return nil, "", newOpenAIHTTPError(resp, raw)
 	}
 	return raw, strings.TrimSpace(resp.Header.Get("Content-Type")), nil
 }
@@ -1037,7 +1075,7 @@ func (c *client) doMultipart(ctx context.Context, method, path string, payload [
 			return readErr
 		}
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return &openAIHTTPError{StatusCode: resp.StatusCode, Body: string(raw)}
+			return newOpenAIHTTPError(resp, raw)
 		}
 		if out == nil {
 			return nil
@@ -1093,12 +1131,17 @@ type responsesResponse struct {
 		Type    string `json:"type"`
 		Role    string `json:"role,omitempty"`
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text,omitempty"`
+			Type    string `json:"type"`
+			Text    string `json:"text,omitempty"`
+			Refusal string `json:"refusal,omitempty"`
 		} `json:"content,omitempty"`
 	} `json:"output"`
-	Refusal string `json:"refusal,omitempty"`
-	Usage   struct {
+	Refusal           string `json:"refusal,omitempty"`
+	Status            string `json:"status,omitempty"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details,omitempty"`
+	Usage struct {
 		InputTokens      int `json:"input_tokens"`
 		OutputTokens     int `json:"output_tokens"`
 		TotalTokens      int `json:"total_tokens"`
@@ -1121,6 +1164,22 @@ func extractOutputText(resp responsesResponse) string {
 	return out.String()
 }
 
+// extractRefusal returns the refusal text, which the Responses API reports either at the top
+// level or as a "refusal" content part on the assistant message.
+func extractRefusal(resp responsesResponse) string {
+	if strings.TrimSpace(resp.Refusal) != "" {
+		return resp.Refusal
+	}
+	for _, item := range resp.Output {
+		for _, c := range item.Content {
+			if c.Type == "refusal" && strings.TrimSpace(c.Refusal) != "" {
+				return c.Refusal
+			}
+		}
+	}
+	return ""
+}
+
 func (c *client) GenerateJSON(ctx context.Context, system string, user string, schemaName string, schema map[string]any) (map[string]any, error) {
 	if schemaName == "" {
 		return nil, errors.New("schemaName required")
@@ -1153,8 +1212,8 @@ func (c *client) GenerateJSON(ctx context.Context, system string, user string, s
 	if err := c.doResponsesWithTempFallback(ctx, "POST", "/v1/responses", &req, &resp); err != nil {
 		return nil, err
 	}
-	if resp.Refusal != "" {
-		return nil, fmt.Errorf("model refused: %s", resp.Refusal)
+	if refusal := extractRefusal(resp); refusal != "" {
+		return nil, &JSONOutputError{Class: JSONFailureRefusal, SchemaName: schemaName, Err: fmt.Errorf("model refused: %s", refusal)}
 	}
 
 	jsonText := extractOutputText(resp)
@@ -1164,7 +1223,11 @@ func (c *client) GenerateJSON(ctx context.Context, system string, user string, s
 
 	var obj map[string]any
 	if err := json.Unmarshal([]byte(jsonText), &obj); err != nil {
-		return nil, fmt.Errorf("failed to parse model JSON: %w; text=%s", err, jsonText)
+		class := classifyJSONDecodeError(err)
+		if resp.Status == "incomplete" {
+			class = JSONFailureTruncation
+		}
+		return nil, &JSONOutputError{Class: class, SchemaName: schemaName, Err: fmt.Errorf("failed to parse model JSON: %w; text=%s", err, jsonText)}
 	}
 	return obj, nil
 }
@@ -1293,7 +1356,7 @@ func (c *client) StreamText(ctx context.Context, system string, user string, onD
 		}
 		raw, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
-		return nil, raw, &openAIHTTPError{StatusCode: resp.StatusCode, Body: string(raw)}
+		return nil, raw, newOpenAIHTTPError(resp, raw)
 	}
 
 	resp, raw, err := doStream(reqBody)
@@ -1308,8 +1371,9 @@ func (c *client) StreamText(ctx context.Context, system string, user string, onD
 		if metrics := observability.Current(); metrics != nil {
 			metrics.ObserveLLMRequest(reqBody.Model, "/v1/responses", statusFromRespErr(resp, err), time.Since(start), inputTokens, 0)
 		}
-		return "", err
+		return "", c.throttled(err, time.Second)
 	}
+	c.throttleState().Clear()
 	defer resp.Body.Close()
 
 	var full strings.Builder
@@ -1414,6 +1478,27 @@ func (c *client) GenerateTextInConversation(ctx context.Context, conversationID
 	return text, nil
 }
 
+func (c *client) AppendConversationTurn(ctx context.Context, conversationID string, user string, assistant string) error {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return fmt.Errorf("conversation_id required")
+	}
+	item := func(role, kind, text string) map[string]any {
+		return map[string]any{
+			"type":    "message",
+			"role":    role,
+			"content": []map[string]any{{"type": kind, "text": text}},
+		}
+	}
+	body := map[string]any{
+		"items": []map[string]any{
+			item("user", "input_text", user),
+			item("assistant", "output_text", assistant),
+		},
+	}
+	return c.do(ctx, "POST", "/v1/conversations/"+url.PathEscape(conversationID)+"/items", body, nil)
+}
+
 // StreamTextInConversation streams output_text deltas from the Responses API.
 // It is best-effort: any non-empty delta is forwarded to onDelta and accumulated into the returned text.
 func (c *client) StreamTextInConversation(ctx context.Context, conversationID string, instructions string, user string, onDelta func(delta string)) (string, error) {
@@ -1462,7 +1547,7 @@ func (c *client) StreamTextInConversation(ctx context.Context, conversationID st
 		}
 		raw, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
-		return nil, raw, &openAIHTTPError{StatusCode: resp.StatusCode, Body: string(raw)}
+		return nil, raw, newOpenAIHTTPError(resp, raw)
 	}
 
 	resp, raw, err := doStream(reqBody)
@@ -1477,8 +1562,9 @@ func (c *client) StreamTextInConversation(ctx context.Context, conversationID st
 		if metrics := observability.Current(); metrics != nil {
 			metrics.ObserveLLMRequest(reqBody.Model, "/v1/responses", statusFromRespErr(resp, err), time.Since(start), inputTokens, 0)
 		}
-		return "", err
+		return "", c.throttled(err, time.Second)
 	}
+	c.throttleState().Clear()
 	defer resp.Body.Close()
 
 	var full strings.Builder
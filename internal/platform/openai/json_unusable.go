@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// JSONUnusableKind classifies structured outputs that pass schema validation but carry nothing
+// the caller can use. These are not errors at the client layer (the schema cannot express
+// them), so call sites report them after decoding to show which prompts and models need work.
+type JSONUnusableKind string
+
+const (
+	// JSONUnusableEmptyArray: the array the caller needs (concepts, edges, tool calls) is empty.
+	JSONUnusableEmptyArray JSONUnusableKind = "empty_array"
+	// JSONUnusableMissingKey: a key the caller requires is absent or blank (e.g. items without
+	// a key/name, which get dropped).
+	JSONUnusableMissingKey JSONUnusableKind = "missing_key"
+	// JSONUnusableEmptySelection: every option in a selection was declined (e.g. no lanes).
+	JSONUnusableEmptySelection JSONUnusableKind = "empty_selection"
+)
+
+// JSONUnusable is one finding on a decoded output. Count is how many items it affected.
+type JSONUnusable struct {
+	Kind  JSONUnusableKind
+	Field string
+	Count int
+}
+
+// ReportJSONUnusable counts each finding under the schema and the model of c, and logs them
+// in one warning tagged with the trace in ctx and the caller's fields. No-op without findings.
+func ReportJSONUnusable(ctx context.Context, log *logger.Logger, c Client, schemaName string, findings []JSONUnusable, fields map[string]any) {
+	if len(findings) == 0 {
+		return
+	}
+	model := ModelName(c)
+	if metrics := observability.Current(); metrics != nil {
+		for _, f := range findings {
+			metrics.ObserveLLMJSONUnusable(schemaName, string(f.Kind), f.Field, model)
+		}
+	}
+	if log == nil {
+		return
+	}
+	kv := make([]any, 0, 8+(len(fields)+4)*2)
+	kv = append(kv, "schema", schemaName, "model", model, "findings", jsonUnusableSummary(findings))
+	for k, v := range fields {
+		kv = append(kv, k, v)
+	}
+	for k, v := range ctxutil.TraceFields(ctx) {
+		if _, ok := fields[k]; !ok {
+			kv = append(kv, k, v)
+		}
+	}
+	log.Warn("llm json output unusable", kv...)
+}
+
+// jsonUnusableSummary renders findings as "kind:field" -> count for the log line.
+func jsonUnusableSummary(findings []JSONUnusable) map[string]int {
+	out := make(map[string]int, len(findings))
+	for _, f := range findings {
+		k := string(f.Kind)
+		if f.Field != "" {
+			k += ":" + f.Field
+		}
+		n := f.Count
+		if n <= 0 {
+			n = 1
+		}
+		out[k] += n
+	}
+	return out
+}
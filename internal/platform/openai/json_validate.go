@@ -0,0 +1,406 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/yungbote/neurobridge-backend/internal/observability"
+)
+
+// JSONFailureClass classifies why a structured output could not be used, so callers can make
+// uniform retry decisions.
+type JSONFailureClass string
+
+const (
+	// JSONFailureSchemaViolation: the output parsed but has missing keys, wrong types, or values
+	// outside the schema.
+	JSONFailureSchemaViolation JSONFailureClass = "schema_violation"
+	// JSONFailureTruncation: the output stopped mid-object (token limit or a dropped stream).
+	JSONFailureTruncation JSONFailureClass = "truncation"
+	// JSONFailureRefusal: the model declined to answer. Never repaired.
+	JSONFailureRefusal JSONFailureClass = "refusal"
+)
+
+// maxJSONSchemaProblems caps how many violations are collected (and echoed in a repair prompt).
+const maxJSONSchemaProblems = 12
+
+// JSONOutputError is returned when a structured output fails validation.
+type JSONOutputError struct {
+	Class      JSONFailureClass
+	SchemaName string
+	Problems   []string
+	// Repaired is true when the failure survived the single repair pass.
+	Repaired bool
+	Err      error
+}
+
+func (e *JSONOutputError) Error() string {
+	msg := fmt.Sprintf("llm json %s (%s)", e.Class, e.SchemaName)
+	if e.Repaired {
+		msg += " after repair"
+	}
+	if len(e.Problems) > 0 {
+		msg += ": " + strings.Join(e.Problems, "; ")
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *JSONOutputError) Unwrap() error { return e.Err }
+
+// JSONFailureClassOf returns the failure class of err, or "" when err is not a structured
+// output failure (transport errors, timeouts, ...).
+func JSONFailureClassOf(err error) JSONFailureClass {
+	var out *JSONOutputError
+	if errors.As(err, &out) {
+		return out.Class
+	}
+	return ""
+}
+
+// GenerateJSONValidated calls GenerateJSON, validates the object against schema, and decodes it
+// into target (a pointer to a typed struct). Strict mode is not a guarantee across providers and
+// models, so a schema violation or truncated output gets one repair pass: the same prompt with
+// the validation errors appended. Refusals and transport errors are returned as-is. Failures
+// are *JSONOutputError.
+func GenerateJSONValidated(ctx context.Context, c Client, system string, user string, schemaName string, schema map[string]any, target any) error {
+	if c == nil {
+		return errors.New("client required")
+	}
+	if target == nil {
+		return errors.New("target required")
+	}
+
+	err := generateJSONAttempt(ctx, c, system, user, schemaName, schema, target)
+	if err == nil {
+		observeJSONOutput(schemaName, "ok")
+		return nil
+	}
+	var first *JSONOutputError
+	if !errors.As(err, &first) {
+		return err
+	}
+	if first.Class == JSONFailureRefusal || ctx.Err() != nil {
+		observeJSONOutput(schemaName, string(first.Class))
+		return err
+	}
+
+	err = generateJSONAttempt(ctx, c, system, jsonRepairPrompt(user, first), schemaName, schema, target)
+	if err == nil {
+		observeJSONOutput(schemaName, "repaired")
+		return nil
+	}
+	var second *JSONOutputError
+	if errors.As(err, &second) {
+		second.Repaired = true
+		observeJSONOutput(schemaName, string(second.Class))
+	}
+	return err
+}
+
+func generateJSONAttempt(ctx context.Context, c Client, system, user, schemaName string, schema map[string]any, target any) error {
+	obj, err := c.GenerateJSON(ctx, system, user, schemaName, schema)
+	if err != nil {
+		return classifyGenerateJSONError(schemaName, err)
+	}
+
+	// Round-trip through JSON so validation sees canonical types regardless of the client.
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return &JSONOutputError{Class: JSONFailureSchemaViolation, SchemaName: schemaName, Err: err}
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return &JSONOutputError{Class: JSONFailureSchemaViolation, SchemaName: schemaName, Err: err}
+	}
+	if problems := validateJSONSchema(schema, doc); len(problems) > 0 {
+		return &JSONOutputError{Class: JSONFailureSchemaViolation, SchemaName: schemaName, Problems: problems}
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return &JSONOutputError{Class: JSONFailureSchemaViolation, SchemaName: schemaName, Err: err}
+	}
+	return nil
+}
+
+// classifyGenerateJSONError maps client errors onto the failure taxonomy. The OpenAI client
+// already returns *JSONOutputError; other clients surface json decode errors.
+func classifyGenerateJSONError(schemaName string, err error) error {
+	var out *JSONOutputError
+	if errors.As(err, &out) {
+		return err
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return &JSONOutputError{Class: classifyJSONDecodeError(err), SchemaName: schemaName, Err: err}
+	}
+	return err
+}
+
+// classifyJSONDecodeError treats output that ends mid-value as truncation; anything else that
+// fails to decode is a schema violation.
+func classifyJSONDecodeError(err error) JSONFailureClass {
+	if errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), "unexpected end of JSON input") {
+		return JSONFailureTruncation
+	}
+	return JSONFailureSchemaViolation
+}
+
+func jsonRepairPrompt(user string, fail *JSONOutputError) string {
+	var b strings.Builder
+	b.WriteString(user)
+	b.WriteString("\n\nPREVIOUS_RESPONSE_REJECTED (")
+	b.WriteString(string(fail.Class))
+	b.WriteString("):\n")
+	switch {
+	case len(fail.Problems) > 0:
+		for _, p := range fail.Problems {
+			b.WriteString("- ")
+			b.WriteString(p)
+			b.WriteString("\n")
+		}
+	case fail.Class == JSONFailureTruncation:
+		b.WriteString("- the response was cut off before the JSON object was complete\n")
+	default:
+		b.WriteString("- the response was not valid JSON for the schema\n")
+	}
+	b.WriteString("Return a complete JSON object that satisfies the schema exactly.")
+	if fail.Class == JSONFailureTruncation {
+		b.WriteString(" Keep string fields concise so the whole object fits.")
+	}
+	return b.String()
+}
+
+func observeJSONOutput(schemaName, outcome string) {
+	if metrics := observability.Current(); metrics != nil {
+		metrics.ObserveLLMJSONOutput(schemaName, outcome)
+	}
+}
+
+// ---- Schema validation ----
+//
+// Covers the subset of JSON Schema used by our structured-output schemas: type (string or
+// list), properties, required, additionalProperties, items, enum, const, anyOf/oneOf,
+// minimum/maximum, minItems/maxItems.
+
+func validateJSONSchema(schema map[string]any, v any) []string {
+	if schema == nil {
+		return nil
+	}
+	var problems []string
+	validateJSONValue(schema, v, "$", &problems)
+	return problems
+}
+
+func validateJSONValue(schema map[string]any, v any, path string, problems *[]string) {
+	if len(*problems) >= maxJSONSchemaProblems {
+		return
+	}
+	add := func(format string, args ...any) {
+		if len(*problems) < maxJSONSchemaProblems {
+			*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	for _, key := range []string{"anyOf", "oneOf"} {
+		branches := schemaList(schema[key])
+		if len(branches) == 0 {
+			continue
+		}
+		matched := false
+		for _, b := range branches {
+			bs, ok := b.(map[string]any)
+			if !ok {
+				continue
+			}
+			var sub []string
+			validateJSONValue(bs, v, path, &sub)
+			if len(sub) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("does not match any allowed shape")
+			return
+		}
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		got := jsonTypeOf(v)
+		ok := false
+		for _, t := range types {
+			if t == got || (t == "number" && got == "integer") {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			add("expected %s, got %s", strings.Join(types, " or "), got)
+			return
+		}
+	}
+
+	if enum := schemaList(schema["enum"]); len(enum) > 0 {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("value %s not in enum", jsonString(v))
+		}
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, v) {
+		add("expected const %s, got %s", jsonString(c), jsonString(v))
+	}
+
+	switch val := v.(type) {
+	case float64:
+		if min, ok := schemaNumber(schema["minimum"]); ok && val < min {
+			add("%v is below minimum %v", val, min)
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && val > max {
+			add("%v is above maximum %v", val, max)
+		}
+	case []any:
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(val)) < min {
+			add("has %d items, minimum %v", len(val), min)
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(val)) > max {
+			add("has %d items, maximum %v", len(val), max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				validateJSONValue(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, key := range schemaStrings(schema["required"]) {
+			if _, ok := val[key]; !ok {
+				add("missing required key %q", key)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if ps, ok := props[k].(map[string]any); ok {
+				validateJSONValue(ps, val[k], child, problems)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra && len(*problems) < maxJSONSchemaProblems {
+					*problems = append(*problems, child+": unexpected key")
+				}
+			case map[string]any:
+				validateJSONValue(extra, val[k], child, problems)
+			}
+		}
+	}
+}
+
+func jsonTypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) && !math.IsInf(val, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func schemaTypes(v any) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	default:
+		return schemaStrings(v)
+	}
+}
+
+func schemaStrings(v any) []string {
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaList(v any) []any {
+	switch t := v.(type) {
+	case []any:
+		return t
+	case []string:
+		out := make([]any, 0, len(t))
+		for _, s := range t {
+			out = append(out, s)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, 0, len(t))
+		for _, m := range t {
+			out = append(out, m)
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float64:
+		return t, true
+	}
+	return 0, false
+}
+
+func jsonEqual(a, b any) bool {
+	return jsonString(a) == jsonString(b)
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,201 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+var testEdgeSchema = map[string]any{
+	"type":                 "object",
+	"additionalProperties": false,
+	"properties": map[string]any{
+		"edges": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"from_key":  map[string]any{"type": "string"},
+					"to_key":    map[string]any{"type": "string"},
+					"edge_type": map[string]any{"type": "string", "enum": []any{"prereq", "related"}},
+					"strength":  map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+				},
+				"required": []string{"from_key", "to_key", "edge_type", "strength"},
+			},
+		},
+	},
+	"required": []string{"edges"},
+}
+
+type testEdges struct {
+	Edges []struct {
+		FromKey  string  `json:"from_key"`
+		ToKey    string  `json:"to_key"`
+		EdgeType string  `json:"edge_type"`
+		Strength float64 `json:"strength"`
+	} `json:"edges"`
+}
+
+// Responses API fixtures.
+func outputTextFixture(text string) string {
+	b, _ := json.Marshal(text)
+	return `{"status":"completed","output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":` + string(b) + `}]}]}`
+}
+
+var (
+	validEdgesFixture     = outputTextFixture(`{"edges":[{"from_key":"limits","to_key":"derivatives","edge_type":"prereq","strength":0.9}]}`)
+	stringStrengthFixture = outputTextFixture(`{"edges":[{"from_key":"limits","to_key":"derivatives","edge_type":"prereq","strength":"high"}]}`)
+	truncatedFixture      = `{"status":"incomplete","incomplete_details":{"reason":"max_output_tokens"},"output":[{"type":"message","role":"assistant","content":[{"type":"output_text","text":"{\"edges\":[{\"from_key\":\"limits\",\"to_key\":\"deriv"}]}]}`
+	refusalFixture        = `{"status":"completed","output":[{"type":"message","role":"assistant","content":[{"type":"refusal","refusal":"I can't help with that."}]}]}`
+)
+
+// fixtureServer replays canned Responses API bodies in order and records the user prompts.
+type fixtureServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	userMsgs []string
+}
+
+func (f *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	var req responsesRequest
+	_ = json.Unmarshal(raw, &req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, in := range req.Input {
+		if in.Role == "user" {
+			s, _ := in.Content.(string)
+			f.userMsgs = append(f.userMsgs, s)
+		}
+	}
+	if len(f.bodies) == 0 {
+		http.Error(w, "no fixture left", http.StatusInternalServerError)
+		return
+	}
+	body := f.bodies[0]
+	f.bodies = f.bodies[1:]
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, body)
+}
+
+func newFixtureClient(t *testing.T, bodies ...string) (Client, *fixtureServer) {
+	t.Helper()
+	fs := &fixtureServer{bodies: bodies}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+
+	t.Setenv("OPENAI_API_KEY", "test")
+	t.Setenv("OPENAI_BASE_URL", srv.URL)
+	t.Setenv("OPENAI_MAX_RETRIES", "0")
+	log, err := logger.New("test")
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	c, err := NewClient(log)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c, fs
+}
+
+func TestGenerateJSONValidated(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		c, fs := newFixtureClient(t, validEdgesFixture)
+		var out testEdges
+		if err := GenerateJSONValidated(ctx, c, "sys", "user", "concept_edges", testEdgeSchema, &out); err != nil {
+			t.Fatalf("GenerateJSONValidated: %v", err)
+		}
+		if len(out.Edges) != 1 || out.Edges[0].Strength != 0.9 || len(fs.userMsgs) != 1 {
+			t.Fatalf("out=%+v calls=%d", out, len(fs.userMsgs))
+		}
+	})
+
+	t.Run("schema violation is repaired", func(t *testing.T) {
+		c, fs := newFixtureClient(t, stringStrengthFixture, validEdgesFixture)
+		var out testEdges
+		if err := GenerateJSONValidated(ctx, c, "sys", "user", "concept_edges", testEdgeSchema, &out); err != nil {
+			t.Fatalf("GenerateJSONValidated: %v", err)
+		}
+		if len(fs.userMsgs) != 2 {
+			t.Fatalf("calls = %d, want 2", len(fs.userMsgs))
+		}
+		repair := fs.userMsgs[1]
+		if !strings.Contains(repair, "PREVIOUS_RESPONSE_REJECTED (schema_violation)") || !strings.Contains(repair, "$.edges[0].strength: expected number, got string") {
+			t.Fatalf("repair prompt missing validation errors:\n%s", repair)
+		}
+		if len(out.Edges) != 1 || out.Edges[0].EdgeType != "prereq" {
+			t.Fatalf("out = %+v", out)
+		}
+	})
+
+	t.Run("truncation survives repair", func(t *testing.T) {
+		c, fs := newFixtureClient(t, truncatedFixture, truncatedFixture)
+		var out testEdges
+		err := GenerateJSONValidated(ctx, c, "sys", "user", "concept_edges", testEdgeSchema, &out)
+		var jerr *JSONOutputError
+		if !errors.As(err, &jerr) || jerr.Class != JSONFailureTruncation || !jerr.Repaired {
+			t.Fatalf("err = %v, want repaired truncation", err)
+		}
+		if JSONFailureClassOf(err) != JSONFailureTruncation || len(fs.userMsgs) != 2 {
+			t.Fatalf("class=%q calls=%d", JSONFailureClassOf(err), len(fs.userMsgs))
+		}
+		if !strings.Contains(fs.userMsgs[1], "cut off") {
+			t.Fatalf("repair prompt should mention truncation:\n%s", fs.userMsgs[1])
+		}
+	})
+
+	t.Run("refusal is not repaired", func(t *testing.T) {
+		c, fs := newFixtureClient(t, refusalFixture, validEdgesFixture)
+		var out testEdges
+		err := GenerateJSONValidated(ctx, c, "sys", "user", "concept_edges", testEdgeSchema, &out)
+		if JSONFailureClassOf(err) != JSONFailureRefusal || len(fs.userMsgs) != 1 {
+			t.Fatalf("err=%v calls=%d", err, len(fs.userMsgs))
+		}
+	})
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	var doc any
+	raw := `{"edges":[{"from_key":"a","to_key":"b","edge_type":"causes","strength":1.5,"extra":true},{"from_key":"a"}]}`
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatal(err)
+	}
+	got := validateJSONSchema(testEdgeSchema, doc)
+	want := []string{
+		`$.edges[0].edge_type: value "causes" not in enum`,
+		`$.edges[0].extra: unexpected key`,
+		`$.edges[0].strength: 1.5 is above maximum 1`,
+		`$.edges[1]: missing required key "to_key"`,
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if g == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing problem %q in %v", w, got)
+		}
+	}
+
+	nullable := map[string]any{"type": []any{"string", "null"}}
+	if p := validateJSONSchema(nullable, nil); len(p) != 0 {
+		t.Fatalf("null should satisfy string|null: %v", p)
+	}
+	if p := validateJSONSchema(map[string]any{"type": "integer"}, 2.5); len(p) != 1 {
+		t.Fatalf("2.5 should not be an integer: %v", p)
+	}
+}
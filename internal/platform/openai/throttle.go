@@ -0,0 +1,133 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxThrottleWait caps how far a single provider Retry-After pushes the throttle window.
+const maxThrottleWait = 5 * time.Minute
+
+// ThrottledError is returned when the provider rate limited (429) or shed (503) a request and
+// retries did not get through. RetryAfter is the estimated wait from the throttle state.
+type ThrottledError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("openai throttled (retry after %s): %v", e.RetryAfter, e.Err)
+}
+
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// HTTPStatusCode keeps httpx.IsRetryableError treating the error like the underlying response.
+func (e *ThrottledError) HTTPStatusCode() int {
+	if e == nil {
+		return 0
+	}
+	return e.StatusCode
+}
+
+// AsThrottled reports whether err (or anything it wraps) is a *ThrottledError.
+func AsThrottled(err error) (*ThrottledError, bool) {
+	var te *ThrottledError
+	if errors.As(err, &te) && te != nil {
+		return te, true
+	}
+	return nil, false
+}
+
+func isThrottleStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// Throttle tracks provider backpressure: the time until which calls are expected to be throttled.
+// Throttling is per API key, so clients share one process-wide Throttle (ProviderThrottle).
+type Throttle struct {
+	mu    sync.Mutex
+	until time.Time
+	now   func() time.Time
+}
+
+// NewThrottle returns an idle Throttle; now defaults to time.Now.
+func NewThrottle(now func() time.Time) *Throttle {
+	if now == nil {
+		now = time.Now
+	}
+	return &Throttle{now: now}
+}
+
+var providerThrottle = NewThrottle(nil)
+
+// ProviderThrottle is the throttle state shared by every client in the process.
+func ProviderThrottle() *Throttle { return providerThrottle }
+
+// Note records that the provider asked us to wait; the window only ever extends.
+func (t *Throttle) Note(wait time.Duration) {
+	if t == nil || wait <= 0 {
+		return
+	}
+	if wait > maxThrottleWait {
+		wait = maxThrottleWait
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := t.now().Add(wait); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// Clear ends the throttle window, e.g. after a request got through.
+func (t *Throttle) Clear() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.until = time.Time{}
+	t.mu.Unlock()
+}
+
+// Wait is the estimated time until the provider accepts calls again; 0 when not throttled.
+func (t *Throttle) Wait() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.until.IsZero() {
+		return 0
+	}
+	if d := t.until.Sub(t.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (c *client) throttleState() *Throttle {
+	if c != nil && c.throttle != nil {
+		return c.throttle
+	}
+	return providerThrottle
+}
+
+// throttled records the backpressure carried by a 429/503 error and wraps it as a
+// *ThrottledError; other errors are returned unchanged. fallback is used when the response had
+// no Retry-After.
+func (c *client) throttled(err error, fallback time.Duration) error {
+	var httpErr *openAIHTTPError
+	if !errors.As(err, &httpErr) || !isThrottleStatus(httpErr.StatusCode) {
+		return err
+	}
+	wait := httpErr.RetryAfter
+	if wait <= 0 {
+		wait = fallback
+	}
+	state := c.throttleState()
+	state.Note(wait)
+	return &ThrottledError{StatusCode: httpErr.StatusCode, RetryAfter: state.Wait(), Err: err}
+}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRecordsProviderThrottle(t *testing.T) {
+	var throttled atomic.Bool
+	throttled.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if throttled.Load() {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"Rate limit reached"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := NewThrottle(func() time.Time { return now })
+	c := &client{baseURL: srv.URL, httpClient: srv.Client(), throttle: state}
+
+	err := c.do(context.Background(), http.MethodPost, "/v1/responses", map[string]any{"model": "m"}, nil)
+	te, ok := AsThrottled(err)
+	if !ok {
+		t.Fatalf("expected a throttled error, got %v", err)
+	}
+	if te.StatusCode != http.StatusTooManyRequests || te.RetryAfter != 7*time.Second {
+		t.Fatalf("throttled error = %+v", te)
+	}
+	if got := state.Wait(); got != 7*time.Second {
+		t.Fatalf("throttle wait = %s, want 7s", got)
+	}
+
+	now = now.Add(3 * time.Second)
+	if got := state.Wait(); got != 4*time.Second {
+		t.Fatalf("throttle wait after 3s = %s, want 4s", got)
+	}
+
+	throttled.Store(false)
+	if err := c.do(context.Background(), http.MethodPost, "/v1/responses", map[string]any{"model": "m"}, nil); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if got := state.Wait(); got != 0 {
+		t.Fatalf("throttle not cleared after a success: %s", got)
+	}
+}
+
+func TestThrottleNoteOnlyExtends(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	th := NewThrottle(func() time.Time { return now })
+	th.Note(10 * time.Second)
+	th.Note(2 * time.Second)
+	if got := th.Wait(); got != 10*time.Second {
+		t.Fatalf("wait = %s, want 10s", got)
+	}
+	th.Note(time.Hour)
+	if got := th.Wait(); got != maxThrottleWait {
+		t.Fatalf("wait = %s, want cap %s", got, maxThrottleWait)
+	}
+}
@@ -0,0 +1,109 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type openAIProvider struct {
+	log        *logger.Logger
+	baseURL    string
+	apiKey     string
+	model      string
+	voice      string
+	httpClient *http.Client
+}
+
+func newOpenAIProvider(log *logger.Logger) (Provider, error) {
+	apiKey := strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing OPENAI_API_KEY (set TTS_PROVIDER=stub to run without credentials)")
+	}
+	baseURL := strings.TrimSpace(os.Getenv("OPENAI_BASE_URL"))
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	model := strings.TrimSpace(os.Getenv("OPENAI_TTS_MODEL"))
+	if model == "" {
+		model = "gpt-4o-mini-tts"
+	}
+	voice := strings.TrimSpace(os.Getenv("OPENAI_TTS_VOICE"))
+	if voice == "" {
+		voice = "alloy"
+	}
+	return &openAIProvider{
+		log:        log.With("service", "tts.OpenAI"),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		voice:      voice,
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}, nil
+}
+
+func (p *openAIProvider) Name() string  { return ProviderOpenAI }
+func (p *openAIProvider) Voice() string { return p.voice }
+func (p *openAIProvider) MaxChars() int { return 4000 }
+
+type openAISpeechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+func (p *openAIProvider) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("tts: empty text")
+	}
+	if len(text) > p.MaxChars() {
+		return nil, fmt.Errorf("tts: text exceeds %d chars", p.MaxChars())
+	}
+	voice := strings.TrimSpace(req.Voice)
+	if voice == "" {
+		voice = p.voice
+	}
+	body, err := json.Marshal(openAISpeechRequest{Model: p.model, Input: text, Voice: voice, ResponseFormat: "mp3"})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("tts: openai speech status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("tts: empty audio")
+	}
+	// MP3 output carries no cheap exact length; the estimate is close enough for block mapping.
+	return &Audio{
+		Data:       raw,
+		MimeType:   "audio/mpeg",
+		Extension:  "mp3",
+		DurationMS: EstimateDurationMS(text),
+	}, nil
+}
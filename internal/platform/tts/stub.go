@@ -0,0 +1,77 @@
+package tts
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Stub audio is 8 kHz, 8-bit mono PCM: tiny files that still carry a real duration.
+const (
+	stubSampleRate = 8000
+	stubSilence    = 0x80
+)
+
+type stubProvider struct{}
+
+// NewStub returns a provider that renders silence sized to the estimated speaking time.
+func NewStub() Provider { return stubProvider{} }
+
+func (stubProvider) Name() string  { return ProviderStub }
+func (stubProvider) Voice() string { return "stub" }
+func (stubProvider) MaxChars() int { return 4000 }
+
+func (s stubProvider) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("tts: empty text")
+	}
+	if len(text) > s.MaxChars() {
+		return nil, fmt.Errorf("tts: text exceeds %d chars", s.MaxChars())
+	}
+	durationMS := EstimateDurationMS(text)
+	return &Audio{
+		Data:       silentWAV(durationMS),
+		MimeType:   "audio/wav",
+		Extension:  "wav",
+		DurationMS: durationMS,
+	}, nil
+}
+
+func silentWAV(durationMS int) []byte {
+	samples := stubSampleRate * durationMS / 1000
+	buf := make([]byte, 44+samples)
+	copy(buf[0:], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:], uint32(36+samples))
+	copy(buf[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(buf[16:], 16) // fmt chunk size
+	binary.LittleEndian.PutUint16(buf[20:], 1)  // PCM
+	binary.LittleEndian.PutUint16(buf[22:], 1)  // mono
+	binary.LittleEndian.PutUint32(buf[24:], stubSampleRate)
+	binary.LittleEndian.PutUint32(buf[28:], stubSampleRate) // byte rate
+	binary.LittleEndian.PutUint16(buf[32:], 1)              // block align
+	binary.LittleEndian.PutUint16(buf[34:], 8)              // bits per sample
+	copy(buf[36:], "data")
+	binary.LittleEndian.PutUint32(buf[40:], uint32(samples))
+	for i := 44; i < len(buf); i++ {
+		buf[i] = stubSilence
+	}
+	return buf
+}
+
+// WAVDurationMS reads the duration of a canonical PCM WAV clip.
+func WAVDurationMS(data []byte) (int, bool) {
+	if len(data) < 44 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		return 0, false
+	}
+	byteRate := binary.LittleEndian.Uint32(data[28:])
+	if byteRate == 0 {
+		return 0, false
+	}
+	size := binary.LittleEndian.Uint32(data[40:])
+	return int(uint64(size) * 1000 / uint64(byteRate)), true
+}
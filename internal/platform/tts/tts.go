@@ -0,0 +1,74 @@
+// Package tts synthesizes speech audio from plain text.
+//
+// The provider is chosen by TTS_PROVIDER:
+//   - "openai" (default): OpenAI /v1/audio/speech (needs OPENAI_API_KEY)
+//   - "stub": deterministic silent WAV audio, for environments without credentials and tests
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderStub   = "stub"
+)
+
+// Provider turns text into an audio clip.
+type Provider interface {
+	Name() string
+	// Voice reports the voice used when Request.Voice is empty.
+	Voice() string
+	// MaxChars is the longest text a single Synthesize call accepts.
+	MaxChars() int
+	Synthesize(ctx context.Context, req Request) (*Audio, error)
+}
+
+type Request struct {
+	Text  string
+	Voice string
+}
+
+type Audio struct {
+	Data       []byte
+	MimeType   string
+	Extension  string
+	DurationMS int
+}
+
+// New builds the provider selected by TTS_PROVIDER.
+func New(log *logger.Logger) (Provider, error) {
+	if log == nil {
+		return nil, fmt.Errorf("logger required")
+	}
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("TTS_PROVIDER")))
+	switch name {
+	case "", ProviderOpenAI:
+		return newOpenAIProvider(log)
+	case ProviderStub:
+		return NewStub(), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS_PROVIDER %q", name)
+	}
+}
+
+// Speaking rate used when a provider does not report clip duration (~155 wpm).
+const estimatedMSPerWord = 390
+
+// EstimateDurationMS approximates spoken duration for text at a typical narration pace.
+func EstimateDurationMS(text string) int {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		if utf8.RuneCountInString(strings.TrimSpace(text)) == 0 {
+			return 0
+		}
+		words = 1
+	}
+	return words * estimatedMSPerWord
+}
@@ -0,0 +1,59 @@
+package tts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+func TestStubSynthesize(t *testing.T) {
+	p := NewStub()
+	text := strings.Repeat("word ", 10)
+	audio, err := p.Synthesize(context.Background(), Request{Text: text})
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if audio.MimeType != "audio/wav" || audio.Extension != "wav" {
+		t.Fatalf("unexpected format: %s %s", audio.MimeType, audio.Extension)
+	}
+	if audio.DurationMS != 10*estimatedMSPerWord {
+		t.Fatalf("duration: got %d", audio.DurationMS)
+	}
+	got, ok := WAVDurationMS(audio.Data)
+	if !ok || got != audio.DurationMS {
+		t.Fatalf("WAV header duration: got %d ok=%v, want %d", got, ok, audio.DurationMS)
+	}
+
+	if _, err := p.Synthesize(context.Background(), Request{Text: "  "}); err == nil {
+		t.Fatalf("expected error for empty text")
+	}
+	if _, err := p.Synthesize(context.Background(), Request{Text: strings.Repeat("x", p.MaxChars()+1)}); err == nil {
+		t.Fatalf("expected error for oversized text")
+	}
+}
+
+func TestNewSelectsProviderFromEnv(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	t.Setenv("TTS_PROVIDER", "stub")
+	p, err := New(log)
+	if err != nil || p.Name() != ProviderStub {
+		t.Fatalf("stub: provider=%v err=%v", p, err)
+	}
+
+	t.Setenv("TTS_PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "")
+	if _, err := New(log); err == nil {
+		t.Fatalf("expected missing key error for openai provider")
+	}
+
+	t.Setenv("TTS_PROVIDER", "bogus")
+	if _, err := New(log); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+}
@@ -0,0 +1,104 @@
+package websearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type braveProvider struct {
+	log        *logger.Logger
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	pages      pageFetcher
+}
+
+func newBraveProvider(log *logger.Logger, limits Limits) (Provider, error) {
+	apiKey := strings.TrimSpace(os.Getenv("WEB_SEARCH_API_KEY"))
+	if apiKey == "" {
+		return nil, fmt.Errorf("missing WEB_SEARCH_API_KEY (set WEB_SEARCH_PROVIDER=stub to run without credentials)")
+	}
+	baseURL := strings.TrimSpace(os.Getenv("WEB_SEARCH_BASE_URL"))
+	if baseURL == "" {
+		baseURL = "https://api.search.brave.com"
+	}
+	return &braveProvider{
+		log:        log.With("service", "websearch.Brave"),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		pages:      newPageFetcher(limits),
+	}, nil
+}
+
+func (p *braveProvider) Name() string { return ProviderBrave }
+
+type braveSearchResponse struct {
+	Web struct {
+		Results []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Description string `json:"description"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+func (p *braveProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("websearch: empty query")
+	}
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+	q := url.Values{}
+	q.Set("q", query)
+	q.Set("count", strconv.Itoa(limit))
+	q.Set("safesearch", "strict")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/res/v1/web/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", p.apiKey)
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("websearch: brave http %d", resp.StatusCode)
+	}
+	var body braveSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("websearch: decode brave response: %w", err)
+	}
+	out := make([]Result, 0, len(body.Web.Results))
+	for _, r := range body.Web.Results {
+		if strings.TrimSpace(r.URL) == "" {
+			continue
+		}
+		out = append(out, Result{
+			Title:   strings.TrimSpace(r.Title),
+			URL:     strings.TrimSpace(r.URL),
+			Snippet: collapseSpace(r.Description),
+		})
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (p *braveProvider) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	return p.pages.fetch(ctx, rawURL)
+}
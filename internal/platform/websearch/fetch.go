@@ -0,0 +1,158 @@
+package websearch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/httpx"
+)
+
+// pageFetcher is the guarded page reader shared by the real providers.
+type pageFetcher struct {
+	client *http.Client
+	limits Limits
+}
+
+func newPageFetcher(limits Limits) pageFetcher {
+	return pageFetcher{client: httpx.NewPublicHTTPSClient(limits.Timeout, 3), limits: limits}
+}
+
+func (f pageFetcher) fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u := strings.TrimSpace(rawURL)
+	if !httpx.IsPublicHTTPSURL(ctx, u) {
+		return nil, fmt.Errorf("websearch: url not allowed: %s", u)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "NeurobridgeBot/1.0 (chat web enrichment)")
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("websearch: http %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "text/plain" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("websearch: unsupported content type %q", mediaType)
+	}
+
+	// Read one byte past the cap to tell a page that fits from one that was cut.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{URL: u}
+	if resp.Request != nil && resp.Request.URL != nil {
+		page.URL = resp.Request.URL.String()
+	}
+	if int64(len(body)) > f.limits.MaxBytes {
+		body = body[:f.limits.MaxBytes]
+		page.Truncated = true
+	}
+	if mediaType == "text/plain" {
+		page.Text = collapseSpace(string(body))
+	} else {
+		page.Title, page.Text = ReadableText(body)
+	}
+	if text, cut := capRunes(page.Text, f.limits.MaxChars); cut {
+		page.Text = text
+		page.Truncated = true
+	}
+	return page, nil
+}
+
+// skippedElements hold no readable prose.
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true, "iframe": true,
+}
+
+// blockElements end a line of text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "section": true, "article": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "pre": true, "blockquote": true,
+}
+
+// ReadableText extracts the title and visible prose of an HTML document, one line per block
+// element, skipping scripts, styles and page chrome (navigation, headers, footers, forms).
+func ReadableText(doc []byte) (string, string) {
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	var title strings.Builder
+	var b strings.Builder
+	skipDepth := 0
+	inTitle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return collapseSpace(title.String()), collapseLines(b.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tt := z.Token()
+			tag := tt.Data
+			if skippedElements[tag] && tt.Type == html.StartTagToken {
+				skipDepth++
+			}
+			inTitle = tag == "title"
+			if blockElements[tag] {
+				b.WriteString("\n")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skippedElements[tag] && skipDepth > 0 {
+				skipDepth--
+			}
+			if tag == "title" {
+				inTitle = false
+			}
+			if blockElements[tag] {
+				b.WriteString("\n")
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			// Source line breaks inside a text run are layout, not structure.
+			b.WriteString(collapseSpace(string(z.Text())))
+			b.WriteString(" ")
+		}
+	}
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func collapseLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	for _, ln := range lines {
+		if ln = collapseSpace(ln); ln != "" {
+			out = append(out, ln)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func capRunes(s string, max int) (string, bool) {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s, false
+	}
+	return string([]rune(s)[:max]), true
+}
@@ -0,0 +1,61 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Stub is an in-memory provider. Search returns Results (up to limit) for any query and
+// Fetch serves Pages by URL; nothing touches the network. Queries records every search.
+type Stub struct {
+	Results   []Result
+	Pages     map[string]Page
+	SearchErr error
+
+	mu      sync.Mutex
+	Queries []string
+}
+
+// NewStub returns a stub with one canned result and its page.
+func NewStub() *Stub {
+	const u = "https://example.com/websearch-stub"
+	return &Stub{
+		Results: []Result{{Title: "Example result", URL: u, Snippet: "Canned web search result."}},
+		Pages:   map[string]Page{u: {URL: u, Title: "Example result", Text: "Canned web page text."}},
+	}
+}
+
+func (s *Stub) Name() string { return ProviderStub }
+
+func (s *Stub) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.Queries = append(s.Queries, query)
+	s.mu.Unlock()
+	if s.SearchErr != nil {
+		return nil, s.SearchErr
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("websearch: empty query")
+	}
+	out := s.Results
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return append([]Result(nil), out...), nil
+}
+
+func (s *Stub) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	page, ok := s.Pages[strings.TrimSpace(rawURL)]
+	if !ok {
+		return nil, fmt.Errorf("websearch: stub has no page for %s", rawURL)
+	}
+	return &page, nil
+}
@@ -0,0 +1,97 @@
+// Package websearch finds and reads public web pages for features that need information
+// beyond a user's own materials.
+//
+// The provider is chosen by WEB_SEARCH_PROVIDER:
+//   - "" or "none" (default): web search is off and New returns a nil Provider
+//   - "brave": Brave Search API (needs WEB_SEARCH_API_KEY)
+//   - "stub": deterministic canned results, for environments without credentials and tests
+//
+// Every provider fetches result pages through the same guarded fetcher: https only, public
+// hosts only (redirects included), and hard caps on bytes read and text returned.
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+const (
+	ProviderBrave = "brave"
+	ProviderStub  = "stub"
+)
+
+// Provider searches the web and reads result pages.
+type Provider interface {
+	Name() string
+	// Search returns up to limit results for query, best first.
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+	// Fetch downloads rawURL and returns its readable text, capped by the provider's Limits.
+	// URLs that are not public https addresses are refused.
+	Fetch(ctx context.Context, rawURL string) (*Page, error)
+}
+
+type Result struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+type Page struct {
+	URL   string
+	Title string
+	Text  string
+	// Truncated is set when the body or the extracted text hit a limit.
+	Truncated bool
+}
+
+// Limits bound every page fetch.
+type Limits struct {
+	MaxBytes int64
+	MaxChars int
+	Timeout  time.Duration
+}
+
+// LimitsFromEnv reads WEB_SEARCH_FETCH_MAX_BYTES (default 512 KiB), WEB_SEARCH_FETCH_MAX_CHARS
+// (default 6000) and WEB_SEARCH_FETCH_TIMEOUT_SECONDS (default 8).
+func LimitsFromEnv() Limits {
+	l := Limits{
+		MaxBytes: int64(envutil.Int("WEB_SEARCH_FETCH_MAX_BYTES", 512*1024)),
+		MaxChars: envutil.Int("WEB_SEARCH_FETCH_MAX_CHARS", 6000),
+		Timeout:  time.Duration(envutil.Int("WEB_SEARCH_FETCH_TIMEOUT_SECONDS", 8)) * time.Second,
+	}
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = 512 * 1024
+	}
+	if l.MaxChars <= 0 {
+		l.MaxChars = 6000
+	}
+	if l.Timeout <= 0 {
+		l.Timeout = 8 * time.Second
+	}
+	return l
+}
+
+// New builds the provider selected by WEB_SEARCH_PROVIDER. It returns nil, nil when web
+// search is not configured.
+func New(log *logger.Logger) (Provider, error) {
+	if log == nil {
+		return nil, fmt.Errorf("logger required")
+	}
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("WEB_SEARCH_PROVIDER")))
+	switch name {
+	case "", "none":
+		return nil, nil
+	case ProviderBrave:
+		return newBraveProvider(log, LimitsFromEnv())
+	case ProviderStub:
+		return NewStub(), nil
+	default:
+		return nil, fmt.Errorf("unknown WEB_SEARCH_PROVIDER %q", name)
+	}
+}
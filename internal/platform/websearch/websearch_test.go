@@ -0,0 +1,71 @@
+package websearch
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+func TestReadableText(t *testing.T) {
+	doc := `<html><head><title> Release notes </title><style>p{color:red}</style>
+		<script>var leak = "do not read";</script></head>
+		<body><nav><a href="/">Home</a> <a href="/docs">Docs</a></nav><svg/>
+		<h1>Version 2.4</h1><p>Adds   streaming
+		responses.</p><ul><li>Fixes retries</li><li>Drops Go 1.20</li></ul>
+		<footer>Copyright</footer></body></html>`
+	title, text := ReadableText([]byte(doc))
+	if title != "Release notes" {
+		t.Fatalf("title = %q", title)
+	}
+	want := "Version 2.4\nAdds streaming responses.\nFixes retries\nDrops Go 1.20"
+	if text != want {
+		t.Fatalf("text = %q, want %q", text, want)
+	}
+}
+
+func TestFetchRefusesNonPublicURLs(t *testing.T) {
+	f := newPageFetcher(Limits{MaxBytes: 1024, MaxChars: 100, Timeout: 1})
+	for _, u := range []string{"http://example.com/", "https://127.0.0.1/admin", "https://10.0.0.8/", "https://localhost/", "file:///etc/passwd"} {
+		if _, err := f.fetch(context.Background(), u); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Fatalf("%s: err = %v, want refusal", u, err)
+		}
+	}
+}
+
+func TestNewSelectsProviderFromEnv(t *testing.T) {
+	log, err := logger.New("development")
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	t.Setenv("WEB_SEARCH_PROVIDER", "")
+	if p, err := New(log); p != nil || err != nil {
+		t.Fatalf("unset: provider=%v err=%v, want disabled", p, err)
+	}
+
+	t.Setenv("WEB_SEARCH_PROVIDER", "stub")
+	p, err := New(log)
+	if err != nil || p.Name() != ProviderStub {
+		t.Fatalf("stub: provider=%v err=%v", p, err)
+	}
+	results, err := p.Search(context.Background(), "anything", 3)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("stub search: %v %v", results, err)
+	}
+	if page, err := p.Fetch(context.Background(), results[0].URL); err != nil || page.Text == "" {
+		t.Fatalf("stub fetch: %v %v", page, err)
+	}
+
+	t.Setenv("WEB_SEARCH_PROVIDER", "brave")
+	t.Setenv("WEB_SEARCH_API_KEY", "")
+	if _, err := New(log); err == nil {
+		t.Fatalf("expected missing key error for brave provider")
+	}
+
+	t.Setenv("WEB_SEARCH_PROVIDER", "bogus")
+	if _, err := New(log); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+}
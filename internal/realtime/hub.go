@@ -27,6 +27,7 @@ const (
 	SSEEventJobDone                SSEEvent = "JobDone"
 	SSEEventJobCanceled            SSEEvent = "JobCanceled"
 	SSEEventJobRestarted           SSEEvent = "JobRestarted"
+	SSEEventJobDeferred            SSEEvent = "JobDeferred"
 
 	SSEEventChatThreadCreated  SSEEvent = "ChatThreadCreated"
 	SSEEventChatMessageCreated SSEEvent = "ChatMessageCreated"
@@ -35,6 +36,8 @@ const (
 	SSEEventChatMessageError   SSEEvent = "ChatMessageError"
 	SSEEventRuntimePrompt      SSEEvent = "RuntimePrompt"
 
+	SSEEventNotificationCreated SSEEvent = "NotificationCreated"
+
 	// Backwards-compat alias (typo).
 	SSEEVentChatMessageError SSEEvent = SSEEventChatMessageError
 )
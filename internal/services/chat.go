@@ -335,7 +335,8 @@ type ChatService interface {
 	ListPendingIntakeQuestions(dbc dbctx.Context, limit int) ([]*types.ChatMessage, error)
 
 	// SendMessage persists a user message, creates an assistant placeholder message, and enqueues a "chat_respond" job.
-	SendMessage(dbc dbctx.Context, threadID uuid.UUID, content string, idempotencyKey string) (*types.ChatMessage, *types.ChatMessage, *types.JobRun, error)
+	// verbosity (terse/normal/detailed, validated by the caller) is stored on the user message for the responder.
+	SendMessage(dbc dbctx.Context, threadID uuid.UUID, content string, idempotencyKey string, verbosity string, disabledLanes []string) (*types.ChatMessage, *types.ChatMessage, *types.JobRun, error)
 
 	// RebuildThread enqueues a deterministic rebuild of derived chat artifacts (docs/summaries/graph/memory).
 	RebuildThread(dbc dbctx.Context, threadID uuid.UUID) (*types.JobRun, error)
@@ -641,7 +642,7 @@ func (s *chatService) ListPendingIntakeQuestions(dbc dbctx.Context, limit int) (
 	return dedup, nil
 }
 
-func (s *chatService) SendMessage(dbc dbctx.Context, threadID uuid.UUID, content string, idempotencyKey string) (*types.ChatMessage, *types.ChatMessage, *types.JobRun, error) {
+func (s *chatService) SendMessage(dbc dbctx.Context, threadID uuid.UUID, content string, idempotencyKey string, verbosity string, disabledLanes []string) (*types.ChatMessage, *types.ChatMessage, *types.JobRun, error) {
 	rd := ctxutil.GetRequestData(dbc.Ctx)
 	if rd == nil || rd.UserID == uuid.Nil {
 		return nil, nil, nil, fmt.Errorf("not authenticated")
@@ -814,6 +815,12 @@ func (s *chatService) SendMessage(dbc dbctx.Context, threadID uuid.UUID, content
 				}
 			}
 		}
+		if v := strings.ToLower(strings.TrimSpace(verbosity)); v != "" && v != "normal" {
+			sessionMeta["verbosity"] = v
+		}
+		if len(disabledLanes) > 0 {
+			sessionMeta["disabled_lanes"] = disabledLanes
+		}
 		metaJSON := encodeMetadata(sessionMeta)
 
 		// ──────────────────────────────────────────────────────────────────────────────
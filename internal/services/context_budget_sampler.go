@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// ContextBudgetSample is the per-lane token accounting of one chat context plan.
+type ContextBudgetSample struct {
+	UserID           uuid.UUID
+	ThreadID         uuid.UUID
+	PathID           *uuid.UUID
+	LanesEnabled     []string
+	Lanes            []types.ContextBudgetLaneUsage
+	MaxContextTokens int
+}
+
+// ContextBudgetSampler records one in every N context plans for the truncation-pressure
+// summary. Losing a sample is harmless, so a busy writer drops rather than queueing.
+type ContextBudgetSampler interface {
+	// Observe counts a plan and schedules a write when it is sampled; it never blocks the caller.
+	// The sample must not be mutated afterwards.
+	Observe(sample ContextBudgetSample)
+}
+
+type contextBudgetSampler struct {
+	log      *logger.Logger
+	repo     repos.ContextBudgetStatRepo
+	outlines PathOutlineService
+	every    uint64
+	timeout  time.Duration
+
+	sem  chan struct{}
+	seen atomic.Uint64
+}
+
+func NewContextBudgetSampler(baseLog *logger.Logger, repo repos.ContextBudgetStatRepo, outlines PathOutlineService) ContextBudgetSampler {
+	every := envutil.Int("CHAT_CONTEXT_BUDGET_SAMPLE_EVERY", 20)
+	if every < 0 {
+		every = 0
+	}
+	timeoutMS := envutil.Int("CHAT_CONTEXT_BUDGET_SAMPLE_TIMEOUT_MS", 2000)
+	if timeoutMS <= 0 {
+		timeoutMS = 2000
+	}
+	concurrency := envutil.Int("CHAT_CONTEXT_BUDGET_SAMPLE_CONCURRENCY", 2)
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &contextBudgetSampler{
+		log:      baseLog.With("service", "ContextBudgetSampler"),
+		repo:     repo,
+		outlines: outlines,
+		every:    uint64(every),
+		timeout:  time.Duration(timeoutMS) * time.Millisecond,
+		sem:      make(chan struct{}, concurrency),
+	}
+}
+
+func (s *contextBudgetSampler) Observe(sample ContextBudgetSample) {
+	// CHAT_CONTEXT_BUDGET_SAMPLE_EVERY=0 turns sampling off.
+	if s == nil || s.repo == nil || s.every == 0 || len(sample.Lanes) == 0 {
+		return
+	}
+	if s.seen.Add(1)%s.every != 0 {
+		return
+	}
+	select {
+	case s.sem <- struct{}{}:
+	default:
+		s.log.WarnThrottled("context_budget_sample_dropped", time.Minute, "context budget sample dropped: writer busy")
+		return
+	}
+	go func() {
+		defer func() {
+			<-s.sem
+			if r := recover(); r != nil {
+				s.log.Warn("context budget sample panicked", "thread_id", sample.ThreadID, "panic", r)
+			}
+		}()
+		if err := s.write(sample); err != nil {
+			s.log.Debug("context budget sample write failed", "error", err, "thread_id", sample.ThreadID)
+		}
+	}()
+}
+
+func (s *contextBudgetSampler) write(sample ContextBudgetSample) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	dbc := dbctx.Context{Ctx: ctx}
+
+	nodes := 0
+	if sample.PathID != nil && *sample.PathID != uuid.Nil && s.outlines != nil {
+		if outline, err := s.outlines.Outline(dbc, *sample.PathID); err == nil && outline != nil {
+			nodes = len(outline.OrderedNodes())
+		}
+	}
+	lanesEnabled, err := json.Marshal(sample.LanesEnabled)
+	if err != nil {
+		return err
+	}
+	lanes, err := json.Marshal(sample.Lanes)
+	if err != nil {
+		return err
+	}
+	dropped := false
+	for _, u := range sample.Lanes {
+		dropped = dropped || u.Dropped
+	}
+	return s.repo.Create(dbc, &types.ContextBudgetStat{
+		UserID:           sample.UserID,
+		ThreadID:         sample.ThreadID,
+		PathID:           sample.PathID,
+		PathSizeBucket:   types.PathSizeBucket(nodes),
+		PathNodeCount:    nodes,
+		LanesEnabled:     lanesEnabled,
+		Lanes:            lanes,
+		MaxContextTokens: sample.MaxContextTokens,
+		AnyLaneDropped:   dropped,
+	})
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// DocServingCache holds the shared, read-mostly inputs of the doc serving decision so the
+// first doc open of a session doesn't pay for them cold. Entries are short-lived: concept
+// scopes only change on (re)builds and policy snapshots on eval runs, both minutes apart.
+// Path-scoped entries are also dropped as soon as SyncPath sees the path was rebuilt.
+type DocServingCache interface {
+	// PathConcepts returns the path-scoped concept rows, loading them on a miss.
+	PathConcepts(dbc dbctx.Context, pathID uuid.UUID) ([]*types.Concept, error)
+	// PolicySnapshot returns the latest eval snapshot for key, loading it on a miss.
+	PolicySnapshot(dbc dbctx.Context, key string) (*types.PolicyEvalSnapshot, error)
+	// NodeDocTracks returns the tracks the node has a doc on, loading them on a miss.
+	NodeDocTracks(dbc dbctx.Context, pathID uuid.UUID, nodeID uuid.UUID) ([]string, error)
+
+	// SyncPath drops the path's entries when its build stamp (outline version, build job,
+	// ready time) moved since they were cached. Call it with a freshly loaded path row.
+	SyncPath(path *types.Path)
+	// InvalidatePath drops every entry scoped to the path.
+	InvalidatePath(pathID uuid.UUID)
+
+	PathConceptsWarm(pathID uuid.UUID) bool
+	PolicySnapshotWarm(key string) bool
+	NodeDocTracksWarm(nodeID uuid.UUID) bool
+}
+
+type docServingCache struct {
+	log      *logger.Logger
+	concepts repos.ConceptRepo
+	evals    repos.PolicyEvalSnapshotRepo
+	nodeDocs repos.LearningNodeDocRepo
+
+	scopes    *ttlLRU[uuid.UUID, []*types.Concept]
+	snapshots *ttlLRU[string, *types.PolicyEvalSnapshot]
+	tracks    *ttlLRU[uuid.UUID, nodeDocTracks]
+	stamps    *ttlLRU[uuid.UUID, string]
+}
+
+type nodeDocTracks struct {
+	pathID uuid.UUID
+	tracks []string
+}
+
+func NewDocServingCache(baseLog *logger.Logger, concepts repos.ConceptRepo, evals repos.PolicyEvalSnapshotRepo, nodeDocs repos.LearningNodeDocRepo) DocServingCache {
+	ttl := time.Duration(envutil.Int("DOC_SERVING_CACHE_TTL_SECONDS", 120)) * time.Second
+	maxPaths := envutil.Int("DOC_SERVING_CACHE_MAX_PATHS", 512)
+	return &docServingCache{
+		log:       baseLog.With("service", "DocServingCache"),
+		concepts:  concepts,
+		evals:     evals,
+		nodeDocs:  nodeDocs,
+		scopes:    newTTLLRU[uuid.UUID, []*types.Concept](maxPaths, ttl),
+		snapshots: newTTLLRU[string, *types.PolicyEvalSnapshot](32, ttl),
+		tracks:    newTTLLRU[uuid.UUID, nodeDocTracks](maxPaths*2, ttl),
+		// Stamps outlive the entries they guard, so a rebuild is noticed even after a refill.
+		stamps: newTTLLRU[uuid.UUID, string](maxPaths, 0),
+	}
+}
+
+func (s *docServingCache) PathConcepts(dbc dbctx.Context, pathID uuid.UUID) ([]*types.Concept, error) {
+	if pathID == uuid.Nil || s.concepts == nil {
+		return nil, nil
+	}
+	if rows, ok := s.scopes.Get(pathID); ok {
+		return rows, nil
+	}
+	rows, err := s.concepts.GetByScope(dbc, "path", &pathID)
+	if err != nil {
+		return nil, err
+	}
+	// Inside a transaction the rows may not be committed yet; don't publish them.
+	if dbc.Tx == nil {
+		s.scopes.Set(pathID, rows)
+	}
+	return rows, nil
+}
+
+func (s *docServingCache) PolicySnapshot(dbc dbctx.Context, key string) (*types.PolicyEvalSnapshot, error) {
+	if key == "" || s.evals == nil {
+		return nil, nil
+	}
+	if snap, ok := s.snapshots.Get(key); ok {
+		return snap, nil
+	}
+	snap, err := s.evals.GetLatestByKey(dbc, key)
+	if err != nil {
+		return nil, err
+	}
+	if dbc.Tx == nil {
+		s.snapshots.Set(key, snap)
+	}
+	return snap, nil
+}
+
+func (s *docServingCache) NodeDocTracks(dbc dbctx.Context, pathID uuid.UUID, nodeID uuid.UUID) ([]string, error) {
+	if nodeID == uuid.Nil || s.nodeDocs == nil {
+		return nil, nil
+	}
+	if ent, ok := s.tracks.Get(nodeID); ok {
+		return ent.tracks, nil
+	}
+	tracks, err := s.nodeDocs.ListTracksByPathNodeID(dbc, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if dbc.Tx == nil {
+		s.tracks.Set(nodeID, nodeDocTracks{pathID: pathID, tracks: tracks})
+	}
+	return tracks, nil
+}
+
+func (s *docServingCache) SyncPath(path *types.Path) {
+	if path == nil || path.ID == uuid.Nil {
+		return
+	}
+	stamp := pathBuildStamp(path)
+	if prev, ok := s.stamps.Get(path.ID); ok && prev == stamp {
+		return
+	}
+	s.InvalidatePath(path.ID)
+	s.stamps.Set(path.ID, stamp)
+}
+
+func (s *docServingCache) InvalidatePath(pathID uuid.UUID) {
+	if pathID == uuid.Nil {
+		return
+	}
+	s.scopes.Delete(pathID)
+	s.tracks.DeleteFunc(func(_ uuid.UUID, ent nodeDocTracks) bool { return ent.pathID == pathID })
+}
+
+// pathBuildStamp changes whenever a build or doc commit touches the path: node writes and doc
+// commits bump OutlineVersion, and a rebuild runs under a new job and sets ReadyAt again.
+func pathBuildStamp(p *types.Path) string {
+	stamp := fmt.Sprintf("%d", p.OutlineVersion)
+	if p.JobID != nil {
+		stamp += "|" + p.JobID.String()
+	}
+	if p.ReadyAt != nil {
+		stamp += "|" + p.ReadyAt.UTC().Format(time.RFC3339Nano)
+	}
+	return stamp
+}
+
+func (s *docServingCache) PathConceptsWarm(pathID uuid.UUID) bool {
+	_, ok := s.scopes.Get(pathID)
+	return ok
+}
+
+func (s *docServingCache) PolicySnapshotWarm(key string) bool {
+	_, ok := s.snapshots.Get(key)
+	return ok
+}
+
+func (s *docServingCache) NodeDocTracksWarm(nodeID uuid.UUID) bool {
+	_, ok := s.tracks.Get(nodeID)
+	return ok
+}
+
+// ttlLRU is a small mutex-guarded LRU whose entries also expire after ttl (ttl <= 0 never expires).
+type ttlLRU[K comparable, V any] struct {
+	mu    sync.Mutex
+	max   int
+	ttl   time.Duration
+	order *list.List
+	items map[K]*list.Element
+	now   func() time.Time
+}
+
+type ttlLRUEntry[K comparable, V any] struct {
+	key       K
+	val       V
+	expiresAt time.Time
+}
+
+func newTTLLRU[K comparable, V any](max int, ttl time.Duration) *ttlLRU[K, V] {
+	if max <= 0 {
+		max = 1
+	}
+	return &ttlLRU[K, V]{max: max, ttl: ttl, order: list.New(), items: map[K]*list.Element{}, now: time.Now}
+}
+
+func (c *ttlLRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	ent := el.Value.(*ttlLRUEntry[K, V])
+	if c.ttl > 0 && c.now().After(ent.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return ent.val, true
+}
+
+func (c *ttlLRU[K, V]) Set(key K, val V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		ent := el.Value.(*ttlLRUEntry[K, V])
+		ent.val, ent.expiresAt = val, exp
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&ttlLRUEntry[K, V]{key: key, val: val, expiresAt: exp})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*ttlLRUEntry[K, V]).key)
+	}
+}
+
+func (c *ttlLRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// DeleteFunc drops every entry for which drop returns true.
+func (c *ttlLRU[K, V]) DeleteFunc(drop func(key K, val V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		ent := el.Value.(*ttlLRUEntry[K, V])
+		if drop(ent.key, ent.val) {
+			c.order.Remove(el)
+			delete(c.items, ent.key)
+		}
+		el = next
+	}
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// DocGenSchedClass is the job_run.sched_class of background doc generation jobs.
+const DocGenSchedClass = "docgen"
+
+// docGenSchedJobTypes are fair-scheduled. Interactive edits (node_doc_edit*) are left out: a
+// learner is waiting on them and they are one job per request anyway.
+var docGenSchedJobTypes = map[string]bool{
+	"node_doc_build":             true,
+	"node_doc_prefetch":          true,
+	"node_doc_progressive_build": true,
+	"node_doc_patch":             true,
+	"node_doc_regenerate":        true,
+	"doc_probe_select":           true,
+}
+
+// DocGenPathInflightCap bounds how many doc generation jobs (or, inline, node generations) run
+// at once for one path; 0 disables the cap.
+func DocGenPathInflightCap() int {
+	n := envutil.Int("DOCGEN_SCHED_PATH_INFLIGHT_CAP", 4)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// docGenSchedTags returns the scheduling class and path a job is tagged with at enqueue.
+func docGenSchedTags(jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (string, *uuid.UUID) {
+	if !docGenSchedJobTypes[jobType] {
+		return "", nil
+	}
+	if entityType == "path" && entityID != nil && *entityID != uuid.Nil {
+		id := *entityID
+		return DocGenSchedClass, &id
+	}
+	if s, ok := payload["path_id"].(string); ok {
+		if id, err := uuid.Parse(strings.TrimSpace(s)); err == nil && id != uuid.Nil {
+			return DocGenSchedClass, &id
+		}
+	}
+	return DocGenSchedClass, nil
+}
+
+// DocGenScheduler shares doc generation slots fairly across users. Each Tick of a docgen job
+// asks TryClaim first; a job that isn't picked stays queued and retries on its next poll.
+//
+// Users are served by weighted fair queuing: every claim advances the user's virtual time by
+// 1/weight and the free slots go to the users with the lowest virtual time, each taking its
+// oldest job whose path is under the per-path in-flight cap. A user with 60 queued jobs thus
+// gets no more slots than one with 6 while both have work.
+type DocGenScheduler interface {
+	Applies(job *types.JobRun) bool
+	// TryClaim reports whether job may run now; when it does, the job is marked running.
+	TryClaim(ctx context.Context, job *types.JobRun) (bool, error)
+	// Release returns the job's slot once its tick has finished.
+	Release(ctx context.Context, job *types.JobRun)
+	Snapshot(ctx context.Context) (*DocGenSchedulerSnapshot, error)
+}
+
+type DocGenSchedulerConfig struct {
+	Enabled bool
+	// Slots is the number of docgen jobs allowed to run at once across all workers.
+	Slots           int
+	PathInflightCap int
+	// RunningStaleAfter: running jobs without a heartbeat for this long no longer hold a slot.
+	RunningStaleAfter time.Duration
+	// QueuedLiveFor: queued jobs whose workflow hasn't polled for this long aren't planned for.
+	QueuedLiveFor time.Duration
+	HeadsPerUser  int
+}
+
+func DocGenSchedulerConfigFromEnv() DocGenSchedulerConfig {
+	cfg := DocGenSchedulerConfig{
+		Enabled:           envutil.Bool("DOCGEN_SCHED_ENABLED", true),
+		Slots:             envutil.Int("DOCGEN_SCHED_SLOTS", envutil.Int("WORKER_CONCURRENCY", 4)),
+		PathInflightCap:   DocGenPathInflightCap(),
+		RunningStaleAfter: time.Duration(envutil.Int("DOCGEN_SCHED_RUNNING_STALE_SECONDS", 120)) * time.Second,
+		QueuedLiveFor:     time.Duration(envutil.Int("DOCGEN_SCHED_QUEUED_LIVE_SECONDS", 120)) * time.Second,
+		HeadsPerUser:      envutil.Int("DOCGEN_SCHED_HEADS_PER_USER", 8),
+	}
+	if cfg.Slots <= 0 {
+		cfg.Slots = 4
+	}
+	if cfg.RunningStaleAfter <= 0 {
+		cfg.RunningStaleAfter = 2 * time.Minute
+	}
+	if cfg.QueuedLiveFor <= 0 {
+		cfg.QueuedLiveFor = 2 * time.Minute
+	}
+	if cfg.HeadsPerUser <= 0 {
+		cfg.HeadsPerUser = 8
+	}
+	return cfg
+}
+
+type DocGenSchedUser struct {
+	OwnerUserID    uuid.UUID `json:"owner_user_id"`
+	InFlight       int       `json:"in_flight"`
+	Queued         int       `json:"queued"`
+	Weight         float64   `json:"weight"`
+	VirtualTime    float64   `json:"virtual_time"`
+	ClaimedTotal   int64     `json:"claimed_total"`
+	CompletedTotal int64     `json:"completed_total"`
+}
+
+type DocGenSchedPath struct {
+	PathID   uuid.UUID `json:"path_id"`
+	InFlight int       `json:"in_flight"`
+}
+
+type DocGenSchedulerSnapshot struct {
+	Enabled         bool              `json:"enabled"`
+	Slots           int               `json:"slots"`
+	PathInflightCap int               `json:"path_inflight_cap"`
+	InFlight        int               `json:"in_flight"`
+	Queued          int               `json:"queued"`
+	Users           []DocGenSchedUser `json:"users"`
+	Paths           []DocGenSchedPath `json:"paths"`
+}
+
+type docGenScheduler struct {
+	db   *gorm.DB
+	log  *logger.Logger
+	repo repos.JobSchedulerRepo
+	cfg  DocGenSchedulerConfig
+}
+
+func NewDocGenScheduler(db *gorm.DB, baseLog *logger.Logger, repo repos.JobSchedulerRepo, cfg DocGenSchedulerConfig) DocGenScheduler {
+	return &docGenScheduler{
+		db:   db,
+		log:  baseLog.With("service", "DocGenScheduler"),
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+func (s *docGenScheduler) Applies(job *types.JobRun) bool {
+	return s != nil && s.cfg.Enabled && s.db != nil && s.repo != nil &&
+		job != nil && job.SchedClass == DocGenSchedClass && job.OwnerUserID != uuid.Nil
+}
+
+func (s *docGenScheduler) TryClaim(ctx context.Context, job *types.JobRun) (bool, error) {
+	if !s.Applies(job) {
+		return true, nil
+	}
+	now := time.Now().UTC()
+	claimed := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dbc := dbctx.Context{Ctx: ctx, Tx: tx}
+		if err := s.repo.Lock(dbc, DocGenSchedClass); err != nil {
+			return err
+		}
+		snap, err := s.repo.Snapshot(dbc, DocGenSchedClass, s.snapshotOptions(now, s.cfg.HeadsPerUser))
+		if err != nil {
+			return err
+		}
+		in := docGenPlanInputFrom(snap, s.cfg)
+		// The asking job is runnable by definition, even if it fell out of the live window.
+		in.addCandidate(docGenCandidateFrom(job))
+		picks, floor := planDocGenClaims(in)
+		for _, c := range picks {
+			if c.JobID != job.ID {
+				continue
+			}
+			claimed, err = s.repo.MarkClaimed(dbc, DocGenSchedClass, job, floor, now)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (s *docGenScheduler) Release(ctx context.Context, job *types.JobRun) {
+	if !s.Applies(job) {
+		return
+	}
+	if err := s.repo.MarkReleased(dbctx.Context{Ctx: ctx, Tx: s.db}, DocGenSchedClass, job.OwnerUserID); err != nil && s.log != nil {
+		s.log.WarnThrottled("docgen_sched.release", time.Minute, "docgen scheduler release failed", "error", err, "job_id", job.ID)
+	}
+}
+
+func (s *docGenScheduler) Snapshot(ctx context.Context) (*DocGenSchedulerSnapshot, error) {
+	if s == nil || s.repo == nil {
+		return &DocGenSchedulerSnapshot{}, nil
+	}
+	out := &DocGenSchedulerSnapshot{Enabled: s.cfg.Enabled, Slots: s.cfg.Slots, PathInflightCap: s.cfg.PathInflightCap}
+	now := time.Now().UTC()
+	snap, err := s.repo.Snapshot(dbctx.Context{Ctx: ctx, Tx: s.db}, DocGenSchedClass, s.snapshotOptions(now, 1))
+	if err != nil {
+		return nil, err
+	}
+	users := map[uuid.UUID]*DocGenSchedUser{}
+	user := func(id uuid.UUID) *DocGenSchedUser {
+		if u, ok := users[id]; ok {
+			return u
+		}
+		u := &DocGenSchedUser{OwnerUserID: id, Weight: 1}
+		users[id] = u
+		return u
+	}
+	paths := map[uuid.UUID]int{}
+	for _, r := range snap.Running {
+		user(r.OwnerUserID).InFlight += r.Count
+		out.InFlight += r.Count
+		if r.PathID != nil {
+			paths[*r.PathID] += r.Count
+		}
+	}
+	for _, d := range snap.Depths {
+		user(d.OwnerUserID).Queued += d.Count
+		out.Queued += d.Count
+	}
+	for _, st := range snap.States {
+		if st == nil {
+			continue
+		}
+		u := user(st.OwnerUserID)
+		u.Weight, u.VirtualTime = st.Weight, st.VirtualTime
+		u.ClaimedTotal, u.CompletedTotal = st.ClaimedTotal, st.CompletedTotal
+	}
+	for _, u := range users {
+		out.Users = append(out.Users, *u)
+	}
+	sort.Slice(out.Users, func(i, j int) bool {
+		if out.Users[i].InFlight+out.Users[i].Queued != out.Users[j].InFlight+out.Users[j].Queued {
+			return out.Users[i].InFlight+out.Users[i].Queued > out.Users[j].InFlight+out.Users[j].Queued
+		}
+		return out.Users[i].OwnerUserID.String() < out.Users[j].OwnerUserID.String()
+	})
+	for id, n := range paths {
+		out.Paths = append(out.Paths, DocGenSchedPath{PathID: id, InFlight: n})
+	}
+	sort.Slice(out.Paths, func(i, j int) bool { return out.Paths[i].PathID.String() < out.Paths[j].PathID.String() })
+	return out, nil
+}
+
+func (s *docGenScheduler) snapshotOptions(now time.Time, heads int) repos.JobSchedSnapshotOptions {
+	return repos.JobSchedSnapshotOptions{
+		Now:               now,
+		RunningFreshAfter: now.Add(-s.cfg.RunningStaleAfter),
+		LiveAfter:         now.Add(-s.cfg.QueuedLiveFor),
+		HeadsPerUser:      heads,
+	}
+}
+
+type docGenCandidate struct {
+	JobID     uuid.UUID
+	UserID    uuid.UUID
+	PathID    uuid.UUID // uuid.Nil: untagged, not subject to the path cap
+	CreatedAt time.Time
+}
+
+func docGenCandidateFrom(job *types.JobRun) docGenCandidate {
+	c := docGenCandidate{JobID: job.ID, UserID: job.OwnerUserID, CreatedAt: job.CreatedAt}
+	if job.SchedPathID != nil {
+		c.PathID = *job.SchedPathID
+	}
+	return c
+}
+
+type docGenPlanInput struct {
+	Slots   int
+	PathCap int
+	// Running is the fresh running count; RunningByPath and RunningByUser break it down.
+	Running       int
+	RunningByPath map[uuid.UUID]int
+	RunningByUser map[uuid.UUID]int
+	// Heads holds each user's runnable jobs, oldest first.
+	Heads       map[uuid.UUID][]docGenCandidate
+	VirtualTime map[uuid.UUID]float64
+	Weight      map[uuid.UUID]float64
+}
+
+func docGenPlanInputFrom(snap *repos.JobSchedSnapshot, cfg DocGenSchedulerConfig) *docGenPlanInput {
+	in := &docGenPlanInput{
+		Slots:         cfg.Slots,
+		PathCap:       cfg.PathInflightCap,
+		RunningByPath: map[uuid.UUID]int{},
+		RunningByUser: map[uuid.UUID]int{},
+		Heads:         map[uuid.UUID][]docGenCandidate{},
+		VirtualTime:   map[uuid.UUID]float64{},
+		Weight:        map[uuid.UUID]float64{},
+	}
+	if snap == nil {
+		return in
+	}
+	for _, r := range snap.Running {
+		in.Running += r.Count
+		in.RunningByUser[r.OwnerUserID] += r.Count
+		if r.PathID != nil {
+			in.RunningByPath[*r.PathID] += r.Count
+		}
+	}
+	for _, j := range snap.Heads {
+		if j != nil {
+			in.addCandidate(docGenCandidateFrom(j))
+		}
+	}
+	for _, st := range snap.States {
+		if st == nil {
+			continue
+		}
+		in.VirtualTime[st.OwnerUserID] = st.VirtualTime
+		in.Weight[st.OwnerUserID] = st.Weight
+	}
+	return in
+}
+
+func (in *docGenPlanInput) addCandidate(c docGenCandidate) {
+	list := in.Heads[c.UserID]
+	for _, x := range list {
+		if x.JobID == c.JobID {
+			return
+		}
+	}
+	list = append(list, c)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	in.Heads[c.UserID] = list
+}
+
+// planDocGenClaims picks the jobs that should hold the free slots right now, in claim order,
+// and returns the virtual time floor new or returning users start from (so an idle user can't
+// bank credit and then monopolize the slots).
+func planDocGenClaims(in *docGenPlanInput) ([]docGenCandidate, float64) {
+	users := make([]uuid.UUID, 0, len(in.Heads))
+	for u, list := range in.Heads {
+		if len(list) > 0 {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].String() < users[j].String() })
+
+	floor, haveFloor := 0.0, false
+	for u, n := range in.RunningByUser {
+		if vt, ok := in.VirtualTime[u]; ok && n > 0 && (!haveFloor || vt < floor) {
+			floor, haveFloor = vt, true
+		}
+	}
+	if !haveFloor {
+		for _, u := range users {
+			if vt, ok := in.VirtualTime[u]; ok && (!haveFloor || vt < floor) {
+				floor, haveFloor = vt, true
+			}
+		}
+	}
+
+	vt := map[uuid.UUID]float64{}
+	heads := map[uuid.UUID][]docGenCandidate{}
+	for _, u := range users {
+		v, ok := in.VirtualTime[u]
+		if !ok || v < floor {
+			v = floor
+		}
+		vt[u] = v
+		heads[u] = append([]docGenCandidate(nil), in.Heads[u]...)
+	}
+	pathCount := map[uuid.UUID]int{}
+	for p, n := range in.RunningByPath {
+		pathCount[p] = n
+	}
+	underCap := func(c docGenCandidate) bool {
+		return in.PathCap <= 0 || c.PathID == uuid.Nil || pathCount[c.PathID] < in.PathCap
+	}
+
+	var picks []docGenCandidate
+	for free := in.Slots - in.Running; free > 0; free-- {
+		bestUser, bestIdx := uuid.Nil, -1
+		for _, u := range users {
+			idx := -1
+			for i, c := range heads[u] {
+				if underCap(c) {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				continue
+			}
+			if bestIdx < 0 || vt[u] < vt[bestUser] ||
+				(vt[u] == vt[bestUser] && heads[u][idx].CreatedAt.Before(heads[bestUser][bestIdx].CreatedAt)) {
+				bestUser, bestIdx = u, idx
+			}
+		}
+		if bestIdx < 0 {
+			break
+		}
+		c := heads[bestUser][bestIdx]
+		heads[bestUser] = append(heads[bestUser][:bestIdx:bestIdx], heads[bestUser][bestIdx+1:]...)
+		picks = append(picks, c)
+		if c.PathID != uuid.Nil {
+			pathCount[c.PathID]++
+		}
+		w := in.Weight[bestUser]
+		if w <= 0 {
+			w = 1
+		}
+		vt[bestUser] += 1 / w
+	}
+	return picks, floor
+}
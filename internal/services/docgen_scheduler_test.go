@@ -0,0 +1,163 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// docGenSim replays the scheduler round by round: every round the free slots are planned and
+// claimed (charging virtual time the way MarkClaimed does), then every claimed job finishes.
+type docGenSim struct {
+	t       *testing.T
+	slots   int
+	pathCap int
+	queued  map[uuid.UUID][]docGenCandidate
+	vt      map[uuid.UUID]float64
+}
+
+func newDocGenSim(t *testing.T, slots, pathCap int) *docGenSim {
+	return &docGenSim{t: t, slots: slots, pathCap: pathCap, queued: map[uuid.UUID][]docGenCandidate{}, vt: map[uuid.UUID]float64{}}
+}
+
+func (s *docGenSim) enqueue(user, path uuid.UUID, n int, at time.Time) {
+	for i := 0; i < n; i++ {
+		s.queued[user] = append(s.queued[user], docGenCandidate{
+			JobID:     uuid.New(),
+			UserID:    user,
+			PathID:    path,
+			CreatedAt: at.Add(time.Duration(i) * time.Millisecond),
+		})
+	}
+}
+
+// round claims and completes one batch and returns the jobs claimed per user.
+func (s *docGenSim) round() map[uuid.UUID]int {
+	in := &docGenPlanInput{
+		Slots:         s.slots,
+		PathCap:       s.pathCap,
+		RunningByPath: map[uuid.UUID]int{},
+		RunningByUser: map[uuid.UUID]int{},
+		Heads:         map[uuid.UUID][]docGenCandidate{},
+		VirtualTime:   map[uuid.UUID]float64{},
+		Weight:        map[uuid.UUID]float64{},
+	}
+	for u, list := range s.queued {
+		for i, c := range list {
+			if i >= 8 {
+				break
+			}
+			in.addCandidate(c)
+		}
+		if v, ok := s.vt[u]; ok {
+			in.VirtualTime[u] = v
+		}
+	}
+	picks, floor := planDocGenClaims(in)
+	if len(picks) > s.slots {
+		s.t.Fatalf("planned %d claims for %d slots", len(picks), s.slots)
+	}
+	perPath := map[uuid.UUID]int{}
+	claimed := map[uuid.UUID]int{}
+	for _, c := range picks {
+		perPath[c.PathID]++
+		if s.pathCap > 0 && perPath[c.PathID] > s.pathCap {
+			s.t.Fatalf("path %s has %d in flight, cap %d", c.PathID, perPath[c.PathID], s.pathCap)
+		}
+		v := s.vt[c.UserID]
+		if v < floor {
+			v = floor
+		}
+		s.vt[c.UserID] = v + 1
+		claimed[c.UserID]++
+		list := s.queued[c.UserID]
+		for i := range list {
+			if list[i].JobID == c.JobID {
+				s.queued[c.UserID] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+	}
+	return claimed
+}
+
+func TestDocGenSchedulerSmallUserNotStarved(t *testing.T) {
+	heavy, light := uuid.New(), uuid.New()
+	now := time.Now()
+	sim := newDocGenSim(t, 4, 4)
+	// The heavy user's 60 jobs are all older than the light user's 6.
+	sim.enqueue(heavy, uuid.New(), 60, now.Add(-time.Minute))
+	sim.enqueue(light, uuid.New(), 6, now)
+
+	rounds := 0
+	for len(sim.queued[light]) > 0 {
+		rounds++
+		if rounds > 4 {
+			t.Fatalf("light user still has %d jobs queued after %d rounds", len(sim.queued[light]), rounds-1)
+		}
+		claimed := sim.round()
+		if claimed[heavy] == 0 {
+			t.Fatalf("round %d: heavy user made no progress", rounds)
+		}
+	}
+	if rounds != 3 {
+		t.Fatalf("light user finished in %d rounds, want 3 (two slots per round)", rounds)
+	}
+	if got := len(sim.queued[heavy]); got != 54 {
+		t.Fatalf("heavy user has %d jobs left, want 54", got)
+	}
+
+	// Alone again, the heavy user gets every slot.
+	if claimed := sim.round(); claimed[heavy] != 4 {
+		t.Fatalf("heavy user alone claimed %d slots, want 4", claimed[heavy])
+	}
+}
+
+func TestDocGenSchedulerPathCap(t *testing.T) {
+	user := uuid.New()
+	pathA, pathB := uuid.New(), uuid.New()
+	now := time.Now()
+	sim := newDocGenSim(t, 4, 2)
+	sim.enqueue(user, pathA, 6, now.Add(-time.Minute))
+	sim.enqueue(user, pathB, 1, now)
+
+	// Path A is capped at 2, so the user's younger path B job takes a slot alongside.
+	if claimed := sim.round(); claimed[user] != 3 {
+		t.Fatalf("claimed %d, want 3 (2 on path A, 1 on path B)", claimed[user])
+	}
+	// Only path A is left: the cap leaves the other slots idle.
+	if claimed := sim.round(); claimed[user] != 2 {
+		t.Fatalf("claimed %d, want 2", claimed[user])
+	}
+}
+
+func TestDocGenSchedulerReturningUserGetsNoBankedCredit(t *testing.T) {
+	busy, idle := uuid.New(), uuid.New()
+	now := time.Now()
+	in := &docGenPlanInput{
+		Slots:         4,
+		Running:       2,
+		RunningByPath: map[uuid.UUID]int{},
+		RunningByUser: map[uuid.UUID]int{busy: 2},
+		Heads:         map[uuid.UUID][]docGenCandidate{},
+		VirtualTime:   map[uuid.UUID]float64{busy: 100, idle: 3},
+		Weight:        map[uuid.UUID]float64{},
+	}
+	for i := 0; i < 4; i++ {
+		in.addCandidate(docGenCandidate{JobID: uuid.New(), UserID: busy, CreatedAt: now.Add(time.Duration(i) * time.Millisecond)})
+		in.addCandidate(docGenCandidate{JobID: uuid.New(), UserID: idle, CreatedAt: now.Add(time.Second + time.Duration(i)*time.Millisecond)})
+	}
+	picks, floor := planDocGenClaims(in)
+	if floor != 100 {
+		t.Fatalf("floor = %v, want 100", floor)
+	}
+	per := map[uuid.UUID]int{}
+	for _, c := range picks {
+		per[c.UserID]++
+	}
+	// Without the floor the idle user would take both free slots on its old virtual time.
+	if per[busy] != 1 || per[idle] != 1 {
+		t.Fatalf("picks per user = %v, want one each", per)
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 type JobService interface {
 	Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error)
+	EnqueueDeferred(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any, notBefore time.Time) (*types.JobRun, error)
 	Dispatch(dbc dbctx.Context, jobID uuid.UUID) error
 	SignalResume(dbc dbctx.Context, jobID uuid.UUID) error
 	EnqueueDebouncedUserModelUpdate(dbc dbctx.Context, userID uuid.UUID) (*types.JobRun, bool, error)
@@ -34,6 +35,7 @@ type JobService interface {
 	EnqueueNodeDocPrefetchIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, pathID uuid.UUID, materialSetID uuid.UUID, trigger string) (*types.JobRun, bool, error)
 	EnqueueNodeDocProgressiveBuildIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, pathID uuid.UUID, materialSetID uuid.UUID, anchorNodeID uuid.UUID, trigger string) (*types.JobRun, bool, error)
 	EnqueueDocProbeSelectIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, pathID uuid.UUID, materialSetID uuid.UUID, anchorNodeID uuid.UUID, trigger string) (*types.JobRun, bool, error)
+	EnqueuePrereqGatePrefetchIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, pathID uuid.UUID, trigger string) (*types.JobRun, bool, error)
 	EnqueueProgressionCompactIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, materialSetID uuid.UUID, trigger string) (*types.JobRun, bool, error)
 	EnqueueVariantStatsRefreshIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, materialSetID uuid.UUID, trigger string) (*types.JobRun, bool, error)
 	EnqueueDocVariantEvalIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, materialSetID uuid.UUID, trigger string) (*types.JobRun, bool, error)
@@ -47,6 +49,14 @@ type JobService interface {
 	GetLatestForEntityForRequestUser(dbc dbctx.Context, entityType string, entityID uuid.UUID, jobType string) (*types.JobRun, error)
 	CancelForRequestUser(dbc dbctx.Context, jobID uuid.UUID) (*types.JobRun, error)
 	RestartForRequestUser(dbc dbctx.Context, jobID uuid.UUID) (*types.JobRun, error)
+	ListForRequestUser(dbc dbctx.Context, filter repos.JobRunListFilter) ([]JobHistoryEntry, error)
+}
+
+// JobHistoryEntry pairs a job with the raw message of its latest failure (failed jobs only).
+// Callers must sanitize LastError before exposing it.
+type JobHistoryEntry struct {
+	Job       *types.JobRun
+	LastError string
 }
 
 type jobService struct {
@@ -78,6 +88,20 @@ func NewJobService(
 }
 
 func (s *jobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any) (*types.JobRun, error) {
+	return s.enqueue(dbc, ownerUserID, jobType, entityType, entityID, payload, nil)
+}
+
+// EnqueueDeferred enqueues a job that workers leave alone until notBefore, used to accept work
+// while the LLM provider is throttling us. The job is dispatched right away; the run loop waits.
+func (s *jobService) EnqueueDeferred(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any, notBefore time.Time) (*types.JobRun, error) {
+	if notBefore.IsZero() {
+		return s.enqueue(dbc, ownerUserID, jobType, entityType, entityID, payload, nil)
+	}
+	nb := notBefore.UTC()
+	return s.enqueue(dbc, ownerUserID, jobType, entityType, entityID, payload, &nb)
+}
+
+func (s *jobService) enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType string, entityType string, entityID *uuid.UUID, payload map[string]any, notBefore *time.Time) (*types.JobRun, error) {
 	if ownerUserID == uuid.Nil {
 		return nil, fmt.Errorf("missing owner_user_id")
 	}
@@ -90,18 +114,6 @@ func (s *jobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType s
 	if payload == nil {
 		payload = map[string]any{}
 	}
-	if td := ctxutil.GetTraceData(dbc.Ctx); td != nil {
-		if td.TraceID != "" {
-			if _, ok := payload["trace_id"]; !ok {
-				payload["trace_id"] = td.TraceID
-			}
-		}
-		if td.RequestID != "" {
-			if _, ok := payload["request_id"]; !ok {
-				payload["request_id"] = td.RequestID
-			}
-		}
-	}
 	transaction := dbc.Tx
 	if transaction == nil {
 		transaction = s.db
@@ -125,16 +137,26 @@ func (s *jobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType s
 		Progress:    0,
 		Attempts:    0,
 		Message:     "Queued",
+		NotBefore:   notBefore,
 		Payload:     payloadJSON,
 		Result:      datatypes.JSON([]byte(`{}`)),
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	job.SchedClass, job.SchedPathID = docGenSchedTags(jobType, entityType, entityID, payload)
+	applyJobTraceEnvelope(job, ctxutil.GetTraceData(dbc.Ctx))
+	if notBefore != nil {
+		job.Stage = "deferred"
+		job.Message = "Waiting for model capacity"
+	}
 	if _, err := s.repo.Create(dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}, []*types.JobRun{job}); err != nil {
 		return nil, fmt.Errorf("create job: %w", err)
 	}
 	// Notify immediately (request-time)
 	s.notify.JobCreated(ownerUserID, job)
+	if notBefore != nil {
+		s.notify.JobDeferred(ownerUserID, job)
+	}
 
 	// Important: if we're inside a *real* DB transaction, do NOT start Temporal yet.
 	// Callers must invoke Dispatch() after the transaction commits.
@@ -152,6 +174,23 @@ func (s *jobService) Enqueue(dbc dbctx.Context, ownerUserID uuid.UUID, jobType s
 	return job, nil
 }
 
+// applyJobTraceEnvelope records the trace the job is enqueued under. A job enqueued while
+// another job runs continues that job's chain one hop deeper.
+func applyJobTraceEnvelope(job *types.JobRun, td *ctxutil.TraceData) {
+	if job == nil || td == nil {
+		return
+	}
+	job.TraceID = strings.TrimSpace(td.TraceID)
+	job.RequestID = strings.TrimSpace(td.RequestID)
+	if td.JobID != uuid.Nil {
+		parent := td.JobID
+		job.ParentJobID = &parent
+		job.TraceDepth = td.Depth + 1
+	} else {
+		job.TraceDepth = 1
+	}
+}
+
 type txCommitter interface {
 	Commit() error
 	Rollback() error
@@ -432,6 +471,43 @@ func (s *jobService) EnqueueDocProbeSelectIfNeeded(dbc dbctx.Context, ownerUserI
 	return job, true, nil
 }
 
+// EnqueuePrereqGatePrefetchIfNeeded queues a prereq gate prefetch for one path, or for all of
+// the user's active paths when pathID is nil. A runnable job for the same scope dedupes it.
+func (s *jobService) EnqueuePrereqGatePrefetchIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, pathID uuid.UUID, trigger string) (*types.JobRun, bool, error) {
+	if ownerUserID == uuid.Nil {
+		return nil, false, fmt.Errorf("missing owner_user_id")
+	}
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = s.db
+	}
+
+	entityType := "user"
+	entityID := ownerUserID
+	payload := map[string]any{
+		"trigger": trigger,
+	}
+	if pathID != uuid.Nil {
+		entityType = "path"
+		entityID = pathID
+		payload["path_id"] = pathID.String()
+	}
+	repoCtx := dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}
+	exists, err := s.repo.ExistsRunnable(repoCtx, ownerUserID, "prereq_gate_prefetch", entityType, &entityID)
+	if err != nil {
+		return nil, false, err
+	}
+	if exists {
+		return nil, false, nil
+	}
+
+	job, err := s.Enqueue(repoCtx, ownerUserID, "prereq_gate_prefetch", entityType, &entityID, payload)
+	if err != nil {
+		return nil, false, err
+	}
+	return job, true, nil
+}
+
 func (s *jobService) EnqueueProgressionCompactIfNeeded(dbc dbctx.Context, ownerUserID uuid.UUID, materialSetID uuid.UUID, trigger string) (*types.JobRun, bool, error) {
 	if ownerUserID == uuid.Nil {
 		return nil, false, fmt.Errorf("missing owner_user_id")
@@ -799,6 +875,52 @@ func (s *jobService) GetLatestForEntityForRequestUser(dbc dbctx.Context, entityT
 	return s.repo.GetLatestByEntity(dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}, rd.UserID, entityType, entityID, jobType)
 }
 
+func (s *jobService) ListForRequestUser(dbc dbctx.Context, filter repos.JobRunListFilter) ([]JobHistoryEntry, error) {
+	rd := ctxutil.GetRequestData(dbc.Ctx)
+	if rd == nil || rd.UserID == uuid.Nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	transaction := dbc.Tx
+	if transaction == nil {
+		transaction = s.db
+	}
+	repoCtx := dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}
+
+	// Always scope to the caller, whatever the filter says.
+	filter.OwnerUserID = rd.UserID
+	rows, err := s.repo.ListForOwner(repoCtx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	failedIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if row != nil && row.Status == "failed" {
+			failedIDs = append(failedIDs, row.ID)
+		}
+	}
+	failures, err := s.repo.LatestFailureMessages(repoCtx, failedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]JobHistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		entry := JobHistoryEntry{Job: row}
+		if row.Status == "failed" {
+			entry.LastError = strings.TrimSpace(failures[row.ID])
+			if entry.LastError == "" {
+				entry.LastError = strings.TrimSpace(row.Error)
+			}
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
 func (s *jobService) CancelForRequestUser(dbc dbctx.Context, jobID uuid.UUID) (*types.JobRun, error) {
 	rd := ctxutil.GetRequestData(dbc.Ctx)
 	if rd == nil || rd.UserID == uuid.Nil {
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/realtime"
+)
+
+const (
+	NotificationKindPathBuildCompleted   = "path_build_completed"
+	NotificationKindPathBuildFailed      = "path_build_failed"
+	NotificationKindDocPatchApplied      = "doc_patch_applied"
+	NotificationKindDocVariantRolledBack = "doc_variant_rolled_back"
+	NotificationKindPrereqUnblocked      = "prereq_unblocked"
+)
+
+type NotificationInput struct {
+	UserID   uuid.UUID
+	Kind     string
+	Title    string
+	Body     string
+	LinkType string
+	LinkID   *uuid.UUID
+	// DedupKey collapses repeats of one event; empty means every call is distinct.
+	DedupKey string
+	Metadata map[string]any
+}
+
+type NotificationPage struct {
+	Notifications []*types.UserNotification
+	// NextCursor is nil on the last page.
+	NextCursor *repos.UserNotificationCursor
+	Unread     int64
+}
+
+type NotificationService interface {
+	// Notify records a notification and pushes it to the user's SSE channel. It returns nil
+	// (and no error) when the dedup key was already used or the per-kind daily cap is reached.
+	Notify(dbc dbctx.Context, in NotificationInput) (*types.UserNotification, error)
+	List(dbc dbctx.Context, userID uuid.UUID, before *repos.UserNotificationCursor, limit int, unreadOnly bool) (NotificationPage, error)
+	MarkRead(dbc dbctx.Context, userID, id uuid.UUID) (found bool, err error)
+	MarkAllRead(dbc dbctx.Context, userID uuid.UUID) (int64, error)
+}
+
+type notificationService struct {
+	log   *logger.Logger
+	repo  repos.UserNotificationRepo
+	emit  SSEEmitter
+	now   func() time.Time
+	daily int
+}
+
+func NewNotificationService(log *logger.Logger, repo repos.UserNotificationRepo, emit SSEEmitter) NotificationService {
+	return &notificationService{
+		log:   log.With("service", "NotificationService"),
+		repo:  repo,
+		emit:  emit,
+		now:   func() time.Time { return time.Now().UTC() },
+		daily: envutil.Int("NOTIFICATION_DAILY_CAP_PER_KIND", 20),
+	}
+}
+
+func (s *notificationService) Notify(dbc dbctx.Context, in NotificationInput) (*types.UserNotification, error) {
+	in.Kind = strings.TrimSpace(in.Kind)
+	if s == nil || s.repo == nil || in.UserID == uuid.Nil || in.Kind == "" {
+		return nil, nil
+	}
+	now := s.now()
+	if s.daily > 0 {
+		n, err := s.repo.CountByKindSince(dbc, in.UserID, in.Kind, now.Add(-24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		if n >= int64(s.daily) {
+			s.log.Debug("Notification capped", "user_id", in.UserID, "kind", in.Kind, "count", n)
+			return nil, nil
+		}
+	}
+
+	row := &types.UserNotification{
+		ID:        uuid.New(),
+		UserID:    in.UserID,
+		Kind:      in.Kind,
+		Title:     strings.TrimSpace(in.Title),
+		Body:      strings.TrimSpace(in.Body),
+		LinkType:  strings.TrimSpace(in.LinkType),
+		LinkID:    in.LinkID,
+		DedupKey:  strings.TrimSpace(in.DedupKey),
+		CreatedAt: now,
+	}
+	if len(in.Metadata) > 0 {
+		if b, err := json.Marshal(in.Metadata); err == nil {
+			row.Metadata = datatypes.JSON(b)
+		}
+	}
+	created, err := s.repo.Create(dbc, row)
+	if err != nil || !created {
+		return nil, err
+	}
+
+	if s.emit != nil {
+		data := map[string]any{"notification": row}
+		if unread, err := s.repo.CountUnread(dbc, in.UserID); err == nil {
+			data["unread"] = unread
+		}
+		ctx := dbc.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		s.emit.Emit(ctx, realtime.SSEMessage{
+			Channel: in.UserID.String(),
+			Event:   realtime.SSEEventNotificationCreated,
+			Data:    data,
+		})
+	}
+	return row, nil
+}
+
+func (s *notificationService) List(dbc dbctx.Context, userID uuid.UUID, before *repos.UserNotificationCursor, limit int, unreadOnly bool) (NotificationPage, error) {
+	page := NotificationPage{Notifications: []*types.UserNotification{}}
+	if userID == uuid.Nil {
+		return page, nil
+	}
+	if limit <= 0 {
+		limit = repos.UserNotificationListDefaultLimit
+	}
+	if limit > repos.UserNotificationListMaxLimit {
+		limit = repos.UserNotificationListMaxLimit
+	}
+	// Fetch one extra row to learn whether another page exists.
+	rows, err := s.repo.ListByUser(dbc, userID, before, limit+1, unreadOnly)
+	if err != nil {
+		return page, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = &repos.UserNotificationCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	page.Notifications = rows
+	page.Unread, err = s.repo.CountUnread(dbc, userID)
+	return page, err
+}
+
+func (s *notificationService) MarkRead(dbc dbctx.Context, userID, id uuid.UUID) (bool, error) {
+	return s.repo.MarkRead(dbc, userID, id, s.now())
+}
+
+func (s *notificationService) MarkAllRead(dbc dbctx.Context, userID uuid.UUID) (int64, error) {
+	return s.repo.MarkAllRead(dbc, userID, s.now())
+}
+
+// =========================
+// Job notifications
+// =========================
+
+// notifyingJobNotifier records inbox notifications for terminal path builds and doc patches
+// on top of the realtime job events emitted by the wrapped notifier.
+type notifyingJobNotifier struct {
+	JobNotifier
+	log   *logger.Logger
+	notes NotificationService
+}
+
+// WithJobNotifications wraps a JobNotifier so finished path builds and applied doc patches
+// also land in the user's notification inbox. Terminal events may be emitted more than once
+// per job (worker + replayed activity); the per-job dedup key keeps one row.
+func WithJobNotifications(base JobNotifier, log *logger.Logger, notes NotificationService) JobNotifier {
+	if notes == nil {
+		return base
+	}
+	return &notifyingJobNotifier{JobNotifier: base, log: log.With("service", "JobNotifications"), notes: notes}
+}
+
+func (n *notifyingJobNotifier) JobDone(userID uuid.UUID, job *types.JobRun) {
+	if n.JobNotifier != nil {
+		n.JobNotifier.JobDone(userID, job)
+	}
+	switch safeJobType(job) {
+	case "learning_build", "learning_build_progressive":
+		n.record(userID, job, NotificationKindPathBuildCompleted, "Your path is ready", "")
+	case "node_doc_patch":
+		n.record(userID, job, NotificationKindDocPatchApplied, "Your lesson edit was applied", "")
+	}
+}
+
+func (n *notifyingJobNotifier) JobFailed(userID uuid.UUID, job *types.JobRun, stage string, errorMessage string) {
+	if n.JobNotifier != nil {
+		n.JobNotifier.JobFailed(userID, job, stage, errorMessage)
+	}
+	switch safeJobType(job) {
+	case "learning_build", "learning_build_progressive":
+		n.record(userID, job, NotificationKindPathBuildFailed, "Your path could not be built", "Open the path to retry the build.")
+	}
+}
+
+func (n *notifyingJobNotifier) record(userID uuid.UUID, job *types.JobRun, kind, title, body string) {
+	if userID == uuid.Nil || job == nil || job.ID == uuid.Nil {
+		return
+	}
+	in := NotificationInput{
+		UserID:   userID,
+		Kind:     kind,
+		Title:    title,
+		Body:     body,
+		DedupKey: "job:" + job.ID.String(),
+		Metadata: map[string]any{"job_id": job.ID.String(), "job_type": job.JobType},
+	}
+	link := jobLinkData(job)
+	for k, v := range link {
+		in.Metadata[k] = v
+	}
+	switch {
+	case job.EntityType == "path_node" && job.EntityID != nil:
+		in.LinkType, in.LinkID = "path_node", job.EntityID
+	case job.EntityType == "path" && job.EntityID != nil:
+		in.LinkType, in.LinkID = "path", job.EntityID
+	default:
+		if id, err := uuid.Parse(fmt.Sprint(link["path_id"])); err == nil && id != uuid.Nil {
+			in.LinkType, in.LinkID = "path", &id
+		}
+	}
+	if _, err := n.notes.Notify(dbctx.Context{Ctx: context.Background()}, in); err != nil {
+		n.log.Warn("Failed to record job notification", "error", err, "job_id", job.ID, "kind", kind)
+	}
+}
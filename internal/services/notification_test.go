@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+	"github.com/yungbote/neurobridge-backend/internal/realtime"
+)
+
+// memNotificationRepo keeps rows in memory with the same dedup and read semantics as the DB repo.
+type memNotificationRepo struct {
+	repos.UserNotificationRepo
+	rows []*types.UserNotification
+}
+
+func (r *memNotificationRepo) Create(dbc dbctx.Context, row *types.UserNotification) (bool, error) {
+	for _, existing := range r.rows {
+		if existing.UserID == row.UserID && existing.DedupKey == row.DedupKey && row.DedupKey != "" {
+			return false, nil
+		}
+	}
+	r.rows = append(r.rows, row)
+	return true, nil
+}
+
+func (r *memNotificationRepo) CountByKindSince(dbc dbctx.Context, userID uuid.UUID, kind string, since time.Time) (int64, error) {
+	var n int64
+	for _, row := range r.rows {
+		if row.UserID == userID && row.Kind == kind && !row.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memNotificationRepo) CountUnread(dbc dbctx.Context, userID uuid.UUID) (int64, error) {
+	var n int64
+	for _, row := range r.rows {
+		if row.UserID == userID && row.ReadAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type recordingEmitter struct{ msgs []realtime.SSEMessage }
+
+func (e *recordingEmitter) Emit(ctx context.Context, msg realtime.SSEMessage) {
+	e.msgs = append(e.msgs, msg)
+}
+
+type nopJobNotifier struct{ JobNotifier }
+
+func (nopJobNotifier) JobDone(userID uuid.UUID, job *types.JobRun)                               {}
+func (nopJobNotifier) JobFailed(userID uuid.UUID, job *types.JobRun, stage, errorMessage string) {}
+
+func TestNotificationServiceDedupAndDailyCap(t *testing.T) {
+	t.Setenv("NOTIFICATION_DAILY_CAP_PER_KIND", "2")
+	log, err := logger.New("test")
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	repo := &memNotificationRepo{}
+	emit := &recordingEmitter{}
+	svc := NewNotificationService(log, repo, emit).(*notificationService)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	dbc := dbctx.Context{Ctx: context.Background()}
+	userID := uuid.New()
+	notify := func(kind, key string) *types.UserNotification {
+		t.Helper()
+		n, err := svc.Notify(dbc, NotificationInput{UserID: userID, Kind: kind, Title: "t", DedupKey: key})
+		if err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+		return n
+	}
+
+	if notify(NotificationKindDocPatchApplied, "job:1") == nil {
+		t.Fatalf("first notification should be recorded")
+	}
+	if notify(NotificationKindDocPatchApplied, "job:1") != nil {
+		t.Fatalf("repeat dedup key should be dropped")
+	}
+	if notify(NotificationKindDocPatchApplied, "job:2") == nil {
+		t.Fatalf("second distinct notification should be recorded")
+	}
+	if notify(NotificationKindDocPatchApplied, "job:3") != nil {
+		t.Fatalf("third notification of a kind within a day should be capped")
+	}
+	if notify(NotificationKindPathBuildCompleted, "job:4") == nil {
+		t.Fatalf("cap is per kind")
+	}
+
+	now = now.Add(25 * time.Hour)
+	if notify(NotificationKindDocPatchApplied, "job:3") == nil {
+		t.Fatalf("cap should reset after a day")
+	}
+
+	if len(repo.rows) != 4 || len(emit.msgs) != 4 {
+		t.Fatalf("rows=%d emitted=%d, want 4 each", len(repo.rows), len(emit.msgs))
+	}
+	last := emit.msgs[len(emit.msgs)-1]
+	if last.Event != realtime.SSEEventNotificationCreated || last.Channel != userID.String() || last.Data.(map[string]any)["unread"] != int64(4) {
+		t.Fatalf("unexpected SSE message: %+v", last)
+	}
+}
+
+func TestJobNotificationsOnePerJob(t *testing.T) {
+	log, err := logger.New("test")
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	repo := &memNotificationRepo{}
+	notes := NewNotificationService(log, repo, nil)
+	jn := WithJobNotifications(nopJobNotifier{}, log, notes)
+
+	userID := uuid.New()
+	pathID := uuid.New()
+	build := &types.JobRun{ID: uuid.New(), JobType: "learning_build", EntityType: "path", EntityID: &pathID}
+	// Terminal events can be replayed by the activity; only one notification per job.
+	jn.JobDone(userID, build)
+	jn.JobDone(userID, build)
+	jn.JobDone(userID, &types.JobRun{ID: uuid.New(), JobType: "chat_respond"})
+	failed := &types.JobRun{ID: uuid.New(), JobType: "learning_build_progressive", EntityType: "path", EntityID: &pathID}
+	jn.JobFailed(userID, failed, "build", "boom")
+
+	if len(repo.rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(repo.rows))
+	}
+	if got := repo.rows[0]; got.Kind != NotificationKindPathBuildCompleted || got.LinkType != "path" || got.LinkID == nil || *got.LinkID != pathID {
+		t.Fatalf("build notification = %+v", got)
+	}
+	if got := repo.rows[1]; got.Kind != NotificationKindPathBuildFailed || got.DedupKey != "job:"+failed.ID.String() {
+		t.Fatalf("failure notification = %+v", got)
+	}
+}
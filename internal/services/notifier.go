@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 
 	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
 	"github.com/yungbote/neurobridge-backend/internal/realtime"
 )
 
@@ -23,6 +24,7 @@ type JobNotifier interface {
 	JobDone(userID uuid.UUID, job *types.JobRun)
 	JobCanceled(userID uuid.UUID, job *types.JobRun)
 	JobRestarted(userID uuid.UUID, job *types.JobRun)
+	JobDeferred(userID uuid.UUID, job *types.JobRun)
 }
 
 type jobNotifier struct {
@@ -41,7 +43,7 @@ func (n *jobNotifier) JobCreated(userID uuid.UUID, job *types.JobRun) {
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobCreated,
 		Data:    data,
@@ -63,7 +65,7 @@ func (n *jobNotifier) JobProgress(userID uuid.UUID, job *types.JobRun, stage str
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobProgress,
 		Data:    data,
@@ -84,7 +86,7 @@ func (n *jobNotifier) JobFailed(userID uuid.UUID, job *types.JobRun, stage strin
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobFailed,
 		Data:    data,
@@ -103,7 +105,7 @@ func (n *jobNotifier) JobDone(userID uuid.UUID, job *types.JobRun) {
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobDone,
 		Data:    data,
@@ -122,7 +124,7 @@ func (n *jobNotifier) JobCanceled(userID uuid.UUID, job *types.JobRun) {
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobCanceled,
 		Data:    data,
@@ -141,17 +143,57 @@ func (n *jobNotifier) JobRestarted(userID uuid.UUID, job *types.JobRun) {
 	for k, v := range jobLinkData(job) {
 		data[k] = v
 	}
-	n.emit.Emit(context.Background(), realtime.SSEMessage{
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
 		Channel: userID.String(),
 		Event:   realtime.SSEEventJobRestarted,
 		Data:    data,
 	})
 }
 
+// JobDeferred tells the client a queued job waits until job.NotBefore (e.g. LLM provider
+// throttling), so the UI can explain the delay.
+func (n *jobNotifier) JobDeferred(userID uuid.UUID, job *types.JobRun) {
+	if n == nil || n.emit == nil || userID == uuid.Nil {
+		return
+	}
+	data := map[string]any{
+		"job_id":   safeJobID(job),
+		"job_type": safeJobType(job),
+		"job":      job,
+	}
+	if job != nil && job.NotBefore != nil {
+		data["not_before"] = job.NotBefore.UTC()
+	}
+	for k, v := range jobLinkData(job) {
+		data[k] = v
+	}
+	n.emit.Emit(jobTraceContext(job), realtime.SSEMessage{
+		Channel: userID.String(),
+		Event:   realtime.SSEEventJobDeferred,
+		Data:    data,
+	})
+}
+
 // =========================
 // helpers
 // =========================
 
+// jobTraceContext carries the job's trace envelope so its events link back to the request that
+// started the chain.
+func jobTraceContext(job *types.JobRun) context.Context {
+	ctx := context.Background()
+	if job == nil || (job.TraceID == "" && job.RequestID == "") {
+		return ctx
+	}
+	return ctxutil.WithTraceData(ctx, &ctxutil.TraceData{
+		TraceID:   job.TraceID,
+		RequestID: job.RequestID,
+		SpanID:    job.ID.String(),
+		JobID:     job.ID,
+		Depth:     job.TraceDepth,
+	})
+}
+
 func safeJobID(job *types.JobRun) uuid.UUID {
 	if job == nil {
 		return uuid.Nil
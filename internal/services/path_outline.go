@@ -0,0 +1,232 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// PathOutlineService is the shared read model for a path's ordered nodes. Outlines are cached
+// in-process per path and revalidated on every read against path.outline_version, which node
+// writes and doc commits bump in their own transaction; a cached outline is therefore never
+// served once a newer write has committed.
+type PathOutlineService interface {
+	// Outline returns the path's current outline, or nil when the path does not exist.
+	Outline(dbc dbctx.Context, pathID uuid.UUID) (*PathOutline, error)
+}
+
+// PathOutline is an immutable snapshot shared between callers: nodes it hands out must be
+// copied before they are modified.
+type PathOutline struct {
+	PathID  uuid.UUID
+	Version int64
+
+	nodes []*PathOutlineNode
+	byID  map[uuid.UUID]*PathOutlineNode
+}
+
+type PathOutlineNode struct {
+	Node *types.PathNode
+	// ConceptKeys are the node's concept and prerequisite keys, lowercased and deduplicated.
+	ConceptKeys    []string
+	DocContentHash string
+	// Description is what outlines show for the node (see types.PathNodeDescription):
+	// a synced or user-edited description when set, otherwise the build goal.
+	Description       string
+	DescriptionSource string
+	// DocDigest is the committed doc's summary snippet and objectives (version 0 without one).
+	DocDigest types.NodeDocDigest
+}
+
+// OrderedNodes returns the path's nodes in index order.
+func (o *PathOutline) OrderedNodes() []*types.PathNode {
+	if o == nil {
+		return nil
+	}
+	out := make([]*types.PathNode, 0, len(o.nodes))
+	for _, n := range o.nodes {
+		out = append(out, n.Node)
+	}
+	return out
+}
+
+// NodeByID returns the node when it belongs to this path.
+func (o *PathOutline) NodeByID(id uuid.UUID) (*types.PathNode, bool) {
+	if o == nil {
+		return nil, false
+	}
+	n, ok := o.byID[id]
+	if !ok {
+		return nil, false
+	}
+	return n.Node, true
+}
+
+func (o *PathOutline) ConceptKeysForNode(id uuid.UUID) []string {
+	if o == nil {
+		return nil
+	}
+	if n, ok := o.byID[id]; ok {
+		return append([]string(nil), n.ConceptKeys...)
+	}
+	return nil
+}
+
+// DocContentHash is the committed doc's content hash for the node ("" without a doc).
+func (o *PathOutline) DocContentHash(id uuid.UUID) string {
+	if o == nil {
+		return ""
+	}
+	if n, ok := o.byID[id]; ok {
+		return n.DocContentHash
+	}
+	return ""
+}
+
+// Description returns the node's outline description and its source ("" without either).
+func (o *PathOutline) Description(id uuid.UUID) (string, string) {
+	if o == nil {
+		return "", ""
+	}
+	if n, ok := o.byID[id]; ok {
+		return n.Description, n.DescriptionSource
+	}
+	return "", ""
+}
+
+// DocDigest returns the digest of the node's committed doc.
+func (o *PathOutline) DocDigest(id uuid.UUID) types.NodeDocDigest {
+	if o == nil {
+		return types.NodeDocDigest{}
+	}
+	if n, ok := o.byID[id]; ok {
+		return n.DocDigest
+	}
+	return types.NodeDocDigest{}
+}
+
+type pathOutlineService struct {
+	log   *logger.Logger
+	paths repos.PathRepo
+	nodes repos.PathNodeRepo
+
+	outlines *ttlLRU[uuid.UUID, *PathOutline]
+}
+
+func NewPathOutlineService(baseLog *logger.Logger, paths repos.PathRepo, nodes repos.PathNodeRepo) PathOutlineService {
+	// The TTL only bounds how long idle paths hold memory; freshness comes from the version check.
+	ttl := time.Duration(envutil.Int("PATH_OUTLINE_CACHE_TTL_SECONDS", 900)) * time.Second
+	return &pathOutlineService{
+		log:      baseLog.With("service", "PathOutlineService"),
+		paths:    paths,
+		nodes:    nodes,
+		outlines: newTTLLRU[uuid.UUID, *PathOutline](envutil.Int("PATH_OUTLINE_CACHE_MAX_PATHS", 512), ttl),
+	}
+}
+
+func (s *pathOutlineService) Outline(dbc dbctx.Context, pathID uuid.UUID) (*PathOutline, error) {
+	if pathID == uuid.Nil || s.paths == nil || s.nodes == nil {
+		return nil, nil
+	}
+	metrics := observability.Current()
+
+	// The version is read before the rows: a write committing in between leaves the rows newer
+	// than the version they are cached under, which costs the next read a reload but can never
+	// pass old rows off as current.
+	version, ok, err := s.paths.GetOutlineVersion(dbc, pathID)
+	if err != nil {
+		metrics.IncPathOutlineCache("error")
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	result := "miss"
+	if cached, hit := s.outlines.Get(pathID); hit {
+		if cached.Version == version {
+			metrics.IncPathOutlineCache("hit")
+			return cached, nil
+		}
+		result = "stale"
+	}
+	metrics.IncPathOutlineCache(result)
+
+	rows, err := s.nodes.ListOutlineByPathID(dbc, pathID)
+	if err != nil {
+		metrics.IncPathOutlineCache("error")
+		return nil, err
+	}
+	outline := newPathOutline(pathID, version, rows)
+	// Inside a transaction the version and rows may not be committed yet; don't publish them.
+	if dbc.Tx == nil {
+		s.outlines.Set(pathID, outline)
+	}
+	return outline, nil
+}
+
+func newPathOutline(pathID uuid.UUID, version int64, rows []repos.PathOutlineRow) *PathOutline {
+	out := &PathOutline{
+		PathID:  pathID,
+		Version: version,
+		nodes:   make([]*PathOutlineNode, 0, len(rows)),
+		byID:    make(map[uuid.UUID]*PathOutlineNode, len(rows)),
+	}
+	for _, r := range rows {
+		if r.Node == nil || r.Node.ID == uuid.Nil {
+			continue
+		}
+		n := &PathOutlineNode{
+			Node:           r.Node,
+			ConceptKeys:    outlineNodeConceptKeys(r.Node),
+			DocContentHash: r.DocContentHash,
+			DocDigest:      r.DocDigest,
+		}
+		n.Description, n.DescriptionSource = types.PathNodeDescription(r.Node.Metadata)
+		out.nodes = append(out.nodes, n)
+		out.byID[r.Node.ID] = n
+	}
+	return out
+}
+
+func outlineNodeConceptKeys(node *types.PathNode) []string {
+	if node == nil || len(node.Metadata) == 0 || string(node.Metadata) == "null" {
+		return nil
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(node.Metadata, &meta); err != nil || meta == nil {
+		return nil
+	}
+	keys := []string{}
+	for _, field := range []string{"concept_keys", "prereq_concept_keys"} {
+		switch v := meta[field].(type) {
+		case string:
+			keys = append(keys, v)
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					keys = append(keys, s)
+				}
+			}
+		}
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, k := range keys {
+		k = strings.TrimSpace(strings.ToLower(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, k)
+	}
+	return out
+}
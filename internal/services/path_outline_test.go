@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// outlineStore stands in for the path and path_node tables: commit applies a write and its
+// outline_version bump atomically, like the repos do in one transaction.
+type outlineStore struct {
+	mu       sync.Mutex
+	pathID   uuid.UUID
+	version  int64
+	nodes    []*types.PathNode
+	listHook func()
+	lists    atomic.Int32
+}
+
+func (s *outlineStore) commit(apply func(nodes []*types.PathNode) []*types.PathNode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes = apply(s.nodes)
+	s.version++
+}
+
+func (s *outlineStore) retitle(title string) {
+	s.commit(func(nodes []*types.PathNode) []*types.PathNode {
+		out := make([]*types.PathNode, 0, len(nodes))
+		for _, n := range nodes {
+			cp := *n
+			cp.Title = title
+			out = append(out, &cp)
+		}
+		return out
+	})
+}
+
+type fakeOutlinePathRepo struct {
+	repos.PathRepo
+	store *outlineStore
+}
+
+func (r fakeOutlinePathRepo) GetOutlineVersion(dbc dbctx.Context, id uuid.UUID) (int64, bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if id != r.store.pathID {
+		return 0, false, nil
+	}
+	return r.store.version, true, nil
+}
+
+type fakeOutlineNodeRepo struct {
+	repos.PathNodeRepo
+	store *outlineStore
+}
+
+func (r fakeOutlineNodeRepo) ListOutlineByPathID(dbc dbctx.Context, pathID uuid.UUID) ([]repos.PathOutlineRow, error) {
+	r.store.lists.Add(1)
+	if r.store.listHook != nil {
+		r.store.listHook()
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	out := make([]repos.PathOutlineRow, 0, len(r.store.nodes))
+	for _, n := range r.store.nodes {
+		cp := *n
+		out = append(out, repos.PathOutlineRow{Node: &cp, DocContentHash: "hash-" + n.Title})
+	}
+	return out, nil
+}
+
+func newOutlineFixture(t *testing.T) (*outlineStore, PathOutlineService) {
+	t.Helper()
+	log, err := logger.New("test")
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	pathID := uuid.New()
+	store := &outlineStore{
+		pathID:  pathID,
+		version: 1,
+		nodes: []*types.PathNode{
+			{ID: uuid.New(), PathID: pathID, Index: 0, Title: "v1", Metadata: datatypes.JSON(`{"concept_keys":["Loops"," loops"],"prereq_concept_keys":["variables"],"goal":"Learn loops"}`)},
+			{ID: uuid.New(), PathID: pathID, Index: 1, Title: "v1", Metadata: datatypes.JSON(`{"goal":"Learn loops","description":"Counted loops.","description_source":"derived_from_doc"}`)},
+		},
+	}
+	return store, NewPathOutlineService(log, fakeOutlinePathRepo{store: store}, fakeOutlineNodeRepo{store: store})
+}
+
+func TestPathOutlineCachesUntilVersionMoves(t *testing.T) {
+	store, svc := newOutlineFixture(t)
+	dbc := dbctx.Context{Ctx: context.Background()}
+
+	first, err := svc.Outline(dbc, store.pathID)
+	if err != nil || first == nil {
+		t.Fatalf("Outline: %+v %v", first, err)
+	}
+	nodes := first.OrderedNodes()
+	if len(nodes) != 2 || nodes[0].Index != 0 || nodes[1].Index != 1 {
+		t.Fatalf("ordered nodes = %+v", nodes)
+	}
+	if got := first.ConceptKeysForNode(nodes[0].ID); fmt.Sprint(got) != "[loops variables]" {
+		t.Fatalf("ConceptKeysForNode = %v", got)
+	}
+	if _, ok := first.NodeByID(uuid.New()); ok {
+		t.Fatalf("NodeByID found a node from another path")
+	}
+	if first.DocContentHash(nodes[1].ID) != "hash-v1" {
+		t.Fatalf("DocContentHash = %q", first.DocContentHash(nodes[1].ID))
+	}
+	if desc, source := first.Description(nodes[0].ID); desc != "Learn loops" || source != types.PathNodeDescriptionSourceBuild {
+		t.Fatalf("build description = %q (%s)", desc, source)
+	}
+	if desc, source := first.Description(nodes[1].ID); desc != "Counted loops." || source != types.PathNodeDescriptionSourceDoc {
+		t.Fatalf("synced description = %q (%s), want it preferred over the goal", desc, source)
+	}
+
+	again, _ := svc.Outline(dbc, store.pathID)
+	if again != first || store.lists.Load() != 1 {
+		t.Fatalf("unchanged version reloaded the outline (%d loads)", store.lists.Load())
+	}
+
+	store.retitle("v2")
+	after, _ := svc.Outline(dbc, store.pathID)
+	if after.Version != 2 || after.OrderedNodes()[0].Title != "v2" || store.lists.Load() != 2 {
+		t.Fatalf("outline after commit = version %d title %q (%d loads)", after.Version, after.OrderedNodes()[0].Title, store.lists.Load())
+	}
+
+	// Reads inside a transaction may see uncommitted rows; they must not be published.
+	store.retitle("v3")
+	if o, _ := svc.Outline(dbctx.Context{Ctx: context.Background(), Tx: &gorm.DB{}}, store.pathID); o.Version != 3 {
+		t.Fatalf("in-tx outline version = %d", o.Version)
+	}
+	_, _ = svc.Outline(dbc, store.pathID)
+	if store.lists.Load() != 4 {
+		t.Fatalf("in-tx outline was cached (%d loads)", store.lists.Load())
+	}
+
+	if o, err := svc.Outline(dbc, uuid.New()); o != nil || err != nil {
+		t.Fatalf("unknown path = %+v %v", o, err)
+	}
+}
+
+func TestPathOutlineWriteBetweenVersionAndRows(t *testing.T) {
+	store, svc := newOutlineFixture(t)
+	dbc := dbctx.Context{Ctx: context.Background()}
+
+	// A commit lands after the version check but before the rows are read: the load returns
+	// the newer rows under the older version, so the next read must reload rather than hit.
+	store.listHook = func() {
+		store.listHook = nil
+		store.retitle("v2")
+	}
+	raced, _ := svc.Outline(dbc, store.pathID)
+	if raced.Version != 1 || raced.OrderedNodes()[0].Title != "v2" {
+		t.Fatalf("raced load = version %d title %q", raced.Version, raced.OrderedNodes()[0].Title)
+	}
+	next, _ := svc.Outline(dbc, store.pathID)
+	if next.Version != 2 || next.OrderedNodes()[0].Title != "v2" || store.lists.Load() != 2 {
+		t.Fatalf("read after raced load = version %d title %q (%d loads)", next.Version, next.OrderedNodes()[0].Title, store.lists.Load())
+	}
+	_, _ = svc.Outline(dbc, store.pathID)
+	if store.lists.Load() != 2 {
+		t.Fatalf("settled outline was not cached (%d loads)", store.lists.Load())
+	}
+}
+
+func TestPathOutlineNeverOlderThanLastCommit(t *testing.T) {
+	store, svc := newOutlineFixture(t)
+	dbc := dbctx.Context{Ctx: context.Background()}
+	var committed atomic.Int64
+	committed.Store(1)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				floor := committed.Load()
+				o, err := svc.Outline(dbc, store.pathID)
+				if err != nil {
+					t.Errorf("Outline: %v", err)
+					return
+				}
+				var seen int64
+				fmt.Sscanf(o.OrderedNodes()[0].Title, "v%d", &seen)
+				if seen < floor {
+					t.Errorf("read started after commit %d returned rows from commit %d", floor, seen)
+					return
+				}
+			}
+		}()
+	}
+	for v := 2; v <= 200; v++ {
+		store.retitle(fmt.Sprintf("v%d", v))
+		committed.Store(int64(v))
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// PrereqGateNightly enqueues a prereq_gate_prefetch job per user once a day, so gates on the
+// nodes users are about to reach are evaluated before they open them. Enqueueing dedupes
+// against a runnable job, so several worker replicas running the loop enqueue once per user.
+type PrereqGateNightly interface {
+	// Run blocks until ctx is done, enqueueing a round at the configured hour each day.
+	Run(ctx context.Context)
+	// EnqueueRound enqueues one round now and returns how many jobs were queued.
+	EnqueueRound(ctx context.Context) (int, error)
+}
+
+type prereqGateNightly struct {
+	log      *logger.Logger
+	paths    repos.PathRepo
+	jobs     JobService
+	hourUTC  int
+	maxUsers int
+}
+
+func NewPrereqGateNightly(baseLog *logger.Logger, paths repos.PathRepo, jobs JobService) PrereqGateNightly {
+	hour := envutil.Int("PREREQ_GATE_NIGHTLY_HOUR_UTC", 3)
+	if hour < 0 || hour > 23 {
+		hour = 3
+	}
+	maxUsers := envutil.Int("PREREQ_GATE_NIGHTLY_MAX_USERS", 5000)
+	if maxUsers <= 0 {
+		maxUsers = 5000
+	}
+	return &prereqGateNightly{
+		log:      baseLog.With("service", "PrereqGateNightly"),
+		paths:    paths,
+		jobs:     jobs,
+		hourUTC:  hour,
+		maxUsers: maxUsers,
+	}
+}
+
+func (n *prereqGateNightly) Run(ctx context.Context) {
+	if n == nil || n.paths == nil || n.jobs == nil {
+		return
+	}
+	if !envutil.Bool("PREREQ_GATE_NIGHTLY_ENABLED", true) {
+		return
+	}
+	for {
+		now := time.Now().UTC()
+		timer := time.NewTimer(nextPrereqGateRound(now, n.hourUTC).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		queued, err := n.EnqueueRound(ctx)
+		if err != nil {
+			n.log.Warn("prereq gate nightly round failed", "error", err, "queued", queued)
+			continue
+		}
+		n.log.Info("prereq gate nightly round enqueued", "queued", queued)
+	}
+}
+
+func (n *prereqGateNightly) EnqueueRound(ctx context.Context) (int, error) {
+	dbc := dbctx.Context{Ctx: ctx}
+	rows, err := n.paths.ListByStatus(dbc, []string{"ready"})
+	if err != nil {
+		return 0, err
+	}
+	users := prereqGateNightlyUsers(rows, n.maxUsers)
+	queued := 0
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			return queued, ctx.Err()
+		}
+		_, created, err := n.jobs.EnqueuePrereqGatePrefetchIfNeeded(dbc, userID, uuid.Nil, "nightly")
+		if err != nil {
+			n.log.Warn("prereq gate nightly enqueue failed", "error", err, "user_id", userID)
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+	if len(users) == n.maxUsers {
+		n.log.Info("prereq gate nightly round hit the user cap", "max_users", n.maxUsers)
+	}
+	return queued, nil
+}
+
+// prereqGateNightlyUsers returns the owners of non-archived paths, most recently active first,
+// capped at maxUsers.
+func prereqGateNightlyUsers(paths []*types.Path, maxUsers int) []uuid.UUID {
+	lastSeen := map[uuid.UUID]time.Time{}
+	for _, p := range paths {
+		if p == nil || p.UserID == nil || *p.UserID == uuid.Nil {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(p.Status), "archived") {
+			continue
+		}
+		seen := time.Time{}
+		if p.LastViewedAt != nil {
+			seen = *p.LastViewedAt
+		}
+		if prev, ok := lastSeen[*p.UserID]; !ok || seen.After(prev) {
+			lastSeen[*p.UserID] = seen
+		}
+	}
+	out := make([]uuid.UUID, 0, len(lastSeen))
+	for id := range lastSeen {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !lastSeen[out[i]].Equal(lastSeen[out[j]]) {
+			return lastSeen[out[i]].After(lastSeen[out[j]])
+		}
+		return out[i].String() < out[j].String()
+	})
+	if maxUsers > 0 && len(out) > maxUsers {
+		out = out[:maxUsers]
+	}
+	return out
+}
+
+// nextPrereqGateRound is the next hourUTC:00 strictly after now.
+func nextPrereqGateRound(now time.Time, hourUTC int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hourUTC, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/observability"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+type SessionPrewarmStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Dropped int64 `json:"dropped"`
+	Errors  int64 `json:"errors"`
+}
+
+// SessionPrewarmer fills the doc serving caches when a session starts or switches paths, so
+// the first doc open doesn't load everything cold. It runs inline in the API process (no job
+// row, no Temporal): the work is a handful of reads and losing it only costs latency.
+// Path-scoped inputs are loaded once; per-node inputs are loaded for the active node (the
+// first one when the session has none) and the node after it in path order.
+type SessionPrewarmer interface {
+	// Trigger schedules a prewarm and returns immediately; it never blocks the caller.
+	Trigger(pathID uuid.UUID, nodeID uuid.UUID)
+	// Prewarm runs synchronously under a short deadline and reports whether anything was loaded.
+	Prewarm(ctx context.Context, pathID uuid.UUID, nodeID uuid.UUID) (bool, error)
+	Stats() SessionPrewarmStats
+}
+
+type sessionPrewarmer struct {
+	log        *logger.Logger
+	cache      DocServingCache
+	paths      repos.PathRepo
+	pathNodes  repos.PathNodeRepo
+	policyKeys []string
+	timeout    time.Duration
+
+	sem      chan struct{}
+	inflight sync.Map // pathID -> struct{}
+
+	hits, misses, dropped, errors atomic.Int64
+}
+
+func NewSessionPrewarmer(baseLog *logger.Logger, cache DocServingCache, paths repos.PathRepo, pathNodes repos.PathNodeRepo, policyKeys []string) SessionPrewarmer {
+	timeoutMS := envutil.Int("SESSION_PREWARM_TIMEOUT_MS", 1500)
+	if timeoutMS <= 0 {
+		timeoutMS = 1500
+	}
+	concurrency := envutil.Int("SESSION_PREWARM_CONCURRENCY", 4)
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &sessionPrewarmer{
+		log:        baseLog.With("service", "SessionPrewarmer"),
+		cache:      cache,
+		paths:      paths,
+		pathNodes:  pathNodes,
+		policyKeys: policyKeys,
+		timeout:    time.Duration(timeoutMS) * time.Millisecond,
+		sem:        make(chan struct{}, concurrency),
+	}
+}
+
+func (p *sessionPrewarmer) Trigger(pathID uuid.UUID, nodeID uuid.UUID) {
+	if p == nil || p.cache == nil || pathID == uuid.Nil {
+		return
+	}
+	// The next node is only known after a node lookup; it was warmed alongside the active one.
+	if p.warm(pathID, nodeIDs(nodeID)) {
+		p.record("hit")
+		return
+	}
+	// One prewarm per path at a time; a second login on the same path just rides along.
+	if _, busy := p.inflight.LoadOrStore(pathID, struct{}{}); busy {
+		p.record("dropped")
+		return
+	}
+	select {
+	case p.sem <- struct{}{}:
+	default:
+		p.inflight.Delete(pathID)
+		p.record("dropped")
+		return
+	}
+	go func() {
+		defer func() {
+			<-p.sem
+			p.inflight.Delete(pathID)
+			if r := recover(); r != nil {
+				p.log.Warn("session prewarm panicked", "path_id", pathID, "panic", r)
+			}
+		}()
+		if _, err := p.Prewarm(context.Background(), pathID, nodeID); err != nil {
+			p.log.Debug("session prewarm failed", "error", err, "path_id", pathID)
+		}
+	}()
+}
+
+func (p *sessionPrewarmer) Prewarm(ctx context.Context, pathID uuid.UUID, nodeID uuid.UUID) (bool, error) {
+	if p == nil || p.cache == nil || pathID == uuid.Nil {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	dbc := dbctx.Context{Ctx: ctx}
+
+	if p.paths != nil {
+		path, err := p.paths.GetByID(dbc, pathID)
+		if err != nil {
+			p.record("error")
+			return false, err
+		}
+		// A rebuilt path must not be reported warm off entries from the previous build.
+		p.cache.SyncPath(path)
+	}
+	nodes, err := p.prewarmNodes(dbc, pathID, nodeID)
+	if err != nil {
+		p.record("error")
+		return false, err
+	}
+
+	if p.warm(pathID, nodes) {
+		p.record("hit")
+		return false, nil
+	}
+
+	if _, err := p.cache.PathConcepts(dbc, pathID); err != nil {
+		p.record("error")
+		return false, err
+	}
+	for _, key := range p.policyKeys {
+		if _, err := p.cache.PolicySnapshot(dbc, key); err != nil {
+			p.record("error")
+			return false, err
+		}
+	}
+	for _, id := range nodes {
+		if _, err := p.cache.NodeDocTracks(dbc, pathID, id); err != nil {
+			p.record("error")
+			return false, err
+		}
+	}
+	p.record("miss")
+	return true, nil
+}
+
+// prewarmNodes returns the active node (the first node when nodeID is unset or not on the
+// path) and the node after it by index, at most two.
+func (p *sessionPrewarmer) prewarmNodes(dbc dbctx.Context, pathID uuid.UUID, nodeID uuid.UUID) ([]uuid.UUID, error) {
+	if p.pathNodes == nil {
+		return nodeIDs(nodeID), nil
+	}
+	rows, err := p.pathNodes.GetByPathIDs(dbc, []uuid.UUID{pathID})
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]*types.PathNode, 0, len(rows))
+	for _, n := range rows {
+		if n != nil && n.ID != uuid.Nil {
+			ordered = append(ordered, n)
+		}
+	}
+	if len(ordered) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Index < ordered[j].Index })
+	at := 0
+	for i, n := range ordered {
+		if n.ID == nodeID {
+			at = i
+			break
+		}
+	}
+	out := []uuid.UUID{ordered[at].ID}
+	if at+1 < len(ordered) {
+		out = append(out, ordered[at+1].ID)
+	}
+	return out, nil
+}
+
+func (p *sessionPrewarmer) warm(pathID uuid.UUID, nodes []uuid.UUID) bool {
+	if !p.cache.PathConceptsWarm(pathID) {
+		return false
+	}
+	for _, key := range p.policyKeys {
+		if !p.cache.PolicySnapshotWarm(key) {
+			return false
+		}
+	}
+	for _, id := range nodes {
+		if !p.cache.NodeDocTracksWarm(id) {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeIDs(nodeID uuid.UUID) []uuid.UUID {
+	if nodeID == uuid.Nil {
+		return nil
+	}
+	return []uuid.UUID{nodeID}
+}
+
+func (p *sessionPrewarmer) record(result string) {
+	switch result {
+	case "hit":
+		p.hits.Add(1)
+	case "miss":
+		p.misses.Add(1)
+	case "dropped":
+		p.dropped.Add(1)
+	case "error":
+		p.errors.Add(1)
+	}
+	observability.Current().IncSessionPrewarm(result)
+}
+
+func (p *sessionPrewarmer) Stats() SessionPrewarmStats {
+	if p == nil {
+		return SessionPrewarmStats{}
+	}
+	return SessionPrewarmStats{
+		Hits:    p.hits.Load(),
+		Misses:  p.misses.Load(),
+		Dropped: p.dropped.Load(),
+		Errors:  p.errors.Load(),
+	}
+}
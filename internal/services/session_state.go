@@ -188,16 +188,19 @@ type SessionStateService interface {
 }
 
 type sessionStateService struct {
-	db   *gorm.DB
-	log  *logger.Logger
-	repo repos.UserSessionStateRepo
+	db      *gorm.DB
+	log     *logger.Logger
+	repo    repos.UserSessionStateRepo
+	prewarm SessionPrewarmer
 }
 
-func NewSessionStateService(db *gorm.DB, baseLog *logger.Logger, repo repos.UserSessionStateRepo) SessionStateService {
+// prewarm may be nil (workers, tests); session state works the same without it.
+func NewSessionStateService(db *gorm.DB, baseLog *logger.Logger, repo repos.UserSessionStateRepo, prewarm SessionPrewarmer) SessionStateService {
 	return &sessionStateService{
-		db:   db,
-		log:  baseLog.With("service", "SessionStateService"),
-		repo: repo,
+		db:      db,
+		log:     baseLog.With("service", "SessionStateService"),
+		repo:    repo,
+		prewarm: prewarm,
 	}
 }
 
@@ -211,6 +214,8 @@ func (s *sessionStateService) Patch(dbc dbctx.Context, patch SessionStatePatch)
 		return nil, fmt.Errorf("unauthorized")
 	}
 
+	// Set inside run: whether this patch started the session or moved it to another path.
+	pathEntered := false
 	run := func(inner dbctx.Context) (*types.UserSessionState, error) {
 		if err := s.repo.Ensure(inner, rd.UserID, rd.SessionID); err != nil {
 			return nil, err
@@ -220,6 +225,12 @@ func (s *sessionStateService) Patch(dbc dbctx.Context, patch SessionStatePatch)
 		if err != nil {
 			return nil, err
 		}
+		// Ensure stamps last_seen_at == created_at; any later patch moves last_seen_at.
+		created := prev != nil && prev.LastSeenAt.Equal(prev.CreatedAt)
+		var prevPathID *uuid.UUID
+		if prev != nil {
+			prevPathID = prev.ActivePathID
+		}
 
 		now := time.Now().UTC()
 		updates := map[string]any{
@@ -338,11 +349,18 @@ func (s *sessionStateService) Patch(dbc dbctx.Context, patch SessionStatePatch)
 		if state == nil {
 			return nil, fmt.Errorf("session state not found")
 		}
+		if state.ActivePathID != nil && *state.ActivePathID != uuid.Nil {
+			pathEntered = created || prevPathID == nil || *prevPathID != *state.ActivePathID
+		}
 		return state, nil
 	}
 
 	if dbc.Tx != nil {
-		return run(dbc)
+		state, err := run(dbc)
+		if err == nil {
+			s.triggerPrewarm(state, pathEntered)
+		}
+		return state, err
 	}
 
 	var out *types.UserSessionState
@@ -357,5 +375,17 @@ func (s *sessionStateService) Patch(dbc dbctx.Context, patch SessionStatePatch)
 		s.log.Warn("Patch transaction error", "error", err)
 		return nil, err
 	}
+	s.triggerPrewarm(out, pathEntered)
 	return out, nil
 }
+
+func (s *sessionStateService) triggerPrewarm(state *types.UserSessionState, pathEntered bool) {
+	if s.prewarm == nil || !pathEntered || state == nil || state.ActivePathID == nil {
+		return
+	}
+	nodeID := uuid.Nil
+	if state.ActivePathNodeID != nil {
+		nodeID = *state.ActivePathNodeID
+	}
+	s.prewarm.Trigger(*state.ActivePathID, nodeID)
+}
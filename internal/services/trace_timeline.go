@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/envutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// Timeline entry kinds, in the order entries sharing a timestamp are listed.
+const (
+	TraceEntryRequest  = "request"
+	TraceEntryJob      = "job"
+	TraceEntryLLMTrace = "llm_trace"
+	TraceEntryRevision = "revision"
+	TraceEntryExposure = "exposure"
+)
+
+var traceEntryOrder = map[string]int{
+	TraceEntryRequest:  0,
+	TraceEntryJob:      1,
+	TraceEntryLLMTrace: 2,
+	TraceEntryRevision: 3,
+	TraceEntryExposure: 4,
+}
+
+// TraceTimeline is everything recorded under one request trace, oldest first.
+type TraceTimeline struct {
+	TraceID    string               `json:"trace_id"`
+	RequestIDs []string             `json:"request_ids"`
+	Entries    []TraceTimelineEntry `json:"entries"`
+	// Truncated is set when a source hit the row limit (TRACE_TIMELINE_MAX_ROWS).
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// TraceTimelineEntry is one row of the timeline. SpanID/ParentSpanID link jobs into the chain:
+// a job's span is its job run id and its parent is the job that enqueued it, or the request.
+type TraceTimelineEntry struct {
+	At           time.Time      `json:"at"`
+	Kind         string         `json:"kind"`
+	ID           string         `json:"id"`
+	SpanID       string         `json:"span_id,omitempty"`
+	ParentSpanID string         `json:"parent_span_id,omitempty"`
+	Depth        int            `json:"depth"`
+	RequestID    string         `json:"request_id,omitempty"`
+	Summary      string         `json:"summary"`
+	Data         map[string]any `json:"data,omitempty"`
+}
+
+type TraceTimelineService interface {
+	Timeline(ctx context.Context, traceID string) (*TraceTimeline, error)
+}
+
+type traceTimelineService struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewTraceTimelineService(db *gorm.DB, baseLog *logger.Logger) TraceTimelineService {
+	return &traceTimelineService{db: db, log: baseLog.With("service", "TraceTimelineService")}
+}
+
+// traceTimelineRows are the rows recorded under one trace, as loaded from each source.
+type traceTimelineRows struct {
+	Jobs        []*types.JobRun
+	Revisions   []*types.LearningNodeDocRevision
+	Exposures   []*types.DocVariantExposure
+	Generations []*types.DocGenerationTrace
+	Truncated   bool
+}
+
+func (s *traceTimelineService) Timeline(ctx context.Context, traceID string) (*TraceTimeline, error) {
+	traceID = strings.TrimSpace(traceID)
+	if traceID == "" {
+		return nil, fmt.Errorf("missing trace_id")
+	}
+	limit := envutil.Int("TRACE_TIMELINE_MAX_ROWS", 500)
+	if limit <= 0 {
+		limit = 500
+	}
+	db := s.db.WithContext(ctx)
+	var rows traceTimelineRows
+
+	// Jobs enqueued before the trace envelope carried the ids in their payload.
+	if err := db.Where("trace_id = ? OR (trace_id = '' AND payload->>'trace_id' = ?)", traceID, traceID).
+		Order("created_at ASC, id ASC").Limit(limit).Find(&rows.Jobs).Error; err != nil {
+		return nil, fmt.Errorf("load jobs: %w", err)
+	}
+	jobIDs := make([]uuid.UUID, 0, len(rows.Jobs))
+	for _, j := range rows.Jobs {
+		jobIDs = append(jobIDs, j.ID)
+	}
+
+	q := db.Where("metadata->'"+repos.NodeDocRevisionTraceKey+"'->>'trace_id' = ?", traceID)
+	if len(jobIDs) > 0 {
+		q = q.Or("job_id IN ?", jobIDs)
+	}
+	if err := q.Order("created_at ASC, id ASC").Limit(limit).Find(&rows.Revisions).Error; err != nil {
+		return nil, fmt.Errorf("load revisions: %w", err)
+	}
+	if err := db.Where("trace_id = ?", traceID).
+		Order("created_at ASC, id ASC").Limit(limit).Find(&rows.Exposures).Error; err != nil {
+		return nil, fmt.Errorf("load exposures: %w", err)
+	}
+	if err := db.Where("request_trace_id = ?", traceID).
+		Order("created_at ASC, id ASC").Limit(limit).Find(&rows.Generations).Error; err != nil {
+		return nil, fmt.Errorf("load llm traces: %w", err)
+	}
+	rows.Truncated = len(rows.Jobs) == limit || len(rows.Revisions) == limit ||
+		len(rows.Exposures) == limit || len(rows.Generations) == limit
+
+	return buildTraceTimeline(traceID, rows), nil
+}
+
+// buildTraceTimeline stitches the rows into one timeline. Request log lines live in the
+// service logs rather than the database, so each request id seen in the rows gets a request
+// entry (at its earliest row) that points there.
+func buildTraceTimeline(traceID string, rows traceTimelineRows) *TraceTimeline {
+	out := &TraceTimeline{TraceID: traceID, RequestIDs: []string{}, Entries: []TraceTimelineEntry{}, Truncated: rows.Truncated}
+
+	for _, j := range rows.Jobs {
+		if j == nil {
+			continue
+		}
+		reqID := j.RequestID
+		if reqID == "" {
+			reqID = legacyJobRequestID(j)
+		}
+		e := TraceTimelineEntry{
+			At:        j.CreatedAt.UTC(),
+			Kind:      TraceEntryJob,
+			ID:        j.ID.String(),
+			SpanID:    j.ID.String(),
+			Depth:     j.TraceDepth,
+			RequestID: reqID,
+			Summary:   fmt.Sprintf("%s %s (%s)", j.JobType, j.Status, j.Stage),
+			Data: map[string]any{
+				"job_type":    j.JobType,
+				"status":      j.Status,
+				"stage":       j.Stage,
+				"attempts":    j.Attempts,
+				"entity_type": j.EntityType,
+				"updated_at":  j.UpdatedAt.UTC(),
+			},
+		}
+		if j.EntityID != nil {
+			e.Data["entity_id"] = j.EntityID.String()
+		}
+		if j.Error != "" {
+			e.Data["error"] = j.Error
+		}
+		if j.ParentJobID != nil {
+			e.ParentSpanID = j.ParentJobID.String()
+		} else {
+			e.ParentSpanID = reqID
+		}
+		out.Entries = append(out.Entries, e)
+	}
+
+	for _, g := range rows.Generations {
+		if g == nil {
+			continue
+		}
+		e := TraceTimelineEntry{
+			At:        g.CreatedAt.UTC(),
+			Kind:      TraceEntryLLMTrace,
+			ID:        g.ID.String(),
+			RequestID: g.RequestID,
+			Summary:   strings.TrimSpace("doc generation " + g.Model),
+			Data: map[string]any{
+				"generation_trace_id": g.TraceID,
+				"path_node_id":        g.PathNodeID.String(),
+				"policy_version":      g.PolicyVersion,
+				"prompt_hash":         g.PromptHash,
+			},
+		}
+		if g.JobID != nil {
+			e.SpanID = g.JobID.String()
+		}
+		out.Entries = append(out.Entries, e)
+	}
+
+	for _, r := range rows.Revisions {
+		if r == nil {
+			continue
+		}
+		e := TraceTimelineEntry{
+			At:      r.CreatedAt.UTC(),
+			Kind:    TraceEntryRevision,
+			ID:      r.ID.String(),
+			Summary: fmt.Sprintf("revision %s %s", r.Operation, r.Status),
+			Data: map[string]any{
+				"doc_id":       r.DocID.String(),
+				"path_node_id": r.PathNodeID.String(),
+				"block_id":     r.BlockID,
+				"operation":    r.Operation,
+				"status":       r.Status,
+				"model":        r.Model,
+				"tokens_in":    r.TokensIn,
+				"tokens_out":   r.TokensOut,
+			},
+		}
+		if r.JobID != nil {
+			e.SpanID = r.JobID.String()
+		}
+		applyRevisionTrace(&e, r)
+		out.Entries = append(out.Entries, e)
+	}
+
+	for _, x := range rows.Exposures {
+		if x == nil {
+			continue
+		}
+		e := TraceTimelineEntry{
+			At:        x.CreatedAt.UTC(),
+			Kind:      TraceEntryExposure,
+			ID:        x.ID.String(),
+			SpanID:    x.RequestID,
+			RequestID: x.RequestID,
+			Summary:   fmt.Sprintf("exposure %s (%s)", x.ExposureKind, x.VariantKind),
+			Data: map[string]any{
+				"path_node_id":   x.PathNodeID.String(),
+				"variant_kind":   x.VariantKind,
+				"policy_version": x.PolicyVersion,
+				"source":         x.Source,
+			},
+		}
+		out.Entries = append(out.Entries, e)
+	}
+
+	firstSeen := map[string]time.Time{}
+	for _, e := range out.Entries {
+		if e.RequestID == "" {
+			continue
+		}
+		if at, ok := firstSeen[e.RequestID]; !ok || e.At.Before(at) {
+			firstSeen[e.RequestID] = e.At
+		}
+	}
+	for reqID, at := range firstSeen {
+		out.RequestIDs = append(out.RequestIDs, reqID)
+		out.Entries = append(out.Entries, TraceTimelineEntry{
+			At:        at,
+			Kind:      TraceEntryRequest,
+			ID:        reqID,
+			SpanID:    reqID,
+			RequestID: reqID,
+			Summary:   "request (log lines: search request_id in the service logs)",
+		})
+	}
+	sort.Strings(out.RequestIDs)
+
+	sort.SliceStable(out.Entries, func(i, j int) bool {
+		a, b := out.Entries[i], out.Entries[j]
+		if !a.At.Equal(b.At) {
+			return a.At.Before(b.At)
+		}
+		if a.Kind != b.Kind {
+			return traceEntryOrder[a.Kind] < traceEntryOrder[b.Kind]
+		}
+		return a.ID < b.ID
+	})
+	return out
+}
+
+// applyRevisionTrace fills span, depth and request from the trace its writer recorded.
+func applyRevisionTrace(e *TraceTimelineEntry, r *types.LearningNodeDocRevision) {
+	if len(r.Metadata) == 0 {
+		return
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(r.Metadata, &meta); err != nil {
+		return
+	}
+	trace, _ := meta[repos.NodeDocRevisionTraceKey].(map[string]any)
+	if trace == nil {
+		return
+	}
+	if v, ok := trace["span_id"].(string); ok && v != "" {
+		e.SpanID = v
+	}
+	if v, ok := trace["request_id"].(string); ok {
+		e.RequestID = v
+	}
+	if v, ok := trace["trace_depth"].(float64); ok {
+		e.Depth = int(v)
+	}
+}
+
+func legacyJobRequestID(j *types.JobRun) string {
+	if len(j.Payload) == 0 {
+		return ""
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(j.Payload, &payload); err != nil {
+		return ""
+	}
+	v, _ := payload["request_id"].(string)
+	return strings.TrimSpace(v)
+}
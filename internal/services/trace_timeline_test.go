@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	learningrepo "github.com/yungbote/neurobridge-backend/internal/data/repos/learning"
+	"github.com/yungbote/neurobridge-backend/internal/data/repos/testutil"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+)
+
+// TestTraceTimelineTwoHopChain follows a request that enqueues a doc patch job, which enqueues
+// a reindex job that writes a revision.
+func TestTraceTimelineTwoHopChain(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	reqCtx := ctxutil.WithTraceData(context.Background(), &ctxutil.TraceData{TraceID: "trace-1", RequestID: "req-1"})
+
+	patch := &types.JobRun{ID: uuid.New(), JobType: "node_doc_patch", Status: "succeeded", Stage: "done", CreatedAt: t0.Add(time.Second)}
+	applyJobTraceEnvelope(patch, ctxutil.GetTraceData(reqCtx))
+	if patch.TraceID != "trace-1" || patch.RequestID != "req-1" || patch.ParentJobID != nil || patch.TraceDepth != 1 {
+		t.Fatalf("first hop envelope: %+v", patch)
+	}
+
+	// The worker restores the envelope with the job as the span; jobs it enqueues go one deeper.
+	patchCtx := jobTraceContext(patch)
+	index := &types.JobRun{ID: uuid.New(), JobType: "chat_path_node_index", Status: "running", Stage: "embed", CreatedAt: t0.Add(3 * time.Second)}
+	applyJobTraceEnvelope(index, ctxutil.GetTraceData(patchCtx))
+	if index.TraceID != "trace-1" || index.ParentJobID == nil || *index.ParentJobID != patch.ID || index.TraceDepth != 2 {
+		t.Fatalf("second hop envelope: %+v", index)
+	}
+
+	trace := ctxutil.TraceFields(patchCtx)
+	if trace["span_id"] != patch.ID.String() || trace["trace_depth"] != 1 {
+		t.Fatalf("patch job trace fields = %v", trace)
+	}
+	meta, _ := json.Marshal(map[string]any{repos.NodeDocRevisionTraceKey: trace, "trace_id": "docgen-abc"})
+	rev := &types.LearningNodeDocRevision{ID: uuid.New(), JobID: &patch.ID, Operation: "patch", Status: "applied", Metadata: datatypes.JSON(meta), CreatedAt: t0.Add(2 * time.Second)}
+	gen := &types.DocGenerationTrace{ID: uuid.New(), TraceID: "docgen-abc", Model: "m", RequestTraceID: "trace-1", RequestID: "req-1", JobID: &patch.ID, CreatedAt: t0.Add(2 * time.Second)}
+	exposure := &types.DocVariantExposure{ID: uuid.New(), TraceID: "trace-1", RequestID: "req-1", ExposureKind: "base", VariantKind: "base", CreatedAt: t0}
+
+	tl := buildTraceTimeline("trace-1", traceTimelineRows{
+		Jobs:        []*types.JobRun{index, patch},
+		Revisions:   []*types.LearningNodeDocRevision{rev},
+		Exposures:   []*types.DocVariantExposure{exposure},
+		Generations: []*types.DocGenerationTrace{gen},
+	})
+
+	kinds := make([]string, 0, len(tl.Entries))
+	for _, e := range tl.Entries {
+		kinds = append(kinds, e.Kind)
+	}
+	want := []string{TraceEntryRequest, TraceEntryExposure, TraceEntryJob, TraceEntryLLMTrace, TraceEntryRevision, TraceEntryJob}
+	if len(kinds) != len(want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("kinds = %v, want %v", kinds, want)
+		}
+	}
+	if len(tl.RequestIDs) != 1 || tl.RequestIDs[0] != "req-1" || !tl.Entries[0].At.Equal(t0) {
+		t.Fatalf("request entry: %+v %v", tl.Entries[0], tl.RequestIDs)
+	}
+
+	byID := map[string]TraceTimelineEntry{}
+	for _, e := range tl.Entries {
+		byID[e.ID] = e
+	}
+	if e := byID[patch.ID.String()]; e.ParentSpanID != "req-1" || e.Depth != 1 {
+		t.Fatalf("patch entry: %+v", e)
+	}
+	if e := byID[index.ID.String()]; e.ParentSpanID != patch.ID.String() || e.Depth != 2 || e.RequestID != "req-1" {
+		t.Fatalf("index entry: %+v", e)
+	}
+	if e := byID[rev.ID.String()]; e.SpanID != patch.ID.String() || e.Depth != 1 || e.RequestID != "req-1" {
+		t.Fatalf("revision entry: %+v", e)
+	}
+	if e := byID[gen.ID.String()]; e.SpanID != patch.ID.String() || e.Data["generation_trace_id"] != "docgen-abc" {
+		t.Fatalf("llm trace entry: %+v", e)
+	}
+}
+
+func TestTraceTimelineQuery(t *testing.T) {
+	db := testutil.DB(t)
+	tx := testutil.Tx(t, db)
+	log := testutil.Logger(t)
+
+	reqCtx := ctxutil.WithTraceData(context.Background(), &ctxutil.TraceData{TraceID: "trace-q", RequestID: "req-q"})
+	user := testutil.SeedUser(t, dbctx.Context{Ctx: reqCtx, Tx: tx}, "trace-timeline@example.com")
+	t0 := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+
+	patch := &types.JobRun{ID: uuid.New(), OwnerUserID: user.ID, JobType: "node_doc_patch", Status: "succeeded", Stage: "done", Payload: datatypes.JSON(`{}`), Result: datatypes.JSON(`{}`), CreatedAt: t0, UpdatedAt: t0}
+	applyJobTraceEnvelope(patch, ctxutil.GetTraceData(reqCtx))
+	patchCtx := jobTraceContext(patch)
+	index := &types.JobRun{ID: uuid.New(), OwnerUserID: user.ID, JobType: "chat_path_node_index", Status: "queued", Stage: "queued", Payload: datatypes.JSON(`{}`), Result: datatypes.JSON(`{}`), CreatedAt: t0.Add(2 * time.Second), UpdatedAt: t0}
+	applyJobTraceEnvelope(index, ctxutil.GetTraceData(patchCtx))
+	legacy := &types.JobRun{ID: uuid.New(), OwnerUserID: user.ID, JobType: "old", Status: "succeeded", Stage: "done", Payload: datatypes.JSON(`{"trace_id":"trace-q","request_id":"req-q"}`), Result: datatypes.JSON(`{}`), CreatedAt: t0.Add(-time.Second), UpdatedAt: t0}
+	other := &types.JobRun{ID: uuid.New(), OwnerUserID: user.ID, JobType: "other", Status: "queued", Stage: "queued", TraceID: "trace-other", Payload: datatypes.JSON(`{}`), Result: datatypes.JSON(`{}`), CreatedAt: t0, UpdatedAt: t0}
+	for _, j := range []*types.JobRun{patch, index, legacy, other} {
+		if err := tx.Create(j).Error; err != nil {
+			t.Fatalf("seed job: %v", err)
+		}
+	}
+
+	// The writers stamp the trace of the job context they run under.
+	pathID, nodeID := uuid.New(), uuid.New()
+	revs := learningrepo.NewLearningNodeDocRevisionRepo(tx, log)
+	if _, err := revs.Create(dbctx.Context{Ctx: patchCtx, Tx: tx}, []*types.LearningNodeDocRevision{{
+		DocID: uuid.New(), UserID: user.ID, PathID: pathID, PathNodeID: nodeID, BlockID: "b1", BlockType: "paragraph",
+		Operation: "patch", CitationPolicy: "reuse_only", Status: "applied",
+		BeforeJSON: datatypes.JSON(`{}`), AfterJSON: datatypes.JSON(`{}`), CreatedAt: t0.Add(time.Second),
+	}}); err != nil {
+		t.Fatalf("create revision: %v", err)
+	}
+	gens := learningrepo.NewDocGenerationTraceRepo(tx, log)
+	if err := gens.Upsert(dbctx.Context{Ctx: patchCtx, Tx: tx}, &types.DocGenerationTrace{
+		TraceID: "docgen-" + uuid.NewString(), UserID: user.ID, PathID: pathID, PathNodeID: nodeID,
+		PolicyVersion: "v1", SchemaVersion: 1, TraceJSON: datatypes.JSON(`{}`), CreatedAt: t0.Add(time.Second),
+	}); err != nil {
+		t.Fatalf("upsert generation trace: %v", err)
+	}
+
+	tl, err := NewTraceTimelineService(tx, log).Timeline(context.Background(), "trace-q")
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	counts := map[string]int{}
+	byID := map[string]TraceTimelineEntry{}
+	for _, e := range tl.Entries {
+		counts[e.Kind]++
+		byID[e.ID] = e
+	}
+	if counts[TraceEntryJob] != 3 || counts[TraceEntryRevision] != 1 || counts[TraceEntryLLMTrace] != 1 || counts[TraceEntryRequest] != 1 {
+		t.Fatalf("entry counts = %v", counts)
+	}
+	if _, ok := byID[other.ID.String()]; ok {
+		t.Fatalf("job from another trace included")
+	}
+	if e := byID[index.ID.String()]; e.ParentSpanID != patch.ID.String() || e.Depth != 2 {
+		t.Fatalf("index entry: %+v", e)
+	}
+	for _, e := range tl.Entries {
+		if (e.Kind == TraceEntryRevision || e.Kind == TraceEntryLLMTrace) && e.SpanID != patch.ID.String() {
+			t.Fatalf("%s entry not linked to the patch job: %+v", e.Kind, e)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,14 +50,114 @@ type EventInput struct {
 	Data            map[string]any `json:"data,omitempty"`
 }
 
+type EventIngestStatus string
+
+const (
+	EventIngestAccepted  EventIngestStatus = "accepted"
+	EventIngestDuplicate EventIngestStatus = "duplicate"
+	EventIngestRejected  EventIngestStatus = "rejected"
+)
+
+// EventIngestItem reports what happened to one input event, by its index in the batch.
+type EventIngestItem struct {
+	Index         int               `json:"index"`
+	ClientEventID string            `json:"client_event_id"`
+	Status        EventIngestStatus `json:"status"`
+	Reason        string            `json:"reason,omitempty"`
+	// Clamped is set when occurred_at was older than the replay window and was moved up to it.
+	Clamped bool `json:"clamped,omitempty"`
+	// GeneratedID is set when the client sent no usable client_event_id and the server made one.
+	GeneratedID bool `json:"generated_id,omitempty"`
+}
+
+type EventIngestResult struct {
+	Accepted     int               `json:"accepted"`
+	Duplicates   int               `json:"duplicates"`
+	Rejected     int               `json:"rejected"`
+	Clamped      int               `json:"clamped"`
+	GeneratedIDs int               `json:"generated_ids"`
+	Events       []EventIngestItem `json:"events"`
+}
+
+var (
+	// ErrClientEventIDRequired rejects a batch with events lacking a client_event_id UUID once
+	// EVENT_CLIENT_ID_REQUIRED_AFTER has passed.
+	ErrClientEventIDRequired = errors.New("client_event_id (uuid) is required on every event")
+	ErrTooManyEvents         = fmt.Errorf("too many events (max %d)", maxEventsPerBatch)
+)
+
+const (
+	maxEventsPerBatch = 200
+
+	defaultEventMaxFutureSkew = 5 * time.Minute
+	defaultEventMaxPastAge    = 7 * 24 * time.Hour
+)
+
+// eventIngestConfig is the ingestion policy: the client_event_id deprecation cutoff and the
+// occurred_at replay window.
+type eventIngestConfig struct {
+	// ClientIDRequiredAfter: before it (or when zero), events without a client_event_id UUID get
+	// a generated one and a warning; after it the whole batch is rejected.
+	ClientIDRequiredAfter time.Time
+	// MaxFutureSkew: events claiming to happen further ahead of the server clock are rejected.
+	MaxFutureSkew time.Duration
+	// MaxPastAge: older events are clamped to now-MaxPastAge (the client time is kept in data).
+	MaxPastAge time.Duration
+}
+
+func eventIngestConfigFromEnv() eventIngestConfig {
+	cfg := eventIngestConfig{
+		MaxFutureSkew: envSeconds("EVENT_INGEST_MAX_FUTURE_SKEW_SECONDS", defaultEventMaxFutureSkew),
+		MaxPastAge:    envSeconds("EVENT_INGEST_MAX_PAST_AGE_SECONDS", defaultEventMaxPastAge),
+	}
+	if raw := getRawEnv("EVENT_CLIENT_ID_REQUIRED_AFTER"); raw != "" {
+		cfg.ClientIDRequiredAfter = parseTime(raw)
+	}
+	return cfg
+}
+
+func envSeconds(key string, def time.Duration) time.Duration {
+	raw := getRawEnv(key)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
+
+func (c eventIngestConfig) clientIDRequired(now time.Time) bool {
+	return !c.ClientIDRequiredAfter.IsZero() && !now.Before(c.ClientIDRequiredAfter)
+}
+
+// guardOccurredAt applies the replay window. It returns the time to store, whether it was
+// clamped, and whether the event is too far in the future to accept. A zero limit disables
+// that side of the check.
+func (c eventIngestConfig) guardOccurredAt(occurred, now time.Time) (time.Time, bool, bool) {
+	if c.MaxFutureSkew > 0 && occurred.After(now.Add(c.MaxFutureSkew)) {
+		return occurred, false, true
+	}
+	if c.MaxPastAge > 0 {
+		if floor := now.Add(-c.MaxPastAge); occurred.Before(floor) {
+			return floor, true, false
+		}
+	}
+	return occurred, false, false
+}
+
 type EventService interface {
-	Ingest(dbc dbctx.Context, inputs []EventInput) (int, error)
+	// Ingest stores a batch idempotently: events are unique per (user, client_event_id), so
+	// retried submissions come back as duplicates and downstream folds never see them twice.
+	Ingest(dbc dbctx.Context, inputs []EventInput) (*EventIngestResult, error)
 }
 
 type eventService struct {
 	db   *gorm.DB
 	log  *logger.Logger
 	repo repos.UserEventRepo
+	cfg  eventIngestConfig
 }
 
 func NewEventService(db *gorm.DB, baseLog *logger.Logger, repo repos.UserEventRepo) EventService {
@@ -63,42 +165,76 @@ func NewEventService(db *gorm.DB, baseLog *logger.Logger, repo repos.UserEventRe
 		db:   db,
 		log:  baseLog.With("service", "EventService"),
 		repo: repo,
+		cfg:  eventIngestConfigFromEnv(),
 	}
 }
 
-func (s *eventService) Ingest(dbc dbctx.Context, inputs []EventInput) (int, error) {
+func (s *eventService) Ingest(dbc dbctx.Context, inputs []EventInput) (*EventIngestResult, error) {
 	rd := ctxutil.GetRequestData(dbc.Ctx)
 	if rd == nil || rd.UserID == uuid.Nil {
-		return 0, fmt.Errorf("not authenticated")
+		return nil, fmt.Errorf("not authenticated")
 	}
+	res := &EventIngestResult{Events: make([]EventIngestItem, len(inputs))}
 	if len(inputs) == 0 {
-		return 0, nil
+		return res, nil
 	}
-	if len(inputs) > 200 {
-		return 0, fmt.Errorf("too many events (max 200)")
+	if len(inputs) > maxEventsPerBatch {
+		return nil, ErrTooManyEvents
 	}
 	now := time.Now().UTC()
+	requireID := s.cfg.clientIDRequired(now)
 	rows := make([]*types.UserEvent, 0, len(inputs))
-	metrics := observability.Current()
+	rowIndex := make([]int, 0, len(inputs))
+	rowData := make([]map[string]any, 0, len(inputs))
+	seen := map[string]bool{}
+	missingIDs := []int{}
 	for i := range inputs {
 		in := inputs[i]
+		item := &res.Events[i]
+		item.Index = i
 
 		typ := strings.TrimSpace(strings.ToLower(in.Type))
 		if !eventTypeRe.MatchString(typ) {
-			return 0, fmt.Errorf("invalid event type at index %d", i)
+			return nil, fmt.Errorf("invalid event type at index %d", i)
 		}
 
+		clientID := strings.TrimSpace(in.ClientEventID)
+		if _, err := uuid.Parse(clientID); err != nil {
+			if requireID {
+				missingIDs = append(missingIDs, i)
+				continue
+			}
+			if s.log != nil {
+				s.log.WarnThrottled("event_ingest.client_event_id", time.Minute, "event ingest: missing or non-uuid client_event_id", "type", typ, "client_event_id", clientID)
+			}
+			// Non-UUID ids from older clients still dedupe; only empty ones need a fallback.
+			if clientID == "" {
+				clientID = uuid.New().String()
+				item.GeneratedID = true
+				res.GeneratedIDs++
+			}
+		}
+		item.ClientEventID = clientID
+		if seen[clientID] {
+			item.Status = EventIngestDuplicate
+			item.Reason = "duplicate_in_batch"
+			continue
+		}
+		seen[clientID] = true
+
 		occurred := now
 		if in.OccurredAt != nil && !in.OccurredAt.IsZero() {
 			occurred = in.OccurredAt.UTC()
 		}
-
-		clientID := strings.TrimSpace(in.ClientEventID)
-		if clientID == "" {
-			if s.log != nil {
-				s.log.Warn("event ingest: missing client_event_id; generating fallback", "type", typ)
-			}
-			clientID = uuid.New().String()
+		stored, clamped, future := s.cfg.guardOccurredAt(occurred, now)
+		if future {
+			item.Status = EventIngestRejected
+			item.Reason = "occurred_at_in_future"
+			continue
+		}
+		if clamped {
+			item.Clamped = true
+			res.Clamped++
 		}
 
 		var (
@@ -141,6 +277,9 @@ func (s *eventService) Ingest(dbc dbctx.Context, inputs []EventInput) (int, erro
 		if strings.TrimSpace(in.Modality) != "" {
 			data["modality"] = strings.TrimSpace(in.Modality)
 		}
+		if clamped {
+			data["client_occurred_at"] = occurred.Format(time.RFC3339Nano)
+		}
 		if reqKeys, ok := requiredEventKeys[typ]; ok && len(reqKeys) > 0 {
 			missing := make([]string, 0, len(reqKeys))
 			for _, key := range reqKeys {
@@ -154,50 +293,14 @@ func (s *eventService) Ingest(dbc dbctx.Context, inputs []EventInput) (int, erro
 				})
 			}
 		}
-		if metrics != nil {
-			switch typ {
-			case personalization.EventClientPerf:
-				kind := strings.TrimSpace(fmt.Sprint(data["kind"]))
-				name := strings.TrimSpace(fmt.Sprint(data["name"]))
-				ms := floatFromAny(data["duration_ms"])
-				if ms <= 0 {
-					ms = floatFromAny(data["value"])
-				}
-				if ms > 0 {
-					metrics.ObserveClientPerf(kind, name, ms/1000)
-				}
-			case personalization.EventClientError:
-				kind := strings.TrimSpace(fmt.Sprint(data["kind"]))
-				metrics.IncClientError(kind)
-			case personalization.EventExperimentExposure:
-				experiment := stringFromAny(data["experiment"])
-				variant := stringFromAny(data["variant"])
-				source := stringFromAny(data["source"])
-				metrics.IncExperimentExposure(experiment, variant, source)
-			case personalization.EventExperimentGuardrailBreach:
-				experiment := stringFromAny(data["experiment"])
-				guardrail := stringFromAny(data["guardrail"])
-				metrics.IncExperimentGuardrail(experiment, guardrail)
-			case personalization.EventEngagementFunnelStep:
-				funnel := stringFromAny(data["funnel"])
-				step := stringFromAny(data["step"])
-				metrics.IncEngagementFunnelStep(funnel, step)
-			case personalization.EventCostTelemetry:
-				category := stringFromAny(data["category"])
-				source := stringFromAny(data["source"])
-				amount := floatFromAny(data["amount_usd"])
-				metrics.AddCost(category, source, amount)
-			case personalization.EventSecurityEvent:
-				event := stringFromAny(data["event"])
-				metrics.IncSecurityEvent(event)
-			}
-		}
 		b, _ := json.Marshal(data)
+		rowIndex = append(rowIndex, i)
+		rowData = append(rowData, data)
 		rows = append(rows, &types.UserEvent{
 			ID:              uuid.New(),
 			UserID:          rd.UserID,
 			ClientEventID:   clientID,
-			OccurredAt:      occurred,
+			OccurredAt:      stored,
 			SessionID:       rd.SessionID,
 			PathID:          pathID,
 			PathNodeID:      pathNodeID,
@@ -211,14 +314,81 @@ func (s *eventService) Ingest(dbc dbctx.Context, inputs []EventInput) (int, erro
 			UpdatedAt:       now,
 		})
 	}
+	if len(missingIDs) > 0 {
+		return nil, fmt.Errorf("%w (missing at indexes %v)", ErrClientEventIDRequired, missingIDs)
+	}
+
 	transaction := dbc.Tx
 	if transaction == nil {
 		transaction = s.db
 	}
-	n, err := s.repo.CreateIgnoreDuplicates(dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}, rows)
+	inserted, err := s.repo.CreateIgnoreDuplicatesReturningIDs(dbctx.Context{Ctx: dbc.Ctx, Tx: transaction}, rows)
 	if err != nil {
 		s.log.Warn("event ingest failed", "error", err)
-		return 0, err
+		return nil, err
+	}
+	metrics := observability.Current()
+	for k, row := range rows {
+		item := &res.Events[rowIndex[k]]
+		if !inserted[row.ID] {
+			item.Status = EventIngestDuplicate
+			continue
+		}
+		item.Status = EventIngestAccepted
+		if metrics != nil {
+			observeEventMetrics(metrics, row.Type, rowData[k])
+		}
+	}
+	for _, item := range res.Events {
+		switch item.Status {
+		case EventIngestAccepted:
+			res.Accepted++
+		case EventIngestDuplicate:
+			res.Duplicates++
+		case EventIngestRejected:
+			res.Rejected++
+		}
+	}
+	return res, nil
+}
+
+// observeEventMetrics records client-reported telemetry for an accepted event only, so
+// retried batches don't double count.
+func observeEventMetrics(metrics *observability.Metrics, typ string, data map[string]any) {
+	switch typ {
+	case personalization.EventClientPerf:
+		kind := strings.TrimSpace(fmt.Sprint(data["kind"]))
+		name := strings.TrimSpace(fmt.Sprint(data["name"]))
+		ms := floatFromAny(data["duration_ms"])
+		if ms <= 0 {
+			ms = floatFromAny(data["value"])
+		}
+		if ms > 0 {
+			metrics.ObserveClientPerf(kind, name, ms/1000)
+		}
+	case personalization.EventClientError:
+		kind := strings.TrimSpace(fmt.Sprint(data["kind"]))
+		metrics.IncClientError(kind)
+	case personalization.EventExperimentExposure:
+		experiment := stringFromAny(data["experiment"])
+		variant := stringFromAny(data["variant"])
+		source := stringFromAny(data["source"])
+		metrics.IncExperimentExposure(experiment, variant, source)
+	case personalization.EventExperimentGuardrailBreach:
+		experiment := stringFromAny(data["experiment"])
+		guardrail := stringFromAny(data["guardrail"])
+		metrics.IncExperimentGuardrail(experiment, guardrail)
+	case personalization.EventEngagementFunnelStep:
+		funnel := stringFromAny(data["funnel"])
+		step := stringFromAny(data["step"])
+		metrics.IncEngagementFunnelStep(funnel, step)
+	case personalization.EventCostTelemetry:
+		category := stringFromAny(data["category"])
+		source := stringFromAny(data["source"])
+		amount := floatFromAny(data["amount_usd"])
+		metrics.AddCost(category, source, amount)
+	case personalization.EventSecurityEvent:
+		event := stringFromAny(data["event"])
+		metrics.IncSecurityEvent(event)
 	}
-	return n, nil
 }
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yungbote/neurobridge-backend/internal/data/repos"
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+	"github.com/yungbote/neurobridge-backend/internal/platform/ctxutil"
+	"github.com/yungbote/neurobridge-backend/internal/platform/dbctx"
+	"github.com/yungbote/neurobridge-backend/internal/platform/logger"
+)
+
+// memUserEventRepo enforces the (user_id, client_event_id) uniqueness the DB index provides.
+type memUserEventRepo struct {
+	repos.UserEventRepo
+	rows []*types.UserEvent
+}
+
+func (r *memUserEventRepo) CreateIgnoreDuplicatesReturningIDs(dbc dbctx.Context, events []*types.UserEvent) (map[uuid.UUID]bool, error) {
+	out := map[uuid.UUID]bool{}
+	for _, ev := range events {
+		dup := false
+		for _, existing := range r.rows {
+			if existing.UserID == ev.UserID && existing.ClientEventID == ev.ClientEventID {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			r.rows = append(r.rows, ev)
+			out[ev.ID] = true
+		}
+	}
+	return out, nil
+}
+
+func newTestEventService(t *testing.T, cfg eventIngestConfig) (*eventService, *memUserEventRepo, dbctx.Context) {
+	t.Helper()
+	log, err := logger.New("test")
+	if err != nil {
+		t.Fatalf("logger.New: %v", err)
+	}
+	repo := &memUserEventRepo{}
+	svc := &eventService{log: log, repo: repo, cfg: cfg}
+	ctx := ctxutil.WithRequestData(context.Background(), &ctxutil.RequestData{UserID: uuid.New(), SessionID: uuid.New()})
+	return svc, repo, dbctx.Context{Ctx: ctx}
+}
+
+func eventStatuses(res *EventIngestResult) []EventIngestStatus {
+	out := make([]EventIngestStatus, 0, len(res.Events))
+	for _, item := range res.Events {
+		out = append(out, item.Status)
+	}
+	return out
+}
+
+func TestEventIngestDuplicateBatches(t *testing.T) {
+	svc, repo, dbc := newTestEventService(t, eventIngestConfig{MaxFutureSkew: time.Minute, MaxPastAge: time.Hour})
+	a, b := uuid.NewString(), uuid.NewString()
+	batch := []EventInput{
+		{ClientEventID: a, Type: "quiz_completed"},
+		{ClientEventID: b, Type: "block_read", Data: map[string]any{"block_id": "b1"}},
+		{ClientEventID: a, Type: "quiz_completed"},
+	}
+
+	res, err := svc.Ingest(dbc, batch)
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Accepted != 2 || res.Duplicates != 1 || res.Rejected != 0 {
+		t.Fatalf("first batch = %+v", res)
+	}
+	if got := res.Events[2]; got.Status != EventIngestDuplicate || got.Reason != "duplicate_in_batch" || got.ClientEventID != a {
+		t.Fatalf("in-batch duplicate = %+v", got)
+	}
+
+	// The client retries the whole batch after a dropped response.
+	res, err = svc.Ingest(dbc, batch)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if res.Accepted != 0 || res.Duplicates != 3 {
+		t.Fatalf("retried batch = %+v (%v)", res, eventStatuses(res))
+	}
+	if len(repo.rows) != 2 {
+		t.Fatalf("stored %d events, want 2", len(repo.rows))
+	}
+}
+
+func TestEventIngestClockSkew(t *testing.T) {
+	svc, repo, dbc := newTestEventService(t, eventIngestConfig{MaxFutureSkew: 5 * time.Minute, MaxPastAge: 24 * time.Hour})
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+	nearFuture := now.Add(time.Minute)
+	old := now.Add(-72 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	res, err := svc.Ingest(dbc, []EventInput{
+		{ClientEventID: uuid.NewString(), Type: "block_read", OccurredAt: &future},
+		{ClientEventID: uuid.NewString(), Type: "block_read", OccurredAt: &nearFuture},
+		{ClientEventID: uuid.NewString(), Type: "block_read", OccurredAt: &old},
+		{ClientEventID: uuid.NewString(), Type: "block_read", OccurredAt: &recent},
+	})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Accepted != 3 || res.Rejected != 1 || res.Clamped != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if got := res.Events[0]; got.Status != EventIngestRejected || got.Reason != "occurred_at_in_future" {
+		t.Fatalf("future event = %+v", got)
+	}
+	if !res.Events[2].Clamped || res.Events[1].Clamped || res.Events[3].Clamped {
+		t.Fatalf("clamp flags = %+v", res.Events)
+	}
+
+	if len(repo.rows) != 3 {
+		t.Fatalf("stored %d events, want 3", len(repo.rows))
+	}
+	clamped := repo.rows[1]
+	floor := now.Add(-24 * time.Hour)
+	if d := clamped.OccurredAt.Sub(floor); d < 0 || d > time.Minute {
+		t.Fatalf("clamped occurred_at = %v, want ~%v", clamped.OccurredAt, floor)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(clamped.Data, &data); err != nil {
+		t.Fatalf("data: %v", err)
+	}
+	if data["client_occurred_at"] != old.Format(time.RFC3339Nano) {
+		t.Fatalf("client_occurred_at = %v", data["client_occurred_at"])
+	}
+	if !repo.rows[0].OccurredAt.Equal(nearFuture) || !repo.rows[2].OccurredAt.Equal(recent) {
+		t.Fatalf("in-window times changed: %v %v", repo.rows[0].OccurredAt, repo.rows[2].OccurredAt)
+	}
+}
+
+func TestEventIngestClientEventIDPolicy(t *testing.T) {
+	// Deprecation window: missing ids are generated, non-UUID ids are kept so retries dedupe.
+	svc, repo, dbc := newTestEventService(t, eventIngestConfig{ClientIDRequiredAfter: time.Now().Add(time.Hour)})
+	res, err := svc.Ingest(dbc, []EventInput{{Type: "path_opened"}, {ClientEventID: "legacy-1", Type: "path_opened"}})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Accepted != 2 || res.GeneratedIDs != 1 || !res.Events[0].GeneratedID || res.Events[1].GeneratedID {
+		t.Fatalf("result = %+v", res)
+	}
+	if _, err := uuid.Parse(repo.rows[0].ClientEventID); err != nil || repo.rows[1].ClientEventID != "legacy-1" {
+		t.Fatalf("client ids = %q, %q", repo.rows[0].ClientEventID, repo.rows[1].ClientEventID)
+	}
+
+	// After the cutoff the whole batch is rejected.
+	svc.cfg.ClientIDRequiredAfter = time.Now().Add(-time.Hour)
+	_, err = svc.Ingest(dbc, []EventInput{{ClientEventID: uuid.NewString(), Type: "path_opened"}, {Type: "path_opened"}})
+	if !errors.Is(err, ErrClientEventIDRequired) {
+		t.Fatalf("err = %v, want ErrClientEventIDRequired", err)
+	}
+	if len(repo.rows) != 2 {
+		t.Fatalf("rejected batch stored rows: %d", len(repo.rows))
+	}
+}
@@ -29,6 +29,8 @@ type Activities struct {
 	Registry *jobrt.Registry
 	Notify   services.JobNotifier
 	Metrics  *observability.Metrics
+	// Scheduler gates fair-scheduled (docgen) jobs; nil runs every job as soon as it ticks.
+	Scheduler services.DocGenScheduler
 }
 
 func (a *Activities) Tick(ctx context.Context, jobID string) (TickResult, error) {
@@ -87,6 +89,44 @@ func (a *Activities) Tick(ctx context.Context, jobID string) (TickResult, error)
 		return res, nil
 	}
 
+	if until, ok := deferredUntil(job, time.Now()); ok {
+		// Deferred (e.g. LLM provider throttled at enqueue): leave it queued and wake up then.
+		res.Status = job.Status
+		res.Stage = job.Stage
+		res.Progress = job.Progress
+		res.Message = job.Message
+		res.WaitUntil = &until
+		finalStatus = "deferred"
+		return res, nil
+	}
+
+	if a.Scheduler != nil && a.Scheduler.Applies(job) {
+		claimed, err := a.Scheduler.TryClaim(ctx, job)
+		switch {
+		case err != nil:
+			// Fail open: a scheduler outage must not stall doc generation.
+			if a.Log != nil {
+				a.Log.WarnThrottled("jobrun.sched_claim", time.Minute, "docgen scheduler claim failed; running unscheduled", "error", err, "job_id", parsedJobID)
+			}
+		case !claimed:
+			// Not this job's turn: keep it queued (touching updated_at keeps it in the
+			// scheduler's live set) and try again on the next poll.
+			now := time.Now().UTC()
+			_ = a.DB.WithContext(ctx).
+				Model(&types.JobRun{}).
+				Where("id = ? AND status IN ?", parsedJobID, []string{"queued", "running"}).
+				Updates(map[string]any{"status": "queued", "updated_at": now}).Error
+			res.Status = "queued"
+			res.Stage = job.Stage
+			res.Progress = job.Progress
+			res.Message = job.Message
+			finalStatus = "throttled"
+			return res, nil
+		default:
+			defer a.Scheduler.Release(context.WithoutCancel(ctx), job)
+		}
+	}
+
 	stopHB := a.startHeartbeat(ctx, parsedJobID)
 	defer stopHB()
 
@@ -121,6 +161,9 @@ func (a *Activities) Tick(ctx context.Context, jobID string) (TickResult, error)
 			if td.RequestID != "" {
 				jobLog = jobLog.With("request_id", td.RequestID)
 			}
+			if td.Depth > 0 {
+				jobLog = jobLog.With("trace_depth", td.Depth)
+			}
 		}
 	}
 	if !ok {
@@ -223,6 +266,17 @@ func (a *Activities) startHeartbeat(ctx context.Context, jobID uuid.UUID) func()
 	return func() { close(done) }
 }
 
+// deferredUntil reports whether a queued job must not run before its NotBefore.
+func deferredUntil(job *types.JobRun, now time.Time) (time.Time, bool) {
+	if job == nil || job.NotBefore == nil || !strings.EqualFold(strings.TrimSpace(job.Status), "queued") {
+		return time.Time{}, false
+	}
+	if !job.NotBefore.After(now) {
+		return time.Time{}, false
+	}
+	return *job.NotBefore, true
+}
+
 func extractWaitUntil(raw []byte) *time.Time {
 	if len(raw) == 0 || strings.TrimSpace(string(raw)) == "" || strings.TrimSpace(string(raw)) == "null" {
 		return nil
@@ -0,0 +1,27 @@
+package jobrun
+
+import (
+	"testing"
+	"time"
+
+	types "github.com/yungbote/neurobridge-backend/internal/domain"
+)
+
+func TestDeferredUntil(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(45 * time.Second)
+	earlier := now.Add(-time.Second)
+
+	if until, ok := deferredUntil(&types.JobRun{Status: "queued", NotBefore: &later}, now); !ok || !until.Equal(later) {
+		t.Fatalf("queued job before not_before should wait until %s, got %s %v", later, until, ok)
+	}
+	if _, ok := deferredUntil(&types.JobRun{Status: "queued", NotBefore: &earlier}, now); ok {
+		t.Fatalf("job past not_before should run")
+	}
+	if _, ok := deferredUntil(&types.JobRun{Status: "queued"}, now); ok {
+		t.Fatalf("job without not_before should run")
+	}
+	if _, ok := deferredUntil(&types.JobRun{Status: "running", NotBefore: &later}, now); ok {
+		t.Fatalf("running job should not be deferred")
+	}
+}
@@ -36,6 +36,7 @@ type Runner struct {
 	registry *jobrt.Registry
 	notify   services.JobNotifier
 	metrics  *observability.Metrics
+	sched    services.DocGenScheduler
 }
 
 func NewRunner(
@@ -46,6 +47,7 @@ func NewRunner(
 	registry *jobrt.Registry,
 	notify services.JobNotifier,
 	metrics *observability.Metrics,
+	sched services.DocGenScheduler,
 ) (*Runner, error) {
 	if tc == nil {
 		return nil, fmt.Errorf("temporal client is not configured")
@@ -61,6 +63,7 @@ func NewRunner(
 		registry: registry,
 		notify:   notify,
 		metrics:  metrics,
+		sched:    sched,
 	}, nil
 }
 
@@ -172,12 +175,13 @@ func (r *Runner) newWorker() (worker.Worker, error) {
 	})
 
 	acts := &jobrun.Activities{
-		Log:      r.log,
-		DB:       r.db,
-		Jobs:     r.jobRepo,
-		Registry: r.registry,
-		Notify:   r.notify,
-		Metrics:  r.metrics,
+		Log:       r.log,
+		DB:        r.db,
+		Jobs:      r.jobRepo,
+		Registry:  r.registry,
+		Notify:    r.notify,
+		Metrics:   r.metrics,
+		Scheduler: r.sched,
 	}
 
 	w.RegisterWorkflowWithOptions(jobrun.Workflow, workflow.RegisterOptions{Name: jobrun.WorkflowName})